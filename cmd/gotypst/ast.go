@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// runAST parses an input file and prints its syntax tree as JSON or
+// S-expressions. Syntax errors do not fail the command, since they are
+// part of the tree.
+func runAST(args []string) error {
+	fs := flag.NewFlagSet("ast", flag.ExitOnError)
+	formatName := fs.String("format", "json", "The format to print the tree in: json or sexpr")
+
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return asUsageError(err)
+	}
+	if len(positional) < 1 {
+		return usageErrorf("missing input file")
+	}
+	input := positional[0]
+
+	format, err := syntax.ParseTreeFormat(*formatName)
+	if err != nil {
+		return asUsageError(err)
+	}
+	text, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("cannot read input file: %w", err)
+	}
+
+	vpath, err := syntax.NewVirtualPath("/" + filepath.Base(input))
+	if err != nil {
+		return err
+	}
+	id := syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern()
+	data, err := syntax.MarshalTree(syntax.NewSource(id, string(text)).Root(), format)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
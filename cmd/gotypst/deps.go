@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/boergens/gotypst"
+	"github.com/boergens/gotypst/routines"
+)
+
+// dependencies are the files a document depends on, as printed by the deps
+// command. All paths are absolute.
+type dependencies struct {
+	// Main is the main source file, or "-" if it was read from stdin.
+	Main string `json:"main"`
+	// Sources are the source files, including the main file and the files
+	// it transitively imports and includes.
+	Sources []string `json:"sources"`
+	// Files are the other files the document reads, like images, data
+	// files, and bibliographies.
+	Files []string `json:"files"`
+}
+
+// runDeps compiles an input file and prints the files it depends on as
+// JSON, so that build systems can track when to recompile it. Files that
+// are only found at compile time, like those read with a computed path,
+// are included since the document is compiled in full.
+//
+// The dependencies are printed even if compilation fails, since they still
+// decide when it's worth trying again.
+func runDeps(args []string) error {
+	fs := flag.NewFlagSet("deps", flag.ExitOnError)
+	pretty := fs.Bool("pretty", false, "Whether to pretty-print the output")
+	root := fs.String("root", "", "Project root directory")
+	diagFormat := diagnosticFormatFlag(fs)
+	color := fs.String("color", "auto", "Whether to use colors in diagnostics")
+	fonts := fontsFlag(fs)
+	inputs := inputsFlag(fs)
+
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return asUsageError(err)
+	}
+	if len(positional) < 1 {
+		return usageErrorf("missing input file")
+	}
+	input := positional[0]
+
+	format, err := parseDiagnosticFormat(*diagFormat)
+	if err != nil {
+		return asUsageError(err)
+	}
+	colored, err := useColor(*color)
+	if err != nil {
+		return asUsageError(err)
+	}
+	printer := &diagnosticPrinter{out: os.Stderr, format: format, color: colored}
+
+	projectRoot, err := projectRootFor(input, *root)
+	if err != nil {
+		return err
+	}
+	world, err := newWorld(input, projectRoot, *fonts, inputs)
+	if err != nil {
+		return err
+	}
+	printer.source = world.Source
+
+	_, diags := gotypst.Compile(world, gotypst.CompileOptions{Routines: routines.Standard})
+	printer.print(diags)
+
+	main := input
+	if input != stdio {
+		if main, err = filepath.Abs(input); err != nil {
+			return err
+		}
+	}
+	sources, files := world.Dependencies()
+	if err := writeDeps(os.Stdout, dependencies{Main: main, Sources: sources, Files: files}, *pretty); err != nil {
+		return exportErrorf("cannot write dependencies: %w", err)
+	}
+	if diags.HasErrors() {
+		return errDiagnosed
+	}
+	return nil
+}
+
+// writeDeps writes the dependencies as a JSON object on its own line, or
+// indented if pretty is set. Empty lists are written as such rather than
+// as null.
+func writeDeps(w io.Writer, deps dependencies, pretty bool) error {
+	if deps.Sources == nil {
+		deps.Sources = []string{}
+	}
+	if deps.Files == nil {
+		deps.Files = []string{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(deps)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDeps(t *testing.T) {
+	var buf bytes.Buffer
+	deps := dependencies{Main: "/doc/main.typ", Sources: []string{"/doc/main.typ", "/doc/a&b.typ"}}
+	if err := writeDeps(&buf, deps, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"main":"/doc/main.typ","sources":["/doc/main.typ","/doc/a&b.typ"],"files":[]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("writeDeps() = %s, want %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeDeps(&buf, dependencies{Main: "/main.typ"}, true); err != nil {
+		t.Fatal(err)
+	}
+	want = "{\n  \"main\": \"/main.typ\",\n  \"sources\": [],\n  \"files\": []\n}\n"
+	if buf.String() != want {
+		t.Errorf("writeDeps(pretty) = %s, want %s", buf.String(), want)
+	}
+}
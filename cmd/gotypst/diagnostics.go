@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// diagnosticFormat selects how diagnostics are printed.
+type diagnosticFormat int
+
+const (
+	// formatHuman prints diagnostics with source excerpts.
+	formatHuman diagnosticFormat = iota
+	// formatShort prints one line per diagnostic.
+	formatShort
+	// formatJSONLines prints one JSON object per diagnostic and line, for
+	// CI systems and editor plugins.
+	formatJSONLines
+)
+
+// parseDiagnosticFormat parses the value of the --diagnostic-format flag.
+func parseDiagnosticFormat(s string) (diagnosticFormat, error) {
+	switch s {
+	case "human":
+		return formatHuman, nil
+	case "short":
+		return formatShort, nil
+	case "json":
+		return formatJSONLines, nil
+	}
+	return 0, fmt.Errorf("invalid diagnostic format: %s (expected human, short, or json)", s)
+}
+
+// diagnosticFormatFlag registers the --diagnostic-format flag, which is
+// also accepted as --diagnostics-format.
+func diagnosticFormatFlag(fs *flag.FlagSet) *string {
+	format := fs.String("diagnostic-format", "human", "The format to emit diagnostics in: human, short, or json")
+	fs.StringVar(format, "diagnostics-format", "human", "The format to emit diagnostics in (alias)")
+	return format
+}
+
+// useColor decides whether to color the output based on the value of the
+// --color flag. In auto mode, colors are used if stderr is a terminal and
+// NO_COLOR is not set.
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := os.Stderr.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	}
+	return false, fmt.Errorf("invalid color mode: %s (expected auto, always, or never)", mode)
+}
+
+// ANSI styles for the parts of a diagnostic.
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleError   = "\x1b[1;91m"
+	styleWarning = "\x1b[1;93m"
+	styleHelp    = "\x1b[1;92m"
+	styleBorder  = "\x1b[34m"
+)
+
+// tabWidth is the number of columns a tab is expanded to in excerpts.
+const tabWidth = 2
+
+// diagnosticPrinter renders diagnostics for the terminal.
+//
+// Matches Rust: fn print_diagnostics in typst-cli/src/compile.rs
+type diagnosticPrinter struct {
+	out    io.Writer
+	format diagnosticFormat
+	color  bool
+	// source looks up the source file a span points into.
+	source func(id syntax.FileId) (*syntax.Source, error)
+}
+
+// print writes the diagnostics. Each trace point of a diagnostic is shown
+// as a separate help message pointing at the call through which the error
+// propagated.
+func (p *diagnosticPrinter) print(diags []foundations.SourceDiagnostic) {
+	if p.format == formatJSONLines {
+		p.printJSON(diags)
+		return
+	}
+	for _, diag := range diags {
+		p.emit(diag.Severity.String(), p.severityStyle(diag.Severity), diag.Message, diag.Span, diag.Hints)
+		for _, point := range diag.Trace {
+			if p.surrounds(point.Span, diag.Span) {
+				continue
+			}
+			p.emit("help", styleHelp, point.V, point.Span, nil)
+		}
+	}
+}
+
+// emit writes a single diagnostic message with its excerpt and hints.
+func (p *diagnosticPrinter) emit(severity, style, message string, span syntax.Span, hints []string) {
+	loc, ok := p.locate(span)
+	if p.format == formatShort {
+		if ok {
+			fmt.Fprintf(p.out, "%s:%d:%d: ", loc.path, loc.line+1, loc.column+1)
+		}
+		fmt.Fprintf(p.out, "%s: %s\n", p.paint(style, severity), message)
+		return
+	}
+
+	fmt.Fprintf(p.out, "%s%s\n", p.paint(style, severity), p.paint(styleBold, ": "+message))
+	if !ok {
+		for _, hint := range hints {
+			fmt.Fprintf(p.out, "  = hint: %s\n", hint)
+		}
+		fmt.Fprintln(p.out)
+		return
+	}
+
+	number := strconv.Itoa(loc.line + 1)
+	pad := strings.Repeat(" ", len(number))
+	border := func(s string) string { return p.paint(styleBorder, s) }
+
+	fmt.Fprintf(p.out, "%s %s %s:%d:%d\n", pad, border("┌─"), loc.path, loc.line+1, loc.column+1)
+	fmt.Fprintf(p.out, "%s %s\n", pad, border("│"))
+	fmt.Fprintf(p.out, "%s %s %s\n", border(number), border("│"), expandTabs(loc.text))
+	fmt.Fprintf(p.out, "%s %s %s%s\n", pad, border("│"),
+		strings.Repeat(" ", displayWidth(loc.text[:loc.start])),
+		p.paint(p.labelStyle(style), strings.Repeat("^", max(1, displayWidth(loc.text[loc.start:loc.end])))))
+	if len(hints) > 0 {
+		fmt.Fprintf(p.out, "%s %s\n", pad, border("│"))
+		for _, hint := range hints {
+			fmt.Fprintf(p.out, "%s %s hint: %s\n", pad, border("="), hint)
+		}
+	}
+	fmt.Fprintln(p.out)
+}
+
+// jsonDiagnostic is a diagnostic as printed in the JSON format. The file
+// and range are left out if the diagnostic doesn't point into a file.
+type jsonDiagnostic struct {
+	Severity string     `json:"severity"`
+	Message  string     `json:"message"`
+	File     string     `json:"file,omitempty"`
+	Range    *jsonRange `json:"range,omitempty"`
+	Hints    []string   `json:"hints"`
+	// Trace holds the calls through which the error propagated, like
+	// the help messages of the human format.
+	Trace []jsonTracePoint `json:"trace"`
+}
+
+// jsonTracePoint is a point of a diagnostic's trace in the JSON format.
+type jsonTracePoint struct {
+	Message string     `json:"message"`
+	File    string     `json:"file,omitempty"`
+	Range   *jsonRange `json:"range,omitempty"`
+}
+
+// jsonRange is the range of a span in the JSON format. Lines and columns
+// are 1-indexed, like in the other formats, and columns count characters.
+// The end is exclusive.
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+// jsonPosition is a position in a source file in the JSON format.
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// printJSON writes each diagnostic as a JSON object on its own line.
+func (p *diagnosticPrinter) printJSON(diags []foundations.SourceDiagnostic) {
+	enc := json.NewEncoder(p.out)
+	enc.SetEscapeHTML(false)
+	for _, diag := range diags {
+		out := jsonDiagnostic{
+			Severity: diag.Severity.String(),
+			Message:  diag.Message,
+			Hints:    diag.Hints,
+			Trace:    []jsonTracePoint{},
+		}
+		if out.Hints == nil {
+			out.Hints = []string{}
+		}
+		out.File, out.Range = p.jsonSpan(diag.Span)
+		for _, point := range diag.Trace {
+			if p.surrounds(point.Span, diag.Span) {
+				continue
+			}
+			traced := jsonTracePoint{Message: point.V}
+			traced.File, traced.Range = p.jsonSpan(point.Span)
+			out.Trace = append(out.Trace, traced)
+		}
+		enc.Encode(out)
+	}
+}
+
+// jsonSpan returns the file and range of a span, or nothing for a span
+// that doesn't point into a file.
+func (p *diagnosticPrinter) jsonSpan(span syntax.Span) (string, *jsonRange) {
+	source, start, end, ok := p.resolve(span)
+	if !ok {
+		return "", nil
+	}
+	lines := source.Lines()
+	position := func(offset int) jsonPosition {
+		line, column := lines.ByteToLineColumn(offset)
+		return jsonPosition{Line: line + 1, Column: column + 1}
+	}
+	return filePath(source.Id()), &jsonRange{Start: position(start), End: position(max(end, start))}
+}
+
+// location is the position of a span in its source file.
+type location struct {
+	path string
+	// line and column are 0-indexed. The column counts characters.
+	line, column int
+	// text is the first line of the span. start and end are the byte range
+	// of the span within it.
+	text       string
+	start, end int
+}
+
+// locate finds the file, line, and column a span points to.
+func (p *diagnosticPrinter) locate(span syntax.Span) (location, bool) {
+	source, start, end, ok := p.resolve(span)
+	if !ok {
+		return location{}, false
+	}
+	lines := source.Lines()
+	line, column := lines.ByteToLineColumn(start)
+	lineStart := lines.LineStart(line)
+	text := lines.Line(line)
+	end = min(max(end, start), lineStart+len(text))
+	return location{
+		path:   filePath(source.Id()),
+		line:   line,
+		column: column,
+		text:   text,
+		start:  start - lineStart,
+		end:    end - lineStart,
+	}, true
+}
+
+// resolve finds the source and byte range of a span.
+func (p *diagnosticPrinter) resolve(span syntax.Span) (*syntax.Source, int, int, bool) {
+	id := span.Id()
+	if id == nil || p.source == nil {
+		return nil, 0, 0, false
+	}
+	source, err := p.source(*id)
+	if err != nil || source == nil {
+		return nil, 0, 0, false
+	}
+	start, end, ok := source.Range(span)
+	if !ok {
+		return nil, 0, 0, false
+	}
+	return source, start, end, true
+}
+
+// surrounds reports whether the trace point's span contains the error's
+// span. Such trace points add no information and are skipped.
+func (p *diagnosticPrinter) surrounds(point, span syntax.Span) bool {
+	source, pointStart, pointEnd, ok := p.resolve(point)
+	if !ok {
+		return true
+	}
+	errSource, start, end, ok := p.resolve(span)
+	return ok && errSource == source && pointStart <= start && end <= pointEnd
+}
+
+// severityStyle returns the style of a severity's label.
+func (p *diagnosticPrinter) severityStyle(severity foundations.DiagnosticSeverity) string {
+	if severity == foundations.SeverityWarning {
+		return styleWarning
+	}
+	return styleError
+}
+
+// labelStyle returns the style of the carets below an excerpt. They take
+// the color of the severity without being bold.
+func (p *diagnosticPrinter) labelStyle(style string) string {
+	return "\x1b[" + strings.TrimPrefix(style, "\x1b[1;")
+}
+
+// paint wraps text in an ANSI style if colors are enabled.
+func (p *diagnosticPrinter) paint(style, text string) string {
+	if !p.color {
+		return text
+	}
+	return style + text + styleReset
+}
+
+// filePath returns the path of a file as shown in diagnostics. Files in the
+// project are shown relative to the project root, files in packages are
+// prefixed with the package specification.
+func filePath(id syntax.FileId) string {
+	rooted := id.Get()
+	if rooted.Package() != nil {
+		return rooted.String()
+	}
+	return rooted.VPath().GetWithoutSlash()
+}
+
+// expandTabs replaces tabs with spaces.
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", tabWidth))
+}
+
+// displayWidth returns the number of columns s takes up in an excerpt.
+func displayWidth(s string) int {
+	return utf8.RuneCountInString(s) + strings.Count(s, "\t")*(tabWidth-1)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestPrintDiagnostics(t *testing.T) {
+	vpath, err := syntax.NewVirtualPath("/main.typ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern()
+	source := syntax.NewSource(id, "#let x = 1\n#\tfoo(x)\n")
+	lookup := func(syntax.FileId) (*syntax.Source, error) { return source, nil }
+
+	diag := foundations.NewSourceError(syntax.SpanFromRange(id, 13, 16), "unknown variable: foo").
+		WithHint("if you meant to display multiple letters as is, try adding spaces")
+
+	tests := []struct {
+		format diagnosticFormat
+		want   string
+	}{
+		{formatHuman, `error: unknown variable: foo
+  ┌─ main.typ:2:3
+  │
+2 │ #  foo(x)
+  │    ^^^
+  │
+  = hint: if you meant to display multiple letters as is, try adding spaces
+
+`},
+		{formatShort, "main.typ:2:3: error: unknown variable: foo\n"},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		printer := &diagnosticPrinter{out: &out, format: tt.format, source: lookup}
+		printer.print([]foundations.SourceDiagnostic{diag})
+		if out.String() != tt.want {
+			t.Errorf("got:\n%s\nwant:\n%s", out.String(), tt.want)
+		}
+	}
+}
+
+func TestPrintDetachedDiagnostic(t *testing.T) {
+	var out strings.Builder
+	printer := &diagnosticPrinter{out: &out, color: true}
+	printer.print([]foundations.SourceDiagnostic{foundations.NewSourceWarning(syntax.Detached(), "unknown font family: fooo")})
+	want := "\x1b[1;93mwarning\x1b[0m\x1b[1m: unknown font family: fooo\x1b[0m\n\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrintJSONDiagnostics(t *testing.T) {
+	vpath, err := syntax.NewVirtualPath("/main.typ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern()
+	source := syntax.NewSource(id, "#let x = 1\n#\tfoo(x)\n")
+	lookup := func(syntax.FileId) (*syntax.Source, error) { return source, nil }
+
+	diags := []foundations.SourceDiagnostic{
+		foundations.NewSourceError(syntax.SpanFromRange(id, 13, 16), "unknown variable: foo").
+			WithHint("if you meant to display multiple letters as is, try adding spaces"),
+		foundations.NewSourceWarning(syntax.Detached(), "unknown font family: fooo"),
+	}
+
+	var out strings.Builder
+	printer := &diagnosticPrinter{out: &out, format: formatJSONLines, color: true, source: lookup}
+	printer.print(diags)
+
+	want := `{"severity":"error","message":"unknown variable: foo","file":"main.typ","range":{"start":{"line":2,"column":3},"end":{"line":2,"column":6}},"hints":["if you meant to display multiple letters as is, try adding spaces"],"trace":[]}
+{"severity":"warning","message":"unknown font family: fooo","hints":[],"trace":[]}
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
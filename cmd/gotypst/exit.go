@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes of the CLI. They are stable, so that scripts and CI pipelines
+// can tell why a run failed.
+const (
+	// exitSuccess is returned when the command succeeded.
+	exitSuccess = 0
+	// exitCompileError is returned when the document failed to compile,
+	// including when warnings fail the compilation.
+	exitCompileError = 1
+	// exitUsageError is returned when the command line is invalid, like an
+	// unknown flag, a bad flag value, or a missing argument.
+	exitUsageError = 2
+	// exitExportError is returned when the document compiled, but the
+	// output could not be written.
+	exitExportError = 3
+)
+
+// usageError is an error in how the command line was used.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// usageErrorf formats an error in how the command line was used.
+func usageErrorf(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// asUsageError marks err as an error in how the command line was used. It
+// returns nil for a nil error.
+func asUsageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err: err}
+}
+
+// exportError is an error in writing the output of a compiled document.
+type exportError struct {
+	err error
+}
+
+func (e *exportError) Error() string { return e.err.Error() }
+func (e *exportError) Unwrap() error { return e.err }
+
+// exportErrorf formats an error in writing the output.
+func exportErrorf(format string, args ...any) error {
+	return &exportError{err: fmt.Errorf(format, args...)}
+}
+
+// exitCode returns the exit code for the outcome of a command.
+func exitCode(err error) int {
+	var usage *usageError
+	var export *exportError
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.As(err, &usage):
+		return exitUsageError
+	case errors.As(err, &export):
+		return exitExportError
+	default:
+		return exitCompileError
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/boergens/gotypst"
+	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/lsp"
+	"github.com/boergens/gotypst/routines"
+)
+
+// runLSP runs a language server that talks to the editor over stdin and
+// stdout.
+func runLSP(args []string) error {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	fonts := fontsFlag(fs)
+	inputs := inputsFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return asUsageError(err)
+	}
+
+	server := lsp.NewServer(lsp.Options{
+		Library: eval.NewLibraryBuilder().WithInputs(inputs).Build(),
+		NewWorld: func(root, path string) (lsp.World, error) {
+			return newWorld(path, root, *fonts, inputs)
+		},
+		Compile: func(world lsp.World) []foundations.SourceDiagnostic {
+			_, diags := gotypst.Compile(world, gotypst.CompileOptions{Routines: routines.Standard})
+			return diags
+		},
+	})
+	return server.Serve(os.Stdin, os.Stdout)
+}
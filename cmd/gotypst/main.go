@@ -4,46 +4,73 @@
 //
 //	gotypst compile input.typ -o output.pdf
 //	gotypst compile input.typ                   # outputs to input.pdf
+//	gotypst compile - -o - --root .             # stdin to stdout
+//	gotypst query input.typ '<label>' --field value
+//	gotypst deps input.typ                      # files the document reads
+//	gotypst ast input.typ --format sexpr
+//	gotypst lsp                                 # language server on stdio
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/boergens/gotypst"
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/font"
 	"github.com/boergens/gotypst/kit"
-	"github.com/boergens/gotypst/layout/pages"
+	"github.com/boergens/gotypst/library/foundations"
 	"github.com/boergens/gotypst/pdf"
-	"github.com/boergens/gotypst/realize"
-	"github.com/boergens/gotypst/syntax"
+	"github.com/boergens/gotypst/routines"
+	"github.com/boergens/gotypst/timing"
 )
 
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitUsageError)
 	}
 
 	switch os.Args[1] {
 	case "compile", "c":
-		if err := runCompile(os.Args[2:]); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+		exitOnError(runCompile(os.Args[2:]))
+	case "query":
+		exitOnError(runQuery(os.Args[2:]))
+	case "deps":
+		exitOnError(runDeps(os.Args[2:]))
+	case "ast":
+		exitOnError(runAST(os.Args[2:]))
+	case "lsp":
+		exitOnError(runLSP(os.Args[2:]))
 	case "help", "-h", "--help":
 		printUsage()
 	case "version", "-v", "--version":
 		printVersion()
 	default:
 		// Assume single argument is input file for compile
-		if err := runCompile(os.Args[1:]); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+		exitOnError(runCompile(os.Args[1:]))
+	}
+}
+
+// errDiagnosed is returned when compilation failed and the diagnostics
+// have already been printed.
+var errDiagnosed = errors.New("compilation failed")
+
+// exitOnError prints the error, unless it was already reported as
+// diagnostics, and exits with the exit code for it.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, errDiagnosed) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 	}
+	os.Exit(exitCode(err))
 }
 
 func printUsage() {
@@ -51,19 +78,83 @@ func printUsage() {
 
 Usage:
   gotypst compile <input.typ> [-o <output.pdf>]
+  gotypst compile - -o - --root <dir>
   gotypst <input.typ> [-o <output.pdf>]
+  gotypst query <input.typ> <selector> [--field <field>] [--one] [--format json|yaml] [--pretty]
+  gotypst deps <input.typ> [--pretty]
+  gotypst ast <input.typ> [--format json|sexpr]
+  gotypst lsp [--font-path <dir>] [--ignore-system-fonts] [--ignore-embedded-fonts]
   gotypst help
   gotypst version
 
 Commands:
   compile, c    Compile a Typst document to PDF
+  query         Print the elements matching a selector, like heading or <label>
+  deps          Print the files a document depends on as JSON, for build systems
+  ast           Print the syntax tree of a file, for tooling
+  lsp           Run a language server on stdin and stdout for editors
   help          Show this help message
   version       Show version information
 
 Options:
-  -o, --output  Output file path (default: input file with .pdf extension)
-  --root        Project root directory (default: input file directory)
-  --font-path   Additional font directories (can be specified multiple times)`)
+  -o, --output  Output file path (default: input file with .pdf extension),
+                or - to write the PDF to stdout
+  --root        Project root directory (default: input file directory).
+                Required if the input is -, which reads the source from stdin
+  --font-path   Additional font directories (can be specified multiple times),
+                whose fonts take precedence over system and embedded fonts
+  --ignore-system-fonts
+                Do not use the fonts installed on the system
+  --ignore-embedded-fonts
+                Do not use the fonts embedded into the binary (built with
+                the embed_fonts tag)
+  --input       Add a string key-value pair visible through sys.inputs, as
+                key=value (can be specified multiple times)
+  --diagnostic-format
+                The format to emit diagnostics in: human, short, or json
+                (default: human). json prints one object per line with the
+                severity, message, file, range, hints, and trace
+  --color       Whether to use colors in diagnostics: auto, always, or never (default: auto)
+  --warnings    How to treat warnings: warn or error (default: warn)
+  --fail-on-warning
+                Write the output, but exit with a failure status if there
+                were warnings, to gate CI pipelines on them
+  -j, --jobs    Number of parallel jobs for layout and PDF export (default: 1)
+  --pdf-password
+                Encrypt the PDF and require this password to open it
+  --pdf-owner-password
+                Encrypt the PDF with this password for lifting the permissions
+  --pdf-deny    Encrypt the PDF and deny a comma-separated list of operations:
+                print, modify, copy, or annotate
+  --pdf-object-streams
+                Pack PDF objects into compressed object streams (PDF 1.5+)
+  --pdf-uncompressed
+                Write PDF streams uncompressed, for debugging
+  --timings     Print how long parsing, evaluation, realization, the layout
+                of each page run and page, and export took. With
+                --timings=<path>, also write a trace in the Chrome trace
+                event format there, for Perfetto or speedscope
+
+Query options:
+  --field       Extract just one field from all retrieved elements
+  --one         Expect and retrieve exactly one element
+  --format      The format to serialize in: json or yaml (default: json)
+  --pretty      Whether to pretty-print the serialized output
+
+Deps options:
+  --pretty      Whether to pretty-print the output. The output is an object
+                with the main file, the sources it imports and includes,
+                and the other files it reads, all as absolute paths
+
+AST options:
+  --format      The format to print the tree in: json or sexpr (default: json)
+
+Exit codes:
+  0             Success
+  1             The document failed to compile, or had warnings with
+                --fail-on-warning
+  2             The command line is invalid
+  3             The output could not be written`)
 }
 
 func printVersion() {
@@ -75,273 +166,301 @@ func runCompile(args []string) error {
 	output := fs.String("o", "", "Output file path")
 	outputLong := fs.String("output", "", "Output file path (long form)")
 	root := fs.String("root", "", "Project root directory")
-	var fontPaths []string
-	fs.Func("font-path", "Additional font directory", func(s string) error {
-		fontPaths = append(fontPaths, s)
-		return nil
-	})
+	diagFormat := diagnosticFormatFlag(fs)
+	color := fs.String("color", "auto", "Whether to use colors in diagnostics")
+	warnings := fs.String("warnings", "warn", "How to treat warnings: warn or error")
+	failOnWarning := fs.Bool("fail-on-warning", false, "Exit with a failure status if there were warnings")
+	jobs := fs.Int("jobs", 1, "Number of parallel jobs for layout and PDF export")
+	fs.IntVar(jobs, "j", 1, "Number of parallel jobs (short form)")
+	fonts := fontsFlag(fs)
+	inputs := inputsFlag(fs)
+	pdfPassword := fs.String("pdf-password", "", "Password required to open the PDF")
+	pdfOwnerPassword := fs.String("pdf-owner-password", "", "Password for lifting the PDF permissions")
+	pdfDeny := fs.String("pdf-deny", "", "Comma-separated operations to deny in the PDF")
+	pdfObjectStreams := fs.Bool("pdf-object-streams", false, "Pack PDF objects into object streams")
+	pdfUncompressed := fs.Bool("pdf-uncompressed", false, "Write PDF streams uncompressed")
+	timings := timingsFlag(fs)
 
 	if err := fs.Parse(args); err != nil {
-		return err
+		return asUsageError(err)
 	}
 
 	if fs.NArg() < 1 {
-		return fmt.Errorf("missing input file")
+		return usageErrorf("missing input file")
 	}
 
 	input := fs.Arg(0)
 
+	format, err := parseDiagnosticFormat(*diagFormat)
+	if err != nil {
+		return asUsageError(err)
+	}
+	colored, err := useColor(*color)
+	if err != nil {
+		return asUsageError(err)
+	}
+	mode, err := parseWarningMode(*warnings, *failOnWarning)
+	if err != nil {
+		return asUsageError(err)
+	}
+	printer := &diagnosticPrinter{out: os.Stderr, format: format, color: colored}
+
 	// Determine output path
 	outPath := *output
 	if outPath == "" {
 		outPath = *outputLong
 	}
+	if outPath == "" && input == stdio {
+		return usageErrorf("--output is required when reading the input from stdin")
+	}
 	if outPath == "" {
 		// Default to input file with .pdf extension
 		ext := filepath.Ext(input)
 		outPath = strings.TrimSuffix(input, ext) + ".pdf"
 	}
 
-	// Determine project root
-	projectRoot := *root
-	if projectRoot == "" {
-		projectRoot = filepath.Dir(input)
-	}
-
-	return compile(input, outPath, projectRoot, fontPaths)
-}
-
-// compile performs the full compilation pipeline:
-// Parse -> Evaluate -> Layout -> Render
-func compile(inputPath, outputPath, projectRoot string, fontPaths []string) error {
-	// Get absolute paths
-	absInput, err := filepath.Abs(inputPath)
+	projectRoot, err := projectRootFor(input, *root)
 	if err != nil {
-		return fmt.Errorf("cannot resolve input path: %w", err)
-	}
-
-	absRoot, err := filepath.Abs(projectRoot)
-	if err != nil {
-		return fmt.Errorf("cannot resolve project root: %w", err)
+		return err
 	}
 
-	// Create the FileWorld
-	opts := []kit.FileWorldOption{}
-	if len(fontPaths) > 0 {
-		opts = append(opts, kit.WithFontDirs(fontPaths...))
+	if *jobs < 1 {
+		return usageErrorf("invalid number of jobs: %d (expected at least 1)", *jobs)
 	}
 
-	// Get relative path from root
-	mainPath, err := filepath.Rel(absRoot, absInput)
+	encryption, err := pdfEncryption(*pdfPassword, *pdfOwnerPassword, *pdfDeny)
 	if err != nil {
-		mainPath = absInput
+		return asUsageError(err)
+	}
+	exportOpts := pdf.ExportOptions{
+		Streaming:     true,
+		Jobs:          *jobs,
+		Encryption:    encryption,
+		Uncompressed:  *pdfUncompressed,
+		ObjectStreams: *pdfObjectStreams,
 	}
 
-	world, err := kit.NewFileWorld(absRoot, mainPath, opts...)
-	if err != nil {
-		return fmt.Errorf("cannot create world: %w", err)
+	timer := timings.timer()
+	err = compile(input, outPath, projectRoot, *fonts, inputs, printer, mode, exportOpts, timer)
+	if reportErr := reportTimings(timer, timings.path); err == nil {
+		err = reportErr
 	}
+	return err
+}
 
-	// Set up standard library
-	stdlib := buildStandardLibrary()
-	world = mustRebuildWorldWithLibrary(world, stdlib)
+// warningMode decides how warnings affect the outcome of a compilation.
+type warningMode int
+
+const (
+	// warningsAllow reports warnings without failing the compilation.
+	warningsAllow warningMode = iota
+	// warningsFail reports warnings and writes the output, but fails the
+	// compilation if there were any.
+	warningsFail
+	// warningsDeny reports warnings as errors, so that no output is
+	// written if there were any.
+	warningsDeny
+)
 
-	// Get and parse the main source
-	source, err := world.Source(world.MainFile())
-	if err != nil {
-		return fmt.Errorf("cannot read source: %w", err)
+// parseWarningMode combines the values of the --warnings and
+// --fail-on-warning flags. Denying warnings takes precedence over failing
+// on them.
+func parseWarningMode(warnings string, failOnWarning bool) (warningMode, error) {
+	switch warnings {
+	case "warn":
+		if failOnWarning {
+			return warningsFail, nil
+		}
+		return warningsAllow, nil
+	case "error":
+		return warningsDeny, nil
 	}
+	return 0, fmt.Errorf("invalid warnings mode: %s (expected warn or error)", warnings)
+}
 
-	// Check for parse errors
-	if errs := source.Root().Errors(); len(errs) > 0 {
-		return formatParseErrors(source, errs)
+// pdfEncryption builds the encryption of the PDF from the values of the
+// --pdf-password, --pdf-owner-password, and --pdf-deny flags. It returns
+// nil if none of them is given.
+func pdfEncryption(password, ownerPassword, deny string) (*pdf.Encryption, error) {
+	if password == "" && ownerPassword == "" && deny == "" {
+		return nil, nil
+	}
+	encryption := &pdf.Encryption{UserPassword: password, OwnerPassword: ownerPassword}
+	if deny == "" {
+		return encryption, nil
+	}
+	for _, op := range strings.Split(deny, ",") {
+		switch strings.TrimSpace(op) {
+		case "print":
+			encryption.Permissions.NoPrint = true
+		case "modify":
+			encryption.Permissions.NoModify = true
+		case "copy":
+			encryption.Permissions.NoCopy = true
+		case "annotate":
+			encryption.Permissions.NoAnnotate = true
+		default:
+			return nil, fmt.Errorf("invalid PDF permission: %s (expected print, modify, copy, or annotate)", op)
+		}
 	}
+	return encryption, nil
+}
 
-	// Evaluate the source
-	content, err := evaluate(world, source)
+// compile performs the full compilation pipeline:
+// Parse -> Evaluate -> Layout -> Render
+//
+// Errors and warnings in the document are printed as diagnostics. Whether
+// warnings fail the compilation, and whether the PDF is still written
+// then, depends on the warning mode. The PDF is exported with exportOpts;
+// up to its number of jobs page runs are laid out and page content streams
+// are encoded in parallel. The stages are timed with the timer, which may
+// be nil.
+func compile(inputPath, outputPath, projectRoot string, fonts font.SearchOptions, inputs map[string]string, printer *diagnosticPrinter, mode warningMode, exportOpts pdf.ExportOptions, timer *timing.Timer) error {
+	world, err := newWorld(inputPath, projectRoot, fonts, inputs)
 	if err != nil {
-		return fmt.Errorf("evaluation failed: %w", err)
+		return err
 	}
+	printer.source = world.Source
 
-	// Layout the document
-	doc, err := layout(world, content)
-	if err != nil {
-		return fmt.Errorf("layout failed: %w", err)
+	doc, diags := gotypst.Compile(world, gotypst.CompileOptions{Routines: routines.Standard, Jobs: exportOpts.Jobs, Timer: timer})
+	warnings := diags.Warnings()
+	if mode == warningsDeny {
+		for i := range diags {
+			diags[i].Severity = foundations.SeverityError
+		}
+	}
+	printer.print(diags)
+	if doc == nil || (mode == warningsDeny && len(warnings) > 0) {
+		return errDiagnosed
 	}
 
 	// Render to PDF
-	outFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("cannot create output file: %w", err)
-	}
-	defer outFile.Close()
+	endExport := timer.Start("export", "")
+	defer endExport()
+	if outputPath == stdio {
+		if err := pdf.ExportWithOptions(doc, os.Stdout, exportOpts); err != nil {
+			return exportErrorf("PDF export failed: %w", err)
+		}
+	} else {
+		outFile, err := os.Create(outputPath)
+		if err != nil {
+			return exportErrorf("cannot create output file: %w", err)
+		}
+		defer outFile.Close()
 
-	if err := pdf.Export(doc, outFile); err != nil {
-		return fmt.Errorf("PDF export failed: %w", err)
+		if err := pdf.ExportWithOptions(doc, outFile, exportOpts); err != nil {
+			return exportErrorf("PDF export failed: %w", err)
+		}
+		fmt.Printf("Compiled %s -> %s\n", inputPath, outputPath)
+	}
+	if mode == warningsFail && len(warnings) > 0 {
+		return errDiagnosed
 	}
-
-	fmt.Printf("Compiled %s -> %s\n", inputPath, outputPath)
 	return nil
 }
 
-// buildStandardLibrary constructs the standard library scope.
-func buildStandardLibrary() *eval.Scope {
-	return eval.Library()
-}
+// stdio is the path that stands for stdin as the input and for stdout as
+// the output.
+const stdio = "-"
 
-// mustRebuildWorldWithLibrary creates a new FileWorld with the given library.
-// This is a workaround since FileWorld doesn't allow changing library after creation.
-func mustRebuildWorldWithLibrary(old *kit.FileWorld, lib *eval.Scope) *kit.FileWorld {
-	// Get the main file's virtual path
-	mainFile := old.MainFile()
-	rpath := mainFile.RootedPath()
-	mainPath := "."
-	if rpath != nil {
-		mainPath = rpath.VPath().AsRootedPath()
+// projectRootFor returns the project root for an input file: the given
+// root, or else the input file's directory. An input read from stdin has
+// no directory, so the root must be given for it.
+func projectRootFor(input, root string) (string, error) {
+	if root != "" {
+		return root, nil
 	}
-
-	root := old.Root()
-	fontBook := old.FontBook()
-
-	world, err := kit.NewFileWorld(root, mainPath,
-		kit.WithLibrary(lib),
-		kit.WithFontBook(fontBook),
-	)
-	if err != nil {
-		// Should not happen if old world was valid
-		panic(fmt.Sprintf("failed to rebuild world: %v", err))
+	if input == stdio {
+		return "", usageErrorf("--root is required when reading the input from stdin")
 	}
-	return world
+	return filepath.Dir(input), nil
 }
 
-// evaluate evaluates the source and returns content.
-func evaluate(world *kit.FileWorld, source *syntax.Source) (*eval.Content, error) {
-	// Create the evaluation engine
-	engine := eval.NewEngine(world)
-
-	// Create VM for evaluation
-	scopes := eval.NewScopes(world.Library())
-	ctx := eval.NewContext()
-	vm := eval.NewVm(engine, ctx, scopes, source.Root().Span())
-
-	// Get the markup node
-	markup := syntax.MarkupNodeFromNode(source.Root())
-	if markup == nil {
-		return nil, fmt.Errorf("source root is not markup")
-	}
-
-	// Evaluate the markup to get content
-	value, err := evalMarkup(vm, markup)
+// newWorld creates the world for compiling the input file, with the
+// standard library set up and the fonts found with the given options. The
+// metadata of the fonts is cached in the user's cache directory. The
+// inputs are available to the document as sys.inputs. If the input path
+// is "-", the main source is read from stdin.
+func newWorld(inputPath, projectRoot string, fonts font.SearchOptions, inputs map[string]string) (*kit.FileWorld, error) {
+	absRoot, err := filepath.Abs(projectRoot)
 	if err != nil {
-		return nil, err
-	}
-
-	// Extract content from the value
-	if cv, ok := value.(eval.ContentValue); ok {
-		return &cv.Content, nil
+		return nil, fmt.Errorf("cannot resolve project root: %w", err)
 	}
 
-	return &eval.Content{}, nil
-}
+	// Create the FileWorld with the standard library
+	library := eval.NewLibraryBuilder().WithInputs(inputs).Build()
+	opts := []kit.WorldOption{kit.WithLibrary(library), kit.WithCachedFontSearch(fonts, kit.DefaultFontCachePath())}
 
-// evalMarkup evaluates markup and returns content.
-// This wraps the internal eval function.
-func evalMarkup(vm *eval.Vm, markup *syntax.MarkupNode) (eval.Value, error) {
-	// Evaluate all expressions in the markup
-	var elements []eval.ContentElement
-
-	for _, expr := range markup.Exprs() {
-		val, err := eval.EvalExpr(vm, expr)
+	if inputPath == stdio {
+		text, err := io.ReadAll(os.Stdin)
 		if err != nil {
-			return nil, err
-		}
-		if vm.HasFlow() {
-			break
+			return nil, fmt.Errorf("cannot read input from stdin: %w", err)
 		}
-
-		// Convert value to content elements
-		if cv, ok := val.(eval.ContentValue); ok {
-			elements = append(elements, cv.Content.Elements...)
+		world, err := kit.NewStdinWorld(absRoot, text, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create world: %w", err)
 		}
+		return world, nil
 	}
 
-	return eval.ContentValue{Content: eval.Content{Elements: elements}}, nil
-}
-
-// layout converts evaluated content to a paged document.
-// This is the main entry point that wires up realization and page collection.
-func layout(world *kit.FileWorld, content *eval.Content) (*pages.PagedDocument, error) {
-	// Create evaluation engine for realization
-	evalEngine := eval.NewEngine(world)
-
-	// Create empty styles for initial realization
-	realizeStyles := eval.EmptyStyleChain()
-
-	// Wrap content in a sequence element for realization
-	var rootElem eval.ContentElement
-	if content != nil && len(content.Elements) > 0 {
-		rootElem = &eval.SequenceElem{Children: content.Elements}
-	}
-
-	// Realize the content - apply show rules, group elements, collapse spaces
-	realizedPairs, err := realize.Realize(
-		realize.LayoutDocument{},
-		evalEngine,
-		rootElem,
-		realizeStyles,
-	)
+	absInput, err := filepath.Abs(inputPath)
 	if err != nil {
-		return nil, fmt.Errorf("realization failed: %w", err)
+		return nil, fmt.Errorf("cannot resolve input path: %w", err)
 	}
 
-	// Convert realized pairs to pages.Content
-	pageContent := convertRealizedContent(realizedPairs)
-
-	// Create layout engine
-	layoutEngine := &pages.Engine{
-		World: world,
+	// Get relative path from root
+	mainPath, err := filepath.Rel(absRoot, absInput)
+	if err != nil {
+		mainPath = absInput
 	}
 
-	// Create default style chain for layout
-	layoutStyles := pages.StyleChain{
-		Styles: map[string]interface{}{},
+	world, err := kit.NewFileWorld(absRoot, mainPath, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create world: %w", err)
 	}
-
-	// Layout the document
-	return pages.LayoutDocument(layoutEngine, pageContent, layoutStyles)
+	return world, nil
 }
 
-// convertRealizedContent converts realized pairs to pages.Content.
-// This bridges the realize package output to the pages package input.
-func convertRealizedContent(pairs []realize.Pair) *pages.Content {
-	if len(pairs) == 0 {
-		return &pages.Content{
-			Elements: make([]eval.ContentElement, 0),
-		}
-	}
+// fontsFlag registers the --font-path flag, which may be given multiple
+// times, and the --ignore-system-fonts and --ignore-embedded-fonts flags,
+// and returns the font search options they are collected into.
+func fontsFlag(fs *flag.FlagSet) *font.SearchOptions {
+	opts := &font.SearchOptions{}
+	fs.Func("font-path", "Additional font directory", func(s string) error {
+		opts.FontPaths = append(opts.FontPaths, s)
+		return nil
+	})
+	fs.BoolVar(&opts.IgnoreSystemFonts, "ignore-system-fonts", false, "Ignore the fonts installed on the system")
+	fs.BoolVar(&opts.IgnoreEmbeddedFonts, "ignore-embedded-fonts", false, "Ignore the fonts embedded into the binary")
+	return opts
+}
 
-	elements := make([]eval.ContentElement, 0, len(pairs))
-	for _, pair := range pairs {
-		if pair.Content != nil {
-			elements = append(elements, pair.Content)
+// inputsFlag registers the --input flag, which may be given multiple times,
+// and returns the map the inputs are collected into.
+func inputsFlag(fs *flag.FlagSet) map[string]string {
+	inputs := map[string]string{}
+	fs.Func("input", "Add a string key-value pair visible through sys.inputs", func(s string) error {
+		key, value, err := parseInput(s)
+		if err != nil {
+			return err
 		}
-	}
-
-	return &pages.Content{
-		Elements: elements,
-	}
+		inputs[key] = value
+		return nil
+	})
+	return inputs
 }
 
-// formatParseErrors formats parse errors for display.
-func formatParseErrors(source *syntax.Source, errs []*syntax.SyntaxError) error {
-	if len(errs) == 0 {
-		return nil
+// parseInput parses a key-value pair of the form key=value.
+//
+// Matches Rust: fn parse_sys_input_pair in typst-cli/src/args.rs
+func parseInput(raw string) (string, string, error) {
+	key, value, ok := strings.Cut(raw, "=")
+	if !ok {
+		return "", "", errors.New("input must be a key and a value separated by an equal sign")
 	}
-
-	var msgs []string
-	for _, err := range errs {
-		msgs = append(msgs, err.Message)
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", "", errors.New("input key must not be empty")
 	}
-
-	return fmt.Errorf("parse errors:\n  %s", strings.Join(msgs, "\n  "))
+	return key, value, nil
 }
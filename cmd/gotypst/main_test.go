@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		raw        string
+		key, value string
+		err        string
+	}{
+		{raw: "name=value", key: "name", value: "value"},
+		{raw: " name =a=b", key: "name", value: "a=b"},
+		{raw: "empty=", key: "empty", value: ""},
+		{raw: "novalue", err: "input must be a key and a value separated by an equal sign"},
+		{raw: "=value", err: "input key must not be empty"},
+	}
+	for _, tt := range tests {
+		key, value, err := parseInput(tt.raw)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseInput(%q) error = %v, want %q", tt.raw, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || key != tt.key || value != tt.value {
+			t.Errorf("parseInput(%q) = %q, %q, %v; want %q, %q", tt.raw, key, value, err, tt.key, tt.value)
+		}
+	}
+}
+
+func TestPDFEncryption(t *testing.T) {
+	encryption, err := pdfEncryption("", "", "")
+	if err != nil || encryption != nil {
+		t.Errorf("pdfEncryption without flags = %v, %v; want nil", encryption, err)
+	}
+
+	encryption, err = pdfEncryption("secret", "", "print, copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	perms := encryption.Permissions
+	if encryption.UserPassword != "secret" || !perms.NoPrint || !perms.NoCopy || perms.NoModify || perms.NoAnnotate {
+		t.Errorf("pdfEncryption = %+v", encryption)
+	}
+
+	if _, err := pdfEncryption("", "", "print,share"); err == nil {
+		t.Error("expected an error for an unknown permission")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitSuccess},
+		{errDiagnosed, exitCompileError},
+		{errors.New("cannot create world"), exitCompileError},
+		{usageErrorf("missing input file"), exitUsageError},
+		{asUsageError(errors.New("invalid color mode")), exitUsageError},
+		{exportErrorf("PDF export failed: %w", errors.New("disk full")), exitExportError},
+		{fmt.Errorf("compile: %w", exportErrorf("cannot create output file")), exitExportError},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+	if asUsageError(nil) != nil {
+		t.Error("asUsageError(nil) is not nil")
+	}
+}
+
+func TestParseWarningMode(t *testing.T) {
+	tests := []struct {
+		warnings string
+		fail     bool
+		want     warningMode
+	}{
+		{"warn", false, warningsAllow},
+		{"warn", true, warningsFail},
+		{"error", false, warningsDeny},
+		{"error", true, warningsDeny},
+	}
+	for _, tt := range tests {
+		got, err := parseWarningMode(tt.warnings, tt.fail)
+		if err != nil || got != tt.want {
+			t.Errorf("parseWarningMode(%q, %v) = %v, %v; want %v", tt.warnings, tt.fail, got, err, tt.want)
+		}
+	}
+	if _, err := parseWarningMode("ignore", false); err == nil {
+		t.Error("expected an error for an unknown warnings mode")
+	}
+}
+
+func TestProjectRootFor(t *testing.T) {
+	if root, err := projectRootFor("docs/main.typ", ""); err != nil || root != "docs" {
+		t.Errorf("projectRootFor(file) = %q, %v; want docs", root, err)
+	}
+	if root, err := projectRootFor(stdio, "project"); err != nil || root != "project" {
+		t.Errorf("projectRootFor(stdin, project) = %q, %v; want project", root, err)
+	}
+	if _, err := projectRootFor(stdio, ""); exitCode(err) != exitUsageError {
+		t.Errorf("projectRootFor(stdin) without a root = %v, want a usage error", err)
+	}
+}
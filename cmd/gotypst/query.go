@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/boergens/gotypst"
+	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/kit"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/routines"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// runQuery processes an input file and prints the elements matching a
+// selector, or one of their fields, as JSON or YAML.
+//
+// Matches Rust: pub fn query in typst-cli/src/query.rs
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	field := fs.String("field", "", "Extract just one field from all retrieved elements")
+	one := fs.Bool("one", false, "Expect and retrieve exactly one element")
+	formatName := fs.String("format", "json", "The format to serialize in: json or yaml")
+	pretty := fs.Bool("pretty", false, "Whether to pretty-print the serialized output")
+	root := fs.String("root", "", "Project root directory")
+	diagFormat := diagnosticFormatFlag(fs)
+	color := fs.String("color", "auto", "Whether to use colors in diagnostics")
+	fonts := fontsFlag(fs)
+	inputs := inputsFlag(fs)
+
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return asUsageError(err)
+	}
+	if len(positional) < 1 {
+		return usageErrorf("missing input file")
+	}
+	if len(positional) < 2 {
+		return usageErrorf("missing selector")
+	}
+	input, selector := positional[0], positional[1]
+
+	format, err := parseSerializationFormat(*formatName)
+	if err != nil {
+		return asUsageError(err)
+	}
+	dformat, err := parseDiagnosticFormat(*diagFormat)
+	if err != nil {
+		return asUsageError(err)
+	}
+	colored, err := useColor(*color)
+	if err != nil {
+		return asUsageError(err)
+	}
+	printer := &diagnosticPrinter{out: os.Stderr, format: dformat, color: colored}
+
+	projectRoot, err := projectRootFor(input, *root)
+	if err != nil {
+		return err
+	}
+	world, err := newWorld(input, projectRoot, *fonts, inputs)
+	if err != nil {
+		return err
+	}
+	printer.source = world.Source
+
+	elements, diags, err := retrieve(world, selector)
+	printer.print(diags)
+	if err != nil {
+		return err
+	}
+	if diags.HasErrors() {
+		return errDiagnosed
+	}
+
+	if *one && len(elements) != 1 {
+		return fmt.Errorf("expected exactly one element, found %d", len(elements))
+	}
+
+	mapped := make([]foundations.Value, 0, len(elements))
+	for _, elem := range elements {
+		if *field == "" {
+			mapped = append(mapped, foundations.ContentValue{Content: foundations.Content{
+				Elements: []foundations.ContentElement{elem},
+			}})
+			continue
+		}
+		if value, ok := foundations.ElementFields(elem).Get(*field); ok {
+			mapped = append(mapped, value)
+		}
+	}
+
+	var value foundations.Value = foundations.NewArray(mapped...)
+	if *one {
+		if len(mapped) == 0 {
+			return fmt.Errorf("no such field found for element")
+		}
+		value = mapped[0]
+	}
+
+	serialized, err := serialize(value, format, *pretty)
+	if err != nil {
+		return err
+	}
+	fmt.Println(serialized)
+	return nil
+}
+
+// queryError is an error in the query itself rather than in the document,
+// like a selector that fails to evaluate. It is reported without source
+// excerpts.
+type queryError struct {
+	message string
+}
+
+func (e *queryError) Error() string {
+	return e.message
+}
+
+// retrieve evaluates the selector, which is given as Typst code, and
+// returns the elements of the document matching it. An error is returned
+// for a faulty selector, while errors in the document are among the
+// diagnostics.
+//
+// Matches Rust: fn retrieve in typst-cli/src/query.rs
+func retrieve(world *kit.FileWorld, selector string) ([]foundations.ContentElement, gotypst.Diagnostics, error) {
+	engine := eval.NewEngine(world)
+	value, err := eval.EvalString(engine, selector, syntax.Detached(), syntax.ModeCode, nil)
+	if err != nil {
+		var b strings.Builder
+		b.WriteString("failed to evaluate selector")
+		for i, diag := range foundations.ErrorDiagnostics(err) {
+			if i == 0 {
+				b.WriteString(": ")
+			} else {
+				b.WriteString(", ")
+			}
+			b.WriteString(diag.Message)
+		}
+		return nil, engine.Sink.Warnings, &queryError{message: b.String()}
+	}
+	located, err := foundations.CastLocatableSelector(value)
+	if err != nil {
+		return nil, engine.Sink.Warnings, &queryError{message: err.Error()}
+	}
+
+	elements, diags := gotypst.Query(world, located.Selector, gotypst.CompileOptions{Routines: routines.Standard})
+	return elements, append(diags, engine.Sink.Warnings...), nil
+}
+
+// parseInterleaved parses flags that may appear before, between, and after
+// positional arguments, and returns the positional arguments. Everything
+// after a `--` terminator is positional.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
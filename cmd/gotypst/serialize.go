@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// serializationFormat selects how query results are serialized.
+type serializationFormat int
+
+const (
+	formatJSON serializationFormat = iota
+	formatYAML
+)
+
+// parseSerializationFormat parses the value of the --format flag.
+func parseSerializationFormat(s string) (serializationFormat, error) {
+	switch s {
+	case "json":
+		return formatJSON, nil
+	case "yaml":
+		return formatYAML, nil
+	}
+	return 0, fmt.Errorf("invalid format: %s (expected json or yaml)", s)
+}
+
+// serialize converts a value to JSON or YAML. JSON is only indented if
+// pretty is set.
+//
+// Matches Rust: fn serialize in typst-cli/src/query.rs
+func serialize(v foundations.Value, format serializationFormat, pretty bool) (string, error) {
+	data := serialData(v)
+	var b strings.Builder
+	if format == formatYAML {
+		writeYAML(&b, data, 0)
+		return b.String(), nil
+	}
+	indent := ""
+	if pretty {
+		indent = "  "
+	}
+	if err := writeJSON(&b, data, indent, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// serialMap is a map that keeps its keys in insertion order.
+type serialMap struct {
+	keys   []string
+	values []any
+}
+
+func (m *serialMap) set(key string, value any) {
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+// serialData converts a value into plain data: nil, booleans, integers,
+// floats, strings, slices, and ordered maps. Content becomes a map of its
+// fields headed by the name of its function. Values without a natural
+// representation are serialized as their repr.
+//
+// Matches Rust: impl Serialize for Value
+func serialData(v foundations.Value) any {
+	switch v := v.(type) {
+	case nil, foundations.NoneValue:
+		return nil
+	case foundations.Bool:
+		return bool(v)
+	case foundations.Int:
+		return int64(v)
+	case foundations.Float:
+		return float64(v)
+	case foundations.Str:
+		return string(v)
+	case foundations.LabelValue:
+		return string(v)
+	case foundations.SymbolValue:
+		return v.Get()
+	case *foundations.Array:
+		items := make([]any, 0, v.Len())
+		for _, item := range v.Items() {
+			items = append(items, serialData(item))
+		}
+		return items
+	case *foundations.Dict:
+		m := &serialMap{}
+		for _, key := range v.Keys() {
+			value, _ := v.Get(key)
+			m.set(key, serialData(value))
+		}
+		return m
+	case foundations.ContentValue:
+		if len(v.Content.Elements) == 1 {
+			return serialElement(v.Content.Elements[0])
+		}
+		return serialElement(&foundations.SequenceElem{Children: splitContent(v.Content)})
+	}
+	return foundations.Repr(v)
+}
+
+// serialElement converts an element into a map of its fields.
+func serialElement(elem foundations.ContentElement) *serialMap {
+	m := &serialMap{}
+	m.set("func", foundations.ElementName(elem))
+	fields := foundations.ElementFields(elem)
+	for _, key := range fields.Keys() {
+		value, _ := fields.Get(key)
+		m.set(key, serialData(value))
+	}
+	return m
+}
+
+// splitContent returns the elements of content as separate pieces of
+// content, like the children of a sequence.
+func splitContent(content foundations.Content) []foundations.Content {
+	children := make([]foundations.Content, len(content.Elements))
+	for i, elem := range content.Elements {
+		children[i] = foundations.Content{Elements: []foundations.ContentElement{elem}}
+	}
+	return children
+}
+
+// writeJSON writes data as JSON. With a non-empty indent, nested values
+// are put on separate lines.
+func writeJSON(b *strings.Builder, data any, indent string, depth int) error {
+	newline := func(depth int) {
+		if indent != "" {
+			b.WriteByte('\n')
+			b.WriteString(strings.Repeat(indent, depth))
+		}
+	}
+	switch d := data.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(d))
+	case int64:
+		b.WriteString(strconv.FormatInt(d, 10))
+	case float64:
+		if math.IsNaN(d) || math.IsInf(d, 0) {
+			// Like serde_json, represent non-finite floats as null.
+			b.WriteString("null")
+			return nil
+		}
+		s := strconv.FormatFloat(d, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		b.WriteString(s)
+	case string:
+		encoded, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		b.Write(encoded)
+	case []any:
+		if len(d) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteByte('[')
+		for i, item := range d {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			newline(depth + 1)
+			if err := writeJSON(b, item, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		b.WriteByte(']')
+	case *serialMap:
+		if len(d.keys) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteByte('{')
+		for i, key := range d.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			newline(depth + 1)
+			if err := writeJSON(b, key, indent, depth+1); err != nil {
+				return err
+			}
+			b.WriteByte(':')
+			if indent != "" {
+				b.WriteByte(' ')
+			}
+			if err := writeJSON(b, d.values[i], indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot serialize %T", data)
+	}
+	return nil
+}
+
+// writeYAML writes data as a YAML document in block style.
+func writeYAML(b *strings.Builder, data any, depth int) {
+	pad := strings.Repeat("  ", depth)
+	switch d := data.(type) {
+	case []any:
+		if len(d) == 0 {
+			b.WriteString("[]\n")
+			return
+		}
+		for _, item := range d {
+			b.WriteString(pad + "-")
+			writeYAMLItem(b, item, depth+1)
+		}
+	case *serialMap:
+		if len(d.keys) == 0 {
+			b.WriteString("{}\n")
+			return
+		}
+		for i, key := range d.keys {
+			b.WriteString(pad + yamlScalar(key) + ":")
+			writeYAMLItem(b, d.values[i], depth+1)
+		}
+	default:
+		b.WriteString(yamlScalar(d) + "\n")
+	}
+}
+
+// writeYAMLItem writes the value of a sequence item or map entry after its
+// marker. Non-empty collections start on the next line, indented.
+func writeYAMLItem(b *strings.Builder, data any, depth int) {
+	switch d := data.(type) {
+	case []any:
+		if len(d) > 0 {
+			b.WriteByte('\n')
+			writeYAML(b, d, depth)
+			return
+		}
+	case *serialMap:
+		if len(d.keys) > 0 {
+			b.WriteByte('\n')
+			writeYAML(b, d, depth)
+			return
+		}
+	}
+	b.WriteByte(' ')
+	writeYAML(b, data, 0)
+}
+
+// yamlScalar formats a scalar for YAML. Strings are quoted if they could be
+// read as something else.
+func yamlScalar(data any) string {
+	switch d := data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(d)
+	case int64:
+		return strconv.FormatInt(d, 10)
+	case float64:
+		switch {
+		case math.IsNaN(d):
+			return ".nan"
+		case math.IsInf(d, 1):
+			return ".inf"
+		case math.IsInf(d, -1):
+			return "-.inf"
+		}
+		s := strconv.FormatFloat(d, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case string:
+		if yamlNeedsQuotes(d) {
+			encoded, _ := json.Marshal(d)
+			return string(encoded)
+		}
+		return d
+	}
+	return fmt.Sprint(data)
+}
+
+// yamlNeedsQuotes reports whether a string must be quoted to be read back
+// as the same string.
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", ".nan", ".inf", "-.inf":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #")
+}
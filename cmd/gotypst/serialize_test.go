@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+func TestSerialize(t *testing.T) {
+	dict := foundations.NewDict()
+	dict.Set("name", foundations.Str("intro"))
+	dict.Set("level", foundations.Int(2))
+	dict.Set("tags", foundations.NewArray(foundations.Str("yes"), foundations.Float(1), foundations.NoneValue{}))
+
+	tests := []struct {
+		format serializationFormat
+		pretty bool
+		want   string
+	}{
+		{formatJSON, false, `{"name":"intro","level":2,"tags":["yes",1.0,null]}`},
+		{formatJSON, true, "{\n  \"name\": \"intro\",\n  \"level\": 2,\n  \"tags\": [\n    \"yes\",\n    1.0,\n    null\n  ]\n}"},
+		{formatYAML, false, "name: intro\nlevel: 2\ntags:\n  - \"yes\"\n  - 1.0\n  - null\n"},
+	}
+	for _, tt := range tests {
+		got, err := serialize(dict, tt.format, tt.pretty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("serialize(%v, pretty=%v) =\n%s\nwant\n%s", tt.format, tt.pretty, got, tt.want)
+		}
+	}
+}
+
+func TestSerializeContent(t *testing.T) {
+	content := foundations.ContentValue{Content: foundations.Content{Elements: []foundations.ContentElement{
+		&foundations.SymbolElem{Text: "α"},
+		&foundations.SymbolElem{Text: "β"},
+	}}}
+
+	got, err := serialize(content, formatJSON, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"func":"sequence","children":[{"func":"symbol","text":"α"},{"func":"symbol","text":"β"}]}`
+	if got != want {
+		t.Errorf("serialize(content) = %s, want %s", got, want)
+	}
+}
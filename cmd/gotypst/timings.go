@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/boergens/gotypst/timing"
+)
+
+// timingsValue is the value of the --timings flag. Given without a value,
+// the flag enables the timings; given a path, it also writes a trace of
+// them there.
+type timingsValue struct {
+	enabled bool
+	// path is where the trace is written, or empty for no trace.
+	path string
+}
+
+func (v *timingsValue) String() string {
+	if v == nil || !v.enabled {
+		return ""
+	}
+	return v.path
+}
+
+func (v *timingsValue) Set(s string) error {
+	if enabled, err := strconv.ParseBool(s); err == nil {
+		v.enabled, v.path = enabled, ""
+		return nil
+	}
+	if s == "" {
+		return fmt.Errorf("missing path for the timings trace")
+	}
+	v.enabled, v.path = true, s
+	return nil
+}
+
+// IsBoolFlag lets the flag be given without a value.
+func (v *timingsValue) IsBoolFlag() bool { return true }
+
+// timingsFlag registers the --timings flag and returns its value.
+func timingsFlag(fs *flag.FlagSet) *timingsValue {
+	v := &timingsValue{}
+	fs.Var(v, "timings", "Print how long each stage took; with =<path>, also write a trace there")
+	return v
+}
+
+// timer returns the timer to record the stages with, or nil if the
+// timings are disabled.
+func (v *timingsValue) timer() *timing.Timer {
+	if !v.enabled {
+		return nil
+	}
+	return timing.NewTimer()
+}
+
+// reportTimings prints the summary of the timed stages to stderr and
+// writes the trace if a path was given. Failing to write the trace is an
+// export error.
+func reportTimings(timer *timing.Timer, path string) error {
+	if timer == nil {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "timings:")
+	if err := timer.WriteSummary(os.Stderr); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return exportErrorf("cannot create timings trace: %w", err)
+	}
+	defer file.Close()
+	if err := timer.WriteTrace(file); err != nil {
+		return exportErrorf("cannot write timings trace: %w", err)
+	}
+	return nil
+}
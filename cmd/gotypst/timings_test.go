@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestTimingsFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		enabled bool
+		path    string
+	}{
+		{nil, false, ""},
+		{[]string{"--timings"}, true, ""},
+		{[]string{"--timings=trace.json"}, true, "trace.json"},
+		{[]string{"--timings=false"}, false, ""},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("compile", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		timings := timingsFlag(fs)
+		if err := fs.Parse(append(tt.args, "main.typ")); err != nil {
+			t.Fatalf("Parse(%v): %v", tt.args, err)
+		}
+		if timings.enabled != tt.enabled || timings.path != tt.path || fs.Arg(0) != "main.typ" {
+			t.Errorf("Parse(%v) = %+v, args %v", tt.args, *timings, fs.Args())
+		}
+		if (timings.timer() != nil) != tt.enabled {
+			t.Errorf("Parse(%v) timer = %v", tt.args, timings.timer())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Compilation for Typst.
+// Translated from typst/src/lib.rs
+
+package gotypst
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/introspection"
+	"github.com/boergens/gotypst/timing"
+)
+
+// Compile compiles the main file of the world into a paged document.
+// The document is nil if compilation fails. The diagnostics hold the
+// warnings emitted along the way, and the errors if compilation failed.
+//
+// Pipeline: Parse -> Evaluate -> Realize -> Layout
+//
+// Matches Rust: pub fn compile(world: &dyn World) -> Warned<SourceResult<PagedDocument>>
+func Compile(world World, opts CompileOptions) (*Document, Diagnostics) {
+	// A single engine collects the warnings of all stages.
+	engine := newEngine(world, opts)
+	defer engine.Arena.Release()
+
+	content, err := evaluate(engine, world, opts.Timer)
+	if err != nil {
+		return nil, failure(engine, err)
+	}
+
+	doc, err := engine.Routines.LayoutDocument(engine, content, opts.Jobs, opts.Timer)
+	if err != nil {
+		return nil, failure(engine, err)
+	}
+	return doc, engine.Sink.Warnings
+}
+
+// Query evaluates and realizes the main file of the world and returns the
+// elements matching the selector. Of the options, only the routines and
+// limits apply.
+func Query(world World, selector foundations.Selector, opts CompileOptions) ([]foundations.ContentElement, Diagnostics) {
+	engine := newEngine(world, opts)
+	defer engine.Arena.Release()
+
+	content, err := evaluate(engine, world, nil)
+	if err != nil {
+		return nil, failure(engine, err)
+	}
+	kind := foundations.LayoutDocument{Info: &foundations.DocumentInfo{}}
+	root := &foundations.SequenceElem{Children: []foundations.Content{content}}
+	pairs, err := engine.Routines.Realize(engine, kind, root, foundations.EmptyStyleChain())
+	if err != nil {
+		return nil, failure(engine, err)
+	}
+
+	elements := make([]foundations.ContentElement, 0, len(pairs))
+	for _, pair := range pairs {
+		if pair.Content != nil {
+			elements = append(elements, pair.Content)
+		}
+	}
+	return introspection.NewIntrospector(elements).Query(selector), engine.Sink.Warnings
+}
+
+// newEngine creates an engine for the given world through whose routines
+// evaluation, realization, and layout call each other. Its arena reuses
+// the blocks of earlier compilations and must be released once the
+// compilation is done.
+func newEngine(world World, opts CompileOptions) *foundations.Engine {
+	routines := opts.Routines
+	if routines == nil {
+		routines = foundations.RoutineSet{}
+	}
+	engine := foundations.NewEngine(world, routines)
+	if opts.Limits != nil {
+		engine.Budget = foundations.NewBudget(*opts.Limits)
+	}
+	engine.Arena = foundations.AcquireArena()
+	return engine
+}
+
+// failure returns the diagnostics of a failed compilation: the errors
+// followed by the warnings emitted before the failure.
+func failure(engine *foundations.Engine, err error) Diagnostics {
+	return append(Diagnostics(foundations.ErrorDiagnostics(err)), engine.Sink.Warnings...)
+}
+
+// evaluate parses and evaluates the main file and returns its content.
+// Parsing and evaluation are timed with the timer.
+func evaluate(engine *foundations.Engine, world World, timer *timing.Timer) (foundations.Content, error) {
+	endParse := timer.Start("parse", "")
+	source, err := world.Source(world.MainFile())
+	endParse()
+	if err != nil {
+		return foundations.Content{}, fmt.Errorf("cannot read source: %w", err)
+	}
+	defer timer.Start("eval", "")()
+
+	module, err := engine.Routines.EvalSource(engine, source)
+	if err != nil {
+		return foundations.Content{}, err
+	}
+	return module.Content, nil
+}
@@ -0,0 +1,141 @@
+package gotypst
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/boergens/gotypst/kit"
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/introspection"
+	"github.com/boergens/gotypst/syntax"
+	"github.com/boergens/gotypst/timing"
+)
+
+// pipeline stands in for the compiler's routines. Evaluation turns each
+// source into a single space, realization flattens the sequence it is
+// given and tags its elements, and layout puts out a page per element. Routines it does not
+// override fail.
+type pipeline struct {
+	foundations.RoutineSet
+
+	// source is the text of the last source evaluated.
+	source string
+	// layoutErr makes layout fail.
+	layoutErr error
+}
+
+func (p *pipeline) EvalSource(engine *foundations.Engine, source *syntax.Source) (*foundations.Module, error) {
+	if errs := source.Root().Errors(); len(errs) > 0 {
+		return nil, foundations.SyntaxErrors(errs)
+	}
+	p.source = source.Text()
+	engine.Sink.Warn(foundations.NewSourceWarning(source.Root().Span(), "evaluated"))
+	return &foundations.Module{Content: foundations.SpaceElemShared()}, nil
+}
+
+func (p *pipeline) Realize(engine *foundations.Engine, kind foundations.RealizationKind, content foundations.ContentElement, styles *foundations.StyleChain) ([]foundations.Pair, error) {
+	var pairs []foundations.Pair
+	for _, child := range content.(*foundations.SequenceElem).Children {
+		for _, elem := range child.Elements {
+			tag := introspection.NewStartTag(elem, introspection.Location{Hash: uint64(len(pairs) + 1)}, introspection.TagFlags{Introspectable: true})
+			pairs = append(pairs, foundations.Pair{Content: tag, Styles: styles}, foundations.Pair{Content: elem, Styles: styles})
+		}
+	}
+	return pairs, nil
+}
+
+func (p *pipeline) LayoutDocument(engine *foundations.Engine, content foundations.Content, jobs int, timer *timing.Timer) (*layout.PagedDocument, error) {
+	if p.layoutErr != nil {
+		return nil, p.layoutErr
+	}
+	defer timer.Start("layout", "")()
+	doc := &layout.PagedDocument{}
+	for range content.Elements {
+		doc.Pages = append(doc.Pages, layout.Page{Number: len(doc.Pages) + 1})
+	}
+	return doc, nil
+}
+
+// testWorld creates an in-memory world whose main file holds the text.
+func testWorld(t *testing.T, text string) *kit.MemoryWorld {
+	t.Helper()
+	world, err := kit.NewMemoryWorld("main.typ", map[string][]byte{"main.typ": []byte(text)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return world
+}
+
+func TestCompile(t *testing.T) {
+	world, err := kit.NewFileWorld("testdata", "main.typ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	routines := &pipeline{}
+	doc, diags := Compile(world, CompileOptions{Routines: routines})
+	if diags.HasErrors() {
+		t.Fatalf("compilation failed: %v", diags)
+	}
+	if routines.source != "Hello, World!\n" {
+		t.Errorf("evaluated %q, want the main file", routines.source)
+	}
+	if doc == nil || len(doc.Pages) != 1 {
+		t.Fatalf("expected a page for the evaluated space, got %v", doc)
+	}
+	if len(diags.Warnings()) != 1 {
+		t.Errorf("warnings = %v, want the one from evaluation", diags)
+	}
+}
+
+func TestCompileTimings(t *testing.T) {
+	timer := timing.NewTimer()
+	doc, diags := Compile(testWorld(t, "Hello, World!"), CompileOptions{Routines: &pipeline{}, Timer: timer})
+	if diags.HasErrors() || doc == nil {
+		t.Fatalf("compilation failed: %v", diags)
+	}
+	var names []string
+	for _, event := range timer.Events() {
+		if !slices.Contains(names, event.Name) {
+			names = append(names, event.Name)
+		}
+	}
+	want := []string{"parse", "eval", "layout"}
+	if !slices.Equal(names, want) {
+		t.Errorf("timed stages = %v, want %v", names, want)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	doc, diags := Compile(testWorld(t, "#let"), CompileOptions{Routines: &pipeline{}})
+	if doc != nil {
+		t.Error("expected no document for a syntax error")
+	}
+	if len(diags.Errors()) == 0 {
+		t.Error("expected a syntax error")
+	}
+
+	// Warnings emitted before the failure are kept.
+	routines := &pipeline{layoutErr: errors.New("cannot lay out")}
+	doc, diags = Compile(testWorld(t, "Hello"), CompileOptions{Routines: routines})
+	if doc != nil || len(diags.Errors()) != 1 || len(diags.Warnings()) != 1 {
+		t.Errorf("expected the layout error and the evaluation warning, got %v", diags)
+	}
+
+	doc, diags = Compile(testWorld(t, "Hello"), CompileOptions{})
+	if doc != nil || !diags.HasErrors() {
+		t.Error("expected an error without routines")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	selector := foundations.ElemSelector{Element: foundations.Element{Name: "space"}}
+	elements, diags := Query(testWorld(t, "Hello"), selector, CompileOptions{Routines: &pipeline{}})
+	if diags.HasErrors() {
+		t.Fatalf("query failed: %v", diags)
+	}
+	if len(elements) != 1 {
+		t.Errorf("Query = %v, want the evaluated space", elements)
+	}
+}
@@ -79,6 +79,11 @@ func (e *TracedError) Unwrap() error {
 	return e.Err
 }
 
+// Tracepoint describes the point through which the error propagated.
+func (e *TracedError) Tracepoint() syntax.Spanned[string] {
+	return syntax.NewSpanned("error occurred in this "+e.Point, e.Span)
+}
+
 // ----------------------------------------------------------------------------
 // Access Trait Implementation
 // ----------------------------------------------------------------------------
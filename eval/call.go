@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
 	"github.com/boergens/gotypst/syntax"
 )
 
@@ -28,6 +29,9 @@ type ClosureFunc = foundations.ClosureFunc
 type WithFunc = foundations.WithFunc
 type ClosureAstNode = foundations.ClosureAstNode
 type ContextAstNode = foundations.ContextAstNode
+type ContextElem = foundations.ContextElem
+type Engine = foundations.Engine
+type Location = foundations.Location
 
 // Value type aliases - using actual foundations type names
 type NoneValue = foundations.NoneValue
@@ -62,6 +66,14 @@ type ContentElement = foundations.ContentElement
 type Module = foundations.Module
 type FileID = syntax.FileId
 
+// Element aliases
+type HElem = layout.HElem
+type VElem = layout.VElem
+type BlockElement = layout.BlockElement
+type ColumnsElement = layout.ColumnsElement
+type ColbreakElem = layout.ColbreakElem
+type PlaceElement = layout.PlaceElement
+
 // Type constants
 const (
 	TypeNone     = foundations.TypeNone
@@ -116,10 +128,7 @@ type ArgsValue = foundations.ArgsValue
 // AsFunc attempts to cast a Value to a Func.
 // Returns the Func and true if the value is a function, nil and false otherwise.
 func AsFunc(v foundations.Value) (*foundations.Func, bool) {
-	if fv, ok := v.(foundations.FuncValue); ok {
-		return fv.Func, true
-	}
-	return nil, false
+	return foundations.AsFunc(v)
 }
 
 // AsInt attempts to cast a Value to int64.
@@ -286,7 +295,7 @@ func EvalFuncCallExpr(vm *Vm, expr *syntax.FuncCallExpr) (foundations.Value, err
 
 	// Check call depth.
 	if err := vm.CheckCallDepth(); err != nil {
-		return nil, err
+		return nil, atSpan(err, span)
 	}
 
 	var calleeValue foundations.Value
@@ -352,6 +361,9 @@ func EvalFuncCallExpr(vm *Vm, expr *syntax.FuncCallExpr) (foundations.Value, err
 func CallFunc(vm *Vm, f *foundations.Func, args *foundations.Args) (foundations.Value, error) {
 	// Check call depth
 	if err := vm.CheckCallDepth(); err != nil {
+		if args != nil {
+			return nil, atSpan(err, args.Span)
+		}
 		return nil, err
 	}
 
@@ -363,6 +375,8 @@ func CallFunc(vm *Vm, f *foundations.Func, args *foundations.Args) (foundations.
 		return callClosure(vm, f, repr.Closure, args)
 	case foundations.WithFunc:
 		return callWith(vm, repr, args)
+	case foundations.WasmFunc:
+		return callWasm(vm, f, args)
 	default:
 		return nil, &InvalidCalleeError{
 			Value: foundations.FuncValue{Func: f},
@@ -380,6 +394,14 @@ func callNative(vm *Vm, native foundations.NativeFunc, args *foundations.Args) (
 	return native.Func(*vm.Engine, *vm.Context, args)
 }
 
+// callWasm calls a function exported by a plugin.
+func callWasm(vm *Vm, f *foundations.Func, args *foundations.Args) (foundations.Value, error) {
+	vm.EnterCall()
+	defer vm.ExitCall()
+
+	return f.Call(vm.Engine, vm.Context, args)
+}
+
 // callClosure calls a user-defined closure.
 func callClosure(vm *Vm, f *foundations.Func, closure *foundations.Closure, args *foundations.Args) (foundations.Value, error) {
 	vm.EnterCall()
@@ -423,6 +445,14 @@ func callClosure(vm *Vm, f *foundations.Func, closure *foundations.Closure, args
 
 	// Bind parameters
 	if params != nil {
+		// Positional parameters after a sink take the last positional
+		// arguments, so the sink only gets what the positional parameters
+		// leave over. A negative size means the sink gets no positional
+		// arguments.
+		sinkSize := args.Pos().Len() - countPosParams(params)
+		var sink *syntax.SinkParam
+		var sinkPos []foundations.Arg
+
 		defaultIdx := 0
 		for _, param := range params.Children() {
 			switch p := param.(type) {
@@ -449,16 +479,15 @@ func callClosure(vm *Vm, f *foundations.Func, closure *foundations.Closure, args
 				defaultIdx++
 
 			case *syntax.SinkParam:
-				// Sink parameter (..rest)
-				if p.Name() != nil {
-					name := p.Name().Get()
-					// Collect remaining positional args
-					remaining := args.All()
-					arr := foundations.NewArray()
-					for _, v := range remaining {
-						arr.Push(v.V)
+				// Sink parameter (..rest), bound once all other parameters
+				// have taken their arguments.
+				sink = p
+				if sinkSize > 0 {
+					consumed, err := args.Consume(sinkSize)
+					if err != nil {
+						return nil, atSpan(err, args.Span)
 					}
-					vm.DefineSimple(name, arr)
+					sinkPos = consumed
 				}
 
 			case *syntax.PlaceholderParam:
@@ -478,6 +507,16 @@ func callClosure(vm *Vm, f *foundations.Func, closure *foundations.Closure, args
 				}
 			}
 		}
+
+		// The sink collects the remaining named arguments along with its
+		// positional ones, even if it is unnamed.
+		if sink != nil {
+			remaining := args.Take()
+			remaining.Items = append(remaining.Items, sinkPos...)
+			if name := sink.Name(); name != nil {
+				vm.DefineSimple(name.Get(), foundations.ArgsValue{Args: remaining})
+			}
+		}
 	}
 
 	// Check for unexpected arguments
@@ -508,6 +547,19 @@ func callClosure(vm *Vm, f *foundations.Func, closure *foundations.Closure, args
 	return output, nil
 }
 
+// countPosParams counts the parameters of a closure that take a positional
+// argument.
+func countPosParams(params *syntax.ParamsNode) int {
+	count := 0
+	for _, param := range params.Children() {
+		switch param.(type) {
+		case *syntax.PosParam, *syntax.PlaceholderParam, *syntax.DestructuringParam:
+			count++
+		}
+	}
+	return count
+}
+
 // callWith calls a function with pre-applied arguments.
 func callWith(vm *Vm, with foundations.WithFunc, args *foundations.Args) (foundations.Value, error) {
 	// Merge pre-applied args with new args
@@ -772,9 +824,14 @@ func evalFieldCall(vm *Vm, targetExpr syntax.Expr, field *syntax.IdentExpr, args
 		args.Span = span
 	}
 
-	// TODO: Look up method in target's type scope.
-	// This requires implementing Type.Scope() which maps types to their method scopes.
-	// For now, we only support direct field access on specific types.
+	// Methods are looked up in the scope of the target's type and receive
+	// the target as their first argument.
+	if scope := target.Type().Scope(); scope != nil {
+		if binding := scope.Get(fieldName); binding != nil {
+			args.Insert(0, targetExpr.ToUntyped().Span(), target)
+			return &FieldCallResult{Kind: FieldCallNormal, Callee: binding.Value(), Args: args}, nil
+		}
+	}
 
 	// Certain value types have their own ways to access method fields.
 	switch target.(type) {
@@ -828,9 +885,13 @@ func getField(target foundations.Value, field string, span syntax.Span) (foundat
 		}
 	case SymbolValue:
 		// Symbols can have modifiers accessed as fields.
-		// For now, return an error - full implementation would look up symbol modifiers.
+		// Matches Rust: Value::Symbol(symbol) => symbol.clone().modified(field)
+		if modified, ok := v.Modified(field); ok {
+			return modified, nil
+		}
+		return nil, atSpan(fmt.Errorf("unknown symbol modifier"), span)
 	}
-	return nil, &FieldNotFoundError{Field: field, Type: target.Type(), Span: span}
+	return nil, &foundations.FieldNotFoundError{Owner: target.Type().String(), Field: field, Span: span}
 }
 
 // missingFieldCallError produces an error when we cannot call the field.
@@ -86,6 +86,9 @@ loop:
 		if err != nil {
 			return nil, atSpan(err, span)
 		}
+		if err := vm.CheckSize(span, output); err != nil {
+			return nil, err
+		}
 
 		if vm.Flow != nil {
 			warnForDiscardedContent(vm.Engine, vm.Flow, output)
@@ -267,6 +270,9 @@ func evalExpr(vm *Vm, expr syntax.Expr) (foundations.Value, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := vm.CheckSize(span, value); err != nil {
+		return nil, err
+	}
 
 	// Add span to the value
 	value = foundations.Spanned(value, span)
@@ -500,30 +506,33 @@ func evalFieldAccess(vm *Vm, e *syntax.FieldAccessExpr) (foundations.Value, erro
 	fieldName := field.Get()
 	fieldSpan := field.ToUntyped().Span()
 
-	// Try normal field access
-	value, fieldErr := target.Field(fieldName, vm.Engine, fieldSpan)
+	// Modules and symbols resolve their fields themselves, as in
+	// `sym.arrow.r`.
+	switch target.(type) {
+	case foundations.ModuleValue, foundations.SymbolValue:
+		return getField(target, fieldName, fieldSpan)
+	}
+
+	// Try normal field access, like `it.body` on content.
+	value, fieldErr := foundations.FieldOf(target, fieldName)
 	if fieldErr == nil {
 		return value, nil
 	}
 
 	// Check for get rule field access (accessing element fields from style chain)
 	if funcVal, ok := target.(foundations.FuncValue); ok {
-		if funcVal.Func != nil {
-			if elem := funcVal.Func.ToElement(); elem != nil {
-				if fieldID := elem.FieldID(fieldName); fieldID != nil {
-					styles, err := vm.Context.Styles.At(fieldSpan)
-					if err != nil {
-						return nil, err
-					}
-					if value, err := elem.FieldFromStyles(*fieldID, styles); err == nil {
-						return value, nil
-					}
+		if funcVal.Func != nil && funcVal.Func.Name != nil {
+			value, found, err := vm.Context.StyleField(*funcVal.Func.Name, fieldName)
+			if found {
+				if err != nil {
+					return nil, atSpan(err, fieldSpan)
 				}
+				return value, nil
 			}
 		}
 	}
 
-	return nil, fieldErr
+	return nil, atSpan(fieldErr, fieldSpan)
 }
 
 // ----------------------------------------------------------------------------
@@ -541,19 +550,21 @@ func evalContextual(vm *Vm, e *syntax.ContextualExpr) (foundations.Value, error)
 	// Collect captured variables
 	captured := captureScope(vm, e)
 
-	// Define the closure
+	// Define the closure. It is called once the context is known.
 	closure := &foundations.Closure{
-		Node:         foundations.ContextNode{Node: body.ToUntyped()},
+		Node:         foundations.ContextAstNode{Node: e.ToUntyped()},
 		Defaults:     nil,
 		Captured:     captured,
 		NumPosParams: 0,
 	}
 
-	fn := foundations.NewFunc(closure).WithSpan(body.ToUntyped().Span())
+	fn := &foundations.Func{
+		Span: body.ToUntyped().Span(),
+		Repr: foundations.ClosureFunc{Closure: closure},
+	}
 
 	// Wrap in ContextElem and return as content
-	contextElem := foundations.NewContextElem(fn)
-	return foundations.ContentValue{Content: contextElem.Pack().WithSpan(body.ToUntyped().Span())}, nil
+	return foundations.ContentValue{Content: foundations.NewContextElem(fn).Pack()}, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -610,10 +621,10 @@ func Display(v foundations.Value) foundations.Content {
 	case foundations.Str:
 		return foundations.TextContent(string(val))
 	case foundations.SymbolValue:
-		return foundations.TextContent(string(val.Char))
+		return foundations.TextContent(val.Get())
 	default:
 		// Display other values as their repr
-		return foundations.TextContent(fmt.Sprintf("%v", v))
+		return foundations.TextContent(foundations.Repr(v))
 	}
 }
 
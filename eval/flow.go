@@ -201,6 +201,9 @@ func evalWhileLoop(vm *Vm, e *syntax.WhileLoopExpr) (foundations.Value, error) {
 		} else if i >= MaxIterations {
 			return nil, fmt.Errorf("loop seems to be infinite")
 		}
+		if err := vm.Step(e.ToUntyped().Span()); err != nil {
+			return nil, err
+		}
 
 		// Evaluate body
 		value, err := evalExpr(vm, body)
@@ -212,6 +215,9 @@ func evalWhileLoop(vm *Vm, e *syntax.WhileLoopExpr) (foundations.Value, error) {
 		if err != nil {
 			return nil, wrapErrorAt(err, body.ToUntyped().Span())
 		}
+		if err := vm.CheckSize(body.ToUntyped().Span(), output); err != nil {
+			return nil, err
+		}
 
 		// Handle flow events
 		switch vm.Flow.(type) {
@@ -265,6 +271,10 @@ func evalForLoop(vm *Vm, e *syntax.ForLoopExpr) (foundations.Value, error) {
 
 	// Helper to run the loop body
 	runBody := func() (shouldBreak bool, err error) {
+		if err := vm.Step(e.ToUntyped().Span()); err != nil {
+			return false, err
+		}
+
 		body := e.Body()
 		if body == nil {
 			return false, nil
@@ -279,6 +289,9 @@ func evalForLoop(vm *Vm, e *syntax.ForLoopExpr) (foundations.Value, error) {
 		if err != nil {
 			return false, wrapErrorAt(err, body.ToUntyped().Span())
 		}
+		if err := vm.CheckSize(body.ToUntyped().Span(), output); err != nil {
+			return false, err
+		}
 
 		// Handle flow events
 		switch vm.Flow.(type) {
@@ -316,8 +316,13 @@ func importFile(engine *foundations.Engine, id syntax.FileId, span syntax.Span)
 		}
 	}
 
-	// Evaluate the file.
-	return EvalSource(engine, source, span)
+	// Evaluate the file. Errors point into the file and are traced back to
+	// the import or include.
+	module, err := EvalSource(engine, source, span)
+	if err != nil {
+		return nil, &TracedError{Err: err, Point: "import", Span: span}
+	}
+	return module, nil
 }
 
 // importPackage imports an external package.
@@ -450,8 +455,16 @@ func resolvePathToFileId(engine *foundations.Engine, path string, span syntax.Sp
 
 // EvalSource evaluates a source file and returns a module.
 //
-// This is a simplified version that creates a module from source.
+// The module is memoized: evaluating an unchanged file again returns the
+// module from the earlier evaluation.
 func EvalSource(engine *foundations.Engine, source *syntax.Source, span syntax.Span) (*Module, error) {
+	return evalSourceMemoized(engine, source, span)
+}
+
+// evalSource evaluates a source file without memoization.
+//
+// This is a simplified version that creates a module from source.
+func evalSource(engine *foundations.Engine, source *syntax.Source, span syntax.Span) (*Module, error) {
 	root := source.Root()
 	if root == nil {
 		return nil, &ImportError{
@@ -460,12 +473,10 @@ func EvalSource(engine *foundations.Engine, source *syntax.Source, span syntax.S
 		}
 	}
 
-	// Check for parser errors.
+	// Report syntax errors in the file itself, where they occurred, rather
+	// than at the import or include.
 	if errs := root.Errors(); len(errs) > 0 {
-		return nil, &ImportError{
-			Message: fmt.Sprintf("parse error: %v", errs[0]),
-			Span:    span,
-		}
+		return nil, foundations.SyntaxErrors(errs)
 	}
 
 	// Create a new context for module evaluation.
@@ -0,0 +1,256 @@
+// Library construction for Typst.
+// Translated from typst-library/src/lib.rs
+
+package eval
+
+import (
+	"github.com/boergens/gotypst/library/calc"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/introspection"
+	"github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/library/math"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/library/pdf"
+	"github.com/boergens/gotypst/library/symbols"
+	"github.com/boergens/gotypst/library/visualize"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// TypstVersion is the version of Typst whose behavior the library follows. It
+// is available to documents as `sys.version`.
+var TypstVersion = foundations.VersionValue{Major: 0, Minor: 13, Patch: 0}
+
+// Feature is an optional feature of the library.
+// Matches Rust: pub enum Feature
+type Feature int
+
+const (
+	// FeatureHTML enables the `html` module and HTML export.
+	FeatureHTML Feature = iota
+)
+
+// String returns the name of the feature.
+func (f Feature) String() string {
+	switch f {
+	case FeatureHTML:
+		return "html"
+	default:
+		return "unknown"
+	}
+}
+
+// Features is a set of enabled features.
+// Matches Rust: pub struct Features
+type Features map[Feature]bool
+
+// IsEnabled reports whether the feature is enabled.
+func (f Features) IsEnabled(feature Feature) bool {
+	return f[feature]
+}
+
+// LibraryBuilder configures the construction of the standard library.
+//
+// Matches Rust: pub struct LibraryBuilder
+type LibraryBuilder struct {
+	inputs   *foundations.Dict
+	features Features
+	funcs    []*foundations.Func
+}
+
+// NewLibraryBuilder creates a builder for the standard library with no
+// inputs, no optional features, and no custom functions.
+//
+// Matches Rust: Library::builder
+func NewLibraryBuilder() *LibraryBuilder {
+	return &LibraryBuilder{features: Features{}}
+}
+
+// WithInputs sets the inputs, which documents can read from
+// `sys.inputs`.
+//
+// Matches Rust: LibraryBuilder::with_inputs
+func (b *LibraryBuilder) WithInputs(inputs map[string]string) *LibraryBuilder {
+	b.inputs = foundations.NewDict()
+	for key, value := range inputs {
+		b.inputs.Set(key, foundations.Str(value))
+	}
+	return b
+}
+
+// WithFeature enables or disables an optional feature.
+//
+// Matches Rust: LibraryBuilder::with_features
+func (b *LibraryBuilder) WithFeature(feature Feature, enabled bool) *LibraryBuilder {
+	b.features[feature] = enabled
+	return b
+}
+
+// WithFunc registers a custom native function under its name. It is
+// defined after the built-in definitions and thus takes precedence over a
+// built-in function of the same name.
+func (b *LibraryBuilder) WithFunc(f *foundations.Func) *LibraryBuilder {
+	b.funcs = append(b.funcs, f)
+	return b
+}
+
+// Build constructs the global scope of the standard library.
+//
+// Matches Rust: LibraryBuilder::build
+func (b *LibraryBuilder) Build() *foundations.Scope {
+	global := foundations.NewScope()
+	define := func(name string, value foundations.Value) {
+		global.Define(name, value, syntax.Detached())
+	}
+	defineFunc := func(f *foundations.Func) {
+		define(*f.Name, foundations.FuncValue{Func: f})
+	}
+
+	// Foundations.
+	for _, t := range globalTypes {
+		define(t.Ident(), foundations.TypeValue{Inner: t})
+	}
+	defineFunc(foundations.AssertFunc())
+	defineFunc(foundations.PanicFunc())
+	defineFunc(foundations.ReprFunc())
+	defineFunc(foundations.PluginFunc())
+	define("sys", b.sysModule())
+	define("calc", foundations.ModuleValue{Module: calc.Module()})
+
+	// Visualize.
+	for _, c := range namedColors {
+		define(c.name, c.color)
+	}
+	defineFunc(visualize.ImageFunc())
+	defineFunc(visualize.LineFunc())
+	defineFunc(visualize.CurveFunc())
+
+	// Layout.
+	defineFunc(layout.PageFunc())
+	defineFunc(layout.HFunc())
+	defineFunc(layout.VFunc())
+	defineFunc(layout.BoxFunc())
+	defineFunc(layout.BlockFunc())
+	defineFunc(layout.AlignFunc())
+	defineFunc(layout.PadFunc())
+	defineFunc(layout.StackFunc())
+	defineFunc(layout.PlaceFunc())
+	defineFunc(layout.MoveFunc())
+	defineFunc(layout.RotateFunc())
+	defineFunc(layout.ScaleFunc())
+	defineFunc(layout.GridFunc())
+	defineFunc(layout.ColumnsFunc())
+	defineFunc(layout.ColbreakFunc())
+	defineFunc(layout.RepeatFunc())
+	defineFunc(layout.MeasureFunc())
+
+	// Model.
+	defineFunc(model.DocumentFunc())
+	defineFunc(model.ParFunc())
+	defineFunc(model.LinebreakFunc())
+	defineFunc(model.LinkFunc())
+	defineFunc(model.NumberingFunc())
+	defineFunc(model.HeadingFunc())
+	defineFunc(model.OutlineFunc())
+	defineFunc(model.CiteFunc())
+
+	// Introspection.
+	defineFunc(introspection.HereFunc())
+	defineFunc(introspection.CounterFunc())
+
+	// PDF.
+	define("pdf", foundations.ModuleValue{Module: pdf.Module()})
+
+	// Math and symbols.
+	define("math", foundations.ModuleValue{Module: math.Module()})
+	define("sym", symbols.Sym.Value())
+	define("emoji", symbols.Emoji.Value())
+
+	if b.features.IsEnabled(FeatureHTML) {
+		define("html", foundations.ModuleValue{Module: &foundations.Module{
+			Name:  "html",
+			Scope: foundations.NewScope(),
+		}})
+	}
+
+	for _, f := range b.funcs {
+		defineFunc(f)
+	}
+	return global
+}
+
+// sysModule creates the `sys` module, which holds the version of Typst
+// and the inputs.
+func (b *LibraryBuilder) sysModule() foundations.Value {
+	inputs := b.inputs
+	if inputs == nil {
+		inputs = foundations.NewDict()
+	}
+	scope := foundations.NewScope()
+	scope.Define("version", TypstVersion, syntax.Detached())
+	scope.Define("inputs", inputs, syntax.Detached())
+	return foundations.ModuleValue{Module: &foundations.Module{Name: "sys", Scope: scope}}
+}
+
+// Library constructs the standard library with the default configuration.
+func Library() *foundations.Scope {
+	return NewLibraryBuilder().Build()
+}
+
+// globalTypes are the types defined in the global scope.
+var globalTypes = []foundations.Type{
+	foundations.TypeNone,
+	foundations.TypeAuto,
+	foundations.TypeBool,
+	foundations.TypeInt,
+	foundations.TypeFloat,
+	foundations.TypeLength,
+	foundations.TypeAngle,
+	foundations.TypeRatio,
+	foundations.TypeRelative,
+	foundations.TypeFraction,
+	foundations.TypeStr,
+	foundations.TypeBytes,
+	foundations.TypeLabel,
+	foundations.TypeDatetime,
+	foundations.TypeDuration,
+	foundations.TypeDecimal,
+	foundations.TypeColor,
+	foundations.TypeGradient,
+	foundations.TypeTiling,
+	foundations.TypeSymbol,
+	foundations.TypeContent,
+	foundations.TypeArray,
+	foundations.TypeDict,
+	foundations.TypeFunc,
+	foundations.TypeArgs,
+	foundations.TypeType,
+	foundations.TypeModule,
+	foundations.TypeVersion,
+	foundations.TypeSelector,
+}
+
+// namedColors are the predefined colors.
+// Matches Rust: Color::BLACK etc. in visualize/color.rs
+var namedColors = []struct {
+	name  string
+	color foundations.Color
+}{
+	{"black", foundations.NewLuma(0, 1)},
+	{"gray", foundations.NewLuma(170.0/255, 1)},
+	{"silver", foundations.NewLuma(221.0/255, 1)},
+	{"white", foundations.NewLuma(1, 1)},
+	{"navy", foundations.NewRgbaFromBytes(0x00, 0x1f, 0x3f, 0xff)},
+	{"blue", foundations.NewRgbaFromBytes(0x00, 0x74, 0xd9, 0xff)},
+	{"aqua", foundations.NewRgbaFromBytes(0x7f, 0xdb, 0xff, 0xff)},
+	{"teal", foundations.NewRgbaFromBytes(0x39, 0xcc, 0xcc, 0xff)},
+	{"eastern", foundations.NewRgbaFromBytes(0x23, 0x9d, 0xad, 0xff)},
+	{"purple", foundations.NewRgbaFromBytes(0xb1, 0x0d, 0xc9, 0xff)},
+	{"fuchsia", foundations.NewRgbaFromBytes(0xf0, 0x12, 0xbe, 0xff)},
+	{"maroon", foundations.NewRgbaFromBytes(0x85, 0x14, 0x4b, 0xff)},
+	{"red", foundations.NewRgbaFromBytes(0xff, 0x41, 0x36, 0xff)},
+	{"orange", foundations.NewRgbaFromBytes(0xff, 0x85, 0x1b, 0xff)},
+	{"yellow", foundations.NewRgbaFromBytes(0xff, 0xdc, 0x00, 0xff)},
+	{"olive", foundations.NewRgbaFromBytes(0x3d, 0x99, 0x70, 0xff)},
+	{"green", foundations.NewRgbaFromBytes(0x2e, 0xcc, 0x40, 0xff)},
+	{"lime", foundations.NewRgbaFromBytes(0x01, 0xff, 0x70, 0xff)},
+}
@@ -3,6 +3,8 @@ package eval
 import (
 	"math"
 	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
 )
 
 func TestLibrary(t *testing.T) {
@@ -26,8 +28,8 @@ func TestLibrary_Colors(t *testing.T) {
 			continue
 		}
 		// Colors are now stored as Color interface values (e.g., Rgba)
-		if _, ok := binding.Value.(Color); !ok {
-			t.Errorf("Expected %q to be a Color, got %T", name, binding.Value)
+		if _, ok := binding.Value().(Color); !ok {
+			t.Errorf("Expected %q to be a Color, got %T", name, binding.Value())
 		}
 	}
 }
@@ -42,8 +44,8 @@ func TestLibrary_Alignments(t *testing.T) {
 			t.Errorf("Expected alignment %q to be defined", name)
 			continue
 		}
-		if _, ok := binding.Value.(AlignmentValue); !ok {
-			t.Errorf("Expected %q to be an AlignmentValue, got %T", name, binding.Value)
+		if _, ok := binding.Value().(AlignmentValue); !ok {
+			t.Errorf("Expected %q to be an AlignmentValue, got %T", name, binding.Value())
 		}
 	}
 
@@ -54,8 +56,8 @@ func TestLibrary_Alignments(t *testing.T) {
 			t.Errorf("Expected direction %q to be defined", name)
 			continue
 		}
-		if _, ok := binding.Value.(DirectionValue); !ok {
-			t.Errorf("Expected %q to be a DirectionValue, got %T", name, binding.Value)
+		if _, ok := binding.Value().(DirectionValue); !ok {
+			t.Errorf("Expected %q to be a DirectionValue, got %T", name, binding.Value())
 		}
 	}
 }
@@ -70,8 +72,8 @@ func TestLibrary_Types(t *testing.T) {
 			t.Errorf("Expected type %q to be defined", name)
 			continue
 		}
-		if _, ok := binding.Value.(TypeValue); !ok {
-			t.Errorf("Expected %q to be a TypeValue, got %T", name, binding.Value)
+		if _, ok := binding.Value().(TypeValue); !ok {
+			t.Errorf("Expected %q to be a TypeValue, got %T", name, binding.Value())
 		}
 	}
 }
@@ -84,9 +86,9 @@ func TestLibrary_CalcModule(t *testing.T) {
 		t.Fatal("Expected calc module to be defined")
 	}
 
-	calcModule, ok := calcBinding.Value.(ModuleValue)
+	calcModule, ok := calcBinding.Value().(ModuleValue)
 	if !ok {
-		t.Fatalf("Expected calc to be a ModuleValue, got %T", calcBinding.Value)
+		t.Fatalf("Expected calc to be a ModuleValue, got %T", calcBinding.Value())
 	}
 
 	// Check constants
@@ -111,8 +113,8 @@ func TestLibrary_CalcModule(t *testing.T) {
 			t.Errorf("Expected calc.%s to be defined", name)
 			continue
 		}
-		if _, ok := binding.Value.(FuncValue); !ok {
-			t.Errorf("Expected calc.%s to be a FuncValue, got %T", name, binding.Value)
+		if _, ok := binding.Value().(FuncValue); !ok {
+			t.Errorf("Expected calc.%s to be a FuncValue, got %T", name, binding.Value())
 		}
 	}
 }
@@ -127,8 +129,8 @@ func TestLibrary_ElementFunctions(t *testing.T) {
 			t.Errorf("Expected element function %q to be defined", name)
 			continue
 		}
-		if _, ok := binding.Value.(FuncValue); !ok {
-			t.Errorf("Expected %q to be a FuncValue, got %T", name, binding.Value)
+		if _, ok := binding.Value().(FuncValue); !ok {
+			t.Errorf("Expected %q to be a FuncValue, got %T", name, binding.Value())
 		}
 	}
 }
@@ -136,7 +138,7 @@ func TestLibrary_ElementFunctions(t *testing.T) {
 func TestLibrary_CalcConstants(t *testing.T) {
 	lib := Library()
 	calcBinding := lib.Get("calc")
-	calcModule := calcBinding.Value.(ModuleValue)
+	calcModule := calcBinding.Value().(ModuleValue)
 	calcScope := calcModule.Module.Scope
 
 	// Test pi
@@ -144,12 +146,12 @@ func TestLibrary_CalcConstants(t *testing.T) {
 	if piBinding == nil {
 		t.Fatal("calc.pi not found")
 	}
-	if pi, ok := piBinding.Value.(FloatValue); ok {
+	if pi, ok := piBinding.Value().(FloatValue); ok {
 		if math.Abs(float64(pi)-math.Pi) > 1e-10 {
 			t.Errorf("calc.pi = %v, expected %v", pi, math.Pi)
 		}
 	} else {
-		t.Errorf("calc.pi is not a FloatValue: %T", piBinding.Value)
+		t.Errorf("calc.pi is not a FloatValue: %T", piBinding.Value())
 	}
 
 	// Test e
@@ -157,12 +159,12 @@ func TestLibrary_CalcConstants(t *testing.T) {
 	if eBinding == nil {
 		t.Fatal("calc.e not found")
 	}
-	if e, ok := eBinding.Value.(FloatValue); ok {
+	if e, ok := eBinding.Value().(FloatValue); ok {
 		if math.Abs(float64(e)-math.E) > 1e-10 {
 			t.Errorf("calc.e = %v, expected %v", e, math.E)
 		}
 	} else {
-		t.Errorf("calc.e is not a FloatValue: %T", eBinding.Value)
+		t.Errorf("calc.e is not a FloatValue: %T", eBinding.Value())
 	}
 
 	// Test inf
@@ -170,12 +172,12 @@ func TestLibrary_CalcConstants(t *testing.T) {
 	if infBinding == nil {
 		t.Fatal("calc.inf not found")
 	}
-	if inf, ok := infBinding.Value.(FloatValue); ok {
+	if inf, ok := infBinding.Value().(FloatValue); ok {
 		if !math.IsInf(float64(inf), 1) {
 			t.Errorf("calc.inf = %v, expected +Inf", inf)
 		}
 	} else {
-		t.Errorf("calc.inf is not a FloatValue: %T", infBinding.Value)
+		t.Errorf("calc.inf is not a FloatValue: %T", infBinding.Value())
 	}
 
 	// Test nan
@@ -183,12 +185,12 @@ func TestLibrary_CalcConstants(t *testing.T) {
 	if nanBinding == nil {
 		t.Fatal("calc.nan not found")
 	}
-	if nan, ok := nanBinding.Value.(FloatValue); ok {
+	if nan, ok := nanBinding.Value().(FloatValue); ok {
 		if !math.IsNaN(float64(nan)) {
 			t.Errorf("calc.nan = %v, expected NaN", nan)
 		}
 	} else {
-		t.Errorf("calc.nan is not a FloatValue: %T", nanBinding.Value)
+		t.Errorf("calc.nan is not a FloatValue: %T", nanBinding.Value())
 	}
 }
 
@@ -214,9 +216,9 @@ func TestLibrary_ColorValues(t *testing.T) {
 			continue
 		}
 		// Colors are stored as Rgba (implements Color interface)
-		rgba, ok := binding.Value.(Rgba)
+		rgba, ok := binding.Value().(Rgba)
 		if !ok {
-			t.Errorf("%q is not an Rgba: %T", tc.name, binding.Value)
+			t.Errorf("%q is not an Rgba: %T", tc.name, binding.Value())
 			continue
 		}
 		r, g, b, a := rgba.ToBytes()
@@ -236,8 +238,8 @@ func TestLibrary_Lorem(t *testing.T) {
 	if loremBinding == nil {
 		t.Fatal("Expected lorem function to be defined")
 	}
-	if _, ok := loremBinding.Value.(FuncValue); !ok {
-		t.Fatalf("Expected lorem to be a FuncValue, got %T", loremBinding.Value)
+	if _, ok := loremBinding.Value().(FuncValue); !ok {
+		t.Fatalf("Expected lorem to be a FuncValue, got %T", loremBinding.Value())
 	}
 }
 
@@ -293,3 +295,41 @@ func TestLibrary_LoremOutput(t *testing.T) {
 		t.Errorf("Word 17 should be 'magnam' (from lipsum crate), got %q", loremWords[17])
 	}
 }
+
+func TestLibraryBuilder_Inputs(t *testing.T) {
+	lib := NewLibraryBuilder().WithInputs(map[string]string{"name": "gotypst"}).Build()
+
+	sys, ok := lib.Get("sys").Read().(foundations.ModuleValue)
+	if !ok {
+		t.Fatal("Expected sys to be a module")
+	}
+	inputs, ok := sys.Module.Scope.Get("inputs").Read().(*foundations.Dict)
+	if !ok {
+		t.Fatal("Expected sys.inputs to be a dictionary")
+	}
+	if v, ok := inputs.Get("name"); !ok || v != foundations.Str("gotypst") {
+		t.Errorf("sys.inputs.name = %v, want \"gotypst\"", v)
+	}
+	if v := sys.Module.Scope.Get("version").Read(); v != TypstVersion {
+		t.Errorf("sys.version = %v, want %v", v, TypstVersion)
+	}
+}
+
+func TestLibraryBuilder_Features(t *testing.T) {
+	if Library().Get("html") != nil {
+		t.Error("Expected html to be disabled by default")
+	}
+	lib := NewLibraryBuilder().WithFeature(FeatureHTML, true).Build()
+	if _, ok := lib.Get("html").Read().(foundations.ModuleValue); !ok {
+		t.Error("Expected html module with the html feature enabled")
+	}
+}
+
+func TestLibraryBuilder_Funcs(t *testing.T) {
+	name := "repr"
+	custom := &foundations.Func{Name: &name, Repr: foundations.NativeFunc{}}
+	lib := NewLibraryBuilder().WithFunc(custom).Build()
+	if fv, ok := lib.Get("repr").Read().(foundations.FuncValue); !ok || fv.Func != custom {
+		t.Error("Expected the custom function to replace the built-in one")
+	}
+}
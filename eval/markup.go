@@ -5,6 +5,7 @@ package eval
 
 import (
 	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/text"
 	"github.com/boergens/gotypst/syntax"
 )
 
@@ -18,6 +19,7 @@ func evalMarkup(vm *Vm, markup *syntax.MarkupNode) (foundations.Value, error) {
 	flow := vm.TakeFlow()
 	exprs := markup.Exprs()
 	seq := make([]foundations.Content, 0, len(exprs))
+	size := 0
 
 	i := 0
 loop:
@@ -105,8 +107,12 @@ loop:
 				}
 			} else {
 				// Add displayed value to sequence
-				content := Display(value).WithSpan(expr.ToUntyped().Span())
+				content := vm.display(value, expr.ToUntyped().Span())
 				seq = append(seq, content)
+				size += len(content.Elements)
+				if err := vm.Engine.Budget.CheckContentSize(expr.ToUntyped().Span(), size); err != nil {
+					return nil, err
+				}
 			}
 		}
 
@@ -122,20 +128,32 @@ loop:
 	return foundations.ContentValue{Content: foundations.Sequence(seq)}, nil
 }
 
+// display turns a value in markup into content with the span of its
+// expression. Symbols, which every escape and shorthand evaluates to, are
+// allocated in the engine's arena, like text, spaces, and paragraph
+// breaks.
+func (vm *Vm) display(value foundations.Value, span syntax.Span) foundations.Content {
+	if sym, ok := value.(foundations.SymbolValue); ok {
+		arena := vm.Engine.Arena
+		return arena.Content(arena.Symbol(sym.Get(), span))
+	}
+	return Display(value).WithSpan(span)
+}
+
 // ----------------------------------------------------------------------------
 // Text and Whitespace
 // ----------------------------------------------------------------------------
 
 // evalText evaluates a text expression.
 // Matches Rust: impl Eval for ast::Text
-func evalText(_ *Vm, e *syntax.TextExpr) (foundations.Value, error) {
-	return foundations.ContentValue{Content: foundations.TextElemPacked(e.Get())}, nil
+func evalText(vm *Vm, e *syntax.TextExpr) (foundations.Value, error) {
+	return foundations.ContentValue{Content: text.PackedIn(vm.Engine.Arena, e.Get(), e.ToUntyped().Span())}, nil
 }
 
 // evalSpace evaluates a space expression.
 // Matches Rust: impl Eval for ast::Space
-func evalSpace(_ *Vm, _ *syntax.SpaceExpr) (foundations.Value, error) {
-	return foundations.ContentValue{Content: foundations.SpaceElemShared()}, nil
+func evalSpace(vm *Vm, _ *syntax.SpaceExpr) (foundations.Value, error) {
+	return foundations.ContentValue{Content: vm.Engine.Arena.Space()}, nil
 }
 
 // evalLinebreak evaluates a linebreak expression.
@@ -146,8 +164,8 @@ func evalLinebreak(_ *Vm, _ *syntax.LinebreakExpr) (foundations.Value, error) {
 
 // evalParbreak evaluates a paragraph break expression.
 // Matches Rust: impl Eval for ast::Parbreak
-func evalParbreak(_ *Vm, _ *syntax.ParbreakExpr) (foundations.Value, error) {
-	return foundations.ContentValue{Content: foundations.ParbreakElemShared()}, nil
+func evalParbreak(vm *Vm, _ *syntax.ParbreakExpr) (foundations.Value, error) {
+	return foundations.ContentValue{Content: vm.Engine.Arena.Parbreak()}, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -303,13 +303,13 @@ func valueToContent(v foundations.Value) foundations.Content {
 		return foundations.Content{}
 	case foundations.SymbolValue:
 		// Symbols display as their character
-		return foundations.Content{Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: string(val.Char)}}}
+		return foundations.Content{Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: val.Get()}}}
 	default:
 		if v == nil {
 			return foundations.Content{}
 		}
-		// Fallback: format as string
-		return foundations.Content{Elements: []foundations.ContentElement{textElemPacked(fmt.Sprintf("%v", v))}}
+		// Fallback: display the value's representation
+		return foundations.Content{Elements: []foundations.ContentElement{textElemPacked(foundations.Repr(v))}}
 	}
 }
 
@@ -326,7 +326,7 @@ func valueToTextContent(v foundations.Value) foundations.Content {
 		if v == nil {
 			return foundations.Content{}
 		}
-		return foundations.Content{Elements: []foundations.ContentElement{textElemPacked(fmt.Sprintf("%v", v))}}
+		return foundations.Content{Elements: []foundations.ContentElement{textElemPacked(foundations.Repr(v))}}
 	}
 }
 
@@ -0,0 +1,144 @@
+// Memoized module evaluation for Typst.
+// Corresponds to the #[comemo::memoize] eval function in typst-eval/src/lib.rs
+
+package eval
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/memo"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// moduleKey identifies the evaluation of a file: its content and the
+// standard library it is evaluated with.
+type moduleKey struct {
+	id      syntax.FileId
+	text    memo.Hash
+	library *foundations.Scope
+}
+
+// memoizedModule is the result of evaluating a file, together with what
+// the evaluation read from the world and emitted into the sink.
+type memoizedModule struct {
+	module *Module
+	sink   *foundations.Sink
+	reads  []worldRead
+}
+
+// moduleCache holds the modules of evaluated files across compilations.
+var moduleCache = memo.NewCache[moduleKey, *memoizedModule]()
+
+// evalSourceMemoized evaluates a source file, reusing the module from an
+// earlier evaluation if neither the file nor anything the evaluation read
+// from the world has changed since.
+//
+// Matches Rust: #[comemo::memoize] pub fn eval
+func evalSourceMemoized(engine *foundations.Engine, source *syntax.Source, span syntax.Span) (*Module, error) {
+	// Traced evaluations must actually run to observe the traced span.
+	if engine.World == nil || engine.Traced != nil {
+		return evalSource(engine, source, span)
+	}
+
+	key := moduleKey{
+		id:      source.Id(),
+		text:    memo.HashString(source.Text()),
+		library: engine.World.Library(),
+	}
+	if cached, ok := moduleCache.Get(key); ok && cached.valid(engine.World) {
+		engine.Sink.Extend(cached.sink)
+		return cached.module, nil
+	}
+
+	// Evaluate with a tracked world and a fresh sink, so that the reads and
+	// the warnings of this evaluation can be stored with its result.
+	world := &trackedWorld{World: engine.World}
+	sub := *engine
+	sub.World = world
+	sub.Sink = foundations.NewSink()
+	module, err := evalSource(&sub, source, span)
+	engine.Sink.Extend(sub.Sink)
+	if err != nil {
+		return nil, err
+	}
+
+	if !world.volatile {
+		moduleCache.Put(key, &memoizedModule{module: module, sink: sub.Sink, reads: world.reads})
+	}
+	return module, nil
+}
+
+// valid checks whether everything the evaluation read is still the same.
+// Reading through a tracked world records the reads again, so that an
+// enclosing evaluation depends on them, too.
+func (m *memoizedModule) valid(world foundations.World) bool {
+	for _, read := range m.reads {
+		if readHash(world, read.id, read.source) != read.hash {
+			return false
+		}
+	}
+	return true
+}
+
+// worldRead is a file read through the world and a fingerprint of the
+// result.
+type worldRead struct {
+	id syntax.FileId
+	// source is whether the file was read as a source rather than as raw
+	// bytes.
+	source bool
+	hash   memo.Hash
+}
+
+// readHash reads a file through the world and fingerprints the result. A
+// failed read is fingerprinted by its error message.
+func readHash(world foundations.World, id syntax.FileId, source bool) memo.Hash {
+	if source {
+		src, err := world.Source(id)
+		return sourceHash(src, err)
+	}
+	data, err := world.File(id)
+	return fileHash(data, err)
+}
+
+func sourceHash(src *syntax.Source, err error) memo.Hash {
+	if err != nil {
+		return memo.HashString("error: " + err.Error())
+	}
+	return memo.HashString(src.Text())
+}
+
+func fileHash(data []byte, err error) memo.Hash {
+	if err != nil {
+		return memo.HashString("error: " + err.Error())
+	}
+	return memo.HashBytes(data)
+}
+
+// trackedWorld records the files read through it, so that a memoized
+// result can later be checked against the current state of the world.
+//
+// Matches Rust: comemo::Tracked<dyn World>
+type trackedWorld struct {
+	foundations.World
+	reads []worldRead
+	// volatile is set if the evaluation used the current date, which is
+	// not tracked. Such evaluations are not memoized.
+	volatile bool
+}
+
+func (w *trackedWorld) Source(id syntax.FileId) (*syntax.Source, error) {
+	src, err := w.World.Source(id)
+	w.reads = append(w.reads, worldRead{id: id, source: true, hash: sourceHash(src, err)})
+	return src, err
+}
+
+func (w *trackedWorld) File(id syntax.FileId) ([]byte, error) {
+	data, err := w.World.File(id)
+	w.reads = append(w.reads, worldRead{id: id, hash: fileHash(data, err)})
+	return data, err
+}
+
+func (w *trackedWorld) Today(offset *int) *foundations.Datetime {
+	w.volatile = true
+	return w.World.Today(offset)
+}
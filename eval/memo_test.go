@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// memoWorld is an in-memory world whose files can be changed between
+// evaluations.
+type memoWorld struct {
+	library *foundations.Scope
+	sources map[syntax.FileId]*syntax.Source
+}
+
+func newMemoWorld() *memoWorld {
+	return &memoWorld{library: foundations.NewScope(), sources: map[syntax.FileId]*syntax.Source{}}
+}
+
+func (w *memoWorld) set(t *testing.T, path, text string) *syntax.Source {
+	t.Helper()
+	vpath, err := syntax.NewVirtualPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern()
+	w.sources[id] = syntax.NewSource(id, text)
+	return w.sources[id]
+}
+
+func (w *memoWorld) Library() *foundations.Scope { return w.library }
+func (w *memoWorld) MainFile() syntax.FileId     { panic("no main file") }
+
+func (w *memoWorld) Source(id syntax.FileId) (*syntax.Source, error) {
+	if src, ok := w.sources[id]; ok {
+		return src, nil
+	}
+	return nil, errors.New("file not found")
+}
+
+func (w *memoWorld) File(id syntax.FileId) ([]byte, error) {
+	src, err := w.Source(id)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(src.Text()), nil
+}
+
+func (w *memoWorld) Today(offset *int) *foundations.Datetime { return foundations.Today(offset) }
+
+func TestEvalSourceMemoized(t *testing.T) {
+	world := newMemoWorld()
+	source := world.set(t, "/a.typ", "#let x = 1")
+
+	first, err := EvalSource(NewEngine(world), source, syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := EvalSource(NewEngine(world), source, syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("evaluating an unchanged file did not reuse the module")
+	}
+
+	changed := world.set(t, "/a.typ", "#let x = 2")
+	third, err := EvalSource(NewEngine(world), changed, syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first {
+		t.Error("evaluating a changed file reused the old module")
+	}
+}
+
+func TestEvalSourceMemoizedImports(t *testing.T) {
+	world := newMemoWorld()
+	source := world.set(t, "/a.typ", `#import "b.typ": y`+"\n#let x = y")
+	world.set(t, "/b.typ", "#let y = 1")
+
+	first, err := EvalSource(NewEngine(world), source, syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The importing file is unchanged, but the imported one is not.
+	world.set(t, "/b.typ", "#let y = 2")
+	second, err := EvalSource(NewEngine(world), source, syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("module was reused although an imported file changed")
+	}
+	if x := second.Scope.Get("x"); x == nil || x.Read() != foundations.Int(2) {
+		t.Errorf("x = %v, want 2", x)
+	}
+}
@@ -0,0 +1,428 @@
+// Native function registration for Typst.
+// Lets Go embedders expose host functions to Typst code.
+
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Signature describes the parameters of a native function registered from
+// Go, in the order of the parameters of the Go function.
+//
+// The type of a parameter is derived from the Go type and need not be
+// given. A named parameter is passed by name, like `f(limit: 5)`. A
+// parameter with a default may be omitted. A variadic parameter must have
+// a slice type and come after all other positional parameters; it collects
+// the remaining positional arguments.
+type Signature struct {
+	Params []foundations.ParamInfo
+}
+
+var (
+	errorType = reflect.TypeFor[error]()
+	valueType = reflect.TypeFor[foundations.Value]()
+	bytesType = reflect.TypeFor[[]byte]()
+)
+
+// RegisterNative defines a Go function in the scope, so that Typst code
+// can call it under the name.
+//
+// Arguments are converted from Typst values to the types of the Go
+// parameters: booleans, integers, floats, strings, bytes, slices, maps
+// with string keys, pointers (with none as nil), and foundations.Value,
+// which receives the value as is. The function may return nothing, a
+// value, an error, or a value and an error. Returned values are converted
+// back along the same rules; a returned error fails the call at the call
+// site.
+//
+// For example, a host function looking up a user by id:
+//
+//	eval.RegisterNative(scope, "user", func(id int, fields []string) (map[string]string, error) {
+//		return db.Lookup(id, fields)
+//	}, eval.Signature{Params: []foundations.ParamInfo{
+//		{Name: "id"},
+//		{Name: "fields", Named: true, Default: foundations.NewArray()},
+//	}})
+func RegisterNative(scope *foundations.Scope, name string, fn any, sig Signature) error {
+	f, err := NewNativeFunc(name, fn, sig)
+	if err != nil {
+		return err
+	}
+	scope.Define(name, foundations.FuncValue{Func: f}, syntax.Detached())
+	return nil
+}
+
+// NewNativeFunc wraps a Go function as a Typst function, marshaling its
+// arguments and results as described for RegisterNative.
+func NewNativeFunc(name string, fn any, sig Signature) (*foundations.Func, error) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("native %s: expected a function, got %s", name, t)
+	}
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("native %s: Go variadic functions are not supported, use a slice parameter", name)
+	}
+	if t.NumIn() != len(sig.Params) {
+		return nil, fmt.Errorf("native %s: function has %d parameters, but the signature describes %d", name, t.NumIn(), len(sig.Params))
+	}
+
+	params := make([]foundations.ParamInfo, len(sig.Params))
+	variadic := false
+	for i, param := range sig.Params {
+		in := t.In(i)
+		if !marshalable(in) {
+			return nil, fmt.Errorf("native %s: parameter %s has unsupported type %s", name, param.Name, in)
+		}
+		if variadic && !param.Named {
+			return nil, fmt.Errorf("native %s: positional parameter %s follows a variadic one", name, param.Name)
+		}
+		if param.Variadic {
+			if in.Kind() != reflect.Slice || in == bytesType {
+				return nil, fmt.Errorf("native %s: variadic parameter %s must be a slice", name, param.Name)
+			}
+			variadic = true
+		}
+		param.Type = typstType(in)
+		params[i] = param
+	}
+
+	switch {
+	case t.NumOut() > 2,
+		t.NumOut() == 2 && t.Out(1) != errorType,
+		t.NumOut() >= 1 && t.Out(0) != errorType && !marshalable(t.Out(0)):
+		return nil, fmt.Errorf("native %s: unsupported results %s", name, t)
+	}
+
+	native := func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+		in := make([]reflect.Value, len(params))
+		for i, param := range params {
+			arg, err := nativeArg(args, param, t.In(i))
+			if err != nil {
+				return nil, err
+			}
+			in[i] = arg
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		return nativeResult(v.Call(in), args.Span)
+	}
+
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: native,
+			Info: &foundations.FuncInfo{Name: name, Params: params},
+		},
+	}, nil
+}
+
+// nativeArg takes the argument for a parameter and converts it to the Go
+// type.
+func nativeArg(args *foundations.Args, param foundations.ParamInfo, t reflect.Type) (reflect.Value, error) {
+	if param.Variadic {
+		all := args.All()
+		out := reflect.MakeSlice(t, len(all), len(all))
+		for i, arg := range all {
+			elem, err := fromValue(arg.V, t.Elem())
+			if err != nil {
+				return reflect.Value{}, castError(err, param.Name, arg.Span)
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	}
+
+	var arg *syntax.Spanned[foundations.Value]
+	if param.Named {
+		arg = args.Named(param.Name)
+	} else if param.Default != nil {
+		arg = args.Eat()
+	} else {
+		spanned, err := args.Expect(param.Name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		arg = &spanned
+	}
+
+	if arg == nil {
+		if param.Default == nil {
+			return reflect.Value{}, &foundations.MissingArgumentError{Name: param.Name, Span: args.Span}
+		}
+		out, err := fromValue(param.Default, t)
+		if err != nil {
+			return reflect.Value{}, castError(err, param.Name, args.Span)
+		}
+		return out, nil
+	}
+
+	out, err := fromValue(arg.V, t)
+	if err != nil {
+		return reflect.Value{}, castError(err, param.Name, arg.Span)
+	}
+	return out, nil
+}
+
+// nativeResult converts the results of a Go function into a value.
+func nativeResult(out []reflect.Value, span syntax.Span) (foundations.Value, error) {
+	if n := len(out); n > 0 && out[n-1].Type() == errorType {
+		if !out[n-1].IsNil() {
+			err := out[n-1].Interface().(error)
+			var diag foundations.SourceDiagnostic
+			if errors.As(err, &diag) {
+				return nil, err
+			}
+			return nil, foundations.NewSourceError(span, err.Error())
+		}
+		out = out[:n-1]
+	}
+	if len(out) == 0 {
+		return foundations.None, nil
+	}
+	v, err := toValue(out[0])
+	if err != nil {
+		return nil, foundations.NewSourceError(span, err.Error())
+	}
+	return v, nil
+}
+
+// castError attaches the parameter and span to a conversion error.
+func castError(err error, name string, span syntax.Span) error {
+	var mismatch *foundations.TypeMismatchError
+	if errors.As(err, &mismatch) {
+		mismatch.Field = name
+		mismatch.Span = span
+		return mismatch
+	}
+	return foundations.NewSourceError(span, err.Error())
+}
+
+// marshalable reports whether values of the Go type can be converted from
+// and to Typst values.
+func marshalable(t reflect.Type) bool {
+	if t == valueType || t == bytesType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice, reflect.Pointer:
+		return marshalable(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && marshalable(t.Elem())
+	case reflect.Interface:
+		return t.Implements(valueType)
+	}
+	return false
+}
+
+// typstType returns the Typst type that corresponds to a Go type. Types
+// that accept any value map to TypeDyn.
+func typstType(t reflect.Type) foundations.Type {
+	if t == bytesType {
+		return foundations.TypeBytes
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return foundations.TypeBool
+	case reflect.String:
+		return foundations.TypeStr
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return foundations.TypeInt
+	case reflect.Float32, reflect.Float64:
+		return foundations.TypeFloat
+	case reflect.Slice:
+		return foundations.TypeArray
+	case reflect.Map:
+		return foundations.TypeDict
+	case reflect.Pointer:
+		return typstType(t.Elem())
+	}
+	return foundations.TypeDyn
+}
+
+// fromValue converts a Typst value to the Go type.
+func fromValue(v foundations.Value, t reflect.Type) (reflect.Value, error) {
+	mismatch := func() error {
+		return &foundations.TypeMismatchError{Expected: typstType(t).String(), Got: v.Type().String()}
+	}
+
+	if t.Kind() == reflect.Interface {
+		if v == nil || !reflect.TypeOf(v).Implements(t) {
+			return reflect.Value{}, &foundations.TypeMismatchError{Expected: t.String(), Got: v.Type().String()}
+		}
+		return reflect.ValueOf(v).Convert(t), nil
+	}
+	if t == bytesType {
+		b, ok := v.(foundations.BytesValue)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		return reflect.ValueOf([]byte(b)), nil
+	}
+
+	out := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Pointer:
+		if _, ok := v.(foundations.NoneValue); ok {
+			return out, nil
+		}
+		elem, err := fromValue(v, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Bool:
+		b, ok := v.(foundations.Bool)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		out.SetBool(bool(b))
+	case reflect.String:
+		s, ok := v.(foundations.Str)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		out.SetString(string(s))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := v.(foundations.Int)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		if out.OverflowInt(int64(i)) {
+			return reflect.Value{}, fmt.Errorf("number too large: %d", i)
+		}
+		out.SetInt(int64(i))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := v.(foundations.Int)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		if i < 0 {
+			return reflect.Value{}, fmt.Errorf("number must be at least zero")
+		}
+		if out.OverflowUint(uint64(i)) {
+			return reflect.Value{}, fmt.Errorf("number too large: %d", i)
+		}
+		out.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		switch n := v.(type) {
+		case foundations.Float:
+			out.SetFloat(float64(n))
+		case foundations.Int:
+			out.SetFloat(float64(n))
+		default:
+			return reflect.Value{}, mismatch()
+		}
+	case reflect.Slice:
+		arr, ok := v.(*foundations.Array)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		items := arr.Items()
+		out = reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			elem, err := fromValue(item, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(elem)
+		}
+	case reflect.Map:
+		dict, ok := v.(*foundations.Dict)
+		if !ok {
+			return reflect.Value{}, mismatch()
+		}
+		keys, values := dict.Iter()
+		out = reflect.MakeMapWithSize(t, len(keys))
+		for i, key := range keys {
+			elem, err := fromValue(values[i], t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+	default:
+		return reflect.Value{}, mismatch()
+	}
+	return out, nil
+}
+
+// toValue converts a Go value to a Typst value. Map entries are added in
+// key order.
+func toValue(v reflect.Value) (foundations.Value, error) {
+	t := v.Type()
+	if t.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return foundations.None, nil
+		}
+		return v.Interface().(foundations.Value), nil
+	}
+	if t == bytesType {
+		return foundations.BytesValue(v.Bytes()), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return foundations.None, nil
+		}
+		return toValue(v.Elem())
+	case reflect.Bool:
+		return foundations.Bool(v.Bool()), nil
+	case reflect.String:
+		return foundations.Str(v.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return foundations.Int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("number too large: %d", v.Uint())
+		}
+		return foundations.Int(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return foundations.Float(v.Float()), nil
+	case reflect.Slice:
+		arr := foundations.ArrayWithCapacity(v.Len())
+		for i := range v.Len() {
+			item, err := toValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			arr.Push(item)
+		}
+		return arr, nil
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		dict := foundations.NewDict()
+		for _, key := range keys {
+			item, err := toValue(v.MapIndex(reflect.ValueOf(key).Convert(t.Key())))
+			if err != nil {
+				return nil, err
+			}
+			dict.Set(key, item)
+		}
+		return dict, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to a value", t)
+}
@@ -0,0 +1,170 @@
+package eval
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// callRegistered calls the function bound to name in the scope.
+func callRegistered(t *testing.T, scope *foundations.Scope, name string, args *foundations.Args) (foundations.Value, error) {
+	t.Helper()
+	binding := scope.Get(name)
+	if binding == nil {
+		t.Fatalf("%s is not defined", name)
+	}
+	fv, ok := binding.Read().(foundations.FuncValue)
+	if !ok {
+		t.Fatalf("%s is %T, not a function", name, binding.Read())
+	}
+	native := fv.Func.Repr.(foundations.NativeFunc)
+	return native.Func(foundations.Engine{}, foundations.Context{}, args)
+}
+
+func named(args *foundations.Args, name string, value foundations.Value) *foundations.Args {
+	key := foundations.Str(name)
+	args.Items = append(args.Items, foundations.Arg{
+		Name:  &key,
+		Value: syntax.Spanned[foundations.Value]{V: value},
+	})
+	return args
+}
+
+func TestRegisterNative(t *testing.T) {
+	scope := foundations.NewScope()
+	err := RegisterNative(scope, "lookup", func(id int, fields []string, upper bool) (map[string]string, error) {
+		if id < 0 {
+			return nil, errors.New("no such user")
+		}
+		out := map[string]string{}
+		for _, field := range fields {
+			value := field + "-of-user"
+			if upper {
+				value = strings.ToUpper(value)
+			}
+			out[field] = value
+		}
+		return out, nil
+	}, Signature{Params: []foundations.ParamInfo{
+		{Name: "id"},
+		{Name: "fields", Variadic: true},
+		{Name: "upper", Named: true, Default: foundations.Bool(false)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := scope.Get("lookup").Read().(foundations.FuncValue).Func.Repr.(foundations.NativeFunc).Info
+	if info.Params[0].Type != foundations.TypeInt || info.Params[1].Type != foundations.TypeArray {
+		t.Errorf("param types = %v, %v", info.Params[0].Type, info.Params[1].Type)
+	}
+
+	args := named(foundations.NewArgs(syntax.Detached(), foundations.Int(7), foundations.Str("name"), foundations.Str("mail")), "upper", foundations.Bool(true))
+	got, err := callRegistered(t, scope, "lookup", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict, ok := got.(*foundations.Dict)
+	if !ok {
+		t.Fatalf("result = %T, want dictionary", got)
+	}
+	keys, values := dict.Iter()
+	if len(keys) != 2 || keys[0] != "mail" || keys[1] != "name" || values[1] != foundations.Str("NAME-OF-USER") {
+		t.Errorf("result = %v, %v", keys, values)
+	}
+
+	// Errors of the Go function fail the call.
+	_, err = callRegistered(t, scope, "lookup", foundations.NewArgs(syntax.Detached(), foundations.Int(-1)))
+	if err == nil || err.Error() != "no such user" {
+		t.Errorf("error = %v, want no such user", err)
+	}
+
+	// Arguments of the wrong type are rejected.
+	_, err = callRegistered(t, scope, "lookup", foundations.NewArgs(syntax.Detached(), foundations.Str("7")))
+	var mismatch *foundations.TypeMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Expected != "integer" || mismatch.Field != "id" {
+		t.Errorf("error = %v, want a type mismatch for id", err)
+	}
+
+	// Missing required arguments are rejected.
+	if _, err := callRegistered(t, scope, "lookup", foundations.NewArgs(syntax.Detached())); err == nil {
+		t.Error("expected an error for a missing argument")
+	}
+}
+
+func TestRegisterNativeConversions(t *testing.T) {
+	scope := foundations.NewScope()
+	err := RegisterNative(scope, "scale", func(x float64, factor *int8, raw foundations.Value) []any {
+		f := 1.0
+		if factor != nil {
+			f = float64(*factor)
+		}
+		return []any{x * f, raw}
+	}, Signature{Params: []foundations.ParamInfo{
+		{Name: "x"},
+		{Name: "factor", Default: foundations.None},
+		{Name: "raw", Named: true, Default: foundations.None},
+	}})
+	if err == nil {
+		t.Fatal("expected []any to be rejected")
+	}
+
+	err = RegisterNative(scope, "scale", func(x float64, factor *int8) float64 {
+		if factor == nil {
+			return x
+		}
+		return x * float64(*factor)
+	}, Signature{Params: []foundations.ParamInfo{
+		{Name: "x"},
+		{Name: "factor", Default: foundations.None},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []foundations.Value
+		want foundations.Value
+		err  string
+	}{
+		{args: []foundations.Value{foundations.Int(3)}, want: foundations.Float(3)},
+		{args: []foundations.Value{foundations.Float(1.5), foundations.Int(4)}, want: foundations.Float(6)},
+		{args: []foundations.Value{foundations.Float(1), foundations.Int(300)}, err: "number too large: 300"},
+		{args: []foundations.Value{foundations.Float(1), foundations.Int(1), foundations.Int(2)}, err: "unexpected argument"},
+	}
+	for _, tt := range tests {
+		got, err := callRegistered(t, scope, "scale", foundations.NewArgs(syntax.Detached(), tt.args...))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("scale%v error = %v, want %q", tt.args, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("scale%v = %v, %v; want %v", tt.args, got, err, tt.want)
+		}
+	}
+}
+
+func TestRegisterNativeInvalid(t *testing.T) {
+	scope := foundations.NewScope()
+	tests := []struct {
+		name string
+		fn   any
+		sig  Signature
+	}{
+		{"not a function", 42, Signature{}},
+		{"arity", func(a, b int) {}, Signature{Params: []foundations.ParamInfo{{Name: "a"}}}},
+		{"variadic not last", func(a []int, b int) {}, Signature{Params: []foundations.ParamInfo{{Name: "a", Variadic: true}, {Name: "b"}}}},
+		{"unsupported param", func(c chan int) {}, Signature{Params: []foundations.ParamInfo{{Name: "c"}}}},
+		{"second result", func() (int, int) { return 0, 0 }, Signature{}},
+	}
+	for _, tt := range tests {
+		if err := RegisterNative(scope, "f", tt.fn, tt.sig); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -45,7 +45,7 @@ func Join(lhs, rhs foundations.Value) (foundations.Value, error) {
 			return foundations.Str(string(l) + string(r)), nil
 		}
 		if r, ok := rhs.(foundations.SymbolValue); ok {
-			return foundations.Str(string(l) + string(r.Char)), nil
+			return foundations.Str(string(l) + r.Get()), nil
 		}
 		if r, ok := rhs.(foundations.ContentValue); ok {
 			return foundations.ContentValue{Content: joinContent(strToContent(l), r.Content)}, nil
@@ -53,10 +53,10 @@ func Join(lhs, rhs foundations.Value) (foundations.Value, error) {
 
 	case foundations.SymbolValue:
 		if r, ok := rhs.(foundations.Str); ok {
-			return foundations.Str(string(l.Char) + string(r)), nil
+			return foundations.Str(l.Get() + string(r)), nil
 		}
 		if r, ok := rhs.(foundations.SymbolValue); ok {
-			return foundations.Str(string(l.Char) + string(r.Char)), nil
+			return foundations.Str(l.Get() + r.Get()), nil
 		}
 		if r, ok := rhs.(foundations.ContentValue); ok {
 			return foundations.ContentValue{Content: joinContent(symbolToContent(l), r.Content)}, nil
@@ -240,7 +240,7 @@ func Add(lhs, rhs foundations.Value) (foundations.Value, error) {
 		case foundations.Str:
 			return foundations.Str(string(l) + string(r)), nil
 		case foundations.SymbolValue:
-			return foundations.Str(string(l) + string(r.Char)), nil
+			return foundations.Str(string(l) + r.Get()), nil
 		case foundations.ContentValue:
 			return foundations.ContentValue{Content: joinContent(strToContent(l), r.Content)}, nil
 		}
@@ -248,9 +248,9 @@ func Add(lhs, rhs foundations.Value) (foundations.Value, error) {
 	case foundations.SymbolValue:
 		switch r := rhs.(type) {
 		case foundations.SymbolValue:
-			return foundations.Str(string(l.Char) + string(r.Char)), nil
+			return foundations.Str(l.Get() + r.Get()), nil
 		case foundations.Str:
-			return foundations.Str(string(l.Char) + string(r)), nil
+			return foundations.Str(l.Get() + string(r)), nil
 		case foundations.ContentValue:
 			return foundations.ContentValue{Content: joinContent(symbolToContent(l), r.Content)}, nil
 		}
@@ -1117,5 +1117,5 @@ func strToContent(s foundations.Str) foundations.Content {
 }
 
 func symbolToContent(s foundations.SymbolValue) foundations.Content {
-	return foundations.Content{Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: string(s.Char)}}}
+	return foundations.Content{Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: s.Get()}}}
 }
@@ -0,0 +1,108 @@
+// Evaluator routines for Typst.
+// Translated from typst/src/lib.rs (ROUTINES)
+
+package eval
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/math"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Routines implements the callbacks through which lower layers, like
+// realization, call back into the evaluator. Without them, closures
+// cannot be called from outside of the evaluator. They are the evaluator's
+// part of foundations.Routines.
+type Routines struct{}
+
+// EvalClosure calls a closure with the given context. This is how the body
+// of a context expression is evaluated once its styles and location are
+// known.
+// Matches Rust: fn eval_closure in typst-eval/src/call.rs
+func (Routines) EvalClosure(engine *foundations.Engine, context *foundations.Context, fn *foundations.Func, closure *foundations.Closure, args *foundations.Args) (foundations.Value, error) {
+	var library *foundations.Scope
+	if engine.World != nil {
+		library = engine.World.Library()
+	}
+	vm := NewVm(engine, context, foundations.NewScopes(library), fn.Span)
+	return callClosure(vm, fn, closure, args)
+}
+
+// EvalString evaluates a string as code, markup, or math.
+func (Routines) EvalString(engine *foundations.Engine, text string, span syntax.Span, mode syntax.SyntaxMode, scope *foundations.Scope) (foundations.Value, error) {
+	return EvalString(engine, text, span, mode, scope)
+}
+
+// EvalSource evaluates a source file into a module. Errors in the file
+// are reported where they occurred.
+// Matches Rust: typst_eval::eval as called by typst/src/lib.rs
+func (Routines) EvalSource(engine *foundations.Engine, source *syntax.Source) (*foundations.Module, error) {
+	return EvalSource(engine, source, source.Root().Span())
+}
+
+// NewEngine creates an engine for the given world that can only evaluate:
+// its routines for realization and layout fail. The compiler combines the
+// routines of all subsystems instead.
+func NewEngine(world foundations.World) *foundations.Engine {
+	return foundations.NewEngine(world, foundations.RoutineSet{Eval: Routines{}})
+}
+
+// EvalString evaluates a string as code, markup, or math. All nodes of the
+// parsed string are given the span, so that errors point to where the
+// string came from. The bindings of scope are available to the string in
+// addition to the standard library.
+//
+// Matches Rust: pub fn eval_string in typst-eval/src/lib.rs
+func EvalString(engine *foundations.Engine, text string, span syntax.Span, mode syntax.SyntaxMode, scope *foundations.Scope) (foundations.Value, error) {
+	var root *syntax.SyntaxNode
+	switch mode {
+	case syntax.ModeMarkup:
+		root = syntax.Parse(text)
+	case syntax.ModeMath:
+		root = syntax.ParseMath(text)
+	default:
+		root = syntax.ParseCode(text)
+	}
+	root.Synthesize(span)
+
+	// Check for well-formedness.
+	if errs := root.Errors(); len(errs) > 0 {
+		return nil, foundations.SyntaxErrors(errs)
+	}
+
+	var library *foundations.Scope
+	if engine.World != nil {
+		library = engine.World.Library()
+	}
+	scopes := foundations.NewScopes(library)
+	if scope != nil {
+		scopes.SetTop(scope)
+		scopes.Enter()
+	}
+	vm := NewVm(engine, foundations.NewContext(), scopes, root.Span())
+
+	var output foundations.Value
+	var err error
+	switch mode {
+	case syntax.ModeMarkup:
+		output, err = evalMarkup(vm, syntax.MarkupNodeFromNode(root))
+	case syntax.ModeMath:
+		output, err = evalMath(vm, syntax.MathNodeFromNode(root))
+		if content, ok := output.(foundations.ContentValue); ok && err == nil {
+			output = foundations.ContentValue{Content: foundations.Content{
+				Elements: []foundations.ContentElement{&math.EquationElem{Body: content.Content}},
+			}}
+		}
+	default:
+		output, err = evalCode(vm, syntax.CodeNodeFromNode(root).Exprs())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle control flow.
+	if vm.Flow != nil {
+		return nil, vm.Flow.Forbidden()
+	}
+	return output, nil
+}
@@ -203,8 +203,11 @@ func castToShowableSelector(val foundations.Value, expr syntax.Expr) (*foundatio
 			Selector: foundations.ElemSelector{Element: foundations.Element{Name: v.Inner.String()}},
 		}, nil
 
+	case foundations.SelectorValue:
+		return &foundations.ShowableSelector{Selector: v.Selector}, nil
+
 	default:
-		return nil, atSpan(fmt.Errorf("expected selector (function, label, string, or type), found %s", val.Type()), span)
+		return nil, atSpan(fmt.Errorf("expected selector (function, label, string, type, or selector), found %s", val.Type()), span)
 	}
 }
 
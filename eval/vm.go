@@ -138,38 +138,40 @@ func (vm *Vm) TakeFlow() FlowEvent {
 	return flow
 }
 
-// MaxCallDepth is the maximum call stack depth before a stack overflow error.
-const MaxCallDepth = 64
-
-// callDepth tracks the current call stack depth (stored in Engine for sharing).
-// For now we track it locally in a package variable since Engine doesn't have it yet.
-var callDepth int
-
-// CheckCallDepth checks if we've exceeded the maximum call depth.
-// Returns an error if the call stack is too deep.
+// CheckCallDepth checks whether another function call would exceed the
+// maximum call depth of the engine's budget.
 func (vm *Vm) CheckCallDepth() error {
-	if callDepth >= MaxCallDepth {
-		return &CallStackOverflowError{}
+	if vm.Engine.Route == nil {
+		return nil
 	}
-	return nil
+	return vm.Engine.Budget.CheckCallDepth(syntax.Detached(), vm.Engine.Route.Len())
 }
 
-// EnterCall increments the call depth counter.
+// EnterCall increments the call depth. The depth is tracked on the engine's
+// route, so that it is shared by the VMs of nested calls.
 func (vm *Vm) EnterCall() {
-	callDepth++
+	if vm.Engine.Route != nil {
+		vm.Engine.Route.Increase()
+	}
 }
 
-// ExitCall decrements the call depth counter.
+// ExitCall decrements the call depth.
 func (vm *Vm) ExitCall() {
-	if callDepth > 0 {
-		callDepth--
+	if vm.Engine.Route != nil {
+		vm.Engine.Route.Decrease()
 	}
 }
 
-// CallStackOverflowError is returned when the call stack is too deep.
-type CallStackOverflowError struct{}
-
-func (e *CallStackOverflowError) Error() string {
-	return "maximum function call depth exceeded"
+// Step consumes one loop iteration from the engine's budget.
+func (vm *Vm) Step(span syntax.Span) error {
+	return vm.Engine.Budget.Step(span)
 }
 
+// CheckSize ensures that a value produced by joining or an operation is
+// within the content size limit of the engine's budget.
+func (vm *Vm) CheckSize(span syntax.Span, value foundations.Value) error {
+	if content, ok := value.(foundations.ContentValue); ok {
+		return vm.Engine.Budget.CheckContentSize(span, len(content.Content.Elements))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package font
+
+import "sort"
+
+// Coverage is the set of characters a font has glyphs for, stored as the
+// lengths of alternating runs of uncovered and covered code points,
+// starting with an uncovered run at zero.
+// Matches Rust: typst::text::Coverage
+type Coverage []uint32
+
+// NewCoverage creates the coverage of a set of characters.
+func NewCoverage(runes []rune) Coverage {
+	sorted := append([]rune(nil), runes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var runs Coverage
+	var next uint32
+	for _, r := range sorted {
+		c := uint32(r)
+		if c < next {
+			continue // duplicate
+		}
+		if len(runs) > 0 && c == next {
+			runs[len(runs)-1]++
+		} else {
+			runs = append(runs, c-next, 1)
+		}
+		next = c + 1
+	}
+	return runs
+}
+
+// Contains reports whether the character is covered.
+func (c Coverage) Contains(r rune) bool {
+	if r < 0 {
+		return false
+	}
+	target := uint32(r)
+	var cursor uint32
+	for i, run := range c {
+		if target < cursor+run {
+			return i%2 == 1
+		}
+		cursor += run
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package font
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	coverage := NewCoverage([]rune{'c', 'a', 'b', 'x', 'b'})
+	if want := (Coverage{'a', 3, 'x' - 'd', 1}); !reflect.DeepEqual(coverage, want) {
+		t.Errorf("coverage = %v, want %v", coverage, want)
+	}
+	for _, r := range "abcx" {
+		if !coverage.Contains(r) {
+			t.Errorf("%q is not covered", r)
+		}
+	}
+	for _, r := range "\x00`dwyé" {
+		if coverage.Contains(r) {
+			t.Errorf("%q is covered", r)
+		}
+	}
+	if NewCoverage(nil).Contains('a') {
+		t.Error("empty coverage contains 'a'")
+	}
+}
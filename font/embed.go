@@ -6,7 +6,9 @@ import (
 )
 
 // EmbeddedFonts provides access to bundled fallback fonts.
-// To use, set this to an embed.FS containing font files.
+// Building with the embed_fonts tag sets it to the default fonts in the
+// assets directory. Otherwise, set this to an embed.FS containing font
+// files.
 //
 // Example usage in your main package:
 //
@@ -93,6 +95,13 @@ func DefaultFallbackFamilies() []string {
 		"Courier New",
 		"Courier",
 		"monospace",
+
+		// Emoji fallbacks
+		"Noto Color Emoji",
+		"Apple Color Emoji",
+		"Segoe UI Emoji",
+		"Twemoji Mozilla",
+		"emoji",
 	}
 }
 
@@ -124,6 +133,13 @@ var GenericFamilyMapping = map[string][]string{
 		"Apple Chancery",
 		"cursive",
 	},
+	"emoji": {
+		"Noto Color Emoji",
+		"Apple Color Emoji",
+		"Segoe UI Emoji",
+		"Twemoji Mozilla",
+		"Noto Emoji",
+	},
 	"fantasy": {
 		"Impact",
 		"Papyrus",
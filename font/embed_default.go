@@ -0,0 +1,15 @@
+//go:build embed_fonts
+
+package font
+
+import "embed"
+
+// defaultFonts are the default fonts of Typst, embedded with the
+// embed_fonts build tag. See assets/README.md for the files to provide.
+//
+//go:embed assets
+var defaultFonts embed.FS
+
+func init() {
+	EmbeddedFonts = &defaultFonts
+}
@@ -1,13 +1,16 @@
 // Package font provides font loading, discovery, and management for gotypst.
 //
 // This package handles:
-//   - Loading fonts from TTF/OTF/TTC files
+//   - Loading fonts from TTF/OTF/TTC/WOFF files
 //   - Discovering fonts from system directories
 //   - Managing a collection of fonts (FontBook)
 //   - Font matching by family, weight, style, and stretch
 package font
 
 import (
+	"os"
+	"sync"
+
 	"github.com/go-text/typesetting/font"
 )
 
@@ -31,6 +34,38 @@ type Font struct {
 	// RawData stores the original font file bytes for subsetting.
 	// This is nil for TTC fonts where the data is shared.
 	RawData []byte
+
+	// lazy is set for fonts created from cached metadata, whose file is
+	// only read once the face or the data are needed.
+	lazy bool
+	once sync.Once
+}
+
+// NewLazyFont creates a font from known metadata, like that of a font
+// cache. The file at path is only read and parsed when the face or the raw
+// data of the font are first needed.
+func NewLazyFont(info FontInfo, path string, index int) *Font {
+	return &Font{Info: info, Path: path, Index: index, lazy: true}
+}
+
+// load reads and parses the file of a lazy font. If that fails, the font
+// stays without face and data.
+func (f *Font) load() {
+	if !f.lazy {
+		return
+	}
+	f.once.Do(func() {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			return
+		}
+		loaded, err := LoadFace(data, f.Path, f.Index)
+		if err != nil {
+			return
+		}
+		f.face = loaded.face
+		f.RawData = loaded.RawData
+	})
 }
 
 // Family returns the font family name.
@@ -54,6 +89,7 @@ func (f *Font) Weight() int {
 // Face returns the underlying font face for text shaping.
 // Implements gotypst.Font.
 func (f *Font) Face() *font.Face {
+	f.load()
 	return f.face
 }
 
@@ -76,6 +112,9 @@ type FontInfo struct {
 
 	// Stretch is the font stretch/width.
 	Stretch Stretch
+
+	// Coverage is the set of characters the font has glyphs for.
+	Coverage Coverage
 }
 
 // Style represents font style.
@@ -221,6 +260,7 @@ func BoldItalicVariant() Variant {
 
 // CanSubset returns true if the font has raw data available for subsetting.
 func (f *Font) CanSubset() bool {
+	f.load()
 	return len(f.RawData) > 0
 }
 
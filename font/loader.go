@@ -2,6 +2,7 @@ package font
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
 )
 
 // LoadFromFile loads fonts from a file path.
@@ -24,11 +26,18 @@ func LoadFromFile(path string) ([]*Font, error) {
 
 // LoadFromBytes loads fonts from raw bytes.
 // The path parameter is used for metadata (can be empty for embedded fonts).
+// Returns multiple fonts for font collections. WOFF and WOFF2 fonts are
+// decompressed into the TTF, OTF, or collection they wrap.
 func LoadFromBytes(data []byte, path string) ([]*Font, error) {
 	if len(data) < 4 {
 		return nil, errors.New("font data too short")
 	}
 
+	data, err := unwrapWebFont(data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if it's a font collection (TTC)
 	if isTTC(data) {
 		return loadTTC(data, path)
@@ -38,6 +47,84 @@ func LoadFromBytes(data []byte, path string) ([]*Font, error) {
 	return loadSingle(data, path, 0)
 }
 
+// LoadFace loads the face with the given index from raw bytes. The index
+// selects a face of a font collection and must be zero for other fonts.
+func LoadFace(data []byte, path string, index int) (*Font, error) {
+	data, err := unwrapWebFont(data)
+	if err != nil {
+		return nil, err
+	}
+	if isTTC(data) {
+		// The loaders only read the table directories, so that just the
+		// selected face is parsed.
+		loaders, err := ot.NewLoaders(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse TTC: %w", err)
+		}
+		if index < 0 || index >= len(loaders) {
+			return nil, fmt.Errorf("font index %d out of range for collection of %d fonts", index, len(loaders))
+		}
+		parsed, err := font.NewFont(loaders[index])
+		if err != nil {
+			return nil, fmt.Errorf("parse font %d of collection: %w", index, err)
+		}
+		face := font.NewFace(parsed)
+		return &Font{
+			face:    face,
+			Info:    extractInfo(face),
+			Path:    path,
+			Index:   index,
+			RawData: data,
+		}, nil
+	}
+
+	if index != 0 {
+		return nil, fmt.Errorf("font index %d out of range for a single font", index)
+	}
+	fonts, err := LoadFromBytes(data, path)
+	if err != nil {
+		return nil, err
+	}
+	return fonts[0], nil
+}
+
+// unwrapWebFont decompresses WOFF and WOFF2 fonts and returns other fonts
+// as they are.
+func unwrapWebFont(data []byte) ([]byte, error) {
+	switch {
+	case isWOFF2(data):
+		decoded, err := decodeWOFF2(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode WOFF2: %w", err)
+		}
+		return decoded, nil
+	case isWOFF(data):
+		decoded, err := decodeWOFF(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode WOFF: %w", err)
+		}
+		return decoded, nil
+	default:
+		return data, nil
+	}
+}
+
+// faceOffset returns the offset of the table directory of the face with
+// the given index. For fonts that are not collections, it is zero.
+func faceOffset(data []byte, index int) (int, error) {
+	if !isTTC(data) {
+		return 0, nil
+	}
+	if len(data) < 12 {
+		return 0, errors.New("truncated collection header")
+	}
+	count := int(binary.BigEndian.Uint32(data[8:]))
+	if index < 0 || index >= count || 12+4*(index+1) > len(data) {
+		return 0, errors.New("font index out of range")
+	}
+	return int(binary.BigEndian.Uint32(data[12+4*index:])), nil
+}
+
 // isTTC checks if the data starts with a TTC header.
 func isTTC(data []byte) bool {
 	return len(data) >= 4 && string(data[:4]) == "ttcf"
@@ -133,14 +220,29 @@ func extractInfo(face *font.Face) FontInfo {
 		info.Stretch = StretchNormal
 	}
 
+	info.Coverage = cmapCoverage(face.Font.Cmap)
+
 	return info
 }
 
+// cmapCoverage returns the coverage of a font's character map.
+func cmapCoverage(cmap font.Cmap) Coverage {
+	if cmap == nil {
+		return nil
+	}
+	var runes []rune
+	for it := cmap.Iter(); it.Next(); {
+		r, _ := it.Char()
+		runes = append(runes, r)
+	}
+	return NewCoverage(runes)
+}
+
 // IsFontFile checks if a path points to a supported font file.
 func IsFontFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
-	case ".ttf", ".otf", ".ttc", ".otc":
+	case ".ttf", ".otf", ".ttc", ".otc", ".woff", ".woff2":
 		return true
 	default:
 		return false
@@ -0,0 +1,479 @@
+package font
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/go-text/typesetting/font"
+)
+
+// MathConstants holds the global constants of an OpenType MATH table.
+//
+// Distances are in font units and must be divided by UnitsPerEm to obtain
+// em values. Percentages are stored as given in the font (e.g. 70 for 70%).
+//
+// Reference: https://learn.microsoft.com/en-us/typography/opentype/spec/math#mathconstants-table
+type MathConstants struct {
+	UnitsPerEm uint16
+
+	ScriptPercentScaleDown       int16
+	ScriptScriptPercentScaleDown int16
+	DelimitedSubFormulaMinHeight uint16
+	DisplayOperatorMinHeight     uint16
+
+	MathLeading                              int16
+	AxisHeight                               int16
+	AccentBaseHeight                         int16
+	FlattenedAccentBaseHeight                int16
+	SubscriptShiftDown                       int16
+	SubscriptTopMax                          int16
+	SubscriptBaselineDropMin                 int16
+	SuperscriptShiftUp                       int16
+	SuperscriptShiftUpCramped                int16
+	SuperscriptBottomMin                     int16
+	SuperscriptBaselineDropMax               int16
+	SubSuperscriptGapMin                     int16
+	SuperscriptBottomMaxWithSubscript        int16
+	SpaceAfterScript                         int16
+	UpperLimitGapMin                         int16
+	UpperLimitBaselineRiseMin                int16
+	LowerLimitGapMin                         int16
+	LowerLimitBaselineDropMin                int16
+	StackTopShiftUp                          int16
+	StackTopDisplayStyleShiftUp              int16
+	StackBottomShiftDown                     int16
+	StackBottomDisplayStyleShiftDown         int16
+	StackGapMin                              int16
+	StackDisplayStyleGapMin                  int16
+	StretchStackTopShiftUp                   int16
+	StretchStackBottomShiftDown              int16
+	StretchStackGapAboveMin                  int16
+	StretchStackGapBelowMin                  int16
+	FractionNumeratorShiftUp                 int16
+	FractionNumeratorDisplayStyleShiftUp     int16
+	FractionDenominatorShiftDown             int16
+	FractionDenominatorDisplayStyleShiftDown int16
+	FractionNumeratorGapMin                  int16
+	FractionNumDisplayStyleGapMin            int16
+	FractionRuleThickness                    int16
+	FractionDenominatorGapMin                int16
+	FractionDenomDisplayStyleGapMin          int16
+	SkewedFractionHorizontalGap              int16
+	SkewedFractionVerticalGap                int16
+	OverbarVerticalGap                       int16
+	OverbarRuleThickness                     int16
+	OverbarExtraAscender                     int16
+	UnderbarVerticalGap                      int16
+	UnderbarRuleThickness                    int16
+	UnderbarExtraDescender                   int16
+	RadicalVerticalGap                       int16
+	RadicalDisplayStyleVerticalGap           int16
+	RadicalRuleThickness                     int16
+	RadicalExtraAscender                     int16
+	RadicalKernBeforeDegree                  int16
+	RadicalKernAfterDegree                   int16
+
+	RadicalDegreeBottomRaisePercent int16
+}
+
+// errInvalidMathTable is returned when a MATH table is malformed.
+var errInvalidMathTable = errors.New("invalid MATH table")
+
+// MathConstants returns the constants from the font's MATH table, or nil if
+// the font is not a math font.
+func (f *Font) MathConstants() *MathConstants {
+	f.load()
+	if len(f.RawData) == 0 || f.face == nil {
+		return nil
+	}
+	constants, err := parseMathConstants(f.RawData, f.Index)
+	if err != nil {
+		return nil
+	}
+	constants.UnitsPerEm = f.face.Upem()
+	return constants
+}
+
+// parseMathConstants locates the MATH table in the font (or the face with
+// the given index in a collection) and parses its constants subtable.
+func parseMathConstants(data []byte, index int) (*MathConstants, error) {
+	table, err := findTable(data, index, "MATH")
+	if err != nil {
+		return nil, err
+	}
+	// MATH header: majorVersion, minorVersion, mathConstantsOffset,
+	// mathGlyphInfoOffset, mathVariantsOffset.
+	if len(table) < 10 {
+		return nil, errInvalidMathTable
+	}
+	offset := int(binary.BigEndian.Uint16(table[4:]))
+	// The constants subtable consists of four 16-bit values, 51 value
+	// records of four bytes each, and one trailing 16-bit value.
+	const size = 4*2 + 51*4 + 2
+	if offset == 0 || offset+size > len(table) {
+		return nil, errInvalidMathTable
+	}
+	r := mathReader{data: table[offset : offset+size]}
+
+	c := &MathConstants{}
+	c.ScriptPercentScaleDown = r.int16()
+	c.ScriptScriptPercentScaleDown = r.int16()
+	c.DelimitedSubFormulaMinHeight = uint16(r.int16())
+	c.DisplayOperatorMinHeight = uint16(r.int16())
+	for _, field := range []*int16{
+		&c.MathLeading,
+		&c.AxisHeight,
+		&c.AccentBaseHeight,
+		&c.FlattenedAccentBaseHeight,
+		&c.SubscriptShiftDown,
+		&c.SubscriptTopMax,
+		&c.SubscriptBaselineDropMin,
+		&c.SuperscriptShiftUp,
+		&c.SuperscriptShiftUpCramped,
+		&c.SuperscriptBottomMin,
+		&c.SuperscriptBaselineDropMax,
+		&c.SubSuperscriptGapMin,
+		&c.SuperscriptBottomMaxWithSubscript,
+		&c.SpaceAfterScript,
+		&c.UpperLimitGapMin,
+		&c.UpperLimitBaselineRiseMin,
+		&c.LowerLimitGapMin,
+		&c.LowerLimitBaselineDropMin,
+		&c.StackTopShiftUp,
+		&c.StackTopDisplayStyleShiftUp,
+		&c.StackBottomShiftDown,
+		&c.StackBottomDisplayStyleShiftDown,
+		&c.StackGapMin,
+		&c.StackDisplayStyleGapMin,
+		&c.StretchStackTopShiftUp,
+		&c.StretchStackBottomShiftDown,
+		&c.StretchStackGapAboveMin,
+		&c.StretchStackGapBelowMin,
+		&c.FractionNumeratorShiftUp,
+		&c.FractionNumeratorDisplayStyleShiftUp,
+		&c.FractionDenominatorShiftDown,
+		&c.FractionDenominatorDisplayStyleShiftDown,
+		&c.FractionNumeratorGapMin,
+		&c.FractionNumDisplayStyleGapMin,
+		&c.FractionRuleThickness,
+		&c.FractionDenominatorGapMin,
+		&c.FractionDenomDisplayStyleGapMin,
+		&c.SkewedFractionHorizontalGap,
+		&c.SkewedFractionVerticalGap,
+		&c.OverbarVerticalGap,
+		&c.OverbarRuleThickness,
+		&c.OverbarExtraAscender,
+		&c.UnderbarVerticalGap,
+		&c.UnderbarRuleThickness,
+		&c.UnderbarExtraDescender,
+		&c.RadicalVerticalGap,
+		&c.RadicalDisplayStyleVerticalGap,
+		&c.RadicalRuleThickness,
+		&c.RadicalExtraAscender,
+		&c.RadicalKernBeforeDegree,
+		&c.RadicalKernAfterDegree,
+	} {
+		*field = r.valueRecord()
+	}
+	c.RadicalDegreeBottomRaisePercent = r.int16()
+
+	return c, nil
+}
+
+// findTable returns the bytes of the table with the given tag. For font
+// collections, index selects the face.
+func findTable(data []byte, index int, tag string) ([]byte, error) {
+	start, err := faceOffset(data, index)
+	if err != nil {
+		return nil, err
+	}
+	return findTableAt(data, start, tag)
+}
+
+// findTableAt looks up a table in the table directory starting at start.
+func findTableAt(data []byte, start int, tag string) ([]byte, error) {
+	if start+12 > len(data) {
+		return nil, errors.New("truncated font directory")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[start+4:]))
+	for i := 0; i < numTables; i++ {
+		rec := start + 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, errors.New("truncated font directory")
+		}
+		if string(data[rec:rec+4]) != tag {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if offset+length > len(data) {
+			return nil, errors.New("table out of bounds")
+		}
+		return data[offset : offset+length], nil
+	}
+	return nil, fmt.Errorf("font has no %s table", tag)
+}
+
+// mathReader reads big-endian values from a MATH constants subtable.
+type mathReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *mathReader) int16() int16 {
+	v := int16(binary.BigEndian.Uint16(r.data[r.pos:]))
+	r.pos += 2
+	return v
+}
+
+// valueRecord reads a MathValueRecord and returns its value, ignoring the
+// device table offset.
+func (r *mathReader) valueRecord() int16 {
+	v := r.int16()
+	r.pos += 2
+	return v
+}
+
+// GlyphVariant is a larger version of a glyph from the MATH variants table.
+type GlyphVariant struct {
+	// Glyph is the ID of the variant glyph.
+	Glyph uint16
+	// Advance is the glyph's advance in the direction of growth, in font
+	// units.
+	Advance uint16
+}
+
+// GlyphPart is one piece of a glyph assembly.
+type GlyphPart struct {
+	// Glyph is the ID of the part's glyph.
+	Glyph uint16
+	// StartConnector is the length of the connector at the start of the
+	// part, in font units.
+	StartConnector uint16
+	// EndConnector is the length of the connector at the end of the part.
+	EndConnector uint16
+	// FullAdvance is the full advance of the part.
+	FullAdvance uint16
+	// Extender indicates that the part can be repeated.
+	Extender bool
+}
+
+// GlyphConstruction lists the ways a glyph can grow: a sequence of
+// increasingly large variants and, optionally, an assembly of parts for
+// arbitrary sizes. Vertical assembly parts are ordered bottom to top,
+// horizontal ones left to right.
+type GlyphConstruction struct {
+	Variants []GlyphVariant
+	Assembly []GlyphPart
+}
+
+// MathVariants holds the glyph constructions of an OpenType MATH table.
+//
+// Reference: https://learn.microsoft.com/en-us/typography/opentype/spec/math#mathvariants-table
+type MathVariants struct {
+	UnitsPerEm uint16
+	// MinConnectorOverlap is the minimum overlap of connecting glyphs in
+	// an assembly, in font units.
+	MinConnectorOverlap uint16
+
+	vertical   map[uint16]*GlyphConstruction
+	horizontal map[uint16]*GlyphConstruction
+}
+
+// Vertical returns the construction for growing the glyph vertically, or
+// nil if there is none.
+func (v *MathVariants) Vertical(glyph uint16) *GlyphConstruction {
+	if v == nil {
+		return nil
+	}
+	return v.vertical[glyph]
+}
+
+// Horizontal returns the construction for growing the glyph horizontally,
+// or nil if there is none.
+func (v *MathVariants) Horizontal(glyph uint16) *GlyphConstruction {
+	if v == nil {
+		return nil
+	}
+	return v.horizontal[glyph]
+}
+
+// MathVariants returns the glyph constructions from the font's MATH table,
+// or nil if the font has none.
+func (f *Font) MathVariants() *MathVariants {
+	f.load()
+	if len(f.RawData) == 0 || f.face == nil {
+		return nil
+	}
+	variants, err := parseMathVariants(f.RawData, f.Index)
+	if err != nil {
+		return nil
+	}
+	variants.UnitsPerEm = f.face.Upem()
+	return variants
+}
+
+// GlyphIndex returns the ID of the glyph the font maps r to.
+func (f *Font) GlyphIndex(r rune) (uint16, bool) {
+	f.load()
+	if f.face == nil {
+		return 0, false
+	}
+	gid, ok := f.face.NominalGlyph(r)
+	return uint16(gid), ok
+}
+
+// GlyphAdvance returns the horizontal advance of a glyph in font units.
+func (f *Font) GlyphAdvance(glyph uint16) float64 {
+	f.load()
+	if f.face == nil {
+		return 0
+	}
+	return float64(f.face.HorizontalAdvance(font.GID(glyph)))
+}
+
+// parseMathVariants parses the MathVariants subtable of the MATH table.
+func parseMathVariants(data []byte, index int) (*MathVariants, error) {
+	table, err := findTable(data, index, "MATH")
+	if err != nil {
+		return nil, err
+	}
+	if len(table) < 10 {
+		return nil, errInvalidMathTable
+	}
+	offset := int(binary.BigEndian.Uint16(table[8:]))
+	if offset == 0 || offset+10 > len(table) {
+		return nil, errInvalidMathTable
+	}
+	sub := table[offset:]
+
+	v := &MathVariants{
+		MinConnectorOverlap: binary.BigEndian.Uint16(sub[0:]),
+	}
+	vertCoverage := int(binary.BigEndian.Uint16(sub[2:]))
+	horizCoverage := int(binary.BigEndian.Uint16(sub[4:]))
+	vertCount := int(binary.BigEndian.Uint16(sub[6:]))
+	horizCount := int(binary.BigEndian.Uint16(sub[8:]))
+	if 10+2*(vertCount+horizCount) > len(sub) {
+		return nil, errInvalidMathTable
+	}
+
+	v.vertical, err = parseConstructions(sub, vertCoverage, sub[10:10+2*vertCount])
+	if err != nil {
+		return nil, err
+	}
+	v.horizontal, err = parseConstructions(sub, horizCoverage, sub[10+2*vertCount:10+2*(vertCount+horizCount)])
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// parseConstructions parses the glyph constructions whose offsets (relative
+// to sub) are listed in offsets, keyed by the glyphs of the coverage table.
+func parseConstructions(sub []byte, coverageOffset int, offsets []byte) (map[uint16]*GlyphConstruction, error) {
+	count := len(offsets) / 2
+	if count == 0 {
+		return nil, nil
+	}
+	glyphs, err := parseCoverage(sub, coverageOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	constructions := make(map[uint16]*GlyphConstruction, count)
+	for i, glyph := range glyphs {
+		if i >= count {
+			break
+		}
+		offset := int(binary.BigEndian.Uint16(offsets[2*i:]))
+		c, err := parseConstruction(sub, offset)
+		if err != nil {
+			return nil, err
+		}
+		constructions[glyph] = c
+	}
+	return constructions, nil
+}
+
+// parseConstruction parses a MathGlyphConstruction table.
+func parseConstruction(sub []byte, offset int) (*GlyphConstruction, error) {
+	if offset+4 > len(sub) {
+		return nil, errInvalidMathTable
+	}
+	assemblyOffset := int(binary.BigEndian.Uint16(sub[offset:]))
+	variantCount := int(binary.BigEndian.Uint16(sub[offset+2:]))
+	if offset+4+4*variantCount > len(sub) {
+		return nil, errInvalidMathTable
+	}
+
+	c := &GlyphConstruction{}
+	for i := 0; i < variantCount; i++ {
+		rec := sub[offset+4+4*i:]
+		c.Variants = append(c.Variants, GlyphVariant{
+			Glyph:   binary.BigEndian.Uint16(rec[0:]),
+			Advance: binary.BigEndian.Uint16(rec[2:]),
+		})
+	}
+
+	if assemblyOffset != 0 {
+		// GlyphAssembly: italicsCorrection (value record), partCount, parts.
+		start := offset + assemblyOffset
+		if start+6 > len(sub) {
+			return nil, errInvalidMathTable
+		}
+		partCount := int(binary.BigEndian.Uint16(sub[start+4:]))
+		if start+6+10*partCount > len(sub) {
+			return nil, errInvalidMathTable
+		}
+		for i := 0; i < partCount; i++ {
+			rec := sub[start+6+10*i:]
+			c.Assembly = append(c.Assembly, GlyphPart{
+				Glyph:          binary.BigEndian.Uint16(rec[0:]),
+				StartConnector: binary.BigEndian.Uint16(rec[2:]),
+				EndConnector:   binary.BigEndian.Uint16(rec[4:]),
+				FullAdvance:    binary.BigEndian.Uint16(rec[6:]),
+				Extender:       binary.BigEndian.Uint16(rec[8:])&1 != 0,
+			})
+		}
+	}
+	return c, nil
+}
+
+// parseCoverage returns the glyphs of an OpenType coverage table in
+// coverage index order.
+func parseCoverage(sub []byte, offset int) ([]uint16, error) {
+	if offset+4 > len(sub) {
+		return nil, errInvalidMathTable
+	}
+	format := binary.BigEndian.Uint16(sub[offset:])
+	count := int(binary.BigEndian.Uint16(sub[offset+2:]))
+	switch format {
+	case 1:
+		if offset+4+2*count > len(sub) {
+			return nil, errInvalidMathTable
+		}
+		glyphs := make([]uint16, count)
+		for i := range glyphs {
+			glyphs[i] = binary.BigEndian.Uint16(sub[offset+4+2*i:])
+		}
+		return glyphs, nil
+	case 2:
+		if offset+4+6*count > len(sub) {
+			return nil, errInvalidMathTable
+		}
+		var glyphs []uint16
+		for i := 0; i < count; i++ {
+			rec := sub[offset+4+6*i:]
+			start := binary.BigEndian.Uint16(rec[0:])
+			end := binary.BigEndian.Uint16(rec[2:])
+			for g := int(start); g <= int(end); g++ {
+				glyphs = append(glyphs, uint16(g))
+			}
+		}
+		return glyphs, nil
+	default:
+		return nil, errInvalidMathTable
+	}
+}
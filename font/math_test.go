@@ -0,0 +1,155 @@
+package font
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// buildMathFont builds a minimal sfnt with only a MATH table whose
+// constants are filled with sequential values.
+func buildMathFont() []byte {
+	const constantsSize = 4*2 + 51*4 + 2
+	math := make([]byte, 10+constantsSize)
+	binary.BigEndian.PutUint16(math[0:], 1)  // majorVersion
+	binary.BigEndian.PutUint16(math[4:], 10) // mathConstantsOffset
+	c := math[10:]
+	binary.BigEndian.PutUint16(c[0:], 70) // scriptPercentScaleDown
+	binary.BigEndian.PutUint16(c[2:], 50) // scriptScriptPercentScaleDown
+	binary.BigEndian.PutUint16(c[4:], 1300)
+	binary.BigEndian.PutUint16(c[6:], 1450)
+	for i := 0; i < 51; i++ {
+		binary.BigEndian.PutUint16(c[8+4*i:], uint16(100+i))
+	}
+	binary.BigEndian.PutUint16(c[8+51*4:], 60)
+
+	return wrapMathTable(math)
+}
+
+func TestParseMathConstants(t *testing.T) {
+	c, err := parseMathConstants(buildMathFont(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ScriptPercentScaleDown != 70 || c.ScriptScriptPercentScaleDown != 50 {
+		t.Errorf("unexpected script scale %d/%d", c.ScriptPercentScaleDown, c.ScriptScriptPercentScaleDown)
+	}
+	if c.DisplayOperatorMinHeight != 1450 {
+		t.Errorf("DisplayOperatorMinHeight = %d, want 1450", c.DisplayOperatorMinHeight)
+	}
+	if c.MathLeading != 100 || c.AxisHeight != 101 {
+		t.Errorf("unexpected leading/axis %d/%d", c.MathLeading, c.AxisHeight)
+	}
+	if c.UpperLimitGapMin != 114 || c.LowerLimitBaselineDropMin != 117 {
+		t.Errorf("unexpected limit gaps %d/%d", c.UpperLimitGapMin, c.LowerLimitBaselineDropMin)
+	}
+	if c.RadicalKernAfterDegree != 150 {
+		t.Errorf("RadicalKernAfterDegree = %d, want 150", c.RadicalKernAfterDegree)
+	}
+	if c.RadicalDegreeBottomRaisePercent != 60 {
+		t.Errorf("RadicalDegreeBottomRaisePercent = %d, want 60", c.RadicalDegreeBottomRaisePercent)
+	}
+}
+
+func TestParseMathConstantsMissingTable(t *testing.T) {
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint32(data[0:], 0x00010000)
+	if _, err := parseMathConstants(data, 0); err == nil {
+		t.Error("expected error for font without MATH table")
+	}
+}
+
+// wrapMathTable builds a minimal sfnt containing the given MATH table.
+func wrapMathTable(math []byte) []byte {
+	data := make([]byte, 12+16)
+	binary.BigEndian.PutUint32(data[0:], 0x00010000)
+	binary.BigEndian.PutUint16(data[4:], 1)
+	copy(data[12:], "MATH")
+	binary.BigEndian.PutUint32(data[12+8:], uint32(len(data)))
+	binary.BigEndian.PutUint32(data[12+12:], uint32(len(math)))
+	return append(data, math...)
+}
+
+// buildVariantsFont builds a MATH table whose variants subtable has a
+// vertical construction for glyph 5 with two variants and a three-part
+// assembly.
+func buildVariantsFont() []byte {
+	var b []byte
+	u16 := func(v uint16) { b = binary.BigEndian.AppendUint16(b, v) }
+
+	// MATH header; the variants subtable starts at offset 10.
+	u16(1)
+	u16(0)
+	u16(0)
+	u16(0)
+	u16(10)
+
+	// MathVariants: minConnectorOverlap, vertCoverage, horizCoverage,
+	// vertCount, horizCount, vertOffsets[1].
+	u16(20)
+	u16(12) // coverage at sub+12
+	u16(0)
+	u16(1)
+	u16(0)
+	u16(18) // construction at sub+18
+
+	// Coverage format 1 with glyph 5.
+	u16(1)
+	u16(1)
+	u16(5)
+
+	// MathGlyphConstruction: assembly offset, variant count, variants.
+	u16(12) // assembly at construction+12
+	u16(2)
+	u16(6)
+	u16(1200)
+	u16(7)
+	u16(1800)
+
+	// GlyphAssembly: italics correction, part count, parts.
+	u16(0)
+	u16(0)
+	u16(3)
+	for _, part := range [][5]uint16{
+		{8, 0, 100, 500, 0},
+		{9, 100, 100, 400, 1},
+		{10, 100, 0, 500, 0},
+	} {
+		for _, v := range part {
+			u16(v)
+		}
+	}
+
+	return wrapMathTable(b)
+}
+
+func TestParseMathVariants(t *testing.T) {
+	v, err := parseMathVariants(buildVariantsFont(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.MinConnectorOverlap != 20 {
+		t.Errorf("MinConnectorOverlap = %d, want 20", v.MinConnectorOverlap)
+	}
+	if v.Horizontal(5) != nil {
+		t.Error("expected no horizontal construction")
+	}
+	c := v.Vertical(5)
+	if c == nil {
+		t.Fatal("expected vertical construction for glyph 5")
+	}
+	if len(c.Variants) != 2 || c.Variants[1] != (GlyphVariant{Glyph: 7, Advance: 1800}) {
+		t.Errorf("unexpected variants %+v", c.Variants)
+	}
+	if len(c.Assembly) != 3 {
+		t.Fatalf("expected 3 assembly parts, got %d", len(c.Assembly))
+	}
+	if !c.Assembly[1].Extender || c.Assembly[0].Extender {
+		t.Errorf("unexpected extender flags %+v", c.Assembly)
+	}
+	if c.Assembly[2].Glyph != 10 || c.Assembly[2].StartConnector != 100 {
+		t.Errorf("unexpected top part %+v", c.Assembly[2])
+	}
+	if v.Vertical(6) != nil {
+		t.Error("expected no construction for uncovered glyph")
+	}
+}
@@ -0,0 +1,50 @@
+package font
+
+// SearchOptions configures where SearchFonts looks for fonts.
+type SearchOptions struct {
+	// FontPaths are additional directories to load fonts from.
+	FontPaths []string
+
+	// IgnoreSystemFonts skips the fonts installed on the system.
+	IgnoreSystemFonts bool
+
+	// IgnoreEmbeddedFonts skips the fonts embedded into the binary.
+	IgnoreEmbeddedFonts bool
+
+	// Discover finds the fonts in the font paths and the system font
+	// directories. If nil, DiscoverFonts is used. It can be replaced, for
+	// example to consult a cache of font metadata.
+	Discover func(dirs []string) ([]*Font, error)
+}
+
+// SearchFonts creates a FontBook with the fonts from the font paths, the
+// system, and the embedded fonts, in that order. When several fonts match
+// a family and variant equally well, the one added first is selected, so
+// fonts from the font paths take precedence over system fonts, which in
+// turn take precedence over the embedded ones.
+//
+// Matches Rust: typst_kit::fonts::FontSearcher::search_with
+func SearchFonts(opts SearchOptions) *FontBook {
+	book := NewFontBook()
+	discover := opts.Discover
+	if discover == nil {
+		discover = DiscoverFonts
+	}
+
+	if len(opts.FontPaths) > 0 {
+		fonts, _ := discover(opts.FontPaths)
+		book.Add(fonts...)
+	}
+
+	if !opts.IgnoreSystemFonts {
+		fonts, _ := discover(SystemFontDirs())
+		book.Add(fonts...)
+	}
+
+	if !opts.IgnoreEmbeddedFonts {
+		fonts, _ := LoadEmbeddedFonts()
+		book.Add(fonts...)
+	}
+
+	return book
+}
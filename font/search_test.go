@@ -0,0 +1,28 @@
+package font
+
+import "testing"
+
+func TestSearchFontsIgnore(t *testing.T) {
+	book := SearchFonts(SearchOptions{
+		FontPaths:           []string{t.TempDir()},
+		IgnoreSystemFonts:   true,
+		IgnoreEmbeddedFonts: true,
+	})
+	if book.Len() != 0 {
+		t.Errorf("got %d fonts, want none", book.Len())
+	}
+}
+
+func TestFontBookPrecedence(t *testing.T) {
+	// Fonts are added in the order of precedence, so of two equally good
+	// matches the one added first is selected.
+	custom := &Font{Info: FontInfo{Family: "Libertinus Serif", Style: StyleNormal, Weight: WeightNormal}, Path: "custom.otf"}
+	system := &Font{Info: FontInfo{Family: "Libertinus Serif", Style: StyleNormal, Weight: WeightNormal}, Path: "system.otf"}
+	embedded := &Font{Info: FontInfo{Family: "Libertinus Serif", Style: StyleNormal, Weight: WeightNormal}}
+
+	book := NewFontBook()
+	book.Add(custom, system, embedded)
+	if got := book.Select([]string{"Libertinus Serif"}, NormalVariant()); got != custom {
+		t.Errorf("selected %q, want the font from the font path", got.Path)
+	}
+}
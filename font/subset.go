@@ -133,8 +133,17 @@ func (s *Subsetter) Subset(glyphSet *GlyphSet) (*SubsettedFont, error) {
 		return nil, errors.New("font data too short")
 	}
 
-	// Parse the font directory
-	tables, err := parseFontDirectory(s.data)
+	// Parse the font directory, which for font collections is the one
+	// of the font's face
+	index := 0
+	if s.font != nil {
+		index = s.font.Index
+	}
+	start, err := faceOffset(s.data, index)
+	if err != nil {
+		return nil, fmt.Errorf("parse font directory: %w", err)
+	}
+	tables, err := parseFontDirectoryAt(s.data, start)
 	if err != nil {
 		return nil, fmt.Errorf("parse font directory: %w", err)
 	}
@@ -177,7 +186,17 @@ type tableRecord struct {
 
 // parseFontDirectory parses the TrueType font directory.
 func parseFontDirectory(data []byte) (map[string]tableRecord, error) {
-	r := bytes.NewReader(data)
+	return parseFontDirectoryAt(data, 0)
+}
+
+// parseFontDirectoryAt parses the TrueType font directory starting at
+// start. The offsets of the tables are relative to the start of data, also
+// for the faces of font collections.
+func parseFontDirectoryAt(data []byte, start int) (map[string]tableRecord, error) {
+	if start < 0 || start > len(data) {
+		return nil, errors.New("font directory out of bounds")
+	}
+	r := bytes.NewReader(data[start:])
 
 	// Read offset table
 	var sfntVersion uint32
@@ -0,0 +1,90 @@
+package font
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// isWOFF checks if the data starts with a WOFF header.
+func isWOFF(data []byte) bool {
+	return len(data) >= 4 && string(data[:4]) == "wOFF"
+}
+
+// isWOFF2 checks if the data starts with a WOFF2 header.
+func isWOFF2(data []byte) bool {
+	return len(data) >= 4 && string(data[:4]) == "wOF2"
+}
+
+// decodeWOFF converts a WOFF font into the TTF or OTF font it wraps, by
+// decompressing its tables and writing them with a plain table directory.
+// The result can be parsed, subsetted, and embedded like any other font.
+func decodeWOFF(data []byte) ([]byte, error) {
+	const headerSize, entrySize = 44, 20
+	if len(data) < headerSize {
+		return nil, errors.New("truncated WOFF header")
+	}
+	flavor := binary.BigEndian.Uint32(data[4:])
+	numTables := int(binary.BigEndian.Uint16(data[12:]))
+	if headerSize+entrySize*numTables > len(data) {
+		return nil, errors.New("truncated WOFF table directory")
+	}
+
+	// The sfnt header and table directory, followed by the tables, each
+	// padded to four bytes.
+	dirSize := 12 + 16*numTables
+	out := make([]byte, dirSize)
+	putSfntHeader(out, flavor, numTables)
+
+	for i := 0; i < numTables; i++ {
+		entry := data[headerSize+entrySize*i:]
+		offset := int(binary.BigEndian.Uint32(entry[4:]))
+		compLength := int(binary.BigEndian.Uint32(entry[8:]))
+		origLength := int(binary.BigEndian.Uint32(entry[12:]))
+		if offset+compLength > len(data) || compLength > origLength {
+			return nil, fmt.Errorf("WOFF table %q out of bounds", entry[:4])
+		}
+
+		table := data[offset : offset+compLength]
+		if compLength < origLength {
+			r, err := zlib.NewReader(bytes.NewReader(table))
+			if err != nil {
+				return nil, fmt.Errorf("decompress WOFF table %q: %w", entry[:4], err)
+			}
+			table = make([]byte, origLength)
+			if _, err := io.ReadFull(r, table); err != nil {
+				return nil, fmt.Errorf("decompress WOFF table %q: %w", entry[:4], err)
+			}
+		}
+
+		record := out[12+16*i:]
+		copy(record[0:4], entry[:4])
+		binary.BigEndian.PutUint32(record[4:], binary.BigEndian.Uint32(entry[16:]))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[12:], uint32(origLength))
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+
+	return out, nil
+}
+
+// putSfntHeader writes the header of a table directory with the given
+// number of tables.
+func putSfntHeader(b []byte, flavor uint32, numTables int) {
+	binary.BigEndian.PutUint32(b[0:], flavor)
+	binary.BigEndian.PutUint16(b[4:], uint16(numTables))
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= numTables {
+		searchRange *= 2
+		entrySelector++
+	}
+	binary.BigEndian.PutUint16(b[6:], uint16(searchRange*16))
+	binary.BigEndian.PutUint16(b[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(b[10:], uint16(numTables*16-searchRange*16))
+}
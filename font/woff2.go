@@ -0,0 +1,645 @@
+package font
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/andybalholm/brotli"
+)
+
+// errTruncatedWOFF2 is returned when a WOFF2 font or one of its streams
+// ends early.
+var errTruncatedWOFF2 = errors.New("truncated WOFF2 data")
+
+// woff2KnownTags are the tags that a WOFF2 table directory refers to by
+// index instead of spelling them out.
+var woff2KnownTags = [63]string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post",
+	"cvt ", "fpgm", "glyf", "loca", "prep", "CFF ", "VORG", "EBDT",
+	"EBLC", "gasp", "hdmx", "kern", "LTSH", "PCLT", "VDMX", "vhea",
+	"vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC", "JSTF", "MATH",
+	"CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar",
+	"gvar", "hsty", "just", "lcar", "mort", "morx", "opbd", "prop",
+	"trak", "Zapf", "Silf", "Glat", "Gloc", "Feat", "Sill",
+}
+
+// woff2Table is an entry of a WOFF2 table directory.
+type woff2Table struct {
+	tag string
+	// version is the transform version from the table's flags.
+	version byte
+	// origLength is the length of the table in the decoded font.
+	origLength uint32
+	// data is the table as stored, possibly transformed.
+	data []byte
+}
+
+// transformed reports whether the table is stored transformed. For glyf
+// and loca, version 3 is the null transform, for all others version 0.
+func (t *woff2Table) transformed() bool {
+	if t.tag == "glyf" || t.tag == "loca" {
+		return t.version != 3
+	}
+	return t.version != 0
+}
+
+// woff2Face is a font of a WOFF2 file, which is one of several for
+// collections.
+type woff2Face struct {
+	flavor uint32
+	// tables are indices into the table directory.
+	tables []int
+}
+
+// decodeWOFF2 converts a WOFF2 font into the TTF, OTF, or font collection
+// it wraps. It decompresses the tables, undoes the glyf, loca, and hmtx
+// transforms, and writes the tables with plain table directories.
+func decodeWOFF2(data []byte) ([]byte, error) {
+	const headerSize = 48
+	if len(data) < headerSize {
+		return nil, errors.New("truncated WOFF2 header")
+	}
+	flavor := binary.BigEndian.Uint32(data[4:])
+	numTables := int(binary.BigEndian.Uint16(data[12:]))
+	compressedSize := int(binary.BigEndian.Uint32(data[20:]))
+
+	r := &woff2Reader{data: data, pos: headerSize}
+	tables := make([]woff2Table, numTables)
+	lengths := make([]int, numTables)
+	streamSize := 0
+	for i := range tables {
+		t := &tables[i]
+		flags := r.u8()
+		if flags&0x3f == 0x3f {
+			t.tag = string(r.bytes(4))
+		} else {
+			t.tag = woff2KnownTags[flags&0x3f]
+		}
+		t.version = flags >> 6
+		t.origLength = r.base128()
+		lengths[i] = int(t.origLength)
+		if t.transformed() {
+			lengths[i] = int(r.base128())
+		}
+		streamSize += lengths[i]
+	}
+
+	var collectionVersion uint32
+	var faces []woff2Face
+	if flavor == 0x74746366 { // ttcf
+		collectionVersion = r.u32()
+		numFaces := int(r.uint255())
+		for i := 0; i < numFaces && r.err == nil; i++ {
+			face := woff2Face{tables: make([]int, r.uint255())}
+			face.flavor = r.u32()
+			for j := range face.tables {
+				face.tables[j] = int(r.uint255())
+				if face.tables[j] >= numTables {
+					return nil, fmt.Errorf("WOFF2 font %d refers to missing table %d", i, face.tables[j])
+				}
+			}
+			faces = append(faces, face)
+		}
+	} else {
+		face := woff2Face{flavor: flavor}
+		for i := range tables {
+			face.tables = append(face.tables, i)
+		}
+		faces = append(faces, face)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("WOFF2 table directory: %w", r.err)
+	}
+
+	// All tables are compressed together into a single stream, in the
+	// order of the table directory.
+	compressed := r.bytes(compressedSize)
+	if r.err != nil {
+		return nil, errors.New("truncated WOFF2 table data")
+	}
+	stream, err := io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(compressed)), int64(streamSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress WOFF2 tables: %w", err)
+	}
+	if len(stream) != streamSize {
+		return nil, fmt.Errorf("WOFF2 tables have %d bytes, want %d", len(stream), streamSize)
+	}
+	for i := range tables {
+		tables[i].data, stream = stream[:lengths[i]], stream[lengths[i]:]
+	}
+
+	decoded, err := untransformWOFF2(tables, faces)
+	if err != nil {
+		return nil, err
+	}
+	return writeWOFF2(tables, decoded, faces, collectionVersion), nil
+}
+
+// untransformWOFF2 returns the tables as they appear in the decoded font.
+func untransformWOFF2(tables []woff2Table, faces []woff2Face) ([][]byte, error) {
+	decoded := make([][]byte, len(tables))
+	for i, t := range tables {
+		if !t.transformed() {
+			decoded[i] = t.data
+		}
+	}
+
+	// A transformed loca table is empty: the glyf table of the same face
+	// holds everything needed to rebuild it. The minimum x coordinates of
+	// the glyphs are kept for rebuilding the hmtx table.
+	xMins := map[int][]int16{}
+	for _, face := range faces {
+		glyf, loca := tableIndex(tables, face, "glyf"), tableIndex(tables, face, "loca")
+		if glyf < 0 || decoded[glyf] != nil {
+			continue
+		}
+		if loca < 0 || !tables[loca].transformed() {
+			return nil, errors.New("WOFF2 glyf table is transformed without its loca table")
+		}
+		glyfData, locaData, mins, err := untransformGlyf(tables[glyf].data)
+		if err != nil {
+			return nil, fmt.Errorf("WOFF2 glyf table: %w", err)
+		}
+		if len(locaData) != int(tables[loca].origLength) {
+			return nil, fmt.Errorf("WOFF2 loca table has %d bytes, want %d", len(locaData), tables[loca].origLength)
+		}
+		decoded[glyf], decoded[loca] = glyfData, locaData
+		xMins[glyf] = mins
+	}
+
+	for _, face := range faces {
+		hmtx := tableIndex(tables, face, "hmtx")
+		if hmtx < 0 || decoded[hmtx] != nil || tables[hmtx].version != 1 {
+			continue
+		}
+		mins, ok := xMins[tableIndex(tables, face, "glyf")]
+		hhea := tableIndex(tables, face, "hhea")
+		if !ok || hhea < 0 || len(decoded[hhea]) < 36 {
+			return nil, errors.New("WOFF2 hmtx table is transformed without transformed glyf and hhea tables")
+		}
+		numHMetrics := int(binary.BigEndian.Uint16(decoded[hhea][34:]))
+		hmtxData, err := untransformHmtx(tables[hmtx].data, numHMetrics, mins)
+		if err != nil {
+			return nil, fmt.Errorf("WOFF2 hmtx table: %w", err)
+		}
+		decoded[hmtx] = hmtxData
+	}
+
+	for i, t := range tables {
+		if decoded[i] == nil {
+			return nil, fmt.Errorf("WOFF2 table %q has unsupported transform %d", t.tag, t.version)
+		}
+	}
+	return decoded, nil
+}
+
+// writeWOFF2 writes the decoded tables of a WOFF2 font with a table
+// directory for each face, and a collection header if there are several.
+// Tables shared between faces are written once.
+func writeWOFF2(tables []woff2Table, decoded [][]byte, faces []woff2Face, collectionVersion uint32) []byte {
+	collection := collectionVersion != 0
+	size := 0
+	if collection {
+		size = 12 + 4*len(faces)
+		if collectionVersion >= 0x00020000 {
+			// The unused DSIG fields.
+			size += 12
+		}
+	}
+	dirOffsets := make([]int, len(faces))
+	for i, face := range faces {
+		dirOffsets[i] = size
+		size += 12 + 16*len(face.tables)
+	}
+
+	out := make([]byte, size)
+	if collection {
+		copy(out, "ttcf")
+		binary.BigEndian.PutUint32(out[4:], collectionVersion)
+		binary.BigEndian.PutUint32(out[8:], uint32(len(faces)))
+		for i, offset := range dirOffsets {
+			binary.BigEndian.PutUint32(out[12+4*i:], uint32(offset))
+		}
+	} else if head := tableIndex(tables, faces[0], "head"); head >= 0 && len(decoded[head]) >= 12 {
+		// The checksum adjustment is computed once the font is written
+		// and must be zero until then.
+		clear(decoded[head][8:12])
+	}
+
+	offsets := make([]int, len(tables))
+	for i, table := range decoded {
+		offsets[i] = len(out)
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+
+	for i, face := range faces {
+		// Table records are sorted by tag.
+		order := slices.Clone(face.tables)
+		slices.SortFunc(order, func(a, b int) int {
+			return bytes.Compare([]byte(tables[a].tag), []byte(tables[b].tag))
+		})
+		dir := out[dirOffsets[i]:]
+		putSfntHeader(dir, face.flavor, len(order))
+		for j, index := range order {
+			record := dir[12+16*j:]
+			copy(record[0:4], tables[index].tag)
+			binary.BigEndian.PutUint32(record[4:], calculateChecksum(decoded[index]))
+			binary.BigEndian.PutUint32(record[8:], uint32(offsets[index]))
+			binary.BigEndian.PutUint32(record[12:], uint32(len(decoded[index])))
+		}
+	}
+
+	if !collection {
+		if head := tableIndex(tables, faces[0], "head"); head >= 0 && len(decoded[head]) >= 12 {
+			binary.BigEndian.PutUint32(out[offsets[head]+8:], 0xB1B0AFBA-calculateChecksum(out))
+		}
+	}
+	return out
+}
+
+// tableIndex returns the index of the face's table with the given tag, or
+// -1 if it has none.
+func tableIndex(tables []woff2Table, face woff2Face, tag string) int {
+	for _, i := range face.tables {
+		if tables[i].tag == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// Flags of the components of a composite glyph.
+const (
+	argsAreWords    = 0x0001
+	haveScale       = 0x0008
+	moreComponents  = 0x0020
+	haveXYScale     = 0x0040
+	haveTwoByTwo    = 0x0080
+	haveInstruction = 0x0100
+)
+
+// Flags of the points of a simple glyph.
+const (
+	onCurvePoint = 0x01
+	xShort       = 0x02
+	yShort       = 0x04
+	xSameOrPos   = 0x10
+	ySameOrPos   = 0x20
+	overlapFirst = 0x40
+)
+
+// untransformGlyf rebuilds the glyf and loca tables from a transformed glyf
+// table. It also returns the minimum x coordinate of each glyph, which is
+// its left side bearing.
+func untransformGlyf(data []byte) (glyf, loca []byte, xMins []int16, err error) {
+	r := &woff2Reader{data: data}
+	r.u16() // reserved
+	options := r.u16()
+	numGlyphs := int(r.u16())
+	indexFormat := r.u16()
+	var sizes [7]int
+	for i := range sizes {
+		sizes[i] = int(r.u32())
+	}
+	stream := func(size int) *woff2Reader {
+		return &woff2Reader{data: r.bytes(size)}
+	}
+	contours, points, flags := stream(sizes[0]), stream(sizes[1]), stream(sizes[2])
+	glyphs, composites, bboxes := stream(sizes[3]), stream(sizes[4]), stream(sizes[5])
+	instructions := stream(sizes[6])
+	var overlaps []byte
+	if options&1 != 0 {
+		overlaps = r.bytes((numGlyphs + 7) / 8)
+	}
+	bboxBitmap := bboxes.bytes(4 * ((numGlyphs + 31) / 32))
+	if r.err != nil || bboxes.err != nil {
+		return nil, nil, nil, errTruncatedWOFF2
+	}
+	if indexFormat > 1 {
+		return nil, nil, nil, fmt.Errorf("invalid index format %d", indexFormat)
+	}
+
+	locaOffset := func(offset int) error {
+		if indexFormat == 0 {
+			if offset/2 > 0xffff {
+				return errors.New("glyphs too large for short loca offsets")
+			}
+			loca = binary.BigEndian.AppendUint16(loca, uint16(offset/2))
+		} else {
+			loca = binary.BigEndian.AppendUint32(loca, uint32(offset))
+		}
+		return nil
+	}
+
+	xMins = make([]int16, numGlyphs)
+	for i := 0; i < numGlyphs; i++ {
+		if err := locaOffset(len(glyf)); err != nil {
+			return nil, nil, nil, err
+		}
+		numContours := int16(contours.u16())
+		hasBBox := bboxBitmap[i/8]&(0x80>>(i%8)) != 0
+		var bbox [4]int16
+		if hasBBox {
+			for j := range bbox {
+				bbox[j] = int16(bboxes.u16())
+			}
+		}
+
+		switch {
+		case numContours == 0:
+			if hasBBox {
+				return nil, nil, nil, fmt.Errorf("empty glyph %d has a bounding box", i)
+			}
+			continue
+
+		case numContours < 0:
+			if !hasBBox {
+				return nil, nil, nil, fmt.Errorf("composite glyph %d has no bounding box", i)
+			}
+			start := composites.pos
+			hasInstructions := false
+			for more := true; more; {
+				flags := composites.u16()
+				composites.u16() // glyph index
+				size := 2
+				if flags&argsAreWords != 0 {
+					size = 4
+				}
+				switch {
+				case flags&haveScale != 0:
+					size += 2
+				case flags&haveXYScale != 0:
+					size += 4
+				case flags&haveTwoByTwo != 0:
+					size += 8
+				}
+				composites.bytes(size)
+				hasInstructions = hasInstructions || flags&haveInstruction != 0
+				more = flags&moreComponents != 0
+			}
+			if composites.err != nil {
+				return nil, nil, nil, errTruncatedWOFF2
+			}
+			glyf = appendGlyphHeader(glyf, numContours, bbox)
+			glyf = append(glyf, composites.data[start:composites.pos]...)
+			if hasInstructions {
+				n := glyphs.uint255()
+				glyf = binary.BigEndian.AppendUint16(glyf, n)
+				glyf = append(glyf, instructions.bytes(int(n))...)
+			}
+
+		default:
+			var endPoints []byte
+			numPoints := 0
+			for j := 0; j < int(numContours); j++ {
+				numPoints += int(points.uint255())
+				endPoints = binary.BigEndian.AppendUint16(endPoints, uint16(numPoints-1))
+			}
+			if points.err != nil || numPoints > len(flags.data)-flags.pos {
+				return nil, nil, nil, errTruncatedWOFF2
+			}
+
+			// Each point is stored as a flag and a triplet of its delta
+			// to the previous point, and rewritten with the shortest
+			// glyf encoding of the delta.
+			var pointFlags, xs, ys []byte
+			x, y := 0, 0
+			minX, minY, maxX, maxY := 0, 0, 0, 0
+			for j, flag := range flags.bytes(numPoints) {
+				dx, dy := glyphs.triplet(flag)
+				x, y = x+dx, y+dy
+				if j == 0 {
+					minX, minY, maxX, maxY = x, y, x, y
+				}
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+
+				var f byte
+				if flag&0x80 == 0 {
+					f |= onCurvePoint
+				}
+				if j == 0 && overlaps != nil && overlaps[i/8]&(0x80>>(i%8)) != 0 {
+					f |= overlapFirst
+				}
+				f, xs = appendCoordinate(f, xs, dx, xShort, xSameOrPos)
+				f, ys = appendCoordinate(f, ys, dy, yShort, ySameOrPos)
+				pointFlags = append(pointFlags, f)
+			}
+			if !hasBBox {
+				bbox = [4]int16{int16(minX), int16(minY), int16(maxX), int16(maxY)}
+			}
+
+			n := glyphs.uint255()
+			glyf = appendGlyphHeader(glyf, numContours, bbox)
+			glyf = append(glyf, endPoints...)
+			glyf = binary.BigEndian.AppendUint16(glyf, n)
+			glyf = append(glyf, instructions.bytes(int(n))...)
+			glyf = append(glyf, pointFlags...)
+			glyf = append(glyf, xs...)
+			glyf = append(glyf, ys...)
+		}
+
+		xMins[i] = bbox[0]
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	if err := locaOffset(len(glyf)); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, s := range []*woff2Reader{contours, points, flags, glyphs, composites, bboxes, instructions} {
+		if s.err != nil {
+			return nil, nil, nil, s.err
+		}
+	}
+	return glyf, loca, xMins, nil
+}
+
+// appendGlyphHeader appends the contour count and bounding box of a glyph.
+func appendGlyphHeader(glyf []byte, numContours int16, bbox [4]int16) []byte {
+	glyf = binary.BigEndian.AppendUint16(glyf, uint16(numContours))
+	for _, v := range bbox {
+		glyf = binary.BigEndian.AppendUint16(glyf, uint16(v))
+	}
+	return glyf
+}
+
+// appendCoordinate appends the delta of a point's coordinate in the
+// shortest form and sets the flags describing it.
+func appendCoordinate(flag byte, coords []byte, delta int, short, sameOrPos byte) (byte, []byte) {
+	switch {
+	case delta == 0:
+		flag |= sameOrPos
+	case delta > -256 && delta < 256:
+		flag |= short
+		if delta > 0 {
+			flag |= sameOrPos
+		} else {
+			delta = -delta
+		}
+		coords = append(coords, byte(delta))
+	default:
+		coords = binary.BigEndian.AppendUint16(coords, uint16(int16(delta)))
+	}
+	return flag, coords
+}
+
+// untransformHmtx rebuilds an hmtx table, whose left side bearings were
+// dropped where they equal the minimum x coordinate of the glyph.
+func untransformHmtx(data []byte, numHMetrics int, xMins []int16) ([]byte, error) {
+	r := &woff2Reader{data: data}
+	flags := r.u8()
+	if flags&0x03 == 0 || flags&0xfc != 0 {
+		return nil, fmt.Errorf("invalid transform flags %#x", flags)
+	}
+	if numHMetrics < 1 || numHMetrics > len(xMins) {
+		return nil, fmt.Errorf("invalid number of metrics %d", numHMetrics)
+	}
+	advances := make([]uint16, numHMetrics)
+	for i := range advances {
+		advances[i] = r.u16()
+	}
+	bearings := slices.Clone(xMins)
+	for i := range bearings {
+		// Bit 0 drops the bearings of the glyphs with an advance, bit 1
+		// those of the glyphs without.
+		dropped := flags&0x01 != 0
+		if i >= numHMetrics {
+			dropped = flags&0x02 != 0
+		}
+		if !dropped {
+			bearings[i] = int16(r.u16())
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	out := make([]byte, 0, 2*numHMetrics+2*len(bearings))
+	for i, bearing := range bearings {
+		if i < numHMetrics {
+			out = binary.BigEndian.AppendUint16(out, advances[i])
+		}
+		out = binary.BigEndian.AppendUint16(out, uint16(bearing))
+	}
+	return out, nil
+}
+
+// woff2Reader reads big-endian values from a WOFF2 stream. Reading past the
+// end yields zeros and sets err.
+type woff2Reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *woff2Reader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data)-r.pos {
+		r.err = errTruncatedWOFF2
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *woff2Reader) u8() byte {
+	if b := r.bytes(1); len(b) == 1 {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *woff2Reader) u16() uint16 {
+	if b := r.bytes(2); len(b) == 2 {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *woff2Reader) u32() uint32 {
+	if b := r.bytes(4); len(b) == 4 {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// base128 reads a UIntBase128, a number stored in up to five bytes of
+// seven bits each.
+func (r *woff2Reader) base128() uint32 {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b := r.u8()
+		if r.err != nil {
+			return 0
+		}
+		if (i == 0 && b == 0x80) || v&0xfe000000 != 0 {
+			r.err = errors.New("invalid UIntBase128")
+			return 0
+		}
+		v = v<<7 | uint32(b&0x7f)
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+	r.err = errors.New("invalid UIntBase128")
+	return 0
+}
+
+// uint255 reads a 255UInt16, a number stored in one to three bytes.
+func (r *woff2Reader) uint255() uint16 {
+	switch code := r.u8(); code {
+	case 253:
+		return r.u16()
+	case 254:
+		return uint16(r.u8()) + 506
+	case 255:
+		return uint16(r.u8()) + 253
+	default:
+		return uint16(code)
+	}
+}
+
+// triplet reads the coordinate deltas of a glyph point from its flag and
+// the one to four bytes following in the glyph stream. The low seven bits
+// of the flag select how the bytes encode the deltas and their signs.
+func (r *woff2Reader) triplet(flag byte) (dx, dy int) {
+	flag &= 0x7f
+	withSign := func(flag byte, v int) int {
+		if flag&1 != 0 {
+			return v
+		}
+		return -v
+	}
+	switch {
+	case flag < 10:
+		dy = withSign(flag, (int(flag&14)<<7)+int(r.u8()))
+	case flag < 20:
+		dx = withSign(flag, (int((flag-10)&14)<<7)+int(r.u8()))
+	case flag < 84:
+		b0, b1 := int(flag-20), int(r.u8())
+		dx = withSign(flag, 1+(b0&0x30)+(b1>>4))
+		dy = withSign(flag>>1, 1+((b0&0x0c)<<2)+(b1&0x0f))
+	case flag < 120:
+		b0 := int(flag - 84)
+		dx = withSign(flag, 1+((b0/12)<<8)+int(r.u8()))
+		dy = withSign(flag>>1, 1+(((b0%12)>>2)<<8)+int(r.u8()))
+	case flag < 124:
+		b0, b1, b2 := int(r.u8()), int(r.u8()), int(r.u8())
+		dx = withSign(flag, (b0<<4)+(b1>>4))
+		dy = withSign(flag>>1, ((b1&0x0f)<<8)+b2)
+	default:
+		dx = withSign(flag, int(r.u16()))
+		dy = withSign(flag>>1, int(r.u16()))
+	}
+	return dx, dy
+}
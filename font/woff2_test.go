@@ -0,0 +1,479 @@
+package font
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-text/typesetting/font"
+)
+
+// readWOFF2 reads the test font, Source Code Pro Italic as served by the
+// Rust documentation. Its glyf and loca tables are transformed.
+func readWOFF2(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/SourceCodePro-It.woff2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// testGlyphs splits the glyf table of a font into its glyphs.
+func testGlyphs(t *testing.T, sfnt []byte) [][]byte {
+	t.Helper()
+	head, err := findTableAt(sfnt, 0, "head")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loca, err := findTableAt(sfnt, 0, "loca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	glyf, err := findTableAt(sfnt, 0, "glyf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var offsets []int
+	if binary.BigEndian.Uint16(head[50:]) == 0 {
+		for i := 0; i < len(loca); i += 2 {
+			offsets = append(offsets, 2*int(binary.BigEndian.Uint16(loca[i:])))
+		}
+	} else {
+		for i := 0; i < len(loca); i += 4 {
+			offsets = append(offsets, int(binary.BigEndian.Uint32(loca[i:])))
+		}
+	}
+	glyphs := make([][]byte, len(offsets)-1)
+	for i := range glyphs {
+		if offsets[i] > offsets[i+1] || offsets[i+1] > len(glyf) {
+			t.Fatalf("glyph %d out of bounds", i)
+		}
+		glyphs[i] = glyf[offsets[i]:offsets[i+1]]
+	}
+	return glyphs
+}
+
+func TestDecodeWOFF2(t *testing.T) {
+	sfnt, err := decodeWOFF2(readWOFF2(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := parseFontDirectory(sfnt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tag, record := range records {
+		table := slices.Clone(sfnt[record.offset : record.offset+record.length])
+		if tag == "head" {
+			// The checksum of head is that without the checksum adjustment.
+			clear(table[8:12])
+		}
+		if record.checksum != calculateChecksum(table) {
+			t.Errorf("%s: wrong checksum", tag)
+		}
+	}
+	if sum := calculateChecksum(sfnt); sum != 0xB1B0AFBA {
+		t.Errorf("font checksum = %#x, want 0xb1b0afba", sum)
+	}
+
+	// The hmtx table is stored as it is, while the glyphs are rebuilt from
+	// the transformed glyf table. The left side bearings of the font equal
+	// the minimum x coordinates of its glyphs, which only holds if their
+	// points were decoded correctly.
+	glyphs := testGlyphs(t, sfnt)
+	maxp, _ := findTableAt(sfnt, 0, "maxp")
+	if n := int(binary.BigEndian.Uint16(maxp[4:])); len(glyphs) != n {
+		t.Fatalf("got %d glyphs, want %d", len(glyphs), n)
+	}
+	hhea, _ := findTableAt(sfnt, 0, "hhea")
+	hmtx, _ := findTableAt(sfnt, 0, "hmtx")
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	for i, glyph := range glyphs {
+		offset := 4*numHMetrics + 2*(i-numHMetrics)
+		if i < numHMetrics {
+			offset = 4*i + 2
+		}
+		var xMin int16
+		if len(glyph) > 0 {
+			xMin = int16(binary.BigEndian.Uint16(glyph[2:]))
+		}
+		if bearing := int16(binary.BigEndian.Uint16(hmtx[offset:])); bearing != xMin {
+			t.Errorf("glyph %d: xMin = %d, want left side bearing %d", i, xMin, bearing)
+		}
+	}
+
+	fonts, err := LoadFromBytes(readWOFF2(t), "SourceCodePro-It.woff2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fonts) != 1 {
+		t.Fatalf("got %d fonts, want 1", len(fonts))
+	}
+	f := fonts[0]
+	if f.Family() != "Source Code Pro" || f.Style() != StyleItalic {
+		t.Errorf("font = %s %s, want Source Code Pro italic", f.Family(), f.Style())
+	}
+	gid, ok := f.GlyphIndex('g')
+	if !ok {
+		t.Fatal("font has no glyph for g")
+	}
+	outline, ok := f.Face().GlyphData(font.GID(gid)).(font.GlyphOutline)
+	if !ok || len(outline.Segments) == 0 {
+		t.Errorf("glyph for g has no outline")
+	}
+	if advance := f.GlyphAdvance(gid); advance != 600 {
+		t.Errorf("advance of g = %v, want 600", advance)
+	}
+}
+
+func TestWOFF2RoundTrip(t *testing.T) {
+	sfnt, err := decodeWOFF2(readWOFF2(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := decodeWOFF2(buildWOFF2(t, sfnt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, sfnt) {
+		t.Error("re-encoded font decodes differently")
+	}
+}
+
+func TestDecodeWOFF2Invalid(t *testing.T) {
+	data := readWOFF2(t)
+	if _, err := decodeWOFF2(data[:40]); err == nil {
+		t.Error("truncated header: expected an error")
+	}
+	if _, err := decodeWOFF2(data[:len(data)/2]); err == nil {
+		t.Error("truncated table data: expected an error")
+	}
+
+	// Flip a byte in the middle of the compressed tables.
+	corrupt := slices.Clone(data)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := decodeWOFF2(corrupt); err == nil {
+		t.Error("corrupt table data: expected an error")
+	}
+}
+
+func TestWOFF2Reader(t *testing.T) {
+	r := &woff2Reader{data: []byte{
+		0x3f,             // UIntBase128 63
+		0x81, 0x80, 0x00, // UIntBase128 16384
+		0xfc,       // 255UInt16 252
+		0xff, 0x00, // 255UInt16 253
+		0xfe, 0x01, // 255UInt16 507
+		0xfd, 0x12, 0x34, // 255UInt16 0x1234
+		0x80, 0x01, // UIntBase128 with a leading zero
+	}}
+	if v := r.base128(); v != 63 {
+		t.Errorf("base128 = %d, want 63", v)
+	}
+	if v := r.base128(); v != 16384 {
+		t.Errorf("base128 = %d, want 16384", v)
+	}
+	for _, want := range []uint16{252, 253, 507, 0x1234} {
+		if v := r.uint255(); v != want {
+			t.Errorf("uint255 = %d, want %d", v, want)
+		}
+	}
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	r.base128()
+	if r.err == nil {
+		t.Error("leading zero: expected an error")
+	}
+}
+
+// buildWOFF2 wraps a TTF font into a WOFF2 font, transforming its glyf,
+// loca, and hmtx tables. The tables are stored in the order they appear in
+// the font, so that decoding restores it byte for byte.
+func buildWOFF2(t *testing.T, sfnt []byte) []byte {
+	t.Helper()
+	records, err := parseFontDirectory(sfnt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := make([]tableRecord, 0, len(records))
+	for _, record := range records {
+		order = append(order, record)
+	}
+	slices.SortFunc(order, func(a, b tableRecord) int { return int(a.offset) - int(b.offset) })
+
+	glyf, xMins := transformGlyf(t, sfnt)
+	var dir, stream []byte
+	for _, record := range order {
+		table := sfnt[record.offset : record.offset+record.length]
+		index := slices.Index(woff2KnownTags[:], record.tag)
+		if index < 0 {
+			t.Fatalf("unknown tag %q", record.tag)
+		}
+		var version byte
+		switch record.tag {
+		case "glyf":
+			table = glyf
+		case "loca":
+			table = nil
+		case "hmtx":
+			table = transformHmtx(t, sfnt, xMins)
+			version = 1
+		}
+		dir = append(dir, byte(index)|version<<6)
+		dir = appendBase128(dir, record.length)
+		if record.tag == "glyf" || record.tag == "loca" || version != 0 {
+			dir = appendBase128(dir, uint32(len(table)))
+		}
+		stream = append(stream, table...)
+	}
+
+	var compressed bytes.Buffer
+	w := brotli.NewWriter(&compressed)
+	w.Write(stream)
+	w.Close()
+
+	header := make([]byte, 48)
+	copy(header, "wOF2")
+	copy(header[4:], sfnt[:4])
+	binary.BigEndian.PutUint32(header[8:], uint32(48+len(dir)+compressed.Len()))
+	binary.BigEndian.PutUint16(header[12:], uint16(len(order)))
+	binary.BigEndian.PutUint32(header[16:], uint32(len(sfnt)))
+	binary.BigEndian.PutUint32(header[20:], uint32(compressed.Len()))
+	return append(append(header, dir...), compressed.Bytes()...)
+}
+
+// transformGlyf writes the glyphs of a font into the streams of a
+// transformed glyf table. It also returns the minimum x coordinate of each
+// glyph.
+func transformGlyf(t *testing.T, sfnt []byte) ([]byte, []int16) {
+	t.Helper()
+	head, _ := findTableAt(sfnt, 0, "head")
+	glyphs := testGlyphs(t, sfnt)
+	var contours, points, flags, coords, composites, bboxes, instructions []byte
+	bboxBitmap := make([]byte, 4*((len(glyphs)+31)/32))
+	xMins := make([]int16, len(glyphs))
+
+	for i, glyph := range glyphs {
+		if len(glyph) == 0 {
+			contours = binary.BigEndian.AppendUint16(contours, 0)
+			continue
+		}
+		numContours := int16(binary.BigEndian.Uint16(glyph))
+		bbox := glyph[2:10]
+		xMins[i] = int16(binary.BigEndian.Uint16(bbox))
+		contours = append(contours, glyph[:2]...)
+		r := &woff2Reader{data: glyph, pos: 10}
+
+		if numContours < 0 {
+			bboxBitmap[i/8] |= 0x80 >> (i % 8)
+			bboxes = append(bboxes, bbox...)
+			hasInstructions := false
+			for more := true; more; {
+				flags := r.u16()
+				size := 6
+				if flags&argsAreWords != 0 {
+					size = 8
+				}
+				switch {
+				case flags&haveScale != 0:
+					size += 2
+				case flags&haveXYScale != 0:
+					size += 4
+				case flags&haveTwoByTwo != 0:
+					size += 8
+				}
+				r.pos -= 2
+				composites = append(composites, r.bytes(size)...)
+				hasInstructions = hasInstructions || flags&haveInstruction != 0
+				more = flags&moreComponents != 0
+			}
+			if hasInstructions {
+				n := r.u16()
+				coords = appendUint255(coords, n)
+				instructions = append(instructions, r.bytes(int(n))...)
+			}
+			if r.err != nil {
+				t.Fatalf("glyph %d: %v", i, r.err)
+			}
+			continue
+		}
+
+		last := -1
+		for j := 0; j < int(numContours); j++ {
+			end := int(r.u16())
+			points = appendUint255(points, uint16(end-last))
+			last = end
+		}
+		numPoints := last + 1
+		instructionLength := r.u16()
+		instruction := r.bytes(int(instructionLength))
+
+		// Expand the repeated flags, then read the coordinate deltas.
+		pointFlags := make([]byte, 0, numPoints)
+		for len(pointFlags) < numPoints {
+			f := r.u8()
+			pointFlags = append(pointFlags, f)
+			if f&0x08 != 0 {
+				for n := r.u8(); n > 0; n-- {
+					pointFlags = append(pointFlags, f)
+				}
+			}
+		}
+		delta := func(f, short, sameOrPos byte) int {
+			switch {
+			case f&short != 0 && f&sameOrPos != 0:
+				return int(r.u8())
+			case f&short != 0:
+				return -int(r.u8())
+			case f&sameOrPos != 0:
+				return 0
+			default:
+				return int(int16(r.u16()))
+			}
+		}
+		dxs, dys := make([]int, numPoints), make([]int, numPoints)
+		for j, f := range pointFlags {
+			dxs[j] = delta(f, xShort, xSameOrPos)
+		}
+		for j, f := range pointFlags {
+			dys[j] = delta(f, yShort, ySameOrPos)
+		}
+		if r.err != nil || (numPoints > 0 && pointFlags[0]&overlapFirst != 0) {
+			t.Fatalf("glyph %d: unsupported", i)
+		}
+
+		x, y := 0, 0
+		minX, minY, maxX, maxY := 0, 0, 0, 0
+		for j, f := range pointFlags {
+			var flag byte
+			flag, coords = appendTriplet(coords, f&onCurvePoint != 0, dxs[j], dys[j])
+			flags = append(flags, flag)
+			x, y = x+dxs[j], y+dys[j]
+			if j == 0 {
+				minX, minY, maxX, maxY = x, y, x, y
+			}
+			minX, minY = min(minX, x), min(minY, y)
+			maxX, maxY = max(maxX, x), max(maxY, y)
+		}
+		coords = appendUint255(coords, instructionLength)
+		instructions = append(instructions, instruction...)
+
+		// Bounding boxes that differ from that of the points are kept.
+		computed := appendGlyphHeader(nil, 0, [4]int16{int16(minX), int16(minY), int16(maxX), int16(maxY)})
+		if !bytes.Equal(computed[2:], bbox) {
+			bboxBitmap[i/8] |= 0x80 >> (i % 8)
+			bboxes = append(bboxes, bbox...)
+		}
+	}
+
+	out := make([]byte, 36)
+	binary.BigEndian.PutUint16(out[4:], uint16(len(glyphs)))
+	copy(out[6:8], head[50:52])
+	bboxes = append(bboxBitmap, bboxes...)
+	for i, s := range [][]byte{contours, points, flags, coords, composites, bboxes, instructions} {
+		binary.BigEndian.PutUint32(out[8+4*i:], uint32(len(s)))
+	}
+	for _, s := range [][]byte{contours, points, flags, coords, composites, bboxes, instructions} {
+		out = append(out, s...)
+	}
+	return out, xMins
+}
+
+// transformHmtx writes an hmtx table without the left side bearings, which
+// must equal the minimum x coordinates of the glyphs.
+func transformHmtx(t *testing.T, sfnt []byte, xMins []int16) []byte {
+	t.Helper()
+	hhea, _ := findTableAt(sfnt, 0, "hhea")
+	hmtx, _ := findTableAt(sfnt, 0, "hmtx")
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	out := []byte{0x03}
+	for i := 0; i < numHMetrics; i++ {
+		out = append(out, hmtx[4*i:4*i+2]...)
+	}
+	for i, xMin := range xMins {
+		offset := 4*numHMetrics + 2*(i-numHMetrics)
+		if i < numHMetrics {
+			offset = 4*i + 2
+		}
+		if int16(binary.BigEndian.Uint16(hmtx[offset:])) != xMin {
+			t.Fatalf("glyph %d: left side bearing differs from xMin", i)
+		}
+	}
+	return out
+}
+
+// appendTriplet appends the coordinate deltas of a point in the shortest
+// triplet encoding and returns the flag selecting it.
+func appendTriplet(b []byte, onCurve bool, dx, dy int) (byte, []byte) {
+	var flag byte
+	if !onCurve {
+		flag = 0x80
+	}
+	absX, absY := max(dx, -dx), max(dy, -dy)
+	var xSign, ySign byte
+	if dx >= 0 {
+		xSign = 1
+	}
+	if dy >= 0 {
+		ySign = 1
+	}
+	signs := xSign + 2*ySign
+	switch {
+	case dx == 0 && absY < 1280:
+		flag += byte((absY&0xf00)>>7) + ySign
+		b = append(b, byte(absY))
+	case dy == 0 && absX < 1280:
+		flag += 10 + byte((absX&0xf00)>>7) + xSign
+		b = append(b, byte(absX))
+	case absX < 65 && absY < 65:
+		flag += 20 + byte((absX-1)&0x30) + byte(((absY-1)&0x30)>>2) + signs
+		b = append(b, byte((absX-1)&0xf)<<4|byte((absY-1)&0xf))
+	case absX < 769 && absY < 769:
+		flag += 84 + byte(12*(((absX-1)&0x300)>>8)) + byte(((absY-1)&0x300)>>6) + signs
+		b = append(b, byte(absX-1), byte(absY-1))
+	case absX < 4096 && absY < 4096:
+		flag += 120 + signs
+		b = append(b, byte(absX>>4), byte(absX&0xf)<<4|byte(absY>>8), byte(absY))
+	default:
+		flag += 124 + signs
+		b = append(b, byte(absX>>8), byte(absX), byte(absY>>8), byte(absY))
+	}
+	return flag, b
+}
+
+// appendBase128 appends v as a UIntBase128.
+func appendBase128(b []byte, v uint32) []byte {
+	var groups []byte
+	for {
+		groups = append(groups, byte(v&0x7f))
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := len(groups) - 1; i > 0; i-- {
+		b = append(b, groups[i]|0x80)
+	}
+	return append(b, groups[0])
+}
+
+// appendUint255 appends v as a 255UInt16.
+func appendUint255(b []byte, v uint16) []byte {
+	switch {
+	case v < 253:
+		return append(b, byte(v))
+	case v < 506:
+		return append(b, 255, byte(v-253))
+	case v < 762:
+		return append(b, 254, byte(v-506))
+	default:
+		return append(b, 253, byte(v>>8), byte(v))
+	}
+}
@@ -0,0 +1,119 @@
+package font
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+)
+
+// testTable is a table of a synthetic font.
+type testTable struct {
+	tag  string
+	data []byte
+}
+
+// buildWOFF wraps tables into a WOFF font, compressing those that get
+// smaller.
+func buildWOFF(t *testing.T, tables []testTable) []byte {
+	t.Helper()
+	header := make([]byte, 44+20*len(tables))
+	copy(header, "wOFF")
+	binary.BigEndian.PutUint32(header[4:], 0x00010000)
+	binary.BigEndian.PutUint16(header[12:], uint16(len(tables)))
+
+	var body []byte
+	for i, table := range tables {
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		w.Write(table.data)
+		w.Close()
+		stored := table.data
+		if compressed.Len() < len(table.data) {
+			stored = compressed.Bytes()
+		}
+
+		entry := header[44+20*i:]
+		copy(entry, table.tag)
+		binary.BigEndian.PutUint32(entry[4:], uint32(len(header)+len(body)))
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(stored)))
+		binary.BigEndian.PutUint32(entry[12:], uint32(len(table.data)))
+		body = append(body, stored...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(header, body...)
+}
+
+func TestDecodeWOFF(t *testing.T) {
+	tables := []testTable{
+		{"cmap", bytes.Repeat([]byte("compressible "), 20)},
+		{"head", []byte{1, 2, 3}},
+	}
+	sfnt, err := decodeWOFF(buildWOFF(t, tables))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := parseFontDirectory(sfnt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("got %d tables, want 2", len(records))
+	}
+	for _, table := range tables {
+		got, err := findTableAt(sfnt, 0, table.tag)
+		if err != nil {
+			t.Fatalf("%s: %v", table.tag, err)
+		}
+		if !bytes.Equal(got, table.data) {
+			t.Errorf("%s = %q, want %q", table.tag, got, table.data)
+		}
+		if records[table.tag].offset%4 != 0 {
+			t.Errorf("%s is not aligned", table.tag)
+		}
+	}
+
+	if _, err := decodeWOFF([]byte("wOFF")); err == nil {
+		t.Error("truncated WOFF: expected an error")
+	}
+}
+
+func TestCollectionDirectory(t *testing.T) {
+	// A collection of two faces with a table each. Table offsets count
+	// from the start of the collection.
+	face := func(tag string, offset uint32) []byte {
+		dir := make([]byte, 12+16)
+		binary.BigEndian.PutUint32(dir[0:], 0x00010000)
+		binary.BigEndian.PutUint16(dir[4:], 1)
+		copy(dir[12:], tag)
+		binary.BigEndian.PutUint32(dir[20:], offset)
+		binary.BigEndian.PutUint32(dir[24:], 4)
+		return dir
+	}
+	header := make([]byte, 12+8)
+	copy(header, "ttcf")
+	binary.BigEndian.PutUint32(header[8:], 2)
+	binary.BigEndian.PutUint32(header[12:], 20)
+	binary.BigEndian.PutUint32(header[16:], 48)
+	data := append(header, face("MATH", 76)...)
+	data = append(data, face("MATH", 80)...)
+	data = append(data, "AAAABBBB"...)
+
+	for index, want := range []string{"AAAA", "BBBB"} {
+		table, err := findTable(data, index, "MATH")
+		if err != nil || string(table) != want {
+			t.Errorf("face %d: MATH = %q, %v, want %q", index, table, err, want)
+		}
+		start, _ := faceOffset(data, index)
+		records, err := parseFontDirectoryAt(data, start)
+		if err != nil || records["MATH"].length != 4 {
+			t.Errorf("face %d: directory = %v, %v", index, records, err)
+		}
+	}
+	if _, err := faceOffset(data, 2); err == nil {
+		t.Error("face 2: expected an error")
+	}
+}
@@ -3,13 +3,12 @@ module github.com/boergens/gotypst
 go 1.25.5
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/go-text/typesetting v0.3.2
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/image v0.23.0
 	golang.org/x/text v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	github.com/BurntSushi/toml v1.6.0 // indirect
-	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/image v0.23.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
-)
+require github.com/BurntSushi/toml v1.6.0 // indirect
@@ -2,132 +2,88 @@
 //
 // Typst is a modern typesetting system designed for creating documents
 // with a clean syntax and powerful features. This package provides the
-// core interfaces and types for embedding Typst compilation in Go applications.
+// entry points for embedding Typst compilation in Go applications.
 //
-// To use this package, implement the World interface to provide access
-// to the file system, packages, and other resources needed for compilation.
+// To compile a document, implement the World interface to provide access
+// to the sources, files, and fonts needed for compilation, and pass it to
+// Compile along with the routines of the compiler; routines.Standard holds
+// the standard ones. The kit package provides a World backed by the file
+// system (kit.FileWorld) and one backed by an in-memory map of files
+// (kit.MemoryWorld), which allows compiling documents without touching the
+// disk.
 package gotypst
 
+import (
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/timing"
+)
+
 // World provides access to the external environment during compilation.
 //
 // Implementations of this interface define how Typst interacts with
 // the file system, retrieves packages, and accesses fonts.
+//
+// Matches Rust: pub trait World
 type World interface {
-	// Library returns the standard library.
-	Library() Library
-
-	// MainFile returns the main source file for compilation.
-	MainFile() FileID
-
-	// Source returns the source content for a file.
-	Source(id FileID) (Source, error)
-
-	// File returns the raw bytes of a file.
-	File(id FileID) ([]byte, error)
-
-	// Font returns font data by index.
-	Font(index int) (Font, error)
-
-	// FontCount returns the number of available fonts.
-	FontCount() int
-
-	// Today returns the current date.
-	Today(offset *int) Date
-}
-
-// FileID uniquely identifies a file in the World.
-type FileID struct {
-	Package *PackageSpec
-	Path    string
-}
+	foundations.World
 
-// PackageSpec identifies a package by namespace, name, and version.
-type PackageSpec struct {
-	Namespace string
-	Name      string
-	Version   Version
+	// FontBook returns the fonts available to the document.
+	FontBook() *font.FontBook
 }
 
-// Version represents a semantic version.
-type Version struct {
-	Major int
-	Minor int
-	Patch int
-}
-
-// Source represents parsed source content.
-type Source struct {
-	ID   FileID
-	Text string
-}
-
-// Library represents the standard library.
-type Library interface{}
-
-// Font represents loaded font data that can be used for text shaping.
-//
-// Implementations should provide access to the underlying font face
-// for text shaping and metadata for font selection.
-type Font interface {
-	// Family returns the font family name.
-	Family() string
+// Document is a compiled document, laid out into pages.
+type Document = layout.PagedDocument
 
-	// Style returns the font style (normal, italic, oblique).
-	Style() FontStyle
+// Diagnostics are the errors and warnings of a compilation.
+type Diagnostics []foundations.SourceDiagnostic
 
-	// Weight returns the font weight (100-900).
-	Weight() int
-
-	// Face returns the underlying font face for text shaping.
-	// The returned value should be compatible with go-text/typesetting.
-	Face() interface{}
+// HasErrors reports whether any of the diagnostics is an error.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == foundations.SeverityError {
+			return true
+		}
+	}
+	return false
 }
 
-// FontStyle represents font style.
-type FontStyle uint8
-
-const (
-	FontStyleNormal  FontStyle = iota // Upright
-	FontStyleItalic                   // Italic
-	FontStyleOblique                  // Oblique (slanted)
-)
-
-// Date represents a date value.
-type Date struct {
-	Year  int
-	Month int
-	Day   int
+// Errors returns the diagnostics that are errors.
+func (d Diagnostics) Errors() Diagnostics {
+	return d.filter(foundations.SeverityError)
 }
 
-// Document represents a compiled Typst document.
-type Document struct {
-	Pages []Page
-	Title *string
+// Warnings returns the diagnostics that are warnings.
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(foundations.SeverityWarning)
 }
 
-// Page represents a single page in a document.
-type Page struct {
-	Frame  Frame
-	Fill   Color
-	Number int
+func (d Diagnostics) filter(severity foundations.DiagnosticSeverity) Diagnostics {
+	var out Diagnostics
+	for _, diag := range d {
+		if diag.Severity == severity {
+			out = append(out, diag)
+		}
+	}
+	return out
 }
 
-// Frame represents a laid-out frame of content.
-type Frame struct {
-	Width  Length
-	Height Length
-	Items  []FrameItem
-}
+// CompileOptions configures a compilation.
+type CompileOptions struct {
+	// Routines evaluate, realize, and lay out the document. Usually, these
+	// are routines.Standard. If nil, compilation fails.
+	Routines foundations.Routines
 
-// FrameItem represents an item within a frame.
-type FrameItem interface {
-	frameItem()
-}
+	// Jobs is the maximum number of page runs laid out concurrently. With
+	// zero or one, runs are laid out one after another.
+	Jobs int
 
-// Length represents a physical length.
-type Length float64
+	// Limits bounds the resources evaluation may use. If nil, the default
+	// limits apply. Set limits when compiling untrusted documents.
+	Limits *foundations.Limits
 
-// Color represents a color value.
-type Color struct {
-	R, G, B, A uint8
+	// Timer records how long parsing, evaluation, realization, and the
+	// layout of each page run and page take. If nil, nothing is recorded.
+	Timer *timing.Timer
 }
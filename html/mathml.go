@@ -0,0 +1,425 @@
+package html
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/boergens/gotypst/layout"
+	mathlayout "github.com/boergens/gotypst/layout/math"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+// MathMode specifies how equations are exported to HTML.
+type MathMode int
+
+const (
+	// MathModeMathML serializes equations as MathML, which browsers lay
+	// out natively and assistive technology can read.
+	MathModeMathML MathMode = iota
+	// MathModeSVG lays out equations and embeds them as SVG images, for
+	// viewers without MathML support.
+	MathModeSVG
+)
+
+// MathML serializes an equation as a MathML math element. Block equations
+// are displayed on their own line.
+func MathML(elem *libmath.EquationElem) string {
+	w := &mathmlWriter{}
+	w.b.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if elem.Block {
+		w.b.WriteString(` display="block"`)
+	}
+	if elem.Alt != nil {
+		fmt.Fprintf(&w.b, ` alttext="%s"`, escapeHTML(*elem.Alt))
+	}
+	w.b.WriteString(">")
+	w.row(elem.Body)
+	w.b.WriteString("</math>")
+	return w.b.String()
+}
+
+// mathmlWriter serializes math content to MathML, keeping track of the
+// font variant, weight, and slant set by the surrounding styles.
+type mathmlWriter struct {
+	b       strings.Builder
+	variant libmath.MathVariant
+	bold    bool
+	italic  *bool
+}
+
+// row writes content as a single MathML row, as expected for the arguments
+// of fractions, scripts, and the like.
+func (w *mathmlWriter) row(content foundations.Content) {
+	w.b.WriteString("<mrow>")
+	w.content(content)
+	w.b.WriteString("</mrow>")
+}
+
+// content writes the elements of content one after another.
+func (w *mathmlWriter) content(content foundations.Content) {
+	for _, elem := range content.Elements {
+		w.element(elem)
+	}
+}
+
+// element writes a single element. Elements without a MathML
+// counterpart contribute their plain text, if any.
+func (w *mathmlWriter) element(elem foundations.ContentElement) {
+	switch e := elem.(type) {
+	case *libmath.FracElem:
+		w.b.WriteString("<mfrac>")
+		w.row(e.Num)
+		w.row(e.Denom)
+		w.b.WriteString("</mfrac>")
+	case *libmath.BinomElem:
+		w.b.WriteString(`<mrow><mo>(</mo><mfrac linethickness="0">`)
+		w.row(e.Upper)
+		w.b.WriteString("<mrow>")
+		for i, lower := range e.Lower {
+			if i > 0 {
+				w.b.WriteString("<mo>,</mo>")
+			}
+			w.content(lower)
+		}
+		w.b.WriteString("</mrow></mfrac><mo>)</mo></mrow>")
+	case *libmath.VecElem:
+		rows := make([][]foundations.Content, len(e.Children))
+		for i, child := range e.Children {
+			rows[i] = []foundations.Content{child}
+		}
+		w.table(rows, e.Delim, e.Align)
+	case *libmath.MatElem:
+		w.table(e.Rows, e.Delim, e.Align)
+	case *libmath.CasesElem:
+		rows := make([][]foundations.Content, len(e.Children))
+		for i, child := range e.Children {
+			rows[i] = []foundations.Content{child}
+		}
+		delim := libmath.Delimiters{Open: e.Delim.Open}
+		if e.Reverse {
+			delim = libmath.Delimiters{Close: e.Delim.Close}
+		}
+		w.table(rows, delim, "start")
+	case *libmath.RootElem:
+		if len(e.Index.Elements) == 0 {
+			w.b.WriteString("<msqrt>")
+			w.content(e.Radicand)
+			w.b.WriteString("</msqrt>")
+			return
+		}
+		w.b.WriteString("<mroot>")
+		w.row(e.Radicand)
+		w.row(e.Index)
+		w.b.WriteString("</mroot>")
+	case *libmath.AttachElem:
+		w.attach(e)
+	case *libmath.LimitsElem:
+		w.content(e.Body)
+	case *libmath.ScriptsElem:
+		w.content(e.Body)
+	case *libmath.AccentElem:
+		w.b.WriteString(`<mover accent="true">`)
+		w.row(e.Base)
+		fmt.Fprintf(&w.b, "<mo>%s</mo>", escapeHTML(string(spacingAccent(e.Accent))))
+		w.b.WriteString("</mover>")
+	case *libmath.LrElem:
+		w.row(e.Body)
+	case *libmath.MidElem:
+		fmt.Fprintf(&w.b, `<mo stretchy="true">%s</mo>`, escapeHTML(e.Body.PlainText()))
+	case *libmath.AlignPointElem:
+		// Alignment only matters for the rows of block equations, which
+		// MathML lays out on its own.
+	case *libmath.PrimesElem:
+		fmt.Fprintf(&w.b, "<mo>%s</mo>", strings.Repeat("′", e.Count))
+	case *libmath.MathStyleElem:
+		w.styled(e)
+	case *libmath.OpElem:
+		text := e.Text.PlainText()
+		if utf8.RuneCountInString(text) == 1 {
+			fmt.Fprintf(&w.b, `<mi mathvariant="normal">%s</mi>`, escapeHTML(text))
+		} else {
+			fmt.Fprintf(&w.b, "<mi>%s</mi>", escapeHTML(text))
+		}
+	case *foundations.SequenceElem:
+		for _, child := range e.Children {
+			w.content(child)
+		}
+	case *foundations.StyledElem:
+		w.content(e.Child)
+	case *foundations.SymbolElem:
+		w.text(e.Text)
+	case foundations.PlainTextElement:
+		var b strings.Builder
+		e.PlainText(&b)
+		w.text(b.String())
+	}
+}
+
+// attach writes a base with its attachments. Attachments go above and
+// below operators with limits, to the sides otherwise.
+func (w *mathmlWriter) attach(e *libmath.AttachElem) {
+	top := joinAttachments(e.T, e.TR)
+	bottom := joinAttachments(e.B, e.BR)
+
+	if e.TL != nil || e.BL != nil {
+		w.b.WriteString("<mmultiscripts>")
+		w.row(e.Base)
+		w.scriptPair(bottom, top)
+		w.b.WriteString("<mprescripts/>")
+		w.scriptPair(e.BL, e.TL)
+		w.b.WriteString("</mmultiscripts>")
+		return
+	}
+
+	under, over, both := "msub", "msup", "msubsup"
+	if hasLimits(e.Base) {
+		under, over, both = "munder", "mover", "munderover"
+	}
+	switch {
+	case top != nil && bottom != nil:
+		fmt.Fprintf(&w.b, "<%s>", both)
+		w.row(e.Base)
+		w.row(*bottom)
+		w.row(*top)
+		fmt.Fprintf(&w.b, "</%s>", both)
+	case bottom != nil:
+		fmt.Fprintf(&w.b, "<%s>", under)
+		w.row(e.Base)
+		w.row(*bottom)
+		fmt.Fprintf(&w.b, "</%s>", under)
+	case top != nil:
+		fmt.Fprintf(&w.b, "<%s>", over)
+		w.row(e.Base)
+		w.row(*top)
+		fmt.Fprintf(&w.b, "</%s>", over)
+	default:
+		w.content(e.Base)
+	}
+}
+
+// scriptPair writes a subscript and superscript of mmultiscripts, with
+// none for a missing one.
+func (w *mathmlWriter) scriptPair(sub, sup *foundations.Content) {
+	for _, script := range []*foundations.Content{sub, sup} {
+		if script == nil {
+			w.b.WriteString("<none/>")
+		} else {
+			w.row(*script)
+		}
+	}
+}
+
+// table writes the rows of a vector, matrix, or case distinction between
+// its delimiters.
+func (w *mathmlWriter) table(rows [][]foundations.Content, delim libmath.Delimiters, align string) {
+	w.b.WriteString("<mrow>")
+	if delim.Open != "" {
+		fmt.Fprintf(&w.b, "<mo>%s</mo>", escapeHTML(delim.Open))
+	}
+	columnAlign := "center"
+	switch align {
+	case "start":
+		columnAlign = "left"
+	case "end":
+		columnAlign = "right"
+	}
+	fmt.Fprintf(&w.b, `<mtable columnalign="%s">`, columnAlign)
+	for _, row := range rows {
+		w.b.WriteString("<mtr>")
+		for _, cell := range row {
+			w.b.WriteString("<mtd>")
+			w.content(cell)
+			w.b.WriteString("</mtd>")
+		}
+		w.b.WriteString("</mtr>")
+	}
+	w.b.WriteString("</mtable>")
+	if delim.Close != "" {
+		fmt.Fprintf(&w.b, "<mo>%s</mo>", escapeHTML(delim.Close))
+	}
+	w.b.WriteString("</mrow>")
+}
+
+// styled writes content with a changed font variant, weight, or slant.
+// Properties the element leaves unset are inherited.
+func (w *mathmlWriter) styled(e *libmath.MathStyleElem) {
+	variant, bold, italic := w.variant, w.bold, w.italic
+	if e.Variant != nil {
+		w.variant = *e.Variant
+	}
+	if e.Bold != nil {
+		w.bold = *e.Bold
+	}
+	if e.Italic != nil {
+		slant := *e.Italic
+		w.italic = &slant
+	}
+	w.content(e.Body)
+	w.variant, w.bold, w.italic = variant, bold, italic
+}
+
+// text writes math text as MathML tokens: numbers, identifiers, and
+// operators. Text with several letters, like a quoted string, is kept
+// together as text.
+func (w *mathmlWriter) text(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	letters := 0
+	for _, c := range text {
+		if unicode.IsLetter(c) {
+			letters++
+		}
+	}
+	if letters > 1 {
+		fmt.Fprintf(&w.b, "<mtext>%s</mtext>", escapeHTML(w.style(text)))
+		return
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+		case unicode.IsDigit(c):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' && j+1 < len(runes) && unicode.IsDigit(runes[j+1])) {
+				j++
+			}
+			fmt.Fprintf(&w.b, "<mn>%s</mn>", escapeHTML(w.style(string(runes[i:j]))))
+			i = j - 1
+		case unicode.IsLetter(c):
+			if w.italic != nil && !*w.italic && w.variant == libmath.VariantSerif && !w.bold {
+				fmt.Fprintf(&w.b, `<mi mathvariant="normal">%s</mi>`, escapeHTML(string(c)))
+			} else {
+				fmt.Fprintf(&w.b, "<mi>%s</mi>", escapeHTML(w.style(string(c))))
+			}
+		default:
+			fmt.Fprintf(&w.b, "<mo>%s</mo>", escapeHTML(string(c)))
+		}
+	}
+}
+
+// style remaps text to the mathematical alphanumeric symbols for the
+// current style. Default-styled text is left alone, since MathML viewers
+// italicize single letters themselves.
+func (w *mathmlWriter) style(text string) string {
+	if w.variant == libmath.VariantSerif && !w.bold && w.italic == nil {
+		return text
+	}
+	return mathlayout.StyledText(text, w.variant, w.bold, w.italic)
+}
+
+// hasLimits reports whether attachments to a base go above and below it.
+func hasLimits(base foundations.Content) bool {
+	if len(base.Elements) != 1 {
+		return false
+	}
+	switch e := base.Elements[0].(type) {
+	case *libmath.LimitsElem:
+		return true
+	case *libmath.OpElem:
+		return e.Limits
+	case *foundations.SymbolElem:
+		c, size := utf8.DecodeRuneInString(e.Text)
+		return size == len(e.Text) && libmath.IsLargeOperator(c)
+	}
+	return false
+}
+
+// joinAttachments combines two attachments on the same side, like a
+// superscript and primes. It returns nil if there are none.
+func joinAttachments(a, b *foundations.Content) *foundations.Content {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	joined := foundations.Content{Elements: append(append([]foundations.ContentElement{}, a.Elements...), b.Elements...)}
+	return &joined
+}
+
+// spacingAccent returns the spacing form of a combining accent, which
+// MathML expects as the accent operator.
+func spacingAccent(accent rune) rune {
+	switch accent {
+	case libmath.AccentHat:
+		return '^'
+	case libmath.AccentTilde:
+		return '~'
+	case libmath.AccentBar:
+		return '¯'
+	case libmath.AccentVec:
+		return '→'
+	case libmath.AccentDot:
+		return '˙'
+	case libmath.AccentDDot:
+		return '¨'
+	case libmath.AccentBreve:
+		return '˘'
+	case libmath.AccentAcute:
+		return '´'
+	case libmath.AccentGrave:
+		return '`'
+	case libmath.AccentRing:
+		return '˚'
+	case libmath.AccentCaron:
+		return 'ˇ'
+	}
+	return accent
+}
+
+// EquationSVG lays out an equation at the given font size and returns it
+// as an SVG image. Its vertical-align style puts the equation's baseline
+// on the baseline of the surrounding text.
+func EquationSVG(elem *libmath.EquationElem, fontSize layout.Abs) string {
+	frame := mathlayout.LayoutEquation(elem, fontSize)
+	width, height := float64(frame.Width()), float64(frame.Height())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="math" width="%.2fpt" height="%.2fpt" viewBox="0 0 %.2f %.2f" style="vertical-align: %.2fpt;" role="img"`,
+		width, height, width, height, -float64(frame.Height()-frame.Baseline))
+	if elem.Alt != nil {
+		fmt.Fprintf(&b, ` aria-label="%s"`, escapeHTML(*elem.Alt))
+	}
+	b.WriteString(">")
+	writeMathFrameSVG(&b, frame, layout.Point{})
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// writeMathFrameSVG writes the items of a laid out math frame as SVG.
+func writeMathFrameSVG(b *strings.Builder, frame *mathlayout.MathFrame, origin layout.Point) {
+	for _, entry := range frame.Items {
+		x, y := origin.X+entry.Pos.X, origin.Y+entry.Pos.Y
+		switch item := entry.Item.(type) {
+		case mathlayout.TextItem:
+			// Math text is placed by its top edge, with the baseline at
+			// 80% of the font size.
+			fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-size="%.2f">%s</text>`,
+				float64(x), float64(y+item.FontSize*0.8), float64(item.FontSize), escapeHTML(item.Text))
+		case mathlayout.LineItem:
+			x2, y2 := x+item.Length, y
+			if item.Vertical {
+				x2, y2 = x, y+item.Length
+			}
+			stroke := "black"
+			if item.Paint != nil && item.Paint.Color != nil {
+				c := item.Paint.Color
+				stroke = (&Color{R: c.R, G: c.G, B: c.B, A: c.A}).CSS()
+			}
+			fmt.Fprintf(b, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-width="%.2f"/>`,
+				float64(x), float64(y), float64(x2), float64(y2), stroke, float64(item.Thickness))
+		case mathlayout.ChildFrame:
+			writeMathFrameSVG(b, item.Frame, layout.Point{X: x, Y: y})
+		case mathlayout.GlyphItem:
+			// Glyphs without a Unicode mapping can't be set as text and
+			// are left out.
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package html
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/layout/pages"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+func mathContent(elems ...foundations.ContentElement) foundations.Content {
+	return foundations.Content{Elements: elems}
+}
+
+func sym(text string) *foundations.SymbolElem {
+	return &foundations.SymbolElem{Text: text}
+}
+
+func TestMathML(t *testing.T) {
+	two := mathContent(sym("2"))
+	tests := []struct {
+		name string
+		elem *libmath.EquationElem
+		want string
+	}{
+		{
+			"tokens",
+			&libmath.EquationElem{Body: mathContent(sym("x"), sym("="), sym("1.5"))},
+			"<mrow><mi>x</mi><mo>=</mo><mn>1.5</mn></mrow>",
+		},
+		{
+			"fraction",
+			&libmath.EquationElem{Body: mathContent(&libmath.FracElem{Num: mathContent(sym("a")), Denom: mathContent(sym("b"))}), Block: true},
+			`display="block"><mrow><mfrac><mrow><mi>a</mi></mrow><mrow><mi>b</mi></mrow></mfrac></mrow>`,
+		},
+		{
+			"superscript",
+			&libmath.EquationElem{Body: mathContent(&libmath.AttachElem{Base: mathContent(sym("x")), T: &two})},
+			"<msup><mrow><mi>x</mi></mrow><mrow><mn>2</mn></mrow></msup>",
+		},
+		{
+			"limits",
+			&libmath.EquationElem{Body: mathContent(&libmath.AttachElem{Base: mathContent(sym("∑")), B: &two})},
+			"<munder><mrow><mo>∑</mo></mrow><mrow><mn>2</mn></mrow></munder>",
+		},
+		{
+			"square root",
+			&libmath.EquationElem{Body: mathContent(&libmath.RootElem{Radicand: two})},
+			"<msqrt><mn>2</mn></msqrt>",
+		},
+		{
+			"bold",
+			&libmath.EquationElem{Body: mathContent(&libmath.MathStyleElem{Body: mathContent(sym("x")), Bold: new(bool)}, &libmath.MathStyleElem{Body: mathContent(sym("A")), Bold: boolPtr(true), Italic: new(bool)})},
+			"<mi>x</mi><mi>𝐀</mi>",
+		},
+		{
+			"text",
+			&libmath.EquationElem{Body: mathContent(sym("if"), sym("<"))},
+			"<mtext>if</mtext><mo>&lt;</mo>",
+		},
+	}
+	for _, tt := range tests {
+		if got := MathML(tt.elem); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: MathML = %q, missing %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestRenderEquation(t *testing.T) {
+	eq := &libmath.EquationElem{Body: mathContent(sym("x"))}
+	group := pages.Frame{Size: layout.Size{Width: 10, Height: 12}}
+	group.Push(layout.Point{}, pages.EquationItem{Equation: eq, FontSize: 11})
+	group.Push(layout.Point{}, pages.TextItem{Text: "𝑥", FontSize: 11})
+	page := pages.Frame{Size: layout.Size{Width: 100, Height: 100}}
+	page.Push(layout.Point{X: 5, Y: 5}, pages.GroupItem{Frame: group})
+	doc := &pages.PagedDocument{Pages: []pages.Page{{Frame: page}}}
+
+	for _, tt := range []struct {
+		mode MathMode
+		want string
+	}{
+		{MathModeMathML, "<mi>x</mi>"},
+		{MathModeSVG, `<text x="0.00" y="8.80" font-size="11.00">𝑥</text>`},
+	} {
+		var buf bytes.Buffer
+		r := NewRenderer()
+		r.Math = tt.mode
+		if err := r.RenderDocument(doc, &buf); err != nil {
+			t.Fatal(err)
+		}
+		html := buf.String()
+		if !strings.Contains(html, tt.want) {
+			t.Errorf("mode %d: output missing %q", tt.mode, tt.want)
+		}
+		// The laid out math is replaced by the equation.
+		if strings.Contains(html, `class="text"`) {
+			t.Errorf("mode %d: output contains the laid out text", tt.mode)
+		}
+	}
+}
@@ -16,6 +16,8 @@ type Renderer struct {
 	buf strings.Builder
 	// indent tracks current indentation level.
 	indent int
+	// Math selects how equations are exported.
+	Math MathMode
 }
 
 // NewRenderer creates a new HTML renderer.
@@ -41,6 +43,15 @@ func (r *Renderer) RenderDocument(doc *pages.PagedDocument, w io.Writer) error {
 	} else {
 		r.writeln("<title>Document</title>")
 	}
+	if doc.Info.Description != nil {
+		r.writef("<meta name=\"description\" content=\"%s\">\n", escapeHTML(*doc.Info.Description))
+	}
+	if len(doc.Info.Author) > 0 {
+		r.writef("<meta name=\"authors\" content=\"%s\">\n", escapeHTML(strings.Join(doc.Info.Author, ", ")))
+	}
+	if len(doc.Info.Keywords) > 0 {
+		r.writef("<meta name=\"keywords\" content=\"%s\">\n", escapeHTML(strings.Join(doc.Info.Keywords, ", ")))
+	}
 
 	// Add base styles
 	r.writeln("<style>")
@@ -50,6 +61,7 @@ func (r *Renderer) RenderDocument(doc *pages.PagedDocument, w io.Writer) error {
 	r.writeln(".frame { position: absolute; }")
 	r.writeln(".text { position: absolute; white-space: pre; font-family: serif; }")
 	r.writeln(".image { position: absolute; }")
+	r.writeln(".math { position: absolute; }")
 	r.indent--
 	r.writeln("</style>")
 	r.indent--
@@ -113,6 +125,13 @@ func (r *Renderer) renderFrame(frame *pages.Frame, origin layout.Point) {
 func (r *Renderer) renderFrameItem(item pages.FrameItem, pos layout.Point) {
 	switch it := item.(type) {
 	case pages.GroupItem:
+		if len(it.Frame.Items) > 0 {
+			if eq, ok := it.Frame.Items[0].Item.(pages.EquationItem); ok {
+				r.renderEquation(eq, it.Frame.Size, pos)
+				return
+			}
+		}
+
 		// Nested frame - render as a positioned div
 		width := float64(it.Frame.Size.Width)
 		height := float64(it.Frame.Size.Height)
@@ -144,6 +163,20 @@ func (r *Renderer) renderText(item pages.TextItem, pos layout.Point) {
 		float64(pos.X), float64(pos.Y), fontSize, escapeHTML(item.Text))
 }
 
+// renderEquation renders an equation in place of the group that holds its
+// laid out math.
+func (r *Renderer) renderEquation(item pages.EquationItem, size layout.Size, pos layout.Point) {
+	var body string
+	switch r.Math {
+	case MathModeSVG:
+		body = EquationSVG(item.Equation, item.FontSize)
+	default:
+		body = MathML(item.Equation)
+	}
+	r.writef(`<div class="math" style="left: %.2fpt; top: %.2fpt; width: %.2fpt; height: %.2fpt; font-size: %.2fpt;">%s</div>`+"\n",
+		float64(pos.X), float64(pos.Y), float64(size.Width), float64(size.Height), float64(item.FontSize), body)
+}
+
 // renderImage renders an image item.
 func (r *Renderer) renderImage(item pages.ImageItem, pos layout.Point) {
 	width := float64(item.Size.Width)
@@ -73,6 +73,33 @@ func TestRenderDocumentWithTitle(t *testing.T) {
 	}
 }
 
+func TestRenderDocumentMetadata(t *testing.T) {
+	description := "A \"short\" report"
+	doc := &pages.PagedDocument{
+		Info: pages.DocumentInfo{
+			Author:      []string{"Ada", "Grace"},
+			Description: &description,
+			Keywords:    []string{"math"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Export(doc, &buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	html := buf.String()
+	for _, want := range []string{
+		`<meta name="description" content="A &quot;short&quot; report">`,
+		`<meta name="authors" content="Ada, Grace">`,
+		`<meta name="keywords" content="math">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
+
 func TestRenderTextItem(t *testing.T) {
 	doc := &pages.PagedDocument{
 		Pages: []pages.Page{
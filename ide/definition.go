@@ -0,0 +1,74 @@
+package ide
+
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Definition is the target of a jump to definition.
+// Matches Rust: typst_ide::Definition
+type Definition struct {
+	// Span is the span of the defining node. For a file, it is the span of
+	// the file's root node.
+	Span syntax.Span
+}
+
+// DefinitionAt finds the definition of the symbol at the cursor. It
+// resolves the paths of imports and includes to their files and
+// references to the labels they point to in the same source.
+//
+// Matches Rust: typst_ide::definition
+func DefinitionAt(world foundations.World, source *syntax.Source, cursor int, side syntax.Side) *Definition {
+	leaf := syntax.NewLinkedNode(source.Root()).LeafAt(cursor, side)
+	if leaf == nil {
+		return nil
+	}
+	parent := leaf.Parent()
+	if parent == nil {
+		return nil
+	}
+
+	switch leaf.Kind() {
+	case syntax.Str:
+		if kind := parent.Kind(); kind != syntax.ModuleImport && kind != syntax.ModuleInclude {
+			return nil
+		}
+		return fileDefinition(world, source, syntax.StrExprFromNode(leaf.Get()).Get())
+	case syntax.RefMarker:
+		target := syntax.RefExprFromNode(parent.Get()).Target()
+		if span, ok := FindLabel(source, target); ok {
+			return &Definition{Span: span}
+		}
+	}
+	return nil
+}
+
+// fileDefinition resolves a path relative to a source to the root of the
+// file it points to. Package imports are not resolved.
+func fileDefinition(world foundations.World, source *syntax.Source, path string) *Definition {
+	if path == "" || strings.HasPrefix(path, "@") {
+		return nil
+	}
+	id, err := source.Id().Join(path)
+	if err != nil {
+		return nil
+	}
+	target, err := world.Source(id)
+	if err != nil {
+		return nil
+	}
+	return &Definition{Span: target.Root().Span()}
+}
+
+// FindLabel returns the span of the first label with the given name in a
+// source.
+func FindLabel(source *syntax.Source, name string) (syntax.Span, bool) {
+	for _, label := range source.Index().Labels {
+		if label.Name == name {
+			return label.Span, true
+		}
+	}
+	return syntax.Span{}, false
+}
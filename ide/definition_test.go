@@ -0,0 +1,84 @@
+package ide
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/kit"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func testSource(t *testing.T, files map[string]string) (*kit.MemoryWorld, *syntax.Source) {
+	t.Helper()
+	data := make(map[string][]byte, len(files))
+	for path, text := range files {
+		data[path] = []byte(text)
+	}
+	world, err := kit.NewMemoryWorld("main.typ", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := world.Source(world.MainFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return world, source
+}
+
+func TestDefinitionOfImport(t *testing.T) {
+	text := `#import "chapters/intro.typ": title`
+	world, source := testSource(t, map[string]string{
+		"main.typ":           text,
+		"chapters/intro.typ": "#let title = [Intro]",
+	})
+
+	def := DefinitionAt(world, source, strings.Index(text, "intro"), syntax.After)
+	if def == nil {
+		t.Fatal("expected a definition")
+	}
+	id := def.Span.Id()
+	if id == nil || id.Get().VPath().String() != "/chapters/intro.typ" {
+		t.Errorf("definition in %v, want /chapters/intro.typ", id)
+	}
+
+	// The imported names are not resolved.
+	if def := DefinitionAt(world, source, strings.Index(text, "title"), syntax.After); def != nil {
+		t.Errorf("unexpected definition %+v", def)
+	}
+}
+
+func TestDefinitionOfMissingFile(t *testing.T) {
+	text := `#include "missing.typ"`
+	world, source := testSource(t, map[string]string{"main.typ": text})
+	if def := DefinitionAt(world, source, strings.Index(text, "missing"), syntax.After); def != nil {
+		t.Errorf("unexpected definition %+v", def)
+	}
+}
+
+func TestDefinitionOfReference(t *testing.T) {
+	text := "= Intro <intro>\nSee @intro."
+	world, source := testSource(t, map[string]string{"main.typ": text})
+
+	def := DefinitionAt(world, source, strings.Index(text, "@intro")+2, syntax.After)
+	if def == nil {
+		t.Fatal("expected a definition")
+	}
+	start, end, ok := source.Range(def.Span)
+	if !ok || text[start:end] != "<intro>" {
+		t.Errorf("definition at %q, want <intro>", text[start:end])
+	}
+
+	text = "See @nowhere."
+	world, source = testSource(t, map[string]string{"main.typ": text})
+	if def := DefinitionAt(world, source, strings.Index(text, "@")+1, syntax.After); def != nil {
+		t.Errorf("unexpected definition %+v", def)
+	}
+}
+
+func TestDefinitionInBrokenDocument(t *testing.T) {
+	text := "= Intro <intro>\nSee @intro.\n#let x = (1,"
+	world, source := testSource(t, map[string]string{"main.typ": text})
+	if def := DefinitionAt(world, source, strings.Index(text, "@intro")+2, syntax.After); def == nil {
+		t.Error("expected a definition despite the syntax error")
+	}
+}
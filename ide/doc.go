@@ -0,0 +1,7 @@
+// Package ide provides code intelligence for Typst sources: jumping to
+// definitions, tooltips for standard library functions, and outlines of
+// a document's headings. It works on the syntax tree with the positions
+// of its nodes and is the basis of the language server.
+//
+// Translated from typst-ide.
+package ide
@@ -0,0 +1,39 @@
+package ide
+
+import "github.com/boergens/gotypst/syntax"
+
+// DocumentSymbol is a heading in the outline of a source file.
+type DocumentSymbol struct {
+	// Name is the plain text of the heading.
+	Name string
+	// Level is the heading's nesting level, starting at 1.
+	Level int
+	// Span is the span of the heading.
+	Span syntax.Span
+	// Children are the headings nested below this one.
+	Children []DocumentSymbol
+}
+
+// DocumentSymbols returns the outline of a source file: its headings,
+// nested by level. A heading is nested below the closest preceding heading
+// of a lower level.
+func DocumentSymbols(source *syntax.Source) []DocumentSymbol {
+	var headings []DocumentSymbol
+	for _, heading := range source.Index().Headings {
+		headings = append(headings, DocumentSymbol{Name: heading.Title, Level: heading.Level, Span: heading.Span})
+	}
+	symbols, _ := nest(headings, 0)
+	return symbols
+}
+
+// nest builds the tree of headings deeper than level from the start of the
+// list. It returns the tree and the headings that come after it.
+func nest(headings []DocumentSymbol, level int) ([]DocumentSymbol, []DocumentSymbol) {
+	var symbols []DocumentSymbol
+	for len(headings) > 0 && headings[0].Level > level {
+		symbol := headings[0]
+		symbol.Children, headings = nest(headings[1:], symbol.Level)
+		symbols = append(symbols, symbol)
+	}
+	return symbols, headings
+}
@@ -0,0 +1,35 @@
+package ide
+
+import (
+	"testing"
+)
+
+func TestDocumentSymbols(t *testing.T) {
+	text := "= Intro\nText.\n== First *steps*\n=== Details\n== Next\n= Outro\n"
+	_, source := testSource(t, map[string]string{"main.typ": text})
+
+	symbols := DocumentSymbols(source)
+	if len(symbols) != 2 || symbols[0].Name != "Intro" || symbols[1].Name != "Outro" {
+		t.Fatalf("top-level symbols = %+v", symbols)
+	}
+	intro := symbols[0].Children
+	if len(intro) != 2 || intro[0].Name != "First steps" || intro[1].Name != "Next" {
+		t.Fatalf("children of Intro = %+v", intro)
+	}
+	if len(intro[0].Children) != 1 || intro[0].Children[0].Name != "Details" || intro[0].Children[0].Level != 3 {
+		t.Errorf("children of First steps = %+v", intro[0].Children)
+	}
+
+	start, end, ok := source.Range(symbols[1].Span)
+	if !ok || text[start:end] != "= Outro" {
+		t.Errorf("span of Outro covers %q", text[start:end])
+	}
+}
+
+func TestDocumentSymbolsSkippedLevel(t *testing.T) {
+	_, source := testSource(t, map[string]string{"main.typ": "=== Deep\n= Top\n"})
+	symbols := DocumentSymbols(source)
+	if len(symbols) != 2 || symbols[0].Name != "Deep" || symbols[1].Name != "Top" {
+		t.Errorf("symbols = %+v", symbols)
+	}
+}
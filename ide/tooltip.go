@@ -0,0 +1,151 @@
+package ide
+
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Tooltip describes the symbol under the cursor.
+// Matches Rust: typst_ide::Tooltip
+type Tooltip struct {
+	// Text is the description.
+	Text string
+	// Code is set if the text is Typst code, like a function signature,
+	// rather than prose.
+	Code bool
+}
+
+// TooltipAt describes the identifier at the cursor if it refers to a value
+// of the standard library. Functions are described by their signature,
+// other values by their representation. Field accesses on modules, like
+// calc.pow, are followed.
+//
+// Matches Rust: typst_ide::tooltip
+func TooltipAt(library *foundations.Scope, source *syntax.Source, cursor int, side syntax.Side) *Tooltip {
+	leaf := syntax.NewLinkedNode(source.Root()).LeafAt(cursor, side)
+	if leaf == nil || library == nil {
+		return nil
+	}
+	if kind := leaf.Kind(); kind != syntax.Ident && kind != syntax.MathIdent {
+		return nil
+	}
+
+	path := []string{leaf.Text()}
+	if parent := leaf.Parent(); parent != nil && parent.Kind() == syntax.FieldAccess && leaf.Index() > 0 {
+		path = append(identPath(parent.Get().Children()[0]), leaf.Text())
+	}
+	value := lookup(library, path)
+	if value == nil {
+		return nil
+	}
+
+	if fn, ok := value.(foundations.FuncValue); ok {
+		if sig := Signature(fn.Func); sig != "" {
+			return &Tooltip{Text: sig, Code: true}
+		}
+		return nil
+	}
+	return &Tooltip{Text: foundations.Repr(value), Code: true}
+}
+
+// identPath returns the names of a chain of field accesses, like
+// ["calc", "pow"] for calc.pow, or nil if the node is anything else.
+func identPath(node *syntax.SyntaxNode) []string {
+	switch node.Kind() {
+	case syntax.Ident, syntax.MathIdent:
+		return []string{node.Text()}
+	case syntax.FieldAccess:
+		access := syntax.FieldAccessExprFromNode(node)
+		field := access.Field()
+		target := identPath(node.Children()[0])
+		if target == nil || field == nil {
+			return nil
+		}
+		return append(target, field.Get())
+	}
+	return nil
+}
+
+// lookup resolves a path of names in a scope, descending into the scopes
+// of modules and functions.
+func lookup(scope *foundations.Scope, path []string) foundations.Value {
+	if len(path) == 0 {
+		return nil
+	}
+	var value foundations.Value
+	for i, name := range path {
+		if scope == nil {
+			return nil
+		}
+		binding := scope.Get(name)
+		if binding == nil {
+			return nil
+		}
+		value = binding.Read()
+		if i == len(path)-1 {
+			break
+		}
+		switch v := value.(type) {
+		case foundations.ModuleValue:
+			scope = v.Module.Scope
+		case foundations.FuncValue:
+			scope = v.Func.Scope()
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// Signature returns the signature of a native function, like
+// "pad(body: content, left: relative = 0pt, ..)". It is taken from the
+// function's info or, for element functions, from the element's fields.
+// Closures have no recorded signature and yield the empty string.
+func Signature(f *foundations.Func) string {
+	native, ok := f.Repr.(foundations.NativeFunc)
+	if !ok {
+		return ""
+	}
+
+	var name string
+	var params []string
+	switch {
+	case native.Info != nil:
+		name = native.Info.Name
+		for _, p := range native.Info.Params {
+			params = append(params, param(p.Name, p.Type, p.Default, p.Variadic))
+		}
+	case f.Element() != nil:
+		elem := f.Element()
+		name = elem.Name
+		for _, field := range elem.Fields {
+			params = append(params, param(field.Name, field.Type, field.Default, field.Variadic))
+		}
+	default:
+		return ""
+	}
+	if name == "" && f.Name != nil {
+		name = *f.Name
+	}
+	return name + "(" + strings.Join(params, ", ") + ")"
+}
+
+// param formats a parameter of a signature.
+func param(name string, typ foundations.Type, def foundations.Value, variadic bool) string {
+	var b strings.Builder
+	if variadic {
+		b.WriteString("..")
+	}
+	b.WriteString(name)
+	if typ != foundations.TypeNone {
+		b.WriteString(": ")
+		b.WriteString(typ.String())
+	}
+	if def != nil {
+		b.WriteString(" = ")
+		b.WriteString(foundations.Repr(def))
+	}
+	return b.String()
+}
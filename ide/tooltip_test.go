@@ -0,0 +1,92 @@
+package ide
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func testLibrary() *foundations.Scope {
+	name := "pow"
+	pow := &foundations.Func{
+		Name: &name,
+		Repr: foundations.NativeFunc{Info: &foundations.FuncInfo{
+			Name: "pow",
+			Params: []foundations.ParamInfo{
+				{Name: "base", Type: foundations.TypeInt},
+				{Name: "exponent", Type: foundations.TypeInt},
+			},
+		}},
+	}
+	calc := foundations.NewScope()
+	calc.Define("pow", foundations.FuncValue{Func: pow}, syntax.Detached())
+	calc.Define("pi", foundations.Float(3.5), syntax.Detached())
+
+	library := foundations.NewScope()
+	library.Define("calc", foundations.ModuleValue{Module: &foundations.Module{Name: "calc", Scope: calc}}, syntax.Detached())
+	library.Define("pow", foundations.FuncValue{Func: pow}, syntax.Detached())
+	return library
+}
+
+func TestTooltipSignature(t *testing.T) {
+	library := testLibrary()
+	tests := []struct {
+		text   string
+		cursor string
+		want   string
+	}{
+		{"#calc.pow(2, 3)", "pow", "pow(base: integer, exponent: integer)"},
+		{"#pow(2, 3)", "pow", "pow(base: integer, exponent: integer)"},
+		{"#calc.pi", "pi", "3.5"},
+	}
+	for _, tt := range tests {
+		_, source := testSource(t, map[string]string{"main.typ": tt.text})
+		tip := TooltipAt(library, source, strings.Index(tt.text, tt.cursor), syntax.After)
+		if tip == nil {
+			t.Errorf("%s: expected a tooltip", tt.text)
+			continue
+		}
+		if !tip.Code || tip.Text != tt.want {
+			t.Errorf("%s: tooltip = %+v, want %q", tt.text, tip, tt.want)
+		}
+	}
+}
+
+func TestTooltipUnknown(t *testing.T) {
+	library := testLibrary()
+	for _, text := range []string{"#unknown", "#calc.unknown", "Plain text"} {
+		_, source := testSource(t, map[string]string{"main.typ": text})
+		if tip := TooltipAt(library, source, len(text)-2, syntax.After); tip != nil {
+			t.Errorf("%s: unexpected tooltip %+v", text, tip)
+		}
+	}
+}
+
+func TestSignatureDefaultsAndVariadic(t *testing.T) {
+	f := &foundations.Func{Repr: foundations.NativeFunc{Info: &foundations.FuncInfo{
+		Name: "stack",
+		Params: []foundations.ParamInfo{
+			{Name: "spacing", Type: foundations.TypeInt, Default: foundations.Int(0), Named: true},
+			{Name: "children", Type: foundations.TypeContent, Variadic: true},
+		},
+	}}}
+	if got, want := Signature(f), "stack(spacing: integer = 0, ..children: content)"; got != want {
+		t.Errorf("Signature = %q, want %q", got, want)
+	}
+	if got := Signature(&foundations.Func{Repr: foundations.ClosureFunc{}}); got != "" {
+		t.Errorf("closure signature = %q, want empty", got)
+	}
+}
+
+func TestTooltipInBrokenDocument(t *testing.T) {
+	library := testLibrary()
+	for _, text := range []string{"#calc.pow(2,", "#{ calc.pow(1 + }", "$ calc.pow( $", "#let f = calc.pow(2, 3"} {
+		_, source := testSource(t, map[string]string{"main.typ": text})
+		tooltip := TooltipAt(library, source, strings.Index(text, "pow"), syntax.After)
+		if tooltip == nil || tooltip.Text != "pow(base: integer, exponent: integer)" {
+			t.Errorf("%q: tooltip = %+v", text, tooltip)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Persistent cache of font metadata.
+// Speeds up font discovery by parsing only the font files that changed
+// since the last run.
+
+package kit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/boergens/gotypst/font"
+)
+
+// fontCacheVersion is the version of the cache file format. Caches of
+// other versions are discarded.
+const fontCacheVersion = 1
+
+// FontCache is a persistent cache of the metadata of font files.
+//
+// Fonts found through the cache are created lazily: as long as a font file
+// has the modification time and size recorded in the cache, its faces are
+// created from the cached metadata and the file is only read once a face is
+// used. If the modification time changed, the file is hashed, and only
+// parsed again if its content changed too.
+type FontCache struct {
+	path    string
+	entries map[string]*fontCacheEntry
+	dirty   bool
+	mu      sync.Mutex
+}
+
+// fontCacheFile is the serialized form of a font cache.
+type fontCacheFile struct {
+	Version int                        `json:"version"`
+	Files   map[string]*fontCacheEntry `json:"files"`
+}
+
+// fontCacheEntry holds the metadata of the faces in one font file.
+type fontCacheEntry struct {
+	ModTime time.Time       `json:"mtime"`
+	Size    int64           `json:"size"`
+	Hash    string          `json:"hash"`
+	Faces   []font.FontInfo `json:"faces"`
+}
+
+// DefaultFontCachePath returns the path of the font cache in the user's
+// cache directory, or an empty string if there is none.
+func DefaultFontCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gotypst", "fonts.json")
+}
+
+// OpenFontCache opens the font cache at path. A missing, unreadable, or
+// outdated cache file results in an empty cache, which is written to path
+// on Save.
+func OpenFontCache(path string) *FontCache {
+	c := &FontCache{path: path, entries: make(map[string]*fontCacheEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var file fontCacheFile
+	if json.Unmarshal(data, &file) != nil || file.Version != fontCacheVersion {
+		c.dirty = true
+		return c
+	}
+	for path, entry := range file.Files {
+		if entry != nil {
+			c.entries[path] = entry
+		}
+	}
+	return c
+}
+
+// Discover finds the fonts in the given directories, like
+// font.DiscoverFonts, but creates the fonts of unchanged files from the
+// cached metadata. It can be used as the Discover function of
+// font.SearchOptions.
+func (c *FontCache) Discover(dirs []string) ([]*font.Font, error) {
+	var fonts []*font.Font
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || seen[path] || !font.IsFontFile(path) {
+				return nil
+			}
+			seen[path] = true
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fonts = append(fonts, c.load(path, info)...)
+			return nil
+		})
+	}
+
+	return fonts, nil
+}
+
+// load returns the fonts in the file at path, from the cache if possible.
+func (c *FontCache) load(path string, info fs.FileInfo) []*font.Font {
+	c.mu.Lock()
+	entry := c.entries[path]
+	c.mu.Unlock()
+
+	if entry != nil && entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() {
+		return lazyFonts(entry, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var fonts []*font.Font
+	if entry != nil && entry.Hash == hash {
+		// Only the modification time changed.
+		fonts = lazyFonts(entry, path)
+	} else {
+		fonts, _ = font.LoadFromBytes(data, path)
+		faces := make([]font.FontInfo, len(fonts))
+		for i, f := range fonts {
+			faces[i] = f.Info
+		}
+		entry = &fontCacheEntry{Hash: hash, Faces: faces}
+	}
+
+	c.mu.Lock()
+	c.entries[path] = &fontCacheEntry{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Hash:    entry.Hash,
+		Faces:   entry.Faces,
+	}
+	c.dirty = true
+	c.mu.Unlock()
+	return fonts
+}
+
+// lazyFonts creates the fonts of a cached file.
+func lazyFonts(entry *fontCacheEntry, path string) []*font.Font {
+	fonts := make([]*font.Font, len(entry.Faces))
+	for i, info := range entry.Faces {
+		fonts[i] = font.NewLazyFont(info, path, i)
+	}
+	return fonts
+}
+
+// Save writes the cache back to its file if it changed. Entries of font
+// files that no longer exist are dropped.
+func (c *FontCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.entries {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			delete(c.entries, path)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(fontCacheFile{Version: fontCacheVersion, Files: c.entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that a concurrent run never reads
+	// a partially written cache.
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// WithCachedFontSearch loads the fonts found with the given search options
+// like WithFontSearch, but looks them up in the font cache at cachePath
+// and updates it. With an empty cachePath, no cache is used.
+func WithCachedFontSearch(opts font.SearchOptions, cachePath string) WorldOption {
+	return func(c *worldConfig) {
+		if cachePath == "" {
+			c.fontBook = font.SearchFonts(opts)
+			return
+		}
+		cache := OpenFontCache(cachePath)
+		opts.Discover = cache.Discover
+		c.fontBook = font.SearchFonts(opts)
+		// A cache that cannot be written only costs time on the next run.
+		_ = cache.Save()
+	}
+}
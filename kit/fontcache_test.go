@@ -0,0 +1,81 @@
+package kit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boergens/gotypst/font"
+)
+
+func TestFontCache(t *testing.T) {
+	dir := t.TempDir()
+	fontPath := filepath.Join(dir, "fonts", "Serif.otf")
+	if err := os.MkdirAll(filepath.Dir(fontPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fontPath, []byte("not really a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cachePath := filepath.Join(dir, "cache", "fonts.json")
+
+	// The first run parses the file and records its metadata.
+	cache := OpenFontCache(cachePath)
+	if fonts, _ := cache.Discover([]string{filepath.Dir(fontPath)}); len(fonts) != 0 {
+		t.Errorf("got %d fonts from an invalid file", len(fonts))
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pretend that the file had a face, so that a run served from the
+	// cache is told apart from one that parses the file.
+	cache = OpenFontCache(cachePath)
+	entry := cache.entries[fontPath]
+	if entry == nil {
+		t.Fatalf("no cache entry for %s", fontPath)
+	}
+	entry.Faces = []font.FontInfo{{Family: "Cached Serif"}}
+	cache.dirty = true
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = OpenFontCache(cachePath)
+	fonts, _ := cache.Discover([]string{filepath.Dir(fontPath)})
+	if len(fonts) != 1 || fonts[0].Family() != "Cached Serif" {
+		t.Fatalf("fonts = %v, want the cached face", fonts)
+	}
+	if fonts[0].Face() != nil {
+		t.Error("lazy font of an invalid file has a face")
+	}
+
+	// A new modification time with the same content keeps the entry.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(fontPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if fonts, _ := cache.Discover([]string{filepath.Dir(fontPath)}); len(fonts) != 1 {
+		t.Errorf("touched file: got %d fonts, want the cached face", len(fonts))
+	}
+
+	// Changed content is parsed again.
+	if err := os.WriteFile(fontPath, []byte("still not a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if fonts, _ := cache.Discover([]string{filepath.Dir(fontPath)}); len(fonts) != 0 {
+		t.Errorf("changed file: got %d fonts, want it parsed again", len(fonts))
+	}
+
+	// Entries of deleted files are dropped.
+	if err := os.Remove(fontPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if cache = OpenFontCache(cachePath); len(cache.entries) != 0 {
+		t.Errorf("entries = %v, want none", cache.entries)
+	}
+}
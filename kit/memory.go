@@ -0,0 +1,209 @@
+// In-memory World implementation for Typst.
+// Provides a foundations.World whose project files live in memory.
+
+package kit
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// MemoryWorld is an implementation of the World interface whose project
+// files are held in memory, keyed by their path in the project. It does
+// not touch the file system, except to read the files of packages if a
+// package resolver is configured.
+//
+// The files can be changed between compilations with SetFile and
+// RemoveFile. A changed file is parsed anew on its next access, while
+// sources handed out before stay as they were.
+type MemoryWorld struct {
+	// mainFile is the main source file being compiled.
+	mainFile syntax.FileId
+
+	worldConfig
+
+	// files holds the bytes of the project files.
+	files map[syntax.FileId][]byte
+
+	// sources caches parsed sources by file ID.
+	sources map[syntax.FileId]*syntax.Source
+
+	// mu protects files and sources.
+	mu sync.RWMutex
+}
+
+// NewMemoryWorld creates a MemoryWorld with the given files, which are
+// keyed by their path in the project. The main file must be among them.
+//
+// Unless a font book is configured, the world uses the embedded fonts.
+func NewMemoryWorld(mainPath string, files map[string][]byte, opts ...WorldOption) (*MemoryWorld, error) {
+	w := &MemoryWorld{
+		worldConfig: worldConfig{library: foundations.NewScope()},
+		files:       make(map[syntax.FileId][]byte),
+		sources:     make(map[syntax.FileId]*syntax.Source),
+	}
+	for path, data := range files {
+		if err := w.SetFile(path, data); err != nil {
+			return nil, err
+		}
+	}
+
+	mainFile, err := projectFile(mainPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := w.files[mainFile]; !ok {
+		return nil, fmt.Errorf("main file does not exist: %s", mainPath)
+	}
+	w.mainFile = mainFile
+
+	for _, opt := range opts {
+		opt(&w.worldConfig)
+	}
+
+	if w.fontBook == nil {
+		w.fontBook = font.NewFontBook()
+		fonts, _ := font.LoadEmbeddedFonts()
+		w.fontBook.Add(fonts...)
+	}
+
+	return w, nil
+}
+
+// projectFile returns the ID of a file in the project.
+func projectFile(path string) (syntax.FileId, error) {
+	if len(path) == 0 || path[0] != '/' {
+		path = "/" + path
+	}
+	vpath, err := syntax.NewVirtualPath(path)
+	if err != nil {
+		return syntax.FileId{}, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	return syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern(), nil
+}
+
+// SetFile adds a file to the project or replaces its content.
+func (w *MemoryWorld) SetFile(path string, data []byte) error {
+	id, err := projectFile(path)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.files[id] = data
+	if src, ok := w.sources[id]; ok && string(data) != src.Text() {
+		delete(w.sources, id)
+	}
+	return nil
+}
+
+// RemoveFile removes a file from the project.
+func (w *MemoryWorld) RemoveFile(path string) error {
+	id, err := projectFile(path)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.files, id)
+	delete(w.sources, id)
+	return nil
+}
+
+// Library returns the standard library scope.
+func (w *MemoryWorld) Library() *foundations.Scope {
+	return w.library
+}
+
+// MainFile returns the main source file ID.
+func (w *MemoryWorld) MainFile() syntax.FileId {
+	return w.mainFile
+}
+
+// Source returns the parsed source content for a file.
+//
+// The source is parsed on first access and cached until its file changes.
+func (w *MemoryWorld) Source(id syntax.FileId) (*syntax.Source, error) {
+	w.mu.RLock()
+	src, ok := w.sources[id]
+	w.mu.RUnlock()
+	if ok {
+		return src, nil
+	}
+
+	data, err := w.File(id)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if src, ok := w.sources[id]; ok {
+		return src, nil
+	}
+	src = syntax.NewSource(id, string(data))
+	w.sources[id] = src
+	return src, nil
+}
+
+// File returns the raw bytes of a file.
+func (w *MemoryWorld) File(id syntax.FileId) ([]byte, error) {
+	rpath := id.Get()
+	if rpath == nil {
+		return nil, fmt.Errorf("cannot resolve file ID: no rooted path")
+	}
+
+	if spec := rpath.Package(); spec != nil {
+		if w.packageResolver == nil {
+			return nil, fmt.Errorf("package imports not supported: no package resolver configured")
+		}
+		pkgRoot, err := w.packageResolver.Resolve(spec)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve package %s: %w", spec.Name, err)
+		}
+		path := rpath.VPath().Realize(pkgRoot)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, &FileNotFoundError{Path: path}
+		}
+		return data, err
+	}
+
+	w.mu.RLock()
+	data, ok := w.files[id]
+	w.mu.RUnlock()
+	if !ok {
+		return nil, &FileNotFoundError{Path: rpath.VPath().GetWithSlash()}
+	}
+	return data, nil
+}
+
+// Font returns the font at the given index.
+// Returns an error if the index is out of bounds.
+func (w *MemoryWorld) Font(index int) (*font.Font, error) {
+	f := w.fontBook.Font(index)
+	if f == nil {
+		return nil, fmt.Errorf("font index out of bounds: %d", index)
+	}
+	return f, nil
+}
+
+// FontCount returns the number of available fonts.
+func (w *MemoryWorld) FontCount() int {
+	return w.fontBook.Len()
+}
+
+// FontBook returns the font book for direct font access.
+func (w *MemoryWorld) FontBook() *font.FontBook {
+	return w.fontBook
+}
+
+// Today returns the current date, optionally adjusted by an offset.
+func (w *MemoryWorld) Today(offset *int) *foundations.Datetime {
+	return foundations.Today(offset)
+}
@@ -0,0 +1,58 @@
+package kit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryWorld(t *testing.T) {
+	w, err := NewMemoryWorld("main.typ", map[string][]byte{
+		"main.typ":         []byte("= Hello"),
+		"/images/logo.png": []byte("png"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := w.Source(w.MainFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Text() != "= Hello" {
+		t.Errorf("main source = %q", src.Text())
+	}
+
+	logo, err := w.MainFile().Join("images/logo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := w.File(logo); err != nil || string(data) != "png" {
+		t.Errorf("File(logo) = %q, %v", data, err)
+	}
+
+	// Changing a file gives it a new source and leaves the old one alone.
+	if err := w.SetFile("main.typ", []byte("= World")); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := w.Source(w.MainFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated == src || updated.Text() != "= World" || src.Text() == "= World" {
+		t.Errorf("updated source = %q, same = %v", updated.Text(), updated == src)
+	}
+
+	if err := w.RemoveFile("images/logo.png"); err != nil {
+		t.Fatal(err)
+	}
+	var notFound *FileNotFoundError
+	if _, err := w.File(logo); !errors.As(err, &notFound) || notFound.Path != "/images/logo.png" {
+		t.Errorf("File(removed) error = %v", err)
+	}
+}
+
+func TestMemoryWorldMissingMain(t *testing.T) {
+	if _, err := NewMemoryWorld("main.typ", map[string][]byte{"other.typ": nil}); err == nil {
+		t.Error("expected an error for a missing main file")
+	}
+}
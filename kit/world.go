@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"github.com/boergens/gotypst/font"
@@ -21,7 +22,8 @@ import (
 //
 // FileWorld handles:
 //   - Reading source files from the filesystem
-//   - Caching parsed sources and raw file bytes
+//   - Caching parsed sources and raw file bytes, and revalidating them
+//     across compilations
 //   - Resolving file paths relative to a project root
 //   - Providing the current date for date functions
 //   - Loading and managing fonts
@@ -32,11 +34,11 @@ type FileWorld struct {
 	// mainFile is the main source file being compiled.
 	mainFile syntax.FileId
 
-	// library is the standard library scope.
-	library *foundations.Scope
+	// mainText is the text of the main source if it doesn't come from a
+	// file, like when it's read from stdin, and nil otherwise.
+	mainText []byte
 
-	// fontBook manages loaded fonts.
-	fontBook *font.FontBook
+	worldConfig
 
 	// sourceCache caches parsed sources by file ID.
 	sourceCache map[syntax.FileId]*syntax.Source
@@ -47,8 +49,22 @@ type FileWorld struct {
 	// pathCache maps file IDs to resolved absolute paths.
 	pathCache map[syntax.FileId]string
 
+	// staleSources and staleFiles hold the cached entries that must be
+	// checked against the file system before their next use.
+	staleSources map[syntax.FileId]bool
+	staleFiles   map[syntax.FileId]bool
+
 	// mu protects the caches.
 	mu sync.RWMutex
+}
+
+// worldConfig holds the settings shared by the worlds of this package.
+type worldConfig struct {
+	// library is the standard library scope.
+	library *foundations.Scope
+
+	// fontBook manages loaded fonts.
+	fontBook *font.FontBook
 
 	// packageResolver resolves package specifications to file system paths.
 	// If nil, package imports are not supported.
@@ -62,39 +78,52 @@ type PackageResolver interface {
 	Resolve(spec *syntax.PackageSpec) (string, error)
 }
 
+// WorldOption configures a FileWorld or a MemoryWorld.
+type WorldOption func(*worldConfig)
+
 // FileWorldOption configures a FileWorld.
-type FileWorldOption func(*FileWorld)
+type FileWorldOption = WorldOption
 
 // WithLibrary sets the standard library scope.
-func WithLibrary(lib *foundations.Scope) FileWorldOption {
-	return func(w *FileWorld) {
-		w.library = lib
+func WithLibrary(lib *foundations.Scope) WorldOption {
+	return func(c *worldConfig) {
+		c.library = lib
 	}
 }
 
 // WithPackageResolver sets the package resolver.
-func WithPackageResolver(resolver PackageResolver) FileWorldOption {
-	return func(w *FileWorld) {
-		w.packageResolver = resolver
+func WithPackageResolver(resolver PackageResolver) WorldOption {
+	return func(c *worldConfig) {
+		c.packageResolver = resolver
 	}
 }
 
 // WithFontBook sets the font book for the world.
-// If not set, system fonts will be loaded automatically.
-func WithFontBook(book *font.FontBook) FileWorldOption {
-	return func(w *FileWorld) {
-		w.fontBook = book
+// If not set, a FileWorld loads the system and the embedded fonts and a
+// MemoryWorld only the embedded fonts.
+func WithFontBook(book *font.FontBook) WorldOption {
+	return func(c *worldConfig) {
+		c.fontBook = book
+	}
+}
+
+// WithFontSearch loads the fonts found with the given search options. Fonts
+// from the font paths take precedence over the system fonts, which take
+// precedence over the embedded fonts.
+func WithFontSearch(opts font.SearchOptions) WorldOption {
+	return func(c *worldConfig) {
+		c.fontBook = font.SearchFonts(opts)
 	}
 }
 
 // WithFontDirs loads fonts from the specified directories.
-func WithFontDirs(dirs ...string) FileWorldOption {
-	return func(w *FileWorld) {
+func WithFontDirs(dirs ...string) WorldOption {
+	return func(c *worldConfig) {
 		fonts, _ := font.DiscoverFonts(dirs)
-		if w.fontBook == nil {
-			w.fontBook = font.NewFontBook()
+		if c.fontBook == nil {
+			c.fontBook = font.NewFontBook()
 		}
-		w.fontBook.Add(fonts...)
+		c.fontBook.Add(fonts...)
 	}
 }
 
@@ -144,34 +173,65 @@ func NewFileWorld(root string, mainPath string, opts ...FileWorldOption) (*FileW
 		vpath, _ = syntax.NewVirtualPath("/main.typ")
 	}
 	rpath := syntax.NewRootedPath(syntax.ProjectRoot(), *vpath)
-	mainFileId := rpath.Intern()
+	w := newFileWorld(absRoot, rpath.Intern(), opts)
 
-	w := &FileWorld{
-		root:        absRoot,
-		mainFile:    mainFileId,
-		library:     foundations.NewScope(),
-		sourceCache: make(map[syntax.FileId]*syntax.Source),
-		fileCache:   make(map[syntax.FileId][]byte),
-		pathCache:   make(map[syntax.FileId]string),
+	// Store the path mapping
+	w.pathCache[w.mainFile] = absMainPath
+	return w, nil
+}
+
+// StdinPath is the virtual path of the main file of a world created with
+// NewStdinWorld. Diagnostics in the main source point to it.
+const StdinPath = "/<stdin>"
+
+// NewStdinWorld creates a FileWorld whose main source is the given text,
+// typically read from stdin, rather than a file. All other files are
+// resolved relative to the root directory, as with NewFileWorld.
+//
+// Matches Rust: SystemWorld::new with Input::Stdin
+func NewStdinWorld(root string, text []byte, opts ...FileWorldOption) (*FileWorld, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve project root: %w", err)
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("project root does not exist: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("project root is not a directory: %s", absRoot)
 	}
 
-	// Store the path mapping
-	w.pathCache[mainFileId] = absMainPath
+	vpath, err := syntax.NewVirtualPath(StdinPath)
+	if err != nil {
+		return nil, err
+	}
+	w := newFileWorld(absRoot, syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern(), opts)
+	w.mainText = text
+	return w, nil
+}
 
+// newFileWorld creates a FileWorld with empty caches and applies the
+// options. The system and embedded fonts are loaded if no font book was
+// provided.
+func newFileWorld(root string, mainFile syntax.FileId, opts []FileWorldOption) *FileWorld {
+	w := &FileWorld{
+		root:         root,
+		mainFile:     mainFile,
+		worldConfig:  worldConfig{library: foundations.NewScope()},
+		sourceCache:  make(map[syntax.FileId]*syntax.Source),
+		fileCache:    make(map[syntax.FileId][]byte),
+		pathCache:    make(map[syntax.FileId]string),
+		staleSources: make(map[syntax.FileId]bool),
+		staleFiles:   make(map[syntax.FileId]bool),
+	}
 	for _, opt := range opts {
-		opt(w)
+		opt(&w.worldConfig)
 	}
-
-	// Load system fonts if no font book was provided
 	if w.fontBook == nil {
-		w.fontBook, _ = font.SystemFontBook()
-		if w.fontBook == nil {
-			// Fallback to empty font book
-			w.fontBook = font.NewFontBook()
-		}
+		w.fontBook = font.SearchFonts(font.SearchOptions{})
 	}
-
-	return w, nil
+	return w
 }
 
 // Library returns the standard library scope.
@@ -186,67 +246,60 @@ func (w *FileWorld) MainFile() syntax.FileId {
 
 // Source returns the parsed source content for a file.
 //
-// The source is cached after first access. If the file cannot be read
-// or parsed, an error is returned.
+// The source is cached after first access. After a Reset, the file is read
+// again: if its content is unchanged, the cached source is returned as is,
+// and otherwise it is parsed anew. A changed file gets a new source rather
+// than an updated one, since the old one may still be in use by an earlier
+// compilation. If the file cannot be read or parsed, an error is returned.
 func (w *FileWorld) Source(id syntax.FileId) (*syntax.Source, error) {
 	// Check cache first
 	w.mu.RLock()
-	if src, ok := w.sourceCache[id]; ok {
-		w.mu.RUnlock()
+	src, cached := w.sourceCache[id]
+	stale := w.staleSources[id]
+	w.mu.RUnlock()
+	if cached && !stale {
 		return src, nil
 	}
-	w.mu.RUnlock()
 
-	// Resolve the path
-	path, err := w.resolvePath(id)
+	content, err := w.load(id, "source file")
 	if err != nil {
 		return nil, err
 	}
 
-	// Read the file
-	content, err := w.readFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read source file %s: %w", path, err)
-	}
-
-	// Parse the source
-	src := syntax.NewSource(id, string(content))
-
-	// Cache it
 	w.mu.Lock()
-	w.sourceCache[id] = src
-	w.mu.Unlock()
+	defer w.mu.Unlock()
+	if !cached || string(content) != src.Text() {
+		src = syntax.NewSource(id, string(content))
+		w.sourceCache[id] = src
+	}
+	delete(w.staleSources, id)
 
 	return src, nil
 }
 
 // File returns the raw bytes of a file.
 //
-// The file content is cached after first access.
+// The file content is cached after first access and read again after a
+// Reset.
 func (w *FileWorld) File(id syntax.FileId) ([]byte, error) {
 	// Check cache first
 	w.mu.RLock()
-	if data, ok := w.fileCache[id]; ok {
-		w.mu.RUnlock()
+	data, cached := w.fileCache[id]
+	stale := w.staleFiles[id]
+	w.mu.RUnlock()
+	if cached && !stale {
 		return data, nil
 	}
-	w.mu.RUnlock()
 
-	// Resolve the path
-	path, err := w.resolvePath(id)
+	data, err := w.load(id, "file")
 	if err != nil {
 		return nil, err
 	}
 
-	// Read the file
-	data, err := w.readFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read file %s: %w", path, err)
-	}
-
 	// Cache it
 	w.mu.Lock()
 	w.fileCache[id] = data
+	delete(w.staleFiles, id)
 	w.mu.Unlock()
 
 	return data, nil
@@ -313,8 +366,9 @@ func (w *FileWorld) resolvePath(id syntax.FileId) (string, error) {
 
 		vpath := rpath.VPath()
 		absPath = vpath.Realize(pkgRoot)
-	} else if root == syntax.ProjectRoot() {
-		// Project file - resolve relative to root
+	} else if _, ok := root.(*syntax.VirtualRootProject); ok {
+		// Project file - resolve relative to root. Roots are compared by
+		// type, since pointers to the empty project root need not be equal.
 		vpath := rpath.VPath()
 		absPath = vpath.Realize(w.root)
 	} else {
@@ -329,6 +383,24 @@ func (w *FileWorld) resolvePath(id syntax.FileId) (string, error) {
 	return absPath, nil
 }
 
+// load returns the contents of a file, which is read from the file system
+// unless it's a main source that doesn't come from a file. what names the
+// kind of file in errors.
+func (w *FileWorld) load(id syntax.FileId, what string) ([]byte, error) {
+	if id == w.mainFile && w.mainText != nil {
+		return w.mainText, nil
+	}
+	path, err := w.resolvePath(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := w.readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s %s: %w", what, path, err)
+	}
+	return data, nil
+}
+
 // readFile reads a file from the filesystem.
 func (w *FileWorld) readFile(path string) ([]byte, error) {
 	data, err := os.ReadFile(path)
@@ -341,6 +413,23 @@ func (w *FileWorld) readFile(path string) ([]byte, error) {
 	return data, nil
 }
 
+// Reset prepares the world for another compilation. Cached sources and
+// files are kept, but checked for changes on their next access. Unchanged
+// sources keep their identity, so that results memoized for them stay
+// valid.
+//
+// Matches Rust: SystemWorld::reset
+func (w *FileWorld) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id := range w.sourceCache {
+		w.staleSources[id] = true
+	}
+	for id := range w.fileCache {
+		w.staleFiles[id] = true
+	}
+}
+
 // ClearCache clears all cached sources and files.
 func (w *FileWorld) ClearCache() {
 	w.mu.Lock()
@@ -370,6 +459,32 @@ func (w *FileWorld) Root() string {
 	return w.root
 }
 
+// Dependencies returns the absolute paths of the files the world has read,
+// each sorted: the sources, like the main file and the files it imports
+// and includes, and the raw files, like images, data, and bibliographies.
+// Files that could not be read, and a main source that doesn't come from a
+// file, are left out. Files read before a Reset are still included;
+// ClearCache forgets them.
+//
+// Matches Rust: SystemWorld::dependencies
+func (w *FileWorld) Dependencies() (sources, files []string) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for id := range w.sourceCache {
+		if path, ok := w.pathCache[id]; ok {
+			sources = append(sources, path)
+		}
+	}
+	for id := range w.fileCache {
+		if path, ok := w.pathCache[id]; ok {
+			files = append(files, path)
+		}
+	}
+	slices.Sort(sources)
+	slices.Sort(files)
+	return sources, files
+}
+
 // ----------------------------------------------------------------------------
 // Errors
 // ----------------------------------------------------------------------------
@@ -0,0 +1,129 @@
+package kit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestFileWorldDependencies(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"main.typ":        `#include "chapter.typ"`,
+		"chapter.typ":     "= Chapter",
+		"data/table.csv":  "a,b",
+		"unused/note.typ": "unused",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewFileWorld(root, "main.typ", WithFontBook(font.NewFontBook()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	join := func(path string) syntax.FileId {
+		id, err := w.MainFile().Join(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	for _, id := range []syntax.FileId{w.MainFile(), join("chapter.typ")} {
+		if _, err := w.Source(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.File(join("data/table.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.File(join("missing.png")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+
+	sources, files := w.Dependencies()
+	wantSources := []string{filepath.Join(root, "chapter.typ"), filepath.Join(root, "main.typ")}
+	wantFiles := []string{filepath.Join(root, "data", "table.csv")}
+	if !slices.Equal(sources, wantSources) || !slices.Equal(files, wantFiles) {
+		t.Errorf("Dependencies() = %v, %v; want %v, %v", sources, files, wantSources, wantFiles)
+	}
+}
+
+func TestStdinWorld(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "chapter.typ"), []byte("= Chapter"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewStdinWorld(root, []byte(`#include "chapter.typ"`), WithFontBook(font.NewFontBook()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.MainFile().Get().VPath().GetWithSlash(); got != StdinPath {
+		t.Errorf("main file is %s, want %s", got, StdinPath)
+	}
+	src, err := w.Source(w.MainFile())
+	if err != nil || src.Text() != `#include "chapter.typ"` {
+		t.Fatalf("Source(main) = %v, %v", src, err)
+	}
+
+	// Other files are read relative to the root.
+	chapter, err := w.MainFile().Join("chapter.typ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Source(chapter); err != nil {
+		t.Fatal(err)
+	}
+
+	// The main source is kept across compilations and isn't a dependency.
+	w.Reset()
+	if again, err := w.Source(w.MainFile()); err != nil || again != src {
+		t.Errorf("Source(main) after Reset = %v, %v", again, err)
+	}
+	sources, _ := w.Dependencies()
+	if want := []string{filepath.Join(root, "chapter.typ")}; !slices.Equal(sources, want) {
+		t.Errorf("Dependencies() sources = %v, want %v", sources, want)
+	}
+}
+
+func TestFileWorldSourceChanged(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.typ")
+	if err := os.WriteFile(path, []byte("= Old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewFileWorld(root, "main.typ", WithFontBook(font.NewFontBook()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := w.Source(w.MainFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A changed file gets a new source and leaves the old one alone.
+	if err := os.WriteFile(path, []byte("= New"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.Reset()
+	src, err := w.Source(w.MainFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src == old || src.Text() != "= New" || old.Text() != "= Old" {
+		t.Errorf("after a change, Source = %q (same: %v), old source = %q", src.Text(), src == old, old.Text())
+	}
+	if again, _ := w.Source(w.MainFile()); again != src {
+		t.Error("the new source is not cached")
+	}
+}
@@ -3,4 +3,24 @@
 // This package is a Go translation of typst-layout from the original Typst
 // compiler. It converts abstract document content into positioned frames
 // ready for rendering.
+//
+// # Frames
+//
+// Frame is the output of layout and the input of every exporter. A frame
+// holds positioned items: groups of nested frames with an optional
+// transformation and clip, text, shapes, images, and links. Frame.All
+// iterates over the items of a frame, and Frame.Walk over the items of a
+// frame and its groups, each with its transformation to the frame's
+// coordinates. The frame model only depends on this package, so exporters
+// for other formats can be built outside of this repository.
+//
+// # Regions
+//
+// Layouters lay content out into regions. A Region is the size available
+// to a single frame and whether the frame expands to fill it; Regions are
+// a sequence of them, like the pages of a flow, each as wide as the first.
+// A region of infinite width or height is unbounded in that direction, as
+// in a container that sizes itself to its content; frames laid out into
+// it take the size of their content. The flow, grid, and math layouters
+// share these types.
 package layout
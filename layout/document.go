@@ -0,0 +1,65 @@
+// Paged documents for Typst.
+// Translated from typst-library/src/layout/page.rs
+
+package layout
+
+// PagedDocument represents a fully laid out document.
+type PagedDocument struct {
+	// Pages contains the laid out pages.
+	Pages []Page
+	// Info contains document metadata.
+	Info DocumentInfo
+	// Attachments are the files embedded into the document.
+	Attachments []Attachment
+}
+
+// Attachment is a file embedded into the document.
+type Attachment struct {
+	// Name is the file name of the attachment.
+	Name string
+	// Data is the content of the file.
+	Data []byte
+	// MimeType is the MIME type of the file, if known.
+	MimeType string
+	// Description describes the file, if there is a description.
+	Description string
+	// Relationship is how the file relates to the document: "source",
+	// "data", "alternative", "supplement", or empty if unspecified.
+	Relationship string
+}
+
+// DocumentInfo contains document metadata.
+type DocumentInfo struct {
+	Title       *string
+	Author      []string
+	Description *string
+	Keywords    []string
+	Date        *Date
+}
+
+// Date represents a date value.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// Page represents a single laid out page.
+type Page struct {
+	// Frame contains the page content.
+	Frame Frame
+	// Fill is the page background fill.
+	Fill *Paint
+	// Numbering is the page numbering pattern.
+	Numbering *Numbering
+	// Supplement is the plain text of the page supplement.
+	Supplement string
+	// Number is the logical page number.
+	Number int
+}
+
+// Numbering represents a page numbering pattern.
+type Numbering struct {
+	// Pattern is the numbering pattern string.
+	Pattern string
+}
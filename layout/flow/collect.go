@@ -3,6 +3,8 @@ package flow
 import (
 	"github.com/boergens/gotypst/eval"
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
 )
 
 // Collector converts content elements into flow layout children.
@@ -20,6 +22,8 @@ type Collector struct {
 	children []Child
 	// lastWasSpacing tracks if the last child was spacing.
 	lastWasSpacing bool
+	// parSituation is the situation of the next paragraph.
+	parSituation model.ParSituation
 }
 
 // StyleChain represents a chain of styles for content.
@@ -82,6 +86,8 @@ func (c *Collector) collectElement(elem eval.ContentElement) {
 		return
 	}
 
+	defer c.updateParSituation(elem)
+
 	switch e := elem.(type) {
 	// Text and inline elements
 	case *eval.TextElement:
@@ -98,6 +104,8 @@ func (c *Collector) collectElement(elem eval.ContentElement) {
 		c.collectParagraph(e)
 
 	// Block elements
+	case *eval.BlockElement:
+		c.collectBlock(e)
 	case *eval.HeadingElement:
 		c.collectHeading(e)
 	case *eval.RawElement:
@@ -111,11 +119,21 @@ func (c *Collector) collectElement(elem eval.ContentElement) {
 	case *eval.TermsElement:
 		c.collectTerms(e)
 
+	// Spacing
+	case *eval.VElem:
+		c.collectV(e)
+
 	// Layout elements
 	case *eval.StackElement:
 		c.collectStack(e)
 	case *eval.AlignElement:
 		c.collectAlign(e)
+	case *eval.ColumnsElement:
+		c.collectColumns(e)
+	case *eval.ColbreakElem:
+		c.collectColbreak(e)
+	case *eval.PlaceElement:
+		c.collectPlace(e)
 
 	// Styling elements
 	case *eval.StrongElement:
@@ -159,6 +177,12 @@ func (c *Collector) collectElement(elem eval.ContentElement) {
 	}
 }
 
+// updateParSituation tracks whether the next paragraph directly follows
+// another one.
+func (c *Collector) updateParSituation(elem eval.ContentElement) {
+	c.parSituation = nextParSituation(c.parSituation, elem)
+}
+
 // collectText handles text elements.
 // Text is collected into inline content that will become lines.
 func (c *Collector) collectText(elem *eval.TextElement) {
@@ -188,24 +212,88 @@ func (c *Collector) collectParbreak(elem *eval.ParbreakElement) {
 	// Add relative spacing with weakness (can be collapsed).
 	spacing := c.getParSpacing()
 	if spacing > 0 {
-		c.addRelSpacing(spacing, 1) // weakness 1 = collapsible
+		c.addRelSpacing(Rel{Abs: spacing}, 1) // weakness 1 = collapsible
 	}
 	c.lastWasSpacing = true
 }
 
+// collectV handles vertical spacing elements.
+// Weak spacing collapses with adjacent weak spacing and at region
+// boundaries, fractional spacing shares the remaining space of a region.
+func (c *Collector) collectV(elem *eval.VElem) {
+	var weakness uint8
+	if elem.Weak {
+		weakness = 1
+	}
+	if elem.Amount.IsFrac() {
+		c.addFrSpacing(layout.Fr(elem.Amount.Fr.Value), weakness)
+		return
+	}
+	c.addRelSpacing(Rel{
+		Abs:   layout.Abs(elem.Amount.Rel.Abs.Points),
+		Ratio: elem.Amount.Rel.Rel.Value,
+	}, weakness)
+}
+
 // collectParagraph handles paragraph elements.
 func (c *Collector) collectParagraph(elem *eval.ParagraphElement) {
 	// Paragraphs are multi-child blocks that can break across regions.
 	// The actual line breaking happens in inline layout.
 	align := c.getParagraphAlignment()
 	c.children = append(c.children, &MultiChild{
-		Align:  align,
-		Sticky: false,
-		Alone:  false,
+		Align:     align,
+		Sticky:    false,
+		Alone:     false,
+		Situation: c.parSituation,
+		Span:      foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
 
+// collectLines adds the lines of a laid-out paragraph. Lines are separated
+// by the leading, which is measured from the bottom edge of one line to
+// the top edge of the next, so the text's edges determine how far apart
+// the baselines are. Unless their costs are zero, the first two and the
+// last two lines are kept together to prevent orphans and widows.
+// Matches Rust: Collector::lines
+func (c *Collector) collectLines(lines []Frame, leading layout.Abs, align Axes[FixedAlignment], costs Costs) {
+	n := len(lines)
+	preventOrphans := costs.Orphan > 0 && n >= 2 && !lines[1].IsEmpty()
+	preventWidows := costs.Widow > 0 && n >= 2 && !lines[n-2].IsEmpty()
+	preventAll := n == 3 && preventOrphans && preventWidows
+
+	heightAt := func(i int) layout.Abs {
+		if i < 0 || i >= n {
+			return 0
+		}
+		return lines[i].Height()
+	}
+	front1, front2 := heightAt(0), heightAt(1)
+	back2, back1 := heightAt(n-2), heightAt(n-1)
+
+	for i, frame := range lines {
+		if i > 0 {
+			c.addRelSpacing(Rel{Abs: leading}, 5)
+		}
+
+		line := &LineChild{Frame: frame, Align: align, Need: frame.Height()}
+		switch {
+		case preventAll && i == 0:
+			line.Need = front1 + leading + front2 + leading + back1
+			line.Cost = max(costs.Orphan, costs.Widow)
+		case preventOrphans && i == 0:
+			line.Need = front1 + leading + front2
+			line.Cost = costs.Orphan
+		case preventWidows && i >= 2 && i+2 == n:
+			line.Need = back2 + leading + back1
+			line.Cost = costs.Widow
+		}
+
+		c.children = append(c.children, line)
+	}
+	c.lastWasSpacing = false
+}
+
 // collectHeading handles heading elements.
 func (c *Collector) collectHeading(elem *eval.HeadingElement) {
 	// Headings are sticky blocks - they shouldn't be alone at region bottom.
@@ -214,10 +302,33 @@ func (c *Collector) collectHeading(elem *eval.HeadingElement) {
 		Align:  align,
 		Sticky: true, // Headings stick to following content
 		Alone:  true, // Can be alone (for chapter-start headings)
+		Span:   foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
 
+// collectBlock handles block elements. Breakable blocks may span several
+// regions, unbreakable ones are kept in one. A sticky block moves to the
+// next region together with the block after it.
+// Matches Rust: Collector::block
+func (c *Collector) collectBlock(elem *eval.BlockElement) {
+	align := c.getBlockAlignment()
+	if elem.Breakable != nil && !*elem.Breakable {
+		c.children = append(c.children, &SingleChild{
+			Align:  align,
+			Sticky: elem.Sticky,
+			Span:   foundations.ElementSpan(elem),
+		})
+	} else {
+		c.children = append(c.children, &MultiChild{
+			Align:  align,
+			Sticky: elem.Sticky,
+			Span:   foundations.ElementSpan(elem),
+		})
+	}
+	c.lastWasSpacing = false
+}
+
 // collectRaw handles raw/code elements.
 func (c *Collector) collectRaw(elem *eval.RawElement) {
 	if elem.Block {
@@ -227,6 +338,7 @@ func (c *Collector) collectRaw(elem *eval.RawElement) {
 			Align:  align,
 			Sticky: false,
 			Alone:  false,
+			Span:   foundations.ElementSpan(elem),
 		})
 	}
 	// Inline raw elements are handled as part of paragraph content.
@@ -241,6 +353,7 @@ func (c *Collector) collectList(elem *eval.ListElement) {
 		Align:  align,
 		Sticky: false,
 		Alone:  false,
+		Span:   foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
@@ -253,6 +366,7 @@ func (c *Collector) collectEnum(elem *eval.EnumElement) {
 		Align:  align,
 		Sticky: false,
 		Alone:  false,
+		Span:   foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
@@ -265,6 +379,7 @@ func (c *Collector) collectTerms(elem *eval.TermsElement) {
 		Align:  align,
 		Sticky: false,
 		Alone:  false,
+		Span:   foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
@@ -281,6 +396,7 @@ func (c *Collector) collectStack(elem *eval.StackElement) {
 			Align:  align,
 			Sticky: false,
 			Alone:  false,
+			Span:   foundations.ElementSpan(elem),
 		})
 	} else {
 		// Horizontal stack - single unbreakable block
@@ -289,11 +405,91 @@ func (c *Collector) collectStack(elem *eval.StackElement) {
 			Align:  align,
 			Sticky: false,
 			Alone:  false,
+			Span:   foundations.ElementSpan(elem),
 		})
 	}
 	c.lastWasSpacing = false
 }
 
+// collectColumns handles columns elements. Their body is collected into a
+// nested flow that a breakable block lays out in columns. The gutter
+// defaults to 4% of the width.
+// Matches Rust: ColumnsElem::layout
+func (c *Collector) collectColumns(elem *eval.ColumnsElement) {
+	gutter := Rel{Ratio: 0.04}
+	if elem.Gutter != nil {
+		gutter = Rel{
+			Abs:   layout.Abs(elem.Gutter.Abs.Points),
+			Ratio: elem.Gutter.Rel.Value,
+		}
+	}
+	inner := NewCollector(c.engine, FlowModeBlock, c.styles, c.locator)
+	inner.collectContent(&elem.Body)
+	c.children = append(c.children, &MultiChild{
+		Align: c.getBlockAlignment(),
+		Columns: &MultiColumns{
+			Count:    elem.CountInt(),
+			Gutter:   gutter,
+			Children: inner.children,
+		},
+		Span: foundations.ElementSpan(elem),
+	})
+	c.lastWasSpacing = false
+}
+
+// collectColbreak handles column breaks. A weak break is ignored at the
+// start of a column.
+// Matches Rust: Collector::run for ColbreakElem
+func (c *Collector) collectColbreak(elem *eval.ColbreakElem) {
+	c.children = append(c.children, BreakChild{Weak: elem.Weak})
+	c.lastWasSpacing = false
+}
+
+// collectPlace handles place elements. Floats with auto alignment leave
+// the vertical alignment unset; the composer then picks the top or the
+// bottom of the region. The clearance defaults to 1.5em.
+// Matches Rust: Collector::place
+func (c *Collector) collectPlace(elem *eval.PlaceElement) {
+	placed := &PlacedChild{
+		Float:     elem.Float,
+		Clearance: layout.Abs(16.5), // 1.5em at 11pt
+		location:  c.locator.Next(),
+		Span:      foundations.ElementSpan(elem),
+	}
+	align := elem.Align()
+	if align.Horizontal != nil {
+		switch string(*align.Horizontal) {
+		case "center":
+			placed.AlignX = FixedAlignCenter
+		case "right", "end":
+			placed.AlignX = FixedAlignEnd
+		}
+	}
+	if align.Vertical != nil {
+		var y FixedAlignment
+		switch string(*align.Vertical) {
+		case "horizon":
+			y = FixedAlignCenter
+		case "bottom":
+			y = FixedAlignEnd
+		}
+		placed.AlignY = &y
+	}
+	if elem.Scope == "parent" {
+		placed.Scope = PlacementScopePage
+	}
+	if elem.Clearance != nil {
+		placed.Clearance = layout.Abs(elem.Clearance.Points)
+	}
+	if elem.Dx != nil {
+		placed.Delta.X = Rel{Abs: layout.Abs(elem.Dx.Abs.Points), Ratio: elem.Dx.Rel.Value}
+	}
+	if elem.Dy != nil {
+		placed.Delta.Y = Rel{Abs: layout.Abs(elem.Dy.Abs.Points), Ratio: elem.Dy.Rel.Value}
+	}
+	c.children = append(c.children, placed)
+}
+
 // collectAlign handles alignment elements.
 func (c *Collector) collectAlign(elem *eval.AlignElement) {
 	// Aligned content modifies the alignment of its body.
@@ -335,6 +531,7 @@ func (c *Collector) collectEquation(elem *eval.EquationElement) {
 			Align:  align,
 			Sticky: false,
 			Alone:  false,
+			Span:   foundations.ElementSpan(elem),
 		})
 	}
 	// Inline math is handled as part of paragraph content.
@@ -409,6 +606,7 @@ func (c *Collector) collectImage(elem *eval.ImageElement) {
 		Align:  align,
 		Sticky: false,
 		Alone:  false,
+		Span:   foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
@@ -421,14 +619,15 @@ func (c *Collector) collectUnknown(elem eval.ContentElement) {
 		Align:  align,
 		Sticky: false,
 		Alone:  false,
+		Span:   foundations.ElementSpan(elem),
 	})
 	c.lastWasSpacing = false
 }
 
 // addRelSpacing adds relative spacing to the children.
-func (c *Collector) addRelSpacing(amount layout.Abs, weakness uint8) {
+func (c *Collector) addRelSpacing(amount Rel, weakness uint8) {
 	c.children = append(c.children, RelChild{
-		Amount:   Rel{Abs: amount},
+		Amount:   amount,
 		Weakness: weakness,
 	})
 	c.lastWasSpacing = true
@@ -1,9 +1,13 @@
 package flow
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
 )
 
 func TestCollectEmpty(t *testing.T) {
@@ -71,6 +75,34 @@ func TestCollectParagraph(t *testing.T) {
 	}
 }
 
+func TestCollectParSituation(t *testing.T) {
+	par := func() *eval.ParagraphElement {
+		return &eval.ParagraphElement{Body: eval.Content{
+			Elements: []eval.ContentElement{&eval.TextElement{Text: "Text"}},
+		}}
+	}
+	content := &eval.Content{
+		Elements: []eval.ContentElement{
+			par(),
+			&eval.ParbreakElement{},
+			par(),
+			&eval.HeadingElement{Depth: 1},
+			par(),
+		},
+	}
+
+	var situations []model.ParSituation
+	for _, child := range Collect(&Engine{}, content, FlowModeBlock, StyleChain{}, &Locator{}) {
+		if multi, ok := child.(*MultiChild); ok {
+			situations = append(situations, multi.Situation)
+		}
+	}
+	want := []model.ParSituation{model.ParFirst, model.ParConsecutive, model.ParOther}
+	if !slices.Equal(situations, want) {
+		t.Errorf("situations = %v, want %v", situations, want)
+	}
+}
+
 func TestCollectHeading(t *testing.T) {
 	engine := &Engine{}
 	content := &eval.Content{
@@ -295,3 +327,146 @@ func TestCollectStack(t *testing.T) {
 		t.Errorf("expected SingleChild for horizontal stack, got %T", childrenH[0])
 	}
 }
+
+func TestCollectLines(t *testing.T) {
+	c := NewCollector(&Engine{}, FlowModeBlock, StyleChain{}, &Locator{})
+	var lines []Frame
+	for i := 0; i < 4; i++ {
+		line := NewFrame(layout.Size{Width: 100, Height: 10})
+		line.Push(layout.Point{}, FrameItemTag{})
+		lines = append(lines, line)
+	}
+	c.collectLines(lines, 5, Axes[FixedAlignment]{}, DefaultCosts())
+
+	// Four lines separated by three leadings.
+	if len(c.children) != 7 {
+		t.Fatalf("expected 7 children, got %d", len(c.children))
+	}
+	leading, ok := c.children[1].(RelChild)
+	if !ok || leading.Amount.Abs != 5 || leading.Weakness != 5 {
+		t.Errorf("expected weak leading of 5pt, got %#v", c.children[1])
+	}
+
+	wantNeed := []layout.Abs{25, 10, 25, 10}
+	for i, want := range wantNeed {
+		line, ok := c.children[2*i].(*LineChild)
+		if !ok {
+			t.Fatalf("child %d: expected line, got %T", 2*i, c.children[2*i])
+		}
+		if line.Need != want {
+			t.Errorf("line %d: need = %v, want %v", i, line.Need, want)
+		}
+	}
+}
+
+func TestCollectStickyBlock(t *testing.T) {
+	unbreakable := false
+	content := &eval.Content{
+		Elements: []eval.ContentElement{
+			&eval.BlockElement{Sticky: true},
+			&eval.BlockElement{Breakable: &unbreakable, Sticky: true},
+			&eval.BlockElement{},
+		},
+	}
+
+	children := Collect(&Engine{}, content, FlowModeBlock, StyleChain{}, &Locator{})
+	if len(children) != 3 {
+		t.Fatalf("expected 3 children, got %d", len(children))
+	}
+	if multi, ok := children[0].(*MultiChild); !ok || !multi.Sticky {
+		t.Errorf("expected sticky MultiChild, got %#v", children[0])
+	}
+	if single, ok := children[1].(*SingleChild); !ok || !single.Sticky {
+		t.Errorf("expected sticky SingleChild, got %#v", children[1])
+	}
+	if multi, ok := children[2].(*MultiChild); !ok || multi.Sticky {
+		t.Errorf("expected non-sticky MultiChild, got %#v", children[2])
+	}
+}
+
+func TestCollectColumns(t *testing.T) {
+	count := int64(3)
+	content := &eval.Content{
+		Elements: []eval.ContentElement{
+			&eval.ColumnsElement{
+				Count: &count,
+				Body: eval.Content{Elements: []eval.ContentElement{
+					&eval.BlockElement{},
+					&eval.ColbreakElem{Weak: true},
+					&eval.BlockElement{},
+				}},
+			},
+		},
+	}
+
+	children := Collect(&Engine{}, content, FlowModeBlock, StyleChain{}, &Locator{})
+	if len(children) != 1 {
+		t.Fatalf("expected 1 child, got %d", len(children))
+	}
+	multi, ok := children[0].(*MultiChild)
+	if !ok || multi.Columns == nil {
+		t.Fatalf("expected MultiChild with columns, got %#v", children[0])
+	}
+	if multi.Columns.Count != 3 || multi.Columns.Gutter != (Rel{Ratio: 0.04}) {
+		t.Errorf("columns = %d with gutter %v", multi.Columns.Count, multi.Columns.Gutter)
+	}
+	if len(multi.Columns.Children) != 3 {
+		t.Fatalf("expected 3 children in columns, got %d", len(multi.Columns.Children))
+	}
+	if brk, ok := multi.Columns.Children[1].(BreakChild); !ok || !brk.Weak {
+		t.Errorf("expected weak BreakChild, got %#v", multi.Columns.Children[1])
+	}
+}
+
+func TestCollectPlace(t *testing.T) {
+	clearance := foundations.Length{Points: 4}
+	content := &eval.Content{
+		Elements: []eval.ContentElement{
+			&eval.PlaceElement{Alignment: foundations.Str("bottom"), Float: true, Clearance: &clearance},
+			&eval.PlaceElement{Alignment: foundations.Auto, Float: true},
+			&eval.PlaceElement{Alignment: foundations.Str("right")},
+		},
+	}
+
+	children := Collect(&Engine{}, content, FlowModeBlock, StyleChain{}, &Locator{})
+	if len(children) != 3 {
+		t.Fatalf("expected 3 children, got %d", len(children))
+	}
+	bottom := children[0].(*PlacedChild)
+	if bottom.AlignY == nil || *bottom.AlignY != FixedAlignEnd || !bottom.Float || bottom.Clearance != 4 {
+		t.Errorf("unexpected bottom float %#v", bottom)
+	}
+	auto := children[1].(*PlacedChild)
+	if auto.AlignY != nil || auto.Clearance != 16.5 {
+		t.Errorf("unexpected auto float %#v", auto)
+	}
+	right := children[2].(*PlacedChild)
+	if right.Float || right.AlignX != FixedAlignEnd || right.AlignY != nil {
+		t.Errorf("unexpected placed child %#v", right)
+	}
+	if bottom.Location() == auto.Location() {
+		t.Error("placed children share a location")
+	}
+}
+
+func TestCollectLinesCosts(t *testing.T) {
+	var lines []Frame
+	for i := 0; i < 4; i++ {
+		line := NewFrame(layout.Size{Width: 100, Height: 10})
+		line.Push(layout.Point{}, FrameItemTag{})
+		lines = append(lines, line)
+	}
+
+	// Orphans are allowed, widows are kept at half the cost.
+	c := NewCollector(&Engine{}, FlowModeBlock, StyleChain{}, &Locator{})
+	c.collectLines(lines, 5, Axes[FixedAlignment]{}, Costs{Widow: 0.5})
+
+	wantNeed := []layout.Abs{10, 10, 25, 10}
+	wantCost := []float64{0, 0, 0.5, 0}
+	for i := range wantNeed {
+		line := c.children[2*i].(*LineChild)
+		if line.Need != wantNeed[i] || line.Cost != wantCost[i] {
+			t.Errorf("line %d: need = %v, cost = %v, want %v, %v", i, line.Need, line.Cost, wantNeed[i], wantCost[i])
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package flow
+
+import (
+	"github.com/boergens/gotypst/layout"
+)
+
+// balanceTolerance is how close the search for the height of balanced
+// columns gets to the smallest height that fits.
+const balanceTolerance layout.Abs = 0.5
+
+// Compose distributes the composer's work into as many regions as needed
+// and returns one frame per region. Sticky blocks at the end of a region,
+// like headings, move on to the next region together with the block they
+// stick to.
+//
+// Matches Rust: layout_fragment_impl in typst-layout/src/flow/mod.rs
+func Compose(composer *Composer, regions Regions) ([]Frame, error) {
+	var frames []Frame
+	for {
+		frame, err := composeRegion(composer, regions)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+
+		if composer.Work.Done() && (!regions.Expand.Y || len(regions.Backlog) == 0) {
+			return frames, nil
+		}
+		regions.Next()
+	}
+}
+
+// composeRegion lays out the columns of a region and arranges them side by
+// side. If the work ends in the region and the columns are balanced, the
+// columns are made about equally tall.
+//
+// Matches Rust: Composer::columns
+func composeRegion(composer *Composer, regions Regions) (Frame, error) {
+	columns := composer.Config.Columns
+	if columns.Count <= 1 {
+		return composeColumn(composer, regions)
+	}
+
+	init := composer.Work.Clone()
+	height := regions.Size.Height
+	children, err := composeColumns(composer, columns.Count, columnRegions(columns, regions, height))
+	if err != nil {
+		return Frame{}, err
+	}
+	if columns.Balance && composer.Work.Done() {
+		if children, err = balance(composer, init, regions, children); err != nil {
+			return Frame{}, err
+		}
+	}
+
+	size := layout.Size{Width: regions.Size.Width, Height: height}
+	if !regions.Expand.Y {
+		size.Height = 0
+		for i := range children {
+			size.Height = max(size.Height, children[i].Height())
+		}
+	}
+
+	output := NewFrame(size)
+	var offset layout.Abs
+	for _, column := range children {
+		output.PushFrame(layout.Point{X: offset}, column)
+		offset += column.Width() + columns.Gutter
+	}
+	return output, nil
+}
+
+// composeColumns distributes the work into up to count columns and returns
+// one frame per column.
+func composeColumns(composer *Composer, count int, inner Regions) ([]Frame, error) {
+	var children []Frame
+	for range count {
+		frame, err := composeColumn(composer, inner)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, frame)
+		if composer.Work.Done() {
+			break
+		}
+		inner.Next()
+	}
+	return children, nil
+}
+
+// composeColumn distributes as much of the work as fits into a single
+// column. Each column handles the footnotes and floats of its own content,
+// so a footnote stays in the column that references it.
+//
+// Matches Rust: Composer::column
+func composeColumn(composer *Composer, regions Regions) (Frame, error) {
+	for {
+		frame, stop := Distribute(composer, regions)
+		switch s := stop.(type) {
+		case StopError:
+			return Frame{}, s.Err
+		case StopRelayout:
+			// An insertion changed the region, so it is laid out again.
+			continue
+		}
+		return frame, nil
+	}
+}
+
+// columnRegions returns the regions of the columns of a region with the
+// given column height. Each region of the backlog is repeated once per
+// column, so that a column that ends the region continues in the first
+// column of the next.
+func columnRegions(columns ColumnConfig, regions Regions, height layout.Abs) Regions {
+	var backlog []layout.Abs
+	for _, h := range append([]layout.Abs{height}, regions.Backlog...) {
+		for range columns.Count {
+			backlog = append(backlog, h)
+		}
+	}
+	inner := regions.Map(func(size layout.Size) layout.Size {
+		return layout.Size{Width: columns.Width, Height: size.Height}
+	})
+	inner.Size.Height = height
+	inner.Expand.X = true
+	inner.Backlog = backlog[1:]
+	return inner
+}
+
+// balance searches for the smallest column height at which the work that
+// was left at the start of the region still fits into its columns, and
+// returns the columns laid out at that height. The natural columns are
+// kept if no smaller height fits.
+func balance(composer *Composer, init Work, regions Regions, natural []Frame) ([]Frame, error) {
+	columns := composer.Config.Columns
+	done := composer.Work.Clone()
+
+	// The columns get one more region after them, so that content that
+	// does not fit into the last column is left over instead of being
+	// forced into it.
+	trial := func(height layout.Abs) ([]Frame, error) {
+		*composer.Work = init.Clone()
+		outer := Regions{Full: regions.Full, Backlog: []layout.Abs{height}}
+		inner := columnRegions(columns, outer, height)
+		inner.Backlog = inner.Backlog[:columns.Count]
+		return composeColumns(composer, columns.Count, inner)
+	}
+
+	low, high := layout.Abs(0), regions.Size.Height
+	best, bestWork := natural, done
+	for high-low > balanceTolerance {
+		mid := (low + high) / 2
+		children, err := trial(mid)
+		if err != nil {
+			return nil, err
+		}
+		if composer.Work.Done() {
+			high, best, bestWork = mid, children, composer.Work.Clone()
+		} else {
+			low = mid
+		}
+	}
+
+	*composer.Work = bestWork
+	return best, nil
+}
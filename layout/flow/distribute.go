@@ -2,6 +2,8 @@ package flow
 
 import (
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
 )
 
 // Distribute distributes as many children as fit from composer.work into the
@@ -27,7 +29,7 @@ func Distribute(composer *Composer, regions Regions) (Frame, Stop) {
 		forced = d.composer.Work.Done()
 	}
 
-	region := NewRegion(regions.Size, regions.Expand)
+	region := layout.NewRegion(regions.Size, regions.Expand)
 	return d.finalize(region, init, forced)
 }
 
@@ -52,6 +54,8 @@ type Distributor struct {
 type distributionSnapshot struct {
 	work  Work
 	items int
+	// space is the height that was still available in the region.
+	space layout.Abs
 }
 
 // Item represents a laid out item in a distribution.
@@ -134,7 +138,12 @@ func (p PlacedItem) Migratable() bool {
 
 // run distributes content into the region.
 func (d *Distributor) run() Stop {
-	// First, handle spill of a breakable block.
+	// First, place floats deferred from earlier regions.
+	if err := d.composer.processQueuedFloats(&d.regions); err != nil {
+		return StopError{Err: err}
+	}
+
+	// Then, handle spill of a breakable block.
 	if spill := d.composer.Work.Spill; spill != nil {
 		d.composer.Work.Spill = nil
 		if stop := d.multiSpill(spill); stop != nil {
@@ -329,10 +338,11 @@ func (d *Distributor) line(line *LineChild) Stop {
 	}
 
 	// If the line's need doesn't fit but does fit in the next region,
-	// finish the region.
+	// finish the region, unless the space left empty costs more than a
+	// widow or orphan.
 	if !d.regions.Size.Height.Fits(line.Need) {
 		iter := d.regions.Iter()
-		if len(iter) > 1 && iter[1].Fits(line.Need) {
+		if len(iter) > 1 && iter[1].Fits(line.Need) && d.worthMoving(line.Cost, d.regions.Size.Height) {
 			return StopFinish{Forced: false}
 		}
 	}
@@ -340,12 +350,18 @@ func (d *Distributor) line(line *LineChild) Stop {
 	return d.frame(line.Frame.Clone(), line.Align, false, false)
 }
 
+// layoutError stops distribution with an error in the layout of a child,
+// attributed to the span of the child's element.
+func layoutError(err error, span syntax.Span) Stop {
+	return StopError{Err: &foundations.LayoutError{Err: err, Span: span}}
+}
+
 // single processes an unbreakable block.
 func (d *Distributor) single(single *SingleChild) Stop {
 	// Lay out the block.
-	frame, err := single.Layout(d.composer.Engine, NewRegion(d.regions.Base(), d.regions.Expand))
+	frame, err := single.Layout(d.composer.Engine, layout.NewRegion(d.regions.Base(), d.regions.Expand))
 	if err != nil {
-		return StopError{Err: err}
+		return layoutError(err, single.Span)
 	}
 
 	// Handle fractionally sized blocks.
@@ -377,7 +393,7 @@ func (d *Distributor) multi(multi *MultiChild) Stop {
 	// Lay out the block.
 	frame, spill, err := multi.Layout(d.composer.Engine, d.regions)
 	if err != nil {
-		return StopError{Err: err}
+		return layoutError(err, multi.Span)
 	}
 
 	if frame.IsEmpty() && spill != nil && spill.ExistNonEmptyFrame && d.regions.MayProgress() {
@@ -412,6 +428,9 @@ func (d *Distributor) multiSpill(spill *MultiSpill) Stop {
 	align := spill.Align()
 	frame, nextSpill, err := spill.Layout(d.composer.Engine, d.regions)
 	if err != nil {
+		if spill.multi != nil {
+			return layoutError(err, spill.multi.Span)
+		}
 		return StopError{Err: err}
 	}
 
@@ -488,7 +507,7 @@ func (d *Distributor) placed(placed *PlacedChild) Stop {
 	} else {
 		frame, err := placed.Layout(d.composer.Engine, d.regions.Base())
 		if err != nil {
-			return StopError{Err: err}
+			return layoutError(err, placed.Span)
 		}
 		if err := d.composer.Footnotes(&d.regions, &frame, 0, true, true); err != nil {
 			return StopError{Err: err}
@@ -531,10 +550,11 @@ func (d *Distributor) finalize(
 	} else if len(d.items) > 0 && d.allMigratable() {
 		// Restore initial state if all items are migratable.
 		d.restore(init)
-	} else if d.sticky != nil {
+	} else if d.sticky != nil && d.worthMoving(d.composer.Config.costs().Sticky, d.sticky.space) {
 		// Restore sticky snapshot to move suffix to next region.
 		d.restore(*d.sticky)
 	}
+	d.composer.retainPlacedFloats()
 
 	d.trimSpacing()
 
@@ -560,11 +580,23 @@ func (d *Distributor) finalize(
 		}
 	}
 
-	// When we have fractional spacing, occupy remaining space.
+	// Floats at the top and the bottom take space away from the flow.
+	var topSize, bottomSize layout.Abs
+	for _, pf := range d.composer.PlacedFloats() {
+		if pf.Align == FixedAlignEnd {
+			bottomSize += pf.Amount()
+		} else {
+			topSize += pf.Amount()
+		}
+	}
+	flowHeight := region.Size.Height - topSize - bottomSize
+
+	// When we have fractional spacing, occupy remaining space. In an
+	// unbounded region, there is no remaining space to occupy.
 	var frSpace layout.Abs
-	if frs > 0 && region.Size.Height > 0 {
-		frSpace = region.Size.Height - used.Height
-		used.Height = region.Size.Height
+	if frs > 0 && flowHeight > 0 && flowHeight.IsFinite() {
+		frSpace = flowHeight - used.Height
+		used.Height = flowHeight
 	}
 
 	// Lay out fractionally sized blocks.
@@ -576,10 +608,10 @@ func (d *Distributor) finalize(
 				continue
 			}
 			length := share(frItem.Amount, frs, frSpace)
-			pod := NewRegion(layout.Size{Width: region.Size.Width, Height: length}, region.Expand)
+			pod := layout.NewRegion(layout.Size{Width: region.Size.Width, Height: length}, region.Expand)
 			frame, err := frItem.Single.Layout(d.composer.Engine, pod)
 			if err != nil {
-				return Frame{}, StopError{Err: err}
+				return Frame{}, layoutError(err, frItem.Single.Span)
 			}
 			if frame.Width() > used.Width {
 				used.Width = frame.Width()
@@ -597,12 +629,13 @@ func (d *Distributor) finalize(
 	}
 
 	// Determine region's size.
-	size := selectSize(region.Expand, region.Size, minSize(used, region.Size))
+	used.Height += topSize + bottomSize
+	size := region.Fit(used)
 	free := size.Height - used.Height
 
 	output := Soft(size)
 	ruler := FixedAlignStart
-	var offset layout.Abs
+	offset := topSize
 	frFrameIdx := 0
 
 	// Position all items.
@@ -649,16 +682,20 @@ func (d *Distributor) finalize(
 		}
 	}
 
-	// Position placed floats from the composer.
+	// Stack the floats from the top and the bottom of the region, in the
+	// order in which they were placed, separated from the flow by their
+	// clearance.
+	offsetTop, offsetBottom := layout.Abs(0), size.Height-bottomSize
 	for _, pf := range d.composer.PlacedFloats() {
 		x := pf.Placed.AlignX.Position(size.Width - pf.Frame.Width())
 		var y layout.Abs
-		if pf.Placed.AlignY != nil {
-			// Top/bottom positioned float.
-			y = pf.Placed.AlignY.Position(size.Height - pf.Frame.Height())
+		if pf.Align == FixedAlignEnd {
+			offsetBottom += pf.Placed.Clearance
+			y = offsetBottom
+			offsetBottom += pf.Frame.Height()
 		} else {
-			// Inline float - position relative to current flow offset.
-			y = offset + ruler.Position(free)
+			y = offsetTop
+			offsetTop += pf.Amount()
 		}
 		delta := RelAxesToPoint(pf.Placed.Delta, size)
 		pos := layout.Point{X: x + delta.X, Y: y + delta.Y}
@@ -676,9 +713,20 @@ func (d *Distributor) snapshot() distributionSnapshot {
 	return distributionSnapshot{
 		work:  d.composer.Work.Clone(),
 		items: len(d.items),
+		space: d.regions.Size.Height,
 	}
 }
 
+// worthMoving reports whether content is moved to the next region at the
+// given cost, which leaves the space still available in this region empty.
+// A cost of one moves it regardless of the space, a cost of zero never.
+func (d *Distributor) worthMoving(cost float64, space layout.Abs) bool {
+	if cost >= 1 {
+		return true
+	}
+	return cost > 0 && space <= layout.Abs(cost)*d.regions.Full.Height
+}
+
 // restore restores a snapshot of the work and items.
 func (d *Distributor) restore(snapshot distributionSnapshot) {
 	*d.composer.Work = snapshot.work
@@ -702,27 +750,3 @@ func share(fr, total layout.Fr, space layout.Abs) layout.Abs {
 	}
 	return layout.Abs(float64(fr) / float64(total) * float64(space))
 }
-
-// selectSize selects the size based on expansion settings.
-func selectSize(expand Axes[bool], full, used layout.Size) layout.Size {
-	result := used
-	if expand.X {
-		result.Width = full.Width
-	}
-	if expand.Y {
-		result.Height = full.Height
-	}
-	return result
-}
-
-// minSize returns the component-wise minimum of two sizes.
-func minSize(a, b layout.Size) layout.Size {
-	result := a
-	if b.Width < result.Width {
-		result.Width = b.Width
-	}
-	if b.Height < result.Height {
-		result.Height = b.Height
-	}
-	return result
-}
@@ -4,7 +4,7 @@
 // - Block-level flow layout
 // - Content collection and preprocessing
 // - Frame composition with floats and footnotes
-// - Content distribution across multiple regions
+// - Content distribution across multiple regions and columns
 //
 // The flow layout pipeline:
 // 1. Collect children into preprocessed structures
@@ -1,9 +1,13 @@
 package flow
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	layoutlib "github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/library/model"
 )
 
 func TestComposerFloat_BasicPlacement(t *testing.T) {
@@ -12,7 +16,7 @@ func TestComposerFloat_BasicPlacement(t *testing.T) {
 	config := &Config{Mode: FlowModeRoot}
 	composer := &Composer{Engine: engine, Work: work, Config: config}
 
-	regions := NewRegions(
+	regions := layout.NewRegions(
 		layout.Size{Width: 100, Height: 200},
 		Axes[bool]{X: false, Y: false},
 		layout.Size{Width: 100, Height: 200},
@@ -70,7 +74,7 @@ func TestComposerFloat_QueueWhenNoFit(t *testing.T) {
 	}
 	work.Floats = []*PlacedChild{placed}
 
-	regions := NewRegions(
+	regions := layout.NewRegions(
 		layout.Size{Width: 100, Height: 200},
 		Axes[bool]{X: false, Y: false},
 		layout.Size{Width: 100, Height: 200},
@@ -108,7 +112,7 @@ func TestComposerFloat_SkipAlreadyProcessed(t *testing.T) {
 	config := &Config{Mode: FlowModeRoot}
 	composer := &Composer{Engine: engine, Work: work, Config: config}
 
-	regions := NewRegions(
+	regions := layout.NewRegions(
 		layout.Size{Width: 100, Height: 200},
 		Axes[bool]{X: false, Y: false},
 		layout.Size{Width: 100, Height: 200},
@@ -239,7 +243,7 @@ func TestDistribute_WithFloats(t *testing.T) {
 	config := &Config{Mode: FlowModeRoot}
 	composer := &Composer{Engine: engine, Work: work, Config: config}
 
-	regions := NewRegions(
+	regions := layout.NewRegions(
 		layout.Size{Width: 100, Height: 200},
 		Axes[bool]{X: false, Y: true},
 		layout.Size{Width: 100, Height: 200},
@@ -320,7 +324,7 @@ func TestFlushChild_TriggersFinishWithPendingFloats(t *testing.T) {
 
 	d := &Distributor{
 		composer: composer,
-		regions: NewRegions(
+		regions: layout.NewRegions(
 			layout.Size{Width: 100, Height: 200},
 			Axes[bool]{X: false, Y: false},
 			layout.Size{Width: 100, Height: 200},
@@ -345,7 +349,7 @@ func TestFlushChild_NopWithoutPendingFloats(t *testing.T) {
 
 	d := &Distributor{
 		composer: composer,
-		regions: NewRegions(
+		regions: layout.NewRegions(
 			layout.Size{Width: 100, Height: 200},
 			Axes[bool]{X: false, Y: false},
 			layout.Size{Width: 100, Height: 200},
@@ -357,3 +361,339 @@ func TestFlushChild_NopWithoutPendingFloats(t *testing.T) {
 		t.Errorf("Expected nil when no pending floats, got %v", stop)
 	}
 }
+
+// block creates an unbreakable child with a laid out frame of the given
+// height.
+func block(height layout.Abs, sticky bool) *SingleChild {
+	frame := Soft(layout.Size{Width: 100, Height: height})
+	frame.PushFrame(layout.Point{}, Soft(layout.Size{Width: 100, Height: height}))
+	return &SingleChild{Sticky: sticky, frame: &frame}
+}
+
+func TestCompose_StickyMovesWithNextBlock(t *testing.T) {
+	for _, sticky := range []bool{true, false} {
+		heading := block(20, sticky)
+		work := NewWork([]Child{block(60, false), heading, block(40, false)})
+		composer := &Composer{Engine: &Engine{}, Work: work, Config: &Config{Mode: FlowModeRoot}}
+		regions := layout.NewRegions(
+			layout.Size{Width: 100, Height: 100},
+			Axes[bool]{X: false, Y: false},
+			layout.Size{Width: 100, Height: 100},
+		)
+		regions.Backlog = []layout.Abs{100}
+
+		frames, err := Compose(composer, regions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(frames) != 2 {
+			t.Fatalf("sticky %v: got %d frames, want 2", sticky, len(frames))
+		}
+
+		// A sticky heading moves to the second page with the block after
+		// it; otherwise, it stays at the bottom of the first page.
+		want := layout.Abs(80)
+		if sticky {
+			want = 60
+		}
+		if got := frames[0].Height(); got != want {
+			t.Errorf("sticky %v: first frame height = %v, want %v", sticky, got, want)
+		}
+	}
+}
+
+func TestCompose_StickyCost(t *testing.T) {
+	// Moving the heading leaves 40% of the first page empty, which only a
+	// sticky cost of at least 40% accepts.
+	for _, tc := range []struct {
+		cost float64
+		want layout.Abs
+	}{
+		{cost: 0.5, want: 60},
+		{cost: 0.3, want: 80},
+		{cost: 0, want: 80},
+	} {
+		work := NewWork([]Child{block(60, false), block(20, true), block(40, false)})
+		costs := DefaultCosts()
+		costs.Sticky = tc.cost
+		composer := &Composer{Engine: &Engine{}, Work: work, Config: &Config{Mode: FlowModeRoot, Costs: &costs}}
+		regions := layout.NewRegions(
+			layout.Size{Width: 100, Height: 100},
+			Axes[bool]{X: false, Y: false},
+			layout.Size{Width: 100, Height: 100},
+		)
+		regions.Backlog = []layout.Abs{100}
+
+		frames, err := Compose(composer, regions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := frames[0].Height(); got != tc.want {
+			t.Errorf("cost %v: first frame height = %v, want %v", tc.cost, got, tc.want)
+		}
+	}
+}
+
+func TestCompose_StickyAtRegionStartStays(t *testing.T) {
+	// Migrating a sticky block from the start of the last, repeating
+	// region cannot help, so it stays while the next block moves on.
+	last := layout.Size{Width: 100, Height: 50}
+	work := NewWork([]Child{block(20, true), block(40, false)})
+	composer := &Composer{Engine: &Engine{}, Work: work, Config: &Config{Mode: FlowModeRoot}}
+	regions := layout.NewRegions(last, Axes[bool]{X: false, Y: false}, last)
+	regions.Last = &last
+
+	frames, err := Compose(composer, regions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Height() != 20 {
+		t.Errorf("got %d frames, first of height %v; want 2, 20", len(frames), frames[0].Height())
+	}
+}
+
+func TestRegionsNext(t *testing.T) {
+	last := layout.Size{Width: 100, Height: 30}
+	regions := layout.NewRegions(layout.Size{Width: 100, Height: 10}, Axes[bool]{}, layout.Size{Width: 100, Height: 10})
+	regions.Backlog = []layout.Abs{20}
+	regions.Last = &last
+
+	var heights []layout.Abs
+	for range 3 {
+		regions.Next()
+		heights = append(heights, regions.Size.Height)
+	}
+	if heights[0] != 20 || heights[1] != 30 || heights[2] != 30 || regions.Full.Height != 30 {
+		t.Errorf("heights = %v, full = %v", heights, regions.Full.Height)
+	}
+	if regions.MayProgress() {
+		t.Error("the repeating last region may not progress")
+	}
+}
+
+// columnsComposer creates a composer that lays the children out in two
+// columns with a gutter of 10pt in regions 100pt wide.
+func columnsComposer(balance bool, children ...Child) *Composer {
+	columns := NewColumnConfig(2, 100, 10)
+	columns.Balance = balance
+	return &Composer{
+		Engine: &Engine{},
+		Work:   NewWork(children),
+		Config: &Config{Mode: FlowModeRoot, Columns: columns},
+	}
+}
+
+func TestNewColumnConfig(t *testing.T) {
+	columns := NewColumnConfig(3, 100, 5)
+	if columns.Count != 3 || columns.Width != 30 || columns.Gutter != 5 {
+		t.Errorf("got %+v", columns)
+	}
+	if columns := NewColumnConfig(0, 100, 5); columns.Count != 1 || columns.Width != 100 {
+		t.Errorf("got %+v for zero columns", columns)
+	}
+}
+
+func TestCompose_ColumnsFillInOrder(t *testing.T) {
+	composer := columnsComposer(false, block(60, false), block(60, false), block(60, false))
+	size := layout.Size{Width: 100, Height: 100}
+	regions := layout.NewRegions(size, Axes[bool]{X: true, Y: true}, size)
+	regions.Backlog = []layout.Abs{100}
+
+	frames, err := Compose(composer, regions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+	first := frames[0].Items()
+	if len(first) != 2 || first[0].Pos.X != 0 || first[1].Pos.X != 55 {
+		t.Errorf("first region has columns %+v, want two at 0 and 55", first)
+	}
+	if got := len(frames[1].Items()); got != 1 {
+		t.Errorf("second region has %d columns, want 1", got)
+	}
+}
+
+func TestCompose_ColumnsBalance(t *testing.T) {
+	children := []Child{block(20, false), block(20, false), block(20, false), block(20, false)}
+	size := layout.Size{Width: 100, Height: 200}
+
+	for _, balance := range []bool{false, true} {
+		composer := columnsComposer(balance, children...)
+		frames, err := Compose(composer, layout.NewRegions(size, Axes[bool]{X: true}, size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(frames) != 1 {
+			t.Fatalf("balance %v: got %d frames, want 1", balance, len(frames))
+		}
+
+		// Unbalanced, everything fits into the first column. Balanced,
+		// each column takes half of the blocks.
+		wantColumns, wantHeight := 1, layout.Abs(80)
+		if balance {
+			wantColumns, wantHeight = 2, 40
+		}
+		if got := len(frames[0].Items()); got != wantColumns {
+			t.Errorf("balance %v: got %d columns, want %d", balance, got, wantColumns)
+		}
+		if got := frames[0].Height(); !got.ApproxEq(wantHeight) {
+			t.Errorf("balance %v: height = %v, want %v", balance, got, wantHeight)
+		}
+		if !composer.Work.Done() {
+			t.Errorf("balance %v: work is not done", balance)
+		}
+	}
+}
+
+func TestCompose_Colbreak(t *testing.T) {
+	composer := columnsComposer(false, block(20, false), BreakChild{}, block(20, false))
+	size := layout.Size{Width: 100, Height: 100}
+
+	frames, err := Compose(composer, layout.NewRegions(size, Axes[bool]{X: true}, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 1 || len(frames[0].Items()) != 2 {
+		t.Fatalf("got %d frames; want one with both columns", len(frames))
+	}
+	if got := frames[0].Height(); got != 20 {
+		t.Errorf("height = %v, want 20", got)
+	}
+}
+
+func TestMultiChildColumns(t *testing.T) {
+	multi := &MultiChild{Columns: &MultiColumns{
+		Count:    2,
+		Gutter:   Rel{Abs: 10},
+		Children: []Child{block(20, false), block(20, false)},
+	}}
+	size := layout.Size{Width: 100, Height: 100}
+
+	frame, spill, err := multi.Layout(&Engine{}, layout.NewRegions(size, Axes[bool]{X: true}, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spill != nil {
+		t.Error("expected no spill")
+	}
+	if frame.Height() != 20 || len(frame.Items()) != 2 {
+		t.Errorf("got frame of height %v with %d columns; want balanced columns", frame.Height(), len(frame.Items()))
+	}
+}
+
+// float creates a floating placed child with a laid out frame of the given
+// height. A nil alignment lets the composer pick the top or the bottom.
+func float(height, clearance layout.Abs, align *FixedAlignment, location Location) *PlacedChild {
+	frame := Soft(layout.Size{Width: 100, Height: height})
+	frame.PushFrame(layout.Point{}, Soft(layout.Size{Width: 100, Height: height}))
+	return &PlacedChild{AlignY: align, Float: true, Clearance: clearance, location: location, frame: &frame}
+}
+
+func TestCompose_FloatsStackAtTopAndBottom(t *testing.T) {
+	top, bottom := FixedAlignStart, FixedAlignEnd
+	work := NewWork([]Child{float(30, 10, &top, 1), block(40, false), float(20, 5, &bottom, 2)})
+	composer := &Composer{Engine: &Engine{}, Work: work, Config: &Config{Mode: FlowModeRoot}}
+	size := layout.Size{Width: 100, Height: 200}
+
+	frames, err := Compose(composer, layout.NewRegions(size, Axes[bool]{X: true, Y: true}, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 1 {
+		t.Fatalf("got %d frames, want 1", len(frames))
+	}
+
+	// The block comes first, then the top and the bottom float.
+	var ys []layout.Abs
+	for _, entry := range frames[0].Items() {
+		ys = append(ys, entry.Pos.Y)
+	}
+	want := []layout.Abs{40, 0, 180}
+	if len(ys) != len(want) || ys[0] != want[0] || ys[1] != want[1] || ys[2] != want[2] {
+		t.Errorf("positions = %v, want %v", ys, want)
+	}
+}
+
+func TestCompose_FloatDeferredInOrder(t *testing.T) {
+	top := FixedAlignStart
+	first, second := float(50, 0, &top, 1), float(10, 0, &top, 2)
+	work := NewWork([]Child{block(60, false), first, second})
+	composer := &Composer{Engine: &Engine{}, Work: work, Config: &Config{Mode: FlowModeRoot}}
+	size := layout.Size{Width: 100, Height: 100}
+	regions := layout.NewRegions(size, Axes[bool]{X: true, Y: true}, size)
+	regions.Backlog = []layout.Abs{100}
+
+	frames, err := Compose(composer, regions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+
+	// The first float does not fit next to the block, and the second one
+	// waits for it even though it would fit.
+	if got := len(frames[0].Items()); got != 1 {
+		t.Errorf("first region has %d items, want only the block", got)
+	}
+	items := frames[1].Items()
+	if len(items) != 2 || items[0].Pos.Y != 0 || items[1].Pos.Y != 50 {
+		t.Errorf("second region has items %+v, want both floats stacked in order", items)
+	}
+	if !composer.Work.Done() {
+		t.Error("work is not done")
+	}
+}
+
+func TestComposerFloat_AutoAlignment(t *testing.T) {
+	for _, tc := range []struct {
+		remaining layout.Abs
+		want      FixedAlignment
+	}{
+		{remaining: 200, want: FixedAlignStart},
+		{remaining: 50, want: FixedAlignEnd},
+	} {
+		composer := &Composer{Engine: &Engine{}, Work: NewWork(nil), Config: &Config{Mode: FlowModeRoot}}
+		regions := layout.NewRegions(
+			layout.Size{Width: 100, Height: tc.remaining},
+			Axes[bool]{X: false, Y: false},
+			layout.Size{Width: 100, Height: 200},
+		)
+
+		if err := composer.Float(float(20, 10, nil, 1), &regions, true, true); err != nil {
+			t.Fatal(err)
+		}
+		floats := composer.PlacedFloats()
+		if len(floats) != 1 || floats[0].Align != tc.want {
+			t.Errorf("remaining %v: got %+v, want alignment %v", tc.remaining, floats, tc.want)
+		}
+		if got := regions.Size.Height; got != tc.remaining-30 {
+			t.Errorf("remaining %v: region height = %v, want %v", tc.remaining, got, tc.remaining-30)
+		}
+	}
+}
+
+func TestNextParSituation(t *testing.T) {
+	elements := []foundations.ContentElement{
+		&model.ParElem{},
+		&foundations.ParbreakElem{},
+		&layoutlib.VElem{},
+		&model.ParElem{},
+		&model.HeadingElem{},
+		&model.ParElem{},
+	}
+	var situations []model.ParSituation
+	situation := model.ParFirst
+	for _, elem := range elements {
+		if _, ok := elem.(*model.ParElem); ok {
+			situations = append(situations, situation)
+		}
+		situation = nextParSituation(situation, elem)
+	}
+	want := []model.ParSituation{model.ParFirst, model.ParConsecutive, model.ParOther}
+	if !slices.Equal(situations, want) {
+		t.Errorf("situations = %v, want %v", situations, want)
+	}
+}
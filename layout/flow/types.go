@@ -2,6 +2,10 @@ package flow
 
 import (
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	layoutlib "github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
 )
 
 // FlowMode represents the mode of flow layout.
@@ -108,10 +112,8 @@ func (a FixedAlignment) Max(other FixedAlignment) FixedAlignment {
 	return a
 }
 
-// Axes holds a pair of values for horizontal (X) and vertical (Y) axes.
-type Axes[T any] struct {
-	X, Y T
-}
+// Axes is an alias for layout.Axes for convenience.
+type Axes[T any] = layout.Axes[T]
 
 // Tag represents an introspection tag.
 type Tag struct {
@@ -162,6 +164,9 @@ type LineChild struct {
 	// Need includes the line's height plus following lines grouped by
 	// widow/orphan prevention.
 	Need layout.Abs
+	// Cost is the widow or orphan cost of breaking the region before the
+	// lines grouped in Need are all placed.
+	Cost float64
 }
 
 func (LineChild) isChild() {}
@@ -172,7 +177,10 @@ type SingleChild struct {
 	Sticky bool
 	Alone  bool
 	Fr     *layout.Fr // nil if not fractionally sized
-	frame  *Frame     // cached layout result
+	// Span is the span of the element, to which layout errors are
+	// attributed.
+	Span  syntax.Span
+	frame *Frame // cached layout result
 }
 
 func (SingleChild) isChild() {}
@@ -191,17 +199,89 @@ type MultiChild struct {
 	Align  Axes[FixedAlignment]
 	Sticky bool
 	Alone  bool
+	// Situation is where a paragraph is relative to the preceding
+	// content, which decides whether its first line is indented.
+	Situation model.ParSituation
+	// Columns is set if the block lays out its children in columns.
+	Columns *MultiColumns
+	// Span is the span of the element, to which layout errors are
+	// attributed.
+	Span syntax.Span
 }
 
 func (MultiChild) isChild() {}
 
+// nextParSituation returns the situation of the paragraph that would
+// follow elem, given the situation before it. Only vertical spacing and
+// paragraph breaks may come between consecutive paragraphs.
+// Matches Rust: Collector::par_situation updates in flow/collect.rs
+func nextParSituation(situation model.ParSituation, elem foundations.ContentElement) model.ParSituation {
+	switch elem.(type) {
+	case *model.ParElem:
+		return model.ParConsecutive
+	case *layoutlib.VElem, *foundations.ParbreakElem:
+		return situation
+	}
+	return model.ParOther
+}
+
+// MultiColumns is the content of a block that is laid out in columns.
+// Matches Rust: ColumnsElem in typst-library/src/layout/columns.rs
+type MultiColumns struct {
+	// Count is the number of columns.
+	Count int
+	// Gutter is the gap between two columns, relative to the width of
+	// the region.
+	Gutter Rel
+	// Children are the children of the flow inside the columns.
+	Children []Child
+}
+
 // Layout lays out the multi child across regions, returning the first frame
 // and optional spill for remaining content.
 func (m *MultiChild) Layout(engine *Engine, regions Regions) (Frame, *MultiSpill, error) {
+	if m.Columns != nil {
+		return m.layoutColumns(engine, regions)
+	}
 	// TODO: Implement actual layout
 	return Frame{}, nil, nil
 }
 
+// layoutColumns lays out the children of a columns block with a nested
+// flow. The columns of the block's last region are balanced.
+// Matches Rust: layout_columns in typst-layout/src/flow/mod.rs
+func (m *MultiChild) layoutColumns(engine *Engine, regions Regions) (Frame, *MultiSpill, error) {
+	width := regions.Size.Width
+	columns := NewColumnConfig(m.Columns.Count, width, m.Columns.Gutter.RelativeTo(width))
+	columns.Balance = true
+	composer := &Composer{
+		Engine: engine,
+		Work:   NewWork(m.Columns.Children),
+		Config: &Config{Mode: FlowModeBlock, Columns: columns},
+	}
+	frames, err := Compose(composer, regions)
+	if err != nil {
+		return Frame{}, nil, err
+	}
+	return m.spill(frames)
+}
+
+// spill returns the first of the frames of the block and a spill holding
+// the others.
+func (m *MultiChild) spill(frames []Frame) (Frame, *MultiSpill, error) {
+	if len(frames) == 0 {
+		return Frame{}, nil, nil
+	}
+	if len(frames) == 1 {
+		return frames[0], nil, nil
+	}
+	spill := &MultiSpill{multi: m, frames: frames[1:]}
+	for i := range spill.frames {
+		spill.ExistNonEmptyFrame = spill.ExistNonEmptyFrame || !spill.frames[i].IsEmpty()
+	}
+	return frames[0], spill, nil
+}
+
 // PlacedChild represents an absolutely or floatingly placed child.
 type PlacedChild struct {
 	AlignX    FixedAlignment
@@ -210,13 +290,20 @@ type PlacedChild struct {
 	Float     bool
 	Clearance layout.Abs
 	Delta     Axes[Rel]
-	location  Location
+	// Span is the span of the element, to which layout errors are
+	// attributed.
+	Span     syntax.Span
+	location Location
+	frame    *Frame // cached layout result
 }
 
 func (PlacedChild) isChild() {}
 
 // Layout lays out the placed child at the given base size.
 func (p *PlacedChild) Layout(engine *Engine, base layout.Size) (Frame, error) {
+	if p.frame != nil {
+		return *p.frame, nil
+	}
 	// TODO: Implement actual layout
 	return Frame{}, nil
 }
@@ -248,10 +335,16 @@ type MultiSpill struct {
 	full               layout.Abs
 	backlog            []layout.Abs
 	minBacklogLen      int
+	// frames are the remaining frames of a block that was laid out
+	// across all regions at once.
+	frames []Frame
 }
 
 // Layout continues layout of the spill in the given regions.
 func (s *MultiSpill) Layout(engine *Engine, regions Regions) (Frame, *MultiSpill, error) {
+	if len(s.frames) > 0 {
+		return s.multi.spill(s.frames)
+	}
 	// TODO: Implement actual layout
 	return Frame{}, nil, nil
 }
@@ -269,7 +362,12 @@ type Rel struct {
 
 // RelativeTo resolves the relative length to an absolute length.
 func (r Rel) RelativeTo(base layout.Abs) layout.Abs {
-	return r.Abs + layout.Abs(r.Ratio*float64(base))
+	share := layout.Abs(r.Ratio * float64(base))
+	if !share.IsFinite() {
+		// An unbounded base has no share to take.
+		share = 0
+	}
+	return r.Abs + share
 }
 
 // RelAxesToPoint converts axes of relative values to a point.
@@ -387,59 +485,11 @@ type FrameItemImage struct {
 
 func (FrameItemImage) isFrameItem() {}
 
-// Region represents a single layout region.
-type Region struct {
-	Size   layout.Size
-	Expand Axes[bool]
-}
-
-// NewRegion creates a new region with the given size and expansion settings.
-func NewRegion(size layout.Size, expand Axes[bool]) Region {
-	return Region{Size: size, Expand: expand}
-}
-
-// Regions represents multiple layout regions.
-type Regions struct {
-	Size    layout.Size
-	Expand  Axes[bool]
-	Full    layout.Size
-	Backlog []layout.Abs
-	Last    *layout.Size
-}
+// Region is an alias for layout.Region for convenience.
+type Region = layout.Region
 
-// NewRegions creates new regions with the given parameters.
-func NewRegions(size layout.Size, expand Axes[bool], full layout.Size) Regions {
-	return Regions{
-		Size:   size,
-		Expand: expand,
-		Full:   full,
-	}
-}
-
-// Base returns the base size for relative measurements.
-func (r *Regions) Base() layout.Size {
-	return r.Full
-}
-
-// MayProgress returns true if moving to a subsequent region might improve things.
-func (r *Regions) MayProgress() bool {
-	return len(r.Backlog) > 0 || r.Last != nil
-}
-
-// IsFull returns true if the region is (over)full.
-func (r *Regions) IsFull() bool {
-	return r.Size.Height <= 0
-}
-
-// Iter returns an iterator over remaining region heights.
-func (r *Regions) Iter() []layout.Abs {
-	result := []layout.Abs{r.Size.Height}
-	result = append(result, r.Backlog...)
-	if r.Last != nil {
-		result = append(result, r.Last.Height)
-	}
-	return result
-}
+// Regions is an alias for layout.Regions for convenience.
+type Regions = layout.Regions
 
 // Engine represents the layout engine context.
 type Engine struct {
@@ -477,9 +527,10 @@ func (w *Work) Advance() {
 	w.index++
 }
 
-// Done returns true if all children have been processed.
+// Done returns true if all children have been processed and no floats
+// are waiting for a later region.
 func (w *Work) Done() bool {
-	return w.index >= len(w.children) && w.Spill == nil
+	return w.index >= len(w.children) && w.Spill == nil && len(w.Floats) == 0
 }
 
 // Clone creates a copy of the work state.
@@ -505,13 +556,86 @@ func (w *Work) Clone() Work {
 // Config holds shared flow configuration.
 type Config struct {
 	Mode FlowMode
+	// Columns is the configuration of the columns in each region.
+	Columns ColumnConfig
+	// Costs weighs the region breaks of the flow. If nil, DefaultCosts
+	// is used.
+	Costs *Costs
 	// TODO: Add more configuration fields as needed
 }
 
+// costs returns the costs of the flow's region breaks.
+func (c *Config) costs() Costs {
+	if c == nil || c.Costs == nil {
+		return DefaultCosts()
+	}
+	return *c.Costs
+}
+
+// Costs weighs the region breaks that would leave a line or a heading
+// stranded. Each cost is a ratio from zero to one. At zero, content breaks
+// wherever the region is full; at one, the stranded content always moves
+// to the next region, as long as it fits there. In between, it only moves
+// if that leaves at most the cost's share of the region empty.
+// Matches Rust: the widow and orphan costs of typst_library::text::Costs
+type Costs struct {
+	// Widow is the cost of leaving the last line of a paragraph alone at
+	// the top of a region.
+	Widow float64
+	// Orphan is the cost of leaving the first line of a paragraph alone at
+	// the bottom of a region.
+	Orphan float64
+	// Sticky is the cost of leaving a heading, or another sticky block,
+	// alone at the bottom of a region, apart from the content it belongs
+	// to.
+	Sticky float64
+}
+
+// DefaultCosts returns costs that always avoid widows, orphans, and
+// stranded headings.
+func DefaultCosts() Costs {
+	return Costs{Widow: 1, Orphan: 1, Sticky: 1}
+}
+
+// ColumnConfig holds the configuration of columns. A count of zero or one
+// lays the flow out in a single column spanning the whole region.
+// Matches Rust: ColumnConfig in typst-layout/src/flow/mod.rs
+type ColumnConfig struct {
+	// Count is the number of columns.
+	Count int
+	// Width is the width of each column.
+	Width layout.Abs
+	// Gutter is the gap between two columns.
+	Gutter layout.Abs
+	// Balance makes the columns of the last region end at about the
+	// same height instead of filling them one after the other.
+	Balance bool
+}
+
+// NewColumnConfig creates the configuration for count columns with the
+// given gutter in a region of the given width.
+func NewColumnConfig(count int, width, gutter layout.Abs) ColumnConfig {
+	count = max(count, 1)
+	return ColumnConfig{
+		Count:  count,
+		Width:  (width - gutter*layout.Abs(count-1)) / layout.Abs(count),
+		Gutter: gutter,
+	}
+}
+
 // PlacedFloat represents a float that has been laid out and is ready for placement.
 type PlacedFloat struct {
 	Placed *PlacedChild
 	Frame  Frame
+	// Align is the resolved vertical alignment: FixedAlignStart for the
+	// top of the region and FixedAlignEnd for the bottom.
+	Align FixedAlignment
+}
+
+// Amount returns the height the float takes away from the in-flow
+// content, including its clearance.
+func (f PlacedFloat) Amount() layout.Abs {
+	return f.Frame.Height() + f.Placed.Clearance
 }
 
 // Composer handles flow composition including floats and footnotes.
@@ -538,8 +662,13 @@ func (c *Composer) Footnotes(
 }
 
 // Float processes a floating placed child.
-// It lays out the float and either places it immediately or queues it
-// for subsequent regions if it doesn't fit.
+// It lays out the float and either places it at the top or the bottom of
+// the region or queues it for subsequent regions if it doesn't fit. A
+// float is also queued while earlier floats are waiting, so that floats
+// keep their order. The clearance is only needed if there is in-flow
+// content to keep the float apart from.
+//
+// Matches Rust: Composer::float in typst-layout/src/flow/compose.rs
 func (c *Composer) Float(
 	placed *PlacedChild,
 	regions *Regions,
@@ -555,92 +684,96 @@ func (c *Composer) Float(
 	if err := c.processQueuedFloats(regions); err != nil {
 		return err
 	}
+	if len(c.Work.Floats) > 0 && migratable {
+		c.Work.Floats = append(c.Work.Floats, placed)
+		return nil
+	}
 
-	// Layout the float at the base size.
-	frame, err := placed.Layout(c.Engine, regions.Base())
+	placedNow, err := c.place(placed, regions, clearance, migratable)
 	if err != nil {
 		return err
 	}
-
-	// Check if the float fits in the current region.
-	// For clearance, we need space after existing content.
-	requiredHeight := frame.Height()
-	if clearance {
-		requiredHeight += placed.Clearance
-	}
-
-	// Check if the float fits.
-	if regions.Size.Height.Fits(requiredHeight) {
-		// Float fits - place it.
-		c.placedFloats = append(c.placedFloats, PlacedFloat{
-			Placed: placed,
-			Frame:  frame,
-		})
-		c.Work.Skips[placed.Location()] = struct{}{}
-
-		// Reduce available height for inline floats (AlignY == nil).
-		// Top/bottom floats don't consume flow space.
-		if placed.AlignY == nil {
-			regions.Size.Height -= requiredHeight
-		}
-	} else if migratable && regions.MayProgress() {
-		// Float doesn't fit but can be queued for subsequent regions.
+	if !placedNow {
 		c.Work.Floats = append(c.Work.Floats, placed)
-	} else {
-		// Float doesn't fit and cannot be queued - place it anyway.
-		// This can happen when there's no room but we must proceed.
-		c.placedFloats = append(c.placedFloats, PlacedFloat{
-			Placed: placed,
-			Frame:  frame,
-		})
-		c.Work.Skips[placed.Location()] = struct{}{}
 	}
-
 	return nil
 }
 
-// processQueuedFloats attempts to place any queued floats that might now fit.
-func (c *Composer) processQueuedFloats(regions *Regions) error {
-	if len(c.Work.Floats) == 0 {
-		return nil
+// place lays out a float and places it if it fits into the region, or if
+// it cannot be queued or moving on to a later region would not help.
+// The region shrinks by the float's height and clearance.
+func (c *Composer) place(
+	placed *PlacedChild,
+	regions *Regions,
+	clearance bool,
+	migratable bool,
+) (bool, error) {
+	// Layout the float at the base size.
+	frame, err := placed.Layout(c.Engine, regions.Base())
+	if err != nil {
+		return false, err
 	}
 
-	// Try to place queued floats.
-	remaining := make([]*PlacedChild, 0, len(c.Work.Floats))
-	for _, queued := range c.Work.Floats {
-		// Skip if already processed.
-		if _, ok := c.Work.Skips[queued.Location()]; ok {
-			continue
-		}
+	need := frame.Height()
+	if clearance {
+		need += placed.Clearance
+	}
+	if !regions.Size.Height.Fits(need) && migratable && regions.MayProgress() {
+		return false, nil
+	}
 
-		// Layout the float.
-		frame, err := queued.Layout(c.Engine, regions.Base())
-		if err != nil {
-			return err
+	// A float without explicit alignment goes to the top if its midpoint
+	// would be in the upper half of the region when laid out in flow, and
+	// to the bottom otherwise.
+	align := FixedAlignStart
+	if placed.AlignY != nil {
+		align = *placed.AlignY
+	} else if base := regions.Base().Height; base > 0 {
+		used := base - regions.Size.Height
+		if (used+need/2)/base > 0.5 {
+			align = FixedAlignEnd
 		}
+	}
+
+	float := PlacedFloat{Placed: placed, Frame: frame, Align: align}
+	c.placedFloats = append(c.placedFloats, float)
+	c.Work.Skips[placed.Location()] = struct{}{}
+	regions.Size.Height -= float.Amount()
+	return true, nil
+}
 
-		// Check if it fits.
-		if regions.Size.Height.Fits(frame.Height()) {
-			c.placedFloats = append(c.placedFloats, PlacedFloat{
-				Placed: queued,
-				Frame:  frame,
-			})
-			c.Work.Skips[queued.Location()] = struct{}{}
-
-			// Reduce available height for inline floats.
-			if queued.AlignY == nil {
-				regions.Size.Height -= frame.Height()
+// processQueuedFloats places queued floats in order until one doesn't fit.
+// That float and all after it stay queued.
+func (c *Composer) processQueuedFloats(regions *Regions) error {
+	for len(c.Work.Floats) > 0 {
+		queued := c.Work.Floats[0]
+		// Skip if already processed.
+		if _, ok := c.Work.Skips[queued.Location()]; !ok {
+			placedNow, err := c.place(queued, regions, false, true)
+			if err != nil {
+				return err
+			}
+			if !placedNow {
+				return nil
 			}
-		} else {
-			// Still doesn't fit - keep in queue.
-			remaining = append(remaining, queued)
 		}
+		c.Work.Floats = c.Work.Floats[1:]
 	}
-	c.Work.Floats = remaining
-
 	return nil
 }
 
+// retainPlacedFloats drops the placed floats that are no longer marked as
+// processed, because a snapshot from before their placement was restored.
+func (c *Composer) retainPlacedFloats() {
+	kept := c.placedFloats[:0]
+	for _, pf := range c.placedFloats {
+		if _, ok := c.Work.Skips[pf.Placed.Location()]; ok {
+			kept = append(kept, pf)
+		}
+	}
+	c.placedFloats = kept
+}
+
 // PlacedFloats returns the floats that have been placed in this composition.
 func (c *Composer) PlacedFloats() []PlacedFloat {
 	return c.placedFloats
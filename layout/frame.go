@@ -0,0 +1,280 @@
+// Frames for Typst.
+// Translated from typst-library/src/layout/frame.rs
+
+package layout
+
+import "iter"
+
+// Frame represents a laid out frame of content. Frames are what layout
+// produces and what exporters consume: a page is a frame, and frames nest
+// through groups. Positions are relative to the frame's top-left corner,
+// with the y-axis pointing down.
+type Frame struct {
+	// Size is the frame dimensions.
+	Size Size
+	// Items contains the positioned frame content.
+	Items []PositionedItem
+}
+
+// Width returns the frame width.
+func (f *Frame) Width() Abs {
+	return f.Size.Width
+}
+
+// Height returns the frame height.
+func (f *Frame) Height() Abs {
+	return f.Size.Height
+}
+
+// Push adds an item to the frame at the given position.
+func (f *Frame) Push(pos Point, item FrameItem) {
+	f.Items = append(f.Items, PositionedItem{Pos: pos, Item: item})
+}
+
+// PushFrame adds a nested frame at the given position.
+func (f *Frame) PushFrame(pos Point, frame Frame) {
+	f.Items = append(f.Items, PositionedItem{Pos: pos, Item: GroupItem{Frame: frame}})
+}
+
+// PushMultiple adds multiple items at specified positions.
+func (f *Frame) PushMultiple(items []PositionedItem) {
+	f.Items = append(f.Items, items...)
+}
+
+// IsEmpty returns whether the frame has no items.
+func (f *Frame) IsEmpty() bool {
+	return len(f.Items) == 0
+}
+
+// All iterates over the items of the frame with their positions, in
+// drawing order. Groups are yielded as they are, without entering them.
+func (f *Frame) All() iter.Seq2[Point, FrameItem] {
+	return func(yield func(Point, FrameItem) bool) {
+		for _, entry := range f.Items {
+			if !yield(entry.Pos, entry.Item) {
+				return
+			}
+		}
+	}
+}
+
+// Walk iterates over the items of the frame and, recursively, of its
+// groups, in drawing order. Groups are entered instead of yielded. Each
+// item comes with the transformation from its coordinates to the frame's,
+// which combines the positions and transformations of the enclosing
+// groups. Exporters that need the clips or descriptions of groups use All
+// and descend into groups themselves.
+func (f *Frame) Walk() iter.Seq2[Transform, FrameItem] {
+	return func(yield func(Transform, FrameItem) bool) {
+		f.walk(IdentityTransform(), yield)
+	}
+}
+
+// walk yields the items of the frame in the coordinates given by ts. It
+// returns false once yield asked to stop.
+func (f *Frame) walk(ts Transform, yield func(Transform, FrameItem) bool) bool {
+	for _, entry := range f.Items {
+		local := TranslateTransform(entry.Pos.X, entry.Pos.Y).Then(ts)
+		group, ok := entry.Item.(GroupItem)
+		if !ok {
+			if !yield(local, entry.Item) {
+				return false
+			}
+			continue
+		}
+		if group.Transform != nil {
+			local = group.Transform.Then(local)
+		}
+		if !group.Frame.walk(local, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// PositionedItem represents an item with its position in the frame.
+type PositionedItem struct {
+	Pos  Point
+	Item FrameItem
+}
+
+// FrameItem represents an item within a frame. The items that render
+// something are defined in this package: groups, text, shapes, images,
+// and links. Exporters skip the items they don't know.
+type FrameItem interface {
+	isFrameItem()
+}
+
+// ForeignItem is embedded by frame items defined outside of this package,
+// like the introspection tags of page layout. It marks them as frame
+// items.
+type ForeignItem struct{}
+
+func (ForeignItem) isFrameItem() {}
+
+// GroupItem represents a nested frame.
+type GroupItem struct {
+	Frame Frame
+	// Transform is applied to the frame's contents relative to the
+	// group's position, or nil if the contents aren't transformed.
+	Transform *Transform
+	// Clip is the outline, in the frame's coordinates, that the contents
+	// are clipped to, or nil if they aren't clipped.
+	Clip Curve
+	// Alt describes the group's contents for assistive technology, like
+	// the caption of a figure. It is empty if the group has no description.
+	Alt string
+}
+
+func (GroupItem) isFrameItem() {}
+
+// TextItem represents text content for rendering.
+// This is a simplified item for direct text rendering.
+type TextItem struct {
+	// Text is the text content to render.
+	Text string
+	// FontSize is the font size in points.
+	FontSize Abs
+}
+
+func (TextItem) isFrameItem() {}
+
+// ShapedTextItem represents shaped text with glyph-level positioning.
+// This provides precise glyph positioning for high-quality PDF output.
+type ShapedTextItem struct {
+	// Glyphs contains the shaped glyph data.
+	Glyphs []ShapedGlyph
+	// FontSize is the font size in points.
+	FontSize Abs
+	// Fill is the text color.
+	Fill *Paint
+}
+
+func (ShapedTextItem) isFrameItem() {}
+
+// ShapedGlyph represents a single shaped glyph for rendering.
+type ShapedGlyph struct {
+	// GlyphID is the glyph index in the font.
+	GlyphID uint16
+	// XAdvance is the horizontal advance in em units.
+	XAdvance float64
+	// XOffset is the horizontal offset in em units.
+	XOffset float64
+	// YOffset is the vertical offset in em units.
+	YOffset float64
+	// Char is the original character (for ToUnicode mapping).
+	Char rune
+	// Font is a pointer to the font used for this glyph.
+	// Using interface{} to avoid circular import with font package.
+	Font interface{}
+}
+
+// ImageItem represents an embedded image.
+type ImageItem struct {
+	// Image contains the image data and metadata.
+	Image Image
+	// Size is the rendered size of the image.
+	Size Size
+	// Alt is the alternative text of the image, or empty if it has none.
+	Alt string
+}
+
+func (ImageItem) isFrameItem() {}
+
+// LinkItem marks an area of the frame as a link. It doesn't render
+// anything; exporters make the area clickable.
+type LinkItem struct {
+	// Dest is where the link points to.
+	Dest Destination
+	// Size is the size of the clickable area.
+	Size Size
+}
+
+func (LinkItem) isFrameItem() {}
+
+// Destination is the target of a link. Exactly one of URL, Label, and
+// Position is set; links to labels are resolved to positions by
+// ResolveLinks once the document is laid out.
+type Destination struct {
+	// URL is the web address of an external link.
+	URL string
+	// Label is the label of the element an internal link points to.
+	Label string
+	// Position is the position an internal link points to.
+	Position *Position
+}
+
+// Position is a point on a page of the document.
+type Position struct {
+	// Page is the 0-based index of the page.
+	Page int
+	// Point is the position on the page, from its top-left corner.
+	Point Point
+}
+
+// Image represents image data for embedding.
+type Image struct {
+	// Data is the raw image bytes.
+	Data []byte
+	// Format specifies the image format.
+	Format ImageFormat
+	// Width is the natural image width in pixels.
+	Width int
+	// Height is the natural image height in pixels.
+	Height int
+	// BitsPerComponent is typically 8.
+	BitsPerComponent int
+	// ColorSpace specifies the color space (e.g., DeviceRGB, DeviceGray).
+	ColorSpace ColorSpace
+	// Alpha contains optional alpha channel data.
+	Alpha []byte
+}
+
+// ImageFormat specifies the image encoding format.
+type ImageFormat int
+
+const (
+	// ImageFormatJPEG represents JPEG/DCTDecode encoded images.
+	ImageFormatJPEG ImageFormat = iota
+	// ImageFormatPNG represents PNG/FlateDecode encoded images.
+	ImageFormatPNG
+	// ImageFormatRaw represents uncompressed raw pixel data.
+	ImageFormatRaw
+)
+
+// ColorSpace specifies the PDF color space.
+type ColorSpace int
+
+const (
+	// ColorSpaceDeviceRGB represents the RGB color space.
+	ColorSpaceDeviceRGB ColorSpace = iota
+	// ColorSpaceDeviceGray represents the grayscale color space.
+	ColorSpaceDeviceGray
+	// ColorSpaceDeviceCMYK represents the CMYK color space.
+	ColorSpaceDeviceCMYK
+)
+
+// String returns the PDF name for the color space.
+func (cs ColorSpace) String() string {
+	switch cs {
+	case ColorSpaceDeviceRGB:
+		return "DeviceRGB"
+	case ColorSpaceDeviceGray:
+		return "DeviceGray"
+	case ColorSpaceDeviceCMYK:
+		return "DeviceCMYK"
+	default:
+		return "DeviceRGB"
+	}
+}
+
+// Paint represents a fill color or pattern.
+type Paint struct {
+	// Color is a solid color fill.
+	Color *Color
+}
+
+// Color represents an RGBA color.
+type Color struct {
+	R, G, B, A uint8
+}
@@ -0,0 +1,68 @@
+package layout
+
+import (
+	"testing"
+)
+
+func TestFrameWalk(t *testing.T) {
+	text := TextItem{Text: "inner", FontSize: 10}
+	scale := ScaleTransform(2, 2)
+	inner := Frame{Size: Size{Width: 20, Height: 10}}
+	inner.Push(Point{X: 1, Y: 2}, text)
+
+	var frame Frame
+	frame.Push(Point{X: 5}, LinkItem{Dest: Destination{URL: "https://typst.app"}})
+	frame.Push(Point{X: 10, Y: 20}, GroupItem{Frame: inner, Transform: &scale})
+
+	var direct []FrameItem
+	for _, item := range frame.All() {
+		direct = append(direct, item)
+	}
+	if len(direct) != 2 {
+		t.Fatalf("All yielded %d items, want 2", len(direct))
+	}
+	if _, ok := direct[1].(GroupItem); !ok {
+		t.Errorf("All yielded %T, want the group itself", direct[1])
+	}
+
+	var walked []FrameItem
+	var transforms []Transform
+	for ts, item := range frame.Walk() {
+		walked = append(walked, item)
+		transforms = append(transforms, ts)
+	}
+	if len(walked) != 2 || walked[1] != text {
+		t.Fatalf("Walk yielded %v, want the link and the text of the group", walked)
+	}
+
+	// The text is scaled with its group and then placed at the group's
+	// position.
+	if got := transforms[1].Apply(Point{}); got != (Point{X: 12, Y: 24}) {
+		t.Errorf("text origin is at %+v, want (12, 24)", got)
+	}
+	if got := transforms[0].Apply(Point{}); got != (Point{X: 5}) {
+		t.Errorf("link origin is at %+v, want (5, 0)", got)
+	}
+
+	// Walking stops when asked to.
+	count := 0
+	for range frame.Walk() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Walk continued after break")
+	}
+}
+
+func TestForeignItem(t *testing.T) {
+	type marker struct {
+		ForeignItem
+		Name string
+	}
+	var frame Frame
+	frame.Push(Point{}, marker{Name: "tag"})
+	if frame.IsEmpty() {
+		t.Error("frame with a foreign item is empty")
+	}
+}
@@ -12,7 +12,7 @@ type GridLayouter struct {
 	// Grid is the grid structure being laid out.
 	Grid *Grid
 	// Regions provides the available layout regions.
-	Regions *flow.Regions
+	Regions *layout.Regions
 	// RCols holds resolved column widths.
 	RCols []layout.Abs
 	// Width is the total grid width.
@@ -38,6 +38,9 @@ type GridLayouter struct {
 
 	// CellLocators maps cell positions to their locators.
 	CellLocators map[Axes[int]]interface{}
+	// RowspanPieces holds the parts of each rowspan cell, one for each
+	// region the cell spans or is repeated in, keyed by cell position.
+	RowspanPieces map[Axes[int]][]CellLayout
 	// Styles is the inherited style chain.
 	Styles interface{}
 	// IsRTL indicates right-to-left layout.
@@ -56,20 +59,21 @@ type GridLayouter struct {
 func NewGridLayouter(
 	engine *flow.Engine,
 	grid *Grid,
-	regions *flow.Regions,
+	regions *layout.Regions,
 	styles interface{},
 	isRTL bool,
 ) *GridLayouter {
 	gl := &GridLayouter{
-		Grid:         grid,
-		Regions:      regions,
-		RCols:        make([]layout.Abs, len(grid.Cols)),
-		Finished:     make([]flow.Frame, 0),
-		CellLocators: make(map[Axes[int]]interface{}),
-		Styles:       styles,
-		IsRTL:        isRTL,
-		RowState:     NewRowState(),
-		engine:       engine,
+		Grid:          grid,
+		Regions:       regions,
+		RCols:         make([]layout.Abs, len(grid.Cols)),
+		Finished:      make([]flow.Frame, 0),
+		CellLocators:  make(map[Axes[int]]interface{}),
+		RowspanPieces: make(map[Axes[int]][]CellLayout),
+		Styles:        styles,
+		IsRTL:         isRTL,
+		RowState:      NewRowState(),
+		engine:        engine,
 	}
 
 	// Initialize current region state.
@@ -99,6 +103,9 @@ func (gl *GridLayouter) Layout() ([]flow.Frame, error) {
 		if err := gl.layoutRow(gl.Current.Row); err != nil {
 			return nil, err
 		}
+		if gl.UnbreakableRowsLeft > 0 {
+			gl.UnbreakableRowsLeft--
+		}
 		gl.Current.Row++
 	}
 
@@ -117,6 +124,7 @@ func (gl *GridLayouter) Layout() ([]flow.Frame, error) {
 // 3. Distribute remaining space to fractional columns
 func (gl *GridLayouter) measureColumns() error {
 	available := gl.Regions.Size.Width
+	var rel layout.Abs
 
 	// Track fractional columns for final distribution.
 	var frCols []int
@@ -129,7 +137,8 @@ func (gl *GridLayouter) measureColumns() error {
 	for i, sizing := range gl.Grid.Cols {
 		switch s := sizing.(type) {
 		case SizingRel:
-			gl.RCols[i] = s.RelativeTo(available)
+			gl.RCols[i] = s.RelativeTo(gl.Regions.Base().Width)
+			rel += gl.RCols[i]
 		case SizingAuto:
 			autoCols = append(autoCols, i)
 			gl.RCols[i] = 0 // Will be measured
@@ -140,8 +149,8 @@ func (gl *GridLayouter) measureColumns() error {
 		}
 	}
 
-	// Phase 2: Measure auto columns.
-	if err := gl.measureAutoColumns(autoCols); err != nil {
+	// Phase 2: Measure auto columns in the space the fixed columns leave.
+	if err := gl.measureAutoColumns(autoCols, available-rel); err != nil {
 		return err
 	}
 
@@ -152,8 +161,9 @@ func (gl *GridLayouter) measureColumns() error {
 	}
 	remaining := available - usedWidth
 
-	// Phase 3: Distribute remaining space to fractional columns.
-	if len(frCols) > 0 && remaining > 0 && totalFr > 0 {
+	// Phase 3: Distribute remaining space to fractional columns. In an
+	// unbounded region, there is no remaining space to distribute.
+	if len(frCols) > 0 && remaining > 0 && remaining.IsFinite() && totalFr > 0 {
 		for _, i := range frCols {
 			fr := gl.Grid.Cols[i].(SizingFr).Fr
 			gl.RCols[i] = layout.Abs(float64(remaining) * float64(fr) / float64(totalFr))
@@ -172,12 +182,20 @@ func (gl *GridLayouter) measureColumns() error {
 	return nil
 }
 
-// measureAutoColumns measures the natural width of auto columns.
-func (gl *GridLayouter) measureAutoColumns(autoCols []int) error {
+// measureAutoColumns measures the natural width of auto columns. Cells are
+// measured in a region of the available width that doesn't expand, so that
+// they take their natural width, but no more than is available.
+// Matches Rust: GridLayouter::measure_auto_columns
+func (gl *GridLayouter) measureAutoColumns(autoCols []int, available layout.Abs) error {
 	if len(autoCols) == 0 {
 		return nil
 	}
 
+	pod := layout.NewRegion(
+		layout.Size{Width: available, Height: gl.Regions.Base().Height},
+		layout.Axes[bool]{},
+	)
+
 	// For each auto column, find the maximum width needed by any cell.
 	for _, col := range autoCols {
 		var maxWidth layout.Abs
@@ -195,13 +213,13 @@ func (gl *GridLayouter) measureAutoColumns(autoCols []int) error {
 			}
 
 			// Measure the cell's natural width.
-			width, err := gl.measureCellWidth(cell)
+			size, err := gl.measureCell(cell, pod)
 			if err != nil {
 				return err
 			}
 
-			if width > maxWidth {
-				maxWidth = width
+			if size.Width > maxWidth {
+				maxWidth = size.Width
 			}
 		}
 
@@ -211,37 +229,39 @@ func (gl *GridLayouter) measureAutoColumns(autoCols []int) error {
 	return nil
 }
 
-// measureCellWidth measures the natural width of a cell.
-// It determines the minimum width needed to display the cell's content
-// without wrapping (its intrinsic width).
-func (gl *GridLayouter) measureCellWidth(cell *Cell) (layout.Abs, error) {
+// measureCell measures the size of a cell's content laid out into the
+// region. Content that can't measure itself is estimated.
+func (gl *GridLayouter) measureCell(cell *Cell, region layout.Region) (layout.Size, error) {
 	if cell.Body == nil {
-		return 0, nil
+		return layout.Size{}, nil
 	}
 
 	// Check if the body implements the Measurable interface.
 	if m, ok := cell.Body.(Measurable); ok {
-		return m.MeasureWidth(), nil
+		return m.Measure(region), nil
 	}
 
 	// Check if the body is a flow.Frame (has a Size method).
-	if sizer, ok := cell.Body.(interface{ Width() layout.Abs }); ok {
-		return sizer.Width(), nil
+	if sizer, ok := cell.Body.(interface {
+		Width() layout.Abs
+		Height() layout.Abs
+	}); ok {
+		return region.Fit(layout.Size{Width: sizer.Width(), Height: sizer.Height()}), nil
 	}
 
 	// Check for string content - common case for simple table cells.
 	if s, ok := cell.Body.(string); ok {
-		// Estimate width based on string length.
+		// Estimate the size based on string length and available width.
 		// Assume ~6pt per character at 12pt font (0.5em average character width).
-		const defaultFontSize = 12.0
-		const charWidthFactor = 0.5
-		return layout.Abs(len(s)) * defaultFontSize * charWidthFactor, nil
+		text := TextContent{Text: s, FontSize: 12}
+		return text.Measure(region), nil
 	}
 
-	// Default minimum width for unknown content types.
-	// This provides a reasonable fallback while ensuring cells have some width.
+	// Default size for unknown content types.
+	// This provides a reasonable fallback while ensuring cells have some size.
 	const defaultMinWidth = 20.0 // ~0.28 inches
-	return defaultMinWidth, nil
+	const defaultHeight = 14.4   // ~12pt * 1.2 line height
+	return region.Fit(layout.Size{Width: defaultMinWidth, Height: defaultHeight}), nil
 }
 
 // shrinkAutoColumns applies fair-share shrinking to auto columns.
@@ -307,13 +327,15 @@ func (gl *GridLayouter) shrinkAutoColumns(autoCols []int, excess layout.Abs) {
 
 // layoutRow lays out a single row.
 func (gl *GridLayouter) layoutRow(y int) error {
-	// Check if this is a gutter row.
-	isGutter := gl.Grid.HasGutter && y%2 == 1
-
-	if isGutter {
+	if gl.Grid.IsGutterTrack(y) {
 		return gl.layoutGutterRow(y)
 	}
 
+	// Keep rows connected by unbreakable rowspans together.
+	if err := gl.checkForUnbreakableRows(y); err != nil {
+		return err
+	}
+
 	// Determine the row sizing.
 	sizing := gl.Grid.Rows[y]
 
@@ -331,22 +353,25 @@ func (gl *GridLayouter) layoutRow(y int) error {
 	}
 }
 
-// layoutGutterRow lays out a gutter row.
+// layoutGutterRow lays out a gutter row. Gutter never breaks the region:
+// gutter at the top of a region is omitted and gutter at its bottom is
+// stripped when the region is finished.
 func (gl *GridLayouter) layoutGutterRow(y int) error {
-	height := gl.Grid.RowGutter
-
-	// Check if there's room in the current region.
-	if !gl.fitsInRegion(height) {
-		if err := gl.finishRegion(false); err != nil {
-			return err
-		}
+	rows := &gl.RRows[gl.Current.RegionIdx]
+	if len(rows.Heights) == 0 {
+		return nil
 	}
+	rows.IsGutter[y] = true
 
-	// Record the gutter row.
-	gl.RRows[gl.Current.RegionIdx].Heights[y] = height
-	gl.RRows[gl.Current.RegionIdx].IsGutter[y] = true
-	gl.Current.Height += height
-
+	switch s := gl.Grid.Rows[y].(type) {
+	case SizingRel:
+		gl.recordRow(y, s.RelativeTo(gl.Regions.Full.Height))
+	case SizingFr:
+		return gl.deferFractionalRow(y, s)
+	default:
+		// Gutter has no cells, so an auto gutter row is empty.
+		gl.recordRow(y, 0)
+	}
 	return nil
 }
 
@@ -375,8 +400,7 @@ func (gl *GridLayouter) layoutAutoRow(y int) error {
 	}
 
 	// Record the row.
-	gl.RRows[gl.Current.RegionIdx].Heights[y] = height
-	gl.Current.Height += height
+	gl.recordRow(y, height)
 
 	return nil
 }
@@ -399,10 +423,28 @@ func (gl *GridLayouter) layoutRelativeRow(y int, sizing SizingRel) error {
 		return err
 	}
 
+	gl.recordRow(y, height)
+
+	return nil
+}
+
+// recordRow records the height of a laid out row in the current region and
+// adds it to the rowspans that span the row.
+func (gl *GridLayouter) recordRow(y int, height layout.Abs) {
 	gl.RRows[gl.Current.RegionIdx].Heights[y] = height
 	gl.Current.Height += height
+	gl.accumulateRowspans(y, height)
+}
 
-	return nil
+// accumulateRowspans adds height to the current region's part of the
+// rowspans that span row y.
+func (gl *GridLayouter) accumulateRowspans(y int, height layout.Abs) {
+	for i := range gl.Rowspans {
+		rs := &gl.Rowspans[i]
+		if rs.Y <= y && y < rs.Y+rs.RowspanCount {
+			rs.Heights[len(rs.Heights)-1] += height
+		}
+	}
 }
 
 // deferFractionalRow defers a fractional row to region finalization.
@@ -416,30 +458,51 @@ func (gl *GridLayouter) deferFractionalRow(y int, sizing SizingFr) error {
 
 // measureRowHeight measures the natural height of a row.
 func (gl *GridLayouter) measureRowHeight(y int) (layout.Abs, error) {
+	return gl.simulateRowHeight(y, nil)
+}
+
+// simulateRowHeight measures the natural height of a row. A rowspan that
+// ends in the row makes the row tall enough for the part of its cell that
+// the rows above it, in this and earlier regions, don't cover. Pending
+// holds the heights of rows above that are simulated but not laid out.
+// Matches Rust: GridLayouter::measure_auto_row
+func (gl *GridLayouter) simulateRowHeight(y int, pending map[int]layout.Abs) (layout.Abs, error) {
 	var maxHeight layout.Abs
 
 	for x := 0; x < gl.Grid.ColCount; x++ {
-		cell := gl.Grid.CellAt(x, y)
-		if cell == nil {
-			continue
+		var cell *Cell
+		switch entry := gl.Grid.EntryAt(x, y).(type) {
+		case EntryCell:
+			cell = entry.Cell
+		case EntryMerged:
+			cell = entry.Parent
 		}
-
-		// Skip cells that start in an earlier row (rowspan continuation).
-		if cell.Y != y {
+		if cell == nil || cell.X != x {
 			continue
 		}
 
-		// For single-row cells, measure height directly.
-		if cell.Rowspan == 1 {
-			height, err := gl.measureCellHeight(cell, x)
+		var height layout.Abs
+		switch {
+		case cell.Y == y && cell.Rowspan == 1:
+			// For single-row cells, measure height directly.
+			h, err := gl.measureCellHeight(cell)
 			if err != nil {
 				return 0, err
 			}
-			if height > maxHeight {
-				maxHeight = height
+			height = h
+		case cell.Rowspan > 1 && cell.Y+cell.Rowspan-1 == y:
+			// The last row of a rowspan takes what is left of it.
+			h, err := gl.measureCellHeight(cell)
+			if err != nil {
+				return 0, err
 			}
+			height = h - gl.spannedHeight(cell.Y, y, pending)
+		default:
+			continue
+		}
+		if height > maxHeight {
+			maxHeight = height
 		}
-		// Multi-row cells are handled by rowspan tracking.
 	}
 
 	// Minimum row height.
@@ -450,55 +513,105 @@ func (gl *GridLayouter) measureRowHeight(y int) (layout.Abs, error) {
 	return maxHeight, nil
 }
 
-// measureCellHeight measures the natural height of a cell.
-// The height depends on the available width, as content may wrap.
-func (gl *GridLayouter) measureCellHeight(cell *Cell, x int) (layout.Abs, error) {
-	if cell.Body == nil {
-		return 0, nil
+// spannedHeight returns the total height of rows from..to-1 in all
+// regions, taking rows in pending from there.
+func (gl *GridLayouter) spannedHeight(from, to int, pending map[int]layout.Abs) layout.Abs {
+	var total layout.Abs
+	for y := from; y < to; y++ {
+		if h, ok := pending[y]; ok {
+			total += h
+			continue
+		}
+		for _, rows := range gl.RRows {
+			total += rows.Heights[y]
+		}
 	}
+	return total
+}
 
-	// Calculate the available width for this cell.
-	width := layout.Abs(0)
-	for col := x; col < x+cell.Colspan && col < gl.Grid.ColCount; col++ {
-		width += gl.RCols[col]
+// checkForUnbreakableRows keeps the rows connected by unbreakable rowspans
+// starting at row y in one region. If the group doesn't fit into the rest
+// of the current region, the region is finished first, unless the group
+// already starts the region.
+// Matches Rust: GridLayouter::check_for_unbreakable_rows
+func (gl *GridLayouter) checkForUnbreakableRows(y int) error {
+	if gl.UnbreakableRowsLeft > 0 {
+		return nil
 	}
-
-	// Check if the body implements the Measurable interface.
-	if m, ok := cell.Body.(Measurable); ok {
-		return m.MeasureHeight(width), nil
+	count := gl.unbreakableGroupLen(y)
+	if count <= 1 {
+		return nil
 	}
 
-	// Check if the body is a flow.Frame (has a Height method).
-	if sizer, ok := cell.Body.(interface{ Height() layout.Abs }); ok {
-		return sizer.Height(), nil
+	height, err := gl.simulateGroupHeight(y, count)
+	if err != nil {
+		return err
 	}
-
-	// Check for string content.
-	if s, ok := cell.Body.(string); ok {
-		// Estimate height based on string length and available width.
-		const defaultFontSize layout.Abs = 12.0
-		const charWidthFactor = 0.5
-		const lineHeightFactor = 1.2
-
-		charWidth := defaultFontSize * charWidthFactor
-		if width <= 0 {
-			width = charWidth * layout.Abs(len(s)) // Assume single line
+	if !gl.fitsInRegion(height) && gl.Current.Height > gl.Current.Initial && gl.canBreakBefore(y) {
+		if err := gl.finishRegion(false); err != nil {
+			return err
 		}
+	}
+	gl.UnbreakableRowsLeft = count
+	return nil
+}
 
-		charsPerLine := int(float64(width) / float64(charWidth))
-		if charsPerLine <= 0 {
-			charsPerLine = 1
+// unbreakableGroupLen returns how many rows, starting at row y, unbreakable
+// rowspans connect.
+func (gl *GridLayouter) unbreakableGroupLen(y int) int {
+	end := y + 1
+	for row := y; row < end && row < gl.Grid.RowCount; row++ {
+		for x := 0; x < gl.Grid.ColCount; x++ {
+			cell := gl.Grid.CellAt(x, row)
+			if cell != nil && cell.Y == row && cell.Rowspan > 1 && !cell.Breakable {
+				end = max(end, row+cell.Rowspan)
+			}
 		}
-		numLines := (len(s) + charsPerLine - 1) / charsPerLine
-		if numLines < 1 {
-			numLines = 1
+	}
+	return min(end, gl.Grid.RowCount) - y
+}
+
+// simulateGroupHeight returns the total height of count rows starting at
+// row y without laying them out.
+// Matches Rust: GridLayouter::simulate_unbreakable_row_group
+func (gl *GridLayouter) simulateGroupHeight(y, count int) (layout.Abs, error) {
+	pending := make(map[int]layout.Abs, count)
+	var total layout.Abs
+	for row := y; row < y+count; row++ {
+		var height layout.Abs
+		switch s := gl.Grid.Rows[row].(type) {
+		case SizingRel:
+			height = s.RelativeTo(gl.Regions.Full.Height)
+		case SizingFr:
+			// Fractional rows take no space of their own.
+		default:
+			if !gl.Grid.IsGutterTrack(row) {
+				h, err := gl.simulateRowHeight(row, pending)
+				if err != nil {
+					return 0, err
+				}
+				height = h
+			}
 		}
-		return layout.Abs(numLines) * defaultFontSize * lineHeightFactor, nil
+		pending[row] = height
+		total += height
 	}
+	return total, nil
+}
 
-	// Default height for unknown content types.
-	const defaultHeight = 14.4 // ~12pt * 1.2 line height
-	return defaultHeight, nil
+// measureCellHeight measures the natural height of a cell.
+// The height depends on the available width, as content may wrap. The cell
+// fills its columns and may grow as tall as it needs to.
+func (gl *GridLayouter) measureCellHeight(cell *Cell) (layout.Abs, error) {
+	pod := layout.NewRegion(
+		layout.Size{Width: gl.cellWidth(cell), Height: layout.Inf()},
+		layout.Axes[bool]{X: true},
+	)
+	size, err := gl.measureCell(cell, pod)
+	if err != nil {
+		return 0, err
+	}
+	return size.Height, nil
 }
 
 // layoutRowCells lays out all cells in a row.
@@ -525,11 +638,7 @@ func (gl *GridLayouter) layoutRowCells(y int, height layout.Abs) error {
 
 // layoutCell lays out a single cell at the given position.
 func (gl *GridLayouter) layoutCell(cell *Cell, dx, dy, height layout.Abs) error {
-	// Calculate the cell's width (accounting for colspan).
-	width := layout.Abs(0)
-	for col := cell.X; col < cell.X+cell.Colspan && col < gl.Grid.ColCount; col++ {
-		width += gl.RCols[col]
-	}
+	width := gl.cellWidth(cell)
 
 	// If this is a multi-row cell, register it as a rowspan.
 	if cell.Rowspan > 1 {
@@ -552,6 +661,15 @@ func (gl *GridLayouter) layoutCell(cell *Cell, dx, dy, height layout.Abs) error
 	return nil
 }
 
+// cellWidth returns the width of a cell, accounting for colspan.
+func (gl *GridLayouter) cellWidth(cell *Cell) layout.Abs {
+	width := layout.Abs(0)
+	for col := cell.X; col < cell.X+cell.Colspan && col < gl.Grid.ColCount; col++ {
+		width += gl.RCols[col]
+	}
+	return width
+}
+
 // CellLayout stores the layout information for a cell.
 type CellLayout struct {
 	// X is the horizontal position of the cell.
@@ -564,6 +682,8 @@ type CellLayout struct {
 	Height layout.Abs
 	// Align is the cell's content alignment.
 	Align flow.Axes[flow.FixedAlignment]
+	// Region is the index of the region the cell is in.
+	Region int
 }
 
 // AlignContentInCell calculates the position offset for content within a cell
@@ -596,7 +716,7 @@ func (gl *GridLayouter) registerRowspan(cell *Cell, dx, dy layout.Abs) {
 		DY:            dy,
 		FirstRegion:   gl.Current.RegionIdx,
 		RegionFull:    gl.Regions.Size.Height,
-		Heights:       []layout.Abs{gl.Regions.Size.Height - dy},
+		Heights:       []layout.Abs{0},
 		IsUnbreakable: !cell.Breakable,
 	}
 	gl.Rowspans = append(gl.Rowspans, rowspan)
@@ -680,6 +800,7 @@ func (gl *GridLayouter) stripTrailingGutter() {
 		// Remove this gutter row's height.
 		if h, ok := rrows.Heights[lastRow]; ok {
 			gl.Current.Height -= h
+			gl.accumulateRowspans(lastRow, -h)
 			delete(rrows.Heights, lastRow)
 			delete(rrows.IsGutter, lastRow)
 		}
@@ -709,7 +830,11 @@ func (gl *GridLayouter) sizeFractionalRows() {
 	var frRows []int
 	var totalFr layout.Fr
 
+	rows := gl.RRows[gl.Current.RegionIdx]
 	for y := 0; y < gl.Current.Row; y++ {
+		if _, ok := rows.Heights[y]; !ok {
+			continue
+		}
 		if sizing, ok := gl.Grid.Rows[y].(SizingFr); ok {
 			frRows = append(frRows, y)
 			totalFr += sizing.Fr
@@ -720,9 +845,9 @@ func (gl *GridLayouter) sizeFractionalRows() {
 		return
 	}
 
-	// Calculate remaining space.
+	// Calculate remaining space. In an unbounded region, there is none.
 	remaining := gl.Regions.Size.Height - gl.Current.Height
-	if remaining <= 0 {
+	if remaining <= 0 || !remaining.IsFinite() {
 		return
 	}
 
@@ -730,20 +855,16 @@ func (gl *GridLayouter) sizeFractionalRows() {
 	for _, y := range frRows {
 		fr := gl.Grid.Rows[y].(SizingFr).Fr
 		height := layout.Abs(float64(remaining) * float64(fr) / float64(totalFr))
-		gl.RRows[gl.Current.RegionIdx].Heights[y] = height
-		gl.Current.Height += height
+		gl.recordRow(y, height)
 	}
 }
 
-// completeRowspans finalizes rowspans that end in this region.
+// completeRowspans places the rowspans whose rows are all laid out.
 func (gl *GridLayouter) completeRowspans() {
-	// Filter out completed rowspans.
 	var remaining []Rowspan
 	for _, rs := range gl.Rowspans {
-		endRow := rs.Y + rs.RowspanCount
-		if endRow <= gl.Current.Row {
-			// Rowspan is complete.
-			// TODO: Place the rowspan cell content.
+		if rs.Y+rs.RowspanCount <= gl.Current.Row {
+			gl.placeRowspan(rs)
 		} else {
 			remaining = append(remaining, rs)
 		}
@@ -751,12 +872,41 @@ func (gl *GridLayouter) completeRowspans() {
 	gl.Rowspans = remaining
 }
 
+// placeRowspan splits a rowspan cell into one piece per region it spans.
+// The first piece starts at the cell's row, later ones below the headers
+// repeated at the top of their region.
+// Matches Rust: GridLayouter::layout_rowspan
+func (gl *GridLayouter) placeRowspan(rs Rowspan) {
+	cell := gl.Grid.CellAt(rs.X, rs.Y)
+	if cell == nil {
+		return
+	}
+	pos := Axes[int]{X: rs.X, Y: rs.Y}
+	pieces := make([]CellLayout, len(rs.Heights))
+	for i, height := range rs.Heights {
+		region := rs.FirstRegion + i
+		y := gl.RRows[region].Start
+		if i == 0 {
+			y = rs.DY
+		}
+		pieces[i] = CellLayout{
+			X:      rs.DX,
+			Y:      y,
+			Width:  gl.cellWidth(cell),
+			Height: height,
+			Align:  cell.Align,
+			Region: region,
+		}
+	}
+	gl.RowspanPieces[pos] = pieces
+	gl.CellLocators[pos] = &pieces[0]
+}
+
 // buildRegionFrame creates the output frame for the current region.
 func (gl *GridLayouter) buildRegionFrame() flow.Frame {
-	// Calculate the actual height used.
-	height := gl.Current.Height
-
-	size := layout.Size{Width: gl.Width, Height: height}
+	// The grid takes the size of its rows and columns, but no more than
+	// the region.
+	size := layout.Size{Width: gl.Width, Height: gl.Current.Height}.Min(gl.Regions.Size)
 	frame := flow.NewFrame(size)
 
 	// TODO: Add cell frames to the output frame.
@@ -769,14 +919,8 @@ func (gl *GridLayouter) buildRegionFrame() flow.Frame {
 func (gl *GridLayouter) advanceRegion() error {
 	gl.Current.RegionIdx++
 
-	// Get the next region's height from backlog.
-	if len(gl.Regions.Backlog) > 0 {
-		gl.Regions.Size.Height = gl.Regions.Backlog[0]
-		gl.Regions.Backlog = gl.Regions.Backlog[1:]
-	} else if gl.Regions.Last != nil {
-		gl.Regions.Size.Height = gl.Regions.Last.Height
-		gl.Regions.Last = nil
-	}
+	// Take the next region's height from the backlog.
+	gl.Regions.Next()
 
 	// Reset current region state.
 	gl.Current.Height = 0
@@ -785,23 +929,77 @@ func (gl *GridLayouter) advanceRegion() error {
 	// Add a new row state for this region.
 	gl.RRows = append(gl.RRows, NewRowState())
 
-	// Update rowspan heights for the new region.
+	// Start a new part of the rowspans continuing in the new region.
 	for i := range gl.Rowspans {
-		gl.Rowspans[i].Heights = append(gl.Rowspans[i].Heights, gl.Regions.Size.Height)
+		gl.Rowspans[i].Heights = append(gl.Rowspans[i].Heights, 0)
 	}
 
 	return nil
 }
 
-// prepareHeadersForNextRegion prepares headers to repeat in the next region.
+// prepareHeadersForNextRegion repeats the headers at the top of the new
+// region. Their rows keep the heights they had where they were first laid
+// out, and rowspans within them are repeated with them. Rowspans that
+// continue from the previous region start below the headers.
 func (gl *GridLayouter) prepareHeadersForNextRegion() {
 	// Move pending headers to repeating headers.
 	gl.RepeatingHeaders = append(gl.RepeatingHeaders, gl.PendingHeaders...)
 	gl.PendingHeaders = nil
 
-	// Layout repeating headers at the start of the new region.
+	rows := &gl.RRows[gl.Current.RegionIdx]
+	offsets := make(map[int]layout.Abs)
 	for _, header := range gl.RepeatingHeaders {
-		_ = header // TODO: Re-layout header rows
+		for y := header.StartY; y < header.EndY; y++ {
+			height, isGutter := gl.firstRowHeight(y)
+			offsets[y] = gl.Current.Height
+			rows.Heights[y] = height
+			if isGutter {
+				rows.IsGutter[y] = true
+			}
+			gl.Current.Height += height
+		}
+		gl.repeatHeaderRowspans(header, offsets)
+	}
+	gl.Current.Initial = gl.Current.Height
+	rows.Start = gl.Current.Height
+}
+
+// firstRowHeight returns the height of a row in the first region it was
+// laid out in and whether it is a gutter row.
+func (gl *GridLayouter) firstRowHeight(y int) (layout.Abs, bool) {
+	for _, rows := range gl.RRows {
+		if height, ok := rows.Heights[y]; ok {
+			return height, rows.IsGutter[y]
+		}
+	}
+	return 0, false
+}
+
+// repeatHeaderRowspans adds a piece in the current region for each rowspan
+// in a repeated header, given the offsets of the header's rows.
+func (gl *GridLayouter) repeatHeaderRowspans(header Header, offsets map[int]layout.Abs) {
+	rows := gl.RRows[gl.Current.RegionIdx]
+	for y := header.StartY; y < header.EndY; y++ {
+		dx := layout.Abs(0)
+		for x := 0; x < gl.Grid.ColCount; x++ {
+			cell := gl.Grid.CellAt(x, y)
+			if cell != nil && cell.X == x && cell.Y == y && cell.Rowspan > 1 {
+				var height layout.Abs
+				for row := y; row < min(y+cell.Rowspan, header.EndY); row++ {
+					height += rows.Heights[row]
+				}
+				pos := Axes[int]{X: x, Y: y}
+				gl.RowspanPieces[pos] = append(gl.RowspanPieces[pos], CellLayout{
+					X:      dx,
+					Y:      offsets[y],
+					Width:  gl.cellWidth(cell),
+					Height: height,
+					Align:  cell.Align,
+					Region: gl.Current.RegionIdx,
+				})
+			}
+			dx += gl.RCols[x]
+		}
 	}
 }
 
@@ -16,10 +16,10 @@ func TestNewGridLayouter(t *testing.T) {
 		RowCount: 2,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -53,10 +53,10 @@ func TestMeasureColumns_Fixed(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -90,10 +90,10 @@ func TestMeasureColumns_Relative(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -124,10 +124,10 @@ func TestMeasureColumns_Fractional(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -420,10 +420,10 @@ func TestCellAlignment_StoredInLayout(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -472,10 +472,10 @@ func TestMeasureColumns_Auto(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 500, Height: 300},
 		Full:   layout.Size{Width: 500, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -510,10 +510,10 @@ func TestMeasureColumns_AutoWithNilBody(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -545,10 +545,10 @@ func TestMeasureColumns_AutoWithMeasurable(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -557,8 +557,9 @@ func TestMeasureColumns_AutoWithMeasurable(t *testing.T) {
 		t.Fatalf("measureColumns failed: %v", err)
 	}
 
-	// Width should match the measurable's reported width
-	expectedWidth := measurableContent.MeasureWidth()
+	// Width should match the measurable's reported natural width
+	unbounded := layout.NewRegion(layout.Size{Width: layout.Inf(), Height: layout.Inf()}, layout.Axes[bool]{})
+	expectedWidth := measurableContent.Measure(unbounded).Width
 	if gl.RCols[0] != expectedWidth {
 		t.Errorf("expected column 0 width %v, got %v", expectedWidth, gl.RCols[0])
 	}
@@ -580,10 +581,10 @@ func TestMeasureColumns_MixedSizing(t *testing.T) {
 		RowCount: 1,
 	}
 
-	regions := &flow.Regions{
+	regions := &layout.Regions{
 		Size:   layout.Size{Width: 200, Height: 300},
 		Full:   layout.Size{Width: 200, Height: 300},
-		Expand: flow.Axes[bool]{X: false, Y: false},
+		Expand: layout.Axes[bool]{X: false, Y: false},
 	}
 
 	gl := NewGridLayouter(nil, grid, regions, nil, false)
@@ -609,6 +610,12 @@ func TestMeasureColumns_MixedSizing(t *testing.T) {
 	}
 }
 
+// cellRegion returns the region of a cell of the given width that may grow
+// as tall as it needs to.
+func cellRegion(width layout.Abs) layout.Region {
+	return layout.NewRegion(layout.Size{Width: width, Height: layout.Inf()}, layout.Axes[bool]{X: true})
+}
+
 func TestTextContent_MeasureWidth(t *testing.T) {
 	tc := &TextContent{
 		Text:            "Hello",
@@ -616,11 +623,24 @@ func TestTextContent_MeasureWidth(t *testing.T) {
 		ApproxCharWidth: 6,
 	}
 
-	width := tc.MeasureWidth()
+	// In an unbounded region, the text takes its natural width.
+	unbounded := layout.NewRegion(layout.Size{Width: layout.Inf(), Height: layout.Inf()}, layout.Axes[bool]{})
+	width := tc.Measure(unbounded).Width
 	expected := layout.Abs(5 * 6) // 5 chars * 6pt per char
 	if width != expected {
 		t.Errorf("expected width %v, got %v", expected, width)
 	}
+
+	// In a narrower region, it takes no more than the region's width.
+	narrow := layout.NewRegion(layout.Size{Width: 20, Height: layout.Inf()}, layout.Axes[bool]{})
+	if width := tc.Measure(narrow).Width; width != 20 {
+		t.Errorf("expected width 20 in a narrow region, got %v", width)
+	}
+
+	// In an expanding region, it fills the region.
+	if width := tc.Measure(cellRegion(100)).Width; width != 100 {
+		t.Errorf("expected width 100 in an expanding region, got %v", width)
+	}
 }
 
 func TestTextContent_MeasureHeight(t *testing.T) {
@@ -631,14 +651,14 @@ func TestTextContent_MeasureHeight(t *testing.T) {
 	}
 
 	// With wide width, should be single line
-	height := tc.MeasureHeight(200)
+	height := tc.Measure(cellRegion(200)).Height
 	expectedSingleLine := layout.Abs(12 * 1.2) // 12pt * 1.2 line height
 	if !height.ApproxEq(expectedSingleLine) {
 		t.Errorf("expected height %v for single line, got %v", expectedSingleLine, height)
 	}
 
 	// With narrow width, should wrap to multiple lines
-	height = tc.MeasureHeight(36) // 36pt = 6 chars per line
+	height = tc.Measure(cellRegion(36)).Height // 36pt = 6 chars per line
 	// "Hello World Test" = 16 chars, 6 chars per line = 3 lines
 	expectedMultiLine := layout.Abs(3 * 12 * 1.2)
 	if !height.ApproxEq(expectedMultiLine) {
@@ -652,12 +672,13 @@ func TestFrameContent_Measure(t *testing.T) {
 		Height: 50,
 	}
 
-	if fc.MeasureWidth() != 100 {
-		t.Errorf("expected width 100, got %v", fc.MeasureWidth())
+	size := fc.Measure(layout.NewRegion(layout.Size{Width: 200, Height: 200}, layout.Axes[bool]{}))
+	if size.Width != 100 {
+		t.Errorf("expected width 100, got %v", size.Width)
 	}
 
-	if fc.MeasureHeight(200) != 50 {
-		t.Errorf("expected height 50, got %v", fc.MeasureHeight(200))
+	if size.Height != 50 {
+		t.Errorf("expected height 50, got %v", size.Height)
 	}
 }
 
@@ -668,11 +689,330 @@ func TestMeasuredCell(t *testing.T) {
 		Height: 20,
 	}
 
-	if mc.MeasureWidth() != 80 {
-		t.Errorf("expected width 80, got %v", mc.MeasureWidth())
+	size := mc.Measure(layout.NewRegion(layout.Size{Width: 100, Height: 100}, layout.Axes[bool]{}))
+	if size.Width != 80 {
+		t.Errorf("expected width 80, got %v", size.Width)
+	}
+
+	if size.Height != 20 {
+		t.Errorf("expected height 20, got %v", size.Height)
+	}
+}
+
+// rowspanGrid builds a one-column grid with the given rows, where a cell
+// at row y spans the given number of rows.
+func rowspanGrid(rows []Sizing, spans map[int]*Cell) *Grid {
+	entries := make([]Entry, len(rows))
+	for y := range rows {
+		if cell, ok := spans[y]; ok {
+			entries[y] = EntryCell{Cell: cell}
+			for row := y + 1; row < y+cell.Rowspan; row++ {
+				entries[row] = EntryMerged{Parent: cell}
+			}
+		}
+	}
+	return &Grid{
+		Cols:     []Sizing{SizingRel{Abs: 100}},
+		Rows:     rows,
+		Entries:  entries,
+		ColCount: 1,
+		RowCount: len(rows),
+	}
+}
+
+// frameOfHeight returns an empty frame of the given height as cell body.
+func frameOfHeight(height layout.Abs) *flow.Frame {
+	frame := flow.NewFrame(layout.Size{Height: height})
+	return &frame
+}
+
+func TestLayout_RowspanLastRowTakesRest(t *testing.T) {
+	span := &Cell{X: 0, Y: 0, Colspan: 1, Rowspan: 2, Breakable: true, Body: frameOfHeight(60)}
+	side := &Cell{X: 1, Y: 0, Colspan: 1, Rowspan: 1, Body: frameOfHeight(20)}
+	below := &Cell{X: 1, Y: 1, Colspan: 1, Rowspan: 1, Body: frameOfHeight(20)}
+	grid := &Grid{
+		Cols:     []Sizing{SizingRel{Abs: 50}, SizingRel{Abs: 50}},
+		Rows:     []Sizing{SizingAuto{}, SizingAuto{}},
+		Entries:  []Entry{EntryCell{Cell: span}, EntryCell{Cell: side}, EntryMerged{Parent: span}, EntryCell{Cell: below}},
+		ColCount: 2,
+		RowCount: 2,
+	}
+	regions := &layout.Regions{
+		Size: layout.Size{Width: 100, Height: 300},
+		Full: layout.Size{Width: 100, Height: 300},
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	if _, err := gl.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	if h := gl.RRows[0].Heights[1]; h != 40 {
+		t.Errorf("expected last spanned row height 40, got %v", h)
+	}
+	pieces := gl.RowspanPieces[Axes[int]{X: 0, Y: 0}]
+	if len(pieces) != 1 || pieces[0].Height != 60 {
+		t.Fatalf("expected one piece of height 60, got %+v", pieces)
+	}
+}
+
+func TestLayout_UnboundedRegion(t *testing.T) {
+	cell := &Cell{X: 0, Y: 0, Colspan: 1, Rowspan: 1, Body: frameOfHeight(20)}
+	grid := &Grid{
+		Cols:     []Sizing{SizingRel{Abs: 50}, SizingRel{Ratio: 0.5}, SizingFr{Fr: 1}},
+		Rows:     []Sizing{SizingAuto{}, SizingFr{Fr: 1}},
+		Entries:  []Entry{EntryCell{Cell: cell}, nil, nil, nil, nil, nil},
+		ColCount: 3,
+		RowCount: 2,
+	}
+	// An auto-sized container lays the grid out without bounds.
+	unbounded := layout.Size{Width: layout.Inf(), Height: layout.Inf()}
+	regions := &layout.Regions{Size: unbounded, Full: unbounded}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	frames, err := gl.Layout()
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	// Relative and fractional tracks have nothing to take a share of.
+	if gl.RCols[1] != 0 || gl.RCols[2] != 0 {
+		t.Errorf("expected relative and fractional columns of width 0, got %v", gl.RCols)
+	}
+	if len(frames) != 1 || frames[0].Size() != (layout.Size{Width: 50, Height: 20}) {
+		t.Errorf("expected a single 50x20 frame, got %+v", frames)
+	}
+}
+
+func TestLayout_LastRegionRepeats(t *testing.T) {
+	rows := []Sizing{SizingRel{Abs: 40}, SizingRel{Abs: 40}, SizingRel{Abs: 40}}
+	grid := rowspanGrid(rows, nil)
+	last := layout.Size{Width: 100, Height: 50}
+	regions := &layout.Regions{
+		Size: layout.Size{Width: 100, Height: 100},
+		Full: layout.Size{Width: 100, Height: 100},
+		Last: &last,
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	frames, err := gl.Layout()
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	// The third row moves on to the last region, whose full height then
+	// bounds the grid.
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+	if regions.Full.Height != 50 || regions.Last == nil {
+		t.Errorf("expected the last region to stay and become full, got full %v, last %v", regions.Full, regions.Last)
+	}
+}
+
+func TestLayout_BreakableRowspanSplitsAcrossRegions(t *testing.T) {
+	span := &Cell{X: 0, Y: 0, Colspan: 1, Rowspan: 3, Breakable: true}
+	rows := []Sizing{SizingRel{Abs: 40}, SizingRel{Abs: 40}, SizingRel{Abs: 40}}
+	grid := rowspanGrid(rows, map[int]*Cell{0: span})
+	regions := &layout.Regions{
+		Size:    layout.Size{Width: 100, Height: 100},
+		Full:    layout.Size{Width: 100, Height: 100},
+		Backlog: []layout.Abs{100},
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	frames, err := gl.Layout()
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
 	}
 
-	if mc.MeasureHeight(100) != 20 {
-		t.Errorf("expected height 20, got %v", mc.MeasureHeight(100))
+	pieces := gl.RowspanPieces[Axes[int]{X: 0, Y: 0}]
+	want := []CellLayout{
+		{Y: 0, Width: 100, Height: 80, Region: 0},
+		{Y: 0, Width: 100, Height: 40, Region: 1},
+	}
+	if len(pieces) != len(want) {
+		t.Fatalf("expected %d pieces, got %d", len(want), len(pieces))
+	}
+	for i, w := range want {
+		if pieces[i] != w {
+			t.Errorf("piece %d: expected %+v, got %+v", i, w, pieces[i])
+		}
+	}
+}
+
+func TestLayout_UnbreakableRowspanMovesToNextRegion(t *testing.T) {
+	span := &Cell{X: 0, Y: 1, Colspan: 1, Rowspan: 2}
+	rows := []Sizing{SizingRel{Abs: 40}, SizingRel{Abs: 40}, SizingRel{Abs: 40}}
+	grid := rowspanGrid(rows, map[int]*Cell{1: span})
+	regions := &layout.Regions{
+		Size:    layout.Size{Width: 100, Height: 100},
+		Full:    layout.Size{Width: 100, Height: 100},
+		Backlog: []layout.Abs{100},
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	if _, err := gl.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	if _, ok := gl.RRows[0].Heights[1]; ok {
+		t.Error("expected the rowspan's first row to move to the next region")
+	}
+	pieces := gl.RowspanPieces[Axes[int]{X: 0, Y: 1}]
+	if len(pieces) != 1 || pieces[0].Region != 1 || pieces[0].Y != 0 || pieces[0].Height != 80 {
+		t.Fatalf("expected one piece of height 80 at the top of region 1, got %+v", pieces)
+	}
+}
+
+func TestLayout_HeaderRepeatsAboveContinuingRowspan(t *testing.T) {
+	header := &Cell{X: 0, Y: 0, Colspan: 1, Rowspan: 1}
+	span := &Cell{X: 0, Y: 1, Colspan: 1, Rowspan: 3, Breakable: true}
+	rows := []Sizing{SizingRel{Abs: 20}, SizingRel{Abs: 40}, SizingRel{Abs: 40}, SizingRel{Abs: 40}}
+	grid := rowspanGrid(rows, map[int]*Cell{0: header, 1: span})
+	regions := &layout.Regions{
+		Size:    layout.Size{Width: 100, Height: 100},
+		Full:    layout.Size{Width: 100, Height: 100},
+		Backlog: []layout.Abs{100},
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	gl.RepeatingHeaders = []Header{{StartY: 0, EndY: 1}}
+	if _, err := gl.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	if h := gl.RRows[1].Heights[0]; h != 20 {
+		t.Errorf("expected repeated header row of height 20, got %v", h)
+	}
+	pieces := gl.RowspanPieces[Axes[int]{X: 0, Y: 1}]
+	if len(pieces) != 2 {
+		t.Fatalf("expected 2 pieces, got %d", len(pieces))
+	}
+	if pieces[1].Y != 20 || pieces[1].Height != 40 {
+		t.Errorf("expected second piece below the header with height 40, got %+v", pieces[1])
+	}
+}
+
+func TestInterleaveGutter(t *testing.T) {
+	tracks := []Sizing{SizingAuto{}, SizingAuto{}, SizingAuto{}, SizingAuto{}}
+	gutter := []Sizing{SizingRel{Abs: 5}, SizingFr{Fr: 1}}
+
+	got := InterleaveGutter(tracks, gutter)
+	want := []Sizing{
+		SizingAuto{}, SizingRel{Abs: 5},
+		SizingAuto{}, SizingFr{Fr: 1},
+		SizingAuto{}, SizingFr{Fr: 1},
+		SizingAuto{},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d tracks, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("track %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	if got := InterleaveGutter(tracks[:2], nil); got[1] != (SizingRel{}) {
+		t.Errorf("expected zero gutter without gutter tracks, got %v", got[1])
+	}
+}
+
+func TestLayout_GutterOmittedAroundRegionBreak(t *testing.T) {
+	content := []Sizing{SizingRel{Abs: 40}, SizingRel{Abs: 40}, SizingRel{Abs: 40}}
+	grid := &Grid{
+		Cols:      []Sizing{SizingRel{Abs: 100}},
+		Rows:      InterleaveGutter(content, []Sizing{SizingRel{Abs: 10}}),
+		Entries:   make([]Entry, 5),
+		ColCount:  1,
+		RowCount:  5,
+		HasGutter: true,
+	}
+	regions := &layout.Regions{
+		Size:    layout.Size{Width: 100, Height: 100},
+		Full:    layout.Size{Width: 100, Height: 100},
+		Backlog: []layout.Abs{100},
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	if _, err := gl.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	// Rows 0, 1, and 2 fill the first region; the gutter after them is
+	// stripped and not repeated at the top of the second region.
+	if _, ok := gl.RRows[0].Heights[3]; ok {
+		t.Error("expected trailing gutter to be stripped from the first region")
+	}
+	if _, ok := gl.RRows[1].Heights[3]; ok {
+		t.Error("expected gutter at the top of the second region to be omitted")
+	}
+	if h := gl.RRows[0].Heights[1]; h != 10 {
+		t.Errorf("expected gutter height 10, got %v", h)
+	}
+	if gl.Current.Height != 40 {
+		t.Errorf("expected the second region to hold only row 4, got height %v", gl.Current.Height)
+	}
+}
+
+func TestLayout_FractionalGutter(t *testing.T) {
+	content := []Sizing{SizingRel{Abs: 20}, SizingRel{Abs: 20}, SizingRel{Abs: 20}}
+	grid := &Grid{
+		Cols:      []Sizing{SizingRel{Abs: 100}},
+		Rows:      InterleaveGutter(content, []Sizing{SizingFr{Fr: 1}, SizingFr{Fr: 3}}),
+		Entries:   make([]Entry, 5),
+		ColCount:  1,
+		RowCount:  5,
+		HasGutter: true,
+	}
+	regions := &layout.Regions{
+		Size: layout.Size{Width: 100, Height: 100},
+		Full: layout.Size{Width: 100, Height: 100},
+	}
+
+	gl := NewGridLayouter(nil, grid, regions, nil, false)
+	if _, err := gl.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	if h := gl.RRows[0].Heights[1]; h != 10 {
+		t.Errorf("expected first gutter height 10, got %v", h)
+	}
+	if h := gl.RRows[0].Heights[3]; h != 30 {
+		t.Errorf("expected second gutter height 30, got %v", h)
+	}
+}
+
+func TestLineGenerator_SkipsGutter(t *testing.T) {
+	grid := &Grid{
+		ColCount:  3,
+		RowCount:  3,
+		HasGutter: true,
+		Stroke:    &Stroke{Thickness: 1},
+	}
+	rcols := []layout.Abs{50, 10, 50}
+	rowHeights := map[int]layout.Abs{0: 30, 1: 5, 2: 30}
+
+	lg := NewLineGenerator(grid, rcols, rowHeights, false)
+	hlines, vlines := lg.GenerateAllLines()
+
+	// Four horizontal lines, each split at the gutter column.
+	if len(hlines) != 8 {
+		t.Fatalf("expected 8 horizontal segments, got %d", len(hlines))
+	}
+	if hlines[0].Start != 0 || hlines[0].Length != 50 || hlines[1].Start != 60 || hlines[1].Length != 50 {
+		t.Errorf("expected segments around the gutter column, got %+v and %+v", hlines[0], hlines[1])
+	}
+
+	// Four vertical lines, each split at the gutter row.
+	if len(vlines) != 8 {
+		t.Fatalf("expected 8 vertical segments, got %d", len(vlines))
+	}
+	if vlines[1].Start != 35 || vlines[1].Length != 30 {
+		t.Errorf("expected segment below the gutter row, got %+v", vlines[1])
 	}
 }
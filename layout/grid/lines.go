@@ -27,18 +27,24 @@ func NewLineGenerator(grid *Grid, rcols []layout.Abs, rowHeights map[int]layout.
 }
 
 // GenerateHorizontalLines generates all horizontal line segments.
-// These are lines that run between rows.
+// These are lines that run between rows. Lines are not drawn across gutter
+// columns, so each line has a segment per run of content columns.
 func (lg *LineGenerator) GenerateHorizontalLines() []LineSegment {
 	var segments []LineSegment
 
-	// Calculate total width.
-	totalWidth := layout.Abs(0)
-	for _, w := range lg.RCols {
-		totalWidth += w
+	runs := contentRuns(lg.RCols, lg.Grid.IsGutterTrack)
+	if lg.IsRTL {
+		totalWidth := layout.Abs(0)
+		for _, w := range lg.RCols {
+			totalWidth += w
+		}
+		for i := range runs {
+			runs[i].start = totalWidth - runs[i].start - runs[i].length
+		}
 	}
 
 	// Generate line at top (y=0).
-	segments = append(segments, lg.generateHLine(0, totalWidth)...)
+	segments = append(segments, lg.generateHLine(0, runs)...)
 
 	// Generate lines between rows and at bottom.
 	y := layout.Abs(0)
@@ -46,62 +52,92 @@ func (lg *LineGenerator) GenerateHorizontalLines() []LineSegment {
 		height := lg.RowHeights[row]
 		y += height
 
-		segments = append(segments, lg.generateHLine(y, totalWidth)...)
+		segments = append(segments, lg.generateHLine(y, runs)...)
 	}
 
 	return segments
 }
 
 // GenerateVerticalLines generates all vertical line segments.
-// These are lines that run between columns.
+// These are lines that run between columns. Lines are not drawn across
+// gutter rows, so each line has a segment per run of content rows.
 func (lg *LineGenerator) GenerateVerticalLines() []LineSegment {
 	var segments []LineSegment
 
-	// Calculate total height.
-	totalHeight := layout.Abs(0)
-	for row := 0; row < lg.Grid.RowCount; row++ {
-		totalHeight += lg.RowHeights[row]
+	heights := make([]layout.Abs, lg.Grid.RowCount)
+	for row := range heights {
+		heights[row] = lg.RowHeights[row]
 	}
+	runs := contentRuns(heights, lg.Grid.IsGutterTrack)
 
 	// Generate line at left (x=0).
-	segments = append(segments, lg.generateVLine(0, totalHeight)...)
+	segments = append(segments, lg.generateVLine(0, runs)...)
 
 	// Generate lines between columns and at right.
 	x := layout.Abs(0)
 	for col := 0; col < lg.Grid.ColCount; col++ {
 		x += lg.RCols[col]
-		segments = append(segments, lg.generateVLine(x, totalHeight)...)
+		segments = append(segments, lg.generateVLine(x, runs)...)
 	}
 
 	return segments
 }
 
+// lineRun is a stretch of consecutive content tracks along a line.
+type lineRun struct {
+	start, length layout.Abs
+}
+
+// contentRuns returns the runs of consecutive tracks with the given sizes
+// that aren't gutter.
+func contentRuns(sizes []layout.Abs, isGutter func(int) bool) []lineRun {
+	var runs []lineRun
+	var pos layout.Abs
+	open := false
+	for i, size := range sizes {
+		switch {
+		case isGutter(i):
+			open = false
+		case open:
+			runs[len(runs)-1].length += size
+		default:
+			runs = append(runs, lineRun{start: pos, length: size})
+			open = true
+		}
+		pos += size
+	}
+	return runs
+}
+
 // generateHLine generates horizontal line segments at the given y position.
-func (lg *LineGenerator) generateHLine(y, maxLength layout.Abs) []LineSegment {
+func (lg *LineGenerator) generateHLine(y layout.Abs, runs []lineRun) []LineSegment {
 	// Determine the stroke for this line.
 	stroke := lg.Grid.Stroke
 	if stroke == nil {
 		return nil
 	}
 
-	// For now, generate a single segment across the full width.
+	// For now, generate a segment for each run of content columns.
 	// TODO: Implement proper segment generation with:
 	// - Interruptions for merged cells (colspan/rowspan blocking)
 	// - Stroke changes from cell overrides
 	// - Priority handling
 
-	return []LineSegment{
-		{
+	segments := make([]LineSegment, 0, len(runs))
+	for _, run := range runs {
+		segments = append(segments, LineSegment{
 			Stroke:   stroke,
 			Offset:   y,
-			Length:   maxLength,
+			Start:    run.start,
+			Length:   run.length,
 			Priority: GridStrokePriority,
-		},
+		})
 	}
+	return segments
 }
 
 // generateVLine generates vertical line segments at the given x position.
-func (lg *LineGenerator) generateVLine(x, maxLength layout.Abs) []LineSegment {
+func (lg *LineGenerator) generateVLine(x layout.Abs, runs []lineRun) []LineSegment {
 	stroke := lg.Grid.Stroke
 	if stroke == nil {
 		return nil
@@ -116,14 +152,17 @@ func (lg *LineGenerator) generateVLine(x, maxLength layout.Abs) []LineSegment {
 		x = totalWidth - x
 	}
 
-	return []LineSegment{
-		{
+	segments := make([]LineSegment, 0, len(runs))
+	for _, run := range runs {
+		segments = append(segments, LineSegment{
 			Stroke:   stroke,
 			Offset:   x,
-			Length:   maxLength,
+			Start:    run.start,
+			Length:   run.length,
 			Priority: GridStrokePriority,
-		},
+		})
 	}
+	return segments
 }
 
 // GenerateAllLines generates all grid lines (horizontal and vertical).
@@ -163,7 +202,8 @@ func MergeSegments(segments []LineSegment) []LineSegment {
 		// Check if this segment can be merged with current.
 		if strokesEqual(current.Stroke, seg.Stroke) &&
 			current.Priority == seg.Priority &&
-			current.Offset+current.Length == seg.Offset {
+			current.Offset == seg.Offset &&
+			current.Start+current.Length == seg.Start {
 			// Extend current segment.
 			current.Length += seg.Length
 		} else {
@@ -27,7 +27,12 @@ func (SizingRel) isSizing() {}
 
 // RelativeTo resolves the relative sizing to an absolute value.
 func (s SizingRel) RelativeTo(base layout.Abs) layout.Abs {
-	return s.Abs + layout.Abs(s.Ratio*float64(base))
+	share := layout.Abs(s.Ratio * float64(base))
+	if !share.IsFinite() {
+		// An unbounded base has no share to take.
+		share = 0
+	}
+	return s.Abs + share
 }
 
 // SizingFr indicates a fractional size.
@@ -84,9 +89,11 @@ func (EntryMerged) isEntry() {}
 
 // Grid holds the fully resolved grid structure.
 type Grid struct {
-	// Cols contains the column sizing specifications.
+	// Cols contains the column sizing specifications. With gutter, the
+	// gutter columns are interleaved at the odd indices.
 	Cols []Sizing
-	// Rows contains the row sizing specifications.
+	// Rows contains the row sizing specifications. With gutter, the
+	// gutter rows are interleaved at the odd indices.
 	Rows []Sizing
 	// Entries is a 2D grid of entries, indexed as [y*cols + x].
 	Entries []Entry
@@ -96,10 +103,6 @@ type Grid struct {
 	RowCount int
 	// HasGutter indicates if gutter rows/cols are present.
 	HasGutter bool
-	// ColGutter is the gutter between columns.
-	ColGutter layout.Abs
-	// RowGutter is the gutter between rows.
-	RowGutter layout.Abs
 	// Fill is the default fill for cells.
 	Fill interface{}
 	// Stroke is the default stroke for grid lines.
@@ -123,20 +126,54 @@ func (g *Grid) CellAt(x, y int) *Cell {
 	return nil
 }
 
-// EffectiveRowCount returns the number of rows excluding trailing gutter.
+// IsGutterTrack reports whether the row or column at index i is gutter.
+func (g *Grid) IsGutterTrack(i int) bool {
+	return g.HasGutter && i%2 == 1
+}
+
+// EffectiveRowCount returns the number of rows excluding gutter rows.
 func (g *Grid) EffectiveRowCount() int {
-	if g.HasGutter && g.RowCount > 0 {
-		return g.RowCount - 1
+	if g.HasGutter {
+		return (g.RowCount + 1) / 2
 	}
 	return g.RowCount
 }
 
+// InterleaveGutter returns the tracks with a gutter track between each two
+// of them. The i-th gutter track is gutter[i], or the last gutter track
+// if there are fewer gutter tracks than gaps, or zero if there are none.
+// There is no gutter after the last track.
+// Matches Rust: CellGrid::new_internal
+func InterleaveGutter(tracks, gutter []Sizing) []Sizing {
+	if len(tracks) == 0 {
+		return nil
+	}
+	result := make([]Sizing, 0, 2*len(tracks)-1)
+	for i, track := range tracks {
+		if i > 0 {
+			var g Sizing = SizingRel{}
+			switch {
+			case i-1 < len(gutter):
+				g = gutter[i-1]
+			case len(gutter) > 0:
+				g = gutter[len(gutter)-1]
+			}
+			result = append(result, g)
+		}
+		result = append(result, track)
+	}
+	return result
+}
+
 // RowState tracks the state of rows in a region.
 type RowState struct {
 	// Heights maps row index to resolved height.
 	Heights map[int]layout.Abs
 	// IsGutter maps row index to whether it's a gutter row.
 	IsGutter map[int]bool
+	// Start is the height of the repeated headers at the top of the
+	// region, below which rows continued from the previous region begin.
+	Start layout.Abs
 }
 
 // NewRowState creates an empty RowState.
@@ -157,7 +194,7 @@ func (r RowState) Clone() RowState {
 	for k, v := range r.IsGutter {
 		isGutter[k] = v
 	}
-	return RowState{Heights: heights, IsGutter: isGutter}
+	return RowState{Heights: heights, IsGutter: isGutter, Start: r.Start}
 }
 
 // Current tracks the current region's state during layout.
@@ -192,7 +229,8 @@ type Rowspan struct {
 	FirstRegion int
 	// RegionFull is the full height available in the first region.
 	RegionFull layout.Abs
-	// Heights holds per-region available heights.
+	// Heights holds the height the rowspan occupies in each region it
+	// spans, starting with the first region.
 	Heights []layout.Abs
 	// MaxResolvedRow is the maximum row resolved so far (nil = none).
 	MaxResolvedRow *int
@@ -273,6 +311,8 @@ type LineSegment struct {
 	Stroke *Stroke
 	// Offset is the position along the perpendicular axis.
 	Offset layout.Abs
+	// Start is the position along the line's own axis where it begins.
+	Start layout.Abs
 	// Length is the length of the segment.
 	Length layout.Abs
 	// Priority determines which stroke wins on overlaps.
@@ -291,18 +331,15 @@ const (
 	ExplicitLinePriority
 )
 
-// Axes holds a pair of values for horizontal (X) and vertical (Y) axes.
-type Axes[T any] struct {
-	X, Y T
-}
+// Axes is an alias for layout.Axes for convenience.
+type Axes[T any] = layout.Axes[T]
 
-// Measurable represents content that can measure its intrinsic dimensions.
-// Content implementing this interface can provide its natural width and height.
+// Measurable represents content that can measure its size in a region.
 type Measurable interface {
-	// MeasureWidth returns the natural (unconstrained) width of the content.
-	MeasureWidth() layout.Abs
-	// MeasureHeight returns the height of the content given a constrained width.
-	MeasureHeight(width layout.Abs) layout.Abs
+	// Measure returns the size of the content laid out into the region.
+	// The content wraps to the region's width; if that is unbounded, the
+	// content takes its natural (unconstrained) width.
+	Measure(region layout.Region) layout.Size
 }
 
 // MeasuredCell wraps a pre-measured cell body with cached dimensions.
@@ -315,14 +352,10 @@ type MeasuredCell struct {
 	Height layout.Abs
 }
 
-func (m *MeasuredCell) MeasureWidth() layout.Abs {
-	return m.Width
-}
-
-func (m *MeasuredCell) MeasureHeight(width layout.Abs) layout.Abs {
+func (m *MeasuredCell) Measure(region layout.Region) layout.Size {
 	// For pre-measured cells, return cached height.
 	// In practice, this might need recalculation if width differs significantly.
-	return m.Height
+	return region.Fit(layout.Size{Width: m.Width, Height: m.Height})
 }
 
 // TextContent represents simple text content that can be measured.
@@ -337,24 +370,24 @@ type TextContent struct {
 	ApproxCharWidth layout.Abs
 }
 
-func (t *TextContent) MeasureWidth() layout.Abs {
-	if t.ApproxCharWidth == 0 {
-		// Default to ~0.5em per character for monospace-like estimation.
-		t.ApproxCharWidth = t.FontSize * 0.5
+func (t *TextContent) Measure(region layout.Region) layout.Size {
+	// Default to ~0.5em per character for monospace-like estimation.
+	charWidth := t.ApproxCharWidth
+	if charWidth == 0 {
+		charWidth = t.FontSize * 0.5
 	}
-	return layout.Abs(len(t.Text)) * t.ApproxCharWidth
-}
+	natural := layout.Abs(len(t.Text)) * charWidth
+	lineHeight := t.FontSize * 1.2 // 1.2 line height factor
 
-func (t *TextContent) MeasureHeight(width layout.Abs) layout.Abs {
-	if width <= 0 || t.FontSize <= 0 {
-		return t.FontSize * 1.2 // Default line height
+	width := region.Size.Width
+	if width <= 0 || charWidth <= 0 {
+		return region.Fit(layout.Size{Width: natural, Height: lineHeight})
 	}
 	// Estimate number of lines based on text length and available width.
-	charWidth := t.ApproxCharWidth
-	if charWidth == 0 {
-		charWidth = t.FontSize * 0.5
+	charsPerLine := len(t.Text)
+	if width.IsFinite() {
+		charsPerLine = int(width / charWidth)
 	}
-	charsPerLine := int(width / charWidth)
 	if charsPerLine <= 0 {
 		charsPerLine = 1
 	}
@@ -362,7 +395,7 @@ func (t *TextContent) MeasureHeight(width layout.Abs) layout.Abs {
 	if numLines < 1 {
 		numLines = 1
 	}
-	return layout.Abs(numLines) * t.FontSize * 1.2 // 1.2 line height factor
+	return region.Fit(layout.Size{Width: natural, Height: layout.Abs(numLines) * lineHeight})
 }
 
 // FrameContent wraps a flow.Frame for measurement.
@@ -373,11 +406,7 @@ type FrameContent struct {
 	Height layout.Abs
 }
 
-func (f *FrameContent) MeasureWidth() layout.Abs {
-	return f.Width
-}
-
-func (f *FrameContent) MeasureHeight(width layout.Abs) layout.Abs {
-	// For frames, the height is fixed regardless of width constraint.
-	return f.Height
+func (f *FrameContent) Measure(region layout.Region) layout.Size {
+	// For frames, the size is fixed regardless of the region.
+	return region.Fit(layout.Size{Width: f.Width, Height: f.Height})
 }
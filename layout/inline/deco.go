@@ -59,6 +59,7 @@ const (
 	TopEdgeCapHeight
 	TopEdgeXHeight
 	TopEdgeBounds
+	TopEdgeBaseline
 )
 
 // BottomEdge represents the bottom edge metric for text bounds.
@@ -480,6 +481,8 @@ func determineEdges(text *DecoTextItem, topEdge TopEdge, bottomEdge BottomEdge)
 	case TopEdgeBounds:
 		// Would calculate actual bounds from glyphs
 		top = Em(0.8).At(text.Size)
+	case TopEdgeBaseline:
+		top = 0
 	}
 
 	switch bottomEdge {
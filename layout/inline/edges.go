@@ -0,0 +1,120 @@
+package inline
+
+import (
+	"github.com/go-text/typesetting/font"
+)
+
+// TextTopEdge is the top edge of text, which determines where lines start
+// and how far apart they are. It is a font metric or, if Length is set, a
+// fixed distance above the baseline.
+// Matches Rust: TopEdge in typst-library/src/text/mod.rs
+type TextTopEdge struct {
+	Metric TopEdge
+	Length *Abs
+}
+
+// Resolve returns the distance of the edge above the baseline at a glyph.
+// Matches Rust: TopEdge::resolve
+func (e TextTopEdge) Resolve(g *ShapedGlyph) Abs {
+	if e.Length != nil {
+		return *e.Length
+	}
+	switch e.Metric {
+	case TopEdgeCapHeight:
+		return faceLineMetric(g, font.CapHeight, 0.7).At(g.Size)
+	case TopEdgeXHeight:
+		return faceLineMetric(g, font.XHeight, 0.5).At(g.Size)
+	case TopEdgeBaseline:
+		return 0
+	case TopEdgeBounds:
+		if top, _, ok := glyphBounds(g); ok {
+			return top.At(g.Size)
+		}
+	}
+	ascender, _ := faceExtents(g)
+	return ascender.At(g.Size)
+}
+
+// TextBottomEdge is the bottom edge of text. It is a font metric or, if
+// Length is set, a fixed distance below the baseline.
+// Matches Rust: BottomEdge in typst-library/src/text/mod.rs
+type TextBottomEdge struct {
+	Metric BottomEdge
+	Length *Abs
+}
+
+// Resolve returns the distance of the edge below the baseline at a glyph.
+// Matches Rust: BottomEdge::resolve
+func (e TextBottomEdge) Resolve(g *ShapedGlyph) Abs {
+	if e.Length != nil {
+		return *e.Length
+	}
+	switch e.Metric {
+	case BottomEdgeBaseline:
+		return 0
+	case BottomEdgeBounds:
+		if _, bottom, ok := glyphBounds(g); ok {
+			return bottom.At(g.Size)
+		}
+	}
+	_, descender := faceExtents(g)
+	return descender.At(g.Size)
+}
+
+// faceExtents returns the ascender and the descender of a glyph's font,
+// both as positive distances from the baseline. Typical values are used
+// for glyphs without a font.
+func faceExtents(g *ShapedGlyph) (ascender, descender Em) {
+	ascender, descender = 0.8, 0.2
+	if g.Font == nil || g.Font.Upem() == 0 {
+		return ascender, descender
+	}
+	if extents, ok := g.Font.FontHExtents(); ok {
+		upem := float64(g.Font.Upem())
+		ascender = Em(float64(extents.Ascender) / upem)
+		descender = Em(-float64(extents.Descender) / upem)
+	}
+	return ascender, descender
+}
+
+// faceLineMetric returns a line metric of a glyph's font, or the fallback
+// if the font does not define it.
+func faceLineMetric(g *ShapedGlyph, metric font.LineMetric, fallback Em) Em {
+	if g.Font == nil || g.Font.Upem() == 0 {
+		return fallback
+	}
+	value := g.Font.LineMetric(metric)
+	if value == 0 {
+		return fallback
+	}
+	return Em(float64(value) / float64(g.Font.Upem()))
+}
+
+// glyphBounds returns how far a glyph's outline extends above and below
+// the baseline.
+func glyphBounds(g *ShapedGlyph) (top, bottom Em, ok bool) {
+	if g.Font == nil || g.Font.Upem() == 0 {
+		return 0, 0, false
+	}
+	extents, ok := g.Font.GlyphExtents(font.GID(g.GlyphID))
+	if !ok {
+		return 0, 0, false
+	}
+	upem := float64(g.Font.Upem())
+	top = Em(float64(extents.YBearing) / upem)
+	bottom = Em(-float64(extents.YBearing+extents.Height) / upem)
+	return top, bottom, true
+}
+
+// Measure returns how far the shaped text extends above and below the
+// baseline with the given edges.
+// Matches Rust: ShapedText::measure
+func (s *ShapedText) Measure(top TextTopEdge, bottom TextBottomEdge) (Abs, Abs) {
+	var ascent, descent Abs
+	for i := 0; i < s.Glyphs.Len(); i++ {
+		g := s.Glyphs.At(i)
+		ascent = max(ascent, top.Resolve(g))
+		descent = max(descent, bottom.Resolve(g))
+	}
+	return ascent, descent
+}
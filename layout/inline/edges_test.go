@@ -0,0 +1,69 @@
+package inline
+
+import "testing"
+
+func TestTextEdges(t *testing.T) {
+	glyph := &ShapedGlyph{Size: 10, Char: 'a'}
+	length := Abs(3)
+
+	tops := []struct {
+		edge TextTopEdge
+		want Abs
+	}{
+		{TextTopEdge{Metric: TopEdgeAscender}, 8},
+		{TextTopEdge{Metric: TopEdgeCapHeight}, 7},
+		{TextTopEdge{Metric: TopEdgeXHeight}, 5},
+		{TextTopEdge{Metric: TopEdgeBaseline}, 0},
+		{TextTopEdge{Metric: TopEdgeBounds}, 8},
+		{TextTopEdge{Length: &length}, 3},
+	}
+	for _, tt := range tops {
+		if got := tt.edge.Resolve(glyph); got != tt.want {
+			t.Errorf("top edge %v: got %v, want %v", tt.edge.Metric, got, tt.want)
+		}
+	}
+
+	bottoms := []struct {
+		edge TextBottomEdge
+		want Abs
+	}{
+		{TextBottomEdge{Metric: BottomEdgeDescender}, 2},
+		{TextBottomEdge{Metric: BottomEdgeBaseline}, 0},
+		{TextBottomEdge{Length: &length}, 3},
+	}
+	for _, tt := range bottoms {
+		if got := tt.edge.Resolve(glyph); got != tt.want {
+			t.Errorf("bottom edge %v: got %v, want %v", tt.edge.Metric, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTextFrameEdges(t *testing.T) {
+	shaped := shapedItem("ab").shaped
+	config := &Config{
+		TopEdge:    TextTopEdge{Metric: TopEdgeCapHeight},
+		BottomEdge: TextBottomEdge{Metric: BottomEdgeBaseline},
+	}
+	frame := buildTextFrame(shaped, config, 0, 0)
+	if frame.Size.Height != 7 || frame.Baseline != 7 {
+		t.Errorf("cap-height to baseline: height %v, baseline %v; want 7, 7", frame.Size.Height, frame.Baseline)
+	}
+
+	config = &Config{}
+	frame = buildTextFrame(shaped, config, 0, 0)
+	if frame.Size.Height != 10 || frame.Baseline != 8 {
+		t.Errorf("ascender to descender: height %v, baseline %v; want 10, 8", frame.Size.Height, frame.Baseline)
+	}
+}
+
+func TestBuildTextFrameShift(t *testing.T) {
+	shaped := shapedItem("ab").shaped
+	shaped.Shift = 2
+	frame := buildTextFrame(shaped, &Config{}, 0, 0)
+	if len(frame.Items) != 1 || frame.Items[0].Pos.Y != 2 {
+		t.Fatalf("text item at %v, want y = 2", frame.Items)
+	}
+	if frame.Baseline != 8 {
+		t.Errorf("baseline = %v, want 8", frame.Baseline)
+	}
+}
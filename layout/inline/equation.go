@@ -0,0 +1,45 @@
+package inline
+
+// EquationPart is a laid out part of an inline equation. Lines may break
+// between parts, at the spacing that follows a part.
+type EquationPart struct {
+	// Frame is the laid out math. It is not inspected here, which keeps
+	// the math layout out of this package's imports.
+	Frame interface{}
+	// Size is the size of the laid out math.
+	Size FinalSize
+	// Baseline is the distance from the top of the math to its baseline,
+	// which is aligned on the baseline of the text.
+	Baseline Abs
+	// Space is the spacing after the part. It is dropped if a line breaks
+	// there.
+	Space Abs
+}
+
+// FinalEquationItem is a part of an inline equation in a frame, with its
+// top-left corner at the item's position.
+type FinalEquationItem struct {
+	// Frame is the laid out math of the part.
+	Frame interface{}
+	// Size is the size of the laid out math.
+	Size FinalSize
+}
+
+func (FinalEquationItem) isFinalFrameItem() {}
+
+// EquationItems turns the parts of an inline equation into paragraph
+// items: a frame for each part, aligned on the text baseline, followed by
+// weak spacing at which a line may break.
+// Matches Rust: the handling of MathParItem in typst-layout/src/inline/collect.rs
+func EquationItems(parts []EquationPart) []Item {
+	var items []Item
+	for i, part := range parts {
+		frame := &FinalFrame{Size: part.Size, Baseline: part.Baseline}
+		frame.Push(FinalPoint{}, FinalEquationItem{Frame: part.Frame, Size: part.Size})
+		items = append(items, NewInlineFrameItem(frame))
+		if i < len(parts)-1 {
+			items = append(items, &AbsoluteItem{Amount: part.Space, Weak: true})
+		}
+	}
+	return items
+}
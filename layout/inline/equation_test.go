@@ -0,0 +1,42 @@
+package inline
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+)
+
+func TestEquationItems(t *testing.T) {
+	parts := []EquationPart{
+		{Size: FinalSize{Width: 20, Height: 12}, Baseline: 8, Space: 3},
+		{Size: FinalSize{Width: 10, Height: 20}, Baseline: 14},
+	}
+	items := EquationItems(parts)
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want frame, spacing, frame", len(items))
+	}
+	if space, ok := items[1].(*AbsoluteItem); !ok || !space.Weak || space.Amount != 3 {
+		t.Errorf("items[1] = %+v, want weak spacing of 3pt", items[1])
+	}
+
+	// The parts are aligned on the line's baseline, and the taller part
+	// determines its height above and below.
+	text := shapedItem("ab")
+	line := &Line{Items: []Item{text, items[0], items[1], items[2]}, Width: 53}
+	p := &Preparation{Config: &Config{Align: layout.AlignStart}}
+	frame, err := Commit(p, line, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame.Baseline != 14 {
+		t.Errorf("line baseline = %v, want 14", frame.Baseline)
+	}
+	if got := frame.Height() - frame.Baseline; got != 6 {
+		t.Errorf("line depth = %v, want 6", got)
+	}
+	for i, want := range []FinalPoint{{X: 20, Y: 6}, {X: 43, Y: 0}} {
+		if got := frame.Items[i+1].Pos; got != want {
+			t.Errorf("part %d at %v, want %v", i, got, want)
+		}
+	}
+}
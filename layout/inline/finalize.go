@@ -177,7 +177,7 @@ func Commit(
 	}
 
 	// Handle hanging punctuation to the left
-	if leadingText := line.LeadingText(); leadingText != nil {
+	if leadingText := line.LeadingText(); leadingText != nil && p.Config.Overhang {
 		if glyphs := leadingText.Glyphs.Kept(); len(glyphs) > 0 {
 			glyph := &glyphs[0]
 			if !leadingText.Dir.IsPositive() && (len(line.Items) > 1 || len(glyphs) > 1) {
@@ -189,7 +189,7 @@ func Commit(
 	}
 
 	// Handle hanging punctuation to the right
-	if trailingText := line.TrailingText(); trailingText != nil {
+	if trailingText := line.TrailingText(); trailingText != nil && p.Config.Overhang {
 		if glyphs := trailingText.Glyphs.Kept(); len(glyphs) > 0 {
 			glyph := &glyphs[len(glyphs)-1]
 			if trailingText.Dir.IsPositive() && (len(line.Items) > 1 || len(glyphs) > 1) {
@@ -264,7 +264,7 @@ func Commit(
 			if it.shaped == nil {
 				continue
 			}
-			frame := buildTextFrame(it.shaped, justificationRatio, extraJustification)
+			frame := buildTextFrame(it.shaped, p.Config, justificationRatio, extraJustification)
 			if frame.Baseline > top {
 				top = frame.Baseline
 			}
@@ -275,7 +275,16 @@ func Commit(
 			offset += frame.Size.Width
 
 		case *InlineFrameItem:
-			frame := &FinalFrame{Size: FinalSize{Width: it.width, Height: 0}}
+			frame := it.Frame
+			if frame == nil {
+				frame = &FinalFrame{Size: FinalSize{Width: it.width, Height: 0}}
+			}
+			if frame.Baseline > top {
+				top = frame.Baseline
+			}
+			if frame.Size.Height-frame.Baseline > bottom {
+				bottom = frame.Size.Height - frame.Baseline
+			}
 			posFrames = append(posFrames, positionedFrame{offset, frame, idx})
 			offset += it.width
 
@@ -360,11 +369,11 @@ func alignPosition(align layout.Alignment, remaining Abs) Abs {
 // buildTextFrame builds a frame from shaped text with justification.
 func buildTextFrame(
 	shaped *ShapedText,
+	config *Config,
 	justificationRatio float64,
 	extraJustification Abs,
 ) *FinalFrame {
-	var width, height Abs
-	var baseline Abs
+	var width Abs
 
 	// Calculate dimensions from glyphs
 	for _, g := range shaped.Glyphs.Kept() {
@@ -387,24 +396,17 @@ func buildTextFrame(
 		}
 
 		width += advance
-
-		// Track height (simplified - would use actual glyph metrics)
-		glyphHeight := g.Size * 1.2 // Approximation
-		if glyphHeight > height {
-			height = glyphHeight
-		}
 	}
 
-	// Baseline is typically at 80% from top (simplified)
-	baseline = height * 0.8
-
+	// The frame extends from the top to the bottom edge of the text.
+	top, bottom := shaped.Measure(config.TopEdge, config.BottomEdge)
 	frame := &FinalFrame{
-		Size:     FinalSize{Width: width, Height: height},
-		Baseline: baseline,
+		Size:     FinalSize{Width: width, Height: top + bottom},
+		Baseline: top,
 	}
 
-	// Add text item to frame
-	frame.Push(FinalPoint{X: 0, Y: 0}, FinalTextItem{Text: shaped})
+	// Add text item to frame, moved by the baseline shift
+	frame.Push(FinalPoint{X: 0, Y: shaped.Shift}, FinalTextItem{Text: shaped})
 
 	return frame
 }
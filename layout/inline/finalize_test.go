@@ -0,0 +1,30 @@
+package inline
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+)
+
+func TestCommitOverhang(t *testing.T) {
+	item := shapedItem("ab.")
+	line := &Line{Items: []Item{item}, Width: 30}
+
+	for _, tt := range []struct {
+		overhang bool
+		want     Abs
+	}{
+		// The period hangs 80% of its width into the right margin.
+		{true, 18},
+		{false, 10},
+	} {
+		p := &Preparation{Config: &Config{Align: layout.AlignEnd, Overhang: tt.overhang}}
+		frame, err := Commit(p, line, 40, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := frame.Items[0].Pos.X; got != tt.want {
+			t.Errorf("overhang %v: text at x = %v, want %v", tt.overhang, got, tt.want)
+		}
+	}
+}
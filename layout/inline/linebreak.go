@@ -2,11 +2,12 @@ package inline
 
 import (
 	"math"
+	"sort"
 	"strings"
 	"unicode"
 
 	"github.com/boergens/gotypst/layout"
-	"golang.org/x/text/unicode/bidi"
+	"github.com/rivo/uniseg"
 )
 
 // Cost represents the cost of a line or inline layout.
@@ -30,6 +31,20 @@ const (
 // Zero width space character.
 const ZWS = '\u200B'
 
+// LineSeparator ends a line that is justified despite the forced break.
+const LineSeparator = '\u2028'
+
+// LinebreakText returns the text that a forced line break contributes to
+// a paragraph: a line separator if the broken line should be justified and
+// a newline otherwise.
+// Matches Rust: LinebreakElem handling in collect.rs
+func LinebreakText(justify bool) string {
+	if justify {
+		return string(LineSeparator)
+	}
+	return "\n"
+}
+
 // Breakpoint represents a line break opportunity.
 type Breakpoint int
 
@@ -666,86 +681,72 @@ func (c *CumulativeVec[T]) get(index int) T {
 	return c.total
 }
 
+// breakOpportunity is a position in a paragraph's text at which a line
+// may or must end.
+type breakOpportunity struct {
+	end int
+	bp  BreakpointInfo
+}
+
 // breakpointsFn calls f for all possible line break points.
 func breakpointsFn(p *Preparation, f func(end int, bp BreakpointInfo)) {
+	for _, b := range p.breakpoints() {
+		f(b.end, b.bp)
+	}
+}
+
+// breakpoints returns the line break opportunities of the text. They are
+// computed once per paragraph and shared by all line breaking passes,
+// which may break the paragraph at several widths.
+func (p *Preparation) breakpoints() []breakOpportunity {
+	if p.breaks == nil {
+		p.breaks = computeBreakpoints(p)
+	}
+	return p.breaks
+}
+
+// computeBreakpoints finds the line break opportunities of the text with
+// the line breaking algorithm of UAX #14, and the hyphenation
+// opportunities within its words. The line breaking properties of UAX #14
+// already include the line-start and line-end prohibition rules (kinsoku)
+// of CJK typesetting.
+// Matches Rust: typst_layout::inline::linebreak::breakpoints
+func computeBreakpoints(p *Preparation) []breakOpportunity {
 	text := p.Text
 
 	// Single breakpoint at end for empty text.
 	if len(text) == 0 {
-		f(0, Mandatory())
-		return
+		return []breakOpportunity{{end: 0, bp: Mandatory()}}
 	}
 
 	hyphenate := p.Config.Hyphenate == nil || *p.Config.Hyphenate
-
-	runes := []rune(text)
-	runeOffsets := computeRuneOffsets(text)
+	breaks := make([]breakOpportunity, 0, len(text)/4+1)
+	add := func(end int, bp BreakpointInfo) {
+		breaks = append(breaks, breakOpportunity{end: end, bp: bp})
+	}
 
 	last := 0
-
-	for i, r := range runes {
-		offset := runeOffsets[i]
-		nextOffset := len(text)
-		if i+1 < len(runeOffsets) {
-			nextOffset = runeOffsets[i+1]
+	state := -1
+	for rest := text; len(rest) > 0; {
+		var segment string
+		var mandatory bool
+		segment, rest, mandatory, state = uniseg.FirstLineSegmentInString(rest, state)
+		end := last + len(segment)
+
+		// Hyphenate the word before the break.
+		if hyphenate {
+			word := strings.TrimRightFunc(segment, unicode.IsSpace)
+			hyphenateSegment(p, last, word, add)
 		}
 
-		// Check for line break opportunities.
-		bp := classifyBreakpoint(r, i == len(runes)-1)
-		if bp == nil {
-			continue
-		}
-
-		// Hyphenate between last and current breakpoint.
-		if hyphenate && last < offset {
-			segment := text[last:offset]
-			hyphenateSegment(p, last, segment, f)
+		if mandatory {
+			add(end, Mandatory())
+		} else {
+			add(end, Normal())
 		}
-
-		f(nextOffset, *bp)
-		last = nextOffset
-	}
-}
-
-// computeRuneOffsets returns byte offsets for each rune.
-func computeRuneOffsets(s string) []int {
-	offsets := make([]int, 0, len(s))
-	offset := 0
-	for _, r := range s {
-		offsets = append(offsets, offset)
-		offset += len(string(r))
-	}
-	return offsets
-}
-
-// classifyBreakpoint determines if a character is a break opportunity.
-func classifyBreakpoint(r rune, isLast bool) *BreakpointInfo {
-	if isLast {
-		bp := Mandatory()
-		return &bp
-	}
-
-	// Mandatory breaks.
-	if r == '\n' || r == '\r' || r == '\u0085' || r == '\u2028' || r == '\u2029' {
-		bp := Mandatory()
-		return &bp
-	}
-
-	// Normal breaks after spaces and certain punctuation.
-	if unicode.IsSpace(r) {
-		bp := Normal()
-		return &bp
-	}
-
-	// Use bidi class for additional break opportunities.
-	props, _ := bidi.LookupRune(r)
-	switch props.Class() {
-	case bidi.WS, bidi.S, bidi.B:
-		bp := Normal()
-		return &bp
+		last = end
 	}
-
-	return nil
+	return breaks
 }
 
 // hyphenateSegment generates hyphenation breakpoints within a segment.
@@ -811,9 +812,12 @@ func makeLine(p *Preparation, start, end int, bp BreakpointInfo, pred *Line) Lin
 
 	full := p.Text[start:end]
 
-	// Determine if line should be justified.
-	justify := strings.HasSuffix(full, "\u2028") ||
-		(p.Config.Justify && !bp.IsMandatory())
+	// Determine if line should be justified. A forced break ends a ragged
+	// line unless it is a line separator, and the last line of the
+	// paragraph is only justified with justify-last-line.
+	last := end >= len(p.Text)
+	justify := strings.HasSuffix(full, string(LineSeparator)) ||
+		(p.Config.Justify && (!bp.IsMandatory() || (last && p.Config.JustifyLastLine)))
 
 	// Process dashes.
 	var dash Dash
@@ -849,11 +853,12 @@ func makeLine(p *Preparation, start, end int, bp BreakpointInfo, pred *Line) Lin
 func collectLineItems(p *Preparation, start, end int, trim Trim) []Item {
 	var items []Item
 
-	for _, pi := range p.Items {
-		// Skip items completely before the range.
-		if pi.Range.End <= start {
-			continue
-		}
+	// The items are in text order, so the line's first item is found
+	// without scanning the items of all lines before it.
+	first := sort.Search(len(p.Items), func(i int) bool {
+		return p.Items[i].Range.End > start
+	})
+	for _, pi := range p.Items[first:] {
 		// Stop at items completely after the range.
 		if pi.Range.Start >= end {
 			break
@@ -869,5 +874,36 @@ func collectLineItems(p *Preparation, start, end int, trim Trim) []Item {
 		}
 	}
 
-	return items
+	return collapseWeakSpacing(items)
+}
+
+// collapseWeakSpacing removes weak spacing at the start and end of a line
+// and collapses adjacent weak spacing into the largest one.
+func collapseWeakSpacing(items []Item) []Item {
+	isWeak := func(item Item) bool {
+		a, ok := item.(*AbsoluteItem)
+		return ok && a.Weak
+	}
+
+	out := items[:0]
+	for _, item := range items {
+		if !isWeak(item) {
+			out = append(out, item)
+			continue
+		}
+		if len(out) == 0 {
+			continue
+		}
+		if prev, ok := out[len(out)-1].(*AbsoluteItem); ok && prev.Weak {
+			if item.(*AbsoluteItem).Amount > prev.Amount {
+				out[len(out)-1] = item
+			}
+			continue
+		}
+		out = append(out, item)
+	}
+	for len(out) > 0 && isWeak(out[len(out)-1]) {
+		out = out[:len(out)-1]
+	}
+	return out
 }
@@ -1,6 +1,7 @@
 package inline
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/boergens/gotypst/layout"
@@ -279,6 +280,75 @@ func TestBreakpointsFn(t *testing.T) {
 	})
 }
 
+func TestBreakpointsCJK(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+	}{
+		// Breaks between ideographs.
+		{"中文字", []int{3, 6, 9}},
+		// No break before a closing mark or a full stop.
+		{"中文。字", []int{3, 9, 12}},
+		// No break after an opening or before a closing bracket.
+		{"中「文」字", []int{3, 12, 15}},
+		// Breaks between CJK and Latin text, but not within words.
+		{"中ab字", []int{3, 5, 8}},
+		// No break before small kana.
+		{"ちょっと", []int{9, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			no := false
+			p := &Preparation{Text: tt.text, Config: &Config{Hyphenate: &no}}
+			var ends []int
+			breakpointsFn(p, func(end int, bp BreakpointInfo) {
+				ends = append(ends, end)
+			})
+			if !slices.Equal(ends, tt.want) {
+				t.Errorf("breakpoints = %v, want %v", ends, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakpointsUAX14(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+	}{
+		// Breaks after hyphens, but not within numbers.
+		{"well-known 1-2", []int{5, 11, 14}},
+		// No break before punctuation or after opening brackets.
+		{"word, (next) end", []int{6, 13, 16}},
+		// A soft hyphen is a break opportunity.
+		{"hy\u00adphen", []int{4, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			no := false
+			p := &Preparation{Text: tt.text, Config: &Config{Hyphenate: &no}}
+			var ends []int
+			breakpointsFn(p, func(end int, bp BreakpointInfo) {
+				ends = append(ends, end)
+			})
+			if !slices.Equal(ends, tt.want) {
+				t.Errorf("breakpoints = %v, want %v", ends, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakpointsComputedOnce(t *testing.T) {
+	p := &Preparation{Text: "computed once per paragraph", Config: &Config{}}
+	first := p.breakpoints()
+	if len(first) == 0 || !first[len(first)-1].bp.IsMandatory() {
+		t.Fatalf("breakpoints = %v", first)
+	}
+	if again := p.breakpoints(); &again[0] != &first[0] {
+		t.Error("breakpoints were computed again")
+	}
+}
+
 func TestLinebreakSimple(t *testing.T) {
 	// Create a simple preparation with some text items
 	text := "Hello world this is a test"
@@ -425,3 +495,69 @@ func TestIsVowel(t *testing.T) {
 		}
 	}
 }
+
+func TestCollapseWeakSpacing(t *testing.T) {
+	weak := func(amount Abs) *AbsoluteItem { return &AbsoluteItem{Amount: amount, Weak: true} }
+	strong := &AbsoluteItem{Amount: 3}
+	fr := &FractionalItem{Amount: 1}
+
+	tests := []struct {
+		name  string
+		items []Item
+		want  []Item
+	}{
+		{"leading and trailing", []Item{weak(2), strong, weak(4)}, []Item{strong}},
+		{"adjacent keep largest", []Item{strong, weak(2), weak(5), weak(1), fr}, []Item{strong, weak(5), fr}},
+		{"strong kept", []Item{strong, strong}, []Item{strong, strong}},
+		{"only weak", []Item{weak(1), weak(2)}, []Item{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collapseWeakSpacing(tt.items)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d items, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].NaturalWidth() != tt.want[i].NaturalWidth() {
+					t.Errorf("item %d width = %v, want %v", i, got[i].NaturalWidth(), tt.want[i].NaturalWidth())
+				}
+			}
+		})
+	}
+}
+
+func TestMakeLineJustify(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		end      int
+		lastLine bool
+		want     bool
+	}{
+		{"forced break", "ab\ncd", 3, false, false},
+		{"justified forced break", "ab" + LinebreakText(true) + "cd", 5, false, true},
+		{"last line", "ab\ncd", 5, false, false},
+		{"justified last line", "ab\ncd", 5, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Preparation{
+				Text:   tt.text,
+				Config: &Config{Justify: true, JustifyLastLine: tt.lastLine},
+			}
+			start := 0
+			if tt.end == len(tt.text) {
+				start = tt.end - 2
+			}
+			line := makeLine(p, start, tt.end, Mandatory(), nil)
+			if line.Justify != tt.want {
+				t.Errorf("Justify = %v, want %v", line.Justify, tt.want)
+			}
+		})
+	}
+
+	p := &Preparation{Text: "ab cd", Config: &Config{Justify: true}}
+	if line := makeLine(p, 0, 3, Normal(), nil); !line.Justify {
+		t.Error("line ending at a normal break should be justified")
+	}
+}
@@ -0,0 +1,66 @@
+package inline
+
+// NewPreparation prepares the items of a paragraph for line breaking. If
+// the config enables CJK-Latin spacing, spacing is added between adjacent
+// CJK and Latin characters.
+// Matches Rust: prepare in typst-layout/src/inline/prepare.rs
+func NewPreparation(text string, items []PreparedItem, config *Config) *Preparation {
+	if config.CJKLatinSpacing {
+		addCJKLatinSpacing(items)
+	}
+	return &Preparation{Text: text, Items: items, Config: config}
+}
+
+// addCJKLatinSpacing adds a quarter em of spacing between CJK characters
+// and adjacent Latin letters or numbers. The spacing can shrink to an
+// eighth of an em during justification. Tags are skipped, and any other
+// non-text item separates the characters around it.
+// Matches Rust: add_cjk_latin_spacing
+func addCJKLatinSpacing(items []PreparedItem) {
+	var texts []*ShapedText
+	var prev *ShapedGlyph
+	for _, pi := range items {
+		switch item := pi.Item.(type) {
+		case *TagItem:
+			continue
+		case *TextItem:
+			if item.shaped != nil {
+				texts = append(texts, item.shaped)
+				continue
+			}
+		}
+		texts = append(texts, nil)
+	}
+
+	for i, text := range texts {
+		if text == nil {
+			prev = nil
+			continue
+		}
+		glyphs := text.Glyphs.All()
+		for j := range glyphs {
+			glyph := &glyphs[j]
+			var next *ShapedGlyph
+			if j+1 < len(glyphs) {
+				next = &glyphs[j+1]
+			} else if i+1 < len(texts) && texts[i+1] != nil && len(texts[i+1].Glyphs.All()) > 0 {
+				next = &texts[i+1].Glyphs.All()[0]
+			}
+
+			// CJK followed by a Latin character.
+			if glyph.IsCJScript() && next != nil && next.IsLetterOrNumber() {
+				glyph.XAdvance += 0.25
+				glyph.Adjustability.Shrinkability[1] += 0.125
+			}
+
+			// Latin followed by a CJK character.
+			if glyph.IsCJScript() && prev != nil && prev.IsLetterOrNumber() {
+				glyph.XAdvance += 0.25
+				glyph.XOffset += 0.25
+				glyph.Adjustability.Shrinkability[0] += 0.125
+			}
+
+			prev = glyph
+		}
+	}
+}
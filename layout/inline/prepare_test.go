@@ -0,0 +1,56 @@
+package inline
+
+import (
+	"testing"
+)
+
+// shapedItem creates a text item with one glyph of one em per character.
+func shapedItem(text string) *TextItem {
+	var glyphs []ShapedGlyph
+	for i, c := range text {
+		glyphs = append(glyphs, ShapedGlyph{
+			XAdvance: EmOne(),
+			Size:     10,
+			Range:    Range{Start: i, End: i + len(string(c))},
+			Char:     c,
+			Script:   getScript(c),
+		})
+	}
+	return &TextItem{shaped: &ShapedText{Text: text, Glyphs: NewGlyphsFromSlice(glyphs)}}
+}
+
+func TestCJKLatinSpacing(t *testing.T) {
+	cjk := shapedItem("中文")
+	latin := shapedItem("ab")
+	tail := shapedItem("字")
+	items := []PreparedItem{
+		{Item: cjk},
+		{Item: &TagItem{}},
+		{Item: latin},
+		{Item: tail},
+	}
+	p := NewPreparation("中文ab字", items, &Config{CJKLatinSpacing: true})
+
+	if got := p.Items[0].Item.NaturalWidth(); got != 22.5 {
+		t.Errorf("CJK before Latin: width = %v, want 22.5", got)
+	}
+	if got := p.Items[2].Item.NaturalWidth(); got != 20 {
+		t.Errorf("Latin: width = %v, want 20", got)
+	}
+	glyph := tail.shaped.Glyphs.At(0)
+	if glyph.XAdvance != 1.25 || glyph.XOffset != 0.25 || glyph.Adjustability.Shrinkability[0] != 0.125 {
+		t.Errorf("CJK after Latin: advance %v, offset %v, shrink %v", glyph.XAdvance, glyph.XOffset, glyph.Adjustability.Shrinkability)
+	}
+	if first := cjk.shaped.Glyphs.At(0); first.XAdvance != EmOne() {
+		t.Errorf("CJK between CJK: advance %v, want 1em", first.XAdvance)
+	}
+}
+
+func TestCJKLatinSpacingDisabled(t *testing.T) {
+	cjk := shapedItem("中")
+	items := []PreparedItem{{Item: cjk}, {Item: shapedItem("a")}}
+	NewPreparation("中a", items, &Config{})
+	if got := cjk.NaturalWidth(); got != 10 {
+		t.Errorf("width = %v, want 10", got)
+	}
+}
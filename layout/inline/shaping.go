@@ -3,10 +3,15 @@ package inline
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"slices"
 	"sync"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/memo"
 	"github.com/go-text/typesetting/di"
 	"github.com/go-text/typesetting/font"
 	"github.com/go-text/typesetting/language"
@@ -276,6 +281,13 @@ type ShapedText struct {
 	Region  *Region     // Optional region
 	Variant FontVariant // Font variant used
 	Glyphs  *Glyphs     // Shaped glyphs
+
+	// Tracking is the extra space the glyphs' advances include after
+	// each cluster. Exporters that position glyphs by their font widths
+	// need it to recover the natural advances.
+	Tracking Abs
+	// Shift moves the text's baseline. Positive values move it down.
+	Shift Abs
 }
 
 // FontVariant describes a font variant (style, weight, stretch).
@@ -370,13 +382,15 @@ func (s *ShapedText) Shrinkability() Abs {
 // Empty returns an empty ShapedText with the same metadata.
 func (s *ShapedText) Empty() *ShapedText {
 	return &ShapedText{
-		Base:    s.Base,
-		Text:    "",
-		Dir:     s.Dir,
-		Lang:    s.Lang,
-		Region:  s.Region,
-		Variant: s.Variant,
-		Glyphs:  NewGlyphsFromSlice(nil),
+		Base:     s.Base,
+		Text:     "",
+		Dir:      s.Dir,
+		Lang:     s.Lang,
+		Region:   s.Region,
+		Variant:  s.Variant,
+		Glyphs:   NewGlyphsFromSlice(nil),
+		Tracking: s.Tracking,
+		Shift:    s.Shift,
 	}
 }
 
@@ -559,15 +573,24 @@ var (
 // ShapingContext holds context for a shaping operation.
 type ShapingContext struct {
 	Shaper   *shaping.HarfbuzzShaper
-	Faces    []*font.Face  // Font faces for fallback
-	Size     Abs           // Font size
-	Variant  FontVariant   // Font variant
+	Faces    []*font.Face // Font faces for fallback
+	Size     Abs          // Font size
+	Variant  FontVariant  // Font variant
 	Features []shaping.FontFeature
 	Fallback bool // Enable font fallback
 	Dir      Dir
-	glyphs   []ShapedGlyph
-	used     []*font.Face
-	mu       sync.Mutex
+	Tracking Abs     // Extra space after each cluster
+	Spacing  float64 // Width of spaces relative to their normal width
+	SpaceAbs Abs     // Extra width of spaces, added after scaling
+	Shift    Abs     // Baseline shift, positive values move text down
+	// SmallCaps turns lowercase letters into small capitals, and Script
+	// shapes the text as superscript or subscript. Only ShapeSynthesized
+	// applies them.
+	SmallCaps bool
+	Script    *ScriptShift
+	glyphs    []ShapedGlyph
+	used      []*font.Face
+	mu        sync.Mutex
 }
 
 // NewShapingContext creates a new shaping context.
@@ -577,21 +600,46 @@ func NewShapingContext(faces []*font.Face, size Abs) *ShapingContext {
 		Faces:    faces,
 		Size:     size,
 		Fallback: true,
+		Spacing:  1,
 		glyphs:   make([]ShapedGlyph, 0, 128),
 	}
 }
 
 // Shape shapes text and returns ShapedText.
+//
+// Shaping results are memoized across calls and compilations, keyed by the
+// text and everything in the context that affects its shaping: the fonts,
+// size, variant, features, direction, and language. The number of memoized
+// runs is bounded by SetShapeCacheCapacity. Printable ASCII text that the
+// font's default features leave alone is positioned from its metrics
+// without running the shaper.
 func Shape(ctx *ShapingContext, base int, text string, dir Dir, lang Lang, region *Region) *ShapedText {
 	if len(text) == 0 {
 		return &ShapedText{
-			Base:    base,
-			Text:    text,
-			Dir:     dir,
-			Lang:    lang,
-			Region:  region,
-			Variant: ctx.Variant,
-			Glyphs:  NewGlyphsFromSlice(nil),
+			Base:     base,
+			Text:     text,
+			Dir:      dir,
+			Lang:     lang,
+			Region:   region,
+			Variant:  ctx.Variant,
+			Glyphs:   NewGlyphsFromSlice(nil),
+			Tracking: ctx.Tracking,
+			Shift:    ctx.Shift,
+		}
+	}
+
+	key := shapeKey(ctx, text, dir, lang, region)
+	if run, ok := shapeCache.Get(key); ok && slices.Equal(run.faces, ctx.Faces) {
+		return &ShapedText{
+			Base:     base,
+			Text:     text,
+			Dir:      dir,
+			Lang:     lang,
+			Region:   region,
+			Variant:  ctx.Variant,
+			Glyphs:   NewGlyphsFromVec(run.at(base)),
+			Tracking: ctx.Tracking,
+			Shift:    ctx.Shift,
 		}
 	}
 
@@ -602,23 +650,128 @@ func Shape(ctx *ShapingContext, base int, text string, dir Dir, lang Lang, regio
 	ctx.used = ctx.used[:0]
 	ctx.Dir = dir
 
+	// Faces are not safe for concurrent use, so text is shaped one run at a
+	// time even when pages are laid out in parallel.
+	facesMu.Lock()
 	shapeSegment(ctx, base, text)
+	facesMu.Unlock()
 
 	trackAndSpace(ctx)
 	calculateAdjustability(ctx, lang, region)
 
 	glyphs := make([]ShapedGlyph, len(ctx.glyphs))
 	copy(glyphs, ctx.glyphs)
+	shapeCache.Put(key, newShapedRun(ctx.Faces, glyphs, base))
 
 	return &ShapedText{
-		Base:    base,
-		Text:    text,
-		Dir:     dir,
-		Lang:    lang,
-		Region:  region,
-		Variant: ctx.Variant,
-		Glyphs:  NewGlyphsFromVec(glyphs),
+		Base:     base,
+		Text:     text,
+		Dir:      dir,
+		Lang:     lang,
+		Region:   region,
+		Variant:  ctx.Variant,
+		Glyphs:   NewGlyphsFromVec(glyphs),
+		Tracking: ctx.Tracking,
+		Shift:    ctx.Shift,
+	}
+}
+
+// facesMu serializes the use of font faces during shaping.
+var facesMu sync.Mutex
+
+// DefaultShapeCacheCapacity is the number of shaped runs kept across
+// compilations unless the embedder sets another bound.
+const DefaultShapeCacheCapacity = 10000
+
+// shapeCache holds shaped text runs across compilations, so that words and
+// runs that repeat, like those in headers, footers, and table cells, are
+// shaped once.
+var shapeCache = newShapeCache()
+
+// newShapeCache creates the cache of shaped runs with the default bound.
+func newShapeCache() *memo.Cache[memo.Hash, *shapedRun] {
+	c := memo.NewCache[memo.Hash, *shapedRun]()
+	c.SetCapacity(DefaultShapeCacheCapacity)
+	return c
+}
+
+// SetShapeCacheCapacity bounds the number of shaped runs kept across
+// compilations. Once the bound is reached, the runs used longest ago are
+// dropped. A capacity of zero or less removes the bound, leaving only
+// memo.Evict to drop runs. Embedders with tight memory or with very large
+// documents can tune it.
+func SetShapeCacheCapacity(capacity int) {
+	shapeCache.SetCapacity(capacity)
+}
+
+// shapedRun is a memoized shaping result. The glyph ranges are relative to
+// the start of the text, so that the run can be reused at any offset.
+type shapedRun struct {
+	// faces are the faces the text was shaped with. They are compared on
+	// lookup, since the key only identifies them by address.
+	faces  []*font.Face
+	glyphs []ShapedGlyph
+}
+
+// newShapedRun creates a run from glyphs shaped at the given base offset.
+func newShapedRun(faces []*font.Face, glyphs []ShapedGlyph, base int) *shapedRun {
+	return &shapedRun{
+		faces:  slices.Clone(faces),
+		glyphs: shiftGlyphs(glyphs, -base),
+	}
+}
+
+// at returns a copy of the run's glyphs for text starting at base. The
+// caller may modify the copy.
+func (r *shapedRun) at(base int) []ShapedGlyph {
+	return shiftGlyphs(r.glyphs, base)
+}
+
+// shiftGlyphs returns a copy of the glyphs with their ranges moved by delta.
+func shiftGlyphs(glyphs []ShapedGlyph, delta int) []ShapedGlyph {
+	out := make([]ShapedGlyph, len(glyphs))
+	for i, g := range glyphs {
+		g.Range = Range{Start: g.Range.Start + delta, End: g.Range.End + delta}
+		out[i] = g
+	}
+	return out
+}
+
+// shapeKey hashes the text and the parts of the context that its shaping
+// depends on.
+func shapeKey(ctx *ShapingContext, text string, dir Dir, lang Lang, region *Region) memo.Hash {
+	h := memo.NewHasher()
+	h.WriteString(text)
+	h.WriteUint64(uint64(dir))
+	h.WriteString(string(lang))
+	if region != nil {
+		h.WriteUint64(1)
+		h.WriteString(string(*region))
+	} else {
+		h.WriteUint64(0)
+	}
+	h.WriteUint64(math.Float64bits(float64(ctx.Size)))
+	h.WriteUint64(uint64(ctx.Variant.Style))
+	h.WriteUint64(uint64(ctx.Variant.Weight))
+	h.WriteUint64(uint64(ctx.Variant.Stretch))
+	if ctx.Fallback {
+		h.WriteUint64(1)
+	} else {
+		h.WriteUint64(0)
+	}
+	h.WriteUint64(math.Float64bits(float64(ctx.Tracking)))
+	h.WriteUint64(math.Float64bits(ctx.Spacing))
+	h.WriteUint64(math.Float64bits(float64(ctx.SpaceAbs)))
+	h.WriteUint64(uint64(len(ctx.Features)))
+	for _, feature := range ctx.Features {
+		h.WriteUint64(uint64(feature.Tag))
+		h.WriteUint64(uint64(feature.Value))
+	}
+	h.WriteUint64(uint64(len(ctx.Faces)))
+	for _, face := range ctx.Faces {
+		h.WriteUint64(uint64(reflect.ValueOf(face).Pointer()))
 	}
+	return h.Sum()
 }
 
 // shapeSegment shapes a text segment using available fonts.
@@ -652,6 +805,10 @@ func shapeSegment(ctx *ShapingContext, base int, text string) {
 		return
 	}
 
+	if shapeSimple(ctx, base, text, face) {
+		return
+	}
+
 	ctx.used = append(ctx.used, face)
 
 	// Prepare shaping input
@@ -661,48 +818,71 @@ func shapeSegment(ctx *ShapingContext, base int, text string) {
 		direction = di.DirectionRTL
 	}
 
+	// The text is shaped at one em in font units, so that the positions
+	// come out exact and independent of the text size.
+	upem := float64(face.Upem())
 	input := shaping.Input{
 		Text:         runes,
 		RunStart:     0,
 		RunEnd:       len(runes),
 		Face:         face,
-		Size:         toFixed(float64(ctx.Size)),
+		Size:         toFixed(upem),
 		Direction:    direction,
 		FontFeatures: ctx.Features,
 	}
+	scale := float64(toFixed(upem))
 
 	// Shape the text
 	output := ctx.Shaper.Shape(input)
 
-	// Convert shaped glyphs to our format
-	byteOffset := 0
-	runeIdx := 0
-
-	for i, glyph := range output.Glyphs {
-		// Calculate byte range for this cluster
-		cluster := glyph.ClusterIndex
-
-		// Find the byte position for this rune
-		for runeIdx < cluster && runeIdx < len(runes) {
-			byteOffset += len(string(runes[runeIdx]))
-			runeIdx++
+	// Find the byte range of each glyph's cluster, which extends to the
+	// next cluster in logical order.
+	offsets := make([]int, len(runes)+1)
+	for i, r := range runes {
+		offsets[i+1] = offsets[i] + utf8.RuneLen(r)
+	}
+	present := make([]bool, len(runes))
+	for _, g := range output.Glyphs {
+		if g.ClusterIndex < len(runes) {
+			present[g.ClusterIndex] = true
 		}
-
-		start := base + byteOffset
-
-		// Find end of cluster
-		endRune := cluster + 1
-		if i+1 < len(output.Glyphs) {
-			endRune = output.Glyphs[i+1].ClusterIndex
-		} else {
-			endRune = len(runes)
+	}
+	ends := make([]int, len(runes))
+	for i, next := len(runes)-1, len(runes); i >= 0; i-- {
+		ends[i] = next
+		if present[i] {
+			next = i
 		}
+	}
+	clusterEnd := func(i int) int {
+		if cluster := output.Glyphs[i].ClusterIndex; cluster < len(runes) {
+			return ends[cluster]
+		}
+		return len(runes)
+	}
+
+	for i := 0; i < len(output.Glyphs); i++ {
+		glyph := output.Glyphs[i]
+		cluster := glyph.ClusterIndex
 
-		endByte := byteOffset
-		for r := cluster; r < endRune && r < len(runes); r++ {
-			endByte += len(string(runes[r]))
+		// Shape runs of characters the font has no glyphs for, such as
+		// emoji in a text font, with the next font.
+		if glyph.GlyphID == 0 && ctx.Fallback && hasUnusedFace(ctx) {
+			j := i
+			start, end := cluster, clusterEnd(i)
+			for j+1 < len(output.Glyphs) && output.Glyphs[j+1].GlyphID == 0 {
+				j++
+				if cluster := output.Glyphs[j].ClusterIndex; cluster < start {
+					start = cluster
+				}
+				if e := clusterEnd(j); e > end {
+					end = e
+				}
+			}
+			shapeSegment(ctx, base+offsets[start], text[offsets[start]:offsets[end]])
+			i = j
+			continue
 		}
-		end := base + endByte
 
 		// Get the character for this cluster
 		var c rune
@@ -711,17 +891,17 @@ func shapeSegment(ctx *ShapingContext, base int, text string) {
 		}
 
 		script := getScript(c)
-		xAdvance := Em(float64(glyph.XAdvance) / float64(ctx.Size))
+		xAdvance := Em(float64(glyph.XAdvance) / scale)
 
 		ctx.glyphs = append(ctx.glyphs, ShapedGlyph{
 			Font:          face,
 			GlyphID:       uint16(glyph.GlyphID),
 			XAdvance:      xAdvance,
-			XOffset:       Em(float64(glyph.XOffset) / float64(ctx.Size)),
-			YOffset:       Em(float64(glyph.YOffset) / float64(ctx.Size)),
+			XOffset:       Em(float64(glyph.XOffset) / scale),
+			YOffset:       Em(float64(glyph.YOffset) / scale),
 			Size:          ctx.Size,
 			Adjustability: Adjustability{},
-			Range:         Range{Start: start, End: end},
+			Range:         Range{Start: base + offsets[cluster], End: base + offsets[clusterEnd(i)]},
 			SafeToBreak:   true, // Simplified; HarfBuzz provides this info
 			Char:          c,
 			IsJustifiable: isJustifiable(c, script, xAdvance, [2]Em{0, 0}),
@@ -732,6 +912,17 @@ func shapeSegment(ctx *ShapingContext, base int, text string) {
 	ctx.used = ctx.used[:len(ctx.used)-1]
 }
 
+// hasUnusedFace reports whether a font is left to shape text with that the
+// fonts in use have no glyphs for.
+func hasUnusedFace(ctx *ShapingContext) bool {
+	for _, f := range ctx.Faces {
+		if f != nil && !containsFace(ctx.used, f) {
+			return true
+		}
+	}
+	return false
+}
+
 // shapeTofus creates placeholder glyphs for missing characters.
 func shapeTofus(ctx *ShapingContext, base int, text string, face *font.Face) {
 	xAdvance := Em(0.5) // Default tofu width
@@ -775,26 +966,23 @@ func shapeTofus(ctx *ShapingContext, base int, text string, face *font.Face) {
 	}
 }
 
-// trackAndSpace applies tracking and word spacing.
+// trackAndSpace applies tracking and word spacing. Spaces are scaled by
+// the spacing ratio and widened by its absolute part, and every glyph that ends a cluster, except the last
+// one, is followed by the tracking. Since adjustability is computed
+// afterwards, justification stretches and shrinks the spaced widths.
+// Matches Rust: track_and_space
 func trackAndSpace(ctx *ShapingContext) {
-	// Simplified tracking - in production, this would read from styles
-	tracking := Em(0) // Default no tracking
-	spacing := Em(1)  // Default 100% word spacing
+	tracking := EmFromAbs(ctx.Tracking, ctx.Size)
+	spacing := Em(ctx.Spacing)
+	spaceAbs := EmFromAbs(ctx.SpaceAbs, ctx.Size)
 
 	for i := 0; i < len(ctx.glyphs); i++ {
 		g := &ctx.glyphs[i]
 
-		// Adjust NBSP to match regular space width
-		if g.Char == '\u00A0' {
-			// In production, would calculate delta from font metrics
-		}
-
-		// Apply word spacing
 		if g.IsSpace() {
-			g.XAdvance = g.XAdvance * spacing
+			g.XAdvance = g.XAdvance*spacing + spaceAbs
 		}
 
-		// Apply tracking between glyphs (not at end of cluster)
 		if i+1 < len(ctx.glyphs) && g.Range.Start != ctx.glyphs[i+1].Range.Start {
 			g.XAdvance += tracking
 		}
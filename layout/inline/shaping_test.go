@@ -3,6 +3,7 @@ package inline
 import (
 	"testing"
 
+	"github.com/go-text/typesetting/font"
 	"github.com/go-text/typesetting/language"
 )
 
@@ -458,3 +459,134 @@ func TestShapedTextString(t *testing.T) {
 		t.Error("String() returned empty")
 	}
 }
+
+func TestShapedRunRebase(t *testing.T) {
+	glyphs := []ShapedGlyph{
+		{Char: 'a', Range: Range{Start: 10, End: 11}},
+		{Char: 'b', Range: Range{Start: 11, End: 12}},
+	}
+	run := newShapedRun(nil, glyphs, 10)
+
+	got := run.at(3)
+	if got[0].Range != (Range{Start: 3, End: 4}) || got[1].Range != (Range{Start: 4, End: 5}) {
+		t.Errorf("at(3) ranges = %v, %v", got[0].Range, got[1].Range)
+	}
+
+	// Modifying a copy must not affect the cached run.
+	got[0].XAdvance = 1
+	if run.at(0)[0].XAdvance != 0 {
+		t.Error("modifying the glyphs of a cached run changed the run")
+	}
+}
+
+func TestShapeKey(t *testing.T) {
+	ctx := NewShapingContext(nil, 10)
+	key := shapeKey(ctx, "word", DirLTR, "en", nil)
+	if shapeKey(ctx, "word", DirLTR, "en", nil) != key {
+		t.Error("same input hashes differently")
+	}
+	region := Region("US")
+	if shapeKey(ctx, "word", DirLTR, "en", &region) == key {
+		t.Error("region is not part of the key")
+	}
+	if shapeKey(ctx, "word", DirRTL, "en", nil) == key {
+		t.Error("direction is not part of the key")
+	}
+	ctx.Size = 12
+	if shapeKey(ctx, "word", DirLTR, "en", nil) == key {
+		t.Error("size is not part of the key")
+	}
+	key = shapeKey(ctx, "word", DirLTR, "en", nil)
+	ctx.Tracking = 1
+	if shapeKey(ctx, "word", DirLTR, "en", nil) == key {
+		t.Error("tracking is not part of the key")
+	}
+	key = shapeKey(ctx, "word", DirLTR, "en", nil)
+	ctx.Spacing = 2
+	if shapeKey(ctx, "word", DirLTR, "en", nil) == key {
+		t.Error("spacing is not part of the key")
+	}
+	key = shapeKey(ctx, "word", DirLTR, "en", nil)
+	ctx.SpaceAbs = 1
+	if shapeKey(ctx, "word", DirLTR, "en", nil) == key {
+		t.Error("absolute spacing is not part of the key")
+	}
+}
+
+func TestTrackAndSpace(t *testing.T) {
+	ctx := NewShapingContext(nil, 10)
+	ctx.Tracking = 1
+	ctx.Spacing = 1.5
+	ctx.SpaceAbs = 1
+	ctx.glyphs = []ShapedGlyph{
+		{Char: 'a', XAdvance: 0.5, Range: Range{Start: 0, End: 1}},
+		{Char: ' ', XAdvance: 0.2, Range: Range{Start: 1, End: 2}},
+		// A base and a combining mark in one cluster.
+		{Char: 'e', XAdvance: 0.4, Range: Range{Start: 2, End: 4}},
+		{Char: '\u0301', XAdvance: 0, Range: Range{Start: 2, End: 4}},
+		{Char: 'b', XAdvance: 0.5, Range: Range{Start: 4, End: 5}},
+	}
+	trackAndSpace(ctx)
+
+	// The tracking of 1pt is 0.1em at 10pt. The last glyph and glyphs
+	// inside a cluster are not tracked. The space is scaled to 0.3em and
+	// widened by 0.1em before tracking.
+	want := []Em{0.6, 0.5, 0.4, 0.1, 0.5}
+	for i, g := range ctx.glyphs {
+		if abs(float64(g.XAdvance-want[i])) > 1e-9 {
+			t.Errorf("glyph %d (%q): XAdvance = %v, want %v", i, g.Char, g.XAdvance, want[i])
+		}
+	}
+
+	// Justification stretches spaces by their spaced width.
+	calculateAdjustability(ctx, "en", nil)
+	if got := ctx.glyphs[1].Stretchability()[1]; abs(float64(got)-0.25) > 1e-9 {
+		t.Errorf("space stretchability = %v, want 0.25", got)
+	}
+}
+
+func TestShapeFallback(t *testing.T) {
+	primary, fallback := goRegular(t), goRegular(t)
+	ctx := NewShapingContext([]*font.Face{primary, fallback}, 10)
+
+	shaped := Shape(ctx, 0, "a😀b", DirLTR, "en", nil)
+	glyphs := shaped.Glyphs.All()
+	if len(glyphs) != 3 {
+		t.Fatalf("got %d glyphs, want 3", len(glyphs))
+	}
+	for i, want := range []struct {
+		face  *font.Face
+		start int
+		end   int
+	}{{primary, 0, 1}, {fallback, 1, 5}, {primary, 5, 6}} {
+		g := glyphs[i]
+		if g.Font != want.face || g.Range != (Range{Start: want.start, End: want.end}) {
+			t.Errorf("glyph %d: range %v, fallback font %v", i, g.Range, g.Font == fallback)
+		}
+	}
+}
+
+func TestShapeCacheCapacity(t *testing.T) {
+	defer SetShapeCacheCapacity(DefaultShapeCacheCapacity)
+	ctx := NewShapingContext([]*font.Face{goRegular(t)}, 10)
+
+	SetShapeCacheCapacity(2)
+	first := Shape(ctx, 0, "header", DirLTR, "en", nil)
+	Shape(ctx, 0, "footer", DirLTR, "en", nil)
+	Shape(ctx, 0, "cell", DirLTR, "en", nil)
+	if n := shapeCache.Len(); n != 2 {
+		t.Errorf("cache holds %d runs, want 2", n)
+	}
+
+	// A repeated run is shaped the same at a different offset.
+	again := Shape(ctx, 20, "header", DirLTR, "en", nil)
+	a, b := first.Glyphs.All(), again.Glyphs.All()
+	if len(a) != len(b) || len(a) == 0 {
+		t.Fatalf("got %d and %d glyphs", len(a), len(b))
+	}
+	for i := range a {
+		if a[i].GlyphID != b[i].GlyphID || b[i].Range.Start != a[i].Range.Start+20 {
+			t.Errorf("glyph %d: %+v and %+v", i, a[i], b[i])
+		}
+	}
+}
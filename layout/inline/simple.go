@@ -0,0 +1,408 @@
+package inline
+
+import (
+	"slices"
+
+	"github.com/boergens/gotypst/memo"
+	"github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/font/opentype/tables"
+)
+
+// Most text in long documents is plain ASCII in fonts whose default
+// features leave most of its characters alone. Running the full shaper on
+// such text spends most of its time planning lookups that never apply. For
+// these runs, the glyphs come straight from the cmap and their positions
+// from the advances and the kerning pairs, which gives the same result as
+// the shaper at a fraction of the cost.
+
+// The range of characters the fast path handles: printable ASCII.
+const (
+	firstSimple = 0x20
+	lastSimple  = 0x7e
+	simpleCount = lastSimple - firstSimple + 1
+)
+
+// ignoreBaseGlyphs is the lookup flag that makes a lookup skip base glyphs.
+const ignoreBaseGlyphs = 1 << 1
+
+// defaultFeatures are the features the shaper applies to horizontal
+// left-to-right text of an unknown script when none are requested.
+var defaultFeatures = tags(
+	"rvrn", "ltra", "ltrm", "rand", "trak", "Harf", "HARF", "Buzz", "BUZZ",
+	"abvm", "blwm", "ccmp", "locl", "mark", "mkmk", "rlig",
+	"calt", "clig", "curs", "dist", "kern", "liga", "rclt",
+)
+
+// defaultScripts are the scripts the shaper picks the features of text of
+// an unknown script from, in order of preference.
+var defaultScripts = tags("DFLT", "dflt", "latn")
+
+// tags converts feature or script names to tags.
+func tags(names ...string) []font.Tag {
+	out := make([]font.Tag, len(names))
+	for i, name := range names {
+		out[i] = ot.MustNewTag(name)
+	}
+	return out
+}
+
+// simpleFace holds what positioning printable ASCII text in a face takes.
+type simpleFace struct {
+	upem     float64
+	glyphs   [simpleCount]font.GID
+	advances [simpleCount]float64
+	// shaped marks the characters that the face has no glyph for or that a
+	// default lookup other than pair positioning may apply to. Text with
+	// any of them is left to the shaper.
+	shaped [simpleCount]bool
+	// pairs are the subtables of the default pair positioning lookups, in
+	// the order the shaper applies the lookups.
+	pairs [][]tables.PairPos
+	// kerns are the subtables of the legacy kern table, if the shaper
+	// uses it.
+	kerns []font.SimpleKerns
+}
+
+// DefaultSimpleFaceCacheCapacity is the number of faces whose simple
+// metrics are kept across compilations unless the embedder sets another
+// bound.
+const DefaultSimpleFaceCacheCapacity = 256
+
+// simpleFaces holds the simple metrics of the faces shaped recently, and nil
+// for faces that need the shaper for all text. Like the shaped runs, they
+// are dropped by memo.Evict and once the bound is reached.
+var simpleFaces = newSimpleFaceCache()
+
+// newSimpleFaceCache creates the cache of simple metrics with the default
+// bound.
+func newSimpleFaceCache() *memo.Cache[*font.Face, *simpleFace] {
+	c := memo.NewCache[*font.Face, *simpleFace]()
+	c.SetCapacity(DefaultSimpleFaceCacheCapacity)
+	return c
+}
+
+// SetSimpleFaceCacheCapacity bounds the number of faces whose simple
+// metrics are kept across compilations. Once the bound is reached, the
+// faces used longest ago are dropped. A capacity of zero or less removes
+// the bound, leaving only memo.Evict to drop them.
+func SetSimpleFaceCacheCapacity(capacity int) {
+	simpleFaces.SetCapacity(capacity)
+}
+
+// newSimpleFace reads the simple metrics of a face, or returns nil if
+// text in the face always needs the shaper, as with AAT layout tables and
+// variable or hinted instances.
+func newSimpleFace(face *font.Face) *simpleFace {
+	if face.Font == nil || face.Upem() == 0 || len(face.Morx) != 0 || face.Kerx != nil ||
+		!face.Trak.IsEmpty() || len(face.Coords()) != 0 ||
+		len(face.GSUB.FeatureVariations) != 0 || len(face.GPOS.FeatureVariations) != 0 {
+		return nil
+	}
+	if x, y := face.Ppem(); x != 0 || y != 0 {
+		return nil
+	}
+
+	s := &simpleFace{upem: float64(face.Upem())}
+	for i := range s.glyphs {
+		glyph, ok := face.NominalGlyph(rune(firstSimple + i))
+		s.glyphs[i] = glyph
+		s.advances[i] = float64(face.HorizontalAdvance(glyph))
+		s.shaped[i] = !ok || glyph == 0 ||
+			face.GDEF.GlyphClassDef != nil && face.GDEF.GlyphProps(tables.GlyphID(glyph))&(tables.GPLigature|tables.GPMark) != 0
+	}
+
+	lookups, _ := defaultLookups(&face.GSUB.Layout)
+	for _, index := range lookups {
+		if int(index) >= len(face.GSUB.Lookups) {
+			continue
+		}
+		lookup := face.GSUB.Lookups[index]
+		for _, subtable := range lookup.Subtables {
+			s.coverSubtable(subtable, lookup.Flag)
+		}
+	}
+
+	lookups, gposKern := defaultLookups(&face.GPOS.Layout)
+	for _, index := range lookups {
+		if int(index) >= len(face.GPOS.Lookups) {
+			continue
+		}
+		lookup := face.GPOS.Lookups[index]
+		var pairs []tables.PairPos
+		for _, subtable := range lookup.Subtables {
+			if pair, ok := subtable.(tables.PairPos); ok && lookup.Flag&ignoreBaseGlyphs == 0 {
+				pairs = append(pairs, pair)
+			} else {
+				s.coverSubtable(subtable, lookup.Flag)
+			}
+		}
+		if len(pairs) > 0 {
+			s.pairs = append(s.pairs, pairs)
+		}
+	}
+
+	// The shaper falls back to the kern table if GPOS doesn't kern.
+	if face.GPOS.Lookups != nil && gposKern {
+		return s
+	}
+	for _, subtable := range face.Kern {
+		if !subtable.IsExtended && subtable.IsVariation() || !subtable.IsHorizontal() {
+			continue
+		}
+		switch subtable.Data.(type) {
+		case font.Kern0, font.Kern2, font.Kern3:
+			if subtable.IsCrossStream() {
+				return nil
+			}
+			if !subtable.IsBackwards() {
+				s.kerns = append(s.kerns, subtable.Data.(font.SimpleKerns))
+			}
+		default:
+			return nil
+		}
+	}
+	return s
+}
+
+// coverSubtable marks the characters a lookup subtable may apply to as
+// shaped. A subtable applies to the glyphs in its coverage, except for
+// ligatures and contextual rules that need glyphs no character maps to,
+// like the combining accents many fonts compose letters with.
+func (s *simpleFace) coverSubtable(subtable interface{ Cov() tables.Coverage }, flag uint16) {
+	var context [][]tables.Coverage
+	switch subtable := subtable.(type) {
+	case tables.LigatureSubs:
+		s.coverLigatures(subtable)
+		return
+	case tables.ContextualSubs:
+		if data, ok := subtable.Data.(tables.ContextualSubs3); ok {
+			context = [][]tables.Coverage{data.Coverages}
+		}
+	case tables.ChainedContextualSubs:
+		if data, ok := subtable.Data.(tables.ChainedContextualSubs3); ok {
+			context = [][]tables.Coverage{data.BacktrackCoverages, data.InputCoverages, data.LookaheadCoverages}
+		}
+	case tables.ContextualPos:
+		if data, ok := subtable.Data.(tables.ContextualPos3); ok {
+			context = [][]tables.Coverage{data.Coverages}
+		}
+	case tables.ChainedContextualPos:
+		if data, ok := subtable.Data.(tables.ChainedContextualPos3); ok {
+			context = [][]tables.Coverage{data.BacktrackCoverages, data.InputCoverages, data.LookaheadCoverages}
+		}
+	}
+
+	// A lookup that skips base glyphs matches its context across them.
+	if flag&ignoreBaseGlyphs == 0 {
+		for _, coverages := range context {
+			for _, coverage := range coverages {
+				if !slices.ContainsFunc(s.glyphs[:], func(glyph font.GID) bool { return covers(coverage, glyph) }) {
+					return
+				}
+			}
+		}
+	}
+	for i, glyph := range s.glyphs {
+		if covers(subtable.Cov(), glyph) {
+			s.shaped[i] = true
+		}
+	}
+}
+
+// covers reports whether a glyph is in a coverage. A missing coverage is
+// taken to cover all glyphs.
+func covers(coverage tables.Coverage, glyph font.GID) bool {
+	if coverage == nil {
+		return true
+	}
+	_, ok := coverage.Index(tables.GlyphID(glyph))
+	return ok
+}
+
+// coverLigatures marks the characters that start a ligature whose other
+// components are characters too as shaped.
+func (s *simpleFace) coverLigatures(subtable tables.LigatureSubs) {
+	for i, glyph := range s.glyphs {
+		index, ok := subtable.Coverage.Index(tables.GlyphID(glyph))
+		if !ok || index >= len(subtable.LigatureSets) {
+			continue
+		}
+		for _, ligature := range subtable.LigatureSets[index].Ligatures {
+			if !slices.ContainsFunc(ligature.ComponentGlyphIDs, func(component tables.GlyphID) bool {
+				return !slices.Contains(s.glyphs[:], font.GID(component))
+			}) {
+				s.shaped[i] = true
+				break
+			}
+		}
+	}
+}
+
+// defaultLookups returns the lookups of a layout table that the shaper
+// applies to text of an unknown script and language, sorted by index, and
+// whether they include kerning.
+func defaultLookups(layout *font.Layout) ([]uint16, bool) {
+	script := -1
+	for _, tag := range defaultScripts {
+		if script = layout.FindScript(tag); script >= 0 {
+			break
+		}
+	}
+	if script < 0 || layout.Scripts[script].DefaultLangSys == nil {
+		return nil, false
+	}
+
+	langSys := layout.Scripts[script].DefaultLangSys
+	features := slices.Clone(langSys.FeatureIndices)
+	if langSys.RequiredFeatureIndex != 0xFFFF {
+		features = append(features, langSys.RequiredFeatureIndex)
+	}
+
+	var lookups []uint16
+	kern := false
+	for i, index := range features {
+		if int(index) >= len(layout.Features) {
+			continue
+		}
+		feature := layout.Features[index]
+		required := i == len(langSys.FeatureIndices)
+		if !required && !slices.Contains(defaultFeatures, feature.Tag) {
+			continue
+		}
+		kern = kern || feature.Tag == ot.MustNewTag("kern")
+		lookups = append(lookups, feature.LookupListIndices...)
+	}
+	slices.Sort(lookups)
+	return slices.Compact(lookups), kern
+}
+
+// simplePos is the position of a glyph in font units.
+type simplePos struct {
+	advance float64
+	xOffset float64
+	yOffset float64
+}
+
+// shapeSimple positions text in the face without the shaper if it only
+// consists of characters the face's default lookups leave alone, and
+// reports whether it did. Faces are shaped one run at a time under
+// facesMu.
+func shapeSimple(ctx *ShapingContext, base int, text string, face *font.Face) bool {
+	if ctx.Dir == DirRTL || len(ctx.Features) != 0 {
+		return false
+	}
+	s, ok := simpleFaces.Get(face)
+	if !ok {
+		s = newSimpleFace(face)
+		simpleFaces.Put(face, s)
+	}
+	if s == nil {
+		return false
+	}
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c < firstSimple || c > lastSimple || s.shaped[c-firstSimple] {
+			return false
+		}
+	}
+
+	glyphs := make([]font.GID, len(text))
+	pos := make([]simplePos, len(text))
+	for i := 0; i < len(text); i++ {
+		glyphs[i] = s.glyphs[text[i]-firstSimple]
+		pos[i].advance = s.advances[text[i]-firstSimple]
+	}
+	s.position(glyphs, pos)
+
+	for i := 0; i < len(text); i++ {
+		c := rune(text[i])
+		script := getScript(c)
+		xAdvance := Em(pos[i].advance / s.upem)
+		ctx.glyphs = append(ctx.glyphs, ShapedGlyph{
+			Font:          face,
+			GlyphID:       uint16(glyphs[i]),
+			XAdvance:      xAdvance,
+			XOffset:       Em(pos[i].xOffset / s.upem),
+			YOffset:       Em(pos[i].yOffset / s.upem),
+			Size:          ctx.Size,
+			Range:         Range{Start: base + i, End: base + i + 1},
+			SafeToBreak:   true,
+			Char:          c,
+			IsJustifiable: isJustifiable(c, script, xAdvance, [2]Em{0, 0}),
+			Script:        script,
+		})
+	}
+	return true
+}
+
+// position applies the pair positioning lookups and the kern table to the
+// glyphs like the shaper does.
+func (s *simpleFace) position(glyphs []font.GID, pos []simplePos) {
+	for _, lookup := range s.pairs {
+		for i := 0; i+1 < len(glyphs); {
+			next := i + 1
+			for _, subtable := range lookup {
+				if applied, skip := applyPair(subtable, glyphs, pos, i); applied {
+					if skip {
+						next = i + 2
+					}
+					break
+				}
+			}
+			i = next
+		}
+	}
+
+	// The kern table splits each value between the pair, so that the
+	// second glyph moves by the full value.
+	for _, kerns := range s.kerns {
+		for i := 0; i+1 < len(glyphs); i++ {
+			if kern := float64(kerns.KernPair(glyphs[i], glyphs[i+1])); kern != 0 {
+				pos[i].advance += kern / 2
+				pos[i+1].advance += kern / 2
+				pos[i+1].xOffset += kern / 2
+			}
+		}
+	}
+}
+
+// applyPair applies a pair positioning subtable to the glyph at i and the
+// one after it. It reports whether the subtable applied, and whether the
+// second glyph is done with, as it is when the subtable positions it.
+func applyPair(pair tables.PairPos, glyphs []font.GID, pos []simplePos, i int) (bool, bool) {
+	first, second := tables.GlyphID(glyphs[i]), tables.GlyphID(glyphs[i+1])
+	var format2 tables.ValueFormat
+	var record1, record2 tables.ValueRecord
+	switch data := pair.Data.(type) {
+	case tables.PairPosData1:
+		index, ok := data.Cov().Index(first)
+		if !ok {
+			return false, false
+		}
+		record, ok := data.PairSets[index].FindGlyph(second)
+		if !ok {
+			return false, false
+		}
+		format2, record1, record2 = data.ValueFormat2, record.ValueRecord1, record.ValueRecord2
+	case tables.PairPosData2:
+		if _, ok := data.Cov().Index(first); !ok {
+			return false, false
+		}
+		class2, ok := data.ClassDef2.Class(second)
+		if !ok {
+			return false, false
+		}
+		class1, _ := data.ClassDef1.Class(first)
+		record := data.Record(class1, class2)
+		format2, record1, record2 = data.ValueFormat2, record.ValueRecord1, record.ValueRecord2
+	default:
+		return false, false
+	}
+	pos[i].advance += float64(record1.XAdvance)
+	pos[i].xOffset += float64(record1.XPlacement)
+	pos[i].yOffset += float64(record1.YPlacement)
+	pos[i+1].advance += float64(record2.XAdvance)
+	pos[i+1].xOffset += float64(record2.XPlacement)
+	pos[i+1].yOffset += float64(record2.YPlacement)
+	return true, format2 != 0
+}
@@ -0,0 +1,96 @@
+package inline
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/shaping"
+)
+
+// shapeGlyphs shapes a segment like Shape does, with or without the fast
+// path for simple text.
+func shapeGlyphs(ctx *ShapingContext, text string, simple bool) []ShapedGlyph {
+	face := ctx.Faces[0]
+	facesMu.Lock()
+	defer facesMu.Unlock()
+	if !simple {
+		simpleFaces.Put(face, nil)
+		defer simpleFaces.Remove(face)
+	}
+	ctx.glyphs = ctx.glyphs[:0]
+	ctx.used = ctx.used[:0]
+	shapeSegment(ctx, 3, text)
+	return append([]ShapedGlyph(nil), ctx.glyphs...)
+}
+
+func TestShapeSimple(t *testing.T) {
+	face := goRegular(t)
+	ctx := NewShapingContext([]*font.Face{face}, 10.5)
+	text := "Hello, World! (1 + 2 = 3) ~{x}_"
+
+	fast := shapeGlyphs(ctx, text, true)
+	full := shapeGlyphs(ctx, text, false)
+	if len(fast) != len(text) || len(full) != len(text) {
+		t.Fatalf("got %d and %d glyphs for %d characters", len(fast), len(full), len(text))
+	}
+	for i := range fast {
+		a, b := fast[i], full[i]
+		if a.GlyphID != b.GlyphID || a.Char != b.Char || a.Range != b.Range ||
+			math.Abs(float64(a.XAdvance-b.XAdvance)) > 1e-9 || a.XOffset != b.XOffset || a.YOffset != b.YOffset ||
+			a.IsJustifiable != b.IsJustifiable || a.Script != b.Script {
+			t.Errorf("glyph %d: fast path %+v, shaper %+v", i, a, b)
+		}
+	}
+
+	// Advances are in em, independent of the text size.
+	glyph, _ := face.NominalGlyph('H')
+	want := Em(float64(face.HorizontalAdvance(glyph)) / float64(face.Upem()))
+	if math.Abs(float64(full[0].XAdvance-want)) > 1e-9 {
+		t.Errorf("advance of H = %v, want %v", full[0].XAdvance, want)
+	}
+}
+
+func TestShapeSimpleFallsBack(t *testing.T) {
+	face := goRegular(t)
+	for _, tt := range []struct {
+		name string
+		text string
+		dir  Dir
+		feat bool
+	}{
+		{"non-ASCII", "héllo", DirLTR, false},
+		{"tab", "a\tb", DirLTR, false},
+		{"right-to-left", "hello", DirRTL, false},
+		{"features", "hello", DirLTR, true},
+	} {
+		ctx := NewShapingContext([]*font.Face{face}, 10)
+		ctx.Dir = tt.dir
+		if tt.feat {
+			ctx.Features = []shaping.FontFeature{featureOn("tnum")}
+		}
+		if shapeSimple(ctx, 0, tt.text, face) {
+			t.Errorf("%s: took the fast path", tt.name)
+		}
+	}
+}
+
+func TestSimpleFaceCacheCapacity(t *testing.T) {
+	defer SetSimpleFaceCacheCapacity(DefaultSimpleFaceCacheCapacity)
+	SetSimpleFaceCacheCapacity(2)
+
+	// Every parse is a new face, as with fonts loaded by separate worlds.
+	for range 3 {
+		face := goRegular(t)
+		ctx := NewShapingContext([]*font.Face{face}, 10)
+		facesMu.Lock()
+		simple := shapeSimple(ctx, 0, "hello", face)
+		facesMu.Unlock()
+		if !simple {
+			t.Fatal("plain text did not take the fast path")
+		}
+	}
+	if n := simpleFaces.Len(); n != 2 {
+		t.Errorf("cache holds %d faces, want 2", n)
+	}
+}
@@ -0,0 +1,234 @@
+package inline
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/shaping"
+)
+
+// ScriptKind distinguishes superscripts from subscripts.
+type ScriptKind int
+
+const (
+	ScriptSuper ScriptKind = iota // Raised above the baseline
+	ScriptSub                     // Lowered below the baseline
+)
+
+// String returns the name of the script kind.
+func (k ScriptKind) String() string {
+	if k == ScriptSub {
+		return "subscripts"
+	}
+	return "superscripts"
+}
+
+// feature returns the OpenType feature that selects the script glyphs.
+func (k ScriptKind) feature() string {
+	if k == ScriptSub {
+		return "subs"
+	}
+	return "sups"
+}
+
+// ScriptShift configures text as superscript or subscript.
+// Matches Rust: typst_library::text::ShiftSettings
+type ScriptShift struct {
+	Kind ScriptKind
+	// Typographic uses the script glyphs of the font, selected with the
+	// sups or subs feature, if the font has them.
+	Typographic bool
+	// Size is the text size of synthesized scripts. Zero selects the size
+	// the font recommends, or 60% of the text size.
+	Size Abs
+	// Baseline is the baseline shift of synthesized scripts. Positive
+	// values move the text down. Nil selects the shift the font
+	// recommends, or -0.5em for superscripts and 0.2em for subscripts.
+	Baseline *Abs
+}
+
+// smallCapsScale is the size of synthesized small capitals relative to the
+// text size, if the font does not tell its x-height and cap height.
+const smallCapsScale = 0.7
+
+// ShapeSynthesized shapes text like Shape, and applies the small caps and
+// the script shift of the context. They use the smcp, sups, and subs
+// features of the first font if it has them. Otherwise, small caps are
+// synthesized from capitals at a smaller size and scripts from the text at
+// a smaller size and shifted baseline, and a warning for each synthesized
+// feature is returned.
+func ShapeSynthesized(ctx *ShapingContext, base int, text string, dir Dir, lang Lang, region *Region) ([]*ShapedText, []string) {
+	var warnings []string
+	var face *font.Face
+	if len(ctx.Faces) > 0 {
+		face = ctx.Faces[0]
+	}
+
+	shaper := ctx.derive()
+	if script := ctx.Script; script != nil {
+		tag := script.Kind.feature()
+		if script.Typographic && HasFeature(face, tag) {
+			shaper.Features = append(shaper.Features, featureOn(tag))
+		} else {
+			if script.Typographic {
+				warnings = append(warnings, fmt.Sprintf("font has no %s feature, synthesizing %s", tag, script.Kind))
+			}
+			size, baseline := scriptMetrics(face, *script, ctx.Size)
+			shaper.Shift += baseline
+			shaper.Size = size
+		}
+	}
+
+	if !ctx.SmallCaps {
+		return []*ShapedText{Shape(shaper, base, text, dir, lang, region)}, warnings
+	}
+	if HasFeature(face, "smcp") {
+		shaper.Features = append(shaper.Features, featureOn("smcp"))
+		return []*ShapedText{Shape(shaper, base, text, dir, lang, region)}, warnings
+	}
+
+	warnings = append(warnings, "font has no smcp feature, synthesizing small capitals")
+	capitals := shaper.derive()
+	capitals.Size = Abs(float64(shaper.Size) * smallCapsRatio(face))
+
+	var results []*ShapedText
+	for _, run := range caseRuns(text) {
+		runText := text[run.Start:run.End]
+		if !run.lower {
+			results = append(results, Shape(shaper, base+run.Start, runText, dir, lang, region))
+			continue
+		}
+		upper, offsets := upperRunes(runText)
+		shaped := Shape(capitals, base+run.Start, upper, dir, lang, region)
+		// The glyph ranges point into the capitals; move them back to the
+		// original text.
+		for i := range shaped.Glyphs.inner {
+			g := &shaped.Glyphs.inner[i]
+			g.Range.Start = base + run.Start + offsets[g.Range.Start-base-run.Start]
+			g.Range.End = base + run.Start + offsets[g.Range.End-base-run.Start]
+		}
+		shaped.Text = runText
+		results = append(results, shaped)
+	}
+	return results, warnings
+}
+
+// derive returns a copy of the context for shaping with other settings.
+func (ctx *ShapingContext) derive() *ShapingContext {
+	return &ShapingContext{
+		Shaper:    ctx.Shaper,
+		Faces:     ctx.Faces,
+		Size:      ctx.Size,
+		Variant:   ctx.Variant,
+		Features:  append([]shaping.FontFeature(nil), ctx.Features...),
+		Fallback:  ctx.Fallback,
+		Dir:       ctx.Dir,
+		Tracking:  ctx.Tracking,
+		Spacing:   ctx.Spacing,
+		SpaceAbs:  ctx.SpaceAbs,
+		Shift:     ctx.Shift,
+		SmallCaps: ctx.SmallCaps,
+		Script:    ctx.Script,
+	}
+}
+
+// HasFeature reports whether a font can substitute glyphs with the
+// OpenType feature of the given tag.
+func HasFeature(face *font.Face, tag string) bool {
+	if face == nil || face.Font == nil || len(tag) != 4 {
+		return false
+	}
+	_, ok := face.Font.GSUB.FindFeatureIndex(ot.MustNewTag(tag))
+	return ok
+}
+
+// featureOn returns an enabled feature.
+func featureOn(tag string) shaping.FontFeature {
+	return shaping.FontFeature{Tag: ot.MustNewTag(tag), Value: 1}
+}
+
+// scriptMetrics returns the text size and the baseline shift of
+// synthesized scripts in text of the given size. Unless set explicitly,
+// they come from the font's OS/2 table, falling back to the defaults of
+// Typst.
+// Matches Rust: typst_library::text::shift::ScriptMetrics
+func scriptMetrics(face *font.Face, script ScriptShift, textSize Abs) (Abs, Abs) {
+	size, baseline := 0.6, Em(-0.5)
+	if script.Kind == ScriptSub {
+		baseline = 0.2
+	}
+	if face != nil && face.Upem() != 0 {
+		upem := float64(face.Upem())
+		ySize := font.SuperscriptEmYSize
+		if script.Kind == ScriptSub {
+			ySize = font.SubscriptEmYSize
+			if offset := face.LineMetric(font.SubscriptEmYOffset); offset > 0 {
+				baseline = Em(float64(offset) / upem)
+			}
+		}
+		if s := float64(face.LineMetric(ySize)) / upem; s > 0 && s < 1 {
+			size = s
+		}
+	}
+	scaled, shift := Abs(float64(textSize)*size), baseline.At(textSize)
+	if script.Size > 0 {
+		scaled = script.Size
+	}
+	if script.Baseline != nil {
+		shift = *script.Baseline
+	}
+	return scaled, shift
+}
+
+// smallCapsRatio returns the size of synthesized small capitals relative
+// to the text size, so that their height matches the x-height.
+func smallCapsRatio(face *font.Face) float64 {
+	if face == nil {
+		return smallCapsScale
+	}
+	capHeight := face.LineMetric(font.CapHeight)
+	xHeight := face.LineMetric(font.XHeight)
+	if capHeight <= 0 || xHeight <= 0 || xHeight >= capHeight {
+		return smallCapsScale
+	}
+	return float64(xHeight / capHeight)
+}
+
+// caseRun is a range of text whose letters are all lowercase or none of
+// them is.
+type caseRun struct {
+	Range
+	lower bool
+}
+
+// caseRuns splits text into runs of lowercase letters, which synthesized
+// small caps turn into small capitals, and runs of other characters.
+func caseRuns(text string) []caseRun {
+	var runs []caseRun
+	for i, r := range text {
+		lower := unicode.IsLower(r) && unicode.ToUpper(r) != r
+		if n := len(runs); n > 0 && runs[n-1].lower == lower {
+			runs[n-1].End = i + utf8.RuneLen(r)
+			continue
+		}
+		runs = append(runs, caseRun{Range: Range{Start: i, End: i + utf8.RuneLen(r)}, lower: lower})
+	}
+	return runs
+}
+
+// upperRunes converts text to uppercase character by character. Offsets
+// maps each byte offset of a character boundary in the result to the
+// offset of the same boundary in text.
+func upperRunes(text string) (string, map[int]int) {
+	upper := make([]byte, 0, len(text))
+	offsets := map[int]int{}
+	for i, r := range text {
+		offsets[len(upper)] = i
+		upper = utf8.AppendRune(upper, unicode.ToUpper(r))
+	}
+	offsets[len(upper)] = len(text)
+	return string(upper), offsets
+}
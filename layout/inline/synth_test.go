@@ -0,0 +1,88 @@
+package inline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-text/typesetting/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// goRegular parses the Go Regular font, which has neither small capitals
+// nor script glyphs.
+func goRegular(t *testing.T) *font.Face {
+	t.Helper()
+	face, err := font.ParseTTF(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return face
+}
+
+func TestShapeSynthesizedSmallCaps(t *testing.T) {
+	face := goRegular(t)
+	if HasFeature(face, "smcp") {
+		t.Fatal("Go Regular has small capitals")
+	}
+	ctx := NewShapingContext([]*font.Face{face}, 10)
+	ctx.SmallCaps = true
+
+	texts, warnings := ShapeSynthesized(ctx, 4, "Hello World", DirLTR, "en", nil)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "smcp") {
+		t.Errorf("warnings = %q", warnings)
+	}
+	var got []string
+	for _, text := range texts {
+		got = append(got, text.Text)
+	}
+	if strings.Join(got, "|") != "H|ello| W|orld" {
+		t.Fatalf("runs = %q", got)
+	}
+
+	small := texts[1]
+	for _, g := range small.Glyphs.All() {
+		if g.Size >= 10 || g.Size <= 0 {
+			t.Errorf("small capital %q has size %v", g.Char, g.Size)
+		}
+		if g.Char != 'E' && g.Char != 'L' && g.Char != 'O' {
+			t.Errorf("glyph of %q, want a capital", g.Char)
+		}
+	}
+	// The ranges point into the original text at its base.
+	if first := small.Glyphs.All()[0]; first.Range != (Range{Start: 5, End: 6}) {
+		t.Errorf("range of first small capital = %v", first.Range)
+	}
+	if g := texts[0].Glyphs.All()[0]; g.Size != 10 {
+		t.Errorf("capital has size %v", g.Size)
+	}
+}
+
+func TestShapeSynthesizedScripts(t *testing.T) {
+	face := goRegular(t)
+	ctx := NewShapingContext([]*font.Face{face}, 10)
+	ctx.Shift = 1
+	ctx.Script = &ScriptShift{Kind: ScriptSuper}
+
+	texts, warnings := ShapeSynthesized(ctx, 0, "2", DirLTR, "en", nil)
+	if len(texts) != 1 || len(warnings) != 0 {
+		t.Fatalf("got %d texts, warnings %q", len(texts), warnings)
+	}
+	if size := texts[0].Glyphs.All()[0].Size; size >= 10 {
+		t.Errorf("superscript size = %v", size)
+	}
+	if texts[0].Shift >= 1 {
+		t.Errorf("superscript shift = %v, want raised", texts[0].Shift)
+	}
+
+	// Typographic scripts fall back to synthesized ones with a warning.
+	baseline := Abs(3)
+	ctx.Script = &ScriptShift{Kind: ScriptSub, Typographic: true, Size: 5, Baseline: &baseline}
+	texts, warnings = ShapeSynthesized(ctx, 0, "2", DirLTR, "en", nil)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "subs") {
+		t.Errorf("warnings = %q", warnings)
+	}
+	if g := texts[0].Glyphs.All()[0]; g.Size != 5 || texts[0].Shift != 4 {
+		t.Errorf("subscript size %v, shift %v, want 5 and 4", g.Size, texts[0].Shift)
+	}
+}
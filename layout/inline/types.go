@@ -79,11 +79,19 @@ func (*FractionalItem) Textual() string {
 
 // InlineFrameItem represents layouted inline-level content.
 type InlineFrameItem struct {
+	// Frame is the laid out content. It is aligned on the line's baseline
+	// with its own baseline.
+	Frame *FinalFrame
 	width Abs
 }
 
 func (*InlineFrameItem) isItem() {}
 
+// NewInlineFrameItem creates an item for laid out inline-level content.
+func NewInlineFrameItem(frame *FinalFrame) *InlineFrameItem {
+	return &InlineFrameItem{Frame: frame, width: frame.Size.Width}
+}
+
 // NaturalWidth returns the frame width.
 func (f *InlineFrameItem) NaturalWidth() Abs {
 	return f.width
@@ -305,6 +313,9 @@ func DefaultCosts() Costs {
 type Config struct {
 	// Justify indicates whether to justify text.
 	Justify bool
+	// JustifyLastLine indicates whether to also justify the last line of
+	// a justified paragraph.
+	JustifyLastLine bool
 	// Linebreaks is the line breaking algorithm to use.
 	Linebreaks layout.Linebreaks
 	// FirstLineIndent is the indent for the first line.
@@ -325,6 +336,13 @@ type Config struct {
 	Fallback bool
 	// CJKLatinSpacing indicates whether to add CJK-Latin spacing.
 	CJKLatinSpacing bool
+	// Overhang indicates whether punctuation at the start and end of
+	// lines hangs into the margin, so that the text edges look straight.
+	Overhang bool
+	// TopEdge is the top edge of text, which determines line heights.
+	TopEdge TextTopEdge
+	// BottomEdge is the bottom edge of text.
+	BottomEdge TextBottomEdge
 	// Costs for layout decisions.
 	Costs Costs
 }
@@ -337,6 +355,9 @@ type Preparation struct {
 	Items []PreparedItem
 	// Config is the shared configuration.
 	Config *Config
+
+	// breaks caches the line break opportunities of the text.
+	breaks []breakOpportunity
 }
 
 // PreparedItem associates a byte range with an item.
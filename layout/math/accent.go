@@ -0,0 +1,41 @@
+package math
+
+import (
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+// LayoutAccent lays out an accent centered above its base.
+//
+// The accent sits at the font's accent base height. Bases that are taller
+// than that push the accent up so that it clears them.
+//
+// Matches Rust: fn layout_accent in typst-layout/src/math/accent.rs
+func LayoutAccent(elem *libmath.AccentElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+
+	// The base of an accent is laid out in cramped style.
+	baseCtx := ctx.WithStyle(ctx.Style, true)
+	baseFrame := LayoutContent(&elem.Base, baseCtx, constants)
+
+	// Approximate accent glyph metrics (would use the glyph's bounds in
+	// production).
+	accentWidth := Em(0.5).At(fontSize)
+	accentHeight := Em(0.25).At(fontSize)
+
+	// Distance from the base's baseline to the accent's bottom edge.
+	accentBottom := max(baseFrame.Baseline, constants.AccentBaseHeight.At(fontSize))
+	ascent := max(baseFrame.Baseline, accentBottom+accentHeight)
+	width := max(baseFrame.Width(), accentWidth)
+
+	frame := &MathFrame{
+		Size:     Size{Width: width, Height: ascent + baseFrame.Height() - baseFrame.Baseline},
+		Baseline: ascent,
+	}
+	frame.PushFrame(Point{X: (width - baseFrame.Width()) / 2, Y: ascent - baseFrame.Baseline}, baseFrame)
+	frame.Push(Point{X: (width - accentWidth) / 2, Y: ascent - accentBottom - accentHeight}, TextItem{
+		Text:     string(elem.Accent),
+		FontSize: fontSize,
+	})
+
+	return frame
+}
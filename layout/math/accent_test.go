@@ -0,0 +1,56 @@
+package math
+
+import (
+	"testing"
+
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+func TestLayoutAccent(t *testing.T) {
+	accent := &libmath.AccentElem{Base: *mathText("x"), Accent: libmath.AccentHat}
+	ctx := &MathContext{FontSize: 12, Style: StyleText}
+	constants := DefaultMathConstants()
+
+	frame := LayoutAccent(accent, ctx, constants)
+	base := LayoutContent(mathText("x"), ctx, constants)
+
+	if len(frame.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(frame.Items))
+	}
+	if !approxEqual(frame.Width(), base.Width()) {
+		t.Errorf("width = %v, want %v", frame.Width(), base.Width())
+	}
+	if frame.Height() <= base.Height() {
+		t.Errorf("height %v should exceed base height %v", frame.Height(), base.Height())
+	}
+
+	item, ok := frame.Items[1].Item.(TextItem)
+	if !ok || item.Text != string(libmath.AccentHat) {
+		t.Errorf("expected accent text item, got %#v", frame.Items[1].Item)
+	}
+	// The accent clears the base.
+	accentBottom := frame.Items[1].Pos.Y + Em(0.25).At(12)
+	if accentBottom > frame.Baseline-base.Baseline+1e-9 {
+		t.Errorf("accent bottom %v overlaps base top %v", accentBottom, frame.Baseline-base.Baseline)
+	}
+}
+
+func TestLayoutAccentShortBase(t *testing.T) {
+	// A base shorter than the accent base height keeps the accent at that
+	// height.
+	constants := DefaultMathConstants()
+	constants.AccentBaseHeight = Em(2)
+	accent := &libmath.AccentElem{Base: mathSymbol("."), Accent: libmath.AccentDot}
+	ctx := &MathContext{FontSize: 10, Style: StyleText}
+
+	frame := LayoutAccent(accent, ctx, constants)
+	want := Em(2).At(10) + Em(0.25).At(10)
+	if !approxEqual(frame.Baseline, want) {
+		t.Errorf("baseline = %v, want %v", frame.Baseline, want)
+	}
+
+	elem := LayoutElement(accent, ctx, constants)
+	if !approxEqual(elem.Height(), frame.Height()) {
+		t.Errorf("LayoutElement height = %v, want %v", elem.Height(), frame.Height())
+	}
+}
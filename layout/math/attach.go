@@ -0,0 +1,268 @@
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
+)
+
+// LayoutAttach lays out a base with attachments.
+//
+// Depending on the base, the top and bottom attachments are placed either
+// as limits (above and below the base, like on a sum in display style) or
+// as scripts (to the right, like on an integral). The corner attachments
+// tl, bl, tr, and br are always placed as scripts.
+//
+// Matches Rust: fn layout_attach in typst-layout/src/math/attach.rs
+func LayoutAttach(elem *libmath.AttachElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	base, limits := attachBase(elem, ctx)
+
+	var baseFrame *MathFrame
+	if text, ok := operatorText(base); ok && ctx.Style == StyleDisplay && isLargeOperatorText(text) {
+		baseFrame = layoutLargeOperator(text, ctx, constants)
+	} else {
+		baseFrame = LayoutContent(base, ctx, constants)
+	}
+
+	tr, br := elem.TR, elem.BR
+	if limits {
+		baseFrame = layoutLimits(baseFrame, elem.T, elem.B, ctx, constants)
+	} else {
+		// Without limits, the top and bottom attachments become the
+		// right-hand scripts unless those are given explicitly.
+		if tr == nil {
+			tr = elem.T
+		}
+		if br == nil {
+			br = elem.B
+		}
+	}
+
+	return layoutScripts(baseFrame, elem.TL, elem.BL, tr, br, ctx, constants)
+}
+
+// attachBase unwraps limits() and scripts() around the base and decides
+// whether the top and bottom attachments are placed as limits.
+func attachBase(elem *libmath.AttachElem, ctx *MathContext) (*foundations.Content, bool) {
+	display := ctx.Style == StyleDisplay
+	if len(elem.Base.Elements) == 1 {
+		switch b := elem.Base.Elements[0].(type) {
+		case *libmath.LimitsElem:
+			return &b.Body, b.Inline || display
+		case *libmath.ScriptsElem:
+			return &b.Body, false
+		case *libmath.OpElem:
+			return &elem.Base, b.Limits && display
+		}
+	}
+	if text, ok := operatorText(&elem.Base); ok {
+		runes := []rune(text)
+		if len(runes) == 1 {
+			return &elem.Base, libmath.DefaultLimits(runes[0]).Active(display)
+		}
+	}
+	return &elem.Base, false
+}
+
+// operatorText returns the text of content consisting of a single symbol
+// or text element.
+func operatorText(content *foundations.Content) (string, bool) {
+	if content == nil || len(content.Elements) != 1 {
+		return "", false
+	}
+	return elementText(content.Elements[0])
+}
+
+// elementText returns the text of a symbol or text element.
+func elementText(elem foundations.ContentElement) (string, bool) {
+	switch e := elem.(type) {
+	case *foundations.SymbolElem:
+		return e.Text, true
+	case *libtext.TextElem:
+		return e.Body, true
+	}
+	return "", false
+}
+
+// isLargeOperatorText reports whether text is a single large operator.
+func isLargeOperatorText(text string) bool {
+	runes := []rune(text)
+	return len(runes) == 1 && libmath.IsLargeOperator(runes[0])
+}
+
+// layoutLargeOperator lays out a large operator in display style. The glyph
+// is enlarged to at least the font's display operator height and centered
+// on the math axis.
+func layoutLargeOperator(text string, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	size := fontSize
+	if minHeight := constants.DisplayOperatorMinHeight.At(fontSize); minHeight > size {
+		size = minHeight
+	}
+
+	width := Em(0.5).At(size) * Abs(len([]rune(text)))
+	frame := &MathFrame{
+		Size:     Size{Width: width, Height: size},
+		Baseline: size/2 + constants.AxisHeight.At(fontSize),
+	}
+	frame.Push(Point{X: 0, Y: 0}, TextItem{
+		Text:     text,
+		FontSize: size,
+	})
+	return frame
+}
+
+// layoutLimits stacks the top and bottom attachments centered above and
+// below the base.
+func layoutLimits(base *MathFrame, top, bottom *foundations.Content, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	topCtx := ctx.WithStyle(ctx.Style.ScriptStyle(), ctx.Cramped)
+	bottomCtx := ctx.WithStyle(ctx.Style.ScriptStyle(), true)
+
+	var topFrame, bottomFrame *MathFrame
+	if top != nil && len(top.Elements) > 0 {
+		topFrame = LayoutContent(top, topCtx, constants)
+	}
+	if bottom != nil && len(bottom.Elements) > 0 {
+		bottomFrame = LayoutContent(bottom, bottomCtx, constants)
+	}
+	if topFrame == nil && bottomFrame == nil {
+		return base
+	}
+
+	width := base.Width()
+	var topHeight, bottomHeight Abs
+	if topFrame != nil {
+		width = max(width, topFrame.Width())
+		// Distance from the base's top edge to the top limit's baseline.
+		rise := max(
+			constants.UpperLimitBaselineRiseMin.At(fontSize),
+			constants.UpperLimitGapMin.At(fontSize)+topFrame.Height()-topFrame.Baseline,
+		)
+		topHeight = rise + topFrame.Baseline
+	}
+	if bottomFrame != nil {
+		width = max(width, bottomFrame.Width())
+		// Distance from the base's bottom edge to the bottom limit's baseline.
+		drop := max(
+			constants.LowerLimitBaselineDropMin.At(fontSize),
+			constants.LowerLimitGapMin.At(fontSize)+bottomFrame.Baseline,
+		)
+		bottomHeight = drop + bottomFrame.Height() - bottomFrame.Baseline
+	}
+
+	frame := &MathFrame{
+		Size:     Size{Width: width, Height: topHeight + base.Height() + bottomHeight},
+		Baseline: topHeight + base.Baseline,
+	}
+	if topFrame != nil {
+		frame.PushFrame(Point{X: (width - topFrame.Width()) / 2, Y: 0}, topFrame)
+	}
+	frame.PushFrame(Point{X: (width - base.Width()) / 2, Y: topHeight}, base)
+	if bottomFrame != nil {
+		frame.PushFrame(Point{
+			X: (width - bottomFrame.Width()) / 2,
+			Y: frame.Height() - bottomFrame.Height(),
+		}, bottomFrame)
+	}
+	return frame
+}
+
+// layoutScripts places the corner attachments around an already laid out
+// base. Superscripts and subscripts on the same side share their shifts,
+// which are increased if the two would come too close.
+func layoutScripts(base *MathFrame, tl, bl, tr, br *foundations.Content, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	supCtx := ctx.WithStyle(ctx.Style.ScriptStyle(), ctx.Cramped)
+	subCtx := ctx.WithStyle(ctx.Style.ScriptStyle(), true)
+
+	layout := func(content *foundations.Content, c *MathContext) *MathFrame {
+		if content == nil || len(content.Elements) == 0 {
+			return nil
+		}
+		return LayoutContent(content, c, constants)
+	}
+	tlFrame, trFrame := layout(tl, supCtx), layout(tr, supCtx)
+	blFrame, brFrame := layout(bl, subCtx), layout(br, subCtx)
+	if tlFrame == nil && trFrame == nil && blFrame == nil && brFrame == nil {
+		return base
+	}
+
+	supShift := constants.SuperscriptShiftUp.At(fontSize)
+	if ctx.Cramped {
+		supShift = constants.SuperscriptShiftUpCramped.At(fontSize)
+	}
+	subShift := constants.SubscriptShiftDown.At(fontSize)
+
+	// Make sure that superscripts and subscripts do not collide.
+	var supDescent, subAscent Abs
+	for _, f := range []*MathFrame{tlFrame, trFrame} {
+		if f != nil {
+			supDescent = max(supDescent, f.Height()-f.Baseline)
+		}
+	}
+	for _, f := range []*MathFrame{blFrame, brFrame} {
+		if f != nil {
+			subAscent = max(subAscent, f.Baseline)
+		}
+	}
+	if (tlFrame != nil || trFrame != nil) && (blFrame != nil || brFrame != nil) {
+		gap := (supShift - supDescent) - (subAscent - subShift)
+		if minGap := constants.SubSuperscriptGapMin.At(fontSize); gap < minGap {
+			subShift += minGap - gap
+		}
+	}
+
+	// Compute the extents relative to the base's baseline.
+	ascent := base.Baseline
+	descent := base.Height() - base.Baseline
+	var leftWidth, rightWidth Abs
+	for _, f := range []*MathFrame{tlFrame, trFrame} {
+		if f != nil {
+			ascent = max(ascent, supShift+f.Baseline)
+			descent = max(descent, f.Height()-f.Baseline-supShift)
+		}
+	}
+	for _, f := range []*MathFrame{blFrame, brFrame} {
+		if f != nil {
+			ascent = max(ascent, f.Baseline-subShift)
+			descent = max(descent, subShift+f.Height()-f.Baseline)
+		}
+	}
+	for _, f := range []*MathFrame{tlFrame, blFrame} {
+		if f != nil {
+			leftWidth = max(leftWidth, f.Width())
+		}
+	}
+	for _, f := range []*MathFrame{trFrame, brFrame} {
+		if f != nil {
+			rightWidth = max(rightWidth, f.Width())
+		}
+	}
+	if rightWidth > 0 {
+		rightWidth += constants.SpaceAfterScript.At(fontSize)
+	}
+
+	frame := &MathFrame{
+		Size:     Size{Width: leftWidth + base.Width() + rightWidth, Height: ascent + descent},
+		Baseline: ascent,
+	}
+	frame.PushFrame(Point{X: leftWidth, Y: ascent - base.Baseline}, base)
+
+	rightX := leftWidth + base.Width()
+	if tlFrame != nil {
+		// Left scripts are aligned towards the base.
+		frame.PushFrame(Point{X: leftWidth - tlFrame.Width(), Y: ascent - supShift - tlFrame.Baseline}, tlFrame)
+	}
+	if blFrame != nil {
+		frame.PushFrame(Point{X: leftWidth - blFrame.Width(), Y: ascent + subShift - blFrame.Baseline}, blFrame)
+	}
+	if trFrame != nil {
+		frame.PushFrame(Point{X: rightX, Y: ascent - supShift - trFrame.Baseline}, trFrame)
+	}
+	if brFrame != nil {
+		frame.PushFrame(Point{X: rightX, Y: ascent + subShift - brFrame.Baseline}, brFrame)
+	}
+
+	return frame
+}
@@ -0,0 +1,148 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
+)
+
+func mathText(text string) *foundations.Content {
+	return &foundations.Content{
+		Elements: []foundations.ContentElement{&libtext.TextElem{Body: text}},
+	}
+}
+
+func mathSymbol(text string) foundations.Content {
+	return foundations.Content{
+		Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: text}},
+	}
+}
+
+func TestLayoutAttachLimitsInDisplay(t *testing.T) {
+	// sum_(i=0)^n in display style places the limits above and below.
+	attach := &libmath.AttachElem{
+		Base: mathSymbol("∑"),
+		T:    mathText("n"),
+		B:    mathText("i=0"),
+	}
+	constants := DefaultMathConstants()
+
+	display := LayoutAttach(attach, &MathContext{FontSize: 12, Style: StyleDisplay}, constants)
+	inline := LayoutAttach(attach, &MathContext{FontSize: 12, Style: StyleText}, constants)
+
+	op := layoutLargeOperator("∑", &MathContext{FontSize: 12, Style: StyleDisplay}, constants)
+	if display.Height() <= op.Height() {
+		t.Errorf("display height %v should exceed operator height %v", display.Height(), op.Height())
+	}
+
+	// With limits, the frame is no wider than the widest part.
+	bottom := LayoutContent(mathText("i=0"), &MathContext{FontSize: 12, Style: StyleScript}, constants)
+	if !approxEqual(display.Width(), max(op.Width(), bottom.Width())) {
+		t.Errorf("display width = %v, want %v", display.Width(), max(op.Width(), bottom.Width()))
+	}
+
+	// Inline, the attachments become scripts to the right of the base.
+	symbol := LayoutSymbol(&foundations.SymbolElem{Text: "∑"}, &MathContext{FontSize: 12, Style: StyleText})
+	if inline.Width() < symbol.Width()+bottom.Width() {
+		t.Errorf("inline width %v should place scripts beside the operator", inline.Width())
+	}
+}
+
+func TestLayoutAttachIntegralUsesScripts(t *testing.T) {
+	attach := &libmath.AttachElem{
+		Base: mathSymbol("∫"),
+		T:    mathText("b"),
+		B:    mathText("a"),
+	}
+	ctx := &MathContext{FontSize: 12, Style: StyleDisplay}
+	constants := DefaultMathConstants()
+
+	frame := LayoutAttach(attach, ctx, constants)
+	op := layoutLargeOperator("∫", ctx, constants)
+
+	if frame.Width() <= op.Width() {
+		t.Errorf("integral scripts should be placed to the right: width %v, operator %v", frame.Width(), op.Width())
+	}
+}
+
+func TestLayoutAttachLimitsElem(t *testing.T) {
+	base := foundations.Content{Elements: []foundations.ContentElement{
+		&libmath.LimitsElem{Body: *mathText("lim"), Inline: true},
+	}}
+	attach := &libmath.AttachElem{Base: base, B: mathText("x")}
+	ctx := &MathContext{FontSize: 12, Style: StyleText}
+	constants := DefaultMathConstants()
+
+	frame := LayoutAttach(attach, ctx, constants)
+	body := LayoutContent(mathText("lim"), ctx, constants)
+
+	if !approxEqual(frame.Width(), body.Width()) {
+		t.Errorf("limits width = %v, want %v", frame.Width(), body.Width())
+	}
+	if !approxEqual(frame.Baseline, body.Baseline) {
+		t.Errorf("limits baseline = %v, want %v", frame.Baseline, body.Baseline)
+	}
+	if frame.Height() <= body.Height() {
+		t.Errorf("limits height %v should exceed body height %v", frame.Height(), body.Height())
+	}
+}
+
+func TestLayoutAttachScriptsElem(t *testing.T) {
+	base := foundations.Content{Elements: []foundations.ContentElement{
+		&libmath.ScriptsElem{Body: mathSymbol("∑")},
+	}}
+	attach := &libmath.AttachElem{Base: base, B: mathText("i")}
+	ctx := &MathContext{FontSize: 12, Style: StyleDisplay}
+	constants := DefaultMathConstants()
+
+	frame := LayoutAttach(attach, ctx, constants)
+	op := layoutLargeOperator("∑", ctx, constants)
+
+	if frame.Width() <= op.Width() {
+		t.Errorf("scripts() should force scripts: width %v, operator %v", frame.Width(), op.Width())
+	}
+}
+
+func TestLayoutAttachCorners(t *testing.T) {
+	// Attachments on all four corners.
+	attach := &libmath.AttachElem{
+		Base: *mathText("X"),
+		TL:   mathText("1"),
+		BL:   mathText("2"),
+		TR:   mathText("3"),
+		BR:   mathText("4"),
+	}
+	ctx := &MathContext{FontSize: 12, Style: StyleText}
+	constants := DefaultMathConstants()
+
+	frame := LayoutAttach(attach, ctx, constants)
+	if len(frame.Items) != 5 {
+		t.Fatalf("expected 5 items, got %d", len(frame.Items))
+	}
+
+	script := LayoutContent(mathText("1"), &MathContext{FontSize: 12, Style: StyleScript}, constants)
+	if !approxEqual(frame.Items[0].Pos.X, script.Width()) {
+		t.Errorf("base x = %v, want %v", frame.Items[0].Pos.X, script.Width())
+	}
+
+	// Superscripts are placed above subscripts.
+	tl, bl := frame.Items[1], frame.Items[2]
+	if bl.Pos.Y <= tl.Pos.Y {
+		t.Errorf("subscript y %v should be below superscript y %v", bl.Pos.Y, tl.Pos.Y)
+	}
+}
+
+func TestLayoutLargeOperatorDisplay(t *testing.T) {
+	constants := DefaultMathConstants()
+	display := LayoutElement(&foundations.SymbolElem{Text: "∑"}, &MathContext{FontSize: 10, Style: StyleDisplay}, constants)
+	inline := LayoutElement(&foundations.SymbolElem{Text: "∑"}, &MathContext{FontSize: 10, Style: StyleText}, constants)
+
+	if !approxEqual(display.Height(), constants.DisplayOperatorMinHeight.At(10)) {
+		t.Errorf("display height = %v, want %v", display.Height(), constants.DisplayOperatorMinHeight.At(10))
+	}
+	if display.Height() <= inline.Height() {
+		t.Errorf("display operator %v should be larger than inline %v", display.Height(), inline.Height())
+	}
+}
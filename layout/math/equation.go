@@ -1,27 +1,179 @@
 package math
 
 import (
-	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/layout/inline"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
 )
 
 // LayoutEquation lays out an equation element.
 // This is the main entry point for equation layout.
-func LayoutEquation(elem *eval.EquationElement, fontSize Abs) *MathFrame {
+func LayoutEquation(elem *libmath.EquationElem, fontSize Abs) *MathFrame {
+	return LayoutEquationWithFont(elem, fontSize, nil)
+}
+
+// LayoutEquationWithFont lays out an equation element using the metrics,
+// glyph variants, and assemblies from the MATH table of the given font. A
+// nil font falls back to the default constants.
+func LayoutEquationWithFont(elem *libmath.EquationElem, fontSize Abs, mathFont *font.Font) *MathFrame {
 	// Determine the math style based on whether it's a block equation
 	style := StyleText
 	if elem.Block {
 		style = StyleDisplay
 	}
 
+	ctx, constants := equationContext(fontSize, style, mathFont)
+	return LayoutContent(&elem.Body, ctx, constants)
+}
+
+// equationContext creates the context and constants for laying out an
+// equation in the given style. The script sizes follow the font's MATH
+// table, if any.
+func equationContext(fontSize Abs, style MathStyle, mathFont *font.Font) (*MathContext, MathConstants) {
 	ctx := &MathContext{
 		FontSize: fontSize,
 		Style:    style,
 		Cramped:  false,
+		Font:     mathFont,
 	}
 
 	constants := DefaultMathConstants()
+	if mathFont != nil {
+		c := mathFont.MathConstants()
+		constants = MathConstantsFromFont(c)
+		if c != nil {
+			ctx.ScriptScale = float64(c.ScriptPercentScaleDown) / 100
+			ctx.ScriptScriptScale = float64(c.ScriptScriptPercentScaleDown) / 100
+		}
+	}
+	return ctx, constants
+}
 
-	return LayoutContent(&elem.Body, ctx, constants)
+// LayoutEquationInline lays out an equation that is part of a paragraph.
+// The equation is set in text style at the size of the surrounding text,
+// and split into parts after top-level relations and binary operators, at
+// which the paragraph may break lines. Each part's baseline is aligned on
+// the text baseline.
+//
+// Matches Rust: fn layout_equation_inline in typst-layout/src/math/mod.rs
+func LayoutEquationInline(elem *libmath.EquationElem, fontSize Abs, mathFont *font.Font) []inline.EquationPart {
+	ctx, constants := equationContext(fontSize, StyleText, mathFont)
+	elements := elem.Body.Elements
+	if len(elements) == 0 {
+		return nil
+	}
+
+	frames := make([]*MathFrame, len(elements))
+	for i, e := range elements {
+		frames[i] = LayoutElement(e, ctx, constants)
+	}
+	classes := resolveClasses(elements)
+	gaps := runGaps(elements, ctx)
+
+	var parts []inline.EquationPart
+	start := 0
+	for i := range elements {
+		last := i == len(elements)-1
+		if !last && !inlineBreakAfter(classes[i], classes[i+1]) {
+			continue
+		}
+		// The first frame of a part starts flush, so its gap is dropped.
+		partGaps := append([]Abs{0}, gaps[start+1:i+1]...)
+		frame := joinSpaced(frames[start:i+1], partGaps)
+		part := inline.EquationPart{
+			Frame:    frame,
+			Size:     inline.FinalSize{Width: frame.Width(), Height: frame.Height()},
+			Baseline: frame.Baseline,
+		}
+		if !last {
+			part.Space = gaps[i+1]
+		}
+		parts = append(parts, part)
+		start = i + 1
+	}
+	return parts
+}
+
+// inlineBreakAfter reports whether a line may break between two atoms of an
+// inline equation: after a relation or binary operator, unless a closing
+// delimiter, punctuation, or another relation follows.
+func inlineBreakAfter(class, next MathClass) bool {
+	if class != ClassRel && class != ClassBin {
+		return false
+	}
+	switch next {
+	case ClassRel, ClassClose, ClassPunct:
+		return false
+	}
+	return true
+}
+
+// LayoutEquationBlock lays out a block equation into the region.
+//
+// The equation body is centered horizontally. Without a number, the
+// equation spans the region if it expands horizontally and takes the width
+// of its body otherwise. If number is non-empty, it is placed at the start
+// or end of the region's width (depending on the element's number-align)
+// and vertically centered against the body. The body is shifted away from
+// the number if they would otherwise overlap. In a region of unbounded
+// width, a numbered equation leaves room for the number on both sides of
+// the body, so that the body stays centered.
+//
+// Matches Rust: fn layout_equation_block in typst-layout/src/math/mod.rs
+func LayoutEquationBlock(elem *libmath.EquationElem, fontSize Abs, region layout.Region, number string) *MathFrame {
+	body := LayoutEquation(elem, fontSize)
+
+	if number == "" {
+		width := region.Fit(layout.Size{Width: body.Width(), Height: body.Height()}).Width
+		frame := &MathFrame{
+			Size:     Size{Width: width, Height: body.Height()},
+			Baseline: body.Baseline,
+		}
+		frame.PushFrame(Point{X: (width - body.Width()) / 2, Y: 0}, body)
+		return frame
+	}
+
+	numFrame := LayoutText(&libtext.TextElem{Body: number}, &MathContext{
+		FontSize: fontSize,
+		Style:    StyleText,
+	})
+
+	width := region.Size.Width
+	if !width.IsFinite() {
+		width = body.Width() + 2*numFrame.Width()
+	}
+
+	// Keep the body centered in the full width, but never let it run into
+	// the number.
+	bodyX := (width - body.Width()) / 2
+	numX := width - numFrame.Width()
+	if elem.NumberAtStart() {
+		numX = 0
+		if bodyX < numFrame.Width() {
+			bodyX = numFrame.Width()
+		}
+	} else if bodyX+body.Width() > numX {
+		bodyX = numX - body.Width()
+		if bodyX < 0 {
+			bodyX = 0
+		}
+	}
+
+	height := body.Height()
+	if numFrame.Height() > height {
+		height = numFrame.Height()
+	}
+
+	frame := &MathFrame{
+		Size:     Size{Width: width, Height: height},
+		Baseline: (height-body.Height())/2 + body.Baseline,
+	}
+	frame.PushFrame(Point{X: bodyX, Y: (height - body.Height()) / 2}, body)
+	frame.PushFrame(Point{X: numX, Y: (height - numFrame.Height()) / 2}, numFrame)
+
+	return frame
 }
 
 // EquationLayoutResult contains the result of equation layout
@@ -37,7 +189,7 @@ type EquationLayoutResult struct {
 
 // LayoutEquationWithResult lays out an equation and returns a result
 // with additional metadata for rendering.
-func LayoutEquationWithResult(elem *eval.EquationElement, fontSize Abs) *EquationLayoutResult {
+func LayoutEquationWithResult(elem *libmath.EquationElem, fontSize Abs) *EquationLayoutResult {
 	frame := LayoutEquation(elem, fontSize)
 	return &EquationLayoutResult{
 		Frame:    frame,
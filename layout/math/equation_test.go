@@ -0,0 +1,154 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	"github.com/boergens/gotypst/library/model"
+	libtext "github.com/boergens/gotypst/library/text"
+)
+
+func blockEquation(text string) *libmath.EquationElem {
+	return &libmath.EquationElem{
+		Body: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: text},
+			},
+		},
+		Block: true,
+	}
+}
+
+// blockRegion returns the region of a block equation in a flow of the given
+// width.
+func blockRegion(width Abs) layout.Region {
+	return layout.NewRegion(layout.Size{Width: width, Height: layout.Inf()}, layout.Axes[bool]{X: true})
+}
+
+func TestLayoutEquationBlockCentered(t *testing.T) {
+	elem := blockEquation("x")
+	body := LayoutEquation(elem, Abs(12))
+
+	frame := LayoutEquationBlock(elem, Abs(12), blockRegion(200), "")
+
+	if frame.Width() != 200 {
+		t.Errorf("expected width 200, got %v", frame.Width())
+	}
+	if len(frame.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(frame.Items))
+	}
+	wantX := (Abs(200) - body.Width()) / 2
+	if got := frame.Items[0].Pos.X; got != wantX {
+		t.Errorf("expected body at x=%v, got %v", wantX, got)
+	}
+}
+
+func TestLayoutEquationBlockNumbered(t *testing.T) {
+	elem := blockEquation("x")
+	pattern, err := model.ParseNumberingPattern("(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	elem.Numbering = &model.Numbering{Pattern: pattern}
+
+	frame := LayoutEquationBlock(elem, Abs(12), blockRegion(200), pattern.Apply([]int{1}))
+
+	if len(frame.Items) != 2 {
+		t.Fatalf("expected body and number items, got %d", len(frame.Items))
+	}
+	num := frame.Items[1]
+	child, ok := num.Item.(ChildFrame)
+	if !ok {
+		t.Fatalf("expected number to be a child frame, got %T", num.Item)
+	}
+	if got, want := num.Pos.X+child.Frame.Width(), Abs(200); got != want {
+		t.Errorf("expected number to end at %v, got %v", want, got)
+	}
+
+	elem.NumberAlign = foundations.Str("start")
+	frame = LayoutEquationBlock(elem, Abs(12), blockRegion(200), "(1)")
+	if got := frame.Items[1].Pos.X; got != 0 {
+		t.Errorf("expected number at start, got x=%v", got)
+	}
+}
+
+func TestLayoutEquationBlockAvoidsNumber(t *testing.T) {
+	elem := blockEquation("abcdefghij")
+	body := LayoutEquation(elem, Abs(12))
+	// Wide enough for body and number side by side, but not for a
+	// centered body.
+	width := body.Width() + Abs(25)
+
+	frame := LayoutEquationBlock(elem, Abs(12), blockRegion(width), "(1)")
+
+	bodyEnd := frame.Items[0].Pos.X + body.Width()
+	if numX := frame.Items[1].Pos.X; bodyEnd > numX {
+		t.Errorf("body (ends at %v) overlaps number (starts at %v)", bodyEnd, numX)
+	}
+}
+
+func TestLayoutEquationBlockUnbounded(t *testing.T) {
+	elem := blockEquation("x")
+	body := LayoutEquation(elem, Abs(12))
+	unbounded := layout.NewRegion(layout.Size{Width: layout.Inf(), Height: layout.Inf()}, layout.Axes[bool]{X: true})
+
+	// Without a number, the equation takes the width of its body.
+	frame := LayoutEquationBlock(elem, Abs(12), unbounded, "")
+	if frame.Width() != body.Width() {
+		t.Errorf("expected width %v, got %v", body.Width(), frame.Width())
+	}
+
+	// With a number, it leaves room for the number on both sides.
+	frame = LayoutEquationBlock(elem, Abs(12), unbounded, "(1)")
+	num := frame.Items[1].Item.(ChildFrame).Frame
+	if want := body.Width() + 2*num.Width(); frame.Width() != want {
+		t.Errorf("expected width %v, got %v", want, frame.Width())
+	}
+	if got, want := frame.Items[0].Pos.X, num.Width(); got != want {
+		t.Errorf("expected body at x=%v, got %v", want, got)
+	}
+}
+
+func TestLayoutEquationInlineParts(t *testing.T) {
+	var elems []foundations.ContentElement
+	for _, text := range []string{"x", "=", "a", "+", "b"} {
+		elems = append(elems, &libtext.TextElem{Body: text})
+	}
+	elem := &libmath.EquationElem{Body: foundations.Content{Elements: elems}}
+
+	// Lines may break after the relation and the binary operator.
+	parts := LayoutEquationInline(elem, Abs(10), nil)
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(parts))
+	}
+	if parts[0].Space <= 0 || parts[1].Space <= 0 {
+		t.Errorf("expected spacing after the operators, got %v and %v", parts[0].Space, parts[1].Space)
+	}
+	if parts[2].Space != 0 {
+		t.Errorf("expected no spacing after the last part, got %v", parts[2].Space)
+	}
+
+	// The parts are as wide as the whole equation, minus the spacing at
+	// which they break.
+	whole := LayoutEquation(elem, Abs(10))
+	var width Abs
+	for _, part := range parts {
+		width += part.Size.Width + part.Space
+	}
+	if width != whole.Width() {
+		t.Errorf("expected parts to span %v, got %v", whole.Width(), width)
+	}
+}
+
+func TestFontSizeForStyleScale(t *testing.T) {
+	ctx := &MathContext{FontSize: 10}
+	if got := ctx.FontSizeForStyle(StyleScript); got != 7 {
+		t.Errorf("expected default script size 7, got %v", got)
+	}
+	ctx.ScriptScale = 0.8
+	if got := ctx.FontSizeForStyle(StyleScript); got != 8 {
+		t.Errorf("expected script size 8, got %v", got)
+	}
+}
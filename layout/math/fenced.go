@@ -0,0 +1,263 @@
+package math
+
+import (
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+const (
+	// delimiterWidth is the width of a delimiter laid out without a math
+	// font.
+	delimiterWidth = Em(0.3)
+	// delimShortfall is how much shorter than the wrapped content a
+	// stretched delimiter may be.
+	// Matches Rust: const DELIM_SHORT_FALL: Em = Em::new(0.1)
+	delimShortfall = Em(0.1)
+	// maxAssemblyRepeats bounds how often an assembly's extenders are
+	// repeated.
+	maxAssemblyRepeats = 1024
+)
+
+// LayoutLr lays out content between stretchable delimiters.
+//
+// The delimiters at the start and end of the body as well as the bodies of
+// mid() elements are stretched to the height of the remaining content,
+// measured symmetrically around the math axis and scaled by the element's
+// size.
+//
+// Matches Rust: fn layout_lr in typst-layout/src/math/lr.rs
+func LayoutLr(elem *libmath.LrElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	elements := flattenContent(&elem.Body)
+
+	// Lay out everything that is not stretched to determine the height.
+	frames := make([]*MathFrame, len(elements))
+	stretched := make([]string, len(elements))
+	for i, el := range elements {
+		if mid, ok := el.(*libmath.MidElem); ok {
+			if text, ok := operatorText(&mid.Body); ok {
+				stretched[i] = text
+				continue
+			}
+			frames[i] = LayoutContent(&mid.Body, ctx, constants)
+			continue
+		}
+		if i == 0 || i == len(elements)-1 {
+			if text, ok := elementText(el); ok && isDelimiterText(text) {
+				stretched[i] = text
+				continue
+			}
+		}
+		frames[i] = LayoutElement(el, ctx, constants)
+	}
+
+	axis := constants.AxisHeight.At(fontSize)
+	var maxExtent Abs
+	for _, f := range frames {
+		if f != nil {
+			maxExtent = max(maxExtent, f.Baseline-axis, f.Height()-f.Baseline+axis)
+		}
+	}
+	height := 2 * maxExtent
+	if elem.Size != nil {
+		height = Abs(elem.Size.Abs.Points) + Abs(elem.Size.Rel.Value)*height
+	}
+
+	for i, text := range stretched {
+		if text != "" {
+			frames[i] = stretchDelimiter(text, height, ctx, constants)
+		}
+	}
+
+	return joinSpaced(frames, runGaps(elements, ctx))
+}
+
+// layoutDelimiters surrounds a frame with opening and closing delimiters
+// stretched to its height. An empty delimiter takes up no space.
+func layoutDelimiters(body *MathFrame, open, close string, ctx *MathContext, constants MathConstants) *MathFrame {
+	if open == "" && close == "" {
+		return body
+	}
+
+	frames := make([]*MathFrame, 0, 3)
+	if open != "" {
+		frames = append(frames, stretchDelimiter(open, body.Height(), ctx, constants))
+	}
+	frames = append(frames, body)
+	if close != "" {
+		frames = append(frames, stretchDelimiter(close, body.Height(), ctx, constants))
+	}
+	return joinHorizontal(frames)
+}
+
+// flattenContent returns the elements of content with nested sequences
+// expanded.
+func flattenContent(content *foundations.Content) []foundations.ContentElement {
+	var elements []foundations.ContentElement
+	for _, el := range content.Elements {
+		if seq, ok := el.(*foundations.SequenceElem); ok {
+			for i := range seq.Children {
+				elements = append(elements, flattenContent(&seq.Children[i])...)
+			}
+			continue
+		}
+		elements = append(elements, el)
+	}
+	return elements
+}
+
+// isDelimiterText reports whether text is a single opening, closing, or
+// fence character that can be stretched.
+func isDelimiterText(text string) bool {
+	runes := []rune(text)
+	if len(runes) != 1 {
+		return false
+	}
+	switch runes[0] {
+	case '(', ')', '[', ']', '{', '}', '|', '‖', '/', '\\',
+		'⟨', '⟩', '⟦', '⟧', '⟪', '⟫', '⌈', '⌉', '⌊', '⌋', '⌜', '⌝', '⌞', '⌟',
+		'⦃', '⦄', '⦇', '⦈', '⟮', '⟯', '⦅', '⦆', '⎰', '⎱':
+		return true
+	}
+	return false
+}
+
+// stretchDelimiter lays out a delimiter so that it covers target minus the
+// permitted shortfall, centered on the math axis.
+func stretchDelimiter(text string, target Abs, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	frame := stretchGlyph(text, target-delimShortfall.At(fontSize), ctx)
+	frame.Baseline = frame.Height()/2 + constants.AxisHeight.At(fontSize)
+	return frame
+}
+
+// stretchGlyph lays out a glyph that is at least target high. With a math
+// font, the smallest sufficient variant from the font's MATH table is used,
+// falling back to an assembly of parts. Without one, the glyph is scaled.
+//
+// Matches Rust: fn stretch_glyph in typst-layout/src/math/stretch.rs
+func stretchGlyph(text string, target Abs, ctx *MathContext) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+
+	if runes := []rune(text); ctx.Font != nil && len(runes) == 1 {
+		if frame := stretchWithFont(ctx.Font, runes[0], target, fontSize); frame != nil {
+			return frame
+		}
+	}
+
+	size := max(fontSize, target)
+	frame := &MathFrame{
+		Size:     Size{Width: delimiterWidth.At(size), Height: size},
+		Baseline: size * 0.8,
+	}
+	frame.Push(Point{X: 0, Y: 0}, TextItem{Text: text, FontSize: size})
+	return frame
+}
+
+// stretchWithFont grows a glyph using the vertical constructions of the
+// font's MATH table. It returns nil if the font has none for the glyph.
+func stretchWithFont(f *font.Font, r rune, target, fontSize Abs) *MathFrame {
+	glyph, ok := f.GlyphIndex(r)
+	if !ok {
+		return nil
+	}
+	variants := f.MathVariants()
+	construction := variants.Vertical(glyph)
+	if construction == nil || variants.UnitsPerEm == 0 {
+		return nil
+	}
+	scale := fontSize / Abs(variants.UnitsPerEm)
+
+	var best *font.GlyphVariant
+	for i := range construction.Variants {
+		best = &construction.Variants[i]
+		if Abs(best.Advance)*scale >= target {
+			return glyphFrame(f, best.Glyph, Abs(best.Advance)*scale, scale, fontSize)
+		}
+	}
+	if len(construction.Assembly) > 0 {
+		return assembleGlyph(f, construction.Assembly, Abs(variants.MinConnectorOverlap)*scale, target, scale, fontSize)
+	}
+	if best != nil {
+		return glyphFrame(f, best.Glyph, Abs(best.Advance)*scale, scale, fontSize)
+	}
+	return nil
+}
+
+// glyphFrame creates a frame holding a single glyph of the given height.
+func glyphFrame(f *font.Font, glyph uint16, height, scale, fontSize Abs) *MathFrame {
+	frame := &MathFrame{
+		Size:     Size{Width: Abs(f.GlyphAdvance(glyph)) * scale, Height: height},
+		Baseline: height,
+	}
+	frame.Push(Point{X: 0, Y: 0}, GlyphItem{Font: f, Glyph: glyph, FontSize: fontSize})
+	return frame
+}
+
+// assembleGlyph builds a glyph of at least the target height from assembly
+// parts, which are listed bottom to top. Extenders are repeated as often as
+// needed, and the overlap between connecting parts is spread evenly.
+//
+// Matches Rust: fn assemble in typst-layout/src/math/stretch.rs
+func assembleGlyph(f *font.Font, parts []font.GlyphPart, minOverlap, target, scale, fontSize Abs) *MathFrame {
+	var seq []font.GlyphPart
+	var full Abs
+	for repeat := 0; repeat < maxAssemblyRepeats; repeat++ {
+		seq = seq[:0]
+		hasExtender := false
+		for _, part := range parts {
+			count := 1
+			if part.Extender {
+				count = repeat
+				hasExtender = true
+			}
+			for i := 0; i < count; i++ {
+				seq = append(seq, part)
+			}
+		}
+
+		full = 0
+		for _, part := range seq {
+			full += Abs(part.FullAdvance) * scale
+		}
+		if !hasExtender || len(seq) < 2 || full-Abs(len(seq)-1)*minOverlap >= target {
+			break
+		}
+	}
+	if len(seq) == 0 {
+		return &MathFrame{}
+	}
+
+	// Spread the overlap evenly, staying within what the connectors allow.
+	overlap := minOverlap
+	if len(seq) > 1 {
+		maxOverlap := Abs(-1)
+		for i := 1; i < len(seq); i++ {
+			allowed := Abs(min(seq[i-1].EndConnector, seq[i].StartConnector)) * scale
+			if maxOverlap < 0 || allowed < maxOverlap {
+				maxOverlap = allowed
+			}
+		}
+		maxOverlap = max(maxOverlap, minOverlap)
+		overlap = min(max((full-target)/Abs(len(seq)-1), minOverlap), maxOverlap)
+	}
+	height := full - Abs(len(seq)-1)*overlap
+
+	var width Abs
+	for _, part := range seq {
+		width = max(width, Abs(f.GlyphAdvance(part.Glyph))*scale)
+	}
+
+	frame := &MathFrame{
+		Size:     Size{Width: width, Height: height},
+		Baseline: height,
+	}
+	bottom := height
+	for _, part := range seq {
+		advance := Abs(part.FullAdvance) * scale
+		frame.Push(Point{X: 0, Y: bottom - advance}, GlyphItem{Font: f, Glyph: part.Glyph, FontSize: fontSize})
+		bottom += overlap - advance
+	}
+	return frame
+}
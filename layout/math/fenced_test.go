@@ -0,0 +1,153 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+// delimiterText returns the text of a delimiter laid out without a font.
+func delimiterText(entry FrameEntry) string {
+	child, ok := entry.Item.(ChildFrame)
+	if !ok || len(child.Frame.Items) != 1 {
+		return ""
+	}
+	text, _ := child.Frame.Items[0].Item.(TextItem)
+	return text.Text
+}
+
+func TestLayoutLrStretchesDelimiters(t *testing.T) {
+	frac := &libmath.FracElem{Num: *mathText("a"), Denom: *mathText("b")}
+	lr := &libmath.LrElem{Body: libmath.Delimited(
+		foundations.Content{Elements: []foundations.ContentElement{frac}}, "(", ")",
+	)}
+	ctx := &MathContext{FontSize: 12, Style: StyleDisplay}
+	constants := DefaultMathConstants()
+
+	frame := LayoutLr(lr, ctx, constants)
+	if len(frame.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(frame.Items))
+	}
+	if open := delimiterText(frame.Items[0]); open != "(" {
+		t.Errorf("expected opening paren, got %q", open)
+	}
+
+	body := LayoutFrac(frac, ctx, constants)
+	axis := constants.AxisHeight.At(12)
+	extent := max(body.Baseline-axis, body.Height()-body.Baseline+axis)
+	want := 2*extent - delimShortfall.At(12)
+
+	open := frame.Items[0].Item.(ChildFrame).Frame
+	if !approxEqual(open.Height(), want) {
+		t.Errorf("delimiter height = %v, want %v", open.Height(), want)
+	}
+	// Stretched delimiters are centered on the axis.
+	if !approxEqual(open.Baseline, open.Height()/2+axis) {
+		t.Errorf("delimiter baseline = %v, want %v", open.Baseline, open.Height()/2+axis)
+	}
+}
+
+func TestLayoutLrSize(t *testing.T) {
+	body := libmath.Delimited(foundations.Content{Elements: []foundations.ContentElement{
+		&libmath.FracElem{Num: *mathText("a"), Denom: *mathText("b")},
+	}}, "[", "]")
+	ctx := &MathContext{FontSize: 12, Style: StyleDisplay}
+	constants := DefaultMathConstants()
+
+	auto := LayoutLr(&libmath.LrElem{Body: body}, ctx, constants)
+	larger := LayoutLr(&libmath.LrElem{Body: body, Size: &foundations.Relative{
+		Rel: foundations.Ratio{Value: 1.5},
+	}}, ctx, constants)
+
+	autoDelim := auto.Items[0].Item.(ChildFrame).Frame
+	largerDelim := larger.Items[0].Item.(ChildFrame).Frame
+	if largerDelim.Height() <= autoDelim.Height() {
+		t.Errorf("size 150%% delimiter %v should exceed auto %v", largerDelim.Height(), autoDelim.Height())
+	}
+}
+
+func TestLayoutLrMid(t *testing.T) {
+	frac := &libmath.FracElem{Num: *mathText("a"), Denom: *mathText("b")}
+	mid := &libmath.MidElem{Body: mathSymbol("|")}
+	lr := &libmath.LrElem{Body: libmath.Delimited(foundations.Content{
+		Elements: []foundations.ContentElement{mathText("x").Elements[0], mid, frac},
+	}, "{", "}")}
+	ctx := &MathContext{FontSize: 12, Style: StyleDisplay}
+
+	frame := LayoutLr(lr, ctx, DefaultMathConstants())
+	if len(frame.Items) != 5 {
+		t.Fatalf("expected 5 items, got %d", len(frame.Items))
+	}
+	open := frame.Items[0].Item.(ChildFrame).Frame
+	middle := frame.Items[2].Item.(ChildFrame).Frame
+	if !approxEqual(middle.Height(), open.Height()) {
+		t.Errorf("mid height = %v, want %v", middle.Height(), open.Height())
+	}
+	// Text between the delimiters is not stretched.
+	if x := frame.Items[1].Item.(ChildFrame).Frame; x.Height() >= open.Height() {
+		t.Errorf("inner text should not be stretched: %v", x.Height())
+	}
+}
+
+func TestLayoutLrNoDelimiters(t *testing.T) {
+	lr := &libmath.LrElem{Body: *mathText("x")}
+	ctx := &MathContext{FontSize: 12, Style: StyleText}
+
+	frame := LayoutLr(lr, ctx, DefaultMathConstants())
+	text := LayoutContent(mathText("x"), ctx, DefaultMathConstants())
+	if !approxEqual(frame.Width(), text.Width()) || !approxEqual(frame.Height(), text.Height()) {
+		t.Errorf("lr without delimiters should match its body, got %v", frame.Size)
+	}
+}
+
+func TestAssembleGlyph(t *testing.T) {
+	parts := []font.GlyphPart{
+		{Glyph: 1, EndConnector: 100, FullAdvance: 500},
+		{Glyph: 2, StartConnector: 100, EndConnector: 100, FullAdvance: 400, Extender: true},
+		{Glyph: 3, StartConnector: 100, FullAdvance: 500},
+	}
+	f := &font.Font{}
+
+	// Units map one to one onto points.
+	frame := assembleGlyph(f, parts, 20, 1500, 1, 1000)
+	if frame.Height() < 1500 {
+		t.Errorf("assembly height %v is below the target", frame.Height())
+	}
+	// Two extenders are needed: 500+400+500 minus overlaps is too short.
+	if len(frame.Items) != 4 {
+		t.Fatalf("expected 4 parts, got %d", len(frame.Items))
+	}
+	// Parts are stacked from the bottom up.
+	bottom := frame.Items[0].Item.(GlyphItem)
+	top := frame.Items[3].Item.(GlyphItem)
+	if bottom.Glyph != 1 || top.Glyph != 3 {
+		t.Errorf("unexpected part order %d..%d", bottom.Glyph, top.Glyph)
+	}
+	if !approxEqual(frame.Items[3].Pos.Y, 0) {
+		t.Errorf("top part y = %v, want 0", frame.Items[3].Pos.Y)
+	}
+	if !approxEqual(frame.Items[0].Pos.Y+500, frame.Height()) {
+		t.Errorf("bottom part ends at %v, want %v", frame.Items[0].Pos.Y+500, frame.Height())
+	}
+
+	// Short targets use the minimal assembly with maximal overlap.
+	short := assembleGlyph(f, parts, 20, 100, 1, 1000)
+	if len(short.Items) != 2 || !approxEqual(short.Height(), 900) {
+		t.Errorf("expected two parts of height 900, got %d parts of %v", len(short.Items), short.Height())
+	}
+}
+
+func TestStretchGlyphWithoutFont(t *testing.T) {
+	ctx := &MathContext{FontSize: 10, Style: StyleText}
+
+	small := stretchGlyph("(", 5, ctx)
+	if !approxEqual(small.Height(), 10) {
+		t.Errorf("glyph should not shrink below the font size, got %v", small.Height())
+	}
+	large := stretchGlyph("(", 30, ctx)
+	if !approxEqual(large.Height(), 30) {
+		t.Errorf("stretched height = %v, want 30", large.Height())
+	}
+}
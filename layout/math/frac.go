@@ -1,7 +1,11 @@
 package math
 
 import (
-	"github.com/boergens/gotypst/eval"
+	"unicode/utf8"
+
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
 )
 
 // LayoutFrac lays out a fraction element.
@@ -12,12 +16,33 @@ import (
 //   - Denominator positioned below the fraction bar
 //
 // The baseline of the resulting frame is at the math axis (center of fraction bar).
-func LayoutFrac(elem *eval.MathFracElement, ctx *MathContext, constants MathConstants) *MathFrame {
+func LayoutFrac(elem *libmath.FracElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	return layoutFraction(&elem.Num, &elem.Denom, ctx, constants, true)
+}
+
+// LayoutBinom lays out a binomial coefficient: the upper and lower parts are
+// stacked like a fraction without a bar and enclosed in parentheses.
+// Multiple lower parts are separated by commas.
+func LayoutBinom(elem *libmath.BinomElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	var lower foundations.Content
+	for i, part := range elem.Lower {
+		if i > 0 {
+			lower.Elements = append(lower.Elements, &foundations.SymbolElem{Text: ","})
+		}
+		lower.Elements = append(lower.Elements, part.Elements...)
+	}
+	stack := layoutFraction(&elem.Upper, &lower, ctx, constants, false)
+	return layoutDelimiters(stack, "(", ")", ctx, constants)
+}
+
+// layoutFraction stacks num over denom around the math axis, optionally
+// separated by a fraction bar.
+func layoutFraction(num, denom *foundations.Content, ctx *MathContext, constants MathConstants, bar bool) *MathFrame {
 	fontSize := ctx.FontSizeForStyle(ctx.Style)
 
 	// Layout numerator and denominator
-	numFrame := LayoutContent(&elem.Num, ctx, constants)
-	denomFrame := LayoutContent(&elem.Denom, ctx, constants)
+	numFrame := LayoutContent(num, ctx, constants)
+	denomFrame := LayoutContent(denom, ctx, constants)
 
 	// Calculate the width (max of numerator/denominator, with some padding)
 	const horizontalPadding = 0.1 // 10% padding on each side
@@ -82,7 +107,7 @@ func LayoutFrac(elem *eval.MathFracElement, ctx *MathContext, constants MathCons
 	}
 
 	// Total height: from numerator top to denominator bottom
-	totalTop := numY + numBaseline           // Distance from axis to numerator top
+	totalTop := numY + numBaseline                               // Distance from axis to numerator top
 	totalBottom := -denomY + denomFrame.Height() - denomBaseline // Distance from axis to denominator bottom
 
 	totalHeight := totalTop + totalBottom
@@ -107,12 +132,14 @@ func LayoutFrac(elem *eval.MathFracElement, ctx *MathContext, constants MathCons
 
 	// Position fraction bar (horizontal line at the axis)
 	// The bar is drawn from its left edge
-	barX := Abs(0)
-	barY := totalTop - axisHeight // Y position of the bar center
-	frame.Push(Point{X: barX, Y: barY}, LineItem{
-		Length:    barWidth,
-		Thickness: ruleThickness,
-	})
+	if bar {
+		barX := Abs(0)
+		barY := totalTop - axisHeight // Y position of the bar center
+		frame.Push(Point{X: barX, Y: barY}, LineItem{
+			Length:    barWidth,
+			Thickness: ruleThickness,
+		})
+	}
 
 	// Position denominator (centered horizontally)
 	denomX := (barWidth - denomWidth) / 2
@@ -126,7 +153,7 @@ func LayoutFrac(elem *eval.MathFracElement, ctx *MathContext, constants MathCons
 
 // LayoutContent lays out math content recursively.
 // This is the main entry point for laying out arbitrary math content.
-func LayoutContent(content *eval.Content, ctx *MathContext, constants MathConstants) *MathFrame {
+func LayoutContent(content *foundations.Content, ctx *MathContext, constants MathConstants) *MathFrame {
 	if content == nil || len(content.Elements) == 0 {
 		// Return empty frame
 		return &MathFrame{
@@ -144,24 +171,36 @@ func LayoutContent(content *eval.Content, ctx *MathContext, constants MathConsta
 	return LayoutHorizontal(content.Elements, ctx, constants)
 }
 
-// LayoutHorizontal arranges multiple elements horizontally.
-func LayoutHorizontal(elements []eval.ContentElement, ctx *MathContext, constants MathConstants) *MathFrame {
+// LayoutHorizontal arranges multiple elements horizontally, spaced
+// according to their math classes.
+func LayoutHorizontal(elements []foundations.ContentElement, ctx *MathContext, constants MathConstants) *MathFrame {
 	if len(elements) == 0 {
 		return &MathFrame{}
 	}
+	return layoutRun(elements, ctx, constants)
+}
 
-	// Layout each element
-	frames := make([]*MathFrame, len(elements))
-	for i, elem := range elements {
-		frames[i] = LayoutElement(elem, ctx, constants)
-	}
+// joinHorizontal places frames next to each other, aligned on their
+// baselines. Nil frames are skipped.
+func joinHorizontal(frames []*MathFrame) *MathFrame {
+	return joinSpaced(frames, nil)
+}
 
+// joinSpaced places frames next to each other, aligned on their baselines,
+// with gaps[i] of space before frames[i]. Nil frames are skipped.
+func joinSpaced(frames []*MathFrame, gaps []Abs) *MathFrame {
 	// Calculate total width and find max baseline/height
 	var totalWidth Abs
 	var maxAboveBaseline Abs
 	var maxBelowBaseline Abs
 
-	for _, f := range frames {
+	for i, f := range frames {
+		if f == nil {
+			continue
+		}
+		if i < len(gaps) {
+			totalWidth += gaps[i]
+		}
 		totalWidth += f.Width()
 		aboveBaseline := f.Baseline
 		belowBaseline := f.Height() - f.Baseline
@@ -186,7 +225,13 @@ func LayoutHorizontal(elements []eval.ContentElement, ctx *MathContext, constant
 
 	// Position each frame, aligned on baseline
 	x := Abs(0)
-	for _, f := range frames {
+	for i, f := range frames {
+		if f == nil {
+			continue
+		}
+		if i < len(gaps) {
+			x += gaps[i]
+		}
 		y := maxAboveBaseline - f.Baseline
 		result.PushFrame(Point{X: x, Y: y}, f)
 		x += f.Width()
@@ -196,24 +241,49 @@ func LayoutHorizontal(elements []eval.ContentElement, ctx *MathContext, constant
 }
 
 // LayoutElement lays out a single content element.
-func LayoutElement(elem eval.ContentElement, ctx *MathContext, constants MathConstants) *MathFrame {
+func LayoutElement(elem foundations.ContentElement, ctx *MathContext, constants MathConstants) *MathFrame {
 	if elem == nil {
 		return &MathFrame{}
 	}
 
 	switch e := elem.(type) {
-	case *eval.MathFracElement:
+	case *libmath.FracElem:
 		return LayoutFrac(e, ctx, constants)
-	case *eval.TextElement:
+	case *libmath.BinomElem:
+		return LayoutBinom(e, ctx, constants)
+	case *libmath.VecElem:
+		return LayoutVec(e, ctx, constants)
+	case *libmath.MatElem:
+		return LayoutMat(e, ctx, constants)
+	case *libmath.CasesElem:
+		return LayoutCases(e, ctx, constants)
+	case *libtext.TextElem:
 		return LayoutText(e, ctx)
-	case *eval.MathSymbolElement:
+	case *foundations.SymbolElem:
+		if ctx.Style == StyleDisplay && isLargeOperatorText(e.Text) {
+			return layoutLargeOperator(e.Text, ctx, constants)
+		}
 		return LayoutSymbol(e, ctx)
-	case *eval.MathAttachElement:
+	case *libmath.AttachElem:
 		return LayoutAttach(e, ctx, constants)
-	case *eval.MathRootElement:
+	case *libmath.LimitsElem:
+		return LayoutContent(&e.Body, ctx, constants)
+	case *libmath.ScriptsElem:
+		return LayoutContent(&e.Body, ctx, constants)
+	case *libmath.AccentElem:
+		return LayoutAccent(e, ctx, constants)
+	case *libmath.RootElem:
 		return LayoutRoot(e, ctx, constants)
-	case *eval.MathDelimitedElement:
-		return LayoutDelimited(e, ctx, constants)
+	case *libmath.LrElem:
+		return LayoutLr(e, ctx, constants)
+	case *libmath.MidElem:
+		return LayoutContent(&e.Body, ctx, constants)
+	case *libmath.MathStyleElem:
+		return LayoutMathStyle(e, ctx, constants)
+	case *libmath.OpElem:
+		return LayoutOp(e, ctx, constants)
+	case *foundations.SequenceElem:
+		return LayoutSequence(e, ctx, constants)
 	default:
 		// For unknown elements, return empty frame
 		return &MathFrame{}
@@ -221,13 +291,14 @@ func LayoutElement(elem eval.ContentElement, ctx *MathContext, constants MathCon
 }
 
 // LayoutText lays out a text element.
-func LayoutText(elem *eval.TextElement, ctx *MathContext) *MathFrame {
+func LayoutText(elem *libtext.TextElem, ctx *MathContext) *MathFrame {
 	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	text := styleText(elem.Body, ctx)
 
 	// Approximate text width (would use actual shaping in production)
 	// Use approximately 0.5em per character for math text
 	charWidth := Em(0.5).At(fontSize)
-	width := charWidth * Abs(len(elem.Text))
+	width := charWidth * Abs(utf8.RuneCountInString(text))
 
 	// Height is approximately the font size
 	height := fontSize
@@ -244,7 +315,7 @@ func LayoutText(elem *eval.TextElement, ctx *MathContext) *MathFrame {
 	}
 
 	frame.Push(Point{X: 0, Y: 0}, TextItem{
-		Text:     elem.Text,
+		Text:     text,
 		FontSize: fontSize,
 	})
 
@@ -252,12 +323,13 @@ func LayoutText(elem *eval.TextElement, ctx *MathContext) *MathFrame {
 }
 
 // LayoutSymbol lays out a math symbol element.
-func LayoutSymbol(elem *eval.MathSymbolElement, ctx *MathContext) *MathFrame {
+func LayoutSymbol(elem *foundations.SymbolElem, ctx *MathContext) *MathFrame {
 	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	text := styleText(elem.Text, ctx)
 
 	// Approximate symbol width
 	charWidth := Em(0.5).At(fontSize)
-	width := charWidth * Abs(len(elem.Symbol))
+	width := charWidth * Abs(utf8.RuneCountInString(text))
 
 	height := fontSize
 	baseline := height * 0.8
@@ -271,108 +343,15 @@ func LayoutSymbol(elem *eval.MathSymbolElement, ctx *MathContext) *MathFrame {
 	}
 
 	frame.Push(Point{X: 0, Y: 0}, TextItem{
-		Text:     elem.Symbol,
+		Text:     text,
 		FontSize: fontSize,
 	})
 
 	return frame
 }
 
-// LayoutAttach lays out subscripts and superscripts.
-func LayoutAttach(elem *eval.MathAttachElement, ctx *MathContext, constants MathConstants) *MathFrame {
-	fontSize := ctx.FontSizeForStyle(ctx.Style)
-
-	// Layout base
-	baseFrame := LayoutContent(&elem.Base, ctx, constants)
-
-	// Create context for scripts (smaller size)
-	scriptCtx := &MathContext{
-		FontSize: ctx.FontSize,
-		Style:    ctx.Style.ScriptStyle(),
-		Cramped:  ctx.Cramped,
-	}
-
-	// Layout subscript and superscript
-	var subFrame, supFrame *MathFrame
-	hasSubscript := len(elem.Subscript.Elements) > 0
-	hasSuperscript := len(elem.Superscript.Elements) > 0
-
-	if hasSubscript {
-		subFrame = LayoutContent(&elem.Subscript, scriptCtx, constants)
-	}
-	if hasSuperscript {
-		supFrame = LayoutContent(&elem.Superscript, scriptCtx, constants)
-	}
-
-	// Calculate positioning
-	// Scripts are positioned to the right of the base
-	scriptX := baseFrame.Width()
-
-	// Superscript shift up and subscript shift down
-	supShift := Em(0.4).At(fontSize) // Shift up from baseline
-	subShift := Em(0.2).At(fontSize) // Shift down from baseline
-
-	// Calculate total size
-	width := scriptX
-	if hasSuperscript && supFrame.Width() > 0 {
-		width = scriptX + supFrame.Width()
-	}
-	if hasSubscript && subFrame.Width() > width-scriptX {
-		width = scriptX + subFrame.Width()
-	}
-
-	// Height calculation
-	topExtent := baseFrame.Baseline
-	bottomExtent := baseFrame.Height() - baseFrame.Baseline
-
-	if hasSuperscript {
-		supTop := baseFrame.Baseline - supShift + supFrame.Baseline
-		if supTop > topExtent {
-			topExtent = supTop
-		}
-	}
-	if hasSubscript {
-		subBottom := (baseFrame.Height() - baseFrame.Baseline) + subShift + (subFrame.Height() - subFrame.Baseline)
-		if subBottom > bottomExtent {
-			bottomExtent = subBottom
-		}
-	}
-
-	height := topExtent + bottomExtent
-	baseline := topExtent
-
-	// Create result frame
-	frame := &MathFrame{
-		Size: Size{
-			Width:  width,
-			Height: height,
-		},
-		Baseline: baseline,
-	}
-
-	// Position base
-	baseY := baseline - baseFrame.Baseline
-	frame.PushFrame(Point{X: 0, Y: baseY}, baseFrame)
-
-	// Position superscript
-	if hasSuperscript {
-		supY := baseline - supShift - supFrame.Baseline
-		frame.PushFrame(Point{X: scriptX, Y: supY}, supFrame)
-	}
-
-	// Position subscript
-	if hasSubscript {
-		subY := baseline + subShift - subFrame.Baseline + (baseFrame.Height() - baseFrame.Baseline - baseline + baseFrame.Baseline)
-		// Simplify: position subscript below baseline
-		subY = baseline + subShift
-		frame.PushFrame(Point{X: scriptX, Y: subY}, subFrame)
-	}
-
-	return frame
-}
-
 // LayoutRoot lays out a root (square root, nth root) element.
-func LayoutRoot(elem *eval.MathRootElement, ctx *MathContext, constants MathConstants) *MathFrame {
+func LayoutRoot(elem *libmath.RootElem, ctx *MathContext, constants MathConstants) *MathFrame {
 	fontSize := ctx.FontSizeForStyle(ctx.Style)
 
 	// Layout radicand (content under the root)
@@ -414,11 +393,7 @@ func LayoutRoot(elem *eval.MathRootElement, ctx *MathContext, constants MathCons
 
 	// Handle index (for nth roots) if present
 	if len(elem.Index.Elements) > 0 {
-		indexCtx := &MathContext{
-			FontSize: ctx.FontSize,
-			Style:    StyleScriptScript,
-			Cramped:  true,
-		}
+		indexCtx := ctx.WithStyle(StyleScriptScript, true)
 		indexFrame := LayoutContent(&elem.Index, indexCtx, constants)
 		// Position index in the "v" of the root symbol
 		indexX := Abs(0)
@@ -429,43 +404,11 @@ func LayoutRoot(elem *eval.MathRootElement, ctx *MathContext, constants MathCons
 	return frame
 }
 
-// LayoutDelimited lays out delimited content (parentheses, brackets, etc.).
-func LayoutDelimited(elem *eval.MathDelimitedElement, ctx *MathContext, constants MathConstants) *MathFrame {
-	fontSize := ctx.FontSizeForStyle(ctx.Style)
-
-	// Layout body content
-	bodyFrame := LayoutContent(&elem.Body, ctx, constants)
-
-	// Delimiter dimensions
-	delimWidth := Em(0.3).At(fontSize)
-
-	// Calculate total size
-	width := delimWidth + bodyFrame.Width() + delimWidth
-	height := bodyFrame.Height()
-	baseline := bodyFrame.Baseline
-
-	frame := &MathFrame{
-		Size: Size{
-			Width:  width,
-			Height: height,
-		},
-		Baseline: baseline,
+// LayoutSequence lays out a sequence of content as a horizontal run.
+func LayoutSequence(elem *foundations.SequenceElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	var elements []foundations.ContentElement
+	for _, child := range elem.Children {
+		elements = append(elements, child.Elements...)
 	}
-
-	// Add opening delimiter
-	frame.Push(Point{X: 0, Y: 0}, TextItem{
-		Text:     elem.Open,
-		FontSize: fontSize,
-	})
-
-	// Position body
-	frame.PushFrame(Point{X: delimWidth, Y: 0}, bodyFrame)
-
-	// Add closing delimiter
-	frame.Push(Point{X: delimWidth + bodyFrame.Width(), Y: 0}, TextItem{
-		Text:     elem.Close,
-		FontSize: fontSize,
-	})
-
-	return frame
+	return LayoutHorizontal(elements, ctx, constants)
 }
@@ -4,7 +4,9 @@ import (
 	"math"
 	"testing"
 
-	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
 )
 
 // approxEqual checks if two Abs values are approximately equal
@@ -16,15 +18,15 @@ func approxEqual(a, b Abs) bool {
 
 func TestLayoutFrac(t *testing.T) {
 	// Create a simple fraction: a/b
-	frac := &eval.MathFracElement{
-		Num: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "a"},
+	frac := &libmath.FracElem{
+		Num: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "a"},
 			},
 		},
-		Denom: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "b"},
+		Denom: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "b"},
 			},
 		},
 	}
@@ -64,28 +66,28 @@ func TestLayoutFrac(t *testing.T) {
 
 func TestLayoutFracNested(t *testing.T) {
 	// Create a nested fraction: (a/b) / c
-	innerFrac := &eval.MathFracElement{
-		Num: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "a"},
+	innerFrac := &libmath.FracElem{
+		Num: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "a"},
 			},
 		},
-		Denom: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "b"},
+		Denom: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "b"},
 			},
 		},
 	}
 
-	outerFrac := &eval.MathFracElement{
-		Num: eval.Content{
-			Elements: []eval.ContentElement{
+	outerFrac := &libmath.FracElem{
+		Num: foundations.Content{
+			Elements: []foundations.ContentElement{
 				innerFrac,
 			},
 		},
-		Denom: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "c"},
+		Denom: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "c"},
 			},
 		},
 	}
@@ -104,15 +106,15 @@ func TestLayoutFracNested(t *testing.T) {
 	}
 
 	// Nested fraction should be taller than a simple fraction
-	simpleFrac := &eval.MathFracElement{
-		Num: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "x"},
+	simpleFrac := &libmath.FracElem{
+		Num: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "x"},
 			},
 		},
-		Denom: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "y"},
+		Denom: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "y"},
 			},
 		},
 	}
@@ -125,15 +127,15 @@ func TestLayoutFracNested(t *testing.T) {
 }
 
 func TestLayoutFracDisplayVsInline(t *testing.T) {
-	frac := &eval.MathFracElement{
-		Num: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "a"},
+	frac := &libmath.FracElem{
+		Num: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "a"},
 			},
 		},
-		Denom: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "b"},
+		Denom: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "b"},
 			},
 		},
 	}
@@ -171,13 +173,13 @@ func TestLayoutFracDisplayVsInline(t *testing.T) {
 
 func TestLayoutFracEmptyContent(t *testing.T) {
 	// Fraction with empty numerator
-	frac := &eval.MathFracElement{
-		Num: eval.Content{
-			Elements: []eval.ContentElement{},
+	frac := &libmath.FracElem{
+		Num: foundations.Content{
+			Elements: []foundations.ContentElement{},
 		},
-		Denom: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "b"},
+		Denom: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "b"},
 			},
 		},
 	}
@@ -210,7 +212,7 @@ func TestLayoutFracEmptyContent(t *testing.T) {
 }
 
 func TestLayoutText(t *testing.T) {
-	text := &eval.TextElement{Text: "xyz"}
+	text := &libtext.TextElem{Body: "xyz"}
 	ctx := &MathContext{
 		FontSize: Abs(12),
 		Style:    StyleText,
@@ -247,7 +249,7 @@ func TestLayoutText(t *testing.T) {
 }
 
 func TestLayoutSymbol(t *testing.T) {
-	symbol := &eval.MathSymbolElement{Symbol: "alpha"}
+	symbol := &foundations.SymbolElem{Text: "alpha"}
 	ctx := &MathContext{
 		FontSize: Abs(12),
 		Style:    StyleText,
@@ -267,10 +269,10 @@ func TestLayoutSymbol(t *testing.T) {
 }
 
 func TestLayoutHorizontal(t *testing.T) {
-	elements := []eval.ContentElement{
-		&eval.TextElement{Text: "a"},
-		&eval.MathSymbolElement{Symbol: "+"},
-		&eval.TextElement{Text: "b"},
+	elements := []foundations.ContentElement{
+		&libtext.TextElem{Body: "a"},
+		&foundations.SymbolElem{Text: "+"},
+		&libtext.TextElem{Body: "b"},
 	}
 
 	ctx := &MathContext{
@@ -291,29 +293,30 @@ func TestLayoutHorizontal(t *testing.T) {
 		t.Errorf("expected 3 items, got %d", len(frame.Items))
 	}
 
-	// Width should be sum of individual widths
-	var expectedWidth Abs
+	// Width should be sum of individual widths plus the medium spaces
+	// around the binary operator
+	expectedWidth := 2 * SpaceMedium.Amount().At(ctx.FontSize)
 	for _, elem := range elements {
 		f := LayoutElement(elem, ctx, constants)
 		expectedWidth += f.Width()
 	}
 
-	if frame.Width() != expectedWidth {
+	if !approxEqual(frame.Width(), expectedWidth) {
 		t.Errorf("expected width %v, got %v", expectedWidth, frame.Width())
 	}
 }
 
 func TestLayoutAttach(t *testing.T) {
 	// x^2
-	attach := &eval.MathAttachElement{
-		Base: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "x"},
+	attach := &libmath.AttachElem{
+		Base: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "x"},
 			},
 		},
-		Superscript: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "2"},
+		T: &foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "2"},
 			},
 		},
 	}
@@ -337,7 +340,7 @@ func TestLayoutAttach(t *testing.T) {
 	}
 
 	// Width should be greater than base alone
-	baseFrame := LayoutText(&eval.TextElement{Text: "x"}, ctx)
+	baseFrame := LayoutText(&libtext.TextElem{Body: "x"}, ctx)
 	if frame.Width() <= baseFrame.Width() {
 		t.Errorf("attach frame width %v should be greater than base width %v",
 			frame.Width(), baseFrame.Width())
@@ -346,10 +349,10 @@ func TestLayoutAttach(t *testing.T) {
 
 func TestLayoutRoot(t *testing.T) {
 	// sqrt(x)
-	root := &eval.MathRootElement{
-		Radicand: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "x"},
+	root := &libmath.RootElem{
+		Radicand: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "x"},
 			},
 		},
 	}
@@ -373,16 +376,16 @@ func TestLayoutRoot(t *testing.T) {
 	}
 }
 
-func TestLayoutDelimited(t *testing.T) {
+func TestLayoutLr(t *testing.T) {
 	// (a + b)
-	delim := &eval.MathDelimitedElement{
-		Open:  "(",
-		Close: ")",
-		Body: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "a"},
-				&eval.MathSymbolElement{Symbol: "+"},
-				&eval.TextElement{Text: "b"},
+	lr := &libmath.LrElem{
+		Body: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&foundations.SymbolElem{Text: "("},
+				&libtext.TextElem{Body: "a"},
+				&foundations.SymbolElem{Text: "+"},
+				&libtext.TextElem{Body: "b"},
+				&foundations.SymbolElem{Text: ")"},
 			},
 		},
 	}
@@ -394,10 +397,10 @@ func TestLayoutDelimited(t *testing.T) {
 	}
 	constants := DefaultMathConstants()
 
-	frame := LayoutDelimited(delim, ctx, constants)
+	frame := LayoutLr(lr, ctx, constants)
 
 	if frame == nil {
-		t.Fatal("LayoutDelimited returned nil")
+		t.Fatal("LayoutLr returned nil")
 	}
 
 	// Should have items for open paren, body, and close paren
@@ -408,18 +411,18 @@ func TestLayoutDelimited(t *testing.T) {
 
 func TestLayoutEquation(t *testing.T) {
 	// Block equation: $x/y$
-	equation := &eval.EquationElement{
-		Body: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.MathFracElement{
-					Num: eval.Content{
-						Elements: []eval.ContentElement{
-							&eval.TextElement{Text: "x"},
+	equation := &libmath.EquationElem{
+		Body: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libmath.FracElem{
+					Num: foundations.Content{
+						Elements: []foundations.ContentElement{
+							&libtext.TextElem{Body: "x"},
 						},
 					},
-					Denom: eval.Content{
-						Elements: []eval.ContentElement{
-							&eval.TextElement{Text: "y"},
+					Denom: foundations.Content{
+						Elements: []foundations.ContentElement{
+							&libtext.TextElem{Body: "y"},
 						},
 					},
 				},
@@ -444,10 +447,10 @@ func TestLayoutEquation(t *testing.T) {
 }
 
 func TestLayoutEquationWithResult(t *testing.T) {
-	equation := &eval.EquationElement{
-		Body: eval.Content{
-			Elements: []eval.ContentElement{
-				&eval.TextElement{Text: "x"},
+	equation := &libmath.EquationElem{
+		Body: foundations.Content{
+			Elements: []foundations.ContentElement{
+				&libtext.TextElem{Body: "x"},
 			},
 		},
 		Block: false,
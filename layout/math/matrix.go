@@ -0,0 +1,201 @@
+package math
+
+import (
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	"github.com/boergens/gotypst/library/visualize"
+)
+
+const (
+	// defaultRowGap is the default gap between matrix rows and vector elements.
+	defaultRowGap = Em(0.2)
+	// defaultColGap is the default gap between matrix columns.
+	defaultColGap = Em(0.5)
+)
+
+// LayoutVec lays out a column vector.
+func LayoutVec(elem *libmath.VecElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	rows := make([][]foundations.Content, len(elem.Children))
+	for i, child := range elem.Children {
+		rows[i] = []foundations.Content{child}
+	}
+	gap := resolveGap(elem.Gap, defaultRowGap, fontSize)
+	body := layoutMatrixBody(rows, elem.Align, gap, 0, nil, ctx, constants)
+	return layoutDelimiters(body, elem.Delim.Open, elem.Delim.Close, ctx, constants)
+}
+
+// LayoutMat lays out a matrix, including its augmentation lines.
+func LayoutMat(elem *libmath.MatElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	rowGap := resolveGap(elem.RowGap, defaultRowGap, fontSize)
+	colGap := resolveGap(elem.ColumnGap, defaultColGap, fontSize)
+	body := layoutMatrixBody(elem.Rows, elem.Align, rowGap, colGap, elem.Augment, ctx, constants)
+	return layoutDelimiters(body, elem.Delim.Open, elem.Delim.Close, ctx, constants)
+}
+
+// LayoutCases lays out a case distinction. The branches are start-aligned
+// and only the delimiter facing them is drawn.
+func LayoutCases(elem *libmath.CasesElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	rows := make([][]foundations.Content, len(elem.Children))
+	for i, child := range elem.Children {
+		rows[i] = []foundations.Content{child}
+	}
+	gap := resolveGap(elem.Gap, defaultRowGap, fontSize)
+
+	align := "start"
+	open, close := elem.Delim.Open, ""
+	if elem.Reverse {
+		align = "end"
+		open, close = "", elem.Delim.Close
+	}
+
+	body := layoutMatrixBody(rows, align, gap, 0, nil, ctx, constants)
+	return layoutDelimiters(body, open, close, ctx, constants)
+}
+
+// resolveGap returns the given gap or the default if it is unset.
+func resolveGap(gap *foundations.Length, def Em, fontSize Abs) Abs {
+	if gap == nil {
+		return def.At(fontSize)
+	}
+	return Abs(gap.Points)
+}
+
+// layoutMatrixBody arranges cells in a grid that is vertically centered on
+// the math axis.
+//
+// Each column is as wide as its widest cell, and each row is as tall as its
+// tallest cell. Cells are aligned horizontally within their column and on a
+// shared baseline within their row.
+func layoutMatrixBody(
+	rows [][]foundations.Content,
+	align string,
+	rowGap, colGap Abs,
+	augment *libmath.Augment,
+	ctx *MathContext,
+	constants MathConstants,
+) *MathFrame {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+
+	ncols := 0
+	for _, row := range rows {
+		if len(row) > ncols {
+			ncols = len(row)
+		}
+	}
+	if len(rows) == 0 || ncols == 0 {
+		return &MathFrame{}
+	}
+
+	// Lay out all cells and measure columns and rows.
+	cells := make([][]*MathFrame, len(rows))
+	colWidths := make([]Abs, ncols)
+	ascents := make([]Abs, len(rows))
+	descents := make([]Abs, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]*MathFrame, len(row))
+		for c := range row {
+			cell := LayoutContent(&row[c], ctx, constants)
+			cells[r][c] = cell
+			if cell.Width() > colWidths[c] {
+				colWidths[c] = cell.Width()
+			}
+			if cell.Baseline > ascents[r] {
+				ascents[r] = cell.Baseline
+			}
+			if d := cell.Height() - cell.Baseline; d > descents[r] {
+				descents[r] = d
+			}
+		}
+	}
+
+	// Compute column and row offsets.
+	colX := make([]Abs, ncols)
+	var width Abs
+	for c, w := range colWidths {
+		if c > 0 {
+			width += colGap
+		}
+		colX[c] = width
+		width += w
+	}
+	rowY := make([]Abs, len(rows))
+	var height Abs
+	for r := range rows {
+		if r > 0 {
+			height += rowGap
+		}
+		rowY[r] = height
+		height += ascents[r] + descents[r]
+	}
+
+	frame := &MathFrame{
+		Size:     Size{Width: width, Height: height},
+		Baseline: height/2 + constants.AxisHeight.At(fontSize),
+	}
+
+	for r, row := range cells {
+		for c, cell := range row {
+			x := colX[c]
+			switch align {
+			case "start":
+			case "end":
+				x += colWidths[c] - cell.Width()
+			default:
+				x += (colWidths[c] - cell.Width()) / 2
+			}
+			y := rowY[r] + ascents[r] - cell.Baseline
+			frame.PushFrame(Point{X: x, Y: y}, cell)
+		}
+	}
+
+	if augment != nil {
+		thickness, paint := augmentStroke(augment.Stroke, constants.FractionRuleThickness.At(fontSize))
+		for _, offset := range augment.HLine {
+			r := libmath.ResolveOffset(offset, len(rows))
+			if r <= 0 || r >= len(rows) {
+				continue
+			}
+			frame.Push(Point{X: 0, Y: rowY[r] - rowGap/2}, LineItem{
+				Length:    width,
+				Thickness: thickness,
+				Paint:     paint,
+			})
+		}
+		for _, offset := range augment.VLine {
+			c := libmath.ResolveOffset(offset, ncols)
+			if c <= 0 || c >= ncols {
+				continue
+			}
+			frame.Push(Point{X: colX[c] - colGap/2, Y: 0}, LineItem{
+				Length:    height,
+				Thickness: thickness,
+				Vertical:  true,
+				Paint:     paint,
+			})
+		}
+	}
+
+	return frame
+}
+
+// augmentStroke returns the thickness and paint of augmentation lines. The
+// parts the stroke leaves unset default to the given thickness and the
+// text color. Only solid colors can be drawn in math frames.
+func augmentStroke(stroke *visualize.Stroke, thickness Abs) (Abs, *layout.Paint) {
+	if stroke == nil {
+		return thickness, nil
+	}
+	if stroke.Thickness != nil {
+		thickness = Abs(stroke.Thickness.Points)
+	}
+	var paint *layout.Paint
+	if c, ok := stroke.Paint.(foundations.Color); ok {
+		r, g, b, a := c.ToRgba().ToBytes()
+		paint = &layout.Paint{Color: &layout.Color{R: r, G: g, B: b, A: a}}
+	}
+	return thickness, paint
+}
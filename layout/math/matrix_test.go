@@ -0,0 +1,192 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
+	"github.com/boergens/gotypst/library/visualize"
+)
+
+func textContent(text string) foundations.Content {
+	return foundations.Content{
+		Elements: []foundations.ContentElement{&libtext.TextElem{Body: text}},
+	}
+}
+
+func testContext() *MathContext {
+	return &MathContext{FontSize: Abs(12), Style: StyleDisplay}
+}
+
+func TestLayoutMat(t *testing.T) {
+	mat := &libmath.MatElem{
+		Rows: [][]foundations.Content{
+			{textContent("a"), textContent("bbb")},
+			{textContent("c"), textContent("d")},
+		},
+		Delim: libmath.DefaultMatDelim,
+		Align: "center",
+	}
+
+	frame := LayoutMat(mat, testContext(), DefaultMathConstants())
+
+	// Open delimiter, body, close delimiter.
+	if len(frame.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(frame.Items))
+	}
+	body := frame.Items[1].Item.(ChildFrame).Frame
+	if len(body.Items) != 4 {
+		t.Fatalf("expected 4 cells, got %d", len(body.Items))
+	}
+
+	// The second column is as wide as "bbb", and "d" is centered in it.
+	colGap := defaultColGap.At(12)
+	bbb := body.Items[1]
+	d := body.Items[3]
+	wantX := bbb.Pos.X + (bbb.Item.(ChildFrame).Frame.Width()-d.Item.(ChildFrame).Frame.Width())/2
+	if !approxEqual(d.Pos.X, wantX) {
+		t.Errorf("expected centered cell at x=%v, got %v", wantX, d.Pos.X)
+	}
+	if !approxEqual(bbb.Pos.X, Em(0.5).At(12)+colGap) {
+		t.Errorf("expected second column at x=%v, got %v", Em(0.5).At(12)+colGap, bbb.Pos.X)
+	}
+
+	// Rows are stacked with the row gap in between.
+	rowGap := defaultRowGap.At(12)
+	if want := 2*Abs(12) + rowGap; !approxEqual(body.Height(), want) {
+		t.Errorf("expected body height %v, got %v", want, body.Height())
+	}
+}
+
+func TestLayoutMatAugment(t *testing.T) {
+	mat := &libmath.MatElem{
+		Rows: [][]foundations.Content{
+			{textContent("1"), textContent("2"), textContent("3")},
+			{textContent("4"), textContent("5"), textContent("6")},
+		},
+		Augment: &libmath.Augment{HLine: []int{1}, VLine: []int{-1}},
+	}
+
+	frame := LayoutMat(mat, testContext(), DefaultMathConstants())
+
+	var hlines, vlines int
+	for _, entry := range frame.Items {
+		line, ok := entry.Item.(LineItem)
+		if !ok {
+			continue
+		}
+		if line.Vertical {
+			vlines++
+			if line.Length != frame.Height() {
+				t.Errorf("expected vertical line of length %v, got %v", frame.Height(), line.Length)
+			}
+		} else {
+			hlines++
+		}
+	}
+	if hlines != 1 || vlines != 1 {
+		t.Errorf("expected 1 horizontal and 1 vertical line, got %d and %d", hlines, vlines)
+	}
+}
+
+func TestLayoutMatAugmentStroke(t *testing.T) {
+	thickness := foundations.Length{Points: 2}
+	mat := &libmath.MatElem{
+		Rows: [][]foundations.Content{
+			{textContent("1"), textContent("2")},
+			{textContent("3"), textContent("4")},
+		},
+		Augment: &libmath.Augment{
+			HLine:  []int{1},
+			VLine:  []int{1},
+			Stroke: &visualize.Stroke{Paint: foundations.NewRgbaFromBytes(255, 0, 0, 255), Thickness: &thickness},
+		},
+	}
+
+	frame := LayoutMat(mat, testContext(), DefaultMathConstants())
+
+	var lines int
+	for _, entry := range frame.Items {
+		line, ok := entry.Item.(LineItem)
+		if !ok {
+			continue
+		}
+		lines++
+		if line.Thickness != 2 {
+			t.Errorf("expected a thickness of 2pt, got %v", line.Thickness)
+		}
+		if line.Paint == nil || line.Paint.Color == nil || *line.Paint.Color != (layout.Color{R: 255, A: 255}) {
+			t.Errorf("expected a red line, got %+v", line.Paint)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+}
+
+func TestLayoutVec(t *testing.T) {
+	vec := &libmath.VecElem{
+		Children: []foundations.Content{textContent("x"), textContent("y"), textContent("z")},
+		Delim:    libmath.Delimiters{Open: "[", Close: "]"},
+	}
+
+	frame := LayoutVec(vec, testContext(), DefaultMathConstants())
+
+	if open := delimiterText(frame.Items[0]); open != "[" {
+		t.Errorf("expected opening bracket, got %q", open)
+	}
+	// The vector is centered on the math axis.
+	axis := DefaultMathConstants().AxisHeight.At(12)
+	if !approxEqual(frame.Baseline, frame.Height()/2+axis) {
+		t.Errorf("expected baseline %v, got %v", frame.Height()/2+axis, frame.Baseline)
+	}
+}
+
+func TestLayoutCases(t *testing.T) {
+	cases := &libmath.CasesElem{
+		Children: []foundations.Content{textContent("1"), textContent("0 else")},
+		Delim:    libmath.DefaultCasesDelim,
+	}
+
+	frame := LayoutCases(cases, testContext(), DefaultMathConstants())
+
+	if len(frame.Items) != 2 {
+		t.Fatalf("expected delimiter and body, got %d items", len(frame.Items))
+	}
+	if open := delimiterText(frame.Items[0]); open != "{" {
+		t.Errorf("expected opening brace, got %q", open)
+	}
+	body := frame.Items[1].Item.(ChildFrame).Frame
+	for _, cell := range body.Items {
+		if cell.Pos.X != 0 {
+			t.Errorf("expected start-aligned branch, got x=%v", cell.Pos.X)
+		}
+	}
+
+	cases.Reverse = true
+	frame = LayoutCases(cases, testContext(), DefaultMathConstants())
+	if closing := delimiterText(frame.Items[1]); closing != "}" {
+		t.Errorf("expected closing brace for reversed cases, got %q", closing)
+	}
+}
+
+func TestLayoutBinom(t *testing.T) {
+	binom := &libmath.BinomElem{
+		Upper: textContent("n"),
+		Lower: []foundations.Content{textContent("k")},
+	}
+
+	frame := LayoutBinom(binom, testContext(), DefaultMathConstants())
+
+	if len(frame.Items) != 3 {
+		t.Fatalf("expected delimiters around the stack, got %d items", len(frame.Items))
+	}
+	stack := frame.Items[1].Item.(ChildFrame).Frame
+	for _, entry := range stack.Items {
+		if _, ok := entry.Item.(LineItem); ok {
+			t.Error("binomial should not have a fraction bar")
+		}
+	}
+}
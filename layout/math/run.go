@@ -0,0 +1,142 @@
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
+)
+
+// layoutRun lays out elements next to each other and inserts the spacing
+// that TeX prescribes between adjacent atoms based on their math classes.
+//
+// Matches Rust: MathRun::into_line_frame in typst-layout/src/math/run.rs
+func layoutRun(elements []foundations.ContentElement, ctx *MathContext, constants MathConstants) *MathFrame {
+	frames := make([]*MathFrame, len(elements))
+	for i, elem := range elements {
+		frames[i] = LayoutElement(elem, ctx, constants)
+	}
+	return joinSpaced(frames, runGaps(elements, ctx))
+}
+
+// runGaps returns the space to insert before each element of a run.
+func runGaps(elements []foundations.ContentElement, ctx *MathContext) []Abs {
+	fontSize := ctx.FontSizeForStyle(ctx.Style)
+	classes := resolveClasses(elements)
+	gaps := make([]Abs, len(elements))
+	for i := 1; i < len(elements); i++ {
+		gaps[i] = GetSpacingAbs(classes[i-1], classes[i], ctx.Style, fontSize)
+	}
+	return gaps
+}
+
+// resolveClasses determines the math class of each element of a run. A
+// binary operator that has no operand on one of its sides is treated as an
+// ordinary atom, like the minus in "-x" (TeXbook, Appendix G, rules 5 and
+// 6).
+func resolveClasses(elements []foundations.ContentElement) []MathClass {
+	classes := make([]MathClass, len(elements))
+	for i, elem := range elements {
+		classes[i] = elementClass(elem)
+	}
+	for i, class := range classes {
+		if class != ClassBin {
+			continue
+		}
+		if i == 0 || i == len(classes)-1 {
+			classes[i] = ClassOrd
+			continue
+		}
+		switch classes[i-1] {
+		case ClassBin, ClassOp, ClassRel, ClassOpen, ClassPunct:
+			classes[i] = ClassOrd
+			continue
+		}
+		switch classes[i+1] {
+		case ClassRel, ClassClose, ClassPunct:
+			classes[i] = ClassOrd
+		}
+	}
+	return classes
+}
+
+// elementClass returns the math class of an element.
+func elementClass(elem foundations.ContentElement) MathClass {
+	switch e := elem.(type) {
+	case *libtext.TextElem:
+		return textClass(e.Body)
+	case *foundations.SymbolElem:
+		return textClass(e.Text)
+	case *libmath.OpElem:
+		return ClassOp
+	case *libmath.AttachElem:
+		return contentClass(&e.Base)
+	case *libmath.LimitsElem:
+		return contentClass(&e.Body)
+	case *libmath.ScriptsElem:
+		return contentClass(&e.Body)
+	case *libmath.MathStyleElem:
+		return contentClass(&e.Body)
+	case *libmath.FracElem, *libmath.BinomElem, *libmath.LrElem:
+		return ClassInner
+	case *libmath.MidElem:
+		return ClassRel
+	default:
+		return ClassOrd
+	}
+}
+
+// contentClass returns the class of content consisting of a single
+// element. Other content is ordinary.
+func contentClass(content *foundations.Content) MathClass {
+	if len(content.Elements) == 1 {
+		return elementClass(content.Elements[0])
+	}
+	return ClassOrd
+}
+
+// textClass returns the class of a single character. Longer text, like
+// numbers and words, is ordinary.
+func textClass(text string) MathClass {
+	runes := []rune(text)
+	if len(runes) != 1 {
+		return ClassOrd
+	}
+	return charClass(runes[0])
+}
+
+// charClass returns the default math class of a character.
+//
+// Matches Rust: fn default_math_class in typst-library/src/math/mod.rs
+func charClass(c rune) MathClass {
+	if libmath.IsLargeOperator(c) {
+		return ClassOp
+	}
+	switch c {
+	case '+', '-', '−', '*', '∗', '×', '÷', '·', '⋅', '∙', '∘', '±', '∓',
+		'∪', '∩', '∧', '∨', '⊕', '⊖', '⊗', '⊘', '⊙', '⊛', '⊎', '⊓', '⊔',
+		'∖', '⋆', '⋄', '≀', '⅋', '⟇', '⊞', '⊟', '⊠', '⊡', '⋉', '⋊', '⋋', '⋌':
+		return ClassBin
+	case '=', '<', '>', ':', '≤', '≥', '≠', '≈', '≡', '≢', '∼', '≃', '≅', '≍',
+		'≐', '≔', '∝', '≪', '≫', '≺', '≻', '⪯', '⪰', '≼', '≽', '⩽', '⩾', '≲', '≳',
+		'∈', '∉', '∋', '∌', '⊂', '⊃', '⊆', '⊇', '⊊', '⊋', '⊄', '⊅', '⊏', '⊐', '⊑', '⊒',
+		'∣', '∤', '∥', '∦', '⊢', '⊣', '⊨', '⊩', '⊧', '⊲', '⊳', '⊴', '⊵',
+		'←', '→', '↑', '↓', '↔', '↦', '↩', '↪', '⇐', '⇒', '⇔', '⇑', '⇓',
+		'⟵', '⟶', '⟷', '⟸', '⟹', '⟺', '⟼', '↗', '↘', '↙', '↖', '⇝', '↝':
+		return ClassRel
+	case '(', '[', '{', '⟨', '⟪', '⟦', '⌈', '⌊', '⌜', '⌞', '⦃', '⦇', '⦅', '⟮', '⎰', '⟅':
+		return ClassOpen
+	case ')', ']', '}', '⟩', '⟫', '⟧', '⌉', '⌋', '⌝', '⌟', '⦄', '⦈', '⦆', '⟯', '⎱', '⟆':
+		return ClassClose
+	case ',', ';':
+		return ClassPunct
+	}
+	return ClassOrd
+}
+
+// LayoutOp lays out a text operator. Its text is always upright.
+func LayoutOp(elem *libmath.OpElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	upright := *ctx
+	italic := false
+	upright.Italic = &italic
+	return LayoutContent(&elem.Text, &upright, constants)
+}
@@ -0,0 +1,129 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
+)
+
+func textElems(texts ...string) []foundations.ContentElement {
+	elems := make([]foundations.ContentElement, len(texts))
+	for i, text := range texts {
+		elems[i] = &libtext.TextElem{Body: text}
+	}
+	return elems
+}
+
+func TestResolveClasses(t *testing.T) {
+	tests := []struct {
+		texts []string
+		want  []MathClass
+	}{
+		{[]string{"a", "+", "b"}, []MathClass{ClassOrd, ClassBin, ClassOrd}},
+		// A leading binary operator is unary.
+		{[]string{"-", "x"}, []MathClass{ClassOrd, ClassOrd}},
+		// So is one following a relation or an opening delimiter.
+		{[]string{"a", "=", "-", "b"}, []MathClass{ClassOrd, ClassRel, ClassOrd, ClassOrd}},
+		{[]string{"(", "-", "b", ")"}, []MathClass{ClassOpen, ClassOrd, ClassOrd, ClassClose}},
+		// A binary operator before a closing delimiter or at the end too.
+		{[]string{"a", "+", ")"}, []MathClass{ClassOrd, ClassOrd, ClassClose}},
+		{[]string{"a", "+"}, []MathClass{ClassOrd, ClassOrd}},
+		{[]string{"x", ",", "y"}, []MathClass{ClassOrd, ClassPunct, ClassOrd}},
+		{[]string{"12", "∑"}, []MathClass{ClassOrd, ClassOp}},
+	}
+	for _, tt := range tests {
+		got := resolveClasses(textElems(tt.texts...))
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("resolveClasses(%q)[%d] = %v, want %v", tt.texts, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestLayoutRunSpacing(t *testing.T) {
+	ctx := &MathContext{FontSize: 18, Style: StyleText}
+	constants := DefaultMathConstants()
+	em := Em(1).At(18)
+
+	gapBetween := func(texts ...string) Abs {
+		frame := layoutRun(textElems(texts...), ctx, constants)
+		var sum Abs
+		for _, entry := range frame.Items {
+			sum += entry.Item.(ChildFrame).Frame.Width()
+		}
+		return frame.Width() - sum
+	}
+
+	// Medium spaces around binary operators, thick around relations.
+	if got, want := gapBetween("a", "+", "b"), 2*SpaceMedium.Amount().At(18); !approxEqual(got, want) {
+		t.Errorf("a+b spacing = %v, want %v", got, want)
+	}
+	if got, want := gapBetween("a", "=", "b"), 2*SpaceThick.Amount().At(18); !approxEqual(got, want) {
+		t.Errorf("a=b spacing = %v, want %v", got, want)
+	}
+	// No space around a unary minus.
+	if got := gapBetween("-", "b"); !approxEqual(got, 0) {
+		t.Errorf("-b spacing = %v, want 0", got)
+	}
+	// Thin space after a comma.
+	if got, want := gapBetween("x", ",", "y"), em*3/18; !approxEqual(got, want) {
+		t.Errorf("x,y spacing = %v, want %v", got, want)
+	}
+
+	// Relations lose their spacing in script style.
+	script := ctx.WithStyle(StyleScript, false)
+	frame := layoutRun(textElems("a", "=", "b"), script, constants)
+	var sum Abs
+	for _, entry := range frame.Items {
+		sum += entry.Item.(ChildFrame).Frame.Width()
+	}
+	if !approxEqual(frame.Width(), sum) {
+		t.Errorf("script relation spacing = %v, want 0", frame.Width()-sum)
+	}
+}
+
+func TestLayoutOp(t *testing.T) {
+	ctx := &MathContext{FontSize: 12, Style: StyleText}
+	constants := DefaultMathConstants()
+	op := &libmath.OpElem{Text: mathSymbol("d")}
+
+	frame := LayoutElement(op, ctx, constants)
+	if text := frame.Items[0].Item.(TextItem).Text; text != "d" {
+		t.Errorf("operator text should be upright, got %q", text)
+	}
+
+	// A thin space separates an operator from its argument, but not from
+	// parentheses.
+	sin := &libmath.OpElem{Text: mathSymbol("sin")}
+	withArg := layoutRun([]foundations.ContentElement{sin, &libtext.TextElem{Body: "x"}}, ctx, constants)
+	if got := withArg.Items[1].Pos.X - withArg.Items[0].Item.(ChildFrame).Frame.Width(); !approxEqual(got, SpaceThin.Amount().At(12)) {
+		t.Errorf("sin x spacing = %v, want thin space", got)
+	}
+	withParen := layoutRun([]foundations.ContentElement{sin, &libtext.TextElem{Body: "("}}, ctx, constants)
+	if got := withParen.Items[1].Pos.X - withParen.Items[0].Item.(ChildFrame).Frame.Width(); !approxEqual(got, 0) {
+		t.Errorf("sin( spacing = %v, want 0", got)
+	}
+}
+
+func TestLayoutOpLimits(t *testing.T) {
+	constants := DefaultMathConstants()
+	lim := foundations.Content{Elements: []foundations.ContentElement{
+		&libmath.OpElem{Text: mathSymbol("lim"), Limits: true},
+	}}
+	attach := &libmath.AttachElem{Base: lim, B: mathText("n")}
+
+	display := LayoutAttach(attach, &MathContext{FontSize: 12, Style: StyleDisplay}, constants)
+	inline := LayoutAttach(attach, &MathContext{FontSize: 12, Style: StyleText}, constants)
+
+	limText := mathSymbol("lim")
+	body := LayoutContent(&limText, &MathContext{FontSize: 12, Style: StyleDisplay}, constants)
+	if !approxEqual(display.Width(), body.Width()) {
+		t.Errorf("display limits width = %v, want %v", display.Width(), body.Width())
+	}
+	if inline.Width() <= body.Width() {
+		t.Errorf("inline attachments should be scripts, width %v", inline.Width())
+	}
+}
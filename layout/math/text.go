@@ -0,0 +1,309 @@
+package math
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	libmath "github.com/boergens/gotypst/library/math"
+)
+
+// LayoutMathStyle lays out content with a changed font variant, weight, or
+// slant. Properties the element leaves unset are inherited.
+func LayoutMathStyle(elem *libmath.MathStyleElem, ctx *MathContext, constants MathConstants) *MathFrame {
+	styled := *ctx
+	if elem.Variant != nil {
+		styled.Variant = *elem.Variant
+	}
+	if elem.Bold != nil {
+		styled.Bold = *elem.Bold
+	}
+	if elem.Italic != nil {
+		italic := *elem.Italic
+		styled.Italic = &italic
+	}
+	return LayoutContent(&elem.Body, &styled, constants)
+}
+
+// styleText remaps the characters of math text to the alphabet selected by
+// the context. Single characters are italicized by default if they are
+// letters; longer text like operator names stays upright.
+func styleText(text string, ctx *MathContext) string {
+	return StyledText(text, ctx.Variant, ctx.Bold, ctx.Italic)
+}
+
+// StyledText remaps the characters of math text to the Unicode
+// mathematical alphanumeric symbols for the given variant, weight, and
+// slant. A nil italic means auto: single letters are italic.
+func StyledText(text string, variant libmath.MathVariant, bold bool, italic *bool) string {
+	autoItalic := utf8.RuneCountInString(text) == 1
+	var b strings.Builder
+	for _, c := range text {
+		b.WriteRune(styledChar(c, variant, bold, italic, autoItalic))
+	}
+	return b.String()
+}
+
+// styledChar maps a character to its counterpart in the Unicode
+// mathematical alphanumeric symbols for the given variant, weight, and
+// slant. A nil italic means auto: letters are italic in the serif and sans
+// variants if autoItalic is set.
+//
+// Matches Rust: fn styled_char in typst-layout/src/math/text.rs
+func styledChar(c rune, variant libmath.MathVariant, bold bool, italic *bool, autoItalic bool) rune {
+	isItalic := autoItalic && isAutoItalic(c) &&
+		(variant == libmath.VariantSerif || variant == libmath.VariantSans)
+	if italic != nil {
+		isItalic = *italic
+	}
+
+	if r, ok := basicException(c); ok {
+		return r
+	}
+	if r, ok := latinException(c, variant, bold, isItalic); ok {
+		return r
+	}
+	if r, ok := greekException(c, variant, bold, isItalic); ok {
+		return r
+	}
+
+	var base, start rune
+	switch {
+	case c >= 'A' && c <= 'Z':
+		base = 'A'
+		start = latinUpperStart(variant, bold, isItalic)
+	case c >= 'a' && c <= 'z':
+		base = 'a'
+		start = latinLowerStart(variant, bold, isItalic)
+	case c >= 'Α' && c <= 'Ω':
+		base = 'Α'
+		start = greekUpperStart(variant, bold, isItalic)
+	case c >= 'α' && c <= 'ω':
+		base = 'α'
+		start = greekLowerStart(variant, bold, isItalic)
+	case c >= 'א' && c <= 'ד':
+		// Hebrew alef to dalet map to the letterlike symbols.
+		base = 'א'
+		start = 0x2135
+	case c >= '0' && c <= '9':
+		base = '0'
+		start = digitStart(variant, bold)
+	default:
+		return c
+	}
+	if start == 0 {
+		return c
+	}
+	return start + (c - base)
+}
+
+// isAutoItalic reports whether c is italicized by default.
+func isAutoItalic(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= 'α' && c <= 'ω':
+		return true
+	}
+	switch c {
+	case 'ħ', 'ı', 'ȷ', '∂', 'ϵ', 'ϑ', 'ϰ', 'ϕ', 'ϱ', 'ϖ':
+		return true
+	}
+	return false
+}
+
+func latinUpperStart(variant libmath.MathVariant, bold, italic bool) rune {
+	switch variant {
+	case libmath.VariantSerif:
+		return pickStart(bold, italic, 0x0041, 0x1D400, 0x1D434, 0x1D468)
+	case libmath.VariantSans:
+		return pickStart(bold, italic, 0x1D5A0, 0x1D5D4, 0x1D608, 0x1D63C)
+	case libmath.VariantCal:
+		return pickStart(bold, false, 0x1D49C, 0x1D4D0, 0, 0)
+	case libmath.VariantFrak:
+		return pickStart(bold, false, 0x1D504, 0x1D56C, 0, 0)
+	case libmath.VariantMono:
+		return 0x1D670
+	case libmath.VariantBb:
+		return 0x1D538
+	}
+	return 0
+}
+
+func latinLowerStart(variant libmath.MathVariant, bold, italic bool) rune {
+	switch variant {
+	case libmath.VariantSerif:
+		return pickStart(bold, italic, 0x0061, 0x1D41A, 0x1D44E, 0x1D482)
+	case libmath.VariantSans:
+		return pickStart(bold, italic, 0x1D5BA, 0x1D5EE, 0x1D622, 0x1D656)
+	case libmath.VariantCal:
+		return pickStart(bold, false, 0x1D4B6, 0x1D4EA, 0, 0)
+	case libmath.VariantFrak:
+		return pickStart(bold, false, 0x1D51E, 0x1D586, 0, 0)
+	case libmath.VariantMono:
+		return 0x1D68A
+	case libmath.VariantBb:
+		return 0x1D552
+	}
+	return 0
+}
+
+func greekUpperStart(variant libmath.MathVariant, bold, italic bool) rune {
+	switch variant {
+	case libmath.VariantSerif:
+		return pickStart(bold, italic, 0x0391, 0x1D6A8, 0x1D6E2, 0x1D71C)
+	case libmath.VariantSans:
+		// Sans-serif Greek only exists in bold.
+		return pickStart(true, italic, 0, 0x1D756, 0, 0x1D790)
+	}
+	return 0
+}
+
+func greekLowerStart(variant libmath.MathVariant, bold, italic bool) rune {
+	switch variant {
+	case libmath.VariantSerif:
+		return pickStart(bold, italic, 0x03B1, 0x1D6C2, 0x1D6FC, 0x1D736)
+	case libmath.VariantSans:
+		return pickStart(true, italic, 0, 0x1D770, 0, 0x1D7AA)
+	}
+	return 0
+}
+
+func digitStart(variant libmath.MathVariant, bold bool) rune {
+	switch variant {
+	case libmath.VariantSerif:
+		return pickStart(bold, false, 0x0030, 0x1D7CE, 0, 0)
+	case libmath.VariantSans:
+		return pickStart(bold, false, 0x1D7E2, 0x1D7EC, 0, 0)
+	case libmath.VariantMono:
+		return 0x1D7F6
+	case libmath.VariantBb:
+		return 0x1D7D8
+	}
+	return 0
+}
+
+// pickStart selects the start of an alphabet by weight and slant.
+func pickStart(bold, italic bool, regular, boldStart, italicStart, boldItalic rune) rune {
+	switch {
+	case bold && italic:
+		return boldItalic
+	case bold:
+		return boldStart
+	case italic:
+		return italicStart
+	default:
+		return regular
+	}
+}
+
+// basicException maps CJK angle brackets to their math counterparts.
+func basicException(c rune) (rune, bool) {
+	switch c {
+	case '〈':
+		return '⟨', true
+	case '〉':
+		return '⟩', true
+	case '《':
+		return '⟪', true
+	case '》':
+		return '⟫', true
+	}
+	return 0, false
+}
+
+// Latin letters that are encoded in the letterlike symbols block.
+var (
+	calExceptions = map[rune]rune{
+		'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+		'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ',
+	}
+	frakExceptions     = map[rune]rune{'C': 'ℭ', 'H': 'ℌ', 'I': 'ℑ', 'R': 'ℜ', 'Z': 'ℨ'}
+	bbExceptions       = map[rune]rune{'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ'}
+	bbItalicExceptions = map[rune]rune{'D': 'ⅅ', 'd': 'ⅆ', 'e': 'ⅇ', 'i': 'ⅈ', 'j': 'ⅉ'}
+)
+
+// latinException handles Latin letters whose styled forms live in the
+// letterlike symbols block rather than the mathematical alphanumerics.
+func latinException(c rune, variant libmath.MathVariant, bold, italic bool) (rune, bool) {
+	switch variant {
+	case libmath.VariantCal:
+		if bold {
+			return 0, false
+		}
+		r, ok := calExceptions[c]
+		return r, ok
+	case libmath.VariantFrak:
+		if bold {
+			return 0, false
+		}
+		r, ok := frakExceptions[c]
+		return r, ok
+	case libmath.VariantBb:
+		if r, ok := bbExceptions[c]; ok {
+			return r, true
+		}
+		if italic {
+			r, ok := bbItalicExceptions[c]
+			return r, ok
+		}
+	case libmath.VariantSerif:
+		if !italic {
+			return 0, false
+		}
+		switch c {
+		case 'h':
+			if !bold {
+				return 'ℎ', true
+			}
+		case 'ħ':
+			return 'ℏ', true
+		case 'ı':
+			return '𝚤', true
+		case 'ȷ':
+			return '𝚥', true
+		}
+	}
+	return 0, false
+}
+
+// greekExceptions lists Greek letters and symbols outside the contiguous
+// ranges, as bold, italic, bold italic, sans bold, sans bold italic, and
+// double-struck forms.
+var greekExceptions = map[rune][6]rune{
+	'ϴ': {'𝚹', '𝛳', '𝜭', '𝝧', '𝞡', 'ϴ'},
+	'∇': {'𝛁', '𝛻', '𝜵', '𝝯', '𝞩', '∇'},
+	'∂': {'𝛛', '𝜕', '𝝏', '𝞉', '𝟃', '∂'},
+	'ϵ': {'𝛜', '𝜖', '𝝐', '𝞊', '𝟄', 'ϵ'},
+	'ϑ': {'𝛝', '𝜗', '𝝑', '𝞋', '𝟅', 'ϑ'},
+	'ϰ': {'𝛞', '𝜘', '𝝒', '𝞌', '𝟆', 'ϰ'},
+	'ϕ': {'𝛟', '𝜙', '𝝓', '𝞍', '𝟇', 'ϕ'},
+	'ϱ': {'𝛠', '𝜚', '𝝔', '𝞎', '𝟈', 'ϱ'},
+	'ϖ': {'𝛡', '𝜛', '𝝕', '𝞏', '𝟉', 'ϖ'},
+	'Γ': {'𝚪', '𝛤', '𝜞', '𝝘', '𝞒', 'ℾ'},
+	'γ': {'𝛄', '𝛾', '𝜸', '𝝲', '𝞬', 'ℽ'},
+	'Π': {'𝚷', '𝛱', '𝜫', '𝝥', '𝞟', 'ℿ'},
+	'π': {'𝛑', '𝜋', '𝝅', '𝝿', '𝞹', 'ℼ'},
+	'∑': {'∑', '∑', '∑', '∑', '∑', '⅀'},
+}
+
+// greekException handles Greek letters and symbols that are not covered by
+// the contiguous alphabet ranges or have double-struck forms.
+func greekException(c rune, variant libmath.MathVariant, bold, italic bool) (rune, bool) {
+	forms, ok := greekExceptions[c]
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case variant == libmath.VariantSerif && bold && !italic:
+		return forms[0], true
+	case variant == libmath.VariantSerif && !bold && italic:
+		return forms[1], true
+	case variant == libmath.VariantSerif && bold && italic:
+		return forms[2], true
+	case variant == libmath.VariantSans && !italic:
+		return forms[3], true
+	case variant == libmath.VariantSans && italic:
+		return forms[4], true
+	case variant == libmath.VariantBb:
+		return forms[5], true
+	}
+	return 0, false
+}
@@ -0,0 +1,82 @@
+package math
+
+import (
+	"testing"
+
+	libmath "github.com/boergens/gotypst/library/math"
+	libtext "github.com/boergens/gotypst/library/text"
+)
+
+func TestStyledChar(t *testing.T) {
+	upright, italic := false, true
+	tests := []struct {
+		c       rune
+		variant libmath.MathVariant
+		bold    bool
+		italic  *bool
+		auto    bool
+		want    rune
+	}{
+		{'x', libmath.VariantSerif, false, nil, true, '𝑥'},
+		{'x', libmath.VariantSerif, false, nil, false, 'x'},
+		{'x', libmath.VariantSerif, false, &upright, true, 'x'},
+		{'h', libmath.VariantSerif, false, nil, true, 'ℎ'},
+		{'A', libmath.VariantSerif, true, &upright, true, '𝐀'},
+		{'A', libmath.VariantSerif, true, nil, true, '𝑨'},
+		{'z', libmath.VariantSans, false, &upright, false, '𝗓'},
+		{'R', libmath.VariantBb, false, nil, true, 'ℝ'},
+		{'A', libmath.VariantBb, false, nil, true, '𝔸'},
+		{'d', libmath.VariantBb, false, &italic, true, 'ⅆ'},
+		{'L', libmath.VariantCal, false, nil, true, 'ℒ'},
+		{'A', libmath.VariantCal, false, nil, true, '𝒜'},
+		{'A', libmath.VariantCal, true, nil, true, '𝓐'},
+		{'g', libmath.VariantFrak, false, nil, true, '𝔤'},
+		{'H', libmath.VariantFrak, false, nil, true, 'ℌ'},
+		{'a', libmath.VariantMono, false, nil, true, '𝚊'},
+		{'1', libmath.VariantSerif, true, nil, true, '𝟏'},
+		{'1', libmath.VariantBb, false, nil, true, '𝟙'},
+		{'α', libmath.VariantSerif, false, nil, true, '𝛼'},
+		{'α', libmath.VariantSerif, true, &upright, true, '𝛂'},
+		{'π', libmath.VariantBb, false, nil, true, 'ℼ'},
+		{'∂', libmath.VariantSerif, true, nil, true, '𝝏'},
+		{'α', libmath.VariantFrak, false, nil, true, 'α'},
+		{'+', libmath.VariantBb, true, nil, true, '+'},
+	}
+	for _, tt := range tests {
+		got := styledChar(tt.c, tt.variant, tt.bold, tt.italic, tt.auto)
+		if got != tt.want {
+			t.Errorf("styledChar(%q, %v, bold=%v) = %q, want %q", tt.c, tt.variant, tt.bold, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutMathStyle(t *testing.T) {
+	bb := libmath.VariantBb
+	bold := true
+	elem := &libmath.MathStyleElem{Body: *mathText("R"), Variant: &bb}
+	ctx := &MathContext{FontSize: 12, Style: StyleText}
+
+	frame := LayoutElement(elem, ctx, DefaultMathConstants())
+	if text := frame.Items[0].Item.(TextItem).Text; text != "ℝ" {
+		t.Errorf("expected double-struck R, got %q", text)
+	}
+
+	// Styles nest: bold inside sans.
+	sans := libmath.VariantSans
+	nested := &libmath.MathStyleElem{Body: *mathText("x"), Bold: &bold}
+	outer := &libmath.MathStyleElem{Variant: &sans}
+	outer.Body.Elements = append(outer.Body.Elements, nested)
+	frame = LayoutElement(outer, ctx, DefaultMathConstants())
+	if text := frame.Items[0].Item.(TextItem).Text; text != "𝙭" {
+		t.Errorf("expected sans bold italic x, got %q", text)
+	}
+
+	// Multi-letter text stays upright and is measured by characters.
+	frame = LayoutText(&libtext.TextElem{Body: "sin"}, ctx)
+	if text := frame.Items[0].Item.(TextItem).Text; text != "sin" {
+		t.Errorf("expected upright operator name, got %q", text)
+	}
+	if !approxEqual(frame.Width(), 3*Em(0.5).At(12)) {
+		t.Errorf("width = %v, want %v", frame.Width(), 3*Em(0.5).At(12))
+	}
+}
@@ -1,7 +1,9 @@
 package math
 
 import (
+	"github.com/boergens/gotypst/font"
 	"github.com/boergens/gotypst/layout"
+	libmath "github.com/boergens/gotypst/library/math"
 )
 
 // Abs is an alias for layout.Abs for convenience.
@@ -60,10 +62,28 @@ type LineItem struct {
 	Length Abs
 	// Thickness is the line thickness.
 	Thickness Abs
+	// Vertical indicates that the line runs downwards from its position
+	// instead of to the right (e.g., matrix augmentation lines).
+	Vertical bool
+	// Paint is the color of the line, or nil for the text color.
+	Paint *layout.Paint
 }
 
 func (LineItem) isMathFrameItem() {}
 
+// GlyphItem represents a glyph of the math font, identified by its ID. It is
+// used for glyph variants and assembly parts that have no Unicode mapping.
+type GlyphItem struct {
+	// Font is the font the glyph belongs to.
+	Font *font.Font
+	// Glyph is the glyph ID.
+	Glyph uint16
+	// FontSize is the font size for this glyph.
+	FontSize Abs
+}
+
+func (GlyphItem) isMathFrameItem() {}
+
 // ChildFrame represents a nested math frame.
 type ChildFrame struct {
 	// Frame is the nested frame.
@@ -100,6 +120,28 @@ type MathContext struct {
 	Style MathStyle
 	// Cramped indicates if the style is cramped (affects superscript positioning).
 	Cramped bool
+	// Font is the math font used for glyph variants and assemblies. If nil,
+	// approximate metrics are used.
+	Font *font.Font
+	// Variant is the current math alphabet.
+	Variant libmath.MathVariant
+	// Bold indicates that letters and digits are set in bold.
+	Bold bool
+	// Italic forces letters to be italic or upright. If nil, single
+	// letters are italic.
+	Italic *bool
+	// ScriptScale and ScriptScriptScale scale the font size in script and
+	// script-script styles. If zero, 70% and 50% are used.
+	ScriptScale       float64
+	ScriptScriptScale float64
+}
+
+// WithStyle returns a copy of the context with the given style.
+func (ctx *MathContext) WithStyle(style MathStyle, cramped bool) *MathContext {
+	c := *ctx
+	c.Style = style
+	c.Cramped = cramped
+	return &c
 }
 
 // FontSizeForStyle returns the font size for a given math style.
@@ -108,8 +150,14 @@ func (ctx *MathContext) FontSizeForStyle(style MathStyle) Abs {
 	case StyleDisplay, StyleText:
 		return ctx.FontSize
 	case StyleScript:
+		if ctx.ScriptScale > 0 {
+			return ctx.FontSize * Abs(ctx.ScriptScale)
+		}
 		return ctx.FontSize * 0.7 // 70% of base size
 	case StyleScriptScript:
+		if ctx.ScriptScriptScale > 0 {
+			return ctx.FontSize * Abs(ctx.ScriptScriptScale)
+		}
 		return ctx.FontSize * 0.5 // 50% of base size
 	default:
 		return ctx.FontSize
@@ -146,20 +194,102 @@ type MathConstants struct {
 
 	// StackGapMin is the minimum gap between stacked elements.
 	StackGapMin Em
+
+	// SuperscriptShiftUp is the standard shift up applied to superscripts.
+	SuperscriptShiftUp Em
+
+	// SuperscriptShiftUpCramped is the superscript shift in cramped styles.
+	SuperscriptShiftUpCramped Em
+
+	// SubscriptShiftDown is the standard shift down applied to subscripts.
+	SubscriptShiftDown Em
+
+	// SubSuperscriptGapMin is the minimum gap between a superscript's bottom
+	// and a subscript's top.
+	SubSuperscriptGapMin Em
+
+	// SpaceAfterScript is the extra space after a subscript or superscript.
+	SpaceAfterScript Em
+
+	// UpperLimitGapMin is the minimum gap between an operator and its upper limit.
+	UpperLimitGapMin Em
+
+	// UpperLimitBaselineRiseMin is the minimum distance between the operator's
+	// top and the upper limit's baseline.
+	UpperLimitBaselineRiseMin Em
+
+	// LowerLimitGapMin is the minimum gap between an operator and its lower limit.
+	LowerLimitGapMin Em
+
+	// LowerLimitBaselineDropMin is the minimum distance between the operator's
+	// bottom and the lower limit's baseline.
+	LowerLimitBaselineDropMin Em
+
+	// AccentBaseHeight is the maximum height of a base that does not require
+	// raising the accent.
+	AccentBaseHeight Em
+
+	// DisplayOperatorMinHeight is the minimum height of large operators in
+	// display style.
+	DisplayOperatorMinHeight Em
 }
 
 // DefaultMathConstants returns the default math typography constants.
 // These are based on traditional TeX values and common math fonts.
 func DefaultMathConstants() MathConstants {
 	return MathConstants{
-		AxisHeight:                   Em(0.25),  // Quarter of em
-		FractionRuleThickness:        Em(0.04),  // 4% of em
-		FractionNumeratorGapMin:      Em(0.10),  // 10% of em
-		FractionDenominatorGapMin:    Em(0.10),  // 10% of em
-		FractionNumeratorShiftUp:     Em(0.68),  // ~2/3 em for display style
-		FractionDenominatorShiftDown: Em(0.68),  // ~2/3 em for display style
-		StackTopShiftUp:              Em(0.34),  // ~1/3 em for inline style
-		StackBottomShiftDown:         Em(0.34),  // ~1/3 em for inline style
-		StackGapMin:                  Em(0.20),  // 20% of em
+		AxisHeight:                   Em(0.25), // Quarter of em
+		FractionRuleThickness:        Em(0.04), // 4% of em
+		FractionNumeratorGapMin:      Em(0.10), // 10% of em
+		FractionDenominatorGapMin:    Em(0.10), // 10% of em
+		FractionNumeratorShiftUp:     Em(0.68), // ~2/3 em for display style
+		FractionDenominatorShiftDown: Em(0.68), // ~2/3 em for display style
+		StackTopShiftUp:              Em(0.34), // ~1/3 em for inline style
+		StackBottomShiftDown:         Em(0.34), // ~1/3 em for inline style
+		StackGapMin:                  Em(0.20), // 20% of em
+		SuperscriptShiftUp:           Em(0.363),
+		SuperscriptShiftUpCramped:    Em(0.289),
+		SubscriptShiftDown:           Em(0.247),
+		SubSuperscriptGapMin:         Em(0.16),
+		SpaceAfterScript:             Em(0.056),
+		UpperLimitGapMin:             Em(0.2),
+		UpperLimitBaselineRiseMin:    Em(0.3),
+		LowerLimitGapMin:             Em(0.167),
+		LowerLimitBaselineDropMin:    Em(0.6),
+		AccentBaseHeight:             Em(0.45),
+		DisplayOperatorMinHeight:     Em(1.3),
+	}
+}
+
+// MathConstantsFromFont derives the math constants from a font's OpenType
+// MATH table. Constants that the layout does not use are ignored.
+func MathConstantsFromFont(c *font.MathConstants) MathConstants {
+	if c == nil || c.UnitsPerEm == 0 {
+		return DefaultMathConstants()
+	}
+	em := func(v int16) Em {
+		return Em(float64(v) / float64(c.UnitsPerEm))
+	}
+	return MathConstants{
+		AxisHeight:                   em(c.AxisHeight),
+		FractionRuleThickness:        em(c.FractionRuleThickness),
+		FractionNumeratorGapMin:      em(c.FractionNumeratorGapMin),
+		FractionDenominatorGapMin:    em(c.FractionDenominatorGapMin),
+		FractionNumeratorShiftUp:     em(c.FractionNumeratorDisplayStyleShiftUp),
+		FractionDenominatorShiftDown: em(c.FractionDenominatorDisplayStyleShiftDown),
+		StackTopShiftUp:              em(c.StackTopShiftUp),
+		StackBottomShiftDown:         em(c.StackBottomShiftDown),
+		StackGapMin:                  em(c.StackGapMin),
+		SuperscriptShiftUp:           em(c.SuperscriptShiftUp),
+		SuperscriptShiftUpCramped:    em(c.SuperscriptShiftUpCramped),
+		SubscriptShiftDown:           em(c.SubscriptShiftDown),
+		SubSuperscriptGapMin:         em(c.SubSuperscriptGapMin),
+		SpaceAfterScript:             em(c.SpaceAfterScript),
+		UpperLimitGapMin:             em(c.UpperLimitGapMin),
+		UpperLimitBaselineRiseMin:    em(c.UpperLimitBaselineRiseMin),
+		LowerLimitGapMin:             em(c.LowerLimitGapMin),
+		LowerLimitBaselineDropMin:    em(c.LowerLimitBaselineDropMin),
+		AccentBaseHeight:             em(c.AccentBaseHeight),
+		DisplayOperatorMinHeight:     Em(float64(c.DisplayOperatorMinHeight) / float64(c.UnitsPerEm)),
 	}
 }
@@ -2,9 +2,9 @@ package pages
 
 import (
 	"strconv"
-	"strings"
 
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/model"
 )
 
 // Finalize pieces together the inner page frame and the marginals.
@@ -88,104 +88,14 @@ func Finalize(engine *Engine, counter *ManualPageCounter, tags *[]Tag, layouted
 	}, nil
 }
 
-// formatPageNumber formats a page number according to the given pattern.
-// Supported patterns:
-//   - "1" or "" - Arabic numerals (1, 2, 3, ...)
-//   - "i" - lowercase Roman numerals (i, ii, iii, iv, ...)
-//   - "I" - uppercase Roman numerals (I, II, III, IV, ...)
-//   - "a" - lowercase letters (a, b, c, ..., aa, ab, ...)
-//   - "A" - uppercase letters (A, B, C, ..., AA, AB, ...)
-//
-// The pattern may also include surrounding text, where the numeral placeholder
-// is replaced. For example, "- 1 -" becomes "- 5 -" for page 5.
+// formatPageNumber formats a page number with a numbering pattern like
+// "1", "i", or "- 1 -". An empty or invalid pattern yields arabic numerals.
 func formatPageNumber(pageNum int, pattern string) string {
-	if pattern == "" || pattern == "1" {
+	parsed, err := model.ParseNumberingPattern(pattern)
+	if err != nil {
 		return strconv.Itoa(pageNum)
 	}
-
-	// Check for specific format patterns
-	switch pattern {
-	case "i":
-		return toRomanLower(pageNum)
-	case "I":
-		return toRomanUpper(pageNum)
-	case "a":
-		return toLetterLower(pageNum)
-	case "A":
-		return toLetterUpper(pageNum)
-	}
-
-	// For patterns with surrounding text, find and replace the format specifier
-	// E.g., "Page 1 of N" -> "Page 5 of N"
-	if strings.Contains(pattern, "1") {
-		return strings.ReplaceAll(pattern, "1", strconv.Itoa(pageNum))
-	}
-	if strings.Contains(pattern, "i") {
-		return strings.ReplaceAll(pattern, "i", toRomanLower(pageNum))
-	}
-	if strings.Contains(pattern, "I") {
-		return strings.ReplaceAll(pattern, "I", toRomanUpper(pageNum))
-	}
-	if strings.Contains(pattern, "a") {
-		return strings.ReplaceAll(pattern, "a", toLetterLower(pageNum))
-	}
-	if strings.Contains(pattern, "A") {
-		return strings.ReplaceAll(pattern, "A", toLetterUpper(pageNum))
-	}
-
-	// Default to Arabic numerals
-	return strconv.Itoa(pageNum)
-}
-
-// toRomanUpper converts a number to uppercase Roman numerals.
-func toRomanUpper(n int) string {
-	if n <= 0 || n >= 4000 {
-		return strconv.Itoa(n) // Fallback for out of range
-	}
-
-	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
-	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
-
-	var result strings.Builder
-	for i, val := range values {
-		for n >= val {
-			result.WriteString(symbols[i])
-			n -= val
-		}
-	}
-	return result.String()
-}
-
-// toRomanLower converts a number to lowercase Roman numerals.
-func toRomanLower(n int) string {
-	return strings.ToLower(toRomanUpper(n))
-}
-
-// toLetterUpper converts a number to uppercase letters (A, B, ..., Z, AA, AB, ...).
-func toLetterUpper(n int) string {
-	if n <= 0 {
-		return strconv.Itoa(n)
-	}
-
-	var result strings.Builder
-	for n > 0 {
-		n-- // Make 1-indexed into 0-indexed
-		result.WriteByte(byte('A' + n%26))
-		n /= 26
-	}
-
-	// Reverse the string
-	s := result.String()
-	runes := []rune(s)
-	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-		runes[i], runes[j] = runes[j], runes[i]
-	}
-	return string(runes)
-}
-
-// toLetterLower converts a number to lowercase letters.
-func toLetterLower(n int) string {
-	return strings.ToLower(toLetterUpper(n))
+	return parsed.Apply([]int{pageNum})
 }
 
 // createPageNumberFrame creates a frame with centered page number text.
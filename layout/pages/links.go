@@ -0,0 +1,69 @@
+package pages
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/layout"
+)
+
+// ResolveLinks resolves the destinations of links to labels into positions
+// in the laid out document. A label resolves to the position of the first
+// start tag carrying it. It fails if a link points to a label that no
+// element has or to a page the document doesn't have.
+func ResolveLinks(docPages []Page) error {
+	labels := make(map[string]Position)
+	for i := range docPages {
+		collectLabels(&docPages[i].Frame, i, layout.Point{}, labels)
+	}
+	for i := range docPages {
+		if err := resolveFrameLinks(&docPages[i].Frame, labels, len(docPages)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectLabels records the position of the first start tag of each label
+// in the frame, which is at offset on the page with the given index.
+func collectLabels(frame *Frame, page int, offset layout.Point, labels map[string]Position) {
+	for _, positioned := range frame.Items {
+		pos := layout.Point{X: offset.X + positioned.Pos.X, Y: offset.Y + positioned.Pos.Y}
+		switch item := positioned.Item.(type) {
+		case GroupItem:
+			collectLabels(&item.Frame, page, pos, labels)
+		case TagItem:
+			if item.Tag.Kind != TagStart || item.Tag.Label == "" {
+				continue
+			}
+			if _, ok := labels[item.Tag.Label]; !ok {
+				labels[item.Tag.Label] = Position{Page: page, Point: pos}
+			}
+		}
+	}
+}
+
+// resolveFrameLinks replaces the label destinations of the frame's links
+// with the positions of their labels and checks positional destinations.
+func resolveFrameLinks(frame *Frame, labels map[string]Position, pageCount int) error {
+	for i := range frame.Items {
+		switch item := frame.Items[i].Item.(type) {
+		case GroupItem:
+			if err := resolveFrameLinks(&item.Frame, labels, pageCount); err != nil {
+				return err
+			}
+			frame.Items[i].Item = item
+		case LinkItem:
+			if item.Dest.Label != "" {
+				pos, ok := labels[item.Dest.Label]
+				if !ok {
+					return fmt.Errorf("label `<%s>` does not exist in the document", item.Dest.Label)
+				}
+				item.Dest = Destination{Position: &pos}
+				frame.Items[i].Item = item
+			} else if item.Dest.Position != nil && item.Dest.Position.Page >= pageCount {
+				return fmt.Errorf("page %d does not exist in the document", item.Dest.Position.Page+1)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package pages
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+)
+
+func TestResolveLinksToLabel(t *testing.T) {
+	target := Hard(layout.Size{Width: 100, Height: 100})
+	target.Push(layout.Point{X: 5, Y: 7}, TagItem{Tag: Tag{Kind: TagStart, Label: "intro"}})
+	second := Hard(layout.Size{Width: 200, Height: 200})
+	second.PushFrame(layout.Point{X: 10, Y: 20}, target)
+
+	first := Hard(layout.Size{Width: 200, Height: 200})
+	first.Push(layout.Point{}, LinkItem{Dest: Destination{Label: "intro"}})
+	docPages := []Page{{Frame: first}, {Frame: second}}
+
+	if err := ResolveLinks(docPages); err != nil {
+		t.Fatalf("ResolveLinks failed: %v", err)
+	}
+	link := docPages[0].Frame.Items[0].Item.(LinkItem)
+	want := Position{Page: 1, Point: layout.Point{X: 15, Y: 27}}
+	if link.Dest.Position == nil || *link.Dest.Position != want || link.Dest.Label != "" {
+		t.Errorf("resolved destination = %+v, want position %+v", link.Dest, want)
+	}
+}
+
+func TestResolveLinksErrors(t *testing.T) {
+	for _, dest := range []Destination{
+		{Label: "missing"},
+		{Position: &Position{Page: 1}},
+	} {
+		frame := Hard(layout.Size{Width: 100, Height: 100})
+		frame.Push(layout.Point{}, LinkItem{Dest: dest})
+		if err := ResolveLinks([]Page{{Frame: frame}}); err == nil {
+			t.Errorf("ResolveLinks(%+v): expected an error", dest)
+		}
+	}
+}
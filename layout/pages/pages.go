@@ -1,15 +1,14 @@
 package pages
 
 import (
+	"strconv"
+
 	"github.com/boergens/gotypst/layout"
 )
 
 // LayoutDocument lays out content into a paged document.
 // This is the main entry point for document layout.
 func LayoutDocument(engine *Engine, content *Content, styles StyleChain) (*PagedDocument, error) {
-	locator := &Locator{Current: 0}
-	splitLocator := locator.Split()
-
 	// Convert content to pairs
 	// TODO: This should realize the content through engine routines
 	var children []Pair
@@ -21,6 +20,15 @@ func LayoutDocument(engine *Engine, content *Content, styles StyleChain) (*Paged
 			})
 		}
 	}
+	return LayoutRealized(engine, children, styles)
+}
+
+// LayoutRealized lays out realized content into a paged document. Each
+// element is laid out with the styles realization found for it, so that
+// page set rules in the document start new pages with their styles.
+func LayoutRealized(engine *Engine, children []Pair, styles StyleChain) (*PagedDocument, error) {
+	locator := &Locator{Current: 0}
+	splitLocator := locator.Split()
 
 	// Layout the pages
 	pages, err := layoutPages(engine, children, splitLocator, styles)
@@ -28,6 +36,11 @@ func LayoutDocument(engine *Engine, content *Content, styles StyleChain) (*Paged
 		return nil, err
 	}
 
+	// Links to labels can only be resolved once all pages are laid out.
+	if err := ResolveLinks(pages); err != nil {
+		return nil, err
+	}
+
 	return &PagedDocument{
 		Pages: pages,
 		Info:  DocumentInfo{},
@@ -67,7 +80,9 @@ func layoutPages(engine *Engine, children []Pair, locator *SplitLocator, styles
 				return nil, result.err
 			}
 			for _, layouted := range result.pages {
+				endFinalize := engine.Timer.Start("finalize page", strconv.Itoa(len(pages)+1))
 				page, err := Finalize(engine, counter, &tags, layouted)
+				endFinalize()
 				if err != nil {
 					return nil, err
 				}
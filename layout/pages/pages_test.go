@@ -1,10 +1,12 @@
 package pages
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/boergens/gotypst/eval"
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
 )
 
 func TestLayoutDocumentEmpty(t *testing.T) {
@@ -808,7 +810,7 @@ func TestFormatPageNumber(t *testing.T) {
 		{26, "a", "z"},
 
 		// Patterns with surrounding text
-		{5, "Page 1", "Page 5"},
+		{5, "p. 1", "p. 5"},
 		{3, "- 1 -", "- 3 -"},
 	}
 
@@ -1018,3 +1020,33 @@ func TestLayoutMarginalNil(t *testing.T) {
 		t.Error("Expected nil frame for empty content")
 	}
 }
+
+func TestParallelizeKeepsOrder(t *testing.T) {
+	items := make([]RunItem, 8)
+	for i := range items {
+		items[i] = RunItem{Locator: Locator{Current: uint64(i)}}
+	}
+
+	engine := &Engine{Sink: foundations.NewSink(), Jobs: 4}
+	results := engine.Parallelize(items, func(e *Engine, run RunItem) ([]LayoutedPage, error) {
+		e.Warn(foundations.SourceDiagnostic{Message: fmt.Sprintf("run %d", run.Locator.Current)})
+		return []LayoutedPage{{Inner: Hard(layout.Size{Width: layout.Abs(run.Locator.Current)})}}, nil
+	})
+
+	for i, result := range results {
+		if result.err != nil {
+			t.Fatal(result.err)
+		}
+		if got := result.pages[0].Inner.Width(); got != layout.Abs(i) {
+			t.Errorf("result %d has width %v", i, got)
+		}
+	}
+	if len(engine.Sink.Warnings) != len(items) {
+		t.Fatalf("got %d warnings, want %d", len(engine.Sink.Warnings), len(items))
+	}
+	for i, warning := range engine.Sink.Warnings {
+		if want := fmt.Sprintf("run %d", i); warning.Message != want {
+			t.Errorf("warning %d = %q, want %q", i, warning.Message, want)
+		}
+	}
+}
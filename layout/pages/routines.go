@@ -0,0 +1,157 @@
+package pages
+
+import (
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/introspection"
+	"github.com/boergens/gotypst/library/pdf"
+	"github.com/boergens/gotypst/timing"
+)
+
+// Routines implements layout's part of foundations.Routines, through which
+// the library lays out content while it is evaluated, like the body of
+// measure().
+type Routines struct{}
+
+// LayoutFrame lays out content into a single frame in the region and
+// returns the frame's size. The content is realized through the engine's
+// routines first, so that its show rules apply.
+// Matches Rust: fn layout_frame in typst-layout/src/flow/mod.rs
+func (Routines) LayoutFrame(engine *foundations.Engine, content foundations.Content, styles *foundations.StyleChain, region foundations.Region) (foundations.Size, error) {
+	if err := engine.Route.CheckLayoutDepth(); err != nil {
+		return foundations.Size{}, err
+	}
+	engine.Route.Increase()
+	defer engine.Route.Decrease()
+
+	if styles == nil {
+		styles = foundations.EmptyStyleChain()
+	}
+	kind := foundations.FragmentBlock
+	pairs, err := engine.Routines.Realize(engine, foundations.LayoutFragment{Kind: &kind}, &foundations.SequenceElem{Children: []foundations.Content{content}}, styles)
+	if err != nil {
+		return foundations.Size{}, err
+	}
+
+	children := make([]Pair, 0, len(pairs))
+	for _, pair := range pairs {
+		child := Pair{Element: pair.Content}
+		if pair.Styles != nil {
+			child.Styles = *pair.Styles
+		}
+		children = append(children, child)
+	}
+
+	area := layout.Size{
+		Width:  layout.Abs(region.Size.Width.Points),
+		Height: layout.Abs(region.Size.Height.Points),
+	}
+	layoutEngine := &Engine{World: engine.World, Sink: engine.Sink}
+	frames, err := layoutFlow(layoutEngine, children, (&Locator{}).Split(), *styles, area)
+	if err != nil {
+		return foundations.Size{}, err
+	}
+
+	var size layout.Size
+	if len(frames) > 0 {
+		size = frames[0].Size
+	}
+	return foundations.Size{
+		Width:  foundations.Length{Points: float64(size.Width)},
+		Height: foundations.Length{Points: float64(size.Height)},
+	}, nil
+}
+
+// LayoutDocument realizes content as a document and lays it out into
+// pages. Document set rules fill in the document's info, and the files
+// embedded with pdf.embed become its attachments.
+// Matches Rust: fn layout_document in typst-layout/src/pages/mod.rs
+func (Routines) LayoutDocument(engine *foundations.Engine, content foundations.Content, jobs int, timer *timing.Timer) (*PagedDocument, error) {
+	info := &foundations.DocumentInfo{}
+	endRealize := timer.Start("realize", "")
+	pairs, err := engine.Routines.Realize(engine, foundations.LayoutDocument{Info: info}, &foundations.SequenceElem{Children: []foundations.Content{content}}, foundations.EmptyStyleChain())
+	endRealize()
+	if err != nil {
+		return nil, err
+	}
+	defer timer.Start("layout", "")()
+
+	children := make([]Pair, 0, len(pairs))
+	elements := make([]foundations.ContentElement, 0, len(pairs))
+	for _, pair := range pairs {
+		if pair.Content == nil {
+			continue
+		}
+		child := Pair{Element: pair.Content}
+		if pair.Styles != nil {
+			child.Styles = *pair.Styles
+		}
+		children = append(children, child)
+		elements = append(elements, pair.Content)
+	}
+
+	layoutEngine := &Engine{World: engine.World, Sink: engine.Sink, Jobs: jobs, Timer: timer}
+	doc, err := LayoutRealized(layoutEngine, children, StyleChain{})
+	if err != nil {
+		return nil, err
+	}
+	doc.Info = documentInfo(engine.World, info)
+	doc.Attachments = attachments(elements)
+	return doc, nil
+}
+
+// attachments collects the files embedded with pdf.embed in document
+// order.
+func attachments(elements []foundations.ContentElement) []Attachment {
+	introspector := introspection.NewIntrospector(elements)
+	var result []Attachment
+	for _, elem := range introspector.Query(foundations.ElemSelector{Element: foundations.Element{Name: "embed"}}) {
+		embed, ok := elem.(*pdf.EmbedElem)
+		if !ok {
+			continue
+		}
+		attachment := Attachment{Name: embed.Name(), Data: embed.Bytes}
+		if embed.MimeType != nil {
+			attachment.MimeType = *embed.MimeType
+		}
+		if embed.Description != nil {
+			attachment.Description = *embed.Description
+		}
+		if embed.Relationship != nil {
+			attachment.Relationship = *embed.Relationship
+		}
+		result = append(result, attachment)
+	}
+	return result
+}
+
+// documentInfo converts the metadata from document set rules into the
+// document's info. An automatic date is the current date.
+func documentInfo(world foundations.World, info *foundations.DocumentInfo) DocumentInfo {
+	result := DocumentInfo{
+		Author:   info.Author,
+		Keywords: info.Keywords,
+	}
+	if info.Title != nil {
+		title := info.Title.ToText()
+		result.Title = &title
+	}
+	if info.Description != nil {
+		description := info.Description.ToText()
+		result.Description = &description
+	}
+
+	var date *foundations.Datetime
+	switch v := info.Date.(type) {
+	case nil, foundations.AutoValue:
+		if world != nil {
+			date = world.Today(nil)
+		}
+	case *foundations.Datetime:
+		date = v
+	}
+	if date != nil && date.Year() != nil {
+		result.Date = &Date{Year: *date.Year(), Month: date.MonthOr(1), Day: date.DayOr(1)}
+	}
+	return result
+}
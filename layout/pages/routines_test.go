@@ -0,0 +1,81 @@
+package pages
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/kit"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/realize"
+	"github.com/boergens/gotypst/syntax"
+	"github.com/boergens/gotypst/timing"
+)
+
+// documentEngine creates an engine with the compiler's routines and
+// returns it with the content of the markup.
+func documentEngine(t *testing.T, markup string) (*foundations.Engine, foundations.Content) {
+	t.Helper()
+	world, err := kit.NewMemoryWorld("main.typ", map[string][]byte{"main.typ": nil}, kit.WithLibrary(eval.Library()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := foundations.NewEngine(world, foundations.RoutineSet{
+		Eval:        eval.Routines{},
+		Realization: realize.Routines{},
+		Layout:      Routines{},
+	})
+	value, err := engine.Routines.EvalString(engine, markup, syntax.Detached(), syntax.ModeMarkup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := value.(foundations.ContentValue)
+	return engine, content.Content
+}
+
+func TestRoutinesLayoutDocument(t *testing.T) {
+	for _, markup := range []string{"", "Hello, World!", "First text\n\nsecond text.", "= Title\nSome body text."} {
+		engine, content := documentEngine(t, markup)
+		doc, err := Routines{}.LayoutDocument(engine, content, 1, nil)
+		if err != nil {
+			t.Fatalf("%q: LayoutDocument failed: %v", markup, err)
+		}
+		if len(doc.Pages) == 0 {
+			t.Fatalf("%q: expected at least one page", markup)
+		}
+		for i, page := range doc.Pages {
+			if page.Frame.Width() <= 0 || page.Frame.Height() <= 0 || page.Number != i+1 {
+				t.Errorf("%q: page %d is %vx%v, number %d", markup, i, page.Frame.Width(), page.Frame.Height(), page.Number)
+			}
+		}
+	}
+}
+
+func TestRoutinesLayoutDocumentInfo(t *testing.T) {
+	engine, content := documentEngine(t, `#set document(title: [Report], author: "Ada", date: none)`)
+	doc, err := Routines{}.LayoutDocument(engine, content, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title == nil || *doc.Info.Title != "Report" || !slices.Equal(doc.Info.Author, []string{"Ada"}) || doc.Info.Date != nil {
+		t.Errorf("Info = %+v", doc.Info)
+	}
+}
+
+func TestRoutinesLayoutDocumentTimings(t *testing.T) {
+	engine, content := documentEngine(t, "Hello, World!")
+	timer := timing.NewTimer()
+	if _, err := (Routines{}).LayoutDocument(engine, content, 1, timer); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, event := range timer.Events() {
+		if !slices.Contains(names, event.Name) {
+			names = append(names, event.Name)
+		}
+	}
+	want := []string{"realize", "layout", "layout run", "finalize page"}
+	if !slices.Equal(names, want) {
+		t.Errorf("timed stages = %v, want %v", names, want)
+	}
+}
@@ -1,8 +1,14 @@
 package pages
 
 import (
+	"math"
+	"strconv"
+	"sync"
+
 	"github.com/boergens/gotypst/eval"
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/timing"
 )
 
 // LayoutBlankPage lays out a single blank page suitable for parity adjustment.
@@ -116,17 +122,74 @@ func layoutPageRunImpl(engine *Engine, children []Pair, locator Locator, initial
 type Engine struct {
 	// World provides access to fonts and files.
 	World interface{}
+	// Sink collects the warnings emitted during layout.
+	Sink *foundations.Sink
+	// Jobs is the maximum number of page runs laid out concurrently. With
+	// zero or one, runs are laid out one after another.
+	Jobs int
+	// Timer records how long page runs and pages take to lay out. If nil,
+	// nothing is recorded.
+	Timer *timing.Timer
 	// TODO: Add more engine fields as needed
 }
 
-// Parallelize runs layout tasks in parallel.
+// Warn emits a warning. Without a sink, the warning is dropped.
+func (e *Engine) Warn(warning foundations.SourceDiagnostic) {
+	if e.Sink != nil {
+		e.Sink.Warn(warning)
+	}
+}
+
+// Parallelize runs layout tasks in parallel, using up to Jobs goroutines.
+// The results are in the order of the items.
+//
+// Each task gets its own sink, and the sinks are merged into the engine's
+// sink in the order of the items afterwards, so that warnings come out the
+// same no matter how the tasks were scheduled. Each task is timed as a
+// page run, on the thread of the goroutine that ran it.
+//
+// Matches Rust: Engine::parallelize
 func (e *Engine) Parallelize(items []RunItem, fn func(*Engine, RunItem) ([]LayoutedPage, error)) []layoutResult {
 	results := make([]layoutResult, len(items))
-	// TODO: Implement parallel execution
-	// For now, run sequentially
-	for i, item := range items {
-		pages, err := fn(e, item)
-		results[i] = layoutResult{pages: pages, err: err}
+	if e.Jobs <= 1 || len(items) <= 1 {
+		for i, item := range items {
+			end := e.Timer.Start("layout run", strconv.Itoa(i+1))
+			pages, err := fn(e, item)
+			end()
+			results[i] = layoutResult{pages: pages, err: err}
+		}
+		return results
+	}
+
+	sinks := make([]*foundations.Sink, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for worker := range min(e.Jobs, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				forked := *e
+				forked.Sink = foundations.NewSink()
+				forked.Timer = e.Timer.Thread(worker + 1)
+				end := forked.Timer.Start("layout run", strconv.Itoa(i+1))
+				pages, err := fn(&forked, items[i])
+				end()
+				results[i] = layoutResult{pages: pages, err: err}
+				sinks[i] = forked.Sink
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if e.Sink != nil {
+		for _, sink := range sinks {
+			e.Sink.Extend(sink)
+		}
 	}
 	return results
 }
@@ -141,149 +204,153 @@ const (
 	paperA4Width layout.Abs = 595.276 // A4 width in points
 )
 
-// Helper functions for style resolution
+// Helper functions for style resolution. Page properties come from
+// `set page(..)` rules in the style chain.
 
 func resolveStyles(children []Pair, initial StyleChain) StyleChain {
 	// TODO: Merge styles from children with initial
 	return initial
 }
 
-func resolvePageWidth(styles StyleChain) layout.Abs {
-	if w := styles.Get("page.width"); w != nil {
-		if abs, ok := w.(layout.Abs); ok {
-			return abs
-		}
+// pageProperty returns a property of the page from its set rules, or nil.
+func pageProperty(styles *StyleChain, name string) foundations.Value {
+	return styles.Get("page", name)
+}
+
+// resolveLength resolves an absolute length, or returns the default for
+// anything else. Auto is an infinite length.
+func resolveLength(value foundations.Value, def layout.Abs) layout.Abs {
+	switch v := value.(type) {
+	case foundations.LengthValue:
+		return layout.Abs(v.Length.Points)
+	case foundations.AutoValue:
+		return layout.Abs(math.Inf(1))
 	}
-	return paperA4Width
+	return def
 }
 
-func resolvePageHeight(styles StyleChain) layout.Abs {
-	if h := styles.Get("page.height"); h != nil {
-		if abs, ok := h.(layout.Abs); ok {
-			return abs
-		}
+// resolveContent resolves optional content, like a header.
+func resolveContent(value foundations.Value) *Content {
+	if c, ok := value.(foundations.ContentValue); ok {
+		return &Content{Elements: c.Content.Elements}
 	}
-	return 841.89 // A4 height in points
+	return nil
+}
+
+func resolvePageWidth(styles StyleChain) layout.Abs {
+	return resolveLength(pageProperty(&styles, "width"), paperA4Width)
+}
+
+func resolvePageHeight(styles StyleChain) layout.Abs {
+	return resolveLength(pageProperty(&styles, "height"), 841.89) // A4 height in points
 }
 
 func resolveFlipped(styles StyleChain) bool {
-	if f := styles.Get("page.flipped"); f != nil {
-		if b, ok := f.(bool); ok {
-			return b
-		}
-	}
-	return false
+	return styles.GetBool("page", "flipped", false)
 }
 
+// resolveMargins resolves the page margins: a length for all sides, or a
+// dictionary with the keys left, top, right, bottom, inside, outside, x,
+// y, and rest. Inside and outside margins are laid out as left and right
+// margins and swapped for pages bound on the other side.
 func resolveMargins(styles StyleChain, defaultMargin layout.Abs, size layout.Size) Sides[layout.Abs] {
-	// TODO: Resolve individual margins from styles
-	return Sides[layout.Abs]{
-		Left:   defaultMargin,
-		Top:    defaultMargin,
-		Right:  defaultMargin,
-		Bottom: defaultMargin,
+	margin := Sides[layout.Abs]{Left: defaultMargin, Top: defaultMargin, Right: defaultMargin, Bottom: defaultMargin}
+	switch v := pageProperty(&styles, "margin").(type) {
+	case foundations.LengthValue:
+		abs := layout.Abs(v.Length.Points)
+		margin = Sides[layout.Abs]{Left: abs, Top: abs, Right: abs, Bottom: abs}
+	case *foundations.Dict:
+		for _, key := range []string{"rest", "x", "y", "left", "top", "right", "bottom", "inside", "outside"} {
+			value, ok := v.Get(key)
+			if !ok {
+				continue
+			}
+			abs := resolveLength(value, defaultMargin)
+			switch key {
+			case "rest":
+				margin = Sides[layout.Abs]{Left: abs, Top: abs, Right: abs, Bottom: abs}
+			case "x":
+				margin.Left, margin.Right = abs, abs
+			case "y":
+				margin.Top, margin.Bottom = abs, abs
+			case "left", "inside":
+				margin.Left = abs
+			case "top":
+				margin.Top = abs
+			case "right", "outside":
+				margin.Right = abs
+			case "bottom":
+				margin.Bottom = abs
+			}
+		}
 	}
+	return margin
 }
 
 func resolveTwoSided(styles StyleChain) bool {
-	if t := styles.Get("page.margin.two-sided"); t != nil {
-		if b, ok := t.(bool); ok {
-			return b
-		}
+	if dict, ok := pageProperty(&styles, "margin").(*foundations.Dict); ok {
+		_, inside := dict.Get("inside")
+		_, outside := dict.Get("outside")
+		return inside || outside
 	}
 	return false
 }
 
 func resolveFill(styles StyleChain) *Paint {
-	if f := styles.Get("page.fill"); f != nil {
-		if p, ok := f.(*Paint); ok {
-			return p
-		}
+	if c, ok := pageProperty(&styles, "fill").(foundations.Color); ok {
+		r, g, b, a := c.ToRgba().ToBytes()
+		return &Paint{Color: &Color{R: r, G: g, B: b, A: a}}
 	}
 	return nil
 }
 
 func resolveForeground(styles StyleChain) *Content {
-	if f := styles.Get("page.foreground"); f != nil {
-		if c, ok := f.(*Content); ok {
-			return c
-		}
-	}
-	return nil
+	return resolveContent(pageProperty(&styles, "foreground"))
 }
 
 func resolveBackground(styles StyleChain) *Content {
-	if b := styles.Get("page.background"); b != nil {
-		if c, ok := b.(*Content); ok {
-			return c
-		}
-	}
-	return nil
+	return resolveContent(pageProperty(&styles, "background"))
 }
 
 func resolveHeaderAscent(styles StyleChain, topMargin layout.Abs) layout.Abs {
-	if h := styles.Get("page.header-ascent"); h != nil {
-		if abs, ok := h.(layout.Abs); ok {
-			return abs
-		}
-	}
-	return topMargin * 0.3 // Default to 30% of top margin
+	return resolveLength(pageProperty(&styles, "header-ascent"), topMargin*0.3) // Default to 30% of top margin
 }
 
 func resolveFooterDescent(styles StyleChain, bottomMargin layout.Abs) layout.Abs {
-	if f := styles.Get("page.footer-descent"); f != nil {
-		if abs, ok := f.(layout.Abs); ok {
-			return abs
-		}
-	}
-	return bottomMargin * 0.3 // Default to 30% of bottom margin
+	return resolveLength(pageProperty(&styles, "footer-descent"), bottomMargin*0.3) // Default to 30% of bottom margin
 }
 
 func resolveNumbering(styles StyleChain) *Numbering {
-	if n := styles.Get("page.numbering"); n != nil {
-		if num, ok := n.(*Numbering); ok {
-			return num
-		}
+	if pattern, ok := pageProperty(&styles, "numbering").(foundations.Str); ok {
+		return &Numbering{Pattern: string(pattern)}
 	}
 	return nil
 }
 
-func resolveSupplement(styles StyleChain) Content {
-	if s := styles.Get("page.supplement"); s != nil {
-		if c, ok := s.(Content); ok {
-			return c
-		}
+func resolveSupplement(styles StyleChain) string {
+	if c, ok := pageProperty(&styles, "supplement").(foundations.ContentValue); ok {
+		return c.Content.ToText()
 	}
-	return Content{}
+	return ""
 }
 
 func resolveBinding(styles StyleChain) Binding {
-	if b := styles.Get("page.binding"); b != nil {
-		if binding, ok := b.(Binding); ok {
-			return binding
-		}
+	switch styles.GetStr("page", "binding", "") {
+	case "left":
+		return BindingLeft
+	case "right":
+		return BindingRight
 	}
 	// Default based on text direction
-	if dir := styles.Get("text.dir"); dir != nil {
-		if d, ok := dir.(layout.Dir); ok && d == layout.DirRTL {
-			return BindingRight
-		}
+	if styles.GetStr("text", "dir", "") == "rtl" {
+		return BindingRight
 	}
 	return BindingLeft
 }
 
 func resolveHeaderFooter(styles StyleChain, numbering *Numbering) (*Content, *Content) {
-	header := styles.Get("page.header")
-	footer := styles.Get("page.footer")
-
-	var headerContent, footerContent *Content
-
-	if h, ok := header.(*Content); ok {
-		headerContent = h
-	}
-	if f, ok := footer.(*Content); ok {
-		footerContent = f
-	}
+	headerContent := resolveContent(pageProperty(&styles, "header"))
+	footerContent := resolveContent(pageProperty(&styles, "footer"))
 
 	// Note: Page numbering is handled in Finalize when there's a Numbering
 	// pattern but no explicit footer. This is because the actual page number
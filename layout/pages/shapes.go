@@ -0,0 +1,36 @@
+package pages
+
+import (
+	"github.com/boergens/gotypst/layout"
+)
+
+// The shapes of the frame model are defined by the layout package.
+type (
+	ShapeItem     = layout.ShapeItem
+	Geometry      = layout.Geometry
+	LineGeometry  = layout.LineGeometry
+	RectGeometry  = layout.RectGeometry
+	CurveGeometry = layout.CurveGeometry
+	Curve         = layout.Curve
+	CurveItem     = layout.CurveItem
+	CurveMove     = layout.CurveMove
+	CurveLine     = layout.CurveLine
+	CurveCubic    = layout.CurveCubic
+	CurveClose    = layout.CurveClose
+	FixedStroke   = layout.FixedStroke
+	LineCap       = layout.LineCap
+	LineJoin      = layout.LineJoin
+	DashPattern   = layout.DashPattern
+	Transform     = layout.Transform
+)
+
+// The line caps and joins of strokes.
+const (
+	LineCapButt   = layout.LineCapButt
+	LineCapRound  = layout.LineCapRound
+	LineCapSquare = layout.LineCapSquare
+
+	LineJoinMiter = layout.LineJoinMiter
+	LineJoinRound = layout.LineJoinRound
+	LineJoinBevel = layout.LineJoinBevel
+)
@@ -3,229 +3,92 @@ package pages
 import (
 	"github.com/boergens/gotypst/eval"
 	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/math"
 )
 
-// PagedDocument represents a fully laid out document.
-type PagedDocument struct {
-	// Pages contains the laid out pages.
-	Pages []Page
-	// Info contains document metadata.
-	Info DocumentInfo
-}
-
-// DocumentInfo contains document metadata.
-type DocumentInfo struct {
-	Title    *string
-	Author   []string
-	Keywords []string
-	Date     *Date
-}
+// The document model is defined by the layout package, so that the
+// compiler's entry points can return documents without depending on page
+// layout.
+type (
+	PagedDocument = layout.PagedDocument
+	Attachment    = layout.Attachment
+	DocumentInfo  = layout.DocumentInfo
+	Date          = layout.Date
+	Page          = layout.Page
+	Numbering     = layout.Numbering
+)
 
-// Date represents a date value.
-type Date struct {
-	Year  int
-	Month int
-	Day   int
-}
+// The frame model is defined by the layout package, so that exporters
+// can be built without depending on page layout.
+type (
+	Frame          = layout.Frame
+	PositionedItem = layout.PositionedItem
+	FrameItem      = layout.FrameItem
+	GroupItem      = layout.GroupItem
+	TextItem       = layout.TextItem
+	ShapedTextItem = layout.ShapedTextItem
+	ShapedGlyph    = layout.ShapedGlyph
+	ImageItem      = layout.ImageItem
+	LinkItem       = layout.LinkItem
+	Destination    = layout.Destination
+	Position       = layout.Position
+	Image          = layout.Image
+	ImageFormat    = layout.ImageFormat
+	ColorSpace     = layout.ColorSpace
+	Paint          = layout.Paint
+	Color          = layout.Color
+)
 
-// Page represents a single laid out page.
-type Page struct {
-	// Frame contains the page content.
-	Frame Frame
-	// Fill is the page background fill.
-	Fill *Paint
-	// Numbering is the page numbering pattern.
-	Numbering *Numbering
-	// Supplement is the page supplement content.
-	Supplement Content
-	// Number is the logical page number.
-	Number int
-}
+// The image formats and color spaces of the frame model.
+const (
+	ImageFormatJPEG = layout.ImageFormatJPEG
+	ImageFormatPNG  = layout.ImageFormatPNG
+	ImageFormatRaw  = layout.ImageFormatRaw
 
-// Frame represents a laid out frame of content.
-type Frame struct {
-	// Size is the frame dimensions.
-	Size layout.Size
-	// Items contains the positioned frame content.
-	Items []PositionedItem
-}
+	ColorSpaceDeviceRGB  = layout.ColorSpaceDeviceRGB
+	ColorSpaceDeviceGray = layout.ColorSpaceDeviceGray
+	ColorSpaceDeviceCMYK = layout.ColorSpaceDeviceCMYK
+)
 
 // Hard creates a frame with hard constraints (non-expandable).
 func Hard(size layout.Size) Frame {
 	return Frame{Size: size, Items: nil}
 }
 
-// Width returns the frame width.
-func (f *Frame) Width() layout.Abs {
-	return f.Size.Width
-}
-
-// Height returns the frame height.
-func (f *Frame) Height() layout.Abs {
-	return f.Size.Height
-}
-
-// Push adds an item to the frame at the given position.
-func (f *Frame) Push(pos layout.Point, item FrameItem) {
-	f.Items = append(f.Items, PositionedItem{Pos: pos, Item: item})
-}
-
-// PushFrame adds a nested frame at the given position.
-func (f *Frame) PushFrame(pos layout.Point, frame Frame) {
-	f.Items = append(f.Items, PositionedItem{Pos: pos, Item: GroupItem{Frame: frame}})
-}
-
-// PushMultiple adds multiple items at specified positions.
-func (f *Frame) PushMultiple(items []PositionedItem) {
-	f.Items = append(f.Items, items...)
-}
-
-// PositionedItem represents an item with its position in the frame.
-type PositionedItem struct {
-	Pos  layout.Point
-	Item FrameItem
-}
-
-// FrameItem represents an item within a frame.
-type FrameItem interface {
-	isFrameItem()
-}
-
-// GroupItem represents a nested frame.
-type GroupItem struct {
-	Frame Frame
-}
-
-func (GroupItem) isFrameItem() {}
-
 // TagItem represents an introspection tag.
 type TagItem struct {
+	layout.ForeignItem
 	Tag Tag
 }
 
-func (TagItem) isFrameItem() {}
-
-// TextItem represents text content for rendering.
-// This is a simplified item for direct text rendering.
-type TextItem struct {
-	// Text is the text content to render.
-	Text string
-	// FontSize is the font size in points.
-	FontSize layout.Abs
-}
-
-func (TextItem) isFrameItem() {}
-
-// ShapedTextItem represents shaped text with glyph-level positioning.
-// This provides precise glyph positioning for high-quality PDF output.
-type ShapedTextItem struct {
-	// Glyphs contains the shaped glyph data.
-	Glyphs []ShapedGlyph
-	// FontSize is the font size in points.
-	FontSize layout.Abs
-	// Fill is the text color.
-	Fill *Paint
-}
-
-func (ShapedTextItem) isFrameItem() {}
-
-// ShapedGlyph represents a single shaped glyph for rendering.
-type ShapedGlyph struct {
-	// GlyphID is the glyph index in the font.
-	GlyphID uint16
-	// XAdvance is the horizontal advance in em units.
-	XAdvance float64
-	// XOffset is the horizontal offset in em units.
-	XOffset float64
-	// YOffset is the vertical offset in em units.
-	YOffset float64
-	// Char is the original character (for ToUnicode mapping).
-	Char rune
-	// Font is a pointer to the font used for this glyph.
-	// Using interface{} to avoid circular import with font package.
-	Font interface{}
-}
-
-// ImageItem represents an embedded image.
-type ImageItem struct {
-	// Image contains the image data and metadata.
-	Image Image
-	// Size is the rendered size of the image.
-	Size layout.Size
-}
-
-func (ImageItem) isFrameItem() {}
-
 // InlineItem represents inline text content (shaped text).
 type InlineItem struct {
+	layout.ForeignItem
 	// Frame contains the finalized inline content.
 	Frame interface{} // inline.FinalFrame - using interface to avoid import cycle
 	// Baseline is the text baseline offset from the top.
 	Baseline layout.Abs
 }
 
-func (InlineItem) isFrameItem() {}
-
-// Image represents image data for embedding.
-type Image struct {
-	// Data is the raw image bytes.
-	Data []byte
-	// Format specifies the image format.
-	Format ImageFormat
-	// Width is the natural image width in pixels.
-	Width int
-	// Height is the natural image height in pixels.
-	Height int
-	// BitsPerComponent is typically 8.
-	BitsPerComponent int
-	// ColorSpace specifies the color space (e.g., DeviceRGB, DeviceGray).
-	ColorSpace ColorSpace
-	// Alpha contains optional alpha channel data.
-	Alpha []byte
-}
-
-// ImageFormat specifies the image encoding format.
-type ImageFormat int
-
-const (
-	// ImageFormatJPEG represents JPEG/DCTDecode encoded images.
-	ImageFormatJPEG ImageFormat = iota
-	// ImageFormatPNG represents PNG/FlateDecode encoded images.
-	ImageFormatPNG
-	// ImageFormatRaw represents uncompressed raw pixel data.
-	ImageFormatRaw
-)
-
-// ColorSpace specifies the PDF color space.
-type ColorSpace int
-
-const (
-	// ColorSpaceDeviceRGB represents the RGB color space.
-	ColorSpaceDeviceRGB ColorSpace = iota
-	// ColorSpaceDeviceGray represents the grayscale color space.
-	ColorSpaceDeviceGray
-	// ColorSpaceDeviceCMYK represents the CMYK color space.
-	ColorSpaceDeviceCMYK
-)
-
-// String returns the PDF name for the color space.
-func (cs ColorSpace) String() string {
-	switch cs {
-	case ColorSpaceDeviceRGB:
-		return "DeviceRGB"
-	case ColorSpaceDeviceGray:
-		return "DeviceGray"
-	case ColorSpaceDeviceCMYK:
-		return "DeviceCMYK"
-	default:
-		return "DeviceRGB"
-	}
+// EquationItem marks a group as a laid out equation. It is the first item
+// of the group's frame and doesn't render anything; exporters that keep
+// the structure of math, like HTML, replace the group with it.
+type EquationItem struct {
+	layout.ForeignItem
+	// Equation is the equation element.
+	Equation *math.EquationElem
+	// FontSize is the size of the text around the equation.
+	FontSize layout.Abs
 }
 
 // Tag represents an introspection tag.
 type Tag struct {
 	Kind     TagKind
 	Location Location
+	// Label is the label of the tagged element, if it has one. Links to
+	// the label point to the position of its start tag.
+	Label string
 	// Elem optionally holds element data for start tags.
 	// This may contain a CounterUpdateElem for page counter updates.
 	Elem TagElement
@@ -302,23 +165,6 @@ func (CounterUpdateElem) isTagElement() {}
 // Location identifies an element location for introspection.
 type Location uint64
 
-// Paint represents a fill color or pattern.
-type Paint struct {
-	// Color is a solid color fill.
-	Color *Color
-}
-
-// Color represents an RGBA color.
-type Color struct {
-	R, G, B, A uint8
-}
-
-// Numbering represents a page numbering pattern.
-type Numbering struct {
-	// Pattern is the numbering pattern string.
-	Pattern string
-}
-
 // Content represents document content.
 type Content struct {
 	Elements []eval.ContentElement
@@ -402,23 +248,15 @@ type LayoutedPage struct {
 	Fill *Paint
 	// Numbering is the page numbering pattern.
 	Numbering *Numbering
-	// Supplement is the page supplement content.
-	Supplement Content
+	// Supplement is the plain text of the page supplement.
+	Supplement string
 }
 
-// StyleChain represents a chain of styles.
-type StyleChain struct {
-	// Styles contains the style values.
-	Styles map[string]interface{}
-}
-
-// Get retrieves a style value.
-func (s StyleChain) Get(key string) interface{} {
-	if s.Styles == nil {
-		return nil
-	}
-	return s.Styles[key]
-}
+// StyleChain is the chain of styles that applies to a piece of content.
+// Set rules in a content block or in the output of a show rule are chained
+// onto the content they style only, so they do not apply elsewhere. The
+// zero value is the empty chain.
+type StyleChain = foundations.StyleChain
 
 // Locator tracks element locations for introspection.
 type Locator struct {
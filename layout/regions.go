@@ -0,0 +1,142 @@
+// Regions for Typst.
+// Translated from typst-library/src/layout/regions.rs
+
+package layout
+
+// Axes holds a pair of values for horizontal (X) and vertical (Y) axes.
+type Axes[T any] struct {
+	X, Y T
+}
+
+// Region is a single region to lay out into. An infinite width or height
+// leaves the region unbounded in that direction, like in a container that
+// sizes itself to its content. A region that expands in a direction makes
+// the frame laid out into it fill it in that direction.
+type Region struct {
+	Size   Size
+	Expand Axes[bool]
+}
+
+// NewRegion creates a new region with the given size and expansion settings.
+func NewRegion(size Size, expand Axes[bool]) Region {
+	return Region{Size: size, Expand: expand}
+}
+
+// Fit returns the size of a frame whose content used the given size in the
+// region. The frame fills the region in the directions it expands in and
+// takes the size of its content, but at most that of the region, in the
+// others. An unbounded direction never expands, since there is nothing to
+// fill.
+//
+// Matches Rust: expand.select(region.size, used.min(region.size))
+func (r Region) Fit(used Size) Size {
+	size := used.Min(r.Size)
+	if r.Expand.X && r.Size.Width.IsFinite() {
+		size.Width = r.Size.Width
+	}
+	if r.Expand.Y && r.Size.Height.IsFinite() {
+		size.Height = r.Size.Height
+	}
+	return size
+}
+
+// Regions returns the regions that consist of just the region.
+//
+// Matches Rust: Regions::one
+func (r Region) Regions() Regions {
+	return NewRegions(r.Size, r.Expand, r.Size)
+}
+
+// Regions represents multiple layout regions. All regions have the width of
+// the first; their heights follow from the backlog and, once that is used
+// up, repeat the last region forever, if there is one.
+type Regions struct {
+	// Size is the remaining size of the first region.
+	Size Size
+	// Expand is whether frames should expand to fill the regions.
+	Expand Axes[bool]
+	// Full is the full size of the first region, against which relative
+	// sizes are resolved.
+	Full Size
+	// Backlog holds the heights of the regions after the first.
+	Backlog []Abs
+	// Last is the region that repeats after the backlog, if any.
+	Last *Size
+}
+
+// NewRegions creates new regions with the given parameters.
+func NewRegions(size Size, expand Axes[bool], full Size) Regions {
+	return Regions{
+		Size:   size,
+		Expand: expand,
+		Full:   full,
+	}
+}
+
+// Base returns the base size for relative measurements.
+func (r *Regions) Base() Size {
+	return r.Full
+}
+
+// Map returns the regions with the size of each region transformed by f,
+// for laying out a child with different bounds, like a column or a cell.
+//
+// Matches Rust: Regions::map
+func (r *Regions) Map(f func(Size) Size) Regions {
+	height := func(h Abs) Abs {
+		return f(Size{Width: r.Size.Width, Height: h}).Height
+	}
+	mapped := Regions{
+		Size:   f(r.Size),
+		Expand: r.Expand,
+		Full:   f(r.Full),
+	}
+	if len(r.Backlog) > 0 {
+		mapped.Backlog = make([]Abs, len(r.Backlog))
+		for i, h := range r.Backlog {
+			mapped.Backlog[i] = height(h)
+		}
+	}
+	if r.Last != nil {
+		last := f(*r.Last)
+		mapped.Last = &last
+	}
+	return mapped
+}
+
+// MayProgress returns true if moving to a subsequent region might improve
+// things. This is not the case at the start of a region that repeats
+// forever, since the next region would be just the same.
+func (r *Regions) MayProgress() bool {
+	return len(r.Backlog) > 0 || (r.Last != nil && r.Size.Height != r.Last.Height)
+}
+
+// Next advances to the next region, if there is one.
+func (r *Regions) Next() {
+	var height Abs
+	switch {
+	case len(r.Backlog) > 0:
+		height, r.Backlog = r.Backlog[0], r.Backlog[1:]
+	case r.Last != nil:
+		height = r.Last.Height
+	default:
+		return
+	}
+	r.Size.Height = height
+	r.Full.Height = height
+}
+
+// IsFull returns true if the region is (over)full.
+func (r *Regions) IsFull() bool {
+	return r.Size.Height <= 0
+}
+
+// Iter returns an iterator over remaining region heights.
+func (r *Regions) Iter() []Abs {
+	result := []Abs{r.Size.Height}
+	result = append(result, r.Backlog...)
+	if r.Last != nil {
+		result = append(result, r.Last.Height)
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package layout
+
+import "testing"
+
+func TestRegionFit(t *testing.T) {
+	used := Size{Width: 30, Height: 120}
+	for _, tt := range []struct {
+		name   string
+		region Region
+		want   Size
+	}{
+		{"shrinks to content", NewRegion(Size{Width: 100, Height: 200}, Axes[bool]{}), Size{Width: 30, Height: 120}},
+		{"at most the region", NewRegion(Size{Width: 100, Height: 50}, Axes[bool]{}), Size{Width: 30, Height: 50}},
+		{"expands", NewRegion(Size{Width: 100, Height: 200}, Axes[bool]{X: true}), Size{Width: 100, Height: 120}},
+		{"unbounded", NewRegion(Size{Width: Inf(), Height: Inf()}, Axes[bool]{X: true, Y: true}), Size{Width: 30, Height: 120}},
+	} {
+		if got := tt.region.Fit(used); got != tt.want {
+			t.Errorf("%s: Fit = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegionsMap(t *testing.T) {
+	last := Size{Width: 100, Height: 80}
+	regions := NewRegions(Size{Width: 100, Height: 40}, Axes[bool]{X: true}, Size{Width: 100, Height: 60})
+	regions.Backlog = []Abs{70}
+	regions.Last = &last
+
+	inset := regions.Map(func(size Size) Size {
+		return Size{Width: size.Width - 20, Height: size.Height - 10}
+	})
+	if inset.Size != (Size{Width: 80, Height: 30}) || inset.Full != (Size{Width: 80, Height: 50}) {
+		t.Errorf("first region = %v of %v, want 80x30 of 80x50", inset.Size, inset.Full)
+	}
+	if len(inset.Backlog) != 1 || inset.Backlog[0] != 60 || *inset.Last != (Size{Width: 80, Height: 70}) {
+		t.Errorf("backlog = %v, last = %v", inset.Backlog, *inset.Last)
+	}
+	if !inset.Expand.X || regions.Backlog[0] != 70 {
+		t.Errorf("Map changed the expansion or the original regions")
+	}
+
+	inset.Next()
+	inset.Next()
+	if inset.Size.Height != 70 || inset.Full.Height != 70 {
+		t.Errorf("after the backlog, height = %v, want the last region's 70", inset.Size.Height)
+	}
+}
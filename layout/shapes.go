@@ -0,0 +1,191 @@
+// Shapes and transformations for frames.
+// Translated from typst-library/src/visualize/shape.rs
+
+package layout
+
+import "math"
+
+// ShapeItem is a geometric shape with an optional fill and stroke.
+type ShapeItem struct {
+	// Geometry is the outline of the shape.
+	Geometry Geometry
+	// Fill is the paint of the shape's interior, if it is filled.
+	Fill *Paint
+	// Stroke is the stroke of the shape's outline, if it is stroked.
+	Stroke *FixedStroke
+}
+
+func (ShapeItem) isFrameItem() {}
+
+// Geometry is the outline of a shape, relative to the shape's position.
+type Geometry interface {
+	isGeometry()
+}
+
+// LineGeometry is a line from the origin to a point.
+type LineGeometry struct {
+	To Point
+}
+
+func (LineGeometry) isGeometry() {}
+
+// RectGeometry is a rectangle with its top-left corner at the origin.
+type RectGeometry struct {
+	Size Size
+}
+
+func (RectGeometry) isGeometry() {}
+
+// CurveGeometry is an arbitrary outline made of lines and cubic Bézier
+// curves.
+type CurveGeometry struct {
+	Curve Curve
+}
+
+func (CurveGeometry) isGeometry() {}
+
+// Curve is a sequence of curve items. It may contain multiple subpaths,
+// each starting with a CurveMove.
+type Curve []CurveItem
+
+// CurveItem is one step of a curve.
+type CurveItem interface {
+	isCurveItem()
+}
+
+// CurveMove starts a new subpath at a point.
+type CurveMove struct {
+	To Point
+}
+
+func (CurveMove) isCurveItem() {}
+
+// CurveLine draws a straight line to a point.
+type CurveLine struct {
+	To Point
+}
+
+func (CurveLine) isCurveItem() {}
+
+// CurveCubic draws a cubic Bézier curve to a point.
+type CurveCubic struct {
+	Control1, Control2, To Point
+}
+
+func (CurveCubic) isCurveItem() {}
+
+// CurveClose closes the current subpath with a line to its start.
+type CurveClose struct{}
+
+func (CurveClose) isCurveItem() {}
+
+// RectCurve returns the outline of a rectangle with its top-left corner
+// at the origin.
+func RectCurve(size Size) Curve {
+	return Curve{
+		CurveMove{To: Point{}},
+		CurveLine{To: Point{X: size.Width}},
+		CurveLine{To: Point{X: size.Width, Y: size.Height}},
+		CurveLine{To: Point{Y: size.Height}},
+		CurveClose{},
+	}
+}
+
+// FixedStroke describes how the outline of a shape is stroked.
+type FixedStroke struct {
+	// Paint is the color of the stroke.
+	Paint Paint
+	// Thickness is the width of the stroke.
+	Thickness Abs
+	// Cap is the shape of the ends of open subpaths and dashes.
+	Cap LineCap
+	// Join is the shape of the corners between segments.
+	Join LineJoin
+	// Dash is the dash pattern, or nil for a solid stroke.
+	Dash *DashPattern
+	// MiterLimit is the ratio of miter length to thickness beyond which
+	// miter joins are beveled instead. If zero, the limit is 4.
+	MiterLimit float64
+}
+
+// LineCap is the shape of the ends of stroked lines.
+type LineCap int
+
+const (
+	// LineCapButt ends the stroke exactly at the end point.
+	LineCapButt LineCap = iota
+	// LineCapRound ends the stroke with a half circle.
+	LineCapRound
+	// LineCapSquare extends the stroke by half its thickness.
+	LineCapSquare
+)
+
+// LineJoin is the shape of the corners of stroked lines.
+type LineJoin int
+
+const (
+	// LineJoinMiter extends the outer edges until they meet.
+	LineJoinMiter LineJoin = iota
+	// LineJoinRound rounds the corner with a circle.
+	LineJoinRound
+	// LineJoinBevel cuts the corner off.
+	LineJoinBevel
+)
+
+// DashPattern alternates between drawn and skipped lengths of a stroke.
+type DashPattern struct {
+	// Array holds the lengths of dashes and gaps, starting with a dash.
+	Array []Abs
+	// Phase is how far into the pattern the stroke starts.
+	Phase Abs
+}
+
+// Transform is an affine transformation. A point (x, y) is mapped to
+// (Sx*x + Kx*y + Tx, Ky*x + Sy*y + Ty).
+type Transform struct {
+	Sx, Ky, Kx, Sy float64
+	Tx, Ty         Abs
+}
+
+// IdentityTransform returns the transformation that changes nothing.
+func IdentityTransform() Transform {
+	return Transform{Sx: 1, Sy: 1}
+}
+
+// TranslateTransform returns a translation by x and y.
+func TranslateTransform(x, y Abs) Transform {
+	return Transform{Sx: 1, Sy: 1, Tx: x, Ty: y}
+}
+
+// ScaleTransform returns a scaling by sx and sy around the origin.
+func ScaleTransform(sx, sy float64) Transform {
+	return Transform{Sx: sx, Sy: sy}
+}
+
+// RotateTransform returns a clockwise rotation by an angle in radians
+// around the origin. Since the y-axis points down, positive angles turn
+// clockwise on the page.
+func RotateTransform(angle float64) Transform {
+	sin, cos := math.Sincos(angle)
+	return Transform{Sx: cos, Ky: sin, Kx: -sin, Sy: cos}
+}
+
+// Then returns the transformation that applies t and then other.
+func (t Transform) Then(other Transform) Transform {
+	return Transform{
+		Sx: other.Sx*t.Sx + other.Kx*t.Ky,
+		Ky: other.Ky*t.Sx + other.Sy*t.Ky,
+		Kx: other.Sx*t.Kx + other.Kx*t.Sy,
+		Sy: other.Ky*t.Kx + other.Sy*t.Sy,
+		Tx: Abs(other.Sx*float64(t.Tx)+other.Kx*float64(t.Ty)) + other.Tx,
+		Ty: Abs(other.Ky*float64(t.Tx)+other.Sy*float64(t.Ty)) + other.Ty,
+	}
+}
+
+// Apply maps a point through the transformation.
+func (t Transform) Apply(p Point) Point {
+	return Point{
+		X: Abs(t.Sx*float64(p.X)+t.Kx*float64(p.Y)) + t.Tx,
+		Y: Abs(t.Ky*float64(p.X)+t.Sy*float64(p.Y)) + t.Ty,
+	}
+}
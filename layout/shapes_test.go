@@ -0,0 +1,24 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransformThen(t *testing.T) {
+	// Rotating a quarter turn clockwise and then moving right maps the
+	// point to the right of the origin onto one below it, moved right.
+	transform := RotateTransform(math.Pi / 2).Then(TranslateTransform(10, 0))
+	got := transform.Apply(Point{X: 1})
+	if math.Abs(float64(got.X-10)) > 1e-9 || math.Abs(float64(got.Y-1)) > 1e-9 {
+		t.Errorf("Apply = %+v, want (10, 1)", got)
+	}
+
+	scaled := TranslateTransform(1, 2).Then(ScaleTransform(2, 3))
+	if got := scaled.Apply(Point{X: 1, Y: 1}); got != (Point{X: 4, Y: 9}) {
+		t.Errorf("Apply = %+v, want (4, 9)", got)
+	}
+	if got := IdentityTransform().Apply(Point{X: 3, Y: 4}); got != (Point{X: 3, Y: 4}) {
+		t.Errorf("identity moved the point to %+v", got)
+	}
+}
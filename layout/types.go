@@ -21,6 +21,16 @@ func (a Abs) Fits(other Abs) bool {
 	return other <= a || a.ApproxEq(other)
 }
 
+// Inf returns an infinite length, which leaves a region unbounded.
+func Inf() Abs {
+	return Abs(math.Inf(1))
+}
+
+// IsFinite returns true if the value is neither infinite nor NaN.
+func (a Abs) IsFinite() bool {
+	return !math.IsInf(float64(a), 0) && !math.IsNaN(float64(a))
+}
+
 // At converts an Em value to Abs at the given font size.
 func (e Em) At(fontSize Abs) Abs {
 	return Abs(float64(e) * float64(fontSize))
@@ -47,6 +57,11 @@ type Size struct {
 	Width, Height Abs
 }
 
+// Min returns the component-wise minimum of two sizes.
+func (s Size) Min(other Size) Size {
+	return Size{Width: min(s.Width, other.Width), Height: min(s.Height, other.Height)}
+}
+
 // Dir represents text direction.
 type Dir int
 
@@ -0,0 +1,588 @@
+package calc
+
+import (
+	"math"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// errTooLarge is returned when the result of an integer operation doesn't
+// fit into a 64-bit integer.
+func errTooLarge() error {
+	return &foundations.OpError{Message: "the result is too large"}
+}
+
+// errNotReal is returned when the result of a computation is not a finite
+// real number.
+func errNotReal() error {
+	return &foundations.OpError{Message: "the result is not a real number"}
+}
+
+// intTypeError returns an error for non-integer input.
+func intTypeError(fn string, v foundations.Value) error {
+	return &foundations.OpError{
+		Message: fn + " expected integer, got " + v.Type().String(),
+	}
+}
+
+// checkedMul multiplies two integers, reporting whether the product fits
+// into an int64.
+func checkedMul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	if p/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return p, true
+}
+
+// floatToInt converts a float to an integer, failing if it is out of range
+// or not a number.
+func floatToInt(x float64) (foundations.Value, error) {
+	if math.IsNaN(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+		return nil, errTooLarge()
+	}
+	return foundations.Int(int64(x)), nil
+}
+
+// Pow raises a base to an exponent. Integers raised to non-negative
+// integer exponents stay integers and fail on overflow instead of
+// silently turning into floats.
+func Pow(base, exponent foundations.Value) (foundations.Value, error) {
+	b, bInt := base.(foundations.Int)
+	e, eInt := exponent.(foundations.Int)
+	if bInt && eInt {
+		if b == 0 && e < 0 {
+			return nil, &foundations.OpError{Message: "zero to the power of a negative number is undefined"}
+		}
+		if e >= 0 {
+			switch b {
+			case 0, 1:
+				if e == 0 {
+					return foundations.Int(1), nil
+				}
+				return b, nil
+			case -1:
+				if e%2 == 0 {
+					return foundations.Int(1), nil
+				}
+				return b, nil
+			}
+			// Any other base overflows within 63 multiplications.
+			result := int64(1)
+			for i := int64(0); i < int64(e); i++ {
+				var ok bool
+				if result, ok = checkedMul(result, int64(b)); !ok {
+					return nil, errTooLarge()
+				}
+			}
+			return foundations.Int(result), nil
+		}
+	}
+	result, err := foundations.Pow(base, exponent)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := result.(foundations.Float); ok && (math.IsNaN(float64(f)) || math.IsInf(float64(f), 0)) {
+		return nil, errNotReal()
+	}
+	return result, nil
+}
+
+// Exp computes e raised to the power of v.
+func Exp(v foundations.Value) (foundations.Value, error) {
+	result, err := foundations.Exp(v)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsInf(float64(result.(foundations.Float)), 0) {
+		return nil, errNotReal()
+	}
+	return result, nil
+}
+
+// Ln computes the natural logarithm of a strictly positive value.
+func Ln(v foundations.Value) (foundations.Value, error) {
+	x, ok := toFloat64(v)
+	if !ok {
+		return nil, numericTypeError("ln", v)
+	}
+	if x <= 0 {
+		return nil, &foundations.OpError{Message: "value must be strictly positive"}
+	}
+	result := math.Log(x)
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, errNotReal()
+	}
+	return foundations.Float(result), nil
+}
+
+// Log computes the logarithm of a strictly positive value to the given
+// base.
+func Log(v, base foundations.Value) (foundations.Value, error) {
+	x, ok := toFloat64(v)
+	if !ok {
+		return nil, numericTypeError("log", v)
+	}
+	b, ok := toFloat64(base)
+	if !ok {
+		return nil, numericTypeError("log", base)
+	}
+	if x <= 0 {
+		return nil, &foundations.OpError{Message: "value must be strictly positive"}
+	}
+	if b <= 0 || b == 1 || math.IsInf(b, 0) || math.IsNaN(b) {
+		return nil, &foundations.OpError{Message: "base must be positive, finite, and not one"}
+	}
+
+	var result float64
+	switch b {
+	case 2:
+		result = math.Log2(x)
+	case 10:
+		result = math.Log10(x)
+	default:
+		result = math.Log(x) / math.Log(b)
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, errNotReal()
+	}
+	return foundations.Float(result), nil
+}
+
+// Floor rounds a number down to the nearest integer.
+func Floor(v foundations.Value) (foundations.Value, error) {
+	return roundToInt("floor", v, math.Floor)
+}
+
+// Ceil rounds a number up to the nearest integer.
+func Ceil(v foundations.Value) (foundations.Value, error) {
+	return roundToInt("ceil", v, math.Ceil)
+}
+
+// Trunc drops the fractional part of a number.
+func Trunc(v foundations.Value) (foundations.Value, error) {
+	return roundToInt("trunc", v, math.Trunc)
+}
+
+// roundToInt rounds a number to an integer with the given function.
+// Integers are returned unchanged.
+func roundToInt(fn string, v foundations.Value, round func(float64) float64) (foundations.Value, error) {
+	switch x := v.(type) {
+	case foundations.Int:
+		return x, nil
+	case foundations.Float:
+		return floatToInt(round(float64(x)))
+	default:
+		return nil, numericTypeError(fn, v)
+	}
+}
+
+// Fract returns the fractional part of a number. It is zero for integers.
+func Fract(v foundations.Value) (foundations.Value, error) {
+	switch x := v.(type) {
+	case foundations.Int:
+		return foundations.Int(0), nil
+	case foundations.Float:
+		f := float64(x)
+		return foundations.Float(f - math.Trunc(f)), nil
+	default:
+		return nil, numericTypeError("fract", v)
+	}
+}
+
+// Round rounds a number to the given number of decimal digits, with halves
+// rounded away from zero. Integers stay integers; a negative number of
+// digits rounds them to tens, hundreds, and so on.
+func Round(v foundations.Value, digits int64) (foundations.Value, error) {
+	switch x := v.(type) {
+	case foundations.Int:
+		if digits >= 0 {
+			return x, nil
+		}
+		scale := int64(1)
+		for i := int64(0); i < -digits; i++ {
+			var ok bool
+			if scale, ok = checkedMul(scale, 10); !ok {
+				return foundations.Int(0), nil
+			}
+		}
+		n := int64(x)
+		rem := n % scale
+		n -= rem
+		if 2*abs64(rem) >= scale {
+			step := scale
+			if rem < 0 {
+				step = -scale
+			}
+			if (step > 0 && n > math.MaxInt64-step) || (step < 0 && n < math.MinInt64-step) {
+				return nil, errTooLarge()
+			}
+			n += step
+		}
+		return foundations.Int(n), nil
+	case foundations.Float:
+		f := float64(x)
+		if digits <= 0 {
+			// Whole numbers, tens, and beyond need no fractional scaling.
+			scale := math.Pow(10, float64(-digits))
+			return foundations.Float(math.Round(f/scale) * scale), nil
+		}
+		if digits > 15 {
+			return x, nil
+		}
+		scale := math.Pow(10, float64(digits))
+		rounded := math.Round(f*scale) / scale
+		if math.IsInf(rounded, 0) || math.IsNaN(rounded) {
+			return x, nil
+		}
+		return foundations.Float(rounded), nil
+	default:
+		return nil, numericTypeError("round", v)
+	}
+}
+
+// abs64 returns the absolute value of an integer that isn't the most
+// negative one.
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// divOperands converts the operands of a division, failing for a zero
+// divisor. The integer operands are only set if both are integers.
+func divOperands(fn string, dividend, divisor foundations.Value) (a, b int64, x, y float64, ints bool, err error) {
+	var ok bool
+	if x, ok = toFloat64(dividend); !ok {
+		return 0, 0, 0, 0, false, numericTypeError(fn, dividend)
+	}
+	if y, ok = toFloat64(divisor); !ok {
+		return 0, 0, 0, 0, false, numericTypeError(fn, divisor)
+	}
+	if y == 0 {
+		return 0, 0, 0, 0, false, &foundations.OpError{Message: "divisor must not be zero"}
+	}
+	ai, aInt := dividend.(foundations.Int)
+	bi, bInt := divisor.(foundations.Int)
+	return int64(ai), int64(bi), x, y, aInt && bInt, nil
+}
+
+// Quo returns the quotient of two numbers, rounded down to an integer.
+func Quo(dividend, divisor foundations.Value) (foundations.Value, error) {
+	a, b, x, y, ints, err := divOperands("quo", dividend, divisor)
+	if err != nil {
+		return nil, err
+	}
+	if !ints {
+		return floatToInt(math.Floor(x / y))
+	}
+	if a == math.MinInt64 && b == -1 {
+		return nil, errTooLarge()
+	}
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return foundations.Int(q), nil
+}
+
+// Rem returns the remainder of a division, which has the sign of the
+// dividend.
+func Rem(dividend, divisor foundations.Value) (foundations.Value, error) {
+	a, b, x, y, ints, err := divOperands("rem", dividend, divisor)
+	if err != nil {
+		return nil, err
+	}
+	if !ints {
+		return foundations.Float(math.Mod(x, y)), nil
+	}
+	if b == -1 {
+		return foundations.Int(0), nil
+	}
+	return foundations.Int(a % b), nil
+}
+
+// DivEuclid performs Euclidean division: the quotient is chosen so that
+// the remainder is never negative.
+func DivEuclid(dividend, divisor foundations.Value) (foundations.Value, error) {
+	a, b, x, y, ints, err := divOperands("div-euclid", dividend, divisor)
+	if err != nil {
+		return nil, err
+	}
+	if !ints {
+		q := math.Trunc(x / y)
+		if math.Mod(x, y) < 0 {
+			if y > 0 {
+				q--
+			} else {
+				q++
+			}
+		}
+		return floatToInt(q)
+	}
+	if a == math.MinInt64 && b == -1 {
+		return nil, errTooLarge()
+	}
+	q := a / b
+	if a%b < 0 {
+		if b > 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return foundations.Int(q), nil
+}
+
+// RemEuclid returns the least non-negative remainder of a division.
+func RemEuclid(dividend, divisor foundations.Value) (foundations.Value, error) {
+	a, b, x, y, ints, err := divOperands("rem-euclid", dividend, divisor)
+	if err != nil {
+		return nil, err
+	}
+	if !ints {
+		r := math.Mod(x, y)
+		if r < 0 {
+			r += math.Abs(y)
+		}
+		return foundations.Float(r), nil
+	}
+	if b == -1 {
+		return foundations.Int(0), nil
+	}
+	r := a % b
+	if r < 0 {
+		r += abs64(b)
+	}
+	return foundations.Int(r), nil
+}
+
+// Gcd returns the greatest common divisor of two integers. It is never
+// negative.
+func Gcd(a, b foundations.Value) (foundations.Value, error) {
+	x, ok := a.(foundations.Int)
+	if !ok {
+		return nil, intTypeError("gcd", a)
+	}
+	y, ok := b.(foundations.Int)
+	if !ok {
+		return nil, intTypeError("gcd", b)
+	}
+	g, ok := gcd(int64(x), int64(y))
+	if !ok {
+		return nil, errTooLarge()
+	}
+	return foundations.Int(g), nil
+}
+
+// gcd computes the non-negative greatest common divisor, reporting whether
+// it fits into an int64.
+func gcd(a, b int64) (int64, bool) {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == math.MinInt64 {
+		return 0, false
+	}
+	return abs64(a), true
+}
+
+// Lcm returns the least common multiple of two integers. It is never
+// negative.
+func Lcm(a, b foundations.Value) (foundations.Value, error) {
+	x, ok := a.(foundations.Int)
+	if !ok {
+		return nil, intTypeError("lcm", a)
+	}
+	y, ok := b.(foundations.Int)
+	if !ok {
+		return nil, intTypeError("lcm", b)
+	}
+	if x == y {
+		if x == math.MinInt64 {
+			return nil, errTooLarge()
+		}
+		return foundations.Int(abs64(int64(x))), nil
+	}
+	if x == 0 || y == 0 {
+		return foundations.Int(0), nil
+	}
+	g, ok := gcd(int64(x), int64(y))
+	if !ok {
+		return nil, errTooLarge()
+	}
+	l, ok := checkedMul(int64(x)/g, int64(y))
+	if !ok || l == math.MinInt64 {
+		return nil, errTooLarge()
+	}
+	return foundations.Int(abs64(l)), nil
+}
+
+// naturalArg converts a non-negative integer argument.
+func naturalArg(fn string, v foundations.Value) (int64, error) {
+	n, ok := v.(foundations.Int)
+	if !ok {
+		return 0, intTypeError(fn, v)
+	}
+	if n < 0 {
+		return 0, &foundations.OpError{Message: "number must be at least zero"}
+	}
+	return int64(n), nil
+}
+
+// Fact computes the factorial of a non-negative integer.
+func Fact(v foundations.Value) (foundations.Value, error) {
+	n, err := naturalArg("fact", v)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := product(1, n)
+	if !ok {
+		return nil, errTooLarge()
+	}
+	return foundations.Int(result), nil
+}
+
+// Perm computes the number of permutations: the ways to choose an ordered
+// sequence of k items from n.
+func Perm(n, k foundations.Value) (foundations.Value, error) {
+	base, err := naturalArg("perm", n)
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := naturalArg("perm", k)
+	if err != nil {
+		return nil, err
+	}
+	if numbers > base {
+		return foundations.Int(0), nil
+	}
+	result, ok := product(base-numbers+1, base)
+	if !ok {
+		return nil, errTooLarge()
+	}
+	return foundations.Int(result), nil
+}
+
+// product multiplies the integers from start to end, reporting whether
+// the result fits into an int64.
+func product(start, end int64) (int64, bool) {
+	result := int64(1)
+	for i := start; i <= end; i++ {
+		var ok bool
+		if result, ok = checkedMul(result, i); !ok {
+			return 0, false
+		}
+	}
+	return result, true
+}
+
+// Binom computes the binomial coefficient: the ways to choose an unordered
+// set of k items from n.
+func Binom(n, k foundations.Value) (foundations.Value, error) {
+	total, err := naturalArg("binom", n)
+	if err != nil {
+		return nil, err
+	}
+	chosen, err := naturalArg("binom", k)
+	if err != nil {
+		return nil, err
+	}
+	if chosen > total {
+		return foundations.Int(0), nil
+	}
+	if chosen > total-chosen {
+		chosen = total - chosen
+	}
+
+	// Each step computes binom(n-k+i, i) from binom(n-k+i-1, i-1), which
+	// multiplies by n-k+i and divides by i. The part of i that doesn't
+	// divide the previous result divides n-k+i, so both divisions are
+	// exact and the intermediate results stay within the final one.
+	result := int64(1)
+	for i := int64(1); i <= chosen; i++ {
+		g, _ := gcd(result, i)
+		var ok bool
+		if result, ok = checkedMul(result/g, (total-chosen+i)/(i/g)); !ok {
+			return nil, errTooLarge()
+		}
+	}
+	return foundations.Int(result), nil
+}
+
+// Min returns the smallest of the values.
+func Min(values ...foundations.Value) (foundations.Value, error) {
+	return extremum("min", values, foundations.Lt)
+}
+
+// Max returns the largest of the values.
+func Max(values ...foundations.Value) (foundations.Value, error) {
+	return extremum("max", values, foundations.Gt)
+}
+
+// extremum returns the first value that no other value beats by the given
+// comparison.
+func extremum(fn string, values []foundations.Value, beats func(a, b foundations.Value) (foundations.Value, error)) (foundations.Value, error) {
+	if len(values) == 0 {
+		return nil, &foundations.OpError{Message: fn + " expected at least one value"}
+	}
+	best := values[0]
+	for _, v := range values[1:] {
+		result, err := beats(v, best)
+		if err != nil {
+			return nil, err
+		}
+		if result == foundations.True {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+// Clamp limits a value to the range between min and max.
+func Clamp(v, min, max foundations.Value) (foundations.Value, error) {
+	inverted, err := foundations.Lt(max, min)
+	if err != nil {
+		return nil, err
+	}
+	if inverted == foundations.True {
+		return nil, &foundations.OpError{Message: "max must be greater than or equal to min"}
+	}
+	below, err := foundations.Lt(v, min)
+	if err != nil {
+		return nil, err
+	}
+	if below == foundations.True {
+		return min, nil
+	}
+	above, err := foundations.Gt(v, max)
+	if err != nil {
+		return nil, err
+	}
+	if above == foundations.True {
+		return max, nil
+	}
+	return v, nil
+}
+
+// Even reports whether an integer is even.
+func Even(v foundations.Value) (foundations.Value, error) {
+	n, ok := v.(foundations.Int)
+	if !ok {
+		return nil, intTypeError("even", v)
+	}
+	return foundations.Bool(n%2 == 0), nil
+}
+
+// Odd reports whether an integer is odd.
+func Odd(v foundations.Value) (foundations.Value, error) {
+	n, ok := v.(foundations.Int)
+	if !ok {
+		return nil, intTypeError("odd", v)
+	}
+	return foundations.Bool(n%2 != 0), nil
+}
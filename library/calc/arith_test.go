@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+func TestIntegerResults(t *testing.T) {
+	tests := []struct {
+		name string
+		call func() (foundations.Value, error)
+		want foundations.Value
+	}{
+		{"pow", func() (foundations.Value, error) { return Pow(foundations.Int(3), foundations.Int(4)) }, foundations.Int(81)},
+		{"pow negative base", func() (foundations.Value, error) { return Pow(foundations.Int(-2), foundations.Int(3)) }, foundations.Int(-8)},
+		{"pow -1 large", func() (foundations.Value, error) { return Pow(foundations.Int(-1), foundations.Int(math.MaxInt64)) }, foundations.Int(-1)},
+		{"pow negative exponent", func() (foundations.Value, error) { return Pow(foundations.Int(2), foundations.Int(-1)) }, foundations.Float(0.5)},
+		{"floor", func() (foundations.Value, error) { return Floor(foundations.Float(-1.5)) }, foundations.Int(-2)},
+		{"ceil", func() (foundations.Value, error) { return Ceil(foundations.Float(1.2)) }, foundations.Int(2)},
+		{"trunc", func() (foundations.Value, error) { return Trunc(foundations.Float(-1.7)) }, foundations.Int(-1)},
+		{"fract", func() (foundations.Value, error) { return Fract(foundations.Float(-3.25)) }, foundations.Float(-0.25)},
+		{"round float", func() (foundations.Value, error) { return Round(foundations.Float(3.14159), 2) }, foundations.Float(3.14)},
+		{"round half away", func() (foundations.Value, error) { return Round(foundations.Float(-2.5), 0) }, foundations.Float(-3)},
+		{"round int tens", func() (foundations.Value, error) { return Round(foundations.Int(-125), -1) }, foundations.Int(-130)},
+		{"quo", func() (foundations.Value, error) { return Quo(foundations.Int(-7), foundations.Int(2)) }, foundations.Int(-4)},
+		{"quo float", func() (foundations.Value, error) { return Quo(foundations.Float(7.5), foundations.Int(2)) }, foundations.Int(3)},
+		{"rem", func() (foundations.Value, error) { return Rem(foundations.Int(-7), foundations.Int(2)) }, foundations.Int(-1)},
+		{"div-euclid", func() (foundations.Value, error) { return DivEuclid(foundations.Int(-7), foundations.Int(2)) }, foundations.Int(-4)},
+		{"div-euclid negative divisor", func() (foundations.Value, error) { return DivEuclid(foundations.Int(7), foundations.Int(-2)) }, foundations.Int(-3)},
+		{"rem-euclid", func() (foundations.Value, error) { return RemEuclid(foundations.Int(-7), foundations.Int(-2)) }, foundations.Int(1)},
+		{"rem-euclid float", func() (foundations.Value, error) { return RemEuclid(foundations.Float(-7), foundations.Float(2)) }, foundations.Float(1)},
+		{"gcd", func() (foundations.Value, error) { return Gcd(foundations.Int(-12), foundations.Int(18)) }, foundations.Int(6)},
+		{"lcm", func() (foundations.Value, error) { return Lcm(foundations.Int(4), foundations.Int(-6)) }, foundations.Int(12)},
+		{"binom", func() (foundations.Value, error) { return Binom(foundations.Int(10), foundations.Int(3)) }, foundations.Int(120)},
+		{"binom large", func() (foundations.Value, error) { return Binom(foundations.Int(62), foundations.Int(31)) }, foundations.Int(465428353255261088)},
+		{"binom out of range", func() (foundations.Value, error) { return Binom(foundations.Int(3), foundations.Int(5)) }, foundations.Int(0)},
+		{"fact", func() (foundations.Value, error) { return Fact(foundations.Int(20)) }, foundations.Int(2432902008176640000)},
+		{"perm", func() (foundations.Value, error) { return Perm(foundations.Int(5), foundations.Int(3)) }, foundations.Int(60)},
+		{"min", func() (foundations.Value, error) {
+			return Min(foundations.Int(3), foundations.Float(-1.5), foundations.Int(2))
+		}, foundations.Float(-1.5)},
+		{"max", func() (foundations.Value, error) {
+			return Max(foundations.Str("a"), foundations.Str("c"), foundations.Str("b"))
+		}, foundations.Str("c")},
+		{"clamp", func() (foundations.Value, error) {
+			return Clamp(foundations.Int(12), foundations.Int(0), foundations.Int(10))
+		}, foundations.Int(10)},
+		{"odd", func() (foundations.Value, error) { return Odd(foundations.Int(-3)) }, foundations.Bool(true)},
+	}
+	for _, tt := range tests {
+		got, err := tt.call()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %v (%T), want %v (%T)", tt.name, got, got, tt.want, tt.want)
+		}
+	}
+}
+
+func TestLogarithms(t *testing.T) {
+	got, err := Log(foundations.Int(1000), foundations.Float(10))
+	assertFloatResult(t, "Log", got, err, 3, false)
+	got, err = Log(foundations.Int(8), foundations.Int(2))
+	assertFloatResult(t, "Log", got, err, 3, false)
+	got, err = Log(foundations.Int(9), foundations.Int(3))
+	assertFloatResult(t, "Log", got, err, 2, false)
+	got, err = Ln(foundations.Float(math.E))
+	assertFloatResult(t, "Ln", got, err, 1, false)
+}
+
+func TestOverflowAndDomainErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		call func() (foundations.Value, error)
+	}{
+		{"pow overflow", func() (foundations.Value, error) { return Pow(foundations.Int(2), foundations.Int(63)) }},
+		{"pow zero to negative", func() (foundations.Value, error) { return Pow(foundations.Int(0), foundations.Int(-1)) }},
+		{"exp overflow", func() (foundations.Value, error) { return Exp(foundations.Int(1000)) }},
+		{"fact overflow", func() (foundations.Value, error) { return Fact(foundations.Int(21)) }},
+		{"fact negative", func() (foundations.Value, error) { return Fact(foundations.Int(-1)) }},
+		{"binom overflow", func() (foundations.Value, error) { return Binom(foundations.Int(100), foundations.Int(50)) }},
+		{"perm overflow", func() (foundations.Value, error) { return Perm(foundations.Int(30), foundations.Int(20)) }},
+		{"lcm overflow", func() (foundations.Value, error) { return Lcm(foundations.Int(math.MaxInt64), foundations.Int(2)) }},
+		{"gcd of most negative", func() (foundations.Value, error) { return Gcd(foundations.Int(math.MinInt64), foundations.Int(0)) }},
+		{"quo overflow", func() (foundations.Value, error) { return Quo(foundations.Int(math.MinInt64), foundations.Int(-1)) }},
+		{"quo by zero", func() (foundations.Value, error) { return Quo(foundations.Int(1), foundations.Int(0)) }},
+		{"rem by zero", func() (foundations.Value, error) { return Rem(foundations.Float(1), foundations.Float(0)) }},
+		{"floor too large", func() (foundations.Value, error) { return Floor(foundations.Float(1e300)) }},
+		{"round overflow", func() (foundations.Value, error) { return Round(foundations.Int(math.MaxInt64), -1) }},
+		{"log of zero", func() (foundations.Value, error) { return Log(foundations.Int(0), foundations.Int(10)) }},
+		{"log base one", func() (foundations.Value, error) { return Log(foundations.Int(5), foundations.Int(1)) }},
+		{"ln of negative", func() (foundations.Value, error) { return Ln(foundations.Int(-1)) }},
+		{"min of nothing", func() (foundations.Value, error) { return Min() }},
+		{"max of mixed types", func() (foundations.Value, error) { return Max(foundations.Int(1), foundations.Str("a")) }},
+		{"clamp inverted", func() (foundations.Value, error) {
+			return Clamp(foundations.Int(1), foundations.Int(5), foundations.Int(0))
+		}},
+		{"even of float", func() (foundations.Value, error) { return Even(foundations.Float(2)) }},
+	}
+	for _, tt := range tests {
+		if got, err := tt.call(); err == nil {
+			t.Errorf("%s = %v, want an error", tt.name, got)
+		}
+	}
+}
@@ -4,4 +4,13 @@
 // - Trigonometric functions (sin, cos, tan, etc.)
 // - Hyperbolic functions (sinh, cosh, tanh)
 // - Inverse trigonometric functions (asin, acos, atan, atan2)
+// - Powers, roots, and logarithms (pow, exp, sqrt, root, ln, log)
+// - Rounding (floor, ceil, round, trunc, fract)
+// - Integer division (quo, rem, div-euclid, rem-euclid)
+// - Number theory (gcd, lcm, binom, fact, perm)
+// - Comparisons (abs, min, max, clamp, even, odd)
+// - Seeded random numbers (random)
+//
+// Integer results that don't fit into 64 bits are errors instead of
+// silently turning into floats.
 package calc
@@ -0,0 +1,297 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Module creates the `calc` module with the calculation functions and the
+// constants pi, tau, e, inf, and nan.
+// Matches Rust: pub fn module() -> Module in foundations/calc.rs
+func Module() *foundations.Module {
+	scope := foundations.NewScope()
+	define := func(f *foundations.Func) {
+		scope.Define(*f.Name, foundations.FuncValue{Func: f}, syntax.Detached())
+	}
+
+	scope.Define("pi", foundations.Float(math.Pi), syntax.Detached())
+	scope.Define("tau", foundations.Float(2*math.Pi), syntax.Detached())
+	scope.Define("e", foundations.Float(math.E), syntax.Detached())
+	scope.Define("inf", foundations.Float(math.Inf(1)), syntax.Detached())
+	scope.Define("nan", foundations.Float(math.NaN()), syntax.Detached())
+
+	for _, f := range []struct {
+		name string
+		call func(foundations.Value) (foundations.Value, error)
+	}{
+		{"abs", foundations.Abs},
+		{"exp", Exp},
+		{"sqrt", foundations.Sqrt},
+		{"ln", Ln},
+		{"sin", Sin},
+		{"cos", Cos},
+		{"tan", Tan},
+		{"asin", Asin},
+		{"acos", Acos},
+		{"atan", Atan},
+		{"sinh", Sinh},
+		{"cosh", Cosh},
+		{"tanh", Tanh},
+		{"floor", Floor},
+		{"ceil", Ceil},
+		{"trunc", Trunc},
+		{"fract", Fract},
+		{"fact", Fact},
+		{"even", Even},
+		{"odd", Odd},
+	} {
+		define(unaryFunc(f.name, "value", f.call))
+	}
+
+	for _, f := range []struct {
+		name, first, second string
+		call                func(a, b foundations.Value) (foundations.Value, error)
+	}{
+		{"pow", "base", "exponent", Pow},
+		{"root", "radicand", "index", foundations.Root},
+		{"quo", "dividend", "divisor", Quo},
+		{"rem", "dividend", "divisor", Rem},
+		{"div-euclid", "dividend", "divisor", DivEuclid},
+		{"rem-euclid", "dividend", "divisor", RemEuclid},
+		{"gcd", "a", "b", Gcd},
+		{"lcm", "a", "b", Lcm},
+		{"binom", "n", "k", Binom},
+		{"perm", "base", "numbers", Perm},
+		// Typst takes the coordinates in x, y order.
+		{"atan2", "x", "y", func(x, y foundations.Value) (foundations.Value, error) { return Atan2(y, x) }},
+	} {
+		define(binaryFunc(f.name, f.first, f.second, f.call))
+	}
+
+	define(logFunc())
+	define(roundFunc())
+	define(extremumFunc("min", Min))
+	define(extremumFunc("max", Max))
+	define(clampFunc())
+	define(randomFunc())
+
+	return &foundations.Module{Name: "calc", Scope: scope}
+}
+
+// calcFunc creates a native function of the calc module.
+func calcFunc(name string, params []foundations.ParamInfo, call func(args *foundations.Args) (foundations.Value, error)) *foundations.Func {
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				return call(args)
+			},
+			Info: &foundations.FuncInfo{Name: name, Params: params},
+		},
+	}
+}
+
+// atSpan attributes an error of a calculation to the span of the call.
+func atSpan(value foundations.Value, err error, span syntax.Span) (foundations.Value, error) {
+	if opErr, ok := err.(*foundations.OpError); ok {
+		diag := foundations.NewSourceError(span, opErr.Message)
+		if opErr.Hint != "" {
+			diag = diag.WithHint(opErr.Hint)
+		}
+		return nil, diag
+	}
+	return value, err
+}
+
+// unaryFunc creates a function that takes a single positional argument.
+func unaryFunc(name, param string, call func(foundations.Value) (foundations.Value, error)) *foundations.Func {
+	params := []foundations.ParamInfo{{Name: param, Type: foundations.TypeDyn}}
+	return calcFunc(name, params, func(args *foundations.Args) (foundations.Value, error) {
+		arg, err := args.Expect(param)
+		if err != nil {
+			return nil, err
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		value, err := call(arg.V)
+		return atSpan(value, err, args.Span)
+	})
+}
+
+// binaryFunc creates a function that takes two positional arguments.
+func binaryFunc(name, first, second string, call func(a, b foundations.Value) (foundations.Value, error)) *foundations.Func {
+	params := []foundations.ParamInfo{
+		{Name: first, Type: foundations.TypeDyn},
+		{Name: second, Type: foundations.TypeDyn},
+	}
+	return calcFunc(name, params, func(args *foundations.Args) (foundations.Value, error) {
+		a, err := args.Expect(first)
+		if err != nil {
+			return nil, err
+		}
+		b, err := args.Expect(second)
+		if err != nil {
+			return nil, err
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		value, err := call(a.V, b.V)
+		return atSpan(value, err, args.Span)
+	})
+}
+
+// logFunc creates `log(value, base: 10)`.
+func logFunc() *foundations.Func {
+	params := []foundations.ParamInfo{
+		{Name: "value", Type: foundations.TypeDyn},
+		{Name: "base", Type: foundations.TypeDyn, Default: foundations.Float(10), Named: true},
+	}
+	return calcFunc("log", params, func(args *foundations.Args) (foundations.Value, error) {
+		v, err := args.Expect("value")
+		if err != nil {
+			return nil, err
+		}
+		var base foundations.Value = foundations.Float(10)
+		if b := args.Named("base"); b != nil {
+			base = b.V
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		value, err := Log(v.V, base)
+		return atSpan(value, err, args.Span)
+	})
+}
+
+// roundFunc creates `round(value, digits: 0)`.
+func roundFunc() *foundations.Func {
+	params := []foundations.ParamInfo{
+		{Name: "value", Type: foundations.TypeDyn},
+		{Name: "digits", Type: foundations.TypeInt, Default: foundations.Int(0), Named: true},
+	}
+	return calcFunc("round", params, func(args *foundations.Args) (foundations.Value, error) {
+		v, err := args.Expect("value")
+		if err != nil {
+			return nil, err
+		}
+		var digits int64
+		if d := args.Named("digits"); d != nil {
+			n, ok := d.V.(foundations.Int)
+			if !ok {
+				return nil, &foundations.TypeMismatchError{Expected: "integer", Got: d.V.Type().String(), Field: "digits", Span: d.Span}
+			}
+			digits = int64(n)
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		value, err := Round(v.V, digits)
+		return atSpan(value, err, args.Span)
+	})
+}
+
+// extremumFunc creates `min(..values)` or `max(..values)`.
+func extremumFunc(name string, call func(values ...foundations.Value) (foundations.Value, error)) *foundations.Func {
+	params := []foundations.ParamInfo{{Name: "values", Type: foundations.TypeDyn, Variadic: true}}
+	return calcFunc(name, params, func(args *foundations.Args) (foundations.Value, error) {
+		var values []foundations.Value
+		for _, arg := range args.All() {
+			values = append(values, arg.V)
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		value, err := call(values...)
+		return atSpan(value, err, args.Span)
+	})
+}
+
+// clampFunc creates `clamp(value, min, max)`.
+func clampFunc() *foundations.Func {
+	params := []foundations.ParamInfo{
+		{Name: "value", Type: foundations.TypeDyn},
+		{Name: "min", Type: foundations.TypeDyn},
+		{Name: "max", Type: foundations.TypeDyn},
+	}
+	return calcFunc("clamp", params, func(args *foundations.Args) (foundations.Value, error) {
+		var values [3]foundations.Value
+		for i, param := range params {
+			arg, err := args.Expect(param.Name)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = arg.V
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+		value, err := Clamp(values[0], values[1], values[2])
+		return atSpan(value, err, args.Span)
+	})
+}
+
+// maxRandomCount is the most numbers random draws at once. It keeps a
+// single call from exhausting memory.
+const maxRandomCount = 1 << 24
+
+// randomFunc creates `random(seed, ..bounds, count: none)`. The seed is
+// required so that a document always compiles to the same output.
+func randomFunc() *foundations.Func {
+	params := []foundations.ParamInfo{
+		{Name: "seed", Type: foundations.TypeDyn},
+		{Name: "bounds", Type: foundations.TypeDyn, Variadic: true},
+		{Name: "count", Type: foundations.TypeDyn, Default: foundations.None, Named: true},
+	}
+	return calcFunc("random", params, func(args *foundations.Args) (foundations.Value, error) {
+		arg, err := args.Expect("seed")
+		if err != nil {
+			return nil, err
+		}
+		seed, ok := foundations.CastSeed(arg.V)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{Expected: "integer or string", Got: arg.V.Type().String(), Field: "seed", Span: arg.Span}
+		}
+		count := int64(-1)
+		if c := args.Named("count"); c != nil && !foundations.IsNone(c.V) {
+			n, ok := c.V.(foundations.Int)
+			if !ok {
+				return nil, &foundations.TypeMismatchError{Expected: "integer or none", Got: c.V.Type().String(), Field: "count", Span: c.Span}
+			}
+			if n < 0 {
+				return nil, foundations.NewSourceError(c.Span, "number must be at least zero")
+			}
+			if n > maxRandomCount {
+				return nil, foundations.NewSourceError(c.Span, fmt.Sprintf("number must be at most %d", maxRandomCount))
+			}
+			count = int64(n)
+		}
+		var bounds []foundations.Value
+		for _, bound := range args.All() {
+			bounds = append(bounds, bound.V)
+		}
+		if err := args.Finish(); err != nil {
+			return nil, err
+		}
+
+		rng := foundations.NewRng(seed)
+		if count < 0 {
+			value, err := Random(rng, bounds...)
+			return atSpan(value, err, args.Span)
+		}
+		items := make([]foundations.Value, 0, min(count, 1024))
+		for i := int64(0); i < count; i++ {
+			value, err := Random(rng, bounds...)
+			if err != nil {
+				return atSpan(nil, err, args.Span)
+			}
+			items = append(items, value)
+		}
+		return foundations.NewArray(items...), nil
+	})
+}
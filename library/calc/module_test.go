@@ -0,0 +1,60 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// callCalc calls a function of the calc module with positional and named
+// arguments.
+func callCalc(t *testing.T, name string, positional []foundations.Value, named map[string]foundations.Value) (foundations.Value, error) {
+	t.Helper()
+	binding := Module().Scope.Get(name)
+	if binding == nil {
+		t.Fatalf("calc has no %s", name)
+	}
+	f := binding.Read().(foundations.FuncValue).Func
+	args := foundations.NewArgs(syntax.Detached())
+	for _, v := range positional {
+		args.Push(syntax.Detached(), v)
+	}
+	for key, v := range named {
+		key := foundations.Str(key)
+		args.Items = append(args.Items, foundations.Arg{Name: &key, Value: syntax.Spanned[foundations.Value]{V: v}})
+	}
+	return f.Repr.(foundations.NativeFunc).Func(foundations.Engine{}, foundations.Context{}, args)
+}
+
+func TestModule(t *testing.T) {
+	for _, name := range []string{"pi", "tau", "e", "inf", "nan", "abs", "pow", "exp", "sqrt", "root", "log", "ln",
+		"floor", "ceil", "round", "trunc", "fract", "quo", "rem", "div-euclid", "rem-euclid",
+		"gcd", "lcm", "binom", "fact", "perm", "min", "max", "clamp", "even", "odd", "atan2"} {
+		if Module().Scope.Get(name) == nil {
+			t.Errorf("calc.%s is not defined", name)
+		}
+	}
+
+	got, err := callCalc(t, "round", []foundations.Value{foundations.Float(2.718)}, map[string]foundations.Value{"digits": foundations.Int(1)})
+	assertFloatResult(t, "round", got, err, 2.7, false)
+
+	got, err = callCalc(t, "log", []foundations.Value{foundations.Int(8)}, map[string]foundations.Value{"base": foundations.Int(2)})
+	assertFloatResult(t, "log", got, err, 3, false)
+
+	// Typst takes the coordinates of atan2 in x, y order.
+	got, err = callCalc(t, "atan2", []foundations.Value{foundations.Int(0), foundations.Int(1)}, nil)
+	assertFloatResult(t, "atan2", got, err, 1.5707963267948966, false)
+
+	if got, err := callCalc(t, "max", []foundations.Value{foundations.Int(1), foundations.Int(5), foundations.Int(3)}, nil); err != nil || got != foundations.Int(5) {
+		t.Errorf("max(1, 5, 3) = %v, %v, want 5", got, err)
+	}
+
+	_, err = callCalc(t, "fact", []foundations.Value{foundations.Int(25)}, nil)
+	if diag, ok := err.(foundations.SourceDiagnostic); !ok || diag.Message != "the result is too large" {
+		t.Errorf("fact(25) error = %v, want a diagnostic that the result is too large", err)
+	}
+	if _, err := callCalc(t, "gcd", []foundations.Value{foundations.Int(1)}, nil); err == nil {
+		t.Error("gcd accepted a missing argument")
+	}
+}
@@ -0,0 +1,58 @@
+package calc
+
+import (
+	"math"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// Random draws the next number from the generator. Without bounds, it is
+// a float in [0, 1). With two integer bounds, it is an integer in
+// [low, high]; with other numeric bounds, it is a float in [low, high).
+func Random(rng *foundations.Rng, bounds ...foundations.Value) (foundations.Value, error) {
+	switch len(bounds) {
+	case 0:
+		return foundations.Float(rng.Float64()), nil
+	case 2:
+	default:
+		return nil, &foundations.OpError{
+			Message: "random expected either no bounds or a lower and an upper bound",
+		}
+	}
+
+	low, lowInt := bounds[0].(foundations.Int)
+	high, highInt := bounds[1].(foundations.Int)
+	if lowInt && highInt {
+		if low > high {
+			return nil, &foundations.OpError{Message: "lower bound must not be larger than upper bound"}
+		}
+		span := uint64(high) - uint64(low)
+		if span == math.MaxUint64 {
+			return foundations.Int(rng.Uint64()), nil
+		}
+		return foundations.Int(uint64(low) + rng.Uint64N(span+1)), nil
+	}
+
+	lo, ok := toFloat64(bounds[0])
+	if !ok {
+		return nil, numericTypeError("random", bounds[0])
+	}
+	hi, ok := toFloat64(bounds[1])
+	if !ok {
+		return nil, numericTypeError("random", bounds[1])
+	}
+	if math.IsNaN(lo) || math.IsNaN(hi) || math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		return nil, &foundations.OpError{Message: "bounds must be finite"}
+	}
+	if lo > hi {
+		return nil, &foundations.OpError{Message: "lower bound must not be larger than upper bound"}
+	}
+	// Interpolate rather than scale the difference of the bounds, which
+	// overflows for bounds of large magnitude and opposite signs.
+	r := rng.Float64()
+	value := lo*(1-r) + hi*r
+	if value >= hi && lo < hi {
+		value = math.Nextafter(hi, lo)
+	}
+	return foundations.Float(value), nil
+}
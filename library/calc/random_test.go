@@ -0,0 +1,121 @@
+package calc
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+func TestRandomDeterministic(t *testing.T) {
+	for _, seed := range []foundations.Value{foundations.Int(42), foundations.Str("quiz")} {
+		a, err := callCalc(t, "random", []foundations.Value{seed}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := callCalc(t, "random", []foundations.Value{seed}, nil)
+		if a != b {
+			t.Errorf("random(%v) = %v, then %v", seed, a, b)
+		}
+		f, ok := a.(foundations.Float)
+		if !ok || f < 0 || f >= 1 {
+			t.Errorf("random(%v) = %v, want float in [0, 1)", seed, a)
+		}
+	}
+
+	x, _ := callCalc(t, "random", []foundations.Value{foundations.Int(1)}, nil)
+	y, _ := callCalc(t, "random", []foundations.Value{foundations.Int(2)}, nil)
+	if x == y {
+		t.Errorf("different seeds gave the same number %v", x)
+	}
+}
+
+func TestRandomBounds(t *testing.T) {
+	rng := foundations.NewRng(7)
+	seen := map[foundations.Int]bool{}
+	for i := 0; i < 200; i++ {
+		v, err := Random(rng, foundations.Int(1), foundations.Int(6))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := v.(foundations.Int)
+		if n < 1 || n > 6 {
+			t.Fatalf("die roll %d out of range", n)
+		}
+		seen[n] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("die rolls covered %d faces, want 6", len(seen))
+	}
+
+	v, err := Random(rng, foundations.Float(-1), foundations.Int(1))
+	if f, ok := v.(foundations.Float); err != nil || !ok || f < -1 || f >= 1 {
+		t.Errorf("Random(-1.0, 1) = %v, %v", v, err)
+	}
+	for i := 0; i < 100; i++ {
+		v, _ := Random(rng, foundations.Float(-math.MaxFloat64), foundations.Float(math.MaxFloat64))
+		if f := float64(v.(foundations.Float)); math.IsInf(f, 0) || f >= math.MaxFloat64 {
+			t.Fatalf("Random(-max, max) = %v", f)
+		}
+	}
+	if v, _ := Random(rng, foundations.Int(3), foundations.Int(3)); v != foundations.Int(3) {
+		t.Errorf("Random(3, 3) = %v", v)
+	}
+
+	for _, bounds := range [][]foundations.Value{
+		{foundations.Int(1)},
+		{foundations.Int(2), foundations.Int(1)},
+		{foundations.Float(0), foundations.Float(math.Inf(1))},
+		{foundations.Str("a"), foundations.Int(1)},
+	} {
+		if _, err := Random(rng, bounds...); err == nil {
+			t.Errorf("Random(%v) should fail", bounds)
+		}
+	}
+}
+
+func TestRandomArgs(t *testing.T) {
+	if _, err := callCalc(t, "random", nil, nil); err == nil {
+		t.Error("random() without a seed should fail")
+	}
+	if _, err := callCalc(t, "random", []foundations.Value{foundations.Float(1.5)}, nil); err == nil {
+		t.Error("random(1.5) should fail")
+	}
+
+	got, err := callCalc(t, "random", []foundations.Value{foundations.Int(3), foundations.Int(0), foundations.Int(9)},
+		map[string]foundations.Value{"count": foundations.Int(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr, ok := got.(*foundations.Array)
+	if !ok || arr.Len() != 5 {
+		t.Fatalf("random(count: 5) = %v", got)
+	}
+	first, _ := callCalc(t, "random", []foundations.Value{foundations.Int(3), foundations.Int(0), foundations.Int(9)}, nil)
+	if arr.At(0) != first {
+		t.Errorf("first of count = %v, single draw = %v", arr.At(0), first)
+	}
+
+	_, err = callCalc(t, "random", []foundations.Value{foundations.Int(1)}, map[string]foundations.Value{"count": foundations.Int(1 << 62)})
+	if diag, ok := err.(foundations.SourceDiagnostic); !ok || diag.Message != "number must be at most 16777216" {
+		t.Errorf("random(count: 1 << 62) error = %v, want a diagnostic", err)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	arr := foundations.NewArray(foundations.Int(1), foundations.Int(2), foundations.Int(3), foundations.Int(4), foundations.Int(5))
+	a, b := arr.Shuffle(9), arr.Shuffle(9)
+	sum := foundations.Int(0)
+	for i := 0; i < a.Len(); i++ {
+		if a.At(i) != b.At(i) {
+			t.Fatalf("shuffles with the same seed differ at %d", i)
+		}
+		sum += a.At(i).(foundations.Int)
+	}
+	if a.Len() != 5 || sum != 15 {
+		t.Errorf("shuffle is not a permutation")
+	}
+	if arr.At(0) != foundations.Int(1) {
+		t.Errorf("shuffle modified the original array")
+	}
+}
@@ -0,0 +1,122 @@
+package foundations
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// arenaBlockSize is the number of values an arena allocates at once.
+const arenaBlockSize = 256
+
+// Arena allocates the small content elements that evaluation creates for
+// each piece of markup in blocks, so that evaluating a large document
+// costs one allocation per block instead of several per element. This
+// takes pressure off the garbage collector.
+//
+// Elements are never freed individually: a block is collected once none
+// of its elements are referenced anymore. When an arena is released, the
+// rest of its current blocks is handed to the next compilation, so that
+// incremental compilations reuse them. Elements already handed out are
+// never reused, since content may outlive its compilation in the memo
+// caches.
+//
+// Packages that define elements of their own allocate them with Alloc,
+// like the text package does for the text of markup.
+//
+// An arena is safe for concurrent use. A nil arena allocates each element
+// on its own.
+type Arena struct {
+	mu sync.Mutex
+	// blocks holds the current block of each element type T as a *[]T.
+	blocks map[reflect.Type]any
+	slots  []ContentElement
+}
+
+// arenas holds the released arenas.
+var arenas = sync.Pool{New: func() any { return &Arena{} }}
+
+// AcquireArena returns an arena for a compilation, reusing the blocks of a
+// released one if there is any.
+func AcquireArena() *Arena {
+	return arenas.Get().(*Arena)
+}
+
+// Release hands the rest of the arena's blocks to a later compilation.
+// The arena must not be used afterwards.
+func (a *Arena) Release() {
+	if a != nil {
+		arenas.Put(a)
+	}
+}
+
+// Alloc returns a new zero value of type T from the arena's block for T.
+// A nil arena allocates the value on its own.
+func Alloc[T any](a *Arena) *T {
+	if a == nil {
+		return new(T)
+	}
+	key := reflect.TypeFor[T]()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	block, ok := a.blocks[key].(*[]T)
+	if !ok {
+		if a.blocks == nil {
+			a.blocks = make(map[reflect.Type]any)
+		}
+		block = new([]T)
+		a.blocks[key] = block
+	}
+	return next(block)
+}
+
+// Symbol returns a new symbol element with the given text and span.
+func (a *Arena) Symbol(text string, span syntax.Span) *SymbolElem {
+	elem := Alloc[SymbolElem](a)
+	elem.Text = text
+	elem.Span = span
+	return elem
+}
+
+// Space returns content holding a single space. Spaces have neither
+// fields nor a span, so all of them share one element and take no room
+// in the arena.
+func (a *Arena) Space() Content {
+	return SpaceElemShared()
+}
+
+// Parbreak returns content holding a single paragraph break. Like spaces,
+// all paragraph breaks share one element.
+func (a *Arena) Parbreak() Content {
+	return ParbreakElemShared()
+}
+
+// Content returns content holding just the element. Appending to the
+// elements of the content copies them, so the block they live in is
+// never written to.
+func (a *Arena) Content(elem ContentElement) Content {
+	if a == nil {
+		return Content{Elements: []ContentElement{elem}}
+	}
+	a.mu.Lock()
+	if len(a.slots) == 0 {
+		a.slots = make([]ContentElement, arenaBlockSize)
+	}
+	elems := a.slots[:1:1]
+	a.slots = a.slots[1:]
+	a.mu.Unlock()
+	elems[0] = elem
+	return Content{Elements: elems}
+}
+
+// next takes the next value from a block, allocating a new block if the
+// current one is used up.
+func next[T any](block *[]T) *T {
+	if len(*block) == 0 {
+		*block = make([]T, arenaBlockSize)
+	}
+	value := &(*block)[0]
+	*block = (*block)[1:]
+	return value
+}
@@ -0,0 +1,71 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestArena(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	for _, arena := range []*Arena{nil, AcquireArena()} {
+		first := arena.Content(arena.Symbol("α", span))
+		second := arena.Content(arena.Symbol("β", syntax.Detached()))
+		if got := first.PlainText() + second.PlainText(); got != "αβ" {
+			t.Errorf("plain text = %q, want αβ", got)
+		}
+		if first.Span() != span {
+			t.Errorf("span = %v, want %v", first.Span(), span)
+		}
+
+		// Appending to content from an arena must not overwrite the
+		// elements allocated after it.
+		joined := append(first.Elements, &SpaceElem{})
+		if len(joined) != 2 || second.PlainText() != "β" {
+			t.Errorf("appending overwrote the next element: %q", second.PlainText())
+		}
+		arena.Release()
+	}
+
+	arena := AcquireArena()
+	defer arena.Release()
+	for i := range 2 * arenaBlockSize {
+		if elem := arena.Symbol("x", span); elem.Text != "x" || elem.Span != span {
+			t.Fatalf("symbol %d = %+v", i, elem)
+		}
+	}
+}
+
+func TestArenaAlloc(t *testing.T) {
+	arena := AcquireArena()
+	defer arena.Release()
+	// Each type gets blocks of its own.
+	sym := Alloc[SymbolElem](arena)
+	quote := Alloc[SmartQuoteElem](arena)
+	sym.Text = "x"
+	if quote.Double || Alloc[SymbolElem](arena).Text != "" {
+		t.Error("allocated values are not zero")
+	}
+	if Alloc[SymbolElem](nil) == nil {
+		t.Error("a nil arena returned no value")
+	}
+	if arena.Space().PlainText() != " " || arena.Parbreak().PlainText() != "\n\n" {
+		t.Error("wrong space or paragraph break")
+	}
+}
+
+func TestSharedContent(t *testing.T) {
+	space := SpaceElemShared()
+	if &space.Elements[0] != &SpaceElemShared().Elements[0] {
+		t.Error("spaces don't share their elements")
+	}
+	// Appending to shared content copies it.
+	_ = append(space.Elements, &ParbreakElem{})
+	if got := ParbreakElemShared().PlainText() + SpaceElemShared().PlainText(); got != "\n\n " {
+		t.Errorf("shared content changed: %q", got)
+	}
+	if SmartQuoteElemPacked(true).Elements[0].(*SmartQuoteElem).Double != true ||
+		SmartQuoteElemPacked(false).Elements[0].(*SmartQuoteElem).Double {
+		t.Error("smart quotes have the wrong kind")
+	}
+}
@@ -218,6 +218,43 @@ func (a *Args) ToNamed() *Dict {
 	return result
 }
 
+// At returns the positional argument at an index or the named argument
+// with a name. A negative index counts from the end of the positional
+// arguments. If a name is given more than once, the last one wins.
+// Matches Rust: #[func] pub fn at(&self, key: ArgumentKey, default: Option<Value>) -> StrResult<Value>
+func (a *Args) At(key Value, def Value) (Value, error) {
+	var slot Value
+	switch key := key.(type) {
+	case Int:
+		pos := a.Pos()
+		index := int(key)
+		if index < 0 {
+			index += pos.Len()
+		}
+		if index >= 0 && index < pos.Len() {
+			slot = pos.items[index]
+		}
+	case Str:
+		for _, item := range a.Items {
+			if item.Name != nil && *item.Name == key {
+				slot = item.Value.V
+			}
+		}
+	default:
+		return nil, &OpError{Message: fmt.Sprintf("expected integer or string, found %s", key.Type())}
+	}
+	if slot != nil {
+		return slot, nil
+	}
+	if def != nil {
+		return def, nil
+	}
+	return nil, &OpError{
+		Message: fmt.Sprintf("arguments do not contain key %s", Repr(key)),
+		Hint:    "use `default` to specify a default value",
+	}
+}
+
 // Clone creates a deep copy of the Args.
 func (a *Args) Clone() *Args {
 	if a == nil {
@@ -270,6 +307,56 @@ func (v ArgsValue) Clone() Value {
 }
 func (ArgsValue) isValue() {}
 
+// argsEqual reports whether two argument lists have the same names and
+// values in the same order.
+func argsEqual(a, b *Args) bool {
+	if len(a.Items) != len(b.Items) {
+		return false
+	}
+	for i, x := range a.Items {
+		y := b.Items[i]
+		if (x.Name == nil) != (y.Name == nil) || (x.Name != nil && *x.Name != *y.Name) {
+			return false
+		}
+		if !Equal(x.Value.V, y.Value.V) {
+			return false
+		}
+	}
+	return true
+}
+
+// ArgsConstruct captures the arguments it is called with, as in
+// `arguments(1, 2, key: 3)`.
+// Matches Rust: Args::construct
+func ArgsConstruct(args *Args) (Value, error) {
+	return ArgsValue{Args: args.Take()}, nil
+}
+
+func init() {
+	m := newMethods(TypeArgs)
+	m.define("pos", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self.(ArgsValue).Args.Pos(), nil
+	})
+	m.define("named", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self.(ArgsValue).Args.ToNamed(), nil
+	})
+	m.define("at", []ParamInfo{
+		{Name: "key", Type: TypeDyn},
+		{Name: "default", Type: TypeDyn, Named: true},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		key, err := args.Expect("key")
+		if err != nil {
+			return nil, err
+		}
+		var def Value
+		if arg := args.Named("default"); arg != nil {
+			def = arg.V
+		}
+		return self.(ArgsValue).Args.At(key.V, def)
+	})
+	m.register()
+}
+
 // ----------------------------------------------------------------------------
 // Error Types
 // ----------------------------------------------------------------------------
@@ -5,6 +5,7 @@ package foundations
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/boergens/gotypst/syntax"
 )
@@ -258,3 +259,505 @@ func (a *Array) Iter() []Value {
 	}
 	return a.items
 }
+
+// Slice returns the items from start to end, where nil is the end of the
+// array. Negative indices count from the end.
+// Matches Rust: Array::slice
+func (a *Array) Slice(start int64, end *int64) (*Array, error) {
+	length := int64(a.Len())
+	s := start
+	if s < 0 {
+		s += length
+	}
+	e := length
+	if end != nil {
+		e = *end
+		if e < 0 {
+			e += length
+		}
+	}
+	if s < 0 || s > length || e < s || e > length {
+		return nil, &OpError{Message: fmt.Sprintf("array index out of bounds (index: %d, len: %d)", start, length)}
+	}
+	return NewArray(append([]Value(nil), a.items[s:e]...)...), nil
+}
+
+// Flatten flattens nested arrays into a single array.
+// Matches Rust: Array::flatten
+func (a *Array) Flatten() *Array {
+	result := NewArray()
+	for _, item := range a.Items() {
+		if nested, ok := item.(*Array); ok {
+			result.items = append(result.items, nested.Flatten().items...)
+		} else {
+			result.items = append(result.items, item)
+		}
+	}
+	return result
+}
+
+// Rev returns the items in reverse order.
+func (a *Array) Rev() *Array {
+	items := make([]Value, a.Len())
+	for i, item := range a.Items() {
+		items[len(items)-1-i] = item
+	}
+	return NewArray(items...)
+}
+
+// Shuffle returns the items in an order determined by the seed. The
+// same seed always produces the same order.
+func (a *Array) Shuffle(seed uint64) *Array {
+	items := append([]Value(nil), a.Items()...)
+	rng := NewRng(seed)
+	for i := len(items) - 1; i > 0; i-- {
+		j := rng.Uint64N(uint64(i + 1))
+		items[i], items[j] = items[j], items[i]
+	}
+	return NewArray(items...)
+}
+
+// arrayFold combines the items of an array with an operator, as in `sum`
+// and `product`. Without items, it returns the default or fails.
+func arrayFold(a *Array, def *Spanned[Value], op func(lhs, rhs Value) (Value, error), what string) (Value, error) {
+	if a.IsEmpty() {
+		if def == nil {
+			return nil, &OpError{Message: fmt.Sprintf("cannot calculate %s of empty array with no default", what)}
+		}
+		return def.V, nil
+	}
+	acc := a.items[0]
+	for _, item := range a.items[1:] {
+		var err error
+		if acc, err = op(acc, item); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+// arraySorted sorts an array stably, by key if there is a key function.
+// Values that cannot be compared are an error.
+// Matches Rust: Array::sorted
+func arraySorted(engine *Engine, context *Context, a *Array, key *Func) (*Array, error) {
+	keys := a.Items()
+	if key != nil {
+		keys = make([]Value, a.Len())
+		for i, item := range a.Items() {
+			k, err := applyFunc(engine, context, key, item)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = k
+		}
+	}
+	order := make([]int, a.Len())
+	for i := range order {
+		order[i] = i
+	}
+	var cmpErr error
+	slices.SortStableFunc(order, func(i, j int) int {
+		c, err := compare(keys[i], keys[j])
+		if err != nil && cmpErr == nil {
+			cmpErr = err
+		}
+		return c
+	})
+	if cmpErr != nil {
+		return nil, cmpErr
+	}
+	items := make([]Value, len(order))
+	for i, idx := range order {
+		items[i] = a.items[idx]
+	}
+	return NewArray(items...), nil
+}
+
+func init() {
+	m := newMethods(TypeArray)
+	fn := func(name string) ParamInfo { return ParamInfo{Name: name, Type: TypeFunc} }
+	expectFunc := func(args *Args, what string) (*Func, error) {
+		return expectArg(args, what, "function", AsFunc)
+	}
+
+	m.define("len", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return Int(self.(*Array).Len()), nil
+	})
+	m.define("first", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self.(*Array).First()
+	})
+	m.define("last", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self.(*Array).Last()
+	})
+	m.define("at", []ParamInfo{
+		{Name: "index", Type: TypeInt},
+		{Name: "default", Type: TypeDyn, Named: true},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		index, err := expectArg(args, "index", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		def := args.Named("default")
+		slot, err := self.(*Array).AtMut(index)
+		if err != nil {
+			if def != nil {
+				return def.V, nil
+			}
+			return nil, err
+		}
+		return *slot, nil
+	})
+	m.define("slice", []ParamInfo{
+		{Name: "start", Type: TypeInt},
+		{Name: "end", Type: TypeInt, Default: None},
+		{Name: "count", Type: TypeInt, Named: true},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		start, err := expectArg(args, "start", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		end, err := sliceEnd(args, start)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Array).Slice(start, end)
+	})
+	m.define("contains", []ParamInfo{{Name: "value", Type: TypeDyn}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		value, err := args.Expect("value")
+		if err != nil {
+			return nil, err
+		}
+		return Bool(self.(*Array).Contains(value.V)), nil
+	})
+	m.define("find", []ParamInfo{fn("searcher")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		searcher, err := expectFunc(args, "searcher")
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range self.(*Array).Items() {
+			found, err := applyTest(engine, context, searcher, item)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return item, nil
+			}
+		}
+		return None, nil
+	})
+	m.define("position", []ParamInfo{fn("searcher")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		searcher, err := expectFunc(args, "searcher")
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range self.(*Array).Items() {
+			found, err := applyTest(engine, context, searcher, item)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return Int(i), nil
+			}
+		}
+		return None, nil
+	})
+	m.define("filter", []ParamInfo{fn("test")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		test, err := expectFunc(args, "test")
+		if err != nil {
+			return nil, err
+		}
+		result := NewArray()
+		for _, item := range self.(*Array).Items() {
+			keep, err := applyTest(engine, context, test, item)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				result.Push(item)
+			}
+		}
+		return result, nil
+	})
+	m.define("map", []ParamInfo{fn("mapper")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		mapper, err := expectFunc(args, "mapper")
+		if err != nil {
+			return nil, err
+		}
+		result := ArrayWithCapacity(self.(*Array).Len())
+		for _, item := range self.(*Array).Items() {
+			mapped, err := applyFunc(engine, context, mapper, item)
+			if err != nil {
+				return nil, err
+			}
+			result.Push(mapped)
+		}
+		return result, nil
+	})
+	m.define("enumerate", []ParamInfo{{Name: "start", Type: TypeInt, Named: true, Default: Int(0)}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		start, _, err := eatNamed(args, "start", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		result := ArrayWithCapacity(self.(*Array).Len())
+		for i, item := range self.(*Array).Items() {
+			result.Push(NewArray(Int(start+int64(i)), item))
+		}
+		return result, nil
+	})
+	m.define("zip", []ParamInfo{
+		{Name: "others", Type: TypeArray, Variadic: true},
+		{Name: "exact", Type: TypeBool, Named: true, Default: Bool(false)},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		exact, _, err := eatNamed(args, "exact", "boolean", AsBool)
+		if err != nil {
+			return nil, err
+		}
+		arrays := []*Array{self.(*Array)}
+		for _, arg := range args.All() {
+			other, ok := arg.V.(*Array)
+			if !ok {
+				return nil, &TypeMismatchError{Expected: "array", Got: arg.V.Type().String(), Field: "others", Span: arg.Span}
+			}
+			arrays = append(arrays, other)
+		}
+		length := arrays[0].Len()
+		for _, other := range arrays[1:] {
+			if exact && other.Len() != length {
+				return nil, &OpError{
+					Message: "second array has different length",
+					Hint:    fmt.Sprintf("expected %d items, found %d", length, other.Len()),
+				}
+			}
+			length = min(length, other.Len())
+		}
+		result := ArrayWithCapacity(length)
+		for i := 0; i < length; i++ {
+			tuple := ArrayWithCapacity(len(arrays))
+			for _, array := range arrays {
+				tuple.Push(array.At(i))
+			}
+			result.Push(tuple)
+		}
+		return result, nil
+	})
+	m.define("fold", []ParamInfo{{Name: "init", Type: TypeDyn}, fn("folder")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		init, err := args.Expect("init")
+		if err != nil {
+			return nil, err
+		}
+		folder, err := expectFunc(args, "folder")
+		if err != nil {
+			return nil, err
+		}
+		acc := init.V
+		for _, item := range self.(*Array).Items() {
+			if acc, err = applyFunc(engine, context, folder, acc, item); err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
+	})
+	m.define("reduce", []ParamInfo{fn("reducer")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		reducer, err := expectFunc(args, "reducer")
+		if err != nil {
+			return nil, err
+		}
+		a := self.(*Array)
+		if a.IsEmpty() {
+			return None, nil
+		}
+		acc := a.items[0]
+		for _, item := range a.items[1:] {
+			if acc, err = applyFunc(engine, context, reducer, acc, item); err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
+	})
+	m.define("sum", []ParamInfo{{Name: "default", Type: TypeDyn, Named: true}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return arrayFold(self.(*Array), args.Named("default"), Add, "sum")
+	})
+	m.define("product", []ParamInfo{{Name: "default", Type: TypeDyn, Named: true}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return arrayFold(self.(*Array), args.Named("default"), Mul, "product")
+	})
+	for name, all := range map[string]bool{"any": false, "all": true} {
+		m.define(name, []ParamInfo{fn("test")}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+			test, err := expectFunc(args, "test")
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range self.(*Array).Items() {
+				ok, err := applyTest(engine, context, test, item)
+				if err != nil {
+					return nil, err
+				}
+				if ok != all {
+					return Bool(ok), nil
+				}
+			}
+			return Bool(all), nil
+		})
+	}
+	m.define("flatten", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self.(*Array).Flatten(), nil
+	})
+	m.define("rev", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self.(*Array).Rev(), nil
+	})
+	m.define("shuffle", []ParamInfo{{Name: "seed", Type: TypeDyn}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		seed, err := expectArg(args, "seed", "integer or string", CastSeed)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Array).Shuffle(seed), nil
+	})
+	m.define("split", []ParamInfo{{Name: "at", Type: TypeDyn}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		at, err := args.Expect("at")
+		if err != nil {
+			return nil, err
+		}
+		result := NewArray()
+		part := NewArray()
+		for _, item := range self.(*Array).Items() {
+			if Equal(item, at.V) {
+				result.Push(part)
+				part = NewArray()
+			} else {
+				part.Push(item)
+			}
+		}
+		result.Push(part)
+		return result, nil
+	})
+	m.define("join", []ParamInfo{
+		{Name: "separator", Type: TypeDyn, Default: None},
+		{Name: "last", Type: TypeDyn, Named: true},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		var sep Value = None
+		if arg := args.Eat(); arg != nil {
+			sep = arg.V
+		}
+		last := sep
+		if arg := args.Named("last"); arg != nil {
+			last = arg.V
+		}
+		items := self.(*Array).Items()
+		var result Value = None
+		for i, item := range items {
+			var err error
+			if i > 0 {
+				separator := sep
+				if i == len(items)-1 {
+					separator = last
+				}
+				if result, err = Join(result, separator); err != nil {
+					return nil, err
+				}
+			}
+			if result, err = Join(result, item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	})
+	m.define("intersperse", []ParamInfo{{Name: "separator", Type: TypeDyn}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		sep, err := args.Expect("separator")
+		if err != nil {
+			return nil, err
+		}
+		result := NewArray()
+		for i, item := range self.(*Array).Items() {
+			if i > 0 {
+				result.Push(sep.V)
+			}
+			result.Push(item)
+		}
+		return result, nil
+	})
+	m.define("sorted", []ParamInfo{{Name: "key", Type: TypeFunc, Named: true}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		key, _, err := eatNamed(args, "key", "function", AsFunc)
+		if err != nil {
+			return nil, err
+		}
+		return arraySorted(engine, context, self.(*Array), key)
+	})
+	m.define("dedup", []ParamInfo{{Name: "key", Type: TypeFunc, Named: true}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		key, _, err := eatNamed(args, "key", "function", AsFunc)
+		if err != nil {
+			return nil, err
+		}
+		result := NewArray()
+		var seen []Value
+		for _, item := range self.(*Array).Items() {
+			k := item
+			if key != nil {
+				if k, err = applyFunc(engine, context, key, item); err != nil {
+					return nil, err
+				}
+			}
+			if !slices.ContainsFunc(seen, func(s Value) bool { return Equal(s, k) }) {
+				seen = append(seen, k)
+				result.Push(item)
+			}
+		}
+		return result, nil
+	})
+	m.define("chunks", []ParamInfo{
+		{Name: "chunk-size", Type: TypeInt},
+		{Name: "exact", Type: TypeBool, Named: true, Default: Bool(false)},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		size, err := expectArg(args, "chunk-size", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		exact, _, err := eatNamed(args, "exact", "boolean", AsBool)
+		if err != nil {
+			return nil, err
+		}
+		if size <= 0 {
+			return nil, &OpError{Message: "number must be positive"}
+		}
+		items := self.(*Array).Items()
+		result := NewArray()
+		for i := 0; i < len(items); i += int(size) {
+			end := min(i+int(size), len(items))
+			if exact && end-i < int(size) {
+				break
+			}
+			result.Push(NewArray(append([]Value(nil), items[i:end]...)...))
+		}
+		return result, nil
+	})
+	m.define("windows", []ParamInfo{{Name: "window-size", Type: TypeInt}}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		size, err := expectArg(args, "window-size", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		if size <= 0 {
+			return nil, &OpError{Message: "number must be positive"}
+		}
+		items := self.(*Array).Items()
+		result := NewArray()
+		for i := 0; i+int(size) <= len(items); i++ {
+			result.Push(NewArray(append([]Value(nil), items[i:i+int(size)]...)...))
+		}
+		return result, nil
+	})
+	m.define("to-dict", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		result := NewDict()
+		for _, item := range self.(*Array).Items() {
+			pair, ok := item.(*Array)
+			if !ok || pair.Len() != 2 {
+				return nil, &OpError{Message: "expected (str, any) pairs"}
+			}
+			key, ok := pair.At(0).(Str)
+			if !ok {
+				return nil, &OpError{Message: fmt.Sprintf("expected string as key, found %s", pair.At(0).Type())}
+			}
+			result.Set(string(key), pair.At(1))
+		}
+		return result, nil
+	})
+
+	m.register()
+}
@@ -0,0 +1,202 @@
+// Assertions and panics for Typst.
+// Translated from typst-library/src/foundations/mod.rs
+
+package foundations
+
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// AssertionError is returned when an assertion fails. It points at the
+// call of the failed assertion.
+type AssertionError struct {
+	Message string
+	Span    syntax.Span
+}
+
+func (e *AssertionError) Error() string {
+	return e.Message
+}
+
+// PanicError is returned by a call to panic. It points at the call.
+type PanicError struct {
+	Message string
+	Span    syntax.Span
+}
+
+func (e *PanicError) Error() string {
+	return e.Message
+}
+
+// AssertFunc creates the assert function, which fails with an error if the
+// condition is not fulfilled. Its scope holds the `eq` and `ne` variants.
+//
+// Matches Rust: pub fn assert in foundations/mod.rs
+func AssertFunc() *Func {
+	name := "assert"
+	scope := NewScope()
+	scope.Define("eq", FuncValue{Func: assertEqFunc()}, syntax.Detached())
+	scope.Define("ne", FuncValue{Func: assertNeFunc()}, syntax.Detached())
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: assertNative,
+			Info: &FuncInfo{
+				Name: "assert",
+				Params: []ParamInfo{
+					{Name: "condition", Type: TypeBool},
+					{Name: "message", Type: TypeStr, Default: None, Named: true},
+				},
+			},
+			Scope: scope,
+		},
+	}
+}
+
+func assertNative(engine Engine, context Context, args *Args) (Value, error) {
+	condition, err := args.Expect("condition")
+	if err != nil {
+		return nil, err
+	}
+	cond, ok := condition.V.(Bool)
+	if !ok {
+		return nil, &TypeMismatchError{Expected: "boolean", Got: condition.V.Type().String(), Span: condition.Span}
+	}
+	message, err := assertMessage(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	if !cond {
+		if message != nil {
+			return nil, &AssertionError{Message: "assertion failed: " + *message, Span: args.Span}
+		}
+		return nil, &AssertionError{Message: "assertion failed", Span: args.Span}
+	}
+	return None, nil
+}
+
+// assertEqFunc creates assert.eq, which fails if the two values are not
+// equal.
+//
+// Matches Rust: pub fn assert_eq in foundations/mod.rs
+func assertEqFunc() *Func {
+	return assertCompareFunc("eq", func(left, right Value, message *string) string {
+		if Equal(left, right) {
+			return ""
+		}
+		if message != nil {
+			return "equality assertion failed: " + *message
+		}
+		return "equality assertion failed: value " + Repr(left) + " was not equal to " + Repr(right)
+	})
+}
+
+// assertNeFunc creates assert.ne, which fails if the two values are equal.
+//
+// Matches Rust: pub fn assert_ne in foundations/mod.rs
+func assertNeFunc() *Func {
+	return assertCompareFunc("ne", func(left, right Value, message *string) string {
+		if !Equal(left, right) {
+			return ""
+		}
+		if message != nil {
+			return "inequality assertion failed: " + *message
+		}
+		return "inequality assertion failed: value " + Repr(left) + " was equal to " + Repr(right)
+	})
+}
+
+// assertCompareFunc creates an assertion that compares two values. The
+// check returns the failure message, or the empty string if the assertion
+// holds.
+func assertCompareFunc(name string, check func(left, right Value, message *string) string) *Func {
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: func(engine Engine, context Context, args *Args) (Value, error) {
+				left, err := args.Expect("left")
+				if err != nil {
+					return nil, err
+				}
+				right, err := args.Expect("right")
+				if err != nil {
+					return nil, err
+				}
+				message, err := assertMessage(args)
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				if failure := check(left.V, right.V, message); failure != "" {
+					return nil, &AssertionError{Message: failure, Span: args.Span}
+				}
+				return None, nil
+			},
+			Info: &FuncInfo{
+				Name: name,
+				Params: []ParamInfo{
+					{Name: "left", Type: TypeDyn},
+					{Name: "right", Type: TypeDyn},
+					{Name: "message", Type: TypeStr, Default: None, Named: true},
+				},
+			},
+		},
+	}
+}
+
+// assertMessage takes the optional named message of an assertion.
+func assertMessage(args *Args) (*string, error) {
+	arg := args.Named("message")
+	if arg == nil || IsNone(arg.V) {
+		return nil, nil
+	}
+	s, ok := arg.V.(Str)
+	if !ok {
+		return nil, &TypeMismatchError{Expected: "string or none", Got: arg.V.Type().String(), Span: arg.Span}
+	}
+	message := string(s)
+	return &message, nil
+}
+
+// PanicFunc creates the panic function, which fails with an error showing
+// the representations of the given values.
+//
+// Matches Rust: pub fn panic in foundations/mod.rs
+func PanicFunc() *Func {
+	name := "panic"
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: panicNative,
+			Info: &FuncInfo{
+				Name:   "panic",
+				Params: []ParamInfo{{Name: "values", Type: TypeDyn, Variadic: true}},
+			},
+		},
+	}
+}
+
+func panicNative(engine Engine, context Context, args *Args) (Value, error) {
+	var values []string
+	for arg := args.Eat(); arg != nil; arg = args.Eat() {
+		values = append(values, Repr(arg.V))
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	message := "panicked"
+	if len(values) > 0 {
+		message += " with: " + strings.Join(values, ", ")
+	}
+	return nil, &PanicError{Message: message, Span: args.Span}
+}
@@ -0,0 +1,95 @@
+package foundations
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestAssert(t *testing.T) {
+	span := syntax.SpanFromRaw(1 << 48)
+	call := func(fn *Func, message Value, values ...Value) error {
+		args := NewArgs(span, values...)
+		if message != nil {
+			name := Str("message")
+			args.Items = append(args.Items, Arg{Span: span, Name: &name, Value: syntax.NewSpanned(message, span)})
+		}
+		_, err := fn.Call(&Engine{}, NewContext(), args)
+		return err
+	}
+	eq, ok := AssertFunc().Scope().Get("eq").Value().(FuncValue)
+	if !ok {
+		t.Fatal("assert.eq is not a function")
+	}
+	ne, ok := AssertFunc().Scope().Get("ne").Value().(FuncValue)
+	if !ok {
+		t.Fatal("assert.ne is not a function")
+	}
+
+	tests := []struct {
+		name    string
+		fn      *Func
+		message Value
+		values  []Value
+		want    string
+	}{
+		{"holds", AssertFunc(), nil, []Value{True}, ""},
+		{"fails", AssertFunc(), nil, []Value{False}, "assertion failed"},
+		{"fails with message", AssertFunc(), Str("oops"), []Value{False}, "assertion failed: oops"},
+		{"eq holds", eq.Func, nil, []Value{Int(1), Float(1)}, ""},
+		{"eq fails", eq.Func, nil, []Value{Int(1), Str("1")}, `equality assertion failed: value 1 was not equal to "1"`},
+		{"eq fails with message", eq.Func, Str("bad"), []Value{Int(1), Int(2)}, "equality assertion failed: bad"},
+		{"ne holds", ne.Func, nil, []Value{Int(1), Int(2)}, ""},
+		{"ne fails", ne.Func, nil, []Value{NewArray(Int(1)), NewArray(Int(1))}, "inequality assertion failed: value (1,) was equal to (1,)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := call(tt.fn, tt.message, tt.values...)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var assertErr *AssertionError
+			if !errors.As(err, &assertErr) {
+				t.Fatalf("got %v, want assertion error", err)
+			}
+			if assertErr.Message != tt.want {
+				t.Errorf("message = %q, want %q", assertErr.Message, tt.want)
+			}
+			if assertErr.Span != span {
+				t.Errorf("span = %v, want call span %v", assertErr.Span, span)
+			}
+		})
+	}
+
+	if err := call(AssertFunc(), nil, Int(1)); err == nil {
+		t.Error("expected error for non-boolean condition")
+	}
+}
+
+func TestPanic(t *testing.T) {
+	span := syntax.SpanFromRaw(1 << 48)
+	tests := []struct {
+		values []Value
+		want   string
+	}{
+		{nil, "panicked"},
+		{[]Value{Str("this is wrong")}, `panicked with: "this is wrong"`},
+		{[]Value{Int(1), NewArray()}, "panicked with: 1, ()"},
+	}
+
+	for _, tt := range tests {
+		_, err := PanicFunc().Call(&Engine{}, NewContext(), NewArgs(span, tt.values...))
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("got %v, want panic error", err)
+		}
+		if panicErr.Message != tt.want || panicErr.Span != span {
+			t.Errorf("got %q at %v, want %q at %v", panicErr.Message, panicErr.Span, tt.want, span)
+		}
+	}
+}
@@ -40,13 +40,17 @@ func (e *ConstructorError) Error() string {
 // Returns the converted value and any error.
 //
 // For optional fields (pointer types), returns nil if the value is None.
-// For required fields, None is an error.
+// Dynamic fields (of type Value) keep None. For other fields, None is an
+// error.
 func ConvertValue(v Value, targetType Type, goType reflect.Type) (any, error) {
 	// Handle none - returns nil for pointer types
 	if IsNone(v) {
 		if goType.Kind() == reflect.Ptr {
 			return reflect.Zero(goType).Interface(), nil
 		}
+		if goType.Kind() == reflect.Interface {
+			return v, nil
+		}
 		return nil, &TypeMismatchError{Expected: targetType.String(), Got: "none"}
 	}
 
@@ -379,6 +383,12 @@ func ParseTypstType(name string) Type {
 		return TypeStyles
 	case "version":
 		return TypeVersion
+	case "location":
+		return TypeLocation
+	case "selector":
+		return TypeSelector
+	case "counter":
+		return TypeCounter
 	default:
 		return TypeDyn // Unknown type - treat as dynamic
 	}
@@ -714,3 +714,172 @@ func hueToRgb(p, q, t float64) float64 {
 	}
 	return p
 }
+
+// ----------------------------------------------------------------------------
+// Color Methods
+// ----------------------------------------------------------------------------
+
+// ColorWithAlpha returns the color with a different alpha. CMYK colors
+// have no alpha and stay unchanged.
+func ColorWithAlpha(c Color, alpha float64) Color {
+	alpha = clamp01(alpha)
+	switch c := c.(type) {
+	case Luma:
+		c.A = alpha
+		return c
+	case Rgba:
+		c.A = alpha
+		return c
+	case LinearRgba:
+		c.A = alpha
+		return c
+	case Oklab:
+		c.Alpha_ = alpha
+		return c
+	case Oklch:
+		c.Alpha_ = alpha
+		return c
+	case Hsl:
+		c.A = alpha
+		return c
+	case Hsv:
+		c.A = alpha
+		return c
+	}
+	return c
+}
+
+// mapColor applies f to the lightness components of a color: the
+// lightness of luma, Oklab, and Oklch colors and the channels of RGB
+// colors. Other colors are mapped in RGB and converted back.
+func mapColor(c Color, f func(float64) float64) Color {
+	switch c := c.(type) {
+	case Luma:
+		c.L = clamp01(f(c.L))
+		return c
+	case Rgba:
+		c.R, c.G, c.B = clamp01(f(c.R)), clamp01(f(c.G)), clamp01(f(c.B))
+		return c
+	case LinearRgba:
+		c.R, c.G, c.B = clamp01(f(c.R)), clamp01(f(c.G)), clamp01(f(c.B))
+		return c
+	case Oklab:
+		c.L = clamp01(f(c.L))
+		return c
+	case Oklch:
+		c.L = clamp01(f(c.L))
+		return c
+	}
+	mapped, _ := ConvertColor(mapColor(c.ToRgba(), f), c.Space())
+	return ColorWithAlpha(mapped, c.ColorAlpha())
+}
+
+// ColorLighten lightens a color by a factor.
+// Matches Rust: Color::lighten
+func ColorLighten(c Color, factor float64) Color {
+	return mapColor(c, func(x float64) float64 { return x + (1-x)*factor })
+}
+
+// ColorDarken darkens a color by a factor.
+// Matches Rust: Color::darken
+func ColorDarken(c Color, factor float64) Color {
+	return mapColor(c, func(x float64) float64 { return x * (1 - factor) })
+}
+
+// ColorNegate returns the negative of a color, computed in RGB.
+// Matches Rust: Color::negate
+func ColorNegate(c Color) Color {
+	rgba := c.ToRgba()
+	negated := Rgba{R: 1 - rgba.R, G: 1 - rgba.G, B: 1 - rgba.B, A: rgba.A}
+	result, _ := ConvertColor(negated, c.Space())
+	return ColorWithAlpha(result, c.ColorAlpha())
+}
+
+// ColorComponents returns the components of a color in its space, as
+// ratios, floats, and angles, followed by the alpha if alpha is set.
+// Matches Rust: Color::components
+func ColorComponents(c Color, alpha bool) *Array {
+	ratio := func(v float64) Value { return RatioValue{Ratio: Ratio{Value: v}} }
+	deg := func(v float64) Value { return AngleValue{Angle: Angle{Radians: v * math.Pi / 180}} }
+	var items []Value
+	switch c := c.(type) {
+	case Luma:
+		items = []Value{ratio(c.L)}
+	case Rgba:
+		items = []Value{ratio(c.R), ratio(c.G), ratio(c.B)}
+	case LinearRgba:
+		items = []Value{ratio(c.R), ratio(c.G), ratio(c.B)}
+	case Oklab:
+		items = []Value{ratio(c.L), Float(c.Ab), Float(c.Bb)}
+	case Oklch:
+		items = []Value{ratio(c.L), Float(c.C), deg(c.H)}
+	case Hsl:
+		items = []Value{deg(c.H), ratio(c.S), ratio(c.L)}
+	case Hsv:
+		items = []Value{deg(c.H), ratio(c.S), ratio(c.V)}
+	case Cmyk:
+		return NewArray(ratio(c.C), ratio(c.M), ratio(c.Y), ratio(c.K))
+	}
+	if alpha {
+		items = append(items, ratio(c.ColorAlpha()))
+	}
+	return NewArray(items...)
+}
+
+func init() {
+	m := newMethods(TypeColor)
+	color := func(f func(c Color, args *Args) (Value, error)) methodFunc {
+		return func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+			return f(self.(Color), args)
+		}
+	}
+	expectRatio := func(args *Args, what string) (float64, error) {
+		return expectArg(args, what, "ratio", asRatio)
+	}
+
+	m.define("to-hex", nil, color(func(c Color, args *Args) (Value, error) {
+		return Str(c.ToRgba().ToHex()), nil
+	}))
+	m.define("lighten", []ParamInfo{{Name: "factor", Type: TypeRatio}}, color(func(c Color, args *Args) (Value, error) {
+		factor, err := expectRatio(args, "factor")
+		if err != nil {
+			return nil, err
+		}
+		return ColorLighten(c, factor), nil
+	}))
+	m.define("darken", []ParamInfo{{Name: "factor", Type: TypeRatio}}, color(func(c Color, args *Args) (Value, error) {
+		factor, err := expectRatio(args, "factor")
+		if err != nil {
+			return nil, err
+		}
+		return ColorDarken(c, factor), nil
+	}))
+	m.define("negate", nil, color(func(c Color, args *Args) (Value, error) {
+		return ColorNegate(c), nil
+	}))
+	m.define("transparentize", []ParamInfo{{Name: "scale", Type: TypeRatio}}, color(func(c Color, args *Args) (Value, error) {
+		scale, err := expectRatio(args, "scale")
+		if err != nil {
+			return nil, err
+		}
+		alpha := c.ColorAlpha()
+		return ColorWithAlpha(c, alpha-alpha*scale), nil
+	}))
+	m.define("opacify", []ParamInfo{{Name: "scale", Type: TypeRatio}}, color(func(c Color, args *Args) (Value, error) {
+		scale, err := expectRatio(args, "scale")
+		if err != nil {
+			return nil, err
+		}
+		alpha := c.ColorAlpha()
+		return ColorWithAlpha(c, alpha+(1-alpha)*scale), nil
+	}))
+	m.define("components", []ParamInfo{{Name: "alpha", Type: TypeBool, Named: true, Default: Bool(true)}}, color(func(c Color, args *Args) (Value, error) {
+		alpha, hasAlpha, err := eatNamed(args, "alpha", "boolean", AsBool)
+		if err != nil {
+			return nil, err
+		}
+		return ColorComponents(c, alpha || !hasAlpha), nil
+	}))
+
+	m.register()
+}
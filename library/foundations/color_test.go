@@ -3,6 +3,8 @@ package foundations
 import (
 	"math"
 	"testing"
+
+	"github.com/boergens/gotypst/syntax"
 )
 
 const epsilon = 0.001
@@ -494,3 +496,38 @@ func TestToBytesRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestColorMethods(t *testing.T) {
+	ratio := func(v float64) Value { return RatioValue{Ratio: Ratio{Value: v}} }
+	gray := NewLuma(0.5, 1)
+
+	got, err := callMethod(t, TypeColor, "lighten", gray, NewArgs(syntax.Detached(), ratio(0.5)))
+	if err != nil || !rgbaApprox(got.(Color).ToRgba(), NewLuma(0.75, 1).ToRgba(), epsilon) {
+		t.Errorf("lighten = %v, %v", got, err)
+	}
+	got, err = callMethod(t, TypeColor, "darken", NewRgba(1, 0.5, 0, 1), NewArgs(syntax.Detached(), ratio(0.5)))
+	if err != nil || !rgbaApprox(got.(Color).ToRgba(), NewRgba(0.5, 0.25, 0, 1).ToRgba(), epsilon) {
+		t.Errorf("darken = %v, %v", got, err)
+	}
+	got, err = callMethod(t, TypeColor, "negate", NewRgba(1, 0, 0, 1), NewArgs(syntax.Detached()))
+	if err != nil || !rgbaApprox(got.(Color).ToRgba(), NewRgba(0, 1, 1, 1).ToRgba(), epsilon) {
+		t.Errorf("negate = %v, %v", got, err)
+	}
+	got, err = callMethod(t, TypeColor, "transparentize", gray, NewArgs(syntax.Detached(), ratio(0.25)))
+	if err != nil || !floatApprox(got.(Color).ColorAlpha(), 0.75, epsilon) {
+		t.Errorf("transparentize = %v, %v", got, err)
+	}
+	got, err = callMethod(t, TypeColor, "to-hex", NewRgba(1, 0, 0, 1), NewArgs(syntax.Detached()))
+	if err != nil || got != Str("#ff0000") {
+		t.Errorf("to-hex = %v, %v", got, err)
+	}
+
+	args := &Args{Items: []Arg{namedArg("alpha", Bool(false))}}
+	got, err = callMethod(t, TypeColor, "components", NewRgba(1, 0, 0, 1), args)
+	if err != nil || !Equal(got, NewArray(ratio(1), ratio(0), ratio(0))) {
+		t.Errorf("components = %v, %v", got, err)
+	}
+	if _, err := callMethod(t, TypeColor, "lighten", gray, NewArgs(syntax.Detached(), Int(1))); err == nil {
+		t.Error("lighten with an integer: expected an error")
+	}
+}
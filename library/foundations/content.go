@@ -3,6 +3,13 @@
 
 package foundations
 
+import (
+	"reflect"
+	"strings"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
 // Content represents typeset content.
 type Content struct {
 	// Elements contains the content elements.
@@ -15,6 +22,104 @@ type ContentElement interface {
 	IsContentElement()
 }
 
+// PlainText returns the text of the content without any formatting, as
+// used for document metadata like the title.
+// Matches Rust: Content::plain_text
+func (c Content) PlainText() string {
+	var b strings.Builder
+	c.writePlainText(&b)
+	return b.String()
+}
+
+// ToText flattens the content to a single line of plain text, as used for
+// outline entries, PDF bookmarks, and alternative descriptions. Runs of
+// spaces, line breaks, and paragraph breaks become a single space, leading
+// and trailing ones are dropped, and smart quotes are straight quotes.
+func (c Content) ToText() string {
+	var b strings.Builder
+	pending := false
+	for _, r := range c.PlainText() {
+		if isTextBreak(r) {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteByte(' ')
+			pending = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isTextBreak reports whether a character separates words in the plain
+// text of content. Non-breaking spaces deliberately do not count.
+func isTextBreak(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+func (c Content) writePlainText(b *strings.Builder) {
+	for _, elem := range c.Elements {
+		if p, ok := elem.(PlainTextElement); ok {
+			p.PlainText(b)
+		}
+	}
+}
+
+// PlainTextElement is implemented by elements that have a plain text
+// representation. Elements without one contribute nothing to the plain
+// text of the content they are in.
+// Matches Rust: trait PlainText
+type PlainTextElement interface {
+	ContentElement
+	// PlainText writes the element's plain text to b.
+	PlainText(b *strings.Builder)
+}
+
+// LocatedElement is implemented by elements that know their location in
+// the document once they have been realized.
+type LocatedElement interface {
+	ContentElement
+	// ElementLocation returns the element's location, or nil if it has
+	// not been located.
+	ElementLocation() *Location
+}
+
+// ElemMeta holds what an element carries besides its own fields. Elements
+// that can be labelled embed it; the others, like spaces and paragraph
+// breaks, cannot be.
+// Matches Rust: the header of Content's Inner
+type ElemMeta struct {
+	// Label is the label attached to the element, if any.
+	Label *string
+	// Span is where the element was created, to which errors in its
+	// layout are attributed.
+	Span syntax.Span
+}
+
+// elemMeta returns the metadata of the element that embeds it.
+func (m *ElemMeta) elemMeta() *ElemMeta { return m }
+
+// metaElement is implemented by the elements that embed ElemMeta.
+type metaElement interface {
+	ContentElement
+	elemMeta() *ElemMeta
+}
+
+// copyElement returns a shallow copy of an element that embeds ElemMeta,
+// so that its label or span can be changed without affecting the
+// original.
+func copyElement(elem metaElement) metaElement {
+	v := reflect.ValueOf(elem).Elem()
+	copied := reflect.New(v.Type())
+	copied.Elem().Set(v)
+	return copied.Interface().(metaElement)
+}
+
 // ContentValue represents content as a Value.
 type ContentValue struct {
 	Content Content
@@ -32,10 +137,14 @@ type StyledElem struct {
 	Child Content
 	// Styles are the styles to apply.
 	Styles *Styles
+	ElemMeta
 }
 
 func (*StyledElem) IsContentElement() {}
 
+// PlainText writes the plain text of the styled content.
+func (e *StyledElem) PlainText(b *strings.Builder) { e.Child.writePlainText(b) }
+
 // StyledWithMap wraps content with a style map.
 // Corresponds to Rust's Content::styled_with_map.
 func StyledWithMap(content Content, styles *Styles) Content {
@@ -50,20 +159,174 @@ func StyledWithMap(content Content, styles *Styles) Content {
 	}
 }
 
+// StyledWithMap applies styles to the content. Set rules style the content
+// that follows them in the same block this way, so their styles do not leak
+// out of the block.
+func (c Content) StyledWithMap(styles *Styles) Content {
+	return StyledWithMap(c, styles)
+}
+
+// StyledWithRecipe applies a show rule to the content. A show rule without
+// a selector transforms the content right away, all others are applied
+// during realization.
+// Matches Rust: Content::styled_with_recipe
+func (c Content) StyledWithRecipe(engine *Engine, context *Context, recipe *Recipe) (Content, error) {
+	if recipe.Selector == nil {
+		return recipe.Apply(engine, context, c)
+	}
+	styles := NewStyles()
+	styles.AddRecipe(recipe)
+	return StyledWithMap(c, styles), nil
+}
+
 // SequenceElem is a sequence of content elements.
 // Corresponds to Rust's SequenceElem in foundations/content/mod.rs.
 type SequenceElem struct {
 	// Children are the content elements in sequence.
 	Children []Content
+	ElemMeta
 }
 
 func (*SequenceElem) IsContentElement() {}
 
+// PlainText writes the plain text of the children in order.
+func (e *SequenceElem) PlainText(b *strings.Builder) {
+	for _, child := range e.Children {
+		child.writePlainText(b)
+	}
+}
+
 // SymbolElem represents a symbol in math mode.
 // Corresponds to Rust's SymbolElem in typst-library/src/text/symbol.rs.
 type SymbolElem struct {
 	// Text is the symbol text/character.
 	Text string
+	ElemMeta
 }
 
 func (*SymbolElem) IsContentElement() {}
+
+// PlainText writes the symbol's text.
+func (e *SymbolElem) PlainText(b *strings.Builder) { b.WriteString(e.Text) }
+
+// SpaceElem is a space between words.
+// Corresponds to Rust's SpaceElem in text/space.rs.
+type SpaceElem struct{}
+
+func (*SpaceElem) IsContentElement() {}
+
+// PlainText writes a space.
+func (*SpaceElem) PlainText(b *strings.Builder) { b.WriteByte(' ') }
+
+// Repr returns the space as markup.
+func (*SpaceElem) Repr() string { return "[ ]" }
+
+// SpaceElemShared returns content holding a single space. All spaces share
+// the same elements, so that they cost no allocation.
+// Matches Rust: SpaceElem::shared
+func SpaceElemShared() Content {
+	return Content{Elements: sharedSpace}
+}
+
+// ParbreakElem is a paragraph break.
+// Corresponds to Rust's ParbreakElem in model/par.rs.
+type ParbreakElem struct{}
+
+func (*ParbreakElem) IsContentElement() {}
+
+// PlainText writes a blank line.
+func (*ParbreakElem) PlainText(b *strings.Builder) { b.WriteString("\n\n") }
+
+// ParbreakElemShared returns content holding a single paragraph break. All
+// paragraph breaks share the same elements.
+// Matches Rust: ParbreakElem::shared
+func ParbreakElemShared() Content {
+	return Content{Elements: sharedParbreak}
+}
+
+// SmartQuoteElem is a quote that is replaced by the opening or closing
+// quote of the text language during layout.
+// Corresponds to Rust's SmartQuoteElem in text/smartquote.rs.
+type SmartQuoteElem struct {
+	// Double is whether this is a double quote.
+	Double bool
+}
+
+func (*SmartQuoteElem) IsContentElement() {}
+
+// PlainText writes the straight quote. Which curly quote it becomes
+// depends on its surroundings and the language, which are only known in
+// layout.
+func (e *SmartQuoteElem) PlainText(b *strings.Builder) {
+	if e.Double {
+		b.WriteByte('"')
+	} else {
+		b.WriteByte('\'')
+	}
+}
+
+// SmartQuoteElemPacked returns content holding a single smart quote.
+func SmartQuoteElemPacked(double bool) Content {
+	if double {
+		return Content{Elements: sharedDoubleQuote}
+	}
+	return Content{Elements: sharedSingleQuote}
+}
+
+// The elements of the shared content. Their capacity is their length, so
+// appending to them copies them. Like all content, they must not be
+// modified in place.
+var (
+	sharedSpace       = []ContentElement{&SpaceElem{}}
+	sharedParbreak    = []ContentElement{&ParbreakElem{}}
+	sharedSingleQuote = []ContentElement{&SmartQuoteElem{}}
+	sharedDoubleQuote = []ContentElement{&SmartQuoteElem{Double: true}}
+)
+
+func init() {
+	m := newMethods(TypeContent)
+	content := func(f func(c Content, args *Args) (Value, error)) methodFunc {
+		return func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+			return f(self.(ContentValue).Content, args)
+		}
+	}
+
+	m.define("func", nil, content(func(c Content, args *Args) (Value, error) {
+		return FuncValue{Func: ElementFuncOf(ContentElem(c))}, nil
+	}))
+	m.define("fields", nil, content(func(c Content, args *Args) (Value, error) {
+		return ElementFields(ContentElem(c)), nil
+	}))
+	m.define("has", []ParamInfo{{Name: "field", Type: TypeStr}}, content(func(c Content, args *Args) (Value, error) {
+		field, err := expectArg(args, "field", "string", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		return Bool(ElementFields(ContentElem(c)).Contains(field)), nil
+	}))
+	m.define("at", []ParamInfo{
+		{Name: "field", Type: TypeStr},
+		{Name: "default", Type: TypeDyn, Named: true},
+	}, content(func(c Content, args *Args) (Value, error) {
+		field, err := expectArg(args, "field", "string", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		def := args.Named("default")
+		value, err := ContentField(c, field)
+		if err != nil && def != nil {
+			return def.V, nil
+		}
+		return value, err
+	}))
+	m.define("location", nil, content(func(c Content, args *Args) (Value, error) {
+		if located, ok := ContentElem(c).(LocatedElement); ok {
+			if location := located.ElementLocation(); location != nil {
+				return LocationValue{Location: location}, nil
+			}
+		}
+		return None, nil
+	}))
+
+	m.register()
+}
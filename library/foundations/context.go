@@ -37,7 +37,7 @@ func NewContextWith(location *Location, styles *StyleChain) *Context {
 // GetLocation returns the location, or an error if not available.
 func (c *Context) GetLocation() (*Location, error) {
 	if c == nil || c.Location == nil {
-		return nil, &ContextError{Message: "can only be used when context is known"}
+		return nil, newContextError()
 	}
 	return c.Location, nil
 }
@@ -45,16 +45,118 @@ func (c *Context) GetLocation() (*Location, error) {
 // GetStyles returns the styles, or an error if not available.
 func (c *Context) GetStyles() (*StyleChain, error) {
 	if c == nil || c.Styles == nil {
-		return nil, &ContextError{Message: "can only be used when context is known"}
+		return nil, newContextError()
 	}
 	return c.Styles, nil
 }
 
+// StyleField returns the value of an element's field in the active styles,
+// like `text.size` inside of a `context` expression. If no set rule
+// configured the field, its default is returned. The boolean result is false
+// if the element has no such settable field.
+//
+// Matches Rust: the get rule access in typst-eval/src/code.rs
+func (c *Context) StyleField(elem, field string) (Value, bool, error) {
+	def, ok := styleFieldDefault(elem, field)
+	if !ok {
+		return nil, false, nil
+	}
+	styles, err := c.GetStyles()
+	if err != nil {
+		return nil, true, err
+	}
+	if value := styles.Get(elem, field); value != nil {
+		return value, true, nil
+	}
+	return def, true, nil
+}
+
+// styleFieldDefaults holds the defaults of settable fields of elements
+// whose fields are not declared through RegisterElement.
+var styleFieldDefaults = map[string]map[string]Value{
+	"text": {
+		"size":   LengthValue{Length: Length{Points: 11}},
+		"lang":   Str("en"),
+		"region": None,
+		"dir":    Auto,
+		"font":   Str("libertinus serif"),
+		"weight": Int(400),
+		"style":  Str("normal"),
+		"fill":   Luma{L: 0, A: 1},
+
+		"tracking": LengthValue{},
+		"spacing":  RatioValue{Ratio: Ratio{Value: 1}},
+		"baseline": LengthValue{},
+		"overhang": Bool(true),
+	},
+}
+
+// styleFieldDefault returns the default of an element's settable field.
+func styleFieldDefault(elem, field string) (Value, bool) {
+	if fields, ok := styleFieldDefaults[elem]; ok {
+		value, ok := fields[field]
+		return value, ok
+	}
+	if def := GetElement(elem); def != nil {
+		if f := def.FieldByName(field); f != nil && !f.Positional {
+			if f.Default == nil {
+				return None, true
+			}
+			return f.Default, true
+		}
+	}
+	return nil, false
+}
+
 // ContextError is returned when context is not available.
 type ContextError struct {
 	Message string
+	Hints   []string
+}
+
+// newContextError creates the error for contextual operations that are
+// used outside of a context expression.
+func newContextError() *ContextError {
+	return &ContextError{
+		Message: "can only be used when context is known",
+		Hints: []string{
+			"try wrapping this in a `context` expression",
+			"the `context` expression should wrap everything that depends on this function",
+		},
+	}
 }
 
 func (e *ContextError) Error() string {
 	return e.Message
 }
+
+// ContextElem is the content produced by a `context` expression. Its
+// function is only called once the styles and location at the place where
+// the content ends up are known.
+//
+// Matches Rust's ContextElem in context.rs.
+type ContextElem struct {
+	// Func evaluates the body of the context expression.
+	Func *Func
+	ElemMeta
+}
+
+func (*ContextElem) IsContentElement() {}
+
+// NewContextElem creates a context element for the given function.
+func NewContextElem(fn *Func) *ContextElem {
+	return &ContextElem{Func: fn}
+}
+
+// Pack wraps the element in content.
+func (e *ContextElem) Pack() Content {
+	return Content{Elements: []ContentElement{e}}
+}
+
+// Show calls the element's function with the given styles and location.
+// The caller displays the returned value.
+//
+// Matches Rust: impl Show for Packed<ContextElem>
+func (e *ContextElem) Show(engine *Engine, styles *StyleChain, location *Location) (Value, error) {
+	return e.Func.Call(engine, NewContextWith(location, styles), NewArgs(e.Func.Span))
+}
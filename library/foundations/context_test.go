@@ -0,0 +1,69 @@
+package foundations
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestContextStyleField(t *testing.T) {
+	name := "text"
+	size := Str("size")
+	args := &Args{Items: []Arg{{
+		Name:  &size,
+		Value: syntax.Spanned[Value]{V: LengthValue{Length: Length{Points: 14}}},
+	}}}
+	styles := EmptyStyleChain().Chain(&Styles{Rules: []StyleRule{{
+		Func: &Func{Name: &name},
+		Args: args,
+	}}})
+	context := NewContextWith(nil, styles)
+
+	value, found, err := context.StyleField("text", "size")
+	if !found || err != nil {
+		t.Fatalf("StyleField(text, size) = %v, %v", found, err)
+	}
+	if value != (LengthValue{Length: Length{Points: 14}}) {
+		t.Errorf("text.size = %#v, want 14pt", value)
+	}
+
+	value, found, err = context.StyleField("text", "lang")
+	if !found || err != nil || value != Str("en") {
+		t.Errorf("text.lang = %#v, %v, %v, want the default", value, found, err)
+	}
+
+	if _, found, _ := context.StyleField("text", "nonexistent"); found {
+		t.Error("unknown field should not be found")
+	}
+
+	_, found, err = NewContext().StyleField("text", "size")
+	var contextErr *ContextError
+	if !found || !errors.As(err, &contextErr) {
+		t.Errorf("StyleField without styles = %v, %v, want a context error", found, err)
+	}
+	if len(contextErr.Hints) == 0 {
+		t.Error("context error should hint at context expressions")
+	}
+}
+
+func TestContextElemShow(t *testing.T) {
+	var got *Context
+	fn := &Func{Repr: NativeFunc{Func: func(engine Engine, context Context, args *Args) (Value, error) {
+		got = &context
+		return Str("shown"), nil
+	}}}
+
+	styles := EmptyStyleChain()
+	location := &Location{Hash: 7}
+	value, err := NewContextElem(fn).Show(&Engine{}, styles, location)
+	if err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if value != Str("shown") {
+		t.Errorf("Show() = %#v, want the function's result", value)
+	}
+	if got == nil || got.Styles != styles || got.Location != location {
+		t.Errorf("function called with context %#v, want the given styles and location", got)
+	}
+}
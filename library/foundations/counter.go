@@ -0,0 +1,60 @@
+// Counter values for Typst.
+// Translated from typst-library/src/introspection/counter.rs
+//
+// The counter state and its computation are in the introspection package.
+
+package foundations
+
+// CounterKey identifies a counter.
+// Corresponds to Rust's CounterKey enum.
+type CounterKey interface {
+	isCounterKey()
+}
+
+// PageCounterKey identifies the page counter.
+// Corresponds to Rust's CounterKey::Page variant.
+type PageCounterKey struct{}
+
+func (PageCounterKey) isCounterKey() {}
+
+// SelectorCounterKey identifies the counter of the elements matching a
+// selector, like the heading counter.
+// Corresponds to Rust's CounterKey::Selector variant.
+type SelectorCounterKey struct {
+	Selector Selector
+}
+
+func (SelectorCounterKey) isCounterKey() {}
+
+// StrCounterKey identifies a counter that is only changed by explicit
+// updates.
+// Corresponds to Rust's CounterKey::Str variant.
+type StrCounterKey struct {
+	Name string
+}
+
+func (StrCounterKey) isCounterKey() {}
+
+// CounterValue is a counter as a value, as created by `counter(heading)`.
+type CounterValue struct {
+	Key CounterKey
+}
+
+func (CounterValue) Type() Type         { return TypeCounter }
+func (v CounterValue) Display() Content { return Content{} }
+func (v CounterValue) Clone() Value     { return v }
+func (CounterValue) isValue()           {}
+
+// reprCounterKey returns the code for the argument of `counter(..)` that
+// produces the key.
+func reprCounterKey(key CounterKey) string {
+	switch key := key.(type) {
+	case PageCounterKey:
+		return "page"
+	case SelectorCounterKey:
+		return reprSelector(key.Selector)
+	case StrCounterKey:
+		return reprStr(key.Name)
+	}
+	return ".."
+}
@@ -0,0 +1,191 @@
+// Diagnostics for Typst.
+// Translated from typst-library/src/diag.rs
+
+package foundations
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// NewSourceError creates an error diagnostic at a span.
+// Matches Rust: SourceDiagnostic::error
+func NewSourceError(span syntax.Span, message string) SourceDiagnostic {
+	return SourceDiagnostic{Span: span, Severity: SeverityError, Message: message}
+}
+
+// NewSourceWarning creates a warning diagnostic at a span.
+// Matches Rust: SourceDiagnostic::warning
+func NewSourceWarning(span syntax.Span, message string) SourceDiagnostic {
+	return SourceDiagnostic{Span: span, Severity: SeverityWarning, Message: message}
+}
+
+// WithHint returns the diagnostic with an additional hint.
+// Matches Rust: SourceDiagnostic::with_hint
+func (d SourceDiagnostic) WithHint(hint string) SourceDiagnostic {
+	d.Hints = append(d.Hints[:len(d.Hints):len(d.Hints)], hint)
+	return d
+}
+
+// Error returns the message of the diagnostic, so that a diagnostic can be
+// returned as an error.
+func (d SourceDiagnostic) Error() string {
+	return d.Message
+}
+
+// String returns the name of the severity.
+func (s DiagnosticSeverity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// SourceErrors is a list of errors that are reported together, like all
+// syntax errors of a file.
+// Matches Rust: type SourceResult<T> = Result<T, EcoVec<SourceDiagnostic>>
+type SourceErrors []SourceDiagnostic
+
+func (e SourceErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, d := range e {
+		msgs[i] = d.Message
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// SyntaxErrors converts the errors of a syntax tree into diagnostics.
+func SyntaxErrors(errs []*syntax.SyntaxError) SourceErrors {
+	diags := make(SourceErrors, len(errs))
+	for i, err := range errs {
+		diags[i] = SourceDiagnostic{
+			Span:     err.Span,
+			Severity: SeverityError,
+			Message:  err.Message,
+			Hints:    err.Hints,
+		}
+	}
+	return diags
+}
+
+// LayoutError is an error that occurred while laying out an element. It
+// points at the element, unless the error it wraps points somewhere more
+// specific.
+// Equivalent to Rust's .at(elem.span()) in layout code
+type LayoutError struct {
+	Err  error
+	Span syntax.Span
+}
+
+func (e *LayoutError) Error() string { return e.Err.Error() }
+func (e *LayoutError) Unwrap() error { return e.Err }
+
+// AtElement attributes an error to the element it occurred in.
+func AtElement(err error, elem ContentElement) error {
+	if err == nil {
+		return nil
+	}
+	return &LayoutError{Err: err, Span: ElementSpan(elem)}
+}
+
+// Tracer is implemented by errors that record a point through which an
+// error propagated, like a function call.
+type Tracer interface {
+	Tracepoint() syntax.Spanned[string]
+}
+
+// ErrorDiagnostics converts an error into diagnostics.
+//
+// Errors from parsing, evaluation, and layout come in many types. The
+// message is taken from the outermost error, while the error chain is
+// searched for the innermost span, for hints, and for trace points. Errors
+// carry their span and hints either in `Span` and `Hints` fields or through
+// a `Span()` method.
+func ErrorDiagnostics(err error) []SourceDiagnostic {
+	if err == nil {
+		return nil
+	}
+	var list SourceErrors
+	if errors.As(err, &list) {
+		return append([]SourceDiagnostic(nil), list...)
+	}
+
+	diag := NewSourceError(syntax.Detached(), err.Error())
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if d, ok := e.(SourceDiagnostic); ok {
+			diag.Severity = d.Severity
+			if !d.Span.IsDetached() {
+				diag.Span = d.Span
+			}
+			diag.Hints = append(diag.Hints, d.Hints...)
+			diag.Trace = append(append([]syntax.Spanned[string](nil), d.Trace...), reverseTrace(diag.Trace)...)
+			return []SourceDiagnostic{diag}
+		}
+		if t, ok := e.(Tracer); ok {
+			diag.Trace = append(diag.Trace, t.Tracepoint())
+			continue
+		}
+		if span, ok := errorSpan(e); ok && !span.IsDetached() {
+			diag.Span = span
+		}
+		diag.Hints = append(diag.Hints, errorHints(e)...)
+	}
+	diag.Trace = reverseTrace(diag.Trace)
+	return []SourceDiagnostic{diag}
+}
+
+var (
+	spanType  = reflect.TypeOf(syntax.Span{})
+	hintsType = reflect.TypeOf([]string(nil))
+)
+
+// errorSpan returns the span an error points at, if it has one.
+func errorSpan(err error) (syntax.Span, bool) {
+	if s, ok := err.(interface{ Span() syntax.Span }); ok {
+		return s.Span(), true
+	}
+	if f, ok := errorField(err, "Span", spanType); ok {
+		return f.Interface().(syntax.Span), true
+	}
+	return syntax.Span{}, false
+}
+
+// errorHints returns the hints attached to an error.
+func errorHints(err error) []string {
+	if f, ok := errorField(err, "Hints", hintsType); ok {
+		return f.Interface().([]string)
+	}
+	return nil
+}
+
+// errorField looks up a field of the given type in an error struct.
+func errorField(err error, name string, typ reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() || f.Type() != typ {
+		return reflect.Value{}, false
+	}
+	return f, true
+}
+
+// reverseTrace returns the trace points in reverse order. The error chain is
+// walked from the outside in, but traces are listed innermost first.
+func reverseTrace(trace []syntax.Spanned[string]) []syntax.Spanned[string] {
+	reversed := make([]syntax.Spanned[string], len(trace))
+	for i, point := range trace {
+		reversed[len(trace)-1-i] = point
+	}
+	return reversed
+}
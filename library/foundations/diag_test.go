@@ -0,0 +1,70 @@
+package foundations
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// tracedError records a trace point for testing.
+type tracedError struct {
+	err  error
+	span syntax.Span
+}
+
+func (e *tracedError) Error() string { return e.err.Error() }
+func (e *tracedError) Unwrap() error { return e.err }
+func (e *tracedError) Tracepoint() syntax.Spanned[string] {
+	return syntax.NewSpanned("error occurred in this function call", e.span)
+}
+
+func TestErrorDiagnostics(t *testing.T) {
+	inner := syntax.SpanFromRaw(1<<48 | 2)
+	call := syntax.SpanFromRaw(1<<48 | 3)
+
+	err := &tracedError{
+		err:  &ConstructorError{Message: "bad value", Span: inner, Hints: []string{"try another"}},
+		span: call,
+	}
+	diags := ErrorDiagnostics(fmt.Errorf("wrapped: %w", err))
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(diags))
+	}
+	diag := diags[0]
+	if diag.Message != "wrapped: bad value" || diag.Span != inner || diag.Severity != SeverityError {
+		t.Errorf("got %q at %v, want %q at %v", diag.Message, diag.Span, "wrapped: bad value", inner)
+	}
+	if !reflect.DeepEqual(diag.Hints, []string{"try another"}) {
+		t.Errorf("hints = %v", diag.Hints)
+	}
+	if len(diag.Trace) != 1 || diag.Trace[0].Span != call {
+		t.Errorf("trace = %v", diag.Trace)
+	}
+
+	plain := ErrorDiagnostics(fmt.Errorf("no span"))
+	if len(plain) != 1 || !plain[0].Span.IsDetached() || plain[0].Message != "no span" {
+		t.Errorf("plain error = %+v", plain)
+	}
+
+	warning := NewSourceWarning(inner, "careful").WithHint("look here")
+	if got := ErrorDiagnostics(warning); len(got) != 1 || got[0].Severity != SeverityWarning || len(got[0].Hints) != 1 {
+		t.Errorf("diagnostic = %+v", got)
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	errs := SyntaxErrors([]*syntax.SyntaxError{
+		{Span: span, Message: "unclosed delimiter"},
+		{Span: span, Message: "expected expression", Hints: []string{"add one"}},
+	})
+	diags := ErrorDiagnostics(fmt.Errorf("parse: %w", errs))
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(diags))
+	}
+	if diags[1].Message != "expected expression" || diags[1].Hints[0] != "add one" {
+		t.Errorf("second diagnostic = %+v", diags[1])
+	}
+}
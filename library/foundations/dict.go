@@ -233,3 +233,52 @@ func (d *Dict) Iter() ([]string, []Value) {
 	}
 	return d.keys, d.values
 }
+
+func init() {
+	m := newMethods(TypeDict)
+
+	m.define("len", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return Int(self.(*Dict).Len()), nil
+	})
+	m.define("at", []ParamInfo{
+		{Name: "key", Type: TypeStr},
+		{Name: "default", Type: TypeDyn, Named: true},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		key, err := expectArg(args, "key", "string", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		def := args.Named("default")
+		if value, ok := self.(*Dict).Get(key); ok {
+			return value, nil
+		}
+		if def != nil {
+			return def.V, nil
+		}
+		return nil, &OpError{
+			Message: fmt.Sprintf("dictionary does not contain key %q", key),
+			Hint:    "use `default` to specify a default value",
+		}
+	})
+	m.define("keys", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		keys := self.(*Dict).Keys()
+		items := make([]Value, len(keys))
+		for i, key := range keys {
+			items[i] = Str(key)
+		}
+		return NewArray(items...), nil
+	})
+	m.define("values", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return NewArray(append([]Value(nil), self.(*Dict).Values()...)...), nil
+	})
+	m.define("pairs", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		keys, values := self.(*Dict).Iter()
+		items := make([]Value, len(keys))
+		for i, key := range keys {
+			items[i] = NewArray(Str(key), values[i])
+		}
+		return NewArray(items...), nil
+	})
+
+	m.register()
+}
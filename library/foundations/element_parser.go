@@ -18,31 +18,39 @@ import (
 // 3. Processes positional arguments in field order
 // 4. Validates required fields are present
 // 5. Checks for unexpected arguments
+//
+// An element that embeds ElemMeta gets the span of the call.
 func ParseElement[T any](def *ElementDef, args *Args) (*T, error) {
 	elem := new(T)
 	v := reflect.ValueOf(elem).Elem()
+	if err := parseElementInto(def, args, v); err != nil {
+		return nil, err
+	}
+	if meta, ok := any(elem).(metaElement); ok {
+		meta.elemMeta().Span = args.Span
+	}
+	return elem, nil
+}
 
+// parseElementInto parses arguments into the fields of an element struct.
+func parseElementInto(def *ElementDef, args *Args, v reflect.Value) error {
 	// Process shorthands first (in specified order if available)
 	if err := processShorthands(def, args, v); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Process named arguments (these override shorthand values)
 	if err := processNamedArgs(def, args, v); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Process positional arguments
 	if err := processPositionalArgs(def, args, v); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Check for unexpected arguments
-	if err := args.Finish(); err != nil {
-		return nil, err
-	}
-
-	return elem, nil
+	return args.Finish()
 }
 
 // ParseSetRule parses arguments for a set rule, returning styles.
@@ -311,6 +319,13 @@ func setReflectValue(fieldValue reflect.Value, converted any, field *FieldDef) e
 		return nil
 	}
 
+	// Interface fields, like Value, hold the value as is, even if it is a
+	// pointer such as a dictionary
+	if fieldValue.Kind() == reflect.Interface && convertedVal.Type().AssignableTo(fieldValue.Type()) {
+		fieldValue.Set(convertedVal)
+		return nil
+	}
+
 	// Handle non-pointer types
 	if convertedVal.Kind() == reflect.Ptr {
 		if !convertedVal.IsNil() {
@@ -4,6 +4,9 @@
 package foundations
 
 import (
+	"fmt"
+	"sync/atomic"
+
 	"github.com/boergens/gotypst/syntax"
 )
 
@@ -25,6 +28,13 @@ type Engine struct {
 
 	// Traced tracks spans for IDE inspection.
 	Traced *Traced
+
+	// Budget enforces the resource limits of the evaluation.
+	Budget *Budget
+
+	// Arena allocates the small elements evaluation creates for markup.
+	// If nil, each element is allocated on its own.
+	Arena *Arena
 }
 
 // NewEngine creates a new engine with the given world and routines.
@@ -35,23 +45,10 @@ func NewEngine(world World, routines Routines) *Engine {
 		Route:    NewRoute(),
 		Sink:     NewSink(),
 		Traced:   nil,
+		Budget:   NewBudget(DefaultLimits()),
 	}
 }
 
-// ----------------------------------------------------------------------------
-// Routines (Virtual Function Table)
-// ----------------------------------------------------------------------------
-
-// Routines defines implementation of various Typst compiler routines.
-// This is essentially a vtable pattern to allow crate/package splitting
-// without circular dependencies.
-//
-// Matches Rust's Routines struct in routines.rs.
-type Routines interface {
-	// EvalClosure calls a closure with the given context and arguments.
-	EvalClosure(engine *Engine, context *Context, fn *Func, closure *Closure, args *Args) (Value, error)
-}
-
 // ----------------------------------------------------------------------------
 // Route (Cycle Detection)
 // ----------------------------------------------------------------------------
@@ -82,7 +79,12 @@ func NewRoute() *Route {
 // CheckShowDepth ensures we are within the maximum show rule depth.
 func (r *Route) CheckShowDepth() error {
 	if r.len > MaxShowRuleDepth {
-		return &DepthExceededError{Kind: "show rule", Depth: r.len, Max: MaxShowRuleDepth}
+		return &DepthExceededError{
+			Kind:  "show rule",
+			Depth: r.len,
+			Max:   MaxShowRuleDepth,
+			Hint:  "maybe a show rule matches its own output",
+		}
 	}
 	return nil
 }
@@ -161,6 +163,90 @@ func (r *Route) Clone() *Route {
 	return clone
 }
 
+// ----------------------------------------------------------------------------
+// Limits (Resource Budget)
+// ----------------------------------------------------------------------------
+
+// Limits bounds the resources an evaluation may use, so that documents from
+// untrusted sources cannot keep a server busy or exhaust its memory.
+type Limits struct {
+	// MaxCallDepth is the maximum nesting depth of function calls. Zero
+	// means the default of MaxCallDepth, since calls cannot be unbounded.
+	MaxCallDepth int
+
+	// MaxSteps is the maximum number of loop iterations across the whole
+	// evaluation. Zero means no limit.
+	MaxSteps int64
+
+	// MaxContentSize is the maximum number of elements in a single piece
+	// of content. Zero means no limit.
+	MaxContentSize int
+}
+
+// DefaultLimits returns the limits used unless configured otherwise. Only
+// the call depth is bounded, like in Typst.
+func DefaultLimits() Limits {
+	return Limits{MaxCallDepth: MaxCallDepth}
+}
+
+// Budget tracks the resources used by an evaluation against its limits.
+// It is shared by all copies of an engine and safe for concurrent use.
+type Budget struct {
+	// Limits are the limits the budget enforces.
+	Limits Limits
+
+	steps atomic.Int64
+}
+
+// NewBudget creates a budget that enforces the given limits.
+func NewBudget(limits Limits) *Budget {
+	return &Budget{Limits: limits}
+}
+
+// CheckCallDepth ensures that a call at the given nesting depth is within
+// the limits.
+func (b *Budget) CheckCallDepth(span syntax.Span, depth int) error {
+	max := MaxCallDepth
+	if b != nil && b.Limits.MaxCallDepth > 0 {
+		max = b.Limits.MaxCallDepth
+	}
+	if depth < max {
+		return nil
+	}
+	return NewSourceError(span, "maximum function call depth exceeded").
+		WithHint("check whether the function calls itself without end")
+}
+
+// Step consumes one loop iteration from the budget.
+func (b *Budget) Step(span syntax.Span) error {
+	if b == nil {
+		return nil
+	}
+	if steps := b.steps.Add(1); b.Limits.MaxSteps <= 0 || steps <= b.Limits.MaxSteps {
+		return nil
+	}
+	return NewSourceError(span, "maximum number of loop iterations exceeded").
+		WithHint(fmt.Sprintf("evaluation is limited to %d iterations", b.Limits.MaxSteps))
+}
+
+// Steps returns the number of loop iterations consumed so far.
+func (b *Budget) Steps() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.steps.Load()
+}
+
+// CheckContentSize ensures that content with the given number of elements
+// is within the limits.
+func (b *Budget) CheckContentSize(span syntax.Span, size int) error {
+	if b == nil || b.Limits.MaxContentSize <= 0 || size <= b.Limits.MaxContentSize {
+		return nil
+	}
+	return NewSourceError(span, "content is too large").
+		WithHint(fmt.Sprintf("content is limited to %d elements", b.Limits.MaxContentSize))
+}
+
 // ----------------------------------------------------------------------------
 // Sink (Warning/Trace Collection)
 // ----------------------------------------------------------------------------
@@ -177,6 +263,16 @@ type Sink struct {
 
 	// Values contains traced values for IDE inspection.
 	Values []TracedValue
+
+	// warned holds the span and message of each recorded warning, so that
+	// a warning emitted repeatedly is only reported once.
+	warned map[warningKey]struct{}
+}
+
+// warningKey identifies a warning for deduplication.
+type warningKey struct {
+	span    syntax.Span
+	message string
 }
 
 // MaxTracedValues is the maximum number of traced values to store.
@@ -196,12 +292,34 @@ func (s *Sink) Delay(errors []SourceDiagnostic) {
 	s.Delayed = append(s.Delayed, errors...)
 }
 
-// Warn adds a warning to the sink.
+// Warn adds a warning to the sink. A warning with the same span and
+// message as an earlier one is dropped.
+// Matches Rust: Sink::warn
 func (s *Sink) Warn(warning SourceDiagnostic) {
-	// TODO: Deduplicate warnings by hash of span+message
+	key := warningKey{span: warning.Span, message: warning.Message}
+	if _, ok := s.warned[key]; ok {
+		return
+	}
+	if s.warned == nil {
+		s.warned = make(map[warningKey]struct{})
+	}
+	s.warned[key] = struct{}{}
 	s.Warnings = append(s.Warnings, warning)
 }
 
+// Extend adds the delayed errors, warnings, and traced values of another
+// sink, as if they had been emitted into this one.
+// Matches Rust: Sink::extend
+func (s *Sink) Extend(other *Sink) {
+	s.Delay(other.Delayed)
+	for _, warning := range other.Warnings {
+		s.Warn(warning)
+	}
+	for _, traced := range other.Values {
+		s.TraceValue(traced.Value, traced.Styles)
+	}
+}
+
 // TraceValue records a traced value and optional styles.
 func (s *Sink) TraceValue(value Value, styles *Styles) {
 	if len(s.Values) < MaxTracedValues {
@@ -292,6 +410,10 @@ type SourceDiagnostic struct {
 
 	// Hints are optional hints for resolving the issue.
 	Hints []string
+
+	// Trace holds the points through which the error propagated, like
+	// function calls, innermost first.
+	Trace []syntax.Spanned[string]
 }
 
 // DiagnosticSeverity indicates the severity of a diagnostic.
@@ -308,6 +430,10 @@ const (
 
 // Location represents a location in the document for introspection.
 type Location struct {
+	// Hash uniquely identifies the element at this location. It is
+	// assigned during realization.
+	Hash uint64
+
 	// Page is the current page number.
 	Page int
 
@@ -315,6 +441,16 @@ type Location struct {
 	Position Point
 }
 
+// LocationValue represents a location as a Value.
+type LocationValue struct {
+	Location *Location
+}
+
+func (LocationValue) Type() Type         { return TypeLocation }
+func (v LocationValue) Display() Content { return Content{} }
+func (v LocationValue) Clone() Value     { return v }
+func (LocationValue) isValue()           {}
+
 // Point represents a position on a page.
 type Point struct {
 	X, Y Length
@@ -329,8 +465,14 @@ type DepthExceededError struct {
 	Kind  string
 	Depth int
 	Max   int
+	// Hint suggests a likely cause, if there is one.
+	Hint string
 }
 
 func (e *DepthExceededError) Error() string {
-	return "maximum " + e.Kind + " depth exceeded"
+	msg := "maximum " + e.Kind + " depth exceeded"
+	if e.Hint != "" {
+		return msg + " (hint: " + e.Hint + ")"
+	}
+	return msg
 }
@@ -0,0 +1,116 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/syntax"
+	"github.com/boergens/gotypst/timing"
+)
+
+func TestSinkWarnDeduplicates(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	sink := NewSink()
+	sink.Warn(NewSourceWarning(span, "unknown font family: fooo"))
+	sink.Warn(NewSourceWarning(span, "unknown font family: fooo"))
+	sink.Warn(NewSourceWarning(span, "unknown font family: barr"))
+	sink.Warn(NewSourceWarning(syntax.Detached(), "unknown font family: fooo"))
+
+	if len(sink.Warnings) != 3 {
+		t.Fatalf("got %d warnings, want 3: %v", len(sink.Warnings), sink.Warnings)
+	}
+}
+
+func TestBudgetCallDepth(t *testing.T) {
+	budget := NewBudget(Limits{MaxCallDepth: 3})
+	if err := budget.CheckCallDepth(syntax.Detached(), 2); err != nil {
+		t.Errorf("depth 2: unexpected error %v", err)
+	}
+	if err := budget.CheckCallDepth(syntax.Detached(), 3); err == nil {
+		t.Error("depth 3: expected an error")
+	}
+
+	// Zero falls back to the default depth.
+	if err := NewBudget(Limits{}).CheckCallDepth(syntax.Detached(), MaxCallDepth-1); err != nil {
+		t.Errorf("default depth: unexpected error %v", err)
+	}
+}
+
+func TestBudgetSteps(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	budget := NewBudget(Limits{MaxSteps: 2})
+	for i := range 2 {
+		if err := budget.Step(span); err != nil {
+			t.Fatalf("step %d: unexpected error %v", i, err)
+		}
+	}
+	err := budget.Step(span)
+	diag, ok := err.(SourceDiagnostic)
+	if !ok {
+		t.Fatalf("step 3: got %v, want a diagnostic", err)
+	}
+	if diag.Span != span || diag.Message != "maximum number of loop iterations exceeded" {
+		t.Errorf("diagnostic = %+v", diag)
+	}
+	if budget.Steps() != 3 {
+		t.Errorf("Steps = %d, want 3", budget.Steps())
+	}
+
+	// Without a limit, steps are only counted.
+	unlimited := NewBudget(DefaultLimits())
+	for range 100 {
+		if err := unlimited.Step(span); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBudgetContentSize(t *testing.T) {
+	budget := NewBudget(Limits{MaxContentSize: 10})
+	if err := budget.CheckContentSize(syntax.Detached(), 10); err != nil {
+		t.Errorf("size 10: unexpected error %v", err)
+	}
+	if err := budget.CheckContentSize(syntax.Detached(), 11); err == nil {
+		t.Error("size 11: expected an error")
+	}
+	if err := NewBudget(DefaultLimits()).CheckContentSize(syntax.Detached(), 1<<30); err != nil {
+		t.Errorf("unlimited: unexpected error %v", err)
+	}
+}
+
+// measuring lays out content as a fixed-size square and records the
+// styles it was given.
+type measuring struct {
+	styles *StyleChain
+}
+
+func (m *measuring) LayoutFrame(engine *Engine, content Content, styles *StyleChain, region Region) (Size, error) {
+	m.styles = styles
+	return Size{Width: Length{Points: 10}, Height: Length{Points: 10}}, nil
+}
+
+func (m *measuring) LayoutDocument(engine *Engine, content Content, jobs int, timer *timing.Timer) (*layout.PagedDocument, error) {
+	return &layout.PagedDocument{}, nil
+}
+
+func TestRoutineSet(t *testing.T) {
+	measure := &measuring{}
+	engine := NewEngine(nil, RoutineSet{Layout: measure})
+
+	styles := EmptyStyleChain()
+	size, err := engine.Routines.LayoutFrame(engine, Content{}, styles, Region{})
+	if err != nil || size.Width.Points != 10 || measure.styles != styles {
+		t.Errorf("LayoutFrame() = %v, %v", size, err)
+	}
+
+	// The routines of subsystems that are left out fail.
+	if _, err := engine.Routines.Realize(engine, LayoutFragment{}, nil, styles); err == nil {
+		t.Error("expected an error from a missing realization")
+	}
+	if _, err := engine.Routines.EvalString(engine, "1", syntax.Detached(), syntax.ModeCode, nil); err == nil {
+		t.Error("expected an error from a missing evaluator")
+	}
+	if _, err := engine.Routines.EvalSource(engine, syntax.NewDetachedSource("")); err == nil {
+		t.Error("expected an error from a missing evaluator")
+	}
+}
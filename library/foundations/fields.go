@@ -0,0 +1,267 @@
+// Element field access for Typst.
+// Translated from typst-library/src/foundations/content/mod.rs
+
+package foundations
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// FieldNotFoundError is returned when a value does not have a field, as in
+// `heading(level: 1)[A].size`.
+type FieldNotFoundError struct {
+	// Owner is what the field was looked up on: an element name like
+	// `heading` or a type name.
+	Owner string
+	Field string
+	Span  syntax.Span
+}
+
+func (e *FieldNotFoundError) Error() string {
+	return fmt.Sprintf("%s does not have field %q", e.Owner, e.Field)
+}
+
+// FieldOf returns a field of a value, as in `it.body` or `(50% + 1pt).ratio`.
+// Matches Rust: Value::field
+func FieldOf(v Value, field string) (Value, error) {
+	switch v := v.(type) {
+	case ContentValue:
+		return ContentField(v.Content, field)
+	case *Dict:
+		if value, ok := v.Get(field); ok {
+			return value, nil
+		}
+		return nil, &OpError{Message: fmt.Sprintf("dictionary does not contain key %q", field)}
+	case LengthValue:
+		switch field {
+		case "abs":
+			return v, nil
+		case "em":
+			// Em lengths are resolved when they are created.
+			return Float(0), nil
+		}
+	case RelativeValue:
+		switch field {
+		case "length":
+			return LengthValue{Length: v.Relative.Abs}, nil
+		case "ratio":
+			return RatioValue{Ratio: v.Relative.Rel}, nil
+		}
+	}
+	if !HasFields(v.Type()) {
+		return nil, &OpError{Message: fmt.Sprintf("cannot access fields on type %s", v.Type())}
+	}
+	return nil, &FieldNotFoundError{Owner: v.Type().String(), Field: field}
+}
+
+// HasFields reports whether values of a type have fields.
+// Matches Rust: fields_on(ty).is_empty()
+func HasFields(t Type) bool {
+	switch t {
+	case TypeContent, TypeDict, TypeLength, TypeRelative:
+		return true
+	}
+	return false
+}
+
+// ContentElem returns the element of content. Content that is not a single
+// element is a sequence of its elements.
+func ContentElem(c Content) ContentElement {
+	if len(c.Elements) == 1 {
+		return c.Elements[0]
+	}
+	children := make([]Content, len(c.Elements))
+	for i, elem := range c.Elements {
+		children[i] = Content{Elements: []ContentElement{elem}}
+	}
+	return &SequenceElem{Children: children}
+}
+
+// ContentField returns a field of content, like the level of a heading.
+// Matches Rust: Content::field_by_name
+func ContentField(c Content, field string) (Value, error) {
+	elem := ContentElem(c)
+	if value, ok := ElementFields(elem).Get(field); ok {
+		return value, nil
+	}
+	return nil, &FieldNotFoundError{Owner: ElementName(elem), Field: field}
+}
+
+// ElementFuncOf returns the function of an element, as in `it.func()`. The
+// function of a registered element constructs new elements of its kind;
+// other elements, like sequences, cannot be constructed.
+// Matches Rust: Content::func
+func ElementFuncOf(elem ContentElement) *Func {
+	name := ElementName(elem)
+	def := elementDefOf(elem)
+	if def == nil {
+		return &Func{Name: &name, Span: syntax.Detached(), Repr: NativeFunc{
+			Func: func(engine Engine, context Context, args *Args) (Value, error) {
+				return nil, &OpError{Message: fmt.Sprintf("cannot construct %s", name)}
+			},
+			Info: &FuncInfo{Name: name},
+		}}
+	}
+	return &Func{Name: &name, Span: syntax.Detached(), Repr: NativeFunc{
+		Func: func(engine Engine, context Context, args *Args) (Value, error) {
+			elem := reflect.New(def.Type)
+			if err := parseElementInto(def, args, elem.Elem()); err != nil {
+				return nil, err
+			}
+			return ContentValue{Content: Content{Elements: []ContentElement{elem.Interface().(ContentElement)}}}, nil
+		},
+		Info: def.ToFuncInfo(),
+	}}
+}
+
+// ElementName returns the name of the function an element belongs to, like
+// `heading`. Elements that are not registered through RegisterElement are
+// named after their Go type, without an `Elem` or `Element` suffix.
+//
+// Matches Rust: Content::elem().name()
+func ElementName(elem ContentElement) string {
+	if def := elementDefOf(elem); def != nil {
+		return def.Name
+	}
+	name := reflect.Indirect(reflect.ValueOf(elem)).Type().Name()
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "Element"), "Elem")
+	return kebabCase(name)
+}
+
+// ElementFields returns the fields of an element as a dictionary. Unset
+// optional fields and fields whose values cannot be represented are left
+// out.
+//
+// Matches Rust: Content::fields
+func ElementFields(elem ContentElement) *Dict {
+	dict := NewDict()
+	v := reflect.Indirect(reflect.ValueOf(elem))
+	if v.Kind() != reflect.Struct {
+		return dict
+	}
+	if def := elementDefOf(elem); def != nil {
+		for _, field := range def.Fields {
+			if value, ok := fieldValue(v.Field(field.GoFieldIndex)); ok {
+				dict.Set(field.Name, value)
+			}
+		}
+	} else {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+			if value, ok := fieldValue(v.Field(i)); ok {
+				dict.Set(kebabCase(field.Name), value)
+			}
+		}
+	}
+	if label := ElementLabel(elem); label != nil {
+		dict.Set("label", *label)
+	}
+	return dict
+}
+
+// elementDefOf returns the registered definition of an element's type.
+func elementDefOf(elem ContentElement) *ElementDef {
+	typ := reflect.TypeOf(elem)
+	if typ == nil {
+		return nil
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	elementsMu.RLock()
+	defer elementsMu.RUnlock()
+	for _, def := range elements {
+		if def.Type == typ {
+			return def
+		}
+	}
+	return nil
+}
+
+var (
+	valueType   = reflect.TypeOf((*Value)(nil)).Elem()
+	contentType = reflect.TypeOf(Content{})
+	elemType    = reflect.TypeOf((*ContentElement)(nil)).Elem()
+)
+
+// fieldValue converts a Go field of an element into a value. Nil pointers
+// and interfaces count as unset.
+func fieldValue(v reflect.Value) (Value, bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+	}
+	if v.Type() == contentType {
+		return ContentValue{Content: v.Interface().(Content)}, true
+	}
+	if v.Type().Implements(valueType) {
+		return v.Interface().(Value), true
+	}
+	if v.Type().Implements(elemType) {
+		return ContentValue{Content: Content{Elements: []ContentElement{v.Interface().(ContentElement)}}}, true
+	}
+	switch x := v.Interface().(type) {
+	case Length:
+		return LengthValue{Length: x}, true
+	case Ratio:
+		return RatioValue{Ratio: x}, true
+	case Relative:
+		return RelativeValue{Relative: x}, true
+	case Angle:
+		return AngleValue{Angle: x}, true
+	case Fraction:
+		return FractionValue{Fraction: x}, true
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return fieldValue(v.Elem())
+	case reflect.String:
+		return Str(v.String()), true
+	case reflect.Bool:
+		return Bool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(v.Int()), true
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Int(int64(v.Uint())), true
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float()), true
+	case reflect.Slice:
+		items := make([]Value, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if item, ok := fieldValue(v.Index(i)); ok {
+				items = append(items, item)
+			}
+		}
+		return NewArray(items...), true
+	}
+	return nil, false
+}
+
+// kebabCase converts a Go identifier like `NumberAlign` into a Typst name
+// like `number-align`.
+func kebabCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at an upper-case letter, unless it continues
+			// an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// fieldsElem is a registered element for testing field access.
+type fieldsElem struct {
+	Title  string  `typst:"title,positional,required"`
+	Level  *int64  `typst:"level,type=int"`
+	Gutter *Length `typst:"gutter,type=length"`
+	ElemMeta
+}
+
+func (*fieldsElem) IsContentElement() {}
+
+// plainElement is an element that is not registered.
+type plainElement struct {
+	NumberAlign string
+	Hidden      *int
+	ElemMeta
+}
+
+func (*plainElement) IsContentElement() {}
+
+func TestElementFields(t *testing.T) {
+	RegisterElement[fieldsElem]("fields-test", nil)
+	level := int64(2)
+	elem := &fieldsElem{Title: "Intro", Level: &level}
+
+	if name := ElementName(elem); name != "fields-test" {
+		t.Errorf("ElementName() = %q", name)
+	}
+	fields := ElementFields(elem)
+	if got := Repr(fields); got != `(title: "Intro", level: 2)` {
+		t.Errorf("fields = %s", got)
+	}
+
+	label := "intro"
+	elem.Label = &label
+	if got := Repr(ElementFields(elem)); got != `(title: "Intro", level: 2, label: <intro>)` {
+		t.Errorf("labelled fields = %s", got)
+	}
+
+	plain := &plainElement{NumberAlign: "end", ElemMeta: ElemMeta{Label: &label}}
+	if name := ElementName(plain); name != "plain" {
+		t.Errorf("ElementName() = %q", name)
+	}
+	if got := Repr(ElementFields(plain)); got != `(number-align: "end", label: <intro>)` {
+		t.Errorf("fields = %s", got)
+	}
+
+	seq := &SequenceElem{Children: []Content{{Elements: []ContentElement{&SymbolElem{Text: "x"}}}}}
+	if name := ElementName(seq); name != "sequence" {
+		t.Errorf("ElementName() = %q", name)
+	}
+	if _, ok := ElementFields(seq).Get("children"); !ok {
+		t.Error("sequence has no children field")
+	}
+}
+
+func TestCastLocatableSelector(t *testing.T) {
+	RegisterElement[fieldsElem]("fields-test", nil)
+	name := "fields-test"
+	fn := &Func{Name: &name, Repr: NativeFunc{}}
+
+	sel, err := CastLocatableSelector(FuncValue{Func: fn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elem, ok := sel.Selector.(ElemSelector); !ok || elem.Element.Name != "fields-test" {
+		t.Errorf("selector = %#v", sel.Selector)
+	}
+
+	sel, err = CastLocatableSelector(LabelValue("intro"))
+	if err != nil || sel.Selector != (LabelSelector{Label: "intro"}) {
+		t.Errorf("selector = %#v, %v", sel.Selector, err)
+	}
+
+	if _, err := CastLocatableSelector(Str("heading")); err == nil {
+		t.Error("expected error for string")
+	}
+	other := "other"
+	if _, err := CastLocatableSelector(FuncValue{Func: &Func{Name: &other, Repr: NativeFunc{}}}); err == nil {
+		t.Error("expected error for non-element function")
+	}
+}
+
+func TestContentFieldAccess(t *testing.T) {
+	RegisterElement[fieldsElem]("fields-test", nil)
+	level := int64(2)
+	content := ContentValue{Content: Content{Elements: []ContentElement{&fieldsElem{Title: "Intro", Level: &level}}}}
+
+	if got, err := FieldOf(content, "level"); err != nil || got != Int(2) {
+		t.Errorf("level = %v, %v", got, err)
+	}
+	if _, err := FieldOf(content, "gutter"); err == nil || err.Error() != `fields-test does not have field "gutter"` {
+		t.Errorf("unset field: %v", err)
+	}
+	if got, err := FieldOf(RelativeValue{Relative: Relative{Abs: Length{Points: 1}, Rel: Ratio{Value: 0.5}}}, "ratio"); err != nil || !Equal(got, RatioValue{Ratio: Ratio{Value: 0.5}}) {
+		t.Errorf("ratio = %v, %v", got, err)
+	}
+	if _, err := FieldOf(Int(1), "x"); err == nil {
+		t.Error("field on integer: expected an error")
+	}
+
+	noArgs := func() *Args { return NewArgs(syntax.Detached()) }
+	if got, err := callMethod(t, TypeContent, "has", content, NewArgs(syntax.Detached(), Str("title"))); err != nil || got != Bool(true) {
+		t.Errorf("has = %v, %v", got, err)
+	}
+	args := NewArgs(syntax.Detached(), Str("gutter"))
+	args.Items = append(args.Items, namedArg("default", Str("none")))
+	if got, err := callMethod(t, TypeContent, "at", content, args); err != nil || got != Str("none") {
+		t.Errorf("at = %v, %v", got, err)
+	}
+	if got, err := callMethod(t, TypeContent, "location", content, noArgs()); err != nil || got != None {
+		t.Errorf("location = %v, %v", got, err)
+	}
+
+	got, err := callMethod(t, TypeContent, "func", content, noArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "fields-test"
+	if !Equal(got, FuncValue{Func: &Func{Name: &name, Repr: NativeFunc{}}}) {
+		t.Errorf("func = %v, want the fields-test element function", got)
+	}
+	rebuilt, err := got.(FuncValue).Func.Call(&Engine{}, &Context{}, NewArgs(syntax.Detached(), Str("Again")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title, err := FieldOf(rebuilt, "title"); err != nil || title != Str("Again") {
+		t.Errorf("rebuilt title = %v, %v", title, err)
+	}
+
+	seq := ContentValue{Content: Content{Elements: []ContentElement{&SymbolElem{Text: "a"}, &SymbolElem{Text: "b"}}}}
+	children, err := FieldOf(seq, "children")
+	if err != nil || children.(*Array).Len() != 2 {
+		t.Errorf("children = %v, %v", children, err)
+	}
+}
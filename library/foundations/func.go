@@ -126,3 +126,97 @@ func (f *Func) Scope() *Scope {
 	}
 	return nil
 }
+
+// Element returns the definition of the element the function constructs,
+// or nil if it is not an element function.
+// Matches Rust: Func::element
+func (f *Func) Element() *ElementDef {
+	if f == nil || f.Name == nil {
+		return nil
+	}
+	if _, ok := f.Repr.(NativeFunc); !ok {
+		return nil
+	}
+	return GetElement(*f.Name)
+}
+
+// Call calls the function with the given engine, context, and arguments.
+// Closures are evaluated through the engine's routines so that this package
+// does not depend on the evaluator.
+// Matches Rust: pub fn call(&self, engine, context, args) -> SourceResult<Value>
+func (f *Func) Call(engine *Engine, context *Context, args *Args) (Value, error) {
+	switch repr := f.Repr.(type) {
+	case NativeFunc:
+		return repr.Func(*engine, *context, args)
+	case ClosureFunc:
+		if engine.Routines == nil {
+			return nil, &OpError{Message: "cannot call closure without routines"}
+		}
+		return engine.Routines.EvalClosure(engine, context, f, repr.Closure, args)
+	case WithFunc:
+		combined := repr.Args.Clone()
+		combined.Items = append(combined.Items, args.Items...)
+		combined.Span = args.Span
+		return repr.Func.Call(engine, context, combined)
+	case WasmFunc:
+		return repr.call(args)
+	default:
+		return nil, &OpError{Message: "value is not callable"}
+	}
+}
+
+func init() {
+	name := "where"
+	scope := NewScope()
+	scope.Define(name, FuncValue{Func: &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: funcWhere,
+			Info: &FuncInfo{
+				Name:   name,
+				Params: []ParamInfo{{Name: "self", Type: TypeFunc}, {Name: "fields", Type: TypeDyn, Variadic: true}},
+			},
+		},
+	}}, syntax.Detached())
+	RegisterTypeScope(TypeFunc, scope)
+}
+
+// funcWhere returns a selector that matches the elements of an element
+// function whose fields have the given values, as in
+// `heading.where(level: 1)`.
+//
+// Matches Rust: Func::where_
+func funcWhere(engine Engine, context Context, args *Args) (Value, error) {
+	self, err := args.Expect("self")
+	if err != nil {
+		return nil, err
+	}
+	fv, ok := self.V.(FuncValue)
+	if !ok {
+		return nil, &TypeMismatchError{Expected: "function", Got: self.V.Type().String(), Field: "self", Span: self.Span}
+	}
+	def := fv.Func.Element()
+	if def == nil {
+		return nil, NewSourceError(args.Span, "`where()` can only be called on element functions")
+	}
+
+	fields := NewDict()
+	for _, item := range args.Items {
+		if item.Name == nil {
+			continue
+		}
+		key := string(*item.Name)
+		if def.FieldByName(key) == nil {
+			return nil, NewSourceError(item.Span, "element `"+def.Name+"` does not have field `"+key+"`")
+		}
+		fields.Set(key, item.Value.V)
+	}
+	for _, key := range fields.Keys() {
+		args.Named(key)
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	return SelectorValue{Selector: ElemSelector{Element: Element{Name: def.Name}, Fields: fields}}, nil
+}
@@ -5,6 +5,7 @@ package foundations
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -65,7 +66,7 @@ func IntConstruct(args *Args) (Value, error) {
 		return Int(0), nil
 
 	case Float:
-		return Int(int64(v)), nil
+		return floatToInt(float64(v), spanned.Span)
 
 	case DecimalValue:
 		// Truncate decimal to integer
@@ -73,7 +74,7 @@ func IntConstruct(args *Args) (Value, error) {
 			return Int(0), nil
 		}
 		f, _ := v.Value.Float64()
-		return Int(int64(f)), nil
+		return floatToInt(f, spanned.Span)
 
 	case Str:
 		return parseIntFromString(string(v), base, spanned.Span)
@@ -86,6 +87,19 @@ func IntConstruct(args *Args) (Value, error) {
 	}
 }
 
+// floatToInt truncates a float to an integer. Floats that are out of the
+// integer range cannot be converted.
+// Matches Rust: fn convert_float_to_int in foundations/int.rs
+func floatToInt(f float64, span syntax.Span) (Value, error) {
+	if math.IsNaN(f) {
+		return nil, &ConstructorError{Message: "cannot convert NaN to an integer", Span: span}
+	}
+	if f <= math.MinInt64-1.0 || f >= math.MaxInt64+1.0 {
+		return nil, &ConstructorError{Message: "number too large", Span: span}
+	}
+	return Int(int64(f)), nil
+}
+
 // parseIntFromString parses an integer from a string with the given base.
 func parseIntFromString(s string, base int, span syntax.Span) (Value, error) {
 	if s == "" {
@@ -0,0 +1,78 @@
+// Labels for Typst.
+// Translated from typst-library/src/foundations/label.rs
+
+package foundations
+
+// LabelConstruct creates a label from a string, as in `label("intro")`.
+// Matches Rust: Label::construct
+func LabelConstruct(args *Args) (Value, error) {
+	name, err := expectArg(args, "name", "string", AsStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, &OpError{Message: "label name must not be empty"}
+	}
+	return LabelValue(name), nil
+}
+
+// ElementLabel returns the label attached to an element, or nil if it has
+// none.
+// Matches Rust: Content::label
+func ElementLabel(elem ContentElement) *LabelValue {
+	meta, ok := elem.(metaElement)
+	if !ok || meta.elemMeta().Label == nil {
+		return nil
+	}
+	label := LabelValue(*meta.elemMeta().Label)
+	return &label
+}
+
+// WithLabel returns a copy of an element with a label attached. It reports
+// false if the element cannot be labelled.
+// Matches Rust: Content::labelled
+func WithLabel(elem ContentElement, label LabelValue) (ContentElement, bool) {
+	meta, ok := elem.(metaElement)
+	if !ok {
+		return elem, false
+	}
+	copied := copyElement(meta)
+	name := string(label)
+	copied.elemMeta().Label = &name
+	return copied, true
+}
+
+// IsUnlabellable reports whether a label cannot be attached to the content.
+// Content of several elements is labelled as a sequence.
+// Matches Rust: Content::can::<dyn Unlabellable>
+func (c Content) IsUnlabellable() bool {
+	switch len(c.Elements) {
+	case 0:
+		return true
+	case 1:
+		_, ok := c.Elements[0].(metaElement)
+		return !ok
+	}
+	return false
+}
+
+// Label returns the label attached to the content, or nil if it has none.
+func (c Content) Label() *LabelValue {
+	if len(c.Elements) != 1 {
+		return nil
+	}
+	return ElementLabel(c.Elements[0])
+}
+
+// Labelled returns the content with a label attached, replacing an existing
+// one. Unlabellable content is returned unchanged.
+func (c Content) Labelled(label LabelValue) Content {
+	elem, ok := WithLabel(ContentElem(c), label)
+	if !ok {
+		return c
+	}
+	return Content{Elements: []ContentElement{elem}}
+}
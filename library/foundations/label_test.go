@@ -0,0 +1,60 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// spaceElem is an element without a label field.
+type spaceElem struct{}
+
+func (*spaceElem) IsContentElement() {}
+
+func TestLabelConstruct(t *testing.T) {
+	got, err := LabelConstruct(NewArgs(syntax.Detached(), Str("intro")))
+	if err != nil || got != LabelValue("intro") {
+		t.Errorf("label(\"intro\") = %v, %v", got, err)
+	}
+	if _, err := LabelConstruct(NewArgs(syntax.Detached(), Str(""))); err == nil {
+		t.Error("empty label: expected an error")
+	}
+	if _, err := LabelConstruct(NewArgs(syntax.Detached(), Int(1))); err == nil {
+		t.Error("integer label: expected an error")
+	}
+}
+
+func TestContentLabelled(t *testing.T) {
+	symbol := &SymbolElem{Text: "x"}
+	content := Content{Elements: []ContentElement{symbol}}
+	if content.IsUnlabellable() || content.Label() != nil {
+		t.Fatalf("unlabelled symbol: unlabellable %v, label %v", content.IsUnlabellable(), content.Label())
+	}
+
+	labelled := content.Labelled("intro")
+	if label := labelled.Label(); label == nil || *label != "intro" {
+		t.Errorf("label = %v, want <intro>", label)
+	}
+	if symbol.Label != nil {
+		t.Error("labelling modified the original element")
+	}
+	if got, _ := ContentField(labelled, "label"); got != LabelValue("intro") {
+		t.Errorf("label field = %v", got)
+	}
+	if label := labelled.Labelled("other").Label(); label == nil || *label != "other" {
+		t.Errorf("relabelled = %v, want <other>", label)
+	}
+
+	sequence := Content{Elements: []ContentElement{symbol, &SymbolElem{Text: "y"}}}.Labelled("pair")
+	if _, ok := sequence.Elements[0].(*SequenceElem); !ok || sequence.Label() == nil {
+		t.Errorf("labelled sequence = %#v", sequence.Elements)
+	}
+
+	space := Content{Elements: []ContentElement{&spaceElem{}}}
+	if !(Content{}).IsUnlabellable() || !space.IsUnlabellable() {
+		t.Error("empty content and spaces must be unlabellable")
+	}
+	if got := space.Labelled("intro"); got.Label() != nil {
+		t.Errorf("labelled space = %v", got.Label())
+	}
+}
@@ -3,6 +3,16 @@
 
 package foundations
 
+import (
+	"math"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// DefaultEmSize is the font size that em lengths are resolved against.
+// Lengths are absolute, so `1em` is the default text size of 11pt.
+const DefaultEmSize = 11.0
+
 // Length represents a physical length value.
 type Length struct {
 	// Points is the length in typographic points (1/72 inch).
@@ -84,3 +94,55 @@ func (FractionValue) Type() Type         { return TypeFraction }
 func (v FractionValue) Display() Content { return Content{} }
 func (v FractionValue) Clone() Value     { return v }
 func (FractionValue) isValue()           {}
+
+// Numeric creates the value of a numeric literal with a unit, like `2cm`,
+// `90deg`, `50%`, or `1fr`. Without a unit, it is a float.
+// Matches Rust: impl Eval for ast::Numeric
+func Numeric(value float64, unit syntax.Unit) Value {
+	switch unit {
+	case syntax.UnitPt, syntax.UnitMm, syntax.UnitCm, syntax.UnitIn:
+		points, _ := unit.ConvertTo(value, syntax.UnitPt)
+		return LengthValue{Length: Length{Points: points}}
+	case syntax.UnitEm:
+		return LengthValue{Length: Length{Points: value * DefaultEmSize}}
+	case syntax.UnitRad:
+		return AngleValue{Angle: Angle{Radians: value}}
+	case syntax.UnitDeg:
+		return AngleValue{Angle: Angle{Radians: value * math.Pi / 180}}
+	case syntax.UnitPercent:
+		return RatioValue{Ratio: Ratio{Value: value / 100}}
+	case syntax.UnitFr:
+		return FractionValue{Fraction: Fraction{Value: value}}
+	}
+	return Float(value)
+}
+
+func init() {
+	lengths := newMethods(TypeLength)
+	for name, unit := range map[string]syntax.Unit{
+		"pt":     syntax.UnitPt,
+		"mm":     syntax.UnitMm,
+		"cm":     syntax.UnitCm,
+		"inches": syntax.UnitIn,
+	} {
+		lengths.define(name, nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+			value, _ := syntax.UnitPt.ConvertTo(self.(LengthValue).Length.Points, unit)
+			return Float(value), nil
+		})
+	}
+	// Lengths are resolved when they are created, so a length is already
+	// absolute.
+	lengths.define("to-absolute", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return self, nil
+	})
+	lengths.register()
+
+	angles := newMethods(TypeAngle)
+	angles.define("deg", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return Float(self.(AngleValue).Angle.Radians * 180 / math.Pi), nil
+	})
+	angles.define("rad", nil, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		return Float(self.(AngleValue).Angle.Radians), nil
+	})
+	angles.register()
+}
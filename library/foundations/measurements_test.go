@@ -0,0 +1,34 @@
+package foundations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestNumeric(t *testing.T) {
+	tests := []struct {
+		value float64
+		unit  syntax.Unit
+		want  Value
+	}{
+		{1.5, syntax.UnitNone, Float(1.5)},
+		{12, syntax.UnitPt, LengthValue{Length: Length{Points: 12}}},
+		{1, syntax.UnitIn, LengthValue{Length: Length{Points: 72}}},
+		{2, syntax.UnitEm, LengthValue{Length: Length{Points: 2 * DefaultEmSize}}},
+		{180, syntax.UnitDeg, AngleValue{Angle: Angle{Radians: math.Pi}}},
+		{50, syntax.UnitPercent, RatioValue{Ratio: Ratio{Value: 0.5}}},
+		{2, syntax.UnitFr, FractionValue{Fraction: Fraction{Value: 2}}},
+	}
+	for _, tt := range tests {
+		if got := Numeric(tt.value, tt.unit); got != tt.want {
+			t.Errorf("Numeric(%v, %v) = %#v, want %#v", tt.value, tt.unit, got, tt.want)
+		}
+	}
+
+	cm := Numeric(2.54, syntax.UnitCm).(LengthValue)
+	if math.Abs(cm.Length.Points-72) > 1e-3 {
+		t.Errorf("2.54cm = %vpt", cm.Length.Points)
+	}
+}
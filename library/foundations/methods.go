@@ -0,0 +1,137 @@
+package foundations
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// methodFunc implements a method of a type. It receives the value the
+// method is called on and the remaining arguments.
+type methodFunc func(engine *Engine, context *Context, self Value, args *Args) (Value, error)
+
+// methods builds the scope of a type's methods, like `"abc".len()` or
+// `(1, 2).map(x => x * 2)`. The evaluator calls a method with the value it
+// is called on as the first argument, named self.
+type methods struct {
+	typ   Type
+	scope *Scope
+}
+
+func newMethods(typ Type) methods {
+	return methods{typ: typ, scope: NewScope()}
+}
+
+// define defines a method. Arguments the method leaves over are an error.
+func (m methods) define(name string, params []ParamInfo, f methodFunc) {
+	fname := name
+	m.scope.Define(name, FuncValue{Func: &Func{
+		Name: &fname,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: func(engine Engine, context Context, args *Args) (Value, error) {
+				self, err := args.Expect("self")
+				if err != nil {
+					return nil, err
+				}
+				if self.V.Type() != m.typ {
+					return nil, &TypeMismatchError{Expected: m.typ.String(), Got: self.V.Type().String(), Field: "self", Span: self.Span}
+				}
+				result, err := f(&engine, &context, self.V, args)
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				return result, nil
+			},
+			Info: &FuncInfo{Name: name, Params: append([]ParamInfo{{Name: "self", Type: m.typ}}, params...)},
+		},
+	}}, syntax.Detached())
+}
+
+// register makes the methods the scope of their type.
+func (m methods) register() {
+	RegisterTypeScope(m.typ, m.scope)
+}
+
+// expectArg consumes a positional argument and casts it, reporting the
+// expected type on failure.
+func expectArg[T any](args *Args, what, expected string, cast func(Value) (T, bool)) (T, error) {
+	var zero T
+	arg, err := args.Expect(what)
+	if err != nil {
+		return zero, err
+	}
+	value, ok := cast(arg.V)
+	if !ok {
+		return zero, &TypeMismatchError{Expected: expected, Got: arg.V.Type().String(), Field: what, Span: arg.Span}
+	}
+	return value, nil
+}
+
+// eatNamed consumes a named argument and casts it. It reports whether the
+// argument was present.
+func eatNamed[T any](args *Args, name, expected string, cast func(Value) (T, bool)) (T, bool, error) {
+	var zero T
+	arg := args.Named(name)
+	if arg == nil {
+		return zero, false, nil
+	}
+	value, ok := cast(arg.V)
+	if !ok {
+		return zero, false, &TypeMismatchError{Expected: expected, Got: arg.V.Type().String(), Field: name, Span: arg.Span}
+	}
+	return value, true, nil
+}
+
+// applyFunc calls a function that was passed to a method, like the mapper
+// of `array.map`, with the given positional arguments.
+func applyFunc(engine *Engine, context *Context, f *Func, values ...Value) (Value, error) {
+	return f.Call(engine, context, NewArgs(f.Span, values...))
+}
+
+// applyTest calls a function that must return a boolean, like the test of
+// `array.filter`.
+func applyTest(engine *Engine, context *Context, f *Func, values ...Value) (bool, error) {
+	result, err := applyFunc(engine, context, f, values...)
+	if err != nil {
+		return false, err
+	}
+	b, ok := result.(Bool)
+	if !ok {
+		return false, &OpError{Message: fmt.Sprintf("expected boolean from function, found %s", result.Type())}
+	}
+	return bool(b), nil
+}
+
+// asOptionalInt casts an integer or none, where none is nil.
+func asOptionalInt(v Value) (*int64, bool) {
+	switch v := v.(type) {
+	case NoneValue:
+		return nil, true
+	case Int:
+		i := int64(v)
+		return &i, true
+	}
+	return nil, false
+}
+
+// asOptionalStr casts a string or none, where none is nil.
+func asOptionalStr(v Value) (*string, bool) {
+	switch v := v.(type) {
+	case NoneValue:
+		return nil, true
+	case Str:
+		s := string(v)
+		return &s, true
+	}
+	return nil, false
+}
+
+// asRatio casts a ratio.
+func asRatio(v Value) (float64, bool) {
+	r, ok := v.(RatioValue)
+	return r.Ratio.Value, ok
+}
@@ -0,0 +1,182 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// testFunc wraps a Go function of one or two values as a Typst function.
+func testFunc(f func(values ...Value) (Value, error)) FuncValue {
+	return FuncValue{Func: &Func{Span: syntax.Detached(), Repr: NativeFunc{
+		Func: func(engine Engine, context Context, args *Args) (Value, error) {
+			var values []Value
+			for _, arg := range args.All() {
+				values = append(values, arg.V)
+			}
+			return f(values...)
+		},
+		Info: &FuncInfo{Name: "test"},
+	}}}
+}
+
+func TestArrayMethods(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Push(Int(v))
+		}
+		return array
+	}
+	double := testFunc(func(values ...Value) (Value, error) { return Mul(values[0], Int(2)) })
+	odd := testFunc(func(values ...Value) (Value, error) { return Bool(values[0].(Int)%2 == 1), nil })
+	negate := testFunc(func(values ...Value) (Value, error) { return Neg(values[0]) })
+	args := func(values ...Value) *Args { return NewArgs(syntax.Detached(), values...) }
+	withNamed := func(args *Args, name string, value Value) *Args {
+		args.Items = append(args.Items, namedArg(name, value))
+		return args
+	}
+
+	tests := []struct {
+		method string
+		target *Array
+		args   *Args
+		want   Value
+	}{
+		{"len", ints(1, 2, 3), args(), Int(3)},
+		{"last", ints(1, 2, 3), args(), Int(3)},
+		{"at", ints(1, 2, 3), args(Int(-1)), Int(3)},
+		{"at", ints(1), withNamed(args(Int(4)), "default", None), None},
+		{"slice", ints(1, 2, 3, 4), args(Int(1), Int(-1)), ints(2, 3)},
+		{"map", ints(1, 2), args(double), ints(2, 4)},
+		{"filter", ints(1, 2, 3), args(odd), ints(1, 3)},
+		{"find", ints(2, 3, 5), args(odd), Int(3)},
+		{"position", ints(2, 4), args(odd), None},
+		{"any", ints(2, 3), args(odd), Bool(true)},
+		{"all", ints(1, 2), args(odd), Bool(false)},
+		{"enumerate", ints(7), withNamed(args(), "start", Int(1)), NewArray(ints(1, 7))},
+		{"zip", ints(1, 2, 3), args(ints(4, 5)), NewArray(ints(1, 4), ints(2, 5))},
+		{"fold", ints(1, 2, 3), args(Int(10), testFunc(func(values ...Value) (Value, error) { return Add(values[0], values[1]) })), Int(16)},
+		{"sum", ints(1, 2, 3), args(), Int(6)},
+		{"product", ints(), withNamed(args(), "default", Int(1)), Int(1)},
+		{"flatten", NewArray(Int(1), NewArray(Int(2), ints(3))), args(), ints(1, 2, 3)},
+		{"rev", ints(1, 2), args(), ints(2, 1)},
+		{"split", ints(1, 0, 2, 0), args(Int(0)), NewArray(ints(1), ints(2), ints())},
+		{"join", NewArray(Str("a"), Str("b"), Str("c")), withNamed(args(Str(", ")), "last", Str(" and ")), Str("a, b and c")},
+		{"intersperse", ints(1, 2), args(Int(0)), ints(1, 0, 2)},
+		{"sorted", ints(3, 1, 2), args(), ints(1, 2, 3)},
+		{"sorted", ints(3, 1, 2), withNamed(args(), "key", negate), ints(3, 2, 1)},
+		{"dedup", ints(1, 2, 1, 3), args(), ints(1, 2, 3)},
+		{"chunks", ints(1, 2, 3), args(Int(2)), NewArray(ints(1, 2), ints(3))},
+		{"windows", ints(1, 2, 3), args(Int(2)), NewArray(ints(1, 2), ints(2, 3))},
+		{"contains", ints(1, 2), args(Int(2)), Bool(true)},
+	}
+	for _, tt := range tests {
+		got, err := callMethod(t, TypeArray, tt.method, tt.target, tt.args)
+		if err != nil {
+			t.Errorf("%s: %v", tt.method, err)
+			continue
+		}
+		if !Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.method, Repr(got), Repr(tt.want))
+		}
+	}
+
+	for _, tt := range []struct {
+		method string
+		target *Array
+		args   *Args
+	}{
+		{"first", ints(), args()},
+		{"sum", ints(), args()},
+		{"sorted", NewArray(Int(1), Str("a")), args()},
+		{"zip", ints(1, 2), withNamed(args(ints(1)), "exact", Bool(true))},
+		{"map", ints(1), args(Int(1))},
+	} {
+		if _, err := callMethod(t, TypeArray, tt.method, tt.target, tt.args); err == nil {
+			t.Errorf("%s: expected an error", tt.method)
+		}
+	}
+}
+
+func TestDictMethods(t *testing.T) {
+	dict := NewDict()
+	dict.Set("a", Int(1))
+	dict.Set("b", Int(2))
+
+	tests := []struct {
+		method string
+		args   *Args
+		want   Value
+	}{
+		{"len", NewArgs(syntax.Detached()), Int(2)},
+		{"keys", NewArgs(syntax.Detached()), NewArray(Str("a"), Str("b"))},
+		{"values", NewArgs(syntax.Detached()), NewArray(Int(1), Int(2))},
+		{"pairs", NewArgs(syntax.Detached()), NewArray(NewArray(Str("a"), Int(1)), NewArray(Str("b"), Int(2)))},
+		{"at", NewArgs(syntax.Detached(), Str("b")), Int(2)},
+		{"at", &Args{Items: []Arg{{Value: Spanned[Value]{V: Str("c")}}, namedArg("default", Int(0))}}, Int(0)},
+	}
+	for _, tt := range tests {
+		got, err := callMethod(t, TypeDict, tt.method, dict, tt.args)
+		if err != nil || !Equal(got, tt.want) {
+			t.Errorf("%s = %v, %v, want %v", tt.method, got, err, tt.want)
+		}
+	}
+	if _, err := callMethod(t, TypeDict, "at", dict, NewArgs(syntax.Detached(), Str("c"))); err == nil {
+		t.Error("at with missing key: expected an error")
+	}
+}
+
+func TestMeasurementMethods(t *testing.T) {
+	inch := LengthValue{Length: Length{Points: 72}}
+	for method, want := range map[string]float64{"pt": 72, "mm": 25.4, "cm": 2.54, "inches": 1} {
+		got, err := callMethod(t, TypeLength, method, inch, NewArgs(syntax.Detached()))
+		if err != nil || !floatApprox(float64(got.(Float)), want, epsilon) {
+			t.Errorf("1in.%s() = %v, %v, want %v", method, got, err, want)
+		}
+	}
+	got, err := callMethod(t, TypeAngle, "deg", Numeric(90, syntax.UnitDeg), NewArgs(syntax.Detached()))
+	if err != nil || !floatApprox(float64(got.(Float)), 90, epsilon) {
+		t.Errorf("90deg.deg() = %v, %v", got, err)
+	}
+}
+
+func TestArgsMethods(t *testing.T) {
+	captured := ArgsValue{Args: &Args{Items: []Arg{
+		{Value: Spanned[Value]{V: Int(1)}},
+		namedArg("key", Str("a")),
+		{Value: Spanned[Value]{V: Int(2)}},
+		namedArg("key", Str("b")),
+	}}}
+	named := NewDict()
+	named.Set("key", Str("b"))
+
+	tests := []struct {
+		method string
+		args   *Args
+		want   Value
+	}{
+		{"pos", NewArgs(syntax.Detached()), NewArray(Int(1), Int(2))},
+		{"named", NewArgs(syntax.Detached()), named},
+		{"at", NewArgs(syntax.Detached(), Int(1)), Int(2)},
+		{"at", NewArgs(syntax.Detached(), Int(-2)), Int(1)},
+		{"at", NewArgs(syntax.Detached(), Str("key")), Str("b")},
+		{"at", &Args{Items: []Arg{{Value: Spanned[Value]{V: Int(2)}}, namedArg("default", NoneValue{})}}, NoneValue{}},
+	}
+	for _, tt := range tests {
+		got, err := callMethod(t, TypeArgs, tt.method, captured, tt.args)
+		if err != nil || !Equal(got, tt.want) {
+			t.Errorf("%s = %v, %v, want %v", tt.method, got, err, tt.want)
+		}
+	}
+	if _, err := callMethod(t, TypeArgs, "at", captured, NewArgs(syntax.Detached(), Str("other"))); err == nil {
+		t.Error("at with missing key: expected an error")
+	}
+
+	if got := Repr(captured); got != `arguments(1, key: "a", 2, key: "b")` {
+		t.Errorf("repr = %s", got)
+	}
+	if !Equal(captured, captured.Clone()) || Equal(captured, ArgsValue{Args: NewArgs(syntax.Detached(), Int(1))}) {
+		t.Error("arguments equality")
+	}
+}
@@ -39,6 +39,8 @@ func Pos(v Value) (Value, error) {
 		return x, nil
 	case Float:
 		return x, nil
+	case LengthValue, AngleValue, RatioValue, RelativeValue, FractionValue:
+		return x, nil
 	default:
 		return nil, mismatch("unary '+'", v, nil)
 	}
@@ -55,6 +57,9 @@ func Neg(v Value) (Value, error) {
 		return Int(-x), nil
 	case Float:
 		return Float(-x), nil
+	case LengthValue, AngleValue, RatioValue, RelativeValue, FractionValue:
+		result, _ := scaleMeasure(x, -1)
+		return result, nil
 	default:
 		return nil, mismatch("unary '-'", v, nil)
 	}
@@ -133,7 +138,10 @@ func Add(lhs, rhs Value) (Value, error) {
 			return addDurationToDatetime(b, a)
 		}
 	}
-	return nil, mismatch("'+'", lhs, rhs)
+	if result, ok := addMeasures(lhs, rhs); ok {
+		return result, nil
+	}
+	return nil, measureMismatch("'+'", lhs, rhs)
 }
 
 // Sub subtracts two values.
@@ -170,7 +178,12 @@ func Sub(lhs, rhs Value) (Value, error) {
 			return Duration(int64(a) - int64(b)), nil
 		}
 	}
-	return nil, mismatch("'-'", lhs, rhs)
+	if neg, ok := scaleMeasure(rhs, -1); ok {
+		if result, ok := addMeasures(lhs, neg); ok {
+			return result, nil
+		}
+	}
+	return nil, measureMismatch("'-'", lhs, rhs)
 }
 
 // Mul multiplies two values.
@@ -209,7 +222,10 @@ func Mul(lhs, rhs Value) (Value, error) {
 			return repeatArray(a, int64(b))
 		}
 	}
-	return nil, mismatch("'*'", lhs, rhs)
+	if result, ok := mulMeasures(lhs, rhs); ok {
+		return result, nil
+	}
+	return nil, measureMismatch("'*'", lhs, rhs)
 }
 
 // Div divides two values.
@@ -243,6 +259,9 @@ func Div(lhs, rhs Value) (Value, error) {
 			return Float(a / b), nil
 		}
 	}
+	if isMeasure(lhs) {
+		return divMeasures(lhs, rhs)
+	}
 	return nil, mismatch("'/'", lhs, rhs)
 }
 
@@ -418,6 +437,9 @@ func Equal(lhs, rhs Value) bool {
 	case Str:
 		b, ok := rhs.(Str)
 		return ok && a == b
+	case LabelValue:
+		b, ok := rhs.(LabelValue)
+		return ok && a == b
 	case *Array:
 		b, ok := rhs.(*Array)
 		if !ok || a.Len() != b.Len() {
@@ -450,6 +472,36 @@ func Equal(lhs, rhs Value) bool {
 	case Duration:
 		b, ok := rhs.(Duration)
 		return ok && a == b
+	case TypeValue:
+		b, ok := rhs.(TypeValue)
+		return ok && a.Inner == b.Inner
+	case ArgsValue:
+		b, ok := rhs.(ArgsValue)
+		return ok && argsEqual(a.Args, b.Args)
+	case FuncValue:
+		// Element functions are equal if they construct the same element,
+		// so that `it.func() == heading` holds.
+		b, ok := rhs.(FuncValue)
+		if !ok {
+			return false
+		}
+		if a.Func == b.Func {
+			return true
+		}
+		def := a.Func.Element()
+		return def != nil && def == b.Func.Element()
+	case AngleValue:
+		b, ok := rhs.(AngleValue)
+		return ok && a == b
+	case FractionValue:
+		b, ok := rhs.(FractionValue)
+		return ok && a == b
+	case LengthValue, RatioValue, RelativeValue:
+		// A length or ratio equals a relative length whose other part is
+		// zero.
+		x, _ := relativeParts(a)
+		y, ok := relativeParts(rhs)
+		return ok && x == y
 	}
 	return false
 }
@@ -513,6 +565,28 @@ func compare(lhs, rhs Value) (int, error) {
 		if ok {
 			return cmp.Compare(int64(a), int64(b)), nil
 		}
+	case AngleValue:
+		b, ok := rhs.(AngleValue)
+		if ok {
+			return cmp.Compare(a.Angle.Radians, b.Angle.Radians), nil
+		}
+	case FractionValue:
+		b, ok := rhs.(FractionValue)
+		if ok {
+			return cmp.Compare(a.Fraction.Value, b.Fraction.Value), nil
+		}
+	case LengthValue, RatioValue, RelativeValue:
+		x, _ := relativeParts(a)
+		if y, ok := relativeParts(rhs); ok {
+			// Relative lengths are only ordered when they differ in one
+			// part, as in `1pt < 2pt` or `50% + 1pt < 60% + 1pt`.
+			switch {
+			case x.Rel == y.Rel:
+				return cmp.Compare(x.Abs.Points, y.Abs.Points), nil
+			case x.Abs == y.Abs:
+				return cmp.Compare(x.Rel.Value, y.Rel.Value), nil
+			}
+		}
 	}
 	return 0, &OpError{
 		Message: fmt.Sprintf("cannot compare %s with %s", lhs.Type(), rhs.Type()),
@@ -646,11 +720,196 @@ func IsZero(v Value) bool {
 		return x == 0
 	case Float:
 		return x == 0
+	case LengthValue:
+		return x.Length.Points == 0
+	case AngleValue:
+		return x.Angle.Radians == 0
+	case RatioValue:
+		return x.Ratio.Value == 0
+	case RelativeValue:
+		return x.Relative.Abs.Points == 0 && x.Relative.Rel.Value == 0
+	case FractionValue:
+		return x.Fraction.Value == 0
 	default:
 		return false
 	}
 }
 
+// --- Measurement Arithmetic ---
+//
+// Lengths, angles, ratios, relative lengths, and fractions follow Typst's
+// coercion rules: a length plus a ratio is a relative length, a
+// measurement times a number or a ratio keeps its unit, and dividing two
+// measurements of the same kind yields a float.
+
+// isMeasure reports whether v is a number with a unit.
+func isMeasure(v Value) bool {
+	switch v.(type) {
+	case LengthValue, AngleValue, RatioValue, RelativeValue, FractionValue:
+		return true
+	}
+	return false
+}
+
+// number returns the value of an integer or float.
+func number(v Value) (float64, bool) {
+	switch x := v.(type) {
+	case Int:
+		return float64(x), true
+	case Float:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// relativeParts returns a length, ratio, or relative length as a relative
+// length.
+func relativeParts(v Value) (Relative, bool) {
+	switch x := v.(type) {
+	case LengthValue:
+		return Relative{Abs: x.Length}, true
+	case RatioValue:
+		return Relative{Rel: x.Ratio}, true
+	case RelativeValue:
+		return x.Relative, true
+	}
+	return Relative{}, false
+}
+
+// scaleMeasure multiplies a measurement by a factor, keeping its unit.
+func scaleMeasure(v Value, factor float64) (Value, bool) {
+	switch x := v.(type) {
+	case LengthValue:
+		return LengthValue{Length: Length{Points: x.Length.Points * factor}}, true
+	case AngleValue:
+		return AngleValue{Angle: Angle{Radians: x.Angle.Radians * factor}}, true
+	case RatioValue:
+		return RatioValue{Ratio: Ratio{Value: x.Ratio.Value * factor}}, true
+	case RelativeValue:
+		return RelativeValue{Relative: Relative{
+			Abs: Length{Points: x.Relative.Abs.Points * factor},
+			Rel: Ratio{Value: x.Relative.Rel.Value * factor},
+		}}, true
+	case FractionValue:
+		return FractionValue{Fraction: Fraction{Value: x.Fraction.Value * factor}}, true
+	}
+	return nil, false
+}
+
+// addMeasures adds two measurements. Lengths and ratios add up to relative
+// lengths.
+func addMeasures(lhs, rhs Value) (Value, bool) {
+	switch a := lhs.(type) {
+	case AngleValue:
+		if b, ok := rhs.(AngleValue); ok {
+			return AngleValue{Angle: Angle{Radians: a.Angle.Radians + b.Angle.Radians}}, true
+		}
+		return nil, false
+	case FractionValue:
+		if b, ok := rhs.(FractionValue); ok {
+			return FractionValue{Fraction: Fraction{Value: a.Fraction.Value + b.Fraction.Value}}, true
+		}
+		return nil, false
+	}
+	x, ok := relativeParts(lhs)
+	if !ok {
+		return nil, false
+	}
+	y, ok := relativeParts(rhs)
+	if !ok {
+		return nil, false
+	}
+	sum := Relative{
+		Abs: Length{Points: x.Abs.Points + y.Abs.Points},
+		Rel: Ratio{Value: x.Rel.Value + y.Rel.Value},
+	}
+	switch {
+	case isType[LengthValue](lhs) && isType[LengthValue](rhs):
+		return LengthValue{Length: sum.Abs}, true
+	case isType[RatioValue](lhs) && isType[RatioValue](rhs):
+		return RatioValue{Ratio: sum.Rel}, true
+	}
+	return RelativeValue{Relative: sum}, true
+}
+
+// mulMeasures multiplies a measurement by a number or a ratio, in either
+// order.
+func mulMeasures(lhs, rhs Value) (Value, bool) {
+	if f, ok := number(rhs); ok {
+		return scaleMeasure(lhs, f)
+	}
+	if f, ok := number(lhs); ok {
+		return scaleMeasure(rhs, f)
+	}
+	if r, ok := rhs.(RatioValue); ok {
+		return scaleMeasure(lhs, r.Ratio.Value)
+	}
+	if r, ok := lhs.(RatioValue); ok {
+		return scaleMeasure(rhs, r.Ratio.Value)
+	}
+	return nil, false
+}
+
+// divMeasures divides a measurement by a number, keeping its unit, or by a
+// measurement of the same kind, yielding a float.
+func divMeasures(lhs, rhs Value) (Value, error) {
+	if IsZero(rhs) {
+		return nil, &OpError{Message: "cannot divide by zero"}
+	}
+	if f, ok := number(rhs); ok {
+		result, _ := scaleMeasure(lhs, 1/f)
+		return result, nil
+	}
+	switch a := lhs.(type) {
+	case AngleValue:
+		if b, ok := rhs.(AngleValue); ok {
+			return Float(a.Angle.Radians / b.Angle.Radians), nil
+		}
+	case FractionValue:
+		if b, ok := rhs.(FractionValue); ok {
+			return Float(a.Fraction.Value / b.Fraction.Value), nil
+		}
+	case LengthValue, RatioValue, RelativeValue:
+		x, _ := relativeParts(a)
+		y, ok := relativeParts(rhs)
+		if !ok {
+			break
+		}
+		switch {
+		case x.Rel.Value == 0 && y.Rel.Value == 0:
+			return Float(x.Abs.Points / y.Abs.Points), nil
+		case x.Abs.Points == 0 && y.Abs.Points == 0:
+			return Float(x.Rel.Value / y.Rel.Value), nil
+		}
+		return nil, &OpError{
+			Message: fmt.Sprintf("cannot divide %s by %s", lhs.Type(), rhs.Type()),
+			Hint:    "only the absolute or the relative parts of both sides may be non-zero",
+		}
+	}
+	return nil, measureMismatch("'/'", lhs, rhs)
+}
+
+// measureMismatch creates a type mismatch error with a hint for mixing
+// numbers with and without units.
+func measureMismatch(op string, lhs, rhs Value) *OpError {
+	err := mismatch(op, lhs, rhs)
+	_, lhsNum := number(lhs)
+	_, rhsNum := number(rhs)
+	switch {
+	case (op == "'+'" || op == "'-'") && (lhsNum && isMeasure(rhs) || isMeasure(lhs) && rhsNum):
+		err.Hint = "add a unit to the number, like `2pt` or `2deg`"
+	case isMeasure(lhs) && isMeasure(rhs) && lhs.Type() != rhs.Type():
+		err.Hint = fmt.Sprintf("%s and %s have incompatible units", lhs.Type(), rhs.Type())
+	}
+	return err
+}
+
+// isType reports whether v has the dynamic type T.
+func isType[T Value](v Value) bool {
+	_, ok := v.(T)
+	return ok
+}
+
 // --- Datetime/Duration Arithmetic Helpers ---
 
 // addDurationToDatetime adds a duration to a datetime, returning a new datetime.
@@ -598,6 +598,128 @@ func TestDictMerge(t *testing.T) {
 	}
 }
 
+// --- Measurement Tests ---
+
+func TestMeasureArithmetic(t *testing.T) {
+	pt := func(v float64) Value { return LengthValue{Length: Length{Points: v}} }
+	deg := func(v float64) Value { return AngleValue{Angle: Angle{Radians: v * math.Pi / 180}} }
+	pct := func(v float64) Value { return RatioValue{Ratio: Ratio{Value: v / 100}} }
+	fr := func(v float64) Value { return FractionValue{Fraction: Fraction{Value: v}} }
+	rel := func(abs, ratio float64) Value {
+		return RelativeValue{Relative: Relative{Abs: Length{Points: abs}, Rel: Ratio{Value: ratio / 100}}}
+	}
+
+	tests := []struct {
+		name    string
+		op      func(Value, Value) (Value, error)
+		lhs     Value
+		rhs     Value
+		want    Value
+		wantErr bool
+	}{
+		{"length + length", Add, pt(1), pt(2), pt(3), false},
+		{"ratio + length", Add, pct(50), pt(2), rel(2, 50), false},
+		{"relative + ratio", Add, rel(2, 50), pct(25), rel(2, 75), false},
+		{"angle + angle", Add, deg(30), deg(60), deg(90), false},
+		{"fraction + fraction", Add, fr(1), fr(2), fr(3), false},
+		{"length - ratio", Sub, pt(2), pct(50), rel(2, -50), false},
+		{"length + int", Add, pt(1), Int(2), nil, true},
+		{"length + angle", Add, pt(1), deg(2), nil, true},
+		{"fraction + length", Add, fr(1), pt(2), nil, true},
+		{"int * angle", Mul, Int(2), deg(30), deg(60), false},
+		{"fraction * int", Mul, fr(1), Int(3), fr(3), false},
+		{"length * ratio", Mul, pt(10), pct(50), pt(5), false},
+		{"ratio * ratio", Mul, pct(50), pct(50), pct(25), false},
+		{"relative * float", Mul, rel(2, 50), Float(2), rel(4, 100), false},
+		{"length * length", Mul, pt(1), pt(2), nil, true},
+		{"length / int", Div, pt(6), Int(2), pt(3), false},
+		{"length / length", Div, pt(6), pt(2), Float(3), false},
+		{"angle / angle", Div, deg(90), deg(45), Float(2), false},
+		{"fraction / fraction", Div, fr(1), fr(4), Float(0.25), false},
+		{"ratio / relative", Div, pct(50), rel(0, 25), Float(2), false},
+		{"relative / relative", Div, rel(1, 50), rel(2, 25), nil, true},
+		{"length / zero", Div, pt(1), Int(0), nil, true},
+		{"length / zero length", Div, pt(1), pt(0), nil, true},
+		{"int / length", Div, Int(1), pt(1), nil, true},
+		{"length / angle", Div, pt(1), deg(1), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(tt.lhs, tt.rhs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !approxValue(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeasureUnary(t *testing.T) {
+	got, err := Neg(RelativeValue{Relative: Relative{Abs: Length{Points: 1}, Rel: Ratio{Value: 0.5}}})
+	want := RelativeValue{Relative: Relative{Abs: Length{Points: -1}, Rel: Ratio{Value: -0.5}}}
+	if err != nil || !Equal(got, want) {
+		t.Errorf("Neg() = %v, %v, want %v", got, err, want)
+	}
+	if got, err := Pos(FractionValue{Fraction: Fraction{Value: 1}}); err != nil || !Equal(got, FractionValue{Fraction: Fraction{Value: 1}}) {
+		t.Errorf("Pos() = %v, %v", got, err)
+	}
+}
+
+func TestMeasureCompare(t *testing.T) {
+	pt := func(v float64) Value { return LengthValue{Length: Length{Points: v}} }
+	pct := func(v float64) Value { return RatioValue{Ratio: Ratio{Value: v / 100}} }
+	rel := func(abs, ratio float64) Value {
+		return RelativeValue{Relative: Relative{Abs: Length{Points: abs}, Rel: Ratio{Value: ratio / 100}}}
+	}
+
+	if !Equal(pt(1), rel(1, 0)) || !Equal(pct(50), rel(0, 50)) || Equal(pt(1), pct(1)) {
+		t.Error("lengths and ratios should equal relative lengths with a zero part")
+	}
+	if Equal(AngleValue{Angle: Angle{Radians: 1}}, Float(1)) {
+		t.Error("an angle should not equal a float")
+	}
+
+	tests := []struct {
+		name    string
+		lhs     Value
+		rhs     Value
+		want    bool
+		wantErr bool
+	}{
+		{"length < length", pt(1), pt(2), true, false},
+		{"ratio < relative", pct(50), rel(0, 60), true, false},
+		{"relative < relative", rel(1, 50), rel(2, 50), true, false},
+		{"angle < angle", AngleValue{Angle: Angle{Radians: 2}}, AngleValue{Angle: Angle{Radians: 1}}, false, false},
+		{"fraction < fraction", FractionValue{Fraction: Fraction{Value: 1}}, FractionValue{Fraction: Fraction{Value: 2}}, true, false},
+		{"length < ratio", pt(1), pct(50), false, true},
+		{"length < angle", pt(1), AngleValue{Angle: Angle{Radians: 1}}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lt(tt.lhs, tt.rhs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Lt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != Bool(tt.want) {
+				t.Errorf("Lt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeasureMismatchHint(t *testing.T) {
+	_, err := Add(LengthValue{Length: Length{Points: 1}}, Int(2))
+	if opErr, ok := err.(*OpError); !ok || opErr.Hint == "" {
+		t.Errorf("Add() error = %v, want a hint", err)
+	}
+}
+
 // --- Helper function ---
 
 func dictWithKeys(keys ...string) *Dict {
@@ -607,3 +729,16 @@ func dictWithKeys(keys ...string) *Dict {
 	}
 	return d
 }
+
+// approxValue is like Equal, but allows rounding errors in measurements.
+func approxValue(a, b Value) bool {
+	if x, ok := a.(AngleValue); ok {
+		y, ok := b.(AngleValue)
+		return ok && math.Abs(x.Angle.Radians-y.Angle.Radians) < 1e-9
+	}
+	if x, ok := a.(Float); ok {
+		y, ok := b.(Float)
+		return ok && math.Abs(float64(x-y)) < 1e-9
+	}
+	return Equal(a, b)
+}
@@ -0,0 +1,372 @@
+// Plugin type for Typst.
+// Translated from typst-library/src/foundations/plugin.rs
+
+package foundations
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/boergens/gotypst/memo"
+	"github.com/boergens/gotypst/syntax"
+	"github.com/boergens/gotypst/wasm"
+)
+
+// The host functions of the WebAssembly minimal protocol, imported by
+// plugins from the "typst_env" module.
+const (
+	pluginEnv        = "typst_env"
+	pluginSendResult = "wasm_minimal_protocol_send_result_to_host"
+	pluginWriteArgs  = "wasm_minimal_protocol_write_args_to_buffer"
+)
+
+// Plugin is a loaded WebAssembly module that follows the WebAssembly
+// minimal protocol. Every call runs on a copy of the base instance, so
+// that calls are pure and cannot observe each other.
+//
+// A plugin function takes the lengths of its byte arguments as 32-bit
+// integers. It fetches the arguments by calling write_args_to_buffer, hands
+// its output to send_result_to_host, and returns 0 on success or 1 if the
+// output is an error message.
+//
+// Matches Rust: pub struct Plugin
+type Plugin struct {
+	module *wasm.Module
+	base   *wasm.Instance
+}
+
+// pluginCall is the state of a single call, reached by the host functions
+// through the data of the instance.
+type pluginCall struct {
+	args   [][]byte
+	output []byte
+}
+
+// plugins caches loaded plugins by the hash of their bytes.
+var plugins = memo.NewCache[memo.Hash, *Plugin]()
+
+// LoadPlugin loads a plugin from the bytes of a WebAssembly module. The
+// exports of the module are checked against the protocol.
+//
+// Matches Rust: Plugin::new
+func LoadPlugin(data []byte) (*Plugin, error) {
+	key := memo.HashBytes(data)
+	if p, ok := plugins.Get(key); ok {
+		return p, nil
+	}
+
+	module, err := wasm.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load WebAssembly module (%v)", err)
+	}
+	for _, export := range module.Exports() {
+		if export.Kind != wasm.ExternFunc {
+			continue
+		}
+		typ, _ := module.ExportedFunc(export.Name)
+		for _, param := range typ.Params {
+			if param != wasm.I32 {
+				return nil, fmt.Errorf("plugin function `%s` has a parameter that is not a 32-bit integer", export.Name)
+			}
+		}
+		if len(typ.Results) != 1 || typ.Results[0] != wasm.I32 {
+			return nil, fmt.Errorf("plugin function `%s` does not return exactly one 32-bit integer", export.Name)
+		}
+	}
+
+	base, err := wasm.Instantiate(module, map[string]map[string]wasm.HostFunc{pluginEnv: {
+		pluginSendResult: {
+			Type: wasm.FuncType{Params: []wasm.ValueType{wasm.I32, wasm.I32}},
+			Func: pluginSendResultToHost,
+		},
+		pluginWriteArgs: {
+			Type: wasm.FuncType{Params: []wasm.ValueType{wasm.I32}},
+			Func: pluginWriteArgsToBuffer,
+		},
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load WebAssembly module (%v)", err)
+	}
+
+	p := &Plugin{module: module, base: base}
+	plugins.Put(key, p)
+	return p, nil
+}
+
+// pluginSendResultToHost copies the output of a plugin function out of its
+// memory.
+func pluginSendResultToHost(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	call := inst.Data.(*pluginCall)
+	ptr, n := uint64(uint32(args[0])), uint64(uint32(args[1]))
+	memory := inst.Memory()
+	if ptr+n > uint64(len(memory)) {
+		return nil, fmt.Errorf("plugin sent its result out of bounds of its memory")
+	}
+	call.output = append([]byte(nil), memory[ptr:ptr+n]...)
+	return nil, nil
+}
+
+// pluginWriteArgsToBuffer copies the concatenated arguments of a plugin
+// function into its memory.
+func pluginWriteArgsToBuffer(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	call := inst.Data.(*pluginCall)
+	ptr := uint64(uint32(args[0]))
+	memory := inst.Memory()
+	for _, arg := range call.args {
+		if ptr+uint64(len(arg)) > uint64(len(memory)) {
+			return nil, fmt.Errorf("plugin requested its arguments out of bounds of its memory")
+		}
+		ptr += uint64(copy(memory[ptr:], arg))
+	}
+	call.args = nil
+	return nil, nil
+}
+
+// Functions returns the names of the functions exported by the plugin.
+func (p *Plugin) Functions() []string {
+	var names []string
+	for _, export := range p.module.Exports() {
+		if export.Kind == wasm.ExternFunc {
+			names = append(names, export.Name)
+		}
+	}
+	return names
+}
+
+// Call calls a function of the plugin and returns its output.
+//
+// Matches Rust: Plugin::call
+func (p *Plugin) Call(name string, args [][]byte) ([]byte, error) {
+	output, _, err := p.run(name, args)
+	return output, err
+}
+
+// Transition calls a function of the plugin and returns a plugin whose
+// state is the one the call left behind. The output of the call is
+// discarded.
+//
+// Matches Rust: PluginFunc::transition
+func (p *Plugin) Transition(name string, args [][]byte) (*Plugin, error) {
+	_, inst, err := p.run(name, args)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{module: p.module, base: inst}, nil
+}
+
+// run calls a function on a copy of the base instance and returns its
+// output and the instance in its state after the call.
+func (p *Plugin) run(name string, args [][]byte) ([]byte, *wasm.Instance, error) {
+	typ, ok := p.module.ExportedFunc(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("plugin does not contain a function called %s", name)
+	}
+	if len(typ.Params) != len(args) {
+		return nil, nil, fmt.Errorf("plugin function takes %d argument%s, but %d were given",
+			len(typ.Params), plural(len(typ.Params)), len(args))
+	}
+
+	lengths := make([]uint64, len(args))
+	for i, arg := range args {
+		if uint64(len(arg)) > 1<<32-1 {
+			return nil, nil, fmt.Errorf("argument %d of plugin function is too large", i+1)
+		}
+		lengths[i] = uint64(len(arg))
+	}
+
+	call := &pluginCall{args: args}
+	inst := p.base.Clone()
+	inst.Data = call
+	results, err := inst.Call(name, lengths...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("plugin panicked: %v", err)
+	}
+
+	switch int32(results[0]) {
+	case 0:
+		return call.output, inst, nil
+	case 1:
+		if !utf8.Valid(call.output) {
+			return nil, nil, fmt.Errorf("plugin errored, but did not return a valid error message")
+		}
+		return nil, nil, fmt.Errorf("plugin errored with: %s", call.output)
+	default:
+		return nil, nil, fmt.Errorf("plugin did not respect the protocol")
+	}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// WasmFunc represents a function exported by a plugin.
+// Matches Rust: Repr::Plugin
+type WasmFunc struct {
+	// Plugin is the plugin the function belongs to.
+	Plugin *Plugin
+	// Name is the name of the exported function.
+	Name string
+}
+
+func (WasmFunc) isFuncRepr() {}
+
+// call calls the plugin function with byte arguments.
+func (f WasmFunc) call(args *Args) (Value, error) {
+	bytes, err := pluginArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	output, err := f.Plugin.Call(f.Name, bytes)
+	if err != nil {
+		return nil, NewSourceError(args.Span, err.Error())
+	}
+	return BytesValue(output), nil
+}
+
+// pluginArgs takes the remaining arguments, which must all be bytes.
+func pluginArgs(args *Args) ([][]byte, error) {
+	var bytes [][]byte
+	for _, arg := range args.All() {
+		b, ok := arg.V.(BytesValue)
+		if !ok {
+			return nil, &TypeMismatchError{Expected: "bytes", Got: arg.V.Type().String(), Span: arg.Span}
+		}
+		bytes = append(bytes, b)
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
+
+// Module returns a module that holds a function for every function the
+// plugin exports.
+func (p *Plugin) Module() *Module {
+	scope := NewScope()
+	for _, name := range p.Functions() {
+		fname := name
+		scope.Define(name, FuncValue{Func: &Func{
+			Name: &fname,
+			Span: syntax.Detached(),
+			Repr: WasmFunc{Plugin: p, Name: name},
+		}}, syntax.Detached())
+	}
+	return &Module{Name: "plugin", Scope: scope}
+}
+
+// PluginFunc creates the plugin function, which loads a WebAssembly module
+// from a path or from bytes and returns a module with its functions. Its
+// scope holds `transition`.
+//
+// Matches Rust: pub fn plugin in foundations/plugin.rs
+func PluginFunc() *Func {
+	name := "plugin"
+	scope := NewScope()
+	scope.Define("transition", FuncValue{Func: pluginTransitionFunc()}, syntax.Detached())
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: pluginNative,
+			Info: &FuncInfo{
+				Name:   "plugin",
+				Params: []ParamInfo{{Name: "source", Type: TypeStr}},
+			},
+			Scope: scope,
+		},
+	}
+}
+
+func pluginNative(engine Engine, context Context, args *Args) (Value, error) {
+	source, err := args.Expect("source")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch v := source.V.(type) {
+	case Str:
+		if data, err = loadPluginFile(engine.World, source.Span, string(v)); err != nil {
+			return nil, err
+		}
+	case BytesValue:
+		data = v
+	default:
+		return nil, &TypeMismatchError{Expected: "string or bytes", Got: v.Type().String(), Field: "source", Span: source.Span}
+	}
+
+	p, err := LoadPlugin(data)
+	if err != nil {
+		return nil, NewSourceError(source.Span, err.Error())
+	}
+	return ModuleValue{Module: p.Module()}, nil
+}
+
+// loadPluginFile reads a plugin relative to the file the span is in.
+func loadPluginFile(world World, span syntax.Span, path string) ([]byte, error) {
+	id := span.Id()
+	if world == nil || id == nil {
+		return nil, NewSourceError(span, "cannot access file system from here")
+	}
+	file, err := id.Join(path)
+	if err != nil {
+		return nil, NewSourceError(span, fmt.Sprintf("invalid path %q: %v", path, err))
+	}
+	data, err := world.File(file)
+	if err != nil {
+		return nil, NewSourceError(span, fmt.Sprintf("failed to load plugin (%v)", err))
+	}
+	return data, nil
+}
+
+// pluginTransitionFunc creates plugin.transition, which calls a plugin
+// function and returns the plugin in the state the call left behind.
+//
+// Matches Rust: pub fn transition in foundations/plugin.rs
+func pluginTransitionFunc() *Func {
+	name := "transition"
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: pluginTransitionNative,
+			Info: &FuncInfo{
+				Name: "transition",
+				Params: []ParamInfo{
+					{Name: "func", Type: TypeFunc},
+					{Name: "arguments", Type: TypeBytes, Variadic: true},
+				},
+			},
+		},
+	}
+}
+
+func pluginTransitionNative(engine Engine, context Context, args *Args) (Value, error) {
+	fn, err := args.Expect("func")
+	if err != nil {
+		return nil, err
+	}
+	fv, ok := fn.V.(FuncValue)
+	if !ok {
+		return nil, &TypeMismatchError{Expected: "function", Got: fn.V.Type().String(), Field: "func", Span: fn.Span}
+	}
+	wasmFunc, ok := fv.Func.Repr.(WasmFunc)
+	if !ok {
+		return nil, NewSourceError(fn.Span, "expected plugin function")
+	}
+	bytes, err := pluginArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	p, err := wasmFunc.Plugin.Transition(wasmFunc.Name, bytes)
+	if err != nil {
+		return nil, NewSourceError(args.Span, err.Error())
+	}
+	return ModuleValue{Module: p.Module()}, nil
+}
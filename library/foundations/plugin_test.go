@@ -0,0 +1,182 @@
+package foundations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// testPlugin assembles a plugin with three functions: echo returns its
+// argument, fail errors with "oops", and count increments a global and
+// returns its value as a single byte.
+func testPlugin() []byte {
+	leb := func(v int) []byte {
+		var out []byte
+		for v >= 0x80 {
+			out = append(out, byte(v)|0x80)
+			v >>= 7
+		}
+		return append(out, byte(v))
+	}
+	cat := func(parts ...[]byte) []byte {
+		var out []byte
+		for _, p := range parts {
+			out = append(out, p...)
+		}
+		return out
+	}
+	vec := func(items ...[]byte) []byte { return cat(leb(len(items)), cat(items...)) }
+	str := func(s string) []byte { return cat(leb(len(s)), []byte(s)) }
+	section := func(id byte, items ...[]byte) []byte {
+		body := vec(items...)
+		return cat([]byte{id}, leb(len(body)), body)
+	}
+	code := func(instrs ...byte) []byte {
+		body := append([]byte{0}, instrs...)
+		body = append(body, 0x0B)
+		return cat(leb(len(body)), body)
+	}
+	const (
+		i32        = 0x7F
+		localGet   = 0x20
+		globalGet  = 0x23
+		globalSet  = 0x24
+		i32Store8  = 0x3A
+		i32Const   = 0x41
+		i32Add     = 0x6A
+		call       = 0x10
+		sendResult = 0
+		writeArgs  = 1
+	)
+
+	return cat(
+		[]byte("\x00asm\x01\x00\x00\x00"),
+		section(1,
+			[]byte{0x60, 2, i32, i32, 0},
+			[]byte{0x60, 1, i32, 0},
+			[]byte{0x60, 1, i32, 1, i32},
+			[]byte{0x60, 0, 1, i32},
+		),
+		section(2,
+			cat(str("typst_env"), str("wasm_minimal_protocol_send_result_to_host"), []byte{0, 0}),
+			cat(str("typst_env"), str("wasm_minimal_protocol_write_args_to_buffer"), []byte{0, 1}),
+		),
+		section(3, []byte{2}, []byte{3}, []byte{3}),
+		section(5, []byte{0, 1}),
+		section(6, []byte{i32, 1, i32Const, 0, 0x0B}),
+		section(7,
+			cat(str("memory"), []byte{2, 0}),
+			cat(str("echo"), []byte{0, 2}),
+			cat(str("fail"), []byte{0, 3}),
+			cat(str("count"), []byte{0, 4}),
+		),
+		section(10,
+			code(i32Const, 0, call, writeArgs,
+				i32Const, 0, localGet, 0, call, sendResult,
+				i32Const, 0),
+			code(i32Const, 0x80|100, 0, i32Const, 4, call, sendResult,
+				i32Const, 1),
+			code(globalGet, 0, i32Const, 1, i32Add, globalSet, 0,
+				i32Const, 0, globalGet, 0, i32Store8, 0, 0,
+				i32Const, 0, i32Const, 1, call, sendResult,
+				i32Const, 0),
+		),
+		section(11, cat([]byte{0, i32Const, 0x80 | 100, 0, 0x0B}, str("oops"))),
+	)
+}
+
+func callFunc(t *testing.T, f *Func, values ...Value) (Value, error) {
+	t.Helper()
+	return f.Call(&Engine{}, &Context{}, NewArgs(syntax.Detached(), values...))
+}
+
+func loadTestPlugin(t *testing.T) *Module {
+	t.Helper()
+	v, err := callFunc(t, PluginFunc(), BytesValue(testPlugin()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.(ModuleValue).Module
+}
+
+func pluginFunction(t *testing.T, m *Module, name string) *Func {
+	t.Helper()
+	b := m.Scope.Get(name)
+	if b == nil {
+		t.Fatalf("plugin has no function %s", name)
+	}
+	return b.Read().(FuncValue).Func
+}
+
+func TestPluginCall(t *testing.T) {
+	m := loadTestPlugin(t)
+
+	out, err := callFunc(t, pluginFunction(t, m, "echo"), BytesValue("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out.(BytesValue)) != "hello" {
+		t.Errorf("echo = %q, want hello", out)
+	}
+
+	if _, err := callFunc(t, pluginFunction(t, m, "fail")); err == nil || !strings.Contains(err.Error(), "plugin errored with: oops") {
+		t.Errorf("fail: err = %v", err)
+	}
+	if _, err := callFunc(t, pluginFunction(t, m, "echo")); err == nil || !strings.Contains(err.Error(), "takes 1 argument, but 0 were given") {
+		t.Errorf("echo without arguments: err = %v", err)
+	}
+	if _, err := callFunc(t, pluginFunction(t, m, "echo"), Str("hello")); err == nil {
+		t.Error("echo accepted a string")
+	}
+}
+
+func TestPluginTransition(t *testing.T) {
+	m := loadTestPlugin(t)
+	count := pluginFunction(t, m, "count")
+
+	// Calls do not change the state of the plugin.
+	for range 2 {
+		out, err := callFunc(t, count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := out.(BytesValue); len(got) != 1 || got[0] != 1 {
+			t.Errorf("count = %v, want [1]", got)
+		}
+	}
+
+	transition := PluginFunc().Scope().Get("transition").Read().(FuncValue).Func
+	v, err := callFunc(t, transition, FuncValue{Func: count})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := v.(ModuleValue).Module
+	out, err := callFunc(t, pluginFunction(t, next, "count"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.(BytesValue); len(got) != 1 || got[0] != 2 {
+		t.Errorf("count after transition = %v, want [2]", got)
+	}
+
+	if _, err := callFunc(t, transition, FuncValue{Func: PluginFunc()}); err == nil {
+		t.Error("transition accepted a native function")
+	}
+}
+
+func TestLoadPluginErrors(t *testing.T) {
+	if _, err := LoadPlugin([]byte("not wasm")); err == nil || !strings.HasPrefix(err.Error(), "failed to load WebAssembly module") {
+		t.Errorf("err = %v", err)
+	}
+
+	// A function returning nothing violates the protocol.
+	data := []byte("\x00asm\x01\x00\x00\x00" +
+		"\x01\x04\x01\x60\x00\x00" +
+		"\x03\x02\x01\x00" +
+		"\x07\x07\x01\x03run\x00\x00" +
+		"\x0a\x04\x01\x02\x00\x0b")
+	if _, err := LoadPlugin(data); err == nil || err.Error() != "plugin function `run` does not return exactly one 32-bit integer" {
+		t.Errorf("err = %v", err)
+	}
+}
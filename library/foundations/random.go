@@ -0,0 +1,64 @@
+package foundations
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// Rng is a deterministic pseudo-random number generator. The same seed
+// always produces the same sequence on every platform, so documents that
+// use randomness still compile reproducibly. There is deliberately no way
+// to seed it from the clock or the operating system.
+//
+// It implements SplitMix64.
+type Rng struct {
+	state uint64
+}
+
+// NewRng creates a generator from a seed.
+func NewRng(seed uint64) *Rng {
+	return &Rng{state: seed}
+}
+
+// CastSeed casts a seed for random numbers: an integer, or a string that
+// is hashed to one.
+func CastSeed(v Value) (uint64, bool) {
+	switch s := v.(type) {
+	case Int:
+		return uint64(s), true
+	case Str:
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		return h.Sum64(), true
+	}
+	return 0, false
+}
+
+// Uint64 returns the next number of the sequence.
+func (r *Rng) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a number in [0, 1).
+func (r *Rng) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Uint64N returns a number in [0, n) without modulo bias. It panics if n
+// is zero.
+func (r *Rng) Uint64N(n uint64) uint64 {
+	// Lemire's method: reject the few products whose low half falls into
+	// the biased range.
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+	return hi
+}
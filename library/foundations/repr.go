@@ -0,0 +1,254 @@
+// Value representations for Typst.
+// Translated from typst-library/src/foundations/repr.rs
+
+package foundations
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Repr returns the debug representation of a value. For most values, this
+// is the code that produces the value.
+//
+// Matches Rust: trait Repr in foundations/repr.rs
+func Repr(v Value) string {
+	switch v := v.(type) {
+	case nil, NoneValue:
+		return "none"
+	case AutoValue:
+		return "auto"
+	case Bool:
+		return strconv.FormatBool(bool(v))
+	case Int:
+		return strconv.FormatInt(int64(v), 10)
+	case Float:
+		return reprFloat(float64(v))
+	case LengthValue:
+		return formatFloat(v.Length.Points, "pt")
+	case AngleValue:
+		return formatFloat(v.Angle.Radians*180/math.Pi, "deg")
+	case RatioValue:
+		return formatFloat(v.Ratio.Value*100, "%")
+	case RelativeValue:
+		switch {
+		case v.Relative.Rel.Value == 0:
+			return formatFloat(v.Relative.Abs.Points, "pt")
+		case v.Relative.Abs.Points == 0:
+			return formatFloat(v.Relative.Rel.Value*100, "%")
+		}
+		return formatFloat(v.Relative.Rel.Value*100, "%") + " + " + formatFloat(v.Relative.Abs.Points, "pt")
+	case FractionValue:
+		return formatFloat(v.Fraction.Value, "fr")
+	case Str:
+		return reprStr(string(v))
+	case BytesValue:
+		return fmt.Sprintf("bytes(%d)", len(v))
+	case LabelValue:
+		return "<" + string(v) + ">"
+	case DecimalValue:
+		if v.Value == nil {
+			return "decimal(\"0\")"
+		}
+		return "decimal(" + reprStr(strings.TrimRight(strings.TrimRight(v.Value.FloatString(28), "0"), ".")) + ")"
+	case VersionValue:
+		return fmt.Sprintf("version(%d, %d, %d)", v.Major, v.Minor, v.Patch)
+	case SymbolValue:
+		return "symbol(" + reprStr(v.Get()) + ")"
+	case TypeValue:
+		return v.Inner.Ident()
+	case ModuleValue:
+		if v.Module == nil {
+			return "<module>"
+		}
+		return "<module " + v.Module.Name + ">"
+	case FuncValue:
+		if v.Func != nil && v.Func.Name != nil {
+			return *v.Func.Name
+		}
+		return "(..) => .."
+	case ContentValue:
+		return reprContent(v.Content)
+	case *Array:
+		return reprArray(v)
+	case *Dict:
+		return reprDict(v)
+	case ArgsValue:
+		return reprArgs(v.Args)
+	case LocationValue:
+		return "location(..)"
+	case SelectorValue:
+		return reprSelector(v.Selector)
+	case CounterValue:
+		return "counter(" + reprCounterKey(v.Key) + ")"
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return "<" + v.Type().String() + ">"
+	}
+}
+
+// ReprFunc creates the repr function, which returns the string
+// representation of a value.
+func ReprFunc() *Func {
+	name := "repr"
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: func(engine Engine, context Context, args *Args) (Value, error) {
+				value, err := args.Expect("value")
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				return Str(Repr(value.V)), nil
+			},
+			Info: &FuncInfo{
+				Name:   "repr",
+				Params: []ParamInfo{{Name: "value", Type: TypeDyn}},
+			},
+		},
+	}
+}
+
+// ReprElement is implemented by elements that are represented as markup
+// instead of a call of their function, like text.
+type ReprElement interface {
+	ContentElement
+	// Repr returns the element's representation.
+	Repr() string
+}
+
+// reprContent returns the representation of content: a call of the
+// element's function with its fields, like `heading(level: 1, body: [A])`.
+// Fields appear in the order of their definition, so the representation
+// is stable across runs. Content with several elements is a sequence.
+// Matches Rust: impl Repr for Content
+func reprContent(c Content) string {
+	if len(c.Elements) == 0 {
+		return "[]"
+	}
+	elem := ContentElem(c)
+	if r, ok := elem.(ReprElement); ok {
+		return r.Repr()
+	}
+	var parts []string
+	if seq, ok := elem.(*SequenceElem); ok {
+		for _, child := range seq.Children {
+			parts = append(parts, reprContent(child))
+		}
+	} else {
+		fields := ElementFields(elem)
+		for _, key := range fields.Keys() {
+			value, _ := fields.Get(key)
+			parts = append(parts, key+": "+Repr(value))
+		}
+	}
+	return ElementName(elem) + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// reprArgs returns the representation of arguments, like
+// `arguments(1, key: 2)`.
+func reprArgs(args *Args) string {
+	var parts []string
+	if args != nil {
+		for _, item := range args.Items {
+			if item.Name != nil {
+				parts = append(parts, string(*item.Name)+": "+Repr(item.Value.V))
+			} else {
+				parts = append(parts, Repr(item.Value.V))
+			}
+		}
+	}
+	return "arguments(" + strings.Join(parts, ", ") + ")"
+}
+
+// reprArray returns the representation of an array. A single element is
+// followed by a trailing comma so that it is not read as a parenthesized
+// expression.
+func reprArray(a *Array) string {
+	items := make([]string, 0, a.Len())
+	for _, item := range a.items {
+		items = append(items, Repr(item))
+	}
+	if len(items) == 1 {
+		return "(" + items[0] + ",)"
+	}
+	return "(" + strings.Join(items, ", ") + ")"
+}
+
+// reprDict returns the representation of a dictionary. Keys that are not
+// valid identifiers are quoted.
+func reprDict(d *Dict) string {
+	if d.Len() == 0 {
+		return "(:)"
+	}
+	pairs := make([]string, 0, d.Len())
+	for i, key := range d.keys {
+		name := key
+		if !syntax.IsIdent(key) {
+			name = reprStr(key)
+		}
+		pairs = append(pairs, name+": "+Repr(d.values[i]))
+	}
+	return "(" + strings.Join(pairs, ", ") + ")"
+}
+
+// reprStr returns a string in quotes with special characters escaped.
+func reprStr(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, c)
+			} else {
+				b.WriteRune(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// reprFloat returns the representation of a float. Unlike integers,
+// floats always have a decimal point so that the two can be told apart.
+func reprFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "float.nan"
+	case math.IsInf(f, 1):
+		return "float.inf"
+	case math.IsInf(f, -1):
+		return "-float.inf"
+	}
+	s := formatFloat(f, "")
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// formatFloat formats a number with a unit, using the shortest
+// representation that round-trips.
+func formatFloat(f float64, unit string) string {
+	return strconv.FormatFloat(f, 'f', -1, 64) + unit
+}
@@ -0,0 +1,94 @@
+package foundations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestRepr(t *testing.T) {
+	dict := NewDict()
+	dict.Set("a", Int(1))
+	dict.Set("two words", Str("x"))
+
+	tests := []struct {
+		name  string
+		value Value
+		want  string
+	}{
+		{"none", None, "none"},
+		{"auto", Auto, "auto"},
+		{"bool", True, "true"},
+		{"int", Int(-12), "-12"},
+		{"float", Float(1), "1.0"},
+		{"float fraction", Float(0.25), "0.25"},
+		{"float nan", Float(math.NaN()), "float.nan"},
+		{"float inf", Float(math.Inf(-1)), "-float.inf"},
+		{"length", LengthValue{Length: Length{Points: 12}}, "12pt"},
+		{"ratio", RatioValue{Ratio: Ratio{Value: 0.5}}, "50%"},
+		{"relative", RelativeValue{Relative: Relative{Abs: Length{Points: 2}, Rel: Ratio{Value: 0.5}}}, "50% + 2pt"},
+		{"fraction", FractionValue{Fraction: Fraction{Value: 2}}, "2fr"},
+		{"str", Str("say \"hi\"\n"), `"say \"hi\"\n"`},
+		{"label", LabelValue("intro"), "<intro>"},
+		{"type", TypeValue{Inner: TypeInt}, "int"},
+		{"empty array", NewArray(), "()"},
+		{"single array", NewArray(Int(1)), "(1,)"},
+		{"array", NewArray(Int(1), Str("a")), `(1, "a")`},
+		{"empty dict", NewDict(), "(:)"},
+		{"dict", dict, `(a: 1, "two words": "x")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Repr(tt.value); got != tt.want {
+				t.Errorf("Repr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeConstructors(t *testing.T) {
+	call := func(ty Type, args ...Value) (Value, error) {
+		fn, ok := AsFunc(TypeValue{Inner: ty})
+		if !ok {
+			t.Fatalf("%s is not callable", ty)
+		}
+		return fn.Call(&Engine{}, NewContext(), NewArgs(syntax.Detached(), args...))
+	}
+
+	if v, err := call(TypeInt, Str("42")); err != nil || v != Int(42) {
+		t.Errorf("int(\"42\") = %v, %v", v, err)
+	}
+	if v, err := call(TypeFloat, Int(3)); err != nil || v != Float(3) {
+		t.Errorf("float(3) = %v, %v", v, err)
+	}
+	if v, err := call(TypeStr, Int(255)); err != nil || v != Str("255") {
+		t.Errorf("str(255) = %v, %v", v, err)
+	}
+	if _, err := call(TypeInt, Float(1e300)); err == nil {
+		t.Error("int(1e300) should fail")
+	}
+	if _, err := call(TypeInt, Float(math.NaN())); err == nil {
+		t.Error("int(float.nan) should fail")
+	}
+
+	v, err := call(TypeType, Str("a"))
+	if err != nil {
+		t.Fatalf("type(\"a\") failed: %v", err)
+	}
+	if !Equal(v, TypeValue{Inner: TypeStr}) || Equal(v, TypeValue{Inner: TypeInt}) {
+		t.Errorf("type(\"a\") = %v, want str", v)
+	}
+
+	if _, ok := AsFunc(TypeValue{Inner: TypeBool}); ok {
+		t.Error("bool should not have a constructor")
+	}
+}
+
+func TestReprFunc(t *testing.T) {
+	v, err := ReprFunc().Call(&Engine{}, NewContext(), NewArgs(syntax.Detached(), Str("a")))
+	if err != nil || v != Str(`"a"`) {
+		t.Errorf("repr(\"a\") = %v, %v", v, err)
+	}
+}
@@ -0,0 +1,242 @@
+// Routines for Typst.
+// Translated from typst-library/src/routines.rs
+
+package foundations
+
+import (
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/syntax"
+	"github.com/boergens/gotypst/timing"
+)
+
+// ----------------------------------------------------------------------------
+// Routines (Virtual Function Table)
+// ----------------------------------------------------------------------------
+
+// Routines defines implementation of various Typst compiler routines.
+// This is essentially a vtable pattern to allow crate/package splitting
+// without circular dependencies: evaluation, realization, and layout each
+// implement their part, and call each other only through the engine. That
+// way, layout can realize the content it lays out and realization can call
+// show rules, even though neither imports the evaluator.
+//
+// Matches Rust's Routines struct in routines.rs.
+type Routines interface {
+	EvalRoutines
+	RealizeRoutines
+	LayoutRoutines
+}
+
+// EvalRoutines are the routines implemented by the evaluator.
+type EvalRoutines interface {
+	// EvalClosure calls a closure with the given context and arguments.
+	EvalClosure(engine *Engine, context *Context, fn *Func, closure *Closure, args *Args) (Value, error)
+
+	// EvalString evaluates a string as code, markup, or math. The bindings
+	// of scope are available in addition to the standard library.
+	EvalString(engine *Engine, text string, span syntax.Span, mode syntax.SyntaxMode, scope *Scope) (Value, error)
+
+	// EvalSource evaluates a source file into a module whose content is
+	// the file's markup.
+	EvalSource(engine *Engine, source *syntax.Source) (*Module, error)
+}
+
+// RealizeRoutines are the routines implemented by realization.
+type RealizeRoutines interface {
+	// Realize applies show rules to content and groups its elements,
+	// producing a flat list of elements with their styles.
+	Realize(engine *Engine, kind RealizationKind, content ContentElement, styles *StyleChain) ([]Pair, error)
+}
+
+// LayoutRoutines are the routines implemented by layout.
+type LayoutRoutines interface {
+	// LayoutFrame lays out content into a single frame in the region and
+	// returns the frame's size.
+	LayoutFrame(engine *Engine, content Content, styles *StyleChain, region Region) (Size, error)
+
+	// LayoutDocument realizes content as a document and lays it out into
+	// pages. Up to jobs page runs are laid out in parallel. Realization and
+	// layout are timed with the timer, which may be nil.
+	//
+	// Rust's compiler calls layout_document directly; it is a routine here
+	// so that the compiler's entry points do not depend on page layout.
+	LayoutDocument(engine *Engine, content Content, jobs int, timer *timing.Timer) (*layout.PagedDocument, error)
+}
+
+// RoutineSet combines the routines of the subsystems. A subsystem that is
+// left out makes its routines fail, so that an engine for evaluation alone
+// can be created without realization and layout.
+type RoutineSet struct {
+	Eval        EvalRoutines
+	Realization RealizeRoutines
+	Layout      LayoutRoutines
+}
+
+// EvalClosure calls a closure through the evaluator.
+func (r RoutineSet) EvalClosure(engine *Engine, context *Context, fn *Func, closure *Closure, args *Args) (Value, error) {
+	if r.Eval == nil {
+		return nil, &OpError{Message: "cannot call closure without routines"}
+	}
+	return r.Eval.EvalClosure(engine, context, fn, closure, args)
+}
+
+// EvalString evaluates a string through the evaluator.
+func (r RoutineSet) EvalString(engine *Engine, text string, span syntax.Span, mode syntax.SyntaxMode, scope *Scope) (Value, error) {
+	if r.Eval == nil {
+		return nil, NewSourceError(span, "cannot evaluate string without routines")
+	}
+	return r.Eval.EvalString(engine, text, span, mode, scope)
+}
+
+// EvalSource evaluates a source file through the evaluator.
+func (r RoutineSet) EvalSource(engine *Engine, source *syntax.Source) (*Module, error) {
+	if r.Eval == nil {
+		return nil, &OpError{Message: "cannot evaluate source without routines"}
+	}
+	return r.Eval.EvalSource(engine, source)
+}
+
+// Realize realizes content through realization.
+func (r RoutineSet) Realize(engine *Engine, kind RealizationKind, content ContentElement, styles *StyleChain) ([]Pair, error) {
+	if r.Realization == nil {
+		return nil, &OpError{Message: "cannot realize content without routines"}
+	}
+	return r.Realization.Realize(engine, kind, content, styles)
+}
+
+// LayoutFrame lays out content through layout.
+func (r RoutineSet) LayoutFrame(engine *Engine, content Content, styles *StyleChain, region Region) (Size, error) {
+	if r.Layout == nil {
+		return Size{}, &OpError{Message: "cannot lay out content without routines"}
+	}
+	return r.Layout.LayoutFrame(engine, content, styles, region)
+}
+
+// LayoutDocument lays out a document through layout.
+func (r RoutineSet) LayoutDocument(engine *Engine, content Content, jobs int, timer *timing.Timer) (*layout.PagedDocument, error) {
+	if r.Layout == nil {
+		return nil, &OpError{Message: "cannot lay out document without routines"}
+	}
+	return r.Layout.LayoutDocument(engine, content, jobs, timer)
+}
+
+// ----------------------------------------------------------------------------
+// Realization Kind
+// ----------------------------------------------------------------------------
+
+// RealizationKind specifies the context for realization.
+// Different kinds affect how content is processed and grouped.
+// Matches Rust: typst-library/src/routines/realize.rs RealizationKind
+type RealizationKind interface {
+	isRealizationKind()
+	// IsDocument returns true if this is a document realization.
+	IsDocument() bool
+	// IsFragment returns true if this is a fragment realization.
+	IsFragment() bool
+}
+
+// LayoutDocument prepares content for full document layout.
+// Matches Rust: RealizationKind::LayoutDocument
+type LayoutDocument struct {
+	// Info receives document metadata populated during realization.
+	Info *DocumentInfo
+}
+
+func (LayoutDocument) isRealizationKind() {}
+func (LayoutDocument) IsDocument() bool   { return true }
+func (LayoutDocument) IsFragment() bool   { return false }
+
+// LayoutFragment prepares content for fragment layout.
+// Matches Rust: RealizationKind::LayoutFragment
+type LayoutFragment struct {
+	// Kind receives the detected fragment kind after realization.
+	Kind *FragmentKind
+}
+
+func (LayoutFragment) isRealizationKind() {}
+func (LayoutFragment) IsDocument() bool   { return false }
+func (LayoutFragment) IsFragment() bool   { return true }
+
+// LayoutPar prepares content for paragraph-specific realization.
+// Matches Rust: RealizationKind::LayoutPar
+type LayoutPar struct{}
+
+func (LayoutPar) isRealizationKind() {}
+func (LayoutPar) IsDocument() bool   { return false }
+func (LayoutPar) IsFragment() bool   { return false }
+
+// HtmlDocument prepares content for HTML document export.
+// Matches Rust: RealizationKind::HtmlDocument
+type HtmlDocument struct {
+	Info       *DocumentInfo
+	IsPhrasing func(ContentElement) bool
+}
+
+func (HtmlDocument) isRealizationKind() {}
+func (HtmlDocument) IsDocument() bool   { return true }
+func (HtmlDocument) IsFragment() bool   { return false }
+
+// HtmlFragment prepares content for HTML fragment export.
+// Matches Rust: RealizationKind::HtmlFragment
+type HtmlFragment struct {
+	Kind       *FragmentKind
+	IsPhrasing func(ContentElement) bool
+}
+
+func (HtmlFragment) isRealizationKind() {}
+func (HtmlFragment) IsDocument() bool   { return false }
+func (HtmlFragment) IsFragment() bool   { return true }
+
+// MathRealization prepares content for mathematical typesetting.
+// Matches Rust: RealizationKind::Math
+type MathRealization struct{}
+
+func (MathRealization) isRealizationKind() {}
+func (MathRealization) IsDocument() bool   { return false }
+func (MathRealization) IsFragment() bool   { return false }
+
+// DocumentInfo holds document metadata populated during realization.
+type DocumentInfo struct {
+	Title       *Content
+	Author      []string
+	Description *Content
+	Keywords    []string
+	Date        Value
+	Locale      string
+}
+
+// FragmentKind indicates the type of fragment detected during realization.
+// Matches Rust: typst-library/src/routines/realize.rs FragmentKind
+type FragmentKind int
+
+const (
+	// FragmentBlock indicates block-level content.
+	FragmentBlock FragmentKind = iota
+	// FragmentInline indicates inline-level content.
+	FragmentInline
+)
+
+// Pair represents a realized element with its associated style chain.
+// Matches Rust: typst-library/src/routines/realize.rs Pair
+type Pair struct {
+	Content ContentElement
+	Styles  *StyleChain
+}
+
+// ----------------------------------------------------------------------------
+// Region
+// ----------------------------------------------------------------------------
+
+// Size is the size of a laid-out frame.
+type Size struct {
+	Width, Height Length
+}
+
+// Region is the space that LayoutFrame lays content out into. An infinite
+// width or height leaves the region unbounded in that direction. A region
+// that expands in a direction makes the frame fill it in that direction.
+// Matches Rust: typst-library/src/layout/regions.rs Region
+type Region struct {
+	Size             Size
+	ExpandX, ExpandY bool
+}
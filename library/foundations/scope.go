@@ -401,15 +401,30 @@ func (s *Scopes) FlattenToScope() *Scope {
 }
 
 // GetInMath looks up a binding for a math identifier.
-// Math identifiers are multi-letter identifiers like "foo" in math mode.
-// Single-letter identifiers in math are not captured (they're interpreted as symbols).
+// Local bindings take precedence. After them, the `math` module of the
+// standard library is searched before the rest of the library, so that
+// symbols like `alpha` resolve without a `sym.` prefix.
 // This matches Rust's Scopes::get_in_math method.
 func (s *Scopes) GetInMath(name string) *Binding {
-	// Math identifiers behave the same as regular identifiers for now
-	// The difference is in which identifiers are considered "captures"
-	// - single letter idents in math mode are not captured (they're symbols)
-	// - multi-letter idents in math mode are captured
-	return s.Get(name)
+	if binding := s.top.Get(name); binding != nil {
+		return binding
+	}
+	for i := len(s.scopes) - 1; i >= 0; i-- {
+		if binding := s.scopes[i].Get(name); binding != nil {
+			return binding
+		}
+	}
+	if s.base == nil {
+		return nil
+	}
+	if math := s.base.Get("math"); math != nil {
+		if module, ok := math.Value().(ModuleValue); ok && module.Module != nil && module.Module.Scope != nil {
+			if binding := module.Module.Scope.Get(name); binding != nil {
+				return binding
+			}
+		}
+	}
+	return s.base.Get(name)
 }
 
 // Bind adds a detached binding to the top scope.
@@ -4,6 +4,9 @@
 package foundations
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/boergens/gotypst/syntax"
 )
 
@@ -21,12 +24,37 @@ type ElemSelector struct {
 	// Element is the element type to match.
 	Element Element
 
-	// Where is an optional filter function for field matching.
-	Where *Func
+	// Fields optionally restricts matches to elements whose fields have
+	// these values, as in `heading.where(level: 1)`.
+	Fields *Dict
 }
 
 func (ElemSelector) isSelector() {}
 
+// Matches reports whether the element is of the selector's element type
+// and has the selector's field values.
+// Matches Rust: Selector::matches for Selector::Elem
+func (s ElemSelector) Matches(elem ContentElement) bool {
+	return ElementName(elem) == s.Element.Name && s.MatchesFields(elem)
+}
+
+// MatchesFields reports whether the element has the selector's field
+// values.
+func (s ElemSelector) MatchesFields(elem ContentElement) bool {
+	if s.Fields == nil || s.Fields.Len() == 0 {
+		return true
+	}
+	fields := ElementFields(elem)
+	keys, values := s.Fields.Iter()
+	for i, key := range keys {
+		value, ok := fields.Get(key)
+		if !ok || !Equal(value, values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // LabelSelector matches content with a specific label.
 // Corresponds to Rust's Selector::Label variant.
 type LabelSelector struct {
@@ -94,6 +122,63 @@ type LocatableSelector struct {
 	Selector Selector
 }
 
+// CastLocatableSelector casts a value to a selector that can be queried:
+// an element function, a label, a location, or a selector made of these.
+// Matches Rust: impl FromValue for LocatableSelector
+func CastLocatableSelector(v Value) (LocatableSelector, error) {
+	switch v := v.(type) {
+	case FuncValue:
+		def := v.Func.Element()
+		if def == nil {
+			return LocatableSelector{}, &ConstructorError{Message: "expected element function"}
+		}
+		return LocatableSelector{Selector: ElemSelector{Element: Element{Name: def.Name}}}, nil
+	case LabelValue:
+		return LocatableSelector{Selector: LabelSelector{Label: string(v)}}, nil
+	case LocationValue:
+		return LocatableSelector{Selector: LocationSelector{Location: v.Location}}, nil
+	case SelectorValue:
+		if err := checkLocatable(v.Selector); err != nil {
+			return LocatableSelector{}, err
+		}
+		return LocatableSelector{Selector: v.Selector}, nil
+	}
+	return LocatableSelector{}, &TypeMismatchError{Expected: "label, selector, location, or function", Got: v.Type().String()}
+}
+
+// checkLocatable fails if the selector can match text, which cannot be
+// located.
+// Matches Rust: fn validate in impl FromValue for LocatableSelector
+func checkLocatable(selector Selector) error {
+	switch sel := selector.(type) {
+	case RegexSelector:
+		return &ConstructorError{Message: "text is not locatable"}
+	case OrSelector:
+		for _, s := range sel.Selectors {
+			if err := checkLocatable(s); err != nil {
+				return err
+			}
+		}
+	case AndSelector:
+		for _, s := range sel.Selectors {
+			if err := checkLocatable(s); err != nil {
+				return err
+			}
+		}
+	case BeforeSelector:
+		if err := checkLocatable(sel.Selector); err != nil {
+			return err
+		}
+		return checkLocatable(sel.End)
+	case AfterSelector:
+		if err := checkLocatable(sel.Selector); err != nil {
+			return err
+		}
+		return checkLocatable(sel.Start)
+	}
+	return nil
+}
+
 // ShowableSelector is a selector that can be used with show rules.
 // Corresponds to Rust's ShowableSelector struct.
 type ShowableSelector struct {
@@ -171,8 +256,258 @@ func NewRecipe(selector Selector, transform Transformation, span syntax.Span) *R
 	}
 }
 
+// Apply applies the recipe's transformation to content. A function
+// receives the content and its result is displayed, so that set rules in
+// the function's body style the content it produces.
+// Matches Rust: Recipe::apply
+func (r *Recipe) Apply(engine *Engine, context *Context, content Content) (Content, error) {
+	switch t := r.Transform.(type) {
+	case NoneTransformation:
+		return Content{}, nil
+	case ContentTransformation:
+		return t.Content, nil
+	case StyleTransformation:
+		return StyledWithMap(content, t.Styles), nil
+	case FuncTransformation:
+		result, err := t.Func.Call(engine, context, NewArgs(r.Span, ContentValue{Content: content}))
+		if err != nil {
+			return Content{}, err
+		}
+		return result.Display(), nil
+	}
+	return content, nil
+}
+
 // RecipeIndex identifies a show rule recipe from the top of the chain.
 // Corresponds to Rust's RecipeIndex struct.
 type RecipeIndex struct {
 	Index int
 }
+
+// ----------------------------------------------------------------------------
+// Selector Values
+// ----------------------------------------------------------------------------
+
+// SelectorValue is a selector as a value, as created by `selector(..)` or
+// `heading.where(..)`.
+type SelectorValue struct {
+	Selector Selector
+}
+
+func (SelectorValue) Type() Type         { return TypeSelector }
+func (v SelectorValue) Display() Content { return Content{} }
+func (v SelectorValue) Clone() Value     { return v }
+func (SelectorValue) isValue()           {}
+
+// CastSelector casts a value to a selector: an element function, a label,
+// a string (matching text), a location, or a selector.
+// Matches Rust: impl FromValue for Selector
+func CastSelector(v Value) (Selector, error) {
+	switch v := v.(type) {
+	case SelectorValue:
+		return v.Selector, nil
+	case FuncValue:
+		def := v.Func.Element()
+		if def == nil {
+			return nil, &ConstructorError{Message: "only element functions can be used as selectors"}
+		}
+		return ElemSelector{Element: Element{Name: def.Name}}, nil
+	case LabelValue:
+		return LabelSelector{Label: string(v)}, nil
+	case Str:
+		return RegexSelector{Pattern: regexp.QuoteMeta(string(v))}, nil
+	case LocationValue:
+		return LocationSelector{Location: v.Location}, nil
+	}
+	return nil, &TypeMismatchError{Expected: "string, label, function, location, or selector", Got: v.Type().String()}
+}
+
+// SelectorConstruct turns a value into a selector, as in
+// `selector(heading)`.
+// Matches Rust: Selector::construct
+func SelectorConstruct(args *Args) (Value, error) {
+	target, err := args.Expect("target")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	selector, err := castSelectorArg(target, "target")
+	if err != nil {
+		return nil, err
+	}
+	return SelectorValue{Selector: selector}, nil
+}
+
+// castSelectorArg casts an argument to a selector, attaching its span to
+// errors.
+func castSelectorArg(arg Spanned[Value], field string) (Selector, error) {
+	selector, err := CastSelector(arg.V)
+	switch e := err.(type) {
+	case nil:
+		return selector, nil
+	case *TypeMismatchError:
+		e.Field, e.Span = field, arg.Span
+	case *ConstructorError:
+		e.Span = arg.Span
+	}
+	return nil, err
+}
+
+func init() {
+	scope := NewScope()
+	define := func(name string, params []ParamInfo, f func(self Selector, args *Args) (Value, error)) {
+		fname := name
+		scope.Define(name, FuncValue{Func: &Func{
+			Name: &fname,
+			Span: syntax.Detached(),
+			Repr: NativeFunc{
+				Func: func(engine Engine, context Context, args *Args) (Value, error) {
+					arg, err := args.Expect("self")
+					if err != nil {
+						return nil, err
+					}
+					self, err := castSelectorArg(arg, "self")
+					if err != nil {
+						return nil, err
+					}
+					return f(self, args)
+				},
+				Info: &FuncInfo{Name: name, Params: append([]ParamInfo{{Name: "self", Type: TypeSelector}}, params...)},
+			},
+		}}, syntax.Detached())
+	}
+
+	variadic := []ParamInfo{{Name: "others", Type: TypeSelector, Variadic: true}}
+	define("or", variadic, func(self Selector, args *Args) (Value, error) {
+		selectors, err := selectorArgs(self, args)
+		if err != nil {
+			return nil, err
+		}
+		return SelectorValue{Selector: OrSelector{Selectors: selectors}}, nil
+	})
+	define("and", variadic, func(self Selector, args *Args) (Value, error) {
+		selectors, err := selectorArgs(self, args)
+		if err != nil {
+			return nil, err
+		}
+		return SelectorValue{Selector: AndSelector{Selectors: selectors}}, nil
+	})
+
+	define("before", []ParamInfo{
+		{Name: "end", Type: TypeSelector},
+		{Name: "inclusive", Type: TypeBool, Named: true, Default: Bool(true)},
+	}, func(self Selector, args *Args) (Value, error) {
+		end, inclusive, err := splitArgs(args, "end")
+		if err != nil {
+			return nil, err
+		}
+		return SelectorValue{Selector: BeforeSelector{Selector: self, End: end, Inclusive: inclusive}}, nil
+	})
+	define("after", []ParamInfo{
+		{Name: "start", Type: TypeSelector},
+		{Name: "inclusive", Type: TypeBool, Named: true, Default: Bool(true)},
+	}, func(self Selector, args *Args) (Value, error) {
+		start, inclusive, err := splitArgs(args, "start")
+		if err != nil {
+			return nil, err
+		}
+		return SelectorValue{Selector: AfterSelector{Selector: self, Start: start, Inclusive: inclusive}}, nil
+	})
+
+	RegisterTypeScope(TypeSelector, scope)
+}
+
+// selectorArgs collects the selector and the remaining positional
+// arguments of `or` and `and`.
+func selectorArgs(self Selector, args *Args) ([]Selector, error) {
+	selectors := []Selector{self}
+	for _, arg := range args.All() {
+		selector, err := castSelectorArg(arg, "others")
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	return selectors, nil
+}
+
+// splitArgs parses the arguments of `before` and `after`: the locatable
+// selector to split at and whether the split point is included.
+func splitArgs(args *Args, name string) (Selector, bool, error) {
+	arg, err := args.Expect(name)
+	if err != nil {
+		return nil, false, err
+	}
+	split, err := CastLocatableSelector(arg.V)
+	if err != nil {
+		if mismatch, ok := err.(*TypeMismatchError); ok {
+			mismatch.Field, mismatch.Span = name, arg.Span
+		}
+		return nil, false, err
+	}
+	inclusive := true
+	if named := args.Named("inclusive"); named != nil {
+		b, ok := named.V.(Bool)
+		if !ok {
+			return nil, false, &TypeMismatchError{Expected: "boolean", Got: named.V.Type().String(), Field: "inclusive", Span: named.Span}
+		}
+		inclusive = bool(b)
+	}
+	if err := args.Finish(); err != nil {
+		return nil, false, err
+	}
+	return split.Selector, inclusive, nil
+}
+
+// reprSelector returns the code that produces the selector.
+// Matches Rust: impl Repr for Selector
+func reprSelector(selector Selector) string {
+	switch sel := selector.(type) {
+	case ElemSelector:
+		if sel.Fields == nil || sel.Fields.Len() == 0 {
+			return sel.Element.Name
+		}
+		keys, values := sel.Fields.Iter()
+		pieces := make([]string, len(keys))
+		for i, key := range keys {
+			pieces[i] = key + ": " + Repr(values[i])
+		}
+		return sel.Element.Name + ".where(" + strings.Join(pieces, ", ") + ")"
+	case LabelSelector:
+		return Repr(LabelValue(sel.Label))
+	case RegexSelector:
+		return "regex(" + reprStr(sel.Pattern) + ")"
+	case LocationSelector:
+		return Repr(LocationValue{Location: sel.Location})
+	case OrSelector:
+		return "selector.or(" + reprSelectors(sel.Selectors) + ")"
+	case AndSelector:
+		return "selector.and(" + reprSelectors(sel.Selectors) + ")"
+	case BeforeSelector:
+		return reprSplit(sel.Selector, "before", sel.End, sel.Inclusive)
+	case AfterSelector:
+		return reprSplit(sel.Selector, "after", sel.Start, sel.Inclusive)
+	}
+	return "selector(..)"
+}
+
+func reprSelectors(selectors []Selector) string {
+	pieces := make([]string, len(selectors))
+	for i, s := range selectors {
+		pieces[i] = reprSelector(s)
+	}
+	return strings.Join(pieces, ", ")
+}
+
+func reprSplit(selector Selector, method string, split Selector, inclusive bool) string {
+	out := reprSelector(selector) + "." + method + "(" + reprSelector(split)
+	if !inclusive {
+		out += ", inclusive: false"
+	}
+	return out + ")"
+}
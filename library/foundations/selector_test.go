@@ -0,0 +1,115 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// selectorTestElem is an element with a field for testing where filters.
+type selectorTestElem struct {
+	Level int64   `typst:"level,type=int,default=1"`
+	Body  Content `typst:"body,positional,required,type=content"`
+}
+
+func (*selectorTestElem) IsContentElement() {}
+
+func init() {
+	RegisterElement[selectorTestElem]("selector-test", nil)
+}
+
+// callMethod calls a method from the scope of a type with the target as
+// its first argument, like a field call does.
+func callMethod(t *testing.T, typ Type, name string, target Value, args *Args) (Value, error) {
+	t.Helper()
+	binding := typ.Scope().Get(name)
+	if binding == nil {
+		t.Fatalf("%s has no method %s", typ, name)
+	}
+	args.Insert(0, syntax.Detached(), target)
+	engine, context := &Engine{}, &Context{}
+	return binding.Read().(FuncValue).Func.Call(engine, context, args)
+}
+
+func namedArg(name string, value Value) Arg {
+	key := Str(name)
+	return Arg{Name: &key, Value: Spanned[Value]{V: value}}
+}
+
+func TestFuncWhere(t *testing.T) {
+	name := "selector-test"
+	elemFunc := FuncValue{Func: &Func{Name: &name, Repr: NativeFunc{}}}
+
+	args := &Args{Items: []Arg{namedArg("level", Int(2))}}
+	got, err := callMethod(t, TypeFunc, "where", elemFunc, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel, ok := got.(SelectorValue)
+	if !ok {
+		t.Fatalf("where() = %T, want selector", got)
+	}
+	if repr := Repr(sel); repr != "selector-test.where(level: 2)" {
+		t.Errorf("repr = %s", repr)
+	}
+
+	elem := sel.Selector.(ElemSelector)
+	if !elem.Matches(&selectorTestElem{Level: 2}) {
+		t.Error("expected level 2 to match")
+	}
+	if elem.Matches(&selectorTestElem{Level: 1}) {
+		t.Error("expected level 1 not to match")
+	}
+
+	_, err = callMethod(t, TypeFunc, "where", elemFunc, &Args{Items: []Arg{namedArg("depth", Int(2))}})
+	if err == nil || err.Error() != "element `selector-test` does not have field `depth`" {
+		t.Errorf("unknown field error = %v", err)
+	}
+
+	plain := "plain"
+	_, err = callMethod(t, TypeFunc, "where", FuncValue{Func: &Func{Name: &plain, Repr: NativeFunc{}}}, &Args{})
+	if err == nil || err.Error() != "`where()` can only be called on element functions" {
+		t.Errorf("non-element error = %v", err)
+	}
+}
+
+func TestSelectorMethods(t *testing.T) {
+	name := "selector-test"
+	elemFunc := FuncValue{Func: &Func{Name: &name, Repr: NativeFunc{}}}
+
+	sel, err := SelectorConstruct(NewArgs(syntax.Detached(), elemFunc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		args   *Args
+		want   string
+	}{
+		{"or", NewArgs(syntax.Detached(), LabelValue("intro"), Str("a.b")), `selector.or(selector-test, <intro>, regex("a\\.b"))`},
+		{"and", NewArgs(syntax.Detached(), LabelValue("intro")), "selector.and(selector-test, <intro>)"},
+		{"before", NewArgs(syntax.Detached(), LabelValue("end")), "selector-test.before(<end>)"},
+		{"after", &Args{Items: []Arg{{Value: Spanned[Value]{V: LabelValue("start")}}, namedArg("inclusive", Bool(false))}}, "selector-test.after(<start>, inclusive: false)"},
+	}
+	for _, tt := range tests {
+		got, err := callMethod(t, TypeSelector, tt.method, sel, tt.args)
+		if err != nil {
+			t.Errorf("%s: %v", tt.method, err)
+			continue
+		}
+		if repr := Repr(got); repr != tt.want {
+			t.Errorf("%s: repr = %s, want %s", tt.method, repr, tt.want)
+		}
+	}
+
+	// The target of a split must be locatable.
+	_, err = callMethod(t, TypeSelector, "before", sel, NewArgs(syntax.Detached(), SelectorValue{Selector: RegexSelector{Pattern: "x"}}))
+	if err == nil || err.Error() != "text is not locatable" {
+		t.Errorf("regex split error = %v", err)
+	}
+
+	if _, err := SelectorConstruct(NewArgs(syntax.Detached(), Int(1))); err == nil {
+		t.Error("expected integers to be rejected")
+	}
+}
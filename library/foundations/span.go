@@ -0,0 +1,68 @@
+// Spans of content for Typst.
+// Translated from typst-library/src/foundations/content/mod.rs
+
+package foundations
+
+import "github.com/boergens/gotypst/syntax"
+
+// ElementSpan returns the span of the expression that created an element,
+// or a detached span if it is unknown.
+// Matches Rust: Content::span
+func ElementSpan(elem ContentElement) syntax.Span {
+	if meta, ok := elem.(metaElement); ok {
+		return meta.elemMeta().Span
+	}
+	return syntax.Detached()
+}
+
+// WithSpan returns the element with a span attached if it does not have
+// one yet. An element that gets a span is copied.
+// Matches Rust: Content::spanned
+func WithSpan(elem ContentElement, span syntax.Span) ContentElement {
+	spanned, _ := withSpan(elem, span)
+	return spanned
+}
+
+// withSpan is WithSpan, and also reports whether the element was copied.
+func withSpan(elem ContentElement, span syntax.Span) (ContentElement, bool) {
+	meta, ok := elem.(metaElement)
+	if !ok || span.IsDetached() || !meta.elemMeta().Span.IsDetached() {
+		return elem, false
+	}
+	copied := copyElement(meta)
+	copied.elemMeta().Span = span
+	return copied, true
+}
+
+// Span returns the span of the first element of the content that has one.
+func (c Content) Span() syntax.Span {
+	for _, elem := range c.Elements {
+		if span := ElementSpan(elem); !span.IsDetached() {
+			return span
+		}
+	}
+	return syntax.Detached()
+}
+
+// WithSpan attaches a span to the elements of the content that do not have
+// one yet. The elements are only copied if one of them changes.
+func (c Content) WithSpan(span syntax.Span) Content {
+	if span.IsDetached() {
+		return c
+	}
+	var elems []ContentElement
+	for i, elem := range c.Elements {
+		spanned, copied := withSpan(elem, span)
+		if elems == nil && copied {
+			elems = make([]ContentElement, len(c.Elements))
+			copy(elems, c.Elements[:i])
+		}
+		if elems != nil {
+			elems[i] = spanned
+		}
+	}
+	if elems == nil {
+		return c
+	}
+	return Content{Elements: elems}
+}
@@ -0,0 +1,85 @@
+package foundations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+// spannedElem is a registered element that records its span.
+type spannedElem struct {
+	Body string `typst:"body,positional,required"`
+	ElemMeta
+}
+
+func (*spannedElem) IsContentElement() {}
+
+func TestContentSpan(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	other := syntax.SpanFromRaw(1<<48 | 3)
+
+	symbol := &SymbolElem{Text: "x"}
+	content := Content{Elements: []ContentElement{&spaceElem{}, symbol}}
+	if !content.Span().IsDetached() {
+		t.Errorf("span of fresh content = %v, want detached", content.Span())
+	}
+
+	spanned := content.WithSpan(span)
+	if got := spanned.Span(); got != span {
+		t.Errorf("span = %v, want %v", got, span)
+	}
+	if !symbol.Span.IsDetached() {
+		t.Error("attaching a span modified the original element")
+	}
+	if !ElementSpan(spanned.Elements[0]).IsDetached() {
+		t.Error("element without a span field got a span")
+	}
+
+	// An existing span is kept, so content keeps pointing at the
+	// expression that created it.
+	respanned := spanned.WithSpan(other)
+	if got := respanned.Span(); got != span {
+		t.Errorf("span after respanning = %v, want %v", got, span)
+	}
+	if &respanned.Elements[0] != &spanned.Elements[0] {
+		t.Error("respanning copied elements that didn't change")
+	}
+}
+
+func TestParseElementSpan(t *testing.T) {
+	def := RegisterElement[spannedElem]("span-test", nil)
+	span := syntax.SpanFromRaw(1<<48 | 4)
+
+	elem, err := ParseElement[spannedElem](def, NewArgs(span, Str("body")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elem.Span != span {
+		t.Errorf("span = %v, want %v", elem.Span, span)
+	}
+	if fields := Repr(ElementFields(elem)); fields != `(body: "body")` {
+		t.Errorf("fields = %s", fields)
+	}
+}
+
+func TestAtElement(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	inner := syntax.SpanFromRaw(1<<48 | 3)
+	elem := &spannedElem{Body: "body", ElemMeta: ElemMeta{Span: span}}
+
+	if AtElement(nil, elem) != nil {
+		t.Error("AtElement(nil) != nil")
+	}
+
+	diags := ErrorDiagnostics(AtElement(fmt.Errorf("cannot expand into infinite width"), elem))
+	if len(diags) != 1 || diags[0].Span != span || diags[0].Message != "cannot expand into infinite width" {
+		t.Errorf("diagnostics = %+v", diags)
+	}
+
+	// An error that points somewhere more specific keeps its span.
+	err := AtElement(&ConstructorError{Message: "bad value", Span: inner}, elem)
+	if diags := ErrorDiagnostics(err); len(diags) != 1 || diags[0].Span != inner {
+		t.Errorf("diagnostics = %+v", diags)
+	}
+}
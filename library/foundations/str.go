@@ -3,6 +3,8 @@ package foundations
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/boergens/gotypst/syntax"
@@ -300,3 +302,334 @@ func StrCodepoints(s Str) *Array {
 	}
 	return NewArray(items...)
 }
+
+// clusterIndex returns the index of the grapheme cluster that starts at a
+// byte offset of a string.
+func clusterIndex(s string, offset int) int {
+	return len(graphemeClusters(s[:offset]))
+}
+
+// StrTrim removes repeated or single occurrences of a pattern from the
+// start, the end, or both ends of a string. Without a pattern, it removes
+// whitespace.
+// Matches Rust: Str::trim
+func StrTrim(s Str, pattern *string, start, end, repeat bool) Str {
+	str := string(s)
+	if pattern == nil {
+		if start {
+			str = strings.TrimLeftFunc(str, unicode.IsSpace)
+		}
+		if end {
+			str = strings.TrimRightFunc(str, unicode.IsSpace)
+		}
+		return Str(str)
+	}
+	if *pattern == "" {
+		return s
+	}
+	if start {
+		for strings.HasPrefix(str, *pattern) {
+			str = str[len(*pattern):]
+			if !repeat {
+				break
+			}
+		}
+	}
+	if end {
+		for strings.HasSuffix(str, *pattern) {
+			str = str[:len(str)-len(*pattern)]
+			if !repeat {
+				break
+			}
+		}
+	}
+	return Str(str)
+}
+
+// StrSplit splits a string at a pattern. Without a pattern, it splits at
+// whitespace.
+// Matches Rust: Str::split
+func StrSplit(s Str, pattern *string) *Array {
+	var parts []string
+	if pattern == nil {
+		parts = strings.Fields(string(s))
+	} else {
+		parts = strings.Split(string(s), *pattern)
+	}
+	items := make([]Value, len(parts))
+	for i, part := range parts {
+		items[i] = Str(part)
+	}
+	return NewArray(items...)
+}
+
+// StrRev reverses the grapheme clusters of a string.
+func StrRev(s Str) Str {
+	clusters := graphemeClusters(string(s))
+	var b strings.Builder
+	for i := len(clusters) - 1; i >= 0; i-- {
+		b.WriteString(clusters[i])
+	}
+	return Str(b.String())
+}
+
+// strReplace replaces up to count occurrences of a pattern, or all of them
+// if count is negative. A function replacement receives a dictionary with
+// the start, end, and text of each match and returns its replacement.
+// Matches Rust: Str::replace
+func strReplace(engine *Engine, context *Context, s Str, pattern string, replacement Value, count int) (Value, error) {
+	switch r := replacement.(type) {
+	case Str:
+		return Str(strings.Replace(string(s), pattern, string(r), count)), nil
+	case FuncValue:
+		str := string(s)
+		var b strings.Builder
+		offset := 0
+		for replaced := 0; pattern != "" && replaced != count; replaced++ {
+			i := strings.Index(str[offset:], pattern)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			end := start + len(pattern)
+			match := NewDict()
+			match.Set("start", Int(clusterIndex(str, start)))
+			match.Set("end", Int(clusterIndex(str, end)))
+			match.Set("text", Str(pattern))
+			match.Set("captures", NewArray())
+			result, err := applyFunc(engine, context, r.Func, match)
+			if err != nil {
+				return nil, err
+			}
+			text, ok := result.(Str)
+			if !ok {
+				return nil, &OpError{Message: fmt.Sprintf("expected string from replacement function, found %s", result.Type())}
+			}
+			b.WriteString(str[offset:start])
+			b.WriteString(string(text))
+			offset = end
+		}
+		b.WriteString(str[offset:])
+		return Str(b.String()), nil
+	}
+	return nil, &TypeMismatchError{Expected: "string or function", Got: replacement.Type().String(), Field: "replacement"}
+}
+
+// sliceEnd reads the optional end and the count of a slice method into
+// an end index, where nil is the end of the collection.
+func sliceEnd(args *Args, start int64) (*int64, error) {
+	var end *int64
+	if arg := args.Eat(); arg != nil {
+		e, ok := asOptionalInt(arg.V)
+		if !ok {
+			return nil, &TypeMismatchError{Expected: "none or integer", Got: arg.V.Type().String(), Field: "end", Span: arg.Span}
+		}
+		end = e
+	}
+	count, hasCount, err := eatNamed(args, "count", "integer", AsInt)
+	if err != nil {
+		return nil, err
+	}
+	if hasCount {
+		if end != nil {
+			return nil, &OpError{Message: "`end` and `count` are mutually exclusive"}
+		}
+		e := start + count
+		end = &e
+	}
+	return end, nil
+}
+
+func init() {
+	m := newMethods(TypeStr)
+	str := func(f func(s Str, args *Args) (Value, error)) methodFunc {
+		return func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+			return f(self.(Str), args)
+		}
+	}
+	pattern := []ParamInfo{{Name: "pattern", Type: TypeStr}}
+
+	m.define("len", nil, str(func(s Str, args *Args) (Value, error) {
+		return StrLen(s), nil
+	}))
+	m.define("first", nil, str(func(s Str, args *Args) (Value, error) {
+		if StrIsEmpty(s) {
+			return nil, &OpError{Message: "string is empty"}
+		}
+		return StrFirst(s), nil
+	}))
+	m.define("last", nil, str(func(s Str, args *Args) (Value, error) {
+		if StrIsEmpty(s) {
+			return nil, &OpError{Message: "string is empty"}
+		}
+		return StrLast(s), nil
+	}))
+	m.define("at", []ParamInfo{
+		{Name: "index", Type: TypeInt},
+		{Name: "default", Type: TypeDyn, Named: true},
+	}, str(func(s Str, args *Args) (Value, error) {
+		index, err := expectArg(args, "index", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		def := args.Named("default")
+		value, err := StrAt(s, Int(index))
+		if err != nil && def != nil {
+			return def.V, nil
+		}
+		return value, err
+	}))
+	m.define("slice", []ParamInfo{
+		{Name: "start", Type: TypeInt},
+		{Name: "end", Type: TypeInt, Default: None},
+		{Name: "count", Type: TypeInt, Named: true},
+	}, str(func(s Str, args *Args) (Value, error) {
+		start, err := expectArg(args, "start", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		end, err := sliceEnd(args, start)
+		if err != nil {
+			return nil, err
+		}
+		if end == nil {
+			return StrSlice(s, Int(start), nil)
+		}
+		e := Int(*end)
+		return StrSlice(s, Int(start), &e)
+	}))
+	m.define("clusters", nil, str(func(s Str, args *Args) (Value, error) {
+		return StrClusters(s), nil
+	}))
+	m.define("codepoints", nil, str(func(s Str, args *Args) (Value, error) {
+		return StrCodepoints(s), nil
+	}))
+	m.define("to-unicode", nil, str(func(s Str, args *Args) (Value, error) {
+		return StrToUnicode(s, args.Span)
+	}))
+	for name, test := range map[string]func(s, pattern string) bool{
+		"contains":    strings.Contains,
+		"starts-with": strings.HasPrefix,
+		"ends-with":   strings.HasSuffix,
+	} {
+		m.define(name, pattern, str(func(s Str, args *Args) (Value, error) {
+			p, err := expectArg(args, "pattern", "string", AsStr)
+			if err != nil {
+				return nil, err
+			}
+			return Bool(test(string(s), p)), nil
+		}))
+	}
+	m.define("find", pattern, str(func(s Str, args *Args) (Value, error) {
+		p, err := expectArg(args, "pattern", "string", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(string(s), p) {
+			return None, nil
+		}
+		return Str(p), nil
+	}))
+	m.define("position", pattern, str(func(s Str, args *Args) (Value, error) {
+		p, err := expectArg(args, "pattern", "string", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		i := strings.Index(string(s), p)
+		if i < 0 {
+			return None, nil
+		}
+		return Int(clusterIndex(string(s), i)), nil
+	}))
+	m.define("replace", []ParamInfo{
+		{Name: "pattern", Type: TypeStr},
+		{Name: "replacement", Type: TypeDyn},
+		{Name: "count", Type: TypeInt, Named: true},
+	}, func(engine *Engine, context *Context, self Value, args *Args) (Value, error) {
+		p, err := expectArg(args, "pattern", "string", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := args.Expect("replacement")
+		if err != nil {
+			return nil, err
+		}
+		count, hasCount, err := eatNamed(args, "count", "integer", AsInt)
+		if err != nil {
+			return nil, err
+		}
+		if !hasCount {
+			count = -1
+		} else if count < 0 {
+			return nil, &OpError{Message: "number must be at least zero"}
+		}
+		return strReplace(engine, context, self.(Str), p, replacement.V, int(count))
+	})
+	m.define("trim", []ParamInfo{
+		{Name: "pattern", Type: TypeStr, Default: None},
+		{Name: "at", Type: TypeStr, Named: true},
+		{Name: "repeat", Type: TypeBool, Named: true, Default: Bool(true)},
+	}, str(func(s Str, args *Args) (Value, error) {
+		var p *string
+		if arg := args.Eat(); arg != nil {
+			opt, ok := asOptionalStr(arg.V)
+			if !ok {
+				return nil, &TypeMismatchError{Expected: "none or string", Got: arg.V.Type().String(), Field: "pattern", Span: arg.Span}
+			}
+			p = opt
+		}
+		at, hasAt, err := eatNamed(args, "at", "alignment", AsStr)
+		if err != nil {
+			return nil, err
+		}
+		if hasAt && at != "start" && at != "end" {
+			return nil, &OpError{Message: "expected either `start` or `end`"}
+		}
+		repeat, hasRepeat, err := eatNamed(args, "repeat", "boolean", AsBool)
+		if err != nil {
+			return nil, err
+		}
+		return StrTrim(s, p, !hasAt || at == "start", !hasAt || at == "end", repeat || !hasRepeat), nil
+	}))
+	m.define("split", []ParamInfo{{Name: "pattern", Type: TypeStr, Default: None}}, str(func(s Str, args *Args) (Value, error) {
+		var p *string
+		if arg := args.Eat(); arg != nil {
+			opt, ok := asOptionalStr(arg.V)
+			if !ok {
+				return nil, &TypeMismatchError{Expected: "none or string", Got: arg.V.Type().String(), Field: "pattern", Span: arg.Span}
+			}
+			p = opt
+		}
+		return StrSplit(s, p), nil
+	}))
+	m.define("rev", nil, str(func(s Str, args *Args) (Value, error) {
+		return StrRev(s), nil
+	}))
+
+	// from-unicode is called on the type, as in `str.from-unicode(97)`,
+	// so it takes an integer instead of a string.
+	fromUnicode := "from-unicode"
+	m.scope.Define(fromUnicode, FuncValue{Func: &Func{
+		Name: &fromUnicode,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: func(engine Engine, context Context, args *Args) (Value, error) {
+				value, err := args.Expect("value")
+				if err != nil {
+					return nil, err
+				}
+				codepoint, ok := value.V.(Int)
+				if !ok {
+					return nil, &TypeMismatchError{Expected: "integer", Got: value.V.Type().String(), Field: "value", Span: value.Span}
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				return StrFromUnicode(codepoint, value.Span)
+			},
+			Info: &FuncInfo{Name: fromUnicode, Params: []ParamInfo{{Name: "value", Type: TypeInt}}},
+		},
+	}}, syntax.Detached())
+
+	m.register()
+}
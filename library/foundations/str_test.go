@@ -1,7 +1,10 @@
 package foundations
 
 import (
+	"fmt"
 	"testing"
+
+	"github.com/boergens/gotypst/syntax"
 )
 
 func TestStrLen(t *testing.T) {
@@ -281,3 +284,69 @@ func valuesEqual(a, b Value) bool {
 		return false
 	}
 }
+
+func TestStrMethods(t *testing.T) {
+	named := func(name string, value Value) *Args {
+		args := NewArgs(syntax.Detached())
+		args.Items = append(args.Items, namedArg(name, value))
+		return args
+	}
+	tests := []struct {
+		method string
+		target Str
+		args   *Args
+		want   Value
+	}{
+		{"len", "héllo", NewArgs(syntax.Detached()), Int(5)},
+		{"contains", "hello", NewArgs(syntax.Detached(), Str("ell")), Bool(true)},
+		{"starts-with", "hello", NewArgs(syntax.Detached(), Str("lo")), Bool(false)},
+		{"position", "aöb", NewArgs(syntax.Detached(), Str("b")), Int(2)},
+		{"find", "hello", NewArgs(syntax.Detached(), Str("x")), None},
+		{"replace", "a-b-c", NewArgs(syntax.Detached(), Str("-"), Str("+")), Str("a+b+c")},
+		{"trim", "  hi  ", NewArgs(syntax.Detached()), Str("hi")},
+		{"trim", "xxhixx", &Args{Items: []Arg{{Value: Spanned[Value]{V: Str("x")}}, namedArg("at", Str("start"))}}, Str("hixx")},
+		{"split", "a, b, c", NewArgs(syntax.Detached(), Str(", ")), NewArray(Str("a"), Str("b"), Str("c"))},
+		{"split", " a  b ", NewArgs(syntax.Detached()), NewArray(Str("a"), Str("b"))},
+		{"rev", "abc", NewArgs(syntax.Detached()), Str("cba")},
+		{"slice", "hello", &Args{Items: []Arg{{Value: Spanned[Value]{V: Int(1)}}, namedArg("count", Int(3))}}, Str("ell")},
+		{"at", "abc", &Args{Items: []Arg{{Value: Spanned[Value]{V: Int(5)}}, namedArg("default", Str("z"))}}, Str("z")},
+		{"to-unicode", "a", NewArgs(syntax.Detached()), Int(97)},
+	}
+	for _, tt := range tests {
+		got, err := callMethod(t, TypeStr, tt.method, tt.target, tt.args)
+		if err != nil {
+			t.Errorf("%q.%s(): %v", tt.target, tt.method, err)
+			continue
+		}
+		if !Equal(got, tt.want) {
+			t.Errorf("%q.%s() = %v, want %v", tt.target, tt.method, got, tt.want)
+		}
+	}
+
+	if _, err := callMethod(t, TypeStr, "first", Str(""), NewArgs(syntax.Detached())); err == nil {
+		t.Error(`"".first(): expected an error`)
+	}
+	if _, err := callMethod(t, TypeStr, "len", Str("a"), named("extra", Int(1))); err == nil {
+		t.Error(`"a".len(extra: 1): expected an error`)
+	}
+}
+
+func TestStrReplaceFunc(t *testing.T) {
+	upper := &Func{Span: syntax.Detached(), Repr: NativeFunc{
+		Func: func(engine Engine, context Context, args *Args) (Value, error) {
+			match, err := args.Expect("match")
+			if err != nil {
+				return nil, err
+			}
+			start, _ := match.V.(*Dict).Get("start")
+			return Str(fmt.Sprintf("<%d>", start)), nil
+		},
+		Info: &FuncInfo{Name: "upper"},
+	}}
+	args := NewArgs(syntax.Detached(), Str("b"), FuncValue{Func: upper})
+	args.Items = append(args.Items, namedArg("count", Int(1)))
+	got, err := callMethod(t, TypeStr, "replace", Str("abab"), args)
+	if err != nil || !Equal(got, Str("a<1>ab")) {
+		t.Errorf("replace = %v, %v", got, err)
+	}
+}
@@ -6,6 +6,8 @@
 package foundations
 
 import (
+	"slices"
+
 	"github.com/boergens/gotypst/syntax"
 )
 
@@ -26,6 +28,11 @@ type Styles struct {
 	Rules []StyleRule
 	// Recipes contains the show rule recipes.
 	Recipes []*Recipe
+	// Revoked contains the indices of recipes that no longer apply, as
+	// returned by StyleChain.Recipes. The output of a show rule revokes
+	// the rule so that it does not match its own output again.
+	// Corresponds to Rust's Style::Revocation variant.
+	Revoked []int
 }
 
 // NewStyles creates a new empty Styles collection.
@@ -38,7 +45,7 @@ func NewStyles() *Styles {
 
 // IsEmpty returns true if there are no rules or recipes.
 func (s *Styles) IsEmpty() bool {
-	return s == nil || (len(s.Rules) == 0 && len(s.Recipes) == 0)
+	return s == nil || (len(s.Rules) == 0 && len(s.Recipes) == 0 && len(s.Revoked) == 0)
 }
 
 // AddRule adds a style rule.
@@ -51,6 +58,11 @@ func (s *Styles) AddRecipe(recipe *Recipe) {
 	s.Recipes = append(s.Recipes, recipe)
 }
 
+// Revoke revokes the recipe with the given index in the style chain.
+func (s *Styles) Revoke(index int) {
+	s.Revoked = append(s.Revoked, index)
+}
+
 // StyleRule represents a single style rule from a set rule.
 // Corresponds to Rust's Style::Property variant.
 type StyleRule struct {
@@ -156,6 +168,61 @@ func (sc *StyleChain) IsEmpty() bool {
 	return sc.parent.IsEmpty()
 }
 
+// Trunk returns the longest chain that all of the given chains extend.
+// Chains share a link only if one was chained onto the other, so the trunk
+// holds exactly the styles that apply to all of them.
+// Matches Rust: StyleChain::trunk
+func Trunk(chains ...*StyleChain) *StyleChain {
+	if len(chains) == 0 {
+		return nil
+	}
+	trunk := chains[0]
+	for _, chain := range chains[1:] {
+		a, b := trunk, chain
+		da, db := a.depth(), b.depth()
+		for ; da > db; da-- {
+			a = a.parent
+		}
+		for ; db > da; db-- {
+			b = b.parent
+		}
+		for a != b {
+			a, b = a.parent, b.parent
+		}
+		trunk = a
+	}
+	return trunk
+}
+
+// Suffix returns the styles that the chain adds on top of trunk, which
+// must be one of its ancestors, flattened from outermost to innermost.
+// Chaining them onto trunk yields a chain with the same styles.
+// Matches Rust: StyleChain::suffix
+func (sc *StyleChain) Suffix(trunk *StyleChain) *Styles {
+	var levels []*Styles
+	for chain := sc; chain != nil && chain != trunk; chain = chain.parent {
+		if chain.styles != nil {
+			levels = append(levels, chain.styles)
+		}
+	}
+	suffix := NewStyles()
+	for i := len(levels) - 1; i >= 0; i-- {
+		suffix.Rules = append(suffix.Rules, levels[i].Rules...)
+		suffix.Recipes = append(suffix.Recipes, levels[i].Recipes...)
+		suffix.Revoked = append(suffix.Revoked, levels[i].Revoked...)
+	}
+	return suffix
+}
+
+// depth returns the number of links in the chain.
+func (sc *StyleChain) depth() int {
+	n := 0
+	for chain := sc; chain != nil; chain = chain.parent {
+		n++
+	}
+	return n
+}
+
 // ToStyles converts the chain to a flat Styles struct.
 // This is an alias for AllStyles for compatibility.
 func (sc *StyleChain) ToStyles() *Styles {
@@ -270,6 +337,17 @@ func (sc *StyleChain) Recipes() []*Recipe {
 	return result
 }
 
+// IsRevoked reports whether the recipe with the given index, as returned by
+// Recipes, has been revoked somewhere in the chain.
+func (sc *StyleChain) IsRevoked(index int) bool {
+	for chain := sc; chain != nil; chain = chain.parent {
+		if chain.styles != nil && slices.Contains(chain.styles.Revoked, index) {
+			return true
+		}
+	}
+	return false
+}
+
 // AllStyles returns a flattened Styles containing all rules from the chain.
 // Rules are ordered from outermost to innermost.
 func (sc *StyleChain) AllStyles() *Styles {
@@ -279,6 +357,7 @@ func (sc *StyleChain) AllStyles() *Styles {
 
 	var allRules []StyleRule
 	var allRecipes []*Recipe
+	var allRevoked []int
 
 	// Collect from outermost to innermost
 	var levels []*StyleChain
@@ -290,16 +369,18 @@ func (sc *StyleChain) AllStyles() *Styles {
 		if levels[i].styles != nil {
 			allRules = append(allRules, levels[i].styles.Rules...)
 			allRecipes = append(allRecipes, levels[i].styles.Recipes...)
+			allRevoked = append(allRevoked, levels[i].styles.Revoked...)
 		}
 	}
 
-	if len(allRules) == 0 && len(allRecipes) == 0 {
+	if len(allRules) == 0 && len(allRecipes) == 0 && len(allRevoked) == 0 {
 		return nil
 	}
 
 	return &Styles{
 		Rules:   allRules,
 		Recipes: allRecipes,
+		Revoked: allRevoked,
 	}
 }
 
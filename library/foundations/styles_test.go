@@ -0,0 +1,130 @@
+package foundations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestStyleChainRevoked(t *testing.T) {
+	outer := NewStyles()
+	outer.AddRecipe(NewRecipe(nil, NoneTransformation{}, syntax.Detached()))
+	chain := NewStyleChain(outer)
+	if chain.IsRevoked(0) {
+		t.Fatal("recipe revoked before its output was visited")
+	}
+
+	revocation := NewStyles()
+	revocation.Revoke(0)
+	inner := chain.Chain(revocation)
+	if inner == chain {
+		t.Fatal("chaining a revocation must not be skipped")
+	}
+	if !inner.IsRevoked(0) || inner.IsRevoked(1) || chain.IsRevoked(0) {
+		t.Errorf("revoked = %v, %v, %v", inner.IsRevoked(0), inner.IsRevoked(1), chain.IsRevoked(0))
+	}
+	if all := inner.AllStyles(); len(all.Revoked) != 1 || all.Revoked[0] != 0 {
+		t.Errorf("flattened revocations = %v", all.Revoked)
+	}
+}
+
+func TestCheckShowDepth(t *testing.T) {
+	route := NewRoute()
+	for range MaxShowRuleDepth {
+		route.Increase()
+	}
+	if err := route.CheckShowDepth(); err != nil {
+		t.Fatalf("depth %d: %v", route.Len(), err)
+	}
+	route.Increase()
+	err := route.CheckShowDepth()
+	if err == nil || !strings.Contains(err.Error(), "maximum show rule depth exceeded") || !strings.Contains(err.Error(), "matches its own output") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestStyleChainScoping(t *testing.T) {
+	textFunc := "text"
+	set := func(fill Value) *Styles {
+		styles := NewStyles()
+		styles.AddRule(StyleRule{
+			Func: &Func{Name: &textFunc},
+			Args: &Args{Items: []Arg{namedArg("fill", fill)}},
+		})
+		return styles
+	}
+	red, blue := NewRgba(1, 0, 0, 1), NewRgba(0, 0, 1, 1)
+
+	outer := NewStyleChain(set(red))
+	inner := outer.Chain(set(blue))
+	if got := inner.Get("text", "fill"); got != blue {
+		t.Errorf("inner fill = %v, want %v", got, blue)
+	}
+	if got := outer.Get("text", "fill"); got != red {
+		t.Errorf("outer fill = %v, want %v", got, red)
+	}
+}
+
+func TestStyleChainTrunk(t *testing.T) {
+	textFunc := "text"
+	set := func(size Value) *Styles {
+		styles := NewStyles()
+		styles.AddRule(StyleRule{Func: &Func{Name: &textFunc}, Args: &Args{Items: []Arg{namedArg("size", size)}}})
+		return styles
+	}
+
+	root := NewStyleChain(set(Int(1)))
+	shared := root.Chain(set(Int(2)))
+	a := shared.Chain(set(Int(3))).Chain(set(Int(4)))
+	b := shared.Chain(set(Int(5)))
+
+	if got := Trunk(a, b, shared); got != shared {
+		t.Errorf("Trunk(a, b, shared) = %p, want %p", got, shared)
+	}
+	if got := Trunk(a); got != a {
+		t.Errorf("Trunk(a) = %p, want a", got)
+	}
+	if got := Trunk(a, NewStyleChain(set(Int(1)))); got != nil {
+		t.Errorf("Trunk of unrelated chains = %p, want nil", got)
+	}
+
+	suffix := a.Suffix(shared)
+	if len(suffix.Rules) != 2 || suffix.Rules[0].Args.Items[0].Value.V != Int(3) {
+		t.Fatalf("suffix = %+v, want the rules of the two inner levels", suffix.Rules)
+	}
+	if got := shared.Chain(suffix).Get("text", "size"); got != Int(4) {
+		t.Errorf("rechained size = %v, want 4", got)
+	}
+	if !shared.Suffix(shared).IsEmpty() {
+		t.Error("suffix of the trunk itself is not empty")
+	}
+}
+
+func TestStyledWithRecipe(t *testing.T) {
+	content := Content{Elements: []ContentElement{&SymbolElem{Text: "x"}}}
+	textFunc := "text"
+	styles := NewStyles()
+	styles.AddRule(StyleRule{Func: &Func{Name: &textFunc}, Args: &Args{Items: []Arg{namedArg("size", Int(2))}}})
+
+	// A show rule with a selector is applied during realization.
+	selective := NewRecipe(ElemSelector{Element: Element{Name: "heading"}}, NoneTransformation{}, syntax.Detached())
+	got, err := content.StyledWithRecipe(&Engine{}, &Context{}, selective)
+	if styled, ok := ContentElem(got).(*StyledElem); err != nil || !ok || len(styled.Styles.Recipes) != 1 {
+		t.Errorf("selective recipe = %#v, %v", got.Elements, err)
+	}
+
+	// A show rule without a selector transforms the content right away.
+	wrap := testFunc(func(values ...Value) (Value, error) {
+		return ContentValue{Content: values[0].(ContentValue).Content.StyledWithMap(styles)}, nil
+	})
+	eager := NewRecipe(nil, FuncTransformation{Func: wrap.Func}, syntax.Detached())
+	got, err = content.StyledWithRecipe(&Engine{}, &Context{}, eager)
+	styled, ok := ContentElem(got).(*StyledElem)
+	if err != nil || !ok || styled.Styles != styles {
+		t.Fatalf("eager recipe = %#v, %v", got.Elements, err)
+	}
+	if NewStyleChain(styled.Styles).GetInt("text", "size", 0) != 2 {
+		t.Error("set rule in show transform does not style its output")
+	}
+}
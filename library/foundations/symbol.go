@@ -0,0 +1,97 @@
+// Symbol modifier resolution for Typst.
+// Translated from typst-library/src/foundations/symbol.rs
+
+package foundations
+
+import (
+	"strings"
+)
+
+// NewSymbol creates a symbol value with the given variants. The empty key
+// holds the default variant.
+func NewSymbol(variants map[string]string) SymbolValue {
+	v := SymbolValue{Variants: variants}
+	if text, ok := v.find(); ok {
+		v.Char = firstRune(text)
+	}
+	return v
+}
+
+// Get returns the text of the variant selected by the applied modifiers.
+//
+// Matches Rust: Symbol::get
+func (v SymbolValue) Get() string {
+	if text, ok := v.find(); ok {
+		return text
+	}
+	return string(v.Char)
+}
+
+// Modified applies a modifier to the symbol. It reports false if no variant
+// of the symbol has all applied modifiers.
+//
+// Matches Rust: Symbol::modified
+func (v SymbolValue) Modified(modifier string) (SymbolValue, bool) {
+	modifiers := make([]string, len(v.Modifiers), len(v.Modifiers)+1)
+	copy(modifiers, v.Modifiers)
+	modified := SymbolValue{Variants: v.Variants, Modifiers: append(modifiers, modifier)}
+	text, ok := modified.find()
+	if !ok {
+		return v, false
+	}
+	modified.Char = firstRune(text)
+	return modified, true
+}
+
+// find selects the variant that has all applied modifiers and the fewest
+// others. Modifiers may be given in any order. Ties are broken by the
+// variant key to keep the choice deterministic.
+//
+// Matches Rust: fn find in typst-library/src/foundations/symbol.rs
+func (v SymbolValue) find() (string, bool) {
+	var best, bestKey string
+	bestTotal := -1
+	for key, text := range v.Variants {
+		var parts []string
+		if key != "" {
+			parts = strings.Split(key, ".")
+		}
+		if !containsAll(parts, v.Modifiers) {
+			continue
+		}
+		if bestTotal < 0 || len(parts) < bestTotal || (len(parts) == bestTotal && key < bestKey) {
+			best, bestKey, bestTotal = text, key, len(parts)
+		}
+	}
+	return best, bestTotal >= 0
+}
+
+// content returns the symbol as content.
+func (v SymbolValue) content() Content {
+	return Content{Elements: []ContentElement{&SymbolElem{Text: v.Get()}}}
+}
+
+// containsAll reports whether every modifier is one of the parts.
+func containsAll(parts, modifiers []string) bool {
+	for _, m := range modifiers {
+		found := false
+		for _, p := range parts {
+			if p == m {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// firstRune returns the first rune of s, or zero if s is empty.
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package foundations
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestSymbolModifiers(t *testing.T) {
+	arrow := NewSymbol(map[string]string{
+		"r":             "→",
+		"l":             "←",
+		"r.double":      "⇒",
+		"r.double.long": "⟹",
+		"r.long":        "⟶",
+	})
+
+	tests := []struct {
+		name      string
+		modifiers []string
+		want      string
+		ok        bool
+	}{
+		{"single", []string{"r"}, "→", true},
+		{"fewest extra", []string{"double"}, "⇒", true},
+		{"chained", []string{"r", "double"}, "⇒", true},
+		{"any order", []string{"double", "r"}, "⇒", true},
+		{"three", []string{"r", "long", "double"}, "⟹", true},
+		{"unknown", []string{"r", "curly"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sym := arrow
+			ok := true
+			for _, m := range tt.modifiers {
+				if sym, ok = sym.Modified(m); !ok {
+					break
+				}
+			}
+			if ok != tt.ok {
+				t.Fatalf("Modified(%v) ok = %v, want %v", tt.modifiers, ok, tt.ok)
+			}
+			if ok && sym.Get() != tt.want {
+				t.Errorf("Get() = %q, want %q", sym.Get(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbolDefaultVariant(t *testing.T) {
+	sym := NewSymbol(map[string]string{"": "α", "alt": "ϵ"})
+	if sym.Get() != "α" || sym.Char != 'α' {
+		t.Errorf("Get() = %q, Char = %q, want α", sym.Get(), sym.Char)
+	}
+
+	alt, ok := sym.Modified("alt")
+	if !ok || alt.Char != 'ϵ' {
+		t.Errorf("Modified(alt) = %q, %v, want ϵ", alt.Char, ok)
+	}
+	if sym.Get() != "α" {
+		t.Errorf("Modified changed the original symbol to %q", sym.Get())
+	}
+}
+
+func TestSymbolPlainChar(t *testing.T) {
+	sym := SymbolValue{Char: '→'}
+	if sym.Get() != "→" {
+		t.Errorf("Get() = %q, want →", sym.Get())
+	}
+	if _, ok := sym.Modified("r"); ok {
+		t.Error("plain character should not accept modifiers")
+	}
+
+	content := sym.Display()
+	if len(content.Elements) != 1 {
+		t.Fatalf("Display() has %d elements, want 1", len(content.Elements))
+	}
+	if elem, ok := content.Elements[0].(*SymbolElem); !ok || elem.Text != "→" {
+		t.Errorf("Display() = %#v, want symbol →", content.Elements[0])
+	}
+}
+
+func TestScopesGetInMath(t *testing.T) {
+	math := NewScope()
+	math.Define("alpha", NewSymbol(map[string]string{"": "α"}), syntax.Detached())
+	math.Define("pi", Str("math"), syntax.Detached())
+
+	global := NewScope()
+	global.Define("math", ModuleValue{Module: &Module{Name: "math", Scope: math}}, syntax.Detached())
+	global.Define("pi", Str("global"), syntax.Detached())
+	global.Define("red", Str("red"), syntax.Detached())
+
+	scopes := NewScopes(global)
+	if b := scopes.GetInMath("alpha"); b == nil {
+		t.Error("alpha not found in math")
+	}
+	if b := scopes.GetInMath("pi"); b == nil || b.Value() != Str("math") {
+		t.Errorf("math scope should shadow the global scope, got %v", b)
+	}
+	if b := scopes.GetInMath("red"); b == nil {
+		t.Error("global bindings should be visible in math")
+	}
+	if b := scopes.Get("alpha"); b != nil {
+		t.Error("alpha should not be found outside of math")
+	}
+
+	scopes.Define("pi", Str("local"), syntax.Detached())
+	if b := scopes.GetInMath("pi"); b == nil || b.Value() != Str("local") {
+		t.Errorf("local bindings should take precedence, got %v", b)
+	}
+}
@@ -24,7 +24,11 @@
 //   - cast.go: Type conversion utilities
 package foundations
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/syntax"
+)
 
 // Value represents a runtime value in the Typst evaluator.
 //
@@ -78,6 +82,9 @@ const (
 	TypeDyn
 	TypeStyles
 	TypeVersion
+	TypeLocation
+	TypeSelector
+	TypeCounter
 )
 
 // String returns the type name.
@@ -143,6 +150,12 @@ func (t Type) String() string {
 		return "styles"
 	case TypeVersion:
 		return "version"
+	case TypeLocation:
+		return "location"
+	case TypeSelector:
+		return "selector"
+	case TypeCounter:
+		return "counter"
 	default:
 		return fmt.Sprintf("Type(%d)", t)
 	}
@@ -191,6 +204,54 @@ func (TypeValue) isValue()           {}
 // Get returns the wrapped type.
 func (v TypeValue) Get() Type { return v.Inner }
 
+// typeConstructors holds the functions that are called when a type is
+// called like a function, as in `int("10")`.
+var typeConstructors = map[Type]func(args *Args) (Value, error){
+	TypeInt:      IntConstruct,
+	TypeFloat:    FloatConstruct,
+	TypeStr:      StrConstruct,
+	TypeArray:    ArrayConstruct,
+	TypeDict:     DictConstruct,
+	TypeType:     TypeConstruct,
+	TypeSelector: SelectorConstruct,
+	TypeArgs:     ArgsConstruct,
+	TypeLabel:    LabelConstruct,
+}
+
+// Constructor returns the type's constructor function, if it has one.
+// Matches Rust: Type::constructor
+func (t Type) Constructor() (*Func, bool) {
+	construct, ok := typeConstructors[t]
+	if !ok {
+		return nil, false
+	}
+	name := t.Ident()
+	return &Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: NativeFunc{
+			Func: func(engine Engine, context Context, args *Args) (Value, error) {
+				return construct(args)
+			},
+			Info:  &FuncInfo{Name: name},
+			Scope: t.Scope(),
+		},
+	}, true
+}
+
+// TypeConstruct determines the type of a value.
+// Matches Rust: Type::construct (the `type` function)
+func TypeConstruct(args *Args) (Value, error) {
+	value, err := args.Expect("value")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	return TypeValue{Inner: value.V.Type()}, nil
+}
+
 // ----------------------------------------------------------------------------
 // Value Conversion Helpers
 // ----------------------------------------------------------------------------
@@ -260,8 +321,12 @@ func AsDict(v Value) (*Dict, bool) {
 
 // AsFunc attempts to convert a value to a function.
 func AsFunc(v Value) (*Func, bool) {
-	if f, ok := v.(FuncValue); ok {
-		return f.Func, true
+	switch v := v.(type) {
+	case FuncValue:
+		return v.Func, true
+	case TypeValue:
+		// Types with a constructor can be called.
+		return v.Inner.Constructor()
 	}
 	return nil, false
 }
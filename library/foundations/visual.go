@@ -44,10 +44,15 @@ func (TilingValue) isValue()           {}
 type SymbolValue struct {
 	// Char is the symbol character.
 	Char rune
+	// Variants maps dot-separated modifier sets to the symbol's text in
+	// that variant. It is nil for a plain character.
+	Variants map[string]string
+	// Modifiers are the modifiers applied to the symbol so far.
+	Modifiers []string
 }
 
 func (SymbolValue) Type() Type         { return TypeSymbol }
-func (v SymbolValue) Display() Content { return Content{} }
+func (v SymbolValue) Display() Content { return v.content() }
 func (v SymbolValue) Clone() Value     { return v }
 func (SymbolValue) isValue()           {}
 
@@ -0,0 +1,380 @@
+// Counters for Typst.
+// Translated from typst-library/src/introspection/counter.rs
+
+package introspection
+
+import (
+	"reflect"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// CounterState is the state of a counter: one number per level, like
+// (1, 2) for the second subsection of the first section.
+// Corresponds to Rust's CounterState struct.
+type CounterState []int
+
+// initialCounterState returns the state of a counter before any update.
+// The page counter starts at one, all other counters at zero.
+// Matches Rust: CounterState::init
+func initialCounterState(key foundations.CounterKey) CounterState {
+	if _, ok := key.(foundations.PageCounterKey); ok {
+		return CounterState{1}
+	}
+	return CounterState{0}
+}
+
+// Step advances the counter at the given level by some amount. Deeper
+// levels are dropped and missing levels are filled with zeros.
+// Matches Rust: CounterState::step
+func (s CounterState) Step(level, by int) CounterState {
+	out := append(CounterState(nil), s...)
+	for len(out) < level {
+		out = append(out, 0)
+	}
+	out = out[:level]
+	if len(out) > 0 {
+		out[len(out)-1] += by
+	}
+	return out
+}
+
+// First returns the number at the first level.
+// Matches Rust: CounterState::first
+func (s CounterState) First() int {
+	if len(s) == 0 {
+		return 0
+	}
+	return s[0]
+}
+
+// Display formats the state with a numbering.
+// Matches Rust: CounterState::display
+func (s CounterState) Display(engine *foundations.Engine, context *foundations.Context, numbering *model.Numbering) (foundations.Value, error) {
+	return numbering.Apply(engine, context, s)
+}
+
+// Update applies an update to the state.
+// Matches Rust: CounterState::update
+func (s CounterState) Update(engine *foundations.Engine, context *foundations.Context, update CounterUpdate) (CounterState, error) {
+	switch u := update.(type) {
+	case CounterUpdateSet:
+		return append(CounterState(nil), u.State...), nil
+	case CounterUpdateStep:
+		return s.Step(u.Level, 1), nil
+	case CounterUpdateFunc:
+		args := foundations.NewArgs(u.Func.Span)
+		for _, n := range s {
+			args.Push(u.Func.Span, foundations.Int(n))
+		}
+		value, err := u.Func.Call(engine, context, args)
+		if err != nil {
+			return nil, err
+		}
+		return counterStateFromValue(value, u.Func.Span)
+	}
+	return s, nil
+}
+
+// counterStateFromValue casts an integer or an array of integers to a
+// counter state.
+func counterStateFromValue(v foundations.Value, span syntax.Span) (CounterState, error) {
+	var values []foundations.Value
+	switch v := v.(type) {
+	case foundations.Int:
+		values = []foundations.Value{v}
+	case *foundations.Array:
+		values = v.Items()
+	default:
+		return nil, &foundations.TypeMismatchError{Expected: "integer or array", Got: v.Type().String(), Span: span}
+	}
+	state := make(CounterState, len(values))
+	for i, value := range values {
+		n, ok := value.(foundations.Int)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{Expected: "integer", Got: value.Type().String(), Span: span}
+		}
+		if n < 0 {
+			return nil, foundations.NewSourceError(span, "number must be at least zero")
+		}
+		state[i] = int(n)
+	}
+	return state, nil
+}
+
+// CounterUpdate is a change of a counter's state.
+// Corresponds to Rust's CounterUpdate enum.
+type CounterUpdate interface {
+	isCounterUpdate()
+}
+
+// CounterUpdateSet sets the counter to a state.
+type CounterUpdateSet struct {
+	State CounterState
+}
+
+func (CounterUpdateSet) isCounterUpdate() {}
+
+// CounterUpdateStep steps the counter at a level, starting from one.
+type CounterUpdateStep struct {
+	Level int
+}
+
+func (CounterUpdateStep) isCounterUpdate() {}
+
+// CounterUpdateFunc computes the new state from the numbers of the
+// current one.
+type CounterUpdateFunc struct {
+	Func *foundations.Func
+}
+
+func (CounterUpdateFunc) isCounterUpdate() {}
+
+// CounterUpdateElem is placed in content by `counter(..).step()` and
+// `counter(..).update(..)` to change a counter at its location.
+// Corresponds to Rust's CounterUpdateElem.
+type CounterUpdateElem struct {
+	Key    foundations.CounterKey
+	Update CounterUpdate
+}
+
+func (*CounterUpdateElem) IsContentElement() {}
+
+// Count is implemented by elements that decide themselves how they step
+// the counter of their element function. Headings, for example, step at
+// their level and not at all when they are not numbered. Other elements
+// step the counter by one.
+// Corresponds to Rust's Count trait.
+type Count interface {
+	// Update returns the update of the element, or nil if it does not
+	// change the counter.
+	Update() CounterUpdate
+}
+
+// Counter computes the state of a counter from the elements of a
+// document.
+// Corresponds to Rust's Counter struct.
+type Counter struct {
+	Key foundations.CounterKey
+}
+
+// Sequence returns the state of the counter before the first element
+// counted by it, followed by the state after each such element, in
+// document order.
+// Matches Rust: Counter::sequence
+func (c Counter) Sequence(engine *foundations.Engine, introspector *Introspector) ([]CounterState, error) {
+	state := initialCounterState(c.Key)
+	sequence := []CounterState{state}
+	for _, e := range introspector.elems {
+		update, ok := c.update(e)
+		if !ok {
+			continue
+		}
+		if update != nil {
+			next, err := state.Update(engine, foundations.NewContext(), update)
+			if err != nil {
+				return nil, err
+			}
+			state = next
+		}
+		sequence = append(sequence, state)
+	}
+	return sequence, nil
+}
+
+// At returns the state of the counter at a location, which includes the
+// update of an element at the location itself.
+// Matches Rust: Counter::at_loc
+func (c Counter) At(engine *foundations.Engine, introspector *Introspector, location Location) (CounterState, error) {
+	sequence, err := c.Sequence(engine, introspector)
+	if err != nil {
+		return nil, err
+	}
+	offset := 0
+	for _, e := range introspector.elems {
+		if _, ok := c.update(e); ok {
+			offset++
+		}
+		if e.location == location {
+			return sequence[offset], nil
+		}
+	}
+	return nil, &foundations.ConstructorError{Message: "location is not in the document"}
+}
+
+// Final returns the state of the counter at the end of the document.
+// Matches Rust: Counter::final_
+func (c Counter) Final(engine *foundations.Engine, introspector *Introspector) (CounterState, error) {
+	sequence, err := c.Sequence(engine, introspector)
+	if err != nil {
+		return nil, err
+	}
+	return sequence[len(sequence)-1], nil
+}
+
+// update returns how an element changes the counter and whether the
+// element is counted at all. The update is nil for counted elements that
+// leave the state unchanged.
+func (c Counter) update(e locatedElem) (CounterUpdate, bool) {
+	if elem, ok := e.elem.(*CounterUpdateElem); ok {
+		if reflect.DeepEqual(elem.Key, c.Key) {
+			return elem.Update, true
+		}
+		return nil, false
+	}
+	key, ok := c.Key.(foundations.SelectorCounterKey)
+	if !ok || !matchesSelector(e, key.Selector) {
+		return nil, false
+	}
+	switch elem := e.elem.(type) {
+	case Count:
+		return elem.Update(), true
+	case *model.HeadingElem:
+		if elem.RefNumbering() == nil {
+			return nil, true
+		}
+		return CounterUpdateStep{Level: elem.ResolvedLevel()}, true
+	}
+	return CounterUpdateStep{Level: 1}, true
+}
+
+// CounterFunc creates the counter function, which returns the counter of
+// the pages, of the elements matching a selector, or of a name, as in
+// `counter(heading)`. The counter's `step` and `update` methods return
+// content that changes the counter where it is placed.
+//
+// Matches Rust: Counter::construct
+func CounterFunc() *foundations.Func {
+	name := "counter"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: counterNative,
+			Info: &foundations.FuncInfo{
+				Name:   name,
+				Params: []foundations.ParamInfo{{Name: "key", Type: foundations.TypeDyn}},
+			},
+		},
+	}
+}
+
+func counterNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	arg, err := args.Expect("key")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	key, err := castCounterKey(arg)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.CounterValue{Key: key}, nil
+}
+
+// castCounterKey casts the argument of `counter(..)` to a key.
+// Matches Rust: impl FromValue for CounterKey
+func castCounterKey(arg syntax.Spanned[foundations.Value]) (foundations.CounterKey, error) {
+	switch v := arg.V.(type) {
+	case foundations.Str:
+		return foundations.StrCounterKey{Name: string(v)}, nil
+	case foundations.FuncValue:
+		if v.Func.Name != nil && *v.Func.Name == "page" {
+			return foundations.PageCounterKey{}, nil
+		}
+	}
+	selector, err := foundations.CastLocatableSelector(arg.V)
+	if err != nil {
+		if mismatch, ok := err.(*foundations.TypeMismatchError); ok {
+			mismatch.Expected = "string, " + mismatch.Expected
+			mismatch.Field, mismatch.Span = "key", arg.Span
+		}
+		return nil, err
+	}
+	return foundations.SelectorCounterKey{Selector: selector.Selector}, nil
+}
+
+func init() {
+	scope := foundations.NewScope()
+	define := func(name string, params []foundations.ParamInfo, f func(key foundations.CounterKey, args *foundations.Args) (CounterUpdate, error)) {
+		fname := name
+		native := func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+			self, err := args.Expect("self")
+			if err != nil {
+				return nil, err
+			}
+			counter, ok := self.V.(foundations.CounterValue)
+			if !ok {
+				return nil, &foundations.TypeMismatchError{Expected: "counter", Got: self.V.Type().String(), Field: "self", Span: self.Span}
+			}
+			update, err := f(counter.Key, args)
+			if err != nil {
+				return nil, err
+			}
+			if err := args.Finish(); err != nil {
+				return nil, err
+			}
+			elem := &CounterUpdateElem{Key: counter.Key, Update: update}
+			return foundations.ContentValue{Content: foundations.Content{
+				Elements: []foundations.ContentElement{elem},
+			}}, nil
+		}
+		scope.Define(name, foundations.FuncValue{Func: &foundations.Func{
+			Name: &fname,
+			Span: syntax.Detached(),
+			Repr: foundations.NativeFunc{
+				Func: native,
+				Info: &foundations.FuncInfo{
+					Name:   name,
+					Params: append([]foundations.ParamInfo{{Name: "self", Type: foundations.TypeCounter}}, params...),
+				},
+			},
+		}}, syntax.Detached())
+	}
+
+	// Matches Rust: Counter::step
+	define("step", []foundations.ParamInfo{
+		{Name: "level", Type: foundations.TypeInt, Named: true, Default: foundations.Int(1)},
+	}, func(key foundations.CounterKey, args *foundations.Args) (CounterUpdate, error) {
+		level := 1
+		if arg := args.Named("level"); arg != nil {
+			n, ok := arg.V.(foundations.Int)
+			if !ok {
+				return nil, &foundations.TypeMismatchError{Expected: "integer", Got: arg.V.Type().String(), Field: "level", Span: arg.Span}
+			}
+			if n < 1 {
+				return nil, foundations.NewSourceError(arg.Span, "number must be positive")
+			}
+			level = int(n)
+		}
+		return CounterUpdateStep{Level: level}, nil
+	})
+
+	// Matches Rust: Counter::update
+	define("update", []foundations.ParamInfo{
+		{Name: "update", Type: foundations.TypeDyn},
+	}, func(key foundations.CounterKey, args *foundations.Args) (CounterUpdate, error) {
+		arg, err := args.Expect("update")
+		if err != nil {
+			return nil, err
+		}
+		if f, ok := arg.V.(foundations.FuncValue); ok {
+			return CounterUpdateFunc{Func: f.Func}, nil
+		}
+		state, err := counterStateFromValue(arg.V, arg.Span)
+		if err != nil {
+			if mismatch, ok := err.(*foundations.TypeMismatchError); ok {
+				mismatch.Expected = "integer, array, or function"
+				mismatch.Field = "update"
+			}
+			return nil, err
+		}
+		return CounterUpdateSet{State: state}, nil
+	})
+
+	foundations.RegisterTypeScope(foundations.TypeCounter, scope)
+}
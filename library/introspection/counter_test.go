@@ -0,0 +1,138 @@
+package introspection
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// sectionElem is a heading-like element that steps its counter at its
+// level.
+type sectionElem struct {
+	Level    int
+	Numbered bool
+}
+
+func (*sectionElem) IsContentElement() {}
+
+func (e *sectionElem) Update() CounterUpdate {
+	if !e.Numbered {
+		return nil
+	}
+	return CounterUpdateStep{Level: e.Level}
+}
+
+func TestCounterStateStep(t *testing.T) {
+	tests := []struct {
+		state CounterState
+		level int
+		want  CounterState
+	}{
+		{CounterState{0}, 1, CounterState{1}},
+		{CounterState{1}, 2, CounterState{1, 1}},
+		{CounterState{1, 2, 3}, 2, CounterState{1, 3}},
+		{CounterState{2}, 3, CounterState{2, 0, 1}},
+	}
+	for _, tt := range tests {
+		got := tt.state.Step(tt.level, 1)
+		if !equalStates(got, tt.want) {
+			t.Errorf("%v.Step(%d) = %v, want %v", tt.state, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestCounterSequence(t *testing.T) {
+	flags := TagFlags{Introspectable: true}
+	elems := []*sectionElem{
+		{Level: 1, Numbered: true},
+		{Level: 2, Numbered: true},
+		{Level: 2, Numbered: false},
+		{Level: 2, Numbered: true},
+		{Level: 1, Numbered: true},
+	}
+	var content []foundations.ContentElement
+	for i, elem := range elems {
+		content = append(content, NewStartTag(elem, Location{Hash: uint64(i + 1)}, flags))
+	}
+	reset := &CounterUpdateElem{
+		Key:    foundations.SelectorCounterKey{Selector: foundations.ElemSelector{Element: foundations.Element{Name: "section"}}},
+		Update: CounterUpdateSet{State: CounterState{7}},
+	}
+	content = append(content, NewStartTag(reset, Location{Hash: 6}, flags))
+	introspector := NewIntrospector(content)
+
+	counter := Counter{Key: foundations.SelectorCounterKey{Selector: foundations.ElemSelector{Element: foundations.Element{Name: "section"}}}}
+	numbering := &model.Numbering{}
+	numbering.Pattern, _ = model.ParseNumberingPattern("1.a")
+
+	wants := []string{"1", "1.a", "1.a", "1.b", "2", "7"}
+	for i, want := range wants {
+		state, err := counter.At(&foundations.Engine{}, introspector, Location{Hash: uint64(i + 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := state.Display(&foundations.Engine{}, foundations.NewContext(), numbering)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != foundations.Str(want) {
+			t.Errorf("state at %d = %v, want %s", i+1, got, want)
+		}
+	}
+
+	final, err := counter.Final(&foundations.Engine{}, introspector)
+	if err != nil || !equalStates(final, CounterState{7}) {
+		t.Errorf("Final() = %v, %v", final, err)
+	}
+
+	page, err := Counter{Key: foundations.PageCounterKey{}}.Final(&foundations.Engine{}, introspector)
+	if err != nil || !equalStates(page, CounterState{1}) {
+		t.Errorf("page Final() = %v, %v", page, err)
+	}
+}
+
+func TestCounterMethods(t *testing.T) {
+	value, err := counterNative(foundations.Engine{}, foundations.Context{}, foundations.NewArgs(syntax.Detached(), foundations.Str("notes")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repr := foundations.Repr(value); repr != `counter("notes")` {
+		t.Errorf("repr = %s", repr)
+	}
+
+	call := func(method string, args *foundations.Args) *CounterUpdateElem {
+		t.Helper()
+		args.Insert(0, syntax.Detached(), value)
+		f := foundations.TypeCounter.Scope().Get(method).Read().(foundations.FuncValue).Func
+		out, err := f.Call(&foundations.Engine{}, foundations.NewContext(), args)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		return out.(foundations.ContentValue).Content.Elements[0].(*CounterUpdateElem)
+	}
+
+	level := foundations.Str("level")
+	step := call("step", &foundations.Args{Items: []foundations.Arg{{Name: &level, Value: syntax.Spanned[foundations.Value]{V: foundations.Int(2)}}}})
+	if step.Update != (CounterUpdateStep{Level: 2}) || step.Key != (foundations.StrCounterKey{Name: "notes"}) {
+		t.Errorf("step() = %+v", step)
+	}
+
+	update := call("update", foundations.NewArgs(syntax.Detached(), foundations.NewArray(foundations.Int(3), foundations.Int(1))))
+	if set, ok := update.Update.(CounterUpdateSet); !ok || !equalStates(set.State, CounterState{3, 1}) {
+		t.Errorf("update() = %+v", update.Update)
+	}
+}
+
+func equalStates(a, b CounterState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+// Here function for Typst.
+// Translated from typst-library/src/introspection/here.rs
+
+package introspection
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// HereFunc creates the here function, which returns the location of the
+// context expression it is called in.
+func HereFunc() *foundations.Func {
+	name := "here"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: hereNative,
+			Info: &foundations.FuncInfo{Name: "here"},
+		},
+	}
+}
+
+// hereNative implements the here() function.
+// Matches Rust: pub fn here(context: Tracked<Context>) -> HintedStrResult<Location>
+func hereNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	location, err := context.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+	return foundations.LocationValue{Location: location}, nil
+}
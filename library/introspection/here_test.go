@@ -0,0 +1,28 @@
+package introspection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestHere(t *testing.T) {
+	native := HereFunc().Repr.(foundations.NativeFunc)
+	location := &foundations.Location{Hash: 42}
+
+	value, err := native.Func(foundations.Engine{}, *foundations.NewContextWith(location, nil), foundations.NewArgs(syntax.Detached()))
+	if err != nil {
+		t.Fatalf("here() failed: %v", err)
+	}
+	if loc, ok := value.(foundations.LocationValue); !ok || loc.Location != location {
+		t.Errorf("here() = %#v, want the context's location", value)
+	}
+
+	_, err = native.Func(foundations.Engine{}, *foundations.NewContext(), foundations.NewArgs(syntax.Detached()))
+	var contextErr *foundations.ContextError
+	if !errors.As(err, &contextErr) {
+		t.Errorf("here() without context = %v, want a context error", err)
+	}
+}
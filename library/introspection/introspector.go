@@ -0,0 +1,145 @@
+// Introspector for Typst.
+// Translated from typst-library/src/introspection/introspector.rs
+
+package introspection
+
+import "github.com/boergens/gotypst/library/foundations"
+
+// Introspector can be queried for elements and their locations.
+//
+// It is built from the introspectable start tags of realized content, which
+// appear in document order.
+type Introspector struct {
+	elems []locatedElem
+}
+
+// locatedElem is an element together with its assigned location.
+type locatedElem struct {
+	elem     foundations.ContentElement
+	location Location
+}
+
+// NewIntrospector creates an introspector from realized content. Sequences
+// are searched for tags recursively.
+//
+// Matches Rust: Introspector::paged
+func NewIntrospector(content []foundations.ContentElement) *Introspector {
+	i := &Introspector{}
+	i.collect(content)
+	return i
+}
+
+// collect records the elements of all introspectable start tags.
+func (i *Introspector) collect(content []foundations.ContentElement) {
+	for _, elem := range content {
+		switch e := elem.(type) {
+		case *TagElem:
+			if e.Tag.Kind == TagStart && e.Tag.Flags.Introspectable {
+				i.elems = append(i.elems, locatedElem{elem: e.Tag.Elem, location: e.Tag.Location})
+			}
+		case *foundations.SequenceElem:
+			for _, child := range e.Children {
+				i.collect(child.Elements)
+			}
+		}
+	}
+}
+
+// Len returns the number of introspectable elements.
+func (i *Introspector) Len() int {
+	return len(i.elems)
+}
+
+// Query returns all elements matching the selector in document order.
+//
+// Matches Rust: Introspector::query
+func (i *Introspector) Query(selector foundations.Selector) []foundations.ContentElement {
+	var out []foundations.ContentElement
+	for _, index := range i.matches(selector) {
+		out = append(out, i.elems[index].elem)
+	}
+	return out
+}
+
+// Location returns the location assigned to an element, if it is known to
+// the introspector.
+func (i *Introspector) Location(elem foundations.ContentElement) (Location, bool) {
+	for _, e := range i.elems {
+		if e.elem == elem {
+			return e.location, true
+		}
+	}
+	return Location{}, false
+}
+
+// matches returns the indices of the elements matching the selector.
+func (i *Introspector) matches(selector foundations.Selector) []int {
+	switch sel := selector.(type) {
+	case foundations.BeforeSelector:
+		end := i.matches(sel.End)
+		if len(end) == 0 {
+			return i.matches(sel.Selector)
+		}
+		return filterIndices(i.matches(sel.Selector), func(index int) bool {
+			return index < end[0] || (sel.Inclusive && index == end[0])
+		})
+	case foundations.AfterSelector:
+		start := i.matches(sel.Start)
+		if len(start) == 0 {
+			return i.matches(sel.Selector)
+		}
+		return filterIndices(i.matches(sel.Selector), func(index int) bool {
+			return index > start[0] || (sel.Inclusive && index == start[0])
+		})
+	}
+	var out []int
+	for index, e := range i.elems {
+		if matchesSelector(e, selector) {
+			out = append(out, index)
+		}
+	}
+	return out
+}
+
+// matchesSelector checks whether a located element matches a selector.
+// Before and after selectors depend on the document order and are handled
+// by the introspector itself.
+//
+// Matches Rust: Selector::matches
+func matchesSelector(e locatedElem, selector foundations.Selector) bool {
+	switch sel := selector.(type) {
+	case foundations.ElemSelector:
+		return sel.Matches(e.elem)
+	case foundations.LabelSelector:
+		label, ok := foundations.ElementFields(e.elem).Get("label")
+		return ok && label == foundations.LabelValue(sel.Label)
+	case foundations.LocationSelector:
+		return sel.Location != nil && sel.Location.Hash == e.location.Hash
+	case foundations.OrSelector:
+		for _, s := range sel.Selectors {
+			if matchesSelector(e, s) {
+				return true
+			}
+		}
+		return false
+	case foundations.AndSelector:
+		for _, s := range sel.Selectors {
+			if !matchesSelector(e, s) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// filterIndices keeps the indices for which keep returns true.
+func filterIndices(indices []int, keep func(int) bool) []int {
+	var out []int
+	for _, index := range indices {
+		if keep(index) {
+			out = append(out, index)
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package introspection
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// noteElem is a labelled element for testing.
+type noteElem struct {
+	Body string
+	foundations.ElemMeta
+}
+
+func (*noteElem) IsContentElement() {}
+
+func TestIntrospectorQuery(t *testing.T) {
+	intro := "intro"
+	first := &noteElem{Body: "first", ElemMeta: foundations.ElemMeta{Label: &intro}}
+	second := &noteElem{Body: "second"}
+	symbol := &foundations.SymbolElem{Text: "α"}
+	flags := TagFlags{Introspectable: true}
+
+	content := []foundations.ContentElement{
+		NewStartTag(first, Location{Hash: 1}, flags),
+		first,
+		NewEndTag(Location{Hash: 1}, 1, flags),
+		&foundations.SequenceElem{Children: []foundations.Content{{Elements: []foundations.ContentElement{
+			NewStartTag(symbol, Location{Hash: 2}, flags),
+			symbol,
+		}}}},
+		NewStartTag(second, Location{Hash: 3}, flags),
+		// Elements that are not introspectable are ignored.
+		NewStartTag(&noteElem{Body: "hidden"}, Location{Hash: 4}, TagFlags{Tagged: true}),
+	}
+	introspector := NewIntrospector(content)
+	if introspector.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", introspector.Len())
+	}
+
+	note := foundations.ElemSelector{Element: foundations.Element{Name: "note"}}
+	secondBody := foundations.NewDict()
+	secondBody.Set("body", foundations.Str("second"))
+	tests := []struct {
+		name     string
+		selector foundations.Selector
+		want     []foundations.ContentElement
+	}{
+		{"element", note, []foundations.ContentElement{first, second}},
+		{"where", foundations.ElemSelector{Element: note.Element, Fields: secondBody}, []foundations.ContentElement{second}},
+		{"label", foundations.LabelSelector{Label: "intro"}, []foundations.ContentElement{first}},
+		{"location", foundations.LocationSelector{Location: &foundations.Location{Hash: 2}}, []foundations.ContentElement{symbol}},
+		{"or", foundations.OrSelector{Selectors: []foundations.Selector{
+			foundations.ElemSelector{Element: foundations.Element{Name: "symbol"}},
+			foundations.LabelSelector{Label: "intro"},
+		}}, []foundations.ContentElement{first, symbol}},
+		{"before", foundations.BeforeSelector{Selector: note, End: foundations.ElemSelector{Element: foundations.Element{Name: "symbol"}}}, []foundations.ContentElement{first}},
+		{"after inclusive", foundations.AfterSelector{Selector: note, Start: foundations.LabelSelector{Label: "intro"}, Inclusive: true}, []foundations.ContentElement{first, second}},
+		{"after", foundations.AfterSelector{Selector: note, Start: foundations.LabelSelector{Label: "intro"}}, []foundations.ContentElement{second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := introspector.Query(tt.selector)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d elements, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("element %d = %#v, want %#v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if loc, ok := introspector.Location(second); !ok || loc.Hash != 3 {
+		t.Errorf("Location() = %v, %v", loc, ok)
+	}
+}
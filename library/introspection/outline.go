@@ -0,0 +1,109 @@
+// Outline entries for Typst.
+// Translated from typst-library/src/model/outline.rs
+
+package introspection
+
+import (
+	"strconv"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/library/model"
+)
+
+// OutlineEntries creates the entries of an outline from the elements
+// matching its target. Elements that are not outlined or deeper than the
+// outline's depth are left out. Each entry's body is the element's number,
+// if it has one, followed by its outline body. Numbers and page numbers
+// become content through packText, usually text.Packed, which is passed in
+// so that introspection does not import package text.
+//
+// Matches Rust: the entry collection in impl Show for Packed<OutlineElem>
+func OutlineEntries(engine *foundations.Engine, introspector *Introspector, outline *model.OutlineElem, packText func(string) foundations.Content) ([]*model.OutlineEntryElem, error) {
+	fill, err := outlineFill(outline.Fill, packText)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*model.OutlineEntryElem
+	for _, elem := range introspector.Query(outline.TargetSelector()) {
+		outlinable, ok := elem.(model.Outlinable)
+		if !ok || !outlinable.IsOutlined() {
+			continue
+		}
+		level := outlinable.OutlineLevel()
+		if outline.Depth != nil && int64(level) > *outline.Depth {
+			continue
+		}
+		location, ok := introspector.Location(elem)
+		if !ok {
+			continue
+		}
+
+		body, err := outlineEntryBody(engine, introspector, outlinable, location, packText)
+		if err != nil {
+			return nil, err
+		}
+		page, err := Counter{Key: foundations.PageCounterKey{}}.At(engine, introspector, location)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &model.OutlineEntryElem{
+			Level:   int64(level),
+			Element: foundations.Content{Elements: []foundations.ContentElement{elem}},
+			Body:    body,
+			Fill:    fill,
+			Page:    packText(strconv.Itoa(page.First())),
+		})
+	}
+	return entries, nil
+}
+
+// outlineEntryBody returns the body of an element's entry, prefixed with
+// the element's number if it is numbered.
+func outlineEntryBody(engine *foundations.Engine, introspector *Introspector, elem model.Outlinable, location Location, packText func(string) foundations.Content) (foundations.Content, error) {
+	refable, ok := elem.(model.Refable)
+	if !ok || refable.RefNumbering() == nil {
+		return elem.OutlineBody(), nil
+	}
+	key := foundations.SelectorCounterKey{Selector: foundations.ElemSelector{Element: foundations.Element{Name: refable.RefCounter()}}}
+	state, err := Counter{Key: key}.At(engine, introspector, location)
+	if err != nil {
+		return foundations.Content{}, err
+	}
+	number, err := state.Display(engine, foundations.NewContext(), refable.RefNumbering())
+	if err != nil {
+		return foundations.Content{}, err
+	}
+
+	var elements []foundations.ContentElement
+	switch n := number.(type) {
+	case foundations.Str:
+		elements = append(elements, packText(string(n)).Elements...)
+	case foundations.ContentValue:
+		elements = append(elements, n.Content.Elements...)
+	}
+	elements = append(elements, packText(" ").Elements...)
+	elements = append(elements, elem.OutlineBody().Elements...)
+	return foundations.Content{Elements: elements}, nil
+}
+
+// outlineFill resolves the fill of an outline. Auto is repeated periods.
+func outlineFill(fill foundations.Value, packText func(string) foundations.Content) (*foundations.Content, error) {
+	switch v := fill.(type) {
+	case nil, foundations.AutoValue:
+		dots := foundations.Content{Elements: []foundations.ContentElement{
+			&layout.RepeatElement{Body: packText(".")},
+		}}
+		return &dots, nil
+	case foundations.NoneValue:
+		return nil, nil
+	case foundations.ContentValue:
+		return &v.Content, nil
+	case foundations.Str:
+		content := packText(string(v))
+		return &content, nil
+	}
+	return nil, &foundations.TypeMismatchError{Expected: "content or none", Got: fill.Type().String(), Field: "fill"}
+}
@@ -0,0 +1,80 @@
+package introspection
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/library/model"
+)
+
+// plainText stands in for text elements in outline entries.
+type plainText struct{ text string }
+
+func (*plainText) IsContentElement() {}
+
+func (e *plainText) PlainText(b *strings.Builder) { b.WriteString(e.text) }
+
+func packPlain(s string) foundations.Content {
+	return foundations.Content{Elements: []foundations.ContentElement{&plainText{text: s}}}
+}
+
+func TestOutlineEntries(t *testing.T) {
+	level := func(n int64) *int64 { return &n }
+	no := false
+	numbering := foundations.Str("1.1")
+	headings := []*model.HeadingElem{
+		{Level: level(1), Numbering: numbering, Body: packPlain("Intro")},
+		{Level: level(2), Numbering: numbering, Body: packPlain("Scope")},
+		{Level: level(2), Numbering: numbering, Outlined: &no, Body: packPlain("Hidden")},
+		{Level: level(3), Numbering: numbering, Body: packPlain("Deep")},
+		{Level: level(1), Body: packPlain("Appendix")},
+	}
+	flags := TagFlags{Introspectable: true}
+	var content []foundations.ContentElement
+	for i, heading := range headings {
+		content = append(content, NewStartTag(heading, Location{Hash: uint64(i + 1)}, flags))
+	}
+	introspector := NewIntrospector(content)
+
+	depth := int64(2)
+	outline := &model.OutlineElem{Depth: &depth}
+	entries, err := OutlineEntries(&foundations.Engine{}, introspector, outline, packPlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		level int64
+		body  string
+	}{
+		{1, "1 Intro"},
+		{2, "1.1 Scope"},
+		{1, "Appendix"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		entry := entries[i]
+		if entry.Level != w.level || entry.Body.PlainText() != w.body {
+			t.Errorf("entry %d = level %d %q, want level %d %q", i, entry.Level, entry.Body.PlainText(), w.level, w.body)
+		}
+		if entry.Page.PlainText() != "1" {
+			t.Errorf("entry %d page = %q", i, entry.Page.PlainText())
+		}
+		if entry.Fill == nil {
+			t.Fatalf("entry %d has no fill", i)
+		}
+		if repeat, ok := entry.Fill.Elements[0].(*layout.RepeatElement); !ok || repeat.Body.PlainText() != "." {
+			t.Errorf("entry %d fill = %+v", i, entry.Fill.Elements[0])
+		}
+	}
+
+	outline.Fill = foundations.NoneValue{}
+	entries, err = OutlineEntries(&foundations.Engine{}, introspector, outline, packPlain)
+	if err != nil || entries[0].Fill != nil {
+		t.Errorf("fill none = %v, %v", entries[0].Fill, err)
+	}
+}
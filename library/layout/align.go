@@ -43,6 +43,7 @@ type AlignElement struct {
 	AlignmentStr string `typst:"alignment,positional,required,type=str"`
 	// Body is the content to align.
 	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
 }
 
 func (*AlignElement) IsContentElement() {}
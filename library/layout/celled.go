@@ -0,0 +1,107 @@
+package layout
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Celled is a grid or table property that can differ from cell to cell,
+// like `align` or `fill`. It is either a single value for all cells, an
+// array of values that cycles through the columns, or a function that
+// receives the column and row of a cell and returns its value, as in
+// `fill: (x, y) => if calc.odd(y) { luma(240) }`.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/grid/mod.rs
+type Celled struct {
+	// Value is the value for all cells, if neither Func nor Array is set.
+	Value foundations.Value
+	// Func computes the value of a cell from its column and row.
+	Func *foundations.Func
+	// Array holds the values of the columns, repeating for further
+	// columns.
+	Array []foundations.Value
+}
+
+// CastCelled casts a property value to a Celled. A function becomes a
+// per-cell function, a non-empty array a per-column list, and anything
+// else the value of all cells.
+// Matches Rust: impl FromValue for Celled<T>
+func CastCelled(v foundations.Value) Celled {
+	switch v := v.(type) {
+	case foundations.FuncValue:
+		return Celled{Func: v.Func}
+	case *foundations.Array:
+		if v.Len() > 0 {
+			return Celled{Array: v.Items()}
+		}
+	}
+	return Celled{Value: v}
+}
+
+// Resolve returns the value of the cell in column x and row y, calling the
+// function if there is one.
+// Matches Rust: Celled::resolve
+func (c Celled) Resolve(engine *foundations.Engine, context *foundations.Context, x, y int) (foundations.Value, error) {
+	switch {
+	case c.Func != nil:
+		return c.Func.Call(engine, context, foundations.NewArgs(c.Func.Span, foundations.Int(x), foundations.Int(y)))
+	case len(c.Array) > 0:
+		return c.Array[x%len(c.Array)], nil
+	}
+	return c.Value, nil
+}
+
+// ResolveAlign returns the alignment of the cell in column x and row y,
+// or nil for the default alignment.
+func (g *GridElement) ResolveAlign(engine *foundations.Engine, context *foundations.Context, x, y int) (foundations.Value, error) {
+	return ResolveCellValue(engine, context, g.Align, x, y, "align")
+}
+
+// ResolveFill returns the fill of the cell in column x and row y, or nil
+// for no fill.
+func (g *GridElement) ResolveFill(engine *foundations.Engine, context *foundations.Context, x, y int) (foundations.Value, error) {
+	return ResolveCellValue(engine, context, g.Fill, x, y, "fill")
+}
+
+// ResolveCellValue resolves the align or fill property of a grid or table
+// for the cell in column x and row y. Auto and none resolve to nil. The
+// result is checked to be an alignment or a paint, depending on the
+// property's name.
+func ResolveCellValue(engine *foundations.Engine, context *foundations.Context, property foundations.Value, x, y int, name string) (foundations.Value, error) {
+	if property == nil {
+		return nil, nil
+	}
+	value, err := CastCelled(property).Resolve(engine, context, x, y)
+	if err != nil {
+		return nil, err
+	}
+	switch value.(type) {
+	case nil, foundations.AutoValue, foundations.NoneValue:
+		return nil, nil
+	}
+	if err := checkCellValue(value, name); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// checkCellValue checks that a resolved align value is an alignment and a
+// resolved fill value is a color, gradient, or tiling.
+func checkCellValue(value foundations.Value, name string) error {
+	switch name {
+	case "align":
+		str, ok := value.(foundations.Str)
+		if !ok {
+			return &foundations.TypeMismatchError{Expected: "alignment", Got: value.Type().String(), Field: name}
+		}
+		_, err := parseAlignmentString(string(str), syntax.Detached())
+		return err
+	case "fill":
+		switch value.Type() {
+		case foundations.TypeColor, foundations.TypeGradient, foundations.TypeTiling:
+			return nil
+		}
+		return &foundations.TypeMismatchError{Expected: "color, gradient, or tiling", Got: value.Type().String(), Field: name}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package layout
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// GridCellElem represents an explicit grid cell with position/span
+// overrides, as created by `grid.cell`.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/grid/mod.rs
+type GridCellElem struct {
+	// Body is the cell's content.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	// X is the column position (0-indexed). If nil, auto-positioned.
+	X *int64 `typst:"x,type=int"`
+	// Y is the row position (0-indexed). If nil, auto-positioned.
+	Y *int64 `typst:"y,type=int"`
+	// Colspan is the number of columns this cell spans (default: 1).
+	Colspan int64 `typst:"colspan,type=int"`
+	// Rowspan is the number of rows this cell spans (default: 1).
+	Rowspan int64 `typst:"rowspan,type=int"`
+	// Inset overrides the grid's inset for this cell.
+	Inset foundations.Value `typst:"inset"`
+	// Align overrides the grid's alignment for this cell.
+	Align foundations.Value `typst:"align"`
+	// Fill overrides the grid's fill for this cell.
+	Fill foundations.Value `typst:"fill"`
+	// Stroke overrides the grid's stroke for this cell.
+	Stroke foundations.Value `typst:"stroke"`
+	// Breakable controls whether rows can break across pages.
+	Breakable foundations.Value `typst:"breakable"`
+}
+
+func (*GridCellElem) IsContentElement() {}
+
+// GridCellDef is the registered element definition for grid.cell, which
+// gives the element its name in show rule selectors.
+var GridCellDef = foundations.RegisterElement[GridCellElem]("grid.cell", nil)
+
+// ResolveCells positions the cells of the grid, which makes them available
+// in Cells. Children that are not explicit cells are wrapped in cells.
+// Matches Rust: CellGrid::resolve
+func (g *GridElement) ResolveCells() error {
+	cells := make([]*GridCellElem, len(g.Children))
+	placements := make([]CellPlacement, len(g.Children))
+	for i, child := range g.Children {
+		cell, ok := foundations.ContentElem(child).(*GridCellElem)
+		if !ok {
+			cell = &GridCellElem{Body: child}
+		}
+		cells[i] = cell
+		placements[i] = CellPlacement{X: cell.X, Y: cell.Y, Colspan: cell.Colspan, Rowspan: cell.Rowspan}
+	}
+	if err := PlaceCells(max(len(g.Columns), 1), placements); err != nil {
+		return err
+	}
+	for i, cell := range cells {
+		cell.X, cell.Y = placements[i].X, placements[i].Y
+		cell.Colspan, cell.Rowspan = placements[i].Colspan, placements[i].Rowspan
+	}
+	g.Cells = cells
+	return nil
+}
+
+// CellPlacement is the position and extent of a grid or table cell. Before
+// placement, a nil X or Y is chosen automatically and a span below one
+// counts as one; afterwards, all of them are set.
+type CellPlacement struct {
+	X, Y             *int64
+	Colspan, Rowspan int64
+}
+
+// ColumnCount returns the number of columns of a grid or table with the
+// given columns argument: an integer count, or an array with the sizing of
+// each column. Anything else is a single column.
+func ColumnCount(columns foundations.Value) int {
+	switch v := columns.(type) {
+	case foundations.Int:
+		return max(int(v), 1)
+	case *foundations.Array:
+		return max(v.Len(), 1)
+	}
+	return 1
+}
+
+// PlaceCells positions cells in a grid with the given number of columns,
+// in order. A cell with both coordinates is placed there; a cell with only
+// a column goes to the first row in which it fits in that column, and a
+// cell with only a row to the first column in which it fits in that row.
+// Other cells are placed at the first free position after the previous
+// automatically placed cell, in reading order. Cells may not overlap or
+// extend past the last column.
+// Matches Rust: resolve_cell_position in layout/grid/resolve.rs
+func PlaceCells(columns int, cells []CellPlacement) error {
+	occupied := map[[2]int64]bool{}
+	fits := func(x, y, colspan, rowspan int64) bool {
+		if x < 0 || y < 0 || x+colspan > int64(columns) {
+			return false
+		}
+		for dy := int64(0); dy < rowspan; dy++ {
+			for dx := int64(0); dx < colspan; dx++ {
+				if occupied[[2]int64{x + dx, y + dy}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	var auto int64
+	for i := range cells {
+		cell := &cells[i]
+		cell.Colspan, cell.Rowspan = max(cell.Colspan, 1), max(cell.Rowspan, 1)
+		if cell.Colspan > int64(columns) {
+			return &foundations.OpError{
+				Message: "cell's colspan would cause it to exceed the available column(s)",
+				Hint:    "try reducing the cell's colspan or adding more columns",
+			}
+		}
+		if (cell.X != nil && *cell.X < 0) || (cell.Y != nil && *cell.Y < 0) {
+			return &foundations.OpError{Message: "cell position must not be negative"}
+		}
+
+		var x, y int64
+		switch {
+		case cell.X != nil && cell.Y != nil:
+			x, y = *cell.X, *cell.Y
+			if x+cell.Colspan > int64(columns) {
+				return &foundations.OpError{
+					Message: fmt.Sprintf("cell at column %d would exceed the available column(s)", x),
+					Hint:    "try placing the cell in another column or reducing its colspan",
+				}
+			}
+			if !fits(x, y, cell.Colspan, cell.Rowspan) {
+				return &foundations.OpError{
+					Message: fmt.Sprintf("attempted to place a second cell at column %d, row %d", x, y),
+					Hint:    "try specifying your cells in a different order",
+				}
+			}
+		case cell.X != nil:
+			x = *cell.X
+			if x+cell.Colspan > int64(columns) {
+				return &foundations.OpError{
+					Message: fmt.Sprintf("cell at column %d would exceed the available column(s)", x),
+					Hint:    "try placing the cell in another column or reducing its colspan",
+				}
+			}
+			for !fits(x, y, cell.Colspan, cell.Rowspan) {
+				y++
+			}
+		case cell.Y != nil:
+			y = *cell.Y
+			for x <= int64(columns)-cell.Colspan && !fits(x, y, cell.Colspan, cell.Rowspan) {
+				x++
+			}
+			if x > int64(columns)-cell.Colspan {
+				return &foundations.OpError{
+					Message: fmt.Sprintf("cell could not be placed in row %d because it was full", y),
+					Hint:    "try specifying the cell's column or placing it in another row",
+				}
+			}
+		default:
+			for !fits(auto%int64(columns), auto/int64(columns), cell.Colspan, cell.Rowspan) {
+				auto++
+			}
+			x, y = auto%int64(columns), auto/int64(columns)
+			auto += cell.Colspan
+		}
+
+		for dy := int64(0); dy < cell.Rowspan; dy++ {
+			for dx := int64(0); dx < cell.Colspan; dx++ {
+				occupied[[2]int64{x + dx, y + dy}] = true
+			}
+		}
+		cell.X, cell.Y = &x, &y
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ type ColumnsElement struct {
 	Gutter *foundations.Relative `typst:"gutter,type=relative"`
 	// Body is the content to arrange in columns.
 	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
 }
 
 func (*ColumnsElement) IsContentElement() {}
@@ -76,3 +77,45 @@ func columnsNative(engine foundations.Engine, context foundations.Context, args
 		Elements: []foundations.ContentElement{elem},
 	}}, nil
 }
+
+// ColbreakElem is a forced column break. The content after it continues in
+// the next column, or on the next page if the current column is the last.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/columns.rs
+type ColbreakElem struct {
+	// Weak makes the break collapse if the column is already empty.
+	Weak bool `typst:"weak,type=bool,default=false"`
+}
+
+func (*ColbreakElem) IsContentElement() {}
+
+// ColbreakDef is the registered element definition for colbreak.
+var ColbreakDef *foundations.ElementDef
+
+func init() {
+	ColbreakDef = foundations.RegisterElement[ColbreakElem]("colbreak", nil)
+}
+
+// ColbreakFunc creates the colbreak element function.
+func ColbreakFunc() *foundations.Func {
+	name := "colbreak"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: colbreakNative,
+			Info: ColbreakDef.ToFuncInfo(),
+		},
+	}
+}
+
+// colbreakNative implements the colbreak() function.
+func colbreakNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[ColbreakElem](ColbreakDef, args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
@@ -30,6 +30,7 @@ type BoxElement struct {
 	Clip bool `typst:"clip,type=bool,default=false"`
 	// Body is the content inside the box.
 	Body foundations.Content `typst:"body,positional,type=content"`
+	foundations.ElemMeta
 }
 
 func (*BoxElement) IsContentElement() {}
@@ -67,6 +68,7 @@ type BlockElement struct {
 	Sticky bool `typst:"sticky,type=bool,default=false"`
 	// Body is the content inside the block.
 	Body foundations.Content `typst:"body,positional,type=content"`
+	foundations.ElemMeta
 }
 
 func (*BlockElement) IsContentElement() {}
@@ -31,10 +31,12 @@ type GridElement struct {
 	Columns []GridTrackSizing
 	// Rows defines the row track sizes.
 	Rows []GridTrackSizing
-	// ColumnGutter is the gap between columns (in points).
-	ColumnGutter *float64
-	// RowGutter is the gap between rows (in points).
-	RowGutter *float64
+	// ColumnGutter defines the gaps between columns. The last gap sizing
+	// repeats for the remaining gaps.
+	ColumnGutter []GridTrackSizing
+	// RowGutter defines the gaps between rows. The last gap sizing repeats
+	// for the remaining gaps.
+	RowGutter []GridTrackSizing
 	// Inset is the cell padding.
 	Inset foundations.Value
 	// Align is the cell alignment.
@@ -43,8 +45,13 @@ type GridElement struct {
 	Fill foundations.Value
 	// Stroke is the cell stroke.
 	Stroke foundations.Value
-	// Children contains the grid cells.
+	// Children contains the grid cells: plain content or explicit cells.
 	Children []foundations.Content
+	// Cells are the cells of the grid with their positions resolved, in
+	// the order of the children. Set by ResolveCells.
+	// Synthesized field.
+	Cells []*GridCellElem
+	foundations.ElemMeta
 }
 
 func (*GridElement) IsContentElement() {}
@@ -119,41 +126,41 @@ func gridNative(engine foundations.Engine, context foundations.Context, args *fo
 	}
 
 	// Get optional gutter argument (sets both column and row gutter)
-	var gutterVal *float64
+	var gutter []GridTrackSizing
 	if gutterArg := args.Find("gutter"); gutterArg != nil {
 		if !foundations.IsNone(gutterArg.V) && !foundations.IsAuto(gutterArg.V) {
-			g, err := parseGridLength(gutterArg.V, gutterArg.Span)
+			g, err := parseGridTrackSizings(gutterArg.V, gutterArg.Span)
 			if err != nil {
 				return nil, err
 			}
-			gutterVal = &g
+			gutter = g
 		}
 	}
 
 	// Get optional column-gutter argument
 	if cgArg := args.Find("column-gutter"); cgArg != nil {
 		if !foundations.IsNone(cgArg.V) && !foundations.IsAuto(cgArg.V) {
-			cg, err := parseGridLength(cgArg.V, cgArg.Span)
+			cg, err := parseGridTrackSizings(cgArg.V, cgArg.Span)
 			if err != nil {
 				return nil, err
 			}
-			elem.ColumnGutter = &cg
+			elem.ColumnGutter = cg
 		}
-	} else if gutterVal != nil {
-		elem.ColumnGutter = gutterVal
+	} else {
+		elem.ColumnGutter = gutter
 	}
 
 	// Get optional row-gutter argument
 	if rgArg := args.Find("row-gutter"); rgArg != nil {
 		if !foundations.IsNone(rgArg.V) && !foundations.IsAuto(rgArg.V) {
-			rg, err := parseGridLength(rgArg.V, rgArg.Span)
+			rg, err := parseGridTrackSizings(rgArg.V, rgArg.Span)
 			if err != nil {
 				return nil, err
 			}
-			elem.RowGutter = &rg
+			elem.RowGutter = rg
 		}
-	} else if gutterVal != nil {
-		elem.RowGutter = gutterVal
+	} else {
+		elem.RowGutter = gutter
 	}
 
 	// Get optional inset argument
@@ -211,20 +218,10 @@ func gridNative(engine foundations.Engine, context foundations.Context, args *fo
 	}}, nil
 }
 
-// ColumnGutterPts returns the column gutter in points, or 0 if not set.
-func (g *GridElement) ColumnGutterPts() float64 {
-	if g.ColumnGutter == nil {
-		return 0
-	}
-	return *g.ColumnGutter
-}
-
-// RowGutterPts returns the row gutter in points, or 0 if not set.
-func (g *GridElement) RowGutterPts() float64 {
-	if g.RowGutter == nil {
-		return 0
-	}
-	return *g.RowGutter
+// HasGutter reports whether the grid has gutter between its columns or
+// rows.
+func (g *GridElement) HasGutter() bool {
+	return len(g.ColumnGutter) > 0 || len(g.RowGutter) > 0
 }
 
 // parseGridTrackSizings parses a value into grid track sizings.
@@ -305,18 +302,3 @@ func parseGridTrackSizing(v foundations.Value, span syntax.Span) (GridTrackSizin
 		Span:     span,
 	}
 }
-
-// parseGridLength extracts a length value in points.
-func parseGridLength(v foundations.Value, span syntax.Span) (float64, error) {
-	if lv, ok := v.(foundations.LengthValue); ok {
-		return lv.Length.Points, nil
-	}
-	if rv, ok := v.(foundations.RelativeValue); ok {
-		return rv.Relative.Abs.Points, nil
-	}
-	return 0, &foundations.TypeMismatchError{
-		Expected: "length",
-		Got:      v.Type().String(),
-		Span:     span,
-	}
-}
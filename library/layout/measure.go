@@ -0,0 +1,92 @@
+// Measure function for Typst.
+// Translated from typst-library/src/layout/measure.rs
+
+package layout
+
+import (
+	"math"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// MeasureFunc creates the measure function, which lays out content and
+// returns its size as a dictionary with width and height.
+func MeasureFunc() *foundations.Func {
+	name := "measure"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: measureNative,
+			Info: &foundations.FuncInfo{
+				Name: "measure",
+				Params: []foundations.ParamInfo{
+					{Name: "width", Type: foundations.TypeDyn, Default: foundations.Auto, Named: true},
+					{Name: "height", Type: foundations.TypeDyn, Default: foundations.Auto, Named: true},
+					{Name: "content", Type: foundations.TypeContent},
+				},
+			},
+		},
+	}
+}
+
+// measureNative implements the measure() function. The content is laid
+// out through the engine's routines, in a region that is unbounded in the
+// directions without a given size.
+// Matches Rust: pub fn measure(engine, context, span, width, height, content) -> SourceResult<Dict>
+func measureNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	width, err := measureBound(args, "width")
+	if err != nil {
+		return nil, err
+	}
+	height, err := measureBound(args, "height")
+	if err != nil {
+		return nil, err
+	}
+	arg, err := args.Expect("content")
+	if err != nil {
+		return nil, err
+	}
+	content, ok := arg.V.(foundations.ContentValue)
+	if !ok {
+		return nil, &foundations.TypeMismatchError{Expected: "content", Got: arg.V.Type().String(), Field: "content", Span: arg.Span}
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	styles, err := context.GetStyles()
+	if err != nil {
+		return nil, err
+	}
+	if engine.Routines == nil {
+		return nil, foundations.NewSourceError(args.Span, "cannot measure content without routines")
+	}
+
+	region := foundations.Region{Size: foundations.Size{Width: width, Height: height}}
+	size, err := engine.Routines.LayoutFrame(&engine, content.Content, styles, region)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := foundations.NewDict()
+	dict.Set("width", foundations.LengthValue{Length: size.Width})
+	dict.Set("height", foundations.LengthValue{Length: size.Height})
+	return dict, nil
+}
+
+// measureBound returns the size of the region in one direction: the given
+// length, or an infinite one for auto.
+func measureBound(args *foundations.Args, name string) (foundations.Length, error) {
+	unbounded := foundations.Length{Points: math.Inf(1)}
+	arg := args.Named(name)
+	if arg == nil || foundations.IsAuto(arg.V) {
+		return unbounded, nil
+	}
+	lv, ok := arg.V.(foundations.LengthValue)
+	if !ok {
+		return unbounded, &foundations.TypeMismatchError{Expected: "auto or length", Got: arg.V.Type().String(), Field: name, Span: arg.Span}
+	}
+	return lv.Length, nil
+}
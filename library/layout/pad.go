@@ -22,6 +22,7 @@ type PadElement struct {
 	Bottom *foundations.Length `typst:"bottom,type=length"`
 	// Body is the content to pad.
 	Body foundations.Content `typst:"body,positional,required"`
+	foundations.ElemMeta
 }
 
 func (*PadElement) IsContentElement() {}
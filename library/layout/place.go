@@ -0,0 +1,127 @@
+package layout
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// PlaceElement places content relative to its parent container, either
+// at a fixed position or floating at the top or bottom of it.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/place.rs
+type PlaceElement struct {
+	// Alignment is an alignment string like "top" or "bottom", or auto.
+	// A float with auto alignment goes to the top or the bottom,
+	// whichever is closer to where it appears in the flow.
+	Alignment foundations.Value `typst:"alignment,positional"`
+	// Scope is "column" to place relative to the current column or
+	// "parent" to place relative to the page, spanning all columns.
+	Scope string `typst:"scope,type=str,default=column"`
+	// Float makes the content float: other content flows around it
+	// instead of being overlapped.
+	Float bool `typst:"float,type=bool,default=false"`
+	// Clearance is the spacing between a float and the in-flow content.
+	// If nil, defaults to 1.5em.
+	Clearance *foundations.Length `typst:"clearance,type=length"`
+	// Dx is the horizontal displacement of the placed content.
+	Dx *foundations.Relative `typst:"dx,type=relative"`
+	// Dy is the vertical displacement of the placed content.
+	Dy *foundations.Relative `typst:"dy,type=relative"`
+	// Body is the content to place.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
+}
+
+func (*PlaceElement) IsContentElement() {}
+
+// PlaceDef is the registered element definition for place.
+var PlaceDef *foundations.ElementDef
+
+func init() {
+	PlaceDef = foundations.RegisterElement[PlaceElement]("place", nil)
+}
+
+// Auto reports whether the float picks its vertical position itself.
+func (p *PlaceElement) Auto() bool {
+	_, ok := p.Alignment.(foundations.AutoValue)
+	return ok
+}
+
+// Align returns the parsed 2D alignment, which is empty for auto.
+func (p *PlaceElement) Align() Alignment2D {
+	s, ok := foundations.AsStr(p.Alignment)
+	if !ok {
+		return Alignment2D{}
+	}
+	result, _ := parseAlignmentString(s, syntax.Detached())
+	return result
+}
+
+// PlaceFunc creates the place element function.
+func PlaceFunc() *foundations.Func {
+	name := "place"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: placeNative,
+			Info: PlaceDef.ToFuncInfo(),
+		},
+	}
+}
+
+// placeNative implements the place() function. The alignment defaults to
+// "start" when only the body is given.
+func placeNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	if args.Remaining() < 2 {
+		args.Insert(0, args.Span, foundations.Str("start"))
+	}
+	elem, err := foundations.ParseElement[PlaceElement](PlaceDef, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate alignment and scope
+	switch v := elem.Alignment.(type) {
+	case nil:
+		return nil, &foundations.TypeMismatchError{Expected: "alignment or auto", Got: "none", Field: "alignment", Span: args.Span}
+	case foundations.AutoValue:
+		if !elem.Float {
+			return nil, &foundations.ConstructorError{
+				Message: "automatic positioning is only available for floating placement",
+				Span:    args.Span,
+			}
+		}
+	default:
+		s, ok := foundations.AsStr(v)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{Expected: "alignment or auto", Got: v.Type().String(), Field: "alignment", Span: args.Span}
+		}
+		align, err := parseAlignmentString(s, args.Span)
+		if err != nil {
+			return nil, err
+		}
+		if elem.Float && (align.Vertical == nil || *align.Vertical == VAlignHorizon) {
+			return nil, &foundations.ConstructorError{
+				Message: "floating placement must be `auto`, `top`, or `bottom`",
+				Span:    args.Span,
+			}
+		}
+	}
+	switch elem.Scope {
+	case "column":
+	case "parent":
+		if !elem.Float {
+			return nil, &foundations.ConstructorError{
+				Message: "parent-scoped placement is only available for floating placement",
+				Span:    args.Span,
+			}
+		}
+	default:
+		return nil, &foundations.TypeMismatchError{Expected: "\"column\" or \"parent\"", Got: "\"" + elem.Scope + "\"", Field: "scope", Span: args.Span}
+	}
+
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
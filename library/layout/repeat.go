@@ -0,0 +1,57 @@
+// Repeat element for filling a line with copies of content.
+// Translated from typst-library/src/layout/repeat.rs
+
+package layout
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// RepeatElement repeats its body as often as fits into the remaining width
+// of the line. It is used for leaders, like the dots between the entries
+// and page numbers of an outline.
+type RepeatElement struct {
+	// Body is the content to repeat.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	// Gap is the space between the repetitions. If nil, there is none.
+	Gap *foundations.Length `typst:"gap,type=length"`
+	// Justify is whether to spread the repetitions out to fill the width
+	// exactly. Defaults to true.
+	Justify *bool `typst:"justify,type=bool,default=true"`
+	foundations.ElemMeta
+}
+
+func (*RepeatElement) IsContentElement() {}
+
+// RepeatDef is the registered element definition for repeat.
+var RepeatDef *foundations.ElementDef
+
+func init() {
+	RepeatDef = foundations.RegisterElement[RepeatElement]("repeat", nil)
+}
+
+// RepeatFunc creates the repeat element function.
+func RepeatFunc() *foundations.Func {
+	name := "repeat"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: repeatNative,
+			Info: RepeatDef.ToFuncInfo(),
+		},
+	}
+}
+
+// repeatNative implements the repeat() function using the generic element
+// parser.
+func repeatNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[RepeatElement](RepeatDef, args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
@@ -3,7 +3,10 @@
 
 package layout
 
-import "github.com/boergens/gotypst/library/foundations"
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
 
 // HElem represents horizontal spacing.
 type HElem struct {
@@ -27,10 +30,10 @@ type VElem struct {
 
 func (*VElem) IsContentElement() {}
 
-// Spacing represents a spacing amount (absolute or fractional).
+// Spacing represents a spacing amount (relative or fractional).
 type Spacing struct {
-	// Abs is the absolute spacing in points (if not fractional).
-	Abs foundations.Length
+	// Rel is the relative spacing (if not fractional).
+	Rel foundations.Relative
 	// Fr is the fractional spacing (if fractional).
 	Fr foundations.Fraction
 	// IsFractional indicates if this is fractional spacing.
@@ -41,3 +44,113 @@ type Spacing struct {
 func (s Spacing) IsFrac() bool {
 	return s.IsFractional
 }
+
+// IsZero returns true if the spacing takes up no space.
+func (s Spacing) IsZero() bool {
+	if s.IsFractional {
+		return s.Fr.Value == 0
+	}
+	return s.Rel.Abs.Points == 0 && s.Rel.Rel.Value == 0
+}
+
+// CastSpacing casts a length, ratio, relative length, or fraction to a
+// spacing amount.
+// Matches Rust: cast! for Spacing
+func CastSpacing(v foundations.Value) (Spacing, error) {
+	switch v := v.(type) {
+	case foundations.LengthValue:
+		return Spacing{Rel: foundations.Relative{Abs: v.Length}}, nil
+	case foundations.RatioValue:
+		return Spacing{Rel: foundations.Relative{Rel: v.Ratio}}, nil
+	case foundations.RelativeValue:
+		return Spacing{Rel: v.Relative}, nil
+	case foundations.FractionValue:
+		return Spacing{Fr: v.Fraction, IsFractional: true}, nil
+	}
+	return Spacing{}, &foundations.TypeMismatchError{Expected: "relative length or fraction", Got: v.Type().String()}
+}
+
+// spacingParams are the parameters of h() and v().
+var spacingParams = []foundations.ParamInfo{
+	{Name: "amount", Type: foundations.TypeDyn},
+	{Name: "weak", Type: foundations.TypeBool, Named: true, Default: foundations.Bool(false)},
+}
+
+// HFunc creates the h element function, which inserts horizontal spacing
+// into a paragraph, as in `h(1cm)` or `h(1fr)`.
+//
+// Matches Rust: HElem
+func HFunc() *foundations.Func {
+	name := "h"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: hNative,
+			Info: &foundations.FuncInfo{Name: name, Params: spacingParams},
+		},
+	}
+}
+
+func hNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	amount, weak, err := spacingArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{&HElem{Amount: amount, Weak: weak}},
+	}}, nil
+}
+
+// VFunc creates the v element function, which inserts vertical spacing
+// into a flow of blocks, as in `v(1cm)` or `v(1fr)`.
+//
+// Matches Rust: VElem
+func VFunc() *foundations.Func {
+	name := "v"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: vNative,
+			Info: &foundations.FuncInfo{Name: name, Params: spacingParams},
+		},
+	}
+}
+
+func vNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	amount, weak, err := spacingArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{&VElem{Amount: amount, Weak: weak}},
+	}}, nil
+}
+
+// spacingArgs parses the amount and the weakness of h() and v().
+func spacingArgs(args *foundations.Args) (Spacing, bool, error) {
+	arg, err := args.Expect("amount")
+	if err != nil {
+		return Spacing{}, false, err
+	}
+	amount, err := CastSpacing(arg.V)
+	if err != nil {
+		if mismatch, ok := err.(*foundations.TypeMismatchError); ok {
+			mismatch.Field, mismatch.Span = "amount", arg.Span
+		}
+		return Spacing{}, false, err
+	}
+	weak := false
+	if named := args.Named("weak"); named != nil {
+		b, ok := named.V.(foundations.Bool)
+		if !ok {
+			return Spacing{}, false, &foundations.TypeMismatchError{Expected: "boolean", Got: named.V.Type().String(), Field: "weak", Span: named.Span}
+		}
+		weak = bool(b)
+	}
+	if err := args.Finish(); err != nil {
+		return Spacing{}, false, err
+	}
+	return amount, weak, nil
+}
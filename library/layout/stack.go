@@ -26,14 +26,27 @@ const (
 type StackElement struct {
 	// Dir is the stacking direction (ltr, rtl, ttb, btt).
 	Dir string `typst:"dir,type=str,default=ttb"`
-	// Spacing is the spacing between children.
-	Spacing *foundations.Length `typst:"spacing,type=length"`
-	// Children contains the content elements to stack.
-	Children []foundations.Content `typst:"children,positional,variadic"`
+	// Spacing is the spacing between children: a relative length or a
+	// fraction like 1fr. If nil, there is none.
+	Spacing foundations.Value `typst:"spacing"`
+	// Children contains the content and spacing to stack. Spacing among
+	// the children, as in `stack(1fr, [centered], 1fr)`, replaces the
+	// spacing between its neighbours.
+	Children []StackChild
+	foundations.ElemMeta
 }
 
 func (*StackElement) IsContentElement() {}
 
+// StackChild is a child of a stack: either spacing or content.
+// Corresponds to Rust's StackChild enum.
+type StackChild struct {
+	// Spacing is set for spacing children.
+	Spacing *Spacing
+	// Content is set for content children.
+	Content *foundations.Content
+}
+
 // StackDef is the registered element definition for stack.
 var StackDef *foundations.ElementDef
 
@@ -55,12 +68,24 @@ func (s *StackElement) Direction() StackDirection {
 	}
 }
 
-// SpacingPts returns the spacing in points, or 0 if not set.
-func (s *StackElement) SpacingPts() float64 {
+// SpacingAmount returns the spacing between children, or zero spacing if
+// not set.
+func (s *StackElement) SpacingAmount() Spacing {
 	if s.Spacing == nil {
+		return Spacing{}
+	}
+	amount, _ := CastSpacing(s.Spacing)
+	return amount
+}
+
+// SpacingPts returns the absolute part of non-fractional spacing in
+// points, or 0 if not set or fractional.
+func (s *StackElement) SpacingPts() float64 {
+	amount := s.SpacingAmount()
+	if amount.IsFrac() {
 		return 0
 	}
-	return s.Spacing.Points
+	return amount.Rel.Abs.Points
 }
 
 // StackFunc creates the stack element function.
@@ -71,17 +96,48 @@ func StackFunc() *foundations.Func {
 		Span: syntax.Detached(),
 		Repr: foundations.NativeFunc{
 			Func: stackNative,
-			Info: StackDef.ToFuncInfo(),
+			Info: stackInfo(),
 		},
 	}
 }
 
-// stackNative implements the stack() function using the generic element parser.
+// stackInfo adds the children to the parameters of the stack definition,
+// which parses only the named arguments.
+func stackInfo() *foundations.FuncInfo {
+	info := StackDef.ToFuncInfo()
+	info.Params = append(info.Params, foundations.ParamInfo{Name: "children", Type: foundations.TypeDyn, Variadic: true})
+	return info
+}
+
+// stackNative implements the stack() function. The children are content
+// or spacing, so they are collected before the generic element parser
+// handles the named arguments.
 func stackNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	var children []StackChild
+	for _, arg := range args.All() {
+		if content, ok := arg.V.(foundations.ContentValue); ok {
+			children = append(children, StackChild{Content: &content.Content})
+			continue
+		}
+		amount, err := CastSpacing(arg.V)
+		if err != nil {
+			return nil, &foundations.TypeMismatchError{Expected: "relative length, fraction, or content", Got: arg.V.Type().String(), Field: "children", Span: arg.Span}
+		}
+		children = append(children, StackChild{Spacing: &amount})
+	}
+
 	elem, err := foundations.ParseElement[StackElement](StackDef, args)
 	if err != nil {
 		return nil, err
 	}
+	elem.Children = children
+	if elem.Spacing != nil && !foundations.IsNone(elem.Spacing) {
+		if _, err := CastSpacing(elem.Spacing); err != nil {
+			return nil, &foundations.TypeMismatchError{Expected: "relative length or fraction", Got: elem.Spacing.Type().String(), Field: "spacing", Span: args.Span}
+		}
+	} else {
+		elem.Spacing = nil
+	}
 
 	// Validate direction
 	switch elem.Dir {
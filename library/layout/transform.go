@@ -0,0 +1,259 @@
+package layout
+
+import (
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/visualize"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// MoveElement moves content without affecting the layout around it:
+// `move(dx: 2pt, dy: -1pt)[text]`.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/transform.rs
+type MoveElement struct {
+	// Dx is the horizontal displacement of the content.
+	Dx *foundations.Relative `typst:"dx,type=relative"`
+	// Dy is the vertical displacement of the content.
+	Dy *foundations.Relative `typst:"dy,type=relative"`
+	// Body is the content to move.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
+}
+
+func (*MoveElement) IsContentElement() {}
+
+// RotateElement rotates content around an origin: `rotate(45deg)[text]`.
+// Unless it reflows, the layout around it is not affected.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/transform.rs
+type RotateElement struct {
+	// Angle is the amount of rotation, clockwise.
+	Angle *foundations.Angle `typst:"angle,positional,type=angle"`
+	// Origin is the alignment of the point to rotate around, within the
+	// content. Unset axes are centered.
+	Origin foundations.Value `typst:"origin"`
+	// Reflow makes the rotated content take up the space of its bounding
+	// box in the layout.
+	Reflow bool `typst:"reflow,type=bool,default=false"`
+	// Body is the content to rotate.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
+}
+
+func (*RotateElement) IsContentElement() {}
+
+// ScaleElement scales content around an origin: `scale(x: -100%)[text]`.
+// A positional factor scales both axes. Unless it reflows, the layout
+// around it is not affected.
+//
+// Reference: typst-reference/crates/typst-library/src/layout/transform.rs
+type ScaleElement struct {
+	// Factor scales both axes, unless they are set separately.
+	Factor *foundations.Ratio `typst:"factor,positional,type=ratio"`
+	// X is the horizontal scale factor.
+	X *foundations.Ratio `typst:"x,type=ratio"`
+	// Y is the vertical scale factor.
+	Y *foundations.Ratio `typst:"y,type=ratio"`
+	// Origin is the alignment of the point to scale around, within the
+	// content. Unset axes are centered.
+	Origin foundations.Value `typst:"origin"`
+	// Reflow makes the scaled content take up the space of its bounding
+	// box in the layout.
+	Reflow bool `typst:"reflow,type=bool,default=false"`
+	// Body is the content to scale.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
+}
+
+func (*ScaleElement) IsContentElement() {}
+
+// Registered element definitions for the transformations.
+var (
+	MoveDef   *foundations.ElementDef
+	RotateDef *foundations.ElementDef
+	ScaleDef  *foundations.ElementDef
+)
+
+func init() {
+	MoveDef = foundations.RegisterElement[MoveElement]("move", nil)
+	RotateDef = foundations.RegisterElement[RotateElement]("rotate", nil)
+	ScaleDef = foundations.RegisterElement[ScaleElement]("scale", nil)
+}
+
+// MoveFunc creates the move element function.
+func MoveFunc() *foundations.Func {
+	name := "move"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: moveNative,
+			Info: MoveDef.ToFuncInfo(),
+		},
+	}
+}
+
+// moveNative implements the move() function.
+func moveNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[MoveElement](MoveDef, args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// RotateFunc creates the rotate element function.
+func RotateFunc() *foundations.Func {
+	name := "rotate"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: rotateNative,
+			Info: RotateDef.ToFuncInfo(),
+		},
+	}
+}
+
+// rotateNative implements the rotate() function. The angle is optional,
+// so a lone positional argument is the body.
+func rotateNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	if args.Remaining() < 2 {
+		args.Insert(0, args.Span, foundations.AngleValue{})
+	}
+	elem, err := foundations.ParseElement[RotateElement](RotateDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castOrigin(elem.Origin, args.Span); err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// ScaleFunc creates the scale element function.
+func ScaleFunc() *foundations.Func {
+	name := "scale"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: scaleNative,
+			Info: ScaleDef.ToFuncInfo(),
+		},
+	}
+}
+
+// scaleNative implements the scale() function. The factor is optional, so
+// a lone positional argument is the body.
+func scaleNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	if args.Remaining() < 2 {
+		args.Insert(0, args.Span, foundations.RatioValue{Ratio: foundations.Ratio{Value: 1}})
+	}
+	elem, err := foundations.ParseElement[ScaleElement](ScaleDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castOrigin(elem.Origin, args.Span); err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// Offset returns the displacement of the moved content. Relative lengths
+// are resolved against the size of the region.
+func (e *MoveElement) Offset(region layout.Size) layout.Point {
+	var offset layout.Point
+	if e.Dx != nil {
+		offset.X = resolveTransformRelative(*e.Dx, region.Width)
+	}
+	if e.Dy != nil {
+		offset.Y = resolveTransformRelative(*e.Dy, region.Height)
+	}
+	return offset
+}
+
+// Transform returns the rotation of content of the given size around its
+// origin.
+func (e *RotateElement) Transform(size layout.Size) visualize.Transform {
+	var angle float64
+	if e.Angle != nil {
+		angle = e.Angle.Radians
+	}
+	return aroundOrigin(visualize.RotateTransform(angle), e.Origin, size)
+}
+
+// Transform returns the scaling of content of the given size around its
+// origin.
+func (e *ScaleElement) Transform(size layout.Size) visualize.Transform {
+	sx, sy := 1.0, 1.0
+	if e.Factor != nil {
+		sx, sy = e.Factor.Value, e.Factor.Value
+	}
+	if e.X != nil {
+		sx = e.X.Value
+	}
+	if e.Y != nil {
+		sy = e.Y.Value
+	}
+	return aroundOrigin(visualize.ScaleTransform(sx, sy), e.Origin, size)
+}
+
+// TransformedSize returns the space that content of the given size takes
+// up after a transformation: its size unless the element reflows, the size
+// of the transformed bounding box otherwise. Offset is the position at
+// which the content's frame must be placed for its bounding box to start at
+// the origin.
+// Matches Rust: measure_and_layout in layout/transform.rs
+func TransformedSize(size layout.Size, ts visualize.Transform, reflow bool) (layout.Size, layout.Point) {
+	if !reflow {
+		return size, layout.Point{}
+	}
+	box := visualize.TransformedBBox(size, ts)
+	return box.Size(), layout.Point{X: -box.Min.X, Y: -box.Min.Y}
+}
+
+// aroundOrigin applies a transformation around the point at the origin
+// alignment within a box of the given size.
+func aroundOrigin(ts visualize.Transform, origin foundations.Value, size layout.Size) visualize.Transform {
+	align, _ := castOrigin(origin, syntax.Detached())
+	var at layout.Point
+	switch {
+	case align.Horizontal == nil || *align.Horizontal == HAlignCenter:
+		at.X = size.Width / 2
+	case *align.Horizontal == HAlignRight || *align.Horizontal == HAlignEnd:
+		at.X = size.Width
+	}
+	switch {
+	case align.Vertical == nil || *align.Vertical == VAlignHorizon:
+		at.Y = size.Height / 2
+	case *align.Vertical == VAlignBottom:
+		at.Y = size.Height
+	}
+	return visualize.TranslateTransform(-at.X, -at.Y).Then(ts).Then(visualize.TranslateTransform(at.X, at.Y))
+}
+
+// castOrigin casts the origin of a transformation. Nil is the center.
+func castOrigin(v foundations.Value, span syntax.Span) (Alignment2D, error) {
+	if v == nil || foundations.IsAuto(v) {
+		return Alignment2D{}, nil
+	}
+	s, ok := foundations.AsStr(v)
+	if !ok {
+		return Alignment2D{}, &foundations.TypeMismatchError{Expected: "alignment", Got: v.Type().String(), Field: "origin", Span: span}
+	}
+	return parseAlignmentString(s, span)
+}
+
+// resolveTransformRelative resolves a relative length against a base
+// length.
+func resolveTransformRelative(rel foundations.Relative, base layout.Abs) layout.Abs {
+	return layout.Abs(rel.Abs.Points) + layout.Abs(rel.Rel.Value)*base
+}
@@ -0,0 +1,172 @@
+// Accent functions for Typst.
+// Translated from typst-library/src/math/accent.rs
+
+package math
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// accentShorthands lists the accent functions defined in the math module
+// together with the accent they apply.
+// Matches Rust: the accents! macro in math/accent.rs
+var accentShorthands = []struct {
+	name   string
+	accent rune
+}{
+	{"grave", AccentGrave},
+	{"acute", AccentAcute},
+	{"hat", AccentHat},
+	{"tilde", AccentTilde},
+	{"macron", AccentBar},
+	{"breve", AccentBreve},
+	{"dot", AccentDot},
+	{"diaer", AccentDDot},
+	{"circle", AccentRing},
+	{"caron", AccentCaron},
+	{"arrow", AccentVec},
+}
+
+// spacingAccents maps spacing (non-combining) accent characters to their
+// combining equivalents.
+var spacingAccents = map[rune]rune{
+	'`': AccentGrave,
+	'´': AccentAcute,
+	'^': AccentHat,
+	'ˆ': AccentHat,
+	'~': AccentTilde,
+	'˜': AccentTilde,
+	'¯': AccentBar,
+	'‾': AccentBar,
+	'˘': AccentBreve,
+	'.': AccentDot,
+	'˙': AccentDot,
+	'¨': AccentDDot,
+	'∘': AccentRing,
+	'○': AccentRing,
+	'˚': AccentRing,
+	'ˇ': AccentCaron,
+	'→': AccentVec,
+}
+
+// CombiningAccent normalizes an accent character to its combining form.
+// Matches Rust: impl Accent { pub fn new(c: char) -> Self }
+func CombiningAccent(c rune) rune {
+	if combining, ok := spacingAccents[c]; ok {
+		return combining
+	}
+	return c
+}
+
+// AccentFunc creates the accent element function.
+func AccentFunc() *foundations.Func {
+	name := "accent"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: accentNative,
+			Info: &foundations.FuncInfo{
+				Name: "accent",
+				Params: []foundations.ParamInfo{
+					{Name: "base", Type: foundations.TypeContent, Named: false},
+					{Name: "accent", Type: foundations.TypeStr, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// accentNative implements the accent() function.
+func accentNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	baseArg, err := args.Expect("base")
+	if err != nil {
+		return nil, err
+	}
+	accentArg, err := args.Expect("accent")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	base, err := castContent(baseArg)
+	if err != nil {
+		return nil, err
+	}
+	accent, err := castAccent(accentArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return contentValue(&AccentElem{Base: base, Accent: accent}), nil
+}
+
+// accentShorthandFunc creates a function like hat() that applies a fixed
+// accent to its argument.
+func accentShorthandFunc(name string, accent rune) *foundations.Func {
+	funcName := name
+	return &foundations.Func{
+		Name: &funcName,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				baseArg, err := args.Expect("base")
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				base, err := castContent(baseArg)
+				if err != nil {
+					return nil, err
+				}
+				return contentValue(&AccentElem{Base: base, Accent: accent}), nil
+			},
+			Info: &foundations.FuncInfo{
+				Name: name,
+				Params: []foundations.ParamInfo{
+					{Name: "base", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// castAccent converts an accent argument: a single character (as string or
+// symbol) or accent content consisting of one symbol.
+func castAccent(arg syntax.Spanned[foundations.Value]) (rune, error) {
+	var text string
+	switch v := arg.V.(type) {
+	case foundations.Str:
+		text = string(v)
+	case foundations.SymbolValue:
+		text = string(v.Char)
+	case foundations.ContentValue:
+		if len(v.Content.Elements) == 1 {
+			if sym, ok := v.Content.Elements[0].(*foundations.SymbolElem); ok {
+				text = sym.Text
+			}
+		}
+	default:
+		return 0, &foundations.TypeMismatchError{
+			Expected: "string, symbol, or content",
+			Got:      arg.V.Type().String(),
+			Span:     arg.Span,
+		}
+	}
+
+	runes := []rune(text)
+	if len(runes) != 1 {
+		return 0, &foundations.ConstructorError{
+			Message: fmt.Sprintf("expected exactly one character as accent, found %q", text),
+			Span:    arg.Span,
+		}
+	}
+	return CombiningAccent(runes[0]), nil
+}
@@ -0,0 +1,218 @@
+// Attachment functions for Typst.
+// Translated from typst-library/src/math/attach.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// AttachFunc creates the attach element function.
+func AttachFunc() *foundations.Func {
+	name := "attach"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: attachNative,
+			Info: &foundations.FuncInfo{
+				Name: "attach",
+				Params: []foundations.ParamInfo{
+					{Name: "base", Type: foundations.TypeContent, Named: false},
+					{Name: "t", Type: foundations.TypeContent, Default: foundations.None, Named: true},
+					{Name: "b", Type: foundations.TypeContent, Default: foundations.None, Named: true},
+					{Name: "tl", Type: foundations.TypeContent, Default: foundations.None, Named: true},
+					{Name: "bl", Type: foundations.TypeContent, Default: foundations.None, Named: true},
+					{Name: "tr", Type: foundations.TypeContent, Default: foundations.None, Named: true},
+					{Name: "br", Type: foundations.TypeContent, Default: foundations.None, Named: true},
+				},
+			},
+		},
+	}
+}
+
+// attachNative implements the attach() function.
+func attachNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &AttachElem{}
+
+	for _, slot := range []struct {
+		name  string
+		field **foundations.Content
+	}{
+		{"t", &elem.T},
+		{"b", &elem.B},
+		{"tl", &elem.TL},
+		{"bl", &elem.BL},
+		{"tr", &elem.TR},
+		{"br", &elem.BR},
+	} {
+		arg := args.Named(slot.name)
+		if arg == nil || foundations.IsNone(arg.V) {
+			continue
+		}
+		content, err := castContent(*arg)
+		if err != nil {
+			return nil, err
+		}
+		*slot.field = &content
+	}
+
+	baseArg, err := args.Expect("base")
+	if err != nil {
+		return nil, err
+	}
+	elem.Base, err = castContent(baseArg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(elem), nil
+}
+
+// ScriptsFunc creates the scripts element function.
+func ScriptsFunc() *foundations.Func {
+	name := "scripts"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: scriptsNative,
+			Info: &foundations.FuncInfo{
+				Name: "scripts",
+				Params: []foundations.ParamInfo{
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// scriptsNative implements the scripts() function.
+func scriptsNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	bodyArg, err := args.Expect("body")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	body, err := castContent(bodyArg)
+	if err != nil {
+		return nil, err
+	}
+	return contentValue(&ScriptsElem{Body: body}), nil
+}
+
+// LimitsFunc creates the limits element function.
+func LimitsFunc() *foundations.Func {
+	name := "limits"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: limitsNative,
+			Info: &foundations.FuncInfo{
+				Name: "limits",
+				Params: []foundations.ParamInfo{
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+					{Name: "inline", Type: foundations.TypeBool, Default: foundations.True, Named: true},
+				},
+			},
+		},
+	}
+}
+
+// limitsNative implements the limits() function.
+func limitsNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &LimitsElem{Inline: true}
+
+	if inlineArg := args.Named("inline"); inlineArg != nil {
+		inline, ok := foundations.AsBool(inlineArg.V)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{
+				Expected: "bool",
+				Got:      inlineArg.V.Type().String(),
+				Span:     inlineArg.Span,
+			}
+		}
+		elem.Inline = inline
+	}
+
+	bodyArg, err := args.Expect("body")
+	if err != nil {
+		return nil, err
+	}
+	elem.Body, err = castContent(bodyArg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(elem), nil
+}
+
+// Limits describes when attachments of a base are placed as limits.
+// Matches Rust: pub enum Limits
+type Limits int
+
+const (
+	// LimitsNever always places attachments as scripts.
+	LimitsNever Limits = iota
+	// LimitsDisplay places attachments as limits in display style only.
+	LimitsDisplay
+	// LimitsAlways always places attachments as limits.
+	LimitsAlways
+)
+
+// Active reports whether limits are used in the given style.
+func (l Limits) Active(display bool) bool {
+	switch l {
+	case LimitsAlways:
+		return true
+	case LimitsDisplay:
+		return display
+	default:
+		return false
+	}
+}
+
+// DefaultLimits returns the default limits placement for a character.
+// Large operators like sums and products use limits in display style,
+// while integrals and everything else use scripts.
+// Matches Rust: impl Limits { pub fn for_char(c: char) -> Self }
+func DefaultLimits(c rune) Limits {
+	if IsIntegral(c) {
+		return LimitsNever
+	}
+	if IsLargeOperator(c) {
+		return LimitsDisplay
+	}
+	return LimitsNever
+}
+
+// IsLargeOperator reports whether c is a large (n-ary) operator that is
+// enlarged in display style.
+func IsLargeOperator(c rune) bool {
+	switch c {
+	case '∑', '∏', '∐', '⋀', '⋁', '⋂', '⋃', '⨀', '⨁', '⨂', '⨄', '⨆':
+		return true
+	}
+	return IsIntegral(c)
+}
+
+// IsIntegral reports whether c is an integral sign.
+func IsIntegral(c rune) bool {
+	switch c {
+	case '∫', '∬', '∭', '∮', '∯', '∰', '∱', '∲', '∳', '⨌', '⨍', '⨎', '⨏', '⨐', '⨑', '⨒', '⨓', '⨔', '⨕', '⨖', '⨗', '⨘', '⨙', '⨚', '⨛', '⨜':
+		return true
+	}
+	return false
+}
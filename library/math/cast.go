@@ -0,0 +1,88 @@
+// Argument casting helpers for the math element functions.
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// atSpan attaches a span to errors that carry one but don't have it set yet.
+func atSpan(err error, span syntax.Span) error {
+	switch e := err.(type) {
+	case *foundations.TypeMismatchError:
+		if e.Span.IsDetached() {
+			e.Span = span
+		}
+	case *foundations.ConstructorError:
+		if e.Span.IsDetached() {
+			e.Span = span
+		}
+	}
+	return err
+}
+
+// castContent converts an argument to content. Content is taken as is and
+// other values are displayed.
+func castContent(arg syntax.Spanned[foundations.Value]) (foundations.Content, error) {
+	switch v := arg.V.(type) {
+	case foundations.ContentValue:
+		return v.Content, nil
+	case foundations.NoneValue:
+		return foundations.Content{}, nil
+	case foundations.SymbolValue:
+		return foundations.Content{Elements: []foundations.ContentElement{
+			&foundations.SymbolElem{Text: v.Get()},
+		}}, nil
+	default:
+		return v.Display(), nil
+	}
+}
+
+// castLength converts an optional length argument. None and auto yield nil.
+func castLength(arg *syntax.Spanned[foundations.Value]) (*foundations.Length, error) {
+	if arg == nil || foundations.IsNone(arg.V) || foundations.IsAuto(arg.V) {
+		return nil, nil
+	}
+	lv, ok := arg.V.(foundations.LengthValue)
+	if !ok {
+		return nil, &foundations.TypeMismatchError{
+			Expected: "length",
+			Got:      arg.V.Type().String(),
+			Span:     arg.Span,
+		}
+	}
+	length := lv.Length
+	return &length, nil
+}
+
+// castRelative converts an optional relative length argument. Auto yields
+// nil.
+func castRelative(arg *syntax.Spanned[foundations.Value]) (*foundations.Relative, error) {
+	if arg == nil || foundations.IsAuto(arg.V) {
+		return nil, nil
+	}
+	var rel foundations.Relative
+	switch v := arg.V.(type) {
+	case foundations.RelativeValue:
+		rel = v.Relative
+	case foundations.LengthValue:
+		rel.Abs = v.Length
+	case foundations.RatioValue:
+		rel.Rel = v.Ratio
+	default:
+		return nil, &foundations.TypeMismatchError{
+			Expected: "auto or relative length",
+			Got:      arg.V.Type().String(),
+			Span:     arg.Span,
+		}
+	}
+	return &rel, nil
+}
+
+// contentValue wraps a single element as a content value.
+func contentValue(elem foundations.ContentElement) foundations.Value {
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}
+}
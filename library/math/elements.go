@@ -3,7 +3,11 @@
 
 package math
 
-import "github.com/boergens/gotypst/library/foundations"
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/library/visualize"
+)
 
 // EquationElem represents a mathematical equation.
 // Matches Rust: typst-library/src/math/equation.rs
@@ -12,6 +16,16 @@ type EquationElem struct {
 	Body foundations.Content
 	// Block indicates if this is a block (display) equation.
 	Block bool
+	// Numbering is how to number block equations (nil for none).
+	Numbering *model.Numbering
+	// NumberAlign is the alignment of the equation number
+	// (nil for the default, end + horizon).
+	NumberAlign foundations.Value
+	// Supplement is the reference supplement (nil or auto for "Equation").
+	Supplement foundations.Value
+	// Alt is an alternative description of the equation.
+	Alt *string
+	foundations.ElemMeta
 }
 
 func (*EquationElem) IsContentElement() {}
@@ -27,6 +41,89 @@ type FracElem struct {
 
 func (*FracElem) IsContentElement() {}
 
+// BinomElem represents a binomial coefficient.
+// Matches Rust: typst-library/src/math/frac.rs
+type BinomElem struct {
+	// Upper is the binomial's upper index.
+	Upper foundations.Content
+	// Lower are the binomial's lower indices, separated by commas.
+	Lower []foundations.Content
+}
+
+func (*BinomElem) IsContentElement() {}
+
+// VecElem represents a column vector.
+// Matches Rust: typst-library/src/math/matrix.rs
+type VecElem struct {
+	// Children are the vector's elements.
+	Children []foundations.Content
+	// Delim is the delimiter pair around the vector.
+	Delim Delimiters
+	// Align is the horizontal alignment of the elements
+	// ("start", "center", or "end").
+	Align string
+	// Gap is the gap between elements (nil for the default).
+	Gap *foundations.Length
+}
+
+func (*VecElem) IsContentElement() {}
+
+// MatElem represents a matrix.
+// Matches Rust: typst-library/src/math/matrix.rs
+type MatElem struct {
+	// Rows are the matrix's rows, each a list of cells.
+	Rows [][]foundations.Content
+	// Delim is the delimiter pair around the matrix.
+	Delim Delimiters
+	// Align is the horizontal alignment of the cells
+	// ("start", "center", or "end").
+	Align string
+	// Augment describes the augmentation lines (nil for none).
+	Augment *Augment
+	// RowGap is the gap between rows (nil for the default).
+	RowGap *foundations.Length
+	// ColumnGap is the gap between columns (nil for the default).
+	ColumnGap *foundations.Length
+}
+
+func (*MatElem) IsContentElement() {}
+
+// CasesElem represents a case distinction.
+// Matches Rust: typst-library/src/math/cases.rs
+type CasesElem struct {
+	// Children are the branches of the case distinction.
+	Children []foundations.Content
+	// Delim is the delimiter pair. Only the side facing the branches is shown.
+	Delim Delimiters
+	// Reverse puts the delimiter on the right side instead of the left.
+	Reverse bool
+	// Gap is the gap between branches (nil for the default).
+	Gap *foundations.Length
+}
+
+func (*CasesElem) IsContentElement() {}
+
+// Delimiters is a pair of opening and closing delimiters.
+// An empty string means there is no delimiter on that side.
+// Matches Rust: DelimiterPair in typst-library/src/math/matrix.rs
+type Delimiters struct {
+	Open  string
+	Close string
+}
+
+// Augment describes the lines drawn through a matrix.
+// Matches Rust: Augment in typst-library/src/math/matrix.rs
+type Augment struct {
+	// HLine are the row indices after which horizontal lines are drawn.
+	// Negative indices count from the bottom.
+	HLine []int
+	// VLine are the column indices after which vertical lines are drawn.
+	// Negative indices count from the right.
+	VLine []int
+	// Stroke is the stroke of the lines (nil for the default).
+	Stroke *visualize.Stroke
+}
+
 // RootElem represents a root (square root, nth root).
 // Matches Rust: typst-library/src/math/root.rs
 type RootElem struct {
@@ -49,19 +146,47 @@ type AttachElem struct {
 	B *foundations.Content
 	// TR is the top-right content (for primes).
 	TR *foundations.Content
+	// TL is the top-left content.
+	TL *foundations.Content
+	// BL is the bottom-left content.
+	BL *foundations.Content
+	// BR is the bottom-right content.
+	BR *foundations.Content
 }
 
 func (*AttachElem) IsContentElement() {}
 
+// ScriptsElem forces attachments to be placed as scripts (to the side),
+// even on large operators.
+// Matches Rust: typst-library/src/math/attach.rs
+type ScriptsElem struct {
+	// Body is the base to which the attachments are applied.
+	Body foundations.Content
+}
+
+func (*ScriptsElem) IsContentElement() {}
+
 // LrElem represents left-right delimited content.
 // Matches Rust: typst-library/src/math/lr.rs
 type LrElem struct {
 	// Body is the delimited content (including delimiters).
 	Body foundations.Content
+	// Size is the size of the delimiters relative to the height of the
+	// wrapped content (nil for auto, i.e. 100%).
+	Size *foundations.Relative
 }
 
 func (*LrElem) IsContentElement() {}
 
+// MidElem scales a delimiter in the middle of an lr() group.
+// Matches Rust: typst-library/src/math/lr.rs (MidElem)
+type MidElem struct {
+	// Body is the delimiter to scale.
+	Body foundations.Content
+}
+
+func (*MidElem) IsContentElement() {}
+
 // AlignPointElem represents an alignment point in equations.
 // Matches Rust: typst-library/src/math/align.rs
 type AlignPointElem struct{}
@@ -85,12 +210,13 @@ type PrimesElem struct {
 
 func (*PrimesElem) IsContentElement() {}
 
-// LimitsElem represents an operator with limits above and below.
-// Matches Rust: typst-library/src/math/op.rs
+// LimitsElem forces attachments to be placed as limits (above and below).
+// Matches Rust: typst-library/src/math/attach.rs
 type LimitsElem struct {
 	// Body is the main operator content.
 	Body foundations.Content
-	// Inline indicates inline (side) positioning vs display (above/below).
+	// Inline indicates whether limits are also used in inline (non-display)
+	// equations. If false, inline equations get scripts instead.
 	Inline bool
 }
 
@@ -109,13 +235,15 @@ func (*AccentElem) IsContentElement() {}
 
 // Common accent characters.
 const (
-	AccentHat    = '\u0302' // COMBINING CIRCUMFLEX ACCENT
-	AccentTilde  = '\u0303' // COMBINING TILDE
-	AccentBar    = '\u0304' // COMBINING MACRON
-	AccentVec    = '\u20D7' // COMBINING RIGHT ARROW ABOVE
-	AccentDot    = '\u0307' // COMBINING DOT ABOVE
-	AccentDDot   = '\u0308' // COMBINING DIAERESIS
-	AccentBreve  = '\u0306' // COMBINING BREVE
-	AccentAcute  = '\u0301' // COMBINING ACUTE ACCENT
-	AccentGrave  = '\u0300' // COMBINING GRAVE ACCENT
+	AccentHat   = '\u0302' // COMBINING CIRCUMFLEX ACCENT
+	AccentTilde = '\u0303' // COMBINING TILDE
+	AccentBar   = '\u0304' // COMBINING MACRON
+	AccentVec   = '\u20D7' // COMBINING RIGHT ARROW ABOVE
+	AccentDot   = '\u0307' // COMBINING DOT ABOVE
+	AccentDDot  = '\u0308' // COMBINING DIAERESIS
+	AccentBreve = '\u0306' // COMBINING BREVE
+	AccentAcute = '\u0301' // COMBINING ACUTE ACCENT
+	AccentGrave = '\u0300' // COMBINING GRAVE ACCENT
+	AccentRing  = '\u030A' // COMBINING RING ABOVE
+	AccentCaron = '\u030C' // COMBINING CARON
 )
@@ -0,0 +1,182 @@
+// Equation element function for Typst.
+// Translated from typst-library/src/math/equation.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// DefaultEquationSupplement is the reference supplement used when none is set.
+const DefaultEquationSupplement = "Equation"
+
+// EquationFunc creates the equation element function.
+func EquationFunc() *foundations.Func {
+	name := "equation"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: equationNative,
+			Info: &foundations.FuncInfo{
+				Name: "equation",
+				Params: []foundations.ParamInfo{
+					{Name: "block", Type: foundations.TypeBool, Default: foundations.False, Named: true},
+					{Name: "numbering", Type: foundations.TypeDyn, Default: foundations.None, Named: true},
+					{Name: "number-align", Type: foundations.TypeDyn, Default: foundations.None, Named: true},
+					{Name: "supplement", Type: foundations.TypeDyn, Default: foundations.Auto, Named: true},
+					{Name: "alt", Type: foundations.TypeStr, Default: foundations.None, Named: true},
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// equationNative implements the equation() function.
+func equationNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &EquationElem{}
+
+	if blockArg := args.Named("block"); blockArg != nil {
+		block, ok := foundations.AsBool(blockArg.V)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{
+				Expected: "bool",
+				Got:      blockArg.V.Type().String(),
+				Span:     blockArg.Span,
+			}
+		}
+		elem.Block = block
+	}
+
+	if numbArg := args.Named("numbering"); numbArg != nil {
+		numbering, err := model.NumberingFromValue(numbArg.V)
+		if err != nil {
+			return nil, atSpan(err, numbArg.Span)
+		}
+		elem.Numbering = numbering
+	}
+
+	if naArg := args.Named("number-align"); naArg != nil {
+		if !foundations.IsNone(naArg.V) && !foundations.IsAuto(naArg.V) {
+			elem.NumberAlign = naArg.V
+		}
+	}
+
+	if suppArg := args.Named("supplement"); suppArg != nil {
+		if !foundations.IsAuto(suppArg.V) {
+			elem.Supplement = suppArg.V
+		}
+	}
+
+	if altArg := args.Named("alt"); altArg != nil {
+		if !foundations.IsNone(altArg.V) {
+			alt, ok := foundations.AsStr(altArg.V)
+			if !ok {
+				return nil, &foundations.TypeMismatchError{
+					Expected: "string or none",
+					Got:      altArg.V.Type().String(),
+					Span:     altArg.Span,
+				}
+			}
+			elem.Alt = &alt
+		}
+	}
+
+	bodyArg, err := args.Expect("body")
+	if err != nil {
+		return nil, err
+	}
+	cv, ok := bodyArg.V.(foundations.ContentValue)
+	if !ok {
+		return nil, &foundations.TypeMismatchError{
+			Expected: "content",
+			Got:      bodyArg.V.Type().String(),
+			Span:     bodyArg.Span,
+		}
+	}
+	elem.Body = cv.Content
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// Synthesize fills in the properties that were not set on the element from
+// `set math.equation(..)` rules in the style chain.
+// Matches Rust: impl Synthesize for Packed<EquationElem>
+func (e *EquationElem) Synthesize(styles *foundations.StyleChain) error {
+	if e.Numbering == nil {
+		if v := styles.Get("equation", "numbering"); v != nil {
+			numbering, err := model.NumberingFromValue(v)
+			if err != nil {
+				return err
+			}
+			e.Numbering = numbering
+		}
+	}
+	if e.NumberAlign == nil {
+		if v := styles.Get("equation", "number-align"); v != nil && !foundations.IsAuto(v) {
+			e.NumberAlign = v
+		}
+	}
+	if e.Supplement == nil {
+		if v := styles.Get("equation", "supplement"); v != nil && !foundations.IsAuto(v) {
+			e.Supplement = v
+		}
+	}
+	return nil
+}
+
+// Numbered returns whether the equation receives a number. Only block
+// equations with a numbering are numbered.
+func (e *EquationElem) Numbered() bool {
+	return e.Block && e.Numbering != nil
+}
+
+// NumberAtStart reports whether the equation number is placed at the start
+// of the line rather than at the end.
+func (e *EquationElem) NumberAtStart() bool {
+	s, ok := foundations.AsStr(e.NumberAlign)
+	if !ok {
+		return false
+	}
+	switch s {
+	case "start", "left":
+		return true
+	default:
+		return false
+	}
+}
+
+// RefSupplement returns the supplement used when referencing the equation.
+// Matches Rust: impl Refable for Packed<EquationElem> (fn supplement)
+func (e *EquationElem) RefSupplement() foundations.Value {
+	if e.Supplement == nil || foundations.IsAuto(e.Supplement) {
+		return foundations.Str(DefaultEquationSupplement)
+	}
+	return e.Supplement
+}
+
+// RefCounter returns the name of the equation counter.
+// Matches Rust: impl Refable for Packed<EquationElem> (fn counter)
+func (e *EquationElem) RefCounter() string {
+	return "equation"
+}
+
+// RefNumbering returns the equation's numbering, or nil if unnumbered.
+// Matches Rust: impl Refable for Packed<EquationElem> (fn numbering)
+func (e *EquationElem) RefNumbering() *model.Numbering {
+	if !e.Block {
+		return nil
+	}
+	return e.Numbering
+}
+
+var _ model.Refable = (*EquationElem)(nil)
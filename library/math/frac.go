@@ -0,0 +1,104 @@
+// Fraction and binomial functions for Typst.
+// Translated from typst-library/src/math/frac.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// FracFunc creates the frac element function.
+func FracFunc() *foundations.Func {
+	name := "frac"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: fracNative,
+			Info: &foundations.FuncInfo{
+				Name: "frac",
+				Params: []foundations.ParamInfo{
+					{Name: "num", Type: foundations.TypeContent, Named: false},
+					{Name: "denom", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// fracNative implements the frac() function.
+func fracNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	numArg, err := args.Expect("numerator")
+	if err != nil {
+		return nil, err
+	}
+	denomArg, err := args.Expect("denominator")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	num, err := castContent(numArg)
+	if err != nil {
+		return nil, err
+	}
+	denom, err := castContent(denomArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return contentValue(&FracElem{Num: num, Denom: denom}), nil
+}
+
+// BinomFunc creates the binom element function.
+func BinomFunc() *foundations.Func {
+	name := "binom"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: binomNative,
+			Info: &foundations.FuncInfo{
+				Name: "binom",
+				Params: []foundations.ParamInfo{
+					{Name: "upper", Type: foundations.TypeContent, Named: false},
+					{Name: "lower", Type: foundations.TypeContent, Named: false, Variadic: true},
+				},
+			},
+		},
+	}
+}
+
+// binomNative implements the binom() function.
+func binomNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	upperArg, err := args.Expect("upper index")
+	if err != nil {
+		return nil, err
+	}
+	upper, err := castContent(upperArg)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := args.All()
+	if len(rest) == 0 {
+		return nil, &foundations.MissingArgumentError{Name: "lower index", Span: args.Span}
+	}
+	elem := &BinomElem{Upper: upper}
+	for _, arg := range rest {
+		lower, err := castContent(arg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Lower = append(elem.Lower, lower)
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(elem), nil
+}
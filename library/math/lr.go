@@ -0,0 +1,145 @@
+// Left/right delimiter functions for Typst.
+// Translated from typst-library/src/math/lr.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// LrFunc creates the lr element function.
+func LrFunc() *foundations.Func {
+	name := "lr"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: lrNative,
+			Info: &foundations.FuncInfo{
+				Name: "lr",
+				Params: []foundations.ParamInfo{
+					{Name: "size", Type: foundations.TypeRelative, Default: foundations.Auto, Named: true},
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// lrNative implements the lr() function.
+func lrNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	size, err := castRelative(args.Named("size"))
+	if err != nil {
+		return nil, err
+	}
+
+	bodyArg, err := args.Expect("body")
+	if err != nil {
+		return nil, err
+	}
+	body, err := castContent(bodyArg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(&LrElem{Body: body, Size: size}), nil
+}
+
+// MidFunc creates the mid element function.
+func MidFunc() *foundations.Func {
+	name := "mid"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: midNative,
+			Info: &foundations.FuncInfo{
+				Name: "mid",
+				Params: []foundations.ParamInfo{
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// midNative implements the mid() function.
+func midNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	bodyArg, err := args.Expect("body")
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	body, err := castContent(bodyArg)
+	if err != nil {
+		return nil, err
+	}
+	return contentValue(&MidElem{Body: body}), nil
+}
+
+// delimitedFuncs lists the functions that wrap their argument in a fixed
+// pair of delimiters.
+// Matches Rust: abs, norm, floor, ceil, and round in math/lr.rs
+var delimitedFuncs = []struct {
+	name        string
+	left, right string
+}{
+	{"abs", "|", "|"},
+	{"norm", "‖", "‖"},
+	{"floor", "⌊", "⌋"},
+	{"ceil", "⌈", "⌉"},
+	{"round", "⌊", "⌉"},
+}
+
+// delimitedFunc creates a function like abs() that wraps its body in the
+// given delimiters and scales them to the body's height.
+func delimitedFunc(name, left, right string) *foundations.Func {
+	funcName := name
+	return &foundations.Func{
+		Name: &funcName,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				size, err := castRelative(args.Named("size"))
+				if err != nil {
+					return nil, err
+				}
+				bodyArg, err := args.Expect("body")
+				if err != nil {
+					return nil, err
+				}
+				body, err := castContent(bodyArg)
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				return contentValue(&LrElem{Body: Delimited(body, left, right), Size: size}), nil
+			},
+			Info: &foundations.FuncInfo{
+				Name: name,
+				Params: []foundations.ParamInfo{
+					{Name: "size", Type: foundations.TypeRelative, Default: foundations.Auto, Named: true},
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
+
+// Delimited surrounds body with the left and right delimiters.
+func Delimited(body foundations.Content, left, right string) foundations.Content {
+	elements := make([]foundations.ContentElement, 0, len(body.Elements)+2)
+	elements = append(elements, &foundations.SymbolElem{Text: left})
+	elements = append(elements, body.Elements...)
+	elements = append(elements, &foundations.SymbolElem{Text: right})
+	return foundations.Content{Elements: elements}
+}
@@ -0,0 +1,476 @@
+// Matrix, vector, and case distinction functions for Typst.
+// Translated from typst-library/src/math/matrix.rs and cases.rs
+
+package math
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/visualize"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Default delimiters of the matrix-like elements.
+var (
+	DefaultVecDelim   = Delimiters{Open: "(", Close: ")"}
+	DefaultMatDelim   = Delimiters{Open: "(", Close: ")"}
+	DefaultCasesDelim = Delimiters{Open: "{", Close: "}"}
+)
+
+// closingDelimiters maps opening delimiters to their closing counterpart.
+var closingDelimiters = map[string]string{
+	"(":  ")",
+	"[":  "]",
+	"{":  "}",
+	"|":  "|",
+	"||": "‖",
+	"‖":  "‖",
+	"⟨":  "⟩",
+	"<":  "⟩",
+	"⌈":  "⌉",
+	"⌊":  "⌋",
+}
+
+// VecFunc creates the vec element function.
+func VecFunc() *foundations.Func {
+	name := "vec"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: vecNative,
+			Info: &foundations.FuncInfo{
+				Name: "vec",
+				Params: []foundations.ParamInfo{
+					{Name: "delim", Type: foundations.TypeDyn, Default: foundations.Str("("), Named: true},
+					{Name: "align", Type: foundations.TypeDyn, Default: foundations.Str("center"), Named: true},
+					{Name: "gap", Type: foundations.TypeLength, Default: foundations.Auto, Named: true},
+					{Name: "children", Type: foundations.TypeContent, Named: false, Variadic: true},
+				},
+			},
+		},
+	}
+}
+
+// vecNative implements the vec() function.
+func vecNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &VecElem{Delim: DefaultVecDelim, Align: "center"}
+
+	if delimArg := args.Named("delim"); delimArg != nil {
+		delim, err := parseDelimiters(*delimArg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Delim = delim
+	}
+	if alignArg := args.Named("align"); alignArg != nil {
+		align, err := parseCellAlign(*alignArg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Align = align
+	}
+	gap, err := castLength(args.Named("gap"))
+	if err != nil {
+		return nil, err
+	}
+	elem.Gap = gap
+
+	for _, arg := range args.All() {
+		child, err := castContent(arg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Children = append(elem.Children, child)
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(elem), nil
+}
+
+// MatFunc creates the mat element function.
+func MatFunc() *foundations.Func {
+	name := "mat"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: matNative,
+			Info: &foundations.FuncInfo{
+				Name: "mat",
+				Params: []foundations.ParamInfo{
+					{Name: "delim", Type: foundations.TypeDyn, Default: foundations.Str("("), Named: true},
+					{Name: "align", Type: foundations.TypeDyn, Default: foundations.Str("center"), Named: true},
+					{Name: "augment", Type: foundations.TypeDyn, Default: foundations.None, Named: true},
+					{Name: "gap", Type: foundations.TypeLength, Default: foundations.Auto, Named: true},
+					{Name: "row-gap", Type: foundations.TypeLength, Default: foundations.Auto, Named: true},
+					{Name: "column-gap", Type: foundations.TypeLength, Default: foundations.Auto, Named: true},
+					{Name: "rows", Type: foundations.TypeArray, Named: false, Variadic: true},
+				},
+			},
+		},
+	}
+}
+
+// matNative implements the mat() function.
+//
+// In math mode, `mat(1, 2; 3, 4)` passes each row as an array. If no
+// argument is an array, the arguments form a single row.
+func matNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &MatElem{Delim: DefaultMatDelim, Align: "center"}
+
+	if delimArg := args.Named("delim"); delimArg != nil {
+		delim, err := parseDelimiters(*delimArg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Delim = delim
+	}
+	if alignArg := args.Named("align"); alignArg != nil {
+		align, err := parseCellAlign(*alignArg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Align = align
+	}
+	if augmentArg := args.Named("augment"); augmentArg != nil {
+		augment, err := parseAugment(*augmentArg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Augment = augment
+	}
+
+	// The gap shorthand sets both gaps; the specific ones take precedence.
+	gap, err := castLength(args.Named("gap"))
+	if err != nil {
+		return nil, err
+	}
+	elem.RowGap, elem.ColumnGap = gap, gap
+	if rowGap, err := castLength(args.Named("row-gap")); err != nil {
+		return nil, err
+	} else if rowGap != nil {
+		elem.RowGap = rowGap
+	}
+	if columnGap, err := castLength(args.Named("column-gap")); err != nil {
+		return nil, err
+	} else if columnGap != nil {
+		elem.ColumnGap = columnGap
+	}
+
+	values := args.All()
+	hasArray := false
+	for _, v := range values {
+		if _, ok := v.V.(*foundations.Array); ok {
+			hasArray = true
+			break
+		}
+	}
+
+	if hasArray {
+		for _, v := range values {
+			var row []foundations.Content
+			if arr, ok := v.V.(*foundations.Array); ok {
+				for _, item := range arr.Items() {
+					cell, err := castContent(syntax.Spanned[foundations.Value]{V: item, Span: v.Span})
+					if err != nil {
+						return nil, err
+					}
+					row = append(row, cell)
+				}
+			} else {
+				cell, err := castContent(v)
+				if err != nil {
+					return nil, err
+				}
+				row = append(row, cell)
+			}
+			elem.Rows = append(elem.Rows, row)
+		}
+	} else if len(values) > 0 {
+		var row []foundations.Content
+		for _, v := range values {
+			cell, err := castContent(v)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, cell)
+		}
+		elem.Rows = append(elem.Rows, row)
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	if elem.Augment != nil {
+		if err := elem.Augment.check(elem.Rows, args.Span); err != nil {
+			return nil, err
+		}
+	}
+
+	return contentValue(elem), nil
+}
+
+// CasesFunc creates the cases element function.
+func CasesFunc() *foundations.Func {
+	name := "cases"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: casesNative,
+			Info: &foundations.FuncInfo{
+				Name: "cases",
+				Params: []foundations.ParamInfo{
+					{Name: "delim", Type: foundations.TypeDyn, Default: foundations.Str("{"), Named: true},
+					{Name: "reverse", Type: foundations.TypeBool, Default: foundations.False, Named: true},
+					{Name: "gap", Type: foundations.TypeLength, Default: foundations.Auto, Named: true},
+					{Name: "children", Type: foundations.TypeContent, Named: false, Variadic: true},
+				},
+			},
+		},
+	}
+}
+
+// casesNative implements the cases() function.
+func casesNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &CasesElem{Delim: DefaultCasesDelim}
+
+	if delimArg := args.Named("delim"); delimArg != nil {
+		delim, err := parseDelimiters(*delimArg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Delim = delim
+	}
+	if reverseArg := args.Named("reverse"); reverseArg != nil {
+		reverse, ok := foundations.AsBool(reverseArg.V)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{
+				Expected: "bool",
+				Got:      reverseArg.V.Type().String(),
+				Span:     reverseArg.Span,
+			}
+		}
+		elem.Reverse = reverse
+	}
+	gap, err := castLength(args.Named("gap"))
+	if err != nil {
+		return nil, err
+	}
+	elem.Gap = gap
+
+	for _, arg := range args.All() {
+		child, err := castContent(arg)
+		if err != nil {
+			return nil, err
+		}
+		elem.Children = append(elem.Children, child)
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(elem), nil
+}
+
+// parseDelimiters casts a delim argument. It accepts none, a single opening
+// delimiter (whose closing counterpart is inferred), or an array of two
+// delimiters, each a string or none.
+func parseDelimiters(arg syntax.Spanned[foundations.Value]) (Delimiters, error) {
+	switch v := arg.V.(type) {
+	case foundations.NoneValue:
+		return Delimiters{}, nil
+	case foundations.Str:
+		open := string(v)
+		if close, ok := closingDelimiters[open]; ok {
+			if open == "||" {
+				open = "‖"
+			} else if open == "<" {
+				open = "⟨"
+			}
+			return Delimiters{Open: open, Close: close}, nil
+		}
+		return Delimiters{}, &foundations.ConstructorError{
+			Message: fmt.Sprintf("invalid delimiter: %q", open),
+			Span:    arg.Span,
+			Hints:   []string{"use an array of two delimiters for a custom pair"},
+		}
+	case *foundations.Array:
+		if v.Len() != 2 {
+			return Delimiters{}, &foundations.ConstructorError{
+				Message: fmt.Sprintf("expected 2 delimiters, found %d", v.Len()),
+				Span:    arg.Span,
+			}
+		}
+		var sides [2]string
+		for i, item := range v.Items() {
+			switch d := item.(type) {
+			case foundations.NoneValue:
+			case foundations.Str:
+				sides[i] = string(d)
+			case foundations.SymbolValue:
+				sides[i] = d.Get()
+			default:
+				return Delimiters{}, &foundations.TypeMismatchError{
+					Expected: "string or none",
+					Got:      item.Type().String(),
+					Span:     arg.Span,
+				}
+			}
+		}
+		return Delimiters{Open: sides[0], Close: sides[1]}, nil
+	default:
+		return Delimiters{}, &foundations.TypeMismatchError{
+			Expected: "string, array, or none",
+			Got:      arg.V.Type().String(),
+			Span:     arg.Span,
+		}
+	}
+}
+
+// parseCellAlign casts an align argument for matrix-like elements.
+func parseCellAlign(arg syntax.Spanned[foundations.Value]) (string, error) {
+	s, ok := foundations.AsStr(arg.V)
+	if ok {
+		switch s {
+		case "start", "left":
+			return "start", nil
+		case "center":
+			return "center", nil
+		case "end", "right":
+			return "end", nil
+		}
+	}
+	got := arg.V.Type().String()
+	if ok {
+		got = fmt.Sprintf("%q", s)
+	}
+	return "", &foundations.TypeMismatchError{
+		Expected: "start, center, or end",
+		Got:      got,
+		Span:     arg.Span,
+	}
+}
+
+// parseAugment casts an augment argument. It accepts none, an integer (a
+// vertical line after that column), or a dictionary with hline, vline, and
+// stroke keys.
+func parseAugment(arg syntax.Spanned[foundations.Value]) (*Augment, error) {
+	if foundations.IsNone(arg.V) {
+		return nil, nil
+	}
+	if n, ok := foundations.AsInt(arg.V); ok {
+		return &Augment{VLine: []int{int(n)}}, nil
+	}
+	dict, ok := foundations.AsDict(arg.V)
+	if !ok {
+		return nil, &foundations.TypeMismatchError{
+			Expected: "integer, dictionary, or none",
+			Got:      arg.V.Type().String(),
+			Span:     arg.Span,
+		}
+	}
+
+	augment := &Augment{}
+	for _, key := range dict.Keys() {
+		value, _ := dict.Get(key)
+		switch key {
+		case "hline", "vline":
+			lines, err := castOffsets(value, arg.Span)
+			if err != nil {
+				return nil, err
+			}
+			if key == "hline" {
+				augment.HLine = lines
+			} else {
+				augment.VLine = lines
+			}
+		case "stroke":
+			if !foundations.IsAuto(value) {
+				stroke, err := visualize.CastStroke(value, arg.Span)
+				if err != nil {
+					return nil, err
+				}
+				augment.Stroke = &stroke
+			}
+		default:
+			return nil, &foundations.ConstructorError{
+				Message: fmt.Sprintf("unexpected key %q, valid keys are \"hline\", \"vline\", and \"stroke\"", key),
+				Span:    arg.Span,
+			}
+		}
+	}
+	return augment, nil
+}
+
+// castOffsets casts an integer or an array of integers to line offsets.
+func castOffsets(v foundations.Value, span syntax.Span) ([]int, error) {
+	if n, ok := foundations.AsInt(v); ok {
+		return []int{int(n)}, nil
+	}
+	if arr, ok := foundations.AsArray(v); ok {
+		offsets := make([]int, 0, arr.Len())
+		for _, item := range arr.Items() {
+			n, ok := foundations.AsInt(item)
+			if !ok {
+				return nil, &foundations.TypeMismatchError{
+					Expected: "integer",
+					Got:      item.Type().String(),
+					Span:     span,
+				}
+			}
+			offsets = append(offsets, int(n))
+		}
+		return offsets, nil
+	}
+	return nil, &foundations.TypeMismatchError{
+		Expected: "integer or array of integers",
+		Got:      v.Type().String(),
+		Span:     span,
+	}
+}
+
+// check ensures that all augmentation lines lie within the matrix.
+// Lines on the outer edges (offset 0 or the full count) are rejected.
+func (a *Augment) check(rows [][]foundations.Content, span syntax.Span) error {
+	nrows := len(rows)
+	ncols := 0
+	for _, row := range rows {
+		if len(row) > ncols {
+			ncols = len(row)
+		}
+	}
+	for _, offset := range a.HLine {
+		if offset == 0 || offset >= nrows || offset <= -nrows {
+			return &foundations.ConstructorError{
+				Message: fmt.Sprintf("cannot draw a horizontal line at offset %d", offset),
+				Span:    span,
+			}
+		}
+	}
+	for _, offset := range a.VLine {
+		if offset == 0 || offset >= ncols || offset <= -ncols {
+			return &foundations.ConstructorError{
+				Message: fmt.Sprintf("cannot draw a vertical line at offset %d", offset),
+				Span:    span,
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveOffset converts a possibly negative line offset into a line index
+// counted from the start.
+func ResolveOffset(offset, count int) int {
+	if offset < 0 {
+		return count + offset
+	}
+	return offset
+}
@@ -0,0 +1,87 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func spanned(v foundations.Value) syntax.Spanned[foundations.Value] {
+	return syntax.Spanned[foundations.Value]{V: v, Span: syntax.Detached()}
+}
+
+func TestParseDelimiters(t *testing.T) {
+	tests := []struct {
+		value foundations.Value
+		want  Delimiters
+	}{
+		{foundations.Str("("), Delimiters{Open: "(", Close: ")"}},
+		{foundations.Str("["), Delimiters{Open: "[", Close: "]"}},
+		{foundations.Str("||"), Delimiters{Open: "‖", Close: "‖"}},
+		{foundations.None, Delimiters{}},
+		{foundations.NewArray(foundations.Str("["), foundations.None), Delimiters{Open: "["}},
+	}
+	for _, tt := range tests {
+		got, err := parseDelimiters(spanned(tt.value))
+		if err != nil {
+			t.Errorf("parseDelimiters(%v): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDelimiters(%v) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseDelimiters(spanned(foundations.Str("x"))); err == nil {
+		t.Error("expected error for invalid delimiter")
+	}
+	if _, err := parseDelimiters(spanned(foundations.NewArray(foundations.Str("(")))); err == nil {
+		t.Error("expected error for a single-element array")
+	}
+}
+
+func TestParseAugment(t *testing.T) {
+	augment, err := parseAugment(spanned(foundations.Int(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(augment.VLine) != 1 || augment.VLine[0] != 2 || len(augment.HLine) != 0 {
+		t.Errorf("unexpected augment %+v", augment)
+	}
+
+	dict := foundations.NewDict()
+	dict.Insert("hline", foundations.NewArray(foundations.Int(1), foundations.Int(-1)))
+	dict.Insert("vline", foundations.Int(1))
+	dict.Insert("stroke", foundations.LengthValue{Length: foundations.Length{Points: 2}})
+	augment, err = parseAugment(spanned(dict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(augment.HLine) != 2 || augment.HLine[1] != -1 || len(augment.VLine) != 1 {
+		t.Errorf("unexpected augment %+v", augment)
+	}
+	if augment.Stroke == nil || augment.Stroke.ResolvedThickness().Points != 2 {
+		t.Errorf("unexpected stroke %+v", augment.Stroke)
+	}
+
+	rows := [][]foundations.Content{{{}, {}}, {{}, {}}}
+	if err := (&Augment{VLine: []int{2}}).check(rows, syntax.Detached()); err == nil {
+		t.Error("expected error for a line on the outer edge")
+	}
+	if err := (&Augment{HLine: []int{-1}}).check(rows, syntax.Detached()); err != nil {
+		t.Errorf("unexpected error for a negative offset: %v", err)
+	}
+
+	bad := foundations.NewDict()
+	bad.Insert("width", foundations.Int(1))
+	if _, err := parseAugment(spanned(bad)); err == nil {
+		t.Error("expected error for unknown key")
+	}
+
+	badStroke := foundations.NewDict()
+	badStroke.Insert("stroke", foundations.Str("thick"))
+	if _, err := parseAugment(spanned(badStroke)); err == nil {
+		t.Error("expected error for a stroke that is not a stroke")
+	}
+}
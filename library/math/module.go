@@ -0,0 +1,50 @@
+// Math module for Typst.
+// Translated from typst-library/src/math/mod.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/symbols"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Module creates the `math` module containing the math element functions.
+// All symbols of `sym` are available in math directly, so that `$alpha$`
+// produces α. Functions take precedence over symbols of the same name.
+// Matches Rust: pub fn module() -> Module
+func Module() *foundations.Module {
+	scope := foundations.NewScope()
+	symbols.Sym.Define(scope)
+	define := func(f *foundations.Func) {
+		scope.Define(*f.Name, foundations.FuncValue{Func: f}, syntax.Detached())
+	}
+
+	define(EquationFunc())
+	define(FracFunc())
+	define(BinomFunc())
+	define(VecFunc())
+	define(MatFunc())
+	define(CasesFunc())
+	define(AttachFunc())
+	define(ScriptsFunc())
+	define(LimitsFunc())
+	define(AccentFunc())
+	for _, shorthand := range accentShorthands {
+		define(accentShorthandFunc(shorthand.name, shorthand.accent))
+	}
+	define(LrFunc())
+	define(MidFunc())
+	for _, delim := range delimitedFuncs {
+		define(delimitedFunc(delim.name, delim.left, delim.right))
+	}
+	for _, style := range mathStyles {
+		define(mathStyleFunc(style.name, style.apply))
+	}
+	define(OpFunc())
+	for _, op := range predefinedOps {
+		scope.Define(op.name, predefinedOp(op.text, op.limits), syntax.Detached())
+	}
+
+	return &foundations.Module{Name: "math", Scope: scope}
+}
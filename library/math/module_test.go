@@ -0,0 +1,24 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+func TestModuleSymbols(t *testing.T) {
+	scope := Module().Scope
+
+	alpha := scope.Get("alpha")
+	if alpha == nil {
+		t.Fatal("alpha not defined in math")
+	}
+	if sym, ok := alpha.Value().(foundations.SymbolValue); !ok || sym.Get() != "α" {
+		t.Errorf("alpha = %v, want α", alpha.Value())
+	}
+
+	// Functions shadow symbols of the same name.
+	if _, ok := scope.Get("dot").Value().(foundations.FuncValue); !ok {
+		t.Errorf("dot is %T, want the accent function", scope.Get("dot").Value())
+	}
+}
@@ -0,0 +1,134 @@
+// Text operator functions for Typst.
+// Translated from typst-library/src/math/op.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// OpElem is a text operator in an equation, like "sin" or "lim".
+// Matches Rust: typst-library/src/math/op.rs
+type OpElem struct {
+	// Text is the operator's text.
+	Text foundations.Content
+	// Limits indicates whether attachments are placed above and below the
+	// operator in display style.
+	Limits bool
+}
+
+func (*OpElem) IsContentElement() {}
+
+// predefinedOps lists the operators defined in the math module, with the
+// text they display and whether they use limits in display style.
+// Matches Rust: the ops! macro in math/op.rs
+var predefinedOps = []struct {
+	name   string
+	text   string
+	limits bool
+}{
+	{"arccos", "arccos", false},
+	{"arcsin", "arcsin", false},
+	{"arctan", "arctan", false},
+	{"arg", "arg", false},
+	{"cos", "cos", false},
+	{"cosh", "cosh", false},
+	{"cot", "cot", false},
+	{"coth", "coth", false},
+	{"csc", "csc", false},
+	{"csch", "csch", false},
+	{"ctg", "ctg", false},
+	{"deg", "deg", false},
+	{"det", "det", true},
+	{"dim", "dim", false},
+	{"exp", "exp", false},
+	{"gcd", "gcd", true},
+	{"lcm", "lcm", true},
+	{"hom", "hom", false},
+	{"id", "id", false},
+	{"im", "im", false},
+	{"inf", "inf", true},
+	{"ker", "ker", false},
+	{"lg", "lg", false},
+	{"lim", "lim", true},
+	{"liminf", "lim\u2009inf", true},
+	{"limsup", "lim\u2009sup", true},
+	{"ln", "ln", false},
+	{"log", "log", false},
+	{"max", "max", true},
+	{"min", "min", true},
+	{"mod", "mod", false},
+	{"Pr", "Pr", true},
+	{"sec", "sec", false},
+	{"sech", "sech", false},
+	{"sin", "sin", false},
+	{"sinc", "sinc", false},
+	{"sinh", "sinh", false},
+	{"sup", "sup", true},
+	{"tan", "tan", false},
+	{"tanh", "tanh", false},
+	{"tg", "tg", false},
+	{"tr", "tr", false},
+}
+
+// OpFunc creates the op element function.
+func OpFunc() *foundations.Func {
+	name := "op"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: opNative,
+			Info: &foundations.FuncInfo{
+				Name: "op",
+				Params: []foundations.ParamInfo{
+					{Name: "text", Type: foundations.TypeContent, Named: false},
+					{Name: "limits", Type: foundations.TypeBool, Default: foundations.False, Named: true},
+				},
+			},
+		},
+	}
+}
+
+// opNative implements the op() function.
+func opNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem := &OpElem{}
+
+	if limitsArg := args.Named("limits"); limitsArg != nil {
+		limits, ok := foundations.AsBool(limitsArg.V)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{
+				Expected: "bool",
+				Got:      limitsArg.V.Type().String(),
+				Span:     limitsArg.Span,
+			}
+		}
+		elem.Limits = limits
+	}
+
+	textArg, err := args.Expect("text")
+	if err != nil {
+		return nil, err
+	}
+	elem.Text, err = castContent(textArg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+
+	return contentValue(elem), nil
+}
+
+// predefinedOp creates the content value of a predefined operator.
+func predefinedOp(text string, limits bool) foundations.Value {
+	return contentValue(&OpElem{
+		Text: foundations.Content{Elements: []foundations.ContentElement{
+			&foundations.SymbolElem{Text: text},
+		}},
+		Limits: limits,
+	})
+}
@@ -0,0 +1,128 @@
+// Math style functions for Typst.
+// Translated from typst-library/src/math/style.rs
+
+package math
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// MathVariant is a font variant in math, selecting one of the Unicode
+// mathematical alphanumeric alphabets.
+// Matches Rust: pub enum MathVariant
+type MathVariant int
+
+const (
+	// VariantSerif is the default serif alphabet.
+	VariantSerif MathVariant = iota
+	// VariantSans is the sans-serif alphabet.
+	VariantSans
+	// VariantCal is the calligraphic (script) alphabet.
+	VariantCal
+	// VariantFrak is the fraktur alphabet.
+	VariantFrak
+	// VariantMono is the monospace alphabet.
+	VariantMono
+	// VariantBb is the blackboard bold (double-struck) alphabet.
+	VariantBb
+)
+
+// String returns the name of the variant.
+func (v MathVariant) String() string {
+	switch v {
+	case VariantSerif:
+		return "serif"
+	case VariantSans:
+		return "sans"
+	case VariantCal:
+		return "cal"
+	case VariantFrak:
+		return "frak"
+	case VariantMono:
+		return "mono"
+	case VariantBb:
+		return "bb"
+	default:
+		return "unknown"
+	}
+}
+
+// MathStyleElem applies a font variant, weight, or slant to math content.
+// Unset properties are inherited from the surrounding math.
+// Matches Rust: typst-library/src/math/style.rs (MathStyleElem)
+type MathStyleElem struct {
+	// Body is the styled content.
+	Body foundations.Content
+	// Variant is the font variant (nil to inherit).
+	Variant *MathVariant
+	// Bold selects the bold weight (nil to inherit).
+	Bold *bool
+	// Italic selects the italic slant (nil to inherit, which makes single
+	// letters italic by default).
+	Italic *bool
+}
+
+func (*MathStyleElem) IsContentElement() {}
+
+// mathStyles lists the style functions defined in the math module together
+// with the style they apply.
+// Matches Rust: upright, italic, bold, serif, sans, cal, frak, mono, and bb
+// in math/style.rs
+var mathStyles = []struct {
+	name  string
+	apply func(elem *MathStyleElem)
+}{
+	{"upright", func(e *MathStyleElem) { e.Italic = boolPtr(false) }},
+	{"italic", func(e *MathStyleElem) { e.Italic = boolPtr(true) }},
+	{"bold", func(e *MathStyleElem) { e.Bold = boolPtr(true) }},
+	{"serif", variantStyle(VariantSerif)},
+	{"sans", variantStyle(VariantSans)},
+	{"cal", variantStyle(VariantCal)},
+	{"frak", variantStyle(VariantFrak)},
+	{"mono", variantStyle(VariantMono)},
+	{"bb", variantStyle(VariantBb)},
+}
+
+// variantStyle returns a style that selects the given variant.
+func variantStyle(variant MathVariant) func(*MathStyleElem) {
+	return func(e *MathStyleElem) { e.Variant = &variant }
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// mathStyleFunc creates a function like bold() that applies a fixed style
+// to its argument.
+func mathStyleFunc(name string, apply func(*MathStyleElem)) *foundations.Func {
+	funcName := name
+	return &foundations.Func{
+		Name: &funcName,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				bodyArg, err := args.Expect("body")
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				body, err := castContent(bodyArg)
+				if err != nil {
+					return nil, err
+				}
+				elem := &MathStyleElem{Body: body}
+				apply(elem)
+				return contentValue(elem), nil
+			},
+			Info: &foundations.FuncInfo{
+				Name: name,
+				Params: []foundations.ParamInfo{
+					{Name: "body", Type: foundations.TypeContent, Named: false},
+				},
+			},
+		},
+	}
+}
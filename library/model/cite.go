@@ -0,0 +1,218 @@
+// Citation elements for Typst.
+// Translated from typst-library/src/model/cite.rs
+
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// CiteElem cites an entry of the bibliography by its label.
+//
+// Adjacent citations, separated by at most spaces, are grouped into a
+// CiteGroup during realization, so that they form a single cluster like
+// "[1]–[3]".
+// Corresponds to Rust's CiteElem in model/cite.rs.
+type CiteElem struct {
+	// Key is the label of the cited bibliography entry.
+	// Required field.
+	Key string `typst:"key,positional,required,type=label"`
+
+	// Supplement is shown with the citation, like a page number.
+	Supplement *foundations.Content `typst:"supplement,type=content"`
+
+	// Form is how the citation is shown: "normal", "prose", "full",
+	// "author", or "year". Default: "normal".
+	Form *string `typst:"form,type=str"`
+
+	// Style is the citation style. Default: the style of the
+	// bibliography.
+	Style *string `typst:"style,type=str"`
+
+	foundations.ElemMeta
+}
+
+func (*CiteElem) IsContentElement() {}
+
+// CiteDef is the registered element definition for cite.
+var CiteDef *foundations.ElementDef
+
+func init() {
+	CiteDef = foundations.RegisterElement[CiteElem]("cite", nil)
+}
+
+// citeForms are the valid values of the form field.
+var citeForms = []string{"normal", "prose", "full", "author", "year"}
+
+// CiteFunc creates the cite element function.
+func CiteFunc() *foundations.Func {
+	name := "cite"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: citeNative,
+			Info: CiteDef.ToFuncInfo(),
+		},
+	}
+}
+
+// citeNative implements the cite() function.
+func citeNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[CiteElem](CiteDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Form != nil && !slices.Contains(citeForms, *elem.Form) {
+		return nil, foundations.NewSourceError(args.Span, fmt.Sprintf("unknown citation form %q", *elem.Form)).
+			WithHint(`expected "normal", "prose", "full", "author", or "year"`)
+	}
+	if elem.Style != nil {
+		if _, ok := CitationStyleByName(*elem.Style); !ok {
+			return nil, foundations.NewSourceError(args.Span, fmt.Sprintf("unknown citation style %q", *elem.Style))
+		}
+	}
+	elem.Span = args.Span
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// ResolvedForm returns the citation's form, defaulting to "normal".
+func (e *CiteElem) ResolvedForm() string {
+	if e.Form == nil {
+		return "normal"
+	}
+	return *e.Form
+}
+
+// CiteGroup is a cluster of adjacent citations, which is shown as a
+// whole. It can't be constructed directly.
+// Corresponds to Rust's CiteGroup in model/cite.rs.
+type CiteGroup struct {
+	// Children are the citations of the cluster, in document order.
+	Children []*CiteElem
+
+	// Span is where the first citation was created.
+	Span syntax.Span
+}
+
+func (*CiteGroup) IsContentElement() {}
+
+// Format formats the cluster in a citation style. number resolves the key
+// of a bibliography entry to the entry's number and reports whether the
+// entry exists.
+func (g *CiteGroup) Format(style *CitationStyle, number func(key string) (int, bool)) (string, error) {
+	items := make([]CitationItem, 0, len(g.Children))
+	for _, cite := range g.Children {
+		n, ok := number(cite.Key)
+		if !ok {
+			return "", foundations.NewSourceError(cite.Span, fmt.Sprintf("key `%s` does not exist in the bibliography", cite.Key))
+		}
+		item := CitationItem{Number: n}
+		if cite.Supplement != nil {
+			item.Supplement = cite.Supplement.ToText()
+		}
+		items = append(items, item)
+	}
+	return style.FormatCluster(items), nil
+}
+
+// CitationItem is a citation of a cluster, resolved to the number of the
+// cited entry.
+type CitationItem struct {
+	// Number is the number of the cited bibliography entry.
+	Number int
+	// Supplement is shown after the number, like a page number.
+	Supplement string
+}
+
+// CitationStyle describes how a numeric citation style shows a cluster of
+// citations. Which separators it uses is what tells styles apart: IEEE
+// shows "[1]–[3], [5]" where Vancouver shows "(1–3,5)".
+// Corresponds to the citation layout of a CSL style.
+type CitationStyle struct {
+	// Name is the style's name, as accepted by the style field of cite.
+	Name string
+	// Prefix and Suffix enclose the whole cluster.
+	Prefix, Suffix string
+	// ItemPrefix and ItemSuffix enclose each citation or range.
+	ItemPrefix, ItemSuffix string
+	// Delimiter separates the citations of a cluster.
+	Delimiter string
+	// RangeDelimiter joins the first and the last number of a collapsed
+	// range.
+	RangeDelimiter string
+	// SupplementDelimiter separates a number from its supplement.
+	SupplementDelimiter string
+}
+
+// citationStyles are the built-in citation styles.
+var citationStyles = []*CitationStyle{
+	{Name: "ieee", ItemPrefix: "[", ItemSuffix: "]", Delimiter: ", ", RangeDelimiter: "–", SupplementDelimiter: ", "},
+	{Name: "vancouver", Prefix: "(", Suffix: ")", Delimiter: ",", RangeDelimiter: "–", SupplementDelimiter: ", "},
+	{Name: "american-physics-society", Prefix: "[", Suffix: "]", Delimiter: ", ", RangeDelimiter: "–", SupplementDelimiter: ", "},
+}
+
+// DefaultCitationStyle is the style used when neither the citation nor
+// the bibliography sets one.
+var DefaultCitationStyle = citationStyles[0]
+
+// CitationStyleByName returns the built-in citation style with a name.
+func CitationStyleByName(name string) (*CitationStyle, bool) {
+	for _, style := range citationStyles {
+		if style.Name == name {
+			return style, true
+		}
+	}
+	return nil, false
+}
+
+// FormatCluster formats the citations of a cluster. They are sorted by
+// number and repeated citations are dropped. Runs of at least three
+// consecutive numbers collapse into a range, unless a citation in the run
+// has a supplement.
+// Matches CSL: collapse="citation-number"
+func (s *CitationStyle) FormatCluster(items []CitationItem) string {
+	sorted := make([]CitationItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		item := sorted[i]
+		if item.Supplement != "" {
+			parts = append(parts, s.ItemPrefix+strconv.Itoa(item.Number)+s.SupplementDelimiter+item.Supplement+s.ItemSuffix)
+			i++
+			continue
+		}
+
+		// Extend the run over consecutive and repeated numbers.
+		j, last := i+1, item.Number
+		for j < len(sorted) && sorted[j].Supplement == "" && sorted[j].Number <= last+1 {
+			last = sorted[j].Number
+			j++
+		}
+		if last-item.Number >= 2 {
+			parts = append(parts, s.number(item.Number)+s.RangeDelimiter+s.number(last))
+		} else {
+			for n := item.Number; n <= last; n++ {
+				parts = append(parts, s.number(n))
+			}
+		}
+		i = j
+	}
+	return s.Prefix + strings.Join(parts, s.Delimiter) + s.Suffix
+}
+
+// number formats a single number of a cluster.
+func (s *CitationStyle) number(n int) string {
+	return s.ItemPrefix + strconv.Itoa(n) + s.ItemSuffix
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+func citationItems(numbers ...int) []CitationItem {
+	items := make([]CitationItem, len(numbers))
+	for i, n := range numbers {
+		items[i] = CitationItem{Number: n}
+	}
+	return items
+}
+
+func TestFormatCluster(t *testing.T) {
+	ieee, _ := CitationStyleByName("ieee")
+	vancouver, _ := CitationStyleByName("vancouver")
+	aps, _ := CitationStyleByName("american-physics-society")
+
+	tests := []struct {
+		style *CitationStyle
+		items []CitationItem
+		want  string
+	}{
+		{ieee, citationItems(1), "[1]"},
+		{ieee, citationItems(1, 2), "[1], [2]"},
+		{ieee, citationItems(3, 1, 2), "[1]–[3]"},
+		{ieee, citationItems(1, 2, 3, 5), "[1]–[3], [5]"},
+		{ieee, citationItems(2, 2, 1), "[1], [2]"},
+		{vancouver, citationItems(5, 1, 2, 3), "(1–3,5)"},
+		{aps, citationItems(1, 2, 3), "[1–3]"},
+		{ieee, []CitationItem{{Number: 1}, {Number: 2, Supplement: "p. 7"}, {Number: 3}}, "[1], [2, p. 7], [3]"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.FormatCluster(tt.items); got != tt.want {
+			t.Errorf("%s.FormatCluster(%v) = %q, want %q", tt.style.Name, tt.items, got, tt.want)
+		}
+	}
+}
+
+func TestCiteGroupFormat(t *testing.T) {
+	numbers := map[string]int{"knuth": 1, "lamport": 2, "dijkstra": 3}
+	number := func(key string) (int, bool) {
+		n, ok := numbers[key]
+		return n, ok
+	}
+
+	page := markup(&foundations.SymbolElem{Text: "p."}, &foundations.SpaceElem{}, &foundations.SymbolElem{Text: "7"})
+	group := &CiteGroup{Children: []*CiteElem{
+		{Key: "dijkstra"},
+		{Key: "knuth"},
+		{Key: "lamport", Supplement: &page},
+	}}
+	got, err := group.Format(DefaultCitationStyle, number)
+	if err != nil || got != "[1], [2, p. 7], [3]" {
+		t.Errorf("Format() = %q, %v", got, err)
+	}
+
+	group = &CiteGroup{Children: []*CiteElem{{Key: "turing"}}}
+	if _, err := group.Format(DefaultCitationStyle, number); err == nil {
+		t.Error("expected an error for a key missing from the bibliography")
+	}
+}
@@ -0,0 +1,86 @@
+// Document element for Typst.
+// Translated from typst-library/src/model/document.rs
+
+package model
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// DocumentElem holds the metadata of the document, like its title and
+// authors. It can only be used in set rules, and only outside of
+// containers. Exporters write it into the output, for example into the
+// PDF document information and XMP metadata or the HTML head.
+//
+// Corresponds to Rust's DocumentElem in model/document.rs.
+type DocumentElem struct {
+	// Title is the document's title. Default: none.
+	Title *foundations.Content `typst:"title,type=content"`
+
+	// Author is the document's author, a string or an array of strings.
+	// Default: empty.
+	Author foundations.Value `typst:"author"`
+
+	// Description is the document's description. Default: none.
+	Description *foundations.Content `typst:"description,type=content"`
+
+	// Keywords are the document's keywords, a string or an array of
+	// strings. Default: empty.
+	Keywords foundations.Value `typst:"keywords"`
+
+	// Date is the document's creation date: a datetime, none to omit it,
+	// or auto to use the current date.
+	Date foundations.Value `typst:"date,default=auto"`
+}
+
+func (*DocumentElem) IsContentElement() {}
+
+// DocumentDef is the registered element definition for document.
+var DocumentDef *foundations.ElementDef
+
+func init() {
+	DocumentDef = foundations.RegisterElement[DocumentElem]("document", nil)
+}
+
+// DocumentFunc creates the document element function.
+func DocumentFunc() *foundations.Func {
+	name := "document"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: documentNative,
+			Info: DocumentDef.ToFuncInfo(),
+		},
+	}
+}
+
+// documentNative implements the document() function. The document element
+// cannot be constructed; its fields are set with set rules.
+func documentNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	return nil, &foundations.ConstructorError{
+		Message: "can only be used in set rules",
+		Span:    args.Span,
+	}
+}
+
+// DocumentStrings returns the strings of an author or keywords value,
+// which is a single string or an array of strings. Other values yield no
+// strings.
+func DocumentStrings(v foundations.Value) []string {
+	if s, ok := foundations.AsStr(v); ok {
+		return []string{s}
+	}
+	arr, ok := v.(*foundations.Array)
+	if !ok {
+		return nil
+	}
+	var result []string
+	for _, item := range arr.Items() {
+		if s, ok := foundations.AsStr(item); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestDocumentFuncOnlyInSetRules(t *testing.T) {
+	args := foundations.NewArgs(syntax.Detached())
+	if _, err := DocumentFunc().Call(&foundations.Engine{}, foundations.NewContext(), args); err == nil {
+		t.Error("expected constructing a document to fail")
+	}
+}
+
+func TestDocumentStrings(t *testing.T) {
+	tests := []struct {
+		value foundations.Value
+		want  []string
+	}{
+		{foundations.Str("Ada"), []string{"Ada"}},
+		{foundations.NewArray(foundations.Str("Ada"), foundations.Str("Grace")), []string{"Ada", "Grace"}},
+		{foundations.None, nil},
+	}
+	for _, tt := range tests {
+		if got := DocumentStrings(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DocumentStrings(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestContentPlainText(t *testing.T) {
+	content := foundations.Content{Elements: []foundations.ContentElement{
+		&foundations.SymbolElem{Text: "A"},
+		&StrongElem{Body: foundations.Content{Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: "B"}}}},
+		&LinebreakElem{},
+		&EmphElem{Body: foundations.Content{Elements: []foundations.ContentElement{&foundations.SymbolElem{Text: "C"}}}},
+		&ParElem{},
+	}}
+	if got := content.PlainText(); got != "AB\nC" {
+		t.Errorf("PlainText() = %q, want %q", got, "AB\nC")
+	}
+}
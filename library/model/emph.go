@@ -3,7 +3,11 @@
 
 package model
 
-import "github.com/boergens/gotypst/library/foundations"
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
 
 // EmphElem emphasizes content by toggling italics.
 //
@@ -15,6 +19,10 @@ type EmphElem struct {
 	// Body is the content to emphasize.
 	// Required field.
 	Body foundations.Content
+	foundations.ElemMeta
 }
 
 func (*EmphElem) IsContentElement() {}
+
+// PlainText writes the plain text of the body.
+func (e *EmphElem) PlainText(b *strings.Builder) { b.WriteString(e.Body.PlainText()) }
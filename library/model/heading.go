@@ -0,0 +1,110 @@
+// Heading element for Typst.
+// Translated from typst-library/src/model/heading.rs
+
+package model
+
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// HeadingElem is a section heading.
+//
+// Headings are numbered with their numbering and listed in outlines unless
+// outlined is false.
+// Corresponds to Rust's HeadingElem in model/heading.rs.
+type HeadingElem struct {
+	// Level is the heading's nesting level, starting at 1. Default: 1.
+	Level *int64 `typst:"level,type=int"`
+
+	// Numbering is how to number the heading: a pattern string, a
+	// function, or none. Default: none.
+	Numbering foundations.Value `typst:"numbering"`
+
+	// Outlined is whether the heading appears in outlines. Default: true.
+	Outlined *bool `typst:"outlined,type=bool"`
+
+	// Body is the heading's title.
+	// Required field.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+
+	foundations.ElemMeta
+}
+
+func (*HeadingElem) IsContentElement() {}
+
+// PlainText writes the plain text of the body.
+func (e *HeadingElem) PlainText(b *strings.Builder) { b.WriteString(e.Body.PlainText()) }
+
+// HeadingDef is the registered element definition for heading.
+var HeadingDef *foundations.ElementDef
+
+func init() {
+	HeadingDef = foundations.RegisterElement[HeadingElem]("heading", nil)
+}
+
+// HeadingFunc creates the heading element function.
+func HeadingFunc() *foundations.Func {
+	name := "heading"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: headingNative,
+			Info: HeadingDef.ToFuncInfo(),
+		},
+	}
+}
+
+// headingNative implements the heading() function.
+func headingNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[HeadingElem](HeadingDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Level != nil && *elem.Level < 1 {
+		return nil, foundations.NewSourceError(args.Span, "level must be at least 1")
+	}
+	if elem.Numbering != nil {
+		if _, err := NumberingFromValue(elem.Numbering); err != nil {
+			return nil, err
+		}
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// ResolvedLevel returns the heading's level, defaulting to 1.
+func (e *HeadingElem) ResolvedLevel() int {
+	if e.Level == nil {
+		return 1
+	}
+	return int(*e.Level)
+}
+
+// RefSupplement implements Refable.
+func (e *HeadingElem) RefSupplement() foundations.Value { return foundations.Str("Section") }
+
+// RefCounter implements Refable.
+func (e *HeadingElem) RefCounter() string { return "heading" }
+
+// RefNumbering implements Refable.
+func (e *HeadingElem) RefNumbering() *Numbering {
+	if e.Numbering == nil {
+		return nil
+	}
+	numbering, _ := NumberingFromValue(e.Numbering)
+	return numbering
+}
+
+// IsOutlined implements Outlinable.
+func (e *HeadingElem) IsOutlined() bool { return e.Outlined == nil || *e.Outlined }
+
+// OutlineLevel implements Outlinable.
+func (e *HeadingElem) OutlineLevel() int { return e.ResolvedLevel() }
+
+// OutlineBody implements Outlinable.
+func (e *HeadingElem) OutlineBody() foundations.Content { return e.Body }
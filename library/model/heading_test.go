@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// markup builds content from elements.
+func markup(elems ...foundations.ContentElement) foundations.Content {
+	return foundations.Content{Elements: elems}
+}
+
+func TestHeadingToText(t *testing.T) {
+	word := func(s string) foundations.ContentElement { return &foundations.SymbolElem{Text: s} }
+	body := markup(
+		&foundations.SpaceElem{},
+		&foundations.SmartQuoteElem{Double: true},
+		&StrongElem{Body: markup(word("Getting"))},
+		&foundations.SpaceElem{}, &foundations.SpaceElem{},
+		word("started"),
+		&foundations.SmartQuoteElem{Double: true},
+		&LinebreakElem{},
+		&foundations.ParbreakElem{},
+		&EmphElem{Body: markup(word("now"), &foundations.SmartQuoteElem{})},
+		&foundations.SpaceElem{},
+	)
+	heading := markup(&HeadingElem{Body: body})
+
+	if got, want := heading.ToText(), `"Getting started" now'`; got != want {
+		t.Errorf("ToText() = %q, want %q", got, want)
+	}
+	if got, want := heading.PlainText(), " \"Getting  started\"\n\n\nnow' "; got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+	if got := markup(&foundations.SpaceElem{}, &LinebreakElem{}).ToText(); got != "" {
+		t.Errorf("ToText() of whitespace = %q, want empty", got)
+	}
+}
+
+func TestHeadingRepr(t *testing.T) {
+	level := int64(2)
+	heading := &HeadingElem{Level: &level, Body: markup(&foundations.SymbolElem{Text: "A"}, &foundations.SpaceElem{})}
+	got := foundations.Repr(foundations.ContentValue{Content: markup(heading)})
+	want := `heading(level: 2, body: sequence(symbol(text: "A"), [ ]))`
+	if got != want {
+		t.Errorf("Repr() = %s, want %s", got, want)
+	}
+	if got := foundations.Repr(foundations.ContentValue{}); got != "[]" {
+		t.Errorf("Repr() of empty content = %s, want []", got)
+	}
+}
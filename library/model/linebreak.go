@@ -0,0 +1,58 @@
+// Line break element for Typst.
+// Translated from typst-library/src/text/linebreak.rs
+
+package model
+
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// LinebreakElem inserts a forced line break.
+//
+// In a justified paragraph, the line before the break is ragged unless
+// Justify is set, in which case it is justified like any other line.
+// Corresponds to Rust's LinebreakElem in text/linebreak.rs. It lives in
+// this package because the text package depends on the evaluator.
+type LinebreakElem struct {
+	// Justify is whether to justify the line before the break.
+	Justify bool `typst:"justify,type=bool,default=false"`
+}
+
+func (*LinebreakElem) IsContentElement() {}
+
+// PlainText writes a newline.
+func (*LinebreakElem) PlainText(b *strings.Builder) { b.WriteByte('\n') }
+
+// LinebreakDef is the registered element definition for linebreak.
+var LinebreakDef *foundations.ElementDef
+
+func init() {
+	LinebreakDef = foundations.RegisterElement[LinebreakElem]("linebreak", nil)
+}
+
+// LinebreakFunc creates the linebreak element function.
+func LinebreakFunc() *foundations.Func {
+	name := "linebreak"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: linebreakNative,
+			Info: LinebreakDef.ToFuncInfo(),
+		},
+	}
+}
+
+// linebreakNative implements the linebreak() function.
+func linebreakNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[LinebreakElem](LinebreakDef, args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
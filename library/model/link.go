@@ -0,0 +1,180 @@
+// Link element for Typst.
+// Translated from typst-library/src/model/link.rs
+
+package model
+
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// LinkElem links to a URL or a location in the document.
+//
+// Corresponds to Rust's LinkElem in model/link.rs.
+type LinkElem struct {
+	// Dest is the destination the link points to: a URL string, a label
+	// of an element in the document, or a dictionary with the keys page,
+	// x, and y for a position on a page.
+	Dest foundations.Value `typst:"dest,positional,required"`
+
+	// Body is the content that should become a link. If omitted for a
+	// URL, the URL is shown without a mailto: or tel: prefix.
+	Body *foundations.Content `typst:"body,positional,type=content"`
+
+	foundations.ElemMeta
+}
+
+func (*LinkElem) IsContentElement() {}
+
+// PlainText writes the plain text of the body, or the URL if there is none.
+func (l *LinkElem) PlainText(b *strings.Builder) {
+	if l.Body != nil {
+		b.WriteString(l.Body.PlainText())
+		return
+	}
+	b.WriteString(l.DisplayURL())
+}
+
+// LinkDef is the registered element definition for link.
+var LinkDef *foundations.ElementDef
+
+func init() {
+	LinkDef = foundations.RegisterElement[LinkElem]("link", nil)
+}
+
+// LinkTarget is the destination of a link after validation. Exactly one
+// of URL, Label, and Position is set.
+//
+// Corresponds to Rust's LinkTarget and Destination in model/link.rs.
+type LinkTarget struct {
+	// URL is the web address of an external link.
+	URL string
+	// Label is the label of the element an internal link points to.
+	Label string
+	// Position is the position an internal link points to.
+	Position *LinkPosition
+}
+
+// LinkPosition is a position on a page of the document.
+type LinkPosition struct {
+	// Page is the 1-based physical page number.
+	Page int
+	// X and Y are the position's offsets from the page's top-left corner.
+	X, Y foundations.Length
+}
+
+// Target returns the validated destination of the link.
+func (l *LinkElem) Target() (LinkTarget, error) {
+	return ParseLinkTarget(l.Dest, syntax.Detached())
+}
+
+// DisplayURL returns the text shown for a URL link without a body.
+func (l *LinkElem) DisplayURL() string {
+	s, _ := foundations.AsStr(l.Dest)
+	for _, prefix := range []string{"mailto:", "tel:"} {
+		if strings.HasPrefix(s, prefix) {
+			return s[len(prefix):]
+		}
+	}
+	return s
+}
+
+// ParseLinkTarget validates a link destination. It accepts a non-empty
+// URL string, a label, or a dictionary with exactly the keys page, x,
+// and y, where page is a positive integer and x and y are lengths.
+func ParseLinkTarget(v foundations.Value, span syntax.Span) (LinkTarget, error) {
+	switch dest := v.(type) {
+	case foundations.LabelValue:
+		return LinkTarget{Label: string(dest)}, nil
+	case *foundations.Dict:
+		return parseLinkPosition(dest, span)
+	}
+	s, ok := foundations.AsStr(v)
+	if !ok {
+		got := "none"
+		if v != nil {
+			got = v.Type().String()
+		}
+		return LinkTarget{}, &foundations.TypeMismatchError{Expected: "string, label, or dictionary", Got: got, Field: "dest", Span: span}
+	}
+	if s == "" {
+		return LinkTarget{}, &foundations.ConstructorError{Message: "URL must not be empty", Span: span}
+	}
+	return LinkTarget{URL: s}, nil
+}
+
+// parseLinkPosition parses a position dictionary of a link destination.
+func parseLinkPosition(dict *foundations.Dict, span syntax.Span) (LinkTarget, error) {
+	for _, key := range dict.Keys() {
+		if key != "page" && key != "x" && key != "y" {
+			return LinkTarget{}, &foundations.ConstructorError{Message: "unexpected key \"" + key + "\", valid keys are \"page\", \"x\", and \"y\"", Span: span}
+		}
+	}
+
+	var pos LinkPosition
+	page, ok := dict.Get("page")
+	if !ok {
+		return LinkTarget{}, &foundations.ConstructorError{Message: "dictionary does not contain key \"page\"", Span: span}
+	}
+	n, ok := foundations.AsInt(page)
+	if !ok {
+		return LinkTarget{}, &foundations.TypeMismatchError{Expected: "integer", Got: page.Type().String(), Field: "page", Span: span}
+	}
+	if n < 1 {
+		return LinkTarget{}, &foundations.ConstructorError{Message: "page number must be at least 1", Span: span}
+	}
+	pos.Page = int(n)
+
+	for _, coord := range []struct {
+		key string
+		out *foundations.Length
+	}{{"x", &pos.X}, {"y", &pos.Y}} {
+		v, ok := dict.Get(coord.key)
+		if !ok {
+			return LinkTarget{}, &foundations.ConstructorError{Message: "dictionary does not contain key \"" + coord.key + "\"", Span: span}
+		}
+		length, ok := v.(foundations.LengthValue)
+		if !ok {
+			return LinkTarget{}, &foundations.TypeMismatchError{Expected: "length", Got: v.Type().String(), Field: coord.key, Span: span}
+		}
+		*coord.out = length.Length
+	}
+	return LinkTarget{Position: &pos}, nil
+}
+
+// LinkFunc creates the link element function.
+func LinkFunc() *foundations.Func {
+	name := "link"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: linkNative,
+			Info: LinkDef.ToFuncInfo(),
+		},
+	}
+}
+
+// linkNative implements the link() function. Only links to URLs may omit
+// the body.
+func linkNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[LinkElem](LinkDef, args)
+	if err != nil {
+		return nil, err
+	}
+	target, err := ParseLinkTarget(elem.Dest, args.Span)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Body == nil && target.URL == "" {
+		return nil, &foundations.ConstructorError{
+			Message: "missing argument: body",
+			Span:    args.Span,
+		}
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func linkPosition(entries map[string]foundations.Value) *foundations.Dict {
+	dict := foundations.NewDict()
+	for key, value := range entries {
+		dict.Set(key, value)
+	}
+	return dict
+}
+
+func TestParseLinkTarget(t *testing.T) {
+	target, err := ParseLinkTarget(foundations.LabelValue("intro"), syntax.Detached())
+	if err != nil || target.Label != "intro" {
+		t.Errorf("label target = %+v, %v", target, err)
+	}
+
+	target, err = ParseLinkTarget(foundations.Str("https://typst.app"), syntax.Detached())
+	if err != nil || target.URL != "https://typst.app" {
+		t.Errorf("URL target = %+v, %v", target, err)
+	}
+
+	target, err = ParseLinkTarget(linkPosition(map[string]foundations.Value{
+		"page": foundations.Int(2),
+		"x":    foundations.LengthValue{Length: foundations.Length{Points: 10}},
+		"y":    foundations.LengthValue{Length: foundations.Length{Points: 20}},
+	}), syntax.Detached())
+	if err != nil {
+		t.Fatalf("position target: %v", err)
+	}
+	if pos := target.Position; pos == nil || pos.Page != 2 || pos.X.Points != 10 || pos.Y.Points != 20 {
+		t.Errorf("position target = %+v", pos)
+	}
+}
+
+func TestParseLinkTargetErrors(t *testing.T) {
+	length := foundations.LengthValue{Length: foundations.Length{Points: 10}}
+	tests := []struct {
+		name string
+		dest foundations.Value
+	}{
+		{"empty URL", foundations.Str("")},
+		{"integer", foundations.Int(1)},
+		{"missing page", linkPosition(map[string]foundations.Value{"x": length, "y": length})},
+		{"page zero", linkPosition(map[string]foundations.Value{"page": foundations.Int(0), "x": length, "y": length})},
+		{"unknown key", linkPosition(map[string]foundations.Value{"page": foundations.Int(1), "x": length, "y": length, "z": length})},
+		{"non-length coordinate", linkPosition(map[string]foundations.Value{"page": foundations.Int(1), "x": foundations.Int(1), "y": length})},
+	}
+	for _, tt := range tests {
+		if _, err := ParseLinkTarget(tt.dest, syntax.Detached()); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestLinkFuncRequiresBodyForInternalLinks(t *testing.T) {
+	args := foundations.NewArgs(syntax.Detached(), foundations.LabelValue("intro"))
+	if _, err := LinkFunc().Call(&foundations.Engine{}, foundations.NewContext(), args); err == nil {
+		t.Error("expected a link to a label without a body to fail")
+	}
+
+	args = foundations.NewArgs(syntax.Detached(), foundations.Str("mailto:ada@example.com"))
+	value, err := LinkFunc().Call(&foundations.Engine{}, foundations.NewContext(), args)
+	if err != nil {
+		t.Fatalf("link to URL: %v", err)
+	}
+	if got := value.(foundations.ContentValue).Content.PlainText(); got != "ada@example.com" {
+		t.Errorf("PlainText() = %q, want %q", got, "ada@example.com")
+	}
+}
@@ -0,0 +1,446 @@
+// Numbering patterns for Typst.
+// Translated from typst-library/src/model/numbering.rs
+
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Numbering defines how to turn a sequence of numbers into content.
+//
+// It is either a pattern string like "1.a)" or a function that receives the
+// numbers as positional arguments.
+// Corresponds to Rust's Numbering enum in model/numbering.rs.
+type Numbering struct {
+	// Pattern is set for string numberings.
+	Pattern *NumberingPattern
+	// Func is set for function numberings.
+	Func *foundations.Func
+}
+
+// NumberingFromValue casts a value to a numbering.
+// Returns nil (and no error) for none.
+func NumberingFromValue(v foundations.Value) (*Numbering, error) {
+	switch v := v.(type) {
+	case foundations.NoneValue:
+		return nil, nil
+	case foundations.Str:
+		pattern, err := ParseNumberingPattern(string(v))
+		if err != nil {
+			return nil, err
+		}
+		return &Numbering{Pattern: pattern}, nil
+	case foundations.FuncValue:
+		return &Numbering{Func: v.Func}, nil
+	default:
+		return nil, &foundations.TypeMismatchError{
+			Expected: "string, function, or none",
+			Got:      v.Type().String(),
+		}
+	}
+}
+
+// NumberingFunc creates the numbering function, which applies a numbering
+// to a sequence of numbers, as in `numbering("1.a", 2, 3)`.
+//
+// Matches Rust: pub fn numbering in model/numbering.rs
+func NumberingFunc() *foundations.Func {
+	name := "numbering"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: numberingNative,
+			Info: &foundations.FuncInfo{
+				Name: name,
+				Params: []foundations.ParamInfo{
+					{Name: "numbering", Type: foundations.TypeDyn},
+					{Name: "numbers", Type: foundations.TypeInt, Variadic: true},
+				},
+			},
+		},
+	}
+}
+
+func numberingNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	arg, err := args.Expect("numbering")
+	if err != nil {
+		return nil, err
+	}
+	numbering, err := NumberingFromValue(arg.V)
+	if err != nil {
+		if mismatch, ok := err.(*foundations.TypeMismatchError); ok {
+			mismatch.Field, mismatch.Span = "numbering", arg.Span
+		}
+		return nil, err
+	}
+	if numbering == nil {
+		return nil, &foundations.TypeMismatchError{Expected: "string or function", Got: "none", Field: "numbering", Span: arg.Span}
+	}
+
+	var numbers []int
+	for _, num := range args.All() {
+		n, ok := num.V.(foundations.Int)
+		if !ok {
+			return nil, &foundations.TypeMismatchError{Expected: "integer", Got: num.V.Type().String(), Field: "numbers", Span: num.Span}
+		}
+		if n < 0 {
+			return nil, foundations.NewSourceError(num.Span, "number must be at least zero")
+		}
+		numbers = append(numbers, int(n))
+	}
+	if err := args.Finish(); err != nil {
+		return nil, err
+	}
+	return numbering.Apply(&engine, &context, numbers)
+}
+
+// Apply applies the numbering to the given numbers.
+// Matches Rust: pub fn apply(&self, engine, context, numbers: &[u64]) -> SourceResult<Value>
+func (n *Numbering) Apply(engine *foundations.Engine, context *foundations.Context, numbers []int) (foundations.Value, error) {
+	if n.Pattern != nil {
+		return foundations.Str(n.Pattern.Apply(numbers)), nil
+	}
+	args := foundations.NewArgs(n.Func.Span)
+	for _, num := range numbers {
+		args.Push(n.Func.Span, foundations.Int(num))
+	}
+	return n.Func.Call(engine, context, args)
+}
+
+// Trimmed returns the numbering with prefix and suffix trimmed.
+// Function numberings are returned unchanged.
+// Matches Rust: pub fn trimmed(mut self) -> Self
+func (n *Numbering) Trimmed() *Numbering {
+	if n.Pattern == nil {
+		return n
+	}
+	pattern := *n.Pattern
+	pattern.Trimmed = true
+	return &Numbering{Pattern: &pattern}
+}
+
+// NumberingPattern is a parsed numbering pattern like "1.a)".
+//
+// Each piece consists of a prefix and a counting symbol. The suffix after
+// the last counting symbol is stored separately.
+// Corresponds to Rust's NumberingPattern struct.
+type NumberingPattern struct {
+	// Pieces are the (prefix, kind) pairs of the pattern.
+	Pieces []NumberingPiece
+	// Suffix is the text after the last counting symbol.
+	Suffix string
+	// Trimmed indicates whether the prefix and suffix should be omitted.
+	Trimmed bool
+}
+
+// NumberingPiece is a counting symbol together with the text before it.
+type NumberingPiece struct {
+	Prefix string
+	Kind   NumberingKind
+}
+
+// ParseNumberingPattern parses a numbering pattern string.
+// Matches Rust: impl FromStr for NumberingPattern
+func ParseNumberingPattern(pattern string) (*NumberingPattern, error) {
+	var pieces []NumberingPiece
+	handled := 0
+	for i, c := range pattern {
+		kind, ok := NumberingKindFromChar(c)
+		if !ok {
+			continue
+		}
+		pieces = append(pieces, NumberingPiece{Prefix: pattern[handled:i], Kind: kind})
+		handled = i + len(string(c))
+	}
+
+	if len(pieces) == 0 {
+		return nil, &foundations.ConstructorError{Message: "invalid numbering pattern"}
+	}
+
+	return &NumberingPattern{Pieces: pieces, Suffix: pattern[handled:]}, nil
+}
+
+// Apply formats the numbers according to the pattern.
+//
+// If there are more numbers than counting symbols, the last symbol (and its
+// prefix) is repeated for the remaining numbers.
+// Matches Rust: pub fn apply(&self, numbers: &[u64]) -> EcoString
+func (p *NumberingPattern) Apply(numbers []int) string {
+	var b strings.Builder
+
+	i := 0
+	for ; i < len(p.Pieces) && i < len(numbers); i++ {
+		piece := p.Pieces[i]
+		if i > 0 || !p.Trimmed {
+			b.WriteString(piece.Prefix)
+		}
+		b.WriteString(piece.Kind.Apply(numbers[i]))
+	}
+
+	last := p.Pieces[len(p.Pieces)-1]
+	for ; i < len(numbers); i++ {
+		if last.Prefix == "" {
+			b.WriteString(p.Suffix)
+		} else {
+			b.WriteString(last.Prefix)
+		}
+		b.WriteString(last.Kind.Apply(numbers[i]))
+	}
+
+	if !p.Trimmed {
+		b.WriteString(p.Suffix)
+	}
+
+	return b.String()
+}
+
+// ApplyKth formats only the k-th number with the k-th counting symbol,
+// including its prefix and the pattern's suffix.
+// Matches Rust: pub fn apply_kth(&self, k: usize, number: u64) -> EcoString
+func (p *NumberingPattern) ApplyKth(k int, number int) string {
+	var b strings.Builder
+	if first := p.Pieces[0]; first.Prefix != "" {
+		b.WriteString(first.Prefix)
+	}
+	idx := k
+	if idx >= len(p.Pieces) {
+		idx = len(p.Pieces) - 1
+	}
+	b.WriteString(p.Pieces[idx].Kind.Apply(number))
+	b.WriteString(p.Suffix)
+	return b.String()
+}
+
+// Len returns the number of counting symbols in the pattern.
+// Matches Rust: pub fn pieces(&self) -> usize
+func (p *NumberingPattern) Len() int {
+	return len(p.Pieces)
+}
+
+// String returns the pattern in its source form.
+func (p *NumberingPattern) String() string {
+	var b strings.Builder
+	for _, piece := range p.Pieces {
+		b.WriteString(piece.Prefix)
+		b.WriteRune(piece.Kind.Char())
+	}
+	b.WriteString(p.Suffix)
+	return b.String()
+}
+
+// NumberingKind is a kind of counting symbol.
+// Corresponds to Rust's NumberingKind enum.
+type NumberingKind int
+
+const (
+	// NumberingArabic is arabic numerals (1, 2, 3, ...).
+	NumberingArabic NumberingKind = iota
+	// NumberingLowerLatin is lowercase latin letters (a, b, c, ..., y, z, aa, ab, ...).
+	NumberingLowerLatin
+	// NumberingUpperLatin is uppercase latin letters (A, B, C, ..., Y, Z, AA, AB, ...).
+	NumberingUpperLatin
+	// NumberingLowerRoman is lowercase roman numerals (i, ii, iii, ...).
+	NumberingLowerRoman
+	// NumberingUpperRoman is uppercase roman numerals (I, II, III, ...).
+	NumberingUpperRoman
+	// NumberingLowerGreek is lowercase greek letters (α, β, γ, ...).
+	NumberingLowerGreek
+	// NumberingUpperGreek is uppercase greek letters (Α, Β, Γ, ...).
+	NumberingUpperGreek
+	// NumberingSymbol is paragraph/note-like symbols: *, †, ‡, §, ¶, and ‖.
+	NumberingSymbol
+	// NumberingCircledNumber is circled numbers (①, ②, ③, ...) up to 50.
+	NumberingCircledNumber
+)
+
+// NumberingKindFromChar creates a numbering kind from a counting symbol.
+// Matches Rust: pub fn from_char(c: char) -> Option<Self>
+func NumberingKindFromChar(c rune) (NumberingKind, bool) {
+	switch c {
+	case '1':
+		return NumberingArabic, true
+	case 'a':
+		return NumberingLowerLatin, true
+	case 'A':
+		return NumberingUpperLatin, true
+	case 'i':
+		return NumberingLowerRoman, true
+	case 'I':
+		return NumberingUpperRoman, true
+	case 'α':
+		return NumberingLowerGreek, true
+	case 'Α':
+		return NumberingUpperGreek, true
+	case '*':
+		return NumberingSymbol, true
+	case '①':
+		return NumberingCircledNumber, true
+	default:
+		return 0, false
+	}
+}
+
+// Char returns the counting symbol for this kind.
+// Matches Rust: pub fn to_char(self) -> char
+func (k NumberingKind) Char() rune {
+	switch k {
+	case NumberingLowerLatin:
+		return 'a'
+	case NumberingUpperLatin:
+		return 'A'
+	case NumberingLowerRoman:
+		return 'i'
+	case NumberingUpperRoman:
+		return 'I'
+	case NumberingLowerGreek:
+		return 'α'
+	case NumberingUpperGreek:
+		return 'Α'
+	case NumberingSymbol:
+		return '*'
+	case NumberingCircledNumber:
+		return '①'
+	default:
+		return '1'
+	}
+}
+
+// Apply formats a single number with this kind.
+// Matches Rust: pub fn apply(self, n: u64) -> EcoString
+func (k NumberingKind) Apply(n int) string {
+	switch k {
+	case NumberingLowerLatin:
+		return zeroless(n, "abcdefghijklmnopqrstuvwxyz")
+	case NumberingUpperLatin:
+		return zeroless(n, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	case NumberingLowerRoman:
+		return roman(n, false)
+	case NumberingUpperRoman:
+		return roman(n, true)
+	case NumberingLowerGreek:
+		return zeroless(n, "αβγδεζηθικλμνξοπρστυφχψω")
+	case NumberingUpperGreek:
+		return zeroless(n, "ΑΒΓΔΕΖΗΘΙΚΛΜΝΞΟΠΡΣΤΥΦΧΨΩ")
+	case NumberingSymbol:
+		if n <= 0 {
+			return "-"
+		}
+		const symbols = "*†‡§¶‖"
+		runes := []rune(symbols)
+		if n > maxSymbolRepeats*len(runes) {
+			return strconv.Itoa(n)
+		}
+		symbol := string(runes[(n-1)%len(runes)])
+		return strings.Repeat(symbol, (n-1)/len(runes)+1)
+	case NumberingCircledNumber:
+		return circled(n)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// zeroless formats a number in a bijective base using the given alphabet,
+// so that the sequence runs a, b, ..., z, aa, ab, ...
+func zeroless(n int, alphabet string) string {
+	if n <= 0 {
+		return "-"
+	}
+	letters := []rune(alphabet)
+	base := len(letters)
+	var out []rune
+	for n > 0 {
+		n--
+		out = append(out, letters[n%base])
+		n /= base
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// maxSymbolRepeats is how often a symbol is repeated at most. Larger
+// numbers fall back to arabic numerals.
+const maxSymbolRepeats = 10
+
+// maxRoman is the largest number written as a roman numeral, with at most
+// three overlined thousands. Larger numbers fall back to arabic numerals.
+const maxRoman = 3999999
+
+// romanNumerals lists roman numeral symbols from largest to smallest,
+// including the subtractive pairs.
+var romanNumerals = []struct {
+	name  string
+	value int
+}{
+	{"M̅", 1000000},
+	{"D̅", 500000},
+	{"C̅", 100000},
+	{"L̅", 50000},
+	{"X̅", 10000},
+	{"V̅", 5000},
+	{"I̅V̅", 4000},
+	{"M", 1000},
+	{"CM", 900},
+	{"D", 500},
+	{"CD", 400},
+	{"C", 100},
+	{"XC", 90},
+	{"L", 50},
+	{"XL", 40},
+	{"X", 10},
+	{"IX", 9},
+	{"V", 5},
+	{"IV", 4},
+	{"I", 1},
+}
+
+// roman formats a number as a roman numeral. Zero is written as "N".
+// Numbers above maxRoman fall back to arabic numerals.
+func roman(n int, upper bool) string {
+	if n == 0 {
+		if upper {
+			return "N"
+		}
+		return "n"
+	}
+	if n < 0 {
+		return "-"
+	}
+	if n > maxRoman {
+		return strconv.Itoa(n)
+	}
+	var b strings.Builder
+	for _, numeral := range romanNumerals {
+		for n >= numeral.value {
+			n -= numeral.value
+			if upper {
+				b.WriteString(numeral.name)
+			} else {
+				b.WriteString(strings.ToLower(numeral.name))
+			}
+		}
+	}
+	return b.String()
+}
+
+// circled formats a number as a circled number (①–㊿).
+// Numbers outside the supported range fall back to arabic numerals.
+func circled(n int) string {
+	switch {
+	case n >= 1 && n <= 20:
+		return string(rune('①' + n - 1))
+	case n >= 21 && n <= 35:
+		return string(rune('㉑' + n - 21))
+	case n >= 36 && n <= 50:
+		return string(rune('㊱' + n - 36))
+	default:
+		return strconv.Itoa(n)
+	}
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestParseNumberingPattern(t *testing.T) {
+	pattern, err := ParseNumberingPattern("(1.a)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pattern.Len() != 2 {
+		t.Fatalf("expected 2 pieces, got %d", pattern.Len())
+	}
+	if pattern.Pieces[0].Prefix != "(" || pattern.Pieces[0].Kind != NumberingArabic {
+		t.Errorf("unexpected first piece %+v", pattern.Pieces[0])
+	}
+	if pattern.Pieces[1].Prefix != "." || pattern.Pieces[1].Kind != NumberingLowerLatin {
+		t.Errorf("unexpected second piece %+v", pattern.Pieces[1])
+	}
+	if pattern.Suffix != ")" {
+		t.Errorf("expected suffix %q, got %q", ")", pattern.Suffix)
+	}
+	if got := pattern.String(); got != "(1.a)" {
+		t.Errorf("String() = %q, want %q", got, "(1.a)")
+	}
+
+	if _, err := ParseNumberingPattern("no counters"); err == nil {
+		t.Error("expected error for pattern without counting symbols")
+	}
+}
+
+func TestNumberingPatternApply(t *testing.T) {
+	tests := []struct {
+		pattern string
+		numbers []int
+		want    string
+	}{
+		{"(1)", []int{3}, "(3)"},
+		{"1.", []int{1, 2, 3}, "1.2.3."},
+		{"1.a", []int{2, 3}, "2.c"},
+		{"I", []int{14}, "XIV"},
+		{"I", []int{3999999}, "M̅M̅M̅D̅C̅C̅C̅C̅L̅X̅X̅X̅X̅V̅I̅V̅CMXCIX"},
+		{"I", []int{1e15}, "1000000000000000"},
+		{"i.", []int{4}, "iv."},
+		{"A", []int{27}, "AA"},
+		{"*", []int{2}, "†"},
+		{"*", []int{7}, "**"},
+		{"*", []int{1e15}, "1000000000000000"},
+		{"①", []int{21}, "㉑"},
+		{"1.a", []int{1}, "1"},
+	}
+
+	for _, tt := range tests {
+		pattern, err := ParseNumberingPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("ParseNumberingPattern(%q): %v", tt.pattern, err)
+		}
+		if got := pattern.Apply(tt.numbers); got != tt.want {
+			t.Errorf("%q.Apply(%v) = %q, want %q", tt.pattern, tt.numbers, got, tt.want)
+		}
+	}
+}
+
+func TestNumberingTrimmed(t *testing.T) {
+	numbering, err := NumberingFromValue(foundations.Str("(1)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := numbering.Trimmed().Pattern.Apply([]int{5}); got != "5" {
+		t.Errorf("trimmed Apply = %q, want %q", got, "5")
+	}
+	// The original numbering is unchanged.
+	if got := numbering.Pattern.Apply([]int{5}); got != "(5)" {
+		t.Errorf("Apply = %q, want %q", got, "(5)")
+	}
+}
+
+func TestNumberingFromValue(t *testing.T) {
+	numbering, err := NumberingFromValue(foundations.None)
+	if err != nil || numbering != nil {
+		t.Errorf("expected nil numbering for none, got %v (err %v)", numbering, err)
+	}
+
+	if _, err := NumberingFromValue(foundations.Int(1)); err == nil {
+		t.Error("expected error for integer numbering")
+	}
+}
+
+func TestNumberingFunc(t *testing.T) {
+	call := func(values ...foundations.Value) (foundations.Value, error) {
+		f := NumberingFunc()
+		return f.Call(&foundations.Engine{}, foundations.NewContext(), foundations.NewArgs(syntax.Detached(), values...))
+	}
+
+	got, err := call(foundations.Str("I.a)"), foundations.Int(4), foundations.Int(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != foundations.Str("IV.b)") {
+		t.Errorf("numbering = %v, want IV.b)", got)
+	}
+
+	if _, err := call(foundations.Str("1"), foundations.Int(-1)); err == nil || err.Error() != "number must be at least zero" {
+		t.Errorf("negative number error = %v", err)
+	}
+	if _, err := call(foundations.Int(1)); err == nil {
+		t.Error("expected an error for an integer numbering")
+	}
+}
@@ -0,0 +1,259 @@
+// Outline element for Typst.
+// Translated from typst-library/src/model/outline.rs
+
+package model
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// DefaultOutlineIndent is how far each level of an outline is indented
+// with `indent: auto`.
+var DefaultOutlineIndent = foundations.Length{Points: 12}
+
+// Outlinable is implemented by elements that can be listed in an outline,
+// such as headings.
+//
+// Corresponds to Rust's Outlinable trait in model/outline.rs.
+type Outlinable interface {
+	foundations.ContentElement
+
+	// IsOutlined reports whether the element is listed in outlines.
+	IsOutlined() bool
+
+	// OutlineLevel returns the element's nesting level in the outline,
+	// starting at 1.
+	OutlineLevel() int
+
+	// OutlineBody returns the text of the element's entry, without its
+	// number.
+	OutlineBody() foundations.Content
+}
+
+// OutlineElem is a table of contents, figures, or other elements.
+//
+// It lists the elements matching its target with their page numbers. Each
+// entry is an OutlineEntryElem, so `show outline.entry` rules can change
+// how entries look.
+// Corresponds to Rust's OutlineElem in model/outline.rs.
+type OutlineElem struct {
+	// Title is the outline's title: content, none for no title, or auto
+	// for "Contents". Default: auto.
+	Title foundations.Value `typst:"title"`
+
+	// Target is the selector of the elements to list. Default: heading.
+	Target foundations.Value `typst:"target"`
+
+	// Depth is the deepest level to list. If nil, all levels are listed.
+	Depth *int64 `typst:"depth,type=int"`
+
+	// Indent is how to indent the entries: auto or true to indent each
+	// level by DefaultOutlineIndent, none or false for no indentation, a
+	// length to indent each level by, or a function that receives the
+	// level, starting at zero, and returns a length or content to put in
+	// front of the entry. Default: none.
+	Indent foundations.Value `typst:"indent"`
+
+	// Fill is the content between an entry and its page number, usually
+	// repeated dots. With none, the page number is just pushed to the end
+	// of the line. If nil or auto, it is repeated periods.
+	Fill foundations.Value `typst:"fill"`
+
+	foundations.ElemMeta
+}
+
+func (*OutlineElem) IsContentElement() {}
+
+// OutlineDef is the registered element definition for outline.
+var OutlineDef *foundations.ElementDef
+
+// OutlineEntryDef is the registered element definition for outline.entry.
+var OutlineEntryDef *foundations.ElementDef
+
+func init() {
+	OutlineDef = foundations.RegisterElement[OutlineElem]("outline", nil)
+	OutlineEntryDef = foundations.RegisterElement[OutlineEntryElem]("outline.entry", nil)
+}
+
+// OutlineFunc creates the outline element function. Its scope holds entry.
+func OutlineFunc() *foundations.Func {
+	name := "outline"
+	scope := foundations.NewScope()
+	scope.Define("entry", foundations.FuncValue{Func: outlineEntryFunc()}, syntax.Detached())
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func:  outlineNative,
+			Info:  OutlineDef.ToFuncInfo(),
+			Scope: scope,
+		},
+	}
+}
+
+// outlineNative implements the outline() function.
+func outlineNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[OutlineElem](OutlineDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Depth != nil && *elem.Depth < 1 {
+		return nil, foundations.NewSourceError(args.Span, "depth must be at least 1")
+	}
+	if elem.Target != nil {
+		if _, err := foundations.CastLocatableSelector(elem.Target); err != nil {
+			return nil, err
+		}
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// TargetSelector returns the selector of the elements to list.
+func (e *OutlineElem) TargetSelector() foundations.Selector {
+	if e.Target != nil {
+		if selector, err := foundations.CastLocatableSelector(e.Target); err == nil {
+			return selector.Selector
+		}
+	}
+	return foundations.ElemSelector{Element: foundations.Element{Name: "heading"}}
+}
+
+// Show realizes the outline as its title followed by its entries, one per
+// line. Each entry is indented according to its level.
+// Matches Rust: impl Show for Packed<OutlineElem>
+func (e *OutlineElem) Show(engine *foundations.Engine, context *foundations.Context, title foundations.Content, entries []*OutlineEntryElem) (foundations.Content, error) {
+	var elements []foundations.ContentElement
+	if _, none := e.Title.(foundations.NoneValue); !none {
+		level, numbered, outlined := int64(1), foundations.Value(foundations.NoneValue{}), false
+		elements = append(elements, &HeadingElem{Level: &level, Numbering: numbered, Outlined: &outlined, Body: title})
+	}
+
+	for i, entry := range entries {
+		if i > 0 {
+			elements = append(elements, &LinebreakElem{})
+		}
+		indent, err := e.indent(engine, context, int(entry.Level)-1)
+		if err != nil {
+			return foundations.Content{}, err
+		}
+		elements = append(elements, indent.Elements...)
+		elements = append(elements, entry)
+	}
+	return foundations.Content{Elements: elements}, nil
+}
+
+// indent returns the content in front of an entry at a zero-based depth.
+func (e *OutlineElem) indent(engine *foundations.Engine, context *foundations.Context, depth int) (foundations.Content, error) {
+	var amount foundations.Length
+	switch v := e.Indent.(type) {
+	case nil, foundations.NoneValue:
+		return foundations.Content{}, nil
+	case foundations.AutoValue:
+		amount = DefaultOutlineIndent
+	case foundations.Bool:
+		if !v {
+			return foundations.Content{}, nil
+		}
+		amount = DefaultOutlineIndent
+	case foundations.LengthValue:
+		amount = v.Length
+	case foundations.FuncValue:
+		result, err := v.Func.Call(engine, context, foundations.NewArgs(v.Func.Span, foundations.Int(depth)))
+		if err != nil {
+			return foundations.Content{}, err
+		}
+		switch r := result.(type) {
+		case foundations.LengthValue:
+			return outlineSpacing(r.Length), nil
+		case foundations.ContentValue:
+			return r.Content, nil
+		}
+		return foundations.Content{}, &foundations.TypeMismatchError{Expected: "length or content", Got: result.Type().String(), Field: "indent"}
+	default:
+		return foundations.Content{}, &foundations.TypeMismatchError{Expected: "auto, bool, length, function, or none", Got: v.Type().String(), Field: "indent"}
+	}
+	return outlineSpacing(foundations.Length{Points: amount.Points * float64(depth)}), nil
+}
+
+// outlineSpacing returns horizontal spacing of a length, or nothing for
+// zero.
+func outlineSpacing(amount foundations.Length) foundations.Content {
+	if amount.Points == 0 {
+		return foundations.Content{}
+	}
+	return foundations.Content{Elements: []foundations.ContentElement{
+		&layout.HElem{Amount: layout.Spacing{Rel: foundations.Relative{Abs: amount}}},
+	}}
+}
+
+// OutlineEntryElem is an entry of an outline. Outlines create their
+// entries from the elements they list, and `show outline.entry` rules can
+// restyle them using the entry's fields, as in
+// `show outline.entry.where(level: 1): strong`.
+//
+// Corresponds to Rust's OutlineEntry in model/outline.rs.
+type OutlineEntryElem struct {
+	// Level is the nesting level of the entry, starting at 1.
+	Level int64 `typst:"level,positional,required,type=int"`
+
+	// Element is the listed element, like a heading.
+	Element foundations.Content `typst:"element,positional,required,type=content"`
+
+	// Body is the text of the entry, including the element's number.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+
+	// Fill is the content between the body and the page number, or nil
+	// for none.
+	Fill *foundations.Content `typst:"fill,positional,required,type=content"`
+
+	// Page is the page number of the element.
+	Page foundations.Content `typst:"page,positional,required,type=content"`
+}
+
+func (*OutlineEntryElem) IsContentElement() {}
+
+// outlineEntryFunc creates the outline.entry element function, so that
+// show rules can construct entries themselves.
+func outlineEntryFunc() *foundations.Func {
+	name := "entry"
+	info := *OutlineEntryDef.ToFuncInfo()
+	info.Name = name
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: outlineEntryNative,
+			Info: &info,
+		},
+	}
+}
+
+// outlineEntryNative implements the outline.entry() function.
+func outlineEntryNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[OutlineEntryElem](OutlineEntryDef, args)
+	if err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// Show realizes the entry as its body, then its fill, and the page number
+// at the end of the line. Without a fill, the page number is pushed to the
+// end by fractional spacing.
+// Matches Rust: impl Show for Packed<OutlineEntry>
+func (e *OutlineEntryElem) Show() foundations.Content {
+	elements := append([]foundations.ContentElement{}, e.Body.Elements...)
+	if e.Fill != nil {
+		elements = append(elements, e.Fill.Elements...)
+	} else {
+		elements = append(elements, &layout.HElem{Amount: layout.Spacing{Fr: foundations.Fraction{Value: 1}, IsFractional: true}})
+	}
+	elements = append(elements, e.Page.Elements...)
+	return foundations.Content{Elements: elements}
+}
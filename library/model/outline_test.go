@@ -0,0 +1,135 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestHeadingFunc(t *testing.T) {
+	level := foundations.Str("level")
+	args := &foundations.Args{Items: []foundations.Arg{
+		{Name: &level, Value: syntax.Spanned[foundations.Value]{V: foundations.Int(2)}},
+		{Value: syntax.Spanned[foundations.Value]{V: foundations.ContentValue{}}},
+	}}
+	value, err := headingNative(foundations.Engine{}, foundations.Context{}, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heading := value.(foundations.ContentValue).Content.Elements[0].(*HeadingElem)
+	if heading.ResolvedLevel() != 2 || !heading.IsOutlined() || heading.RefNumbering() != nil {
+		t.Errorf("heading = %+v", heading)
+	}
+
+	args = &foundations.Args{Items: []foundations.Arg{
+		{Name: &level, Value: syntax.Spanned[foundations.Value]{V: foundations.Int(0)}},
+		{Value: syntax.Spanned[foundations.Value]{V: foundations.ContentValue{}}},
+	}}
+	if _, err := headingNative(foundations.Engine{}, foundations.Context{}, args); err == nil {
+		t.Error("level 0: expected an error")
+	}
+}
+
+func TestOutlineEntryFunc(t *testing.T) {
+	scope := OutlineFunc().Repr.(foundations.NativeFunc).Scope
+	entry := scope.Get("entry").Read().(foundations.FuncValue).Func
+	page := foundations.ContentValue{Content: foundations.Content{Elements: []foundations.ContentElement{&LinebreakElem{}}}}
+	args := foundations.NewArgs(syntax.Detached(), foundations.Int(2), foundations.ContentValue{}, foundations.ContentValue{}, foundations.ContentValue{}, page)
+	value, err := entry.Call(&foundations.Engine{}, foundations.NewContext(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elem := value.(foundations.ContentValue).Content.Elements[0].(*OutlineEntryElem)
+	if elem.Level != 2 || len(elem.Page.Elements) != 1 {
+		t.Errorf("entry = %+v", elem)
+	}
+}
+
+func TestOutlineEntryShow(t *testing.T) {
+	body := foundations.Content{Elements: []foundations.ContentElement{&StrongElem{}}}
+	page := foundations.Content{Elements: []foundations.ContentElement{&EmphElem{}}}
+
+	entry := &OutlineEntryElem{Level: 1, Body: body, Page: page}
+	shown := entry.Show().Elements
+	if len(shown) != 3 {
+		t.Fatalf("entry without fill = %d elements", len(shown))
+	}
+	if h, ok := shown[1].(*layout.HElem); !ok || !h.Amount.IsFrac() {
+		t.Errorf("entry without fill is pushed apart by %T", shown[1])
+	}
+
+	fill := foundations.Content{Elements: []foundations.ContentElement{&layout.RepeatElement{}}}
+	entry.Fill = &fill
+	shown = entry.Show().Elements
+	if len(shown) != 3 {
+		t.Fatalf("entry with fill = %d elements", len(shown))
+	}
+	if _, ok := shown[1].(*layout.RepeatElement); !ok {
+		t.Errorf("entry fill = %T", shown[1])
+	}
+}
+
+func TestOutlineShowIndent(t *testing.T) {
+	entries := []*OutlineEntryElem{{Level: 1}, {Level: 3}}
+	indentOf := func(indent foundations.Value) []float64 {
+		t.Helper()
+		outline := &OutlineElem{Title: foundations.NoneValue{}, Indent: indent}
+		content, err := outline.Show(&foundations.Engine{}, foundations.NewContext(), foundations.Content{}, entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var amounts []float64
+		var pending float64
+		for _, elem := range content.Elements {
+			switch e := elem.(type) {
+			case *layout.HElem:
+				pending = e.Amount.Rel.Abs.Points
+			case *OutlineEntryElem:
+				amounts = append(amounts, pending)
+				pending = 0
+			}
+		}
+		return amounts
+	}
+
+	tests := []struct {
+		name   string
+		indent foundations.Value
+		want   []float64
+	}{
+		{"none", nil, []float64{0, 0}},
+		{"auto", foundations.AutoValue{}, []float64{0, 2 * DefaultOutlineIndent.Points}},
+		{"length", foundations.LengthValue{Length: foundations.Length{Points: 5}}, []float64{0, 10}},
+		{"function", foundations.FuncValue{Func: &foundations.Func{Span: syntax.Detached(), Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				depth, err := args.Expect("level")
+				if err != nil {
+					return nil, err
+				}
+				return foundations.LengthValue{Length: foundations.Length{Points: float64(depth.V.(foundations.Int)) * 7}}, nil
+			},
+			Info: &foundations.FuncInfo{Name: "indent"},
+		}}}, []float64{0, 14}},
+	}
+	for _, tt := range tests {
+		got := indentOf(tt.indent)
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("%s: indents = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOutlineShowTitle(t *testing.T) {
+	outline := &OutlineElem{}
+	title := foundations.Content{Elements: []foundations.ContentElement{&StrongElem{}}}
+	content, err := outline.Show(&foundations.Engine{}, foundations.NewContext(), title, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heading, ok := content.Elements[0].(*HeadingElem)
+	if !ok || heading.IsOutlined() || heading.RefNumbering() != nil {
+		t.Errorf("title = %+v", content.Elements[0])
+	}
+}
@@ -0,0 +1,252 @@
+// Paragraph element for Typst.
+// Translated from typst-library/src/model/par.rs
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// ParElem arranges text, spacing and inline-level elements into a
+// paragraph.
+//
+// Corresponds to Rust's ParElem in model/par.rs.
+type ParElem struct {
+	// Leading is the spacing between lines. If nil, uses 0.65em.
+	Leading *foundations.Length `typst:"leading,type=length"`
+	// Spacing is the spacing between paragraphs. If nil, uses 1.2em.
+	Spacing *foundations.Length `typst:"spacing,type=length"`
+	// Justify is whether to justify text in its line.
+	Justify bool `typst:"justify,type=bool,default=false"`
+	// JustifyLastLine is whether to also justify the last line of a
+	// justified paragraph. Lines ended by a forced break are justified
+	// through `linebreak(justify: true)` instead.
+	JustifyLastLine bool `typst:"justify-last-line,type=bool,default=false"`
+	// FirstLineIndent is the indent of the first line: a length, or a
+	// dictionary with the keys `amount` and `all`. By default, only
+	// paragraphs that directly follow another paragraph are indented. If
+	// nil, no indent.
+	FirstLineIndent foundations.Value `typst:"first-line-indent"`
+	// HangingIndent is the indent of all but the first line. If nil, no
+	// indent.
+	HangingIndent *foundations.Length `typst:"hanging-indent,type=length"`
+	// Costs weighs the page breaks that strand lines and headings: a
+	// dictionary with the ratios `widow`, `orphan`, and `sticky`, each from
+	// 0% to 100%. If nil, all are 100%.
+	Costs foundations.Value `typst:"costs"`
+	// Body is the contents of the paragraph.
+	Body foundations.Content `typst:"body,positional,required,type=content"`
+	foundations.ElemMeta
+}
+
+func (*ParElem) IsContentElement() {}
+
+// ParDef is the registered element definition for par.
+var ParDef *foundations.ElementDef
+
+func init() {
+	ParDef = foundations.RegisterElement[ParElem]("par", nil)
+}
+
+// ParFunc creates the par element function.
+func ParFunc() *foundations.Func {
+	name := "par"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: parNative,
+			Info: ParDef.ToFuncInfo(),
+		},
+	}
+}
+
+// parNative implements the par() function.
+func parNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	elem, err := foundations.ParseElement[ParElem](ParDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if elem.FirstLineIndent != nil {
+		if _, err := CastFirstLineIndent(elem.FirstLineIndent); err != nil {
+			return nil, err
+		}
+	}
+	if elem.Costs != nil {
+		if _, err := CastParCosts(elem.Costs); err != nil {
+			return nil, err
+		}
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// Indent returns the first-line indent of the paragraph, which is zero if
+// none was given.
+func (e *ParElem) Indent() FirstLineIndent {
+	if e.FirstLineIndent == nil {
+		return FirstLineIndent{}
+	}
+	indent, _ := CastFirstLineIndent(e.FirstLineIndent)
+	return indent
+}
+
+// FirstLineIndent configures the indent of the first line of paragraphs.
+//
+// Corresponds to Rust's FirstLineIndent struct in model/par.rs.
+type FirstLineIndent struct {
+	// Amount is the indent.
+	Amount foundations.Length
+	// All is whether to also indent paragraphs that do not follow another
+	// paragraph, like the first paragraph of a document or one after a
+	// heading.
+	All bool
+}
+
+// CastFirstLineIndent casts a length or a dictionary with the keys
+// `amount` and `all` to a first-line indent.
+//
+// Matches Rust: cast! for FirstLineIndent
+func CastFirstLineIndent(v foundations.Value) (FirstLineIndent, error) {
+	if length, ok := v.(foundations.LengthValue); ok {
+		return FirstLineIndent{Amount: length.Length}, nil
+	}
+	dict, ok := foundations.AsDict(v)
+	if !ok {
+		return FirstLineIndent{}, &foundations.TypeMismatchError{Expected: "length or dictionary", Got: v.Type().String(), Field: "first-line-indent"}
+	}
+	var indent FirstLineIndent
+	for _, key := range dict.Keys() {
+		value, _ := dict.Get(key)
+		switch key {
+		case "amount":
+			length, ok := value.(foundations.LengthValue)
+			if !ok {
+				return FirstLineIndent{}, &foundations.TypeMismatchError{Expected: "length", Got: value.Type().String(), Field: "amount"}
+			}
+			indent.Amount = length.Length
+		case "all":
+			all, ok := value.(foundations.Bool)
+			if !ok {
+				return FirstLineIndent{}, &foundations.TypeMismatchError{Expected: "boolean", Got: value.Type().String(), Field: "all"}
+			}
+			indent.All = bool(all)
+		default:
+			return FirstLineIndent{}, &foundations.ConstructorError{
+				Message: fmt.Sprintf("unexpected key %q, valid keys are \"amount\" and \"all\"", key),
+			}
+		}
+	}
+	return indent, nil
+}
+
+// Resolve returns the indent of a paragraph's first line in the given
+// situation. Paragraphs that directly follow another paragraph are
+// indented; all others only if All is set.
+//
+// Matches Rust: the first_line_indent computation in inline::config
+func (i FirstLineIndent) Resolve(situation ParSituation) foundations.Length {
+	if situation == ParConsecutive || i.All {
+		return i.Amount
+	}
+	return foundations.Length{}
+}
+
+// ParCosts weighs the page breaks of the flow that would leave content
+// stranded. A cost of 0% lets a page break wherever it is full; a cost of
+// 100% always moves stranded content to the next page, if it fits there.
+// In between, content is only moved if that leaves at most that share of
+// the page empty.
+//
+// Corresponds to the widow and orphan costs of Rust's Costs struct in
+// text/mod.rs.
+type ParCosts struct {
+	// Widow is the cost of leaving the last line of a paragraph alone at
+	// the top of a page.
+	Widow foundations.Ratio
+	// Orphan is the cost of leaving the first line of a paragraph alone at
+	// the bottom of a page.
+	Orphan foundations.Ratio
+	// Sticky is the cost of leaving a heading alone at the bottom of a
+	// page, apart from the content that follows it.
+	Sticky foundations.Ratio
+}
+
+// DefaultParCosts returns costs that always avoid widows, orphans, and
+// stranded headings.
+func DefaultParCosts() ParCosts {
+	full := foundations.Ratio{Value: 1}
+	return ParCosts{Widow: full, Orphan: full, Sticky: full}
+}
+
+// CastParCosts casts a dictionary with any of the keys `widow`, `orphan`,
+// and `sticky` to costs. Missing keys keep their default of 100%.
+//
+// Matches Rust: cast! for Costs
+func CastParCosts(v foundations.Value) (ParCosts, error) {
+	dict, ok := foundations.AsDict(v)
+	if !ok {
+		return ParCosts{}, &foundations.TypeMismatchError{Expected: "dictionary", Got: v.Type().String(), Field: "costs"}
+	}
+	costs := DefaultParCosts()
+	for _, key := range dict.Keys() {
+		value, _ := dict.Get(key)
+		var cost *foundations.Ratio
+		switch key {
+		case "widow":
+			cost = &costs.Widow
+		case "orphan":
+			cost = &costs.Orphan
+		case "sticky":
+			cost = &costs.Sticky
+		default:
+			return ParCosts{}, &foundations.ConstructorError{
+				Message: fmt.Sprintf("unexpected key %q, valid keys are \"widow\", \"orphan\", and \"sticky\"", key),
+			}
+		}
+		ratio, ok := value.(foundations.RatioValue)
+		if !ok {
+			return ParCosts{}, &foundations.TypeMismatchError{Expected: "ratio", Got: value.Type().String(), Field: key}
+		}
+		if ratio.Ratio.Value < 0 || ratio.Ratio.Value > 1 {
+			return ParCosts{}, &foundations.ConstructorError{
+				Message: fmt.Sprintf("%s cost must be between 0%% and 100%%", key),
+			}
+		}
+		*cost = ratio.Ratio
+	}
+	return costs, nil
+}
+
+// ResolveParCosts returns the costs set for paragraphs in the style chain.
+func ResolveParCosts(styles *foundations.StyleChain) ParCosts {
+	value := styles.Get("par", "costs")
+	if value == nil {
+		return DefaultParCosts()
+	}
+	costs, err := CastParCosts(value)
+	if err != nil {
+		return DefaultParCosts()
+	}
+	return costs
+}
+
+// ParSituation is where a paragraph is placed relative to other content
+// in its flow, which decides whether its first line is indented.
+//
+// Corresponds to Rust's ParSituation enum in model/par.rs.
+type ParSituation int
+
+const (
+	// ParFirst is the first paragraph of a flow.
+	ParFirst ParSituation = iota
+	// ParConsecutive is a paragraph that directly follows another one,
+	// with nothing but spacing in between.
+	ParConsecutive
+	// ParOther is any other paragraph, like one after a heading.
+	ParOther
+)
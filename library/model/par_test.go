@@ -0,0 +1,175 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// namedArgs creates arguments with the given positional values followed by
+// a named boolean argument.
+func namedArgs(name string, value bool, positional ...foundations.Value) *foundations.Args {
+	args := foundations.NewArgs(syntax.Detached(), positional...)
+	key := foundations.Str(name)
+	args.Items = append(args.Items, foundations.Arg{
+		Name:  &key,
+		Value: syntax.Spanned[foundations.Value]{V: foundations.Bool(value)},
+	})
+	return args
+}
+
+func TestParFunc(t *testing.T) {
+	body := foundations.ContentValue{Content: foundations.Content{}}
+	got, err := ParFunc().Call(&foundations.Engine{}, foundations.NewContext(), namedArgs("justify-last-line", true, body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	elems := got.(foundations.ContentValue).Content.Elements
+	par, ok := elems[0].(*ParElem)
+	if !ok {
+		t.Fatalf("expected paragraph, got %T", elems[0])
+	}
+	if !par.JustifyLastLine || par.Justify {
+		t.Errorf("JustifyLastLine = %v, Justify = %v; want true, false", par.JustifyLastLine, par.Justify)
+	}
+}
+
+func TestLinebreakFunc(t *testing.T) {
+	for _, justify := range []bool{false, true} {
+		got, err := LinebreakFunc().Call(&foundations.Engine{}, foundations.NewContext(), namedArgs("justify", justify))
+		if err != nil {
+			t.Fatal(err)
+		}
+		elem := got.(foundations.ContentValue).Content.Elements[0].(*LinebreakElem)
+		if elem.Justify != justify {
+			t.Errorf("Justify = %v, want %v", elem.Justify, justify)
+		}
+	}
+}
+
+func TestCastFirstLineIndent(t *testing.T) {
+	length := foundations.LengthValue{Length: foundations.Length{Points: 12}}
+	indent, err := CastFirstLineIndent(length)
+	if err != nil || indent.Amount.Points != 12 || indent.All {
+		t.Errorf("CastFirstLineIndent(12pt) = %v, %v", indent, err)
+	}
+
+	dict := foundations.NewDict()
+	dict.Set("amount", length)
+	dict.Set("all", foundations.Bool(true))
+	indent, err = CastFirstLineIndent(dict)
+	if err != nil || indent.Amount.Points != 12 || !indent.All {
+		t.Errorf("CastFirstLineIndent((amount: 12pt, all: true)) = %v, %v", indent, err)
+	}
+
+	dict.Set("width", length)
+	if _, err := CastFirstLineIndent(dict); err == nil {
+		t.Error("expected error for unexpected key")
+	}
+	if _, err := CastFirstLineIndent(foundations.Int(1)); err == nil {
+		t.Error("expected error for integer")
+	}
+}
+
+func TestCastParCosts(t *testing.T) {
+	half := foundations.RatioValue{Ratio: foundations.Ratio{Value: 0.5}}
+	dict := foundations.NewDict()
+	dict.Set("widow", half)
+	dict.Set("sticky", foundations.RatioValue{})
+	costs, err := CastParCosts(dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ParCosts{Widow: half.Ratio, Orphan: foundations.Ratio{Value: 1}}
+	if costs != want {
+		t.Errorf("CastParCosts((widow: 50%%, sticky: 0%%)) = %+v, want %+v", costs, want)
+	}
+
+	dict.Set("orphan", foundations.RatioValue{Ratio: foundations.Ratio{Value: 1.5}})
+	if _, err := CastParCosts(dict); err == nil {
+		t.Error("expected error for cost above 100%")
+	}
+	dict = foundations.NewDict()
+	dict.Set("hyphenation", half)
+	if _, err := CastParCosts(dict); err == nil {
+		t.Error("expected error for unexpected key")
+	}
+	dict = foundations.NewDict()
+	dict.Set("widow", foundations.Int(1))
+	if _, err := CastParCosts(dict); err == nil {
+		t.Error("expected error for integer cost")
+	}
+}
+
+func TestResolveParCosts(t *testing.T) {
+	if costs := ResolveParCosts(foundations.EmptyStyleChain()); costs != DefaultParCosts() {
+		t.Errorf("default costs = %+v", costs)
+	}
+
+	dict := foundations.NewDict()
+	dict.Set("orphan", foundations.RatioValue{})
+	name := "par"
+	key := foundations.Str("costs")
+	styles := foundations.EmptyStyleChain().Chain(&foundations.Styles{Rules: []foundations.StyleRule{{
+		Func: &foundations.Func{Name: &name},
+		Args: &foundations.Args{Items: []foundations.Arg{{Name: &key, Value: syntax.Spanned[foundations.Value]{V: dict}}}},
+	}}})
+	costs := ResolveParCosts(styles)
+	if costs.Orphan.Value != 0 || costs.Widow.Value != 1 {
+		t.Errorf("set par(costs: (orphan: 0%%)) = %+v", costs)
+	}
+}
+
+func TestFirstLineIndentResolve(t *testing.T) {
+	amount := foundations.Length{Points: 12}
+	tests := []struct {
+		all       bool
+		situation ParSituation
+		want      float64
+	}{
+		{false, ParFirst, 0},
+		{false, ParConsecutive, 12},
+		{false, ParOther, 0},
+		{true, ParFirst, 12},
+		{true, ParOther, 12},
+	}
+	for _, tt := range tests {
+		indent := FirstLineIndent{Amount: amount, All: tt.all}
+		if got := indent.Resolve(tt.situation).Points; got != tt.want {
+			t.Errorf("all = %v, situation %v: got %v, want %v", tt.all, tt.situation, got, tt.want)
+		}
+	}
+}
+
+func TestParFuncFirstLineIndent(t *testing.T) {
+	body := foundations.ContentValue{Content: foundations.Content{}}
+	call := func(value foundations.Value) (*ParElem, error) {
+		args := foundations.NewArgs(syntax.Detached(), body)
+		key := foundations.Str("first-line-indent")
+		args.Items = append(args.Items, foundations.Arg{
+			Name:  &key,
+			Value: syntax.Spanned[foundations.Value]{V: value},
+		})
+		got, err := ParFunc().Call(&foundations.Engine{}, foundations.NewContext(), args)
+		if err != nil {
+			return nil, err
+		}
+		return got.(foundations.ContentValue).Content.Elements[0].(*ParElem), nil
+	}
+
+	dict := foundations.NewDict()
+	dict.Set("amount", foundations.LengthValue{Length: foundations.Length{Points: 8}})
+	dict.Set("all", foundations.Bool(true))
+	par, err := call(dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indent := par.Indent(); indent.Amount.Points != 8 || !indent.All {
+		t.Errorf("Indent() = %v, want 8pt for all paragraphs", indent)
+	}
+
+	if _, err := call(foundations.Str("wide")); err == nil {
+		t.Error("expected error for string indent")
+	}
+}
@@ -0,0 +1,25 @@
+// Reference support for Typst.
+// Translated from typst-library/src/model/reference.rs
+
+package model
+
+import "github.com/boergens/gotypst/library/foundations"
+
+// Refable is implemented by elements that can be referenced with a label,
+// such as numbered equations, headings, and figures.
+//
+// Corresponds to Rust's Refable trait in model/reference.rs.
+type Refable interface {
+	foundations.ContentElement
+
+	// RefSupplement returns the supplement placed before the number in a
+	// reference, e.g. "Equation". The value is a string or content.
+	RefSupplement() foundations.Value
+
+	// RefCounter returns the name of the counter that numbers the element.
+	RefCounter() string
+
+	// RefNumbering returns the numbering used to display the element's
+	// number, or nil if the element is not numbered.
+	RefNumbering() *Numbering
+}
@@ -3,7 +3,11 @@
 
 package model
 
-import "github.com/boergens/gotypst/library/foundations"
+import (
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+)
 
 // StrongElem strongly emphasizes content by increasing the font weight.
 //
@@ -17,9 +21,14 @@ type StrongElem struct {
 	// Body is the content to strongly emphasize.
 	// Required field.
 	Body foundations.Content
+
+	foundations.ElemMeta
 }
 
 func (*StrongElem) IsContentElement() {}
 
+// PlainText writes the plain text of the body.
+func (e *StrongElem) PlainText(b *strings.Builder) { b.WriteString(e.Body.PlainText()) }
+
 // DefaultStrongDelta is the default font weight increase for strong emphasis.
 const DefaultStrongDelta = 300
@@ -3,7 +3,10 @@
 
 package model
 
-import "github.com/boergens/gotypst/library/foundations"
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
+)
 
 // TableElem represents a table with cells arranged in a grid.
 // Cells flow left-to-right, top-to-bottom.
@@ -20,18 +23,93 @@ type TableElem struct {
 	RowGutter foundations.Value
 	// Inset is the padding inside cells (default: 5pt).
 	Inset foundations.Value
-	// Align specifies cell content alignment.
+	// Align specifies cell content alignment: an alignment, an array of
+	// alignments for the columns, or a function of column and row.
 	Align foundations.Value
-	// Fill is the background fill for cells.
+	// Fill is the background fill for cells: a paint, an array of paints
+	// for the columns, or a function of column and row.
 	Fill foundations.Value
 	// Stroke is the border stroke for cells (default: 1pt + black).
 	Stroke foundations.Value
 	// Children contains the table cell contents and explicit cells.
 	Children []TableChild
+	// Cells are the cells of the table with their positions resolved, in
+	// the order of the children. Plain content children are wrapped in
+	// cells. Set by ResolveCells.
+	// Synthesized field.
+	Cells []*TableCellElem
+	foundations.ElemMeta
 }
 
 func (*TableElem) IsContentElement() {}
 
+// ResolveCellAlign returns the alignment of a cell in column x and row y,
+// or nil for the default alignment. The cell's own align takes precedence
+// over the table's. The cell may be nil for plain content children.
+// Matches Rust: ResolvableCell::resolve_cell for TableCell
+func (t *TableElem) ResolveCellAlign(engine *foundations.Engine, context *foundations.Context, cell *TableCellElem, x, y int) (foundations.Value, error) {
+	if cell != nil && cell.Align != nil && !foundations.IsAuto(cell.Align) {
+		return cell.Align, nil
+	}
+	return layout.ResolveCellValue(engine, context, t.Align, x, y, "align")
+}
+
+// ResolveCellFill returns the fill of a cell in column x and row y, or nil
+// for no fill. The cell's own fill takes precedence over the table's.
+func (t *TableElem) ResolveCellFill(engine *foundations.Engine, context *foundations.Context, cell *TableCellElem, x, y int) (foundations.Value, error) {
+	if cell != nil && cell.Fill != nil && !foundations.IsAuto(cell.Fill) {
+		if foundations.IsNone(cell.Fill) {
+			return nil, nil
+		}
+		return cell.Fill, nil
+	}
+	return layout.ResolveCellValue(engine, context, t.Fill, x, y, "fill")
+}
+
+// ResolveCells positions the cells of the table, which makes them
+// available in Cells. Cells of headers and footers are placed in the order
+// of the children like all other cells.
+// Matches Rust: CellGrid::resolve
+func (t *TableElem) ResolveCells() error {
+	var cells []*TableCellElem
+	for _, child := range t.Children {
+		switch {
+		case child.Content != nil:
+			cells = append(cells, &TableCellElem{Body: *child.Content})
+		case child.Cell != nil:
+			cells = append(cells, child.Cell)
+		case child.Header != nil:
+			cells = appendItemCells(cells, child.Header.Children)
+		case child.Footer != nil:
+			cells = appendItemCells(cells, child.Footer.Children)
+		}
+	}
+
+	placements := make([]layout.CellPlacement, len(cells))
+	for i, cell := range cells {
+		placements[i] = layout.CellPlacement{X: cell.X, Y: cell.Y, Colspan: cell.Colspan, Rowspan: cell.Rowspan}
+	}
+	if err := layout.PlaceCells(layout.ColumnCount(t.Columns), placements); err != nil {
+		return err
+	}
+	for i, cell := range cells {
+		cell.X, cell.Y = placements[i].X, placements[i].Y
+		cell.Colspan, cell.Rowspan = placements[i].Colspan, placements[i].Rowspan
+	}
+	t.Cells = cells
+	return nil
+}
+
+// appendItemCells appends the cells among the items of a header or footer.
+func appendItemCells(cells []*TableCellElem, items []TableItem) []*TableCellElem {
+	for _, item := range items {
+		if item.Cell != nil {
+			cells = append(cells, item.Cell)
+		}
+	}
+	return cells
+}
+
 // TableChild represents an item in the table's children.
 // Corresponds to Rust's TableChild enum.
 type TableChild struct {
@@ -53,29 +131,33 @@ type TableChild struct {
 // Corresponds to Rust's TableCell struct.
 type TableCellElem struct {
 	// Body is the cell's content.
-	Body foundations.Content
+	Body foundations.Content `typst:"body,positional,required,type=content"`
 	// X is the column position (0-indexed). If nil, auto-positioned.
-	X *int
+	X *int64 `typst:"x,type=int"`
 	// Y is the row position (0-indexed). If nil, auto-positioned.
-	Y *int
+	Y *int64 `typst:"y,type=int"`
 	// Colspan is the number of columns this cell spans (default: 1).
-	Colspan int
+	Colspan int64 `typst:"colspan,type=int"`
 	// Rowspan is the number of rows this cell spans (default: 1).
-	Rowspan int
+	Rowspan int64 `typst:"rowspan,type=int"`
 	// Inset overrides the table's inset for this cell.
-	Inset foundations.Value
+	Inset foundations.Value `typst:"inset"`
 	// Align overrides the table's alignment for this cell.
-	Align foundations.Value
+	Align foundations.Value `typst:"align"`
 	// Fill overrides the table's fill for this cell.
-	Fill foundations.Value
+	Fill foundations.Value `typst:"fill"`
 	// Stroke overrides the table's stroke for this cell.
-	Stroke foundations.Value
+	Stroke foundations.Value `typst:"stroke"`
 	// Breakable controls whether rows can break across pages.
-	Breakable foundations.Value
+	Breakable foundations.Value `typst:"breakable"`
 }
 
 func (*TableCellElem) IsContentElement() {}
 
+// TableCellDef is the registered element definition for table.cell, which
+// gives the element its name in show rule selectors.
+var TableCellDef = foundations.RegisterElement[TableCellElem]("table.cell", nil)
+
 // TableHeaderElem represents a repeatable table header.
 // Corresponds to Rust's TableHeader struct.
 type TableHeaderElem struct {
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestTableCellFillFunc(t *testing.T) {
+	gray := foundations.Luma{L: 0.9, A: 1}
+	zebra := &foundations.Func{Span: syntax.Detached(), Repr: foundations.NativeFunc{
+		Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+			if _, err := args.Expect("x"); err != nil {
+				return nil, err
+			}
+			y, err := args.Expect("y")
+			if err != nil {
+				return nil, err
+			}
+			if y.V.(foundations.Int)%2 == 1 {
+				return gray, nil
+			}
+			return foundations.NoneValue{}, nil
+		},
+		Info: &foundations.FuncInfo{Name: "zebra"},
+	}}
+	table := &TableElem{Fill: foundations.FuncValue{Func: zebra}}
+
+	for y, want := range []foundations.Value{nil, gray, nil} {
+		got, err := table.ResolveCellFill(&foundations.Engine{}, foundations.NewContext(), nil, 0, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("fill of row %d = %v, want %v", y, got, want)
+		}
+	}
+
+	red := foundations.Luma{L: 0.5, A: 1}
+	got, err := table.ResolveCellFill(&foundations.Engine{}, foundations.NewContext(), &TableCellElem{Fill: red}, 0, 1)
+	if err != nil || got != red {
+		t.Errorf("cell fill = %v, %v", got, err)
+	}
+}
+
+func TestTableCellAlignArray(t *testing.T) {
+	table := &TableElem{Align: foundations.NewArray(foundations.Str("left"), foundations.Str("right"))}
+	for x, want := range []foundations.Str{"left", "right", "left"} {
+		got, err := table.ResolveCellAlign(&foundations.Engine{}, foundations.NewContext(), nil, x, 0)
+		if err != nil || got != want {
+			t.Errorf("align of column %d = %v, %v, want %v", x, got, err, want)
+		}
+	}
+
+	table.Align = foundations.Int(1)
+	if _, err := table.ResolveCellAlign(&foundations.Engine{}, foundations.NewContext(), nil, 0, 0); err == nil {
+		t.Error("integer align: expected an error")
+	}
+}
+
+func TestTableResolveCells(t *testing.T) {
+	at := func(v int64) *int64 { return &v }
+	body := func() foundations.Content {
+		return foundations.Content{Elements: []foundations.ContentElement{&LinebreakElem{}}}
+	}
+	header := &TableCellElem{Body: body(), Colspan: 2}
+	wide := &TableCellElem{Body: body(), Rowspan: 2}
+	fixed := &TableCellElem{Body: body(), X: at(2), Y: at(0)}
+	plain := body()
+	table := &TableElem{
+		Columns: foundations.Int(3),
+		Children: []TableChild{
+			{Header: &TableHeaderElem{Children: []TableItem{{Cell: header}}}},
+			{Cell: fixed},
+			{Cell: wide},
+			{Content: &plain},
+			{Cell: &TableCellElem{Body: body(), X: at(0)}},
+		},
+	}
+	if err := table.ResolveCells(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][4]int64{
+		{0, 0, 2, 1}, // header
+		{2, 0, 1, 1}, // fixed
+		{0, 1, 1, 2}, // wide
+		{1, 1, 1, 1}, // plain content, wrapped in a cell
+		{0, 3, 1, 1}, // first free row in column 0
+	}
+	if len(table.Cells) != len(want) {
+		t.Fatalf("got %d cells, want %d", len(table.Cells), len(want))
+	}
+	for i, cell := range table.Cells {
+		got := [4]int64{*cell.X, *cell.Y, cell.Colspan, cell.Rowspan}
+		if got != want[i] {
+			t.Errorf("cell %d: (x, y, colspan, rowspan) = %v, want %v", i, got, want[i])
+		}
+	}
+	if table.Cells[0] != header {
+		t.Error("expected header cell to be resolved in place")
+	}
+
+	// Show rules see the resolved position as fields.
+	fields := foundations.ElementFields(table.Cells[3])
+	if x, _ := fields.Get("x"); x != foundations.Int(1) {
+		t.Errorf("x field = %v", x)
+	}
+	if foundations.ElementName(table.Cells[3]) != "table.cell" {
+		t.Errorf("element name = %q", foundations.ElementName(table.Cells[3]))
+	}
+}
+
+func TestTableResolveCellsErrors(t *testing.T) {
+	at := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name  string
+		cells []*TableCellElem
+		want  string
+	}{
+		{"overlap", []*TableCellElem{{X: at(0), Y: at(0)}, {X: at(0), Y: at(0)}}, "second cell at column 0, row 0"},
+		{"colspan", []*TableCellElem{{Colspan: 3}}, "exceed the available column(s)"},
+		{"column", []*TableCellElem{{X: at(2)}}, "exceed the available column(s)"},
+		{"full row", []*TableCellElem{{Y: at(0), Colspan: 2}, {Y: at(0)}}, "row 0 because it was full"},
+	}
+	for _, tt := range tests {
+		table := &TableElem{Columns: foundations.Int(2)}
+		for _, cell := range tt.cells {
+			table.Children = append(table.Children, TableChild{Cell: cell})
+		}
+		err := table.ResolveCells()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Embed element for Typst.
+// Translated from typst-library/src/pdf/embed.rs
+
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// EmbedRelationships are the accepted values of the relationship field:
+// how an embedded file relates to the document, as the PDF/A-3
+// AFRelationship key describes it.
+var EmbedRelationships = []string{"source", "data", "alternative", "supplement"}
+
+// EmbedElem attaches a file to the PDF document. The file is not shown in
+// the document; PDF viewers list it among the attachments.
+//
+// Corresponds to Rust's EmbedElem in pdf/embed.rs.
+type EmbedElem struct {
+	// Path is the path of the file to embed. If Data is given, it only
+	// determines the name of the attachment.
+	Path string `typst:"path,positional,required,type=str"`
+
+	// Data is the raw file data. If omitted, it is read from Path.
+	Data foundations.Value `typst:"data,positional"`
+
+	// Relationship is how the file relates to the document: "source",
+	// "data", "alternative", or "supplement". Default: none.
+	Relationship *string `typst:"relationship,type=str"`
+
+	// MimeType is the MIME type of the file, e.g. "text/xml".
+	// Default: none.
+	MimeType *string `typst:"mime-type,type=str"`
+
+	// Description describes the file for PDF viewers. Default: none.
+	Description *string `typst:"description,type=str"`
+
+	// Bytes is the content of the file, loaded from Data or Path.
+	Bytes []byte
+}
+
+func (*EmbedElem) IsContentElement() {}
+
+// EmbedDef is the registered element definition for embed.
+var EmbedDef *foundations.ElementDef
+
+func init() {
+	EmbedDef = foundations.RegisterElement[EmbedElem]("embed", nil)
+}
+
+// Name returns the name of the attachment: the last component of its path.
+func (e *EmbedElem) Name() string {
+	for i := len(e.Path) - 1; i >= 0; i-- {
+		if e.Path[i] == '/' {
+			return e.Path[i+1:]
+		}
+	}
+	return e.Path
+}
+
+// EmbedFunc creates the embed element function.
+func EmbedFunc() *foundations.Func {
+	name := "embed"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: embedNative,
+			Info: EmbedDef.ToFuncInfo(),
+		},
+	}
+}
+
+// embedNative implements the pdf.embed() function. Without data, the file
+// is read through the world.
+func embedNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	span := args.Span
+	for _, item := range args.Items {
+		if item.Name == nil {
+			span = item.Value.Span
+			break
+		}
+	}
+
+	elem, err := foundations.ParseElement[EmbedElem](EmbedDef, args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch data := elem.Data.(type) {
+	case nil, foundations.NoneValue:
+		if elem.Bytes, err = loadEmbedFile(engine.World, span, elem.Path); err != nil {
+			return nil, err
+		}
+	case foundations.BytesValue:
+		elem.Bytes = data
+	default:
+		return nil, &foundations.TypeMismatchError{Expected: "bytes", Got: data.Type().String(), Field: "data", Span: args.Span}
+	}
+
+	if elem.Relationship != nil && !validRelationship(*elem.Relationship) {
+		return nil, foundations.NewSourceError(args.Span, `expected "source", "data", "alternative", or "supplement"`)
+	}
+	if elem.MimeType != nil && !validMimeType(*elem.MimeType) {
+		return nil, foundations.NewSourceError(args.Span, fmt.Sprintf("invalid MIME type %q", *elem.MimeType)).
+			WithHint("MIME types have the form type/subtype, e.g. text/xml")
+	}
+
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// validRelationship reports whether s is one of EmbedRelationships.
+func validRelationship(s string) bool {
+	for _, r := range EmbedRelationships {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
+
+// validMimeType reports whether s has the form type/subtype, where both
+// parts are non-empty and contain only printable ASCII without spaces.
+func validMimeType(s string) bool {
+	slash := -1
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '/':
+			if slash >= 0 {
+				return false
+			}
+			slash = i
+		case c <= ' ' || c >= 0x7F:
+			return false
+		}
+	}
+	return slash > 0 && slash < len(s)-1
+}
+
+// loadEmbedFile reads a file to embed relative to the file the span is in.
+func loadEmbedFile(world foundations.World, span syntax.Span, path string) ([]byte, error) {
+	id := span.Id()
+	if world == nil || id == nil {
+		return nil, foundations.NewSourceError(span, "cannot access file system from here")
+	}
+	file, err := id.Join(path)
+	if err != nil {
+		return nil, foundations.NewSourceError(span, fmt.Sprintf("invalid path %q: %v", path, err))
+	}
+	data, err := world.File(file)
+	if err != nil {
+		return nil, foundations.NewSourceError(span, fmt.Sprintf("failed to load file (%v)", err))
+	}
+	return data, nil
+}
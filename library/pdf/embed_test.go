@@ -0,0 +1,44 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func callEmbed(args ...foundations.Value) (foundations.Value, error) {
+	return EmbedFunc().Call(&foundations.Engine{}, foundations.NewContext(), foundations.NewArgs(syntax.Detached(), args...))
+}
+
+func TestEmbedWithData(t *testing.T) {
+	value, err := callEmbed(foundations.Str("files/invoice.xml"), foundations.BytesValue("<invoice/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	elem := value.(foundations.ContentValue).Content.Elements[0].(*EmbedElem)
+	if elem.Name() != "invoice.xml" || string(elem.Bytes) != "<invoice/>" {
+		t.Errorf("embed = %q with %q, want invoice.xml with <invoice/>", elem.Name(), elem.Bytes)
+	}
+}
+
+func TestEmbedValidatesFields(t *testing.T) {
+	for _, named := range []struct{ key, value string }{
+		{"relationship", "attachment"},
+		{"mime-type", "xml"},
+		{"mime-type", "text/x ml"},
+	} {
+		args := foundations.NewArgs(syntax.Detached(), foundations.Str("a.xml"), foundations.BytesValue("x"))
+		key := foundations.Str(named.key)
+		args.Items = append(args.Items, foundations.Arg{Name: &key, Value: syntax.NewSpanned[foundations.Value](foundations.Str(named.value), syntax.Detached())})
+		if _, err := EmbedFunc().Call(&foundations.Engine{}, foundations.NewContext(), args); err == nil {
+			t.Errorf("%s: %q: expected an error", named.key, named.value)
+		}
+	}
+}
+
+func TestEmbedWithoutWorld(t *testing.T) {
+	if _, err := callEmbed(foundations.Str("a.xml")); err == nil {
+		t.Error("expected loading a file without a world to fail")
+	}
+}
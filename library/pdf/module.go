@@ -0,0 +1,16 @@
+// PDF module for Typst.
+// Translated from typst-library/src/pdf/mod.rs
+
+package pdf
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Module creates the `pdf` module, which holds PDF-specific functionality.
+func Module() *foundations.Module {
+	scope := foundations.NewScope()
+	scope.Define("embed", foundations.FuncValue{Func: EmbedFunc()}, syntax.Detached())
+	return &foundations.Module{Name: "pdf", Scope: scope}
+}
@@ -2,6 +2,8 @@ package symbols
 
 import (
 	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
 )
 
 func TestSymbolGet(t *testing.T) {
@@ -333,3 +335,51 @@ func TestAllVariants(t *testing.T) {
 		}
 	}
 }
+
+func TestModuleValue(t *testing.T) {
+	value := Root.Value()
+	sym := value.Module.Scope.Get("sym")
+	if sym == nil {
+		t.Fatal("sym not defined")
+	}
+	symModule, ok := sym.Value().(foundations.ModuleValue)
+	if !ok {
+		t.Fatalf("sym is %T, want module", sym.Value())
+	}
+
+	binding := symModule.Module.Scope.Get("arrow")
+	if binding == nil {
+		t.Fatal("sym.arrow not defined")
+	}
+	arrow, ok := binding.Value().(foundations.SymbolValue)
+	if !ok {
+		t.Fatalf("sym.arrow is %T, want symbol", binding.Value())
+	}
+	r, ok := arrow.Modified("r")
+	if !ok || r.Get() != "→" {
+		t.Errorf("arrow.r = %q, want →", r.Get())
+	}
+	double, ok := r.Modified("double")
+	if !ok || double.Get() != "⇒" {
+		t.Errorf("arrow.r.double = %q, want ⇒", double.Get())
+	}
+
+	alpha := symModule.Module.Scope.Get("alpha")
+	if alpha == nil || alpha.Value().(foundations.SymbolValue).Char != 'α' {
+		t.Error("sym.alpha should be α")
+	}
+}
+
+func TestEmojiModuleValue(t *testing.T) {
+	scope := foundations.NewScope()
+	Root.Define(scope)
+	emoji := scope.Get("emoji").Value().(foundations.ModuleValue)
+	face := emoji.Module.Scope.Get("face").Value().(foundations.SymbolValue)
+	grin, ok := face.Modified("grin")
+	if !ok {
+		t.Fatal("emoji.face.grin not found")
+	}
+	if want := Emoji.Get("face").Get("grin"); grin.Get() != want {
+		t.Errorf("emoji.face.grin = %q, want %q", grin.Get(), want)
+	}
+}
@@ -0,0 +1,44 @@
+package symbols
+
+import (
+	"sort"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Value converts the symbol into a symbol value whose variants can be
+// selected by applying modifiers as fields, like `arrow.r.double`.
+func (s *Symbol) Value() foundations.SymbolValue {
+	return foundations.NewSymbol(s.Variants)
+}
+
+// Value converts the module into a module value. Symbols become symbol
+// values and submodules nested module values.
+func (m *Module) Value() foundations.ModuleValue {
+	scope := foundations.NewScope()
+	m.Define(scope)
+	return foundations.ModuleValue{Module: &foundations.Module{Name: m.Name, Scope: scope}}
+}
+
+// Define adds the symbols and submodules of the module to a scope. This is
+// used to make all of `sym` directly accessible in math. Bindings are
+// defined in name order.
+func (m *Module) Define(scope *foundations.Scope) {
+	for _, name := range sortedKeys(m.Submodules) {
+		scope.Define(name, m.Submodules[name].Value(), syntax.Detached())
+	}
+	for _, name := range sortedKeys(m.Symbols) {
+		scope.Define(name, m.Symbols[name].Value(), syntax.Detached())
+	}
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
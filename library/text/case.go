@@ -0,0 +1,133 @@
+// Text case transformations.
+// Translated from typst-library/src/text/case.rs
+
+package text
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Case is a case transformation of text.
+type Case int
+
+const (
+	// CaseLower turns everything into lowercase.
+	CaseLower Case = iota
+	// CaseUpper turns everything into uppercase.
+	CaseUpper
+)
+
+// Apply converts the text to the case.
+func (c Case) Apply(text string) string {
+	if c == CaseUpper {
+		return strings.ToUpper(text)
+	}
+	return strings.ToLower(text)
+}
+
+// ApplyContent returns a copy of the content with all of its text
+// converted to the case. Elements holding content, like strong or
+// headings, are copied with their content converted.
+func (c Case) ApplyContent(content foundations.Content) foundations.Content {
+	return mapText(content, func(t *TextElem) { t.Body = c.Apply(t.Body) })
+}
+
+// mapText returns a copy of the content in which f has modified a copy of
+// each text element. Elements holding content are copied with f applied to
+// the text in their content.
+func mapText(content foundations.Content, f func(*TextElem)) foundations.Content {
+	elements := make([]foundations.ContentElement, len(content.Elements))
+	for i, elem := range content.Elements {
+		elements[i] = mapTextElement(elem, f)
+	}
+	return foundations.Content{Elements: elements}
+}
+
+func mapTextElement(elem foundations.ContentElement, f func(*TextElem)) foundations.ContentElement {
+	switch e := elem.(type) {
+	case *TextElem:
+		out := *e
+		f(&out)
+		return &out
+	case *foundations.SequenceElem:
+		children := make([]foundations.Content, len(e.Children))
+		for i, child := range e.Children {
+			children[i] = mapText(child, f)
+		}
+		return &foundations.SequenceElem{Children: children}
+	case *foundations.StyledElem:
+		return &foundations.StyledElem{Child: mapText(e.Child, f), Styles: e.Styles}
+	}
+
+	// Copy other elements and map the content in their fields.
+	value := reflect.ValueOf(elem)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return elem
+	}
+	out := reflect.New(value.Elem().Type())
+	out.Elem().Set(value.Elem())
+	for i := 0; i < out.Elem().NumField(); i++ {
+		field := out.Elem().Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		switch v := field.Interface().(type) {
+		case foundations.Content:
+			field.Set(reflect.ValueOf(mapText(v, f)))
+		case *foundations.Content:
+			if v != nil {
+				mapped := mapText(*v, f)
+				field.Set(reflect.ValueOf(&mapped))
+			}
+		}
+	}
+	return out.Interface().(foundations.ContentElement)
+}
+
+// UpperFunc creates the upper function, which converts a string or content
+// to uppercase.
+func UpperFunc() *foundations.Func {
+	return caseFunc("upper", CaseUpper)
+}
+
+// LowerFunc creates the lower function, which converts a string or content
+// to lowercase.
+func LowerFunc() *foundations.Func {
+	return caseFunc("lower", CaseLower)
+}
+
+// caseFunc creates a function that converts its argument to a case.
+// Matches Rust: upper and lower in text/case.rs
+func caseFunc(name string, c Case) *foundations.Func {
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				arg, err := args.Expect("text")
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				switch v := arg.V.(type) {
+				case foundations.Str:
+					return foundations.Str(c.Apply(string(v))), nil
+				case foundations.ContentValue:
+					return foundations.ContentValue{Content: c.ApplyContent(v.Content)}, nil
+				}
+				return nil, foundations.NewSourceError(arg.Span, fmt.Sprintf("expected string or content, found %s", arg.V.Type()))
+			},
+			Info: &foundations.FuncInfo{
+				Name:   name,
+				Params: []foundations.ParamInfo{{Name: "text", Type: foundations.TypeDyn}},
+			},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestCaseFuncs(t *testing.T) {
+	call := func(f *foundations.Func, arg foundations.Value) foundations.Value {
+		t.Helper()
+		value, err := f.Call(&foundations.Engine{}, foundations.NewContext(), foundations.NewArgs(syntax.Detached(), arg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	if got := call(UpperFunc(), foundations.Str("Typst")); got != foundations.Str("TYPST") {
+		t.Errorf("upper = %v", got)
+	}
+	if got := call(LowerFunc(), foundations.Str("ABC")); got != foundations.Str("abc") {
+		t.Errorf("lower = %v", got)
+	}
+
+	content := foundations.Content{Elements: []foundations.ContentElement{
+		New("Hello "),
+		&model.StrongElem{Body: Packed("World")},
+		&foundations.SequenceElem{Children: []foundations.Content{Packed("!")}},
+	}}
+	got := call(UpperFunc(), foundations.ContentValue{Content: content}).(foundations.ContentValue).Content
+	if text := got.PlainText(); text != "HELLO WORLD!" {
+		t.Errorf("upper content = %q", text)
+	}
+	strong := got.Elements[1].(*model.StrongElem)
+	if strong.Body.Elements[0].(*TextElem).Body != "WORLD" {
+		t.Errorf("strong body = %+v", strong.Body)
+	}
+	if content.Elements[0].(*TextElem).Body != "Hello " || content.Elements[1].(*model.StrongElem).Body.Elements[0].(*TextElem).Body != "World" {
+		t.Error("upper changed its argument")
+	}
+
+	if _, err := UpperFunc().Call(&foundations.Engine{}, foundations.NewContext(), foundations.NewArgs(syntax.Detached(), foundations.Int(1))); err == nil {
+		t.Error("upper(1): expected an error")
+	}
+}
@@ -0,0 +1,595 @@
+// Syntax definitions for raw highlighting.
+// Corresponds to the syntaxes loaded by Rust's RawElem via syntect.
+
+package text
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SyntaxDefinition is a grammar in the .sublime-syntax format, which
+// assigns scopes like keyword.control to the text of a language. Together
+// with a Theme, it highlights raw text in languages the built-in
+// highlighters do not know.
+//
+// Supported are named and anonymous contexts with match, scope, captures,
+// push, set, pop, include, meta_scope, meta_content_scope, the prototype
+// context, and variables. Patterns use Go's regular expression syntax, so
+// grammars relying on lookaround or backreferences fail to load.
+type SyntaxDefinition struct {
+	// Name is the name of the language, like "Rust".
+	Name string
+	// FileExtensions are the extensions of the language's files, which
+	// also serve as language tags, like "rs".
+	FileExtensions []string
+	// Scope is the scope of the whole text, like source.rust.
+	Scope string
+
+	contexts map[string]*syntaxContext
+}
+
+// syntaxContext is a named or anonymous context of a grammar.
+type syntaxContext struct {
+	metaScope        []string
+	metaContentScope []string
+	includePrototype bool
+	rules            []*syntaxRule
+
+	// flat are the context's rules with includes resolved, computed on
+	// first use.
+	flat []*syntaxRule
+}
+
+// syntaxRule is a match or include in a context.
+type syntaxRule struct {
+	include  string
+	regex    *regexp.Regexp
+	scope    []string
+	captures map[int][]string
+	// push and set name the contexts to push, the last one on top.
+	push []string
+	set  []string
+	pop  int
+}
+
+// Matches reports whether the syntax highlights the given language tag,
+// which may be its name or one of its file extensions.
+func (s *SyntaxDefinition) Matches(lang string) bool {
+	if strings.EqualFold(s.Name, lang) {
+		return true
+	}
+	for _, ext := range s.FileExtensions {
+		if strings.EqualFold(ext, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSublimeSyntax parses a grammar in the .sublime-syntax format.
+func ParseSublimeSyntax(data []byte) (*SyntaxDefinition, error) {
+	var file struct {
+		Name           string            `yaml:"name"`
+		FileExtensions []string          `yaml:"file_extensions"`
+		Scope          string            `yaml:"scope"`
+		Variables      map[string]string `yaml:"variables"`
+		Contexts       map[string][]any  `yaml:"contexts"`
+	}
+	// Grammars declare YAML 1.2, which the decoder rejects even though it
+	// reads the files fine.
+	if bytes.HasPrefix(data, []byte("%YAML")) {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if _, ok := file.Contexts["main"]; !ok {
+		return nil, fmt.Errorf("missing main context")
+	}
+
+	p := &syntaxParser{
+		variables: file.Variables,
+		contexts:  make(map[string]*syntaxContext, len(file.Contexts)),
+	}
+	names := make([]string, 0, len(file.Contexts))
+	for name := range file.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ctx, err := p.context(file.Contexts[name])
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", name, err)
+		}
+		p.contexts[name] = ctx
+	}
+	for name, ctx := range p.contexts {
+		for _, rule := range ctx.rules {
+			for _, target := range append(append([]string{rule.include}, rule.push...), rule.set...) {
+				if target != "" && !strings.HasPrefix(target, "scope:") && p.contexts[target] == nil {
+					return nil, fmt.Errorf("context %s: unknown context %s", name, target)
+				}
+			}
+		}
+	}
+	if prototype := p.contexts["prototype"]; prototype != nil {
+		prototype.includePrototype = false
+	}
+
+	return &SyntaxDefinition{
+		Name:           file.Name,
+		FileExtensions: file.FileExtensions,
+		Scope:          file.Scope,
+		contexts:       p.contexts,
+	}, nil
+}
+
+// syntaxParser converts the decoded YAML of a grammar into contexts.
+type syntaxParser struct {
+	variables map[string]string
+	contexts  map[string]*syntaxContext
+	anonymous int
+}
+
+var variablePattern = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+func (p *syntaxParser) context(items []any) (*syntaxContext, error) {
+	ctx := &syntaxContext{includePrototype: true}
+	for _, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected a rule, found %v", item)
+		}
+		switch {
+		case fields["meta_scope"] != nil:
+			ctx.metaScope = scopes(fields["meta_scope"])
+		case fields["meta_content_scope"] != nil:
+			ctx.metaContentScope = scopes(fields["meta_content_scope"])
+		case fields["meta_include_prototype"] != nil:
+			ctx.includePrototype = fields["meta_include_prototype"] == true
+		case fields["include"] != nil:
+			ctx.rules = append(ctx.rules, &syntaxRule{include: fmt.Sprint(fields["include"])})
+		case fields["match"] != nil:
+			rule, err := p.rule(fields)
+			if err != nil {
+				return nil, err
+			}
+			ctx.rules = append(ctx.rules, rule)
+		}
+	}
+	return ctx, nil
+}
+
+func (p *syntaxParser) rule(fields map[string]any) (*syntaxRule, error) {
+	pattern := p.expand(fmt.Sprint(fields["match"]), 0)
+	// Lines are matched with their newline, before which $ must match.
+	regex, err := regexp.Compile("(?m)" + translatePattern(pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	rule := &syntaxRule{regex: regex, scope: scopes(fields["scope"])}
+	// Capture groups are numbers, which decode as keys of a generic map.
+	if captures, ok := fields["captures"].(map[any]any); ok {
+		rule.captures = make(map[int][]string, len(captures))
+		for key, scope := range captures {
+			group, ok := key.(int)
+			if !ok {
+				return nil, fmt.Errorf("invalid capture group %v", key)
+			}
+			rule.captures[group] = scopes(scope)
+		}
+	}
+	if rule.push, err = p.targets(fields["push"]); err != nil {
+		return nil, err
+	}
+	if rule.set, err = p.targets(fields["set"]); err != nil {
+		return nil, err
+	}
+	switch pop := fields["pop"].(type) {
+	case bool:
+		if pop {
+			rule.pop = 1
+		}
+	case int:
+		rule.pop = pop
+	}
+	return rule, nil
+}
+
+// targets resolves the contexts of a push or set, which may be a name, an
+// anonymous context, or a list of either.
+func (p *syntaxParser) targets(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		if len(v) > 0 {
+			if _, ok := v[0].(map[string]any); ok {
+				name, err := p.anonymousContext(v)
+				if err != nil {
+					return nil, err
+				}
+				return []string{name}, nil
+			}
+		}
+		var names []string
+		for _, item := range v {
+			switch target := item.(type) {
+			case string:
+				names = append(names, target)
+			case []any:
+				name, err := p.anonymousContext(target)
+				if err != nil {
+					return nil, err
+				}
+				names = append(names, name)
+			default:
+				return nil, fmt.Errorf("expected a context, found %v", item)
+			}
+		}
+		return names, nil
+	}
+	return nil, fmt.Errorf("expected a context, found %v", value)
+}
+
+func (p *syntaxParser) anonymousContext(items []any) (string, error) {
+	ctx, err := p.context(items)
+	if err != nil {
+		return "", err
+	}
+	p.anonymous++
+	name := fmt.Sprintf("#anonymous-%d", p.anonymous)
+	p.contexts[name] = ctx
+	return name, nil
+}
+
+// expand substitutes the {{variables}} in a pattern. Variables may refer
+// to other variables, up to a fixed depth to stop cycles.
+func (p *syntaxParser) expand(pattern string, depth int) string {
+	if depth > 10 {
+		return pattern
+	}
+	return variablePattern.ReplaceAllStringFunc(pattern, func(ref string) string {
+		value, ok := p.variables[ref[2:len(ref)-2]]
+		if !ok {
+			return ref
+		}
+		return p.expand(value, depth+1)
+	})
+}
+
+// translatePattern rewrites the Oniguruma escapes common in grammars that
+// Go's regular expressions spell differently.
+func translatePattern(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '\\' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'h':
+			b.WriteString(`[0-9a-fA-F]`)
+		case 'H':
+			b.WriteString(`[^0-9a-fA-F]`)
+		case 'Z':
+			b.WriteString(`\n?\z`)
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
+
+// scopes splits a scope value into its space-separated scopes.
+func scopes(value any) []string {
+	if value == nil {
+		return nil
+	}
+	return strings.Fields(fmt.Sprint(value))
+}
+
+// rulesOf returns the rules of a context with its includes and the
+// prototype resolved.
+func (s *SyntaxDefinition) rulesOf(ctx *syntaxContext) []*syntaxRule {
+	if ctx.flat == nil {
+		visited := map[*syntaxContext]bool{}
+		if prototype := s.contexts["prototype"]; prototype != nil && ctx.includePrototype {
+			ctx.flat = s.collect(prototype, visited, nil)
+		}
+		ctx.flat = s.collect(ctx, visited, ctx.flat)
+	}
+	return ctx.flat
+}
+
+func (s *SyntaxDefinition) collect(ctx *syntaxContext, visited map[*syntaxContext]bool, out []*syntaxRule) []*syntaxRule {
+	if visited[ctx] {
+		return out
+	}
+	visited[ctx] = true
+	for _, rule := range ctx.rules {
+		if rule.include == "" {
+			out = append(out, rule)
+		} else if included := s.contexts[rule.include]; included != nil {
+			out = s.collect(included, visited, out)
+		}
+	}
+	return out
+}
+
+// Highlight assigns scopes to code and styles them with the theme. The
+// tokens cover the whole code, with adjacent tokens of the same style
+// merged.
+func (s *SyntaxDefinition) Highlight(code string, theme *Theme) []Token {
+	h := s.tokenizer(theme)
+	for _, line := range strings.SplitAfter(code, "\n") {
+		if line != "" {
+			h.line(line)
+		}
+	}
+	return h.tokens
+}
+
+// Tokenizer returns a highlighter for one text, which keeps the context
+// stack of the grammar from one line to the next.
+func (s *SyntaxDefinition) Tokenizer(theme *Theme) Highlighter {
+	return s.tokenizer(theme)
+}
+
+func (s *SyntaxDefinition) tokenizer(theme *Theme) *grammarHighlighter {
+	return &grammarHighlighter{syntax: s, theme: theme, stack: []*syntaxContext{s.contexts["main"]}}
+}
+
+// grammarHighlighter is the state of highlighting a text line by line.
+type grammarHighlighter struct {
+	syntax *SyntaxDefinition
+	theme  *Theme
+	stack  []*syntaxContext
+	tokens []Token
+}
+
+// Tokenize implements Highlighter. Grammars often match the end of a line
+// with its newline, so the line is highlighted with one, which is then
+// dropped from the tokens.
+func (h *grammarHighlighter) Tokenize(lang, line string) []Token {
+	h.tokens = nil
+	h.line(line + "\n")
+	tokens := h.tokens
+	h.tokens = nil
+	if n := len(tokens); n > 0 {
+		tokens[n-1].Text = strings.TrimSuffix(tokens[n-1].Text, "\n")
+		if tokens[n-1].Text == "" {
+			tokens = tokens[:n-1]
+		}
+	}
+	return tokens
+}
+
+func (h *grammarHighlighter) line(line string) {
+	pos := 0
+	emptyAt := -1
+	for pos < len(line) {
+		rule, match := h.search(line, pos)
+		if match == nil {
+			h.emit(line[pos:], h.scopes())
+			return
+		}
+		start, end := match[0], match[1]
+		// An empty match may change the stack once, but must not stop
+		// the highlighter from making progress.
+		if start == end && (emptyAt == start || (rule.push == nil && rule.set == nil && rule.pop == 0)) {
+			_, size := utf8.DecodeRuneInString(line[start:])
+			h.emit(line[pos:start+size], h.scopes())
+			pos = start + size
+			continue
+		}
+		if start == end {
+			emptyAt = start
+		}
+
+		h.emit(line[pos:start], h.scopes())
+		h.emitMatch(line, rule, match)
+		h.apply(rule)
+		pos = end
+	}
+}
+
+// search finds the rule of the current context that matches first at or
+// after pos, preferring earlier rules on ties.
+func (h *grammarHighlighter) search(line string, pos int) (*syntaxRule, []int) {
+	var best *syntaxRule
+	var bestMatch []int
+	for _, rule := range h.syntax.rulesOf(h.top()) {
+		match := searchFrom(rule.regex, line, pos)
+		if match != nil && (bestMatch == nil || match[0] < bestMatch[0]) {
+			best, bestMatch = rule, match
+		}
+	}
+	return best, bestMatch
+}
+
+// searchFrom finds the first match of the regex that starts at or after
+// pos. Matching the whole line keeps anchors and word boundaries correct;
+// only when a match straddles pos is the rest of the line matched alone.
+func searchFrom(re *regexp.Regexp, line string, pos int) []int {
+	for _, match := range re.FindAllStringSubmatchIndex(line, -1) {
+		if match[0] >= pos {
+			return match
+		}
+		if match[1] > pos {
+			break
+		}
+	}
+	match := re.FindStringSubmatchIndex(line[pos:])
+	for i := range match {
+		if match[i] >= 0 {
+			match[i] += pos
+		}
+	}
+	return match
+}
+
+// emitMatch emits the matched text, with the scopes of capture groups
+// added to the parts they cover.
+func (h *grammarHighlighter) emitMatch(line string, rule *syntaxRule, match []int) {
+	base := h.scopes()
+	if len(rule.push) > 0 || len(rule.set) > 0 {
+		for _, name := range append(rule.set, rule.push...) {
+			base = append(base, h.syntax.contexts[name].metaScope...)
+		}
+	}
+	base = append(base, rule.scope...)
+	if len(rule.captures) == 0 {
+		h.emit(line[match[0]:match[1]], base)
+		return
+	}
+
+	bounds := []int{match[0], match[1]}
+	for group := range rule.captures {
+		if 2*group+1 < len(match) && match[2*group] >= 0 {
+			bounds = append(bounds, match[2*group], match[2*group+1])
+		}
+	}
+	sort.Ints(bounds)
+	groups := make([]int, 0, len(rule.captures))
+	for group := range rule.captures {
+		groups = append(groups, group)
+	}
+	sort.Ints(groups)
+
+	for i := 0; i+1 < len(bounds); i++ {
+		from, to := bounds[i], bounds[i+1]
+		if from == to {
+			continue
+		}
+		segment := append([]string{}, base...)
+		for _, group := range groups {
+			if 2*group+1 < len(match) && match[2*group] <= from && to <= match[2*group+1] {
+				segment = append(segment, rule.captures[group]...)
+			}
+		}
+		h.emit(line[from:to], segment)
+	}
+}
+
+// apply performs the stack changes of a rule.
+func (h *grammarHighlighter) apply(rule *syntaxRule) {
+	if rule.pop > 0 {
+		h.stack = h.stack[:max(len(h.stack)-rule.pop, 1)]
+	}
+	targets := rule.push
+	if len(rule.set) > 0 {
+		if len(h.stack) > 1 {
+			h.stack = h.stack[:len(h.stack)-1]
+		} else {
+			h.stack = h.stack[:0]
+		}
+		targets = rule.set
+	}
+	for _, name := range targets {
+		h.stack = append(h.stack, h.syntax.contexts[name])
+	}
+}
+
+func (h *grammarHighlighter) top() *syntaxContext {
+	return h.stack[len(h.stack)-1]
+}
+
+// scopes returns the scopes applying to text in the current context.
+func (h *grammarHighlighter) scopes() []string {
+	out := []string{h.syntax.Scope}
+	for _, ctx := range h.stack {
+		out = append(out, ctx.metaScope...)
+		out = append(out, ctx.metaContentScope...)
+	}
+	return out
+}
+
+func (h *grammarHighlighter) emit(text string, scopes []string) {
+	if text == "" {
+		return
+	}
+	style := h.theme.Style(scopes)
+	if n := len(h.tokens); n > 0 && h.tokens[n-1].Style == style {
+		h.tokens[n-1].Text += text
+		return
+	}
+	h.tokens = append(h.tokens, Token{Text: text, Style: style})
+}
+
+// Parsed grammars and themes are cached by the hash of their files, since
+// the same files are usually given to many raw elements.
+var (
+	syntaxCache   = map[[sha256.Size]byte]*SyntaxDefinition{}
+	syntaxCacheMu sync.Mutex
+)
+
+// LoadSyntax parses a .sublime-syntax file, reusing an earlier result for
+// the same data.
+func LoadSyntax(data []byte) (*SyntaxDefinition, error) {
+	key := sha256.Sum256(data)
+	syntaxCacheMu.Lock()
+	defer syntaxCacheMu.Unlock()
+	if syntax, ok := syntaxCache[key]; ok {
+		return syntax, nil
+	}
+	syntax, err := ParseSublimeSyntax(data)
+	if err != nil {
+		return nil, err
+	}
+	syntaxCache[key] = syntax
+	return syntax, nil
+}
+
+// GrammarHighlighter highlights the languages of a set of syntax
+// definitions with a theme. Where several definitions match a language,
+// the first one is used.
+type GrammarHighlighter struct {
+	Syntaxes []*SyntaxDefinition
+	// Theme styles the scopes. If nil, DefaultTheme is used.
+	Theme *Theme
+}
+
+// Highlighter returns a highlighter for one text in the given language,
+// or nil if no definition matches it.
+func (g *GrammarHighlighter) Highlighter(lang string) Highlighter {
+	for _, syntax := range g.Syntaxes {
+		if syntax.Matches(lang) {
+			theme := g.Theme
+			if theme == nil {
+				theme = DefaultTheme
+			}
+			return syntax.Tokenizer(theme)
+		}
+	}
+	return nil
+}
+
+// Languages returns the languages the definitions match by name or file
+// extension.
+func (g *GrammarHighlighter) Languages() []string {
+	var langs []string
+	for _, syntax := range g.Syntaxes {
+		if syntax.Name != "" {
+			langs = append(langs, strings.ToLower(syntax.Name))
+		}
+		langs = append(langs, syntax.FileExtensions...)
+	}
+	return langs
+}
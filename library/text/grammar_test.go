@@ -0,0 +1,250 @@
+package text
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+const testSyntax = `%YAML 1.2
+---
+name: Mini
+file_extensions: [mini, mn]
+scope: source.mini
+variables:
+  ident: '[a-z_]+'
+contexts:
+  prototype:
+    - match: '#.*$'
+      scope: comment.line.mini
+  main:
+    - match: '\b(let|fn)\b'
+      scope: keyword.control.mini
+    - match: '({{ident}})(\()'
+      captures:
+        1: entity.name.function.mini
+        2: punctuation.section.mini
+    - match: '"'
+      push: string
+    - match: '\d+'
+      scope: constant.numeric.mini
+  string:
+    - meta_include_prototype: false
+    - meta_scope: string.quoted.mini
+    - match: '\\.'
+      scope: constant.character.escape.mini
+    - match: '"'
+      pop: true
+`
+
+func TestParseSublimeSyntax(t *testing.T) {
+	def, err := ParseSublimeSyntax([]byte(testSyntax))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "Mini" || def.Scope != "source.mini" {
+		t.Errorf("name = %q, scope = %q", def.Name, def.Scope)
+	}
+	for _, lang := range []string{"mini", "MINI", "mn"} {
+		if !def.Matches(lang) {
+			t.Errorf("expected %q to match", lang)
+		}
+	}
+	if def.Matches("rust") {
+		t.Error("unexpected match for rust")
+	}
+}
+
+func TestParseSublimeSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"name: X\ncontexts: {}\n", "missing main context"},
+		{"contexts:\n  main:\n    - match: '(?=a)'\n", "invalid pattern"},
+		{"contexts:\n  main:\n    - match: a\n      push: nowhere\n", "unknown context nowhere"},
+	}
+	for _, tt := range tests {
+		_, err := ParseSublimeSyntax([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error = %v, want %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestSyntaxHighlight(t *testing.T) {
+	def, err := ParseSublimeSyntax([]byte(testSyntax))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := "let x = f(\"a\\n#b\") # done\n42"
+	spans := def.Highlight(code, DefaultTheme)
+
+	var text strings.Builder
+	styles := map[string]HighlightStyle{}
+	for _, span := range spans {
+		text.WriteString(span.Text)
+		styles[span.Text] = span.Style
+	}
+	if text.String() != code {
+		t.Fatalf("spans cover %q, want %q", text.String(), code)
+	}
+
+	tests := []struct {
+		text  string
+		color string
+	}{
+		{"let", "0000ff"},
+		{"f", "4b69c6"},
+		{"\"a", "298e0d"},
+		{"\\n", "1d6c76"},
+		// The string does not include the prototype, so # is not a comment.
+		{"#b\"", "298e0d"},
+		{"# done", "8a8a8a"},
+		{"42", "b60157"},
+	}
+	for _, tt := range tests {
+		style, ok := styles[tt.text]
+		if !ok {
+			t.Errorf("no span %q in %+v", tt.text, spans)
+			continue
+		}
+		if style.Color != tt.color {
+			t.Errorf("%q: color = %q, want %q", tt.text, style.Color, tt.color)
+		}
+	}
+	if !styles["let"].Bold {
+		t.Error("expected keyword to be bold")
+	}
+}
+
+func TestSyntaxHighlightEmptyMatches(t *testing.T) {
+	data := "contexts:\n  main:\n    - match: 'x*'\n      scope: keyword\n    - match: ''\n      push: other\n  other:\n    - match: ''\n      pop: true\n"
+	def, err := ParseSublimeSyntax([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	for _, span := range def.Highlight("ab xx\ncd", DefaultTheme) {
+		text.WriteString(span.Text)
+	}
+	if text.String() != "ab xx\ncd" {
+		t.Errorf("spans cover %q", text.String())
+	}
+}
+
+func TestSyntaxTokenizer(t *testing.T) {
+	def, err := ParseSublimeSyntax([]byte(testSyntax))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The string spans both lines, so the context stack must carry over.
+	tokenizer := def.Tokenizer(DefaultTheme)
+	first := tokenizer.Tokenize("mini", "let s = \"a")
+	second := tokenizer.Tokenize("mini", "b\" 42")
+
+	if n := len(first); n == 0 || first[n-1].Text != "\"a" || first[n-1].Style.Color != "298e0d" {
+		t.Errorf("first line = %+v", first)
+	}
+	want := []Token{
+		{Text: "b\"", Style: HighlightStyle{Color: "298e0d"}},
+		{Text: " "},
+		{Text: "42", Style: HighlightStyle{Color: "b60157"}},
+	}
+	if !slices.Equal(second, want) {
+		t.Errorf("second line = %+v, want %+v", second, want)
+	}
+}
+
+func TestLoadSyntaxCaches(t *testing.T) {
+	a, err := LoadSyntax([]byte(testSyntax))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadSyntax([]byte(testSyntax))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("expected the same definition for the same data")
+	}
+}
+
+// rawWorld serves files from memory.
+type rawWorld struct {
+	files map[string]string
+}
+
+func (w *rawWorld) Library() *foundations.Scope { return foundations.NewScope() }
+func (w *rawWorld) MainFile() syntax.FileId     { panic("no main file") }
+func (w *rawWorld) Source(id syntax.FileId) (*syntax.Source, error) {
+	return nil, errors.New("not a source")
+}
+func (w *rawWorld) Today(offset *int) *foundations.Datetime { return nil }
+
+func (w *rawWorld) File(id syntax.FileId) ([]byte, error) {
+	if text, ok := w.files[id.Get().VPath().GetWithSlash()]; ok {
+		return []byte(text), nil
+	}
+	return nil, errors.New("file not found")
+}
+
+// rawSpan returns a span in /doc/main.typ.
+func rawSpan(t *testing.T) syntax.Span {
+	vpath, err := syntax.NewVirtualPath("/doc/main.typ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return syntax.SpanFromRange(syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern(), 0, 1)
+}
+
+func TestRawSyntaxesAndTheme(t *testing.T) {
+	world := &rawWorld{files: map[string]string{
+		"/doc/mini.sublime-syntax": testSyntax,
+		"/doc/theme.tmTheme":       testTheme,
+	}}
+	span := rawSpan(t)
+	args := foundations.NewArgs(span, foundations.Str("let x = 1"))
+	for _, named := range [][2]string{{"lang", "mini"}, {"syntaxes", "mini.sublime-syntax"}, {"theme", "theme.tmTheme"}} {
+		key := foundations.Str(named[0])
+		args.Items = append(args.Items, foundations.Arg{Span: span, Name: &key, Value: syntax.Spanned[foundations.Value]{V: foundations.Str(named[1]), Span: span}})
+	}
+
+	value, err := rawNative(foundations.Engine{World: world}, foundations.Context{}, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elem := value.(foundations.ContentValue).Content.Elements[0].(*RawElem)
+	if len(elem.SyntaxDefs) != 1 || elem.ThemeDef == nil {
+		t.Fatalf("syntaxes = %v, theme = %v", elem.SyntaxDefs, elem.ThemeDef)
+	}
+
+	if err := elem.Synthesize(foundations.NewStyleChain(nil)); err != nil {
+		t.Fatal(err)
+	}
+	lines := DefaultHighlightHooks.HighlightLines(elem)
+	if len(lines) != 1 || len(lines[0]) == 0 || lines[0][0].Text != "let" || lines[0][0].Style.Color != "aa0000" {
+		t.Errorf("lines = %+v", lines)
+	}
+
+	elem.Theme = foundations.NoneValue{}
+	for _, token := range DefaultHighlightHooks.HighlightLines(elem)[0] {
+		if token.Style != (HighlightStyle{}) {
+			t.Errorf("expected no style with theme none, got %+v", token)
+		}
+	}
+}
+
+func TestRawSyntaxesMissingFile(t *testing.T) {
+	_, err := loadRawSyntaxes(&rawWorld{}, rawSpan(t), foundations.Str("missing.sublime-syntax"))
+	if err == nil || !strings.Contains(err.Error(), "failed to load syntax") {
+		t.Errorf("error = %v", err)
+	}
+	if _, err := loadRawTheme(nil, syntax.Detached(), foundations.Str("theme.tmTheme")); err == nil {
+		t.Error("expected an error for a path without a world")
+	}
+}
@@ -1,12 +1,10 @@
 // Package text provides text-related functions for the Typst standard library.
 package text
 
-import (
-	"github.com/boergens/gotypst/eval"
-)
+import "github.com/boergens/gotypst/library/foundations"
 
-// HighlightedSpan represents a span of highlighted text with styling.
-type HighlightedSpan struct {
+// Token is a piece of highlighted text with its style.
+type Token struct {
 	Text  string
 	Style HighlightStyle
 }
@@ -23,42 +21,75 @@ type HighlightStyle struct {
 	Underline bool
 }
 
-// SyntaxHighlighter is the interface for syntax highlighting implementations.
-// Highlighters take raw code text and return highlighted spans.
-type SyntaxHighlighter interface {
-	// Highlight takes source code and returns highlighted spans.
-	// Returns nil if highlighting is not possible or not needed.
-	Highlight(code string, lang string) []HighlightedSpan
+// Highlighter splits code into styled tokens, one line at a time.
+//
+// A highlighter receives the lines of one raw text in order, without their
+// newlines, so it may carry state from one line to the next, like an open
+// block comment. The tokens of a line must cover all of its text. A nil
+// result leaves the line unstyled.
+type Highlighter interface {
+	Tokenize(lang, line string) []Token
+}
+
+// HighlighterFunc adapts a function to a Highlighter that keeps no state
+// between lines. It is the easiest way to plug in a third-party
+// highlighter, for example a chroma lexer:
+//
+//	hooks.Register(text.HighlighterFunc(func(lang, line string) []text.Token {
+//		iter, err := lexers.Get(lang).Tokenise(nil, line)
+//		if err != nil {
+//			return nil
+//		}
+//		var tokens []text.Token
+//		for _, t := range iter.Tokens() {
+//			entry := style.Get(t.Type)
+//			tokens = append(tokens, text.Token{Text: t.Value, Style: text.HighlightStyle{
+//				Color: strings.TrimPrefix(entry.Colour.String(), "#"),
+//				Bold:  entry.Bold == chroma.Yes,
+//			}})
+//		}
+//		return tokens
+//	}), "go", "rust")
+type HighlighterFunc func(lang, line string) []Token
 
-	// SupportedLanguages returns a list of language identifiers this highlighter supports.
-	SupportedLanguages() []string
+// Tokenize implements Highlighter.
+func (f HighlighterFunc) Tokenize(lang, line string) []Token {
+	return f(lang, line)
 }
 
 // HighlightHooks manages syntax highlighting hooks.
 type HighlightHooks struct {
-	// highlighters is a map from language to highlighter
-	highlighters map[string]SyntaxHighlighter
+	// highlighters maps a language to the constructor of its highlighter.
+	highlighters map[string]func() Highlighter
 	// defaultHighlighter is used when no language-specific highlighter is found
-	defaultHighlighter SyntaxHighlighter
+	defaultHighlighter func() Highlighter
 }
 
 // NewHighlightHooks creates a new HighlightHooks instance.
 func NewHighlightHooks() *HighlightHooks {
 	return &HighlightHooks{
-		highlighters: make(map[string]SyntaxHighlighter),
+		highlighters: make(map[string]func() Highlighter),
 	}
 }
 
-// Register registers a syntax highlighter for specific languages.
-func (h *HighlightHooks) Register(highlighter SyntaxHighlighter) {
-	for _, lang := range highlighter.SupportedLanguages() {
-		h.highlighters[lang] = highlighter
+// Register registers a highlighter for the given languages. The same
+// highlighter is used for all raw texts, so it should keep no state
+// between lines; use RegisterStateful for highlighters that do.
+func (h *HighlightHooks) Register(highlighter Highlighter, langs ...string) {
+	h.RegisterStateful(func() Highlighter { return highlighter }, langs...)
+}
+
+// RegisterStateful registers a constructor of highlighters for the given
+// languages. A new highlighter is created for each raw text.
+func (h *HighlightHooks) RegisterStateful(newHighlighter func() Highlighter, langs ...string) {
+	for _, lang := range langs {
+		h.highlighters[lang] = newHighlighter
 	}
 }
 
 // RegisterDefault registers a default highlighter used when no language-specific one is found.
-func (h *HighlightHooks) RegisterDefault(highlighter SyntaxHighlighter) {
-	h.defaultHighlighter = highlighter
+func (h *HighlightHooks) RegisterDefault(highlighter Highlighter) {
+	h.defaultHighlighter = func() Highlighter { return highlighter }
 }
 
 // Unregister removes a highlighter for a specific language.
@@ -66,22 +97,16 @@ func (h *HighlightHooks) Unregister(lang string) {
 	delete(h.highlighters, lang)
 }
 
-// GetHighlighter returns the highlighter for a given language, or nil if none registered.
-func (h *HighlightHooks) GetHighlighter(lang string) SyntaxHighlighter {
-	if hl, ok := h.highlighters[lang]; ok {
-		return hl
+// Highlighter returns a highlighter for one raw text in the given
+// language, or nil if none is registered.
+func (h *HighlightHooks) Highlighter(lang string) Highlighter {
+	if newHighlighter, ok := h.highlighters[lang]; ok {
+		return newHighlighter()
 	}
-	return h.defaultHighlighter
-}
-
-// Highlight highlights code using the registered highlighter for the language.
-// Returns nil if no highlighter is registered for the language.
-func (h *HighlightHooks) Highlight(code string, lang string) []HighlightedSpan {
-	highlighter := h.GetHighlighter(lang)
-	if highlighter == nil {
-		return nil
+	if h.defaultHighlighter != nil {
+		return h.defaultHighlighter()
 	}
-	return highlighter.Highlight(code, lang)
+	return nil
 }
 
 // HasHighlighter returns true if a highlighter is registered for the given language.
@@ -90,31 +115,48 @@ func (h *HighlightHooks) HasHighlighter(lang string) bool {
 	return ok || h.defaultHighlighter != nil
 }
 
-// HighlightRawElement highlights a raw element's text.
-// Returns the highlighted spans, or nil if no highlighting is available.
-func (h *HighlightHooks) HighlightRawElement(element *eval.RawElement) []HighlightedSpan {
+// HighlightLines tokenizes the lines of a raw element, which Synthesize
+// must have split. The element's own syntax definitions take precedence
+// over the registered highlighters. With a theme of none, the tokens are
+// left unstyled. Returns nil if the element has no language or no
+// highlighter handles it.
+func (h *HighlightHooks) HighlightLines(element *RawElem) [][]Token {
 	if element.Lang == "" {
 		return nil
 	}
-	return h.Highlight(element.Text, element.Lang)
+	grammars := &GrammarHighlighter{Syntaxes: element.SyntaxDefs, Theme: element.ThemeDef}
+	highlighter := grammars.Highlighter(element.Lang)
+	if highlighter == nil {
+		highlighter = h.Highlighter(element.Lang)
+	}
+	if highlighter == nil {
+		return nil
+	}
+	_, none := element.Theme.(foundations.NoneValue)
+	lines := make([][]Token, len(element.Lines))
+	for i, line := range element.Lines {
+		tokens := highlighter.Tokenize(element.Lang, line.Text)
+		if none {
+			for j := range tokens {
+				tokens[j].Style = HighlightStyle{}
+			}
+		}
+		lines[i] = tokens
+	}
+	return lines
 }
 
 // DefaultHighlightHooks is the global default highlight hooks instance.
 // This can be used when a World implementation doesn't provide custom hooks.
 var DefaultHighlightHooks = NewHighlightHooks()
 
-// NoOpHighlighter is a highlighter that returns the text as a single unhighlighted span.
+// NoOpHighlighter is a highlighter that returns each line as a single unhighlighted token.
 // This is useful as a fallback when no real syntax highlighting is available.
 type NoOpHighlighter struct{}
 
-// Highlight returns the text as a single unhighlighted span.
-func (NoOpHighlighter) Highlight(code string, lang string) []HighlightedSpan {
-	return []HighlightedSpan{{Text: code}}
-}
-
-// SupportedLanguages returns an empty list since this matches no specific language.
-func (NoOpHighlighter) SupportedLanguages() []string {
-	return nil
+// Tokenize returns the line as a single unhighlighted token.
+func (NoOpHighlighter) Tokenize(lang, line string) []Token {
+	return []Token{{Text: line}}
 }
 
 // SimpleKeywordHighlighter provides basic keyword highlighting for common languages.
@@ -203,18 +245,18 @@ func NewSimpleKeywordHighlighter() *SimpleKeywordHighlighter {
 	}
 }
 
-// Highlight implements SyntaxHighlighter.
-func (h *SimpleKeywordHighlighter) Highlight(code string, lang string) []HighlightedSpan {
+// Tokenize implements Highlighter.
+func (h *SimpleKeywordHighlighter) Tokenize(lang, line string) []Token {
 	keywords, ok := h.keywords[lang]
 	if !ok {
-		return []HighlightedSpan{{Text: code}}
+		return []Token{{Text: line}}
 	}
 
-	var spans []HighlightedSpan
+	var spans []Token
 	current := ""
 	word := ""
 
-	for _, r := range code {
+	for _, r := range line {
 		if isWordChar(r) {
 			word += string(r)
 		} else {
@@ -222,10 +264,10 @@ func (h *SimpleKeywordHighlighter) Highlight(code string, lang string) []Highlig
 			if word != "" {
 				if style, isKeyword := keywords[word]; isKeyword {
 					if current != "" {
-						spans = append(spans, HighlightedSpan{Text: current})
+						spans = append(spans, Token{Text: current})
 						current = ""
 					}
-					spans = append(spans, HighlightedSpan{Text: word, Style: style})
+					spans = append(spans, Token{Text: word, Style: style})
 				} else {
 					current += word
 				}
@@ -239,10 +281,10 @@ func (h *SimpleKeywordHighlighter) Highlight(code string, lang string) []Highlig
 	if word != "" {
 		if style, isKeyword := keywords[word]; isKeyword {
 			if current != "" {
-				spans = append(spans, HighlightedSpan{Text: current})
+				spans = append(spans, Token{Text: current})
 				current = ""
 			}
-			spans = append(spans, HighlightedSpan{Text: word, Style: style})
+			spans = append(spans, Token{Text: word, Style: style})
 		} else {
 			current += word
 		}
@@ -250,14 +292,14 @@ func (h *SimpleKeywordHighlighter) Highlight(code string, lang string) []Highlig
 
 	// Emit remaining text
 	if current != "" {
-		spans = append(spans, HighlightedSpan{Text: current})
+		spans = append(spans, Token{Text: current})
 	}
 
 	return spans
 }
 
-// SupportedLanguages implements SyntaxHighlighter.
-func (h *SimpleKeywordHighlighter) SupportedLanguages() []string {
+// Languages returns the languages the highlighter knows keywords of.
+func (h *SimpleKeywordHighlighter) Languages() []string {
 	langs := make([]string, 0, len(h.keywords))
 	for lang := range h.keywords {
 		langs = append(langs, lang)
@@ -272,7 +314,8 @@ func isWordChar(r rune) bool {
 
 // RegisterBuiltinHighlighters registers the built-in syntax highlighters.
 func RegisterBuiltinHighlighters(hooks *HighlightHooks) {
-	hooks.Register(NewSimpleKeywordHighlighter())
+	keywords := NewSimpleKeywordHighlighter()
+	hooks.Register(keywords, keywords.Languages()...)
 	hooks.RegisterDefault(&NoOpHighlighter{})
 }
 
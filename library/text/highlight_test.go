@@ -1,9 +1,11 @@
 package text
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
-	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
 )
 
 func TestHighlightHooks(t *testing.T) {
@@ -17,10 +19,10 @@ func TestHighlightHooksRegister(t *testing.T) {
 	hooks := NewHighlightHooks()
 	highlighter := NewSimpleKeywordHighlighter()
 
-	hooks.Register(highlighter)
+	hooks.Register(highlighter, highlighter.Languages()...)
 
 	// Should have highlighters for the supported languages
-	for _, lang := range highlighter.SupportedLanguages() {
+	for _, lang := range highlighter.Languages() {
 		if !hooks.HasHighlighter(lang) {
 			t.Errorf("expected highlighter for %s after registration", lang)
 		}
@@ -31,10 +33,10 @@ func TestHighlightHooksUnregister(t *testing.T) {
 	hooks := NewHighlightHooks()
 	highlighter := NewSimpleKeywordHighlighter()
 
-	hooks.Register(highlighter)
+	hooks.Register(highlighter, highlighter.Languages()...)
 	hooks.Unregister("go")
 
-	if hl := hooks.GetHighlighter("go"); hl != nil {
+	if hl := hooks.Highlighter("go"); hl != nil {
 		t.Error("expected nil highlighter for 'go' after unregister")
 	}
 
@@ -53,7 +55,7 @@ func TestHighlightHooksDefault(t *testing.T) {
 		t.Error("expected default highlighter to match unknown language")
 	}
 
-	spans := hooks.Highlight("code", "unknown-lang")
+	spans := hooks.Highlighter("unknown-lang").Tokenize("unknown-lang", "code")
 	if len(spans) != 1 || spans[0].Text != "code" {
 		t.Error("expected default highlighter to return text as-is")
 	}
@@ -62,7 +64,7 @@ func TestHighlightHooksDefault(t *testing.T) {
 func TestNoOpHighlighter(t *testing.T) {
 	h := NoOpHighlighter{}
 
-	spans := h.Highlight("func main() {}", "go")
+	spans := h.Tokenize("go", "func main() {}")
 	if len(spans) != 1 {
 		t.Fatalf("expected 1 span, got %d", len(spans))
 	}
@@ -72,11 +74,6 @@ func TestNoOpHighlighter(t *testing.T) {
 	if spans[0].Style != (HighlightStyle{}) {
 		t.Error("expected empty style from NoOpHighlighter")
 	}
-
-	langs := h.SupportedLanguages()
-	if langs != nil {
-		t.Errorf("expected nil supported languages, got %v", langs)
-	}
 }
 
 func TestSimpleKeywordHighlighter(t *testing.T) {
@@ -95,9 +92,9 @@ func TestSimpleKeywordHighlighter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.code, func(t *testing.T) {
-			spans := h.Highlight(tt.code, tt.lang)
+			spans := h.Tokenize(tt.lang, tt.code)
 			if len(spans) != tt.wantSpans {
-				t.Errorf("Highlight(%q, %q) = %d spans, want %d",
+				t.Errorf("Tokenize(%q, %q) = %d spans, want %d",
 					tt.code, tt.lang, len(spans), tt.wantSpans)
 				for i, s := range spans {
 					t.Logf("  span[%d] = %q (style: %+v)", i, s.Text, s.Style)
@@ -111,7 +108,7 @@ func TestSimpleKeywordHighlighterKeywordDetection(t *testing.T) {
 	h := NewSimpleKeywordHighlighter()
 
 	// Test Go keywords
-	spans := h.Highlight("func return if else for range", "go")
+	spans := h.Tokenize("go", "func return if else for range")
 
 	// Count highlighted spans (those with non-empty style)
 	highlighted := 0
@@ -127,9 +124,9 @@ func TestSimpleKeywordHighlighterKeywordDetection(t *testing.T) {
 	}
 }
 
-func TestSimpleKeywordHighlighterSupportedLanguages(t *testing.T) {
+func TestSimpleKeywordHighlighterLanguages(t *testing.T) {
 	h := NewSimpleKeywordHighlighter()
-	langs := h.SupportedLanguages()
+	langs := h.Languages()
 
 	expected := map[string]bool{
 		"go":         true,
@@ -150,47 +147,136 @@ func TestSimpleKeywordHighlighterSupportedLanguages(t *testing.T) {
 	}
 }
 
-func TestHighlightRawElement(t *testing.T) {
+func TestHighlightLines(t *testing.T) {
 	hooks := NewHighlightHooks()
 	RegisterBuiltinHighlighters(hooks)
 
-	element := &eval.RawElement{
-		Text:  "func main() {}",
+	element := &RawElem{
+		Text:  "func main() {\n\treturn\n}",
 		Lang:  "go",
 		Block: true,
 	}
-
-	spans := hooks.HighlightRawElement(element)
-	if spans == nil {
-		t.Fatal("expected spans, got nil")
+	if err := element.Synthesize(foundations.NewStyleChain(nil)); err != nil {
+		t.Fatal(err)
 	}
-	if len(spans) == 0 {
-		t.Error("expected non-empty spans")
+
+	lines := hooks.HighlightLines(element)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
 	}
 
-	// Verify the text is reconstructed correctly
-	var reconstructed string
-	for _, s := range spans {
-		reconstructed += s.Text
+	// Verify each line is reconstructed correctly
+	for i, tokens := range lines {
+		var reconstructed string
+		for _, token := range tokens {
+			reconstructed += token.Text
+		}
+		if reconstructed != element.Lines[i].Text {
+			t.Errorf("line %d: reconstructed text = %q, want %q", i, reconstructed, element.Lines[i].Text)
+		}
 	}
-	if reconstructed != element.Text {
-		t.Errorf("reconstructed text = %q, want %q", reconstructed, element.Text)
+	if lines[1][1].Text != "return" || !lines[1][1].Style.Bold {
+		t.Errorf("expected bold return keyword, got %+v", lines[1])
 	}
 }
 
-func TestHighlightRawElementNoLang(t *testing.T) {
+func TestHighlightLinesNoLang(t *testing.T) {
 	hooks := NewHighlightHooks()
 	RegisterBuiltinHighlighters(hooks)
 
-	element := &eval.RawElement{
+	element := &RawElem{
 		Text:  "plain text",
 		Lang:  "",
 		Block: false,
 	}
+	if err := element.Synthesize(foundations.NewStyleChain(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := hooks.HighlightLines(element)
+	if lines != nil {
+		t.Errorf("expected nil lines for raw element without lang, got %v", lines)
+	}
+}
+
+// commentHighlighter marks the lines inside /* */ comments. It carries
+// whether a comment is open from one line to the next.
+type commentHighlighter struct {
+	open bool
+}
+
+func (h *commentHighlighter) Tokenize(lang, line string) []Token {
+	var style HighlightStyle
+	if h.open || strings.HasPrefix(line, "/*") {
+		style.Italic = true
+	}
+	if strings.HasPrefix(line, "/*") {
+		h.open = true
+	}
+	if strings.HasSuffix(line, "*/") {
+		h.open = false
+	}
+	return []Token{{Text: line, Style: style}}
+}
 
-	spans := hooks.HighlightRawElement(element)
-	if spans != nil {
-		t.Errorf("expected nil spans for raw element without lang, got %v", spans)
+func TestHighlightHooksStateful(t *testing.T) {
+	hooks := NewHighlightHooks()
+	hooks.RegisterStateful(func() Highlighter { return &commentHighlighter{} }, "c")
+
+	element := &RawElem{Text: "/* a\nb */\nc", Lang: "c", Block: true}
+	if err := element.Synthesize(foundations.NewStyleChain(nil)); err != nil {
+		t.Fatal(err)
+	}
+	var italic []bool
+	for _, tokens := range hooks.HighlightLines(element) {
+		italic = append(italic, tokens[0].Style.Italic)
+	}
+	if !slices.Equal(italic, []bool{true, true, false}) {
+		t.Errorf("italic = %v", italic)
+	}
+
+	// Each raw text gets a highlighter of its own, so an unterminated
+	// comment does not leak into the next one.
+	open := &RawElem{Text: "/* a", Lang: "c", Block: true}
+	next := &RawElem{Text: "b", Lang: "c", Block: true}
+	for _, elem := range []*RawElem{open, next} {
+		if err := elem.Synthesize(foundations.NewStyleChain(nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hooks.HighlightLines(open)
+	if tokens := hooks.HighlightLines(next); tokens[0][0].Style.Italic {
+		t.Error("expected highlighter state to be reset between raw texts")
+	}
+}
+
+func TestHighlighterFunc(t *testing.T) {
+	hooks := NewHighlightHooks()
+	var seen []string
+	hooks.Register(HighlighterFunc(func(lang, line string) []Token {
+		seen = append(seen, lang+":"+line)
+		return []Token{{Text: line, Style: HighlightStyle{Color: "ff0000", Underline: true}}}
+	}), "mini")
+
+	element := &RawElem{Text: "a b\nc", Lang: "mini"}
+	if err := element.Synthesize(foundations.NewStyleChain(nil)); err != nil {
+		t.Fatal(err)
+	}
+	element.Highlight(hooks)
+	if !slices.Equal(seen, []string{"mini:a b", "mini:c"}) {
+		t.Errorf("tokenized lines = %v", seen)
+	}
+
+	// Inline raw text is still split at spaces, with each fragment styled.
+	elements := element.Lines[0].Body.Elements
+	if len(elements) != 3 {
+		t.Fatalf("expected 3 fragments, got %d", len(elements))
+	}
+	for _, elem := range elements {
+		text := elem.(*TextElem)
+		if text.Fill != NewRGB(255, 0, 0) || text.Underline == nil || !slices.Equal(text.Font, RawFonts) {
+			t.Errorf("fragment %q not styled: %+v", text.Body, text)
+		}
 	}
 }
 
@@ -3,7 +3,23 @@
 
 package text
 
-import "github.com/boergens/gotypst/library/foundations"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
+	"github.com/rivo/uniseg"
+)
+
+// DefaultRawTabSize is the default size of a tab stop in spaces.
+const DefaultRawTabSize = 2
+
+// RawFonts are the font families raw text is set in.
+var RawFonts = []string{"DejaVu Sans Mono", "monospace"}
 
 // RawElem represents raw text with optional syntax highlighting.
 //
@@ -14,41 +30,447 @@ import "github.com/boergens/gotypst/library/foundations"
 type RawElem struct {
 	// Text is the raw text content.
 	// Required field.
-	Text string
+	Text string `typst:"text,positional,required,type=str"`
 
 	// Block indicates whether the raw text is displayed as a separate block.
 	// In markup mode, using one-backtick notation makes this false.
 	// Using three-backtick notation makes it true if the enclosed content
 	// contains at least one line break.
 	// Default: false
-	Block bool
+	Block bool `typst:"block,type=bool"`
 
 	// Lang is the language to syntax-highlight in.
 	// Empty string means no syntax highlighting.
-	Lang string
+	Lang string `typst:"lang,type=str"`
 
 	// Align is the horizontal alignment for each line in a raw block.
 	// Default: "start"
 	Align string
+
+	// TabSize is the size of a tab stop in spaces. Tabs in the text are
+	// replaced by spaces up to the next tab stop. If nil, it is taken from
+	// set rules.
+	// Default: 2
+	TabSize *int64 `typst:"tab-size,type=int"`
+
+	// LineNumbers shows the number of each line in front of it. If nil, it
+	// is taken from set rules.
+	// Default: false
+	LineNumbers *bool `typst:"line-numbers,type=bool"`
+
+	// BreakWords lets inline raw text break within words when a line is
+	// full, not only at spaces. If nil, it is taken from set rules.
+	// Default: false
+	BreakWords *bool `typst:"break-words,type=bool"`
+
+	// Syntaxes are additional syntax definitions in the .sublime-syntax
+	// format: a path, the bytes of a file, or an array of either. They take
+	// precedence over the built-in highlighters for their languages.
+	Syntaxes foundations.Value `typst:"syntaxes"`
+
+	// Theme is the theme to highlight with in the .tmTheme format, as a
+	// path or bytes. With auto or nil, the default theme is used, and with
+	// none, the text is not colored.
+	Theme foundations.Value `typst:"theme"`
+
+	// SyntaxDefs are the parsed Syntaxes.
+	// Synthesized field.
+	SyntaxDefs []*SyntaxDefinition
+
+	// ThemeDef is the parsed Theme.
+	// Synthesized field.
+	ThemeDef *Theme
+
+	// Lines are the lines of the text, with tabs expanded.
+	// Synthesized field.
+	Lines []*RawLineElem
+
+	foundations.ElemMeta
 }
 
 func (*RawElem) IsContentElement() {}
 
+// PlainText writes the raw text.
+func (e *RawElem) PlainText(b *strings.Builder) { b.WriteString(e.Text) }
+
+// RawDef is the registered element definition for raw.
+var RawDef = foundations.RegisterElement[RawElem]("raw", nil)
+
+// RawFunc creates the raw element function.
+func RawFunc() *foundations.Func {
+	name := "raw"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: rawNative,
+			Info: RawDef.ToFuncInfo(),
+		},
+	}
+}
+
+// rawNative implements the raw() function. Syntaxes and themes given as
+// paths are read through the world, relative to the calling file.
+func rawNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	syntaxesSpan, themeSpan := namedSpan(args, "syntaxes"), namedSpan(args, "theme")
+	elem, err := foundations.ParseElement[RawElem](RawDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if elem.SyntaxDefs, err = loadRawSyntaxes(engine.World, syntaxesSpan, elem.Syntaxes); err != nil {
+		return nil, err
+	}
+	if elem.ThemeDef, err = loadRawTheme(engine.World, themeSpan, elem.Theme); err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// namedSpan returns the span of a named argument, falling back to the span
+// of the call.
+func namedSpan(args *foundations.Args, name string) syntax.Span {
+	for _, item := range args.Items {
+		if item.Name != nil && string(*item.Name) == name {
+			return item.Value.Span
+		}
+	}
+	return args.Span
+}
+
+// loadRawSyntaxes parses the syntax definitions of a path, bytes, or an
+// array of either.
+func loadRawSyntaxes(world foundations.World, span syntax.Span, value foundations.Value) ([]*SyntaxDefinition, error) {
+	var sources []foundations.Value
+	switch v := value.(type) {
+	case nil, foundations.NoneValue:
+		return nil, nil
+	case *foundations.Array:
+		sources = v.Items()
+	default:
+		sources = []foundations.Value{v}
+	}
+
+	defs := make([]*SyntaxDefinition, 0, len(sources))
+	for _, source := range sources {
+		data, err := loadRawSource(world, span, source, "syntax")
+		if err != nil {
+			return nil, err
+		}
+		def, err := LoadSyntax(data)
+		if err != nil {
+			return nil, foundations.NewSourceError(span, fmt.Sprintf("failed to parse syntax (%v)", err))
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// loadRawTheme parses the theme of a path or bytes. Auto and none have no
+// theme to load.
+func loadRawTheme(world foundations.World, span syntax.Span, value foundations.Value) (*Theme, error) {
+	switch value.(type) {
+	case nil, foundations.AutoValue, foundations.NoneValue:
+		return nil, nil
+	}
+	data, err := loadRawSource(world, span, value, "theme")
+	if err != nil {
+		return nil, err
+	}
+	theme, err := LoadTheme(data)
+	if err != nil {
+		return nil, foundations.NewSourceError(span, fmt.Sprintf("failed to parse theme (%v)", err))
+	}
+	return theme, nil
+}
+
+// loadRawSource returns the bytes of a source, reading a path relative to
+// the file the span is in.
+func loadRawSource(world foundations.World, span syntax.Span, source foundations.Value, what string) ([]byte, error) {
+	switch v := source.(type) {
+	case foundations.BytesValue:
+		return v, nil
+	case foundations.Str:
+		id := span.Id()
+		if world == nil || id == nil {
+			return nil, foundations.NewSourceError(span, "cannot access file system from here")
+		}
+		file, err := id.Join(string(v))
+		if err != nil {
+			return nil, foundations.NewSourceError(span, fmt.Sprintf("invalid path %q: %v", string(v), err))
+		}
+		data, err := world.File(file)
+		if err != nil {
+			return nil, foundations.NewSourceError(span, fmt.Sprintf("failed to load %s (%v)", what, err))
+		}
+		return data, nil
+	}
+	return nil, &foundations.TypeMismatchError{Expected: "string or bytes", Got: source.Type().String(), Field: what, Span: span}
+}
+
+// Synthesize fills in the tab size, line numbers, syntaxes, and theme from
+// `set raw(..)` rules where they were not given, and splits the text into
+// lines. Set rules have no file to resolve paths against, so their
+// syntaxes and themes must be given as bytes.
+// Matches Rust: impl Synthesize for Packed<RawElem>
+func (e *RawElem) Synthesize(styles *foundations.StyleChain) error {
+	if e.TabSize == nil {
+		size := styles.GetInt("raw", "tab-size", DefaultRawTabSize)
+		e.TabSize = &size
+	}
+	if e.LineNumbers == nil {
+		numbers := styles.GetBool("raw", "line-numbers", false)
+		e.LineNumbers = &numbers
+	}
+	if e.BreakWords == nil {
+		breakWords := styles.GetBool("raw", "break-words", false)
+		e.BreakWords = &breakWords
+	}
+	if e.Syntaxes == nil {
+		var err error
+		e.Syntaxes = styles.Get("raw", "syntaxes")
+		if e.SyntaxDefs, err = loadRawSyntaxes(nil, syntax.Detached(), e.Syntaxes); err != nil {
+			return err
+		}
+	}
+	if e.Theme == nil {
+		var err error
+		e.Theme = styles.Get("raw", "theme")
+		if e.ThemeDef, err = loadRawTheme(nil, syntax.Detached(), e.Theme); err != nil {
+			return err
+		}
+	}
+	e.Lines = RawLines(e.Text, int(*e.TabSize))
+	if !e.Block {
+		for _, line := range e.Lines {
+			line.Body = InlineRawBody(line.Text, *e.BreakWords)
+		}
+	}
+	return nil
+}
+
+// Highlight styles the lines of the element with the tokens of the
+// highlighter the hooks provide for its language. The lines are streamed
+// through the highlighter one by one, and each token becomes a text element
+// of its own; inline raw text is further split at spaces like its unstyled
+// lines are. Without a highlighter, the lines are left as they are.
+// Synthesize must be called first.
+func (e *RawElem) Highlight(hooks *HighlightHooks) {
+	lines := hooks.HighlightLines(e)
+	if lines == nil {
+		return
+	}
+	breakWords := e.BreakWords != nil && *e.BreakWords
+	for i, tokens := range lines {
+		if tokens == nil {
+			continue
+		}
+		var elements []foundations.ContentElement
+		for _, token := range tokens {
+			if e.Block {
+				elements = append(elements, styledRawText(token.Text, token.Style))
+			} else {
+				elements = inlineRawFragments(elements, token.Text, breakWords, token.Style)
+			}
+		}
+		if len(elements) == 0 {
+			elements = append(elements, rawText(""))
+		}
+		e.Lines[i].Body = foundations.Content{Elements: elements}
+	}
+}
+
+// Show realizes the element as its lines separated by line breaks. Each
+// line is a RawLineElem, so that `show raw.line` rules can style lines
+// individually. With line numbers, each line is preceded by its number,
+// right-aligned to the widest one. Synthesize must be called first.
+// Matches Rust: impl Show for Packed<RawElem>
+func (e *RawElem) Show() foundations.Content {
+	numbers := e.LineNumbers != nil && *e.LineNumbers
+	width := len(strconv.Itoa(len(e.Lines)))
+
+	var elements []foundations.ContentElement
+	for i, line := range e.Lines {
+		if i > 0 {
+			elements = append(elements, &model.LinebreakElem{})
+		}
+		if numbers {
+			elements = append(elements, &TextElem{Body: fmt.Sprintf("%*d  ", width, line.Number)})
+		}
+		elements = append(elements, line)
+	}
+	return foundations.Content{Elements: elements}
+}
+
+// RawLines splits raw text into its lines, with tabs replaced by spaces up
+// to the next tab stop.
+func RawLines(text string, tabSize int) []*RawLineElem {
+	if strings.ContainsRune(text, '\t') {
+		text = alignTabs(text, tabSize)
+	}
+	parts := splitNewlines(text)
+	lines := make([]*RawLineElem, len(parts))
+	for i, part := range parts {
+		lines[i] = &RawLineElem{
+			Number: i + 1,
+			Count:  len(parts),
+			Text:   part,
+			Body:   foundations.Content{Elements: []foundations.ContentElement{rawText(part)}},
+		}
+	}
+	return lines
+}
+
+// InlineRawBody returns the body of a line of inline raw text, which can
+// break across lines. Each word and each run of spaces becomes its own
+// monospace text element, so that the line breaker may break between them
+// and styles apply to each fragment of a broken line on its own. With
+// breakWords, the words may also break between any two grapheme clusters.
+// Matches Rust: the inline case of impl Show for Packed<RawElem>
+func InlineRawBody(text string, breakWords bool) foundations.Content {
+	elements := inlineRawFragments(nil, text, breakWords, HighlightStyle{})
+	if len(elements) == 0 {
+		elements = append(elements, rawText(""))
+	}
+	return foundations.Content{Elements: elements}
+}
+
+// inlineRawFragments appends the fragments of inline raw text, each in the
+// given style, to elements.
+func inlineRawFragments(elements []foundations.ContentElement, text string, breakWords bool, style HighlightStyle) []foundations.ContentElement {
+	for len(text) > 0 {
+		end := strings.IndexByte(text, ' ')
+		if end == 0 {
+			end = len(text) - len(strings.TrimLeft(text, " "))
+		} else if end < 0 {
+			end = len(text)
+		}
+		fragment := text[:end]
+		text = text[end:]
+		if breakWords && fragment[0] != ' ' {
+			fragment = breakableWord(fragment)
+		}
+		elements = append(elements, styledRawText(fragment, style))
+	}
+	return elements
+}
+
+// breakableWord inserts zero-width spaces between the grapheme clusters of
+// a word, which allow line breaks without widening it.
+func breakableWord(word string) string {
+	var b strings.Builder
+	state := -1
+	for len(word) > 0 {
+		var cluster string
+		cluster, word, _, state = uniseg.FirstGraphemeClusterInString(word, state)
+		b.WriteString(cluster)
+		if len(word) > 0 {
+			b.WriteString("\u200b")
+		}
+	}
+	return b.String()
+}
+
+// rawText creates a text element of raw text, in a monospace font and
+// without hyphenation.
+func rawText(body string) *TextElem {
+	hyphenate := false
+	return &TextElem{Body: body, Font: RawFonts, Hyphenate: &hyphenate}
+}
+
+// styledRawText creates a text element of raw text in the style of a
+// highlighted token.
+func styledRawText(body string, style HighlightStyle) *TextElem {
+	elem := rawText(body)
+	if style.Color != "" {
+		if color, err := ColorFromHex(style.Color); err == nil {
+			elem.Fill = color
+		}
+	}
+	if style.Bold {
+		elem.Weight = FontWeightBold
+	}
+	if style.Italic {
+		elem.Style = FontStyleItalic
+	}
+	if style.Underline {
+		elem.Underline = &Underline{}
+	}
+	return elem
+}
+
+// alignTabs replaces each tab with the spaces up to the next tab stop,
+// counting columns in grapheme clusters.
+// Matches Rust: fn align_tabs in text/raw.rs
+func alignTabs(text string, tabSize int) string {
+	divisor := max(tabSize, 1)
+	var b strings.Builder
+	b.Grow(len(text))
+	column := 0
+	state := -1
+	for len(text) > 0 {
+		var cluster string
+		cluster, text, _, state = uniseg.FirstGraphemeClusterInString(text, state)
+		switch cluster {
+		case "\t":
+			required := tabSize - column%divisor
+			b.WriteString(strings.Repeat(" ", required))
+			column += required
+		case "\n":
+			b.WriteString(cluster)
+			column = 0
+		default:
+			b.WriteString(cluster)
+			column++
+		}
+	}
+	return b.String()
+}
+
+// splitNewlines splits text at newlines, treating CRLF as one newline.
+// Matches Rust: fn split_newlines in typst-syntax
+func splitNewlines(text string) []string {
+	var lines []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !syntax.IsNewline(r) {
+			i += size
+			continue
+		}
+		lines = append(lines, text[start:i])
+		i += size
+		if r == '\r' && i < len(text) && text[i] == '\n' {
+			i++
+		}
+		start = i
+	}
+	return append(lines, text[start:])
+}
+
 // RawLineElem represents a single line of raw text.
 // Used for custom styling of individual lines via show rules.
 // Corresponds to Rust's RawLine in text/raw.rs.
 type RawLineElem struct {
 	// Number is the line number (1-indexed).
-	Number int
+	Number int `typst:"number,type=int"`
 
 	// Count is the total number of lines.
-	Count int
+	Count int `typst:"count,type=int"`
 
 	// Text is the line content.
-	Text string
+	Text string `typst:"text,type=str"`
 
 	// Body is the styled line content.
-	Body foundations.Content
+	Body foundations.Content `typst:"body,positional,type=content"`
 }
 
 func (*RawLineElem) IsContentElement() {}
+
+// PlainText writes the line's text.
+func (e *RawLineElem) PlainText(b *strings.Builder) { b.WriteString(e.Text) }
+
+// RawLineDef is the registered element definition for raw.line, which
+// gives the element its name in show rule selectors.
+var RawLineDef = foundations.RegisterElement[RawLineElem]("raw.line", nil)
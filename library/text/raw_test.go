@@ -1,18 +1,20 @@
 package text
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
 	"github.com/boergens/gotypst/syntax"
 )
 
 func TestRawFunc(t *testing.T) {
 	rawFunc := RawFunc()
-	if rawFunc.Func == nil {
+	if rawFunc == nil {
 		t.Fatal("RawFunc returned nil Func")
 	}
-	if rawFunc.Func.Name == nil || *rawFunc.Func.Name != "raw" {
+	if rawFunc.Name == nil || *rawFunc.Name != "raw" {
 		t.Error("RawFunc should have name 'raw'")
 	}
 }
@@ -20,7 +22,7 @@ func TestRawFunc(t *testing.T) {
 func TestRawImpl(t *testing.T) {
 	tests := []struct {
 		name     string
-		args     *eval.Args
+		args     *foundations.Args
 		wantText string
 		wantLang string
 		wantBlock bool
@@ -29,7 +31,7 @@ func TestRawImpl(t *testing.T) {
 		{
 			name: "simple text",
 			args: makeArgs(
-				positionalArg(eval.StrValue("hello world")),
+				positionalArg(foundations.Str("hello world")),
 			),
 			wantText: "hello world",
 			wantLang: "",
@@ -38,8 +40,8 @@ func TestRawImpl(t *testing.T) {
 		{
 			name: "text with lang",
 			args: makeArgs(
-				positionalArg(eval.StrValue("fn main() {}")),
-				namedArg("lang", eval.StrValue("rust")),
+				positionalArg(foundations.Str("fn main() {}")),
+				namedArg("lang", foundations.Str("rust")),
 			),
 			wantText: "fn main() {}",
 			wantLang: "rust",
@@ -48,9 +50,9 @@ func TestRawImpl(t *testing.T) {
 		{
 			name: "block code",
 			args: makeArgs(
-				positionalArg(eval.StrValue("print('hello')")),
-				namedArg("lang", eval.StrValue("python")),
-				namedArg("block", eval.True),
+				positionalArg(foundations.Str("print('hello')")),
+				namedArg("lang", foundations.Str("python")),
+				namedArg("block", foundations.Bool(true)),
 			),
 			wantText: "print('hello')",
 			wantLang: "python",
@@ -59,7 +61,7 @@ func TestRawImpl(t *testing.T) {
 		{
 			name: "multiline code",
 			args: makeArgs(
-				positionalArg(eval.StrValue("line1\nline2\nline3")),
+				positionalArg(foundations.Str("line1\nline2\nline3")),
 			),
 			wantText: "line1\nline2\nline3",
 			wantLang: "",
@@ -73,7 +75,7 @@ func TestRawImpl(t *testing.T) {
 		{
 			name: "wrong type for text",
 			args: makeArgs(
-				positionalArg(eval.IntValue(42)),
+				positionalArg(foundations.Int(42)),
 			),
 			wantErr: true,
 		},
@@ -81,7 +83,7 @@ func TestRawImpl(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := rawImpl(nil, nil, tt.args)
+			result, err := rawNative(foundations.Engine{}, foundations.Context{}, tt.args)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -92,14 +94,14 @@ func TestRawImpl(t *testing.T) {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
-			content, ok := result.(eval.ContentValue)
+			content, ok := result.(foundations.ContentValue)
 			if !ok {
 				t.Fatalf("expected ContentValue, got %T", result)
 			}
 			if len(content.Content.Elements) != 1 {
 				t.Fatalf("expected 1 element, got %d", len(content.Content.Elements))
 			}
-			raw, ok := content.Content.Elements[0].(*eval.RawElement)
+			raw, ok := content.Content.Elements[0].(*RawElem)
 			if !ok {
 				t.Fatalf("expected RawElement, got %T", content.Content.Elements[0])
 			}
@@ -172,46 +174,170 @@ func TestRawLines(t *testing.T) {
 	}{
 		{text: "single line", count: 1},
 		{text: "line1\nline2", count: 2},
-		{text: "line1\nline2\nline3", count: 3},
-		{text: "", count: 1}, // Empty string produces one empty line
+		{text: "line1\r\nline2\rline3", count: 3},
+		{text: "", count: 1},   // Empty string produces one empty line
 		{text: "\n", count: 2}, // Trailing newline produces empty line
 	}
 
 	for _, tt := range tests {
-		element := &eval.RawElement{Text: tt.text}
-		result := RawLines(element)
-		arr, ok := result.(eval.ArrayValue)
-		if !ok {
-			t.Fatalf("RawLines() returned %T, want ArrayValue", result)
+		lines := RawLines(tt.text, DefaultRawTabSize)
+		if len(lines) != tt.count {
+			t.Errorf("RawLines(%q) has %d lines, want %d", tt.text, len(lines), tt.count)
 		}
-		if len(arr) != tt.count {
-			t.Errorf("RawLines(%q) has %d lines, want %d", tt.text, len(arr), tt.count)
+		for i, line := range lines {
+			if line.Number != i+1 || line.Count != tt.count {
+				t.Errorf("RawLines(%q) line %d = %d of %d", tt.text, i, line.Number, line.Count)
+			}
+		}
+	}
+}
+
+func TestRawLinesTabs(t *testing.T) {
+	tests := []struct {
+		text    string
+		tabSize int
+		want    []string
+	}{
+		{"\tx", 2, []string{"  x"}},
+		{"a\tb", 4, []string{"a   b"}},
+		{"ab\tc\n\td", 2, []string{"ab  c", "  d"}},
+		{"é\tx", 2, []string{"é x"}},
+		{"a\tb", 0, []string{"ab"}},
+	}
+	for _, tt := range tests {
+		lines := RawLines(tt.text, tt.tabSize)
+		if len(lines) != len(tt.want) {
+			t.Fatalf("RawLines(%q) has %d lines, want %d", tt.text, len(lines), len(tt.want))
+		}
+		for i, line := range lines {
+			if line.Text != tt.want[i] {
+				t.Errorf("RawLines(%q, %d) line %d = %q, want %q", tt.text, tt.tabSize, i, line.Text, tt.want[i])
+			}
 		}
 	}
 }
 
+func TestRawShow(t *testing.T) {
+	numbers := true
+	elem := &RawElem{Text: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj", LineNumbers: &numbers}
+	if err := elem.Synthesize(foundations.EmptyStyleChain()); err != nil {
+		t.Fatal(err)
+	}
+	if *elem.TabSize != DefaultRawTabSize {
+		t.Errorf("TabSize = %d, want %d", *elem.TabSize, DefaultRawTabSize)
+	}
+
+	content := elem.Show()
+	// Ten lines, each with a number, and nine line breaks between them.
+	if got := len(content.Elements); got != 29 {
+		t.Fatalf("Show produced %d elements, want 29", got)
+	}
+	if num, ok := content.Elements[0].(*TextElem); !ok || num.Body != " 1  " {
+		t.Errorf("first number = %#v, want padded 1", content.Elements[0])
+	}
+	line, ok := content.Elements[1].(*RawLineElem)
+	if !ok || line.Number != 1 || line.Text != "a" {
+		t.Errorf("first line = %#v", content.Elements[1])
+	}
+	if name := foundations.ElementName(line); name != "raw.line" {
+		t.Errorf("line element is named %q, want raw.line", name)
+	}
+	if got := content.PlainText(); got != " 1  a\n 2  b\n 3  c\n 4  d\n 5  e\n 6  f\n 7  g\n 8  h\n 9  i\n10  j" {
+		t.Errorf("plain text = %q", got)
+	}
+}
+
+func TestInlineRawBody(t *testing.T) {
+	bodies := func(content foundations.Content) []string {
+		var out []string
+		for _, elem := range content.Elements {
+			text := elem.(*TextElem)
+			if len(text.Font) == 0 || text.Font[0] != RawFonts[0] || text.Hyphenate == nil || *text.Hyphenate {
+				t.Errorf("fragment %q is not monospace without hyphenation", text.Body)
+			}
+			out = append(out, text.Body)
+		}
+		return out
+	}
+
+	got := bodies(InlineRawBody("let  x = 1", false))
+	if want := []string{"let", "  ", "x", " ", "=", " ", "1"}; !slices.Equal(got, want) {
+		t.Errorf("fragments = %q, want %q", got, want)
+	}
+	got = bodies(InlineRawBody("abc é", true))
+	if want := []string{"a\u200bb\u200bc", " ", "é"}; !slices.Equal(got, want) {
+		t.Errorf("breakable fragments = %q, want %q", got, want)
+	}
+	if got := bodies(InlineRawBody("", false)); len(got) != 1 || got[0] != "" {
+		t.Errorf("empty fragments = %q", got)
+	}
+
+	elem := &RawElem{Text: "a b\nc"}
+	if err := elem.Synthesize(foundations.EmptyStyleChain()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(elem.Lines[0].Body.Elements); got != 3 {
+		t.Errorf("inline line has %d fragments, want 3", got)
+	}
+	if got := elem.Show().PlainText(); got != "a b\nc" {
+		t.Errorf("plain text = %q", got)
+	}
+	elem = &RawElem{Text: "a b", Block: true}
+	if err := elem.Synthesize(foundations.EmptyStyleChain()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(elem.Lines[0].Body.Elements); got != 1 {
+		t.Errorf("block line has %d fragments, want 1", got)
+	}
+}
+
+func TestRawSynthesizeFromStyles(t *testing.T) {
+	name := "raw"
+	key := foundations.Str("tab-size")
+	args := &foundations.Args{Items: []foundations.Arg{{
+		Name:  &key,
+		Value: syntax.Spanned[foundations.Value]{V: foundations.Int(4)},
+	}}}
+	styles := foundations.NewStyles()
+	styles.AddRule(foundations.StyleRule{Func: &foundations.Func{Name: &name}, Args: args})
+
+	elem := &RawElem{Text: "\tx"}
+	if err := elem.Synthesize(foundations.NewStyleChain(styles)); err != nil {
+		t.Fatal(err)
+	}
+	if got := elem.Lines[0].Text; got != "    x" {
+		t.Errorf("line = %q, want four spaces of indent", got)
+	}
+	if *elem.LineNumbers {
+		t.Error("line numbers should be off by default")
+	}
+	if *elem.BreakWords {
+		t.Error("breaking within words should be off by default")
+	}
+}
+
 // Helper functions for creating test arguments
 
-func makeArgs(args ...eval.Arg) *eval.Args {
-	return &eval.Args{
+func makeArgs(args ...foundations.Arg) *foundations.Args {
+	return &foundations.Args{
 		Span:  syntax.Detached(),
 		Items: args,
 	}
 }
 
-func positionalArg(v eval.Value) eval.Arg {
-	return eval.Arg{
+func positionalArg(v foundations.Value) foundations.Arg {
+	return foundations.Arg{
 		Span:  syntax.Detached(),
 		Name:  nil,
-		Value: syntax.Spanned[eval.Value]{V: v, Span: syntax.Detached()},
+		Value: syntax.Spanned[foundations.Value]{V: v, Span: syntax.Detached()},
 	}
 }
 
-func namedArg(name string, v eval.Value) eval.Arg {
-	n := name
-	return eval.Arg{
+func namedArg(name string, v foundations.Value) foundations.Arg {
+	n := foundations.Str(name)
+	return foundations.Arg{
 		Span:  syntax.Detached(),
 		Name:  &n,
-		Value: syntax.Spanned[eval.Value]{V: v, Span: syntax.Detached()},
+		Value: syntax.Spanned[foundations.Value]{V: v, Span: syntax.Detached()},
 	}
 }
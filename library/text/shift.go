@@ -0,0 +1,94 @@
+// Superscripts and subscripts.
+// Translated from typst-library/src/text/shift.rs
+
+package text
+
+import (
+	"github.com/boergens/gotypst/layout/inline"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// SuperFunc creates the super function, which sets its content as
+// superscript.
+func SuperFunc() *foundations.Func {
+	return shiftFunc("super", inline.ScriptSuper)
+}
+
+// SubFunc creates the sub function, which sets its content as subscript.
+func SubFunc() *foundations.Func {
+	return shiftFunc("sub", inline.ScriptSub)
+}
+
+// shiftFunc creates a function that sets its content as a script. With
+// `typographic: true`, the default, the script glyphs of the font are used
+// if it has them. Otherwise, the text is scaled down and shifted, with a
+// warning if typographic scripts were requested.
+// Matches Rust: SuperElem and SubElem in text/shift.rs
+func shiftFunc(name string, kind inline.ScriptKind) *foundations.Func {
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				script := inline.ScriptShift{Kind: kind, Typographic: true}
+				if arg := args.Named("typographic"); arg != nil {
+					typographic, ok := arg.V.(foundations.Bool)
+					if !ok {
+						return nil, &foundations.TypeMismatchError{Expected: "bool", Got: arg.V.Type().String(), Field: "typographic", Span: arg.Span}
+					}
+					script.Typographic = bool(typographic)
+				}
+				if arg := args.Named("baseline"); arg != nil {
+					baseline, err := parseAutoLength(arg, "baseline")
+					if err != nil {
+						return nil, err
+					}
+					script.Baseline = baseline
+				}
+				if arg := args.Named("size"); arg != nil {
+					size, err := parseAutoLength(arg, "size")
+					if err != nil {
+						return nil, err
+					}
+					if size != nil {
+						script.Size = *size
+					}
+				}
+				body, err := expectContent(args, "body")
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				return foundations.ContentValue{Content: mapText(body, func(t *TextElem) {
+					shift := script
+					t.Script = &shift
+				})}, nil
+			},
+			Info: &foundations.FuncInfo{
+				Name: name,
+				Params: []foundations.ParamInfo{
+					{Name: "typographic", Type: foundations.TypeBool, Named: true, Default: foundations.Bool(true)},
+					{Name: "baseline", Type: foundations.TypeDyn, Named: true, Default: foundations.AutoValue{}},
+					{Name: "size", Type: foundations.TypeDyn, Named: true, Default: foundations.AutoValue{}},
+					{Name: "body", Type: foundations.TypeContent},
+				},
+			},
+		},
+	}
+}
+
+// parseAutoLength parses an argument that is auto, giving nil, or a length.
+func parseAutoLength(arg *syntax.Spanned[foundations.Value], field string) (*inline.Abs, error) {
+	if _, ok := arg.V.(foundations.AutoValue); ok {
+		return nil, nil
+	}
+	size, err := parseLength(arg.V, field)
+	if err != nil {
+		return nil, err
+	}
+	abs := size.ToAbs()
+	return &abs, nil
+}
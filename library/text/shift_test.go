@@ -0,0 +1,64 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/boergens/gotypst/layout/inline"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestSmallcapsFunc(t *testing.T) {
+	content := foundations.Content{Elements: []foundations.ContentElement{
+		New("Hello "),
+		&model.StrongElem{Body: Packed("World")},
+	}}
+	args := foundations.NewArgs(syntax.Detached(), foundations.ContentValue{Content: content})
+	value, err := SmallcapsFunc().Call(&foundations.Engine{}, foundations.NewContext(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := value.(foundations.ContentValue).Content
+	strong := got.Elements[1].(*model.StrongElem)
+	if !got.Elements[0].(*TextElem).SmallCaps || !strong.Body.Elements[0].(*TextElem).SmallCaps {
+		t.Error("smallcaps did not enable small capitals")
+	}
+	if content.Elements[0].(*TextElem).SmallCaps {
+		t.Error("smallcaps changed its argument")
+	}
+}
+
+func TestShiftFuncs(t *testing.T) {
+	call := func(f *foundations.Func, args *foundations.Args) *inline.ScriptShift {
+		t.Helper()
+		value, err := f.Call(&foundations.Engine{}, foundations.NewContext(), args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value.(foundations.ContentValue).Content.Elements[0].(*TextElem).Script
+	}
+
+	script := call(SuperFunc(), foundations.NewArgs(syntax.Detached(), foundations.Str("2")))
+	if script == nil || script.Kind != inline.ScriptSuper || !script.Typographic || script.Size != 0 || script.Baseline != nil {
+		t.Errorf("super = %+v", script)
+	}
+
+	named := func(args *foundations.Args, name foundations.Str, value foundations.Value) {
+		args.Items = append(args.Items, foundations.Arg{Name: &name, Value: syntax.NewSpanned(value, syntax.Detached())})
+	}
+	args := foundations.NewArgs(syntax.Detached(), foundations.Str("2"))
+	named(args, "typographic", foundations.Bool(false))
+	named(args, "baseline", foundations.LengthValue{Length: foundations.Length{Points: 2}})
+	named(args, "size", foundations.LengthValue{Length: foundations.Length{Points: 6}})
+	script = call(SubFunc(), args)
+	if script.Kind != inline.ScriptSub || script.Typographic || script.Size != 6 || script.Baseline == nil || *script.Baseline != 2 {
+		t.Errorf("sub = %+v", script)
+	}
+
+	args = foundations.NewArgs(syntax.Detached(), foundations.Str("2"))
+	named(args, "size", foundations.Int(1))
+	if _, err := SubFunc().Call(&foundations.Engine{}, foundations.NewContext(), args); err == nil {
+		t.Error("sub(size: 1): expected an error")
+	}
+}
@@ -0,0 +1,53 @@
+// Small capitals.
+// Translated from typst-library/src/text/smallcaps.rs
+
+package text
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// SmallcapsFunc creates the smallcaps function, which displays the
+// lowercase letters of its content as small capitals. Fonts without small
+// capitals get synthesized ones during shaping, with a warning.
+// Matches Rust: SmallcapsElem in text/smallcaps.rs
+func SmallcapsFunc() *foundations.Func {
+	name := "smallcaps"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				body, err := expectContent(args, "body")
+				if err != nil {
+					return nil, err
+				}
+				if err := args.Finish(); err != nil {
+					return nil, err
+				}
+				return foundations.ContentValue{Content: mapText(body, func(t *TextElem) { t.SmallCaps = true })}, nil
+			},
+			Info: &foundations.FuncInfo{
+				Name:   name,
+				Params: []foundations.ParamInfo{{Name: "body", Type: foundations.TypeContent}},
+			},
+		},
+	}
+}
+
+// expectContent takes a required positional argument that is content or a
+// string, which becomes text.
+func expectContent(args *foundations.Args, what string) (foundations.Content, error) {
+	arg, err := args.Expect(what)
+	if err != nil {
+		return foundations.Content{}, err
+	}
+	switch v := arg.V.(type) {
+	case foundations.ContentValue:
+		return v.Content, nil
+	case foundations.Str:
+		return Packed(string(v)), nil
+	}
+	return foundations.Content{}, &foundations.TypeMismatchError{Expected: "content", Got: arg.V.Type().String(), Field: what, Span: arg.Span}
+}
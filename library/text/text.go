@@ -6,7 +6,11 @@
 package text
 
 import (
+	"strings"
+
 	"github.com/boergens/gotypst/layout/inline"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
 )
 
 // TextElem represents a text element with styling properties.
@@ -39,14 +43,25 @@ type TextElem struct {
 	// If nil, text is not stroked.
 	Stroke *Stroke
 
-	// Tracking adjusts spacing between characters (in em units).
-	Tracking Em
+	// Tracking is the extra spacing between characters.
+	Tracking Size
 
-	// Spacing adjusts word spacing as a ratio (1.0 = 100%).
+	// Spacing scales the width of spaces as a ratio (1.0 = 100%).
 	Spacing float64
 
-	// Baseline shifts the text baseline (in em units).
-	Baseline Em
+	// SpacingAbs is added to the width of spaces after scaling, so that
+	// `spacing: 100% + 1pt` widens each space by one point.
+	SpacingAbs Size
+
+	// Baseline shifts the text baseline. Positive values move text down.
+	Baseline Size
+
+	// TopEdge is the top edge of the text, which determines line heights.
+	// Default is the cap height.
+	TopEdge TopEdge
+
+	// BottomEdge is the bottom edge of the text. Default is the baseline.
+	BottomEdge BottomEdge
 
 	// Underline controls the underline decoration.
 	Underline *Underline
@@ -72,6 +87,15 @@ type TextElem struct {
 	// Fallback enables font fallback for missing glyphs.
 	Fallback bool
 
+	// CJKLatinSpacing adds a quarter em of spacing between CJK and Latin
+	// characters. True corresponds to `cjk-latin-spacing: auto`, false to
+	// `none`.
+	CJKLatinSpacing bool
+
+	// Overhang lets punctuation at the edges of lines hang into the
+	// margin, which makes justified text look straighter.
+	Overhang bool
+
 	// Features are OpenType font features to enable.
 	Features []string
 
@@ -95,19 +119,33 @@ type TextElem struct {
 
 	// SmallCaps enables small capitals.
 	SmallCaps bool
+
+	// Script sets the text as superscript or subscript, if not nil.
+	Script *inline.ScriptShift
+
+	foundations.ElemMeta
+}
+
+// defaultText holds the default values of a text element.
+var defaultText = TextElem{
+	Size:     SizeFromPt(11), // Default 11pt
+	Weight:   FontWeightNormal,
+	Style:    FontStyleNormal,
+	Stretch:  FontStretchNormal,
+	Spacing:  1.0, // 100%
+	Fallback: true,
+
+	TopEdge:         TopEdge{Metric: TopEdgeCapHeight},
+	BottomEdge:      BottomEdge{Metric: BottomEdgeBaseline},
+	CJKLatinSpacing: true,
+	Overhang:        true,
 }
 
 // New creates a new text element with default values.
 func New(body string) *TextElem {
-	return &TextElem{
-		Body:     body,
-		Size:     SizeFromPt(11), // Default 11pt
-		Weight:   FontWeightNormal,
-		Style:    FontStyleNormal,
-		Stretch:  FontStretchNormal,
-		Spacing:  1.0, // 100%
-		Fallback: true,
-	}
+	elem := defaultText
+	elem.Body = body
+	return &elem
 }
 
 // WithFont sets the font families.
@@ -179,6 +217,18 @@ func (t *TextElem) ToFontVariant() inline.FontVariant {
 	}
 }
 
+// ApplyShaping sets the tracking, the word spacing, the baseline shift,
+// the small caps, and the script of a shaping context from the text
+// element.
+func (t *TextElem) ApplyShaping(ctx *inline.ShapingContext) {
+	ctx.Tracking = t.Tracking.ToAbs()
+	ctx.Spacing = t.Spacing
+	ctx.SpaceAbs = t.SpacingAbs.ToAbs()
+	ctx.Shift = t.Baseline.ToAbs()
+	ctx.SmallCaps = t.SmallCaps
+	ctx.Script = t.Script
+}
+
 // HasDecoration returns true if the text has any decoration.
 func (t *TextElem) HasDecoration() bool {
 	return t.Underline != nil || t.Strikethrough != nil || t.Overline != nil
@@ -201,3 +251,26 @@ func (t *TextElem) Decorations() []Decoration {
 
 // IsContentElement marks TextElem as a content element.
 func (*TextElem) IsContentElement() {}
+
+// PlainText writes the text.
+func (t *TextElem) PlainText(b *strings.Builder) { b.WriteString(t.Body) }
+
+// Repr returns the text as markup, like `[Hello]`.
+func (t *TextElem) Repr() string { return "[" + t.Body + "]" }
+
+// Packed returns content holding a single text element with the body.
+// Matches Rust: TextElem::packed
+func Packed(body string) foundations.Content {
+	return foundations.Content{Elements: []foundations.ContentElement{New(body)}}
+}
+
+// PackedIn is like Packed, but allocates the element and its content in
+// the arena and attaches the span. Markup evaluation creates its text
+// this way.
+func PackedIn(arena *foundations.Arena, body string, span syntax.Span) foundations.Content {
+	elem := foundations.Alloc[TextElem](arena)
+	*elem = defaultText
+	elem.Body = body
+	elem.Span = span
+	return arena.Content(elem)
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/boergens/gotypst/layout/inline"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
 )
 
 func TestNewTextElem(t *testing.T) {
@@ -36,6 +38,80 @@ func TestNewTextElem(t *testing.T) {
 	if !te.Fallback {
 		t.Error("Fallback should be true by default")
 	}
+	if !te.CJKLatinSpacing {
+		t.Error("CJKLatinSpacing should be true by default")
+	}
+	if !te.Overhang {
+		t.Error("Overhang should be true by default")
+	}
+
+	if te.TopEdge.Metric != TopEdgeCapHeight || te.BottomEdge.Metric != BottomEdgeBaseline {
+		t.Errorf("edges = %v, %v; want cap-height, baseline", te.TopEdge.Metric, te.BottomEdge.Metric)
+	}
+}
+
+func TestParseEdges(t *testing.T) {
+	top, err := ParseTopEdge(foundations.Str("x-height"))
+	if err != nil || top.Metric != TopEdgeXHeight || top.Length != nil {
+		t.Errorf("ParseTopEdge(x-height) = %v, %v", top, err)
+	}
+	if got := top.ToInline(); got.Metric != inline.TopEdgeXHeight {
+		t.Errorf("ToInline metric = %v, want x-height", got.Metric)
+	}
+
+	top, err = ParseTopEdge(foundations.LengthValue{Length: foundations.Length{Points: 8}})
+	if err != nil || top.Length == nil || *top.Length != SizeFromPt(8) {
+		t.Fatalf("ParseTopEdge(8pt) = %v, %v", top, err)
+	}
+	if got := top.ToInline(); got.Length == nil || *got.Length != 8 {
+		t.Errorf("ToInline length = %v, want 8pt", got.Length)
+	}
+
+	if _, err := ParseTopEdge(foundations.Str("descender")); err == nil {
+		t.Error("expected error for descender as top edge")
+	}
+
+	bottom, err := ParseBottomEdge(foundations.Str("descender"))
+	if err != nil || bottom.Metric != BottomEdgeDescender {
+		t.Errorf("ParseBottomEdge(descender) = %v, %v", bottom, err)
+	}
+	if _, err := ParseBottomEdge(foundations.Int(1)); err == nil {
+		t.Error("expected error for integer bottom edge")
+	}
+}
+
+func TestParseShapingFields(t *testing.T) {
+	tracking, err := ParseTracking(foundations.LengthValue{Length: foundations.Length{Points: 1.5}})
+	if err != nil || tracking != SizeFromPt(1.5) {
+		t.Errorf("ParseTracking(1.5pt) = %v, %v", tracking, err)
+	}
+	if _, err := ParseBaseline(foundations.Int(1)); err == nil {
+		t.Error("expected error for integer baseline")
+	}
+
+	ratio, abs, err := ParseSpacing(foundations.RelativeValue{Relative: foundations.Relative{
+		Abs: foundations.Length{Points: 2},
+		Rel: foundations.Ratio{Value: 1.5},
+	}})
+	if err != nil || ratio != 1.5 || abs != SizeFromPt(2) {
+		t.Errorf("ParseSpacing(150%% + 2pt) = %v, %v, %v", ratio, abs, err)
+	}
+
+	te := New("a b")
+	te.Tracking, te.Spacing, te.SpacingAbs, te.Baseline = 1, ratio, abs, -3
+	ctx := inline.NewShapingContext(nil, 10)
+	te.ApplyShaping(ctx)
+	if ctx.Tracking != 1 || ctx.Spacing != 1.5 || ctx.SpaceAbs != 2 || ctx.Shift != -3 {
+		t.Errorf("ApplyShaping set tracking %v, spacing %v + %v, shift %v", ctx.Tracking, ctx.Spacing, ctx.SpaceAbs, ctx.Shift)
+	}
+	if ctx.SmallCaps || ctx.Script != nil {
+		t.Errorf("ApplyShaping set small caps %v, script %v", ctx.SmallCaps, ctx.Script)
+	}
+	te.SmallCaps, te.Script = true, &inline.ScriptShift{Kind: inline.ScriptSub}
+	te.ApplyShaping(ctx)
+	if !ctx.SmallCaps || ctx.Script != te.Script {
+		t.Errorf("ApplyShaping set small caps %v, script %v", ctx.SmallCaps, ctx.Script)
+	}
 }
 
 func TestTextElemBuilders(t *testing.T) {
@@ -215,3 +291,63 @@ func TestSizeFromEm(t *testing.T) {
 		t.Errorf("SizeFromEm(1.5, 10pt) = %vpt, want 15pt", s.Points())
 	}
 }
+
+func TestPackedIn(t *testing.T) {
+	span := syntax.SpanFromRaw(1<<48 | 2)
+	for _, arena := range []*foundations.Arena{nil, foundations.AcquireArena()} {
+		content := PackedIn(arena, "Hello", span)
+		elem, ok := content.Elements[0].(*TextElem)
+		if len(content.Elements) != 1 || !ok {
+			t.Fatalf("content = %+v", content)
+		}
+		if elem.Body != "Hello" || elem.Size != SizeFromPt(11) || !elem.Fallback || elem.Span != span {
+			t.Errorf("text = %+v", elem)
+		}
+		arena.Release()
+	}
+}
+
+// markup creates the elements of n words of markup, with a paragraph break
+// after every tenth word, like evaluation does. A nil arena allocates each
+// element on its own.
+func markup(arena *foundations.Arena, n int) []foundations.Content {
+	seq := make([]foundations.Content, 0, 2*n)
+	for i := range n {
+		seq = append(seq, PackedIn(arena, "word", syntax.Detached()))
+		if i%10 == 9 {
+			seq = append(seq, arena.Parbreak())
+		} else {
+			seq = append(seq, arena.Space())
+		}
+	}
+	return seq
+}
+
+func TestPackedInAllocations(t *testing.T) {
+	arena := foundations.AcquireArena()
+	defer arena.Release()
+	heap := testing.AllocsPerRun(10, func() { markup(nil, 1000) })
+	pooled := testing.AllocsPerRun(10, func() { markup(arena, 1000) })
+	// Without the arena, each word costs its element and a slice. With it,
+	// a block of each holds 256 words.
+	if heap < 2000 || pooled > 20 {
+		t.Errorf("1000 words took %v allocations on the heap and %v with an arena", heap, pooled)
+	}
+}
+
+func BenchmarkMarkup(b *testing.B) {
+	b.Run("heap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			markup(nil, 1000)
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		arena := foundations.AcquireArena()
+		defer arena.Release()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			markup(arena, 1000)
+		}
+	})
+}
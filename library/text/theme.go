@@ -0,0 +1,342 @@
+// Color themes for raw highlighting.
+// Corresponds to the themes loaded by Rust's RawElem via syntect.
+
+package text
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Theme maps the scopes assigned by a SyntaxDefinition to colors and font
+// styles. Themes are loaded from files in the .tmTheme format.
+type Theme struct {
+	// Name is the name of the theme.
+	Name string
+	// Foreground is the color of text no item applies to, as RGB hex like
+	// "000000", or empty to keep the text color.
+	Foreground string
+	// Background is the background color of code, as RGB hex.
+	Background string
+
+	items []themeItem
+}
+
+// themeItem styles the scopes matched by a selector.
+type themeItem struct {
+	selector   scopeSelector
+	foreground string
+	fontStyle  *HighlightStyle
+}
+
+// Style returns the style of text with the given scopes, outermost first.
+// Foreground and font style are taken from the items whose selectors match
+// most specifically, with later items winning ties. A nil theme styles
+// nothing.
+func (t *Theme) Style(scopes []string) HighlightStyle {
+	if t == nil {
+		return HighlightStyle{}
+	}
+	style := HighlightStyle{Color: t.Foreground}
+	colorScore, fontScore := -1, -1
+	for _, item := range t.items {
+		score := item.selector.score(scopes)
+		if score < 0 {
+			continue
+		}
+		if item.foreground != "" && score >= colorScore {
+			style.Color, colorScore = item.foreground, score
+		}
+		if item.fontStyle != nil && score >= fontScore {
+			style.Bold, style.Italic, style.Underline = item.fontStyle.Bold, item.fontStyle.Italic, item.fontStyle.Underline
+			fontScore = score
+		}
+	}
+	return style
+}
+
+// ParseTmTheme parses a theme in the .tmTheme format, a property list with
+// global settings followed by scoped items.
+func ParseTmTheme(data []byte) (*Theme, error) {
+	value, err := parsePlist(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a dictionary at the root")
+	}
+	settings, ok := root["settings"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing settings")
+	}
+
+	theme := &Theme{}
+	theme.Name, _ = root["name"].(string)
+	for _, entry := range settings {
+		dict, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		values, _ := dict["settings"].(map[string]any)
+		scope, scoped := dict["scope"].(string)
+		if !scoped {
+			// The unscoped entry holds the global settings.
+			if fg, ok := values["foreground"].(string); ok {
+				theme.Foreground = parseThemeColor(fg)
+			}
+			if bg, ok := values["background"].(string); ok {
+				theme.Background = parseThemeColor(bg)
+			}
+			continue
+		}
+
+		item := themeItem{selector: parseScopeSelector(scope)}
+		if fg, ok := values["foreground"].(string); ok {
+			item.foreground = parseThemeColor(fg)
+		}
+		if fontStyle, ok := values["fontStyle"].(string); ok {
+			item.fontStyle = &HighlightStyle{}
+			for _, word := range strings.Fields(fontStyle) {
+				switch word {
+				case "bold":
+					item.fontStyle.Bold = true
+				case "italic":
+					item.fontStyle.Italic = true
+				case "underline":
+					item.fontStyle.Underline = true
+				}
+			}
+		}
+		theme.items = append(theme.items, item)
+	}
+	return theme, nil
+}
+
+// parseThemeColor converts a color like #RGB, #RRGGBB, or #RRGGBBAA to the
+// RGB hex used by HighlightStyle. Alpha is dropped.
+func parseThemeColor(color string) string {
+	hex := strings.ToLower(strings.TrimPrefix(color, "#"))
+	if len(hex) == 3 || len(hex) == 4 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) < 6 {
+		return ""
+	}
+	if _, err := strconv.ParseUint(hex[:6], 16, 32); err != nil {
+		return ""
+	}
+	return hex[:6]
+}
+
+// scopeSelector is a comma-separated list of alternatives, like
+// "string, comment.line - comment.line.shebang".
+type scopeSelector []scopePath
+
+// scopePath selects scopes nested in the given order, like
+// "meta.function keyword", except for those matching an exclusion.
+type scopePath struct {
+	scopes   []string
+	excludes []scopePath
+}
+
+func parseScopeSelector(text string) scopeSelector {
+	var selector scopeSelector
+	for _, alternative := range strings.Split(text, ",") {
+		parts := strings.Split(alternative, " - ")
+		path := scopePath{scopes: strings.Fields(parts[0])}
+		for _, exclude := range parts[1:] {
+			path.excludes = append(path.excludes, scopePath{scopes: strings.Fields(exclude)})
+		}
+		if len(path.scopes) > 0 {
+			selector = append(selector, path)
+		}
+	}
+	return selector
+}
+
+// score rates how specifically the selector matches the scopes, or
+// returns -1 if it does not match. Matching deeper scopes and longer scope
+// names is more specific.
+func (s scopeSelector) score(scopes []string) int {
+	best := -1
+	for _, path := range s {
+		if score := path.score(scopes); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func (p scopePath) score(scopes []string) int {
+	for _, exclude := range p.excludes {
+		if exclude.score(scopes) >= 0 {
+			return -1
+		}
+	}
+
+	// Match the path from its innermost scope outwards, as deep in the
+	// stack as possible.
+	score := 0
+	j := len(scopes) - 1
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		for j >= 0 && !scopeMatches(p.scopes[i], scopes[j]) {
+			j--
+		}
+		if j < 0 {
+			return -1
+		}
+		if i == len(p.scopes)-1 {
+			score = (j+1)*10000 + (strings.Count(p.scopes[i], ".")+1)*100
+		}
+		j--
+	}
+	return score + len(p.scopes)
+}
+
+// scopeMatches reports whether a selector scope like "string.quoted"
+// matches a scope like "string.quoted.double.rust".
+func scopeMatches(selector, scope string) bool {
+	return scope == selector || strings.HasPrefix(scope, selector+".")
+}
+
+// parsePlist decodes an XML property list into maps, slices, strings,
+// booleans, and numbers.
+func parsePlist(data []byte) (any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("missing plist element")
+			}
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local == "plist" {
+				continue
+			}
+			return plistValue(decoder, start)
+		}
+	}
+}
+
+func plistValue(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := map[string]any{}
+		var key string
+		for {
+			child, done, err := plistChild(decoder)
+			if err != nil || done {
+				return dict, err
+			}
+			if child.Name.Local == "key" {
+				if err := decoder.DecodeElement(&key, &child); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value, err := plistValue(decoder, child)
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = value
+		}
+	case "array":
+		var array []any
+		for {
+			child, done, err := plistChild(decoder)
+			if err != nil || done {
+				return array, err
+			}
+			value, err := plistValue(decoder, child)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	case "integer", "real":
+		var text string
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	default:
+		var text string
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		return text, nil
+	}
+}
+
+// plistChild returns the next child element of a dict or array, or done at
+// the end of the parent.
+func plistChild(decoder *xml.Decoder) (xml.StartElement, bool, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, false, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return t, false, nil
+		case xml.EndElement:
+			return xml.StartElement{}, true, nil
+		}
+	}
+}
+
+var (
+	themeCache   = map[[sha256.Size]byte]*Theme{}
+	themeCacheMu sync.Mutex
+)
+
+// LoadTheme parses a .tmTheme file, reusing an earlier result for the same
+// data.
+func LoadTheme(data []byte) (*Theme, error) {
+	key := sha256.Sum256(data)
+	themeCacheMu.Lock()
+	defer themeCacheMu.Unlock()
+	if theme, ok := themeCache[key]; ok {
+		return theme, nil
+	}
+	theme, err := ParseTmTheme(data)
+	if err != nil {
+		return nil, err
+	}
+	themeCache[key] = theme
+	return theme, nil
+}
+
+// DefaultTheme is the theme used for syntax definitions when raw has no
+// theme. Its colors follow the built-in keyword highlighter.
+var DefaultTheme = &Theme{
+	Name: "Default",
+	items: []themeItem{
+		{selector: parseScopeSelector("comment"), foreground: "8a8a8a"},
+		{selector: parseScopeSelector("string"), foreground: "298e0d"},
+		{selector: parseScopeSelector("constant.character.escape"), foreground: "1d6c76", fontStyle: &HighlightStyle{}},
+		{selector: parseScopeSelector("constant"), foreground: "ff6600", fontStyle: &HighlightStyle{Italic: true}},
+		{selector: parseScopeSelector("constant.numeric"), foreground: "b60157", fontStyle: &HighlightStyle{}},
+		{selector: parseScopeSelector("keyword, storage"), foreground: "0000ff", fontStyle: &HighlightStyle{Bold: true}},
+		{selector: parseScopeSelector("keyword.operator"), foreground: "1d6c76", fontStyle: &HighlightStyle{}},
+		{selector: parseScopeSelector("entity.name, support.function, variable.function"), foreground: "4b69c6"},
+		{selector: parseScopeSelector("markup.bold"), fontStyle: &HighlightStyle{Bold: true}},
+		{selector: parseScopeSelector("markup.italic"), fontStyle: &HighlightStyle{Italic: true}},
+		{selector: parseScopeSelector("markup.underline"), fontStyle: &HighlightStyle{Underline: true}},
+	},
+}
@@ -0,0 +1,113 @@
+package text
+
+import "testing"
+
+const testTheme = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>name</key>
+	<string>Test</string>
+	<key>settings</key>
+	<array>
+		<dict>
+			<key>settings</key>
+			<dict>
+				<key>foreground</key>
+				<string>#333</string>
+				<key>background</key>
+				<string>#FFFFFFFF</string>
+			</dict>
+		</dict>
+		<dict>
+			<key>name</key>
+			<string>Keywords</string>
+			<key>scope</key>
+			<string>keyword, storage.type</string>
+			<key>settings</key>
+			<dict>
+				<key>foreground</key>
+				<string>#AA0000</string>
+				<key>fontStyle</key>
+				<string>bold</string>
+			</dict>
+		</dict>
+		<dict>
+			<key>scope</key>
+			<string>string - string.quoted.single</string>
+			<key>settings</key>
+			<dict>
+				<key>foreground</key>
+				<string>#00aa00</string>
+			</dict>
+		</dict>
+		<dict>
+			<key>scope</key>
+			<string>meta.function keyword</string>
+			<key>settings</key>
+			<dict>
+				<key>fontStyle</key>
+				<string>italic underline</string>
+			</dict>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+func TestParseTmTheme(t *testing.T) {
+	theme, err := ParseTmTheme([]byte(testTheme))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Name != "Test" || theme.Foreground != "333333" || theme.Background != "ffffff" {
+		t.Errorf("theme = %q, foreground %q, background %q", theme.Name, theme.Foreground, theme.Background)
+	}
+
+	tests := []struct {
+		scopes []string
+		want   HighlightStyle
+	}{
+		{[]string{"source.x"}, HighlightStyle{Color: "333333"}},
+		{[]string{"source.x", "keyword.control.x"}, HighlightStyle{Color: "aa0000", Bold: true}},
+		{[]string{"source.x", "storage.type.x"}, HighlightStyle{Color: "aa0000", Bold: true}},
+		{[]string{"source.x", "string.quoted.double.x"}, HighlightStyle{Color: "00aa00"}},
+		{[]string{"source.x", "string.quoted.single.x"}, HighlightStyle{Color: "333333"}},
+		{[]string{"source.x", "meta.function.x", "keyword.x"}, HighlightStyle{Color: "aa0000", Italic: true, Underline: true}},
+		// Keywords are not storage.
+		{[]string{"source.x", "storage.modifier"}, HighlightStyle{Color: "333333"}},
+	}
+	for _, tt := range tests {
+		if got := theme.Style(tt.scopes); got != tt.want {
+			t.Errorf("Style(%v) = %+v, want %+v", tt.scopes, got, tt.want)
+		}
+	}
+}
+
+func TestParseTmThemeErrors(t *testing.T) {
+	for _, data := range []string{"", "<plist><array></array></plist>", "<plist><dict></dict></plist>"} {
+		if _, err := ParseTmTheme([]byte(data)); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+func TestScopeSelectorSpecificity(t *testing.T) {
+	scopes := []string{"source.x", "meta.block.x", "string.quoted.x"}
+	deep := parseScopeSelector("string.quoted").score(scopes)
+	shallow := parseScopeSelector("string").score(scopes)
+	outer := parseScopeSelector("meta.block").score(scopes)
+	if !(deep > shallow && shallow > outer && outer >= 0) {
+		t.Errorf("scores: string.quoted %d, string %d, meta.block %d", deep, shallow, outer)
+	}
+	if score := parseScopeSelector("comment").score(scopes); score != -1 {
+		t.Errorf("non-matching selector scored %d", score)
+	}
+}
+
+func TestNilThemeStyle(t *testing.T) {
+	var theme *Theme
+	if style := theme.Style([]string{"keyword"}); style != (HighlightStyle{}) {
+		t.Errorf("nil theme style = %+v", style)
+	}
+}
@@ -2,6 +2,7 @@ package text
 
 import (
 	"github.com/boergens/gotypst/layout/inline"
+	"github.com/boergens/gotypst/library/foundations"
 )
 
 // Size represents a text size value (typically in points).
@@ -216,3 +217,186 @@ func (n NumberWidth) String() string {
 		return "proportional"
 	}
 }
+
+// TopEdge is the top edge of text, which determines the height of lines
+// and where the first line of a block starts. It is a font metric or, if
+// Length is set, a fixed distance above the baseline.
+type TopEdge struct {
+	// Metric is the font metric to use if Length is nil.
+	Metric TopEdgeMetric
+	// Length is a fixed distance above the baseline.
+	Length *Size
+}
+
+// TopEdgeMetric is a font metric that can serve as the top edge of text.
+type TopEdgeMetric int
+
+const (
+	// TopEdgeAscender is the font's ascender, which typically exceeds the
+	// height of all glyphs.
+	TopEdgeAscender TopEdgeMetric = iota
+	// TopEdgeCapHeight is the approximate height of uppercase letters.
+	TopEdgeCapHeight
+	// TopEdgeXHeight is the approximate height of non-ascending lowercase
+	// letters.
+	TopEdgeXHeight
+	// TopEdgeBaseline is the baseline on which the letters rest.
+	TopEdgeBaseline
+	// TopEdgeBounds is the top edge of the glyph's bounding box.
+	TopEdgeBounds
+)
+
+// String returns the metric as a string.
+func (m TopEdgeMetric) String() string {
+	switch m {
+	case TopEdgeCapHeight:
+		return "cap-height"
+	case TopEdgeXHeight:
+		return "x-height"
+	case TopEdgeBaseline:
+		return "baseline"
+	case TopEdgeBounds:
+		return "bounds"
+	default:
+		return "ascender"
+	}
+}
+
+// ToInline converts to the inline package's top edge.
+func (e TopEdge) ToInline() inline.TextTopEdge {
+	if e.Length != nil {
+		length := e.Length.ToAbs()
+		return inline.TextTopEdge{Length: &length}
+	}
+	metric := inline.TopEdgeAscender
+	switch e.Metric {
+	case TopEdgeCapHeight:
+		metric = inline.TopEdgeCapHeight
+	case TopEdgeXHeight:
+		metric = inline.TopEdgeXHeight
+	case TopEdgeBaseline:
+		metric = inline.TopEdgeBaseline
+	case TopEdgeBounds:
+		metric = inline.TopEdgeBounds
+	}
+	return inline.TextTopEdge{Metric: metric}
+}
+
+// BottomEdge is the bottom edge of text. It is a font metric or, if
+// Length is set, a fixed distance below the baseline.
+type BottomEdge struct {
+	// Metric is the font metric to use if Length is nil.
+	Metric BottomEdgeMetric
+	// Length is a fixed distance below the baseline.
+	Length *Size
+}
+
+// BottomEdgeMetric is a font metric that can serve as the bottom edge of
+// text.
+type BottomEdgeMetric int
+
+const (
+	// BottomEdgeBaseline is the baseline on which the letters rest.
+	BottomEdgeBaseline BottomEdgeMetric = iota
+	// BottomEdgeDescender is the font's descender, which typically
+	// exceeds the depth of all glyphs.
+	BottomEdgeDescender
+	// BottomEdgeBounds is the bottom edge of the glyph's bounding box.
+	BottomEdgeBounds
+)
+
+// String returns the metric as a string.
+func (m BottomEdgeMetric) String() string {
+	switch m {
+	case BottomEdgeDescender:
+		return "descender"
+	case BottomEdgeBounds:
+		return "bounds"
+	default:
+		return "baseline"
+	}
+}
+
+// ToInline converts to the inline package's bottom edge.
+func (e BottomEdge) ToInline() inline.TextBottomEdge {
+	if e.Length != nil {
+		length := e.Length.ToAbs()
+		return inline.TextBottomEdge{Length: &length}
+	}
+	metric := inline.BottomEdgeBaseline
+	switch e.Metric {
+	case BottomEdgeDescender:
+		metric = inline.BottomEdgeDescender
+	case BottomEdgeBounds:
+		metric = inline.BottomEdgeBounds
+	}
+	return inline.TextBottomEdge{Metric: metric}
+}
+
+// ParseTopEdge parses the value of `top-edge`: a metric name or a length.
+func ParseTopEdge(v foundations.Value) (TopEdge, error) {
+	switch v := v.(type) {
+	case foundations.LengthValue:
+		size := SizeFromPt(v.Length.Points)
+		return TopEdge{Length: &size}, nil
+	case foundations.Str:
+		for m := TopEdgeAscender; m <= TopEdgeBounds; m++ {
+			if m.String() == string(v) {
+				return TopEdge{Metric: m}, nil
+			}
+		}
+		return TopEdge{}, &foundations.ConstructorError{Message: "expected \"ascender\", \"cap-height\", \"x-height\", \"baseline\", or \"bounds\""}
+	}
+	return TopEdge{}, &foundations.TypeMismatchError{Expected: "string or length", Got: v.Type().String(), Field: "top-edge"}
+}
+
+// ParseBottomEdge parses the value of `bottom-edge`: a metric name or a
+// length.
+func ParseBottomEdge(v foundations.Value) (BottomEdge, error) {
+	switch v := v.(type) {
+	case foundations.LengthValue:
+		size := SizeFromPt(v.Length.Points)
+		return BottomEdge{Length: &size}, nil
+	case foundations.Str:
+		for m := BottomEdgeBaseline; m <= BottomEdgeBounds; m++ {
+			if m.String() == string(v) {
+				return BottomEdge{Metric: m}, nil
+			}
+		}
+		return BottomEdge{}, &foundations.ConstructorError{Message: "expected \"baseline\", \"descender\", or \"bounds\""}
+	}
+	return BottomEdge{}, &foundations.TypeMismatchError{Expected: "string or length", Got: v.Type().String(), Field: "bottom-edge"}
+}
+
+// ParseTracking parses the value of `tracking`: a length.
+func ParseTracking(v foundations.Value) (Size, error) {
+	return parseLength(v, "tracking")
+}
+
+// ParseBaseline parses the value of `baseline`: a length.
+func ParseBaseline(v foundations.Value) (Size, error) {
+	return parseLength(v, "baseline")
+}
+
+// ParseSpacing parses the value of `spacing`: a relative length, whose
+// ratio scales the width of spaces and whose length is added to it. It
+// returns the ratio and the length.
+func ParseSpacing(v foundations.Value) (float64, Size, error) {
+	switch v := v.(type) {
+	case foundations.RatioValue:
+		return v.Ratio.Value, 0, nil
+	case foundations.LengthValue:
+		return 0, SizeFromPt(v.Length.Points), nil
+	case foundations.RelativeValue:
+		return v.Relative.Rel.Value, SizeFromPt(v.Relative.Abs.Points), nil
+	}
+	return 0, 0, &foundations.TypeMismatchError{Expected: "relative length", Got: v.Type().String(), Field: "spacing"}
+}
+
+// parseLength parses a length-valued text field.
+func parseLength(v foundations.Value, field string) (Size, error) {
+	if v, ok := v.(foundations.LengthValue); ok {
+		return SizeFromPt(v.Length.Points), nil
+	}
+	return 0, &foundations.TypeMismatchError{Expected: "length", Got: v.Type().String(), Field: field}
+}
@@ -0,0 +1,835 @@
+package visualize
+
+import (
+	"math"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Curve is an outline made of lines and cubic Bézier curves. It may contain
+// multiple subpaths, each starting with a CurveMove.
+//
+// Reference: typst-reference/crates/typst-library/src/visualize/curve.rs
+type Curve []CurveItem
+
+// CurveItem is one step of a curve.
+type CurveItem interface {
+	isCurveItem()
+}
+
+// CurveMove starts a new subpath at a point.
+type CurveMove struct {
+	To layout.Point
+}
+
+func (CurveMove) isCurveItem() {}
+
+// CurveLine draws a straight line to a point.
+type CurveLine struct {
+	To layout.Point
+}
+
+func (CurveLine) isCurveItem() {}
+
+// CurveCubic draws a cubic Bézier curve to a point.
+type CurveCubic struct {
+	Control1, Control2, To layout.Point
+}
+
+func (CurveCubic) isCurveItem() {}
+
+// CurveClose closes the current subpath with a line to its start.
+type CurveClose struct{}
+
+func (CurveClose) isCurveItem() {}
+
+// RectCurve returns the outline of a rectangle with its top-left corner at
+// the origin.
+func RectCurve(size layout.Size) Curve {
+	return Curve{
+		CurveMove{To: layout.Point{}},
+		CurveLine{To: layout.Point{X: size.Width}},
+		CurveLine{To: layout.Point{X: size.Width, Y: size.Height}},
+		CurveLine{To: layout.Point{Y: size.Height}},
+		CurveClose{},
+	}
+}
+
+// MoveTo starts a new subpath at a point.
+func (c *Curve) MoveTo(p layout.Point) {
+	*c = append(*c, CurveMove{To: p})
+}
+
+// LineTo adds a line to a point.
+func (c *Curve) LineTo(p layout.Point) {
+	*c = append(*c, CurveLine{To: p})
+}
+
+// CubicTo adds a cubic Bézier curve with two control points to a point.
+func (c *Curve) CubicTo(control1, control2, to layout.Point) {
+	*c = append(*c, CurveCubic{Control1: control1, Control2: control2, To: to})
+}
+
+// Close closes the current subpath.
+func (c *Curve) Close() {
+	*c = append(*c, CurveClose{})
+}
+
+// Rect is an axis-aligned rectangle between two corners.
+type Rect struct {
+	Min, Max layout.Point
+}
+
+// Size returns the width and height of the rectangle.
+func (r Rect) Size() layout.Size {
+	return layout.Size{Width: r.Max.X - r.Min.X, Height: r.Max.Y - r.Min.Y}
+}
+
+// Contains reports whether a point lies in the rectangle or on its edge.
+func (r Rect) Contains(p layout.Point) bool {
+	return p.X >= r.Min.X && p.X <= r.Max.X && p.Y >= r.Min.Y && p.Y <= r.Max.Y
+}
+
+// BBox returns the smallest rectangle that contains the curve. The control
+// points of Bézier curves only count as far as the curve reaches towards
+// them. An empty curve has an empty rectangle at the origin.
+// Matches Rust: Curve::bbox
+func (c Curve) BBox() Rect {
+	var box Rect
+	first := true
+	add := func(p layout.Point) {
+		if first {
+			box, first = Rect{Min: p, Max: p}, false
+			return
+		}
+		box.Min.X, box.Min.Y = min(box.Min.X, p.X), min(box.Min.Y, p.Y)
+		box.Max.X, box.Max.Y = max(box.Max.X, p.X), max(box.Max.Y, p.Y)
+	}
+
+	var cursor layout.Point
+	for _, item := range c {
+		switch item := item.(type) {
+		case CurveMove:
+			add(item.To)
+			cursor = item.To
+		case CurveLine:
+			add(item.To)
+			cursor = item.To
+		case CurveCubic:
+			add(item.To)
+			for _, t := range cubicExtrema(cursor, item) {
+				add(cubicPoint(cursor, item, t))
+			}
+			cursor = item.To
+		}
+	}
+	return box
+}
+
+// BBoxSize returns the size of the curve's bounding box.
+// Matches Rust: Curve::bbox_size
+func (c Curve) BBoxSize() layout.Size {
+	return c.BBox().Size()
+}
+
+// FillRule decides which parts of a self-intersecting or nested outline
+// are inside it.
+type FillRule int
+
+const (
+	// FillRuleNonZero fills points that the outline winds around in one
+	// direction more often than in the other.
+	FillRuleNonZero FillRule = iota
+	// FillRuleEvenOdd fills points that the outline winds around an odd
+	// number of times.
+	FillRuleEvenOdd
+)
+
+// String returns the name of the fill rule in Typst.
+func (r FillRule) String() string {
+	if r == FillRuleEvenOdd {
+		return "even-odd"
+	}
+	return "non-zero"
+}
+
+// curveFlatness is the number of lines a cubic Bézier curve is split into
+// when testing whether it contains a point.
+const curveFlatness = 32
+
+// Contains reports whether a point lies inside the area the curve would
+// fill with the given fill rule. Open subpaths are closed implicitly, as
+// when filling. Bézier curves are approximated with lines.
+func (c Curve) Contains(p layout.Point, rule FillRule) bool {
+	winding := 0
+	edge := func(a, b layout.Point) {
+		// Count crossings of the ray from p to the right.
+		if a.Y <= p.Y {
+			if b.Y > p.Y && cross(a, b, p) > 0 {
+				winding++
+			}
+		} else if b.Y <= p.Y && cross(a, b, p) < 0 {
+			winding--
+		}
+	}
+
+	var start, cursor layout.Point
+	open := false
+	for _, item := range c {
+		switch item := item.(type) {
+		case CurveMove:
+			if open {
+				edge(cursor, start)
+			}
+			start, cursor, open = item.To, item.To, true
+		case CurveLine:
+			edge(cursor, item.To)
+			cursor = item.To
+		case CurveCubic:
+			prev := cursor
+			for i := 1; i <= curveFlatness; i++ {
+				next := cubicPoint(cursor, item, float64(i)/curveFlatness)
+				edge(prev, next)
+				prev = next
+			}
+			cursor = item.To
+		case CurveClose:
+			edge(cursor, start)
+			cursor, open = start, false
+		}
+	}
+	if open {
+		edge(cursor, start)
+	}
+
+	if rule == FillRuleEvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// Transform returns the curve with all its points mapped through a
+// transformation.
+func (c Curve) Transform(t Transform) Curve {
+	out := make(Curve, len(c))
+	for i, item := range c {
+		switch item := item.(type) {
+		case CurveMove:
+			out[i] = CurveMove{To: t.Apply(item.To)}
+		case CurveLine:
+			out[i] = CurveLine{To: t.Apply(item.To)}
+		case CurveCubic:
+			out[i] = CurveCubic{Control1: t.Apply(item.Control1), Control2: t.Apply(item.Control2), To: t.Apply(item.To)}
+		default:
+			out[i] = item
+		}
+	}
+	return out
+}
+
+// cross returns the cross product of b-a and p-a, which is positive if p
+// lies to the right of the line from a to b on the page, where the y-axis
+// points down.
+func cross(a, b, p layout.Point) float64 {
+	return float64(b.X-a.X)*float64(p.Y-a.Y) - float64(p.X-a.X)*float64(b.Y-a.Y)
+}
+
+// cubicPoint returns the point at parameter t of a cubic Bézier curve
+// starting at from.
+func cubicPoint(from layout.Point, c CurveCubic, t float64) layout.Point {
+	at := func(p0, p1, p2, p3 layout.Abs) layout.Abs {
+		u := 1 - t
+		return layout.Abs(u*u*u*float64(p0) + 3*u*u*t*float64(p1) + 3*u*t*t*float64(p2) + t*t*t*float64(p3))
+	}
+	return layout.Point{
+		X: at(from.X, c.Control1.X, c.Control2.X, c.To.X),
+		Y: at(from.Y, c.Control1.Y, c.Control2.Y, c.To.Y),
+	}
+}
+
+// cubicExtrema returns the parameters in (0, 1) at which a cubic Bézier
+// curve starting at from turns around horizontally or vertically.
+func cubicExtrema(from layout.Point, c CurveCubic) []float64 {
+	var ts []float64
+	axis := func(p0, p1, p2, p3 layout.Abs) {
+		// The derivative is a*t² + b*t + k.
+		a := 3 * float64(-p0+3*p1-3*p2+p3)
+		b := 6 * float64(p0-2*p1+p2)
+		k := 3 * float64(p1-p0)
+		for _, t := range quadraticRoots(a, b, k) {
+			if t > 0 && t < 1 {
+				ts = append(ts, t)
+			}
+		}
+	}
+	axis(from.X, c.Control1.X, c.Control2.X, c.To.X)
+	axis(from.Y, c.Control1.Y, c.Control2.Y, c.To.Y)
+	return ts
+}
+
+// quadraticRoots returns the real roots of a*t² + b*t + c.
+func quadraticRoots(a, b, c float64) []float64 {
+	const eps = 1e-12
+	if math.Abs(a) < eps {
+		if math.Abs(b) < eps {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return nil
+	}
+	sqrt := math.Sqrt(disc)
+	return []float64{(-b + sqrt) / (2 * a), (-b - sqrt) / (2 * a)}
+}
+
+// Transform is an affine transformation. A point (x, y) is mapped to
+// (Sx*x + Kx*y + Tx, Ky*x + Sy*y + Ty).
+//
+// Reference: typst-reference/crates/typst-library/src/layout/transform.rs
+type Transform struct {
+	Sx, Ky, Kx, Sy float64
+	Tx, Ty         layout.Abs
+}
+
+// IdentityTransform returns the transformation that changes nothing.
+func IdentityTransform() Transform {
+	return Transform{Sx: 1, Sy: 1}
+}
+
+// TranslateTransform returns a translation by x and y.
+func TranslateTransform(x, y layout.Abs) Transform {
+	return Transform{Sx: 1, Sy: 1, Tx: x, Ty: y}
+}
+
+// ScaleTransform returns a scaling by sx and sy around the origin.
+func ScaleTransform(sx, sy float64) Transform {
+	return Transform{Sx: sx, Sy: sy}
+}
+
+// RotateTransform returns a rotation by an angle in radians around the
+// origin. Since the y-axis points down, positive angles turn clockwise on
+// the page.
+func RotateTransform(angle float64) Transform {
+	sin, cos := math.Sincos(angle)
+	return Transform{Sx: cos, Ky: sin, Kx: -sin, Sy: cos}
+}
+
+// SkewTransform returns a skew by the angles ax and ay in radians.
+func SkewTransform(ax, ay float64) Transform {
+	return Transform{Sx: 1, Ky: math.Tan(ay), Kx: math.Tan(ax), Sy: 1}
+}
+
+// Then returns the transformation that applies t and then other.
+func (t Transform) Then(other Transform) Transform {
+	return Transform{
+		Sx: other.Sx*t.Sx + other.Kx*t.Ky,
+		Ky: other.Ky*t.Sx + other.Sy*t.Ky,
+		Kx: other.Sx*t.Kx + other.Kx*t.Sy,
+		Sy: other.Ky*t.Kx + other.Sy*t.Sy,
+		Tx: layout.Abs(other.Sx*float64(t.Tx)+other.Kx*float64(t.Ty)) + other.Tx,
+		Ty: layout.Abs(other.Ky*float64(t.Tx)+other.Sy*float64(t.Ty)) + other.Ty,
+	}
+}
+
+// Invert returns the transformation that undoes t, or false if t
+// collapses the plane and cannot be undone.
+func (t Transform) Invert() (Transform, bool) {
+	det := t.Sx*t.Sy - t.Kx*t.Ky
+	if math.Abs(det) < 1e-12 {
+		return Transform{}, false
+	}
+	inv := Transform{Sx: t.Sy / det, Ky: -t.Ky / det, Kx: -t.Kx / det, Sy: t.Sx / det}
+	inv.Tx = -layout.Abs(inv.Sx*float64(t.Tx) + inv.Kx*float64(t.Ty))
+	inv.Ty = -layout.Abs(inv.Ky*float64(t.Tx) + inv.Sy*float64(t.Ty))
+	return inv, true
+}
+
+// Apply maps a point through the transformation.
+func (t Transform) Apply(p layout.Point) layout.Point {
+	return layout.Point{
+		X: layout.Abs(t.Sx*float64(p.X)+t.Kx*float64(p.Y)) + t.Tx,
+		Y: layout.Abs(t.Ky*float64(p.X)+t.Sy*float64(p.Y)) + t.Ty,
+	}
+}
+
+// TransformedBBox returns the bounding box of a rectangle of the given size
+// with its top-left corner at the origin after a transformation. This is
+// the area that rotated or scaled content occupies when it reflows.
+// Matches Rust: compute_bounding_box in layout/transform.rs
+func TransformedBBox(size layout.Size, t Transform) Rect {
+	return RectCurve(size).Transform(t).BBox()
+}
+
+// CurveElement is a curve made of components that move the pen and draw
+// lines and Bézier curves: `curve(curve.move((0pt, 10pt)), curve.line((20pt, 0pt)))`.
+//
+// Reference: typst-reference/crates/typst-library/src/visualize/curve.rs
+type CurveElement struct {
+	// Fill is how to fill the curve, or nil for no fill.
+	Fill foundations.Value `typst:"fill"`
+	// FillRule is "non-zero" or "even-odd". If nil, it is "non-zero".
+	FillRule *string `typst:"fill-rule,type=str"`
+	// Stroke is how to stroke the curve. If nil, the curve has a 1pt black
+	// stroke unless it is filled; none removes the stroke.
+	Stroke foundations.Value `typst:"stroke"`
+	// Components are the curve.move, curve.line, curve.quad, curve.cubic,
+	// and curve.close elements the curve is made of.
+	Components []foundations.Content `typst:"components,variadic,type=content"`
+	foundations.ElemMeta
+}
+
+func (*CurveElement) IsContentElement() {}
+
+// CurveMoveElem starts a new subpath at a point.
+type CurveMoveElem struct {
+	// Start is the start point of the subpath.
+	Start foundations.Value `typst:"start,positional,required"`
+	// Relative makes Start relative to the end of the previous component.
+	Relative bool `typst:"relative,type=bool,default=false"`
+}
+
+func (*CurveMoveElem) IsContentElement() {}
+
+// CurveLineElem draws a straight line to a point.
+type CurveLineElem struct {
+	// End is the end point of the line.
+	End foundations.Value `typst:"end,positional,required"`
+	// Relative makes End relative to the end of the previous component.
+	Relative bool `typst:"relative,type=bool,default=false"`
+}
+
+func (*CurveLineElem) IsContentElement() {}
+
+// CurveQuadElem draws a quadratic Bézier curve to a point.
+type CurveQuadElem struct {
+	// Control is the control point. None makes the curve a straight line,
+	// and auto mirrors the previous control point.
+	Control foundations.Value `typst:"control,positional,required"`
+	// End is the end point of the curve.
+	End foundations.Value `typst:"end,positional,required"`
+	// Relative makes the points relative to the end of the previous
+	// component.
+	Relative bool `typst:"relative,type=bool,default=false"`
+}
+
+func (*CurveQuadElem) IsContentElement() {}
+
+// CurveCubicElem draws a cubic Bézier curve to a point.
+type CurveCubicElem struct {
+	// ControlStart is the control point at the start. None places it at
+	// the start, and auto mirrors the previous control point.
+	ControlStart foundations.Value `typst:"control-start,positional,required"`
+	// ControlEnd is the control point at the end. None places it at the
+	// end.
+	ControlEnd foundations.Value `typst:"control-end,positional,required"`
+	// End is the end point of the curve.
+	End foundations.Value `typst:"end,positional,required"`
+	// Relative makes the points relative to the end of the previous
+	// component.
+	Relative bool `typst:"relative,type=bool,default=false"`
+}
+
+func (*CurveCubicElem) IsContentElement() {}
+
+// CurveCloseElem closes the current subpath.
+type CurveCloseElem struct {
+	// Mode is "smooth", which closes the subpath with a curve that
+	// continues the control points at both ends, or "straight", which
+	// closes it with a line. If nil, it is "smooth".
+	Mode *string `typst:"mode,type=str"`
+}
+
+func (*CurveCloseElem) IsContentElement() {}
+
+// Registered element definitions for curve and its components.
+var (
+	CurveDef      *foundations.ElementDef
+	CurveMoveDef  *foundations.ElementDef
+	CurveLineDef  *foundations.ElementDef
+	CurveQuadDef  *foundations.ElementDef
+	CurveCubicDef *foundations.ElementDef
+	CurveCloseDef *foundations.ElementDef
+)
+
+func init() {
+	CurveDef = foundations.RegisterElement[CurveElement]("curve", nil)
+	CurveMoveDef = foundations.RegisterElement[CurveMoveElem]("curve.move", nil)
+	CurveLineDef = foundations.RegisterElement[CurveLineElem]("curve.line", nil)
+	CurveQuadDef = foundations.RegisterElement[CurveQuadElem]("curve.quad", nil)
+	CurveCubicDef = foundations.RegisterElement[CurveCubicElem]("curve.cubic", nil)
+	CurveCloseDef = foundations.RegisterElement[CurveCloseElem]("curve.close", nil)
+}
+
+// CurveFunc creates the curve element function. Its scope holds the
+// components.
+func CurveFunc() *foundations.Func {
+	name := "curve"
+	scope := foundations.NewScope()
+	scope.Define("move", foundations.FuncValue{Func: curveComponentFunc("move", CurveMoveDef, parseCurveMove)}, syntax.Detached())
+	scope.Define("line", foundations.FuncValue{Func: curveComponentFunc("line", CurveLineDef, parseCurveLine)}, syntax.Detached())
+	scope.Define("quad", foundations.FuncValue{Func: curveComponentFunc("quad", CurveQuadDef, parseCurveQuad)}, syntax.Detached())
+	scope.Define("cubic", foundations.FuncValue{Func: curveComponentFunc("cubic", CurveCubicDef, parseCurveCubic)}, syntax.Detached())
+	scope.Define("close", foundations.FuncValue{Func: curveComponentFunc("close", CurveCloseDef, parseCurveClose)}, syntax.Detached())
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func:  curveNative,
+			Info:  CurveDef.ToFuncInfo(),
+			Scope: scope,
+		},
+	}
+}
+
+// curveNative implements the curve() function. The components, fill rule,
+// and stroke are checked here so that errors point at the call.
+func curveNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[CurveElement](CurveDef, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range elem.Components {
+		switch foundations.ContentElem(component).(type) {
+		case *CurveMoveElem, *CurveLineElem, *CurveQuadElem, *CurveCubicElem, *CurveCloseElem:
+		default:
+			return nil, &foundations.TypeMismatchError{Expected: "curve component", Got: "content", Field: "components", Span: span}
+		}
+	}
+	if _, err := castFillRule(elem.FillRule, span); err != nil {
+		return nil, err
+	}
+	if !foundations.IsNone(elem.Stroke) {
+		if _, err := CastStroke(elem.Stroke, span); err != nil {
+			return nil, err
+		}
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// curveComponentFunc creates the function of a curve component, which
+// parses its arguments with parse.
+func curveComponentFunc(name string, def *foundations.ElementDef, parse func(args *foundations.Args) (foundations.ContentElement, error)) *foundations.Func {
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: func(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+				elem, err := parse(args)
+				if err != nil {
+					return nil, err
+				}
+				return foundations.ContentValue{Content: foundations.Content{
+					Elements: []foundations.ContentElement{elem},
+				}}, nil
+			},
+			Info: def.ToFuncInfo(),
+		},
+	}
+}
+
+func parseCurveMove(args *foundations.Args) (foundations.ContentElement, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[CurveMoveElem](CurveMoveDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castLinePoint(elem.Start, span); err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+func parseCurveLine(args *foundations.Args) (foundations.ContentElement, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[CurveLineElem](CurveLineDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castLinePoint(elem.End, span); err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+func parseCurveQuad(args *foundations.Args) (foundations.ContentElement, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[CurveQuadElem](CurveQuadDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castControlPoint(elem.Control, span); err != nil {
+		return nil, err
+	}
+	if _, err := castLinePoint(elem.End, span); err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+func parseCurveCubic(args *foundations.Args) (foundations.ContentElement, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[CurveCubicElem](CurveCubicDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castControlPoint(elem.ControlStart, span); err != nil {
+		return nil, err
+	}
+	if foundations.IsAuto(elem.ControlEnd) {
+		return nil, &foundations.TypeMismatchError{Expected: "array or none", Got: "auto", Field: "control-end", Span: span}
+	}
+	if _, err := castControlPoint(elem.ControlEnd, span); err != nil {
+		return nil, err
+	}
+	if _, err := castLinePoint(elem.End, span); err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+func parseCurveClose(args *foundations.Args) (foundations.ContentElement, error) {
+	elem, err := foundations.ParseElement[CurveCloseElem](CurveCloseDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Mode != nil && *elem.Mode != "smooth" && *elem.Mode != "straight" {
+		return nil, &foundations.TypeMismatchError{Expected: "\"smooth\" or \"straight\"", Got: "\"" + *elem.Mode + "\"", Field: "mode", Span: args.Span}
+	}
+	return elem, nil
+}
+
+// castControlPoint casts a control point, which may also be none or auto.
+// It returns nil for those.
+func castControlPoint(v foundations.Value, span syntax.Span) (*[2]foundations.Relative, error) {
+	if foundations.IsNone(v) || foundations.IsAuto(v) {
+		return nil, nil
+	}
+	point, err := castLinePoint(v, span)
+	if err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+// castFillRule casts the name of a fill rule. Nil is the non-zero rule.
+func castFillRule(name *string, span syntax.Span) (FillRule, error) {
+	if name == nil {
+		return FillRuleNonZero, nil
+	}
+	switch *name {
+	case "non-zero":
+		return FillRuleNonZero, nil
+	case "even-odd":
+		return FillRuleEvenOdd, nil
+	}
+	return FillRuleNonZero, &foundations.TypeMismatchError{Expected: "\"non-zero\" or \"even-odd\"", Got: "\"" + *name + "\"", Field: "fill-rule", Span: span}
+}
+
+// CurveLayout is a laid out curve.
+type CurveLayout struct {
+	// Size is the size of the curve's frame, the size of its bounding box.
+	// Parts of the curve at negative coordinates overhang the frame.
+	Size layout.Size
+	// Curve is the outline of the curve in the frame.
+	Curve Curve
+	// Fill is the paint the curve is filled with, or nil.
+	Fill foundations.Value
+	// FillRule decides which parts of the curve are filled.
+	FillRule FillRule
+	// Stroke is how the curve is stroked, or nil if it isn't.
+	Stroke *Stroke
+}
+
+// Layout lays the curve out in a region. Relative lengths in the points
+// are resolved against the region's width and height.
+// Matches Rust: layout_curve
+func (e *CurveElement) Layout(region layout.Size, span syntax.Span) (CurveLayout, error) {
+	var b curveBuilder
+	for _, component := range e.Components {
+		if err := b.add(foundations.ContentElem(component), region, span); err != nil {
+			return CurveLayout{}, err
+		}
+	}
+
+	rule, err := castFillRule(e.FillRule, span)
+	if err != nil {
+		return CurveLayout{}, err
+	}
+	out := CurveLayout{Curve: b.curve, FillRule: rule}
+	if !foundations.IsNone(e.Fill) {
+		out.Fill = e.Fill
+	}
+	if !foundations.IsNone(e.Stroke) && (e.Stroke != nil || out.Fill == nil) {
+		stroke, err := CastStroke(e.Stroke, span)
+		if err != nil {
+			return CurveLayout{}, err
+		}
+		out.Stroke = &stroke
+	}
+
+	size := b.curve.BBoxSize()
+	if math.IsInf(float64(size.Width), 0) || math.IsInf(float64(size.Height), 0) {
+		return CurveLayout{}, foundations.NewSourceError(span, "cannot create curve with infinite size")
+	}
+	out.Size = size
+	return out, nil
+}
+
+// curveBuilder turns curve components into a curve.
+type curveBuilder struct {
+	curve Curve
+	// start is the start of the current subpath, and last the end of the
+	// previous component.
+	start, last layout.Point
+	// started reports whether a subpath is open.
+	started bool
+	// startControl is the first control point of the subpath, and
+	// lastControl the last control point of the previous component, if it
+	// was a curve.
+	startControl, lastControl *layout.Point
+}
+
+// add adds a component to the curve.
+func (b *curveBuilder) add(component foundations.ContentElement, region layout.Size, span syntax.Span) error {
+	resolve := func(v foundations.Value, relative bool) (layout.Point, error) {
+		point, err := castLinePoint(v, span)
+		if err != nil {
+			return layout.Point{}, err
+		}
+		p := resolveLinePoint(point, region)
+		if relative {
+			p = layout.Point{X: b.last.X + p.X, Y: b.last.Y + p.Y}
+		}
+		return p, nil
+	}
+	// control resolves a control point. None is the fallback, and auto the
+	// mirror image of the previous control point.
+	control := func(v foundations.Value, relative bool, fallback layout.Point) (layout.Point, error) {
+		switch {
+		case foundations.IsNone(v):
+			return fallback, nil
+		case foundations.IsAuto(v):
+			return b.mirrored(), nil
+		}
+		return resolve(v, relative)
+	}
+
+	switch c := component.(type) {
+	case *CurveMoveElem:
+		p, err := resolve(c.Start, c.Relative)
+		if err != nil {
+			return err
+		}
+		b.curve.MoveTo(p)
+		b.start, b.last, b.started = p, p, true
+		b.startControl, b.lastControl = nil, nil
+
+	case *CurveLineElem:
+		p, err := resolve(c.End, c.Relative)
+		if err != nil {
+			return err
+		}
+		b.begin()
+		b.curve.LineTo(p)
+		b.last, b.lastControl = p, nil
+
+	case *CurveQuadElem:
+		end, err := resolve(c.End, c.Relative)
+		if err != nil {
+			return err
+		}
+		ctrl, err := control(c.Control, c.Relative, end)
+		if err != nil {
+			return err
+		}
+		b.begin()
+		// A quadratic curve is a cubic one with its control points two
+		// thirds of the way towards the quadratic control point.
+		from := b.last
+		b.curve.CubicTo(
+			layout.Point{X: from.X + (ctrl.X-from.X)*2/3, Y: from.Y + (ctrl.Y-from.Y)*2/3},
+			layout.Point{X: end.X + (ctrl.X-end.X)*2/3, Y: end.Y + (ctrl.Y-end.Y)*2/3},
+			end,
+		)
+		b.controlled(ctrl, ctrl)
+		b.last = end
+
+	case *CurveCubicElem:
+		end, err := resolve(c.End, c.Relative)
+		if err != nil {
+			return err
+		}
+		c1, err := control(c.ControlStart, c.Relative, b.last)
+		if err != nil {
+			return err
+		}
+		c2, err := control(c.ControlEnd, c.Relative, end)
+		if err != nil {
+			return err
+		}
+		b.begin()
+		b.curve.CubicTo(c1, c2, end)
+		b.controlled(c1, c2)
+		b.last = end
+
+	case *CurveCloseElem:
+		if !b.started {
+			return nil
+		}
+		smooth := c.Mode == nil || *c.Mode == "smooth"
+		if smooth && (b.lastControl != nil || b.startControl != nil) {
+			c1 := b.mirrored()
+			c2 := b.start
+			if b.startControl != nil {
+				c2 = layout.Point{X: 2*b.start.X - b.startControl.X, Y: 2*b.start.Y - b.startControl.Y}
+			}
+			b.curve.CubicTo(c1, c2, b.start)
+		}
+		b.curve.Close()
+		b.last, b.started = b.start, false
+		b.startControl, b.lastControl = nil, nil
+
+	default:
+		return &foundations.TypeMismatchError{Expected: "curve component", Got: "content", Field: "components", Span: span}
+	}
+	return nil
+}
+
+// begin starts a subpath at the current point if none is open, as when a
+// curve starts with a line or follows a close.
+func (b *curveBuilder) begin() {
+	if !b.started {
+		b.curve.MoveTo(b.last)
+		b.start, b.started = b.last, true
+		b.startControl = nil
+	}
+}
+
+// controlled records the control points of a curve component that was
+// just added: first follows the component's start and last precedes its
+// end.
+func (b *curveBuilder) controlled(first, last layout.Point) {
+	if n := len(b.curve); n >= 2 {
+		if _, ok := b.curve[n-2].(CurveMove); ok {
+			b.startControl = &first
+		}
+	}
+	b.lastControl = &last
+}
+
+// mirrored returns the mirror image of the previous control point at the
+// current point, or the current point if the previous component wasn't a
+// curve.
+func (b *curveBuilder) mirrored() layout.Point {
+	if b.lastControl == nil {
+		return b.last
+	}
+	return layout.Point{X: 2*b.last.X - b.lastControl.X, Y: 2*b.last.Y - b.lastControl.Y}
+}
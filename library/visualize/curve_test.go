@@ -0,0 +1,196 @@
+package visualize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func TestCurveBBox(t *testing.T) {
+	if got := (Curve{}).BBox(); got != (Rect{}) {
+		t.Errorf("BBox of an empty curve = %+v, want empty", got)
+	}
+
+	rect := RectCurve(layout.Size{Width: 20, Height: 10}).Transform(TranslateTransform(5, -3))
+	if got := rect.BBox(); !approxPoint(got.Min, layout.Point{X: 5, Y: -3}) || !approxPoint(got.Max, layout.Point{X: 25, Y: 7}) {
+		t.Errorf("BBox of a translated rectangle = %+v", got)
+	}
+
+	// The control points lie far above the arc, which only reaches three
+	// quarters of their height.
+	var arc Curve
+	arc.MoveTo(layout.Point{})
+	arc.CubicTo(layout.Point{Y: -40}, layout.Point{X: 40, Y: -40}, layout.Point{X: 40})
+	if got := arc.BBoxSize(); !approxSize(got, layout.Size{Width: 40, Height: 30}) {
+		t.Errorf("BBoxSize of an arc = %+v, want 40x30", got)
+	}
+}
+
+func TestCurveContains(t *testing.T) {
+	// A square with a square hole, both drawn clockwise.
+	var c Curve
+	for _, square := range [][2]layout.Abs{{0, 30}, {10, 20}} {
+		lo, hi := square[0], square[1]
+		c.MoveTo(layout.Point{X: lo, Y: lo})
+		c.LineTo(layout.Point{X: hi, Y: lo})
+		c.LineTo(layout.Point{X: hi, Y: hi})
+		c.LineTo(layout.Point{X: lo, Y: hi})
+		c.Close()
+	}
+
+	tests := []struct {
+		p                layout.Point
+		nonZero, evenOdd bool
+	}{
+		{layout.Point{X: 5, Y: 5}, true, true},
+		{layout.Point{X: 15, Y: 15}, true, false},
+		{layout.Point{X: 40, Y: 15}, false, false},
+		{layout.Point{X: 15, Y: -1}, false, false},
+	}
+	for _, tt := range tests {
+		if got := c.Contains(tt.p, FillRuleNonZero); got != tt.nonZero {
+			t.Errorf("Contains(%v, non-zero) = %v, want %v", tt.p, got, tt.nonZero)
+		}
+		if got := c.Contains(tt.p, FillRuleEvenOdd); got != tt.evenOdd {
+			t.Errorf("Contains(%v, even-odd) = %v, want %v", tt.p, got, tt.evenOdd)
+		}
+	}
+
+	// Open subpaths are closed implicitly, and curves are followed.
+	var arc Curve
+	arc.MoveTo(layout.Point{})
+	arc.CubicTo(layout.Point{Y: -40}, layout.Point{X: 40, Y: -40}, layout.Point{X: 40})
+	if !arc.Contains(layout.Point{X: 20, Y: -25}, FillRuleNonZero) {
+		t.Error("arc doesn't contain a point below its top")
+	}
+	if arc.Contains(layout.Point{X: 20, Y: -35}, FillRuleNonZero) {
+		t.Error("arc contains a point between it and its control points")
+	}
+}
+
+func TestTransform(t *testing.T) {
+	rotate := RotateTransform(math.Pi / 2)
+	if got := rotate.Apply(layout.Point{X: 10}); !approxPoint(got, layout.Point{Y: 10}) {
+		t.Errorf("rotating (10, 0) clockwise = %v, want (0, 10)", got)
+	}
+
+	ts := ScaleTransform(2, 3).Then(TranslateTransform(1, 1))
+	if got := ts.Apply(layout.Point{X: 1, Y: 1}); !approxPoint(got, layout.Point{X: 3, Y: 4}) {
+		t.Errorf("scale then translate = %v, want (3, 4)", got)
+	}
+	inv, ok := ts.Then(rotate).Invert()
+	if !ok {
+		t.Fatal("transformation not invertible")
+	}
+	p := layout.Point{X: 7, Y: -2}
+	if got := inv.Apply(ts.Then(rotate).Apply(p)); !approxPoint(got, p) {
+		t.Errorf("inverse maps back to %v, want %v", got, p)
+	}
+	if _, ok := ScaleTransform(0, 1).Invert(); ok {
+		t.Error("collapsing transformation is invertible")
+	}
+
+	// A 20x10 box turned by 90° occupies 10x20.
+	box := TransformedBBox(layout.Size{Width: 20, Height: 10}, rotate)
+	if !approxSize(box.Size(), layout.Size{Width: 10, Height: 20}) || !approxPoint(box.Min, layout.Point{X: -10}) {
+		t.Errorf("TransformedBBox = %+v", box)
+	}
+}
+
+// callCurveFunc calls a function of the curve element's scope, or curve
+// itself for an empty name, with positional and named arguments.
+func callCurveFunc(t *testing.T, name string, positional []foundations.Value, named map[string]foundations.Value) (foundations.Value, error) {
+	t.Helper()
+	f := CurveFunc()
+	if name != "" {
+		binding := f.Repr.(foundations.NativeFunc).Scope.Get(name)
+		if binding == nil {
+			t.Fatalf("curve has no %s", name)
+		}
+		f = binding.Read().(foundations.FuncValue).Func
+	}
+	args := lineArgs(named)
+	for _, v := range positional {
+		args.Items = append(args.Items, foundations.Arg{Value: syntax.Spanned[foundations.Value]{V: v}})
+	}
+	return f.Repr.(foundations.NativeFunc).Func(foundations.Engine{}, foundations.Context{}, args)
+}
+
+func TestCurveLayout(t *testing.T) {
+	pt := func(x, y float64) foundations.Value {
+		return foundations.NewArray(
+			foundations.LengthValue{Length: foundations.Length{Points: x}},
+			foundations.LengthValue{Length: foundations.Length{Points: y}},
+		)
+	}
+	component := func(name string, relative bool, points ...foundations.Value) foundations.Value {
+		var named map[string]foundations.Value
+		if relative {
+			named = map[string]foundations.Value{"relative": foundations.True}
+		}
+		value, err := callCurveFunc(t, name, points, named)
+		if err != nil {
+			t.Fatalf("curve.%s: %v", name, err)
+		}
+		return value
+	}
+
+	value, err := callCurveFunc(t, "", []foundations.Value{
+		component("move", false, pt(0, 10)),
+		component("line", true, pt(20, 0)),
+		component("quad", false, foundations.NoneValue{}, pt(20, 30)),
+		component("cubic", false, foundations.AutoValue{}, foundations.NoneValue{}, pt(0, 30)),
+		component("close", false),
+	}, map[string]foundations.Value{"fill": foundations.NewLuma(0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	elem := value.(foundations.ContentValue).Content.Elements[0].(*CurveElement)
+	got, err := elem.Layout(layout.Size{Width: 100, Height: 100}, syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The frame has the size of the bounding box, which starts at y=10.
+	if !approxSize(got.Size, layout.Size{Width: 20, Height: 20}) {
+		t.Errorf("Size = %v, want 20x20", got.Size)
+	}
+	if got.Fill == nil || got.Stroke != nil {
+		t.Errorf("filled curve has fill %v and stroke %v, want a fill and no stroke", got.Fill, got.Stroke)
+	}
+	if len(got.Curve) != 6 {
+		t.Fatalf("Curve = %+v, want move, line, two cubics, a closing segment, and close", got.Curve)
+	}
+	if move, ok := got.Curve[0].(CurveMove); !ok || !approxPoint(move.To, layout.Point{Y: 10}) {
+		t.Errorf("Curve[0] = %+v, want a move to (0, 10)", got.Curve[0])
+	}
+	if line, ok := got.Curve[1].(CurveLine); !ok || !approxPoint(line.To, layout.Point{X: 20, Y: 10}) {
+		t.Errorf("Curve[1] = %+v, want a relative line to (20, 10)", got.Curve[1])
+	}
+	// The quad without a control point is straight, and the cubic's auto
+	// control point mirrors its control point at (20, 30).
+	if cubic, ok := got.Curve[3].(CurveCubic); !ok || !approxPoint(cubic.Control1, layout.Point{X: 20, Y: 30}) {
+		t.Errorf("Curve[3] = %+v, want a cubic starting towards (20, 30)", got.Curve[3])
+	}
+	if _, ok := got.Curve[5].(CurveClose); !ok {
+		t.Errorf("Curve[5] = %+v, want close", got.Curve[5])
+	}
+}
+
+func TestCurveErrors(t *testing.T) {
+	if _, err := callCurveFunc(t, "", []foundations.Value{foundations.ContentValue{}}, nil); err == nil {
+		t.Error("curve accepted content that is no component")
+	}
+	if _, err := callCurveFunc(t, "", nil, map[string]foundations.Value{"fill-rule": foundations.Str("odd")}); err == nil {
+		t.Error("curve accepted an unknown fill rule")
+	}
+	if _, err := callCurveFunc(t, "close", nil, map[string]foundations.Value{"mode": foundations.Str("round")}); err == nil {
+		t.Error("curve.close accepted an unknown mode")
+	}
+	if _, err := callCurveFunc(t, "line", []foundations.Value{foundations.Int(1)}, nil); err == nil {
+		t.Error("curve.line accepted an integer as a point")
+	}
+}
@@ -0,0 +1,257 @@
+// Image element for Typst.
+// Translated from typst-library/src/visualize/image/mod.rs
+
+package visualize
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// ImageFormat is the encoding of image data.
+type ImageFormat string
+
+const (
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatJPEG ImageFormat = "jpg"
+	ImageFormatGIF  ImageFormat = "gif"
+	ImageFormatWebP ImageFormat = "webp"
+	ImageFormatSVG  ImageFormat = "svg"
+)
+
+// imageFormats are the formats accepted by the format argument, in the
+// order they are listed in error messages.
+var imageFormats = []ImageFormat{ImageFormatPNG, ImageFormatJPEG, ImageFormatGIF, ImageFormatSVG, ImageFormatWebP}
+
+// DetectImageFormat determines the format of image data from its first
+// bytes.
+// Matches Rust: ImageFormat::detect
+func DetectImageFormat(data []byte) (ImageFormat, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ImageFormatPNG, true
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return ImageFormatJPEG, true
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return ImageFormatGIF, true
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return ImageFormatWebP, true
+	}
+
+	// SVGs are text and may start with an XML declaration, a doctype, or
+	// comments before the root element.
+	head := data[:min(len(data), 2048)]
+	if bytes.Contains(head, []byte("<svg")) {
+		return ImageFormatSVG, true
+	}
+	return "", false
+}
+
+// ImageElement represents an image.
+//
+// Reference: typst-reference/crates/typst-library/src/visualize/image/mod.rs
+type ImageElement struct {
+	// Source is a path to the image file or the raw bytes of the image.
+	Source foundations.Value `typst:"source,positional,required"`
+	// Format of the image. If nil or auto, it is detected from the data.
+	Format foundations.Value `typst:"format"`
+	// Width of the image.
+	Width *foundations.Relative `typst:"width,type=relative"`
+	// Height of the image.
+	Height *foundations.Relative `typst:"height,type=relative"`
+	// Alt is a text describing the image.
+	Alt *string `typst:"alt,type=str"`
+	// Fit is how the image fills its area: "cover", "contain", or "stretch".
+	Fit *string `typst:"fit,type=str"`
+
+	// Data is the encoded image, loaded from the source.
+	Data []byte
+	// Kind is the resolved format of Data.
+	Kind ImageFormat
+
+	foundations.ElemMeta
+}
+
+func (*ImageElement) IsContentElement() {}
+
+// ImageDef is the registered element definition for image.
+var ImageDef *foundations.ElementDef
+
+func init() {
+	ImageDef = foundations.RegisterElement[ImageElement]("image", nil)
+}
+
+// ImageFunc creates the image element function. Its scope holds decode.
+func ImageFunc() *foundations.Func {
+	name := "image"
+	scope := foundations.NewScope()
+	scope.Define("decode", foundations.FuncValue{Func: imageDecodeFunc()}, syntax.Detached())
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func:  imageNative,
+			Info:  ImageDef.ToFuncInfo(),
+			Scope: scope,
+		},
+	}
+}
+
+// imageNative implements the image() function. A string source is a path
+// that is read through the world, bytes are the image itself.
+func imageNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[ImageElement](ImageDef, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var path string
+	switch source := elem.Source.(type) {
+	case foundations.Str:
+		path = string(source)
+		if elem.Data, err = loadImageFile(engine.World, span, path); err != nil {
+			return nil, err
+		}
+	case foundations.BytesValue:
+		elem.Data = source
+	default:
+		return nil, &foundations.TypeMismatchError{Expected: "string or bytes", Got: source.Type().String(), Field: "source", Span: span}
+	}
+
+	if elem.Kind, err = resolveImageFormat(elem.Format, elem.Data, path, span); err != nil {
+		return nil, err
+	}
+	return imageContent(elem), nil
+}
+
+// imageDecodeFunc creates the image.decode function, which creates an image
+// from data in memory. Unlike image(), it treats a string as the textual
+// content of an SVG rather than as a path.
+//
+// Matches Rust: ImageElem::decode
+func imageDecodeFunc() *foundations.Func {
+	name := "decode"
+	info := *ImageDef.ToFuncInfo()
+	info.Name = name
+	info.Params = append([]foundations.ParamInfo{}, info.Params...)
+	info.Params[0].Name = "data"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: imageDecodeNative,
+			Info: &info,
+		},
+	}
+}
+
+func imageDecodeNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[ImageElement](ImageDef, args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch data := elem.Source.(type) {
+	case foundations.Str:
+		elem.Data = []byte(data)
+	case foundations.BytesValue:
+		elem.Data = data
+	default:
+		return nil, &foundations.TypeMismatchError{Expected: "string or bytes", Got: data.Type().String(), Field: "data", Span: span}
+	}
+
+	if elem.Kind, err = resolveImageFormat(elem.Format, elem.Data, "", span); err != nil {
+		return nil, err
+	}
+	return imageContent(elem), nil
+}
+
+func imageContent(elem *ImageElement) foundations.Value {
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}
+}
+
+// sourceSpan returns the span of the first positional argument, falling
+// back to the span of the call.
+func sourceSpan(args *foundations.Args) syntax.Span {
+	for _, item := range args.Items {
+		if item.Name == nil {
+			return item.Value.Span
+		}
+	}
+	return args.Span
+}
+
+// loadImageFile reads an image file relative to the file the span is in.
+func loadImageFile(world foundations.World, span syntax.Span, path string) ([]byte, error) {
+	id := span.Id()
+	if world == nil || id == nil {
+		return nil, foundations.NewSourceError(span, "cannot access file system from here")
+	}
+	file, err := id.Join(path)
+	if err != nil {
+		return nil, foundations.NewSourceError(span, fmt.Sprintf("invalid path %q: %v", path, err))
+	}
+	data, err := world.File(file)
+	if err != nil {
+		return nil, foundations.NewSourceError(span, fmt.Sprintf("failed to load image (%v)", err))
+	}
+	return data, nil
+}
+
+// resolveImageFormat determines the format of image data. An explicit
+// format is checked against the known formats. Otherwise the format is
+// detected from the data and, failing that, from the extension of the path.
+func resolveImageFormat(format foundations.Value, data []byte, path string, span syntax.Span) (ImageFormat, error) {
+	switch f := format.(type) {
+	case nil, foundations.AutoValue:
+	case foundations.Str:
+		if known, ok := parseImageFormat(string(f)); ok {
+			return known, nil
+		}
+		return "", foundations.NewSourceError(span, `expected "png", "jpg", "gif", "svg", or "webp"`)
+	default:
+		return "", &foundations.TypeMismatchError{Expected: "auto or string", Got: f.Type().String(), Field: "format", Span: span}
+	}
+
+	if detected, ok := DetectImageFormat(data); ok {
+		return detected, nil
+	}
+
+	if known, ok := parseImageFormat(strings.TrimPrefix(extension(path), ".")); ok {
+		return known, nil
+	}
+
+	return "", foundations.NewSourceError(span, "unknown image format").
+		WithHint("try specifying the format explicitly")
+}
+
+// parseImageFormat parses a format name or file extension.
+func parseImageFormat(name string) (ImageFormat, bool) {
+	name = strings.ToLower(name)
+	if name == "jpeg" {
+		name = string(ImageFormatJPEG)
+	}
+	for _, known := range imageFormats {
+		if ImageFormat(name) == known {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// extension returns the extension of the final component of a path.
+func extension(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+	if i := strings.LastIndex(name, "."); i > 0 {
+		return name[i:]
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package visualize
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// pngHeader is the start of every PNG file.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// imageWorld serves files from memory.
+type imageWorld struct {
+	files map[string][]byte
+}
+
+func (w *imageWorld) Library() *foundations.Scope { return foundations.NewScope() }
+func (w *imageWorld) MainFile() syntax.FileId     { panic("no main file") }
+func (w *imageWorld) Source(id syntax.FileId) (*syntax.Source, error) {
+	return nil, errors.New("not a source")
+}
+func (w *imageWorld) Today(offset *int) *foundations.Datetime { return nil }
+
+func (w *imageWorld) File(id syntax.FileId) ([]byte, error) {
+	if data, ok := w.files[id.Get().VPath().GetWithSlash()]; ok {
+		return data, nil
+	}
+	return nil, errors.New("file not found")
+}
+
+func fileId(t *testing.T, path string) syntax.FileId {
+	vpath, err := syntax.NewVirtualPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern()
+}
+
+// callImage calls a native function with a source argument located in
+// /doc/main.typ and the given named arguments.
+func callImage(t *testing.T, f *foundations.Func, world foundations.World, source foundations.Value, named map[string]foundations.Value) (*ImageElement, error) {
+	t.Helper()
+	span := syntax.SpanFromRange(fileId(t, "/doc/main.typ"), 0, 1)
+	args := foundations.NewArgs(span, source)
+	for name, value := range named {
+		key := foundations.Str(name)
+		args.Items = append(args.Items, foundations.Arg{Span: span, Name: &key, Value: syntax.Spanned[foundations.Value]{V: value, Span: span}})
+	}
+	native := f.Repr.(foundations.NativeFunc)
+	value, err := native.Func(foundations.Engine{World: world}, foundations.Context{}, args)
+	if err != nil {
+		return nil, err
+	}
+	return value.(foundations.ContentValue).Content.Elements[0].(*ImageElement), nil
+}
+
+func TestImageFromPath(t *testing.T) {
+	world := &imageWorld{files: map[string][]byte{"/doc/logo.png": []byte(pngHeader + "rest")}}
+	elem, err := callImage(t, ImageFunc(), world, foundations.Str("logo.png"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elem.Kind != ImageFormatPNG || string(elem.Data) != pngHeader+"rest" {
+		t.Errorf("got %s image with data %q", elem.Kind, elem.Data)
+	}
+
+	if _, err := callImage(t, ImageFunc(), world, foundations.Str("missing.png"), nil); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
+
+func TestImageFromBytes(t *testing.T) {
+	data := foundations.BytesValue(pngHeader + "rest")
+	elem, err := callImage(t, ImageFunc(), nil, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elem.Kind != ImageFormatPNG {
+		t.Errorf("Kind = %s, want png", elem.Kind)
+	}
+
+	// An explicit format wins over detection.
+	elem, err = callImage(t, ImageFunc(), nil, data, map[string]foundations.Value{"format": foundations.Str("JPEG")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elem.Kind != ImageFormatJPEG {
+		t.Errorf("Kind = %s, want jpg", elem.Kind)
+	}
+
+	_, err = callImage(t, ImageFunc(), nil, data, map[string]foundations.Value{"format": foundations.Str("bmp")})
+	if err == nil || !strings.Contains(err.Error(), `expected "png"`) {
+		t.Errorf("err = %v, want list of formats", err)
+	}
+
+	_, err = callImage(t, ImageFunc(), nil, foundations.BytesValue("garbage"), nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown image format") {
+		t.Errorf("err = %v, want unknown format", err)
+	}
+}
+
+func TestImageDecode(t *testing.T) {
+	decode := ImageFunc().Scope().Get("decode").Read().(foundations.FuncValue).Func
+	svg := `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`
+	elem, err := callImage(t, decode, nil, foundations.Str(svg), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elem.Kind != ImageFormatSVG || string(elem.Data) != svg {
+		t.Errorf("got %s image with data %q", elem.Kind, elem.Data)
+	}
+}
+
+func TestDetectImageFormat(t *testing.T) {
+	tests := []struct {
+		data string
+		want ImageFormat
+	}{
+		{pngHeader, ImageFormatPNG},
+		{"\xff\xd8\xff\xe0", ImageFormatJPEG},
+		{"GIF89a", ImageFormatGIF},
+		{"RIFF\x00\x00\x00\x00WEBPVP8 ", ImageFormatWebP},
+		{"<svg>", ImageFormatSVG},
+		{"plain text", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := DetectImageFormat([]byte(tt.data)); got != tt.want {
+			t.Errorf("DetectImageFormat(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
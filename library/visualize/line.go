@@ -0,0 +1,183 @@
+package visualize
+
+import (
+	"math"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// DefaultLineLength is the length of a line without length or end.
+var DefaultLineLength = foundations.Length{Points: 30}
+
+// LineElement is a line from a start point to an end point, like a
+// horizontal rule: `line(length: 100%)`. Without an end, the line has a
+// length and an angle from the start point.
+//
+// Reference: typst-reference/crates/typst-library/src/visualize/line.rs
+type LineElement struct {
+	// Start is the start point as an array of two relative lengths
+	// (x, y). If nil, the line starts at (0pt, 0pt).
+	Start foundations.Value `typst:"start"`
+	// End is the end point. If nil or none, the end is computed from
+	// length and angle.
+	End foundations.Value `typst:"end"`
+	// Length is the line's length, relative to the width of the
+	// container. Only used without an end. If nil, DefaultLineLength.
+	Length *foundations.Relative `typst:"length,type=relative"`
+	// Angle is the line's angle, clockwise from the x-axis. Only used
+	// without an end.
+	Angle *foundations.Angle `typst:"angle,type=angle"`
+	// Stroke is how to draw the line: a length, a paint, or a dictionary.
+	// If nil, it is a 1pt black stroke.
+	Stroke foundations.Value `typst:"stroke"`
+	foundations.ElemMeta
+}
+
+func (*LineElement) IsContentElement() {}
+
+// LineDef is the registered element definition for line.
+var LineDef *foundations.ElementDef
+
+func init() {
+	LineDef = foundations.RegisterElement[LineElement]("line", nil)
+}
+
+// LineFunc creates the line element function.
+func LineFunc() *foundations.Func {
+	name := "line"
+	return &foundations.Func{
+		Name: &name,
+		Span: syntax.Detached(),
+		Repr: foundations.NativeFunc{
+			Func: lineNative,
+			Info: LineDef.ToFuncInfo(),
+		},
+	}
+}
+
+// lineNative implements the line() function. Points and stroke are
+// checked here so that errors point at the call.
+func lineNative(engine foundations.Engine, context foundations.Context, args *foundations.Args) (foundations.Value, error) {
+	span := sourceSpan(args)
+	elem, err := foundations.ParseElement[LineElement](LineDef, args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := castLinePoint(elem.Start, span); err != nil {
+		return nil, err
+	}
+	if !foundations.IsNone(elem.End) {
+		if _, err := castLinePoint(elem.End, span); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := CastStroke(elem.Stroke, span); err != nil {
+		return nil, err
+	}
+	return foundations.ContentValue{Content: foundations.Content{
+		Elements: []foundations.ContentElement{elem},
+	}}, nil
+}
+
+// LineLayout is a laid out line: a frame of the given size with a line
+// from Start to Start+Delta.
+type LineLayout struct {
+	// Size is the size of the line's frame. It spans from the origin to
+	// the farthest point of the line, so lines into negative coordinates
+	// overhang their frame.
+	Size layout.Size
+	// Start is the position of the line's start in the frame.
+	Start layout.Point
+	// Delta is the offset from the start to the end of the line.
+	Delta layout.Point
+	// Stroke is how the line is drawn.
+	Stroke Stroke
+}
+
+// Layout lays the line out in a region. Relative lengths in the points
+// are resolved against the region's width and height, and the length
+// against its width.
+// Matches Rust: layout_line
+func (e *LineElement) Layout(region layout.Size, span syntax.Span) (LineLayout, error) {
+	start, err := castLinePoint(e.Start, span)
+	if err != nil {
+		return LineLayout{}, err
+	}
+	stroke, err := CastStroke(e.Stroke, span)
+	if err != nil {
+		return LineLayout{}, err
+	}
+	origin := resolveLinePoint(start, region)
+
+	var delta layout.Point
+	if e.End != nil && !foundations.IsNone(e.End) {
+		end, err := castLinePoint(e.End, span)
+		if err != nil {
+			return LineLayout{}, err
+		}
+		target := resolveLinePoint(end, region)
+		delta = layout.Point{X: target.X - origin.X, Y: target.Y - origin.Y}
+	} else {
+		length := foundations.Relative{Abs: DefaultLineLength}
+		if e.Length != nil {
+			length = *e.Length
+		}
+		var angle float64
+		if e.Angle != nil {
+			angle = e.Angle.Radians
+		}
+		abs := resolveRelative(length, region.Width)
+		delta = layout.Point{X: layout.Abs(math.Cos(angle)) * abs, Y: layout.Abs(math.Sin(angle)) * abs}
+	}
+
+	size := layout.Size{
+		Width:  max(origin.X, origin.X+delta.X, 0),
+		Height: max(origin.Y, origin.Y+delta.Y, 0),
+	}
+	if math.IsInf(float64(size.Width), 0) || math.IsInf(float64(size.Height), 0) {
+		return LineLayout{}, foundations.NewSourceError(span, "cannot create line with infinite length")
+	}
+	return LineLayout{Size: size, Start: origin, Delta: delta, Stroke: stroke}, nil
+}
+
+// castLinePoint casts a line's start or end to an x and y relative length.
+// Nil is the origin.
+func castLinePoint(v foundations.Value, span syntax.Span) ([2]foundations.Relative, error) {
+	var point [2]foundations.Relative
+	if v == nil {
+		return point, nil
+	}
+	array, ok := v.(*foundations.Array)
+	if !ok || array.Len() != 2 {
+		return point, foundations.NewSourceError(span, "point array must contain exactly two entries")
+	}
+	for i, item := range array.Items() {
+		switch item := item.(type) {
+		case foundations.LengthValue:
+			point[i] = foundations.Relative{Abs: item.Length}
+		case foundations.RatioValue:
+			point[i] = foundations.Relative{Rel: item.Ratio}
+		case foundations.RelativeValue:
+			point[i] = item.Relative
+		default:
+			return point, &foundations.TypeMismatchError{Expected: "relative length", Got: item.Type().String(), Span: span}
+		}
+	}
+	return point, nil
+}
+
+// resolveLinePoint resolves a point relative to the size of a region.
+func resolveLinePoint(point [2]foundations.Relative, region layout.Size) layout.Point {
+	return layout.Point{X: resolveRelative(point[0], region.Width), Y: resolveRelative(point[1], region.Height)}
+}
+
+// resolveRelative resolves a relative length against a base length. An
+// absolute length stays finite in regions of infinite size.
+func resolveRelative(rel foundations.Relative, base layout.Abs) layout.Abs {
+	if rel.Rel.Value == 0 {
+		return layout.Abs(rel.Abs.Points)
+	}
+	return layout.Abs(rel.Abs.Points) + layout.Abs(rel.Rel.Value)*base
+}
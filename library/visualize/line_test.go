@@ -0,0 +1,122 @@
+package visualize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+func lineArgs(named map[string]foundations.Value) *foundations.Args {
+	args := foundations.NewArgs(syntax.Detached())
+	for key, value := range named {
+		name := foundations.Str(key)
+		args.Items = append(args.Items, foundations.Arg{Name: &name, Value: syntax.Spanned[foundations.Value]{V: value}})
+	}
+	return args
+}
+
+func newLine(t *testing.T, named map[string]foundations.Value) *LineElement {
+	t.Helper()
+	value, err := lineNative(foundations.Engine{}, foundations.Context{}, lineArgs(named))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.(foundations.ContentValue).Content.Elements[0].(*LineElement)
+}
+
+func TestLineLayout(t *testing.T) {
+	region := layout.Size{Width: 200, Height: 100}
+	pt := func(v float64) foundations.Value {
+		return foundations.LengthValue{Length: foundations.Length{Points: v}}
+	}
+	pct := func(v float64) foundations.Value { return foundations.RatioValue{Ratio: foundations.Ratio{Value: v}} }
+
+	tests := []struct {
+		name  string
+		named map[string]foundations.Value
+		size  layout.Size
+		start layout.Point
+		delta layout.Point
+	}{
+		{"default", nil, layout.Size{Width: 30}, layout.Point{}, layout.Point{X: 30}},
+		{"no end", map[string]foundations.Value{"end": foundations.NoneValue{}}, layout.Size{Width: 30}, layout.Point{}, layout.Point{X: 30}},
+		{"full width", map[string]foundations.Value{"length": pct(1)}, layout.Size{Width: 200}, layout.Point{}, layout.Point{X: 200}},
+		{"vertical", map[string]foundations.Value{"length": pt(10), "angle": foundations.AngleValue{Angle: foundations.Angle{Radians: math.Pi / 2}}},
+			layout.Size{Height: 10}, layout.Point{}, layout.Point{Y: 10}},
+		{"start and end", map[string]foundations.Value{
+			"start": foundations.NewArray(pt(10), pct(0.5)),
+			"end":   foundations.NewArray(pct(0.25), pt(20)),
+		}, layout.Size{Width: 50, Height: 50}, layout.Point{X: 10, Y: 50}, layout.Point{X: 40, Y: -30}},
+	}
+	for _, tt := range tests {
+		got, err := newLine(t, tt.named).Layout(region, syntax.Detached())
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !approxSize(got.Size, tt.size) || !approxPoint(got.Start, tt.start) || !approxPoint(got.Delta, tt.delta) {
+			t.Errorf("%s: size %v, start %v, delta %v; want %v, %v, %v", tt.name, got.Size, got.Start, got.Delta, tt.size, tt.start, tt.delta)
+		}
+	}
+
+	infinite := layout.Size{Width: layout.Abs(math.Inf(1)), Height: 100}
+	if _, err := newLine(t, map[string]foundations.Value{"length": pct(1)}).Layout(infinite, syntax.Detached()); err == nil {
+		t.Error("full width line in infinite region: expected an error")
+	}
+	if _, err := newLine(t, nil).Layout(infinite, syntax.Detached()); err != nil {
+		t.Errorf("fixed length line in infinite region: %v", err)
+	}
+}
+
+func TestLineErrors(t *testing.T) {
+	pt := foundations.LengthValue{Length: foundations.Length{Points: 1}}
+	for name, named := range map[string]map[string]foundations.Value{
+		"short point":    {"start": foundations.NewArray(pt)},
+		"string point":   {"end": foundations.NewArray(pt, foundations.Str("x"))},
+		"integer stroke": {"stroke": foundations.Int(1)},
+		"unknown key":    {"stroke": strokeDict(map[string]foundations.Value{"width": pt})},
+	} {
+		if _, err := lineNative(foundations.Engine{}, foundations.Context{}, lineArgs(named)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCastStroke(t *testing.T) {
+	red := foundations.NewLuma(0.5, 1)
+	stroke, err := CastStroke(strokeDict(map[string]foundations.Value{
+		"paint":     red,
+		"thickness": foundations.LengthValue{Length: foundations.Length{Points: 2}},
+		"cap":       foundations.Str("round"),
+	}), syntax.Detached())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stroke.ResolvedPaint() != red || stroke.ResolvedThickness().Points != 2 || stroke.Cap != "round" {
+		t.Errorf("stroke = %+v", stroke)
+	}
+
+	stroke, err = CastStroke(nil, syntax.Detached())
+	if err != nil || stroke.ResolvedThickness() != DefaultStrokeThickness || stroke.ResolvedPaint() != foundations.NewLuma(0, 1) {
+		t.Errorf("default stroke = %+v, %v", stroke, err)
+	}
+}
+
+func strokeDict(entries map[string]foundations.Value) *foundations.Dict {
+	dict := foundations.NewDict()
+	for key, value := range entries {
+		dict.Set(key, value)
+	}
+	return dict
+}
+
+func approxPoint(a, b layout.Point) bool {
+	return a.X.ApproxEq(b.X) && a.Y.ApproxEq(b.Y)
+}
+
+func approxSize(a, b layout.Size) bool {
+	return a.Width.ApproxEq(b.Width) && a.Height.ApproxEq(b.Height)
+}
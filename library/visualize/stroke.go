@@ -0,0 +1,94 @@
+package visualize
+
+import (
+	"fmt"
+
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// DefaultStrokeThickness is the thickness of strokes that do not specify
+// one.
+var DefaultStrokeThickness = foundations.Length{Points: 1}
+
+// Stroke describes how lines and outlines are drawn.
+//
+// Reference: typst-reference/crates/typst-library/src/visualize/stroke.rs
+type Stroke struct {
+	// Paint is the color of the stroke. If nil, it is black.
+	Paint foundations.Value
+	// Thickness is the width of the stroke. If nil, it is
+	// DefaultStrokeThickness.
+	Thickness *foundations.Length
+	// Cap is the shape of the line ends: "butt", "round", or "square". If
+	// empty, it is "butt".
+	Cap string
+}
+
+// CastStroke casts a value to a stroke. A length sets the thickness, a
+// color, gradient, or tiling the paint, and a dictionary may set paint,
+// thickness, and cap.
+// Matches Rust: impl FromValue for Stroke
+func CastStroke(v foundations.Value, span syntax.Span) (Stroke, error) {
+	switch v := v.(type) {
+	case nil, foundations.AutoValue:
+		return Stroke{}, nil
+	case foundations.LengthValue:
+		return Stroke{Thickness: &v.Length}, nil
+	case *foundations.Dict:
+		return castStrokeDict(v, span)
+	}
+	switch v.Type() {
+	case foundations.TypeColor, foundations.TypeGradient, foundations.TypeTiling:
+		return Stroke{Paint: v}, nil
+	}
+	return Stroke{}, &foundations.TypeMismatchError{Expected: "length, color, gradient, tiling, dictionary, or stroke", Got: v.Type().String(), Field: "stroke", Span: span}
+}
+
+func castStrokeDict(dict *foundations.Dict, span syntax.Span) (Stroke, error) {
+	var stroke Stroke
+	keys, values := dict.Iter()
+	for i, key := range keys {
+		switch value := values[i]; key {
+		case "paint":
+			switch value.Type() {
+			case foundations.TypeColor, foundations.TypeGradient, foundations.TypeTiling:
+				stroke.Paint = value
+			default:
+				return Stroke{}, &foundations.TypeMismatchError{Expected: "color, gradient, or tiling", Got: value.Type().String(), Field: "paint", Span: span}
+			}
+		case "thickness":
+			length, ok := value.(foundations.LengthValue)
+			if !ok {
+				return Stroke{}, &foundations.TypeMismatchError{Expected: "length", Got: value.Type().String(), Field: "thickness", Span: span}
+			}
+			stroke.Thickness = &length.Length
+		case "cap":
+			str, ok := value.(foundations.Str)
+			if !ok || (str != "butt" && str != "round" && str != "square") {
+				return Stroke{}, foundations.NewSourceError(span, `cap must be "butt", "round", or "square"`)
+			}
+			stroke.Cap = string(str)
+		default:
+			return Stroke{}, foundations.NewSourceError(span, fmt.Sprintf("unexpected key %q, valid keys are \"paint\", \"thickness\", and \"cap\"", key))
+		}
+	}
+	return stroke, nil
+}
+
+// ResolvedPaint returns the stroke's paint, defaulting to black.
+func (s Stroke) ResolvedPaint() foundations.Value {
+	if s.Paint == nil {
+		return foundations.NewLuma(0, 1)
+	}
+	return s.Paint
+}
+
+// ResolvedThickness returns the stroke's thickness, defaulting to
+// DefaultStrokeThickness.
+func (s Stroke) ResolvedThickness() foundations.Length {
+	if s.Thickness == nil {
+		return DefaultStrokeThickness
+	}
+	return *s.Thickness
+}
@@ -0,0 +1,11 @@
+// Package lsp implements a language server for Typst documents, speaking
+// the Language Server Protocol over a stream like stdin and stdout.
+//
+// The server keeps the open documents in memory and compiles each of them
+// as the main file when it changes, publishing the diagnostics. It answers
+// requests for definitions, hover information, and document symbols with
+// the code intelligence of the ide package.
+//
+// Compilation is injected through Options, so that the server does not
+// depend on the compiler: the gotypst command wires it up in `gotypst lsp`.
+package lsp
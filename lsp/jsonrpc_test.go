@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadMessage(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"shutdown"}`
+	input := fmt.Sprintf("Content-Length: %d\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n%s", len(body), body)
+	r := bufio.NewReader(strings.NewReader(input))
+
+	msg, err := readMessage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Method != "shutdown" || !msg.isRequest() || string(*msg.ID) != "1" {
+		t.Errorf("message = %+v", msg)
+	}
+	if _, err := readMessage(r); !errors.Is(err, io.EOF) {
+		t.Errorf("err = %v, want EOF", err)
+	}
+}
+
+func TestReadMessageInvalid(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("Content-Length: 3\r\n\r\n{x}"))
+	_, err := readMessage(r)
+	var rpcErr *responseError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeParseError {
+		t.Errorf("err = %v, want a parse error", err)
+	}
+
+	r = bufio.NewReader(strings.NewReader("Content-Type: text/plain\r\n\r\n"))
+	if _, err := readMessage(r); err == nil {
+		t.Error("expected an error for a missing Content-Length")
+	}
+
+	// The body is not read, so a huge length allocates nothing.
+	r = bufio.NewReader(strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n", maxContentLength+1)))
+	if _, err := readMessage(r); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("err = %v, want an error for a message above the limit", err)
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, &response{JSONRPC: "2.0", ID: []byte("7")}); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"jsonrpc":"2.0","id":7,"result":null}`
+	want := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
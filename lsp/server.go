@@ -0,0 +1,516 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/boergens/gotypst/ide"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// Options configures a language server.
+type Options struct {
+	// Library is the standard library, used to describe its functions on
+	// hover.
+	Library *foundations.Scope
+
+	// NewWorld creates the world for compiling the file at path, an
+	// absolute path in the project at root. The server creates one world
+	// per open document and reuses it across compilations.
+	NewWorld func(root, path string) (World, error)
+
+	// Compile compiles the main file of a world and returns the errors and
+	// warnings.
+	Compile func(world World) []foundations.SourceDiagnostic
+}
+
+// Server is a language server for Typst documents.
+type Server struct {
+	opts Options
+	out  io.Writer
+
+	// root is the project root all files are resolved in.
+	root string
+	// initialized and shutdown track the lifecycle of the session.
+	initialized bool
+	shutdown    bool
+
+	// docs holds the open documents by file.
+	docs map[syntax.FileId]*document
+	// worlds caches the world of each main file.
+	worlds map[syntax.FileId]World
+	// published records the files diagnostics were published for when
+	// compiling each main file, so that they can be cleared later.
+	published map[syntax.FileId][]syntax.FileId
+}
+
+// document is a text document open in the editor.
+type document struct {
+	uri     string
+	path    string
+	version int
+	source  *syntax.Source
+}
+
+// errExitWithoutShutdown is returned by Serve if the client exits without
+// asking the server to shut down first.
+var errExitWithoutShutdown = errors.New("exit notification without prior shutdown request")
+
+// NewServer creates a language server.
+func NewServer(opts Options) *Server {
+	return &Server{
+		opts:      opts,
+		docs:      make(map[syntax.FileId]*document),
+		worlds:    make(map[syntax.FileId]World),
+		published: make(map[syntax.FileId][]syntax.FileId),
+	}
+}
+
+// Serve reads messages from in and writes responses and notifications to
+// out until the client sends the exit notification or closes the input.
+// It returns an error if the client exits without shutting the server
+// down, as the protocol asks the server to exit with a failure then.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		msg, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			if err := s.writeError(json.RawMessage("null"), rpcErr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a message and answers it if it is a request. Only
+// errors writing to the client are returned.
+func (s *Server) handle(msg *message) error {
+	if !msg.isRequest() {
+		if s.initialized && !s.shutdown {
+			s.notify(msg)
+		}
+		return nil
+	}
+
+	id := *msg.ID
+	var result any
+	var err error
+	switch {
+	case msg.Method == "initialize":
+		result, err = s.initialize(msg.Params)
+	case !s.initialized:
+		err = &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	case s.shutdown:
+		err = &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	case msg.Method == "shutdown":
+		s.shutdown = true
+	case msg.Method == "textDocument/definition":
+		result, err = s.definition(msg.Params)
+	case msg.Method == "textDocument/hover":
+		result, err = s.hover(msg.Params)
+	case msg.Method == "textDocument/documentSymbol":
+		result, err = s.documentSymbol(msg.Params)
+	default:
+		err = &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	if err != nil {
+		var rpcErr *responseError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.writeError(id, rpcErr)
+	}
+	return writeMessage(s.out, &response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// notify handles a notification. Unknown notifications are ignored.
+func (s *Server) notify(msg *message) {
+	var err error
+	switch msg.Method {
+	case "textDocument/didOpen":
+		err = s.didOpen(msg.Params)
+	case "textDocument/didChange":
+		err = s.didChange(msg.Params)
+	case "textDocument/didClose":
+		err = s.didClose(msg.Params)
+	}
+	if err != nil {
+		s.logError("%s: %v", msg.Method, err)
+	}
+}
+
+func (s *Server) writeError(id json.RawMessage, err *responseError) error {
+	return writeMessage(s.out, &errorResponse{JSONRPC: "2.0", ID: id, Error: err})
+}
+
+// sendNotification sends a notification to the client. Write errors are
+// noticed on the next response.
+func (s *Server) sendNotification(method string, params any) {
+	writeMessage(s.out, &notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// logError shows an error in the client's log.
+func (s *Server) logError(format string, args ...any) {
+	s.sendNotification("window/logMessage", map[string]any{
+		"type":    1,
+		"message": fmt.Sprintf(format, args...),
+	})
+}
+
+// ----------------------------------------------------------------------------
+// Lifecycle
+// ----------------------------------------------------------------------------
+
+func (s *Server) initialize(raw json.RawMessage) (any, error) {
+	var params InitializeParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(params.WorkspaceFolders) > 0:
+		s.root, _ = uriToPath(params.WorkspaceFolders[0].URI)
+	case params.RootURI != nil:
+		s.root, _ = uriToPath(*params.RootURI)
+	case params.RootPath != nil:
+		s.root = *params.RootPath
+	}
+	s.initialized = true
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       TextDocumentSyncFull,
+			DefinitionProvider:     true,
+			HoverProvider:          true,
+			DocumentSymbolProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "gotypst"},
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+// Document Synchronization
+// ----------------------------------------------------------------------------
+
+func (s *Server) didOpen(raw json.RawMessage) error {
+	var params DidOpenTextDocumentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	item := params.TextDocument
+	path, err := uriToPath(item.URI)
+	if err != nil {
+		return err
+	}
+	if s.root == "" {
+		// Without a workspace, the first document's directory is the root.
+		s.root = filepath.Dir(path)
+	}
+	id, err := fileID(s.root, path)
+	if err != nil {
+		return fmt.Errorf("%s is outside of the project root %s", path, s.root)
+	}
+
+	doc := &document{uri: item.URI, path: path, version: item.Version, source: syntax.NewSource(id, item.Text)}
+	s.docs[id] = doc
+	s.check(id, doc)
+	return nil
+}
+
+func (s *Server) didChange(raw json.RawMessage) error {
+	var params DidChangeTextDocumentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	id, doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	if len(params.ContentChanges) == 0 {
+		return nil
+	}
+	// With full synchronization, the last change holds the whole text.
+	doc.source.Replace(params.ContentChanges[len(params.ContentChanges)-1].Text)
+	doc.version = params.TextDocument.Version
+	s.check(id, doc)
+	return nil
+}
+
+func (s *Server) didClose(raw json.RawMessage) error {
+	var params DidCloseTextDocumentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	id, _, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	delete(s.docs, id)
+	delete(s.worlds, id)
+	for _, file := range s.published[id] {
+		s.publish(file, nil, nil)
+	}
+	delete(s.published, id)
+	return nil
+}
+
+// document returns an open document by its URI.
+func (s *Server) document(uri string) (syntax.FileId, *document, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return syntax.FileId{}, nil, err
+	}
+	id, err := fileID(s.root, path)
+	if err == nil {
+		if doc, ok := s.docs[id]; ok {
+			return id, doc, nil
+		}
+	}
+	return syntax.FileId{}, nil, fmt.Errorf("document is not open: %s", uri)
+}
+
+// world returns the world for compiling a document as the main file, with
+// the open documents laid over the files on disk.
+func (s *Server) world(id syntax.FileId, doc *document) (World, error) {
+	base, ok := s.worlds[id]
+	if !ok {
+		if s.opts.NewWorld == nil {
+			return nil, errors.New("no world configured")
+		}
+		var err error
+		base, err = s.opts.NewWorld(s.root, doc.path)
+		if err != nil {
+			return nil, err
+		}
+		s.worlds[id] = base
+	}
+	return &overlayWorld{World: base, docs: s.docs}, nil
+}
+
+// ----------------------------------------------------------------------------
+// Diagnostics
+// ----------------------------------------------------------------------------
+
+// check compiles a document as the main file and publishes the diagnostics
+// for each file they occur in. Files that had diagnostics in the previous
+// compilation, but have none now, are cleared.
+func (s *Server) check(id syntax.FileId, doc *document) {
+	if s.opts.Compile == nil {
+		return
+	}
+	world, err := s.world(id, doc)
+	if err != nil {
+		s.logError("cannot compile %s: %v", doc.path, err)
+		return
+	}
+	if r, ok := world.(*overlayWorld).World.(resetter); ok {
+		r.Reset()
+	}
+
+	byFile := map[syntax.FileId][]Diagnostic{id: nil}
+	files := []syntax.FileId{id}
+	for _, diag := range s.opts.Compile(world) {
+		file := id
+		if fid := diag.Span.Id(); fid != nil {
+			file = *fid
+		}
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], s.diagnostic(world, diag))
+	}
+
+	for _, file := range s.published[id] {
+		if _, ok := byFile[file]; !ok {
+			s.publish(file, nil, nil)
+		}
+	}
+	for _, file := range files {
+		var version *int
+		if file == id {
+			version = &doc.version
+		}
+		s.publish(file, version, byFile[file])
+	}
+	s.published[id] = files
+}
+
+// diagnostic converts a diagnostic of the compiler. Hints are appended to
+// the message, since the protocol has no place for them.
+func (s *Server) diagnostic(world World, diag foundations.SourceDiagnostic) Diagnostic {
+	severity := SeverityError
+	if diag.Severity == foundations.SeverityWarning {
+		severity = SeverityWarning
+	}
+	message := diag.Message
+	for _, hint := range diag.Hints {
+		message += "\nhint: " + hint
+	}
+	rng, _ := spanRange(world, diag.Span)
+	return Diagnostic{Range: rng, Severity: severity, Source: "gotypst", Message: message}
+}
+
+// publish sends the diagnostics of a file. Files in packages are skipped.
+func (s *Server) publish(file syntax.FileId, version *int, diags []Diagnostic) {
+	path, ok := filePath(s.root, file)
+	if !ok {
+		return
+	}
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	s.sendNotification("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         pathToURI(path),
+		Version:     version,
+		Diagnostics: diags,
+	})
+}
+
+// ----------------------------------------------------------------------------
+// Language Features
+// ----------------------------------------------------------------------------
+
+func (s *Server) definition(raw json.RawMessage) (any, error) {
+	id, doc, cursor, err := s.position(raw)
+	if err != nil || cursor < 0 {
+		return nil, err
+	}
+	world, err := s.world(id, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	def := ide.DefinitionAt(world, doc.source, cursor, syntax.After)
+	if def == nil {
+		return nil, nil
+	}
+	file := def.Span.Id()
+	if file == nil {
+		return nil, nil
+	}
+	path, ok := filePath(s.root, *file)
+	if !ok {
+		return nil, nil
+	}
+	rng, ok := spanRange(world, def.Span)
+	if !ok {
+		return nil, nil
+	}
+	if source, err := world.Source(*file); err == nil && source.Root().Span() == def.Span {
+		// Jump to the start of a file instead of selecting all of it.
+		rng.End = rng.Start
+	}
+	return &Location{URI: pathToURI(path), Range: rng}, nil
+}
+
+func (s *Server) hover(raw json.RawMessage) (any, error) {
+	_, doc, cursor, err := s.position(raw)
+	if err != nil || cursor < 0 {
+		return nil, err
+	}
+	tooltip := ide.TooltipAt(s.opts.Library, doc.source, cursor, syntax.After)
+	if tooltip == nil {
+		return nil, nil
+	}
+	if tooltip.Code {
+		return &Hover{Contents: MarkupContent{Kind: "markdown", Value: "```typst\n" + tooltip.Text + "\n```"}}, nil
+	}
+	return &Hover{Contents: MarkupContent{Kind: "plaintext", Value: tooltip.Text}}, nil
+}
+
+func (s *Server) documentSymbol(raw json.RawMessage) (any, error) {
+	var params DocumentSymbolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	_, doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return convertSymbols(doc.source, ide.DocumentSymbols(doc.source)), nil
+}
+
+// convertSymbols converts the outline of a source.
+func convertSymbols(source *syntax.Source, symbols []ide.DocumentSymbol) []DocumentSymbol {
+	out := make([]DocumentSymbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		start, end, ok := source.Range(symbol.Span)
+		if !ok {
+			continue
+		}
+		rng := Range{Start: position(source, start), End: position(source, end)}
+		out = append(out, DocumentSymbol{
+			Name:           symbol.Name,
+			Detail:         fmt.Sprintf("heading level %d", symbol.Level),
+			Kind:           SymbolKindNamespace,
+			Range:          rng,
+			SelectionRange: rng,
+			Children:       convertSymbols(source, symbol.Children),
+		})
+	}
+	return out
+}
+
+// position decodes the parameters of a request about a position and
+// returns the document and the byte offset in it, or -1 if the position is
+// outside of the document.
+func (s *Server) position(raw json.RawMessage) (syntax.FileId, *document, int, error) {
+	var params TextDocumentPositionParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return syntax.FileId{}, nil, 0, err
+	}
+	id, doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return syntax.FileId{}, nil, 0, err
+	}
+	cursor := doc.source.Lines().UTF16LineColumnToByte(params.Position.Line, params.Position.Character)
+	return id, doc, cursor, nil
+}
+
+// spanRange returns the range of a span in its file.
+func spanRange(world World, span syntax.Span) (Range, bool) {
+	id := span.Id()
+	if id == nil {
+		return Range{}, false
+	}
+	source, err := world.Source(*id)
+	if err != nil {
+		return Range{}, false
+	}
+	start, end, ok := source.Range(span)
+	if !ok {
+		return Range{}, false
+	}
+	return Range{Start: position(source, start), End: position(source, end)}, true
+}
+
+// position converts a byte offset in a source to a protocol position.
+func position(source *syntax.Source, offset int) Position {
+	line, column := source.Lines().ByteToUTF16LineColumn(offset)
+	return Position{Line: line, Character: column}
+}
@@ -0,0 +1,325 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/kit"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// session runs a language server on the files of a project in a temporary
+// root. The files are kept in memory; documents opened by the client are
+// laid over them.
+type session struct {
+	t     *testing.T
+	root  string
+	files map[string]string
+	input bytes.Buffer
+	id    int
+}
+
+func newSession(t *testing.T, files map[string]string) *session {
+	s := &session{t: t, root: t.TempDir(), files: files}
+	s.request("initialize", map[string]any{"rootUri": pathToURI(s.root)})
+	s.notify("initialized", map[string]any{})
+	return s
+}
+
+func (s *session) uri(path string) string {
+	return pathToURI(filepath.Join(s.root, filepath.FromSlash(path)))
+}
+
+func (s *session) send(msg any) {
+	if err := writeMessage(&s.input, msg); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func (s *session) request(method string, params any) int {
+	s.id++
+	s.send(map[string]any{"jsonrpc": "2.0", "id": s.id, "method": method, "params": params})
+	return s.id
+}
+
+func (s *session) notify(method string, params any) {
+	s.send(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *session) open(path string) {
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri(path), "languageId": "typst", "version": 1, "text": s.files[path]},
+	})
+}
+
+func (s *session) change(path, text string, version int) {
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": s.uri(path), "version": version},
+		"contentChanges": []map[string]any{{"text": text}},
+	})
+}
+
+func (s *session) at(method, path string, line, character int) int {
+	return s.request(method, map[string]any{
+		"textDocument": map[string]any{"uri": s.uri(path)},
+		"position":     map[string]any{"line": line, "character": character},
+	})
+}
+
+// output is a message sent by the server.
+type output struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// run shuts the server down and returns everything it sent.
+func (s *session) run() []output {
+	s.request("shutdown", nil)
+	s.notify("exit", nil)
+
+	server := NewServer(Options{
+		Library: testLibrary(),
+		NewWorld: func(root, path string) (World, error) {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil, err
+			}
+			data := make(map[string][]byte, len(s.files))
+			for path, text := range s.files {
+				data[path] = []byte(text)
+			}
+			return kit.NewMemoryWorld(filepath.ToSlash(rel), data)
+		},
+		Compile: func(world World) []foundations.SourceDiagnostic {
+			source, err := world.Source(world.MainFile())
+			if err != nil {
+				return []foundations.SourceDiagnostic{{Severity: foundations.SeverityError, Message: err.Error()}}
+			}
+			return foundations.SyntaxErrors(source.Root().Errors())
+		},
+	})
+
+	var out bytes.Buffer
+	if err := server.Serve(&s.input, &out); err != nil {
+		s.t.Fatal(err)
+	}
+
+	var outputs []output
+	r := bufio.NewReader(&out)
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if errors.Is(err, io.EOF) {
+			return outputs
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			s.t.Fatalf("bad header %v", header)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			s.t.Fatal(err)
+		}
+		var o output
+		if err := json.Unmarshal(body, &o); err != nil {
+			s.t.Fatal(err)
+		}
+		outputs = append(outputs, o)
+	}
+}
+
+// result finds the response to a request and decodes its result.
+func result(t *testing.T, outputs []output, id int, result any) {
+	t.Helper()
+	for _, o := range outputs {
+		if o.ID != nil && *o.ID == id && o.Method == "" {
+			if o.Error != nil {
+				t.Fatalf("request %d failed: %s", id, o.Error.Message)
+			}
+			if err := json.Unmarshal(o.Result, result); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	}
+	t.Fatalf("no response to request %d", id)
+}
+
+// diagnostics returns the last diagnostics published for a document.
+func diagnostics(t *testing.T, outputs []output, uri string) []Diagnostic {
+	t.Helper()
+	var last *PublishDiagnosticsParams
+	for _, o := range outputs {
+		if o.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		if err := json.Unmarshal(o.Params, &params); err != nil {
+			t.Fatal(err)
+		}
+		if params.URI == uri {
+			last = &params
+		}
+	}
+	if last == nil {
+		t.Fatalf("no diagnostics published for %s", uri)
+	}
+	return last.Diagnostics
+}
+
+func testLibrary() *foundations.Scope {
+	name := "pow"
+	pow := &foundations.Func{
+		Name: &name,
+		Repr: foundations.NativeFunc{Info: &foundations.FuncInfo{
+			Name: "pow",
+			Params: []foundations.ParamInfo{
+				{Name: "base", Type: foundations.TypeInt},
+				{Name: "exponent", Type: foundations.TypeInt},
+			},
+		}},
+	}
+	library := foundations.NewScope()
+	library.Define("pow", foundations.FuncValue{Func: pow}, syntax.Detached())
+	return library
+}
+
+func TestInitialize(t *testing.T) {
+	s := newSession(t, nil)
+	outputs := s.run()
+
+	var init InitializeResult
+	result(t, outputs, 1, &init)
+	caps := init.Capabilities
+	if caps.TextDocumentSync != TextDocumentSyncFull || !caps.DefinitionProvider || !caps.HoverProvider || !caps.DocumentSymbolProvider {
+		t.Errorf("capabilities = %+v", caps)
+	}
+}
+
+func TestNotInitialized(t *testing.T) {
+	server := NewServer(Options{})
+	var in, out bytes.Buffer
+	writeMessage(&in, map[string]any{"jsonrpc": "2.0", "id": 1, "method": "textDocument/hover"})
+	if err := server.Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"code":-32002`) {
+		t.Errorf("got %s, want a not initialized error", out.String())
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	var in bytes.Buffer
+	writeMessage(&in, map[string]any{"jsonrpc": "2.0", "method": "exit"})
+	if err := NewServer(Options{}).Serve(&in, io.Discard); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	s := newSession(t, nil)
+	id := s.request("workspace/symbol", map[string]any{"query": ""})
+	s.notify("$/setTrace", map[string]any{"value": "off"})
+	for _, o := range s.run() {
+		if o.ID != nil && *o.ID == id {
+			if o.Error == nil || o.Error.Code != codeMethodNotFound {
+				t.Errorf("error = %+v, want method not found", o.Error)
+			}
+			return
+		}
+	}
+	t.Fatal("no response")
+}
+
+func TestDiagnosticsOnChange(t *testing.T) {
+	s := newSession(t, map[string]string{"main.typ": "= Hello\n#let x = (1, 2\n"})
+	s.open("main.typ")
+	outputs := s.run()
+
+	diags := diagnostics(t, outputs, s.uri("main.typ"))
+	if len(diags) == 0 {
+		t.Fatal("expected diagnostics for an unclosed array")
+	}
+	if diags[0].Severity != SeverityError || diags[0].Range.Start.Line != 1 {
+		t.Errorf("diagnostic = %+v, want an error on line 1", diags[0])
+	}
+
+	s = newSession(t, map[string]string{"main.typ": "#let x = (1, 2\n"})
+	s.open("main.typ")
+	s.change("main.typ", "#let x = (1, 2)\n", 2)
+	if diags := diagnostics(t, s.run(), s.uri("main.typ")); len(diags) != 0 {
+		t.Errorf("diagnostics = %+v, want them cleared after the fix", diags)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	s := newSession(t, map[string]string{
+		"main.typ":  "#import \"intro.typ\": title\n= Start <start>\nSee @start.",
+		"intro.typ": "#let title = [Intro]",
+	})
+	s.open("main.typ")
+	fileReq := s.at("textDocument/definition", "main.typ", 0, 10)
+	labelReq := s.at("textDocument/definition", "main.typ", 2, 5)
+	noneReq := s.at("textDocument/definition", "main.typ", 2, 1)
+	outputs := s.run()
+
+	var loc Location
+	result(t, outputs, fileReq, &loc)
+	if loc.URI != s.uri("intro.typ") || loc.Range != (Range{}) {
+		t.Errorf("file definition = %+v", loc)
+	}
+
+	result(t, outputs, labelReq, &loc)
+	want := Range{Start: Position{Line: 1, Character: 8}, End: Position{Line: 1, Character: 15}}
+	if loc.URI != s.uri("main.typ") || loc.Range != want {
+		t.Errorf("label definition = %+v, want %+v", loc, want)
+	}
+
+	var none *Location
+	result(t, outputs, noneReq, &none)
+	if none != nil {
+		t.Errorf("definition = %+v, want null", none)
+	}
+}
+
+func TestHover(t *testing.T) {
+	s := newSession(t, map[string]string{"main.typ": "#pow(2, 3)"})
+	s.open("main.typ")
+	req := s.at("textDocument/hover", "main.typ", 0, 2)
+	outputs := s.run()
+
+	var hover Hover
+	result(t, outputs, req, &hover)
+	if hover.Contents.Kind != "markdown" || !strings.Contains(hover.Contents.Value, "pow(base: integer, exponent: integer)") {
+		t.Errorf("hover = %+v", hover)
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	s := newSession(t, map[string]string{"main.typ": "= Intro\n== Motivation\n= Results"})
+	s.open("main.typ")
+	req := s.request("textDocument/documentSymbol", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("main.typ")},
+	})
+	outputs := s.run()
+
+	var symbols []DocumentSymbol
+	result(t, outputs, req, &symbols)
+	if len(symbols) != 2 || symbols[0].Name != "Intro" || symbols[1].Name != "Results" {
+		t.Fatalf("symbols = %+v", symbols)
+	}
+	if children := symbols[0].Children; len(children) != 1 || children[0].Name != "Motivation" || children[0].Range.Start.Line != 1 {
+		t.Errorf("children = %+v", children)
+	}
+}
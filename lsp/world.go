@@ -0,0 +1,96 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/boergens/gotypst/font"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/syntax"
+)
+
+// World is the environment a document is compiled in, like a
+// kit.FileWorld.
+type World interface {
+	foundations.World
+
+	// FontBook returns the fonts available to the document.
+	FontBook() *font.FontBook
+}
+
+// resetter is implemented by worlds that cache files and must check them
+// for changes before another compilation.
+type resetter interface {
+	Reset()
+}
+
+// overlayWorld is a world in which the documents open in the editor replace
+// the files on disk, so that unsaved changes are compiled.
+type overlayWorld struct {
+	World
+	docs map[syntax.FileId]*document
+}
+
+// Source returns the source of an open document, or else of the file.
+func (w *overlayWorld) Source(id syntax.FileId) (*syntax.Source, error) {
+	if doc, ok := w.docs[id]; ok {
+		return doc.source, nil
+	}
+	return w.World.Source(id)
+}
+
+// File returns the text of an open document, or else the file's bytes.
+func (w *overlayWorld) File(id syntax.FileId) ([]byte, error) {
+	if doc, ok := w.docs[id]; ok {
+		return []byte(doc.source.Text()), nil
+	}
+	return w.World.File(id)
+}
+
+// uriToPath converts a file URI to an absolute path.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme: %s", u.Scheme)
+	}
+	path := u.Path
+	// Windows paths are written as file:///C:/path.
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// pathToURI converts an absolute path to a file URI.
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// fileID returns the ID of the file at an absolute path in the project at
+// root.
+func fileID(root, path string) (syntax.FileId, error) {
+	vpath, err := syntax.Virtualize(root, path)
+	if err != nil {
+		return syntax.FileId{}, err
+	}
+	return syntax.NewRootedPath(syntax.ProjectRoot(), *vpath).Intern(), nil
+}
+
+// filePath returns the absolute path of a project file, or false for
+// files in packages.
+func filePath(root string, id syntax.FileId) (string, bool) {
+	rooted := id.Get()
+	if rooted == nil || rooted.Package() != nil {
+		return "", false
+	}
+	return rooted.VPath().Realize(root), true
+}
@@ -0,0 +1,8 @@
+// Package memo provides memoization across compilations.
+//
+// It takes the role of the comemo crate in the Rust implementation: results
+// of expensive steps like module evaluation and text shaping are cached,
+// keyed by hashes of their inputs, so that recompiling a document after a
+// small change only redoes the work that depends on the change. Entries that
+// go unused for a number of compilations are evicted.
+package memo
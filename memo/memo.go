@@ -0,0 +1,198 @@
+package memo
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sync"
+)
+
+// Hash is a 128-bit fingerprint of some data. It is used in place of the
+// data itself when keying cached results.
+type Hash [16]byte
+
+// HashString returns the fingerprint of a string.
+func HashString(s string) Hash {
+	h := NewHasher()
+	h.WriteString(s)
+	return h.Sum()
+}
+
+// HashBytes returns the fingerprint of a byte slice.
+func HashBytes(data []byte) Hash {
+	h := NewHasher()
+	h.WriteBytes(data)
+	return h.Sum()
+}
+
+// Hasher computes a fingerprint from multiple pieces of data. Each piece is
+// prefixed with its length, so that the boundaries between pieces are part
+// of the fingerprint.
+type Hasher struct {
+	h   hash.Hash
+	buf [8]byte
+}
+
+// NewHasher creates an empty hasher.
+func NewHasher() *Hasher {
+	return &Hasher{h: sha256.New()}
+}
+
+// WriteString adds a string to the fingerprint.
+func (h *Hasher) WriteString(s string) {
+	h.WriteUint64(uint64(len(s)))
+	h.h.Write([]byte(s))
+}
+
+// WriteBytes adds a byte slice to the fingerprint.
+func (h *Hasher) WriteBytes(data []byte) {
+	h.WriteUint64(uint64(len(data)))
+	h.h.Write(data)
+}
+
+// WriteUint64 adds an integer to the fingerprint.
+func (h *Hasher) WriteUint64(v uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], v)
+	h.h.Write(h.buf[:])
+}
+
+// Sum returns the fingerprint of everything written so far.
+func (h *Hasher) Sum() Hash {
+	var out Hash
+	copy(out[:], h.h.Sum(nil))
+	return out
+}
+
+// Cache memoizes values by key. It is safe for concurrent use.
+//
+// Every cache is registered globally, so that Evict ages and drops the
+// entries of all caches at once. A cache can additionally be bounded with
+// SetCapacity, in which case the least recently used entries are dropped
+// as new ones come in.
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[K]*entry[K, V]
+	// recent orders the entries from the most to the least recently used.
+	recent list.List
+	// capacity is the maximum number of entries, or zero for no maximum.
+	capacity int
+}
+
+// entry is a cached value together with the number of evictions it has
+// survived without being used.
+type entry[K comparable, V any] struct {
+	value V
+	age   int
+	// use is the entry's element in the cache's recency list, holding the
+	// key.
+	use *list.Element
+}
+
+// evictable is implemented by all caches.
+type evictable interface {
+	evict(maxAge int)
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   []evictable
+)
+
+// NewCache creates an empty cache and registers it for eviction.
+func NewCache[K comparable, V any]() *Cache[K, V] {
+	c := &Cache[K, V]{entries: make(map[K]*entry[K, V])}
+	cachesMu.Lock()
+	caches = append(caches, c)
+	cachesMu.Unlock()
+	return c
+}
+
+// Get returns the value cached for the key. A hit resets the age of the
+// entry.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.age = 0
+		c.recent.MoveToFront(e.use)
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put caches a value for the key, replacing any earlier value. If the
+// cache is full, the least recently used entry is dropped.
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+	c.entries[key] = &entry[K, V]{value: value, use: c.recent.PushFront(key)}
+	c.shrink()
+}
+
+// Remove drops the value cached for the key.
+func (c *Cache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+// SetCapacity bounds the number of entries, dropping the least recently
+// used ones beyond it. A capacity of zero or less removes the bound.
+func (c *Cache[K, V]) SetCapacity(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = max(capacity, 0)
+	c.shrink()
+}
+
+// remove drops the entry for the key, if there is one.
+func (c *Cache[K, V]) remove(key K) {
+	if e, ok := c.entries[key]; ok {
+		c.recent.Remove(e.use)
+		delete(c.entries, key)
+	}
+}
+
+// shrink drops the least recently used entries until the cache is within
+// its capacity.
+func (c *Cache[K, V]) shrink() {
+	for c.capacity > 0 && len(c.entries) > c.capacity {
+		c.remove(c.recent.Back().Value.(K))
+	}
+}
+
+// Len returns the number of cached values.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache[K, V]) evict(maxAge int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		e.age++
+		if e.age > maxAge {
+			c.remove(key)
+		}
+	}
+}
+
+// Evict ages the entries of all caches by one and drops those that have not
+// been used in the last maxAge evictions. An age of zero clears all caches.
+//
+// Long-running embedders like a watcher or a language server should call
+// this after every compilation to bound memory use.
+//
+// Matches Rust: comemo::evict
+func Evict(maxAge int) {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	for _, c := range caches {
+		c.evict(maxAge)
+	}
+}
@@ -0,0 +1,90 @@
+package memo
+
+import "testing"
+
+func TestCacheEvict(t *testing.T) {
+	c := NewCache[string, int]()
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	Evict(1)
+	if c.Len() != 2 {
+		t.Fatalf("after first eviction: Len() = %d, want 2", c.Len())
+	}
+
+	// A hit resets the age, so only the unused entry is dropped.
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v, want 1, true", v, ok)
+	}
+	Evict(1)
+	if _, ok := c.Get("b"); ok {
+		t.Error("b survived two evictions without being used")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a was evicted despite a recent hit")
+	}
+
+	Evict(0)
+	if c.Len() != 0 {
+		t.Errorf("after Evict(0): Len() = %d, want 0", c.Len())
+	}
+}
+
+func TestCacheCapacity(t *testing.T) {
+	c := NewCache[string, int]()
+	defer Evict(0)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+
+	// Shrinking drops the least recently used entries.
+	c.Get("a")
+	c.SetCapacity(2)
+	if _, ok := c.Get("b"); ok || c.Len() != 2 {
+		t.Fatalf("after SetCapacity(2): Len() = %d, b cached = %v", c.Len(), ok)
+	}
+
+	// A new entry pushes out the entry used longest ago.
+	c.Get("a")
+	c.Put("d", 4)
+	if _, ok := c.Get("c"); ok {
+		t.Error("c survived although it was used longest ago")
+	}
+	for _, key := range []string{"a", "d"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s was dropped", key)
+		}
+	}
+
+	// Replacing a value doesn't count as a new entry.
+	c.Put("d", 5)
+	if v, _ := c.Get("d"); v != 5 || c.Len() != 2 {
+		t.Errorf("after replacing d: Get(d) = %d, Len() = %d", v, c.Len())
+	}
+
+	c.SetCapacity(0)
+	c.Put("e", 6)
+	if c.Len() != 3 {
+		t.Errorf("unbounded cache has %d entries, want 3", c.Len())
+	}
+}
+
+func TestHasher(t *testing.T) {
+	if HashString("abc") != HashBytes([]byte("abc")) {
+		t.Error("string and bytes with the same content hash differently")
+	}
+	if HashString("abc") == HashString("abd") {
+		t.Error("different strings hash the same")
+	}
+
+	// Piece boundaries are part of the fingerprint.
+	a := NewHasher()
+	a.WriteString("ab")
+	a.WriteString("c")
+	b := NewHasher()
+	b.WriteString("a")
+	b.WriteString("bc")
+	if a.Sum() == b.Sum() {
+		t.Error("differently split pieces hash the same")
+	}
+}
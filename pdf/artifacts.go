@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+// Content that only decorates the page, like backgrounds and rules, is
+// marked as an artifact, and images and figures are marked with their
+// alternative text. Screen readers skip artifacts and read the alternative
+// text in place of the figure, even in documents without a structure tree.
+
+// writeBackground fills the page with its background paint, marked as a
+// background artifact.
+func writeBackground(content *bytes.Buffer, page *pages.Page) {
+	if page.Fill == nil || page.Fill.Color == nil {
+		return
+	}
+	size := page.Frame.Size
+	fmt.Fprintf(content, "/Artifact <</Type /Background>> BDC\n")
+	fmt.Fprintf(content, "q\n")
+	writeFillColor(content, page.Fill.Color)
+	fmt.Fprintf(content, "0 0 %g %g re\nf\n", float64(size.Width), float64(size.Height))
+	fmt.Fprintf(content, "Q\n")
+	fmt.Fprintf(content, "EMC\n")
+}
+
+// writeShape fills and strokes a shape at (x, y), marked as an artifact.
+// Lines have no interior and are only stroked.
+func writeShape(content *bytes.Buffer, shape pages.ShapeItem, x, y float64) {
+	filled := shape.Fill != nil && shape.Fill.Color != nil
+	stroked := shape.Stroke != nil && shape.Stroke.Paint.Color != nil && shape.Stroke.Thickness > 0
+	if _, ok := shape.Geometry.(pages.LineGeometry); ok {
+		filled = false
+	}
+	if !filled && !stroked {
+		return
+	}
+
+	var path bytes.Buffer
+	switch geometry := shape.Geometry.(type) {
+	case pages.LineGeometry:
+		fmt.Fprintf(&path, "0 0 m\n%g %g l\n", float64(geometry.To.X), float64(geometry.To.Y))
+	case pages.RectGeometry:
+		fmt.Fprintf(&path, "0 0 %g %g re\n", float64(geometry.Size.Width), float64(geometry.Size.Height))
+	case pages.CurveGeometry:
+		writeCurve(&path, geometry.Curve)
+	default:
+		return
+	}
+
+	fmt.Fprintf(content, "/Artifact BMC\n")
+	fmt.Fprintf(content, "q\n")
+	fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", x, y)
+	if filled {
+		writeFillColor(content, shape.Fill.Color)
+	}
+	if stroked {
+		writeStroke(content, shape.Stroke)
+	}
+	content.Write(path.Bytes())
+	switch {
+	case filled && stroked:
+		fmt.Fprintf(content, "B\n")
+	case filled:
+		fmt.Fprintf(content, "f\n")
+	default:
+		fmt.Fprintf(content, "S\n")
+	}
+	fmt.Fprintf(content, "Q\n")
+	fmt.Fprintf(content, "EMC\n")
+}
+
+// writeCurve writes the path of a curve.
+func writeCurve(content *bytes.Buffer, curve pages.Curve) {
+	for _, item := range curve {
+		switch item := item.(type) {
+		case pages.CurveMove:
+			fmt.Fprintf(content, "%g %g m\n", float64(item.To.X), float64(item.To.Y))
+		case pages.CurveLine:
+			fmt.Fprintf(content, "%g %g l\n", float64(item.To.X), float64(item.To.Y))
+		case pages.CurveCubic:
+			fmt.Fprintf(content, "%g %g %g %g %g %g c\n",
+				float64(item.Control1.X), float64(item.Control1.Y),
+				float64(item.Control2.X), float64(item.Control2.Y),
+				float64(item.To.X), float64(item.To.Y))
+		case pages.CurveClose:
+			fmt.Fprintf(content, "h\n")
+		}
+	}
+}
+
+// writeFillColor sets the fill color. Colors are drawn opaque.
+func writeFillColor(content *bytes.Buffer, c *pages.Color) {
+	fmt.Fprintf(content, "%g %g %g rg\n", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// writeStroke sets the color and the line style of a stroke.
+func writeStroke(content *bytes.Buffer, s *pages.FixedStroke) {
+	c := s.Paint.Color
+	fmt.Fprintf(content, "%g %g %g RG\n", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+	fmt.Fprintf(content, "%g w\n", float64(s.Thickness))
+	if s.Cap != pages.LineCapButt {
+		fmt.Fprintf(content, "%d J\n", s.Cap)
+	}
+	if s.Join != pages.LineJoinMiter {
+		fmt.Fprintf(content, "%d j\n", s.Join)
+	}
+	if s.MiterLimit > 0 {
+		fmt.Fprintf(content, "%g M\n", s.MiterLimit)
+	}
+	if s.Dash != nil {
+		fmt.Fprintf(content, "[")
+		for i, length := range s.Dash.Array {
+			if i > 0 {
+				fmt.Fprintf(content, " ")
+			}
+			fmt.Fprintf(content, "%g", float64(length))
+		}
+		fmt.Fprintf(content, "] %g d\n", float64(s.Dash.Phase))
+	}
+}
+
+// beginFigure begins a figure marked with its alternative text. It must be
+// ended with endFigure.
+func beginFigure(content *bytes.Buffer, alt string) {
+	fmt.Fprintf(content, "/Figure <</Alt ")
+	textString(alt).writeTo(content)
+	fmt.Fprintf(content, ">> BDC\n")
+}
+
+// endFigure ends a figure begun with beginFigure.
+func endFigure(content *bytes.Buffer) {
+	fmt.Fprintf(content, "EMC\n")
+}
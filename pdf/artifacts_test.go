@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+func TestWriteShapeArtifact(t *testing.T) {
+	red := &pages.Color{R: 255, A: 255}
+	shape := pages.ShapeItem{
+		Geometry: pages.RectGeometry{Size: layout.Size{Width: 20, Height: 10}},
+		Fill:     &pages.Paint{Color: red},
+	}
+	var content bytes.Buffer
+	writeShape(&content, shape, 5, 6)
+	want := "/Artifact BMC\nq\n1 0 0 1 5 6 cm\n1 0 0 rg\n0 0 20 10 re\nf\nQ\nEMC\n"
+	if got := content.String(); got != want {
+		t.Errorf("writeShape = %q, want %q", got, want)
+	}
+
+	// A rule is a stroked line, even if the shape has a fill.
+	rule := pages.ShapeItem{
+		Geometry: pages.LineGeometry{To: layout.Point{X: 30}},
+		Fill:     &pages.Paint{Color: red},
+		Stroke: &pages.FixedStroke{
+			Paint:     pages.Paint{Color: &pages.Color{A: 255}},
+			Thickness: 1,
+			Cap:       pages.LineCapRound,
+			Dash:      &pages.DashPattern{Array: []layout.Abs{2, 1}},
+		},
+	}
+	content.Reset()
+	writeShape(&content, rule, 0, 0)
+	got := content.String()
+	for _, op := range []string{"/Artifact BMC\n", "0 0 0 RG\n", "1 w\n", "1 J\n", "[2 1] 0 d\n", "0 0 m\n30 0 l\nS\n"} {
+		if !strings.Contains(got, op) {
+			t.Errorf("writeShape of a rule = %q, missing %q", got, op)
+		}
+	}
+	if strings.Contains(got, " rg\n") {
+		t.Errorf("writeShape of a rule = %q, want no fill", got)
+	}
+
+	// Invisible shapes produce no artifact.
+	content.Reset()
+	writeShape(&content, pages.ShapeItem{Geometry: pages.RectGeometry{}}, 0, 0)
+	if content.Len() != 0 {
+		t.Errorf("writeShape of an invisible shape = %q, want nothing", content.String())
+	}
+}
+
+func TestWriteBackgroundArtifact(t *testing.T) {
+	page := &pages.Page{
+		Frame: pages.Hard(layout.Size{Width: 100, Height: 200}),
+		Fill:  &pages.Paint{Color: &pages.Color{R: 255, G: 255, B: 255, A: 255}},
+	}
+	var content bytes.Buffer
+	writeBackground(&content, page)
+	want := "/Artifact <</Type /Background>> BDC\nq\n1 1 1 rg\n0 0 100 200 re\nf\nQ\nEMC\n"
+	if got := content.String(); got != want {
+		t.Errorf("writeBackground = %q, want %q", got, want)
+	}
+}
+
+func TestImageAltText(t *testing.T) {
+	frame := pages.Hard(layout.Size{Width: 100, Height: 100})
+	frame.Push(layout.Point{}, pages.GroupItem{
+		Frame: pages.Hard(layout.Size{Width: 50, Height: 50}),
+		Alt:   "A (small) chart",
+	})
+	frame.Push(layout.Point{}, pages.ImageItem{
+		Image: pages.Image{Data: []byte{0}, Format: pages.ImageFormatRaw, Width: 1, Height: 1, BitsPerComponent: 8, ColorSpace: pages.ColorSpaceDeviceGray},
+		Size:  layout.Size{Width: 10, Height: 10},
+		Alt:   "Straße",
+	})
+
+	var content bytes.Buffer
+	w := NewWriter()
+	if err := w.processFrameWithTransforms(&frame, &content, map[string]Ref{}, new(int)); err != nil {
+		t.Fatal(err)
+	}
+	got := content.String()
+	for _, want := range []string{
+		"/Figure <</Alt (A \\(small\\) chart)>> BDC\n",
+		"/Figure <</Alt <FEFF005300740072006100DF0065>>> BDC\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("content = %q, missing %q", got, want)
+		}
+	}
+	if begins, ends := strings.Count(got, "BDC"), strings.Count(got, "EMC"); begins != 2 || ends != 2 {
+		t.Errorf("content has %d BDC and %d EMC operators, want 2 each", begins, ends)
+	}
+}
@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+// afRelationships maps the relationships of attachments to the values of
+// the PDF AFRelationship key.
+var afRelationships = map[string]Name{
+	"source":      "Source",
+	"data":        "Data",
+	"alternative": "Alternative",
+	"supplement":  "Supplement",
+}
+
+// writeAttachments writes the attachments of the document as embedded
+// files. It returns the embedded files name tree and the array of
+// associated files for the catalog, or nils if there are no attachments.
+// Attachments must have distinct names.
+// Matches Rust: write_embedded_files
+func (w *Writer) writeAttachments(attachments []pages.Attachment) (Dict, Array, error) {
+	if len(attachments) == 0 {
+		return nil, nil, nil
+	}
+
+	specs := make(map[string]Ref, len(attachments))
+	var associated Array
+	for _, attachment := range attachments {
+		if _, ok := specs[attachment.Name]; ok {
+			return nil, nil, fmt.Errorf("duplicate embedded file for path `%s`", attachment.Name)
+		}
+		spec := w.writeAttachment(attachment)
+		specs[attachment.Name] = spec
+		associated = append(associated, spec)
+	}
+
+	// The entries of a name tree must be sorted by their keys.
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := make(Array, 0, 2*len(names))
+	for _, name := range names {
+		entries = append(entries, textString(name), specs[name])
+	}
+	return Dict{Name("EmbeddedFiles"): Dict{Name("Names"): entries}}, associated, nil
+}
+
+// writeAttachment writes the embedded file stream and the file
+// specification of an attachment and returns the reference to the latter.
+func (w *Writer) writeAttachment(attachment pages.Attachment) Ref {
+	file := Stream{
+		Dict: Dict{
+			Name("Type"):   Name("EmbeddedFile"),
+			Name("Params"): Dict{Name("Size"): Int(len(attachment.Data))},
+		},
+		Data: attachment.Data,
+	}
+	if attachment.MimeType != "" {
+		file.Dict[Name("Subtype")] = Name(attachment.MimeType)
+	}
+	fileRef := w.addObject(file)
+
+	spec := Dict{
+		Name("Type"): Name("Filespec"),
+		Name("F"):    textString(attachment.Name),
+		Name("UF"):   textString(attachment.Name),
+		Name("EF"): Dict{
+			Name("F"):  fileRef,
+			Name("UF"): fileRef,
+		},
+	}
+	if attachment.Description != "" {
+		spec[Name("Desc")] = textString(attachment.Description)
+	}
+	relationship, ok := afRelationships[attachment.Relationship]
+	if !ok {
+		relationship = "Unspecified"
+	}
+	spec[Name("AFRelationship")] = relationship
+	return w.addObject(spec)
+}
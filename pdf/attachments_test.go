@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+func TestWriteAttachments(t *testing.T) {
+	w := NewWriter()
+	names, associated, err := w.writeAttachments([]pages.Attachment{
+		{Name: "invoice.xml", Data: []byte("<invoice/>"), MimeType: "text/xml", Description: "Invoice", Relationship: "alternative"},
+		{Name: "data.csv", Data: []byte("a,b")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(associated) != 2 {
+		t.Fatalf("got %d associated files, want 2", len(associated))
+	}
+
+	entries := names[Name("EmbeddedFiles")].(Dict)[Name("Names")].(Array)
+	if len(entries) != 4 || entries[0] != String("data.csv") || entries[2] != String("invoice.xml") {
+		t.Errorf("name tree entries = %v, want sorted by name", entries)
+	}
+
+	objects := make(map[Ref]Object)
+	for _, obj := range w.objects {
+		objects[obj.Ref] = obj.Object
+	}
+	spec := objects[associated[0].(Ref)].(Dict)
+	if spec[Name("AFRelationship")] != Name("Alternative") || spec[Name("Desc")] != String("Invoice") {
+		t.Errorf("file specification = %v", spec)
+	}
+	file := objects[spec[Name("EF")].(Dict)[Name("F")].(Ref)].(Stream)
+	want := Dict{Name("Size"): Int(len("<invoice/>"))}
+	if file.Dict[Name("Subtype")] != Name("text/xml") || !reflect.DeepEqual(file.Dict[Name("Params")], want) {
+		t.Errorf("embedded file = %v", file.Dict)
+	}
+	if other := objects[associated[1].(Ref)].(Dict); other[Name("AFRelationship")] != Name("Unspecified") {
+		t.Errorf("AFRelationship without relationship = %v, want Unspecified", other[Name("AFRelationship")])
+	}
+}
+
+func TestWriteAttachmentsDuplicateName(t *testing.T) {
+	_, _, err := NewWriter().writeAttachments([]pages.Attachment{{Name: "a.txt"}, {Name: "a.txt"}})
+	if err == nil {
+		t.Error("expected an error for duplicate attachment names")
+	}
+}
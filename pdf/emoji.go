@@ -0,0 +1,214 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/boergens/gotypst/layout/inline"
+	"github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/font/opentype/tables"
+)
+
+// foregroundPalette is the palette index of COLR layers that are filled
+// with the text color.
+const foregroundPalette = 0xFFFF
+
+// colorGlyph returns the color data of a glyph: the paint of a COLR glyph,
+// or the PNG or JPEG bitmap of a CBDT or sbix glyph. It returns nil for
+// glyphs that are drawn from their outline.
+func colorGlyph(face *font.Face, gid uint16) font.GlyphData {
+	if face == nil {
+		return nil
+	}
+	switch data := face.GlyphData(font.GID(gid)).(type) {
+	case font.GlyphColor:
+		return data
+	case font.GlyphBitmap:
+		if data.Format == font.PNG || data.Format == font.JPG {
+			return data
+		}
+	}
+	return nil
+}
+
+// colorLayer is a glyph outline of a color glyph filled with a palette
+// color.
+type colorLayer struct {
+	gid     uint16
+	palette uint16
+}
+
+// colorLayers flattens the paint of a COLR glyph into the layers to draw,
+// bottom to top. COLRv0 glyphs and COLRv1 glyphs made of solid layers are
+// drawn exactly. Of other COLRv1 paints, like gradients and transforms, only
+// the glyph outlines are kept and filled with the text color.
+func colorLayers(face *font.Face, paint tables.PaintTable, palette uint16) []colorLayer {
+	switch p := paint.(type) {
+	case tables.PaintColrLayersResolved:
+		layers := make([]colorLayer, len(p))
+		for i, layer := range p {
+			layers[i] = colorLayer{gid: uint16(layer.GlyphID), palette: layer.PaletteIndex}
+		}
+		return layers
+	case tables.PaintColrLayers:
+		if face.COLR == nil {
+			return nil
+		}
+		paints, err := face.COLR.LayerList.Resolve(p)
+		if err != nil {
+			return nil
+		}
+		var layers []colorLayer
+		for _, child := range paints {
+			layers = append(layers, colorLayers(face, child, palette)...)
+		}
+		return layers
+	case tables.PaintGlyph:
+		switch fill := p.Paint.(type) {
+		case tables.PaintSolid:
+			palette = fill.PaletteIndex
+		case tables.PaintVarSolid:
+			palette = fill.PaletteIndex
+		}
+		return []colorLayer{{gid: p.GlyphID, palette: palette}}
+	case tables.PaintColrGlyph:
+		if paint, ok := face.COLR.Search(tables.GlyphID(p.GlyphID)); ok {
+			return colorLayers(face, paint, palette)
+		}
+	}
+	return nil
+}
+
+// writeColorLayers draws the layers of a COLR glyph as filled paths, with
+// the glyph origin at (x, y) in the flipped page coordinates. Layers in the
+// foreground color use the current fill color. Palette colors are drawn
+// opaque, and fully transparent layers are skipped.
+func writeColorLayers(content *bytes.Buffer, face *font.Face, glyph font.GlyphColor, x, y, size float64) {
+	if face.Upem() == 0 {
+		return
+	}
+	scale := size / float64(face.Upem())
+	fmt.Fprintf(content, "q\n")
+	// Font units have an upward y-axis.
+	fmt.Fprintf(content, "%g 0 0 %g %g %g cm\n", scale, -scale, x, y)
+	for _, layer := range colorLayers(face, glyph.Paint, foregroundPalette) {
+		outline, ok := face.GlyphDataOutline(tables.GlyphID(layer.gid))
+		if !ok || len(outline.Segments) == 0 {
+			continue
+		}
+		fmt.Fprintf(content, "q\n")
+		if layer.palette != foregroundPalette {
+			if len(face.CPAL) == 0 || int(layer.palette) >= len(face.CPAL[0]) {
+				fmt.Fprintf(content, "Q\n")
+				continue
+			}
+			c := face.CPAL[0][layer.palette]
+			if c.Alpha == 0 {
+				fmt.Fprintf(content, "Q\n")
+				continue
+			}
+			fmt.Fprintf(content, "%g %g %g rg\n", float64(c.Red)/255, float64(c.Green)/255, float64(c.Blue)/255)
+		}
+		writeOutline(content, outline)
+		fmt.Fprintf(content, "f\nQ\n")
+	}
+	fmt.Fprintf(content, "Q\n")
+}
+
+// writeOutline writes the path of a glyph outline. Quadratic segments are
+// converted to cubic ones.
+func writeOutline(content *bytes.Buffer, outline font.GlyphOutline) {
+	var last ot.SegmentPoint
+	for _, seg := range outline.Segments {
+		switch seg.Op {
+		case ot.SegmentOpMoveTo:
+			p := seg.Args[0]
+			fmt.Fprintf(content, "%g %g m\n", p.X, p.Y)
+			last = p
+		case ot.SegmentOpLineTo:
+			p := seg.Args[0]
+			fmt.Fprintf(content, "%g %g l\n", p.X, p.Y)
+			last = p
+		case ot.SegmentOpQuadTo:
+			c, p := seg.Args[0], seg.Args[1]
+			fmt.Fprintf(content, "%g %g %g %g %g %g c\n",
+				last.X+2.0/3*(c.X-last.X), last.Y+2.0/3*(c.Y-last.Y),
+				p.X+2.0/3*(c.X-p.X), p.Y+2.0/3*(c.Y-p.Y),
+				p.X, p.Y)
+			last = p
+		case ot.SegmentOpCubeTo:
+			c1, c2, p := seg.Args[0], seg.Args[1], seg.Args[2]
+			fmt.Fprintf(content, "%g %g %g %g %g %g c\n", c1.X, c1.Y, c2.X, c2.Y, p.X, p.Y)
+			last = p
+		}
+	}
+}
+
+// emojiKey identifies the bitmap of a glyph.
+type emojiKey struct {
+	face *font.Face
+	gid  uint16
+}
+
+// writeColorBitmap draws the bitmap of a CBDT or sbix glyph as an image,
+// with the glyph origin at (x, y) in the flipped page coordinates. The
+// bitmap is scaled to the glyph's extents at the text size.
+func (w *Writer) writeColorBitmap(content *bytes.Buffer, g *inline.ShapedGlyph, bitmap font.GlyphBitmap, x, y float64, imageRefs map[string]Ref, imageCounter *int) {
+	face := g.Font
+	extents, ok := face.GlyphExtents(font.GID(g.GlyphID))
+	if !ok || face.Upem() == 0 || extents.Width == 0 || extents.Height == 0 {
+		return
+	}
+
+	key := emojiKey{face: face, gid: g.GlyphID}
+	img, ok := w.emoji[key]
+	if !ok {
+		decoded, err := DecodeImageFile(bitmap.Data)
+		if err != nil {
+			// Without its bitmap, the glyph stays invisible.
+			return
+		}
+		img = decoded
+		w.emoji[key] = img
+	}
+	ref, err := w.getOrCreateImageXObject(img)
+	if err != nil {
+		return
+	}
+	name := fmt.Sprintf("Im%d", *imageCounter)
+	*imageCounter++
+	imageRefs[name] = ref
+
+	// Extents are in font units with an upward y-axis, and their height is
+	// negative.
+	scale := float64(g.Size) / float64(face.Upem())
+	left := x + float64(extents.XBearing)*scale
+	bottom := y - float64(extents.YBearing+extents.Height)*scale
+	width := float64(extents.Width) * scale
+	height := -float64(extents.Height) * scale
+
+	// The image's first row is its top, so it is mirrored back upright.
+	fmt.Fprintf(content, "q\n")
+	fmt.Fprintf(content, "%g 0 0 %g %g %g cm\n", width, -height, left, bottom)
+	fmt.Fprintf(content, "/%s Do\n", name)
+	fmt.Fprintf(content, "Q\n")
+}
+
+// writeColorGlyphs draws the color glyphs of shaped text over the
+// invisible text that represents them for extraction. The baseline of the
+// text starts at (x, y).
+func (w *Writer) writeColorGlyphs(content *bytes.Buffer, text *inline.ShapedText, x, y float64, imageRefs map[string]Ref, imageCounter *int) {
+	pen := x
+	for _, g := range text.Glyphs.Kept() {
+		gx := pen + float64(g.XOffset.At(g.Size))
+		gy := y - float64(g.YOffset.At(g.Size))
+		switch data := colorGlyph(g.Font, g.GlyphID).(type) {
+		case font.GlyphColor:
+			writeColorLayers(content, g.Font, data, gx, gy, float64(g.Size))
+		case font.GlyphBitmap:
+			w.writeColorBitmap(content, &g, data, gx, gy, imageRefs, imageCounter)
+		}
+		pen += float64(g.XAdvance.At(g.Size))
+	}
+}
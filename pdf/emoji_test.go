@@ -0,0 +1,44 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/font/opentype/tables"
+)
+
+func TestWriteOutline(t *testing.T) {
+	outline := font.GlyphOutline{Segments: []ot.Segment{
+		{Op: ot.SegmentOpMoveTo, Args: [3]ot.SegmentPoint{{X: 0, Y: 0}}},
+		{Op: ot.SegmentOpQuadTo, Args: [3]ot.SegmentPoint{{X: 3, Y: 3}, {X: 6, Y: 0}}},
+		{Op: ot.SegmentOpLineTo, Args: [3]ot.SegmentPoint{{X: 0, Y: 0}}},
+	}}
+	var content bytes.Buffer
+	writeOutline(&content, outline)
+	want := "0 0 m\n2 2 4 2 6 0 c\n0 0 l\n"
+	if got := content.String(); got != want {
+		t.Errorf("writeOutline = %q, want %q", got, want)
+	}
+}
+
+func TestColorLayers(t *testing.T) {
+	paint := tables.PaintColrLayersResolved{
+		{GlyphID: 4, PaletteIndex: 1},
+		{GlyphID: 5, PaletteIndex: foregroundPalette},
+	}
+	layers := colorLayers(nil, paint, foregroundPalette)
+	if len(layers) != 2 || layers[0] != (colorLayer{gid: 4, palette: 1}) || layers[1] != (colorLayer{gid: 5, palette: foregroundPalette}) {
+		t.Errorf("colorLayers = %+v", layers)
+	}
+
+	glyph := tables.PaintGlyph{GlyphID: 7, Paint: tables.PaintSolid{PaletteIndex: 2}}
+	if layers := colorLayers(nil, glyph, foregroundPalette); len(layers) != 1 || layers[0] != (colorLayer{gid: 7, palette: 2}) {
+		t.Errorf("colorLayers of a solid glyph = %+v", layers)
+	}
+
+	if colorGlyph(nil, 1) != nil {
+		t.Error("colorGlyph without a font is not nil")
+	}
+}
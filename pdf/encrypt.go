@@ -0,0 +1,296 @@
+package pdf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+	"io"
+)
+
+// Encryption configures the encryption of the output with the AES-256
+// standard security handler of PDF 2.0.
+type Encryption struct {
+	// UserPassword is needed to open the document. If empty, the document
+	// opens without a password, but the permissions still apply.
+	UserPassword string
+	// OwnerPassword lifts the permissions when it is used to open the
+	// document. If empty, a random password is used, so that the
+	// permissions can't be lifted.
+	OwnerPassword string
+	// Permissions restricts what readers may do with the document.
+	Permissions Permissions
+}
+
+// Permissions are the operations a reader may be denied. PDF viewers are
+// expected to honor them, but they are not enforced by the encryption.
+type Permissions struct {
+	// NoPrint denies printing.
+	NoPrint bool
+	// NoModify denies modifying the contents.
+	NoModify bool
+	// NoCopy denies copying or extracting text and graphics.
+	NoCopy bool
+	// NoAnnotate denies adding annotations and filling in forms.
+	NoAnnotate bool
+}
+
+// flags returns the value of the P entry of the encryption dictionary.
+// All bits are set except for those of denied operations and the two
+// lowest, which must be zero.
+func (p Permissions) flags() int32 {
+	flags := ^uint32(0) &^ 0b11
+	deny := func(denied bool, bit uint) {
+		if denied {
+			flags &^= 1 << (bit - 1)
+		}
+	}
+	deny(p.NoPrint, 3)
+	deny(p.NoModify, 4)
+	deny(p.NoCopy, 5)
+	deny(p.NoAnnotate, 6)
+	deny(p.NoPrint, 12)
+	return int32(flags)
+}
+
+// encryptor encrypts the strings and streams of a document with the file
+// encryption key.
+type encryptor struct {
+	// key is the 256-bit file encryption key.
+	key []byte
+	// dict is the encryption dictionary.
+	dict Dict
+	// id is the file identifier, which encrypted documents must have.
+	id HexString
+	// random is the source of the initialization vectors.
+	random io.Reader
+}
+
+// newEncryptor creates the file encryption key and the encryption
+// dictionary for the configuration, drawing random bytes from random.
+// Matches ISO 32000-2, 7.6.4.4.7 to 7.6.4.4.9 (revision 6)
+func newEncryptor(config Encryption, random io.Reader) (*encryptor, error) {
+	randomBytes := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		_, err := io.ReadFull(random, b)
+		return b, err
+	}
+
+	key, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	salts, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	userPassword := truncatePassword(config.UserPassword)
+	ownerPassword := truncatePassword(config.OwnerPassword)
+	if config.OwnerPassword == "" {
+		if ownerPassword, err = randomBytes(32); err != nil {
+			return nil, err
+		}
+	}
+
+	// The user key U is the hash of the user password with the validation
+	// salt followed by both salts; UE is the file key encrypted with the
+	// hash of the password with the key salt.
+	u := append(hashPassword(userPassword, salts[0:8], nil), salts[0:16]...)
+	ue, err := aesWithoutIV(hashPassword(userPassword, salts[8:16], nil), key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner key O and OE work the same, but the hashes also cover U.
+	o := append(hashPassword(ownerPassword, salts[16:24], u), salts[16:32]...)
+	oe, err := aesWithoutIV(hashPassword(ownerPassword, salts[24:32], u), key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perms holds the permissions encrypted with the file key, so that
+	// readers can check that P wasn't tampered with.
+	p := config.Permissions.flags()
+	perms := make([]byte, 16)
+	binary.LittleEndian.PutUint32(perms[0:4], uint32(p))
+	copy(perms[4:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 'T', 'a', 'd', 'b'})
+	if _, err := io.ReadFull(random, perms[12:]); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	block.Encrypt(perms, perms)
+
+	dict := Dict{
+		Name("Filter"): Name("Standard"),
+		Name("V"):      Int(5),
+		Name("R"):      Int(6),
+		Name("Length"): Int(256),
+		Name("CF"): Dict{
+			Name("StdCF"): Dict{
+				Name("Type"):      Name("CryptFilter"),
+				Name("CFM"):       Name("AESV3"),
+				Name("AuthEvent"): Name("DocOpen"),
+				Name("Length"):    Int(32),
+			},
+		},
+		Name("StmF"):  Name("StdCF"),
+		Name("StrF"):  Name("StdCF"),
+		Name("O"):     HexString(o),
+		Name("U"):     HexString(u),
+		Name("OE"):    HexString(oe),
+		Name("UE"):    HexString(ue),
+		Name("P"):     Int(p),
+		Name("Perms"): HexString(perms),
+	}
+	return &encryptor{key: key, dict: dict, id: HexString(id), random: random}, nil
+}
+
+// truncatePassword returns the UTF-8 bytes of a password, of which only
+// the first 127 are used.
+func truncatePassword(password string) []byte {
+	b := []byte(password)
+	if len(b) > 127 {
+		b = b[:127]
+	}
+	return b
+}
+
+// hashPassword computes the hash of a password with a salt and, for the
+// owner password, the user key.
+// Matches ISO 32000-2, 7.6.4.3.4 (Algorithm 2.B)
+func hashPassword(password, salt, userKey []byte) []byte {
+	sum := sha256.Sum256(concat(password, salt, userKey))
+	k := sum[:]
+	for round := 0; ; round++ {
+		k1 := make([]byte, 0, 64*(len(password)+len(k)+len(userKey)))
+		for range 64 {
+			k1 = append(k1, password...)
+			k1 = append(k1, k...)
+			k1 = append(k1, userKey...)
+		}
+
+		// The key and IV are 16 bytes each, which AES-128 accepts.
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		// The first 16 bytes of E as a big-endian number modulo 3 pick the
+		// next hash. Since 256 is 1 modulo 3, that's the sum of the bytes.
+		mod := 0
+		for _, b := range e[:16] {
+			mod += int(b)
+		}
+		var h hash.Hash
+		switch mod % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+
+		if round >= 63 && int(e[len(e)-1]) <= round+1-32 {
+			break
+		}
+	}
+	return k[:32]
+}
+
+// concat returns the concatenation of the byte slices.
+func concat(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+// aesWithoutIV encrypts data, whose length is a multiple of the block
+// size, with AES-256 in CBC mode with a zero IV and no padding.
+func aesWithoutIV(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, data)
+	return out, nil
+}
+
+// encrypt encrypts data with AES-256 in CBC mode. The result starts with
+// the random IV and the data is padded as in PKCS #5.
+// Matches ISO 32000-2, 7.6.3.2 (Algorithm 1.A)
+func (e *encryptor) encrypt(data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	out := make([]byte, aes.BlockSize+len(data)+padding)
+	if _, err := io.ReadFull(e.random, out[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	copy(out[aes.BlockSize:], data)
+	for i := len(out) - padding; i < len(out); i++ {
+		out[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], out[aes.BlockSize:])
+	return out, nil
+}
+
+// encryptObject returns the object with all of its strings and stream
+// data encrypted.
+func (e *encryptor) encryptObject(obj Object) (Object, error) {
+	switch v := obj.(type) {
+	case String:
+		data, err := e.encrypt([]byte(v))
+		return HexString(data), err
+	case HexString:
+		data, err := e.encrypt(v)
+		return HexString(data), err
+	case Array:
+		out := make(Array, len(v))
+		for i, item := range v {
+			encrypted, err := e.encryptObject(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = encrypted
+		}
+		return out, nil
+	case Dict:
+		out := make(Dict, len(v))
+		for key, item := range v {
+			encrypted, err := e.encryptObject(item)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = encrypted
+		}
+		return out, nil
+	case Stream:
+		dict, err := e.encryptObject(v.Dict)
+		if err != nil {
+			return nil, err
+		}
+		data, err := e.encrypt(v.Data)
+		if err != nil {
+			return nil, err
+		}
+		return Stream{Dict: dict.(Dict), Data: data}, nil
+	default:
+		return v, nil
+	}
+}
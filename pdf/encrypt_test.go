@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+)
+
+// fileKey recovers the file encryption key from the encryption dictionary
+// with the user password, as a reader opening the document does. It fails
+// the test if the password doesn't match.
+func fileKey(t *testing.T, dict Dict, password string) []byte {
+	t.Helper()
+	u := dict[Name("U")].(HexString)
+	if !bytes.Equal(hashPassword([]byte(password), u[32:40], nil), u[:32]) {
+		t.Fatalf("password %q does not match the user key", password)
+	}
+	block, err := aes.NewCipher(hashPassword([]byte(password), u[40:48], nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ue := dict[Name("UE")].(HexString)
+	key := make([]byte, len(ue))
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(key, ue)
+	return key
+}
+
+// decrypt decrypts data encrypted by an encryptor with the key.
+func decrypt(t *testing.T, key, data []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(out, data[aes.BlockSize:])
+	return out[:len(out)-int(out[len(out)-1])]
+}
+
+func TestEncryptorUserPassword(t *testing.T) {
+	e, err := newEncryptor(Encryption{UserPassword: "secret", OwnerPassword: "owner"}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := fileKey(t, e.dict, "secret"); !bytes.Equal(key, e.key) {
+		t.Error("the user password does not recover the file key")
+	}
+
+	u := e.dict[Name("U")].(HexString)
+	if bytes.Equal(hashPassword([]byte("wrong"), u[32:40], nil), u[:32]) {
+		t.Error("a wrong password matches the user key")
+	}
+	o := e.dict[Name("O")].(HexString)
+	if !bytes.Equal(hashPassword([]byte("owner"), o[32:40], u), o[:32]) {
+		t.Error("the owner password does not match the owner key")
+	}
+}
+
+func TestEncryptorPermissions(t *testing.T) {
+	e, err := newEncryptor(Encryption{Permissions: Permissions{NoPrint: true, NoCopy: true}}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := int32(e.dict[Name("P")].(Int))
+	for bit, allowed := range map[uint]bool{1: false, 2: false, 3: false, 4: true, 5: false, 6: true, 12: false} {
+		if got := p&(1<<(bit-1)) != 0; got != allowed {
+			t.Errorf("bit %d of P = %v, want %v", bit, got, allowed)
+		}
+	}
+
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	perms := make([]byte, 16)
+	block.Decrypt(perms, e.dict[Name("Perms")].(HexString))
+	if int32(binary.LittleEndian.Uint32(perms)) != p || string(perms[8:12]) != "Tadb" {
+		t.Errorf("decrypted Perms = %x, want P %d followed by Tadb", perms, p)
+	}
+}
+
+func TestEncryptObject(t *testing.T) {
+	e, err := newEncryptor(Encryption{}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := e.encryptObject(Dict{
+		Name("Title"): String("Hello"),
+		Name("Kids"):  Array{Ref{ID: 3}, HexString{0xFE, 0xFF}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := obj.(Dict)
+	if got := decrypt(t, e.key, dict[Name("Title")].(HexString)); string(got) != "Hello" {
+		t.Errorf("decrypted title = %q, want Hello", got)
+	}
+	kids := dict[Name("Kids")].(Array)
+	if kids[0] != (Ref{ID: 3}) {
+		t.Errorf("reference changed to %v", kids[0])
+	}
+	if got := decrypt(t, e.key, kids[1].(HexString)); !bytes.Equal(got, []byte{0xFE, 0xFF}) {
+		t.Errorf("decrypted hex string = %x, want feff", got)
+	}
+
+	stream, err := e.encryptObject(Stream{Dict: Dict{}, Data: bytes.Repeat([]byte("x"), 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decrypt(t, e.key, stream.(Stream).Data); !bytes.Equal(got, bytes.Repeat([]byte("x"), 32)) {
+		t.Errorf("decrypted stream = %q", got)
+	}
+}
+
+func TestWriteEncrypted(t *testing.T) {
+	doc := testDocument(2)
+	title := "Quarterly Report"
+	doc.Info.Title = &title
+	var out bytes.Buffer
+	opts := ExportOptions{Encryption: &Encryption{UserPassword: "secret"}}
+	if err := ExportWithOptions(doc, &out, opts); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/Filter /Standard", "/CFM /AESV3", "/Encrypt ", "/ID ["} {
+		if !bytes.Contains(out.Bytes(), []byte(want)) {
+			t.Errorf("encrypted output lacks %q", want)
+		}
+	}
+	if bytes.Contains(out.Bytes(), []byte(title)) {
+		t.Error("encrypted output contains the title in plain text")
+	}
+}
@@ -93,7 +93,6 @@ func (f *Type0Font) ToObjects(fontRef Ref, allocRef func() Ref) []IndirectObject
 		Dict: Dict{},
 		Data: cidToGIDMap,
 	}
-	cidToGIDStream.Compress()
 	objects = append(objects, IndirectObject{Ref: cidToGIDRef, Object: cidToGIDStream})
 
 	// Create CIDFont (DescendantFont)
@@ -115,7 +114,6 @@ func (f *Type0Font) ToObjects(fontRef Ref, allocRef func() Ref) []IndirectObject
 		Dict: Dict{},
 		Data: toUnicodeCMap,
 	}
-	toUnicodeStream.Compress()
 	objects = append(objects, IndirectObject{Ref: toUnicodeRef, Object: toUnicodeStream})
 
 	// Create Type0 font dictionary (the main font object)
@@ -5,13 +5,16 @@ import (
 	"compress/zlib"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
 
 	"github.com/boergens/gotypst/layout/pages"
+	"golang.org/x/image/webp"
 )
 
 // ImageXObject represents a PDF image XObject.
@@ -28,6 +31,10 @@ type ImageXObject struct {
 	ColorSpace string
 	// Filter is the compression filter (e.g., DCTDecode, FlateDecode).
 	Filter string
+	// DecodeParms are the parameters of the filter, if any.
+	DecodeParms Dict
+	// Decode is an optional array mapping samples to color values.
+	Decode []float64
 	// Data is the compressed image data.
 	Data []byte
 	// SMask is an optional soft mask for transparency.
@@ -49,6 +56,18 @@ func (img *ImageXObject) ToIndirectObject() IndirectObject {
 		dict[Name("Filter")] = Name(img.Filter)
 	}
 
+	if img.DecodeParms != nil {
+		dict[Name("DecodeParms")] = img.DecodeParms
+	}
+
+	if len(img.Decode) > 0 {
+		decode := make(Array, len(img.Decode))
+		for i, v := range img.Decode {
+			decode[i] = Real(v)
+		}
+		dict[Name("Decode")] = decode
+	}
+
 	if img.SMask != nil {
 		dict[Name("SMask")] = img.SMask.Ref
 	}
@@ -77,23 +96,54 @@ func EncodeImage(img *pages.Image, ref Ref) (*ImageXObject, error) {
 }
 
 // encodeJPEGImage creates an ImageXObject from JPEG data.
-// JPEG data can be embedded directly using DCTDecode filter.
+// JPEG data is embedded directly using the DCTDecode filter, without
+// recompression.
 func encodeJPEGImage(img *pages.Image, ref Ref) (*ImageXObject, error) {
-	return &ImageXObject{
+	xobj := &ImageXObject{
 		Ref:              ref,
 		Width:            img.Width,
 		Height:           img.Height,
-		BitsPerComponent: img.BitsPerComponent,
+		BitsPerComponent: 8,
 		ColorSpace:       img.ColorSpace.String(),
 		Filter:           "DCTDecode",
 		Data:             img.Data,
-	}, nil
+	}
+
+	// Adobe applications write CMYK JPEGs with inverted samples.
+	if img.ColorSpace == pages.ColorSpaceDeviceCMYK && hasAdobeMarker(img.Data) {
+		xobj.Decode = []float64{1, 0, 1, 0, 1, 0, 1, 0}
+	}
+
+	return xobj, nil
+}
+
+// hasAdobeMarker checks whether JPEG data contains an Adobe APP14 segment
+// before the start of the scan.
+func hasAdobeMarker(data []byte) bool {
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 {
+			break
+		}
+		segment := data[pos+4 : min(pos+2+length, len(data))]
+		if marker == 0xEE && bytes.HasPrefix(segment, []byte("Adobe")) {
+			return true
+		}
+		pos += 2 + length
+	}
+	return false
 }
 
 // encodePNGImage creates an ImageXObject from PNG data.
-// PNG data needs to be decoded and re-encoded for PDF.
+// The pixels are recompressed with Flate using PNG predictors, which PDF
+// readers undo while decoding. Transparency, including that of tRNS chunks,
+// is moved into a soft mask.
 func encodePNGImage(img *pages.Image, ref Ref) (*ImageXObject, error) {
-	// Decode PNG to get raw pixel data
 	pngImg, err := png.Decode(bytes.NewReader(img.Data))
 	if err != nil {
 		return nil, err
@@ -138,47 +188,18 @@ func encodeRawImage(img *pages.Image, ref Ref) (*ImageXObject, error) {
 
 // encodeGoImage encodes a Go image.Image to an ImageXObject.
 func encodeGoImage(img image.Image, ref Ref) (*ImageXObject, error) {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// Extract RGB and alpha data
-	rgb := make([]byte, width*height*3)
-	var alpha []byte
-	hasAlpha := false
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
-			idx := (y*width + x) * 3
-			rgb[idx] = uint8(r >> 8)
-			rgb[idx+1] = uint8(g >> 8)
-			rgb[idx+2] = uint8(b >> 8)
-
-			if a != 0xFFFF {
-				if alpha == nil {
-					alpha = make([]byte, width*height)
-					// Fill previous pixels with full opacity
-					for i := 0; i < y*width+x; i++ {
-						alpha[i] = 255
-					}
-				}
-				hasAlpha = true
-			}
-			if alpha != nil {
-				alpha[y*width+x] = uint8(a >> 8)
-			}
-		}
-	}
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+	samples, alpha, channels := imageChannels(img)
 
-	// Compress RGB data
-	var rgbBuf bytes.Buffer
-	zw := zlib.NewWriter(&rgbBuf)
-	if _, err := zw.Write(rgb); err != nil {
+	data, err := deflatePredicted(samples, width, channels)
+	if err != nil {
 		return nil, err
 	}
-	if err := zw.Close(); err != nil {
-		return nil, err
+
+	colorSpace := "DeviceRGB"
+	if channels == 1 {
+		colorSpace = "DeviceGray"
 	}
 
 	xobj := &ImageXObject{
@@ -186,13 +207,13 @@ func encodeGoImage(img image.Image, ref Ref) (*ImageXObject, error) {
 		Width:            width,
 		Height:           height,
 		BitsPerComponent: 8,
-		ColorSpace:       "DeviceRGB",
+		ColorSpace:       colorSpace,
 		Filter:           "FlateDecode",
-		Data:             rgbBuf.Bytes(),
+		DecodeParms:      predictorParms(width, channels),
+		Data:             data,
 	}
 
-	// Create soft mask for alpha if present
-	if hasAlpha && alpha != nil {
+	if alpha != nil {
 		smask, err := encodeAlphaMask(alpha, width, height, Ref{ID: ref.ID + 1})
 		if err != nil {
 			return nil, err
@@ -205,12 +226,8 @@ func encodeGoImage(img image.Image, ref Ref) (*ImageXObject, error) {
 
 // encodeAlphaMask creates a soft mask XObject for alpha transparency.
 func encodeAlphaMask(alpha []byte, width, height int, ref Ref) (*ImageXObject, error) {
-	var buf bytes.Buffer
-	zw := zlib.NewWriter(&buf)
-	if _, err := zw.Write(alpha); err != nil {
-		return nil, err
-	}
-	if err := zw.Close(); err != nil {
+	data, err := deflatePredicted(alpha, width, 1)
+	if err != nil {
 		return nil, err
 	}
 
@@ -221,26 +238,31 @@ func encodeAlphaMask(alpha []byte, width, height int, ref Ref) (*ImageXObject, e
 		BitsPerComponent: 8,
 		ColorSpace:       "DeviceGray",
 		Filter:           "FlateDecode",
-		Data:             buf.Bytes(),
+		DecodeParms:      predictorParms(width, 1),
+		Data:             data,
 	}, nil
 }
 
 // DecodeImageFile decodes an image from raw file data.
-// It auto-detects the format (JPEG, PNG) and returns a pages.Image.
+// It auto-detects the format (JPEG, PNG, GIF, WebP) and returns a
+// pages.Image. Of animated GIFs, only the first frame is used.
 func DecodeImageFile(data []byte) (*pages.Image, error) {
-	// Try JPEG first
-	if isJPEG(data) {
+	switch {
+	case isJPEG(data):
 		return decodeJPEGFile(data)
-	}
-
-	// Try PNG
-	if isPNG(data) {
+	case isPNG(data):
 		return decodePNGFile(data)
+	case isGIF(data):
+		return decodeGIFFile(data)
+	case isWebP(data):
+		return decodeWebPFile(data)
 	}
 
-	// Try generic image.Decode
+	// Try the decoders registered with the image package.
 	img, format, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
+	if errors.Is(err, image.ErrFormat) {
+		return nil, errors.New("unknown image format")
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -259,6 +281,44 @@ func isPNG(data []byte) bool {
 		data[4] == 0x0D && data[5] == 0x0A && data[6] == 0x1A && data[7] == 0x0A
 }
 
+// isGIF checks if data starts with GIF magic bytes.
+func isGIF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a"))
+}
+
+// isWebP checks if data is a RIFF container holding a WebP image.
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// isAnimatedWebP checks the animation flag of an extended WebP header.
+func isAnimatedWebP(data []byte) bool {
+	const animationBit = 1 << 1
+	return len(data) >= 21 && string(data[12:16]) == "VP8X" && data[20]&animationBit != 0
+}
+
+// decodeGIFFile decodes the first frame of GIF data and returns a
+// pages.Image.
+func decodeGIFFile(data []byte) (*pages.Image, error) {
+	img, err := gif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GIF image: %w", err)
+	}
+	return imageToPages(img, "gif")
+}
+
+// decodeWebPFile decodes WebP data and returns a pages.Image.
+func decodeWebPFile(data []byte) (*pages.Image, error) {
+	if isAnimatedWebP(data) {
+		return nil, errors.New("animated WebP images are not supported")
+	}
+	img, err := webp.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode WebP image: %w", err)
+	}
+	return imageToPages(img, "webp")
+}
+
 // decodeJPEGFile decodes JPEG data and returns a pages.Image.
 // For JPEG, we can embed the original data directly with DCTDecode.
 func decodeJPEGFile(data []byte) (*pages.Image, error) {
@@ -285,94 +345,46 @@ func decodeJPEGFile(data []byte) (*pages.Image, error) {
 	}, nil
 }
 
-// decodePNGFile decodes PNG data and returns a pages.Image.
+// decodePNGFile reads the header of PNG data and returns a pages.Image.
+// The original data is kept, so that PNGs are decoded only once, when they
+// are embedded.
 func decodePNGFile(data []byte) (*pages.Image, error) {
-	img, err := png.Decode(bytes.NewReader(data))
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// Extract RGB and alpha data
-	rgb := make([]byte, width*height*3)
-	var alpha []byte
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
-			idx := (y*width + x) * 3
-			rgb[idx] = uint8(r >> 8)
-			rgb[idx+1] = uint8(g >> 8)
-			rgb[idx+2] = uint8(b >> 8)
-
-			if a != 0xFFFF {
-				if alpha == nil {
-					alpha = make([]byte, width*height)
-					// Fill previous pixels with full opacity
-					for i := 0; i < y*width+x; i++ {
-						alpha[i] = 255
-					}
-				}
-			}
-			if alpha != nil {
-				alpha[y*width+x] = uint8(a >> 8)
-			}
-		}
+	colorSpace := pages.ColorSpaceDeviceRGB
+	if cfg.ColorModel == color.GrayModel || cfg.ColorModel == color.Gray16Model {
+		colorSpace = pages.ColorSpaceDeviceGray
 	}
 
 	return &pages.Image{
-		Data:             rgb,
-		Format:           pages.ImageFormatRaw,
-		Width:            width,
-		Height:           height,
+		Data:             data,
+		Format:           pages.ImageFormatPNG,
+		Width:            cfg.Width,
+		Height:           cfg.Height,
 		BitsPerComponent: 8,
-		ColorSpace:       pages.ColorSpaceDeviceRGB,
-		Alpha:            alpha,
+		ColorSpace:       colorSpace,
 	}, nil
 }
 
 // imageToPages converts a Go image.Image to a pages.Image.
 func imageToPages(img image.Image, format string) (*pages.Image, error) {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// Extract RGB and alpha data
-	rgb := make([]byte, width*height*3)
-	var alpha []byte
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
-			idx := (y*width + x) * 3
-			rgb[idx] = uint8(r >> 8)
-			rgb[idx+1] = uint8(g >> 8)
-			rgb[idx+2] = uint8(b >> 8)
-
-			if a != 0xFFFF {
-				if alpha == nil {
-					alpha = make([]byte, width*height)
-					for i := 0; i < y*width+x; i++ {
-						alpha[i] = 255
-					}
-				}
-			}
-			if alpha != nil {
-				alpha[y*width+x] = uint8(a >> 8)
-			}
-		}
+	samples, alpha, channels := imageChannels(img)
+
+	colorSpace := pages.ColorSpaceDeviceRGB
+	if channels == 1 {
+		colorSpace = pages.ColorSpaceDeviceGray
 	}
 
 	return &pages.Image{
-		Data:             rgb,
+		Data:             samples,
 		Format:           pages.ImageFormatRaw,
-		Width:            width,
-		Height:           height,
+		Width:            img.Bounds().Dx(),
+		Height:           img.Bounds().Dy(),
 		BitsPerComponent: 8,
-		ColorSpace:       pages.ColorSpaceDeviceRGB,
+		ColorSpace:       colorSpace,
 		Alpha:            alpha,
 	}, nil
 }
@@ -0,0 +1,100 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+func TestEncodePNGImageWithAlpha(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 64})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := DecodeImageFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != pages.ImageFormatPNG || !bytes.Equal(img.Data, buf.Bytes()) {
+		t.Fatal("PNG data was not kept for embedding")
+	}
+
+	xobj, err := EncodeImage(img, Ref{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xobj.ColorSpace != "DeviceRGB" || xobj.DecodeParms == nil {
+		t.Errorf("got color space %s with parameters %v", xobj.ColorSpace, xobj.DecodeParms)
+	}
+	if xobj.SMask == nil {
+		t.Fatal("translucent PNG has no soft mask")
+	}
+	alpha := unpredict(t, xobj.SMask.Data, 3, 1)
+	if want := []byte{0, 0, 0, 0, 64, 0}; !bytes.Equal(alpha, want) {
+		t.Errorf("alpha = %v, want %v", alpha, want)
+	}
+}
+
+func TestHasAdobeMarker(t *testing.T) {
+	adobe := []byte{0xFF, 0xD8, 0xFF, 0xEE, 0x00, 0x07, 'A', 'd', 'o', 'b', 'e', 0xFF, 0xDA}
+	if !hasAdobeMarker(adobe) {
+		t.Error("Adobe segment not found")
+	}
+	plain := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F', 0xFF, 0xDA}
+	if hasAdobeMarker(plain) {
+		t.Error("Adobe segment found in plain JPEG")
+	}
+}
+
+func TestDecodeGIFFirstFrame(t *testing.T) {
+	frame := func(c color.Color) *image.Paletted {
+		img := image.NewPaletted(image.Rect(0, 0, 4, 3), palette.Plan9)
+		for i := range img.Pix {
+			img.Pix[i] = uint8(img.Palette.Index(c))
+		}
+		return img
+	}
+	var buf bytes.Buffer
+	anim := &gif.GIF{
+		Image: []*image.Paletted{frame(color.White), frame(color.Black)},
+		Delay: []int{10, 10},
+	}
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := DecodeImageFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 4 || img.Height != 3 {
+		t.Errorf("size = %dx%d, want 4x3", img.Width, img.Height)
+	}
+	if img.Data[0] != 255 {
+		t.Errorf("first sample = %d, want the white first frame", img.Data[0])
+	}
+}
+
+func TestDecodeAnimatedWebP(t *testing.T) {
+	data := []byte("RIFF\x16\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	_, err := DecodeImageFile(data)
+	if err == nil || err.Error() != "animated WebP images are not supported" {
+		t.Errorf("err = %v, want animated WebP error", err)
+	}
+}
+
+func TestDecodeUnknownFormat(t *testing.T) {
+	_, err := DecodeImageFile([]byte("not an image"))
+	if err == nil || err.Error() != "unknown image format" {
+		t.Errorf("err = %v, want unknown format error", err)
+	}
+}
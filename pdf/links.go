@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+// linkArea is a link of a page with its position on the page.
+type linkArea struct {
+	dest pages.Destination
+	pos  layout.Point
+	size layout.Size
+}
+
+// collectLinks appends the links of the frame, which is at offset on its
+// page, to links.
+func collectLinks(frame *pages.Frame, offset layout.Point, links *[]linkArea) {
+	for _, positioned := range frame.Items {
+		pos := layout.Point{X: offset.X + positioned.Pos.X, Y: offset.Y + positioned.Pos.Y}
+		switch item := positioned.Item.(type) {
+		case pages.GroupItem:
+			collectLinks(&item.Frame, pos, links)
+		case pages.LinkItem:
+			*links = append(*links, linkArea{dest: item.Dest, pos: pos, size: item.Size})
+		}
+	}
+}
+
+// linkAnnotations builds the link annotations of a page. Links to URLs get
+// a URI action and links within the document a GoTo action to the
+// destination. Links to unresolved labels are left out.
+// Matches Rust: write_annotations
+func linkAnnotations(page *pages.Page, docPages []pages.Page, pageRefs []Ref) Array {
+	var links []linkArea
+	collectLinks(&page.Frame, layout.Point{}, &links)
+
+	var annots Array
+	pageHeight := float64(page.Frame.Size.Height)
+	for _, link := range links {
+		var action Dict
+		switch {
+		case link.dest.URL != "":
+			action = Dict{
+				Name("S"):   Name("URI"),
+				Name("URI"): String(link.dest.URL),
+			}
+		case link.dest.Position != nil:
+			dest := destination(*link.dest.Position, docPages, pageRefs)
+			if dest == nil {
+				continue
+			}
+			action = Dict{
+				Name("S"): Name("GoTo"),
+				Name("D"): dest,
+			}
+		default:
+			continue
+		}
+
+		x := float64(link.pos.X)
+		y := float64(link.pos.Y)
+		annots = append(annots, Dict{
+			Name("Type"):    Name("Annot"),
+			Name("Subtype"): Name("Link"),
+			Name("Rect"): Array{
+				Real(x),
+				Real(pageHeight - y - float64(link.size.Height)),
+				Real(x + float64(link.size.Width)),
+				Real(pageHeight - y),
+			},
+			Name("Border"): Array{Int(0), Int(0), Int(0)},
+			Name("A"):      action,
+		})
+	}
+	return annots
+}
+
+// destination builds an explicit destination that shows the position at
+// the top-left corner of the window. It returns nil if the page doesn't
+// exist.
+func destination(pos pages.Position, docPages []pages.Page, pageRefs []Ref) Array {
+	if pos.Page < 0 || pos.Page >= len(docPages) || pos.Page >= len(pageRefs) {
+		return nil
+	}
+	height := float64(docPages[pos.Page].Frame.Size.Height)
+	return Array{
+		pageRefs[pos.Page],
+		Name("XYZ"),
+		Real(float64(pos.Point.X)),
+		Real(height - float64(pos.Point.Y)),
+		Null{},
+	}
+}
@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+func TestLinkAnnotations(t *testing.T) {
+	inner := pages.Hard(layout.Size{Width: 50, Height: 50})
+	inner.Push(layout.Point{X: 5, Y: 5}, pages.LinkItem{
+		Dest: pages.Destination{URL: "https://typst.app"},
+		Size: layout.Size{Width: 20, Height: 10},
+	})
+	first := pages.Hard(layout.Size{Width: 100, Height: 200})
+	first.PushFrame(layout.Point{X: 10, Y: 20}, inner)
+	first.Push(layout.Point{}, pages.LinkItem{
+		Dest: pages.Destination{Position: &pages.Position{Page: 1, Point: layout.Point{X: 30, Y: 40}}},
+		Size: layout.Size{Width: 10, Height: 10},
+	})
+	first.Push(layout.Point{}, pages.LinkItem{Dest: pages.Destination{Label: "unresolved"}})
+	docPages := []pages.Page{{Frame: first}, {Frame: pages.Hard(layout.Size{Width: 100, Height: 300})}}
+	pageRefs := []Ref{{ID: 7}, {ID: 8}}
+
+	annots := linkAnnotations(&docPages[0], docPages, pageRefs)
+	if len(annots) != 2 {
+		t.Fatalf("got %d annotations, want 2", len(annots))
+	}
+
+	uri := annots[0].(Dict)
+	if rect := uri[Name("Rect")]; !reflect.DeepEqual(rect, Array{Real(15), Real(165), Real(35), Real(175)}) {
+		t.Errorf("URI link Rect = %v", rect)
+	}
+	if action := uri[Name("A")]; !reflect.DeepEqual(action, Dict{Name("S"): Name("URI"), Name("URI"): String("https://typst.app")}) {
+		t.Errorf("URI link action = %v", action)
+	}
+
+	goTo := annots[1].(Dict)[Name("A")].(Dict)
+	wantDest := Array{Ref{ID: 8}, Name("XYZ"), Real(30), Real(260), Null{}}
+	if goTo[Name("S")] != Name("GoTo") || !reflect.DeepEqual(goTo[Name("D")], wantDest) {
+		t.Errorf("internal link action = %v, want GoTo to %v", goTo, wantDest)
+	}
+}
@@ -0,0 +1,124 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+// infoDict builds the document information dictionary from the document's
+// metadata. It returns nil if there is no metadata.
+func infoDict(info pages.DocumentInfo) Dict {
+	dict := make(Dict)
+	if info.Title != nil {
+		dict[Name("Title")] = textString(*info.Title)
+	}
+	if len(info.Author) > 0 {
+		dict[Name("Author")] = textString(strings.Join(info.Author, ", "))
+	}
+	if info.Description != nil {
+		dict[Name("Subject")] = textString(*info.Description)
+	}
+	if len(info.Keywords) > 0 {
+		dict[Name("Keywords")] = textString(strings.Join(info.Keywords, ", "))
+	}
+	if info.Date != nil {
+		date := String(fmt.Sprintf("D:%04d%02d%02d", info.Date.Year, info.Date.Month, info.Date.Day))
+		dict[Name("CreationDate")] = date
+		dict[Name("ModDate")] = date
+	}
+	if len(dict) == 0 {
+		return nil
+	}
+	return dict
+}
+
+// textString encodes a PDF text string. ASCII text is written as is and
+// other text as UTF-16BE with a byte order mark.
+func textString(s string) Object {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return String(s)
+	}
+	encoded := []byte{0xFE, 0xFF}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	return HexString(encoded)
+}
+
+// xmpMetadata builds the XMP metadata packet for the document's metadata.
+// It returns nil if there is no metadata.
+func xmpMetadata(info pages.DocumentInfo) []byte {
+	var body bytes.Buffer
+	alt := func(tag, value string) {
+		fmt.Fprintf(&body, "<%s><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></%s>\n", tag, xmlEscape(value), tag)
+	}
+	list := func(tag, kind string, values []string) {
+		fmt.Fprintf(&body, "<%s><rdf:%s>", tag, kind)
+		for _, v := range values {
+			fmt.Fprintf(&body, "<rdf:li>%s</rdf:li>", xmlEscape(v))
+		}
+		fmt.Fprintf(&body, "</rdf:%s></%s>\n", kind, tag)
+	}
+
+	if info.Title != nil {
+		alt("dc:title", *info.Title)
+	}
+	if len(info.Author) > 0 {
+		list("dc:creator", "Seq", info.Author)
+	}
+	if info.Description != nil {
+		alt("dc:description", *info.Description)
+	}
+	if len(info.Keywords) > 0 {
+		list("dc:subject", "Bag", info.Keywords)
+		fmt.Fprintf(&body, "<pdf:Keywords>%s</pdf:Keywords>\n", xmlEscape(strings.Join(info.Keywords, ", ")))
+	}
+	if info.Date != nil {
+		date := fmt.Sprintf("%04d-%02d-%02d", info.Date.Year, info.Date.Month, info.Date.Day)
+		fmt.Fprintf(&body, "<xmp:CreateDate>%s</xmp:CreateDate>\n<xmp:ModifyDate>%s</xmp:ModifyDate>\n", date, date)
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<?xpacket begin=\"\xEF\xBB\xBF\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	buf.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	buf.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	buf.WriteString("<rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\">\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	buf.WriteString("<?xpacket end=\"r\"?>")
+	return buf.Bytes()
+}
+
+// xmlEscape escapes the characters with a special meaning in XML.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	for _, c := range s {
+		switch c {
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '&':
+			buf.WriteString("&amp;")
+		case '"':
+			buf.WriteString("&quot;")
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+func TestInfoDict(t *testing.T) {
+	title := "Report"
+	info := pages.DocumentInfo{
+		Title:    &title,
+		Author:   []string{"Ada", "Grace"},
+		Keywords: []string{"math", "engines"},
+		Date:     &pages.Date{Year: 2024, Month: 3, Day: 7},
+	}
+
+	dict := infoDict(info)
+	if dict[Name("Title")] != String("Report") {
+		t.Errorf("Title = %v, want Report", dict[Name("Title")])
+	}
+	if dict[Name("Author")] != String("Ada, Grace") {
+		t.Errorf("Author = %v, want all authors", dict[Name("Author")])
+	}
+	if dict[Name("Keywords")] != String("math, engines") {
+		t.Errorf("Keywords = %v, want all keywords", dict[Name("Keywords")])
+	}
+	if dict[Name("CreationDate")] != String("D:20240307") {
+		t.Errorf("CreationDate = %v, want D:20240307", dict[Name("CreationDate")])
+	}
+
+	if infoDict(pages.DocumentInfo{}) != nil {
+		t.Error("expected no info dictionary without metadata")
+	}
+}
+
+func TestTextStringUTF16(t *testing.T) {
+	got, ok := textString("Ä").(HexString)
+	if !ok {
+		t.Fatalf("expected hex string for non-ASCII text, got %T", textString("Ä"))
+	}
+	if !bytes.Equal(got, []byte{0xFE, 0xFF, 0x00, 0xC4}) {
+		t.Errorf("textString(Ä) = % X, want FE FF 00 C4", []byte(got))
+	}
+}
+
+func TestXMPMetadata(t *testing.T) {
+	title := "Fish & Chips"
+	xmp := string(xmpMetadata(pages.DocumentInfo{Title: &title, Author: []string{"Ada"}}))
+	for _, want := range []string{
+		`<rdf:li xml:lang="x-default">Fish &amp; Chips</rdf:li>`,
+		`<dc:creator><rdf:Seq><rdf:li>Ada</rdf:li></rdf:Seq></dc:creator>`,
+		`<?xpacket end="r"?>`,
+	} {
+		if !strings.Contains(xmp, want) {
+			t.Errorf("XMP metadata is missing %q", want)
+		}
+	}
+
+	if xmpMetadata(pages.DocumentInfo{}) != nil {
+		t.Error("expected no XMP metadata without metadata")
+	}
+}
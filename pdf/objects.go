@@ -5,6 +5,8 @@ import (
 	"compress/zlib"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -156,12 +158,14 @@ func (a Array) writeTo(w io.Writer) error {
 // Dict represents a PDF dictionary.
 type Dict map[Name]Object
 
-// writeTo writes the dictionary in PDF format.
+// writeTo writes the dictionary in PDF format. Keys are written in sorted
+// order, so that the output is deterministic.
 func (d Dict) writeTo(w io.Writer) error {
 	if _, err := w.Write([]byte("<<")); err != nil {
 		return err
 	}
-	for key, val := range d {
+	for _, key := range slices.Sorted(maps.Keys(d)) {
+		val := d[key]
 		if err := key.writeTo(w); err != nil {
 			return err
 		}
@@ -207,12 +211,10 @@ func (s Stream) writeTo(w io.Writer) error {
 	return err
 }
 
-// Compress compresses the stream data using zlib/FlateDecode.
+// Compress compresses the stream data using zlib/FlateDecode. The filter
+// is added to a copy of the dictionary, since the caller's dictionary may
+// be shared with other streams.
 func (s *Stream) Compress() error {
-	if s.Dict == nil {
-		s.Dict = make(Dict)
-	}
-
 	// Check if already compressed
 	if _, ok := s.Dict[Name("Filter")]; ok {
 		return nil
@@ -227,8 +229,13 @@ func (s *Stream) Compress() error {
 		return err
 	}
 
+	dict := maps.Clone(s.Dict)
+	if dict == nil {
+		dict = make(Dict)
+	}
+	dict[Name("Filter")] = Name("FlateDecode")
+	s.Dict = dict
 	s.Data = buf.Bytes()
-	s.Dict[Name("Filter")] = Name("FlateDecode")
 	return nil
 }
 
@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	"github.com/boergens/gotypst/layout/pages"
+	"github.com/boergens/gotypst/library/model"
+)
+
+// pageLabel is the label a PDF viewer shows for a page. Labels with a
+// style are numbered by the viewer, starting at offset; labels without a
+// style show only the prefix.
+type pageLabel struct {
+	style  Name
+	prefix string
+	offset int
+}
+
+// labelForPage returns the label of a page with numbering. The numbering
+// maps to a PDF numbering style if its first counting symbol has one and it
+// has no suffix, since PDF labels can't have one; otherwise the whole
+// formatted number becomes the prefix.
+// Matches Rust: PdfPageLabel::generate
+func labelForPage(page *pages.Page) (pageLabel, bool) {
+	if page.Numbering == nil {
+		return pageLabel{}, false
+	}
+	pattern, err := model.ParseNumberingPattern(page.Numbering.Pattern)
+	if err != nil {
+		return pageLabel{}, false
+	}
+
+	number := page.Number
+	var style Name
+	if pattern.Suffix == "" {
+		switch kind := pattern.Pieces[0].Kind; {
+		case kind == model.NumberingArabic:
+			style = "D"
+		case kind == model.NumberingLowerRoman:
+			style = "r"
+		case kind == model.NumberingUpperRoman:
+			style = "R"
+		case kind == model.NumberingLowerLatin && number <= 26:
+			style = "a"
+		case kind == model.NumberingUpperLatin && number <= 26:
+			style = "A"
+		}
+	}
+
+	if style == "" || number < 1 {
+		return pageLabel{prefix: pattern.Apply([]int{number})}, true
+	}
+	return pageLabel{style: style, prefix: pattern.Pieces[0].Prefix, offset: number}, true
+}
+
+// pageLabels builds the page labels number tree of the document. A new
+// label range starts only where a page's label doesn't continue the range
+// of the page before. It returns nil if no page is numbered.
+// Matches Rust: write_page_labels
+func pageLabels(docPages []pages.Page) Dict {
+	var nums Array
+	var prev *pageLabel
+	for i := range docPages {
+		label, ok := labelForPage(&docPages[i])
+		if !ok {
+			continue
+		}
+		if prev != nil && label.style != "" && label.style == prev.style &&
+			label.prefix == prev.prefix && label.offset == prev.offset+1 {
+			prev = &label
+			continue
+		}
+
+		dict := make(Dict)
+		if label.style != "" {
+			dict[Name("S")] = label.style
+			if label.offset != 1 {
+				dict[Name("St")] = Int(label.offset)
+			}
+		}
+		if label.prefix != "" {
+			dict[Name("P")] = textString(label.prefix)
+		}
+		nums = append(nums, Int(i), dict)
+		prev = &label
+	}
+	if len(nums) == 0 {
+		return nil
+	}
+	return Dict{Name("Nums"): nums}
+}
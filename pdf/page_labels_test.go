@@ -0,0 +1,54 @@
+package pdf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+// numberedPages returns pages with the given numbering patterns, numbered
+// from one within each run of the same pattern.
+func numberedPages(patterns ...string) []pages.Page {
+	result := make([]pages.Page, len(patterns))
+	number := 0
+	for i, pattern := range patterns {
+		if i == 0 || pattern != patterns[i-1] {
+			number = 0
+		}
+		number++
+		result[i].Number = number
+		if pattern != "" {
+			result[i].Numbering = &pages.Numbering{Pattern: pattern}
+		}
+	}
+	return result
+}
+
+func TestPageLabelsRomanThenArabic(t *testing.T) {
+	labels := pageLabels(numberedPages("i", "i", "1", "1", "1"))
+	want := Dict{Name("Nums"): Array{
+		Int(0), Dict{Name("S"): Name("r")},
+		Int(2), Dict{Name("S"): Name("D")},
+	}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("pageLabels = %v, want %v", labels, want)
+	}
+}
+
+func TestPageLabelsPrefixAndSuffix(t *testing.T) {
+	labels := pageLabels(numberedPages("S-1", "- 1 -"))
+	want := Dict{Name("Nums"): Array{
+		Int(0), Dict{Name("S"): Name("D"), Name("P"): String("S-")},
+		Int(1), Dict{Name("P"): String("- 1 -")},
+	}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("pageLabels = %v, want %v", labels, want)
+	}
+}
+
+func TestPageLabelsWithoutNumbering(t *testing.T) {
+	if labels := pageLabels(numberedPages("", "")); labels != nil {
+		t.Errorf("expected no page labels, got %v", labels)
+	}
+}
@@ -0,0 +1,162 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+)
+
+// PNG filter types, written as the first byte of each predicted row.
+const (
+	filterNone byte = iota
+	filterSub
+	filterUp
+	filterAverage
+	filterPaeth
+)
+
+// imageChannels splits an image into 8-bit color samples and, if any pixel
+// is not fully opaque, an 8-bit alpha channel. Colors are returned
+// unassociated with alpha, as PDF soft masks expect. Grayscale images keep a
+// single color channel.
+func imageChannels(img image.Image) (samples []byte, alpha []byte, channels int) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	switch img.(type) {
+	case *image.Gray, *image.Gray16:
+		samples = make([]byte, 0, width*height)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				samples = append(samples, color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			}
+		}
+		return samples, nil, 1
+	}
+
+	samples = make([]byte, 0, width*height*3)
+	alpha = make([]byte, 0, width*height)
+	opaque := true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			samples = append(samples, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xFF
+		}
+	}
+	if opaque {
+		alpha = nil
+	}
+	return samples, alpha, 3
+}
+
+// deflatePredicted compresses 8-bit samples with Flate after applying PNG
+// predictors row by row. Each row uses the filter that yields the smallest
+// sum of absolute differences, the heuristic recommended by the PNG
+// specification.
+func deflatePredicted(samples []byte, width, channels int) ([]byte, error) {
+	stride := width * channels
+	prev := make([]byte, stride)
+	candidates := [5][]byte{}
+	for i := range candidates {
+		candidates[i] = make([]byte, stride)
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	for start := 0; start+stride <= len(samples) && stride > 0; start += stride {
+		row := samples[start : start+stride]
+		best, bestScore := filterNone, -1
+		for filter := filterNone; filter <= filterPaeth; filter++ {
+			out := candidates[filter]
+			applyFilter(filter, out, row, prev, channels)
+			score := 0
+			for _, b := range out {
+				score += int(absSigned(b))
+			}
+			if bestScore < 0 || score < bestScore {
+				best, bestScore = filter, score
+			}
+		}
+		if _, err := zw.Write([]byte{best}); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(candidates[best]); err != nil {
+			return nil, err
+		}
+		prev = row
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// applyFilter writes the filtered bytes of a row into out. The row above is
+// prev, and bpp is the number of bytes per pixel.
+func applyFilter(filter byte, out, row, prev []byte, bpp int) {
+	for i := range row {
+		var left, upLeft byte
+		if i >= bpp {
+			left = row[i-bpp]
+			upLeft = prev[i-bpp]
+		}
+		up := prev[i]
+		switch filter {
+		case filterNone:
+			out[i] = row[i]
+		case filterSub:
+			out[i] = row[i] - left
+		case filterUp:
+			out[i] = row[i] - up
+		case filterAverage:
+			out[i] = row[i] - byte((int(left)+int(up))/2)
+		case filterPaeth:
+			out[i] = row[i] - paeth(left, up, upLeft)
+		}
+	}
+}
+
+// paeth returns the neighbour closest to the linear prediction
+// left + up - upLeft.
+func paeth(left, up, upLeft byte) byte {
+	p := int(left) + int(up) - int(upLeft)
+	pa := abs(p - int(left))
+	pb := abs(p - int(up))
+	pc := abs(p - int(upLeft))
+	switch {
+	case pa <= pb && pa <= pc:
+		return left
+	case pb <= pc:
+		return up
+	default:
+		return upLeft
+	}
+}
+
+// predictorParms returns the decode parameters for data compressed by
+// deflatePredicted.
+func predictorParms(width, channels int) Dict {
+	return Dict{
+		Name("Predictor"):        Int(15),
+		Name("Colors"):           Int(channels),
+		Name("BitsPerComponent"): Int(8),
+		Name("Columns"):          Int(width),
+	}
+}
+
+func absSigned(b byte) byte {
+	if int8(b) < 0 {
+		return byte(-int8(b))
+	}
+	return b
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
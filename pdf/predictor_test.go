@@ -0,0 +1,102 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+)
+
+// unpredict inflates data compressed by deflatePredicted and reverses the
+// PNG predictors, like a PDF reader would.
+func unpredict(t *testing.T, data []byte, width, channels int) []byte {
+	t.Helper()
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stride := width * channels
+	prev := make([]byte, stride)
+	var out []byte
+	for start := 0; start < len(raw); start += stride + 1 {
+		filter, row := raw[start], raw[start+1:start+1+stride]
+		cur := make([]byte, stride)
+		for i := range row {
+			var left, upLeft byte
+			if i >= channels {
+				left = cur[i-channels]
+				upLeft = prev[i-channels]
+			}
+			switch filter {
+			case filterNone:
+				cur[i] = row[i]
+			case filterSub:
+				cur[i] = row[i] + left
+			case filterUp:
+				cur[i] = row[i] + prev[i]
+			case filterAverage:
+				cur[i] = row[i] + byte((int(left)+int(prev[i]))/2)
+			case filterPaeth:
+				cur[i] = row[i] + paeth(left, prev[i], upLeft)
+			default:
+				t.Fatalf("unknown filter type %d", filter)
+			}
+		}
+		out = append(out, cur...)
+		prev = cur
+	}
+	return out
+}
+
+func TestDeflatePredictedRoundTrip(t *testing.T) {
+	const width, height, channels = 7, 5, 3
+	samples := make([]byte, width*height*channels)
+	for i := range samples {
+		samples[i] = byte(i*37 + i/5)
+	}
+
+	data, err := deflatePredicted(samples, width, channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := unpredict(t, data, width, channels); !bytes.Equal(got, samples) {
+		t.Errorf("round trip mismatch:\ngot  %v\nwant %v", got, samples)
+	}
+}
+
+func TestImageChannels(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 2, 1))
+	gray.SetGray(1, 0, color.Gray{Y: 200})
+	samples, alpha, channels := imageChannels(gray)
+	if channels != 1 || alpha != nil || !bytes.Equal(samples, []byte{0, 200}) {
+		t.Errorf("gray: got %v, %v, %d", samples, alpha, channels)
+	}
+
+	opaque := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	opaque.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 255})
+	if _, alpha, _ := imageChannels(opaque); alpha != nil {
+		t.Errorf("opaque image has alpha %v", alpha)
+	}
+
+	// Colors of translucent pixels are not darkened by their alpha.
+	translucent := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	translucent.SetNRGBA(0, 0, color.NRGBA{R: 255, G: 128, B: 0, A: 128})
+	translucent.SetNRGBA(1, 0, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	samples, alpha, channels = imageChannels(translucent)
+	if channels != 3 {
+		t.Fatalf("channels = %d, want 3", channels)
+	}
+	if want := []byte{255, 128, 0, 10, 20, 30}; !bytes.Equal(samples, want) {
+		t.Errorf("samples = %v, want %v", samples, want)
+	}
+	if want := []byte{128, 255}; !bytes.Equal(alpha, want) {
+		t.Errorf("alpha = %v, want %v", alpha, want)
+	}
+}
@@ -1,12 +1,17 @@
 package pdf
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	"io"
+	"math"
+	"sync"
 
 	"github.com/boergens/gotypst/layout/inline"
 	"github.com/boergens/gotypst/layout/pages"
+	"github.com/go-text/typesetting/font"
 )
 
 // Writer handles PDF document generation.
@@ -17,6 +22,9 @@ type Writer struct {
 	nextID int
 	// images maps image data pointers to their XObject references.
 	images map[*pages.Image]Ref
+	// emoji holds the decoded bitmaps of color glyphs, so that each is
+	// embedded once.
+	emoji map[emojiKey]*pages.Image
 	// pageRefs stores references to page objects.
 	pageRefs []Ref
 	// tagManager handles PDF/UA accessibility tagging.
@@ -27,6 +35,43 @@ type Writer struct {
 	renderer *Renderer
 	// fontRefs maps font resource names to their references.
 	fontRefs map[string]Ref
+	// jobs is the maximum number of content streams encoded concurrently.
+	jobs int
+	// streams are the page content streams waiting to be encoded.
+	streams []pendingStream
+	// streaming indicates whether finished objects are written out while
+	// the document is still being processed.
+	streaming bool
+	// out is the output being written to.
+	out *countingWriter
+	// offsets maps the IDs of written objects to their byte offsets, for
+	// the cross-reference table.
+	offsets map[int]int64
+	// encryption configures the encryption of the output, if any.
+	encryption *Encryption
+	// encryptor encrypts the objects while the document is written.
+	encryptor *encryptor
+	// encryptRef is the reference to the encryption dictionary.
+	encryptRef Ref
+	// uncompressed disables the compression of streams.
+	uncompressed bool
+	// objectStreams indicates whether objects other than streams are
+	// packed into object streams, with a cross-reference stream in place
+	// of the cross-reference table.
+	objectStreams bool
+	// packed are the objects waiting to be packed into an object stream.
+	packed []IndirectObject
+	// packedIn maps the IDs of objects in object streams to where they
+	// are, for the cross-reference stream.
+	packedIn map[int]packedLocation
+}
+
+// pendingStream is a page content stream whose object slot has been
+// reserved, but whose data has not been compressed yet.
+type pendingStream struct {
+	// index is the position of the reserved slot in the writer's objects.
+	index int
+	data  []byte
 }
 
 // NewWriter creates a new PDF writer.
@@ -34,6 +79,7 @@ func NewWriter() *Writer {
 	return &Writer{
 		nextID:   1,
 		images:   make(map[*pages.Image]Ref),
+		emoji:    make(map[emojiKey]*pages.Image),
 		renderer: NewRenderer(),
 		fontRefs: make(map[string]Ref),
 	}
@@ -55,6 +101,42 @@ func (w *Writer) EnableTagging() {
 	}
 }
 
+// EnableStreaming makes the writer write objects to the output as soon as
+// they are finished, instead of keeping all of them in memory until the
+// document is complete. Only the cross-reference table is kept until the
+// end. Page content streams are written out in batches of the job count.
+// The output is the same as without streaming.
+func (w *Writer) EnableStreaming() {
+	w.streaming = true
+}
+
+// SetJobs sets the maximum number of page content streams that are encoded
+// concurrently. With zero or one, streams are encoded one after another.
+// The output is the same either way.
+func (w *Writer) SetJobs(jobs int) {
+	w.jobs = jobs
+}
+
+// DisableCompression makes the writer write streams uncompressed, which
+// makes the output readable for debugging. Images keep their encoding.
+func (w *Writer) DisableCompression() {
+	w.uncompressed = true
+}
+
+// EnableObjectStreams makes the writer pack objects other than streams
+// into compressed object streams and write a cross-reference stream
+// instead of a table, which makes the output smaller. Such files need a
+// reader for PDF 1.5 or later.
+func (w *Writer) EnableObjectStreams() {
+	w.objectStreams = true
+}
+
+// SetEncryption makes the writer encrypt the output with the given
+// passwords and permissions. With nil, the output is not encrypted.
+func (w *Writer) SetEncryption(encryption *Encryption) {
+	w.encryption = encryption
+}
+
 // TagManager returns the tag manager for this writer.
 func (w *Writer) TagManager() *TagManager {
 	return w.tagManager
@@ -81,6 +163,25 @@ func (w *Writer) addObjectWithRef(ref Ref, obj Object) {
 
 // Write generates a PDF from a PagedDocument and writes it to w.
 func (w *Writer) Write(doc *pages.PagedDocument, out io.Writer) error {
+	w.out = &countingWriter{w: bufio.NewWriter(out)}
+	w.offsets = make(map[int]int64)
+
+	// Header
+	w.out.WriteString("%PDF-1.7\n")
+	// Binary comment to indicate binary content
+	w.out.WriteString("%\x80\x80\x80\x80\n")
+
+	// Set up encryption first, so that all objects can be encrypted as
+	// they are written
+	if w.encryption != nil {
+		encryptor, err := newEncryptor(*w.encryption, rand.Reader)
+		if err != nil {
+			return err
+		}
+		w.encryptor = encryptor
+		w.encryptRef = w.addObject(encryptor.dict)
+	}
+
 	// Reserve object IDs for catalog and page tree
 	catalogRef := w.allocRef()
 	pagesRef := w.allocRef()
@@ -107,6 +208,22 @@ func (w *Writer) Write(doc *pages.PagedDocument, out io.Writer) error {
 		}
 		pageContentsRefs = append(pageContentsRefs, contentRef)
 		pageImageRefs = append(pageImageRefs, imageRefs)
+
+		// When streaming, write out a batch of pages once there are enough
+		// to keep all jobs busy.
+		if w.streaming && len(w.streams) >= max(w.jobs, 1) {
+			if err := w.encodeStreams(); err != nil {
+				return err
+			}
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Compress the content streams, possibly in parallel.
+	if err := w.encodeStreams(); err != nil {
+		return err
 	}
 
 	// Generate font resources from the font manager
@@ -120,10 +237,14 @@ func (w *Writer) Write(doc *pages.PagedDocument, out io.Writer) error {
 		w.fontRefs[fontRes.ResourceName] = fontRes.Ref
 	}
 
+	// Reserve the page refs up front so that links can point to later pages
+	for range doc.Pages {
+		w.pageRefs = append(w.pageRefs, w.allocRef())
+	}
+
 	// Create page objects
 	for i, page := range doc.Pages {
-		pageRef := w.allocRef()
-		w.pageRefs = append(w.pageRefs, pageRef)
+		pageRef := w.pageRefs[i]
 
 		// Register page ref with tag manager
 		if w.tagManager != nil {
@@ -174,6 +295,11 @@ func (w *Writer) Write(doc *pages.PagedDocument, out io.Writer) error {
 		// Add resources to page
 		pageDict[Name("Resources")] = resources
 
+		// Add link annotations
+		if annots := linkAnnotations(&page, doc.Pages, w.pageRefs); len(annots) > 0 {
+			pageDict[Name("Annots")] = annots
+		}
+
 		// Add StructParents if tagged
 		if w.tagged {
 			pageDict[Name("StructParents")] = Int(i)
@@ -213,24 +339,54 @@ func (w *Writer) Write(doc *pages.PagedDocument, out io.Writer) error {
 		}
 	}
 
+	// Declare the AES-256 encryption, which PDF 1.7 only has as an
+	// extension
+	if w.encryptor != nil {
+		catalogDict[Name("Extensions")] = Dict{
+			Name("ADBE"): Dict{
+				Name("BaseVersion"):    Name("1.7"),
+				Name("ExtensionLevel"): Int(8),
+			},
+		}
+	}
+
+	// Add page labels if pages are numbered
+	if labels := pageLabels(doc.Pages); labels != nil {
+		catalogDict[Name("PageLabels")] = labels
+	}
+
+	// Add XMP metadata if present
+	if xmp := xmpMetadata(doc.Info); xmp != nil {
+		catalogDict[Name("Metadata")] = w.addObject(Stream{
+			Dict: Dict{
+				Name("Type"):    Name("Metadata"),
+				Name("Subtype"): Name("XML"),
+			},
+			Data: xmp,
+		})
+	}
+
+	// Add embedded files
+	embeddedFiles, associated, err := w.writeAttachments(doc.Attachments)
+	if err != nil {
+		return err
+	}
+	if embeddedFiles != nil {
+		catalogDict[Name("Names")] = embeddedFiles
+		catalogDict[Name("AF")] = associated
+	}
+
 	w.addObjectWithRef(catalogRef, catalogDict)
 
 	// Add document info if present
 	var infoRef *Ref
-	if doc.Info.Title != nil || len(doc.Info.Author) > 0 {
-		info := make(Dict)
-		if doc.Info.Title != nil {
-			info[Name("Title")] = String(*doc.Info.Title)
-		}
-		if len(doc.Info.Author) > 0 {
-			info[Name("Author")] = String(doc.Info.Author[0])
-		}
+	if info := infoDict(doc.Info); info != nil {
 		ref := w.addObject(info)
 		infoRef = &ref
 	}
 
-	// Write PDF
-	return w.writePDF(out, catalogRef, infoRef)
+	// Write the remaining objects and the trailer
+	return w.finish(catalogRef, infoRef)
 }
 
 // buildStructTree builds the PDF structure tree for accessibility.
@@ -383,6 +539,7 @@ func (w *Writer) processPage(page *pages.Page, pagesRef Ref) (Ref, map[string]Re
 	// This converts from Typst's top-left origin to PDF's bottom-left origin
 	fmt.Fprintf(&content, "q\n")                              // Save initial state
 	fmt.Fprintf(&content, "1 0 0 -1 0 %g cm\n", pageHeight)   // Flip Y coordinate system
+	writeBackground(&content, page)
 
 	// Process frame items using transform-based positioning
 	err := w.processFrameWithTransforms(&page.Frame, &content, imageRefs, &imageCounter)
@@ -392,17 +549,58 @@ func (w *Writer) processPage(page *pages.Page, pagesRef Ref) (Ref, map[string]Re
 
 	fmt.Fprintf(&content, "Q\n") // Restore initial state
 
-	// Create content stream
-	stream := Stream{
-		Dict: make(Dict),
-		Data: content.Bytes(),
+	// Reserve the content stream's slot. The stream is compressed later
+	// together with those of the other pages.
+	contentRef := w.allocRef()
+	w.streams = append(w.streams, pendingStream{index: len(w.objects), data: content.Bytes()})
+	w.objects = append(w.objects, IndirectObject{Ref: contentRef})
+	return contentRef, imageRefs, nil
+}
+
+// encodeStreams compresses the pending content streams using up to jobs
+// goroutines and stores each in its reserved slot, so that the output does
+// not depend on the order in which the streams finish.
+func (w *Writer) encodeStreams() error {
+	streams := w.streams
+	w.streams = nil
+	errs := make([]error, len(streams))
+	encode := func(i int) {
+		stream := Stream{Dict: make(Dict), Data: streams[i].data}
+		if !w.uncompressed {
+			errs[i] = stream.Compress()
+		}
+		w.objects[streams[i].index].Object = stream
 	}
-	if err := stream.Compress(); err != nil {
-		return Ref{}, nil, err
+
+	if w.jobs <= 1 {
+		for i := range streams {
+			encode(i)
+		}
+	} else {
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for range min(w.jobs, len(streams)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					encode(i)
+				}
+			}()
+		}
+		for i := range streams {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
 	}
 
-	contentRef := w.addObject(stream)
-	return contentRef, imageRefs, nil
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // processFrameWithTransforms recursively processes frame items using PDF transforms.
@@ -415,6 +613,10 @@ func (w *Writer) processFrameWithTransforms(frame *pages.Frame, content *bytes.B
 
 		switch v := item.Item.(type) {
 		case pages.GroupItem:
+			// A described group, like a figure, is read as its description.
+			if v.Alt != "" {
+				beginFigure(content, v.Alt)
+			}
 			// Save state, translate to item position, recurse, restore
 			fmt.Fprintf(content, "q\n")                    // Save graphics state
 			fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", x, y) // Translate to position
@@ -422,6 +624,12 @@ func (w *Writer) processFrameWithTransforms(frame *pages.Frame, content *bytes.B
 				return err
 			}
 			fmt.Fprintf(content, "Q\n") // Restore graphics state
+			if v.Alt != "" {
+				endFigure(content)
+			}
+
+		case pages.ShapeItem:
+			writeShape(content, v, x, y)
 
 		case pages.InlineItem:
 			// Render inline text content
@@ -429,7 +637,7 @@ func (w *Writer) processFrameWithTransforms(frame *pages.Frame, content *bytes.B
 			fmt.Fprintf(content, "q\n")
 			fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", x, y)
 			if finalFrame, ok := v.Frame.(*inline.FinalFrame); ok {
-				w.renderInlineFrameLocal(content, finalFrame)
+				w.renderInlineFrameLocal(content, finalFrame, imageRefs, imageCounter)
 			}
 			fmt.Fprintf(content, "Q\n")
 
@@ -450,11 +658,17 @@ func (w *Writer) processFrameWithTransforms(frame *pages.Frame, content *bytes.B
 			width := float64(v.Size.Width)
 			height := float64(v.Size.Height)
 
+			if v.Alt != "" {
+				beginFigure(content, v.Alt)
+			}
 			fmt.Fprintf(content, "q\n")                                   // Save graphics state
 			fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", x, y)              // Translate to position
 			fmt.Fprintf(content, "%g 0 0 %g 0 0 cm\n", width, height)     // Scale to size
 			fmt.Fprintf(content, "/%s Do\n", imgName)                     // Draw image
 			fmt.Fprintf(content, "Q\n")                                   // Restore graphics state
+			if v.Alt != "" {
+				endFigure(content)
+			}
 
 		case pages.TagItem:
 			// Tags don't produce PDF content
@@ -494,7 +708,7 @@ func (w *Writer) processFrameWithTransforms(frame *pages.Frame, content *bytes.B
 // renderInlineFrameLocal renders an inline frame at the current transform position.
 // This handles all inline frame item types in the transformed coordinate system
 // where Y is already flipped at the page level.
-func (w *Writer) renderInlineFrameLocal(content *bytes.Buffer, frame *inline.FinalFrame) {
+func (w *Writer) renderInlineFrameLocal(content *bytes.Buffer, frame *inline.FinalFrame, imageRefs map[string]Ref, imageCounter *int) {
 	baseline := float64(frame.Baseline)
 
 	for _, entry := range frame.Items {
@@ -503,19 +717,21 @@ func (w *Writer) renderInlineFrameLocal(content *bytes.Buffer, frame *inline.Fin
 
 		switch item := entry.Item.(type) {
 		case inline.FinalTextItem:
-			w.renderShapedTextLocal(content, item.Text, x, y, baseline)
+			w.renderShapedTextLocal(content, item.Text, x, y, baseline, imageRefs, imageCounter)
 
 		case inline.FinalMathScriptItem:
-			w.renderMathScriptLocal(content, item, x, y, baseline)
+			w.renderMathScriptLocal(content, item, x, y, baseline, imageRefs, imageCounter)
 
 		case inline.FinalMathLimitsItem:
-			w.renderMathLimitsLocal(content, item, x, y, baseline)
+			w.renderMathLimitsLocal(content, item, x, y, baseline, imageRefs, imageCounter)
 		}
 	}
 }
 
 // renderShapedTextLocal renders shaped text in transformed coordinates.
-func (w *Writer) renderShapedTextLocal(content *bytes.Buffer, text *inline.ShapedText, x, y, baseline float64) {
+// Color glyphs, like emoji, are shown as invisible text, which keeps them
+// extractable, and drawn over it from their COLR layers or bitmaps.
+func (w *Writer) renderShapedTextLocal(content *bytes.Buffer, text *inline.ShapedText, x, y, baseline float64, imageRefs map[string]Ref, imageCounter *int) {
 	if text == nil || text.Glyphs.Len() == 0 {
 		return
 	}
@@ -530,36 +746,88 @@ func (w *Writer) renderShapedTextLocal(content *bytes.Buffer, text *inline.Shape
 	fontSize := float64(firstGlyph.Size)
 
 	// Register glyphs with font manager and get font name
-	widthUnits := int(float64(firstGlyph.XAdvance) * 1000)
+	widthUnits := int(float64(naturalAdvance(firstGlyph)) * 1000)
 	fontName := w.renderer.FontManager.RegisterGlyph(firstGlyph.Font, firstGlyph.GlyphID, firstGlyph.Char, widthUnits)
 
 	fmt.Fprintf(content, "BT\n")
 	fmt.Fprintf(content, "/%s %g Tf\n", fontName, fontSize)
-	// In transformed coords, y goes down. Text baseline is at y + baseline offset.
-	fmt.Fprintf(content, "%g %g Td\n", x, y+baseline)
-
-	// Build hex string with glyph IDs for CID font
-	// For Identity-H encoding, we output glyph IDs as 2-byte big-endian values
-	fmt.Fprintf(content, "<")
+	// Tracking becomes character spacing, which the viewer adds after
+	// every glyph.
+	if text.Tracking != 0 {
+		fmt.Fprintf(content, "%g Tc\n", float64(text.Tracking))
+	}
+	// The baseline shift becomes text rise, so that text extraction still
+	// sees the text on its line. Text space inherits the flipped y-axis,
+	// so a positive rise moves the text down, like a positive shift.
+	if text.Shift != 0 {
+		fmt.Fprintf(content, "%g Ts\n", float64(text.Shift))
+	}
+	// In transformed coords, y goes down. Text baseline is at y + baseline
+	// offset. The frame position includes the shift, which the rise
+	// already applies.
+	fmt.Fprintf(content, "%g %g Td\n", x, y-float64(text.Shift)+baseline)
+
+	// Build hex strings with glyph IDs for CID font. For Identity-H
+	// encoding, we output glyph IDs as 2-byte big-endian values. The font
+	// widths are the natural advances, and any deviation of the shaped
+	// advances beyond the tracking, such as word spacing, kerning, or
+	// CJK-Latin spacing, becomes a TJ adjustment. Word spacing cannot use
+	// Tw, since that only applies to the single-byte code 32, which never
+	// occurs with a two-byte encoding.
+	tracking := inline.EmFromAbs(text.Tracking, firstGlyph.Size)
+	hasColor := false
+	fmt.Fprintf(content, "[<")
 	for i := range glyphs {
 		g := &glyphs[i]
-		// Register each glyph
-		widthUnits := int(float64(g.XAdvance) * 1000)
-		w.renderer.FontManager.RegisterGlyph(g.Font, g.GlyphID, g.Char, widthUnits)
-		// Output glyph ID as 2-byte hex
-		fmt.Fprintf(content, "%04X", g.GlyphID)
+		natural := naturalAdvance(g)
+		w.renderer.FontManager.RegisterGlyph(g.Font, g.GlyphID, g.Char, int(float64(natural)*1000))
+		if colorGlyph(g.Font, g.GlyphID) != nil {
+			// Switch to invisible text for the color glyph.
+			fmt.Fprintf(content, ">] TJ 3 Tr [<%04X>] TJ 0 Tr [<", g.GlyphID)
+			hasColor = true
+		} else {
+			fmt.Fprintf(content, "%04X", g.GlyphID)
+		}
+		if i+1 == len(glyphs) {
+			break
+		}
+		// TJ adjustments are subtracted from the position, in thousandths
+		// of an em.
+		if adjust := float64(g.XAdvance-natural-tracking) * 1000; math.Abs(adjust) > 1e-3 {
+			fmt.Fprintf(content, "> %g <", -adjust)
+		}
+	}
+	fmt.Fprintf(content, ">] TJ\n")
+	if text.Tracking != 0 {
+		fmt.Fprintf(content, "0 Tc\n")
+	}
+	if text.Shift != 0 {
+		fmt.Fprintf(content, "0 Ts\n")
 	}
-	fmt.Fprintf(content, "> Tj\n")
 	fmt.Fprintf(content, "ET\n")
+
+	if hasColor {
+		w.writeColorGlyphs(content, text, x, y+baseline, imageRefs, imageCounter)
+	}
+}
+
+// naturalAdvance returns the advance of a glyph in its font, without
+// spacing or kerning applied during shaping. Glyphs without a font keep
+// their shaped advance.
+func naturalAdvance(g *inline.ShapedGlyph) inline.Em {
+	if g.Font == nil || g.Font.Upem() == 0 {
+		return g.XAdvance
+	}
+	return inline.Em(g.Font.HorizontalAdvance(font.GID(g.GlyphID)) / float32(g.Font.Upem()))
 }
 
 // renderMathScriptLocal renders math scripts (superscript/subscript) in transformed coordinates.
-func (w *Writer) renderMathScriptLocal(content *bytes.Buffer, item inline.FinalMathScriptItem, x, y, baseline float64) {
+func (w *Writer) renderMathScriptLocal(content *bytes.Buffer, item inline.FinalMathScriptItem, x, y, baseline float64, imageRefs map[string]Ref, imageCounter *int) {
 	// Render base content
 	if item.BaseFrame != nil {
 		fmt.Fprintf(content, "q\n")
 		fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", x, y)
-		w.renderInlineFrameLocal(content, item.BaseFrame)
+		w.renderInlineFrameLocal(content, item.BaseFrame, imageRefs, imageCounter)
 		fmt.Fprintf(content, "Q\n")
 	}
 
@@ -571,7 +839,7 @@ func (w *Writer) renderMathScriptLocal(content *bytes.Buffer, item inline.FinalM
 		superY := y + float64(item.SuperOffset)
 		fmt.Fprintf(content, "q\n")
 		fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", scriptX, superY)
-		w.renderInlineFrameLocal(content, item.SuperFrame)
+		w.renderInlineFrameLocal(content, item.SuperFrame, imageRefs, imageCounter)
 		fmt.Fprintf(content, "Q\n")
 	}
 
@@ -580,13 +848,13 @@ func (w *Writer) renderMathScriptLocal(content *bytes.Buffer, item inline.FinalM
 		subY := y + float64(item.SubOffset)
 		fmt.Fprintf(content, "q\n")
 		fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", scriptX, subY)
-		w.renderInlineFrameLocal(content, item.SubFrame)
+		w.renderInlineFrameLocal(content, item.SubFrame, imageRefs, imageCounter)
 		fmt.Fprintf(content, "Q\n")
 	}
 }
 
 // renderMathLimitsLocal renders math limits (operator with limits above/below) in transformed coordinates.
-func (w *Writer) renderMathLimitsLocal(content *bytes.Buffer, item inline.FinalMathLimitsItem, x, y, baseline float64) {
+func (w *Writer) renderMathLimitsLocal(content *bytes.Buffer, item inline.FinalMathLimitsItem, x, y, baseline float64, imageRefs map[string]Ref, imageCounter *int) {
 	centerX := x + float64(item.CenterX)
 
 	// Render upper limit (centered above nucleus)
@@ -595,7 +863,7 @@ func (w *Writer) renderMathLimitsLocal(content *bytes.Buffer, item inline.FinalM
 		upperY := y + float64(item.UpperOffset)
 		fmt.Fprintf(content, "q\n")
 		fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", upperX, upperY)
-		w.renderInlineFrameLocal(content, item.UpperFrame)
+		w.renderInlineFrameLocal(content, item.UpperFrame, imageRefs, imageCounter)
 		fmt.Fprintf(content, "Q\n")
 	}
 
@@ -604,7 +872,7 @@ func (w *Writer) renderMathLimitsLocal(content *bytes.Buffer, item inline.FinalM
 		nucleusX := centerX - float64(item.NucleusFrame.Size.Width)/2
 		fmt.Fprintf(content, "q\n")
 		fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", nucleusX, y)
-		w.renderInlineFrameLocal(content, item.NucleusFrame)
+		w.renderInlineFrameLocal(content, item.NucleusFrame, imageRefs, imageCounter)
 		fmt.Fprintf(content, "Q\n")
 	}
 
@@ -614,7 +882,7 @@ func (w *Writer) renderMathLimitsLocal(content *bytes.Buffer, item inline.FinalM
 		lowerY := y + float64(item.LowerOffset)
 		fmt.Fprintf(content, "q\n")
 		fmt.Fprintf(content, "1 0 0 1 %g %g cm\n", lowerX, lowerY)
-		w.renderInlineFrameLocal(content, item.LowerFrame)
+		w.renderInlineFrameLocal(content, item.LowerFrame, imageRefs, imageCounter)
 		fmt.Fprintf(content, "Q\n")
 	}
 }
@@ -668,60 +936,138 @@ func (w *Writer) getOrCreateImageXObject(img *pages.Image) (Ref, error) {
 	return imgRef, nil
 }
 
-// writePDF writes the complete PDF document.
-func (w *Writer) writePDF(out io.Writer, catalogRef Ref, infoRef *Ref) error {
-	var buf bytes.Buffer
+// flush writes the objects that have not been written yet and records
+// their offsets. Their memory can be reclaimed afterwards. With object
+// streams, objects that can be packed are held back until there are
+// enough for an object stream.
+func (w *Writer) flush() error {
+	for _, obj := range w.objects {
+		if w.objectStreams && w.packable(obj) {
+			w.packed = append(w.packed, obj)
+			if len(w.packed) == objectStreamCapacity {
+				if err := w.writeObjectStream(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := w.writeObject(obj); err != nil {
+			return err
+		}
+	}
+	w.objects = nil
+	return nil
+}
 
-	// Header
-	buf.WriteString("%PDF-1.7\n")
-	// Binary comment to indicate binary content
-	buf.WriteString("%\x80\x80\x80\x80\n")
+// writeObject compresses and encrypts an object as configured, writes it,
+// and records its offset.
+func (w *Writer) writeObject(obj IndirectObject) error {
+	if stream, ok := obj.Object.(Stream); ok && w.compressible(stream) {
+		if err := stream.Compress(); err != nil {
+			return err
+		}
+		obj.Object = stream
+	}
+	if w.encryptor != nil && obj.Ref != w.encryptRef {
+		encrypted, err := w.encryptor.encryptObject(obj.Object)
+		if err != nil {
+			return err
+		}
+		obj.Object = encrypted
+	}
+	w.offsets[obj.Ref.ID] = w.out.n
+	if err := obj.writeTo(w.out); err != nil {
+		return err
+	}
+	_, err := w.out.WriteString("\n")
+	return err
+}
 
-	// Track object byte offsets for xref table
-	offsets := make(map[int]int64)
+// compressible reports whether a stream is compressed when it is written.
+// XMP metadata stays uncompressed so that tools can find it without
+// parsing the PDF.
+func (w *Writer) compressible(stream Stream) bool {
+	if w.uncompressed {
+		return false
+	}
+	if _, ok := stream.Dict[Name("Filter")]; ok {
+		return false
+	}
+	return stream.Dict[Name("Type")] != Name("Metadata")
+}
 
-	// Write all objects
-	for _, obj := range w.objects {
-		offsets[obj.Ref.ID] = int64(buf.Len())
-		if err := obj.writeTo(&buf); err != nil {
+// finish writes the remaining objects, the cross-reference table or
+// stream, and the trailer, and flushes the output.
+func (w *Writer) finish(catalogRef Ref, infoRef *Ref) error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	trailer := Dict{
+		Name("Root"): catalogRef,
+	}
+	if infoRef != nil {
+		trailer[Name("Info")] = *infoRef
+	}
+	if w.encryptor != nil {
+		trailer[Name("Encrypt")] = w.encryptRef
+		trailer[Name("ID")] = Array{w.encryptor.id, w.encryptor.id}
+	}
+
+	if w.objectStreams {
+		if err := w.writeObjectStream(); err != nil {
 			return err
 		}
-		buf.WriteByte('\n')
+		if err := w.writeXrefStream(trailer); err != nil {
+			return err
+		}
+		return w.out.w.Flush()
 	}
 
 	// Write xref table
-	xrefOffset := buf.Len()
-	fmt.Fprintf(&buf, "xref\n")
-	fmt.Fprintf(&buf, "0 %d\n", w.nextID)
-	fmt.Fprintf(&buf, "0000000000 65535 f \n") // Free entry
+	xrefOffset := w.out.n
+	fmt.Fprintf(w.out, "xref\n")
+	fmt.Fprintf(w.out, "0 %d\n", w.nextID)
+	fmt.Fprintf(w.out, "0000000000 65535 f \n") // Free entry
 
 	for i := 1; i < w.nextID; i++ {
-		if offset, ok := offsets[i]; ok {
-			fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+		if offset, ok := w.offsets[i]; ok {
+			fmt.Fprintf(w.out, "%010d 00000 n \n", offset)
 		} else {
 			// Object ID was allocated but not used
-			fmt.Fprintf(&buf, "0000000000 65535 f \n")
+			fmt.Fprintf(w.out, "0000000000 65535 f \n")
 		}
 	}
 
 	// Write trailer
-	trailer := Dict{
-		Name("Size"): Int(w.nextID),
-		Name("Root"): catalogRef,
-	}
-	if infoRef != nil {
-		trailer[Name("Info")] = *infoRef
-	}
-
-	buf.WriteString("trailer\n")
-	if err := trailer.writeTo(&buf); err != nil {
+	trailer[Name("Size")] = Int(w.nextID)
+	w.out.WriteString("trailer\n")
+	if err := trailer.writeTo(w.out); err != nil {
 		return err
 	}
-	fmt.Fprintf(&buf, "\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+	fmt.Fprintf(w.out, "\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
 
 	// Write to output
-	_, err := out.Write(buf.Bytes())
-	return err
+	return w.out.w.Flush()
+}
+
+// countingWriter buffers writes to the output and counts the bytes written,
+// which are the offsets recorded in the cross-reference table.
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingWriter) WriteString(s string) (int, error) {
+	n, err := c.w.WriteString(s)
+	c.n += int64(n)
+	return n, err
 }
 
 // Export is a convenience function that exports a PagedDocument to PDF.
@@ -730,8 +1076,54 @@ func Export(doc *pages.PagedDocument, out io.Writer) error {
 	return w.Write(doc, out)
 }
 
+// ExportStreaming exports a PagedDocument to PDF, writing objects to out as
+// soon as they are finished.
+func ExportStreaming(doc *pages.PagedDocument, out io.Writer) error {
+	w := NewWriter()
+	w.EnableStreaming()
+	return w.Write(doc, out)
+}
+
 // ExportTagged exports a PagedDocument to PDF with accessibility tagging enabled.
 func ExportTagged(doc *pages.PagedDocument, out io.Writer) error {
 	w := NewTaggedWriter()
 	return w.Write(doc, out)
 }
+
+// ExportOptions configures the export of a document to PDF.
+type ExportOptions struct {
+	// Tagged enables PDF/UA accessibility tagging.
+	Tagged bool
+	// Streaming writes objects to the output as soon as they are finished.
+	Streaming bool
+	// Jobs is the maximum number of page content streams encoded
+	// concurrently.
+	Jobs int
+	// Encryption encrypts the output if it is set.
+	Encryption *Encryption
+	// Uncompressed writes streams uncompressed, for debugging.
+	Uncompressed bool
+	// ObjectStreams packs objects into object streams and writes a
+	// cross-reference stream.
+	ObjectStreams bool
+}
+
+// ExportWithOptions exports a PagedDocument to PDF as configured by opts.
+func ExportWithOptions(doc *pages.PagedDocument, out io.Writer, opts ExportOptions) error {
+	w := NewWriter()
+	if opts.Tagged {
+		w.EnableTagging()
+	}
+	if opts.Streaming {
+		w.EnableStreaming()
+	}
+	w.SetJobs(opts.Jobs)
+	w.SetEncryption(opts.Encryption)
+	if opts.Uncompressed {
+		w.DisableCompression()
+	}
+	if opts.ObjectStreams {
+		w.EnableObjectStreams()
+	}
+	return w.Write(doc, out)
+}
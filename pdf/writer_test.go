@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/boergens/gotypst/layout/inline"
+	"github.com/boergens/gotypst/layout/pages"
+)
+
+// testDocument creates a document with the given number of text pages.
+func testDocument(n int) *pages.PagedDocument {
+	doc := &pages.PagedDocument{}
+	for i := range n {
+		frame := pages.Hard(layout.Size{Width: 595, Height: 842})
+		frame.Push(layout.Point{X: 72, Y: 72}, pages.TextItem{Text: fmt.Sprintf("Page %d", i+1), FontSize: 11})
+		doc.Pages = append(doc.Pages, pages.Page{Frame: frame})
+	}
+	return doc
+}
+
+func TestWriteParallelIsDeterministic(t *testing.T) {
+	doc := testDocument(12)
+
+	var sequential bytes.Buffer
+	if err := NewWriter().Write(doc, &sequential); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		w := NewWriter()
+		w.SetJobs(4)
+		var parallel bytes.Buffer
+		if err := w.Write(doc, &parallel); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sequential.Bytes(), parallel.Bytes()) {
+			t.Fatal("parallel output differs from sequential output")
+		}
+	}
+}
+
+func TestWriteStreaming(t *testing.T) {
+	doc := testDocument(5)
+
+	var buffered bytes.Buffer
+	if err := NewWriter().Write(doc, &buffered); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, jobs := range []int{1, 2} {
+		w := NewWriter()
+		w.EnableStreaming()
+		w.SetJobs(jobs)
+		var streamed bytes.Buffer
+		if err := w.Write(doc, &streamed); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buffered.Bytes(), streamed.Bytes()) {
+			t.Errorf("streamed output with %d jobs differs from buffered output", jobs)
+		}
+		if len(w.objects) != 0 {
+			t.Errorf("%d objects still held after writing", len(w.objects))
+		}
+	}
+}
+
+func TestRenderShapedTextSpacing(t *testing.T) {
+	text := &inline.ShapedText{
+		Glyphs: inline.NewGlyphsFromSlice([]inline.ShapedGlyph{
+			{GlyphID: 1, Char: 'a', Size: 10, XAdvance: 0.5, Range: inline.Range{Start: 0, End: 1}},
+			{GlyphID: 2, Char: 'b', Size: 10, XAdvance: 0.5, Range: inline.Range{Start: 1, End: 2}},
+		}),
+		Tracking: 1,
+		Shift:    2,
+	}
+	var content bytes.Buffer
+	NewWriter().renderShapedTextLocal(&content, text, 0, 2, 8, map[string]Ref{}, new(int))
+	out := content.String()
+
+	for _, want := range []string{"1 Tc\n", "2 Ts\n", "0 8 Td\n", "TJ\n", "0 Tc\n", "0 Ts\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
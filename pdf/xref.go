@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// objectStreamCapacity is the maximum number of objects in one object
+// stream. Streaming writers write out an object stream whenever this many
+// objects are waiting.
+const objectStreamCapacity = 100
+
+// packedLocation is where an object in an object stream is.
+type packedLocation struct {
+	// stream is the ID of the object stream.
+	stream int
+	// index is the position of the object within the stream.
+	index int
+}
+
+// packable reports whether an object can be packed into an object stream.
+// Streams, objects with a generation other than zero, and the encryption
+// dictionary must stay outside.
+// Matches ISO 32000-2, 7.5.7
+func (w *Writer) packable(obj IndirectObject) bool {
+	if _, ok := obj.Object.(Stream); ok {
+		return false
+	}
+	if w.encryptor != nil && obj.Ref == w.encryptRef {
+		return false
+	}
+	return obj.Ref.Gen == 0
+}
+
+// writeObjectStream packs the waiting objects into an object stream and
+// writes it. The stream starts with pairs of object IDs and offsets,
+// followed by the objects themselves.
+func (w *Writer) writeObjectStream() error {
+	if len(w.packed) == 0 {
+		return nil
+	}
+	if w.packedIn == nil {
+		w.packedIn = make(map[int]packedLocation)
+	}
+
+	ref := w.allocRef()
+	var header, body bytes.Buffer
+	for i, obj := range w.packed {
+		fmt.Fprintf(&header, "%d %d ", obj.Ref.ID, body.Len())
+		if err := obj.Object.writeTo(&body); err != nil {
+			return err
+		}
+		body.WriteByte('\n')
+		w.packedIn[obj.Ref.ID] = packedLocation{stream: ref.ID, index: i}
+	}
+
+	stream := Stream{
+		Dict: Dict{
+			Name("Type"):  Name("ObjStm"),
+			Name("N"):     Int(len(w.packed)),
+			Name("First"): Int(header.Len()),
+		},
+		Data: append(header.Bytes(), body.Bytes()...),
+	}
+	w.packed = nil
+	return w.writeObject(IndirectObject{Ref: ref, Object: stream})
+}
+
+// writeXrefStream writes the cross-reference stream, which also takes the
+// place of the trailer, and the end of the file. Each entry is a type of 0
+// for free, 1 for written, or 2 for packed objects, followed by the offset
+// or the object stream's ID, and the index in the object stream.
+// Matches ISO 32000-2, 7.5.8
+func (w *Writer) writeXrefStream(trailer Dict) error {
+	ref := w.allocRef()
+	offset := w.out.n
+	w.offsets[ref.ID] = offset
+
+	// The middle field must fit the largest offset or object ID.
+	largest := max(offset, int64(w.nextID))
+	width := 1
+	for largest >= 1<<(8*width) {
+		width++
+	}
+
+	var data bytes.Buffer
+	entry := func(kind byte, field int64, index int) {
+		data.WriteByte(kind)
+		for i := width - 1; i >= 0; i-- {
+			data.WriteByte(byte(field >> (8 * i)))
+		}
+		data.WriteByte(byte(index >> 8))
+		data.WriteByte(byte(index))
+	}
+	entry(0, 0, 0xFFFF)
+	for id := 1; id < w.nextID; id++ {
+		if written, ok := w.offsets[id]; ok {
+			entry(1, written, 0)
+		} else if packed, ok := w.packedIn[id]; ok {
+			entry(2, int64(packed.stream), packed.index)
+		} else {
+			// Object ID was allocated but not used
+			entry(0, 0, 0xFFFF)
+		}
+	}
+
+	dict := Dict{
+		Name("Type"): Name("XRef"),
+		Name("Size"): Int(w.nextID),
+		Name("W"):    Array{Int(1), Int(width), Int(2)},
+	}
+	for key, value := range trailer {
+		dict[key] = value
+	}
+	stream := Stream{Dict: dict, Data: data.Bytes()}
+	if !w.uncompressed {
+		if err := stream.Compress(); err != nil {
+			return err
+		}
+	}
+
+	// The cross-reference stream is never encrypted.
+	if err := (IndirectObject{Ref: ref, Object: stream}).writeTo(w.out); err != nil {
+		return err
+	}
+	_, err := w.out.WriteString("\nstartxref\n" + strconv.FormatInt(offset, 10) + "\n%%EOF\n")
+	return err
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// inflate decompresses FlateDecode data.
+func inflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// streamData returns the raw data of the stream object that starts at
+// offset in the PDF.
+func streamData(t *testing.T, pdf []byte, offset int) []byte {
+	t.Helper()
+	start := bytes.Index(pdf[offset:], []byte("\nstream\n"))
+	end := bytes.Index(pdf[offset:], []byte("\nendstream"))
+	if start < 0 || end < start {
+		t.Fatalf("no stream at offset %d", offset)
+	}
+	return pdf[offset+start+len("\nstream\n") : offset+end]
+}
+
+func TestWriteObjectStreams(t *testing.T) {
+	var out bytes.Buffer
+	if err := ExportWithOptions(testDocument(3), &out, ExportOptions{ObjectStreams: true}); err != nil {
+		t.Fatal(err)
+	}
+	pdf := out.Bytes()
+	if bytes.Contains(pdf, []byte("\ntrailer\n")) || bytes.Contains(pdf, []byte("\nxref\n")) {
+		t.Error("output with object streams contains a cross-reference table")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xrefOffset, _ := strconv.Atoi(string(m[1]))
+	if !bytes.Contains(pdf[xrefOffset:], []byte("/Type /XRef")) {
+		t.Fatal("startxref does not point to a cross-reference stream")
+	}
+
+	w := regexp.MustCompile(`/W \[1 (\d) 2\]`).FindSubmatch(pdf[xrefOffset:])
+	if w == nil {
+		t.Fatal("cross-reference stream lacks the field widths")
+	}
+	width, _ := strconv.Atoi(string(w[1]))
+	entries := inflate(t, streamData(t, pdf, xrefOffset))
+	entry := func(id int) (kind byte, field, index int) {
+		e := entries[id*(width+3) : (id+1)*(width+3)]
+		for _, b := range e[1 : 1+width] {
+			field = field<<8 | int(b)
+		}
+		return e[0], field, int(e[1+width])<<8 | int(e[2+width])
+	}
+
+	packed := 0
+	for id := 1; id < len(entries)/(width+3); id++ {
+		kind, field, index := entry(id)
+		switch kind {
+		case 1:
+			if !bytes.HasPrefix(pdf[field:], []byte(strconv.Itoa(id)+" 0 obj")) {
+				t.Errorf("entry of object %d does not point to it", id)
+			}
+		case 2:
+			packed++
+			_, offset, _ := entry(field)
+			header := bytes.Fields(inflate(t, streamData(t, pdf, offset)))
+			if got, _ := strconv.Atoi(string(header[2*index])); got != id {
+				t.Errorf("object stream %d holds object %d at index %d, want %d", field, got, index, id)
+			}
+		}
+	}
+	if packed == 0 {
+		t.Error("no objects were packed into object streams")
+	}
+}
+
+func TestWriteUncompressed(t *testing.T) {
+	var out bytes.Buffer
+	if err := ExportWithOptions(testDocument(1), &out, ExportOptions{Uncompressed: true}); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("/FlateDecode")) {
+		t.Error("uncompressed output contains compressed streams")
+	}
+	if !bytes.Contains(out.Bytes(), []byte("(Page 1) Tj")) {
+		t.Error("uncompressed output lacks the readable content stream")
+	}
+}
+
+func TestStreamCompressSharedDict(t *testing.T) {
+	dict := Dict{Name("Type"): Name("XObject")}
+	first := Stream{Dict: dict, Data: []byte("first")}
+	second := Stream{Dict: dict, Data: []byte("second")}
+	if err := first.Compress(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dict[Name("Filter")]; ok {
+		t.Error("Compress added the filter to the caller's dictionary")
+	}
+	if err := second.Compress(); err != nil {
+		t.Fatal(err)
+	}
+	if first.Dict[Name("Filter")] != Name("FlateDecode") || second.Dict[Name("Filter")] != Name("FlateDecode") {
+		t.Errorf("dictionaries after compression = %v, %v", first.Dict, second.Dict)
+	}
+	if got := inflate(t, second.Data); string(got) != "second" {
+		t.Errorf("second stream inflates to %q", got)
+	}
+}
@@ -44,13 +44,19 @@
 //
 //   - Inline content → Paragraphs
 //   - List items → Lists
-//   - Citations → Bibliography handling
+//   - Adjacent citations → Citation groups, shown as one cluster like
+//     "[1]–[3]" with the separators of the citation style
 //
 // # Space Collapsing
 //
 // The algorithm handles typesetting space rules:
 //
+//   - Spaces never start or end a paragraph; outside of paragraphs they
+//     are dropped
 //   - Removes spaces at content boundaries
 //   - Collapses adjacent spaces
-//   - Removes spaces adjacent to destructive elements (breaks, blocks)
+//   - Removes spaces adjacent to destructive elements (line breaks, weak
+//     or fractional spacing, blocks, display equations)
+//   - Looks through tags, so that a space before or after one collapses
+//     as if the tag were not there
 package realize
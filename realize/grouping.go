@@ -2,6 +2,8 @@ package realize
 
 import (
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
 )
 
 // ----------------------------------------------------------------------------
@@ -51,7 +53,7 @@ var textualRule = &GroupingRule{
 	Priority: priorityTextual,
 	Tags:     true, // TEXTUAL handles tags
 	Trigger: func(elem eval.ContentElement, s *state) bool {
-		return isPhrasing(elem) && !isGroupable(elem)
+		return isPhrasing(elem) && !isGroupable(elem) && !isSpace(elem)
 	},
 	Inner: func(elem eval.ContentElement) bool {
 		return isPhrasing(elem)
@@ -62,42 +64,51 @@ var textualRule = &GroupingRule{
 	// Finish is set in init() to avoid initialization cycle
 }
 
-// parRule groups content into paragraphs.
+// parRule groups consecutive inline-level content into implicit
+// paragraphs. Anything else, like a paragraph break or a block, ends the
+// paragraph. Tags within the paragraph stay in it so that the elements
+// they mark can be located within its lines; tags at its edges are not
+// triggers and are therefore trimmed off.
 // Matches Rust: static PAR rule
 var parRule = &GroupingRule{
 	Priority: priorityPar,
-	Tags:     false, // PAR does not handle tags
+	Tags:     true,
 	Trigger: func(elem eval.ContentElement, s *state) bool {
-		return isPhrasing(elem)
+		return isPhrasing(elem) && !isSpace(elem) && !isTag(elem)
 	},
 	Inner: func(elem eval.ContentElement) bool {
 		return isPhrasing(elem)
 	},
+	// Text set rules don't interrupt a paragraph: its elements keep their
+	// own styles. Paragraph and alignment set rules apply to whole
+	// paragraphs, so a paragraph can't continue across them.
 	Interrupt: func(elem eval.Element) bool {
-		return elem.Name == "par" || elem.Name == "text"
+		return elem.Name == "par" || elem.Name == "align"
 	},
 	// Finish is set in init() to avoid initialization cycle
 }
 
-// citesRule groups consecutive citations.
+// citesRule groups consecutive citations into a single cluster.
+// Spaces between the citations don't end the group.
 // Matches Rust: static CITES rule
 var citesRule = &GroupingRule{
 	Priority: priorityCites,
 	Tags:     false,
 	Trigger: func(elem eval.ContentElement, s *state) bool {
-		_, ok := elem.(*eval.CiteElement)
+		_, ok := elem.(*model.CiteElem)
 		return ok
 	},
 	Inner: func(elem eval.ContentElement) bool {
-		return false
+		return isSpace(elem)
 	},
 	Interrupt: func(elem eval.Element) bool {
-		return elem.Name == "cite"
+		return elem.Name == "cite" || elem.Name == "smartquote"
 	},
 	// Finish is set in init() to avoid initialization cycle
 }
 
 // listRule groups consecutive list items.
+// Spaces and paragraph breaks between the items don't end the group.
 // Matches Rust: static LIST rule
 var listRule = &GroupingRule{
 	Priority: priorityList,
@@ -106,9 +117,7 @@ var listRule = &GroupingRule{
 		_, ok := elem.(*eval.ListItemElement)
 		return ok
 	},
-	Inner: func(elem eval.ContentElement) bool {
-		return false
-	},
+	Inner: isListLikeInner,
 	Interrupt: func(elem eval.Element) bool {
 		return elem.Name == "list"
 	},
@@ -116,6 +125,7 @@ var listRule = &GroupingRule{
 }
 
 // enumRule groups consecutive enum items.
+// Spaces and paragraph breaks between the items don't end the group.
 // Matches Rust: static ENUM rule
 var enumRule = &GroupingRule{
 	Priority: priorityEnum,
@@ -124,9 +134,7 @@ var enumRule = &GroupingRule{
 		_, ok := elem.(*eval.EnumItemElement)
 		return ok
 	},
-	Inner: func(elem eval.ContentElement) bool {
-		return false
-	},
+	Inner: isListLikeInner,
 	Interrupt: func(elem eval.Element) bool {
 		return elem.Name == "enum"
 	},
@@ -134,6 +142,7 @@ var enumRule = &GroupingRule{
 }
 
 // termsRule groups consecutive term items.
+// Spaces and paragraph breaks between the items don't end the group.
 // Matches Rust: static TERMS rule
 var termsRule = &GroupingRule{
 	Priority: priorityTerms,
@@ -142,9 +151,7 @@ var termsRule = &GroupingRule{
 		_, ok := elem.(*eval.TermItemElement)
 		return ok
 	},
-	Inner: func(elem eval.ContentElement) bool {
-		return false
-	},
+	Inner: isListLikeInner,
 	Interrupt: func(elem eval.Element) bool {
 		return elem.Name == "terms"
 	},
@@ -162,33 +169,23 @@ func init() {
 
 	// PAR finish: create paragraph from grouped inline content
 	parRule.Finish = func(g *grouped) error {
+		// Collapse spaces within the paragraph.
+		collapseSpaces(&g.s.sink, g.start)
+
 		pairs := g.get()
 		if len(pairs) == 0 {
 			return nil
 		}
 
-		// Collapse spaces within the paragraph.
-		collapseSpaces(pairs, g.start)
-
-		// Take the styles from the first element.
-		var styles *eval.StyleChain
-		if len(pairs) > 0 {
-			styles = pairs[0].Styles
-		}
-
-		// Create paragraph element containing the grouped content.
-		var children []eval.ContentElement
-		for _, p := range pairs {
-			children = append(children, p.Content)
-		}
-
-		par := &eval.ParagraphElement{
-			Body: eval.Content{Elements: children},
-		}
+		// The paragraph gets the styles shared by all of its elements,
+		// which is where its paragraph properties come from. The elements
+		// keep the rest of their styles.
+		body, trunk := repack(pairs)
+		par := &eval.ParagraphElement{Body: body}
 
 		// End the group (remove from sink) and visit the paragraph.
 		st := g.end()
-		return visit(st, par, styles)
+		return visit(st, par, trunk)
 	}
 
 	// CITES finish: create citation group
@@ -198,10 +195,10 @@ func init() {
 			return nil
 		}
 
-		// Collect citations.
-		var cites []*eval.CiteElement
+		// Collect citations, dropping the spaces between them.
+		var cites []*model.CiteElem
 		for _, p := range pairs {
-			if cite, ok := p.Content.(*eval.CiteElement); ok {
+			if cite, ok := p.Content.(*model.CiteElem); ok {
 				cites = append(cites, cite)
 			}
 		}
@@ -214,7 +211,7 @@ func init() {
 		styles := pairs[0].Styles
 
 		// Create citation group.
-		group := &eval.CitationGroup{Citations: cites}
+		group := &model.CiteGroup{Children: cites, Span: cites[0].Span}
 
 		// End the group and visit the citation group.
 		st := g.end()
@@ -223,28 +220,15 @@ func init() {
 
 	// LIST finish: create list from items
 	listRule.Finish = func(g *grouped) error {
-		pairs := g.get()
-		if len(pairs) == 0 {
-			return nil
-		}
-
-		// Collect list items.
-		var items []*eval.ListItemElement
-		for _, p := range pairs {
-			if item, ok := p.Content.(*eval.ListItemElement); ok {
-				items = append(items, item)
-			}
-		}
-
+		items, tight, trunk := listLikeItems(g.get(), func(item *eval.ListItemElement, local *eval.Styles) *eval.ListItemElement {
+			styled := *item
+			styled.Content = styledContent(item.Content, local)
+			return &styled
+		})
 		if len(items) == 0 {
 			return nil
 		}
 
-		// Take styles from first item.
-		styles := pairs[0].Styles
-
-		// Create list element.
-		tight := true
 		list := &eval.ListElement{
 			Items: items,
 			Tight: &tight,
@@ -252,24 +236,16 @@ func init() {
 
 		// End the group and visit the list.
 		st := g.end()
-		return visit(st, list, styles)
+		return visit(st, list, trunk)
 	}
 
 	// ENUM finish: create enum from items
 	enumRule.Finish = func(g *grouped) error {
-		pairs := g.get()
-		if len(pairs) == 0 {
-			return nil
-		}
-
-		// Collect enum items.
-		var items []*eval.EnumItemElement
-		for _, p := range pairs {
-			if item, ok := p.Content.(*eval.EnumItemElement); ok {
-				items = append(items, item)
-			}
-		}
-
+		items, tight, trunk := listLikeItems(g.get(), func(item *eval.EnumItemElement, local *eval.Styles) *eval.EnumItemElement {
+			styled := *item
+			styled.Content = styledContent(item.Content, local)
+			return &styled
+		})
 		if len(items) == 0 {
 			return nil
 		}
@@ -281,11 +257,6 @@ func init() {
 			}
 		}
 
-		// Take styles from first item.
-		styles := pairs[0].Styles
-
-		// Create enum element.
-		tight := true
 		enum := &eval.EnumElement{
 			Items: items,
 			Tight: &tight,
@@ -293,37 +264,29 @@ func init() {
 
 		// End the group and visit the enum.
 		st := g.end()
-		return visit(st, enum, styles)
+		return visit(st, enum, trunk)
 	}
 
 	// TERMS finish: create terms from items
 	termsRule.Finish = func(g *grouped) error {
-		pairs := g.get()
-		if len(pairs) == 0 {
-			return nil
-		}
-
-		// Collect term items.
-		var items []*eval.TermItemElement
-		for _, p := range pairs {
-			if item, ok := p.Content.(*eval.TermItemElement); ok {
-				items = append(items, item)
-			}
-		}
-
+		items, tight, trunk := listLikeItems(g.get(), func(item *eval.TermItemElement, local *eval.Styles) *eval.TermItemElement {
+			styled := *item
+			styled.Term = styledContent(item.Term, local)
+			styled.Description = styledContent(item.Description, local)
+			return &styled
+		})
 		if len(items) == 0 {
 			return nil
 		}
 
-		// Take styles from first item.
-		styles := pairs[0].Styles
-
-		// Create terms element.
-		terms := &eval.TermsElement{Items: items}
+		terms := &eval.TermsElement{
+			Items: items,
+			Tight: &tight,
+		}
 
 		// End the group and visit the terms.
 		st := g.end()
-		return visit(st, terms, styles)
+		return visit(st, terms, trunk)
 	}
 }
 
@@ -384,7 +347,7 @@ var mathRules = []*GroupingRule{
 // isPhrasing returns true if an element is inline/phrasing content.
 // Matches Rust: is_phrasing() in typst-realize/src/lib.rs
 func isPhrasing(elem eval.ContentElement) bool {
-	switch elem.(type) {
+	switch e := elem.(type) {
 	// Text and basic inline elements
 	case *eval.TextElement, *eval.SpaceElement, *eval.SmartQuoteElement:
 		return true
@@ -393,8 +356,8 @@ func isPhrasing(elem eval.ContentElement) bool {
 	case *eval.StrongElement, *eval.EmphElement, *eval.RawElement:
 		return true
 
-	// Links and references
-	case *eval.LinkElement, *eval.RefElement:
+	// Links, references, and citations
+	case *eval.LinkElement, *eval.RefElement, *model.CiteElem, *model.CiteGroup:
 		return true
 
 	// Spacing
@@ -407,7 +370,7 @@ func isPhrasing(elem eval.ContentElement) bool {
 
 	// Math (equations are inline unless display mode)
 	case *eval.EquationElement:
-		return true
+		return !e.Block
 
 	// Line breaks are inline but break lines
 	case *eval.LinebreakElement:
@@ -430,6 +393,103 @@ func isPhrasing(elem eval.ContentElement) bool {
 	}
 }
 
+// isSpace returns true for space elements. Spaces can be inside of a
+// paragraph but never start or end one: leading ones are not grouped, and
+// trailing ones are trimmed off the group and then filtered out.
+// Matches Rust: inner of the PAR and TEXTUAL rules
+func isSpace(elem eval.ContentElement) bool {
+	_, ok := elem.(*eval.SpaceElement)
+	return ok
+}
+
+// isTag returns true for introspection tags.
+func isTag(elem eval.ContentElement) bool {
+	_, ok := elem.(*eval.TagElem)
+	return ok
+}
+
+// repack turns grouped pairs back into content. It returns the common
+// trunk of the pairs' style chains, and content in which each run of
+// elements with the same styles is wrapped in the styles it has on top of
+// the trunk.
+// Matches Rust: fn repack()
+func repack(pairs []Pair) (eval.Content, *eval.StyleChain) {
+	chains := make([]*eval.StyleChain, len(pairs))
+	for i, p := range pairs {
+		chains[i] = p.Styles
+	}
+	trunk := foundations.Trunk(chains...)
+
+	var body eval.Content
+	for i := 0; i < len(pairs); {
+		j := i + 1
+		for j < len(pairs) && pairs[j].Styles == pairs[i].Styles {
+			j++
+		}
+		run := make([]eval.ContentElement, 0, j-i)
+		for _, p := range pairs[i:j] {
+			run = append(run, p.Content)
+		}
+		if local := pairs[i].Styles.Suffix(trunk); !local.IsEmpty() {
+			body.Elements = append(body.Elements, &eval.StyledElement{
+				Child:  eval.Content{Elements: run},
+				Styles: local,
+			})
+		} else {
+			body.Elements = append(body.Elements, run...)
+		}
+		i = j
+	}
+	return body, trunk
+}
+
+// listLikeItems collects the items of a finished list, enum, or terms
+// grouping. The list is tight unless a paragraph break separates two of
+// its items. It is visited with the trunk of the items' styles, and each
+// item keeps the styles it has on top of that, applied through restyle.
+// Matches Rust: fn finish_list_like()
+func listLikeItems[T eval.ContentElement](pairs []Pair, restyle func(item T, local *eval.Styles) T) (items []T, tight bool, trunk *eval.StyleChain) {
+	tight = true
+	var chains []*eval.StyleChain
+	for _, p := range pairs {
+		if _, ok := p.Content.(*eval.ParbreakElement); ok {
+			tight = false
+		}
+		if _, ok := p.Content.(T); ok {
+			chains = append(chains, p.Styles)
+		}
+	}
+	trunk = foundations.Trunk(chains...)
+
+	for _, p := range pairs {
+		item, ok := p.Content.(T)
+		if !ok {
+			continue
+		}
+		if local := p.Styles.Suffix(trunk); !local.IsEmpty() {
+			item = restyle(item, local)
+		}
+		items = append(items, item)
+	}
+	return items, tight, trunk
+}
+
+// styledContent wraps content in styles.
+func styledContent(content eval.Content, styles *eval.Styles) eval.Content {
+	return eval.Content{Elements: []eval.ContentElement{&eval.StyledElement{Child: content, Styles: styles}}}
+}
+
+// isListLikeInner returns true for the elements that may be between the
+// items of a list, enum, or terms grouping without ending it.
+// Matches Rust: inner of list_like_grouping()
+func isListLikeInner(elem eval.ContentElement) bool {
+	switch elem.(type) {
+	case *eval.SpaceElement, *eval.ParbreakElement:
+		return true
+	}
+	return false
+}
+
 // isGroupable returns true if an element participates in grouping.
 // These elements can trigger their own groups.
 // Matches Rust: logic in TEXTUAL trigger
@@ -437,7 +497,7 @@ func isGroupable(elem eval.ContentElement) bool {
 	switch elem.(type) {
 	case *eval.ListItemElement, *eval.EnumItemElement, *eval.TermItemElement:
 		return true
-	case *eval.CiteElement:
+	case *model.CiteElem:
 		return true
 	default:
 		return false
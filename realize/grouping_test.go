@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/model"
 )
 
 // ----------------------------------------------------------------------------
@@ -87,7 +88,7 @@ func TestIsGroupable(t *testing.T) {
 		{"ListItemElement is groupable", &eval.ListItemElement{}, true},
 		{"EnumItemElement is groupable", &eval.EnumItemElement{}, true},
 		{"TermItemElement is groupable", &eval.TermItemElement{}, true},
-		{"CiteElement is groupable", &eval.CiteElement{Key: "key"}, true},
+		{"CiteElem is groupable", &model.CiteElem{Key: "key"}, true},
 
 		// Non-groupable elements
 		{"TextElement is not groupable", &eval.TextElement{Text: "hello"}, false},
@@ -123,6 +124,8 @@ func TestParRule_Trigger(t *testing.T) {
 		{"TextElement triggers", &eval.TextElement{Text: "hello"}, true},
 		{"StrongElement triggers", &eval.StrongElement{}, true},
 		{"LinkElement triggers", &eval.LinkElement{URL: "http://example.com"}, true},
+		{"SpaceElement does not trigger", &eval.SpaceElement{}, false},
+		{"display EquationElement does not trigger", &eval.EquationElement{Block: true}, false},
 		{"HeadingElement does not trigger", &eval.HeadingElement{Depth: 1}, false},
 		{"ListItemElement does not trigger", &eval.ListItemElement{}, false},
 	}
@@ -144,7 +147,8 @@ func TestParRule_Interrupt(t *testing.T) {
 		expected bool
 	}{
 		{"par interrupts", eval.Element{Name: "par"}, true},
-		{"text interrupts", eval.Element{Name: "text"}, true},
+		{"align interrupts", eval.Element{Name: "align"}, true},
+		{"text does not interrupt", eval.Element{Name: "text"}, false},
 		{"heading does not interrupt", eval.Element{Name: "heading"}, false},
 		{"list does not interrupt", eval.Element{Name: "list"}, false},
 	}
@@ -237,7 +241,7 @@ func TestCitesRule_Trigger(t *testing.T) {
 		elem     eval.ContentElement
 		expected bool
 	}{
-		{"CiteElement triggers", &eval.CiteElement{Key: "smith2020"}, true},
+		{"CiteElem triggers", &model.CiteElem{Key: "smith2020"}, true},
 		{"TextElement does not trigger", &eval.TextElement{Text: "hello"}, false},
 		{"RefElement does not trigger", &eval.RefElement{Target: "fig:1"}, false},
 	}
@@ -262,12 +266,12 @@ func TestTextualRule_Trigger(t *testing.T) {
 	}{
 		// Phrasing but not groupable -> triggers
 		{"TextElement triggers", &eval.TextElement{Text: "hello"}, true},
-		{"SpaceElement triggers", &eval.SpaceElement{}, true},
+		{"SpaceElement does not trigger", &eval.SpaceElement{}, false},
 		{"StrongElement triggers", &eval.StrongElement{}, true},
 
 		// Groupable elements don't trigger (they have their own rules)
 		{"ListItemElement does not trigger", &eval.ListItemElement{}, false},
-		{"CiteElement does not trigger", &eval.CiteElement{Key: "key"}, false},
+		{"CiteElem does not trigger", &model.CiteElem{Key: "key"}, false},
 
 		// Non-phrasing doesn't trigger
 		{"HeadingElement does not trigger", &eval.HeadingElement{}, false},
@@ -12,6 +12,11 @@ import (
 	"regexp"
 
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/layout"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/library/pdf"
+	"github.com/boergens/gotypst/library/text"
 	"github.com/boergens/gotypst/syntax"
 )
 
@@ -19,95 +24,33 @@ import (
 // Realization Kind
 // ----------------------------------------------------------------------------
 
-// RealizationKind specifies the context for realization.
-// Different kinds affect how content is processed and grouped.
+// RealizationKind specifies the context for realization. The kinds are
+// defined with the routines, so that layout can realize content through
+// the engine without importing this package.
 // Matches Rust: typst-library/src/routines/realize.rs RealizationKind
-type RealizationKind interface {
-	isRealizationKind()
-	// IsDocument returns true if this is a document realization.
-	IsDocument() bool
-	// IsFragment returns true if this is a fragment realization.
-	IsFragment() bool
-}
-
-// LayoutDocument prepares content for full document layout.
-// Matches Rust: RealizationKind::LayoutDocument
-type LayoutDocument struct {
-	// Info receives document metadata populated during realization.
-	Info *DocumentInfo
-}
-
-func (LayoutDocument) isRealizationKind() {}
-func (LayoutDocument) IsDocument() bool   { return true }
-func (LayoutDocument) IsFragment() bool   { return false }
-
-// LayoutFragment prepares content for fragment layout.
-// Matches Rust: RealizationKind::LayoutFragment
-type LayoutFragment struct {
-	// Kind receives the detected fragment kind after realization.
-	Kind *FragmentKind
-}
-
-func (LayoutFragment) isRealizationKind() {}
-func (LayoutFragment) IsDocument() bool   { return false }
-func (LayoutFragment) IsFragment() bool   { return true }
-
-// LayoutPar prepares content for paragraph-specific realization.
-// Matches Rust: RealizationKind::LayoutPar
-type LayoutPar struct{}
-
-func (LayoutPar) isRealizationKind() {}
-func (LayoutPar) IsDocument() bool   { return false }
-func (LayoutPar) IsFragment() bool   { return false }
-
-// HtmlDocument prepares content for HTML document export.
-// Matches Rust: RealizationKind::HtmlDocument
-type HtmlDocument struct {
-	Info       *DocumentInfo
-	IsPhrasing func(eval.ContentElement) bool
-}
-
-func (HtmlDocument) isRealizationKind() {}
-func (HtmlDocument) IsDocument() bool   { return true }
-func (HtmlDocument) IsFragment() bool   { return false }
-
-// HtmlFragment prepares content for HTML fragment export.
-// Matches Rust: RealizationKind::HtmlFragment
-type HtmlFragment struct {
-	Kind       *FragmentKind
-	IsPhrasing func(eval.ContentElement) bool
-}
-
-func (HtmlFragment) isRealizationKind() {}
-func (HtmlFragment) IsDocument() bool   { return false }
-func (HtmlFragment) IsFragment() bool   { return true }
-
-// Math prepares content for mathematical typesetting.
-// Matches Rust: RealizationKind::Math
-type Math struct{}
-
-func (Math) isRealizationKind() {}
-func (Math) IsDocument() bool   { return false }
-func (Math) IsFragment() bool   { return false }
+type RealizationKind = foundations.RealizationKind
+
+// The kinds of realization.
+type (
+	LayoutDocument = foundations.LayoutDocument
+	LayoutFragment = foundations.LayoutFragment
+	LayoutPar      = foundations.LayoutPar
+	HtmlDocument   = foundations.HtmlDocument
+	HtmlFragment   = foundations.HtmlFragment
+	Math           = foundations.MathRealization
+)
 
 // DocumentInfo holds document metadata populated during realization.
-type DocumentInfo struct {
-	Title    *eval.Content
-	Author   []string
-	Keywords []string
-	Date     eval.Value
-	Locale   string
-}
+type DocumentInfo = foundations.DocumentInfo
 
 // FragmentKind indicates the type of fragment detected during realization.
-// Matches Rust: typst-library/src/routines/realize.rs FragmentKind
-type FragmentKind int
+type FragmentKind = foundations.FragmentKind
 
 const (
 	// FragmentBlock indicates block-level content.
-	FragmentBlock FragmentKind = iota
+	FragmentBlock = foundations.FragmentBlock
 	// FragmentInline indicates inline-level content.
-	FragmentInline
+	FragmentInline = foundations.FragmentInline
 )
 
 // ----------------------------------------------------------------------------
@@ -116,10 +59,7 @@ const (
 
 // Pair represents a realized element with its associated style chain.
 // Matches Rust: typst-library/src/routines/realize.rs Pair
-type Pair struct {
-	Content eval.ContentElement
-	Styles  *eval.StyleChain
-}
+type Pair = foundations.Pair
 
 // ----------------------------------------------------------------------------
 // State
@@ -297,6 +237,13 @@ func visit(s *state, content eval.ContentElement, styles *eval.StyleChain) error
 		return nil
 	}
 
+	return visitShown(s, content, styles)
+}
+
+// visitShown handles content to which no show rule applies (anymore):
+// sequences and styled elements are recursed into, everything else is
+// grouped, filtered, or pushed to the output.
+func visitShown(s *state, content eval.ContentElement, styles *eval.StyleChain) error {
 	// Recurse into sequences.
 	if seq, ok := content.(*eval.SequenceElem); ok {
 		for _, elem := range seq.Children {
@@ -397,7 +344,7 @@ func visitShowRules(s *state, content eval.ContentElement, styles *eval.StyleCha
 	if v.step != nil {
 		if v.step.builtin {
 			// Apply built-in show rule.
-			output, err := applyBuiltinShowRule(s.engine, content, localStyles)
+			output, err := applyBuiltinShowRule(s.engine, content, localStyles, tagLocation(startTag))
 			if err != nil {
 				visitErr = err
 			} else if output != nil {
@@ -405,17 +352,33 @@ func visitShowRules(s *state, content eval.ContentElement, styles *eval.StyleCha
 				handled = true
 			}
 		} else if v.step.recipe != nil {
-			// Apply user-defined show rule.
-			output, err := applyRecipe(s.engine, content, v.step.recipe, localStyles)
-			if err != nil {
+			// Apply user-defined show rule. Its output is visited with the
+			// rule revoked, so that the rule does not match its own output.
+			// A rule that keeps producing content for other rules to
+			// transform eventually exceeds the maximum show rule depth.
+			s.engine.Route.Increase()
+			if err := s.engine.Route.CheckShowDepth(); err != nil {
+				visitErr = err
+			} else if output, err := applyRecipe(s.engine, content, v.step.recipe, localStyles); err != nil {
 				visitErr = err
 			} else if output != nil {
-				visitErr = visitContent(s, *output, localStyles)
+				revocation := foundations.NewStyles()
+				revocation.Revoke(v.step.recipeIndex)
+				visitErr = visitContent(s, *output, localStyles.Chain(revocation))
 				handled = true
 			}
+			s.engine.Route.Decrease()
 		}
 	}
 
+	// Without a transformation, the element itself is realized, with the
+	// styles of matching show-set rules applied. It is not visited again,
+	// as that would find the same show-set rules once more.
+	if !handled && visitErr == nil && localStyles != styles {
+		visitErr = visitShown(s, content, localStyles)
+		handled = true
+	}
+
 	// Restore outside state.
 	s.outside = prevOutside
 
@@ -444,7 +407,8 @@ func isContextElem(elem eval.ContentElement) bool {
 func getVerdict(engine *eval.Engine, elem eval.ContentElement, styles *eval.StyleChain) *verdict {
 	// Get recipes from style chain.
 	recipes := styles.Recipes()
-	if len(recipes) == 0 {
+	builtin := hasBuiltinShowRule(elem)
+	if len(recipes) == 0 && !builtin {
 		return nil
 	}
 
@@ -454,7 +418,7 @@ func getVerdict(engine *eval.Engine, elem eval.ContentElement, styles *eval.Styl
 
 	// Check each recipe for a match.
 	for i, recipe := range recipes {
-		if recipe.Selector == nil {
+		if recipe.Selector == nil || styles.IsRevoked(i) {
 			continue
 		}
 
@@ -479,7 +443,7 @@ func getVerdict(engine *eval.Engine, elem eval.ContentElement, styles *eval.Styl
 	}
 
 	// If nothing to do, return nil.
-	if matchedRecipe == nil && showSetStyles == nil {
+	if matchedRecipe == nil && showSetStyles == nil && !builtin {
 		return nil
 	}
 
@@ -493,11 +457,24 @@ func getVerdict(engine *eval.Engine, elem eval.ContentElement, styles *eval.Styl
 			recipe:      matchedRecipe,
 			recipeIndex: matchedIndex,
 		}
+	} else if builtin {
+		v.step = &showStep{builtin: true}
 	}
 
 	return v
 }
 
+// hasBuiltinShowRule returns true if an element is shown by a built-in
+// show rule when no user-defined show rule applies.
+func hasBuiltinShowRule(elem eval.ContentElement) bool {
+	switch elem.(type) {
+	case *eval.ContextElem, *text.RawElem, *model.TableElem, *model.TableCellElem,
+		*layout.GridElement, *layout.GridCellElem:
+		return true
+	}
+	return false
+}
+
 // prepare prepares an element for realization.
 // This assigns a location, applies show-set rules, synthesizes fields,
 // and returns tags for introspection.
@@ -541,7 +518,7 @@ func isLocatable(elem eval.ContentElement) bool {
 		return true
 	case *eval.EquationElement:
 		return true
-	case *eval.CiteElement:
+	case *model.CiteElem:
 		return true
 	case *eval.RefElement:
 		return true
@@ -551,6 +528,10 @@ func isLocatable(elem eval.ContentElement) bool {
 		return true
 	case *eval.EmphElement:
 		return true
+	case *eval.ContextElem:
+		return true
+	case *pdf.EmbedElem:
+		return true
 	default:
 		return false
 	}
@@ -560,15 +541,7 @@ func isLocatable(elem eval.ContentElement) bool {
 // Labels are used for cross-references in Typst.
 // Matches Rust: content.label().is_some()
 func hasLabel(elem eval.ContentElement) bool {
-	// Check element types that can have labels.
-	// Currently only SymbolElem has a Label field in our implementation.
-	// More elements will get label support as they are implemented.
-	if sym, ok := elem.(*eval.SymbolElem); ok {
-		return sym.Label != nil && *sym.Label != ""
-	}
-	// TODO: Add more element types as they gain label support
-	// (e.g., HeadingElement, ImageElement, EquationElement, etc.)
-	return false
+	return foundations.ElementLabel(elem) != nil
 }
 
 // isTagged returns true if an element is semantically tagged (for accessibility).
@@ -775,7 +748,7 @@ func finish(s *state) error {
 			if len(s.groupings) > 0 {
 				s.groupings = s.groupings[:len(s.groupings)-1]
 			}
-			collapseSpaces(s.sink, 0)
+			collapseSpaces(&s.sink, 0)
 			return false
 		}
 		return len(s.groupings) > 0
@@ -917,10 +890,10 @@ func isFullyInline(s *state) bool {
 func matchesSelector(elem eval.ContentElement, selector eval.Selector, styles *eval.StyleChain) bool {
 	switch sel := selector.(type) {
 	case eval.ElemSelector:
-		return getElementName(elem) == sel.Element.Name
-	case eval.LabelSelector:
-		// TODO: Implement label matching
-		return false
+		return getElementName(elem) == sel.Element.Name && sel.MatchesFields(elem)
+	case foundations.LabelSelector:
+		label := foundations.ElementLabel(elem)
+		return label != nil && string(*label) == sel.Label
 	case eval.TextSelector:
 		if text, ok := elem.(*eval.TextElement); ok {
 			if sel.IsRegex {
@@ -1009,12 +982,14 @@ func getElementName(elem eval.ContentElement) string {
 		return "page"
 	case *eval.PagebreakElem:
 		return "pagebreak"
-	case *eval.CiteElement:
+	case *model.CiteElem:
 		return "cite"
 	case *eval.InlineElem:
 		return "inline"
 	default:
-		return ""
+		// Elements registered with the library are named by their
+		// definition, like raw.line or table.cell.
+		return foundations.ElementName(elem)
 	}
 }
 
@@ -1052,19 +1027,158 @@ func getDocumentInfo(kind RealizationKind) *DocumentInfo {
 	}
 }
 
-// populateDocumentInfo fills in document info from styles.
+// populateDocumentInfo fills in document info from the document set rules
+// in styles. A later rule overrides the fields it sets.
+// Matches Rust: DocumentInfo::populate
 func populateDocumentInfo(info *DocumentInfo, styles *eval.Styles) {
-	// TODO: Extract document properties from styles
+	for _, rule := range styles.Rules {
+		if rule.Func == nil || rule.Func.Name == nil || *rule.Func.Name != "document" || rule.Args == nil {
+			continue
+		}
+		if arg := rule.Args.GetNamed("title"); arg != nil {
+			info.Title = documentContent(arg.V)
+		}
+		if arg := rule.Args.GetNamed("author"); arg != nil {
+			info.Author = model.DocumentStrings(arg.V)
+		}
+		if arg := rule.Args.GetNamed("description"); arg != nil {
+			info.Description = documentContent(arg.V)
+		}
+		if arg := rule.Args.GetNamed("keywords"); arg != nil {
+			info.Keywords = model.DocumentStrings(arg.V)
+		}
+		if arg := rule.Args.GetNamed("date"); arg != nil {
+			info.Date = arg.V
+		}
+	}
+}
+
+// documentContent returns the content of a title or description value,
+// or nil for none.
+func documentContent(v eval.Value) *eval.Content {
+	if eval.IsNone(v) {
+		return nil
+	}
+	content := eval.Display(v)
+	return &content
 }
 
 // applyBuiltinShowRule applies a built-in show rule.
-func applyBuiltinShowRule(engine *eval.Engine, elem eval.ContentElement, styles *eval.StyleChain) (*eval.Content, error) {
+func applyBuiltinShowRule(engine *eval.Engine, elem eval.ContentElement, styles *eval.StyleChain, location *eval.Location) (*eval.Content, error) {
 	// Built-in show rules convert elements to their visual representation.
-	// For now, return nil to indicate no transformation.
-	// TODO: Implement built-in show rules for each element type.
+	switch e := elem.(type) {
+	case *eval.ContextElem:
+		// Evaluate the context expression now that its styles and
+		// location are known.
+		value, err := e.Show(engine, styles, location)
+		if err != nil {
+			return nil, err
+		}
+		output := eval.Display(value)
+		return &output, nil
+	case *text.RawElem:
+		// Split the text into lines, which show rules for raw.line can
+		// then style individually, and highlight them token by token.
+		if err := e.Synthesize(styles); err != nil {
+			return nil, err
+		}
+		e.Highlight(text.DefaultHighlightHooks)
+		output := e.Show()
+		if e.Block {
+			output = eval.Content{Elements: []eval.ContentElement{&eval.BlockElement{Body: output}}}
+		}
+		return &output, nil
+	case *model.TableElem:
+		// Position the cells first, so that show rules for table.cell see
+		// their columns and rows, and apply the rules before the table is
+		// laid out. The table itself stays as it is.
+		if e.Cells == nil {
+			if err := e.ResolveCells(); err != nil {
+				return nil, foundations.AtElement(err, e)
+			}
+			for _, cell := range e.Cells {
+				shown := *cell
+				body, err := showCell(engine, &shown, cell.Body, styles)
+				if err != nil {
+					return nil, err
+				}
+				cell.Body = body
+			}
+		}
+		return nil, nil
+	case *layout.GridElement:
+		if e.Cells == nil {
+			if err := e.ResolveCells(); err != nil {
+				return nil, foundations.AtElement(err, e)
+			}
+			for _, cell := range e.Cells {
+				shown := *cell
+				body, err := showCell(engine, &shown, cell.Body, styles)
+				if err != nil {
+					return nil, err
+				}
+				cell.Body = body
+			}
+		}
+		return nil, nil
+	case *model.TableCellElem:
+		return &e.Body, nil
+	case *layout.GridCellElem:
+		return &e.Body, nil
+	}
+	// TODO: Implement built-in show rules for the other element types.
 	return nil, nil
 }
 
+// showCell applies the show rules for table.cell or grid.cell to a cell
+// and returns the content to lay out in it. The output of a matching show
+// rule is styled to revoke the rule, so that the cell within the output,
+// which shows the given body, is not transformed again. Without a show
+// rule, the body is returned with the styles of show-set rules.
+// Matches Rust: the realization of each cell in layout_cell
+func showCell(engine *eval.Engine, cell eval.ContentElement, body eval.Content, styles *eval.StyleChain) (eval.Content, error) {
+	v := getVerdict(engine, cell, styles)
+	if v == nil {
+		return body, nil
+	}
+	if v.step == nil || v.step.recipe == nil {
+		return foundations.StyledWithMap(body, v.styles), nil
+	}
+
+	localStyles := styles
+	if v.styles != nil && len(v.styles.Rules) > 0 {
+		localStyles = styles.Chain(v.styles)
+	}
+	engine.Route.Increase()
+	defer engine.Route.Decrease()
+	if err := engine.Route.CheckShowDepth(); err != nil {
+		return eval.Content{}, err
+	}
+	output, err := applyRecipe(engine, cell, v.step.recipe, localStyles)
+	if err != nil {
+		return eval.Content{}, err
+	}
+	if output == nil {
+		return foundations.StyledWithMap(body, v.styles), nil
+	}
+
+	styled := foundations.NewStyles()
+	if v.styles != nil {
+		styled.Rules = append(styled.Rules, v.styles.Rules...)
+	}
+	styled.Revoke(v.step.recipeIndex)
+	return foundations.StyledWithMap(*output, styled), nil
+}
+
+// tagLocation returns the location assigned to an element through its
+// start tag, if it has one.
+func tagLocation(tag *eval.TagElem) *eval.Location {
+	if tag == nil {
+		return nil
+	}
+	return &eval.Location{Hash: tag.Tag.Location.Hash}
+}
+
 // applyRecipe applies a user-defined show rule recipe.
 func applyRecipe(engine *eval.Engine, elem eval.ContentElement, recipe *eval.Recipe, styles *eval.StyleChain) (*eval.Content, error) {
 	switch t := recipe.Transform.(type) {
@@ -1131,7 +1245,6 @@ type regexMatch struct {
 // Matches Rust: visit_textual()
 func visitTextual(s *state, pairs []Pair, start int) error {
 	if len(pairs) == 0 {
-		collapseSpaces(pairs, start)
 		return nil
 	}
 
@@ -1139,7 +1252,7 @@ func visitTextual(s *state, pairs []Pair, start int) error {
 	m := findRegexMatchInElems(pairs)
 	if m == nil {
 		// No regex match, just collapse spaces.
-		collapseSpaces(pairs, start)
+		collapseSpaces(&s.sink, start)
 		return nil
 	}
 
@@ -1242,7 +1355,7 @@ func findRegexMatchInStr(text string, styles *eval.StyleChain) *regexMatch {
 	var leftmost *regexMatch
 
 	for i, recipe := range recipes {
-		if recipe.Selector == nil {
+		if recipe.Selector == nil || styles.IsRevoked(i) {
 			continue
 		}
 
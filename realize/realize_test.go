@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/library/model"
+	"github.com/boergens/gotypst/syntax"
 )
 
 // ----------------------------------------------------------------------------
@@ -152,6 +155,195 @@ func TestRealizeParbreak(t *testing.T) {
 	}
 }
 
+func TestRealizeSpacesAroundBreaks(t *testing.T) {
+	content := &eval.SequenceElem{
+		Children: []eval.ContentElement{
+			&eval.SpaceElement{},
+			&eval.TextElement{Text: "First"},
+			&eval.SpaceElement{},
+			&eval.ParbreakElement{},
+			&eval.SpaceElement{},
+			&eval.BlockElement{},
+			&eval.SpaceElement{},
+			&eval.TextElement{Text: "Second"},
+			&eval.SpaceElement{},
+			&eval.TagElem{},
+			&eval.SpaceElement{},
+		},
+	}
+
+	pairs, err := Realize(LayoutDocument{}, nil, content, eval.EmptyStyleChain())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Spaces neither start paragraphs nor survive at their edges.
+	if len(pairs) != 4 {
+		t.Fatalf("expected paragraph, block, paragraph, tag, got %d pairs", len(pairs))
+	}
+	for i, want := range []string{"par", "block", "par"} {
+		if got := getElementName(pairs[i].Content); got != want {
+			t.Errorf("pairs[%d] is %s, want %s", i, got, want)
+		}
+	}
+	for _, i := range []int{0, 2} {
+		par := pairs[i].Content.(*eval.ParagraphElement)
+		if len(par.Body.Elements) != 1 {
+			t.Errorf("paragraph %d has %d children, want only its text", i, len(par.Body.Elements))
+		}
+	}
+}
+
+func TestRealizeParagraphStyles(t *testing.T) {
+	set := func(funcName, field string, value foundations.Value) *eval.Styles {
+		key := foundations.Str(field)
+		return &eval.Styles{Rules: []eval.StyleRule{{
+			Func: &eval.Func{Name: &funcName},
+			Args: &foundations.Args{Items: []foundations.Arg{{Name: &key, Value: syntax.Spanned[foundations.Value]{V: value}}}},
+		}}}
+	}
+
+	// #set par(justify: true); a #text(fill: red)[b] #set text(size: 2) c
+	styles := eval.EmptyStyleChain().Chain(set("par", "justify", foundations.Bool(true)))
+	content := &eval.SequenceElem{
+		Children: []eval.ContentElement{
+			&eval.TextElement{Text: "a"},
+			&eval.SpaceElement{},
+			&eval.StyledElement{
+				Child:  eval.Content{Elements: []eval.ContentElement{&eval.TextElement{Text: "b"}}},
+				Styles: set("text", "fill", foundations.Str("red")),
+			},
+			&eval.SpaceElement{},
+			&eval.StyledElement{
+				Child:  eval.Content{Elements: []eval.ContentElement{&eval.TextElement{Text: "c"}}},
+				Styles: set("text", "size", foundations.Int(2)),
+			},
+		},
+	}
+
+	pairs, err := Realize(LayoutDocument{}, nil, content, styles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Text set rules don't split the paragraph.
+	if len(pairs) != 1 {
+		t.Fatalf("expected a single paragraph, got %d pairs", len(pairs))
+	}
+	par, ok := pairs[0].Content.(*eval.ParagraphElement)
+	if !ok {
+		t.Fatalf("expected ParagraphElement, got %T", pairs[0].Content)
+	}
+
+	// The paragraph has the shared styles, the elements keep their own.
+	if got := pairs[0].Styles.Get("par", "justify"); got != foundations.Bool(true) {
+		t.Errorf("paragraph justify = %v, want true", got)
+	}
+	if got := pairs[0].Styles.Get("text", "fill"); got != nil {
+		t.Errorf("paragraph text fill = %v, want none", got)
+	}
+	if len(par.Body.Elements) != 5 {
+		t.Fatalf("paragraph has %d children, want 5", len(par.Body.Elements))
+	}
+	styled, ok := par.Body.Elements[2].(*eval.StyledElement)
+	if !ok {
+		t.Fatalf("expected the red text to be styled, got %T", par.Body.Elements[2])
+	}
+	if len(styled.Styles.Rules) != 1 || *styled.Styles.Rules[0].Func.Name != "text" {
+		t.Errorf("styled child has rules %+v, want only its text fill", styled.Styles.Rules)
+	}
+}
+
+func TestRealizeListGrouping(t *testing.T) {
+	item := func(text string) *eval.ListItemElement {
+		return &eval.ListItemElement{Content: eval.Content{
+			Elements: []eval.ContentElement{&eval.TextElement{Text: text}},
+		}}
+	}
+	realizeLists := func(children ...eval.ContentElement) []*eval.ListElement {
+		t.Helper()
+		pairs, err := Realize(LayoutDocument{}, nil, &eval.SequenceElem{Children: children}, eval.EmptyStyleChain())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var lists []*eval.ListElement
+		for _, pair := range pairs {
+			if list, ok := pair.Content.(*eval.ListElement); ok {
+				lists = append(lists, list)
+			}
+		}
+		return lists
+	}
+
+	// - a
+	// - b
+	lists := realizeLists(item("a"), &eval.SpaceElement{}, item("b"))
+	if len(lists) != 1 || len(lists[0].Items) != 2 || !*lists[0].Tight {
+		t.Errorf("adjacent items: %d lists, want one tight list of 2 items", len(lists))
+	}
+
+	// - a
+	//
+	// - b
+	lists = realizeLists(item("a"), &eval.ParbreakElement{}, item("b"), &eval.ParbreakElement{})
+	if len(lists) != 1 || len(lists[0].Items) != 2 || *lists[0].Tight {
+		t.Errorf("items separated by a blank line: %d lists, want one wide list of 2 items", len(lists))
+	}
+
+	// A trailing blank line doesn't make the list wide.
+	lists = realizeLists(item("a"), &eval.SpaceElement{}, item("b"), &eval.ParbreakElement{})
+	if len(lists) != 1 || !*lists[0].Tight {
+		t.Errorf("trailing blank line: %d lists, want one tight list", len(lists))
+	}
+
+	// Other content ends the list.
+	lists = realizeLists(item("a"), &eval.ParbreakElement{}, &eval.TextElement{Text: "text"}, &eval.ParbreakElement{}, item("b"))
+	if len(lists) != 2 {
+		t.Errorf("items separated by a paragraph: %d lists, want 2", len(lists))
+	}
+}
+
+func TestRealizeCiteGrouping(t *testing.T) {
+	cite := func(key string) *model.CiteElem {
+		return &model.CiteElem{Key: key}
+	}
+	realizeCites := func(children ...eval.ContentElement) []*model.CiteGroup {
+		t.Helper()
+		pairs, err := Realize(LayoutDocument{}, nil, &eval.SequenceElem{Children: children}, eval.EmptyStyleChain())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var groups []*model.CiteGroup
+		for _, pair := range pairs {
+			par, ok := pair.Content.(*eval.ParagraphElement)
+			if !ok {
+				continue
+			}
+			for _, elem := range par.Body.Elements {
+				if group, ok := elem.(*model.CiteGroup); ok {
+					groups = append(groups, group)
+				}
+			}
+		}
+		return groups
+	}
+
+	// @a @b @c
+	groups := realizeCites(cite("a"), &eval.SpaceElement{}, cite("b"), &eval.SpaceElement{}, cite("c"))
+	if len(groups) != 1 || len(groups[0].Children) != 3 {
+		t.Fatalf("adjacent citations: %d groups, want one group of 3 citations", len(groups))
+	}
+	if groups[0].Children[2].Key != "c" {
+		t.Errorf("last citation is %q, want %q", groups[0].Children[2].Key, "c")
+	}
+
+	// Text ends the group.
+	groups = realizeCites(cite("a"), &eval.SpaceElement{}, &eval.TextElement{Text: "and"}, &eval.SpaceElement{}, cite("b"))
+	if len(groups) != 2 {
+		t.Errorf("citations separated by text: %d groups, want 2", len(groups))
+	}
+}
+
 func TestFragmentKindDetection(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -281,7 +473,7 @@ func TestGetElementName(t *testing.T) {
 		{&eval.AlignElement{}, "align"},
 		{&eval.PageElem{}, "page"},
 		{&eval.PagebreakElem{}, "pagebreak"},
-		{&eval.CiteElement{}, "cite"},
+		{&model.CiteElem{}, "cite"},
 	}
 
 	for _, tt := range tests {
@@ -414,3 +606,46 @@ func TestMatchesSelector(t *testing.T) {
 		})
 	}
 }
+
+func TestRealizeShowSet(t *testing.T) {
+	// show heading: set text(fill: red)
+	text, key := "text", foundations.Str("fill")
+	args := &foundations.Args{Items: []foundations.Arg{{
+		Name:  &key,
+		Value: syntax.Spanned[foundations.Value]{V: foundations.Str("red")},
+	}}}
+	var selector eval.Selector = eval.ElemSelector{Element: eval.Element{Name: "heading"}}
+	recipe := &eval.Recipe{
+		Selector: &selector,
+		Transform: eval.StyleTransformation{Styles: &eval.Styles{
+			Rules: []eval.StyleRule{{Func: &eval.Func{Name: &text}, Args: args}},
+		}},
+	}
+	styles := eval.EmptyStyleChain().Chain(&eval.Styles{Recipes: []*eval.Recipe{recipe}})
+
+	heading := &eval.HeadingElement{Depth: 1}
+	content := &eval.SequenceElem{Children: []eval.ContentElement{heading}}
+	pairs, err := Realize(LayoutDocument{}, nil, content, styles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, pair := range pairs {
+		if pair.Content != heading {
+			continue
+		}
+		found = true
+		if fill := pair.Styles.Get("text", "fill"); fill != foundations.Str("red") {
+			t.Errorf("heading text fill = %v, expected red", fill)
+		}
+	}
+	if !found {
+		t.Fatal("heading missing from realized output")
+	}
+
+	// Show-set styles are scoped to the matched elements.
+	if fill := styles.Get("text", "fill"); fill != nil {
+		t.Errorf("outer text fill = %v, expected none", fill)
+	}
+}
@@ -0,0 +1,14 @@
+package realize
+
+import (
+	"github.com/boergens/gotypst/library/foundations"
+)
+
+// Routines implements realization's part of foundations.Routines, through
+// which layout realizes the content it lays out.
+type Routines struct{}
+
+// Realize applies show rules to content and groups its elements.
+func (Routines) Realize(engine *foundations.Engine, kind foundations.RealizationKind, content foundations.ContentElement, styles *foundations.StyleChain) ([]foundations.Pair, error) {
+	return Realize(kind, engine, content, styles)
+}
@@ -15,18 +15,21 @@ import (
 type SpaceState int
 
 const (
-	// StateInvisible - Elements that don't affect space collapsing (tags, metadata).
+	// StateInvisible - Elements that don't affect space collapsing (tags).
+	// A space before a tag still collapses with what follows the tag.
 	StateInvisible SpaceState = iota
-	// StateDestructive - Elements that discard adjacent spaces (block elements).
+	// StateDestructive - Elements that discard adjacent spaces (breaks,
+	// weak spacing, block elements).
 	StateDestructive
-	// StateSupportive - Normal elements requiring spaces on both sides.
+	// StateSupportive - Normal elements that keep a single space on either
+	// side.
 	StateSupportive
 	// StateSpace - Space elements that can collapse with adjacent spaces.
 	StateSpace
 )
 
 // getSpaceState returns the space state for an element.
-// Matches Rust: impl SpaceState for various element types
+// Matches Rust: the element checks in collapse_spaces() in typst-realize/src/spaces.rs
 func getSpaceState(elem eval.ContentElement) SpaceState {
 	if elem == nil {
 		return StateInvisible
@@ -37,18 +40,25 @@ func getSpaceState(elem eval.ContentElement) SpaceState {
 	case *eval.SpaceElement:
 		return StateSpace
 
-	// Text elements: check if whitespace-only
-	case *eval.TextElement:
-		if isWhitespaceOnly(e.Text) {
-			return StateSpace
-		}
-		return StateSupportive
+	// Tags are invisible: they must not keep a space alive or separate it
+	// from the element it collapses with.
+	case *eval.TagElem:
+		return StateInvisible
 
-	// Destructive: block-level and paragraph boundary elements
-	case *eval.ParbreakElement, *eval.LinebreakElement:
+	// Forced line breaks discard the spaces around them.
+	case *eval.LinebreakElement:
 		return StateDestructive
 
-	case *eval.ParagraphElement, *eval.HeadingElement:
+	// Weak and fractional horizontal spacing absorb adjacent spaces.
+	case *eval.HElem:
+		if e.Weak || e.Amount.IsFrac() {
+			return StateDestructive
+		}
+		return StateSupportive
+
+	// Block-level elements and paragraph breaks never end up within a
+	// paragraph, but a space next to one has nothing to separate.
+	case *eval.ParbreakElement, *eval.ParagraphElement, *eval.HeadingElement:
 		return StateDestructive
 
 	case *eval.ListItemElement, *eval.EnumItemElement, *eval.TermItemElement:
@@ -57,37 +67,18 @@ func getSpaceState(elem eval.ContentElement) SpaceState {
 	case *eval.ListElement, *eval.EnumElement, *eval.TermsElement:
 		return StateDestructive
 
-	case *eval.BlockElement:
+	case *eval.BlockElement, *eval.VElem:
 		return StateDestructive
 
-	case *eval.VElem:
-		return StateDestructive
-
-	// Tags are invisible (pass through)
-	case *eval.TagElem:
-		return StateInvisible
-
-	// Sequences need inspection (but treat as supportive for now)
-	case *eval.SequenceElem:
-		return StateSupportive
-
-	// Styled content is supportive
-	case *eval.StyledElement:
-		return StateSupportive
-
-	// Inline elements are supportive
-	case *eval.StrongElement, *eval.EmphElement, *eval.RawElement:
-		return StateSupportive
-
-	case *eval.LinkElement, *eval.RefElement, *eval.SmartQuoteElement:
-		return StateSupportive
-
-	case *eval.HElem, *eval.BoxElement, *eval.InlineElem:
-		return StateSupportive
-
+	// Display equations are blocks, inline equations are like text.
 	case *eval.EquationElement:
+		if e.Block {
+			return StateDestructive
+		}
 		return StateSupportive
 
+	// Everything else, including text that consists only of whitespace,
+	// is supportive: explicit text is never collapsed.
 	default:
 		return StateSupportive
 	}
@@ -103,84 +94,61 @@ func isWhitespaceOnly(s string) bool {
 	return len(s) > 0
 }
 
-// collapseSpaces collapses spaces within a slice of pairs starting from an offset.
-// This modifies the slice in-place.
+// collapseSpaces collapses the spaces in buf from start onwards in place
+// and shrinks buf accordingly. A space survives only if it is between two
+// supportive elements, disregarding invisible ones; of several adjacent
+// spaces, the first one survives.
 // Matches Rust: collapse_spaces() in typst-realize/src/spaces.rs
-func collapseSpaces(pairs []Pair, start int) {
-	if len(pairs) <= start {
-		return
-	}
-
-	// Work on the slice from start onwards
-	work := pairs[start:]
-	if len(work) == 0 {
+func collapseSpaces(buf *[]Pair, start int) {
+	sink := *buf
+	if len(sink) <= start {
 		return
 	}
 
-	write := 0
-	lastState := StateDestructive // Treat start as destructive (no leading spaces)
-	pendingSpace := -1            // Index of pending space in work slice
+	// The start is destructive, so that leading spaces are removed.
+	state := StateDestructive
+	// space is the index of the pending space while state is StateSpace.
+	space := -1
+	k := start
 
-	for i := 0; i < len(work); i++ {
-		state := getSpaceState(work[i].Content)
+	destruct := func() {
+		if state == StateSpace {
+			copy(sink[space:], sink[space+1:k])
+			k--
+		}
+		state = StateDestructive
+	}
 
-		switch state {
+	for i := start; i < len(sink); i++ {
+		switch getSpaceState(sink[i].Content) {
 		case StateInvisible:
-			// Always keep invisible elements, copy to write position
-			if write != i {
-				work[write] = work[i]
-			}
-			write++
-
+			// Nothing to do.
 		case StateSpace:
-			// Space handling: collapse multiple spaces
-			if lastState == StateDestructive || lastState == StateSpace {
-				// Skip: adjacent to destructive or another space
+			if state != StateSupportive {
 				continue
 			}
-			// Mark as pending (may be removed if followed by destructive)
-			pendingSpace = write
-			if write != i {
-				work[write] = work[i]
-			}
-			write++
-			lastState = StateSpace
-
+			state = StateSpace
+			space = k
 		case StateDestructive:
-			// Remove pending space if any
-			if pendingSpace >= 0 && pendingSpace < write {
-				// Remove the space at pendingSpace by shifting
-				copy(work[pendingSpace:], work[pendingSpace+1:write])
-				write--
-			}
-			pendingSpace = -1
-
-			if write != i {
-				work[write] = work[i]
-			}
-			write++
-			lastState = StateDestructive
-
+			destruct()
 		case StateSupportive:
-			pendingSpace = -1
-			if write != i {
-				work[write] = work[i]
-			}
-			write++
-			lastState = StateSupportive
+			state = StateSupportive
 		}
-	}
 
-	// Remove trailing space
-	if write > 0 && getSpaceState(work[write-1].Content) == StateSpace {
-		write--
+		// Copy over the element (in place).
+		sink[k] = sink[i]
+		k++
 	}
 
-	// Truncate by zeroing out the unused portion (can't actually resize)
-	// The caller is responsible for respecting the logical length
-	for i := write; i < len(work); i++ {
-		work[i] = Pair{}
+	// The end is destructive, so that a trailing space is removed even if
+	// tags follow it.
+	destruct()
+
+	// Clear the excess so that it doesn't keep elements alive.
+	for i := k; i < len(sink); i++ {
+		sink[i] = Pair{}
 	}
+	*buf = sink[:k]
 }
 
 // normalizeSpaces normalizes whitespace within text elements.
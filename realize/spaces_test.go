@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/library/layout"
 )
 
 func TestGetSpaceState(t *testing.T) {
@@ -14,7 +15,7 @@ func TestGetSpaceState(t *testing.T) {
 	}{
 		{"nil", nil, StateInvisible},
 		{"text", &eval.TextElement{Text: "hello"}, StateSupportive},
-		{"whitespace text", &eval.TextElement{Text: "   "}, StateSpace},
+		{"whitespace text", &eval.TextElement{Text: "   "}, StateSupportive},
 		{"space element", &eval.SpaceElement{}, StateSpace},
 		{"parbreak", &eval.ParbreakElement{}, StateDestructive},
 		{"linebreak", &eval.LinebreakElement{}, StateDestructive},
@@ -28,8 +29,11 @@ func TestGetSpaceState(t *testing.T) {
 		{"emph", &eval.EmphElement{}, StateSupportive},
 		{"link", &eval.LinkElement{}, StateSupportive},
 		{"h element", &eval.HElem{}, StateSupportive},
+		{"weak h element", &eval.HElem{Weak: true}, StateDestructive},
+		{"fractional h element", &eval.HElem{Amount: layout.Spacing{IsFractional: true}}, StateDestructive},
 		{"box", &eval.BoxElement{}, StateSupportive},
 		{"equation", &eval.EquationElement{}, StateSupportive},
+		{"display equation", &eval.EquationElement{Block: true}, StateDestructive},
 	}
 
 	for _, tt := range tests {
@@ -69,67 +73,52 @@ func TestIsWhitespaceOnly(t *testing.T) {
 }
 
 func TestCollapseSpaces(t *testing.T) {
-	// Note: collapseSpaces modifies in-place and takes a start index
-	// It's used internally by the grouping system
+	a := Pair{Content: &eval.TextElement{Text: "a"}}
+	b := Pair{Content: &eval.TextElement{Text: "b"}}
+	blank := Pair{Content: &eval.TextElement{Text: " "}}
+	space := Pair{Content: &eval.SpaceElement{}}
+	tag := Pair{Content: &eval.TagElem{}}
+	linebreak := Pair{Content: &eval.LinebreakElement{}}
+	parbreak := Pair{Content: &eval.ParbreakElement{}}
+	block := Pair{Content: &eval.BlockElement{}}
+	weak := Pair{Content: &eval.HElem{Weak: true}}
+	math := Pair{Content: &eval.EquationElement{}}
 
 	tests := []struct {
-		name   string
-		pairs  []Pair
-		start  int
-		verify func([]Pair) bool
+		name  string
+		pairs []Pair
+		start int
+		want  []Pair
 	}{
-		{
-			name:  "empty",
-			pairs: nil,
-			start: 0,
-			verify: func(pairs []Pair) bool {
-				return len(pairs) == 0
-			},
-		},
-		{
-			name: "no spaces",
-			pairs: []Pair{
-				{Content: &eval.TextElement{Text: "hello"}},
-				{Content: &eval.TextElement{Text: "world"}},
-			},
-			start: 0,
-			verify: func(pairs []Pair) bool {
-				// Should have both elements
-				count := 0
-				for _, p := range pairs {
-					if p.Content != nil {
-						count++
-					}
-				}
-				return count == 2
-			},
-		},
-		{
-			name: "collapse leading space",
-			pairs: []Pair{
-				{Content: &eval.SpaceElement{}},
-				{Content: &eval.TextElement{Text: "hello"}},
-			},
-			start: 0,
-			verify: func(pairs []Pair) bool {
-				// Leading space should be collapsed
-				// First non-nil should be TextElement
-				for _, p := range pairs {
-					if p.Content != nil {
-						_, ok := p.Content.(*eval.TextElement)
-						return ok
-					}
-				}
-				return false
-			},
-		},
+		{"empty", nil, 0, nil},
+		{"no spaces", []Pair{a, b}, 0, []Pair{a, b}},
+		{"leading and trailing", []Pair{space, a, space}, 0, []Pair{a}},
+		{"adjacent", []Pair{a, space, space, space, b}, 0, []Pair{a, space, b}},
+		{"whitespace text is kept", []Pair{a, blank, space, b}, 0, []Pair{a, blank, space, b}},
+		{"around linebreak", []Pair{a, space, linebreak, space, b}, 0, []Pair{a, linebreak, b}},
+		{"around weak spacing", []Pair{a, space, weak, space, b}, 0, []Pair{a, weak, b}},
+		{"around parbreak", []Pair{a, space, parbreak, space, b}, 0, []Pair{a, parbreak, b}},
+		{"around block", []Pair{space, block, space}, 0, []Pair{block}},
+		{"around inline math", []Pair{a, space, tag, math, tag, space, b}, 0, []Pair{a, space, tag, math, tag, space, b}},
+		{"across tags", []Pair{a, space, tag, space, tag, b}, 0, []Pair{a, space, tag, tag, b}},
+		{"tag before linebreak", []Pair{a, space, tag, linebreak}, 0, []Pair{a, tag, linebreak}},
+		{"trailing before tags", []Pair{a, space, tag, tag}, 0, []Pair{a, tag, tag}},
+		{"leading after tags", []Pair{tag, space, a}, 0, []Pair{tag, a}},
+		{"only spaces and tags", []Pair{space, tag, space}, 0, []Pair{tag}},
+		{"from offset", []Pair{space, a, space, space}, 1, []Pair{space, a}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			collapseSpaces(tt.pairs, tt.start)
-			if !tt.verify(tt.pairs) {
-				t.Errorf("collapseSpaces() verification failed")
+			pairs := append([]Pair(nil), tt.pairs...)
+			collapseSpaces(&pairs, tt.start)
+			if len(pairs) != len(tt.want) {
+				t.Fatalf("collapseSpaces() left %d pairs, want %d", len(pairs), len(tt.want))
+			}
+			for i := range pairs {
+				if pairs[i].Content != tt.want[i].Content {
+					t.Errorf("pair %d is %s, want %s", i, getElementName(pairs[i].Content), getElementName(tt.want[i].Content))
+				}
 			}
 		})
 	}
@@ -0,0 +1,259 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/boergens/gotypst/layout"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/vector"
+)
+
+// tolerance is the maximum distance in device pixels between curves and
+// the lines they are flattened into.
+const tolerance = 0.1
+
+// Canvas draws anti-aliased paths, glyphs, and images onto an RGBA image.
+// Drawing happens in user space, which the current transformation maps to
+// the pixels of the image, and is clipped to the current clip region.
+type Canvas struct {
+	img   *image.RGBA
+	state canvasState
+	stack []canvasState
+	// glyphs caches the outlines of glyphs in font units.
+	glyphs map[glyphKey]*Path
+}
+
+// canvasState is the part of a canvas's state that Save and Restore keep.
+type canvasState struct {
+	transform layout.Transform
+	// clip is the coverage of the clip region per pixel, or nil if
+	// nothing is clipped.
+	clip *image.Alpha
+}
+
+// NewCanvas creates a transparent canvas of the given size in pixels.
+func NewCanvas(width, height int) *Canvas {
+	return &Canvas{
+		img:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		state: canvasState{transform: layout.IdentityTransform()},
+	}
+}
+
+// Image returns the image the canvas draws onto.
+func (c *Canvas) Image() *image.RGBA {
+	return c.img
+}
+
+// Save pushes the current transformation and clip region, so that Restore
+// can return to them.
+func (c *Canvas) Save() {
+	c.stack = append(c.stack, c.state)
+}
+
+// Restore pops the transformation and clip region saved last.
+func (c *Canvas) Restore() {
+	if len(c.stack) == 0 {
+		return
+	}
+	c.state = c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+// Transform applies t to user space before the current transformation.
+func (c *Canvas) Transform(t layout.Transform) {
+	c.state.transform = t.Then(c.state.transform)
+}
+
+// CurrentTransform returns the mapping from user space to pixels.
+func (c *Canvas) CurrentTransform() layout.Transform {
+	return c.state.transform
+}
+
+// Clear fills the whole canvas with a color, ignoring the clip region.
+func (c *Canvas) Clear(col color.Color) {
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// Fill fills the interior of the path with a color, using the non-zero
+// winding rule.
+func (c *Canvas) Fill(path *Path, col color.Color) {
+	device := path.Transform(c.state.transform)
+	c.paint(device.flatten(tolerance), col)
+}
+
+// Stroke strokes the outline of the path with a color.
+func (c *Canvas) Stroke(path *Path, stroke Stroke, col color.Color) {
+	// The stroke is built in user space, where its width is given, with a
+	// tolerance that ends up at the same number of pixels.
+	scale := scaleFactor(c.state.transform)
+	if scale == 0 {
+		return
+	}
+	polys := strokePolygons(path.flatten(tolerance/scale), stroke, tolerance/scale)
+	lines := make([]polyline, len(polys))
+	for i, poly := range polys {
+		for j, p := range poly {
+			poly[j] = apply(c.state.transform, p)
+		}
+		lines[i] = polyline{points: poly, closed: true}
+	}
+	c.paint(lines, col)
+}
+
+// Clip intersects the clip region with the interior of the path.
+func (c *Canvas) Clip(path *Path) {
+	device := path.Transform(c.state.transform)
+	clip := image.NewAlpha(c.img.Bounds())
+	if mask, rect := c.coverage(device.flatten(tolerance)); mask != nil {
+		draw.Draw(clip, rect, mask, rect.Min, draw.Src)
+	}
+	if c.state.clip != nil {
+		for i := range clip.Pix {
+			clip.Pix[i] = mulAlpha(clip.Pix[i], c.state.clip.Pix[i])
+		}
+	}
+	c.state.clip = clip
+}
+
+// DrawImage draws an image scaled to width and height in user space, with
+// its top-left corner at the origin. The image is resampled bilinearly.
+func (c *Canvas) DrawImage(img image.Image, width, height float64) {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return
+	}
+	t := layout.TranslateTransform(-layout.Abs(bounds.Min.X), -layout.Abs(bounds.Min.Y)).
+		Then(layout.ScaleTransform(width/float64(bounds.Dx()), height/float64(bounds.Dy()))).
+		Then(c.state.transform)
+	var opts *xdraw.Options
+	if c.state.clip != nil {
+		opts = &xdraw.Options{DstMask: c.state.clip}
+	}
+	xdraw.BiLinear.Transform(c.img, f64.Aff3{t.Sx, t.Kx, float64(t.Tx), t.Ky, t.Sy, float64(t.Ty)}, img, bounds, xdraw.Over, opts)
+}
+
+// scaleFactor returns the average factor by which a transformation scales
+// lengths. It sets the flattening tolerance in user space.
+func scaleFactor(t layout.Transform) float64 {
+	return math.Sqrt(math.Abs(t.Sx*t.Sy - t.Ky*t.Kx))
+}
+
+// paint composites a color onto the canvas where the polygons, in device
+// space, cover it.
+func (c *Canvas) paint(lines []polyline, col color.Color) {
+	mask, rect := c.coverage(lines)
+	if mask == nil {
+		return
+	}
+	if c.state.clip != nil {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			row := mask.Pix[mask.PixOffset(rect.Min.X, y):]
+			clip := c.state.clip.Pix[c.state.clip.PixOffset(rect.Min.X, y):]
+			for x := range rect.Dx() {
+				row[x] = mulAlpha(row[x], clip[x])
+			}
+		}
+	}
+	draw.DrawMask(c.img, rect, image.NewUniform(col), image.Point{}, mask, rect.Min, draw.Over)
+}
+
+// coverage rasterizes the polygons, in device space, into an anti-aliased
+// mask. The mask covers only the polygons' bounding box within the canvas,
+// which is returned along with it. It returns a nil mask if the polygons
+// are outside the canvas.
+func (c *Canvas) coverage(lines []polyline) (*image.Alpha, image.Rectangle) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, line := range lines {
+		for _, p := range line.points {
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	if !(minX <= maxX && minY <= maxY) {
+		return nil, image.Rectangle{}
+	}
+	rect := image.Rect(
+		int(math.Floor(math.Max(minX, -1))),
+		int(math.Floor(math.Max(minY, -1))),
+		int(math.Ceil(math.Min(maxX, float64(c.img.Rect.Max.X+1)))),
+		int(math.Ceil(math.Min(maxY, float64(c.img.Rect.Max.Y+1)))),
+	).Intersect(c.img.Bounds())
+	if rect.Empty() {
+		return nil, image.Rectangle{}
+	}
+
+	// The rasterizer's origin is the top-left corner of the mask.
+	r := vector.NewRasterizer(rect.Dx(), rect.Dy())
+	r.DrawOp = draw.Src
+	origin := Point{float64(rect.Min.X), float64(rect.Min.Y)}
+	for _, line := range lines {
+		n := len(line.points)
+		if n < 2 {
+			continue
+		}
+		for i, p := range line.points {
+			addEdge(r, line.points[(i+n-1)%n].sub(origin), p.sub(origin))
+		}
+	}
+	mask := image.NewAlpha(rect)
+	r.Draw(mask, rect, image.Opaque, image.Point{})
+	return mask, rect
+}
+
+// addEdge adds an edge of a polygon to the rasterizer, which sums up the
+// coverage of each edge on its own. Parts of the edge above or below the
+// rasterizer don't cover anything and are dropped. Parts to the left or
+// right only change the winding of the pixels beside them, so they are
+// replaced with vertical edges just outside, which keeps long edges from
+// being walked pixel by pixel.
+func addEdge(r *vector.Rasterizer, a, b Point) {
+	size := r.Size()
+	w, h := float64(size.X), float64(size.Y)
+
+	t0, t1 := 0.0, 1.0
+	if a.Y != b.Y {
+		ta, tb := (0-a.Y)/(b.Y-a.Y), (h-a.Y)/(b.Y-a.Y)
+		t0, t1 = math.Max(t0, math.Min(ta, tb)), math.Min(t1, math.Max(ta, tb))
+	} else if a.Y < 0 || a.Y > h {
+		return
+	}
+	if t0 >= t1 {
+		return
+	}
+
+	// Split the remaining part where it crosses the left and right edges.
+	ts := []float64{t0}
+	if a.X != b.X {
+		for _, edge := range []float64{0, w} {
+			if t := (edge - a.X) / (b.X - a.X); t > t0 && t < t1 {
+				ts = append(ts, t)
+			}
+		}
+		if len(ts) == 3 && ts[1] > ts[2] {
+			ts[1], ts[2] = ts[2], ts[1]
+		}
+	}
+	ts = append(ts, t1)
+
+	for i := 0; i+1 < len(ts); i++ {
+		p, q := a.lerp(b, ts[i]), a.lerp(b, ts[i+1])
+		switch mid := (p.X + q.X) / 2; {
+		case mid < 0:
+			p.X, q.X = -1, -1
+		case mid > w:
+			p.X, q.X = w+1, w+1
+		}
+		r.MoveTo(float32(p.X), float32(p.Y))
+		r.LineTo(float32(q.X), float32(q.Y))
+	}
+}
+
+// mulAlpha multiplies two coverage values.
+func mulAlpha(a, b uint8) uint8 {
+	return uint8((uint16(a)*uint16(b) + 127) / 255)
+}
@@ -0,0 +1,408 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/go-text/typesetting/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+var red = color.NRGBA{R: 255, A: 255}
+
+// alphaAt returns the alpha of the canvas pixel at (x, y).
+func alphaAt(c *Canvas, x, y int) uint8 {
+	return c.Image().RGBAAt(x, y).A
+}
+
+func TestFillRect(t *testing.T) {
+	c := NewCanvas(10, 10)
+	var p Path
+	p.Rect(2, 2, 6, 6)
+	c.Fill(&p, red)
+
+	if got := c.Image().RGBAAt(5, 5); got != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("inside = %v, want opaque red", got)
+	}
+	for _, pt := range []image.Point{{0, 0}, {1, 5}, {8, 5}, {5, 9}} {
+		if a := alphaAt(c, pt.X, pt.Y); a != 0 {
+			t.Errorf("outside (%d, %d) has alpha %d", pt.X, pt.Y, a)
+		}
+	}
+}
+
+func TestFillAntiAliased(t *testing.T) {
+	c := NewCanvas(10, 10)
+	var p Path
+	p.Rect(2.5, 0, 5, 10)
+	c.Fill(&p, red)
+
+	// The edges cover half of their pixels.
+	for _, x := range []int{2, 7} {
+		if a := alphaAt(c, x, 5); a < 120 || a > 135 {
+			t.Errorf("edge pixel %d has alpha %d, want about 128", x, a)
+		}
+	}
+	if a := alphaAt(c, 5, 5); a != 255 {
+		t.Errorf("inner pixel has alpha %d, want 255", a)
+	}
+}
+
+func TestFillNonZero(t *testing.T) {
+	c := NewCanvas(20, 10)
+	var p Path
+	// Overlapping rectangles wound the same way stay filled.
+	p.Rect(0, 0, 8, 10)
+	p.Rect(4, 0, 8, 10)
+	// A rectangle wound the other way cuts a hole.
+	p.Rect(14, 0, 6, 10)
+	p.MoveTo(16, 2)
+	p.LineTo(16, 8)
+	p.LineTo(18, 8)
+	p.LineTo(18, 2)
+	p.Close()
+	c.Fill(&p, red)
+
+	if a := alphaAt(c, 6, 5); a != 255 {
+		t.Errorf("overlap has alpha %d, want 255", a)
+	}
+	if a := alphaAt(c, 17, 5); a != 0 {
+		t.Errorf("hole has alpha %d, want 0", a)
+	}
+	if a := alphaAt(c, 15, 5); a != 255 {
+		t.Errorf("ring has alpha %d, want 255", a)
+	}
+}
+
+func TestFillCurve(t *testing.T) {
+	c := NewCanvas(20, 20)
+	var p Path
+	// A circle of radius 8 around (10, 10) made of four cubic arcs.
+	const k = 0.5522847498 * 8
+	p.MoveTo(18, 10)
+	p.CubicTo(18, 10+k, 10+k, 18, 10, 18)
+	p.CubicTo(10-k, 18, 2, 10+k, 2, 10)
+	p.CubicTo(2, 10-k, 10-k, 2, 10, 2)
+	p.CubicTo(10+k, 2, 18, 10-k, 18, 10)
+	p.Close()
+	c.Fill(&p, red)
+
+	// The covered area approximates the circle's. Flattening cuts off a
+	// little of it.
+	total := 0.0
+	for y := range 20 {
+		for x := range 20 {
+			total += float64(alphaAt(c, x, y)) / 255
+		}
+	}
+	if want := math.Pi * 64; math.Abs(total-want) > 0.02*want {
+		t.Errorf("covered area = %.2f, want %.2f", total, want)
+	}
+	if a := alphaAt(c, 3, 3); a != 0 {
+		t.Errorf("corner outside the circle has alpha %d", a)
+	}
+}
+
+func TestFillOutsideCanvas(t *testing.T) {
+	c := NewCanvas(10, 10)
+	var p Path
+	// A triangle much larger than the canvas, whose long side crosses it
+	// along x + y = 10, still covers it correctly.
+	p.MoveTo(-1000, -1000)
+	p.LineTo(1010, -1000)
+	p.LineTo(-1000, 1010)
+	p.Close()
+	c.Fill(&p, red)
+
+	if a := alphaAt(c, 2, 5); a != 255 {
+		t.Errorf("pixel inside has alpha %d, want 255", a)
+	}
+	if a := alphaAt(c, 8, 8); a != 0 {
+		t.Errorf("pixel beyond the long side has alpha %d, want 0", a)
+	}
+	if a := alphaAt(c, 4, 5); a < 120 || a > 135 {
+		t.Errorf("pixel on the long side has alpha %d, want about 128", a)
+	}
+
+	c = NewCanvas(10, 10)
+	p = Path{}
+	p.Rect(20, 20, 5, 5)
+	c.Fill(&p, red)
+	if a := alphaAt(c, 9, 9); a != 0 {
+		t.Errorf("shape outside the canvas drew alpha %d", a)
+	}
+}
+
+func TestStrokeCaps(t *testing.T) {
+	tests := []struct {
+		cap  LineCap
+		want uint8
+	}{
+		{ButtCap, 0},
+		{SquareCap, 255},
+		{RoundCap, 255},
+	}
+	for _, tt := range tests {
+		c := NewCanvas(20, 10)
+		var p Path
+		p.MoveTo(5, 5)
+		p.LineTo(15, 5)
+		c.Stroke(&p, Stroke{Width: 4, Cap: tt.cap}, red)
+
+		if a := alphaAt(c, 10, 5); a != 255 {
+			t.Errorf("cap %d: line has alpha %d, want 255", tt.cap, a)
+		}
+		// The pixel just beyond the end point.
+		if a := alphaAt(c, 15, 5); a != tt.want {
+			t.Errorf("cap %d: end has alpha %d, want %d", tt.cap, a, tt.want)
+		}
+	}
+}
+
+func TestStrokeJoins(t *testing.T) {
+	tests := []struct {
+		join LineJoin
+		want bool
+	}{
+		{MiterJoin, true},
+		{BevelJoin, false},
+		{RoundJoin, false},
+	}
+	for _, tt := range tests {
+		c := NewCanvas(20, 20)
+		var p Path
+		p.MoveTo(2, 10)
+		p.LineTo(10, 10)
+		p.LineTo(10, 18)
+		c.Stroke(&p, Stroke{Width: 6, Join: tt.join, MiterLimit: 4}, red)
+
+		// The outer corner of the square turn is only covered by a miter.
+		if covered := alphaAt(c, 12, 7) == 255; covered != tt.want {
+			t.Errorf("join %d: corner covered = %v, want %v", tt.join, covered, tt.want)
+		}
+		if a := alphaAt(c, 10, 10); a != 255 {
+			t.Errorf("join %d: vertex has alpha %d, want 255", tt.join, a)
+		}
+	}
+}
+
+func TestStrokeMiterLimit(t *testing.T) {
+	c := NewCanvas(40, 20)
+	var p Path
+	// A sharp turn whose miter would be far longer than the limit.
+	p.MoveTo(2, 10)
+	p.LineTo(30, 10)
+	p.LineTo(2, 12)
+	c.Stroke(&p, Stroke{Width: 2, Join: MiterJoin, MiterLimit: 2}, red)
+
+	if a := alphaAt(c, 36, 10); a != 0 {
+		t.Errorf("miter beyond the limit has alpha %d, want 0", a)
+	}
+}
+
+func TestStrokeDash(t *testing.T) {
+	c := NewCanvas(20, 4)
+	var p Path
+	p.MoveTo(0, 2)
+	p.LineTo(20, 2)
+	c.Stroke(&p, Stroke{Width: 2, Dash: []float64{4, 2}, DashPhase: 1}, red)
+
+	// With the phase, the pattern starts with 3 drawn, 2 skipped, 4
+	// drawn, and so on.
+	want := "###..####..####..###"
+	for x := range 20 {
+		covered := alphaAt(c, x, 2) == 255
+		if covered != (want[x] == '#') {
+			t.Errorf("pixel %d covered = %v, want pattern %s", x, covered, want)
+		}
+	}
+}
+
+func TestStrokeDashOdd(t *testing.T) {
+	c := NewCanvas(12, 4)
+	var p Path
+	p.MoveTo(0, 2)
+	p.LineTo(12, 2)
+	// A pattern with one entry is drawn and skipped by turns.
+	c.Stroke(&p, Stroke{Width: 2, Dash: []float64{3}}, red)
+
+	want := "###...###..."
+	for x := range 12 {
+		covered := alphaAt(c, x, 2) == 255
+		if covered != (want[x] == '#') {
+			t.Errorf("pixel %d covered = %v, want pattern %s", x, covered, want)
+		}
+	}
+}
+
+func TestStrokeClosedRect(t *testing.T) {
+	c := NewCanvas(20, 20)
+	var p Path
+	p.Rect(4, 4, 12, 12)
+	c.Stroke(&p, Stroke{Width: 2}, red)
+
+	// All corners are joined, including the one where the path closes.
+	for _, pt := range []image.Point{{3, 3}, {16, 3}, {16, 16}, {3, 16}} {
+		if a := alphaAt(c, pt.X, pt.Y); a != 255 {
+			t.Errorf("corner (%d, %d) has alpha %d, want 255", pt.X, pt.Y, a)
+		}
+	}
+	if a := alphaAt(c, 10, 10); a != 0 {
+		t.Errorf("inside has alpha %d, want 0", a)
+	}
+}
+
+func TestClip(t *testing.T) {
+	c := NewCanvas(10, 10)
+	var clip, fill Path
+	clip.Rect(0, 0, 5, 10)
+	fill.Rect(0, 0, 10, 10)
+
+	c.Save()
+	c.Clip(&clip)
+	c.Fill(&fill, red)
+	c.Restore()
+
+	if a := alphaAt(c, 2, 5); a != 255 {
+		t.Errorf("inside the clip has alpha %d, want 255", a)
+	}
+	if a := alphaAt(c, 7, 5); a != 0 {
+		t.Errorf("outside the clip has alpha %d, want 0", a)
+	}
+
+	// The clip is gone after restoring.
+	c.Fill(&fill, red)
+	if a := alphaAt(c, 7, 5); a != 255 {
+		t.Errorf("after restore has alpha %d, want 255", a)
+	}
+}
+
+func TestClipIntersects(t *testing.T) {
+	c := NewCanvas(10, 10)
+	var a, b, fill Path
+	a.Rect(0, 0, 6, 10)
+	b.Rect(4, 0, 6, 10)
+	fill.Rect(0, 0, 10, 10)
+	c.Clip(&a)
+	c.Clip(&b)
+	c.Fill(&fill, red)
+
+	for x, want := range []uint8{0, 0, 0, 0, 255, 255, 0, 0, 0, 0} {
+		if got := alphaAt(c, x, 5); got != want {
+			t.Errorf("pixel %d has alpha %d, want %d", x, got, want)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	c := NewCanvas(20, 20)
+	c.Transform(layout.TranslateTransform(10, 10))
+	c.Transform(layout.RotateTransform(math.Pi / 2))
+	c.Transform(layout.ScaleTransform(2, 2))
+	var p Path
+	// The rectangle spans (0, 0) to (4, 1), which turns into (-2, 0) to
+	// (0, 8) relative to the center.
+	p.Rect(0, 0, 4, 1)
+	c.Fill(&p, red)
+
+	if a := alphaAt(c, 9, 15); a != 255 {
+		t.Errorf("rotated rectangle has alpha %d, want 255", a)
+	}
+	if a := alphaAt(c, 15, 11); a != 0 {
+		t.Errorf("unrotated position has alpha %d, want 0", a)
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	src.Set(0, 0, red)
+	src.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	src.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	src.Set(1, 1, color.NRGBA{A: 255})
+
+	c := NewCanvas(20, 20)
+	c.Transform(layout.TranslateTransform(5, 5))
+	c.DrawImage(src, 10, 10)
+
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{6, 6, color.RGBA{R: 255, A: 255}},
+		{13, 6, color.RGBA{G: 255, A: 255}},
+		{6, 13, color.RGBA{B: 255, A: 255}},
+		{13, 13, color.RGBA{A: 255}},
+		{2, 2, color.RGBA{}},
+		{17, 17, color.RGBA{}},
+	}
+	for _, tt := range tests {
+		if got := c.Image().RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestDrawImageClipped(t *testing.T) {
+	src := image.NewUniform(red)
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := range 4 {
+		for x := range 4 {
+			img.Set(x, y, src.C)
+		}
+	}
+
+	c := NewCanvas(10, 10)
+	var clip Path
+	clip.Rect(0, 0, 5, 10)
+	c.Clip(&clip)
+	c.DrawImage(img, 10, 10)
+
+	if a := alphaAt(c, 2, 5); a != 255 {
+		t.Errorf("inside the clip has alpha %d, want 255", a)
+	}
+	if a := alphaAt(c, 7, 5); a != 0 {
+		t.Errorf("outside the clip has alpha %d, want 0", a)
+	}
+}
+
+func TestDrawGlyph(t *testing.T) {
+	face, err := font.ParseTTF(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gid, ok := face.NominalGlyph('H')
+	if !ok {
+		t.Fatal("font has no glyph for H")
+	}
+
+	c := NewCanvas(40, 40)
+	c.DrawGlyph(face, gid, 30, 5, 32, color.Black)
+
+	// The capital H stands on the baseline: its left stem is filled and
+	// there is nothing below the baseline or between the stems above the
+	// crossbar.
+	covered := 0
+	for y := range 40 {
+		for x := range 40 {
+			if alphaAt(c, x, y) > 0 {
+				covered++
+				if y > 33 {
+					t.Fatalf("pixel (%d, %d) below the baseline is covered", x, y)
+				}
+			}
+		}
+	}
+	if covered == 0 {
+		t.Fatal("glyph drew nothing")
+	}
+	if a := alphaAt(c, 9, 28); a != 255 {
+		t.Errorf("left stem has alpha %d, want 255", a)
+	}
+	if a := alphaAt(c, 15, 14); a != 0 {
+		t.Errorf("counter above the crossbar has alpha %d, want 0", a)
+	}
+}
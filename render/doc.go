@@ -1,5 +1,7 @@
-// Package render provides rendering functionality for Typst documents.
+// Package render rasterizes laid-out documents into images.
 //
-// This package handles the conversion of laid-out documents into
-// rasterized images and other visual formats.
+// RenderPage draws a page into an *image.RGBA, which can be encoded as PNG
+// or compared in tests. Underneath, a Canvas fills and strokes paths with
+// anti-aliasing, draws glyph outlines and images, and keeps a stack of
+// transformations and clip regions.
 package render
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+)
+
+// glyphKey identifies a glyph of a font face.
+type glyphKey struct {
+	face *font.Face
+	id   font.GID
+}
+
+// DrawGlyph fills the outline of a glyph at the given size in user space,
+// with its origin on the baseline at (x, y). Glyphs without an outline,
+// such as bitmap-only emoji, are skipped.
+func (c *Canvas) DrawGlyph(face *font.Face, id font.GID, size, x, y float64, col color.Color) {
+	path := c.glyphPath(face, id)
+	if path == nil || path.IsEmpty() {
+		return
+	}
+	upem := float64(face.Upem())
+	if upem == 0 {
+		return
+	}
+
+	// Outlines are in font units with the y-axis pointing up.
+	c.Save()
+	c.Transform(layout.TranslateTransform(layout.Abs(x), layout.Abs(y)))
+	c.Transform(layout.ScaleTransform(size/upem, -size/upem))
+	c.Fill(path, col)
+	c.Restore()
+}
+
+// glyphPath returns the cached outline of a glyph in font units, or nil if
+// it has none.
+func (c *Canvas) glyphPath(face *font.Face, id font.GID) *Path {
+	key := glyphKey{face: face, id: id}
+	if path, ok := c.glyphs[key]; ok {
+		return path
+	}
+	if c.glyphs == nil {
+		c.glyphs = make(map[glyphKey]*Path)
+	}
+	path := outlinePath(face.GlyphData(id))
+	c.glyphs[key] = path
+	return path
+}
+
+// outlinePath converts the outline of glyph data into a path. Bitmap and
+// SVG glyphs contribute their fallback outlines, if any.
+func outlinePath(data font.GlyphData) *Path {
+	var outline font.GlyphOutline
+	switch data := data.(type) {
+	case font.GlyphOutline:
+		outline = data
+	case font.GlyphSVG:
+		outline = data.Outline
+	case font.GlyphBitmap:
+		if data.Outline == nil {
+			return nil
+		}
+		outline = *data.Outline
+	default:
+		return nil
+	}
+
+	path := &Path{}
+	for _, seg := range outline.Segments {
+		a := seg.Args
+		switch seg.Op {
+		case ot.SegmentOpMoveTo:
+			if !path.IsEmpty() {
+				path.Close()
+			}
+			path.MoveTo(float64(a[0].X), float64(a[0].Y))
+		case ot.SegmentOpLineTo:
+			path.LineTo(float64(a[0].X), float64(a[0].Y))
+		case ot.SegmentOpQuadTo:
+			path.QuadTo(float64(a[0].X), float64(a[0].Y), float64(a[1].X), float64(a[1].Y))
+		case ot.SegmentOpCubeTo:
+			path.CubicTo(float64(a[0].X), float64(a[0].Y), float64(a[1].X), float64(a[1].Y), float64(a[2].X), float64(a[2].Y))
+		}
+	}
+	if !path.IsEmpty() {
+		path.Close()
+	}
+	return path
+}
@@ -0,0 +1,239 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/go-text/typesetting/font"
+)
+
+// RenderPage renders a page into an image with pixelPerPt pixels per
+// point. The page is filled with its fill, or white if it has none.
+func RenderPage(page *layout.Page, pixelPerPt float64) (*image.RGBA, error) {
+	size := page.Frame.Size
+	width := max(1, int(math.Ceil(float64(size.Width)*pixelPerPt)))
+	height := max(1, int(math.Ceil(float64(size.Height)*pixelPerPt)))
+
+	canvas := NewCanvas(width, height)
+	fill := color.Color(color.White)
+	if page.Fill != nil && page.Fill.Color != nil {
+		fill = paintColor(page.Fill)
+	}
+	canvas.Clear(fill)
+	canvas.Transform(layout.ScaleTransform(pixelPerPt, pixelPerPt))
+	if err := RenderFrame(canvas, &page.Frame); err != nil {
+		return nil, err
+	}
+	return canvas.Image(), nil
+}
+
+// RenderFrame draws the contents of a frame onto the canvas, with the
+// frame's top-left corner at the origin of user space.
+func RenderFrame(canvas *Canvas, frame *layout.Frame) error {
+	for _, positioned := range frame.Items {
+		x, y := float64(positioned.Pos.X), float64(positioned.Pos.Y)
+		var err error
+		switch item := positioned.Item.(type) {
+		case layout.GroupItem:
+			err = renderGroup(canvas, item, x, y)
+		case layout.ShapeItem:
+			renderShape(canvas, item, x, y)
+		case layout.ShapedTextItem:
+			renderShapedText(canvas, item, x, y)
+		case layout.ImageItem:
+			err = renderImage(canvas, item, x, y)
+		}
+		// Tags and links don't draw anything. Plain text items carry no
+		// font, so there are no glyphs to draw for them.
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderGroup draws a nested frame with its transformation and clip.
+func renderGroup(canvas *Canvas, group layout.GroupItem, x, y float64) error {
+	canvas.Save()
+	defer canvas.Restore()
+	canvas.Transform(layout.TranslateTransform(layout.Abs(x), layout.Abs(y)))
+	if t := group.Transform; t != nil {
+		canvas.Transform(*t)
+	}
+	if group.Clip != nil {
+		canvas.Clip(curvePath(group.Clip))
+	}
+	return RenderFrame(canvas, &group.Frame)
+}
+
+// renderShape fills and strokes a shape. Lines have no interior and are
+// only stroked.
+func renderShape(canvas *Canvas, shape layout.ShapeItem, x, y float64) {
+	var path *Path
+	filled := true
+	switch geometry := shape.Geometry.(type) {
+	case layout.LineGeometry:
+		path = &Path{}
+		path.MoveTo(0, 0)
+		path.LineTo(float64(geometry.To.X), float64(geometry.To.Y))
+		filled = false
+	case layout.RectGeometry:
+		path = curvePath(layout.RectCurve(geometry.Size))
+	case layout.CurveGeometry:
+		path = curvePath(geometry.Curve)
+	default:
+		return
+	}
+
+	canvas.Save()
+	defer canvas.Restore()
+	canvas.Transform(layout.TranslateTransform(layout.Abs(x), layout.Abs(y)))
+	if filled && shape.Fill != nil && shape.Fill.Color != nil {
+		canvas.Fill(path, paintColor(shape.Fill))
+	}
+	if s := shape.Stroke; s != nil && s.Paint.Color != nil {
+		canvas.Stroke(path, convertStroke(s), paintColor(&s.Paint))
+	}
+}
+
+// curvePath converts a curve into a path.
+func curvePath(curve layout.Curve) *Path {
+	path := &Path{}
+	for _, item := range curve {
+		switch item := item.(type) {
+		case layout.CurveMove:
+			path.MoveTo(float64(item.To.X), float64(item.To.Y))
+		case layout.CurveLine:
+			path.LineTo(float64(item.To.X), float64(item.To.Y))
+		case layout.CurveCubic:
+			path.CubicTo(
+				float64(item.Control1.X), float64(item.Control1.Y),
+				float64(item.Control2.X), float64(item.Control2.Y),
+				float64(item.To.X), float64(item.To.Y),
+			)
+		case layout.CurveClose:
+			path.Close()
+		}
+	}
+	return path
+}
+
+// convertStroke converts a shape's stroke into the rasterizer's.
+func convertStroke(s *layout.FixedStroke) Stroke {
+	stroke := Stroke{
+		Width:      float64(s.Thickness),
+		MiterLimit: s.MiterLimit,
+	}
+	switch s.Cap {
+	case layout.LineCapRound:
+		stroke.Cap = RoundCap
+	case layout.LineCapSquare:
+		stroke.Cap = SquareCap
+	}
+	switch s.Join {
+	case layout.LineJoinRound:
+		stroke.Join = RoundJoin
+	case layout.LineJoinBevel:
+		stroke.Join = BevelJoin
+	}
+	if s.Dash != nil {
+		for _, length := range s.Dash.Array {
+			stroke.Dash = append(stroke.Dash, float64(length))
+		}
+		stroke.DashPhase = float64(s.Dash.Phase)
+	}
+	return stroke
+}
+
+// renderShapedText draws shaped glyphs with their origin on the baseline
+// at (x, y). Glyphs whose font isn't a font face are skipped.
+func renderShapedText(canvas *Canvas, text layout.ShapedTextItem, x, y float64) {
+	size := float64(text.FontSize)
+	col := color.Color(color.Black)
+	if text.Fill != nil && text.Fill.Color != nil {
+		col = paintColor(text.Fill)
+	}
+	for _, g := range text.Glyphs {
+		if face, ok := g.Font.(*font.Face); ok && face != nil {
+			canvas.DrawGlyph(face, font.GID(g.GlyphID), size, x+g.XOffset*size, y-g.YOffset*size, col)
+		}
+		x += g.XAdvance * size
+	}
+}
+
+// renderImage draws an image item scaled to its size.
+func renderImage(canvas *Canvas, item layout.ImageItem, x, y float64) error {
+	img, err := decodeImage(&item.Image)
+	if err != nil {
+		return err
+	}
+	canvas.Save()
+	defer canvas.Restore()
+	canvas.Transform(layout.TranslateTransform(layout.Abs(x), layout.Abs(y)))
+	canvas.DrawImage(img, float64(item.Size.Width), float64(item.Size.Height))
+	return nil
+}
+
+// decodeImage decodes the pixels of an image.
+func decodeImage(img *layout.Image) (image.Image, error) {
+	switch img.Format {
+	case layout.ImageFormatJPEG:
+		return jpeg.Decode(bytes.NewReader(img.Data))
+	case layout.ImageFormatPNG:
+		return png.Decode(bytes.NewReader(img.Data))
+	case layout.ImageFormatRaw:
+		return decodeRawImage(img)
+	default:
+		return nil, errors.New("unsupported image format")
+	}
+}
+
+// decodeRawImage interprets uncompressed 8-bit samples in the image's
+// color space, with the optional alpha channel stored separately.
+func decodeRawImage(img *layout.Image) (image.Image, error) {
+	channels := 3
+	switch img.ColorSpace {
+	case layout.ColorSpaceDeviceGray:
+		channels = 1
+	case layout.ColorSpaceDeviceCMYK:
+		channels = 4
+	}
+	pixels := img.Width * img.Height
+	if img.BitsPerComponent != 8 || len(img.Data) < pixels*channels {
+		return nil, fmt.Errorf("raw image data does not match its %dx%d size", img.Width, img.Height)
+	}
+	hasAlpha := len(img.Alpha) >= pixels
+
+	out := image.NewNRGBA(image.Rect(0, 0, img.Width, img.Height))
+	for i := range pixels {
+		s := img.Data[i*channels : (i+1)*channels]
+		var c color.NRGBA
+		switch channels {
+		case 1:
+			c = color.NRGBA{s[0], s[0], s[0], 255}
+		case 3:
+			c = color.NRGBA{s[0], s[1], s[2], 255}
+		case 4:
+			r, g, b := color.CMYKToRGB(s[0], s[1], s[2], s[3])
+			c = color.NRGBA{r, g, b, 255}
+		}
+		if hasAlpha {
+			c.A = img.Alpha[i]
+		}
+		out.Pix[i*4], out.Pix[i*4+1], out.Pix[i*4+2], out.Pix[i*4+3] = c.R, c.G, c.B, c.A
+	}
+	return out, nil
+}
+
+// paintColor returns the color of a paint.
+func paintColor(paint *layout.Paint) color.Color {
+	c := paint.Color
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
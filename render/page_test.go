@@ -0,0 +1,230 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/boergens/gotypst/layout"
+	"github.com/go-text/typesetting/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+var (
+	opaqueRed   = color.RGBA{R: 255, A: 255}
+	opaqueWhite = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+func redPaint() *layout.Paint {
+	return &layout.Paint{Color: &layout.Color{R: 255, A: 255}}
+}
+
+func TestRenderPageSize(t *testing.T) {
+	page := &layout.Page{Frame: layout.Frame{Size: layout.Size{Width: 10, Height: 20.5}}}
+	img, err := RenderPage(page, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got.X != 20 || got.Y != 41 {
+		t.Errorf("size = %v, want 20x41", got)
+	}
+	if got := img.RGBAAt(5, 5); got != opaqueWhite {
+		t.Errorf("background = %v, want white", got)
+	}
+}
+
+func TestRenderPageFill(t *testing.T) {
+	page := &layout.Page{Frame: layout.Frame{Size: layout.Size{Width: 10, Height: 10}}, Fill: redPaint()}
+	img, err := RenderPage(page, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.RGBAAt(5, 5); got != opaqueRed {
+		t.Errorf("background = %v, want red", got)
+	}
+}
+
+func TestRenderShape(t *testing.T) {
+	frame := layout.Frame{Size: layout.Size{Width: 20, Height: 20}}
+	frame.Push(layout.Point{X: 5, Y: 5}, layout.ShapeItem{
+		Geometry: layout.RectGeometry{Size: layout.Size{Width: 10, Height: 10}},
+		Fill:     redPaint(),
+		Stroke: &layout.FixedStroke{
+			Paint:     layout.Paint{Color: &layout.Color{B: 255, A: 255}},
+			Thickness: 2,
+		},
+	})
+	img, err := RenderPage(&layout.Page{Frame: frame}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := img.RGBAAt(10, 10); got != opaqueRed {
+		t.Errorf("fill = %v, want red", got)
+	}
+	// The stroke is centered on the outline, above the fill.
+	if got := img.RGBAAt(4, 10); got != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("stroke outside = %v, want blue", got)
+	}
+	if got := img.RGBAAt(5, 10); got != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("stroke inside = %v, want blue", got)
+	}
+	if got := img.RGBAAt(2, 10); got != opaqueWhite {
+		t.Errorf("outside = %v, want white", got)
+	}
+}
+
+func TestRenderDashedLine(t *testing.T) {
+	frame := layout.Frame{Size: layout.Size{Width: 20, Height: 4}}
+	frame.Push(layout.Point{Y: 2}, layout.ShapeItem{
+		Geometry: layout.LineGeometry{To: layout.Point{X: 20}},
+		// Lines are never filled.
+		Fill: redPaint(),
+		Stroke: &layout.FixedStroke{
+			Paint:     *redPaint(),
+			Thickness: 2,
+			Dash:      &layout.DashPattern{Array: []layout.Abs{5, 5}},
+		},
+	})
+	img, err := RenderPage(&layout.Page{Frame: frame}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x, want := range map[int]color.RGBA{2: opaqueRed, 7: opaqueWhite, 12: opaqueRed, 17: opaqueWhite} {
+		if got := img.RGBAAt(x, 2); got != want {
+			t.Errorf("pixel %d = %v, want %v", x, got, want)
+		}
+	}
+	if got := img.RGBAAt(2, 0); got != opaqueWhite {
+		t.Errorf("above the line = %v, want white", got)
+	}
+}
+
+func TestRenderGroupTransformAndClip(t *testing.T) {
+	inner := layout.Frame{Size: layout.Size{Width: 10, Height: 10}}
+	inner.Push(layout.Point{}, layout.ShapeItem{
+		Geometry: layout.RectGeometry{Size: layout.Size{Width: 10, Height: 10}},
+		Fill:     redPaint(),
+	})
+	transform := layout.ScaleTransform(2, 1)
+	frame := layout.Frame{Size: layout.Size{Width: 40, Height: 20}}
+	frame.Push(layout.Point{X: 5, Y: 5}, layout.GroupItem{
+		Frame:     inner,
+		Transform: &transform,
+		// The clip is in the frame's coordinates, so it is scaled, too.
+		Clip: layout.RectCurve(layout.Size{Width: 5, Height: 10}),
+	})
+	img, err := RenderPage(&layout.Page{Frame: frame}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		x    int
+		want color.RGBA
+	}{
+		{4, opaqueWhite},
+		{6, opaqueRed},
+		{14, opaqueRed},
+		{16, opaqueWhite},
+		{24, opaqueWhite},
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, 10); got != tt.want {
+			t.Errorf("pixel %d = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestRenderRawImage(t *testing.T) {
+	frame := layout.Frame{Size: layout.Size{Width: 10, Height: 10}}
+	frame.Push(layout.Point{}, layout.ImageItem{
+		Image: layout.Image{
+			Data:             []byte{0, 0, 255, 0, 0, 255, 0, 0, 255, 0, 0, 255},
+			Format:           layout.ImageFormatRaw,
+			Width:            2,
+			Height:           2,
+			BitsPerComponent: 8,
+			ColorSpace:       layout.ColorSpaceDeviceRGB,
+			Alpha:            []byte{255, 0, 255, 0},
+		},
+		Size: layout.Size{Width: 10, Height: 10},
+	})
+	img, err := RenderPage(&layout.Page{Frame: frame}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.RGBAAt(1, 1); got != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("opaque pixel = %v, want blue", got)
+	}
+	if got := img.RGBAAt(8, 1); got != opaqueWhite {
+		t.Errorf("transparent pixel = %v, want white", got)
+	}
+}
+
+func TestRenderInvalidImage(t *testing.T) {
+	frame := layout.Frame{Size: layout.Size{Width: 10, Height: 10}}
+	frame.Push(layout.Point{}, layout.ImageItem{
+		Image: layout.Image{Data: []byte("not a png"), Format: layout.ImageFormatPNG},
+		Size:  layout.Size{Width: 10, Height: 10},
+	})
+	if _, err := RenderPage(&layout.Page{Frame: frame}, 1); err == nil {
+		t.Error("expected an error for undecodable image data")
+	}
+}
+
+func TestRenderShapedText(t *testing.T) {
+	face, err := font.ParseTTF(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var glyphs []layout.ShapedGlyph
+	for _, r := range "II" {
+		gid, _ := face.NominalGlyph(r)
+		advance := float64(face.HorizontalAdvance(gid)) / float64(face.Upem())
+		glyphs = append(glyphs, layout.ShapedGlyph{GlyphID: uint16(gid), XAdvance: advance, Char: r, Font: face})
+	}
+
+	frame := layout.Frame{Size: layout.Size{Width: 40, Height: 40}}
+	frame.Push(layout.Point{X: 5, Y: 30}, layout.ShapedTextItem{Glyphs: glyphs, FontSize: 20, Fill: redPaint()})
+	img, err := RenderPage(&layout.Page{Frame: frame}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each capital I is a single stem standing on the baseline, so the
+	// middle row crosses two red stems.
+	stems, inStem := 0, false
+	for x := range 40 {
+		c := img.RGBAAt(x, 22)
+		red := c.R == 255 && c.G < 128
+		if red && !inStem {
+			stems++
+		}
+		inStem = red
+	}
+	if stems != 2 {
+		t.Errorf("found %d stems, want 2", stems)
+	}
+	for x := range 40 {
+		if got := img.RGBAAt(x, 33); got != opaqueWhite {
+			t.Fatalf("pixel (%d, 33) below the baseline = %v", x, got)
+		}
+	}
+}
+
+func TestConvertStroke(t *testing.T) {
+	stroke := convertStroke(&layout.FixedStroke{
+		Thickness: 3,
+		Cap:       layout.LineCapRound,
+		Join:      layout.LineJoinBevel,
+		Dash:      &layout.DashPattern{Array: []layout.Abs{1, 2}, Phase: 0.5},
+	})
+	if stroke.Width != 3 || stroke.Cap != RoundCap || stroke.Join != BevelJoin {
+		t.Errorf("stroke = %+v", stroke)
+	}
+	if len(stroke.Dash) != 2 || stroke.Dash[1] != 2 || math.Abs(stroke.DashPhase-0.5) > 1e-9 {
+		t.Errorf("dash = %v phase %v", stroke.Dash, stroke.DashPhase)
+	}
+}
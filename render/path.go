@@ -0,0 +1,201 @@
+package render
+
+import (
+	"math"
+
+	"github.com/boergens/gotypst/layout"
+)
+
+// Point is a position in user or device space.
+type Point struct {
+	X, Y float64
+}
+
+func (p Point) add(q Point) Point     { return Point{p.X + q.X, p.Y + q.Y} }
+func (p Point) sub(q Point) Point     { return Point{p.X - q.X, p.Y - q.Y} }
+func (p Point) mul(s float64) Point   { return Point{p.X * s, p.Y * s} }
+func (p Point) dot(q Point) float64   { return p.X*q.X + p.Y*q.Y }
+func (p Point) cross(q Point) float64 { return p.X*q.Y - p.Y*q.X }
+func (p Point) length() float64       { return math.Hypot(p.X, p.Y) }
+func (p Point) lerp(q Point, t float64) Point {
+	return Point{p.X + (q.X-p.X)*t, p.Y + (q.Y-p.Y)*t}
+}
+
+// apply maps a point through a transformation.
+func apply(t layout.Transform, p Point) Point {
+	return Point{
+		X: t.Sx*p.X + t.Kx*p.Y + float64(t.Tx),
+		Y: t.Ky*p.X + t.Sy*p.Y + float64(t.Ty),
+	}
+}
+
+// verb is the kind of a path segment.
+type verb uint8
+
+const (
+	verbMove verb = iota
+	verbLine
+	verbQuad
+	verbCubic
+	verbClose
+)
+
+// Path is an outline made of lines and quadratic and cubic Bézier
+// curves. It may contain multiple subpaths.
+type Path struct {
+	verbs  []verb
+	points []Point
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x, y float64) {
+	p.verbs = append(p.verbs, verbMove)
+	p.points = append(p.points, Point{x, y})
+}
+
+// LineTo draws a straight line to (x, y).
+func (p *Path) LineTo(x, y float64) {
+	p.verbs = append(p.verbs, verbLine)
+	p.points = append(p.points, Point{x, y})
+}
+
+// QuadTo draws a quadratic Bézier curve with control point (cx, cy) to
+// (x, y).
+func (p *Path) QuadTo(cx, cy, x, y float64) {
+	p.verbs = append(p.verbs, verbQuad)
+	p.points = append(p.points, Point{cx, cy}, Point{x, y})
+}
+
+// CubicTo draws a cubic Bézier curve with control points (c1x, c1y) and
+// (c2x, c2y) to (x, y).
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float64) {
+	p.verbs = append(p.verbs, verbCubic)
+	p.points = append(p.points, Point{c1x, c1y}, Point{c2x, c2y}, Point{x, y})
+}
+
+// Close closes the current subpath with a line to its start.
+func (p *Path) Close() {
+	p.verbs = append(p.verbs, verbClose)
+}
+
+// Rect appends a closed rectangle with its top-left corner at (x, y).
+func (p *Path) Rect(x, y, width, height float64) {
+	p.MoveTo(x, y)
+	p.LineTo(x+width, y)
+	p.LineTo(x+width, y+height)
+	p.LineTo(x, y+height)
+	p.Close()
+}
+
+// IsEmpty reports whether the path has no segments.
+func (p *Path) IsEmpty() bool {
+	return len(p.verbs) == 0
+}
+
+// Transform returns the path with all points mapped through t.
+func (p *Path) Transform(t layout.Transform) Path {
+	out := Path{verbs: p.verbs, points: make([]Point, len(p.points))}
+	for i, pt := range p.points {
+		out.points[i] = apply(t, pt)
+	}
+	return out
+}
+
+// polyline is a flattened subpath.
+type polyline struct {
+	points []Point
+	closed bool
+}
+
+// maxSubdivisions limits how many lines a single curve is flattened into.
+const maxSubdivisions = 256
+
+// flatten approximates the path's curves with lines that deviate from
+// them by at most tolerance.
+func (p *Path) flatten(tolerance float64) []polyline {
+	var lines []polyline
+	var current polyline
+	finish := func() {
+		if len(current.points) > 0 {
+			lines = append(lines, current)
+		}
+		current = polyline{}
+	}
+	last := func() Point {
+		if len(current.points) == 0 {
+			return Point{}
+		}
+		return current.points[len(current.points)-1]
+	}
+
+	i := 0
+	for _, v := range p.verbs {
+		switch v {
+		case verbMove:
+			finish()
+			current.points = append(current.points, p.points[i])
+			i++
+		case verbLine:
+			if len(current.points) == 0 {
+				current.points = append(current.points, Point{})
+			}
+			current.points = append(current.points, p.points[i])
+			i++
+		case verbQuad:
+			p0, p1, p2 := last(), p.points[i], p.points[i+1]
+			if len(current.points) == 0 {
+				current.points = append(current.points, p0)
+			}
+			n := subdivisions(math.Sqrt(p0.sub(p1.mul(2)).add(p2).length() / (8 * tolerance)))
+			for k := 1; k <= n; k++ {
+				t := float64(k) / float64(n)
+				current.points = append(current.points, p0.lerp(p1, t).lerp(p1.lerp(p2, t), t))
+			}
+			i += 2
+		case verbCubic:
+			p0, p1, p2, p3 := last(), p.points[i], p.points[i+1], p.points[i+2]
+			if len(current.points) == 0 {
+				current.points = append(current.points, p0)
+			}
+			dd := math.Max(
+				p0.sub(p1.mul(2)).add(p2).length(),
+				p1.sub(p2.mul(2)).add(p3).length(),
+			)
+			n := subdivisions(math.Sqrt(3 * dd / (4 * tolerance)))
+			for k := 1; k <= n; k++ {
+				t := float64(k) / float64(n)
+				a, b, c := p0.lerp(p1, t), p1.lerp(p2, t), p2.lerp(p3, t)
+				current.points = append(current.points, a.lerp(b, t).lerp(b.lerp(c, t), t))
+			}
+			i += 3
+		case verbClose:
+			if len(current.points) > 0 {
+				start := current.points[0]
+				current.closed = true
+				finish()
+				// A segment after a close starts at the closed subpath's
+				// start.
+				current.points = append(current.points, start)
+				current.closed = false
+			}
+		}
+	}
+	finish()
+
+	// Drop subpaths that consist of only their start point.
+	out := lines[:0]
+	for _, line := range lines {
+		if len(line.points) > 1 || line.closed {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// subdivisions clamps the number of lines a curve is flattened into.
+func subdivisions(n float64) int {
+	if math.IsNaN(n) || n < 1 {
+		return 1
+	}
+	return int(math.Min(math.Ceil(n), maxSubdivisions))
+}
@@ -0,0 +1,299 @@
+package render
+
+import "math"
+
+// Stroke describes how the outline of a path is stroked.
+type Stroke struct {
+	// Width is the thickness of the stroke in user space.
+	Width float64
+	// Cap is the shape of the ends of open subpaths and dashes.
+	Cap LineCap
+	// Join is the shape of the corners between segments.
+	Join LineJoin
+	// MiterLimit is the ratio of miter length to width beyond which miter
+	// joins are beveled instead. If zero, the limit is 4.
+	MiterLimit float64
+	// Dash alternates between drawn and skipped lengths, starting with a
+	// drawn one. A nil or all-zero pattern strokes solid lines.
+	Dash []float64
+	// DashPhase is how far into the dash pattern the stroke starts.
+	DashPhase float64
+}
+
+// defaultMiterLimit is the miter limit of strokes that don't set one.
+const defaultMiterLimit = 4
+
+// LineCap is the shape of the ends of stroked lines.
+type LineCap int
+
+const (
+	// ButtCap ends the stroke exactly at the end point.
+	ButtCap LineCap = iota
+	// RoundCap ends the stroke with a half circle.
+	RoundCap
+	// SquareCap extends the stroke by half its width.
+	SquareCap
+)
+
+// LineJoin is the shape of the corners of stroked lines.
+type LineJoin int
+
+const (
+	// MiterJoin extends the outer edges until they meet.
+	MiterJoin LineJoin = iota
+	// RoundJoin rounds the corner with a circle.
+	RoundJoin
+	// BevelJoin cuts the corner off.
+	BevelJoin
+)
+
+// strokePolygons returns polygons whose union is the outline of the
+// stroked lines. Every polygon winds the same way, so that the rasterizer
+// adds up their coverage instead of cancelling overlaps.
+func strokePolygons(lines []polyline, s Stroke, tolerance float64) [][]Point {
+	hw := s.Width / 2
+	if !(hw > 0) {
+		return nil
+	}
+	if dashed, ok := dashLines(lines, s.Dash, s.DashPhase); ok {
+		lines = dashed
+	}
+
+	var polys [][]Point
+	add := func(poly ...Point) {
+		polys = append(polys, orient(poly))
+	}
+
+	for _, line := range lines {
+		pts := dedupe(line.points)
+		closed := line.closed && len(pts) > 2
+		if closed && pts[0].sub(pts[len(pts)-1]).length() < 1e-9 {
+			pts = pts[:len(pts)-1]
+		}
+		n := len(pts)
+
+		// A subpath without length still shows up as a dot with round
+		// and square caps.
+		if n == 1 {
+			switch s.Cap {
+			case RoundCap:
+				add(circle(pts[0], hw, tolerance)...)
+			case SquareCap:
+				p := pts[0]
+				add(Point{p.X - hw, p.Y - hw}, Point{p.X + hw, p.Y - hw}, Point{p.X + hw, p.Y + hw}, Point{p.X - hw, p.Y + hw})
+			}
+			continue
+		}
+
+		segments := n - 1
+		if closed {
+			segments = n
+		}
+		for i := 0; i < segments; i++ {
+			a, b := pts[i], pts[(i+1)%n]
+			normal := perp(unit(b.sub(a))).mul(hw)
+			add(a.add(normal), b.add(normal), b.sub(normal), a.sub(normal))
+		}
+
+		for i := 0; i < n; i++ {
+			if !closed && (i == 0 || i == n-1) {
+				continue
+			}
+			prev, next := pts[(i+n-1)%n], pts[(i+1)%n]
+			polys = appendJoin(polys, prev, pts[i], next, s, hw, tolerance)
+		}
+
+		if !closed {
+			polys = appendCap(polys, pts[0], unit(pts[0].sub(pts[1])), s.Cap, hw, tolerance)
+			polys = appendCap(polys, pts[n-1], unit(pts[n-1].sub(pts[n-2])), s.Cap, hw, tolerance)
+		}
+	}
+	return polys
+}
+
+// appendJoin appends the join at vertex v between the segments from p and
+// to q.
+func appendJoin(polys [][]Point, p, v, q Point, s Stroke, hw, tolerance float64) [][]Point {
+	d0, d1 := unit(v.sub(p)), unit(q.sub(v))
+	cross, dot := d0.cross(d1), d0.dot(d1)
+	if math.Abs(cross) < 1e-9 && dot > 0 {
+		// The segments continue in a straight line.
+		return polys
+	}
+	if s.Join == RoundJoin {
+		return append(polys, orient(circle(v, hw, tolerance)))
+	}
+
+	// The join fills the gap on the outer side of the turn, which is
+	// opposite to the direction the path turns to.
+	side := hw
+	if cross > 0 {
+		side = -hw
+	}
+	n0, n1 := perp(d0), perp(d1)
+	a, b := v.add(n0.mul(side)), v.add(n1.mul(side))
+
+	if s.Join == MiterJoin && 1+dot > 1e-12 {
+		limit := s.MiterLimit
+		if limit == 0 {
+			limit = defaultMiterLimit
+		}
+		// The miter length relative to the width is 1/sin(θ/2) for the
+		// angle θ between the segments.
+		if ratio := math.Sqrt(2 / (1 + dot)); ratio <= limit {
+			tip := v.add(unit(n0.add(n1)).mul(side * ratio))
+			return append(polys, orient([]Point{v, a, tip, b}))
+		}
+	}
+	return append(polys, orient([]Point{v, a, b}))
+}
+
+// appendCap appends the cap at the end point p of an open line, where dir
+// points away from the line.
+func appendCap(polys [][]Point, p, dir Point, cap LineCap, hw, tolerance float64) [][]Point {
+	switch cap {
+	case RoundCap:
+		return append(polys, orient(circle(p, hw, tolerance)))
+	case SquareCap:
+		normal := perp(dir).mul(hw)
+		ext := dir.mul(hw)
+		return append(polys, orient([]Point{p.add(normal), p.add(normal).add(ext), p.sub(normal).add(ext), p.sub(normal)}))
+	}
+	return polys
+}
+
+// dashLines splits the lines into dashes. It reports false if the
+// pattern is empty, adds up to zero, or has negative lengths, in which case
+// the lines are stroked solid.
+func dashLines(lines []polyline, pattern []float64, phase float64) ([]polyline, bool) {
+	total := 0.0
+	for _, length := range pattern {
+		if length < 0 || math.IsNaN(length) {
+			return nil, false
+		}
+		total += length
+	}
+	if total <= 0 || math.IsInf(total, 0) {
+		return nil, false
+	}
+	// A pattern with an odd number of entries repeats with dashes and
+	// gaps swapped.
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern[:len(pattern):len(pattern)], pattern...)
+		total *= 2
+	}
+
+	var out []polyline
+	for _, line := range lines {
+		pts := line.points
+		if line.closed && len(pts) > 0 {
+			pts = append(pts[:len(pts):len(pts)], pts[0])
+		}
+
+		// Find where in the pattern the line starts.
+		index, remaining := 0, pattern[0]
+		offset := math.Mod(phase, total)
+		if offset < 0 {
+			offset += total
+		}
+		for offset > 0 {
+			if offset >= remaining {
+				offset -= remaining
+				index = (index + 1) % len(pattern)
+				remaining = pattern[index]
+			} else {
+				remaining -= offset
+				offset = 0
+			}
+		}
+
+		on := index%2 == 0
+		var dash []Point
+		if on && len(pts) > 0 {
+			dash = []Point{pts[0]}
+		}
+		for i := 0; i+1 < len(pts); i++ {
+			a, b := pts[i], pts[i+1]
+			length := b.sub(a).length()
+			pos := 0.0
+			for length-pos > remaining {
+				pos += remaining
+				pt := a.lerp(b, pos/length)
+				if on {
+					out = append(out, polyline{points: append(dash, pt)})
+					dash = nil
+				} else {
+					dash = []Point{pt}
+				}
+				on = !on
+				index = (index + 1) % len(pattern)
+				remaining = pattern[index]
+			}
+			remaining -= length - pos
+			if on {
+				dash = append(dash, b)
+			}
+		}
+		if on && len(dash) > 1 {
+			out = append(out, polyline{points: dash})
+		}
+	}
+	return out, true
+}
+
+// circle approximates a circle with a polygon that deviates from it by at
+// most tolerance.
+func circle(center Point, radius, tolerance float64) []Point {
+	n := 8
+	if tolerance < radius {
+		n = max(n, int(math.Ceil(math.Pi/math.Acos(1-tolerance/radius))))
+	}
+	n = min(n, maxSubdivisions)
+	poly := make([]Point, n)
+	for i := range poly {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		poly[i] = Point{center.X + radius*cos, center.Y + radius*sin}
+	}
+	return poly
+}
+
+// orient reverses the polygon if it winds counter-clockwise in a y-down
+// coordinate system, so that all polygons wind the same way.
+func orient(poly []Point) []Point {
+	area := 0.0
+	for i := range poly {
+		area += poly[i].cross(poly[(i+1)%len(poly)])
+	}
+	if area < 0 {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+	return poly
+}
+
+// dedupe removes consecutive points that coincide.
+func dedupe(pts []Point) []Point {
+	out := make([]Point, 0, len(pts))
+	for _, p := range pts {
+		if len(out) > 0 && p.sub(out[len(out)-1]).length() < 1e-9 {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// unit returns the vector scaled to length one.
+func unit(p Point) Point {
+	length := p.length()
+	if length == 0 {
+		return Point{}
+	}
+	return p.mul(1 / length)
+}
+
+// perp returns the vector rotated by a quarter turn.
+func perp(p Point) Point {
+	return Point{-p.Y, p.X}
+}
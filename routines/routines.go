@@ -0,0 +1,22 @@
+// Package routines combines the routines of the compiler's subsystems:
+// evaluation, realization, and layout. Each subsystem implements its part
+// of foundations.Routines and calls the others only through the engine, so
+// this package is the one place that depends on all of them.
+package routines
+
+import (
+	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/layout/pages"
+	"github.com/boergens/gotypst/library/foundations"
+	"github.com/boergens/gotypst/realize"
+)
+
+// Standard are the routines of the compiler, which gotypst.Compile and
+// gotypst.Query run a document through.
+//
+// Matches Rust: static ROUTINES in typst/src/lib.rs
+var Standard foundations.Routines = foundations.RoutineSet{
+	Eval:        eval.Routines{},
+	Realization: realize.Routines{},
+	Layout:      pages.Routines{},
+}
@@ -0,0 +1,138 @@
+package syntax
+
+import "strings"
+
+// Index lists the labels, references, imports, and headings of a source.
+// It is built from the syntax tree alone, so tools can link documents
+// without evaluating them. Since nothing is evaluated, labels and paths
+// that are computed by code are not included.
+type Index struct {
+	// Labels are the labels attached to markup, like <intro>.
+	Labels []LabelEntry
+	// References are the references to labels, like @intro.
+	References []ReferenceEntry
+	// Imports are the files and packages loaded by imports and includes
+	// with a literal path.
+	Imports []ImportEntry
+	// Headings are the headings of the source.
+	Headings []HeadingEntry
+}
+
+// LabelEntry is a label in markup.
+type LabelEntry struct {
+	// Name is the label's name, without angle brackets.
+	Name string
+	// Span is the span of the label.
+	Span Span
+}
+
+// ReferenceEntry is a reference to a label.
+type ReferenceEntry struct {
+	// Target is the name of the referenced label.
+	Target string
+	// Span is the span of the reference, including its supplement.
+	Span Span
+}
+
+// ImportEntry is a file or package loaded by an import or include.
+type ImportEntry struct {
+	// Path is the path as written, like "chapter.typ" or
+	// "@preview/example:0.1.0".
+	Path string
+	// Include is true for includes and false for imports.
+	Include bool
+	// Span is the span of the path's string literal.
+	Span Span
+}
+
+// HeadingEntry is a heading.
+type HeadingEntry struct {
+	// Level is the heading's nesting level, starting at 1.
+	Level int
+	// Title is the plain text of the heading, without markup and with
+	// whitespace collapsed.
+	Title string
+	// Label is the name of the label attached to the heading, if any.
+	Label string
+	// Span is the span of the heading.
+	Span Span
+}
+
+// Index lists the labels, references, imports, and headings of the
+// source, in the order they appear in.
+func (s *Source) Index() *Index {
+	idx := &Index{}
+	idx.add(s.root)
+	return idx
+}
+
+func (idx *Index) add(node *SyntaxNode) {
+	children := node.Children()
+	for i, child := range children {
+		switch child.Kind() {
+		case Label:
+			// Labels in code are values, not attached to anything.
+			if node.Kind() == Markup {
+				idx.Labels = append(idx.Labels, LabelEntry{Name: LabelExprFromNode(child).Get(), Span: child.Span()})
+			}
+		case Ref:
+			idx.References = append(idx.References, ReferenceEntry{Target: RefExprFromNode(child).Target(), Span: child.Span()})
+		case ModuleImport, ModuleInclude:
+			if path := child.CastFirst(Str); path != nil {
+				idx.Imports = append(idx.Imports, ImportEntry{
+					Path:    StrExprFromNode(path).Get(),
+					Include: child.Kind() == ModuleInclude,
+					Span:    path.Span(),
+				})
+			}
+		case Heading:
+			heading := HeadingExprFromNode(child)
+			entry := HeadingEntry{Level: heading.Level(), Span: child.Span()}
+			if body := heading.Body(); body != nil {
+				entry.Title = plainText(body.ToUntyped())
+			}
+			entry.Label = followingLabel(children[i+1:])
+			idx.Headings = append(idx.Headings, entry)
+		}
+		idx.add(child)
+	}
+}
+
+// followingLabel returns the name of the label at the start of the nodes,
+// which may be preceded by a space.
+func followingLabel(nodes []*SyntaxNode) string {
+	for _, node := range nodes {
+		if node.Kind() == Space {
+			continue
+		}
+		if node.Kind() == Label {
+			return LabelExprFromNode(node).Get()
+		}
+		return ""
+	}
+	return ""
+}
+
+// plainText returns the text of a markup node without its markup, with
+// whitespace collapsed.
+func plainText(node *SyntaxNode) string {
+	var b strings.Builder
+	var visit func(*SyntaxNode)
+	visit = func(n *SyntaxNode) {
+		if children := n.Children(); len(children) > 0 {
+			for _, child := range children {
+				visit(child)
+			}
+			return
+		}
+		switch n.Kind() {
+		case Space:
+			b.WriteByte(' ')
+		case Star, Underscore, Hash:
+		default:
+			b.WriteString(n.Text())
+		}
+	}
+	visit(node)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,61 @@
+package syntax
+
+import "testing"
+
+func TestSourceIndex(t *testing.T) {
+	vpath, _ := NewVirtualPath("/main.typ")
+	id := NewFileId(*NewRootedPath(ProjectRoot(), *vpath))
+	text := "#import \"defs.typ\": x\n" +
+		"= Intro <intro>\n" +
+		"See @intro and @fig[Figure].\n" +
+		"== The *first* part\n" +
+		"#figure[x] <fig>\n" +
+		"#include \"chapter.typ\"\n" +
+		"#import calc: pow\n" +
+		"#let l = <not-attached>\n"
+	source := NewSource(id, text)
+	idx := source.Index()
+
+	covered := func(span Span) string {
+		start, end, ok := source.Range(span)
+		if !ok {
+			t.Fatalf("cannot resolve span %v", span)
+		}
+		return text[start:end]
+	}
+
+	if len(idx.Labels) != 2 || idx.Labels[0].Name != "intro" || idx.Labels[1].Name != "fig" {
+		t.Errorf("labels = %+v", idx.Labels)
+	} else if got := covered(idx.Labels[1].Span); got != "<fig>" {
+		t.Errorf("label span covers %q", got)
+	}
+
+	if len(idx.References) != 2 || idx.References[0].Target != "intro" || idx.References[1].Target != "fig" {
+		t.Errorf("references = %+v", idx.References)
+	} else if got := covered(idx.References[1].Span); got != "@fig[Figure]" {
+		t.Errorf("reference span covers %q", got)
+	}
+
+	want := []ImportEntry{{Path: "defs.typ"}, {Path: "chapter.typ", Include: true}}
+	if len(idx.Imports) != len(want) {
+		t.Fatalf("imports = %+v", idx.Imports)
+	}
+	for i, imp := range idx.Imports {
+		if imp.Path != want[i].Path || imp.Include != want[i].Include {
+			t.Errorf("import %d = %+v, want %+v", i, imp, want[i])
+		}
+	}
+	if got := covered(idx.Imports[0].Span); got != `"defs.typ"` {
+		t.Errorf("import span covers %q", got)
+	}
+
+	if len(idx.Headings) != 2 {
+		t.Fatalf("headings = %+v", idx.Headings)
+	}
+	if h := idx.Headings[0]; h.Level != 1 || h.Title != "Intro" || h.Label != "intro" {
+		t.Errorf("heading = %+v", h)
+	}
+	if h := idx.Headings[1]; h.Level != 2 || h.Title != "The first part" || h.Label != "" {
+		t.Errorf("heading = %+v", h)
+	}
+}
@@ -517,3 +517,150 @@ func (k SyntaxKind) Name() string {
 func (k SyntaxKind) String() string {
 	return k.Name()
 }
+
+// Ident returns the identifier of the syntax kind, like "FuncCall". Unlike
+// the name, it is unique and does not change, so it is suited for
+// serialized syntax trees.
+func (k SyntaxKind) Ident() string {
+	if int(k) < len(kindIdents) {
+		return kindIdents[k]
+	}
+	return "Unknown"
+}
+
+var kindIdents = [...]string{
+	End:                "End",
+	Error:              "Error",
+	Shebang:            "Shebang",
+	LineComment:        "LineComment",
+	BlockComment:       "BlockComment",
+	Markup:             "Markup",
+	Text:               "Text",
+	Space:              "Space",
+	Linebreak:          "Linebreak",
+	Parbreak:           "Parbreak",
+	Escape:             "Escape",
+	Shorthand:          "Shorthand",
+	SmartQuote:         "SmartQuote",
+	Strong:             "Strong",
+	Emph:               "Emph",
+	Raw:                "Raw",
+	RawLang:            "RawLang",
+	RawDelim:           "RawDelim",
+	RawTrimmed:         "RawTrimmed",
+	Link:               "Link",
+	Label:              "Label",
+	Ref:                "Ref",
+	RefMarker:          "RefMarker",
+	Heading:            "Heading",
+	HeadingMarker:      "HeadingMarker",
+	ListItem:           "ListItem",
+	ListMarker:         "ListMarker",
+	EnumItem:           "EnumItem",
+	EnumMarker:         "EnumMarker",
+	TermItem:           "TermItem",
+	TermMarker:         "TermMarker",
+	Equation:           "Equation",
+	Math:               "Math",
+	MathText:           "MathText",
+	MathIdent:          "MathIdent",
+	MathShorthand:      "MathShorthand",
+	MathAlignPoint:     "MathAlignPoint",
+	MathDelimited:      "MathDelimited",
+	MathAttach:         "MathAttach",
+	MathPrimes:         "MathPrimes",
+	MathFrac:           "MathFrac",
+	MathRoot:           "MathRoot",
+	Hash:               "Hash",
+	LeftBrace:          "LeftBrace",
+	RightBrace:         "RightBrace",
+	LeftBracket:        "LeftBracket",
+	RightBracket:       "RightBracket",
+	LeftParen:          "LeftParen",
+	RightParen:         "RightParen",
+	Comma:              "Comma",
+	Semicolon:          "Semicolon",
+	Colon:              "Colon",
+	Star:               "Star",
+	Underscore:         "Underscore",
+	Dollar:             "Dollar",
+	Plus:               "Plus",
+	Minus:              "Minus",
+	Slash:              "Slash",
+	Hat:                "Hat",
+	Dot:                "Dot",
+	Eq:                 "Eq",
+	EqEq:               "EqEq",
+	ExclEq:             "ExclEq",
+	Lt:                 "Lt",
+	LtEq:               "LtEq",
+	Gt:                 "Gt",
+	GtEq:               "GtEq",
+	PlusEq:             "PlusEq",
+	HyphEq:             "HyphEq",
+	StarEq:             "StarEq",
+	SlashEq:            "SlashEq",
+	Dots:               "Dots",
+	Arrow:              "Arrow",
+	Root:               "Root",
+	Bang:               "Bang",
+	Not:                "Not",
+	And:                "And",
+	Or:                 "Or",
+	None:               "None",
+	Auto:               "Auto",
+	Let:                "Let",
+	Set:                "Set",
+	Show:               "Show",
+	Context:            "Context",
+	If:                 "If",
+	Else:               "Else",
+	For:                "For",
+	In:                 "In",
+	While:              "While",
+	Break:              "Break",
+	Continue:           "Continue",
+	Return:             "Return",
+	Import:             "Import",
+	Include:            "Include",
+	As:                 "As",
+	Code:               "Code",
+	Ident:              "Ident",
+	Bool:               "Bool",
+	Int:                "Int",
+	Float:              "Float",
+	Numeric:            "Numeric",
+	Str:                "Str",
+	CodeBlock:          "CodeBlock",
+	ContentBlock:       "ContentBlock",
+	Parenthesized:      "Parenthesized",
+	Array:              "Array",
+	Dict:               "Dict",
+	Named:              "Named",
+	Keyed:              "Keyed",
+	Unary:              "Unary",
+	Binary:             "Binary",
+	FieldAccess:        "FieldAccess",
+	FuncCall:           "FuncCall",
+	Args:               "Args",
+	Spread:             "Spread",
+	Closure:            "Closure",
+	Params:             "Params",
+	LetBinding:         "LetBinding",
+	SetRule:            "SetRule",
+	ShowRule:           "ShowRule",
+	Contextual:         "Contextual",
+	Conditional:        "Conditional",
+	WhileLoop:          "WhileLoop",
+	ForLoop:            "ForLoop",
+	ModuleImport:       "ModuleImport",
+	ImportItems:        "ImportItems",
+	ImportItemPath:     "ImportItemPath",
+	RenamedImportItem:  "RenamedImportItem",
+	ModuleInclude:      "ModuleInclude",
+	LoopBreak:          "LoopBreak",
+	LoopContinue:       "LoopContinue",
+	FuncReturn:         "FuncReturn",
+	Destructuring:      "Destructuring",
+	DestructAssignment: "DestructAssignment",
+}
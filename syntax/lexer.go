@@ -840,6 +840,8 @@ func (l *Lexer) MaybeMathNamedArg(start int) *SyntaxNode {
 				return Leaf(Ident, text)
 			}
 			err := NewSyntaxError("expected identifier, found underscore")
+			err.Expected = Ident.Name()
+			err.Found = Underscore
 			return ErrorNode(err, text)
 		}
 	}
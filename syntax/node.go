@@ -143,6 +143,14 @@ type SyntaxError struct {
 	Message string
 	// Hints provides additional guidance to the user.
 	Hints []string
+	// Expected describes what the parser expected where the error
+	// occurred, like "expression" or "closing paren". It is empty for
+	// errors that are not about a missing or misplaced token.
+	Expected string
+	// Found is the kind of the token the parser found instead, or End at
+	// the end of the text. It is only meaningful if Expected is set or the
+	// error reports an unexpected token.
+	Found SyntaxKind
 }
 
 // NewSyntaxError creates a new detached syntax error.
@@ -164,14 +172,16 @@ func (e *SyntaxError) Clone() *SyntaxError {
 	hints := make([]string, len(e.Hints))
 	copy(hints, e.Hints)
 	return &SyntaxError{
-		Span:    e.Span,
-		Message: e.Message,
-		Hints:   hints,
+		Span:     e.Span,
+		Message:  e.Message,
+		Hints:    hints,
+		Expected: e.Expected,
+		Found:    e.Found,
 	}
 }
 
 func (e *SyntaxError) spanlessEq(other *SyntaxError) bool {
-	if e.Message != other.Message || len(e.Hints) != len(other.Hints) {
+	if e.Message != other.Message || e.Expected != other.Expected || e.Found != other.Found || len(e.Hints) != len(other.Hints) {
 		return false
 	}
 	for i, h := range e.Hints {
@@ -462,7 +472,12 @@ func (n *SyntaxNode) ConvertToError(message string) {
 // Expected converts the node to an error stating that the given thing was expected.
 func (n *SyntaxNode) Expected(expected string) {
 	kind := n.Kind()
+	if kind == Error {
+		return
+	}
 	n.ConvertToError(fmt.Sprintf("expected %s, found %s", expected, kind.Name()))
+	n.data.(*errorNode).error.Expected = expected
+	n.data.(*errorNode).error.Found = kind
 	if kind.IsKeyword() && (expected == "identifier" || expected == "pattern") {
 		text := n.Text()
 		n.Hint(fmt.Sprintf("keyword `%s` is not allowed as an identifier; try `%s_` instead", text, text))
@@ -471,7 +486,12 @@ func (n *SyntaxNode) Expected(expected string) {
 
 // Unexpected converts the node to an error stating it was unexpected.
 func (n *SyntaxNode) Unexpected() {
-	n.ConvertToError(fmt.Sprintf("unexpected %s", n.Kind().Name()))
+	kind := n.Kind()
+	if kind == Error {
+		return
+	}
+	n.ConvertToError(fmt.Sprintf("unexpected %s", kind.Name()))
+	n.data.(*errorNode).error.Found = kind
 }
 
 // Upper returns the upper bound of assigned numbers in this subtree.
@@ -9,6 +9,10 @@ package syntax
 const MaxDepth = 256
 
 // Parse parses a source file as top-level markup.
+//
+// Parsing never fails: syntax errors become Error nodes in the tree, which
+// always covers the whole text. Tools can thus work with the partial tree
+// of a broken document, and find the errors with SyntaxNode.Errors.
 func Parse(text string) *SyntaxNode {
 	p := NewParser(text, 0, ModeMarkup)
 	markupExprs(p, true, SyntaxSetOf(End))
@@ -509,7 +513,13 @@ func (p *Parser) expect(kind SyntaxKind) bool {
 // expectClosingDelimiter consumes the closing delimiter or marks the opener as error.
 func (p *Parser) expectClosingDelimiter(open Marker, kind SyntaxKind) {
 	if !p.eatIf(kind) {
-		p.nodes[open].ConvertToError("unclosed delimiter")
+		node := p.nodes[open]
+		if node.Kind() != Error {
+			node.ConvertToError("unclosed delimiter")
+			err := node.data.(*errorNode).error
+			err.Expected = kind.Name()
+			err.Found = p.current()
+		}
 	}
 }
 
@@ -528,7 +538,10 @@ func (p *Parser) afterError() bool {
 
 // expectedAt produces an error at the given marker position.
 func (p *Parser) expectedAt(m Marker, thing string) {
-	errNode := ErrorNode(NewSyntaxError("expected "+thing), "")
+	err := NewSyntaxError("expected " + thing)
+	err.Expected = thing
+	err.Found = p.current()
+	errNode := ErrorNode(err, "")
 	// Insert error node at position m
 	p.nodes = append(p.nodes[:m], append([]*SyntaxNode{errNode}, p.nodes[m:]...)...)
 }
@@ -349,3 +349,50 @@ func TestParseWhileLoop(t *testing.T) {
 		t.Error("Expected to find a WhileLoop")
 	}
 }
+
+// TestParseAlwaysComplete checks that the tree of a broken document covers
+// all of its text, no matter where the text is cut off.
+func TestParseAlwaysComplete(t *testing.T) {
+	text := "= Intro <intro>\n#let f(x, ..rest) = { if x > 1 [*big*] else { (a: 1, b: (2, 3)) } }\n" +
+		"$ sum_(i=0)^n x_i / 2 $ and `raw` with #f(1, key: \"value\")[body].\n" +
+		"#for (k, v) in (a: 1) { import \"x.typ\": y as z; show heading: set text(red) }"
+	parsers := map[string]func(string) *SyntaxNode{"markup": Parse, "code": ParseCode, "math": ParseMath}
+	for name, parse := range parsers {
+		for cut := 0; cut <= len(text); cut++ {
+			for _, part := range []string{text[:cut], text[cut:]} {
+				if got := parse(part).IntoText(); got != part {
+					t.Fatalf("%s: tree of %q covers %q", name, part, got)
+				}
+			}
+		}
+	}
+}
+
+// TestParseErrorExpectation checks that errors record what was expected
+// and what was found instead.
+func TestParseErrorExpectation(t *testing.T) {
+	tests := []struct {
+		input    string
+		message  string
+		expected string
+		found    SyntaxKind
+	}{
+		{"#let x = )", "expected expression", "expression", RightParen},
+		{"#(1, 2", "unclosed delimiter", "closing paren", End},
+		{"#let while = 1", "expected pattern, found keyword `while`", "pattern", While},
+		{"#(1 2)", "expected comma", "comma", Int},
+		{"#{ let x = 1 else }", "unexpected keyword `else`", "", Else},
+	}
+	for _, tt := range tests {
+		errs := Parse(tt.input).Errors()
+		if len(errs) == 0 {
+			t.Errorf("Parse(%q) has no errors", tt.input)
+			continue
+		}
+		err := errs[0]
+		if err.Message != tt.message || err.Expected != tt.expected || err.Found != tt.found {
+			t.Errorf("Parse(%q) error = %q (expected %q, found %v), want %q (expected %q, found %v)",
+				tt.input, err.Message, err.Expected, err.Found, tt.message, tt.expected, tt.found)
+		}
+	}
+}
@@ -177,6 +177,23 @@ func (f FileId) Get() *RootedPath {
 	return interner.fromId[f.id-1]
 }
 
+// Join resolves a path relative to the directory of this file. Absolute
+// paths are resolved relative to the root. The result lies in the same root
+// as this file.
+// Matches Rust: FileId::join
+func (f FileId) Join(path string) (FileId, error) {
+	rooted := f.Get()
+	base := rooted.VPath().Parent()
+	if base == nil {
+		base = rooted.VPath()
+	}
+	vpath, err := base.Join(path)
+	if err != nil {
+		return FileId{}, err
+	}
+	return NewRootedPath(rooted.Root(), *vpath).Intern(), nil
+}
+
 // String returns a debug string representation of the FileId.
 func (f FileId) String() string {
 	return f.Get().String()
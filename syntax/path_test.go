@@ -310,3 +310,25 @@ func TestFileId(t *testing.T) {
 		t.Error("UniqueFileId should create different id from regular FileId")
 	}
 }
+
+func TestFileIdJoin(t *testing.T) {
+	id := NewRootedPath(ProjectRoot(), *mustPath(t, "src/main.typ")).Intern()
+
+	for _, tt := range []struct{ path, want string }{
+		{"image.png", "/src/image.png"},
+		{"../assets/image.png", "/assets/image.png"},
+		{"/image.png", "/image.png"},
+	} {
+		joined, err := id.Join(tt.path)
+		if err != nil {
+			t.Fatalf("Join(%q): %v", tt.path, err)
+		}
+		if got := joined.Get().VPath().GetWithSlash(); got != tt.want {
+			t.Errorf("Join(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := id.Join("../../escape.png"); err == nil {
+		t.Error("Join of a path escaping the root succeeded")
+	}
+}
@@ -61,7 +61,8 @@ func (s *Source) Text() string {
 	return s.text
 }
 
-// Root returns the untyped syntax tree root node.
+// Root returns the untyped syntax tree root node. The tree is complete
+// even if the source has syntax errors, which are embedded as Error nodes.
 func (s *Source) Root() *SyntaxNode {
 	return s.root
 }
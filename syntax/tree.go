@@ -0,0 +1,127 @@
+package syntax
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TreeFormat is a format a syntax tree can be serialized in.
+type TreeFormat int
+
+const (
+	// TreeJSON serializes the tree as nested JSON objects, one per node.
+	TreeJSON TreeFormat = iota
+	// TreeSExpr serializes the tree as indented S-expressions, one node per
+	// line, which is easier to read and diff.
+	TreeSExpr
+)
+
+// ParseTreeFormat parses the name of a tree format: json or sexpr.
+func ParseTreeFormat(name string) (TreeFormat, error) {
+	switch name {
+	case "json":
+		return TreeJSON, nil
+	case "sexpr", "sexp":
+		return TreeSExpr, nil
+	}
+	return 0, fmt.Errorf("unknown tree format %q (expected json or sexpr)", name)
+}
+
+// TreeNode is the serialized form of a syntax node. It is a stable
+// representation for tools, independent of the node's internals.
+type TreeNode struct {
+	// Kind is the identifier of the node's kind, like "FuncCall".
+	Kind string `json:"kind"`
+	// Start and End are the byte range of the node in the source text.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Span is the number of the node's span within its file, or 0 for a
+	// detached span.
+	Span uint64 `json:"span"`
+	// Text is the text of a leaf or error node.
+	Text string `json:"text,omitempty"`
+	// Error and Hints describe the problem of an error node.
+	Error string   `json:"error,omitempty"`
+	Hints []string `json:"hints,omitempty"`
+	// Children are the children of an inner node.
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// Tree converts a syntax tree into its serialized form. Byte ranges are
+// relative to the start of the given node.
+func Tree(node *SyntaxNode) *TreeNode {
+	return tree(node, 0)
+}
+
+func tree(node *SyntaxNode, offset int) *TreeNode {
+	out := &TreeNode{
+		Kind:  node.Kind().Ident(),
+		Start: offset,
+		End:   offset + node.Len(),
+	}
+	if span := node.Span(); !span.IsDetached() {
+		out.Span = span.Number()
+	}
+
+	switch d := node.data.(type) {
+	case *leafNode:
+		out.Text = d.nodeText
+	case *errorNode:
+		out.Text = d.nodeText
+		out.Error = d.error.Message
+		out.Hints = d.error.Hints
+	case *innerNode:
+		out.Children = make([]*TreeNode, 0, len(d.nodeChildren))
+		for _, child := range d.nodeChildren {
+			out.Children = append(out.Children, tree(child, offset))
+			offset += child.Len()
+		}
+	}
+	return out
+}
+
+// MarshalTree serializes a syntax tree, usually the root of a source, for
+// tools that inspect Typst's syntax. Each node carries its kind, byte
+// range, span number, and, for leaves, its text.
+//
+// The S-expression format writes a node as (Kind start..end "text"), with
+// an error node's message and hints following its text.
+func MarshalTree(node *SyntaxNode, format TreeFormat) ([]byte, error) {
+	switch format {
+	case TreeJSON:
+		data, err := json.MarshalIndent(Tree(node), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case TreeSExpr:
+		var b strings.Builder
+		writeSExpr(&b, Tree(node), 0)
+		b.WriteByte('\n')
+		return []byte(b.String()), nil
+	}
+	return nil, fmt.Errorf("unknown tree format %d", format)
+}
+
+func writeSExpr(b *strings.Builder, node *TreeNode, depth int) {
+	fmt.Fprintf(b, "(%s %d..%d", node.Kind, node.Start, node.End)
+	if node.Children == nil {
+		b.WriteByte(' ')
+		b.WriteString(strconv.Quote(node.Text))
+	}
+	if node.Error != "" {
+		fmt.Fprintf(b, " (error %s", strconv.Quote(node.Error))
+		for _, hint := range node.Hints {
+			fmt.Fprintf(b, " (hint %s)", strconv.Quote(hint))
+		}
+		b.WriteByte(')')
+	}
+	for _, child := range node.Children {
+		b.WriteByte('\n')
+		b.WriteString(strings.Repeat("  ", depth+1))
+		writeSExpr(b, child, depth+1)
+	}
+	b.WriteByte(')')
+}
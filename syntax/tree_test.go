@@ -0,0 +1,81 @@
+package syntax
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalTreeSExpr(t *testing.T) {
+	data, err := MarshalTree(Parse("= Hi\n#f(1)"), TreeSExpr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `(Markup 0..10
+  (Heading 0..4
+    (HeadingMarker 0..1 "=")
+    (Space 1..2 " ")
+    (Markup 2..4
+      (Text 2..4 "Hi")))
+  (Space 4..5 "\n")
+  (Hash 5..6 "#")
+  (FuncCall 6..10
+    (Ident 6..7 "f")
+    (Args 7..10
+      (LeftParen 7..8 "(")
+      (Int 8..9 "1")
+      (RightParen 9..10 ")"))))
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestMarshalTreeJSON(t *testing.T) {
+	vpath, _ := NewVirtualPath("/tree.typ")
+	source := NewSource(NewFileId(*NewRootedPath(ProjectRoot(), *vpath)), "#(1 +")
+	data, err := MarshalTree(source.Root(), TreeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root TreeNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root.Kind != "Markup" || root.Start != 0 || root.End != 5 || root.Span == 0 {
+		t.Errorf("root = %+v", root)
+	}
+
+	var errNode *TreeNode
+	var find func(*TreeNode)
+	find = func(n *TreeNode) {
+		if n.Kind == "Error" && errNode == nil {
+			errNode = n
+		}
+		for _, c := range n.Children {
+			find(c)
+		}
+	}
+	find(&root)
+	if errNode == nil || errNode.Error == "" {
+		t.Fatalf("expected an error node in %s", data)
+	}
+}
+
+func TestParseTreeFormat(t *testing.T) {
+	if f, err := ParseTreeFormat("sexpr"); err != nil || f != TreeSExpr {
+		t.Errorf("sexpr = %v, %v", f, err)
+	}
+	if _, err := ParseTreeFormat("xml"); err == nil {
+		t.Error("expected an error for xml")
+	}
+}
+
+func TestSyntaxKindIdent(t *testing.T) {
+	if got := FuncCall.Ident(); got != "FuncCall" {
+		t.Errorf("FuncCall.Ident() = %q", got)
+	}
+	if got := DestructAssignment.Ident(); got != "DestructAssignment" {
+		t.Errorf("DestructAssignment.Ident() = %q", got)
+	}
+}
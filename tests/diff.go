@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DiffOptions controls how closely a rendered image must match its
+// reference image.
+type DiffOptions struct {
+	// Threshold is the perceptual color distance, from 0 for equal colors
+	// to 1 for the most different ones, up to which two pixels count as the
+	// same. It absorbs small anti-aliasing differences.
+	Threshold float64
+	// MaxDiffPixels is how many pixels may differ beyond the threshold
+	// before the images are considered different.
+	MaxDiffPixels int
+}
+
+// DefaultDiffOptions tolerates slight color differences, but no pixel
+// that visibly changed.
+var DefaultDiffOptions = DiffOptions{Threshold: 0.1}
+
+// ImageDiff is the result of comparing a rendered image to its reference.
+type ImageDiff struct {
+	// SizeMismatch is set if the images differ in size, in which case no
+	// pixels were compared.
+	SizeMismatch bool
+	// DiffPixels is the number of pixels that differ beyond the threshold.
+	DiffPixels int
+	// MaxDistance is the largest perceptual distance of any pixel.
+	MaxDistance float64
+	// Image shows the differing pixels in red over a faded copy of the
+	// reference. It is nil if the sizes differ.
+	Image *image.RGBA
+}
+
+// Matches reports whether the images are the same within the options.
+func (d *ImageDiff) Matches(opts DiffOptions) bool {
+	return !d.SizeMismatch && d.DiffPixels <= opts.MaxDiffPixels
+}
+
+// DiffImages compares a rendered image to its reference pixel by pixel.
+// Pixels are compared by their distance in the YIQ color space, which
+// weighs changes in brightness more than changes in hue, like the eye
+// does. Both images are composited over white first, so that transparent
+// and white pixels compare equal.
+func DiffImages(got, want image.Image, opts DiffOptions) *ImageDiff {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Size() != wb.Size() {
+		return &ImageDiff{SizeMismatch: true}
+	}
+
+	diff := &ImageDiff{Image: image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))}
+	for y := range wb.Dy() {
+		for x := range wb.Dx() {
+			a := got.At(gb.Min.X+x, gb.Min.Y+y)
+			b := want.At(wb.Min.X+x, wb.Min.Y+y)
+			distance := colorDistance(a, b)
+			diff.MaxDistance = math.Max(diff.MaxDistance, distance)
+			if distance > opts.Threshold {
+				diff.DiffPixels++
+				diff.Image.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				diff.Image.SetRGBA(x, y, faded(b))
+			}
+		}
+	}
+	return diff
+}
+
+// maxYIQDelta is the largest possible squared YIQ distance between two
+// colors.
+const maxYIQDelta = 35215.0
+
+// colorDistance returns the perceptual distance between two colors, from
+// 0 for equal colors to 1 for the most different ones. Black and white
+// are about 0.97 apart.
+func colorDistance(a, b color.Color) float64 {
+	r1, g1, b1 := overWhite(a)
+	r2, g2, b2 := overWhite(b)
+	y := yiqY(r1, g1, b1) - yiqY(r2, g2, b2)
+	i := yiqI(r1, g1, b1) - yiqI(r2, g2, b2)
+	q := yiqQ(r1, g1, b1) - yiqQ(r2, g2, b2)
+	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	return math.Sqrt(delta / maxYIQDelta)
+}
+
+// overWhite composites a color over white and returns its 8-bit channels.
+func overWhite(c color.Color) (float64, float64, float64) {
+	r, g, b, a := c.RGBA()
+	white := float64(0xffff - a)
+	return (float64(r) + white) / 257, (float64(g) + white) / 257, (float64(b) + white) / 257
+}
+
+func yiqY(r, g, b float64) float64 { return 0.29889531*r + 0.58662247*g + 0.11448223*b }
+func yiqI(r, g, b float64) float64 { return 0.59597799*r - 0.27417610*g - 0.32180189*b }
+func yiqQ(r, g, b float64) float64 { return 0.21147017*r - 0.52261711*g + 0.31114694*b }
+
+// faded returns a light gray version of a color, as the background of a
+// diff image.
+func faded(c color.Color) color.RGBA {
+	r, g, b := overWhite(c)
+	gray := uint8(255 - (255-yiqY(r, g, b))*0.2)
+	return color.RGBA{R: gray, G: gray, B: gray, A: 255}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestColorDistance(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+
+	tests := []struct {
+		name string
+		a, b color.Color
+		want float64
+	}{
+		{"equal", white, white, 0},
+		{"black and white", black, white, 0.966},
+		{"transparent over white", color.RGBA{}, white, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colorDistance(tt.a, tt.b); math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("colorDistance = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Brightness weighs more than hue.
+	gray := colorDistance(color.RGBA{R: 100, G: 100, B: 100, A: 255}, color.RGBA{R: 120, G: 120, B: 120, A: 255})
+	hue := colorDistance(color.RGBA{R: 100, G: 100, B: 120, A: 255}, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	if hue >= gray {
+		t.Errorf("blue shift %v should be smaller than brightness shift %v", hue, gray)
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	want := filledImage(4, 4, white)
+	got := filledImage(4, 4, white)
+	// A faint anti-aliasing difference and one changed pixel.
+	got.SetRGBA(0, 0, color.RGBA{R: 250, G: 250, B: 250, A: 255})
+	got.SetRGBA(2, 3, color.RGBA{A: 255})
+
+	diff := DiffImages(got, want, DefaultDiffOptions)
+	if diff.SizeMismatch {
+		t.Fatal("unexpected size mismatch")
+	}
+	if diff.DiffPixels != 1 {
+		t.Errorf("DiffPixels = %d, want 1", diff.DiffPixels)
+	}
+	if diff.MaxDistance < 0.9 {
+		t.Errorf("MaxDistance = %v, want close to 1", diff.MaxDistance)
+	}
+	if diff.Matches(DefaultDiffOptions) {
+		t.Error("images should not match")
+	}
+	if !diff.Matches(DiffOptions{Threshold: 0.1, MaxDiffPixels: 1}) {
+		t.Error("images should match with one allowed pixel")
+	}
+
+	if c := diff.Image.RGBAAt(2, 3); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("diff pixel = %v, want red", c)
+	}
+	if c := diff.Image.RGBAAt(1, 1); c.R != c.G || c.G != c.B {
+		t.Errorf("unchanged pixel = %v, want gray", c)
+	}
+}
+
+func TestDiffImagesSizeMismatch(t *testing.T) {
+	diff := DiffImages(image.NewRGBA(image.Rect(0, 0, 2, 2)), image.NewRGBA(image.Rect(0, 0, 2, 3)), DefaultDiffOptions)
+	if !diff.SizeMismatch || diff.Image != nil {
+		t.Errorf("diff = %+v, want a size mismatch", diff)
+	}
+	if diff.Matches(DiffOptions{MaxDiffPixels: 100}) {
+		t.Error("images of different sizes should never match")
+	}
+}
+
+func TestDiffImagesOffsetBounds(t *testing.T) {
+	want := filledImage(2, 2, color.RGBA{A: 255})
+	got := image.NewRGBA(image.Rect(5, 5, 7, 7))
+	for y := 5; y < 7; y++ {
+		for x := 5; x < 7; x++ {
+			got.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+	if diff := DiffImages(got, want, DefaultDiffOptions); diff.DiffPixels != 0 || diff.SizeMismatch {
+		t.Errorf("diff = %+v, want no differences", diff)
+	}
+}
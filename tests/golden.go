@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/boergens/gotypst"
+	"github.com/boergens/gotypst/eval"
+	"github.com/boergens/gotypst/kit"
+	"github.com/boergens/gotypst/render"
+	"github.com/boergens/gotypst/routines"
+)
+
+// goldenPreamble is prepended to the code of every golden test, so that
+// the pages are small and fit their content like in Typst's own suite.
+const goldenPreamble = "#set page(width: 120pt, height: auto, margin: 10pt)\n"
+
+// goldenPixelPerPt is the resolution at which golden tests are rendered.
+const goldenPixelPerPt = 1
+
+var (
+	pageBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	pageSeparator  = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+)
+
+// GoldenResult represents the result of comparing a test's rendered output
+// against its reference image.
+type GoldenResult struct {
+	Test    *TestCase
+	Passed  bool
+	Skipped bool   // No reference exists and none was written
+	Updated bool   // The reference was written or removed
+	Details string // Detailed failure or skip information
+}
+
+// GoldenRunner compiles fixture tests, renders them to images and compares
+// the images against reference PNGs.
+type GoldenRunner struct {
+	refDir string
+	outDir string
+	update bool
+	diff   DiffOptions
+}
+
+// NewGoldenRunner creates a golden runner that keeps reference images in
+// refDir and writes the output and diff of failing tests to outDir.
+func NewGoldenRunner(refDir, outDir string) *GoldenRunner {
+	return &GoldenRunner{
+		refDir: refDir,
+		outDir: outDir,
+		diff:   DefaultDiffOptions,
+	}
+}
+
+// SetUpdate sets whether the runner overwrites references with the
+// current output instead of comparing against them.
+func (r *GoldenRunner) SetUpdate(update bool) {
+	r.update = update
+}
+
+// SetDiffOptions sets how closely the output must match the references.
+func (r *GoldenRunner) SetDiffOptions(opts DiffOptions) {
+	r.diff = opts
+}
+
+// IsGolden reports whether a test is compared against a reference image.
+// These are the paged tests that are expected to compile without errors.
+func IsGolden(tc *TestCase) bool {
+	return slices.Contains(tc.Attrs, "paged") && len(tc.Errors) == 0
+}
+
+// RenderTest compiles a test and renders its pages into a single image,
+// with the pages stacked from top to bottom.
+func RenderTest(tc *TestCase) (*image.RGBA, error) {
+	world, err := kit.NewMemoryWorld("main.typ",
+		map[string][]byte{"main.typ": []byte(goldenPreamble + tc.Code)},
+		kit.WithLibrary(eval.Library()))
+	if err != nil {
+		return nil, err
+	}
+
+	doc, diags := gotypst.Compile(world, gotypst.CompileOptions{Routines: routines.Standard})
+	if diags.HasErrors() {
+		var msgs []string
+		for _, diag := range diags.Errors() {
+			msgs = append(msgs, diag.Message)
+		}
+		return nil, fmt.Errorf("compilation failed: %s", strings.Join(msgs, "; "))
+	}
+
+	var images []*image.RGBA
+	for i := range doc.Pages {
+		img, err := render.RenderPage(&doc.Pages[i], goldenPixelPerPt)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		images = append(images, img)
+	}
+	return stackPages(images), nil
+}
+
+// stackPages merges page images into one, separated by a gray line.
+func stackPages(images []*image.RGBA) *image.RGBA {
+	width, height := 0, 0
+	for i, img := range images {
+		width = max(width, img.Bounds().Dx())
+		height += img.Bounds().Dy()
+		if i > 0 {
+			height++
+		}
+	}
+
+	merged := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(merged, merged.Bounds(), image.NewUniform(pageSeparator), image.Point{}, draw.Src)
+	y := 0
+	for _, img := range images {
+		rect := image.Rect(0, y, img.Bounds().Dx(), y+img.Bounds().Dy())
+		draw.Draw(merged, rect, img, img.Bounds().Min, draw.Src)
+		y = rect.Max.Y + 1
+	}
+	return merged
+}
+
+// isBlank reports whether an image has no visible content, which is the
+// case for tests that only check evaluation.
+func isBlank(img *image.RGBA) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := img.RGBAAt(x, y); c != pageBackground && c != pageSeparator {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// refPath returns the path of a test's reference image.
+func (r *GoldenRunner) refPath(tc *TestCase) string {
+	return filepath.Join(r.refDir, tc.Name+".png")
+}
+
+// Run renders a test and compares the result against its reference. Tests
+// without visible output must not have a reference, so that evaluation
+// tests don't accumulate empty images. In update mode, the reference is
+// written or removed to match the output instead.
+func (r *GoldenRunner) Run(tc *TestCase) *GoldenResult {
+	result := &GoldenResult{Test: tc}
+	if !IsGolden(tc) {
+		result.Skipped = true
+		result.Details = "not a paged test without errors"
+		return result
+	}
+
+	got, err := RenderTest(tc)
+	if err != nil {
+		result.Details = err.Error()
+		return result
+	}
+	blank := isBlank(got)
+
+	refPath := r.refPath(tc)
+	want, err := readPNG(refPath)
+	missing := errors.Is(err, os.ErrNotExist)
+	if err != nil && !missing {
+		result.Details = err.Error()
+		return result
+	}
+
+	if r.update {
+		return r.updateRef(result, got, blank, missing)
+	}
+
+	switch {
+	case missing && blank:
+		result.Passed = true
+	case missing:
+		result.Skipped = true
+		result.Details = fmt.Sprintf("missing reference %s (run with -update to create it)", refPath)
+	case blank:
+		result.Details = fmt.Sprintf("output is empty, but reference %s exists", refPath)
+	default:
+		diff := DiffImages(got, want, r.diff)
+		if diff.Matches(r.diff) {
+			result.Passed = true
+			return result
+		}
+		result.Details = r.describe(diff, got.Bounds().Size(), want.Bounds().Size())
+		if err := r.writeOutput(tc, got, diff); err != nil {
+			result.Details += fmt.Sprintf("; writing output: %v", err)
+		}
+	}
+	return result
+}
+
+// updateRef writes or removes a test's reference to match the output.
+func (r *GoldenRunner) updateRef(result *GoldenResult, got *image.RGBA, blank, missing bool) *GoldenResult {
+	refPath := r.refPath(result.Test)
+	result.Passed = true
+	switch {
+	case blank && missing:
+	case blank:
+		if err := os.Remove(refPath); err != nil {
+			result.Passed = false
+			result.Details = err.Error()
+		}
+		result.Updated = result.Passed
+	default:
+		if err := writePNG(refPath, got); err != nil {
+			result.Passed = false
+			result.Details = err.Error()
+		}
+		result.Updated = result.Passed
+	}
+	return result
+}
+
+// describe explains why a diff failed.
+func (r *GoldenRunner) describe(diff *ImageDiff, got, want image.Point) string {
+	if diff.SizeMismatch {
+		return fmt.Sprintf("size mismatch: got %dx%d, want %dx%d", got.X, got.Y, want.X, want.Y)
+	}
+	return fmt.Sprintf("%d pixels differ (max %d allowed), max distance %.3f",
+		diff.DiffPixels, r.diff.MaxDiffPixels, diff.MaxDistance)
+}
+
+// writeOutput writes the output of a failing test and its diff image to
+// the output directory for inspection.
+func (r *GoldenRunner) writeOutput(tc *TestCase, got *image.RGBA, diff *ImageDiff) error {
+	if err := writePNG(filepath.Join(r.outDir, tc.Name+".png"), got); err != nil {
+		return err
+	}
+	if diff.Image != nil {
+		return writePNG(filepath.Join(r.outDir, tc.Name+".diff.png"), diff.Image)
+	}
+	return nil
+}
+
+// RunAll runs the golden comparison for all tests.
+func (r *GoldenRunner) RunAll(tests []*TestCase) []*GoldenResult {
+	results := make([]*GoldenResult, 0, len(tests))
+	for _, tc := range tests {
+		results = append(results, r.Run(tc))
+	}
+	return results
+}
+
+func readPNG(path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the reference images of golden tests")
+
+// TestGoldenFixtures renders all paged fixture tests and compares them
+// against the reference images in tests/ref. Run with -update to write
+// the current output as the new references.
+func TestGoldenFixtures(t *testing.T) {
+	fixturesDir := getFixturesDir(t)
+	testsDir := filepath.Dir(fixturesDir)
+	runner := NewGoldenRunner(filepath.Join(testsDir, "ref"), filepath.Join(testsDir, "out"))
+	runner.SetUpdate(*update)
+
+	tests, err := NewTestRunner(fixturesDir).LoadFixtures()
+	if err != nil {
+		t.Fatalf("LoadFixtures failed: %v", err)
+	}
+
+	for _, tc := range tests {
+		if !IsGolden(tc) {
+			continue
+		}
+		t.Run(tc.Name, func(t *testing.T) {
+			res := runner.Run(tc)
+			switch {
+			case res.Skipped:
+				t.Skip(res.Details)
+			case !res.Passed:
+				t.Errorf("%s:%d: %s", tc.SourceFile, tc.LineNumber, res.Details)
+			case res.Updated:
+				t.Logf("updated reference")
+			}
+		})
+	}
+}
+
+func filledImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestStackPages(t *testing.T) {
+	black := color.RGBA{A: 255}
+	merged := stackPages([]*image.RGBA{
+		filledImage(4, 2, pageBackground),
+		filledImage(2, 3, black),
+	})
+
+	if got := merged.Bounds().Size(); got != (image.Point{X: 4, Y: 6}) {
+		t.Fatalf("size = %v, want 4x6", got)
+	}
+	if got := merged.RGBAAt(0, 2); got != pageSeparator {
+		t.Errorf("separator = %v, want gray", got)
+	}
+	if got := merged.RGBAAt(1, 4); got != black {
+		t.Errorf("second page = %v, want black", got)
+	}
+	// The narrower page is padded to the right.
+	if got := merged.RGBAAt(3, 4); got != pageSeparator {
+		t.Errorf("padding = %v, want gray", got)
+	}
+}
+
+func TestIsBlank(t *testing.T) {
+	blank := stackPages([]*image.RGBA{
+		filledImage(4, 2, pageBackground),
+		filledImage(4, 2, pageBackground),
+	})
+	if !isBlank(blank) {
+		t.Error("empty pages should be blank")
+	}
+
+	blank.SetRGBA(1, 1, color.RGBA{R: 200, A: 255})
+	if isBlank(blank) {
+		t.Error("page with content should not be blank")
+	}
+}
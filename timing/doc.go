@@ -0,0 +1,12 @@
+// Package timing records how long the stages of a compilation take.
+//
+// It takes the role of the typst-timing crate in the Rust implementation:
+// stages like parsing, evaluation, realization, layout, and export are
+// timed while a document is compiled, and the recorded events can be
+// summarized for the terminal or written as a trace that profilers and
+// flamegraph viewers like Perfetto and speedscope load.
+//
+// A nil timer records nothing, so code can time its stages unconditionally:
+//
+//	defer timer.Start("eval", "")()
+package timing
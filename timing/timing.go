@@ -0,0 +1,189 @@
+package timing
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a timed stage of a compilation.
+type Event struct {
+	// Name is the name of the stage, like eval or layout.
+	Name string
+	// Detail tells apart repeated stages, like the page a stage is for. It
+	// is empty if the stage happens once.
+	Detail string
+	// Thread is the thread the stage ran on. Stages on the same thread
+	// nest; stages on different threads may overlap.
+	Thread int
+	// Start is when the stage started, relative to when the timer was
+	// created.
+	Start time.Duration
+	// Duration is how long the stage took.
+	Duration time.Duration
+}
+
+// Label returns the name of the event together with its detail.
+func (e Event) Label() string {
+	if e.Detail == "" {
+		return e.Name
+	}
+	return e.Name + " " + e.Detail
+}
+
+// end returns when the stage ended.
+func (e Event) end() time.Duration {
+	return e.Start + e.Duration
+}
+
+// Timer records timed stages. It is safe for concurrent use.
+type Timer struct {
+	rec    *recorder
+	thread int
+}
+
+// recorder holds the events of a timer and the timers derived from it.
+type recorder struct {
+	epoch  time.Time
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewTimer creates a timer that records stages on thread 0.
+func NewTimer() *Timer {
+	return &Timer{rec: &recorder{epoch: time.Now()}}
+}
+
+// Thread returns a timer that records into the same events, but on the
+// given thread. Stages running concurrently should be timed on different
+// threads, so that traces show them side by side.
+func (t *Timer) Thread(thread int) *Timer {
+	if t == nil {
+		return nil
+	}
+	return &Timer{rec: t.rec, thread: thread}
+}
+
+// Start starts timing a stage and returns the function that ends it.
+func (t *Timer) Start(name, detail string) func() {
+	if t == nil {
+		return func() {}
+	}
+	start := time.Since(t.rec.epoch)
+	return func() {
+		event := Event{
+			Name:     name,
+			Detail:   detail,
+			Thread:   t.thread,
+			Start:    start,
+			Duration: time.Since(t.rec.epoch) - start,
+		}
+		t.rec.mu.Lock()
+		t.rec.events = append(t.rec.events, event)
+		t.rec.mu.Unlock()
+	}
+}
+
+// Events returns the recorded events ordered by when they started. Events
+// that started at the same time are ordered outermost first.
+func (t *Timer) Events() []Event {
+	if t == nil {
+		return nil
+	}
+	t.rec.mu.Lock()
+	events := slices.Clone(t.rec.events)
+	t.rec.mu.Unlock()
+	slices.SortStableFunc(events, func(a, b Event) int {
+		if a.Start != b.Start {
+			return cmp.Compare(a.Start, b.Start)
+		}
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return events
+}
+
+// WriteSummary writes one line per event with its duration, indented by
+// how deeply the stage is nested in other stages. Stages on other threads
+// are nested in the stages of thread 0 they ran during.
+func (t *Timer) WriteSummary(w io.Writer) error {
+	events := t.Events()
+	width := 0
+	depths := make([]int, len(events))
+	for i, event := range events {
+		for j, outer := range events {
+			if i != j && (outer.Thread == 0 || outer.Thread == event.Thread) && contains(outer, event, j < i) {
+				depths[i]++
+			}
+		}
+		width = max(width, 2*depths[i]+len(event.Label()))
+	}
+	for i, event := range events {
+		label := strings.Repeat("  ", depths[i]) + event.Label()
+		if _, err := fmt.Fprintf(w, "%-*s %10s\n", width, label, formatDuration(event.Duration)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contains reports whether the outer event spans the inner one. Of two
+// events with the same span, the one that comes first is the outer one.
+func contains(outer, inner Event, first bool) bool {
+	if outer.Start == inner.Start && outer.end() == inner.end() {
+		return first
+	}
+	return outer.Start <= inner.Start && inner.end() <= outer.end()
+}
+
+// formatDuration formats a duration in milliseconds.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+}
+
+// traceEvent is an event in the Chrome trace event format. Times are in
+// microseconds.
+type traceEvent struct {
+	Name  string            `json:"name"`
+	Phase string            `json:"ph"`
+	Time  float64           `json:"ts"`
+	Dur   float64           `json:"dur"`
+	Pid   int               `json:"pid"`
+	Tid   int               `json:"tid"`
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+// WriteTrace writes the events in the Chrome trace event format, which
+// chrome://tracing, Perfetto, and speedscope load as a flamegraph.
+//
+// Matches Rust: typst_timing::export_json
+func (t *Timer) WriteTrace(w io.Writer) error {
+	events := t.Events()
+	trace := make([]traceEvent, 0, len(events))
+	for _, event := range events {
+		out := traceEvent{
+			Name:  event.Name,
+			Phase: "X",
+			Time:  microseconds(event.Start),
+			Dur:   microseconds(event.Duration),
+			Pid:   1,
+			Tid:   event.Thread,
+		}
+		if event.Detail != "" {
+			out.Args = map[string]string{"detail": event.Detail}
+		}
+		trace = append(trace, out)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(trace)
+}
+
+// microseconds converts a duration to microseconds.
+func microseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
@@ -0,0 +1,89 @@
+package timing
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// timerWith creates a timer holding the given events.
+func timerWith(events ...Event) *Timer {
+	t := NewTimer()
+	t.rec.events = events
+	return t
+}
+
+func TestTimerStart(t *testing.T) {
+	timer := NewTimer()
+	end := timer.Start("layout", "")
+	timer.Thread(2).Start("layout run", "1")()
+	end()
+
+	events := timer.Events()
+	if len(events) != 2 {
+		t.Fatalf("recorded %d events, want 2", len(events))
+	}
+	if events[0].Name != "layout" || events[1].Label() != "layout run 1" || events[1].Thread != 2 {
+		t.Errorf("events = %+v", events)
+	}
+	if events[1].Start < events[0].Start || events[1].end() > events[0].end() {
+		t.Errorf("run %+v is not within layout %+v", events[1], events[0])
+	}
+
+	// A nil timer records nothing.
+	var none *Timer
+	none.Thread(1).Start("eval", "")()
+	if none.Events() != nil {
+		t.Error("nil timer recorded events")
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	ms := time.Millisecond
+	timer := timerWith(
+		Event{Name: "export", Start: 9 * ms, Duration: ms},
+		Event{Name: "layout", Start: 2 * ms, Duration: 7 * ms},
+		Event{Name: "layout run", Detail: "2", Thread: 2, Start: 3 * ms, Duration: 3 * ms},
+		Event{Name: "layout run", Detail: "1", Thread: 1, Start: 2 * ms, Duration: 4 * ms},
+		Event{Name: "eval", Start: 0, Duration: 2 * ms},
+	)
+	var buf bytes.Buffer
+	if err := timer.WriteSummary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"eval               2.00ms\n" +
+		"layout             7.00ms\n" +
+		"  layout run 1     4.00ms\n" +
+		"  layout run 2     3.00ms\n" +
+		"export             1.00ms\n"
+	if buf.String() != want {
+		t.Errorf("WriteSummary() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteTrace(t *testing.T) {
+	timer := timerWith(
+		Event{Name: "eval", Start: 1500 * time.Microsecond, Duration: time.Millisecond},
+		Event{Name: "finalize page", Detail: "3", Thread: 1, Start: 3 * time.Millisecond, Duration: 250 * time.Microsecond},
+	)
+	var buf bytes.Buffer
+	if err := timer.WriteTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var trace []traceEvent
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 2 {
+		t.Fatalf("trace has %d events, want 2", len(trace))
+	}
+	eval, page := trace[0], trace[1]
+	if eval.Name != "eval" || eval.Phase != "X" || eval.Time != 1500 || eval.Dur != 1000 || eval.Args != nil {
+		t.Errorf("eval event = %+v", eval)
+	}
+	if page.Tid != 1 || page.Time != 3000 || page.Dur != 250 || page.Args["detail"] != "3" {
+		t.Errorf("page event = %+v", page)
+	}
+}
@@ -0,0 +1,224 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Opcodes with immediates or special handling. Numeric instructions are
+// matched by their values in the interpreter.
+const (
+	opUnreachable  = 0x00
+	opNop          = 0x01
+	opBlock        = 0x02
+	opLoop         = 0x03
+	opIf           = 0x04
+	opElse         = 0x05
+	opEnd          = 0x0B
+	opBr           = 0x0C
+	opBrIf         = 0x0D
+	opBrTable      = 0x0E
+	opReturn       = 0x0F
+	opCall         = 0x10
+	opCallIndirect = 0x11
+	opDrop         = 0x1A
+	opSelect       = 0x1B
+	opSelectT      = 0x1C
+	opLocalGet     = 0x20
+	opLocalSet     = 0x21
+	opLocalTee     = 0x22
+	opGlobalGet    = 0x23
+	opGlobalSet    = 0x24
+	opTableGet     = 0x25
+	opTableSet     = 0x26
+	opMemorySize   = 0x3F
+	opMemoryGrow   = 0x40
+	opI32Const     = 0x41
+	opI64Const     = 0x42
+	opF32Const     = 0x43
+	opF64Const     = 0x44
+	opRefNull      = 0xD0
+	opRefIsNull    = 0xD1
+	opRefFunc      = 0xD2
+	opPrefix       = 0xFC
+)
+
+// Instructions behind the 0xFC prefix are stored as 0xFC00 plus their
+// sub-opcode.
+const (
+	opMemoryInit = 0xFC08
+	opDataDrop   = 0xFC09
+	opMemoryCopy = 0xFC0A
+	opMemoryFill = 0xFC0B
+	opTableInit  = 0xFC0C
+	opElemDrop   = 0xFC0D
+	opTableCopy  = 0xFC0E
+	opTableGrow  = 0xFC0F
+	opTableSize  = 0xFC10
+	opTableFill  = 0xFC11
+)
+
+// instr is a decoded instruction with its immediates and, for structured
+// control instructions, the positions of the matching else and end.
+type instr struct {
+	op uint16
+	// a holds the primary immediate: an index, a constant, or the offset of
+	// a memory access. For block, loop, if, and else it holds the index of
+	// the matching end.
+	a uint64
+	// b holds a secondary immediate: a second index, or the index of the
+	// else of an if.
+	b uint32
+	// params and results are the arities of a block type.
+	params, results uint16
+	// labels are the targets of br_table, the last one being the default.
+	labels []uint32
+}
+
+// compile decodes the body of a function into instructions and resolves the
+// structure of its blocks.
+func compile(m *Module, c *code, typ FuncType) ([]instr, error) {
+	r := &reader{data: c.body}
+	numLocals := uint64(len(typ.Params) + len(c.locals))
+	var instrs []instr
+	var open []int // indices of the open block, loop, and if instructions
+
+	for !r.done() {
+		in := instr{op: uint16(r.byte())}
+		switch in.op {
+		case opBlock, opLoop, opIf:
+			in.params, in.results = r.blockType(m)
+			open = append(open, len(instrs))
+		case opElse:
+			if len(open) == 0 || instrs[open[len(open)-1]].op != opIf {
+				return nil, errors.New("else without if")
+			}
+			instrs[open[len(open)-1]].b = uint32(len(instrs))
+		case opEnd:
+			if len(open) > 0 {
+				start := open[len(open)-1]
+				open = open[:len(open)-1]
+				instrs[start].a = uint64(len(instrs))
+				if instrs[start].op == opIf && instrs[start].b != 0 {
+					instrs[instrs[start].b].a = uint64(len(instrs))
+				}
+			} else if !r.done() {
+				return nil, errors.New("end of function before end of body")
+			}
+		case opBr, opBrIf:
+			in.a = uint64(r.u32())
+		case opBrTable:
+			n := r.u32()
+			if int(n) > len(r.data)-r.pos {
+				return nil, errors.New("unexpected end of function")
+			}
+			in.labels = make([]uint32, n+1)
+			for i := range in.labels {
+				in.labels[i] = r.u32()
+			}
+		case opCall:
+			in.a = uint64(r.u32())
+			if in.a >= uint64(m.numFuncs()) {
+				return nil, errors.New("call of unknown function")
+			}
+		case opCallIndirect:
+			in.a = uint64(r.u32())
+			in.b = r.u32()
+			if in.a >= uint64(len(m.types)) {
+				return nil, errors.New("indirect call with unknown type")
+			}
+		case opSelectT:
+			r.valueTypes()
+			in.op = opSelect
+		case opLocalGet, opLocalSet, opLocalTee:
+			in.a = uint64(r.u32())
+			if in.a >= numLocals {
+				return nil, errors.New("access of unknown local")
+			}
+		case opGlobalGet, opGlobalSet:
+			in.a = uint64(r.u32())
+			if in.a >= uint64(len(m.globals)) {
+				return nil, errors.New("access of unknown global")
+			}
+		case opTableGet, opTableSet, opRefFunc:
+			in.a = uint64(r.u32())
+		case opMemorySize, opMemoryGrow:
+			r.byte()
+		case opI32Const:
+			in.a = uint64(uint32(r.sleb(32)))
+		case opI64Const:
+			in.a = uint64(r.sleb(64))
+		case opF32Const:
+			if b := r.bytes(4); b != nil {
+				in.a = uint64(le32(b))
+			}
+		case opF64Const:
+			if b := r.bytes(8); b != nil {
+				in.a = le64(b)
+			}
+		case opRefNull:
+			r.byte()
+		case opPrefix:
+			in.op = 0xFC00 | uint16(r.u32())
+			switch in.op {
+			case opMemoryInit:
+				in.a = uint64(r.u32())
+				r.byte()
+			case opDataDrop, opElemDrop, opTableGrow, opTableSize, opTableFill:
+				in.a = uint64(r.u32())
+			case opMemoryCopy:
+				r.byte()
+				r.byte()
+			case opMemoryFill:
+				r.byte()
+			case opTableInit, opTableCopy:
+				in.a = uint64(r.u32())
+				in.b = r.u32()
+			default:
+				if in.op > 0xFC07 {
+					return nil, fmt.Errorf("unsupported instruction 0xfc %d", in.op&0xFF)
+				}
+			}
+		default:
+			switch {
+			case in.op >= 0x28 && in.op <= 0x3E:
+				r.u32() // alignment
+				in.a = uint64(r.u32())
+			case in.op == opUnreachable, in.op == opNop, in.op == opReturn, in.op == opDrop,
+				in.op == opSelect, in.op == opRefIsNull,
+				in.op >= 0x45 && in.op <= 0xC4:
+			default:
+				return nil, fmt.Errorf("unsupported instruction %#x", in.op)
+			}
+		}
+		instrs = append(instrs, in)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(open) > 0 || len(instrs) == 0 || instrs[len(instrs)-1].op != opEnd {
+		return nil, errors.New("function body is not terminated")
+	}
+	return instrs, nil
+}
+
+// blockType reads the type of a block and returns its arities.
+func (r *reader) blockType(m *Module) (params, results uint16) {
+	if r.pos < len(r.data) {
+		switch b := r.data[r.pos]; b {
+		case 0x40:
+			r.pos++
+			return 0, 0
+		case byte(I32), byte(I64), byte(F32), byte(F64), byte(FuncRef), byte(ExternRef):
+			r.pos++
+			return 0, 1
+		}
+	}
+	index := r.sleb(33)
+	if index < 0 || index >= int64(len(m.types)) {
+		r.fail("block has unknown type")
+		return 0, 0
+	}
+	typ := m.types[index]
+	return uint16(len(typ.Params)), uint16(len(typ.Results))
+}
@@ -0,0 +1,560 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// ValueType is the type of a WebAssembly value.
+type ValueType byte
+
+const (
+	I32       ValueType = 0x7F
+	I64       ValueType = 0x7E
+	F32       ValueType = 0x7D
+	F64       ValueType = 0x7C
+	FuncRef   ValueType = 0x70
+	ExternRef ValueType = 0x6F
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	case FuncRef:
+		return "funcref"
+	case ExternRef:
+		return "externref"
+	default:
+		return fmt.Sprintf("type(%#x)", byte(t))
+	}
+}
+
+// FuncType is the signature of a function.
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+func (t FuncType) equal(other FuncType) bool {
+	if len(t.Params) != len(other.Params) || len(t.Results) != len(other.Results) {
+		return false
+	}
+	for i := range t.Params {
+		if t.Params[i] != other.Params[i] {
+			return false
+		}
+	}
+	for i := range t.Results {
+		if t.Results[i] != other.Results[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t FuncType) String() string {
+	return fmt.Sprintf("%v -> %v", t.Params, t.Results)
+}
+
+// External kinds of imports and exports.
+const (
+	ExternFunc   byte = 0x00
+	ExternTable  byte = 0x01
+	ExternMemory byte = 0x02
+	ExternGlobal byte = 0x03
+)
+
+// Limits bound the size of a memory or table.
+type Limits struct {
+	Min uint32
+	Max *uint32
+}
+
+// Import is a function imported from the host.
+type Import struct {
+	Module string
+	Name   string
+	Kind   byte
+	Type   FuncType
+}
+
+// Export makes a function, table, memory, or global accessible by name.
+type Export struct {
+	Name  string
+	Kind  byte
+	Index uint32
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    constExpr
+}
+
+type table struct {
+	typ    ValueType
+	limits Limits
+}
+
+// constExpr is a constant initializer expression.
+type constExpr struct {
+	op  byte
+	imm uint64
+}
+
+type elemSegment struct {
+	// mode is 0 for active, 1 for passive, and 2 for declarative segments.
+	mode   int
+	table  uint32
+	offset constExpr
+	inits  []constExpr
+}
+
+type dataSegment struct {
+	passive bool
+	memory  uint32
+	offset  constExpr
+	data    []byte
+}
+
+type code struct {
+	locals []ValueType
+	body   []byte
+	instrs []instr
+}
+
+// Module is a decoded WebAssembly module. It can be instantiated any number
+// of times.
+type Module struct {
+	types    []FuncType
+	imports  []Import
+	funcs    []uint32 // type indices of the defined functions
+	tables   []table
+	memories []Limits
+	globals  []global
+	exports  []Export
+	start    *uint32
+	elems    []elemSegment
+	codes    []*code
+	data     []dataSegment
+}
+
+// Imports returns the imports of the module.
+func (m *Module) Imports() []Import { return m.imports }
+
+// Exports returns the exports of the module.
+func (m *Module) Exports() []Export { return m.exports }
+
+// ExportedFunc returns the type of the exported function with the given
+// name.
+func (m *Module) ExportedFunc(name string) (FuncType, bool) {
+	for _, e := range m.exports {
+		if e.Name == name && e.Kind == ExternFunc {
+			return m.funcType(e.Index), true
+		}
+	}
+	return FuncType{}, false
+}
+
+// funcType returns the type of a function in the function index space,
+// which starts with the imported functions.
+func (m *Module) funcType(index uint32) FuncType {
+	if int(index) < len(m.imports) {
+		return m.imports[index].Type
+	}
+	return m.types[m.funcs[int(index)-len(m.imports)]]
+}
+
+// numFuncs returns the size of the function index space.
+func (m *Module) numFuncs() int {
+	return len(m.imports) + len(m.funcs)
+}
+
+// Decode parses a module in the WebAssembly binary format.
+func Decode(data []byte) (*Module, error) {
+	r := &reader{data: data}
+	if len(data) < 8 || string(data[:4]) != "\x00asm" {
+		return nil, errors.New("not a WebAssembly module")
+	}
+	if version := uint32(data[4]) | uint32(data[5])<<8 | uint32(data[6])<<16 | uint32(data[7])<<24; version != 1 {
+		return nil, fmt.Errorf("unsupported WebAssembly version %d", version)
+	}
+	r.pos = 8
+
+	m := &Module{}
+	var funcCount int
+	for !r.done() {
+		id := r.byte()
+		size := int(r.u32())
+		if r.err != nil {
+			break
+		}
+		if size > len(r.data)-r.pos {
+			return nil, errors.New("section extends past end of module")
+		}
+		sec := &reader{data: r.data[r.pos : r.pos+size]}
+		r.pos += size
+
+		switch id {
+		case 0: // custom
+		case 1:
+			m.types = make([]FuncType, sec.u32())
+			for i := range m.types {
+				if sec.byte() != 0x60 {
+					return nil, errors.New("malformed function type")
+				}
+				m.types[i].Params = sec.valueTypes()
+				m.types[i].Results = sec.valueTypes()
+			}
+		case 2:
+			n := sec.u32()
+			for range n {
+				imp := Import{Module: sec.name(), Name: sec.name(), Kind: sec.byte()}
+				if sec.err != nil {
+					break
+				}
+				if imp.Kind != ExternFunc {
+					return nil, fmt.Errorf("unsupported import %s.%s: only functions can be imported", imp.Module, imp.Name)
+				}
+				index := sec.u32()
+				if int(index) >= len(m.types) {
+					return nil, errors.New("import has unknown type")
+				}
+				imp.Type = m.types[index]
+				m.imports = append(m.imports, imp)
+			}
+		case 3:
+			m.funcs = make([]uint32, sec.u32())
+			for i := range m.funcs {
+				m.funcs[i] = sec.u32()
+				if int(m.funcs[i]) >= len(m.types) {
+					return nil, errors.New("function has unknown type")
+				}
+			}
+		case 4:
+			m.tables = make([]table, sec.u32())
+			for i := range m.tables {
+				m.tables[i].typ = ValueType(sec.byte())
+				m.tables[i].limits = sec.limits()
+			}
+		case 5:
+			m.memories = make([]Limits, sec.u32())
+			for i := range m.memories {
+				m.memories[i] = sec.limits()
+			}
+			if len(m.memories) > 1 {
+				return nil, errors.New("multiple memories are not supported")
+			}
+		case 6:
+			m.globals = make([]global, sec.u32())
+			for i := range m.globals {
+				m.globals[i].typ = ValueType(sec.byte())
+				m.globals[i].mutable = sec.byte() == 1
+				m.globals[i].init = sec.constExpr()
+			}
+		case 7:
+			m.exports = make([]Export, sec.u32())
+			for i := range m.exports {
+				m.exports[i] = Export{Name: sec.name(), Kind: sec.byte(), Index: sec.u32()}
+			}
+		case 8:
+			start := sec.u32()
+			m.start = &start
+		case 9:
+			m.elems = make([]elemSegment, sec.u32())
+			for i := range m.elems {
+				m.elems[i] = sec.elemSegment()
+			}
+		case 10:
+			m.codes = make([]*code, sec.u32())
+			for i := range m.codes {
+				m.codes[i] = sec.code()
+			}
+			funcCount = len(m.codes)
+		case 11:
+			m.data = make([]dataSegment, sec.u32())
+			for i := range m.data {
+				m.data[i] = sec.dataSegment()
+			}
+		case 12: // data count
+			sec.u32()
+		default:
+			return nil, fmt.Errorf("unknown section %d", id)
+		}
+		if sec.err != nil {
+			return nil, sec.err
+		}
+		if id != 0 && !sec.done() {
+			return nil, fmt.Errorf("section %d has trailing bytes", id)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if funcCount != len(m.funcs) {
+		return nil, errors.New("function and code section have inconsistent lengths")
+	}
+	for i, c := range m.codes {
+		var err error
+		if c.instrs, err = compile(m, c, m.types[m.funcs[i]]); err != nil {
+			return nil, fmt.Errorf("function %d: %w", len(m.imports)+i, err)
+		}
+	}
+	if m.start != nil && int(*m.start) >= m.numFuncs() {
+		return nil, errors.New("start function is unknown")
+	}
+	for _, e := range m.exports {
+		if e.Kind == ExternFunc && int(e.Index) >= m.numFuncs() {
+			return nil, fmt.Errorf("export %s refers to an unknown function", e.Name)
+		}
+	}
+	return m, nil
+}
+
+// reader decodes the primitives of the binary format. The first error
+// sticks, and all reads after it return zero values.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) done() bool {
+	return r.err != nil || r.pos >= len(r.data)
+}
+
+func (r *reader) fail(msg string) {
+	if r.err == nil {
+		r.err = errors.New(msg)
+	}
+}
+
+func (r *reader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.fail("unexpected end of module")
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n int) []byte {
+	if n < 0 || n > len(r.data)-r.pos {
+		r.fail("unexpected end of module")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// uleb reads an unsigned LEB128 number of at most the given bit width.
+func (r *reader) uleb(bits uint) uint64 {
+	var result uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= bits+7 {
+			r.fail("integer too long")
+			return 0
+		}
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		result |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return result
+		}
+	}
+}
+
+// sleb reads a signed LEB128 number of at most the given bit width.
+func (r *reader) sleb(bits uint) int64 {
+	var result int64
+	var shift uint
+	for {
+		if shift >= bits+7 {
+			r.fail("integer too long")
+			return 0
+		}
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		result |= int64(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				result |= -1 << shift
+			}
+			return result
+		}
+	}
+}
+
+func (r *reader) u32() uint32 {
+	return uint32(r.uleb(32))
+}
+
+func (r *reader) name() string {
+	b := r.bytes(int(r.u32()))
+	if !utf8.Valid(b) {
+		r.fail("name is not valid UTF-8")
+	}
+	return string(b)
+}
+
+func (r *reader) valueTypes() []ValueType {
+	n := int(r.u32())
+	if n > len(r.data)-r.pos {
+		r.fail("unexpected end of module")
+		return nil
+	}
+	types := make([]ValueType, n)
+	for i := range types {
+		types[i] = ValueType(r.byte())
+	}
+	return types
+}
+
+func (r *reader) limits() Limits {
+	flag := r.byte()
+	l := Limits{Min: r.u32()}
+	if flag&1 != 0 {
+		max := r.u32()
+		l.Max = &max
+	}
+	return l
+}
+
+// constExpr reads a constant expression consisting of a single instruction
+// followed by end.
+func (r *reader) constExpr() constExpr {
+	e := constExpr{op: r.byte()}
+	switch e.op {
+	case opI32Const:
+		e.imm = uint64(uint32(r.sleb(32)))
+	case opI64Const:
+		e.imm = uint64(r.sleb(64))
+	case opF32Const:
+		b := r.bytes(4)
+		if b != nil {
+			e.imm = uint64(le32(b))
+		}
+	case opF64Const:
+		b := r.bytes(8)
+		if b != nil {
+			e.imm = le64(b)
+		}
+	case opGlobalGet, opRefFunc:
+		e.imm = uint64(r.u32())
+	case opRefNull:
+		r.byte()
+	default:
+		r.fail(fmt.Sprintf("unsupported constant expression %#x", e.op))
+	}
+	if r.byte() != opEnd {
+		r.fail("constant expression is not terminated")
+	}
+	return e
+}
+
+func (r *reader) elemSegment() elemSegment {
+	flags := r.u32()
+	var seg elemSegment
+	switch {
+	case flags&1 == 0:
+		seg.mode = 0
+		if flags&2 != 0 {
+			seg.table = r.u32()
+		}
+		seg.offset = r.constExpr()
+	case flags&2 == 0:
+		seg.mode = 1
+	default:
+		seg.mode = 2
+	}
+	if flags&3 != 0 {
+		// Element kind or reference type.
+		r.byte()
+	}
+	n := int(r.u32())
+	if n > len(r.data)-r.pos {
+		r.fail("unexpected end of module")
+		return seg
+	}
+	seg.inits = make([]constExpr, n)
+	for i := range seg.inits {
+		if flags&4 != 0 {
+			seg.inits[i] = r.constExpr()
+		} else {
+			seg.inits[i] = constExpr{op: opRefFunc, imm: uint64(r.u32())}
+		}
+	}
+	return seg
+}
+
+func (r *reader) dataSegment() dataSegment {
+	var seg dataSegment
+	switch r.u32() {
+	case 0:
+		seg.offset = r.constExpr()
+	case 1:
+		seg.passive = true
+	case 2:
+		seg.memory = r.u32()
+		seg.offset = r.constExpr()
+	default:
+		r.fail("malformed data segment")
+	}
+	seg.data = r.bytes(int(r.u32()))
+	return seg
+}
+
+func (r *reader) code() *code {
+	size := int(r.u32())
+	body := &reader{data: r.bytes(size)}
+	if r.err != nil {
+		return nil
+	}
+	c := &code{}
+	groups := body.u32()
+	for range groups {
+		n := body.u32()
+		typ := ValueType(body.byte())
+		if uint64(len(c.locals))+uint64(n) > 50000 {
+			body.fail("too many locals")
+			break
+		}
+		for range n {
+			c.locals = append(c.locals, typ)
+		}
+	}
+	if body.err != nil {
+		r.err = body.err
+		return nil
+	}
+	c.body = body.data[body.pos:]
+	return c
+}
+
+func le32(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func le64(b []byte) uint64 {
+	return uint64(le32(b)) | uint64(le32(b[4:]))<<32
+}
+
+// f32 and f64 convert between floats and their bit patterns on the stack.
+func f32(v uint64) float32 { return math.Float32frombits(uint32(v)) }
+func f64(v uint64) float64 { return math.Float64frombits(v) }
@@ -0,0 +1,12 @@
+// Package wasm is a small WebAssembly interpreter.
+//
+// It takes the role of the wasmi crate in the Rust implementation and exists
+// to run Typst plugins. It supports the WebAssembly 1.0 instruction set
+// together with the post-MVP features that current compilers emit by
+// default: multi-value blocks, sign-extension operators, non-trapping
+// float-to-int conversions, bulk memory operations, and reference types.
+// SIMD, threads, and imports other than functions are not supported.
+//
+// Execution is deterministic: there is no access to the host beyond the
+// imported functions, and every trap is reported as an error.
+package wasm
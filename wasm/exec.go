@@ -0,0 +1,793 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// label is an entered block. Branching to it moves its arity values down to
+// height and continues at cont.
+type label struct {
+	height int
+	arity  int
+	cont   int
+	loop   bool
+}
+
+// invoke calls a function with its arguments on the stack and leaves its
+// results there.
+func (inst *Instance) invoke(index uint32) {
+	m := inst.module
+	typ := m.funcType(index)
+	np := len(typ.Params)
+
+	if int(index) < len(inst.host) {
+		args := slices.Clone(inst.stack[len(inst.stack)-np:])
+		inst.stack = inst.stack[:len(inst.stack)-np]
+		results, err := inst.host[index].Func(inst, args)
+		if err != nil {
+			panic(hostError{err})
+		}
+		if len(results) != len(typ.Results) {
+			panic(Trap("host function returned the wrong number of results"))
+		}
+		inst.stack = append(inst.stack, results...)
+		return
+	}
+
+	inst.depth++
+	if inst.depth > maxCallDepth {
+		panic(Trap("call stack exhausted"))
+	}
+	c := m.codes[int(index)-len(inst.host)]
+	locals := make([]uint64, np+len(c.locals))
+	copy(locals, inst.stack[len(inst.stack)-np:])
+	inst.stack = inst.stack[:len(inst.stack)-np]
+	inst.execute(c.instrs, locals, len(typ.Results))
+	inst.depth--
+}
+
+// execute runs the body of a function.
+func (inst *Instance) execute(code []instr, locals []uint64, results int) {
+	// The body is a block whose end returns from the function.
+	labels := []label{{height: len(inst.stack), arity: results, cont: len(code)}}
+
+	// branch leaves the given number of enclosing blocks and returns the
+	// instruction to continue at.
+	branch := func(depth int) int {
+		l := labels[len(labels)-1-depth]
+		n := len(inst.stack)
+		copy(inst.stack[l.height:], inst.stack[n-l.arity:])
+		inst.stack = inst.stack[:l.height+l.arity]
+		if l.loop {
+			labels = labels[:len(labels)-depth]
+		} else {
+			labels = labels[:len(labels)-1-depth]
+		}
+		return l.cont
+	}
+
+	for pc := 0; pc < len(code); {
+		in := &code[pc]
+		pc++
+
+		switch in.op {
+		case opUnreachable:
+			panic(Trap("unreachable"))
+		case opNop:
+		case opBlock:
+			labels = append(labels, label{height: len(inst.stack) - int(in.params), arity: int(in.results), cont: int(in.a) + 1})
+		case opLoop:
+			labels = append(labels, label{height: len(inst.stack) - int(in.params), arity: int(in.params), cont: pc, loop: true})
+		case opIf:
+			l := label{height: len(inst.stack) - 1 - int(in.params), arity: int(in.results), cont: int(in.a) + 1}
+			if uint32(inst.pop()) != 0 {
+				labels = append(labels, l)
+			} else if in.b != 0 {
+				labels = append(labels, l)
+				pc = int(in.b) + 1
+			} else {
+				pc = int(in.a) + 1
+			}
+		case opElse:
+			labels = labels[:len(labels)-1]
+			pc = int(in.a) + 1
+		case opEnd:
+			labels = labels[:len(labels)-1]
+		case opBr:
+			pc = branch(int(in.a))
+		case opBrIf:
+			if uint32(inst.pop()) != 0 {
+				pc = branch(int(in.a))
+			}
+		case opBrTable:
+			i := uint64(uint32(inst.pop()))
+			target := in.labels[len(in.labels)-1]
+			if i < uint64(len(in.labels)-1) {
+				target = in.labels[i]
+			}
+			pc = branch(int(target))
+		case opReturn:
+			pc = branch(len(labels) - 1)
+		case opCall:
+			inst.invoke(uint32(in.a))
+		case opCallIndirect:
+			i := uint64(uint32(inst.pop()))
+			table := inst.tables[in.b]
+			if i >= uint64(len(table)) {
+				panic(Trap("undefined element"))
+			}
+			ref := table[i]
+			if ref == 0 {
+				panic(Trap("uninitialized element"))
+			}
+			if !inst.module.funcType(uint32(ref - 1)).equal(inst.module.types[in.a]) {
+				panic(Trap("indirect call type mismatch"))
+			}
+			inst.invoke(uint32(ref - 1))
+		case opDrop:
+			inst.pop()
+		case opSelect:
+			c := uint32(inst.pop())
+			b := inst.pop()
+			if c == 0 {
+				inst.stack[len(inst.stack)-1] = b
+			}
+		case opLocalGet:
+			inst.push(locals[in.a])
+		case opLocalSet:
+			locals[in.a] = inst.pop()
+		case opLocalTee:
+			locals[in.a] = inst.stack[len(inst.stack)-1]
+		case opGlobalGet:
+			inst.push(inst.globals[in.a])
+		case opGlobalSet:
+			inst.globals[in.a] = inst.pop()
+		case opTableGet:
+			table := inst.tables[in.a]
+			i := uint64(uint32(inst.pop()))
+			if i >= uint64(len(table)) {
+				panic(Trap("out of bounds table access"))
+			}
+			inst.push(table[i])
+		case opTableSet:
+			table := inst.tables[in.a]
+			v := inst.pop()
+			i := uint64(uint32(inst.pop()))
+			if i >= uint64(len(table)) {
+				panic(Trap("out of bounds table access"))
+			}
+			table[i] = v
+		case opMemorySize:
+			inst.push(uint64(len(inst.memory) / PageSize))
+		case opMemoryGrow:
+			delta := uint64(uint32(inst.pop()))
+			old := uint64(len(inst.memory) / PageSize)
+			if old+delta > inst.maxPages() {
+				inst.push(uint64(math.MaxUint32))
+			} else {
+				inst.memory = append(inst.memory, make([]byte, delta*PageSize)...)
+				inst.push(old)
+			}
+		case opI32Const, opI64Const, opF32Const, opF64Const:
+			inst.push(in.a)
+		case opRefNull:
+			inst.push(0)
+		case opRefIsNull:
+			inst.push(boolValue(inst.pop() == 0))
+		case opRefFunc:
+			inst.push(in.a + 1)
+		default:
+			switch {
+			case in.op >= 0x28 && in.op <= 0x35:
+				inst.load(in)
+			case in.op >= 0x36 && in.op <= 0x3E:
+				inst.store(in)
+			case in.op >= 0xFC00:
+				inst.prefixed(in)
+			default:
+				inst.numeric(in.op)
+			}
+		}
+	}
+}
+
+// address pops a memory address, adds the offset, and checks that size
+// bytes can be accessed there.
+func (inst *Instance) address(offset uint64, size uint64) []byte {
+	addr := uint64(uint32(inst.pop())) + offset
+	if addr+size > uint64(len(inst.memory)) {
+		panic(Trap("out of bounds memory access"))
+	}
+	return inst.memory[addr : addr+size]
+}
+
+func (inst *Instance) pop() uint64 {
+	v := inst.stack[len(inst.stack)-1]
+	inst.stack = inst.stack[:len(inst.stack)-1]
+	return v
+}
+
+func (inst *Instance) push(v uint64) {
+	inst.stack = append(inst.stack, v)
+}
+
+func (inst *Instance) load(in *instr) {
+	le := binary.LittleEndian
+	switch in.op {
+	case 0x28, 0x2A: // i32.load, f32.load
+		inst.push(uint64(le.Uint32(inst.address(in.a, 4))))
+	case 0x29, 0x2B: // i64.load, f64.load
+		inst.push(le.Uint64(inst.address(in.a, 8)))
+	case 0x2C: // i32.load8_s
+		inst.push(uint64(uint32(int8(inst.address(in.a, 1)[0]))))
+	case 0x2D: // i32.load8_u
+		inst.push(uint64(inst.address(in.a, 1)[0]))
+	case 0x2E: // i32.load16_s
+		inst.push(uint64(uint32(int16(le.Uint16(inst.address(in.a, 2))))))
+	case 0x2F: // i32.load16_u
+		inst.push(uint64(le.Uint16(inst.address(in.a, 2))))
+	case 0x30: // i64.load8_s
+		inst.push(uint64(int8(inst.address(in.a, 1)[0])))
+	case 0x31: // i64.load8_u
+		inst.push(uint64(inst.address(in.a, 1)[0]))
+	case 0x32: // i64.load16_s
+		inst.push(uint64(int16(le.Uint16(inst.address(in.a, 2)))))
+	case 0x33: // i64.load16_u
+		inst.push(uint64(le.Uint16(inst.address(in.a, 2))))
+	case 0x34: // i64.load32_s
+		inst.push(uint64(int32(le.Uint32(inst.address(in.a, 4)))))
+	case 0x35: // i64.load32_u
+		inst.push(uint64(le.Uint32(inst.address(in.a, 4))))
+	}
+}
+
+func (inst *Instance) store(in *instr) {
+	le := binary.LittleEndian
+	v := inst.pop()
+	switch in.op {
+	case 0x36, 0x38: // i32.store, f32.store
+		le.PutUint32(inst.address(in.a, 4), uint32(v))
+	case 0x37, 0x39: // i64.store, f64.store
+		le.PutUint64(inst.address(in.a, 8), v)
+	case 0x3A, 0x3C: // i32.store8, i64.store8
+		inst.address(in.a, 1)[0] = byte(v)
+	case 0x3B, 0x3D: // i32.store16, i64.store16
+		le.PutUint16(inst.address(in.a, 2), uint16(v))
+	case 0x3E: // i64.store32
+		le.PutUint32(inst.address(in.a, 4), uint32(v))
+	}
+}
+
+// prefixed executes the saturating conversions and the bulk memory and
+// table instructions.
+func (inst *Instance) prefixed(in *instr) {
+	switch in.op {
+	case 0xFC00: // i32.trunc_sat_f32_s
+		inst.push(uint64(uint32(satS32(float64(f32(inst.pop()))))))
+	case 0xFC01: // i32.trunc_sat_f32_u
+		inst.push(uint64(satU32(float64(f32(inst.pop())))))
+	case 0xFC02: // i32.trunc_sat_f64_s
+		inst.push(uint64(uint32(satS32(f64(inst.pop())))))
+	case 0xFC03: // i32.trunc_sat_f64_u
+		inst.push(uint64(satU32(f64(inst.pop()))))
+	case 0xFC04: // i64.trunc_sat_f32_s
+		inst.push(uint64(satS64(float64(f32(inst.pop())))))
+	case 0xFC05: // i64.trunc_sat_f32_u
+		inst.push(satU64(float64(f32(inst.pop()))))
+	case 0xFC06: // i64.trunc_sat_f64_s
+		inst.push(uint64(satS64(f64(inst.pop()))))
+	case 0xFC07: // i64.trunc_sat_f64_u
+		inst.push(satU64(f64(inst.pop())))
+
+	case opMemoryInit:
+		n, s, d := uint64(uint32(inst.pop())), uint64(uint32(inst.pop())), uint64(uint32(inst.pop()))
+		var data []byte
+		if !inst.droppedData[in.a] {
+			data = inst.module.data[in.a].data
+		}
+		if s+n > uint64(len(data)) || d+n > uint64(len(inst.memory)) {
+			panic(Trap("out of bounds memory access"))
+		}
+		copy(inst.memory[d:d+n], data[s:s+n])
+	case opDataDrop:
+		inst.droppedData[in.a] = true
+	case opMemoryCopy:
+		n, s, d := uint64(uint32(inst.pop())), uint64(uint32(inst.pop())), uint64(uint32(inst.pop()))
+		if s+n > uint64(len(inst.memory)) || d+n > uint64(len(inst.memory)) {
+			panic(Trap("out of bounds memory access"))
+		}
+		copy(inst.memory[d:d+n], inst.memory[s:s+n])
+	case opMemoryFill:
+		n, v, d := uint64(uint32(inst.pop())), byte(inst.pop()), uint64(uint32(inst.pop()))
+		if d+n > uint64(len(inst.memory)) {
+			panic(Trap("out of bounds memory access"))
+		}
+		for i := range inst.memory[d : d+n] {
+			inst.memory[d+uint64(i)] = v
+		}
+
+	case opTableInit:
+		n, s, d := uint64(uint32(inst.pop())), uint64(uint32(inst.pop())), uint64(uint32(inst.pop()))
+		var inits []constExpr
+		if !inst.droppedElem[in.a] {
+			inits = inst.module.elems[in.a].inits
+		}
+		table := inst.tables[in.b]
+		if s+n > uint64(len(inits)) || d+n > uint64(len(table)) {
+			panic(Trap("out of bounds table access"))
+		}
+		for i := range n {
+			ref, err := inst.evalConst(inits[s+i])
+			if err != nil {
+				panic(err)
+			}
+			table[d+i] = ref
+		}
+	case opElemDrop:
+		inst.droppedElem[in.a] = true
+	case opTableCopy:
+		n, s, d := uint64(uint32(inst.pop())), uint64(uint32(inst.pop())), uint64(uint32(inst.pop()))
+		dst, src := inst.tables[in.a], inst.tables[in.b]
+		if s+n > uint64(len(src)) || d+n > uint64(len(dst)) {
+			panic(Trap("out of bounds table access"))
+		}
+		copy(dst[d:d+n], src[s:s+n])
+	case opTableGrow:
+		n, ref := uint64(uint32(inst.pop())), inst.pop()
+		table := inst.tables[in.a]
+		old := uint64(len(table))
+		limit := uint64(math.MaxUint32)
+		if limits := inst.module.tables[in.a].limits; limits.Max != nil {
+			limit = uint64(*limits.Max)
+		}
+		if old+n > limit {
+			inst.push(uint64(math.MaxUint32))
+			return
+		}
+		for range n {
+			table = append(table, ref)
+		}
+		inst.tables[in.a] = table
+		inst.push(old)
+	case opTableSize:
+		inst.push(uint64(len(inst.tables[in.a])))
+	case opTableFill:
+		n, ref, d := uint64(uint32(inst.pop())), inst.pop(), uint64(uint32(inst.pop()))
+		table := inst.tables[in.a]
+		if d+n > uint64(len(table)) {
+			panic(Trap("out of bounds table access"))
+		}
+		for i := range n {
+			table[d+i] = ref
+		}
+	}
+}
+
+// numeric executes the instructions without immediates that compute on
+// numbers: comparisons, arithmetic, and conversions.
+func (inst *Instance) numeric(op uint16) {
+	switch {
+	case op == 0x45: // i32.eqz
+		inst.push(boolValue(uint32(inst.pop()) == 0))
+	case op >= 0x46 && op <= 0x4F:
+		b, a := uint32(inst.pop()), uint32(inst.pop())
+		inst.push(boolValue(compareInt(op-0x46, uint64(a), uint64(b), int64(int32(a)), int64(int32(b)))))
+	case op == 0x50: // i64.eqz
+		inst.push(boolValue(inst.pop() == 0))
+	case op >= 0x51 && op <= 0x5A:
+		b, a := inst.pop(), inst.pop()
+		inst.push(boolValue(compareInt(op-0x51, a, b, int64(a), int64(b))))
+	case op >= 0x5B && op <= 0x60:
+		b, a := f32(inst.pop()), f32(inst.pop())
+		inst.push(boolValue(compareFloat(op-0x5B, float64(a), float64(b))))
+	case op >= 0x61 && op <= 0x66:
+		b, a := f64(inst.pop()), f64(inst.pop())
+		inst.push(boolValue(compareFloat(op-0x61, a, b)))
+
+	case op >= 0x67 && op <= 0x69:
+		a := uint32(inst.pop())
+		switch op {
+		case 0x67:
+			inst.push(uint64(bits.LeadingZeros32(a)))
+		case 0x68:
+			inst.push(uint64(bits.TrailingZeros32(a)))
+		case 0x69:
+			inst.push(uint64(bits.OnesCount32(a)))
+		}
+	case op >= 0x6A && op <= 0x78:
+		b, a := uint32(inst.pop()), uint32(inst.pop())
+		inst.push(uint64(i32Binary(op, a, b)))
+	case op >= 0x79 && op <= 0x7B:
+		a := inst.pop()
+		switch op {
+		case 0x79:
+			inst.push(uint64(bits.LeadingZeros64(a)))
+		case 0x7A:
+			inst.push(uint64(bits.TrailingZeros64(a)))
+		case 0x7B:
+			inst.push(uint64(bits.OnesCount64(a)))
+		}
+	case op >= 0x7C && op <= 0x8A:
+		b, a := inst.pop(), inst.pop()
+		inst.push(i64Binary(op, a, b))
+
+	case op >= 0x8B && op <= 0x91:
+		v := inst.pop()
+		switch op {
+		case 0x8B: // f32.abs
+			inst.push(v &^ (1 << 31))
+		case 0x8C: // f32.neg
+			inst.push(v ^ (1 << 31))
+		default:
+			inst.push(fromF32(float32(floatUnary(op-0x8B+0x99, float64(f32(v))))))
+		}
+	case op >= 0x92 && op <= 0x98:
+		b, a := f32(inst.pop()), f32(inst.pop())
+		var r float32
+		switch op {
+		case 0x92:
+			r = a + b
+		case 0x93:
+			r = a - b
+		case 0x94:
+			r = a * b
+		case 0x95:
+			r = a / b
+		default:
+			r = float32(floatBinary(op-0x92+0xA0, float64(a), float64(b)))
+		}
+		inst.push(fromF32(r))
+	case op >= 0x99 && op <= 0x9F:
+		v := inst.pop()
+		switch op {
+		case 0x99: // f64.abs
+			inst.push(v &^ (1 << 63))
+		case 0x9A: // f64.neg
+			inst.push(v ^ (1 << 63))
+		default:
+			inst.push(math.Float64bits(floatUnary(op, f64(v))))
+		}
+	case op >= 0xA0 && op <= 0xA6:
+		b, a := f64(inst.pop()), f64(inst.pop())
+		inst.push(math.Float64bits(floatBinary(op, a, b)))
+
+	default:
+		inst.push(convert(op, inst.pop()))
+	}
+}
+
+// compareInt evaluates the integer comparison with the given offset from
+// eq: eq, ne, lt_s, lt_u, gt_s, gt_u, le_s, le_u, ge_s, ge_u.
+func compareInt(kind uint16, ua, ub uint64, sa, sb int64) bool {
+	switch kind {
+	case 0:
+		return ua == ub
+	case 1:
+		return ua != ub
+	case 2:
+		return sa < sb
+	case 3:
+		return ua < ub
+	case 4:
+		return sa > sb
+	case 5:
+		return ua > ub
+	case 6:
+		return sa <= sb
+	case 7:
+		return ua <= ub
+	case 8:
+		return sa >= sb
+	default:
+		return ua >= ub
+	}
+}
+
+// compareFloat evaluates the float comparison with the given offset from
+// eq: eq, ne, lt, gt, le, ge.
+func compareFloat(kind uint16, a, b float64) bool {
+	switch kind {
+	case 0:
+		return a == b
+	case 1:
+		return a != b
+	case 2:
+		return a < b
+	case 3:
+		return a > b
+	case 4:
+		return a <= b
+	default:
+		return a >= b
+	}
+}
+
+func i32Binary(op uint16, a, b uint32) uint32 {
+	switch op {
+	case 0x6A:
+		return a + b
+	case 0x6B:
+		return a - b
+	case 0x6C:
+		return a * b
+	case 0x6D: // div_s
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			panic(Trap("integer overflow"))
+		}
+		return uint32(int32(a) / int32(b))
+	case 0x6E: // div_u
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		return a / b
+	case 0x6F: // rem_s
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		return uint32(int32(a) % int32(b))
+	case 0x70: // rem_u
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		return a % b
+	case 0x71:
+		return a & b
+	case 0x72:
+		return a | b
+	case 0x73:
+		return a ^ b
+	case 0x74:
+		return a << (b & 31)
+	case 0x75:
+		return uint32(int32(a) >> (b & 31))
+	case 0x76:
+		return a >> (b & 31)
+	case 0x77:
+		return bits.RotateLeft32(a, int(b&31))
+	default:
+		return bits.RotateLeft32(a, -int(b&31))
+	}
+}
+
+func i64Binary(op uint16, a, b uint64) uint64 {
+	switch op {
+	case 0x7C:
+		return a + b
+	case 0x7D:
+		return a - b
+	case 0x7E:
+		return a * b
+	case 0x7F: // div_s
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			panic(Trap("integer overflow"))
+		}
+		return uint64(int64(a) / int64(b))
+	case 0x80: // div_u
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		return a / b
+	case 0x81: // rem_s
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		return uint64(int64(a) % int64(b))
+	case 0x82: // rem_u
+		if b == 0 {
+			panic(Trap("integer divide by zero"))
+		}
+		return a % b
+	case 0x83:
+		return a & b
+	case 0x84:
+		return a | b
+	case 0x85:
+		return a ^ b
+	case 0x86:
+		return a << (b & 63)
+	case 0x87:
+		return uint64(int64(a) >> (b & 63))
+	case 0x88:
+		return a >> (b & 63)
+	case 0x89:
+		return bits.RotateLeft64(a, int(b&63))
+	default:
+		return bits.RotateLeft64(a, -int(b&63))
+	}
+}
+
+// floatUnary evaluates ceil, floor, trunc, nearest, or sqrt, given by their
+// f64 opcodes.
+func floatUnary(op uint16, v float64) float64 {
+	switch op {
+	case 0x9B:
+		return math.Ceil(v)
+	case 0x9C:
+		return math.Floor(v)
+	case 0x9D:
+		return math.Trunc(v)
+	case 0x9E:
+		return math.RoundToEven(v)
+	default:
+		return math.Sqrt(v)
+	}
+}
+
+// floatBinary evaluates an f64 binary operator. The f32 variants of min,
+// max, and copysign compute exactly in f64, too.
+func floatBinary(op uint16, a, b float64) float64 {
+	switch op {
+	case 0xA0:
+		return a + b
+	case 0xA1:
+		return a - b
+	case 0xA2:
+		return a * b
+	case 0xA3:
+		return a / b
+	case 0xA4:
+		return math.Min(a, b)
+	case 0xA5:
+		return math.Max(a, b)
+	default:
+		return math.Copysign(a, b)
+	}
+}
+
+// convert evaluates a conversion or sign extension.
+func convert(op uint16, v uint64) uint64 {
+	switch op {
+	case 0xA7: // i32.wrap_i64
+		return uint64(uint32(v))
+	case 0xA8: // i32.trunc_f32_s
+		return uint64(uint32(int32(truncSigned(float64(f32(v)), 32))))
+	case 0xA9: // i32.trunc_f32_u
+		return uint64(uint32(truncUnsigned(float64(f32(v)), 32)))
+	case 0xAA: // i32.trunc_f64_s
+		return uint64(uint32(int32(truncSigned(f64(v), 32))))
+	case 0xAB: // i32.trunc_f64_u
+		return uint64(uint32(truncUnsigned(f64(v), 32)))
+	case 0xAC: // i64.extend_i32_s
+		return uint64(int64(int32(v)))
+	case 0xAD: // i64.extend_i32_u
+		return uint64(uint32(v))
+	case 0xAE: // i64.trunc_f32_s
+		return uint64(int64(truncSigned(float64(f32(v)), 64)))
+	case 0xAF: // i64.trunc_f32_u
+		return toUint64(truncUnsigned(float64(f32(v)), 64))
+	case 0xB0: // i64.trunc_f64_s
+		return uint64(int64(truncSigned(f64(v), 64)))
+	case 0xB1: // i64.trunc_f64_u
+		return toUint64(truncUnsigned(f64(v), 64))
+	case 0xB2: // f32.convert_i32_s
+		return fromF32(float32(int32(v)))
+	case 0xB3: // f32.convert_i32_u
+		return fromF32(float32(uint32(v)))
+	case 0xB4: // f32.convert_i64_s
+		return fromF32(float32(int64(v)))
+	case 0xB5: // f32.convert_i64_u
+		return fromF32(float32(v))
+	case 0xB6: // f32.demote_f64
+		return fromF32(float32(f64(v)))
+	case 0xB7: // f64.convert_i32_s
+		return math.Float64bits(float64(int32(v)))
+	case 0xB8: // f64.convert_i32_u
+		return math.Float64bits(float64(uint32(v)))
+	case 0xB9: // f64.convert_i64_s
+		return math.Float64bits(float64(int64(v)))
+	case 0xBA: // f64.convert_i64_u
+		return math.Float64bits(float64(v))
+	case 0xBB: // f64.promote_f32
+		return math.Float64bits(float64(f32(v)))
+	case 0xBC, 0xBD, 0xBE, 0xBF: // reinterpretations
+		return v
+	case 0xC0: // i32.extend8_s
+		return uint64(uint32(int32(int8(v))))
+	case 0xC1: // i32.extend16_s
+		return uint64(uint32(int32(int16(v))))
+	case 0xC2: // i64.extend8_s
+		return uint64(int64(int8(v)))
+	case 0xC3: // i64.extend16_s
+		return uint64(int64(int16(v)))
+	default: // i64.extend32_s
+		return uint64(int64(int32(v)))
+	}
+}
+
+// truncSigned truncates a float towards zero and traps unless the result
+// fits into a signed integer of the given width.
+func truncSigned(v float64, width int) float64 {
+	if math.IsNaN(v) {
+		panic(Trap("invalid conversion to integer"))
+	}
+	t := math.Trunc(v)
+	if limit := math.Ldexp(1, width-1); t < -limit || t >= limit {
+		panic(Trap("integer overflow"))
+	}
+	return t
+}
+
+// truncUnsigned truncates a float towards zero and traps unless the result
+// fits into an unsigned integer of the given width.
+func truncUnsigned(v float64, width int) float64 {
+	if math.IsNaN(v) {
+		panic(Trap("invalid conversion to integer"))
+	}
+	t := math.Trunc(v)
+	if t <= -1 || t >= math.Ldexp(1, width) {
+		panic(Trap("integer overflow"))
+	}
+	return t
+}
+
+// toUint64 converts a truncated float in [0, 2^64) to an integer.
+func toUint64(t float64) uint64 {
+	if t >= 1<<63 {
+		return uint64(t-(1<<63)) | 1<<63
+	}
+	return uint64(t)
+}
+
+func satS32(v float64) int32 {
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v <= math.MinInt32:
+		return math.MinInt32
+	case v >= math.MaxInt32:
+		return math.MaxInt32
+	}
+	return int32(v)
+}
+
+func satU32(v float64) uint32 {
+	switch {
+	case math.IsNaN(v), v <= 0:
+		return 0
+	case v >= math.MaxUint32:
+		return math.MaxUint32
+	}
+	return uint32(v)
+}
+
+func satS64(v float64) int64 {
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v <= math.MinInt64:
+		return math.MinInt64
+	case v >= 1<<63:
+		return math.MaxInt64
+	}
+	return int64(v)
+}
+
+func satU64(v float64) uint64 {
+	switch {
+	case math.IsNaN(v), v <= 0:
+		return 0
+	case v >= 1<<64:
+		return math.MaxUint64
+	}
+	return toUint64(math.Trunc(v))
+}
+
+func fromF32(v float32) uint64 {
+	return uint64(math.Float32bits(v))
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,255 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// PageSize is the size of a page of linear memory.
+const PageSize = 65536
+
+// maxPages bounds the linear memory of modules that declare no maximum.
+const maxPages = 65536
+
+// maxCallDepth bounds the nesting of calls, so that runaway recursion traps
+// instead of exhausting the host stack.
+const maxCallDepth = 4096
+
+// HostFunc is a function provided by the host for import by a module.
+type HostFunc struct {
+	Type FuncType
+	// Func receives the instance the call comes from and the arguments, and
+	// returns the results. An error aborts the execution of the module.
+	Func func(inst *Instance, args []uint64) ([]uint64, error)
+}
+
+// Trap is an error raised by the execution of a module.
+type Trap string
+
+func (t Trap) Error() string {
+	return "wasm trap: " + string(t)
+}
+
+// hostError carries an error returned by a host function through the
+// interpreter.
+type hostError struct {
+	err error
+}
+
+// Instance is an instantiated module with its own memory, tables, and
+// globals. An instance must not be used concurrently.
+type Instance struct {
+	module  *Module
+	host    []HostFunc
+	memory  []byte
+	globals []uint64
+	// tables hold function references as the function index plus one, with
+	// zero being the null reference.
+	tables      [][]uint64
+	droppedData []bool
+	droppedElem []bool
+
+	// Data holds state of the host, for use by host functions. It is shared
+	// by clones.
+	Data any
+
+	stack []uint64
+	depth int
+}
+
+// Instantiate creates an instance of a module. The imports of the module
+// are resolved by module and name in imports. Active data and element
+// segments are applied and the start function is run.
+func Instantiate(m *Module, imports map[string]map[string]HostFunc) (*Instance, error) {
+	inst := &Instance{
+		module:      m,
+		droppedData: make([]bool, len(m.data)),
+		droppedElem: make([]bool, len(m.elems)),
+	}
+
+	for _, imp := range m.imports {
+		f, ok := imports[imp.Module][imp.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown import %s.%s", imp.Module, imp.Name)
+		}
+		if !f.Type.equal(imp.Type) {
+			return nil, fmt.Errorf("import %s.%s has type %v, expected %v", imp.Module, imp.Name, f.Type, imp.Type)
+		}
+		inst.host = append(inst.host, f)
+	}
+
+	if len(m.memories) > 0 {
+		if uint64(m.memories[0].Min) > inst.maxPages() {
+			return nil, errors.New("memory exceeds its maximum size")
+		}
+		inst.memory = make([]byte, int(m.memories[0].Min)*PageSize)
+	}
+
+	for _, g := range m.globals {
+		v, err := inst.evalConst(g.init)
+		if err != nil {
+			return nil, err
+		}
+		inst.globals = append(inst.globals, v)
+	}
+
+	for _, t := range m.tables {
+		inst.tables = append(inst.tables, make([]uint64, t.limits.Min))
+	}
+
+	for i, seg := range m.elems {
+		if seg.mode == 1 {
+			continue
+		}
+		inst.droppedElem[i] = true
+		if seg.mode == 2 {
+			continue
+		}
+		offset, err := inst.evalConst(seg.offset)
+		if err != nil {
+			return nil, err
+		}
+		if int(seg.table) >= len(inst.tables) {
+			return nil, errors.New("element segment refers to an unknown table")
+		}
+		table := inst.tables[seg.table]
+		start := uint64(uint32(offset))
+		if start+uint64(len(seg.inits)) > uint64(len(table)) {
+			return nil, Trap("out of bounds table access")
+		}
+		for j, init := range seg.inits {
+			if table[start+uint64(j)], err = inst.evalConst(init); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, seg := range m.data {
+		if seg.passive {
+			continue
+		}
+		inst.droppedData[i] = true
+		offset, err := inst.evalConst(seg.offset)
+		if err != nil {
+			return nil, err
+		}
+		start := uint64(uint32(offset))
+		if seg.memory != 0 || start+uint64(len(seg.data)) > uint64(len(inst.memory)) {
+			return nil, Trap("out of bounds memory access")
+		}
+		copy(inst.memory[start:], seg.data)
+	}
+
+	if m.start != nil {
+		if err := inst.run(*m.start); err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+// evalConst evaluates a constant expression.
+func (inst *Instance) evalConst(e constExpr) (uint64, error) {
+	switch e.op {
+	case opGlobalGet:
+		if e.imm >= uint64(len(inst.globals)) {
+			return 0, errors.New("constant expression refers to an unknown global")
+		}
+		return inst.globals[e.imm], nil
+	case opRefNull:
+		return 0, nil
+	case opRefFunc:
+		if e.imm >= uint64(inst.module.numFuncs()) {
+			return 0, errors.New("constant expression refers to an unknown function")
+		}
+		return e.imm + 1, nil
+	default:
+		return e.imm, nil
+	}
+}
+
+// maxPages returns the maximum number of pages of the memory.
+func (inst *Instance) maxPages() uint64 {
+	if len(inst.module.memories) > 0 && inst.module.memories[0].Max != nil {
+		return min(uint64(*inst.module.memories[0].Max), maxPages)
+	}
+	return maxPages
+}
+
+// Memory returns the linear memory of the instance. The slice is only valid
+// until the memory grows.
+func (inst *Instance) Memory() []byte {
+	return inst.memory
+}
+
+// Clone creates an independent copy of the instance in its current state.
+func (inst *Instance) Clone() *Instance {
+	clone := &Instance{
+		module:      inst.module,
+		host:        inst.host,
+		memory:      slices.Clone(inst.memory),
+		globals:     slices.Clone(inst.globals),
+		droppedData: slices.Clone(inst.droppedData),
+		droppedElem: slices.Clone(inst.droppedElem),
+		Data:        inst.Data,
+	}
+	for _, t := range inst.tables {
+		clone.tables = append(clone.tables, slices.Clone(t))
+	}
+	return clone
+}
+
+// Call calls an exported function. Integers are passed and returned in
+// their two's complement representation, floats as their bit patterns.
+func (inst *Instance) Call(name string, args ...uint64) ([]uint64, error) {
+	index, ok := inst.exportedFunc(name)
+	if !ok {
+		return nil, fmt.Errorf("module has no function %s", name)
+	}
+	typ := inst.module.funcType(index)
+	if len(args) != len(typ.Params) {
+		return nil, fmt.Errorf("function %s takes %d arguments, got %d", name, len(typ.Params), len(args))
+	}
+	inst.stack = append(inst.stack[:0], args...)
+	if err := inst.run(index); err != nil {
+		return nil, err
+	}
+	results := slices.Clone(inst.stack[len(inst.stack)-len(typ.Results):])
+	inst.stack = inst.stack[:0]
+	return results, nil
+}
+
+func (inst *Instance) exportedFunc(name string) (uint32, bool) {
+	for _, e := range inst.module.exports {
+		if e.Name == name && e.Kind == ExternFunc {
+			return e.Index, true
+		}
+	}
+	return 0, false
+}
+
+// run invokes a function with its arguments on the stack and converts traps
+// and host errors into errors.
+func (inst *Instance) run(index uint32) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			inst.depth = 0
+			inst.stack = inst.stack[:0]
+			switch r := r.(type) {
+			case Trap:
+				err = r
+			case hostError:
+				err = r.err
+			case error:
+				// Modules are not type-checked up front, so ill-typed code
+				// may fail in the interpreter instead.
+				err = fmt.Errorf("invalid module: %w", r)
+			default:
+				panic(r)
+			}
+		}
+	}()
+	inst.invoke(index)
+	return nil
+}
@@ -0,0 +1,374 @@
+package wasm
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// The helpers below assemble modules in the binary format.
+
+func uleb(v uint64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7F)
+		v >>= 7
+		if v != 0 {
+			out = append(out, b|0x80)
+			continue
+		}
+		return append(out, b)
+	}
+}
+
+func sleb(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7F)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func cat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func vec(items ...[]byte) []byte {
+	return cat(uleb(uint64(len(items))), cat(items...))
+}
+
+func str(s string) []byte {
+	return cat(uleb(uint64(len(s))), []byte(s))
+}
+
+func section(id byte, items ...[]byte) []byte {
+	body := vec(items...)
+	return cat([]byte{id}, uleb(uint64(len(body))), body)
+}
+
+func funcType(params, results []ValueType) []byte {
+	p := make([]byte, len(params))
+	for i, t := range params {
+		p[i] = byte(t)
+	}
+	r := make([]byte, len(results))
+	for i, t := range results {
+		r[i] = byte(t)
+	}
+	return cat([]byte{0x60}, uleb(uint64(len(p))), p, uleb(uint64(len(r))), r)
+}
+
+func exportFunc(name string, index uint32) []byte {
+	return cat(str(name), []byte{ExternFunc}, uleb(uint64(index)))
+}
+
+// body assembles a function body with the given locals and instructions.
+// The final end is appended.
+func body(locals []ValueType, instrs ...[]byte) []byte {
+	var groups [][]byte
+	for _, t := range locals {
+		groups = append(groups, []byte{1, byte(t)})
+	}
+	b := cat(vec(groups...), cat(instrs...), []byte{opEnd})
+	return cat(uleb(uint64(len(b))), b)
+}
+
+func op(code byte, imms ...uint64) []byte {
+	out := []byte{code}
+	for _, imm := range imms {
+		out = append(out, uleb(imm)...)
+	}
+	return out
+}
+
+func i32Const(v int32) []byte { return cat([]byte{opI32Const}, sleb(int64(v))) }
+func i64Const(v int64) []byte { return cat([]byte{opI64Const}, sleb(v)) }
+
+var (
+	i32    = []ValueType{I32}
+	i64    = []ValueType{I64}
+	i32i32 = []ValueType{I32, I32}
+)
+
+func instantiate(t *testing.T, module []byte, imports map[string]map[string]HostFunc) *Instance {
+	t.Helper()
+	m, err := Decode(cat([]byte("\x00asm\x01\x00\x00\x00"), module))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := Instantiate(m, imports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+func call(t *testing.T, inst *Instance, name string, args ...uint64) uint64 {
+	t.Helper()
+	results, err := inst.Call(name, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%s returned %d results", name, len(results))
+	}
+	return results[0]
+}
+
+func TestArithmetic(t *testing.T) {
+	inst := instantiate(t, cat(
+		section(1, funcType(i32i32, i32), funcType([]ValueType{F64, F64}, []ValueType{F64})),
+		section(3, uleb(0), uleb(0), uleb(1)),
+		section(7, exportFunc("sub", 0), exportFunc("div_s", 1), exportFunc("min", 2)),
+		section(10,
+			body(nil, op(opLocalGet, 0), op(opLocalGet, 1), op(0x6B)),
+			body(nil, op(opLocalGet, 0), op(opLocalGet, 1), op(0x6D)),
+			body(nil, op(opLocalGet, 0), op(opLocalGet, 1), op(0xA4)),
+		),
+	), nil)
+
+	if got := int32(call(t, inst, "sub", 3, 5)); got != -2 {
+		t.Errorf("sub(3, 5) = %d, want -2", got)
+	}
+	if got := int32(call(t, inst, "div_s", uint64(uint32(0xFFFFFFF9)), 2)); got != -3 {
+		t.Errorf("div_s(-7, 2) = %d, want -3", got)
+	}
+	negZero := math.Float64bits(math.Copysign(0, -1))
+	if got := call(t, inst, "min", 0, negZero); got != negZero {
+		t.Errorf("min(0, -0) = %v, want -0", math.Float64frombits(got))
+	}
+
+	_, err := inst.Call("div_s", 1, 0)
+	var trap Trap
+	if !errors.As(err, &trap) || trap != "integer divide by zero" {
+		t.Errorf("div_s(1, 0): err = %v, want divide by zero trap", err)
+	}
+	// The instance remains usable after a trap.
+	if got := int32(call(t, inst, "sub", 1, 1)); got != 0 {
+		t.Errorf("sub(1, 1) after trap = %d, want 0", got)
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	// fact(n) computes the factorial recursively with if/else, sum(n) adds
+	// 1..n in a loop, and pick(i) selects a constant with br_table.
+	fact := body(nil,
+		op(opLocalGet, 0), i64Const(1), op(0x58), // i64.le_s
+		op(opIf, uint64(I64)),
+		i64Const(1),
+		op(opElse),
+		op(opLocalGet, 0),
+		op(opLocalGet, 0), i64Const(1), op(0x7D), // i64.sub
+		op(opCall, 0),
+		op(0x7E), // i64.mul
+		op(opEnd),
+	)
+	sum := body(i32,
+		op(opBlock, 0x40),
+		op(opLoop, 0x40),
+		op(opLocalGet, 0), op(0x45), op(opBrIf, 1), // exit if n == 0
+		op(opLocalGet, 1), op(opLocalGet, 0), op(0x6A), op(opLocalSet, 1),
+		op(opLocalGet, 0), i32Const(1), op(0x6B), op(opLocalSet, 0),
+		op(opBr, 0),
+		op(opEnd),
+		op(opEnd),
+		op(opLocalGet, 1),
+	)
+	pick := body(nil,
+		op(opBlock, 0x40),
+		op(opBlock, 0x40),
+		op(opLocalGet, 0),
+		op(opBrTable, 1, 0, 1),
+		op(opEnd),
+		i32Const(10), op(opReturn),
+		op(opEnd),
+		i32Const(20),
+	)
+	inst := instantiate(t, cat(
+		section(1, funcType(i64, i64), funcType(i32, i32)),
+		section(3, uleb(0), uleb(1), uleb(1)),
+		section(7, exportFunc("fact", 0), exportFunc("sum", 1), exportFunc("pick", 2)),
+		section(10, fact, sum, pick),
+	), nil)
+
+	if got := call(t, inst, "fact", 20); got != 2432902008176640000 {
+		t.Errorf("fact(20) = %d", got)
+	}
+	if got := call(t, inst, "sum", 100); got != 5050 {
+		t.Errorf("sum(100) = %d, want 5050", got)
+	}
+	for i, want := range []uint64{10, 20, 20} {
+		if got := call(t, inst, "pick", uint64(i)); got != want {
+			t.Errorf("pick(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestMemoryAndHost(t *testing.T) {
+	// sum_bytes(ptr, len) asks the host to report each byte and returns
+	// their sum. Memory starts with a data segment.
+	var reported []byte
+	imports := map[string]map[string]HostFunc{"env": {"report": {
+		Type: FuncType{Params: i32},
+		Func: func(inst *Instance, args []uint64) ([]uint64, error) {
+			reported = append(reported, inst.Memory()[args[0]])
+			return nil, nil
+		},
+	}}}
+	sumBytes := body(i32,
+		op(opBlock, 0x40),
+		op(opLoop, 0x40),
+		op(opLocalGet, 1), op(0x45), op(opBrIf, 1),
+		op(opLocalGet, 0), op(opCall, 0),
+		op(opLocalGet, 2), op(opLocalGet, 0), op(0x2D, 0, 0), op(0x6A), op(opLocalSet, 2), // i32.load8_u
+		op(opLocalGet, 0), i32Const(1), op(0x6A), op(opLocalSet, 0),
+		op(opLocalGet, 1), i32Const(1), op(0x6B), op(opLocalSet, 1),
+		op(opBr, 0),
+		op(opEnd),
+		op(opEnd),
+		op(opLocalGet, 2),
+	)
+	grow := body(nil, op(opLocalGet, 0), op(opMemoryGrow, 0))
+	inst := instantiate(t, cat(
+		section(1, funcType(i32, nil), funcType(i32i32, i32), funcType(i32, i32)),
+		section(2, cat(str("env"), str("report"), []byte{ExternFunc}, uleb(0))),
+		section(3, uleb(1), uleb(2)),
+		section(5, []byte{0x01, 1, 2}),
+		section(7, exportFunc("sum_bytes", 1), exportFunc("grow", 2)),
+		section(10, sumBytes, grow),
+		section(11, cat(uleb(0), i32Const(16), []byte{opEnd}, str("\x01\x02\x03"))),
+	), imports)
+
+	if got := call(t, inst, "sum_bytes", 16, 3); got != 6 {
+		t.Errorf("sum_bytes = %d, want 6", got)
+	}
+	if string(reported) != "\x01\x02\x03" {
+		t.Errorf("reported %v", reported)
+	}
+	if _, err := inst.Call("sum_bytes", PageSize-1, 2); err == nil {
+		t.Error("out of bounds access did not trap")
+	}
+
+	if got := call(t, inst, "grow", 1); got != 1 {
+		t.Errorf("grow(1) = %d, want old size 1", got)
+	}
+	if got := uint32(call(t, inst, "grow", 1)); got != math.MaxUint32 {
+		t.Errorf("growing past the maximum = %d, want -1", got)
+	}
+}
+
+func TestHostError(t *testing.T) {
+	failure := errors.New("host failure")
+	imports := map[string]map[string]HostFunc{"env": {"fail": {
+		Func: func(*Instance, []uint64) ([]uint64, error) { return nil, failure },
+	}}}
+	inst := instantiate(t, cat(
+		section(1, funcType(nil, nil)),
+		section(2, cat(str("env"), str("fail"), []byte{ExternFunc}, uleb(0))),
+		section(3, uleb(0)),
+		section(7, exportFunc("run", 1)),
+		section(10, body(nil, op(opCall, 0))),
+	), imports)
+
+	if _, err := inst.Call("run"); !errors.Is(err, failure) {
+		t.Errorf("err = %v, want host failure", err)
+	}
+}
+
+func TestCallIndirect(t *testing.T) {
+	// dispatch(i) calls the i-th function of the table.
+	inst := instantiate(t, cat(
+		section(1, funcType(nil, i32), funcType(i32, i32)),
+		section(3, uleb(0), uleb(0), uleb(1)),
+		section(4, []byte{byte(FuncRef), 0x00, 3}),
+		section(7, exportFunc("dispatch", 2)),
+		section(9, cat(uleb(0), i32Const(0), []byte{opEnd}, vec(uleb(0), uleb(1)))),
+		section(10,
+			body(nil, i32Const(7)),
+			body(nil, i32Const(8)),
+			body(nil, op(opLocalGet, 0), op(opCallIndirect, 0, 0)),
+		),
+	), nil)
+
+	if got := call(t, inst, "dispatch", 1); got != 8 {
+		t.Errorf("dispatch(1) = %d, want 8", got)
+	}
+	if _, err := inst.Call("dispatch", 2); err == nil || err.Error() != "wasm trap: uninitialized element" {
+		t.Errorf("dispatch(2): err = %v", err)
+	}
+	if _, err := inst.Call("dispatch", 3); err == nil || err.Error() != "wasm trap: undefined element" {
+		t.Errorf("dispatch(3): err = %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	// next() increments a mutable global and returns it.
+	inst := instantiate(t, cat(
+		section(1, funcType(nil, i32)),
+		section(3, uleb(0)),
+		section(6, cat([]byte{byte(I32), 1}, i32Const(0), []byte{opEnd})),
+		section(7, exportFunc("next", 0)),
+		section(10, body(nil,
+			op(opGlobalGet, 0), i32Const(1), op(0x6A), op(opGlobalSet, 0),
+			op(opGlobalGet, 0),
+		)),
+	), nil)
+
+	call(t, inst, "next")
+	clone := inst.Clone()
+	if got := call(t, clone, "next"); got != 2 {
+		t.Errorf("clone: next() = %d, want 2", got)
+	}
+	if got := call(t, clone, "next"); got != 3 {
+		t.Errorf("clone: next() = %d, want 3", got)
+	}
+	if got := call(t, inst, "next"); got != 2 {
+		t.Errorf("original: next() = %d, want 2", got)
+	}
+}
+
+func TestRecursionLimit(t *testing.T) {
+	inst := instantiate(t, cat(
+		section(1, funcType(nil, nil)),
+		section(3, uleb(0)),
+		section(7, exportFunc("loop", 0)),
+		section(10, body(nil, op(opCall, 0))),
+	), nil)
+
+	if _, err := inst.Call("loop"); err == nil || err.Error() != "wasm trap: call stack exhausted" {
+		t.Errorf("err = %v, want exhausted call stack", err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"magic", []byte("\x00wat\x01\x00\x00\x00")},
+		{"truncated", cat([]byte("\x00asm\x01\x00\x00\x00"), []byte{1, 10})},
+		{"unterminated body", cat([]byte("\x00asm\x01\x00\x00\x00"),
+			section(1, funcType(nil, nil)),
+			section(3, uleb(0)),
+			section(10, cat(uleb(2), []byte{0, opNop})),
+		)},
+		{"unknown local", cat([]byte("\x00asm\x01\x00\x00\x00"),
+			section(1, funcType(nil, nil)),
+			section(3, uleb(0)),
+			section(10, body(nil, op(opLocalGet, 3), op(opDrop))),
+		)},
+	}
+	for _, tt := range tests {
+		if _, err := Decode(tt.data); err == nil {
+			t.Errorf("%s: decoding succeeded", tt.name)
+		}
+	}
+}